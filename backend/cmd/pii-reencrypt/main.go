@@ -0,0 +1,97 @@
+// Command pii-reencrypt prepares and maintains encryption at rest for the designated PII
+// columns (see database.PIIColumns).
+//
+// Enabling encryption on an existing database:
+//
+//  1. go run ./cmd/pii-reencrypt -widen-only   (make the columns TEXT; no keys needed)
+//  2. set PII_ENCRYPTION_KEYS and PII_ENCRYPTION_ACTIVE_KEY and deploy the app
+//  3. go run ./cmd/pii-reencrypt               (encrypt the plaintext written before step 2)
+//
+// Rotating keys: add the new key to PII_ENCRYPTION_KEYS, make it the active key, deploy, then
+// run the command again to re-encrypt values still under the old key. The old key can be
+// removed once a run reports nothing left to rewrite.
+//
+// Usage:
+//
+//	go run ./cmd/pii-reencrypt -dry-run
+//	go run ./cmd/pii-reencrypt -batch 1000 -skip-widen
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+)
+
+func main() {
+	widenOnly := flag.Bool("widen-only", false, "only widen the PII columns to TEXT, without rewriting values")
+	skipWiden := flag.Bool("skip-widen", false, "do not widen the PII columns before rewriting")
+	dryRun := flag.Bool("dry-run", false, "report what would be rewritten without changing anything")
+	batchSize := flag.Int("batch", 500, "rows read per batch")
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	logger.SetOutput(os.Stdout)
+
+	if *widenOnly && *skipWiden {
+		logger.Fatal("Invalid flags: -widen-only and -skip-widen are mutually exclusive")
+	}
+	if *batchSize < 1 {
+		logger.Fatal("Invalid flags: -batch must be at least 1")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	piiCipher, err := database.NewPIICipher(cfg.PIIEncryption)
+	if err != nil {
+		logger.Fatalf("Failed to load PII encryption keys: %v", err)
+	}
+	if piiCipher == nil && !*widenOnly {
+		logger.Fatal("Refusing to rewrite: PII_ENCRYPTION_KEYS is not set (use -widen-only to only prepare the columns)")
+	}
+
+	logger.Info("Connecting to database...")
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		logger.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB, ok := db.(*database.PostgresDB)
+	if !ok {
+		logger.Fatal("Failed to cast database to PostgresDB")
+	}
+	reencryptor := database.NewPIIReencryptor(sqlxDB.DB, piiCipher)
+
+	if !*skipWiden && !*dryRun {
+		for _, col := range database.PIIColumns {
+			if err := reencryptor.WidenColumn(col); err != nil {
+				logger.Fatalf("Failed to prepare columns: %v", err)
+			}
+			logger.Infof("Widened %s to TEXT", col)
+		}
+	}
+	if *widenOnly {
+		return
+	}
+
+	logger.Infof("Re-encrypting with active key %s (dry run: %t)", piiCipher.ActiveKeyID(), *dryRun)
+	for _, col := range database.PIIColumns {
+		result, err := reencryptor.Rewrite(col, *batchSize, *dryRun)
+		if err != nil {
+			logger.Fatalf("Re-encryption failed: %v", err)
+		}
+		logger.WithFields(logrus.Fields{
+			"scanned":   result.Scanned,
+			"rewritten": result.Rewritten,
+			"skipped":   result.Skipped,
+		}).Infof("Re-encrypted %s", col)
+	}
+}
@@ -0,0 +1,705 @@
+// Command seed-staging populates a staging database with realistic, linked test data:
+// verified bus owners → route permits → buses → owner routes → timetables →
+// published trips with seats → sample passenger bookings.
+//
+// Every record is derived from a deterministic key (phone number, permit number,
+// license plate, schedule name), so running the command again only fills in
+// whatever is missing instead of duplicating data.
+//
+// Usage:
+//
+//	go run ./cmd/seed-staging -owners 5 -routes-per-owner 2 -days 7 -bookings-per-trip 3
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
+)
+
+const (
+	// seedPrefix marks every seeded identifier so staging data is easy to spot and clean up
+	seedPrefix = "STG"
+
+	ownerPhoneBase     = 94770000000 // +94770000001, +94770000002, ...
+	passengerPhoneBase = 94771000000 // +94771000001, +94771000002, ...
+)
+
+// departureSlots are the departure times handed out to seeded timetables, in order
+var departureSlots = []string{"05:30", "07:00", "09:30", "12:00", "14:30", "17:00", "19:30"}
+
+var companyNames = []string{
+	"Lanka Express", "Southern Star", "Hill Country Travels", "Ocean Line",
+	"Kandy Comforts", "Ruhuna Motors", "Central Coach", "Blue Lotus Transport",
+}
+
+var firstNames = []string{"Nimal", "Kamala", "Sunil", "Dilani", "Ruwan", "Ishara", "Chaminda", "Tharushi", "Asela", "Nadeesha"}
+var lastNames = []string{"Perera", "Fernando", "Silva", "Jayasinghe", "Bandara", "Wickramasinghe", "Herath", "Rajapaksha"}
+
+var busTypes = []models.BusType{models.BusTypeNormal, models.BusTypeSemiLuxury, models.BusTypeLuxury}
+
+type options struct {
+	owners          int
+	routesPerOwner  int
+	departures      int
+	days            int
+	bookingsPerTrip int
+	passengers      int
+	layoutID        string
+	seed            int64
+}
+
+type seeder struct {
+	opts   options
+	rng    *rand.Rand
+	logger *logrus.Logger
+	db     *database.PostgresDB
+
+	userRepo        *database.UserRepository
+	ownerRepo       *database.BusOwnerRepository
+	permitRepo      *database.RoutePermitRepository
+	busRepo         *database.BusRepository
+	ownerRouteRepo  *database.BusOwnerRouteRepository
+	masterRouteRepo *database.MasterRouteRepository
+	scheduleRepo    *database.TripScheduleRepository
+	tripRepo        *database.ScheduledTripRepository
+	tripSeatRepo    *database.TripSeatRepository
+	seatLayoutRepo  *database.BusSeatLayoutRepository
+	bookingRepo     *database.AppBookingRepository
+	tripGenerator   *services.TripGeneratorService
+
+	stats map[string]int
+}
+
+func main() {
+	var opts options
+	flag.IntVar(&opts.owners, "owners", 3, "number of bus owners to seed")
+	flag.IntVar(&opts.routesPerOwner, "routes-per-owner", 2, "number of routes (permit + bus + owner route) per owner")
+	flag.IntVar(&opts.departures, "departures-per-route", 2, "number of daily timetables per route")
+	flag.IntVar(&opts.days, "days", 7, "number of days ahead to generate and publish trips for")
+	flag.IntVar(&opts.bookingsPerTrip, "bookings-per-trip", 3, "number of sample bookings per published trip")
+	flag.IntVar(&opts.passengers, "passengers", 20, "size of the seeded passenger pool used for bookings")
+	flag.StringVar(&opts.layoutID, "layout-id", "", "seat layout template ID to use (default: first active template)")
+	flag.Int64Var(&opts.seed, "seed", 42, "random seed, keeps generated names and seat picks reproducible")
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	logger.SetOutput(os.Stdout)
+
+	if err := opts.validate(); err != nil {
+		logger.Fatalf("Invalid flags: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if cfg.Server.Environment == "production" {
+		logger.Fatal("Refusing to seed: ENVIRONMENT is production")
+	}
+
+	logger.Info("Connecting to database...")
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		logger.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB, ok := db.(*database.PostgresDB)
+	if !ok {
+		logger.Fatal("Failed to cast database to PostgresDB")
+	}
+
+	piiCipher, err := database.NewPIICipher(cfg.PIIEncryption)
+	if err != nil {
+		logger.Fatalf("Failed to load PII encryption keys: %v", err)
+	}
+
+	s := newSeeder(opts, sqlxDB, piiCipher, logger)
+	if err := s.run(); err != nil {
+		logger.Fatalf("Seeding failed: %v", err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"owners_created":    s.stats["owners"],
+		"permits_created":   s.stats["permits"],
+		"buses_created":     s.stats["buses"],
+		"routes_created":    s.stats["routes"],
+		"schedules_created": s.stats["schedules"],
+		"trips_generated":   s.stats["trips"],
+		"trips_published":   s.stats["published"],
+		"bookings_created":  s.stats["bookings"],
+	}).Info("✅ Staging seed complete")
+}
+
+func (o options) validate() error {
+	if o.owners < 1 || o.owners > 999 {
+		return fmt.Errorf("-owners must be between 1 and 999")
+	}
+	if o.routesPerOwner < 1 || o.routesPerOwner > 99 {
+		return fmt.Errorf("-routes-per-owner must be between 1 and 99")
+	}
+	if o.departures < 1 || o.departures > len(departureSlots) {
+		return fmt.Errorf("-departures-per-route must be between 1 and %d", len(departureSlots))
+	}
+	if o.days < 1 || o.days > 60 {
+		return fmt.Errorf("-days must be between 1 and 60")
+	}
+	if o.bookingsPerTrip < 0 {
+		return fmt.Errorf("-bookings-per-trip cannot be negative")
+	}
+	if o.passengers < 1 || o.passengers > 9999 {
+		return fmt.Errorf("-passengers must be between 1 and 9999")
+	}
+	return nil
+}
+
+func newSeeder(opts options, db *database.PostgresDB, pii *fieldcrypt.Cipher, logger *logrus.Logger) *seeder {
+	scheduleRepo := database.NewTripScheduleRepository(db.DB)
+	tripRepo := database.NewScheduledTripRepository(db.DB)
+	busRepo := database.NewBusRepository(db)
+	seatLayoutRepo := database.NewBusSeatLayoutRepository(db.DB)
+
+	return &seeder{
+		opts:            opts,
+		rng:             rand.New(rand.NewSource(opts.seed)),
+		logger:          logger,
+		db:              db,
+		userRepo:        database.NewUserRepository(db, pii),
+		ownerRepo:       database.NewBusOwnerRepository(db, pii),
+		permitRepo:      database.NewRoutePermitRepository(db),
+		busRepo:         busRepo,
+		ownerRouteRepo:  database.NewBusOwnerRouteRepository(db),
+		masterRouteRepo: database.NewMasterRouteRepository(db.DB),
+		scheduleRepo:    scheduleRepo,
+		tripRepo:        tripRepo,
+		tripSeatRepo:    database.NewTripSeatRepository(db.DB),
+		seatLayoutRepo:  seatLayoutRepo,
+		bookingRepo:     database.NewAppBookingRepository(db.DB),
+		tripGenerator: services.NewTripGeneratorService(
+			scheduleRepo, tripRepo, busRepo, seatLayoutRepo,
+			database.NewSystemSettingRepository(db),
+			database.NewTimetableExceptionRepository(db.DB),
+		),
+		stats: make(map[string]int),
+	}
+}
+
+func (s *seeder) run() error {
+	masterRoutes, err := s.masterRouteRepo.GetAll(true)
+	if err != nil {
+		return fmt.Errorf("failed to load master routes: %w", err)
+	}
+	if len(masterRoutes) == 0 {
+		return fmt.Errorf("no active master routes found; import master routes before seeding")
+	}
+
+	layoutID, err := s.resolveLayoutID()
+	if err != nil {
+		return err
+	}
+
+	passengers, err := s.seedPassengers()
+	if err != nil {
+		return err
+	}
+
+	for o := 1; o <= s.opts.owners; o++ {
+		owner, err := s.seedOwner(o)
+		if err != nil {
+			return fmt.Errorf("owner %d: %w", o, err)
+		}
+
+		for r := 1; r <= s.opts.routesPerOwner; r++ {
+			masterRoute := masterRoutes[((o-1)*s.opts.routesPerOwner+(r-1))%len(masterRoutes)]
+			if err := s.seedRoute(owner, o, r, &masterRoute, layoutID, passengers); err != nil {
+				return fmt.Errorf("owner %d route %d: %w", o, r, err)
+			}
+		}
+
+		s.logger.WithField("bus_owner_id", owner.ID).Infof("Seeded owner %d/%d", o, s.opts.owners)
+	}
+
+	return nil
+}
+
+// resolveLayoutID returns the seat layout template every seeded bus and trip uses
+func (s *seeder) resolveLayoutID() (string, error) {
+	if s.opts.layoutID != "" {
+		id, err := uuid.Parse(s.opts.layoutID)
+		if err != nil {
+			return "", fmt.Errorf("invalid -layout-id: %w", err)
+		}
+		if _, err := s.seatLayoutRepo.GetTemplateByID(context.Background(), id); err != nil {
+			return "", fmt.Errorf("seat layout %s: %w", id, err)
+		}
+		return id.String(), nil
+	}
+
+	templates, err := s.seatLayoutRepo.ListTemplates(context.Background(), true)
+	if err != nil {
+		return "", err
+	}
+	if len(templates) == 0 {
+		return "", fmt.Errorf("no active seat layout templates found; create one in the admin panel or pass -layout-id")
+	}
+	// Templates are listed newest first; the oldest is the most likely to be the stock layout
+	return templates[len(templates)-1].ID.String(), nil
+}
+
+// seedPassengers makes sure the passenger pool exists and returns it
+func (s *seeder) seedPassengers() ([]*models.User, error) {
+	passengers := make([]*models.User, 0, s.opts.passengers)
+	for i := 1; i <= s.opts.passengers; i++ {
+		phone := fmt.Sprintf("+%d", passengerPhoneBase+i)
+		user, created, err := s.userRepo.GetOrCreateUser(phone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seed passenger %s: %w", phone, err)
+		}
+		if created {
+			first, last := s.personName()
+			if err := s.userRepo.UpdateUserNames(user.ID, first, last); err != nil {
+				return nil, fmt.Errorf("failed to name passenger %s: %w", phone, err)
+			}
+			user.FirstName = models.NullString{NullString: sql.NullString{String: first, Valid: true}}
+			user.LastName = models.NullString{NullString: sql.NullString{String: last, Valid: true}}
+		}
+		if !s.userRepo.HasRole(user, "passenger") {
+			if err := s.userRepo.AddUserRole(user.ID, "passenger"); err != nil {
+				return nil, fmt.Errorf("failed to add passenger role for %s: %w", phone, err)
+			}
+		}
+		passengers = append(passengers, user)
+	}
+	return passengers, nil
+}
+
+// seedOwner gets or creates the n-th owner and makes sure it is verified
+func (s *seeder) seedOwner(n int) (*models.BusOwner, error) {
+	phone := fmt.Sprintf("+%d", ownerPhoneBase+n)
+	user, _, err := s.userRepo.GetOrCreateUser(phone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seed owner user %s: %w", phone, err)
+	}
+	if !s.userRepo.HasRole(user, "bus_owner") {
+		if err := s.userRepo.AddUserRole(user.ID, "bus_owner"); err != nil {
+			return nil, fmt.Errorf("failed to add bus_owner role: %w", err)
+		}
+	}
+
+	owner, err := s.ownerRepo.GetByUserID(user.ID.String())
+	if errors.Is(err, sql.ErrNoRows) {
+		company := fmt.Sprintf("%s %s %03d", seedPrefix, companyNames[(n-1)%len(companyNames)], n)
+		email := fmt.Sprintf("owner%03d@staging.smarttransit.lk", n)
+		owner, err = s.ownerRepo.CreateWithCompany(user.ID.String(), company, fmt.Sprintf("%s-PV-%05d", seedPrefix, n), &email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bus owner: %w", err)
+		}
+		s.stats["owners"]++
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get bus owner: %w", err)
+	}
+
+	if owner.VerificationStatus != "verified" {
+		if _, err := s.db.Exec(`
+			UPDATE bus_owners
+			SET verification_status = 'verified', profile_completed = true, updated_at = NOW()
+			WHERE id = $1`, owner.ID); err != nil {
+			return nil, fmt.Errorf("failed to verify bus owner: %w", err)
+		}
+		owner.VerificationStatus = "verified"
+	}
+
+	return owner, nil
+}
+
+// seedRoute builds one permit → bus → owner route → timetables → trips → bookings chain
+func (s *seeder) seedRoute(owner *models.BusOwner, o, r int, masterRoute *models.MasterRoute, layoutID string, passengers []*models.User) error {
+	permit, err := s.seedPermit(owner, o, r, masterRoute)
+	if err != nil {
+		return err
+	}
+
+	if err := s.seedBus(owner, permit, o, r, layoutID); err != nil {
+		return err
+	}
+
+	ownerRoute, err := s.seedOwnerRoute(owner, masterRoute)
+	if err != nil {
+		return err
+	}
+
+	schedules, err := s.seedSchedules(owner, ownerRoute, permit, o, r, masterRoute)
+	if err != nil {
+		return err
+	}
+
+	return s.seedTrips(owner, schedules, permit, ownerRoute, layoutID, passengers)
+}
+
+func (s *seeder) seedPermit(owner *models.BusOwner, o, r int, masterRoute *models.MasterRoute) (*models.RoutePermit, error) {
+	permitNumber := fmt.Sprintf("%s-PERMIT-%03d-%02d", seedPrefix, o, r)
+
+	existing, err := s.permitRepo.GetByPermitNumber(permitNumber, owner.ID)
+	if err == nil {
+		return &existing.RoutePermit, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get permit %s: %w", permitNumber, err)
+	}
+
+	capacity := 54
+	maxTrips := len(departureSlots)
+	now := time.Now()
+	permit := &models.RoutePermit{
+		ID:                      uuid.New().String(),
+		BusOwnerID:              owner.ID,
+		PermitNumber:            permitNumber,
+		BusRegistrationNumber:   licensePlate(o, r),
+		MasterRouteID:           masterRoute.ID,
+		IssueDate:               now.AddDate(-1, 0, 0),
+		ExpiryDate:              now.AddDate(2, 0, 0),
+		PermitType:              "regular",
+		ApprovedFare:            fareForRoute(masterRoute),
+		ApprovedSeatingCapacity: &capacity,
+		MaxTripsPerDay:          &maxTrips,
+		Status:                  models.VerificationVerified,
+		VerifiedAt:              &now,
+	}
+	if err := s.permitRepo.Create(permit); err != nil {
+		return nil, fmt.Errorf("failed to create permit %s: %w", permitNumber, err)
+	}
+	s.stats["permits"]++
+	return permit, nil
+}
+
+func (s *seeder) seedBus(owner *models.BusOwner, permit *models.RoutePermit, o, r int, layoutID string) error {
+	plate := licensePlate(o, r)
+
+	existing, err := s.busRepo.GetByLicensePlate(plate)
+	if err != nil {
+		return fmt.Errorf("failed to get bus %s: %w", plate, err)
+	}
+	if existing != nil {
+		return nil
+	}
+
+	busType := busTypes[s.rng.Intn(len(busTypes))]
+	year := 2012 + s.rng.Intn(12)
+	bus := &models.Bus{
+		ID:                uuid.New().String(),
+		BusOwnerID:        owner.ID,
+		PermitID:          permit.ID,
+		BusNumber:         fmt.Sprintf("%s-%03d-%02d", seedPrefix, o, r),
+		LicensePlate:      plate,
+		BusType:           busType,
+		ManufacturingYear: &year,
+		Status:            models.BusStatusActive,
+		SeatLayoutID:      &layoutID,
+		HasAC:             busType != models.BusTypeNormal,
+		HasWifi:           busType == models.BusTypeLuxury,
+		HasChargingPorts:  busType != models.BusTypeNormal,
+	}
+	if err := s.busRepo.Create(bus); err != nil {
+		return fmt.Errorf("failed to create bus %s: %w", plate, err)
+	}
+	s.stats["buses"]++
+	return nil
+}
+
+func (s *seeder) seedOwnerRoute(owner *models.BusOwner, masterRoute *models.MasterRoute) (*models.BusOwnerRoute, error) {
+	routes, err := s.ownerRouteRepo.GetByMasterRouteID(owner.ID, masterRoute.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner routes: %w", err)
+	}
+	for i := range routes {
+		if routes[i].Direction == "UP" && strings.HasPrefix(routes[i].CustomRouteName, seedPrefix+" ") {
+			return &routes[i], nil
+		}
+	}
+
+	stops, err := s.masterRouteRepo.GetStopsByRouteID(masterRoute.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stops for route %s: %w", masterRoute.RouteNumber, err)
+	}
+	if len(stops) < 2 {
+		return nil, fmt.Errorf("master route %s has fewer than 2 stops", masterRoute.RouteNumber)
+	}
+
+	stopIDs := make(pq.StringArray, 0, len(stops))
+	for _, stop := range stops {
+		stopIDs = append(stopIDs, stop.ID)
+	}
+
+	route := &models.BusOwnerRoute{
+		ID:              uuid.New().String(),
+		BusOwnerID:      owner.ID,
+		MasterRouteID:   masterRoute.ID,
+		CustomRouteName: fmt.Sprintf("%s %s %s - %s", seedPrefix, masterRoute.RouteNumber, masterRoute.OriginCity, masterRoute.DestinationCity),
+		Direction:       "UP",
+		SelectedStopIDs: stopIDs,
+	}
+	if err := s.ownerRouteRepo.Create(route); err != nil {
+		return nil, fmt.Errorf("failed to create owner route: %w", err)
+	}
+	s.stats["routes"]++
+	return route, nil
+}
+
+func (s *seeder) seedSchedules(owner *models.BusOwner, route *models.BusOwnerRoute, permit *models.RoutePermit, o, r int, masterRoute *models.MasterRoute) ([]*models.TripSchedule, error) {
+	existing, err := s.scheduleRepo.GetByBusOwnerID(owner.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+	byName := make(map[string]*models.TripSchedule, len(existing))
+	for i := range existing {
+		if existing[i].ScheduleName != nil {
+			byName[*existing[i].ScheduleName] = &existing[i]
+		}
+	}
+
+	duration := 120
+	if masterRoute.EstimatedDurationMinutes != nil && *masterRoute.EstimatedDurationMinutes > 0 {
+		duration = *masterRoute.EstimatedDurationMinutes
+	}
+
+	schedules := make([]*models.TripSchedule, 0, s.opts.departures)
+	for d := 0; d < s.opts.departures; d++ {
+		departure := departureSlots[(r-1+d*2)%len(departureSlots)]
+		name := fmt.Sprintf("%s %03d-%02d %s", seedPrefix, o, r, departure)
+		if schedule, ok := byName[name]; ok {
+			schedules = append(schedules, schedule)
+			continue
+		}
+
+		routeID := route.ID
+		notes := "Generated by seed-staging"
+		schedule := &models.TripSchedule{
+			BusOwnerID:               owner.ID,
+			BusOwnerRouteID:          &routeID,
+			ScheduleName:             &name,
+			RecurrenceType:           models.RecurrenceDaily,
+			DepartureTime:            departure,
+			EstimatedDurationMinutes: &duration,
+			BaseFare:                 permit.ApprovedFare,
+			IsActive:                 true,
+			ValidFrom:                time.Now().Truncate(24 * time.Hour),
+			Notes:                    &notes,
+		}
+		if err := s.scheduleRepo.CreateTimetable(schedule); err != nil {
+			return nil, fmt.Errorf("failed to create schedule %s: %w", name, err)
+		}
+		s.stats["schedules"]++
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// seedTrips generates trips for the seeding window, gives them seats, publishes them and books a few
+func (s *seeder) seedTrips(owner *models.BusOwner, schedules []*models.TripSchedule, permit *models.RoutePermit, route *models.BusOwnerRoute, layoutID string, passengers []*models.User) error {
+	start := time.Now()
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	end := start.AddDate(0, 0, s.opts.days-1)
+
+	scheduleIDs := make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		generated, err := s.tripGenerator.GenerateTripsForSchedule(schedule, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to generate trips for schedule %s: %w", schedule.ID, err)
+		}
+		s.stats["trips"] += generated
+		scheduleIDs = append(scheduleIDs, schedule.ID)
+	}
+
+	trips, err := s.tripRepo.GetByScheduleIDsAndDateRange(scheduleIDs, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return fmt.Errorf("failed to load generated trips: %w", err)
+	}
+
+	for i := range trips {
+		trip := &trips[i]
+		if trip.Status == models.ScheduledTripStatusCancelled || trip.DepartureDatetime.Before(time.Now()) {
+			continue
+		}
+
+		if trip.PermitID == nil {
+			if err := s.tripRepo.AssignStaffAndPermit(trip.ID, nil, nil, &permit.ID); err != nil {
+				return err
+			}
+		}
+
+		if trip.SeatLayoutID == nil {
+			if err := s.tripRepo.AssignSeatLayout(trip.ID, &layoutID); err != nil {
+				return err
+			}
+		}
+
+		// CreateTripSeatsFromLayout replaces existing seats, so only call it for trips without any
+		seats, err := s.tripSeatRepo.GetByScheduledTripID(trip.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get seats for trip %s: %w", trip.ID, err)
+		}
+		if len(seats) == 0 {
+			if _, err := s.tripSeatRepo.CreateTripSeatsFromLayout(trip.ID, layoutID, trip.BaseFare); err != nil {
+				return fmt.Errorf("failed to create seats for trip %s: %w", trip.ID, err)
+			}
+			if seats, err = s.tripSeatRepo.GetByScheduledTripID(trip.ID); err != nil {
+				return fmt.Errorf("failed to get seats for trip %s: %w", trip.ID, err)
+			}
+		}
+
+		if !trip.IsBookable {
+			if err := s.tripRepo.PublishTrip(trip.ID, owner.ID); err != nil {
+				return fmt.Errorf("failed to publish trip %s: %w", trip.ID, err)
+			}
+			s.stats["published"]++
+		}
+
+		if err := s.seedBookings(trip, seats, route, passengers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedBookings creates sample confirmed bookings on a trip that has none yet
+func (s *seeder) seedBookings(trip *models.ScheduledTrip, seats []models.TripSeat, route *models.BusOwnerRoute, passengers []*models.User) error {
+	if s.opts.bookingsPerTrip == 0 {
+		return nil
+	}
+
+	count, err := s.bookingRepo.CountBookingsByTripID(trip.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count bookings for trip %s: %w", trip.ID, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	available := make([]models.TripSeat, 0, len(seats))
+	for _, seat := range seats {
+		if seat.Status == models.TripSeatStatusAvailable {
+			available = append(available, seat)
+		}
+	}
+	s.rng.Shuffle(len(available), func(i, j int) { available[i], available[j] = available[j], available[i] })
+
+	var boardingStopID, alightingStopID *string
+	if len(route.SelectedStopIDs) >= 2 {
+		boardingStopID = &route.SelectedStopIDs[0]
+		alightingStopID = &route.SelectedStopIDs[len(route.SelectedStopIDs)-1]
+	}
+
+	for b := 0; b < s.opts.bookingsPerTrip && len(available) > 0; b++ {
+		passenger := passengers[s.rng.Intn(len(passengers))]
+		seatCount := 1 + s.rng.Intn(2)
+		if seatCount > len(available) {
+			seatCount = len(available)
+		}
+		picked := available[:seatCount]
+		available = available[seatCount:]
+
+		name := passengerName(passenger)
+		phone := passenger.Phone
+		total := 0.0
+		bookingSeats := make([]models.BusBookingSeat, 0, seatCount)
+		for i, seat := range picked {
+			tripSeatID := seat.ID
+			total += seat.SeatPrice
+			bookingSeats = append(bookingSeats, models.BusBookingSeat{
+				TripSeatID:         &tripSeatID,
+				PassengerName:      name,
+				PassengerPhone:     &phone,
+				IsPrimaryPassenger: i == 0,
+				Status:             models.SeatBookingBooked,
+				SeatNumber:         seat.SeatNumber,
+			})
+		}
+
+		paymentMethod := "cash"
+		notes := "Generated by seed-staging"
+		booking := &models.MasterBooking{
+			UserID:         passenger.ID.String(),
+			BookingType:    models.BookingTypeBusOnly,
+			BusTotal:       total,
+			Subtotal:       total,
+			TotalAmount:    total,
+			PaymentStatus:  models.MasterPaymentCollectOnBus,
+			PaymentMethod:  &paymentMethod,
+			BookingStatus:  models.MasterBookingConfirmed,
+			PassengerName:  name,
+			PassengerPhone: phone,
+			BookingSource:  models.BookingSourceApp,
+			Notes:          &notes,
+		}
+		busBooking := &models.BusBooking{
+			ScheduledTripID: trip.ID,
+			BoardingStopID:  boardingStopID,
+			AlightingStopID: alightingStopID,
+			NumberOfSeats:   seatCount,
+			FarePerSeat:     trip.BaseFare,
+			TotalFare:       total,
+			Status:          models.BusBookingConfirmed,
+		}
+
+		if _, err := s.bookingRepo.CreateBooking(booking, busBooking, bookingSeats, s.tripSeatRepo); err != nil {
+			return fmt.Errorf("failed to create booking on trip %s: %w", trip.ID, err)
+		}
+		s.stats["bookings"]++
+	}
+
+	return nil
+}
+
+func (s *seeder) personName() (string, string) {
+	return firstNames[s.rng.Intn(len(firstNames))], lastNames[s.rng.Intn(len(lastNames))]
+}
+
+func passengerName(user *models.User) string {
+	var parts []string
+	if user.FirstName.Valid && user.FirstName.String != "" {
+		parts = append(parts, user.FirstName.String)
+	}
+	if user.LastName.Valid && user.LastName.String != "" {
+		parts = append(parts, user.LastName.String)
+	}
+	if len(parts) == 0 {
+		return "Staging Passenger"
+	}
+	return strings.Join(parts, " ")
+}
+
+// licensePlate returns the deterministic plate (and permit bus registration) for an owner's route
+func licensePlate(o, r int) string {
+	return fmt.Sprintf("%s-%03d%02d", seedPrefix, o, r)
+}
+
+// fareForRoute approximates a fare from route distance (LKR 2.5/km, minimum LKR 100)
+func fareForRoute(route *models.MasterRoute) float64 {
+	fare := 100.0
+	if route.TotalDistanceKm != nil {
+		if distanceFare := *route.TotalDistanceKm * 2.5; distanceFare > fare {
+			fare = distanceFare
+		}
+	}
+	// Round to the nearest 10 rupees like published fare tables
+	return float64(int(fare/10+0.5) * 10)
+}
@@ -11,13 +11,24 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"github.com/smarttransit/sms-auth-backend/internal/config"
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/handlers"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/realtime"
 	"github.com/smarttransit/sms-auth-backend/internal/services"
+	"github.com/smarttransit/sms-auth-backend/pkg/email"
+	"github.com/smarttransit/sms-auth-backend/pkg/events"
+	"github.com/smarttransit/sms-auth-backend/pkg/geoip"
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
 	"github.com/smarttransit/sms-auth-backend/pkg/jwt"
+	"github.com/smarttransit/sms-auth-backend/pkg/loadshed"
+	"github.com/smarttransit/sms-auth-backend/pkg/messaging"
+	"github.com/smarttransit/sms-auth-backend/pkg/push"
+	"github.com/smarttransit/sms-auth-backend/pkg/routing"
 	"github.com/smarttransit/sms-auth-backend/pkg/sms"
 	"github.com/smarttransit/sms-auth-backend/pkg/validator"
 )
@@ -83,18 +94,61 @@ func main() {
 	)
 	otpService := services.NewOTPService(db)
 	phoneValidator := validator.NewPhoneValidator()
-	rateLimitService := services.NewRateLimitService(db)
-	auditService := services.NewAuditService(db)
-	userRepository := database.NewUserRepository(db)
+	// OTP rate limits are counted in the database, or in Redis so that replicas share them
+	var rateLimitService services.OTPRateLimiter
+	rateLimitConfig := services.NewRateLimitConfig(cfg.OTP)
+	switch cfg.OTP.RateLimitBackend {
+	case "database":
+		rateLimitService = services.NewRateLimitService(db, rateLimitConfig)
+	case "redis":
+		redisOptions, err := redis.ParseURL(cfg.Redis.URL)
+		if err != nil {
+			logger.Fatalf("Invalid REDIS_URL: %v", err)
+		}
+		redisClient := redis.NewClient(redisOptions)
+		defer redisClient.Close()
+		if err := redisClient.Ping(context.Background()).Err(); err != nil {
+			logger.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		rateLimitService = services.NewRedisRateLimiter(redisClient, cfg.Redis.KeyPrefix, rateLimitConfig)
+		logger.Info("🚦 OTP rate limits shared through Redis")
+	default:
+		logger.Fatalf("Invalid OTP_RATE_LIMIT_BACKEND %q: must be database or redis", cfg.OTP.RateLimitBackend)
+	}
+	// IP geolocation is optional; without databases audit logs keep raw IPs only
+	geoLocator, err := geoip.NewLocator(cfg.GeoIP.CityDBPath, cfg.GeoIP.ASNDBPath)
+	if err != nil {
+		logger.Warnf("⚠️  GeoIP disabled: %v", err)
+		geoLocator = nil
+	} else if geoLocator.Enabled() {
+		logger.Info("🌍 GeoIP enrichment enabled for audit logs and sessions")
+	}
+	auditService := services.NewAuditService(db, geoLocator)
+	// PII columns are encrypted at rest once keys are configured; until then they stay in plaintext
+	piiCipher, err := database.NewPIICipher(cfg.PIIEncryption)
+	if err != nil {
+		logger.Fatalf("Failed to load PII encryption keys: %v", err)
+	}
+	if piiCipher == nil {
+		logger.Warn("⚠️  PII encryption disabled: PII_ENCRYPTION_KEYS not set")
+	} else {
+		logger.Infof("🔒 PII encryption enabled (active key %s)", piiCipher.ActiveKeyID())
+	}
+	userRepository := database.NewUserRepository(db, piiCipher)
+	// Requests see role changes within the claims cache TTL instead of when the token expires
+	userClaimsCache := services.NewUserClaimsCache(userRepository, cfg.JWT)
+	if userClaimsCache != nil {
+		jwtService.SetClaimsEnricher(userClaimsCache)
+	}
 	refreshTokenRepository := database.NewRefreshTokenRepository(db)
 	userSessionRepository := database.NewUserSessionRepository(db)
 
 	// Initialize passenger repository
-	passengerRepository := database.NewPassengerRepository(db)
+	passengerRepository := database.NewPassengerRepository(db, piiCipher)
 
 	// Initialize staff-related repositories
-	staffRepository := database.NewBusStaffRepository(db)
-	ownerRepository := database.NewBusOwnerRepository(db)
+	staffRepository := database.NewBusStaffRepository(db, piiCipher)
+	ownerRepository := database.NewBusOwnerRepository(db, piiCipher)
 	permitRepository := database.NewRoutePermitRepository(db)
 	busRepository := database.NewBusRepository(db)
 
@@ -104,13 +158,18 @@ func main() {
 	if !ok {
 		logger.Fatal("Failed to cast database connection to PostgresDB")
 	}
-	loungeOwnerRepository := database.NewLoungeOwnerRepository(sqlxDB.DB)
+	loungeOwnerRepository := database.NewLoungeOwnerRepository(sqlxDB.DB, piiCipher)
 	loungeRepository := database.NewLoungeRepository(sqlxDB.DB)
-	loungeStaffRepository := database.NewLoungeStaffRepository(sqlxDB.DB)
+	loungeStaffRepository := database.NewLoungeStaffRepository(sqlxDB.DB, piiCipher)
 	seatLayoutRepository := database.NewBusSeatLayoutRepository(sqlxDB.DB)
+	tenantRepository := database.NewTenantRepository(sqlxDB.DB)
 
-	// Initialize staff service
-	staffService := services.NewStaffService(staffRepository, ownerRepository, userRepository)
+	// QA test numbers sign in with the deploy's fixed OTP and get no SMS
+	otpTestNumberService := services.NewOTPTestNumberService(database.NewOTPTestNumberRepository(sqlxDB.DB), userRepository, cfg.OTPTestNumbers, logger)
+	otpService.SetTestNumbers(otpTestNumberService)
+	if otpTestNumberService.Enabled() && !otpTestNumberService.Code().Shared {
+		logger.Warn("⚠️  DEPLOY_ID is not set: each instance has its own QA test OTP")
+	}
 
 	// NOTE: Active trip service is initialized after repositories are ready (see below)
 
@@ -119,6 +178,7 @@ func main() {
 	scheduledTripRepo := database.NewScheduledTripRepository(sqlxDB.DB)
 	masterRouteRepo := database.NewMasterRouteRepository(sqlxDB.DB)
 	systemSettingRepo := database.NewSystemSettingRepository(sqlxDB.DB)
+	timetableExceptionRepo := database.NewTimetableExceptionRepository(sqlxDB.DB)
 
 	// Initialize active trip repository (for real-time trip tracking)
 	activeTripRepo := database.NewActiveTripRepository(db)
@@ -130,6 +190,7 @@ func main() {
 		busRepository,
 		seatLayoutRepository,
 		systemSettingRepo,
+		timetableExceptionRepo,
 	)
 
 	// Initialize SMS Gateway (Dialog)
@@ -155,7 +216,7 @@ func main() {
 		// Choose gateway based on method
 		if cfg.SMS.Method == "url" {
 			logger.Info("Using Dialog URL method (GET request with esmsqk)")
-			urlGateway := sms.NewDialogURLGateway(cfg.SMS.ESMSQK, cfg.SMS.Mask, driverAppHash, passengerAppHash)
+			urlGateway := sms.NewDialogURLGateway(cfg.SMS.ESMSQK, cfg.SMS.Mask, driverAppHash, passengerAppHash, cfg.SMS.HTTP)
 			smsGateway = urlGateway
 		} else {
 			logger.Info("Using Dialog API v2 method (POST with authentication)")
@@ -166,11 +227,48 @@ func main() {
 				Mask:             cfg.SMS.Mask,
 				DriverAppHash:    driverAppHash,
 				PassengerAppHash: passengerAppHash,
+				HTTP:             cfg.SMS.HTTP,
 			})
 			smsGateway = apiGateway
 		}
 
 		logger.Info("Dialog SMS Gateway initialized")
+
+		// Fallback provider for sends Dialog fails or cannot take while its breaker is open
+		switch cfg.SMS.Fallback {
+		case "":
+		case "twilio":
+			twilioGateway := sms.NewTwilioGateway(sms.TwilioConfig{
+				AccountSID:       cfg.SMS.Twilio.AccountSID,
+				AuthToken:        cfg.SMS.Twilio.AuthToken,
+				From:             cfg.SMS.Twilio.From,
+				APIURL:           cfg.SMS.Twilio.APIURL,
+				DriverAppHash:    driverAppHash,
+				PassengerAppHash: passengerAppHash,
+				HTTP:             cfg.SMS.Twilio.HTTP,
+			})
+			smsGateway = sms.NewFailoverGateway(func(attempt sms.DeliveryAttempt) {
+				entry := logger.WithFields(logrus.Fields{
+					"provider":    attempt.Provider,
+					"fallback":    attempt.Fallback,
+					"duration_ms": attempt.Duration.Milliseconds(),
+				})
+				if attempt.Phone != "" {
+					entry = entry.WithField("phone_masked", models.MaskPhone(attempt.Phone, 3))
+				}
+				switch {
+				case attempt.Skipped:
+					entry.Warn("SMS provider skipped, circuit breaker open")
+				case attempt.Err != nil:
+					entry.WithError(attempt.Err).Warn("SMS delivery attempt failed")
+				default:
+					entry.Info("SMS delivered")
+				}
+			}, smsGateway, twilioGateway)
+			logger.WithField("gateway", smsGateway.GetName()).Info("📡 SMS failover enabled")
+		default:
+			logger.Fatalf("Invalid SMS_FALLBACK_PROVIDER %q: must be empty or twilio", cfg.SMS.Fallback)
+		}
 	} else {
 		logger.Info("SMS Gateway in development mode (no actual SMS will be sent)")
 		// Still initialize but won't be used in dev mode
@@ -181,7 +279,77 @@ func main() {
 			Mask:             cfg.SMS.Mask,
 			DriverAppHash:    driverAppHash,
 			PassengerAppHash: passengerAppHash,
+			HTTP:             cfg.SMS.HTTP,
+		})
+	}
+
+	// Initialize push notifications (FCM in production, logged in dev mode)
+	var pushSender push.Sender
+	if cfg.Push.Mode == "production" {
+		logger.Info("Initializing FCM push sender in production mode...")
+		pushSender = push.NewFCMSender(push.FCMConfig{
+			ProjectID:   cfg.Push.FCMProjectID,
+			ClientEmail: cfg.Push.FCMClientEmail,
+			PrivateKey:  cfg.Push.FCMPrivateKey,
+			HTTP:        cfg.Push.HTTP,
+		})
+	} else {
+		logger.Info("Push notifications in development mode (notifications are logged, not sent)")
+		pushSender = push.NewLogSender()
+	}
+	// Language, notification channel and seat preferences, consulted before notifying a user
+	userPreferencesService := services.NewUserPreferencesService(database.NewUserPreferencesRepository(db), passengerRepository, logger)
+	userPreferencesHandler := handlers.NewUserPreferencesHandler(userPreferencesService, logger)
+	pushService := services.NewPushNotificationService(pushSender, userSessionRepository, userPreferencesService, logger)
+
+	// Initialize email delivery (SMTP or SES in production, logged in dev mode)
+	var emailSender email.Sender
+	if cfg.Email.Mode == "production" && cfg.Email.Provider == "ses" {
+		logger.Info("Initializing Amazon SES email sender in production mode...")
+		emailSender = email.NewSESSender(email.SESConfig{
+			Region:          cfg.Email.SESRegion,
+			AccessKeyID:     cfg.Email.SESAccessKeyID,
+			SecretAccessKey: cfg.Email.SESSecretAccessKey,
+			Endpoint:        cfg.Email.SESEndpoint,
+			From:            cfg.Email.From,
+			FromName:        cfg.Email.FromName,
+			HTTP:            cfg.Email.SESHTTP,
+		})
+	} else if cfg.Email.Mode == "production" {
+		logger.Info("Initializing SMTP email sender in production mode...")
+		emailSender = email.NewSMTPSender(email.SMTPConfig{
+			Host:     cfg.Email.SMTPHost,
+			Port:     cfg.Email.SMTPPort,
+			Username: cfg.Email.SMTPUsername,
+			Password: cfg.Email.SMTPPassword,
+			From:     cfg.Email.From,
+			FromName: cfg.Email.FromName,
+		})
+	} else {
+		logger.Info("Email in development mode (messages are logged, not sent)")
+		emailSender = email.NewLogSender()
+	}
+
+	// OTP SMS are delivered from a worker queue in production; dev mode never sends
+	smsQueue := services.NewSMSQueueService(smsGateway, cfg.SMS.Queue, logger)
+	// WhatsApp Business API for users who want OTPs and booking confirmations on WhatsApp
+	var whatsAppClient *messaging.WhatsAppClient
+	if cfg.WhatsApp.Enabled {
+		whatsAppClient = messaging.NewWhatsAppClient(messaging.WhatsAppConfig{
+			APIURL:          cfg.WhatsApp.APIURL,
+			PhoneNumberID:   cfg.WhatsApp.PhoneNumberID,
+			AccessToken:     cfg.WhatsApp.AccessToken,
+			OTPTemplate:     cfg.WhatsApp.OTPTemplate,
+			BookingTemplate: cfg.WhatsApp.BookingTemplate,
+			Language:        cfg.WhatsApp.Language,
+			HTTP:            cfg.WhatsApp.HTTP,
 		})
+		smsQueue.SetWhatsAppSender(whatsAppClient)
+		logger.Info("💬 WhatsApp Business API enabled for OTPs and booking confirmations")
+	}
+	if cfg.SMS.Mode == "production" {
+		smsQueue.Start()
+		defer smsQueue.Stop()
 	}
 
 	logger.Info("Services initialized")
@@ -197,35 +365,119 @@ func main() {
 		passengerRepository,
 		refreshTokenRepository,
 		userSessionRepository,
+		tenantRepository,
 		smsGateway,
+		smsQueue,
+		services.NewOTPResendService(cfg.OTP),
+		otpTestNumberService,
+		geoLocator,
+		userClaimsCache,
+		userPreferencesService,
 		cfg,
 	)
 
-	// Initialize staff handler
+	// Initialize staff device login (biometric-bound device credentials)
+	staffDeviceCredentialRepo := database.NewStaffDeviceCredentialRepository(sqlxDB.DB)
+	staffDeviceService := services.NewStaffDeviceAuthService(staffDeviceCredentialRepo, staffRepository, cfg.StaffDevice)
+	staffDeviceHandler := handlers.NewStaffDeviceHandler(
+		staffDeviceService,
+		staffRepository,
+		ownerRepository,
+		userRepository,
+		refreshTokenRepository,
+		jwtService,
+		auditService,
+	)
+
+	// Initialize passenger notifications and trip sharing (emergency contacts get live tracking links)
+	notificationService := services.NewNotificationService(smsGateway, cfg.SMS.Mode, logger)
+	tripSharingRepo := database.NewTripSharingRepository(sqlxDB.DB)
+	tripSharingService := services.NewTripSharingService(tripSharingRepo, passengerRepository, notificationService, cfg.TripSharing, logger)
+	tripSharingHandler := handlers.NewTripSharingHandler(tripSharingService, phoneValidator)
+
+	// Initialize staff service and handler (bus owners approve staff join requests; staff are notified by push or SMS)
+	staffService := services.NewStaffService(staffRepository, ownerRepository, userRepository, pushService, notificationService, userClaimsCache, staffDeviceService)
 	staffHandler := handlers.NewStaffHandler(staffService, userRepository, staffRepository, scheduledTripRepo)
+	// Staff document renewals (staff upload, their bus owner or an admin approves)
+	staffDocumentService := services.NewStaffDocumentService(database.NewStaffDocumentRenewalRepository(sqlxDB.DB, piiCipher), staffRepository, ownerRepository, pushService, logger)
+	staffDocumentHandler := handlers.NewStaffDocumentHandler(staffDocumentService, ownerRepository, logger)
+
+	// Initialize per-trip owner/staff message threads
+	tripMessageRepo := database.NewTripMessageRepository(sqlxDB.DB)
+	tripMessageService := services.NewTripMessageService(tripMessageRepo, pushService)
+	tripMessageHandler := handlers.NewTripMessageHandler(tripMessageService)
+
+	// Initialize scheduled report email subscriptions (owners and admins)
+	reportSubscriptionRepo := database.NewReportSubscriptionRepository(sqlxDB.DB)
+	reportSubscriptionService := services.NewReportSubscriptionService(reportSubscriptionRepo, ownerRepository, emailSender, cfg.Reports, logger)
+	reportSubscriptionHandler := handlers.NewReportSubscriptionHandler(reportSubscriptionService)
+
+	// Initialize white-label tenant management
+	tenantService := services.NewTenantService(tenantRepository, logger)
+	tenantHandler := handlers.NewTenantHandler(tenantService)
 
 	// Initialize active trip service and handler (for Start Trip / End Trip / Location tracking)
 	logger.Info("🚌 Initializing Active Trip tracking system...")
+	tripLocationHistoryRepo := database.NewTripLocationHistoryRepository(sqlxDB.DB)
+	tripLocationRecorder := services.NewTripLocationRecorder(tripLocationHistoryRepo, cfg.LiveTracking, logger)
+	tripLocationRecorder.Start()
+	defer tripLocationRecorder.Stop()
 	activeTripService := services.NewActiveTripService(
 		activeTripRepo,
 		scheduledTripRepo,
 		staffRepository,
 		busRepository,
 		permitRepository,
+		tripSharingService,
+		realtime.NewHub(cfg.LiveTracking.MaxSubscribersPerTrip),
+		tripLocationHistoryRepo,
+		tripLocationRecorder,
 	)
-	activeTripHandler := handlers.NewActiveTripHandler(activeTripService, staffRepository)
+	activeTripHandler := handlers.NewActiveTripHandler(activeTripService, staffRepository, ownerRepository, cfg.LiveTracking)
 	logger.Info("✓ Active Trip tracking system initialized")
 
 	// Initialize bus owner and permit handlers
-	busOwnerHandler := handlers.NewBusOwnerHandler(ownerRepository, permitRepository, userRepository, staffRepository)
+	busOwnerHandler := handlers.NewBusOwnerHandler(ownerRepository, permitRepository, userRepository, staffRepository, staffService, userClaimsCache)
 	permitHandler := handlers.NewPermitHandler(permitRepository, ownerRepository, masterRouteRepo)
-	busHandler := handlers.NewBusHandler(busRepository, permitRepository, ownerRepository)
+	operatorHandler := handlers.NewOperatorHandler(ownerRepository, tripScheduleRepo)
+
+	// Nightly per-schedule on-time performance (shown in search results and operator pages)
+	punctualityService := services.NewPunctualityService(database.NewPunctualityRepository(sqlxDB.DB), cfg.Punctuality, logger)
+	ownerAnalyticsService := services.NewOwnerAnalyticsService(database.NewOwnerAnalyticsRepository(sqlxDB.DB), cfg.OwnerAnalytics, cfg.Punctuality, logger)
+	gtfsService := services.NewGTFSService(database.NewGTFSRepository(sqlxDB.DB), cfg.GTFS, logger)
+	// Seat layouts checked against bus registered and permit approved seating capacity
+	seatCapacityService := services.NewSeatCapacityService(database.NewSeatCapacityRepository(sqlxDB.DB), logger)
+	busHandler := handlers.NewBusHandler(busRepository, permitRepository, ownerRepository, seatCapacityService)
 	masterRouteHandler := handlers.NewMasterRouteHandler(masterRouteRepo)
+	masterRouteImportHandler := handlers.NewMasterRouteImportHandler(
+		services.NewMasterRouteImportService(database.NewMasterRouteImportRepository(sqlxDB.DB), logger), logger)
 
 	// Initialize bus owner route repository and handler
 	busOwnerRouteRepo := database.NewBusOwnerRouteRepository(db)
 	busOwnerRouteHandler := handlers.NewBusOwnerRouteHandler(busOwnerRouteRepo, ownerRepository)
 
+	// Road routing engine for route polylines and stop-to-stop distances/durations
+	var routingEngine routing.Engine
+	switch {
+	case cfg.Routing.Engine == "google" && cfg.Routing.GoogleAPIKey != "":
+		routingEngine = routing.NewGoogleDirectionsClient(routing.GoogleDirectionsConfig{
+			APIKey: cfg.Routing.GoogleAPIKey,
+			HTTP:   cfg.Routing.GoogleHTTP,
+		})
+	case cfg.Routing.Engine == "osrm" && cfg.Routing.OSRMBaseURL != "":
+		routingEngine = routing.NewOSRMClient(routing.OSRMConfig{
+			BaseURL: cfg.Routing.OSRMBaseURL,
+			Profile: cfg.Routing.OSRMProfile,
+			HTTP:    cfg.Routing.HTTP,
+		})
+	default:
+		logger.Infof("Routing engine %q not configured; route polylines can be uploaded but not generated", cfg.Routing.Engine)
+	}
+	routePolylineService := services.NewRoutePolylineService(database.NewRoutePolylineRepository(sqlxDB.DB), masterRouteRepo, busOwnerRouteRepo, routingEngine, logger)
+	routePolylineHandler := handlers.NewRoutePolylineHandler(routePolylineService, ownerRepository, logger)
+	routeSegmentService := services.NewRouteSegmentService(database.NewRouteSegmentRepository(sqlxDB.DB), masterRouteRepo, routingEngine, cfg.Routing, logger)
+	routeSegmentHandler := handlers.NewRouteSegmentHandler(routeSegmentService, logger)
+
 	// Initialize lounge owner, lounge, staff, and admin handlers
 	logger.Info("🔍 DEBUG: Initializing lounge handlers...")
 	loungeOwnerHandler := handlers.NewLoungeOwnerHandler(loungeOwnerRepository, userRepository)
@@ -236,7 +488,23 @@ func main() {
 	// Initialize lounge booking system
 	logger.Info("🏨 Initializing lounge booking system...")
 	loungeBookingRepo := database.NewLoungeBookingRepository(sqlxDB.DB)
-	loungeBookingHandler := handlers.NewLoungeBookingHandler(loungeBookingRepo, loungeRepository, loungeOwnerRepository)
+	bookingIntentRepo := database.NewBookingIntentRepository(sqlxDB.DB) // Lounge capacity holds feed surge pricing
+	loungePricingRepo := database.NewLoungePricingRepository(sqlxDB.DB)
+	loungePricingService := services.NewLoungePricingService(loungePricingRepo, loungeBookingRepo, bookingIntentRepo)
+	// Platform promo codes for bus and lounge bookings
+	promoCodeService := services.NewPromoCodeService(database.NewPromoCodeRepository(sqlxDB.DB), logger)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeService, logger)
+	loungeBookingHandler := handlers.NewLoungeBookingHandler(loungeBookingRepo, loungeRepository, loungeOwnerRepository, loungePricingService, promoCodeService)
+	loungePricingHandler := handlers.NewLoungePricingHandler(loungePricingService, loungeRepository, loungeOwnerRepository)
+	// Lounge discounts when booked with a bus ticket, funded by the lounge owner or the platform
+	loungeBundleDiscountService := services.NewLoungeBundleDiscountService(database.NewLoungeBundleDiscountRepository(sqlxDB.DB), loungeRepository)
+	loungeBundleDiscountHandler := handlers.NewLoungeBundleDiscountHandler(loungeBundleDiscountService, loungeRepository, loungeOwnerRepository)
+	loungeNoShowService := services.NewLoungeNoShowService(database.NewLoungeNoShowRepository(sqlxDB.DB), notificationService, cfg.LoungeNoShow, logger)
+	loungeNoShowHandler := handlers.NewLoungeNoShowHandler(loungeNoShowService, loungeRepository, loungeOwnerRepository)
+	loungeBriefingService := services.NewLoungeGuestBriefingService(database.NewLoungeGuestBriefingRepository(sqlxDB.DB), loungeStaffRepository, pushService, emailSender, cfg.LoungeBriefing, logger)
+	loungeBriefingHandler := handlers.NewLoungeGuestBriefingHandler(loungeBriefingService, loungeRepository, loungeOwnerRepository, loungeStaffRepository)
+	loungeReviewService := services.NewLoungeReviewService(database.NewLoungeReviewRepository(sqlxDB.DB), loungeBookingRepo, loungeRepository)
+	loungeReviewHandler := handlers.NewLoungeReviewHandler(loungeReviewService)
 	logger.Info("✓ Lounge booking system initialized")
 
 	logger.Info("🔍 DEBUG: Lounge handlers initialized successfully")
@@ -253,7 +521,11 @@ func main() {
 		cfg.JWT.AccessTokenExpiry,
 		cfg.JWT.RefreshTokenExpiry,
 	)
-	adminAuthHandler := handlers.NewAdminAuthHandler(adminAuthService, logger)
+	// Admin roles and the permissions they grant, checked per admin endpoint
+	adminPermissionService := services.NewAdminPermissionService(adminUserRepository, database.NewAdminRolePermissionRepository(sqlxDB.DB), cfg.Security.SuperAdminEmails)
+	adminAuthHandler := handlers.NewAdminAuthHandler(adminAuthService, adminPermissionService, auditService, logger)
+	// Buses, permits and routes are soft-deleted; admins list and restore them
+	deletedEntityHandler := handlers.NewDeletedEntityHandler(database.NewSoftDeleteRepository(db), auditService, logger)
 	logger.Info("✓ Admin authentication system initialized")
 
 	// Initialize bus seat layout system
@@ -279,6 +551,15 @@ func main() {
 	manualBookingRepo := database.NewManualBookingRepository(sqlxDB.DB)
 	logger.Info("✓ Trip seat and manual booking repositories initialized")
 
+	// Owner dynamic pricing rules, applied when trip seats are created and re-evaluated by a job
+	seatPricingService := services.NewSeatPricingService(database.NewSeatPricingRuleRepository(sqlxDB.DB), busOwnerRouteRepo, tripScheduleRepo, cfg.SeatPricing, logger)
+	seatPricingHandler := handlers.NewSeatPricingHandler(seatPricingService, ownerRepository, logger)
+
+	// Trip fares vs. route permit approved fares: admin report and optional publish enforcement
+	fareComplianceService := services.NewFareComplianceService(database.NewFareComplianceRepository(sqlxDB.DB), cfg.FareCompliance, logger)
+	fareComplianceHandler := handlers.NewFareComplianceHandler(fareComplianceService, logger)
+	// Trip search results, dropped per corridor when trips are published, unpublished or cancelled
+	searchCache := services.NewSearchCache(cfg.Search)
 	scheduledTripHandler := handlers.NewScheduledTripHandler(
 		scheduledTripRepo,
 		tripScheduleRepo,
@@ -289,17 +570,33 @@ func main() {
 		staffRepository,
 		systemSettingRepo,
 		tripSeatRepo,
+		services.NewDriverFatigueService(scheduledTripRepo, auditService, cfg.DriverFatigue, logger),
+		fareComplianceService,
+		seatCapacityService,
+		searchCache,
+		seatPricingService,
 	)
 	systemSettingHandler := handlers.NewSystemSettingHandler(systemSettingRepo)
+	// Passenger app remote config and version gating, read from system settings
+	appConfigService := services.NewAppConfigService(systemSettingRepo, cfg.App, logger)
+	appConfigHandler := handlers.NewAppConfigHandler(appConfigService, logger)
 	logger.Info("Trip scheduling handlers initialized")
 
+	// Owner surcharges and discounts on trips, applied wherever seats are priced
+	tripPriceAdjustmentService := services.NewTripPriceAdjustmentService(database.NewTripPriceAdjustmentRepository(sqlxDB.DB), logger)
+	tripPriceAdjustmentHandler := handlers.NewTripPriceAdjustmentHandler(tripPriceAdjustmentService, ownerRepository, logger)
+
 	// Initialize search system
 	logger.Info("Initializing search system...")
 	searchRepo := database.NewSearchRepository(db)
-	searchService := services.NewSearchService(searchRepo, logger)
+	searchService := services.NewSearchService(searchRepo, tripPriceAdjustmentService, searchCache, logger)
 	searchHandler := handlers.NewSearchHandler(searchService, logger)
 	logger.Info("✓ Search system initialized")
 
+	// Waitlists of sold-out trips: freed seats are offered in line order for a limited time
+	tripWaitlistService := services.NewTripWaitlistService(database.NewTripWaitlistRepository(sqlxDB.DB), pushService, cfg.Waitlist, logger)
+	tripWaitlistHandler := handlers.NewTripWaitlistHandler(tripWaitlistService, ownerRepository, logger)
+
 	// Initialize Trip Seat Handler (tripSeatRepo already initialized above)
 	tripSeatHandler := handlers.NewTripSeatHandler(
 		tripSeatRepo,
@@ -307,28 +604,102 @@ func main() {
 		scheduledTripRepo,
 		ownerRepository,
 		busOwnerRouteRepo,
+		seatLayoutRepository,
+		tripPriceAdjustmentService,
+		seatPricingService,
+		tripWaitlistService,
 	)
 	logger.Info("✓ Trip seat handler initialized")
 
+	// Initialize boarding reminders
+	bookingReminderRepo := database.NewBookingReminderRepository(sqlxDB.DB)
+	reminderScheduler := services.NewReminderSchedulerService(bookingReminderRepo, notificationService, userPreferencesService, cfg.Reminder, logger)
+
+	// Booking lifecycle events for internal consumers
+	var eventPublisher events.Publisher = events.NopPublisher{}
+	switch cfg.Events.Publisher {
+	case "channel":
+		eventBus := events.NewChannelBus(cfg.Events.BufferSize)
+		eventBus.Subscribe("log", func(e events.Event) {
+			logger.WithFields(logrus.Fields{"event_id": e.ID, "event_type": e.Type}).Debug("Booking event published")
+		})
+		defer eventBus.Close()
+		eventPublisher = eventBus
+	case "none":
+	default:
+		logger.Fatalf("Invalid EVENTS_PUBLISHER %q: must be channel or none", cfg.Events.Publisher)
+	}
+	bookingEventService := services.NewBookingEventService(eventPublisher, logger)
+	logger.WithField("publisher", eventPublisher.GetName()).Info("Booking event bus initialized")
+
 	// Initialize App Booking system (passenger app bookings)
 	logger.Info("Initializing app booking system...")
 	appBookingRepo := database.NewAppBookingRepository(sqlxDB.DB)
+	// Immutable booking snapshots for receipts and dispute evidence
+	bookingSnapshotRepo := database.NewBookingSnapshotRepository(sqlxDB.DB, piiCipher)
+	bookingSnapshotService := services.NewBookingSnapshotService(bookingSnapshotRepo, appBookingRepo, services.DefaultOrchestratorConfig().DefaultCurrency, logger)
+	// Cancellation fee tiers, shared by the cancellation preview and the cancellation itself
+	cancellationPolicy, err := models.ParseCancellationPolicy(cfg.Booking.CancellationFeeTiers)
+	if err != nil {
+		logger.Fatalf("Invalid BOOKING_CANCELLATION_FEE_TIERS: %v", err)
+	}
+	cancellationPolicy.Currency = services.DefaultOrchestratorConfig().DefaultCurrency
+	// booking.confirmed and booking.cancelled are saved to the outbox with the booking change
+	// and delivered from there to their consumers, then forwarded to the event bus
+	outboxDispatcher := services.NewOutboxDispatcher(database.NewOutboxRepository(sqlxDB.DB), eventPublisher, cfg.Events, logger)
+	services.NewBookingEventConsumers(scheduledTripRepo, reminderScheduler, bookingSnapshotService, logger).Register(outboxDispatcher)
+	// Confirmation (with PDF e-ticket), cancellation and receipt emails
+	bookingEmailService := services.NewBookingEmailService(database.NewBookingEmailRepository(sqlxDB.DB), appBookingRepo, passengerRepository, emailSender, services.DefaultOrchestratorConfig().DefaultCurrency, logger)
+	bookingEmailService.Register(outboxDispatcher)
+	tripWaitlistService.Register(outboxDispatcher)
+	if whatsAppClient != nil && cfg.SMS.Mode == "production" {
+		services.NewWhatsAppBookingConfirmations(userRepository, userPreferencesService, appBookingRepo, whatsAppClient, logger).Register(outboxDispatcher)
+	}
+	// Ed25519-signed boarding QRs, verifiable offline by conductor apps
+	bookingQRService := services.NewBookingQRService(database.NewQRSigningKeyRepository(sqlxDB.DB, piiCipher), cfg.BookingQR, logger)
+	if err := bookingQRService.EnsureKey(); err != nil {
+		logger.Fatalf("Failed to load boarding QR signing keys: %v", err)
+	}
+	bookingQRHandler := handlers.NewBookingQRHandler(bookingQRService, auditService, logger)
 	appBookingHandler := handlers.NewAppBookingHandler(
 		appBookingRepo,
 		scheduledTripRepo,
 		tripSeatRepo,
 		busOwnerRouteRepo,
+		reminderScheduler,
+		bookingSnapshotService,
+		bookingEmailService,
+		bookingQRService,
+		cancellationPolicy,
 		logger,
 	)
-	staffBookingHandler := handlers.NewStaffBookingHandler(appBookingRepo)
+	// Trip boarding windows, enforced on staff check-in/boarding
+	tripBoardingWindowService := services.NewTripBoardingWindowService(database.NewTripBoardingWindowRepository(sqlxDB.DB), ownerRepository, auditService, logger)
+	tripBoardingWindowHandler := handlers.NewTripBoardingWindowHandler(tripBoardingWindowService, ownerRepository, logger)
+	// Passenger contact masking in staff booking views, with audited reveal or call relay
+	passengerContactService := services.NewPassengerContactService(database.NewPassengerContactRepository(sqlxDB.DB), auditService, cfg.StaffPrivacy, logger)
+	seatSwapService := services.NewSeatSwapService(database.NewSeatSwapRepository(sqlxDB.DB), logger)
+	tripETAService := services.NewTripETAService(database.NewTripETARepository(sqlxDB.DB), cfg.ETA, logger)
+	tripETAHandler := handlers.NewTripETAHandler(tripETAService, logger)
+	staffBookingHandler := handlers.NewStaffBookingHandler(appBookingRepo, tripBoardingWindowService, passengerContactService, seatSwapService, bookingQRService, tripETAService)
+	// Unreserved standing tickets, capped per trip by the bus's licensed standing capacity
+	standingTicketHandler := handlers.NewStandingTicketHandler(services.NewStandingTicketService(database.NewStandingTicketRepository(sqlxDB.DB), logger), ownerRepository, logger)
+	cashLedgerHandler := handlers.NewCashLedgerHandler(services.NewCashLedgerService(database.NewCashLedgerRepository(sqlxDB.DB), logger), ownerRepository, logger)
+	meHandler := handlers.NewMeHandler(services.NewMeService(userRepository, passengerRepository, staffRepository, ownerRepository, loungeOwnerRepository, loungeStaffRepository, userPreferencesService, logger), logger)
 	logger.Info("✓ App booking system initialized")
 
 	// ============================================================================
 	// BOOKING ORCHESTRATION SYSTEM (Intent → Payment → Confirm)
 	// ============================================================================
 	logger.Info("🎯 Initializing Booking Orchestration system...")
-	bookingIntentRepo := database.NewBookingIntentRepository(sqlxDB.DB)
 	bookingOrchestratorConfig := services.DefaultOrchestratorConfig()
+	bookingOrchestratorConfig.TTLPolicy = services.NewIntentTTLPolicy(cfg.Booking)
+	bookingOrchestratorConfig.HandoffTTL = cfg.Booking.HandoffTTL
+	bookingOrchestratorConfig.HandoffDeepLink = cfg.Booking.HandoffDeepLink
+	bookingOrchestratorConfig.PriceDriftPolicy = cfg.Booking.PriceDriftPolicy
+	bookingOrchestratorConfig.CallCenterHoldTTL = cfg.Booking.CallCenterHoldTTL
+	bookingOrchestratorConfig.HoldExtension = cfg.Booking.HoldExtension
+	bookingOrchestratorConfig.MaxHoldExtensions = cfg.Booking.MaxHoldExtensions
 
 	// Initialize PAYable payment service
 	payableService := services.NewPAYableService(&cfg.Payment, logger)
@@ -341,6 +712,43 @@ func main() {
 	// Initialize payment audit repository for logging all payment events
 	paymentAuditRepo := database.NewPaymentAuditRepository(sqlxDB.DB, logger)
 	logger.Info("✓ Payment audit repository initialized")
+	paymentAuditHandler := handlers.NewPaymentAuditHandler(paymentAuditRepo)
+
+	// Chargebacks: gateway notices, finance workflow and payout adjustment
+	paymentDisputeService := services.NewPaymentDisputeService(database.NewPaymentDisputeRepository(sqlxDB.DB), paymentAuditRepo, bookingSnapshotRepo, logger)
+	paymentDisputeHandler := handlers.NewPaymentDisputeHandler(paymentDisputeService)
+
+	// Passenger wallets: PAYable top-ups, refund credits and payments towards bookings
+	walletService := services.NewWalletService(database.NewWalletRepository(sqlxDB.DB), userRepository, payableService, logger)
+	walletHandler := handlers.NewWalletHandler(walletService, logger)
+
+	// Refunds of cancelled bookings to the card or wallet that paid, approved by admins
+	refundService := services.NewRefundService(database.NewRefundRepository(sqlxDB.DB), appBookingRepo, scheduledTripRepo, tenantRepository, payableService, paymentAuditRepo, walletService, cancellationPolicy, logger)
+	refundHandler := handlers.NewRefundHandler(refundService)
+
+	// Owner bank accounts and payout batches per settlement cycle
+	ownerPayoutService := services.NewOwnerPayoutService(database.NewOwnerPayoutRepository(sqlxDB.DB), paymentDisputeService, cfg.Payout, logger)
+	ownerPayoutHandler := handlers.NewOwnerPayoutHandler(ownerPayoutService, ownerRepository, loungeOwnerRepository)
+
+	// Optional per-trip waiting rooms for high-demand releases
+	tripWaitingRoomRepo := database.NewTripWaitingRoomRepository(sqlxDB.DB)
+	tripWaitingRoomService := services.NewTripWaitingRoomService(tripWaitingRoomRepo, logger)
+	tripWaitingRoomHandler := handlers.NewTripWaitingRoomHandler(tripWaitingRoomService, ownerRepository, logger)
+
+	// Owner blackouts of online sales per route or schedule, with admin override
+	bookingBlackoutService := services.NewBookingBlackoutService(database.NewBookingBlackoutRepository(sqlxDB.DB), busOwnerRouteRepo, tripScheduleRepo, logger)
+	bookingBlackoutHandler := handlers.NewBookingBlackoutHandler(bookingBlackoutService, ownerRepository, logger)
+
+	// Asynchronous admin bulk operations (approvals, exports, notification sends)
+	adminBulkJobService := services.NewAdminBulkJobService(database.NewAdminBulkJobRepository(sqlxDB.DB), loungeRepository, userRepository, pushService, cfg.AdminJobs, logger)
+	adminBulkJobHandler := handlers.NewAdminBulkJobHandler(adminBulkJobService, logger)
+
+	// Origin/destination/hour demand heatmap from searches and bookings
+	tripBookingListHandler := handlers.NewTripBookingListHandler(services.NewTripBookingListService(database.NewTripBookingListRepository(sqlxDB.DB), passengerContactService, logger), ownerRepository, logger)
+	tripDetailsHandler := handlers.NewTripDetailsHandler(services.NewTripDetailsService(database.NewTripDetailsRepository(sqlxDB.DB), loungeRepository, cancellationPolicy, logger), ownerRepository, logger)
+	ownerAnalyticsHandler := handlers.NewOwnerAnalyticsHandler(ownerAnalyticsService, ownerRepository, logger)
+	gtfsHandler := handlers.NewGTFSHandler(gtfsService, logger)
+	demandAnalyticsHandler := handlers.NewDemandAnalyticsHandler(services.NewDemandAnalyticsService(database.NewDemandAnalyticsRepository(sqlxDB.DB), logger), ownerRepository, logger)
 
 	bookingOrchestratorService := services.NewBookingOrchestratorService(
 		bookingIntentRepo,
@@ -349,24 +757,175 @@ func main() {
 		appBookingRepo,
 		loungeBookingRepo,
 		loungeRepository,
+		loungePricingService,
+		loungeBundleDiscountService,
+		promoCodeService,
+		walletService,
 		busOwnerRouteRepo,
+		tenantRepository,
 		payableService,
+		tripWaitingRoomService,
+		tripWaitlistService,
+		bookingBlackoutService,
+		tripPriceAdjustmentService,
+		bookingEventService,
 		bookingOrchestratorConfig,
 		logger,
 	)
+	// Per-trip concurrency limit and load shedding for intent creation, reported by /health
+	intentLimiter := loadshed.New(cfg.Booking.IntentAdmission)
 	bookingOrchestratorHandler := handlers.NewBookingOrchestratorHandler(
 		bookingOrchestratorService,
 		payableService,
 		paymentAuditRepo,
+		database.NewPaymentWebhookEventRepository(sqlxDB.DB),
+		walletService,
+		intentLimiter,
+		logger,
+	)
+	// Call-center bookings taken over the phone, paid from an SMS payment link
+	callCenterBookingHandler := handlers.NewCallCenterBookingHandler(
+		services.NewCallCenterBookingService(database.NewCallCenterBookingRepository(sqlxDB.DB), bookingOrchestratorService, userRepository, notificationService, logger),
+		logger,
+	)
+	// Per-schedule auto-publish of generated trips, with owner dashboard alerts when a trip can't go live
+	tripAutoPublishService := services.NewTripAutoPublishService(
+		database.NewTripAutoPublishRepository(sqlxDB.DB),
+		tripScheduleRepo,
+		scheduledTripRepo,
+		tripSeatRepo,
+		fareComplianceService,
+		seatCapacityService,
+		searchCache,
+		seatPricingService,
+		cfg.TripAutoPublish,
+		logger,
+	)
+	tripAutoPublishHandler := handlers.NewTripAutoPublishHandler(tripAutoPublishService, ownerRepository, logger)
+	// Holiday calendar for trip generation: system holidays and per-schedule exceptions
+	timetableExceptionHandler := handlers.NewTimetableExceptionHandler(
+		services.NewTimetableExceptionService(timetableExceptionRepo, tripScheduleRepo, scheduledTripRepo, logger),
+		ownerRepository,
 		logger,
 	)
 	logger.Info("✓ Booking Orchestration system initialized")
 
 	// Start background job for intent expiration
-	intentExpirationService := services.NewIntentExpirationService(bookingIntentRepo, logger)
+	intentExpirationService := services.NewIntentExpirationService(bookingIntentRepo, bookingEventService, logger)
 	intentExpirationService.Start()
 	defer intentExpirationService.Stop()
 
+	// Start background job settling intents whose payment webhook never arrived
+	paymentReconciliationService := services.NewPaymentReconciliationService(
+		bookingIntentRepo,
+		tenantRepository,
+		paymentAuditRepo,
+		bookingOrchestratorService,
+		payableService,
+		bookingEventService,
+		walletService,
+		cfg.PaymentReconciliation,
+		logger,
+	)
+	paymentReconciliationService.Start()
+	defer paymentReconciliationService.Stop()
+
+	// Start background job admitting waiting room batches
+	tripWaitingRoomService.Start()
+	defer tripWaitingRoomService.Stop()
+
+	// Start background job offering freed seats to trip waitlists
+	tripWaitlistService.Start()
+	defer tripWaitlistService.Stop()
+
+	// Start background job delivering booking events from the outbox
+	outboxDispatcher.Start()
+	defer outboxDispatcher.Stop()
+
+	// Start background job for boarding reminders
+	reminderScheduler.Start()
+	defer reminderScheduler.Stop()
+
+	// Retry notification SMS deferred while the SMS gateway is degraded
+	notificationService.Start()
+	defer notificationService.Stop()
+
+	// Start background job for scheduled report emails
+	reportSubscriptionService.Start()
+	defer reportSubscriptionService.Stop()
+
+	// Start nightly punctuality aggregation
+	punctualityService.Start()
+	defer punctualityService.Stop()
+
+	// Start the bus owner analytics rollup
+	ownerAnalyticsService.Start()
+	defer ownerAnalyticsService.Stop()
+
+	// Regenerate the GTFS static feed of published trips
+	gtfsService.Start()
+	defer gtfsService.Stop()
+
+	// Start background job marking lounge no-shows
+	loungeNoShowService.Start()
+	defer loungeNoShowService.Stop()
+
+	// Start background job sending lounge staff the morning guest list
+	loungeBriefingService.Start()
+	defer loungeBriefingService.Stop()
+
+	// Start background job purging expired OTPs and anonymizing old audit logs
+	retentionService := services.NewRetentionService(database.NewRetentionRepository(sqlxDB.DB), cfg.Retention, logger)
+	retentionService.Start()
+	defer retentionService.Stop()
+
+	// Start background writer for anonymized feature usage events
+	usageAnalyticsService := services.NewUsageAnalyticsService(database.NewUsageAnalyticsRepository(sqlxDB.DB), cfg.UsageAnalytics, logger)
+	usageAnalyticsService.Start()
+	defer usageAnalyticsService.Stop()
+	usageAnalyticsHandler := handlers.NewUsageAnalyticsHandler(usageAnalyticsService, logger)
+	otpTestNumberHandler := handlers.NewOTPTestNumberHandler(otpTestNumberService, auditService, phoneValidator, logger)
+
+	// Start background job publishing trips of auto-publish schedules
+	tripAutoPublishService.Start()
+	defer tripAutoPublishService.Stop()
+
+	// Start background job repricing upcoming trips from owners' pricing rules
+	seatPricingService.Start()
+	defer seatPricingService.Stop()
+
+	// Start background job generating payout batches
+	ownerPayoutService.Start()
+	defer ownerPayoutService.Stop()
+
+	// Start background worker for admin bulk jobs
+	adminBulkJobService.Start()
+	defer adminBulkJobService.Stop()
+
+	// External gateways whose HTTP resilience metrics are reported by /health
+	gatewayStats := []httpclient.StatsProvider{payableService}
+	smsGateways := []sms.SMSGateway{smsGateway}
+	if failover, ok := smsGateway.(*sms.FailoverGateway); ok {
+		smsGateways = failover.Gateways()
+	}
+	for _, gateway := range smsGateways {
+		if provider, ok := gateway.(httpclient.StatsProvider); ok {
+			gatewayStats = append(gatewayStats, provider)
+		}
+	}
+	if whatsAppClient != nil {
+		gatewayStats = append(gatewayStats, whatsAppClient)
+	}
+	if provider, ok := pushSender.(httpclient.StatsProvider); ok {
+		gatewayStats = append(gatewayStats, provider)
+	}
+	if provider, ok := emailSender.(httpclient.StatsProvider); ok {
+		gatewayStats = append(gatewayStats, provider)
+	}
+	if routingEngine != nil {
+		gatewayStats = append(gatewayStats, routingEngine)
+	}
+
 	// Initialize Gin router
 	router := gin.New()
 
@@ -379,14 +938,14 @@ func main() {
 		AllowOrigins:     cfg.CORS.AllowedOrigins,
 		AllowMethods:     cfg.CORS.AllowedMethods,
 		AllowHeaders:     cfg.CORS.AllowedHeaders,
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
 	router.Use(cors.New(corsConfig))
 
 	// Health check endpoint
-	router.GET("/health", healthCheckHandler(db))
+	router.GET("/health", healthCheckHandler(db, gatewayStats, []*loadshed.Limiter{intentLimiter}, retentionService))
 
 	// Set environment in context for development mode
 	router.Use(func(c *gin.Context) {
@@ -394,9 +953,33 @@ func main() {
 		c.Next()
 	})
 
+	// Conditional GET for heavy, frequently polled read endpoints: unchanged payloads return 304
+	conditionalGET := middleware.ETagMiddleware()
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
+	// Resolve the white-label tenant from X-Tenant-Key; requests without it belong to SmartTransit
+	v1.Use(middleware.TenantMiddleware(tenantRepository))
+	// Count each request by route, role and app version for feature usage analytics
+	v1.Use(middleware.UsageMiddleware(usageAnalyticsService))
+	// Reject app versions below the platform minimum; the config stays reachable so old apps can prompt an upgrade
+	v1.Use(middleware.AppVersionMiddleware(appConfigService, "/api/v1/app/config"))
+	// Maintenance mode: read-only API for everyone but admins
+	v1.Use(middleware.MaintenanceMiddleware(appConfigService, jwtService,
+		"/api/v1/search",
+		"/api/v1/auth/send-otp", // Passengers can still sign in to see their bookings
+		"/api/v1/auth/verify-otp",
+		"/api/v1/auth/resend-otp",
+		"/api/v1/auth/refresh-token",
+		"/api/v1/auth/refresh",
+		"/api/v1/admin/auth/login",
+		"/api/v1/admin/auth/refresh",
+		"/api/v1/payments/webhook",
+	))
 	{
+		// Passenger app remote config (public)
+		v1.GET("/app/config", appConfigHandler.GetConfig)
+
 		// Debug endpoint - shows all request headers and IP detection (public)
 		v1.GET("/debug/headers", debugHeadersHandler())
 
@@ -426,6 +1009,9 @@ func main() {
 			auth.POST("/verify-otp-lounge-owner", func(c *gin.Context) {
 				authHandler.VerifyOTPLoungeOwner(c, loungeOwnerRepository)
 			}) // Lounge owner-specific endpoint
+			auth.POST("/resend-otp", authHandler.ResendOTP)
+			auth.POST("/staff-device/challenge", staffDeviceHandler.CreateLoginChallenge) // Staff device login step 1
+			auth.POST("/staff-device/login", staffDeviceHandler.DeviceLogin)              // Staff device login step 2 (signed challenge)
 			auth.GET("/otp-status/:phone", authHandler.GetOTPStatus)
 			auth.POST("/refresh-token", authHandler.RefreshToken)
 			auth.POST("/refresh", authHandler.RefreshToken) // Alias for mobile compatibility
@@ -434,7 +1020,9 @@ func main() {
 			protected := auth.Group("")
 			protected.Use(middleware.AuthMiddleware(jwtService))
 			{
+				protected.POST("/refresh-claims", authHandler.RefreshClaims)
 				protected.POST("/logout", authHandler.Logout)
+				protected.GET("/sessions", authHandler.ListSessions)
 			}
 		}
 
@@ -452,16 +1040,22 @@ func main() {
 
 			// Protected routes (require admin JWT authentication)
 			adminProtected := adminAuth.Group("")
-			adminProtected.Use(middleware.AuthMiddleware(jwtService))
+			adminProtected.Use(middleware.AuthMiddleware(jwtService), middleware.RequireRole("admin"))
 			{
 				logger.Info("  ✅ GET /api/v1/admin/auth/profile")
 				adminProtected.GET("/profile", adminAuthHandler.GetProfile)
 				logger.Info("  ✅ POST /api/v1/admin/auth/change-password")
 				adminProtected.POST("/change-password", adminAuthHandler.ChangePassword)
 				logger.Info("  ✅ POST /api/v1/admin/auth/create")
-				adminProtected.POST("/create", adminAuthHandler.CreateAdmin)
+				adminProtected.POST("/create", middleware.RequireAdminPermission(adminPermissionService, models.AdminPermAdminsManage), adminAuthHandler.CreateAdmin)
 				logger.Info("  ✅ GET /api/v1/admin/auth/list")
-				adminProtected.GET("/list", adminAuthHandler.ListAdmins)
+				adminProtected.GET("/list", middleware.RequireAdminPermission(adminPermissionService, models.AdminPermAdminsView), adminAuthHandler.ListAdmins)
+				logger.Info("  ✅ PUT /api/v1/admin/auth/admins/:id/role")
+				adminProtected.PUT("/admins/:id/role", middleware.RequireAdminPermission(adminPermissionService, models.AdminPermAdminsManage), adminAuthHandler.UpdateAdminRole)
+				logger.Info("  ✅ GET /api/v1/admin/auth/roles")
+				adminProtected.GET("/roles", middleware.RequireAdminPermission(adminPermissionService, models.AdminPermAdminsView), adminAuthHandler.ListRoles)
+				logger.Info("  ✅ PUT /api/v1/admin/auth/roles/:role/permissions")
+				adminProtected.PUT("/roles/:role/permissions", middleware.RequireAdminPermission(adminPermissionService, models.AdminPermAdminsManage), adminAuthHandler.UpdateRolePermissions)
 			}
 		}
 		logger.Info("🔐 Admin Authentication routes registered successfully")
@@ -469,7 +1063,7 @@ func main() {
 		// Bus Seat Layout routes (admin only)
 		logger.Info("🚌 Registering Bus Seat Layout routes...")
 		busSeatLayout := v1.Group("/admin/seat-layouts")
-		busSeatLayout.Use(middleware.AuthMiddleware(jwtService))
+		busSeatLayout.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermOperationsManage))
 		{
 			logger.Info("  ✅ POST /api/v1/admin/seat-layouts")
 			busSeatLayout.POST("", busSeatLayoutHandler.CreateTemplate)
@@ -484,13 +1078,27 @@ func main() {
 		}
 		logger.Info("🚌 Bus Seat Layout routes registered successfully")
 
+		// Who am I: core user, role profiles and onboarding state in one call
+		v1.GET("/me", middleware.AuthMiddleware(jwtService), meHandler.GetMe)
+
 		// User routes (protected)
 		user := v1.Group("/user")
 		user.Use(middleware.AuthMiddleware(jwtService))
 		{
 			user.GET("/profile", authHandler.GetProfile)
 			user.PUT("/profile", authHandler.UpdateProfile)
+			user.PATCH("/profile", authHandler.UpdateOnboardingDetails)            // Optional onboarding steps (email, NIC, emergency contact)
 			user.POST("/complete-basic-profile", authHandler.CompleteBasicProfile) // Simple first_name + last_name for passengers
+
+			// Language, notification channels, marketing opt-in and seat preferences
+			user.GET("/preferences", userPreferencesHandler.GetPreferences)
+			user.PUT("/preferences", userPreferencesHandler.UpdatePreferences)
+
+			// Emergency contacts (trip sharing and incident alerts)
+			user.GET("/emergency-contacts", tripSharingHandler.GetEmergencyContacts)
+			user.POST("/emergency-contacts", tripSharingHandler.AddEmergencyContact)
+			user.PUT("/emergency-contacts/:id", tripSharingHandler.UpdateEmergencyContact)
+			user.DELETE("/emergency-contacts/:id", tripSharingHandler.DeleteEmergencyContact)
 		}
 
 		// Staff routes
@@ -508,6 +1116,16 @@ func main() {
 				staffProtected.GET("/profile", staffHandler.GetProfile)
 				staffProtected.PUT("/profile", staffHandler.UpdateProfile)
 				staffProtected.GET("/my-trips", staffHandler.GetMyTrips)
+				staffProtected.POST("/join-request", staffHandler.RequestToJoinBusOwner) // Ask a bus owner to approve you
+
+				// Document statuses and renewal uploads (reviewed by the bus owner or an admin)
+				staffProtected.GET("/documents", staffDocumentHandler.GetMyDocuments)
+				staffProtected.POST("/documents", staffDocumentHandler.SubmitRenewal)
+
+				// Device credentials for biometric login
+				staffProtected.POST("/devices", staffDeviceHandler.RegisterDevice)
+				staffProtected.GET("/devices", staffDeviceHandler.GetMyDevices)
+				staffProtected.DELETE("/devices/:id", staffDeviceHandler.RevokeMyDevice)
 
 				// Active Trip routes (Start Trip / End Trip / Location tracking)
 				logger.Info("🚌 Registering Active Trip routes...")
@@ -517,19 +1135,46 @@ func main() {
 				staffProtected.POST("/trips/:id/end", activeTripHandler.EndTrip)
 				staffProtected.GET("/trips/:id/active", activeTripHandler.GetActiveTrip)
 				staffProtected.PUT("/trips/:id/passengers", activeTripHandler.UpdatePassengerCount)
+				staffProtected.POST("/trips/:id/incident", activeTripHandler.ReportIncident)
+				staffProtected.POST("/trips/:id/handover", activeTripHandler.HandOverTrip)
 				staffProtected.GET("/trips/:id/bookings", staffBookingHandler.GetTripBookings)
+				staffProtected.POST("/trips/:id/seat-swap", staffBookingHandler.SwapSeat) // Move a passenger to a free seat
+
+				// Standing tickets: sell on the spot and board in queue order
+				staffProtected.GET("/trips/:id/standing-queue", standingTicketHandler.GetQueue)
+				staffProtected.POST("/trips/:id/standing-tickets", standingTicketHandler.IssueTicket)
+				staffProtected.POST("/standing-tickets/validate", standingTicketHandler.ValidateTicket)
+				staffProtected.POST("/standing-tickets/:id/skip", standingTicketHandler.SkipTicket)
+
+				// Onboard cash sales and the end-of-trip cash handover
+				staffProtected.GET("/trips/:id/sales", cashLedgerHandler.GetSales)
+				staffProtected.POST("/trips/:id/sales", cashLedgerHandler.RecordSale)
+				staffProtected.POST("/trips/:id/sales/:sale_id/void", cashLedgerHandler.VoidSale)
+				staffProtected.GET("/trips/:id/settlement", cashLedgerHandler.GetSettlement)
+				staffProtected.POST("/trips/:id/settlement", cashLedgerHandler.SubmitSettlement)
+
+				// Trip message thread with the bus owner (:id is the scheduled trip ID)
+				staffProtected.GET("/trips/:id/messages", tripMessageHandler.GetTripMessages)
+				staffProtected.POST("/trips/:id/messages", tripMessageHandler.SendTripMessage)
+				staffProtected.POST("/trips/:id/messages/read", tripMessageHandler.MarkTripMessagesRead)
 				logger.Info("✓ Active Trip routes registered")
 			}
 		}
 
 		// Bus Owner routes (all protected)
+		busOwnerOnboarding := v1.Group("/bus-owner")
+		busOwnerOnboarding.Use(middleware.AuthMiddleware(jwtService))
+		{
+			// Profile endpoints (no role or verification needed - for registration flow; completing
+			// onboarding grants the bus_owner role)
+			busOwnerOnboarding.GET("/profile", busOwnerHandler.GetProfile)
+			busOwnerOnboarding.GET("/profile-status", busOwnerHandler.CheckProfileStatus)
+			busOwnerOnboarding.GET("/onboarding/progress", busOwnerHandler.GetOnboardingProgress) // Setup checklist for the owner app
+			busOwnerOnboarding.POST("/complete-onboarding", busOwnerHandler.CompleteOnboarding)
+		}
 		busOwner := v1.Group("/bus-owner")
-		busOwner.Use(middleware.AuthMiddleware(jwtService))
+		busOwner.Use(middleware.AuthMiddleware(jwtService), middleware.RequireRole("bus_owner"))
 		{
-			// Profile endpoints (no verification needed - for registration flow)
-			busOwner.GET("/profile", busOwnerHandler.GetProfile)
-			busOwner.GET("/profile-status", busOwnerHandler.CheckProfileStatus)
-			busOwner.POST("/complete-onboarding", busOwnerHandler.CompleteOnboarding)
 			busOwner.GET("/staff", busOwnerHandler.GetStaff) // Get all staff (no verification needed)
 
 			// Staff management (requires verification)
@@ -537,27 +1182,85 @@ func main() {
 			busOwner.POST("/staff/verify", busOwnerHandler.VerifyStaff)                                                      // Verify if staff can be added (no verification needed)
 			busOwner.POST("/staff/link", middleware.RequireVerifiedBusOwner(ownerRepository), busOwnerHandler.LinkStaff)     // Link verified staff to bus owner
 			busOwner.POST("/staff/unlink", middleware.RequireVerifiedBusOwner(ownerRepository), busOwnerHandler.UnlinkStaff) // Remove staff from bus owner
+
+			// Staff join requests (staff who registered and picked this bus owner)
+			busOwner.GET("/staff/requests", busOwnerHandler.GetStaffRequests)
+			busOwner.POST("/staff/requests/:employment_id/approve", middleware.RequireVerifiedBusOwner(ownerRepository), busOwnerHandler.ApproveStaffRequest)
+			busOwner.POST("/staff/requests/:employment_id/reject", middleware.RequireVerifiedBusOwner(ownerRepository), busOwnerHandler.RejectStaffRequest)
+
+			// Staff document renewals (licenses, NIC, medical certificates)
+			busOwner.GET("/staff/documents/pending", staffDocumentHandler.GetOwnerPendingRenewals)
+			busOwner.POST("/staff/documents/:id/approve", middleware.RequireVerifiedBusOwner(ownerRepository), staffDocumentHandler.OwnerApproveRenewal)
+			busOwner.POST("/staff/documents/:id/reject", middleware.RequireVerifiedBusOwner(ownerRepository), staffDocumentHandler.OwnerRejectRenewal)
+
+			// Staff device credentials (biometric login)
+			busOwner.GET("/staff/:staff_id/devices", staffDeviceHandler.GetStaffDevices)
+			busOwner.DELETE("/staff/:staff_id/devices/:id", staffDeviceHandler.RevokeStaffDevice)
+
+			// Scheduled report emails (bookings, revenue, occupancy for the owner's trips)
+			busOwner.GET("/report-subscriptions", reportSubscriptionHandler.GetSubscriptions)
+			busOwner.POST("/report-subscriptions", reportSubscriptionHandler.CreateOwnerSubscription)
+			busOwner.PATCH("/report-subscriptions/:id", reportSubscriptionHandler.UpdateSubscription)
+			busOwner.DELETE("/report-subscriptions/:id", reportSubscriptionHandler.DeleteSubscription)
+
+			// Pause online sales for a route or schedule without cancelling trips
+			busOwner.GET("/booking-blackouts", bookingBlackoutHandler.GetBlackouts)
+			busOwner.POST("/booking-blackouts", middleware.RequireVerifiedBusOwner(ownerRepository), bookingBlackoutHandler.CreateBlackout)
+			busOwner.POST("/booking-blackouts/:id/lift", bookingBlackoutHandler.LiftBlackout)
+
+			// Trips of auto-publish schedules that could not go live
+			busOwner.GET("/auto-publish-alerts", tripAutoPublishHandler.GetAlerts)
+			busOwner.POST("/auto-publish-alerts/:id/dismiss", tripAutoPublishHandler.DismissAlert)
+
+			// Trip surcharges and discounts, capped at the permit's approved fare
+			busOwner.GET("/price-adjustments", tripPriceAdjustmentHandler.GetAdjustments)
+			busOwner.POST("/price-adjustments", middleware.RequireVerifiedBusOwner(ownerRepository), tripPriceAdjustmentHandler.ApplyAdjustment)
+			busOwner.DELETE("/price-adjustments/:id", tripPriceAdjustmentHandler.RevokeAdjustment)
+
+			// Dynamic seat pricing rules per route or schedule
+			busOwner.GET("/pricing-rules", seatPricingHandler.GetRules)
+			busOwner.POST("/pricing-rules", middleware.RequireVerifiedBusOwner(ownerRepository), seatPricingHandler.CreateRule)
+			busOwner.PUT("/pricing-rules/:id", middleware.RequireVerifiedBusOwner(ownerRepository), seatPricingHandler.UpdateRule)
+			busOwner.DELETE("/pricing-rules/:id", seatPricingHandler.DeleteRule)
+
+			// Revenue, occupancy, cancellation and punctuality dashboard
+			busOwner.GET("/analytics", ownerAnalyticsHandler.GetAnalytics)
+
+			// Demand heatmap for corridors on the owner's routes
+			busOwner.GET("/analytics/demand", demandAnalyticsHandler.GetOwnerDemand)
+
+			// Luggage policy shown to passengers in trip details
+			busOwner.GET("/travel-policy", tripDetailsHandler.GetTravelPolicy)
+			busOwner.PUT("/travel-policy", tripDetailsHandler.UpdateTravelPolicy)
+
+			// Crews' end-of-trip cash handovers awaiting reconciliation
+			busOwner.GET("/cash-settlements", cashLedgerHandler.ListSettlements)
 		}
 
 		// Bus Owner Routes (custom route configurations)
 		busOwnerRoutes := v1.Group("/bus-owner-routes")
-		busOwnerRoutes.Use(middleware.AuthMiddleware(jwtService))
+		busOwnerRoutes.Use(middleware.AuthMiddleware(jwtService), middleware.RequireRole("bus_owner"))
 		{
 			// Read endpoints (no verification needed)
 			busOwnerRoutes.GET("", busOwnerRouteHandler.GetRoutes)
 			busOwnerRoutes.GET("/:id", busOwnerRouteHandler.GetRouteByID)
 			busOwnerRoutes.GET("/by-master-route/:master_route_id", busOwnerRouteHandler.GetRoutesByMasterRoute)
+			busOwnerRoutes.GET("/:id/polyline", routePolylineHandler.GetBusOwnerRoutePolyline)
 
 			// Write endpoints (requires verification)
 			busOwnerRoutes.POST("", middleware.RequireVerifiedBusOwner(ownerRepository), busOwnerRouteHandler.CreateRoute)
 			busOwnerRoutes.PUT("/:id", middleware.RequireVerifiedBusOwner(ownerRepository), busOwnerRouteHandler.UpdateRoute)
 			busOwnerRoutes.DELETE("/:id", middleware.RequireVerifiedBusOwner(ownerRepository), busOwnerRouteHandler.DeleteRoute)
+			busOwnerRoutes.PUT("/:id/polyline", middleware.RequireVerifiedBusOwner(ownerRepository), routePolylineHandler.SetBusOwnerRoutePolyline)
+			busOwnerRoutes.DELETE("/:id/polyline", middleware.RequireVerifiedBusOwner(ownerRepository), routePolylineHandler.ClearBusOwnerRoutePolyline)
+			busOwnerRoutes.POST("/:id/polyline/generate", middleware.RequireVerifiedBusOwner(ownerRepository), routePolylineHandler.GenerateBusOwnerRoutePolyline)
 		}
 
 		// Lounge Owner routes (all protected)
 		logger.Info("🏢 Registering Lounge Owner routes...")
 		loungeOwner := v1.Group("/lounge-owner")
-		loungeOwner.Use(middleware.AuthMiddleware(jwtService))
+		// Lounge owner app sign-in grants the lounge_owner role, so it is there from registration on
+		loungeOwner.Use(middleware.AuthMiddleware(jwtService), middleware.RequireRole("lounge_owner"))
 		{
 			// Registration endpoints (no verification needed - for registration flow)
 			logger.Info("  ✅ POST /api/v1/lounge-owner/register/business-info")
@@ -572,6 +1275,10 @@ func main() {
 			// Profile endpoints
 			logger.Info("  ✅ GET /api/v1/lounge-owner/profile")
 			loungeOwner.GET("/profile", loungeOwnerHandler.GetProfile)
+
+			// Analytics across the owner's lounges, or one with lounge_id
+			logger.Info("  ✅ GET /api/v1/lounge-owner/analytics")
+			loungeOwner.GET("/analytics", loungeBookingHandler.GetOwnerAnalytics)
 		}
 		logger.Info("🏢 Lounge Owner routes registered successfully")
 
@@ -581,15 +1288,21 @@ func main() {
 		{
 			// Public routes (no authentication)
 			logger.Info("  ✅ GET /api/v1/lounges/active (public)")
-			lounges.GET("/active", loungeHandler.GetAllActiveLounges)
+			lounges.GET("/active", conditionalGET, loungeHandler.GetAllActiveLounges)
 			logger.Info("  ✅ GET /api/v1/lounges/states (public)")
-			lounges.GET("/states", loungeHandler.GetDistinctStates)
+			lounges.GET("/states", conditionalGET, loungeHandler.GetDistinctStates)
 			logger.Info("  ✅ GET /api/v1/lounges/by-stop/:stopId (public)")
-			lounges.GET("/by-stop/:stopId", loungeHandler.GetLoungesByStop)
+			lounges.GET("/by-stop/:stopId", conditionalGET, loungeHandler.GetLoungesByStop)
 			logger.Info("  ✅ GET /api/v1/lounges/by-route/:routeId (public)")
-			lounges.GET("/by-route/:routeId", loungeHandler.GetLoungesByRoute)
+			lounges.GET("/by-route/:routeId", conditionalGET, loungeHandler.GetLoungesByRoute)
 			logger.Info("  ✅ GET /api/v1/lounges/near-stop/:routeId/:stopId (public)")
-			lounges.GET("/near-stop/:routeId/:stopId", loungeHandler.GetLoungesNearStop)
+			lounges.GET("/near-stop/:routeId/:stopId", conditionalGET, loungeHandler.GetLoungesNearStop)
+			logger.Info("  ✅ GET /api/v1/lounges/:id/price (public)")
+			lounges.GET("/:id/price", loungePricingHandler.GetPriceQuote)
+			logger.Info("  ✅ GET /api/v1/lounges/:id/reviews (public)")
+			lounges.GET("/:id/reviews", loungeReviewHandler.GetLoungeReviews)
+			logger.Info("  ✅ GET /api/v1/lounges/:id/products/:product_id/reviews (public)")
+			lounges.GET("/:id/products/:product_id/reviews", loungeReviewHandler.GetProductReviews)
 
 			// Protected routes (require JWT authentication)
 			loungesProtected := lounges.Group("")
@@ -598,7 +1311,7 @@ func main() {
 				logger.Info("  ✅ GET /api/v1/lounges/my-lounges (read-only, no approval needed)")
 				loungesProtected.GET("/my-lounges", loungeHandler.GetMyLounges)
 				logger.Info("  ✅ GET /api/v1/lounges/:id (read-only, no approval needed)")
-				loungesProtected.GET("/:id", loungeHandler.GetLoungeByID)
+				loungesProtected.GET("/:id", conditionalGET, loungeHandler.GetLoungeByID)
 
 				// Write operations require approval
 				logger.Info("  ✅ PUT /api/v1/lounges/:id (requires approval)")
@@ -638,7 +1351,7 @@ func main() {
 		{
 			// Products for a lounge (anyone can view, owner can manage)
 			logger.Info("  ✅ GET /api/v1/lounges/:id/products (read-only, no approval needed)")
-			loungesProtectedProducts.GET("/:id/products", loungeBookingHandler.GetLoungeProducts)
+			loungesProtectedProducts.GET("/:id/products", conditionalGET, loungeBookingHandler.GetLoungeProducts)
 			logger.Info("  ✅ POST /api/v1/lounges/:id/products (requires approval)")
 			loungesProtectedProducts.POST("/:id/products", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungeBookingHandler.CreateProduct)
 			logger.Info("  ✅ PUT /api/v1/lounges/:id/products/:product_id (requires approval)")
@@ -646,11 +1359,31 @@ func main() {
 			logger.Info("  ✅ DELETE /api/v1/lounges/:id/products/:product_id (requires approval)")
 			loungesProtectedProducts.DELETE("/:id/products/:product_id", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungeBookingHandler.DeleteProduct)
 
+			// Dynamic pricing for a lounge (owner only)
+			logger.Info("  ✅ GET/POST /api/v1/lounges/:id/price-rules, PUT/DELETE /:rule_id (requires approval to change)")
+			loungesProtectedProducts.GET("/:id/price-rules", loungePricingHandler.GetPriceRules)
+			loungesProtectedProducts.POST("/:id/price-rules", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungePricingHandler.CreatePriceRule)
+			loungesProtectedProducts.PUT("/:id/price-rules/:rule_id", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungePricingHandler.UpdatePriceRule)
+			loungesProtectedProducts.DELETE("/:id/price-rules/:rule_id", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungePricingHandler.DeletePriceRule)
+			logger.Info("  ✅ GET/PUT /api/v1/lounges/:id/surge-pricing (requires approval to change)")
+			loungesProtectedProducts.GET("/:id/surge-pricing", loungePricingHandler.GetSurgeSettings)
+			loungesProtectedProducts.PUT("/:id/surge-pricing", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungePricingHandler.UpdateSurgeSettings)
+			logger.Info("  ✅ GET/POST /api/v1/lounges/:id/bundle-discounts, PUT/DELETE /:rule_id (requires approval to change)")
+			loungesProtectedProducts.GET("/:id/bundle-discounts", loungeBundleDiscountHandler.GetLoungeDiscounts)
+			loungesProtectedProducts.POST("/:id/bundle-discounts", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungeBundleDiscountHandler.CreateLoungeDiscount)
+			loungesProtectedProducts.PUT("/:id/bundle-discounts/:rule_id", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungeBundleDiscountHandler.UpdateLoungeDiscount)
+			loungesProtectedProducts.DELETE("/:id/bundle-discounts/:rule_id", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungeBundleDiscountHandler.DeleteLoungeDiscount)
+			logger.Info("  ✅ GET/PUT /api/v1/lounges/:id/no-show-policy (requires approval to change)")
+			loungesProtectedProducts.GET("/:id/no-show-policy", loungeNoShowHandler.GetNoShowPolicy)
+			loungesProtectedProducts.PUT("/:id/no-show-policy", middleware.RequireApprovedLoungeOwner(loungeOwnerRepository), loungeNoShowHandler.UpdateNoShowPolicy)
+
 			// Bookings for a lounge (owner/staff view - read-only, no approval needed)
 			logger.Info("  ✅ GET /api/v1/lounges/:id/bookings (owner/staff, read-only)")
 			loungesProtectedProducts.GET("/:id/bookings", loungeBookingHandler.GetLoungeBookingsForOwner)
 			logger.Info("  ✅ GET /api/v1/lounges/:id/bookings/today (owner/staff, read-only)")
 			loungesProtectedProducts.GET("/:id/bookings/today", loungeBookingHandler.GetTodaysBookings)
+			logger.Info("  ✅ GET /api/v1/lounges/:id/expected-arrivals (owner/staff, read-only)")
+			loungesProtectedProducts.GET("/:id/expected-arrivals", loungeBriefingHandler.GetExpectedArrivals)
 		}
 
 		// Lounge Bookings - Passenger endpoints
@@ -658,7 +1391,7 @@ func main() {
 		loungeBookings.Use(middleware.AuthMiddleware(jwtService))
 		{
 			logger.Info("  ✅ POST /api/v1/lounge-bookings - Create lounge booking")
-			loungeBookings.POST("", loungeBookingHandler.CreateLoungeBooking)
+			loungeBookings.POST("", middleware.RequirePassengerProfile(passengerRepository, models.ProfileFeatureLoungeBooking), loungeBookingHandler.CreateLoungeBooking)
 			logger.Info("  ✅ GET /api/v1/lounge-bookings - Get my lounge bookings")
 			loungeBookings.GET("", loungeBookingHandler.GetMyLoungeBookings)
 			logger.Info("  ✅ GET /api/v1/lounge-bookings/upcoming - Get upcoming bookings")
@@ -679,6 +1412,12 @@ func main() {
 			// Orders for a booking
 			logger.Info("  ✅ GET /api/v1/lounge-bookings/:id/orders - Get booking orders")
 			loungeBookings.GET("/:id/orders", loungeBookingHandler.GetBookingOrders)
+
+			// Guest reviews of the visit and of ordered products
+			logger.Info("  ✅ POST /api/v1/lounge-bookings/:id/review - Review the lounge")
+			loungeBookings.POST("/:id/review", loungeReviewHandler.ReviewLounge)
+			logger.Info("  ✅ POST /api/v1/lounge-bookings/:id/products/:product_id/review - Review an ordered product")
+			loungeBookings.POST("/:id/products/:product_id/review", loungeReviewHandler.ReviewProduct)
 		}
 
 		// Lounge Orders - In-lounge ordering
@@ -724,6 +1463,9 @@ func main() {
 		{
 			masterRoutes.GET("", masterRouteHandler.ListMasterRoutes)
 			masterRoutes.GET("/:id", masterRouteHandler.GetMasterRouteByID)
+			masterRoutes.GET("/:id/polyline", routePolylineHandler.GetMasterRoutePolyline)
+			masterRoutes.GET("/:id/segments", routeSegmentHandler.GetSegments)
+			masterRoutes.GET("/:id/fare-matrix", routeSegmentHandler.GetFareMatrix)
 		}
 
 		// Bus routes (all protected)
@@ -754,6 +1496,16 @@ func main() {
 			tripSchedules.PUT("/:id", middleware.RequireVerifiedBusOwner(ownerRepository), tripScheduleHandler.UpdateSchedule)
 			tripSchedules.DELETE("/:id", middleware.RequireVerifiedBusOwner(ownerRepository), tripScheduleHandler.DeleteSchedule)
 			tripSchedules.POST("/:id/deactivate", middleware.RequireVerifiedBusOwner(ownerRepository), tripScheduleHandler.DeactivateSchedule)
+
+			// Auto-publish of the schedule's generated trips
+			tripSchedules.GET("/:id/auto-publish", tripAutoPublishHandler.GetSettings)
+			tripSchedules.PUT("/:id/auto-publish", middleware.RequireVerifiedBusOwner(ownerRepository), tripAutoPublishHandler.UpdateSettings)
+
+			// Holiday calendar: skip, move or run the schedule's trip on given dates
+			tripSchedules.GET("/:id/exceptions", timetableExceptionHandler.GetScheduleExceptions)
+			tripSchedules.POST("/:id/exceptions", middleware.RequireVerifiedBusOwner(ownerRepository), timetableExceptionHandler.CreateScheduleException)
+			tripSchedules.PUT("/:id/exceptions/:exceptionId", middleware.RequireVerifiedBusOwner(ownerRepository), timetableExceptionHandler.UpdateScheduleException)
+			tripSchedules.DELETE("/:id/exceptions/:exceptionId", middleware.RequireVerifiedBusOwner(ownerRepository), timetableExceptionHandler.DeleteScheduleException)
 		}
 
 		// Timetable routes (new timetable system - all protected)
@@ -795,19 +1547,39 @@ func main() {
 			// NEW: Assign seat layout (requires verification)
 			scheduledTrips.PATCH("/:id/assign-seat-layout", middleware.RequireVerifiedBusOwner(ownerRepository), scheduledTripHandler.AssignSeatLayout)
 
+			// Virtual waiting room for high-demand releases
+			scheduledTrips.GET("/:id/waiting-room", middleware.RequireVerifiedBusOwner(ownerRepository), tripWaitingRoomHandler.GetWaitingRoom)
+			scheduledTrips.PUT("/:id/waiting-room", middleware.RequireVerifiedBusOwner(ownerRepository), tripWaitingRoomHandler.UpdateWaitingRoom)
+
+			// Waitlist of sold-out trips: passengers join and follow their place, owners see its depth
+			scheduledTrips.POST("/:id/waitlist", tripWaitlistHandler.JoinWaitlist)
+			scheduledTrips.GET("/:id/waitlist/me", tripWaitlistHandler.GetMyWaitlistStatus)
+			scheduledTrips.DELETE("/:id/waitlist/me", tripWaitlistHandler.LeaveWaitlist)
+			scheduledTrips.GET("/:id/waitlist", middleware.RequireVerifiedBusOwner(ownerRepository), tripWaitlistHandler.GetWaitlist)
+
+			// Boarding window (check-in/boarding allowed only within it)
+			scheduledTrips.GET("/:id/boarding-window", middleware.RequireVerifiedBusOwner(ownerRepository), tripBoardingWindowHandler.GetBoardingWindow)
+			scheduledTrips.PUT("/:id/boarding-window", middleware.RequireVerifiedBusOwner(ownerRepository), tripBoardingWindowHandler.UpdateBoardingWindow)
+
+			// Standing tickets (cap limited by the bus's licensed standing capacity)
+			scheduledTrips.GET("/:id/standing-tickets", standingTicketHandler.GetTripStanding)
+			scheduledTrips.PUT("/:id/standing-tickets", middleware.RequireVerifiedBusOwner(ownerRepository), standingTicketHandler.UpdateTripStanding)
+
 			// ============================================================================
 			// TRIP SEATS ROUTES (Seat management for scheduled trips)
 			// ============================================================================
 			// Read endpoints (no verification needed)
-			scheduledTrips.GET("/:id/seats", tripSeatHandler.GetTripSeats)
-			scheduledTrips.GET("/:id/seats/summary", tripSeatHandler.GetTripSeatSummary)
-			scheduledTrips.GET("/:id/route-stops", tripSeatHandler.GetTripRouteStops)
+			scheduledTrips.GET("/:id/seats", conditionalGET, tripSeatHandler.GetTripSeats)
+			scheduledTrips.GET("/:id/seats/summary", conditionalGET, tripSeatHandler.GetTripSeatSummary)
+			scheduledTrips.GET("/:id/seats/availability", conditionalGET, tripSeatHandler.GetSeatAvailability) // ?from_stop=&to_stop= stop IDs
+			scheduledTrips.GET("/:id/route-stops", conditionalGET, tripSeatHandler.GetTripRouteStops)
 
 			// Write endpoints (requires verification)
 			scheduledTrips.POST("/:id/seats/create", middleware.RequireVerifiedBusOwner(ownerRepository), tripSeatHandler.CreateTripSeats)
 			scheduledTrips.POST("/:id/seats/block", middleware.RequireVerifiedBusOwner(ownerRepository), tripSeatHandler.BlockSeats)
 			scheduledTrips.POST("/:id/seats/unblock", middleware.RequireVerifiedBusOwner(ownerRepository), tripSeatHandler.UnblockSeats)
 			scheduledTrips.PUT("/:id/seats/price", middleware.RequireVerifiedBusOwner(ownerRepository), tripSeatHandler.UpdateSeatPrices)
+			scheduledTrips.GET("/:id/seats/pricing", seatPricingHandler.PreviewTrip)
 
 			// ============================================================================
 			// MANUAL BOOKINGS ROUTES (Phone/Agent/Walk-in bookings)
@@ -815,8 +1587,25 @@ func main() {
 			// Read endpoints (no verification needed)
 			scheduledTrips.GET("/:id/manual-bookings", tripSeatHandler.GetManualBookings)
 
+			// All bookings on the trip (app, manual, onboard) with revenue subtotals, or as CSV
+			scheduledTrips.GET("/:id/bookings", tripBookingListHandler.GetTripBookings)
+
+			// Printable passenger manifest by boarding stop, for the owner and the trip's crew
+			scheduledTrips.GET("/:id/manifest", tripBookingListHandler.GetTripManifest)
+
+			// Onboard cash sales and the crew's handover, reconciled by the owner
+			scheduledTrips.GET("/:id/cash-ledger", cashLedgerHandler.GetTripLedger)
+			scheduledTrips.POST("/:id/cash-settlement/review", middleware.RequireVerifiedBusOwner(ownerRepository), cashLedgerHandler.ReviewSettlement)
+
 			// Write endpoints (requires verification)
 			scheduledTrips.POST("/:id/manual-bookings", middleware.RequireVerifiedBusOwner(ownerRepository), tripSeatHandler.CreateManualBooking)
+
+			// ============================================================================
+			// TRIP MESSAGES ROUTES (Owner <-> assigned driver/conductor thread)
+			// ============================================================================
+			scheduledTrips.GET("/:id/messages", tripMessageHandler.GetTripMessages)
+			scheduledTrips.POST("/:id/messages", tripMessageHandler.SendTripMessage)
+			scheduledTrips.POST("/:id/messages/read", tripMessageHandler.MarkTripMessagesRead)
 		}
 
 		// Manual Bookings standalone routes (for operations on existing bookings)
@@ -863,11 +1652,36 @@ func main() {
 			appBookings.POST("/:id/confirm-payment", appBookingHandler.ConfirmPayment)
 			logger.Info("  ✅ POST /api/v1/bookings/:id/cancel - Cancel booking")
 			appBookings.POST("/:id/cancel", appBookingHandler.CancelBooking)
+			logger.Info("  ✅ GET /api/v1/bookings/:id/cancellation-preview - Preview cancellation refund")
+			appBookings.GET("/:id/cancellation-preview", appBookingHandler.GetCancellationPreview)
+			logger.Info("  ✅ POST /api/v1/bookings/:id/seats/cancel - Cancel some seats of a booking")
+			appBookings.POST("/:id/seats/cancel", appBookingHandler.CancelSeats)
+			logger.Info("  ✅ POST /api/v1/bookings/:id/refund - Request refund of a cancelled booking")
+			appBookings.POST("/:id/refund", refundHandler.RequestRefund)
+			logger.Info("  ✅ POST /api/v1/bookings/:id/seat-cancellations/:cancellation_id/refund - Request refund of cancelled seats")
+			appBookings.POST("/:id/seat-cancellations/:cancellation_id/refund", refundHandler.RequestSeatRefund)
 			logger.Info("  ✅ GET /api/v1/bookings/:id/qr - Get booking QR code")
 			appBookings.GET("/:id/qr", appBookingHandler.GetBookingQR)
+			logger.Info("  ✅ GET /api/v1/bookings/:id/tickets - Get per-passenger tickets")
+			appBookings.GET("/:id/tickets", appBookingHandler.GetBookingTickets)
+			logger.Info("  ✅ GET /api/v1/bookings/:id/receipt - Get booking receipt")
+			appBookings.GET("/:id/receipt", appBookingHandler.GetBookingReceipt)
+			logger.Info("  ✅ POST /api/v1/bookings/:id/receipt/email - Email booking receipt")
+			appBookings.POST("/:id/receipt/email", appBookingHandler.EmailBookingReceipt)
 		}
 		logger.Info("📱 App Booking routes registered successfully")
 
+		// Standing tickets (unreserved, boarded in queue order, paid to the conductor)
+		standingTickets := v1.Group("/standing-tickets")
+		standingTickets.Use(middleware.AuthMiddleware(jwtService))
+		{
+			standingTickets.GET("/trips/:trip_id", standingTicketHandler.GetAvailability)
+			standingTickets.POST("", standingTicketHandler.ReserveTicket)
+			standingTickets.GET("", standingTicketHandler.GetMyTickets)
+			standingTickets.GET("/:id", standingTicketHandler.GetMyTicket)
+			standingTickets.POST("/:id/cancel", standingTicketHandler.CancelMyTicket)
+		}
+
 		// ============================================================================
 		// ACTIVE TRIP TRACKING ROUTES (Passenger bus tracking)
 		// ============================================================================
@@ -877,9 +1691,27 @@ func main() {
 		{
 			logger.Info("  ✅ GET /api/v1/active-trips/by-scheduled-trip/:scheduled_trip_id - Track bus by scheduled trip ID")
 			activeTrips.GET("/by-scheduled-trip/:scheduled_trip_id", activeTripHandler.GetActiveTripByScheduledTripID)
+			logger.Info("  ✅ GET /api/v1/active-trips/:id/location/stream - Live bus location stream (server-sent events)")
+			activeTrips.GET("/:id/location/stream", activeTripHandler.StreamLocation)
+			logger.Info("  ✅ GET /api/v1/active-trips/:id/location-history - Replay a trip's route (bus owner)")
+			activeTrips.GET("/:id/location-history", activeTripHandler.GetLocationHistory)
+			logger.Info("  ✅ GET /api/v1/active-trips/:id/eta - Arrival estimates at the trip's stops (?stop_id= for one)")
+			activeTrips.GET("/:id/eta", tripETAHandler.GetETA)
 		}
 		logger.Info("🚌 Active Trip Tracking routes registered successfully")
 
+		// ============================================================================
+		// TRIP SHARING ROUTES (Live tracking links for emergency contacts)
+		// ============================================================================
+		tripSharing := v1.Group("/trip-sharing")
+		tripSharing.Use(middleware.AuthMiddleware(jwtService))
+		{
+			tripSharing.POST("", tripSharingHandler.ShareTrip)
+			tripSharing.DELETE("/:scheduled_trip_id", tripSharingHandler.StopSharingTrip)
+		}
+		v1.GET("/track/:token", tripSharingHandler.GetSharedTrip) // Public - the token is the credential
+		logger.Info("  ✅ GET /api/v1/track/:token (public)")
+
 		// ============================================================================
 		// BOOKING ORCHESTRATION ROUTES (Intent → Payment → Confirm)
 		// ============================================================================
@@ -892,15 +1724,36 @@ func main() {
 			logger.Info("  ✅ POST /api/v1/booking/intent - Create booking intent")
 			bookingOrchestration.POST("/intent", bookingOrchestratorHandler.CreateIntent)
 
+			logger.Info("  ✅ POST /api/v1/booking/trips/:trip_id/queue - Join trip waiting room")
+			bookingOrchestration.POST("/trips/:trip_id/queue", tripWaitingRoomHandler.JoinQueue)
+
+			logger.Info("  ✅ GET /api/v1/booking/queue/:token - Poll waiting room position")
+			bookingOrchestration.GET("/queue/:token", tripWaitingRoomHandler.GetQueueStatus)
+
 			logger.Info("  ✅ GET /api/v1/booking/intents - Get my intents")
 			bookingOrchestration.GET("/intents", bookingOrchestratorHandler.GetMyIntents)
 
+			logger.Info("  ✅ GET /api/v1/booking/intent/active - Get my active intent (resume checkout)")
+			bookingOrchestration.GET("/intent/active", bookingOrchestratorHandler.GetActiveIntent)
+
+			logger.Info("  ✅ POST /api/v1/booking/intent/handoff/claim - Resume intent from handoff token")
+			bookingOrchestration.POST("/intent/handoff/claim", bookingOrchestratorHandler.ClaimIntentHandoff)
+
 			logger.Info("  ✅ GET /api/v1/booking/intent/:intent_id - Get intent status")
 			bookingOrchestration.GET("/intent/:intent_id", bookingOrchestratorHandler.GetIntentStatus)
 
 			logger.Info("  ✅ POST /api/v1/booking/intent/:intent_id/initiate-payment - Initiate payment")
 			bookingOrchestration.POST("/intent/:intent_id/initiate-payment", bookingOrchestratorHandler.InitiatePayment)
 
+			logger.Info("  ✅ POST /api/v1/booking/intent/:intent_id/accept-prices - Accept changed seat prices")
+			bookingOrchestration.POST("/intent/:intent_id/accept-prices", bookingOrchestratorHandler.AcceptIntentPrices)
+
+			logger.Info("  ✅ POST /api/v1/booking/intent/:intent_id/handoff - Hand off intent to another device")
+			bookingOrchestration.POST("/intent/:intent_id/handoff", bookingOrchestratorHandler.CreateIntentHandoff)
+
+			logger.Info("  ✅ POST /api/v1/booking/intent/:intent_id/extend - Extend intent hold")
+			bookingOrchestration.POST("/intent/:intent_id/extend", bookingOrchestratorHandler.ExtendIntent)
+
 			logger.Info("  ✅ POST /api/v1/booking/intent/:intent_id/cancel - Cancel intent")
 			bookingOrchestration.POST("/intent/:intent_id/cancel", bookingOrchestratorHandler.CancelIntent)
 
@@ -936,7 +1789,11 @@ func main() {
 			staffBookings.POST("/board", staffBookingHandler.BoardPassenger)
 			logger.Info("  ✅ POST /api/v1/staff/bookings/no-show - Mark no-show")
 			staffBookings.POST("/no-show", staffBookingHandler.MarkNoShow)
+			logger.Info("  ✅ POST /api/v1/staff/bookings/seats/:seat_id/contact - Reveal or relay a passenger's contact")
+			staffBookings.POST("/seats/:seat_id/contact", staffBookingHandler.ContactPassenger)
 		}
+		logger.Info("  ✅ GET /api/v1/staff/qr-keys - Boarding QR verification keys")
+		v1.GET("/staff/qr-keys", middleware.AuthMiddleware(jwtService), bookingQRHandler.GetVerificationKeys)
 		logger.Info("👨‍✈️ Staff Booking routes registered successfully")
 
 		// Permit-specific trip routes
@@ -944,7 +1801,27 @@ func main() {
 		permits.GET("/:id/scheduled-trips", scheduledTripHandler.GetTripsByPermit)
 
 		// Public bookable trips (no auth required)
-		v1.GET("/bookable-trips", scheduledTripHandler.GetBookableTrips)
+		v1.GET("/bookable-trips", conditionalGET, scheduledTripHandler.GetBookableTrips)
+		v1.GET("/bookable-trips/:id/details", conditionalGET, tripDetailsHandler.GetTripDetails)
+		v1.GET("/scheduled-trips/:id/fare-quote", bookingOrchestratorHandler.GetFareQuote) // Non-binding price preview
+
+		// Public operator pages (no auth required - cacheable, used by marketing/SEO site)
+		operators := v1.Group("/operators")
+		{
+			logger.Info("  ✅ GET /api/v1/operators/:id/timetable (public)")
+			operators.GET("/:id/timetable", operatorHandler.GetOperatorTimetable)
+		}
+
+		// GTFS static and realtime feeds for journey planners (public)
+		gtfs := v1.Group("/gtfs")
+		{
+			logger.Info("  ✅ GET /api/v1/gtfs/static (public)")
+			gtfs.GET("/static", conditionalGET, gtfsHandler.GetStaticFeed)
+			logger.Info("  ✅ GET /api/v1/gtfs/realtime[/vehicle-positions|/trip-updates] (public)")
+			gtfs.GET("/realtime", gtfsHandler.GetRealtimeFeed)
+			gtfs.GET("/realtime/vehicle-positions", gtfsHandler.GetVehiclePositions)
+			gtfs.GET("/realtime/trip-updates", gtfsHandler.GetTripUpdates)
+		}
 
 		// ============================================================================
 		// SEARCH ROUTES (Phase 1 MVP - Trip Discovery)
@@ -968,43 +1845,279 @@ func main() {
 		}
 		logger.Info("🔍 Search routes registered successfully")
 
-		// System Settings routes (protected)
+		// System Settings routes (protected; writes are admin-only since settings hold
+		// maintenance mode and the app version policy)
 		systemSettings := v1.Group("/system-settings")
 		systemSettings.Use(middleware.AuthMiddleware(jwtService))
 		{
 			systemSettings.GET("", systemSettingHandler.GetAllSettings)
 			systemSettings.GET("/:key", systemSettingHandler.GetSettingByKey)
-			systemSettings.PUT("/:key", systemSettingHandler.UpdateSetting)
+			systemSettings.PUT("/:key", middleware.RequireAdminPermission(adminPermissionService, models.AdminPermOperationsManage), systemSettingHandler.UpdateSetting)
 		}
 
 		// Admin routes
 		admin := v1.Group("/admin")
-		// TODO: Add admin auth middleware
+		admin.Use(middleware.AuthMiddleware(jwtService), middleware.RequireRole("admin"))
 		{
+			requireApprovals := middleware.RequireAdminPermission(adminPermissionService, models.AdminPermApprovals)
+			requireDashboard := middleware.RequireAdminPermission(adminPermissionService, models.AdminPermDashboardView)
+
 			// Lounge Owner approval (TODO: Implement)
-			admin.GET("/lounge-owners/pending", adminHandler.GetPendingLoungeOwners)
-			admin.GET("/lounge-owners/:id", adminHandler.GetLoungeOwnerDetails)
-			admin.POST("/lounge-owners/:id/approve", adminHandler.ApproveLoungeOwner)
-			admin.POST("/lounge-owners/:id/reject", adminHandler.RejectLoungeOwner)
+			admin.GET("/lounge-owners/pending", requireApprovals, adminHandler.GetPendingLoungeOwners)
+			admin.GET("/lounge-owners/:id", requireApprovals, adminHandler.GetLoungeOwnerDetails)
+			admin.POST("/lounge-owners/:id/approve", requireApprovals, adminHandler.ApproveLoungeOwner)
+			admin.POST("/lounge-owners/:id/reject", requireApprovals, adminHandler.RejectLoungeOwner)
 
 			// Lounge approval (TODO: Implement)
-			admin.GET("/lounges/pending", adminHandler.GetPendingLounges)
-			admin.POST("/lounges/:id/approve", adminHandler.ApproveLounge)
-			admin.POST("/lounges/:id/reject", adminHandler.RejectLounge)
+			admin.GET("/lounges/pending", requireApprovals, adminHandler.GetPendingLounges)
+			admin.POST("/lounges/:id/approve", requireApprovals, adminHandler.ApproveLounge)
+			admin.POST("/lounges/:id/reject", requireApprovals, adminHandler.RejectLounge)
 
 			// Bus Owner approval (TODO: Implement later)
-			admin.GET("/bus-owners/pending", adminHandler.GetPendingBusOwners)
-			admin.POST("/bus-owners/:id/approve", adminHandler.ApproveBusOwner)
+			admin.GET("/bus-owners/pending", requireApprovals, adminHandler.GetPendingBusOwners)
+			admin.POST("/bus-owners/:id/approve", requireApprovals, adminHandler.ApproveBusOwner)
 
 			// Staff approval (TODO: Implement later)
-			admin.GET("/staff/pending", adminHandler.GetPendingStaff)
-			admin.POST("/staff/:id/approve", adminHandler.ApproveStaff)
+			admin.GET("/staff/pending", requireApprovals, adminHandler.GetPendingStaff)
+			admin.POST("/staff/:id/approve", requireApprovals, adminHandler.ApproveStaff)
 
 			// Dashboard stats (TODO: Implement)
-			admin.GET("/dashboard/stats", adminHandler.GetDashboardStats)
+			admin.GET("/dashboard/stats", requireDashboard, adminHandler.GetDashboardStats)
 
 			// Search analytics
-			admin.GET("/search/analytics", searchHandler.GetSearchAnalytics)
+			admin.GET("/search/analytics", requireDashboard, searchHandler.GetSearchAnalytics)
+			admin.GET("/search/cache", requireDashboard, searchHandler.GetCacheStats)
+		}
+
+		// Admin session lookup (support: where is a user logged in from)
+		adminUsers := v1.Group("/admin/users")
+		adminUsers.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermUsersSupport))
+		{
+			adminUsers.GET("/:id/sessions", authHandler.ListUserSessions)
+		}
+
+		// Admin review of staff document renewals
+		adminStaffDocuments := v1.Group("/admin/staff-documents")
+		adminStaffDocuments.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermApprovals))
+		{
+			adminStaffDocuments.GET("", staffDocumentHandler.GetPendingRenewals)
+			adminStaffDocuments.POST("/:id/approve", staffDocumentHandler.AdminApproveRenewal)
+			adminStaffDocuments.POST("/:id/reject", staffDocumentHandler.AdminRejectRenewal)
+		}
+
+		// Admin seat counter repair (recompute cached scheduled_trips seat counters from trip_seats)
+		adminTripSeats := v1.Group("/admin/trip-seats")
+		adminTripSeats.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermOperationsManage))
+		{
+			adminTripSeats.POST("/repair-counters", tripSeatHandler.RepairSeatCounters)
+		}
+
+		// Admin payment audit search/CSV export, chargeback handling and refund approval (finance)
+		adminPayments := v1.Group("/admin/payments")
+		adminPayments.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermPaymentsManage))
+		{
+			adminPayments.GET("/audit", paymentAuditHandler.SearchPaymentAudits)
+
+			adminPayments.GET("/disputes", paymentDisputeHandler.ListDisputes)
+			adminPayments.POST("/disputes", paymentDisputeHandler.CreateDispute)
+			adminPayments.GET("/disputes/:id", paymentDisputeHandler.GetDispute)
+			adminPayments.PUT("/disputes/:id/status", paymentDisputeHandler.UpdateDisputeStatus)
+			adminPayments.POST("/disputes/:id/evidence", paymentDisputeHandler.AddDisputeEvidence)
+			adminPayments.GET("/dispute-settlements/:bus_owner_id", paymentDisputeHandler.GetOwnerSettlement)
+
+			adminPayments.GET("/refunds", refundHandler.ListRefunds)
+			adminPayments.GET("/refunds/:id", refundHandler.GetRefund)
+			adminPayments.POST("/refunds/:id/approve", refundHandler.ApproveRefund)
+			adminPayments.POST("/refunds/:id/reject", refundHandler.RejectRefund)
+			adminPayments.PUT("/refunds/:id/status", refundHandler.ResolveRefund)
+		}
+
+		// Owner payout history and bank account (bus and lounge owners)
+		payouts := v1.Group("/payouts")
+		payouts.Use(middleware.AuthMiddleware(jwtService))
+		{
+			payouts.GET("", ownerPayoutHandler.GetPayouts)
+			payouts.GET("/bank-account", ownerPayoutHandler.GetBankAccount)
+			payouts.PUT("/bank-account", ownerPayoutHandler.UpdateBankAccount)
+		}
+
+		// Admin payout batches, transfer tracking and bank account verification
+		adminPayouts := v1.Group("/admin/payouts")
+		adminPayouts.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermPayoutsManage))
+		{
+			adminPayouts.GET("/bank-accounts", ownerPayoutHandler.ListBankAccounts)
+			adminPayouts.PUT("/bank-accounts/:id/verify", ownerPayoutHandler.VerifyBankAccount)
+			adminPayouts.POST("/batches", ownerPayoutHandler.GenerateBatch)
+			adminPayouts.GET("/batches", ownerPayoutHandler.ListBatches)
+			adminPayouts.GET("/batches/:id", ownerPayoutHandler.GetBatch)
+			adminPayouts.PUT("/:id/status", ownerPayoutHandler.UpdatePayoutStatus)
+		}
+
+		// Admin view and override of owner booking blackouts
+		adminBlackouts := v1.Group("/admin/booking-blackouts")
+		adminBlackouts.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermOperationsManage))
+		{
+			adminBlackouts.GET("", bookingBlackoutHandler.ListBlackouts)
+			adminBlackouts.POST("/:id/override", bookingBlackoutHandler.OverrideBlackout)
+		}
+
+		// Admin system holiday calendar for trip generation
+		adminTimetableExceptions := v1.Group("/admin/timetable-exceptions")
+		adminTimetableExceptions.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermOperationsManage))
+		{
+			adminTimetableExceptions.GET("", timetableExceptionHandler.ListSystemHolidays)
+			adminTimetableExceptions.POST("", timetableExceptionHandler.CreateSystemHoliday)
+			adminTimetableExceptions.PUT("/:id", timetableExceptionHandler.UpdateSystemHoliday)
+			adminTimetableExceptions.DELETE("/:id", timetableExceptionHandler.DeleteSystemHoliday)
+		}
+
+		// Admin platform-funded lounge bundle discounts
+		adminBundleDiscounts := v1.Group("/admin/lounge-bundle-discounts")
+		adminBundleDiscounts.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermPromotionsManage))
+		{
+			adminBundleDiscounts.GET("", loungeBundleDiscountHandler.ListPlatformDiscounts)
+			adminBundleDiscounts.POST("", loungeBundleDiscountHandler.CreatePlatformDiscount)
+			adminBundleDiscounts.PUT("/:rule_id", loungeBundleDiscountHandler.UpdatePlatformDiscount)
+			adminBundleDiscounts.DELETE("/:rule_id", loungeBundleDiscountHandler.DeletePlatformDiscount)
+		}
+
+		// Admin promo codes for bus and lounge bookings
+		adminPromoCodes := v1.Group("/admin/promo-codes")
+		adminPromoCodes.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermPromotionsManage))
+		{
+			adminPromoCodes.GET("", promoCodeHandler.ListCodes)
+			adminPromoCodes.POST("", promoCodeHandler.CreateCode)
+			adminPromoCodes.GET("/:id", promoCodeHandler.GetCode)
+			adminPromoCodes.PUT("/:id", promoCodeHandler.UpdateCode)
+			adminPromoCodes.DELETE("/:id", promoCodeHandler.DeleteCode)
+		}
+
+		// Passengers check what a promo code takes off before booking
+		promoCodes := v1.Group("/promo-codes")
+		promoCodes.Use(middleware.AuthMiddleware(jwtService))
+		{
+			promoCodes.POST("/validate", promoCodeHandler.ValidateCode)
+		}
+
+		// Passenger wallet balance, ledger and top-ups
+		wallet := v1.Group("/wallet")
+		wallet.Use(middleware.AuthMiddleware(jwtService))
+		{
+			wallet.GET("", walletHandler.GetWallet)
+			wallet.GET("/top-ups", walletHandler.ListTopUps)
+			wallet.POST("/top-ups", walletHandler.TopUp)
+		}
+
+		// Admin fare compliance: trips priced above their route permit's approved fare
+		adminFareCompliance := v1.Group("/admin/fare-compliance")
+		adminFareCompliance.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermOperationsManage))
+		{
+			adminFareCompliance.GET("", fareComplianceHandler.GetReport)
+		}
+
+		// Admin analytics: demand heatmap for planners (underserved corridors) and feature usage for the product team
+		adminAnalytics := v1.Group("/admin/analytics")
+		adminAnalytics.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermDashboardView))
+		{
+			adminAnalytics.GET("/demand", demandAnalyticsHandler.GetPlatformDemand)
+			adminAnalytics.GET("/usage", usageAnalyticsHandler.GetUsage)
+			adminAnalytics.GET("/usage/retention", usageAnalyticsHandler.GetRetention)
+		}
+
+		// Admin call-center bookings: hold seats for a caller and text them the payment link;
+		// the payment webhook confirms the booking when they pay
+		adminCallCenter := v1.Group("/admin/call-center/bookings")
+		adminCallCenter.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermCallCenter))
+		{
+			adminCallCenter.POST("", callCenterBookingHandler.CreateBooking)
+			adminCallCenter.GET("", callCenterBookingHandler.ListBookings)
+			adminCallCenter.GET("/:id", callCenterBookingHandler.GetBooking)
+			adminCallCenter.POST("/:id/resend-link", callCenterBookingHandler.ResendPaymentLink)
+		}
+
+		// Admin bulk jobs: submit, poll progress, download results
+		adminBulkJobs := v1.Group("/admin/bulk-jobs")
+		adminBulkJobs.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermBulkJobs))
+		{
+			adminBulkJobs.POST("", adminBulkJobHandler.CreateJob)
+			adminBulkJobs.GET("", adminBulkJobHandler.ListJobs)
+			adminBulkJobs.GET("/:id", adminBulkJobHandler.GetJob)
+			adminBulkJobs.GET("/:id/results", adminBulkJobHandler.DownloadResults)
+		}
+
+		// QA test numbers: super admins only
+		adminOTPTestNumbers := v1.Group("/admin/otp-test-numbers")
+		adminOTPTestNumbers.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermSecurityManage))
+		{
+			adminOTPTestNumbers.GET("", otpTestNumberHandler.ListNumbers)
+			adminOTPTestNumbers.POST("", otpTestNumberHandler.AddNumber)
+			adminOTPTestNumbers.GET("/code", otpTestNumberHandler.GetCode)
+			adminOTPTestNumbers.DELETE("/:id", otpTestNumberHandler.RemoveNumber)
+		}
+
+		// Boarding QR signing key rotation: super admins only
+		adminQRKeys := v1.Group("/admin/qr-keys")
+		adminQRKeys.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermSecurityManage))
+		{
+			adminQRKeys.GET("", bookingQRHandler.GetVerificationKeys)
+			adminQRKeys.POST("/rotate", bookingQRHandler.RotateKey)
+		}
+
+		// Admin view and restore of soft-deleted buses, permits and routes
+		adminDeletedEntities := v1.Group("/admin/deleted-entities")
+		adminDeletedEntities.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermOperationsManage))
+		{
+			adminDeletedEntities.GET("", deletedEntityHandler.ListDeleted)
+			adminDeletedEntities.POST("/:type/:id/restore", deletedEntityHandler.Restore)
+		}
+
+		// Admin maintenance mode switch
+		adminMaintenance := v1.Group("/admin/maintenance")
+		adminMaintenance.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermOperationsManage))
+		{
+			adminMaintenance.GET("", appConfigHandler.GetMaintenance)
+			adminMaintenance.PUT("", appConfigHandler.UpdateMaintenance)
+		}
+
+		// Admin route map polylines and routing engine estimates
+		adminMasterRoutes := v1.Group("/admin/master-routes")
+		adminMasterRoutes.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermOperationsManage))
+		{
+			adminMasterRoutes.POST("/import", masterRouteImportHandler.ImportMasterRoutes)
+			adminMasterRoutes.PUT("/:id/polyline", routePolylineHandler.SetMasterRoutePolyline)
+			adminMasterRoutes.POST("/:id/polyline/generate", routePolylineHandler.GenerateMasterRoutePolyline)
+			adminMasterRoutes.POST("/:id/segments/compute", routeSegmentHandler.ComputeSegments)
+		}
+
+		// Admin scheduled report emails (platform-wide)
+		adminReports := v1.Group("/admin/report-subscriptions")
+		adminReports.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermReportsManage))
+		{
+			adminReports.GET("", reportSubscriptionHandler.GetSubscriptions)
+			adminReports.POST("", reportSubscriptionHandler.CreateAdminSubscription)
+			adminReports.PATCH("/:id", reportSubscriptionHandler.UpdateSubscription)
+			adminReports.DELETE("/:id", reportSubscriptionHandler.DeleteSubscription)
+		}
+
+		// Report unsubscribe links (public - the token is the credential)
+		v1.GET("/reports/unsubscribe/:token", reportSubscriptionHandler.Unsubscribe)
+		v1.POST("/reports/unsubscribe/:token", reportSubscriptionHandler.Unsubscribe)
+
+		// Tenant app configuration (public - branding for the app's X-Tenant-Key)
+		v1.GET("/tenant/config", tenantHandler.GetConfig)
+
+		// Admin white-label tenant management
+		adminTenants := v1.Group("/admin/tenants")
+		adminTenants.Use(middleware.AuthMiddleware(jwtService), middleware.RequireAdminPermission(adminPermissionService, models.AdminPermTenantsManage))
+		{
+			adminTenants.GET("", tenantHandler.ListTenants)
+			adminTenants.POST("", tenantHandler.CreateTenant)
+			adminTenants.GET("/:id", tenantHandler.GetTenant)
+			adminTenants.PATCH("/:id", tenantHandler.UpdateTenant)
+			adminTenants.GET("/:id/api-keys", tenantHandler.ListAPIKeys)
+			adminTenants.POST("/:id/api-keys", tenantHandler.CreateAPIKey)
+			adminTenants.DELETE("/:id/api-keys/:key_id", tenantHandler.RevokeAPIKey)
+			adminTenants.PUT("/bus-owners/:owner_id", tenantHandler.AssignBusOwner)
 		}
 	}
 
@@ -1124,7 +2237,8 @@ func requestLogger(logger *logrus.Logger) gin.HandlerFunc {
 }
 
 // healthCheckHandler returns a health check endpoint
-func healthCheckHandler(db database.DB) gin.HandlerFunc {
+// External gateways with an open circuit breaker, or a limiter shedding load, report the service as "degraded"
+func healthCheckHandler(db database.DB, gateways []httpclient.StatsProvider, limiters []*loadshed.Limiter, retention *services.RetentionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check database connection
 		dbStatus := "healthy"
@@ -1138,11 +2252,32 @@ func healthCheckHandler(db database.DB) gin.HandlerFunc {
 			return
 		}
 
+		status := "healthy"
+		gatewayStats := make([]httpclient.Stats, 0, len(gateways))
+		for _, gateway := range gateways {
+			stats := gateway.HTTPStats()
+			if stats.State == httpclient.StateOpen {
+				status = "degraded"
+			}
+			gatewayStats = append(gatewayStats, stats)
+		}
+		limiterStats := make([]loadshed.Stats, 0, len(limiters))
+		for _, limiter := range limiters {
+			stats := limiter.Stats()
+			if stats.Shedding {
+				status = "degraded"
+			}
+			limiterStats = append(limiterStats, stats)
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"status":    "healthy",
-			"database":  dbStatus,
-			"version":   version,
-			"timestamp": time.Now().Unix(),
+			"status":        status,
+			"database":      dbStatus,
+			"gateways":      gatewayStats,
+			"load_shedding": limiterStats,
+			"retention":     retention.Stats(),
+			"version":       version,
+			"timestamp":     time.Now().Unix(),
 		})
 	}
 }
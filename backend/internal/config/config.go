@@ -5,9 +5,12 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+	"github.com/smarttransit/sms-auth-backend/pkg/loadshed"
 )
 
 // Config holds all configuration for the application
@@ -27,6 +30,9 @@ type Config struct {
 	// OTP configuration
 	OTP OTPConfig
 
+	// WhatsApp Business API for OTPs and booking confirmations
+	WhatsApp WhatsAppConfig
+
 	// Rate limiting configuration
 	RateLimit RateLimitConfig
 
@@ -36,8 +42,443 @@ type Config struct {
 	// Security configuration
 	Security SecurityConfig
 
+	// Encryption of personal data columns (NIC, address, license numbers)
+	PIIEncryption PIIEncryptionConfig
+
 	// Payment gateway configuration
 	Payment PaymentConfig
+
+	// Booking hold configuration
+	Booking BookingConfig
+
+	// Passenger reminder configuration
+	Reminder ReminderConfig
+
+	// Staff device login configuration
+	StaffDevice StaffDeviceConfig
+
+	// Passenger contact masking in staff views
+	StaffPrivacy StaffPrivacyConfig
+
+	// Signed boarding QRs, verifiable offline by conductor apps
+	BookingQR BookingQRConfig
+
+	// Driver hours-of-service rules checked on trip assignment
+	DriverFatigue DriverFatigueConfig
+
+	// Trip fares checked against route permit approved fares
+	FareCompliance FareComplianceConfig
+
+	// Automatic publishing of generated trips for schedules that opt in
+	TripAutoPublish TripAutoPublishConfig
+
+	// Dynamic seat pricing rules re-evaluated on upcoming trips
+	SeatPricing SeatPricingConfig
+
+	// Passenger trip-sharing and emergency contact configuration
+	TripSharing TripSharingConfig
+
+	// Live bus location streams for passenger apps
+	LiveTracking LiveTrackingConfig
+
+	// Arrival estimates at the stops of active trips
+	ETA ETAConfig
+
+	// Waitlists of sold-out trips
+	Waitlist WaitlistConfig
+
+	// Mobile push notification configuration
+	Push PushConfig
+
+	// Outgoing email configuration
+	Email EmailConfig
+
+	// Scheduled report email subscriptions
+	Reports ReportConfig
+
+	// Nightly on-time performance aggregation
+	Punctuality    PunctualityConfig
+	OwnerAnalytics OwnerAnalyticsConfig
+
+	// Lounge no-show auto-marking
+	LoungeNoShow LoungeNoShowConfig
+
+	// Public GTFS static feed of published trips
+	GTFS GTFSConfig
+
+	// Morning guest list sent to lounge staff
+	LoungeBriefing LoungeBriefingConfig
+
+	// Purging of expired OTPs and anonymization of old audit logs
+	Retention RetentionConfig
+
+	// Gateway status checks for intents whose payment webhook never arrived
+	PaymentReconciliation PaymentReconciliationConfig
+
+	// Anonymized feature usage events for product analytics
+	UsageAnalytics UsageAnalyticsConfig
+
+	// Internal QA phone numbers that sign in with a fixed per-deploy OTP
+	OTPTestNumbers OTPTestNumbersConfig
+
+	// Internal booking lifecycle event bus
+	Events EventsConfig
+
+	// Shared Redis, used by the OTP rate limiter when OTP_RATE_LIMIT_BACKEND=redis
+	Redis RedisConfig
+
+	// Trip search result caching
+	Search SearchConfig
+
+	// IP geolocation of logins and OTP requests
+	GeoIP GeoIPConfig
+
+	// Owner payouts
+	Payout PayoutConfig
+
+	// Asynchronous admin bulk operations
+	AdminJobs AdminJobsConfig
+
+	// Road routing engine for route polylines, segment distances and durations
+	Routing RoutingConfig
+
+	// Passenger app version gating and remote config
+	App AppConfig
+}
+
+// EmailConfig holds outgoing email (SMTP or Amazon SES) configuration
+type EmailConfig struct {
+	Mode         string // "dev" logs messages, "production" sends them through Provider
+	Provider     string // "smtp" or "ses"
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string // Sender address
+	FromName     string // Display name shown to recipients
+
+	// Amazon SES (EMAIL_PROVIDER=ses); the sender address must be verified in SES
+	SESRegion          string
+	SESAccessKeyID     string
+	SESSecretAccessKey string
+	SESEndpoint        string // Overrides https://email.{region}.amazonaws.com
+	SESHTTP            httpclient.Config
+}
+
+// ReportConfig holds settings for scheduled report email subscriptions
+type ReportConfig struct {
+	Enabled          bool
+	CheckInterval    time.Duration // How often the job looks for due subscriptions
+	SendHour         int           // Local hour (Asia/Colombo) reports are delivered at
+	UnsubscribeURL   string        // Public unsubscribe endpoint; the subscription token is appended
+	MaxAttempts      int           // Consecutive failures before a delivery is skipped until the next period
+	MaxSubscriptions int           // Active subscriptions per user
+	MaxCSVRows       int           // Row cap for CSV reports
+}
+
+// PunctualityConfig holds settings for the nightly schedule on-time performance job
+type PunctualityConfig struct {
+	Enabled               bool
+	RunHour               int // Local hour (Asia/Colombo) the aggregation runs at
+	WindowDays            int // Days of completed trips each schedule is measured over
+	DepartureGraceMinutes int // Minutes late a departure can be and still count as on time
+	ArrivalGraceMinutes   int // Minutes after the scheduled arrival that still count as on time
+	MinTrips              int // Trips a schedule needs before its percentage is shown
+}
+
+// OwnerAnalyticsConfig holds settings for the job that rolls trips up into the daily stats
+// bus owner analytics read. On-time rules are the punctuality job's.
+type OwnerAnalyticsConfig struct {
+	Enabled       bool
+	Interval      time.Duration // How often recent days are rolled up again
+	RecomputeDays int           // Local days, up to today, each run rolls up; late bookings and cancellations change them
+	BackfillDays  int           // Local days the first run after startup rolls up
+}
+
+// GTFSConfig holds settings for the GTFS static feed journey planners download. The feed's
+// single agency is the platform, since passengers book every trip through it.
+type GTFSConfig struct {
+	Enabled     bool
+	Interval    time.Duration // How often the feed is regenerated
+	HorizonDays int           // Local days of published trips, from today, the feed covers
+	AgencyName  string
+	AgencyURL   string
+	AgencyPhone string
+	Lang        string        // Language of stop and route names
+	RealtimeTTL time.Duration // How long a GTFS Realtime feed is served before it is rebuilt
+}
+
+// LoungeNoShowConfig holds settings for the job that marks lounge guests who never arrive as no-shows
+type LoungeNoShowConfig struct {
+	Enabled       bool
+	GraceMinutes  int           // Minutes after scheduled arrival before marking, for lounges without their own policy
+	CheckInterval time.Duration // How often the job looks for due no-shows
+}
+
+// LoungeBriefingConfig holds settings for the job that sends lounge staff the day's expected guests
+type LoungeBriefingConfig struct {
+	Enabled  bool
+	SendHour int // Local hour (Asia/Colombo) the briefing is sent at
+}
+
+// RetentionConfig holds settings for the job that purges expired OTP rows and old trip location
+// history, and anonymizes old audit log rows
+type RetentionConfig struct {
+	Enabled             bool
+	CheckInterval       time.Duration // How often the job runs
+	OTPRetention        time.Duration // How long OTP rows are kept after they expire
+	LocationHistory     time.Duration // How long reported bus positions are kept for trip playback
+	AuditAnonymizeAfter time.Duration // Age at which audit rows lose their phone numbers, IPs and user agents
+	BatchSize           int           // Rows handled per statement, so long runs don't hold big locks
+	HashSalt            string        // Mixed into phone/IP hashes so they can't be reversed by lookup
+}
+
+// PaymentReconciliationConfig holds settings for the job that checks PAYable for intents stuck
+// in payment_pending or confirming and confirms or expires them
+type PaymentReconciliationConfig struct {
+	Enabled       bool
+	CheckInterval time.Duration // How often the job runs
+	StuckAfter    time.Duration // How long an intent waits for its webhook before the job checks it
+	AbandonAfter  time.Duration // Age of a still-unpaid payment at which the intent is expired
+	BatchSize     int           // Intents checked per run
+}
+
+// UsageAnalyticsConfig holds settings for the anonymized feature usage pipeline
+type UsageAnalyticsConfig struct {
+	Enabled       bool
+	BufferSize    int           // Events held in memory awaiting a write; more are dropped
+	BatchSize     int           // Events written per insert
+	FlushInterval time.Duration // Longest an event waits before being written
+	HashSalt      string        // Mixed into user hashes so they can't be reversed by lookup
+}
+
+// OTPTestNumbersConfig holds settings for the QA test number allowlist. Listed numbers get no
+// SMS and accept a fixed OTP derived from the deploy ID, so every instance of a deploy agrees
+// on it and it changes with each deploy.
+type OTPTestNumbersConfig struct {
+	Enabled  bool
+	DeployID string // Identifies the running deploy; a random ID is used per process when empty
+	Secret   string // Keys the fixed OTP derivation
+	HashSalt string // Mixed into the stored phone hashes
+}
+
+// EventsConfig holds settings for the internal booking lifecycle event bus and the
+// transactional outbox that booking.confirmed and booking.cancelled are delivered from
+type EventsConfig struct {
+	Publisher  string // "channel" (in-process bus) or "none"
+	BufferSize int    // Events queued for delivery; more are dropped
+
+	OutboxEnabled     bool          // Dispatch outbox events on this instance; they are always saved
+	OutboxInterval    time.Duration // How often the dispatcher looks for due events
+	OutboxBatchSize   int           // Events claimed per run
+	OutboxMaxAttempts int           // Deliveries tried before an event is marked failed
+}
+
+// RedisConfig holds the connection to the Redis shared by all instances
+type RedisConfig struct {
+	URL       string // e.g. redis://:password@localhost:6379/0; rediss:// for TLS
+	KeyPrefix string // Prepended to every key, so environments can share a server
+}
+
+// SearchConfig holds settings for the trip search result cache
+type SearchConfig struct {
+	CacheEnabled    bool
+	CacheTTL        time.Duration // How long identical searches are served from memory
+	CacheMaxEntries int           // Cached searches kept; the soonest to expire are dropped first
+}
+
+// PayoutConfig holds settings for owner payout batches
+type PayoutConfig struct {
+	Enabled           bool          // Generate each cycle's batch automatically once the cycle ends
+	Cycle             string        // Settlement cycle: "weekly" (Monday-Sunday) or "monthly"
+	CommissionPercent int           // Platform commission withheld from gross earnings
+	CheckInterval     time.Duration // How often the job checks for a finished cycle
+}
+
+// AdminJobsConfig holds settings for the worker processing admin bulk jobs
+type AdminJobsConfig struct {
+	Enabled       bool          // Process jobs on this instance; jobs are still accepted when disabled
+	CheckInterval time.Duration // How often the worker looks for queued jobs
+	MaxItems      int           // IDs a single approval or notification job may list
+	MaxExportRows int           // Row cap for user exports
+	StaleAfter    time.Duration // A running job not updated for this long is picked up again
+}
+
+// RoutingConfig holds the road routing engine used to snap route stops to roads and measure
+// the distance and driving time between them. Leaving the selected engine unconfigured
+// disables polyline generation and segment computation; uploads still work.
+type RoutingConfig struct {
+	Engine string // "osrm" or "google"
+
+	OSRMBaseURL string
+	OSRMProfile string // OSRM routing profile, e.g. "driving"
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker for OSRM calls
+
+	GoogleAPIKey string            // Google Directions API key
+	GoogleHTTP   httpclient.Config // Timeouts, retries and circuit breaker for Google Directions calls
+
+	SegmentCacheTTL    time.Duration // How long computed stop-to-stop segments are reused
+	BusDurationPercent int           // Scales engine (car) driving times to bus times, e.g. 130
+}
+
+// AppConfig holds the passenger app versions served by GET /api/v1/app/config. Each value is a
+// default that an app_* system setting of the same name overrides at runtime.
+type AppConfig struct {
+	MinVersionAndroid    string // Oldest Android version allowed to call the API; empty allows all
+	MinVersionIOS        string
+	LatestVersionAndroid string // Newest release, used to suggest optional upgrades
+	LatestVersionIOS     string
+	StoreURLAndroid      string
+	StoreURLIOS          string
+
+	CacheTTL time.Duration // How long settings are cached before system setting changes apply
+}
+
+// GeoIPConfig holds the local MaxMind database files used to geolocate client IPs.
+// Leaving both empty disables geolocation.
+type GeoIPConfig struct {
+	CityDBPath string // GeoLite2-City.mmdb (country and city)
+	ASNDBPath  string // GeoLite2-ASN.mmdb (network operator)
+}
+
+// PushConfig holds push notification (Firebase Cloud Messaging) configuration
+type PushConfig struct {
+	Mode           string // "dev" logs notifications, "production" sends them through FCM
+	FCMProjectID   string
+	FCMClientEmail string // Service account email
+	FCMPrivateKey  string // Service account PEM key (escaped \n newlines are expanded)
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker for FCM calls
+}
+
+// TripSharingConfig holds settings for sharing live trips with passengers' emergency contacts
+type TripSharingConfig struct {
+	Enabled                 bool          // Automatic trip-start and incident SMS to contacts; manual sharing always works
+	TrackingBaseURL         string        // Public tracking page; the share token is appended
+	LinkTTL                 time.Duration // How long a share link stays valid after it is created
+	MaxContactsPerPassenger int
+}
+
+// LiveTrackingConfig holds settings for the live bus location streams passenger apps follow
+type LiveTrackingConfig struct {
+	HeartbeatInterval     time.Duration // Keep-alive interval; each heartbeat also re-reads the trip for updates sent to other instances
+	MaxSubscribersPerTrip int           // Open streams per active trip (0 = unlimited)
+	HistoryBatchSize      int           // Reported positions written to trip_location_history per insert
+	HistoryFlushInterval  time.Duration // Longest a reported position waits before it is written
+	HistoryMaxBuffered    int           // Positions kept while the database is unavailable; the oldest are dropped past this
+}
+
+// ETAConfig holds settings for arrival estimates at the stops of active trips
+type ETAConfig struct {
+	HistoryDays     int           // How far back completed trips time the legs between stops
+	HistoryTrips    int           // Most recent completed trips of a route used
+	HistoryCacheTTL time.Duration // How long a route's leg times are reused before being read again
+}
+
+// WaitlistConfig holds settings for the waitlists of sold-out trips
+type WaitlistConfig struct {
+	OfferTTL      time.Duration // How long a promoted passenger has to book the seats kept for them
+	CheckInterval time.Duration // How often freed seats are offered and lapsed offers expired
+}
+
+// StaffDeviceConfig holds settings for staff device-credential (biometric) login
+type StaffDeviceConfig struct {
+	CredentialTTL      time.Duration // How long a registered device can log in without a new OTP login
+	ChallengeTTL       time.Duration // How long a login challenge can be signed
+	MaxDevicesPerStaff int           // Active devices per staff member (0 = unlimited)
+}
+
+// BookingQRConfig holds settings for signed boarding QRs
+type BookingQRConfig struct {
+	SignatureRequired   bool          // Reject unsigned (legacy) QR codes at staff verification
+	KeyRetention        time.Duration // How long a rotated-out key keeps verifying QRs signed before the rotation
+	ValidAfterDeparture time.Duration // A QR is rejected this long after its trip's departure
+	KeyCacheTTL         time.Duration // How often each server reloads the signing keys
+}
+
+// StaffPrivacyConfig controls how much passenger contact detail conductors and drivers see
+type StaffPrivacyConfig struct {
+	MaskPassengerContacts bool   // Mask passenger phone numbers in staff booking views
+	VisibleDigits         int    // Trailing phone digits left visible when masked
+	ContactAccess         string // How staff reach a masked passenger: "reveal" (audited), "relay" or "none"
+	RelayNumber           string // Call-relay number staff call instead, quoting the booking reference
+	MaxRevealsPerTrip     int    // Contact requests per staff member per trip (0 = unlimited)
+}
+
+// DriverFatigueConfig holds the hours-of-service rules a driver's assigned trips must keep to
+type DriverFatigueConfig struct {
+	Enabled                bool
+	MaxDailyDrivingMinutes int // Driving allowed per local day, summed over the driver's trips
+	MinRestMinutes         int // Rest required between the end of one trip and the start of the next
+	DefaultTripMinutes     int // Duration assumed for trips without an estimated duration
+}
+
+// FareComplianceConfig holds settings for checking trip fares against route permits
+type FareComplianceConfig struct {
+	EnforceOnPublish bool // Block publishing trips priced above their permit's approved fare
+}
+
+// TripAutoPublishConfig holds settings for the job that publishes generated trips of schedules
+// with auto-publish turned on
+type TripAutoPublishConfig struct {
+	Enabled       bool
+	CheckInterval time.Duration // How often the job looks for trips due to go live
+	BatchSize     int           // Trips attempted per run
+}
+
+// SeatPricingConfig holds settings for the job that reprices upcoming trips' seats from the
+// owners' dynamic pricing rules
+type SeatPricingConfig struct {
+	Enabled       bool
+	CheckInterval time.Duration // How often rules are re-evaluated
+	BatchSize     int           // Trips loaded per query
+}
+
+// ReminderConfig holds passenger boarding reminder configuration
+type ReminderConfig struct {
+	Enabled               bool
+	DepartureOffsets      []time.Duration // Send a reminder this long before departure (e.g. 24h, 2h)
+	BusApproachingMinutes int             // Send a reminder when the bus is this many minutes from the boarding stop (0 = off)
+	CheckInterval         time.Duration   // How often the scheduler looks for due reminders
+	MaxAttempts           int             // Delivery attempts before a reminder is marked failed
+}
+
+// BookingConfig holds booking intent hold (TTL) configuration
+type BookingConfig struct {
+	BusOnlyTTL    time.Duration // Hold TTL for bus_only intents
+	LoungeOnlyTTL time.Duration // Hold TTL for lounge_only intents
+	CombinedTTL   time.Duration // Hold TTL for combined bus+lounge intents
+	MinTTL        time.Duration // Floor applied after tier adjustment
+
+	// Reliability tiers (computed from the user's abandonment history)
+	TrustedTTLPercent          int           // TTL percentage for trusted users (100 = unchanged)
+	RestrictedTTLPercent       int           // TTL percentage for users who repeatedly abandon holds
+	AbandonmentLookback        time.Duration // How far back to count abandoned intents
+	RestrictedAbandonThreshold int           // Abandoned intents in lookback that make a user restricted
+	TrustedMinConfirmed        int           // Confirmed intents (with no abandons) that make a user trusted
+
+	// Cross-device checkout handoff
+	HandoffTTL      time.Duration // How long a handoff token stays claimable (capped by the intent's expiry)
+	HandoffDeepLink string        // App deep link the handoff token is appended to
+
+	// Call-center bookings: the passenger pays from an SMS link, so the hold is longer
+	CallCenterHoldTTL time.Duration
+
+	// Explicit hold extensions requested by the app while the passenger finishes paying
+	HoldExtension     time.Duration // Time added per extension
+	MaxHoldExtensions int           // Extensions allowed per intent
+
+	// Seat price changes while an intent is held: "honor_snapshot" keeps the held price,
+	// "require_reaccept" makes the passenger accept the new prices before paying
+	PriceDriftPolicy string
+
+	// Passenger cancellation fees as "<notice>:<fee percent>" tiers, e.g. "24h:0,6h:25,0s:50";
+	// empty refunds every cancellation in full
+	CancellationFeeTiers string
+
+	// Intent creation limits: concurrent holds per scheduled trip and global load shedding
+	IntentAdmission loadshed.Config
 }
 
 // PaymentConfig holds PAYable IPG configuration
@@ -48,6 +489,8 @@ type PaymentConfig struct {
 	LogoURL       string // Merchant logo URL for payment page
 	ReturnURL     string // URL to redirect after payment (app deep link)
 	WebhookURL    string // Server webhook URL for payment notifications
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker for PAYable calls
 }
 
 // ServerConfig holds server-related configuration
@@ -71,6 +514,10 @@ type JWTConfig struct {
 	RefreshSecret      string
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
+	// ClaimsEnrichment replaces the roles in access tokens with the user's current ones
+	ClaimsEnrichment bool
+	// ClaimsCacheTTL is how long a user's current roles are reused before they are re-read
+	ClaimsCacheTTL time.Duration
 }
 
 // SMSConfig holds SMS gateway configuration
@@ -86,6 +533,47 @@ type SMSConfig struct {
 	Mask             string // Dialog SMS mask/source address
 	DriverAppHash    string // App signature hash for Driver/Conductor app SMS auto-read (Android)
 	PassengerAppHash string // App signature hash for Passenger app SMS auto-read (Android)
+
+	HTTP  httpclient.Config // Timeouts, retries and circuit breaker for Dialog calls
+	Queue SMSQueueConfig    // Async OTP delivery (production mode only)
+
+	Fallback string       // "" (none) or "twilio": provider tried when Dialog fails or its breaker is open
+	Twilio   TwilioConfig // Twilio account, used when Fallback is "twilio"
+}
+
+// WhatsAppConfig holds WhatsApp Business Cloud API settings. Users choose WhatsApp per
+// send-otp request or as their messaging_channel preference; everything else keeps going by SMS.
+type WhatsAppConfig struct {
+	Enabled         bool
+	APIURL          string
+	PhoneNumberID   string
+	AccessToken     string
+	OTPTemplate     string // Approved authentication template for OTPs
+	BookingTemplate string // Approved utility template for booking confirmations
+	Language        string // Template language code
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker for WhatsApp calls
+}
+
+// TwilioConfig holds Twilio Programmable Messaging credentials
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	From       string // Sender number in E.164 format, or an alphanumeric sender ID
+	APIURL     string
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker for Twilio calls
+}
+
+// SMSQueueConfig holds settings for the async OTP SMS worker queue
+type SMSQueueConfig struct {
+	Workers     int           // Concurrent senders
+	QueueSize   int           // Pending messages before SendOTP returns 503
+	MaxAttempts int           // Delivery attempts per message, including the first
+	RetryDelay  time.Duration // Base backoff between attempts (doubles each retry)
+	StatusTTL   time.Duration // How long delivery status stays pollable via otp-status
+	// DrainTimeout is how long Stop keeps sending queued messages before failing the rest
+	DrainTimeout time.Duration
 }
 
 // OTPConfig holds OTP-related configuration
@@ -93,8 +581,16 @@ type OTPConfig struct {
 	Length            int
 	ExpiryMinutes     int
 	MaxAttempts       int
-	RateLimit         int
+	RateLimit         int // Max OTP requests per phone number per window
 	RateWindowMinutes int
+
+	IPRateLimit         int // Max OTP requests per IP address per window
+	IPRateWindowMinutes int
+	RateLimitBackend    string // "database" or "redis"; use redis when running several instances
+
+	ResendCooldowns        []time.Duration // Wait before the 1st, 2nd, 3rd... resend; the last value repeats
+	MaxResends             int             // Resends allowed per send-otp before a fresh send-otp is required
+	ResendWhatsAppFallback bool            // Deliver the 2nd and later resends over WhatsApp when the gateway supports it
 }
 
 // RateLimitConfig holds rate limiting configuration
@@ -115,6 +611,14 @@ type SecurityConfig struct {
 	BcryptCost       int
 	EnableRequestLog bool
 	EnableAuditLog   bool
+	SuperAdminEmails []string // Admin accounts that always hold the super_admin role, so roles can be assigned on a new deploy
+}
+
+// PIIEncryptionConfig holds the keys personal data columns are encrypted with. Keys no longer
+// active stay listed so values they encrypted can still be read until they are re-encrypted.
+type PIIEncryptionConfig struct {
+	Keys        string // "<id>:<base64 32-byte key>" pairs, comma-separated; empty leaves PII in plaintext
+	ActiveKeyID string // Key new values are encrypted with
 }
 
 // Load loads configuration from environment variables
@@ -141,6 +645,8 @@ func Load() (*Config, error) {
 			RefreshSecret:      getEnv("JWT_REFRESH_SECRET", ""),
 			AccessTokenExpiry:  time.Duration(getEnvAsInt("JWT_ACCESS_TOKEN_EXPIRY", 3600)) * time.Second,
 			RefreshTokenExpiry: time.Duration(getEnvAsInt("JWT_REFRESH_TOKEN_EXPIRY", 604800)) * time.Second,
+			ClaimsEnrichment:   getEnvAsBool("JWT_CLAIMS_ENRICHMENT", true),
+			ClaimsCacheTTL:     time.Duration(getEnvAsInt("JWT_CLAIMS_CACHE_TTL_SECONDS", 15)) * time.Second,
 		},
 		SMS: SMSConfig{
 			Mode:             getEnv("SMS_MODE", "dev"),          // "dev" or "production"
@@ -155,6 +661,24 @@ func Load() (*Config, error) {
 			// Deprecated fields kept for backward compatibility
 			APIKey:   getEnv("DIALOG_SMS_API_KEY", ""),
 			SenderID: getEnv("DIALOG_SMS_SENDER_ID", "SmartTransit"),
+			HTTP:     getEnvAsHTTPClientConfig("DIALOG_SMS", "dialog"),
+			Queue: SMSQueueConfig{
+				Workers:     getEnvAsInt("SMS_QUEUE_WORKERS", 4),
+				QueueSize:   getEnvAsInt("SMS_QUEUE_SIZE", 1000),
+				MaxAttempts: getEnvAsInt("SMS_QUEUE_MAX_ATTEMPTS", 3),
+				RetryDelay:  time.Duration(getEnvAsInt("SMS_QUEUE_RETRY_DELAY_MS", 2000)) * time.Millisecond,
+				StatusTTL:   time.Duration(getEnvAsInt("SMS_QUEUE_STATUS_TTL_MINUTES", 15)) * time.Minute,
+
+				DrainTimeout: time.Duration(getEnvAsInt("SMS_QUEUE_DRAIN_TIMEOUT_SECONDS", 10)) * time.Second,
+			},
+			Fallback: getEnv("SMS_FALLBACK_PROVIDER", ""),
+			Twilio: TwilioConfig{
+				AccountSID: getEnv("TWILIO_ACCOUNT_SID", ""),
+				AuthToken:  getEnv("TWILIO_AUTH_TOKEN", ""),
+				From:       getEnv("TWILIO_FROM", ""),
+				APIURL:     getEnv("TWILIO_API_URL", "https://api.twilio.com"),
+				HTTP:       getEnvAsHTTPClientConfig("TWILIO", "twilio"),
+			},
 		},
 		OTP: OTPConfig{
 			Length:            getEnvAsInt("OTP_LENGTH", 6),
@@ -162,6 +686,24 @@ func Load() (*Config, error) {
 			MaxAttempts:       getEnvAsInt("OTP_MAX_ATTEMPTS", 3),
 			RateLimit:         getEnvAsInt("OTP_RATE_LIMIT", 3),
 			RateWindowMinutes: getEnvAsInt("OTP_RATE_WINDOW_MINUTES", 10),
+
+			IPRateLimit:         getEnvAsInt("OTP_IP_RATE_LIMIT", 10),
+			IPRateWindowMinutes: getEnvAsInt("OTP_IP_RATE_WINDOW_MINUTES", 60),
+			RateLimitBackend:    getEnv("OTP_RATE_LIMIT_BACKEND", "database"),
+
+			ResendCooldowns:        getEnvAsDurationSlice("OTP_RESEND_COOLDOWNS", []time.Duration{30 * time.Second, 60 * time.Second, 120 * time.Second}),
+			MaxResends:             getEnvAsInt("OTP_MAX_RESENDS", 5),
+			ResendWhatsAppFallback: getEnvAsBool("OTP_RESEND_WHATSAPP_FALLBACK", false),
+		},
+		WhatsApp: WhatsAppConfig{
+			Enabled:         getEnvAsBool("WHATSAPP_ENABLED", false),
+			APIURL:          getEnv("WHATSAPP_API_URL", "https://graph.facebook.com/v21.0"),
+			PhoneNumberID:   getEnv("WHATSAPP_PHONE_NUMBER_ID", ""),
+			AccessToken:     getEnv("WHATSAPP_ACCESS_TOKEN", ""),
+			OTPTemplate:     getEnv("WHATSAPP_OTP_TEMPLATE", "otp_code"),
+			BookingTemplate: getEnv("WHATSAPP_BOOKING_TEMPLATE", "booking_confirmed"),
+			Language:        getEnv("WHATSAPP_TEMPLATE_LANGUAGE", "en"),
+			HTTP:            getEnvAsHTTPClientConfig("WHATSAPP", "whatsapp"),
 		},
 		RateLimit: RateLimitConfig{
 			Requests:      getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
@@ -170,12 +712,17 @@ func Load() (*Config, error) {
 		CORS: CORSConfig{
 			AllowedOrigins: getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"*"}),
 			AllowedMethods: getEnvAsSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-			AllowedHeaders: getEnvAsSlice("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization"}),
+			AllowedHeaders: getEnvAsSlice("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization", "X-Tenant-Key"}),
 		},
 		Security: SecurityConfig{
 			BcryptCost:       getEnvAsInt("BCRYPT_COST", 12),
 			EnableRequestLog: getEnvAsBool("ENABLE_REQUEST_LOGGING", true),
 			EnableAuditLog:   getEnvAsBool("ENABLE_AUDIT_LOGGING", true),
+			SuperAdminEmails: getEnvAsSlice("SUPER_ADMIN_EMAILS", nil),
+		},
+		PIIEncryption: PIIEncryptionConfig{
+			Keys:        getEnv("PII_ENCRYPTION_KEYS", ""),
+			ActiveKeyID: getEnv("PII_ENCRYPTION_ACTIVE_KEY", ""),
 		},
 		Payment: PaymentConfig{
 			Environment:   getEnv("PAYABLE_ENVIRONMENT", "sandbox"),
@@ -184,8 +731,261 @@ func Load() (*Config, error) {
 			LogoURL:       getEnv("PAYABLE_LOGO_URL", ""),
 			ReturnURL:     getEnv("PAYABLE_RETURN_URL", ""),
 			WebhookURL:    getEnv("PAYABLE_WEBHOOK_URL", ""),
+			HTTP:          getEnvAsHTTPClientConfig("PAYABLE", "payable"),
+		},
+		Booking: BookingConfig{
+			BusOnlyTTL:                 time.Duration(getEnvAsInt("INTENT_TTL_BUS_ONLY_SECONDS", 600)) * time.Second,
+			LoungeOnlyTTL:              time.Duration(getEnvAsInt("INTENT_TTL_LOUNGE_ONLY_SECONDS", 600)) * time.Second,
+			CombinedTTL:                time.Duration(getEnvAsInt("INTENT_TTL_COMBINED_SECONDS", 900)) * time.Second,
+			MinTTL:                     time.Duration(getEnvAsInt("INTENT_TTL_MIN_SECONDS", 180)) * time.Second,
+			TrustedTTLPercent:          getEnvAsInt("INTENT_TTL_TRUSTED_PERCENT", 100),
+			RestrictedTTLPercent:       getEnvAsInt("INTENT_TTL_RESTRICTED_PERCENT", 50),
+			AbandonmentLookback:        time.Duration(getEnvAsInt("INTENT_ABANDON_LOOKBACK_DAYS", 30)) * 24 * time.Hour,
+			RestrictedAbandonThreshold: getEnvAsInt("INTENT_ABANDON_RESTRICTED_THRESHOLD", 3),
+			TrustedMinConfirmed:        getEnvAsInt("INTENT_TRUSTED_MIN_CONFIRMED", 3),
+			HandoffTTL:                 time.Duration(getEnvAsInt("INTENT_HANDOFF_TTL_SECONDS", 300)) * time.Second,
+			HandoffDeepLink:            getEnv("INTENT_HANDOFF_DEEP_LINK", "smarttransit://booking/resume"),
+			CallCenterHoldTTL:          time.Duration(getEnvAsInt("INTENT_TTL_CALL_CENTER_SECONDS", 1800)) * time.Second,
+			HoldExtension:              time.Duration(getEnvAsInt("INTENT_HOLD_EXTENSION_SECONDS", 300)) * time.Second,
+			MaxHoldExtensions:          getEnvAsInt("INTENT_MAX_HOLD_EXTENSIONS", 2),
+			PriceDriftPolicy:           getEnv("INTENT_PRICE_DRIFT_POLICY", "honor_snapshot"),
+			CancellationFeeTiers:       getEnv("BOOKING_CANCELLATION_FEE_TIERS", ""),
+			IntentAdmission: loadshed.Config{
+				Name:             "booking_intent_create",
+				Enabled:          getEnvAsBool("INTENT_ADMISSION_ENABLED", true),
+				MaxPerKey:        getEnvAsInt("INTENT_MAX_CONCURRENT_PER_TRIP", 4),
+				KeyWait:          time.Duration(getEnvAsInt("INTENT_TRIP_QUEUE_WAIT_MS", 2000)) * time.Millisecond,
+				MaxQueueDepth:    getEnvAsInt("INTENT_SHED_QUEUE_DEPTH", 200),
+				LatencyThreshold: time.Duration(getEnvAsInt("INTENT_SHED_LATENCY_MS", 3000)) * time.Millisecond,
+				LatencyWindow:    time.Duration(getEnvAsInt("INTENT_SHED_LATENCY_WINDOW_SECONDS", 10)) * time.Second,
+				RetryAfter:       time.Duration(getEnvAsInt("INTENT_SHED_RETRY_AFTER_SECONDS", 5)) * time.Second,
+			},
+		},
+		Reminder: ReminderConfig{
+			Enabled:               getEnvAsBool("REMINDER_ENABLED", true),
+			DepartureOffsets:      getEnvAsDurationSlice("REMINDER_DEPARTURE_OFFSETS", []time.Duration{24 * time.Hour, 2 * time.Hour}),
+			BusApproachingMinutes: getEnvAsInt("REMINDER_BUS_APPROACHING_MINUTES", 15),
+			CheckInterval:         time.Duration(getEnvAsInt("REMINDER_CHECK_INTERVAL_SECONDS", 60)) * time.Second,
+			MaxAttempts:           getEnvAsInt("REMINDER_MAX_ATTEMPTS", 3),
+		},
+		StaffDevice: StaffDeviceConfig{
+			CredentialTTL:      time.Duration(getEnvAsInt("STAFF_DEVICE_CREDENTIAL_DAYS", 90)) * 24 * time.Hour,
+			ChallengeTTL:       time.Duration(getEnvAsInt("STAFF_DEVICE_CHALLENGE_SECONDS", 120)) * time.Second,
+			MaxDevicesPerStaff: getEnvAsInt("STAFF_DEVICE_MAX_PER_STAFF", 3),
+		},
+		BookingQR: BookingQRConfig{
+			SignatureRequired:   getEnvAsBool("BOOKING_QR_SIGNATURE_REQUIRED", false),
+			KeyRetention:        time.Duration(getEnvAsInt("BOOKING_QR_KEY_RETENTION_DAYS", 30)) * 24 * time.Hour,
+			ValidAfterDeparture: time.Duration(getEnvAsInt("BOOKING_QR_VALID_HOURS_AFTER_DEPARTURE", 12)) * time.Hour,
+			KeyCacheTTL:         time.Duration(getEnvAsInt("BOOKING_QR_KEY_CACHE_SECONDS", 300)) * time.Second,
+		},
+		StaffPrivacy: StaffPrivacyConfig{
+			MaskPassengerContacts: getEnvAsBool("STAFF_MASK_PASSENGER_CONTACTS", true),
+			VisibleDigits:         getEnvAsInt("STAFF_CONTACT_VISIBLE_DIGITS", 3),
+			ContactAccess:         getEnv("STAFF_CONTACT_ACCESS", "reveal"),
+			RelayNumber:           getEnv("STAFF_CONTACT_RELAY_NUMBER", ""),
+			MaxRevealsPerTrip:     getEnvAsInt("STAFF_CONTACT_MAX_REVEALS_PER_TRIP", 10),
+		},
+		DriverFatigue: DriverFatigueConfig{
+			Enabled:                getEnvAsBool("DRIVER_FATIGUE_ENABLED", true),
+			MaxDailyDrivingMinutes: getEnvAsInt("DRIVER_MAX_DAILY_DRIVING_HOURS", 10) * 60,
+			MinRestMinutes:         getEnvAsInt("DRIVER_MIN_REST_MINUTES", 30),
+			DefaultTripMinutes:     getEnvAsInt("DRIVER_DEFAULT_TRIP_MINUTES", 120),
+		},
+		FareCompliance: FareComplianceConfig{
+			EnforceOnPublish: getEnvAsBool("FARE_COMPLIANCE_ENFORCE", false),
+		},
+		TripAutoPublish: TripAutoPublishConfig{
+			Enabled:       getEnvAsBool("TRIP_AUTO_PUBLISH_ENABLED", true),
+			CheckInterval: time.Duration(getEnvAsInt("TRIP_AUTO_PUBLISH_CHECK_INTERVAL_SECONDS", 900)) * time.Second,
+			BatchSize:     getEnvAsInt("TRIP_AUTO_PUBLISH_BATCH_SIZE", 200),
+		},
+		SeatPricing: SeatPricingConfig{
+			Enabled:       getEnvAsBool("SEAT_PRICING_ENABLED", true),
+			CheckInterval: time.Duration(getEnvAsInt("SEAT_PRICING_CHECK_INTERVAL_SECONDS", 1800)) * time.Second,
+			BatchSize:     getEnvAsInt("SEAT_PRICING_BATCH_SIZE", 200),
+		},
+		TripSharing: TripSharingConfig{
+			Enabled:                 getEnvAsBool("TRIP_SHARING_ENABLED", true),
+			TrackingBaseURL:         getEnv("TRIP_SHARING_TRACKING_URL", "https://smarttransit.lk/track/"),
+			LinkTTL:                 time.Duration(getEnvAsInt("TRIP_SHARING_LINK_TTL_HOURS", 12)) * time.Hour,
+			MaxContactsPerPassenger: getEnvAsInt("TRIP_SHARING_MAX_CONTACTS", 3),
+		},
+		LiveTracking: LiveTrackingConfig{
+			HeartbeatInterval:     time.Duration(getEnvAsInt("LIVE_TRACKING_HEARTBEAT_SECONDS", 10)) * time.Second,
+			MaxSubscribersPerTrip: getEnvAsInt("LIVE_TRACKING_MAX_SUBSCRIBERS_PER_TRIP", 500),
+			HistoryBatchSize:      getEnvAsInt("LIVE_TRACKING_HISTORY_BATCH_SIZE", 100),
+			HistoryFlushInterval:  time.Duration(getEnvAsInt("LIVE_TRACKING_HISTORY_FLUSH_SECONDS", 5)) * time.Second,
+			HistoryMaxBuffered:    getEnvAsInt("LIVE_TRACKING_HISTORY_MAX_BUFFERED", 10000),
+		},
+		ETA: ETAConfig{
+			HistoryDays:     getEnvAsInt("ETA_HISTORY_DAYS", 30),
+			HistoryTrips:    getEnvAsInt("ETA_HISTORY_TRIPS", 20),
+			HistoryCacheTTL: time.Duration(getEnvAsInt("ETA_HISTORY_CACHE_MINUTES", 30)) * time.Minute,
+		},
+		Waitlist: WaitlistConfig{
+			OfferTTL:      time.Duration(getEnvAsInt("WAITLIST_OFFER_MINUTES", 15)) * time.Minute,
+			CheckInterval: time.Duration(getEnvAsInt("WAITLIST_CHECK_INTERVAL_SECONDS", 30)) * time.Second,
+		},
+		Push: PushConfig{
+			Mode:           getEnv("PUSH_MODE", "dev"),
+			FCMProjectID:   getEnv("FCM_PROJECT_ID", ""),
+			FCMClientEmail: getEnv("FCM_CLIENT_EMAIL", ""),
+			FCMPrivateKey:  strings.ReplaceAll(getEnv("FCM_PRIVATE_KEY", ""), `\n`, "\n"),
+			HTTP:           getEnvAsHTTPClientConfig("FCM", "fcm"),
+		},
+		Email: EmailConfig{
+			Mode:               getEnv("EMAIL_MODE", "dev"),
+			Provider:           getEnv("EMAIL_PROVIDER", "smtp"),
+			SMTPHost:           getEnv("SMTP_HOST", ""),
+			SMTPPort:           getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername:       getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:       getEnv("SMTP_PASSWORD", ""),
+			From:               getEnv("EMAIL_FROM", "reports@smarttransit.lk"),
+			FromName:           getEnv("EMAIL_FROM_NAME", "SmartTransit"),
+			SESRegion:          getEnv("AWS_SES_REGION", "ap-south-1"),
+			SESAccessKeyID:     getEnv("AWS_SES_ACCESS_KEY_ID", ""),
+			SESSecretAccessKey: getEnv("AWS_SES_SECRET_ACCESS_KEY", ""),
+			SESEndpoint:        getEnv("AWS_SES_ENDPOINT", ""),
+			SESHTTP:            getEnvAsHTTPClientConfig("SES", "ses"),
+		},
+		Reports: ReportConfig{
+			Enabled:          getEnvAsBool("REPORTS_ENABLED", true),
+			CheckInterval:    time.Duration(getEnvAsInt("REPORTS_CHECK_INTERVAL_SECONDS", 300)) * time.Second,
+			SendHour:         getEnvAsInt("REPORTS_SEND_HOUR", 6),
+			UnsubscribeURL:   getEnv("REPORTS_UNSUBSCRIBE_URL", "https://api.smarttransit.lk/api/v1/reports/unsubscribe/"),
+			MaxAttempts:      getEnvAsInt("REPORTS_MAX_ATTEMPTS", 3),
+			MaxSubscriptions: getEnvAsInt("REPORTS_MAX_SUBSCRIPTIONS", 10),
+			MaxCSVRows:       getEnvAsInt("REPORTS_MAX_CSV_ROWS", 50000),
+		},
+		Punctuality: PunctualityConfig{
+			Enabled:               getEnvAsBool("PUNCTUALITY_ENABLED", true),
+			RunHour:               getEnvAsInt("PUNCTUALITY_RUN_HOUR", 2),
+			WindowDays:            getEnvAsInt("PUNCTUALITY_WINDOW_DAYS", 90),
+			DepartureGraceMinutes: getEnvAsInt("PUNCTUALITY_DEPARTURE_GRACE_MINUTES", 5),
+			ArrivalGraceMinutes:   getEnvAsInt("PUNCTUALITY_ARRIVAL_GRACE_MINUTES", 10),
+			MinTrips:              getEnvAsInt("PUNCTUALITY_MIN_TRIPS", 5),
+		},
+		OwnerAnalytics: OwnerAnalyticsConfig{
+			Enabled:       getEnvAsBool("OWNER_ANALYTICS_ENABLED", true),
+			Interval:      time.Duration(getEnvAsInt("OWNER_ANALYTICS_INTERVAL_MINUTES", 60)) * time.Minute,
+			RecomputeDays: getEnvAsInt("OWNER_ANALYTICS_RECOMPUTE_DAYS", 3),
+			BackfillDays:  getEnvAsInt("OWNER_ANALYTICS_BACKFILL_DAYS", 35),
+		},
+		GTFS: GTFSConfig{
+			Enabled:     getEnvAsBool("GTFS_ENABLED", true),
+			Interval:    time.Duration(getEnvAsInt("GTFS_REGENERATE_INTERVAL_MINUTES", 60)) * time.Minute,
+			HorizonDays: getEnvAsInt("GTFS_HORIZON_DAYS", 30),
+			AgencyName:  getEnv("GTFS_AGENCY_NAME", "SmartTransit"),
+			AgencyURL:   getEnv("GTFS_AGENCY_URL", "https://smarttransit.lk"),
+			AgencyPhone: getEnv("GTFS_AGENCY_PHONE", ""),
+			Lang:        getEnv("GTFS_LANG", "en"),
+			RealtimeTTL: time.Duration(getEnvAsInt("GTFS_REALTIME_CACHE_SECONDS", 15)) * time.Second,
+		},
+		LoungeNoShow: LoungeNoShowConfig{
+			Enabled:       getEnvAsBool("LOUNGE_NO_SHOW_ENABLED", true),
+			GraceMinutes:  getEnvAsInt("LOUNGE_NO_SHOW_GRACE_MINUTES", 60),
+			CheckInterval: time.Duration(getEnvAsInt("LOUNGE_NO_SHOW_CHECK_INTERVAL_SECONDS", 300)) * time.Second,
+		},
+		LoungeBriefing: LoungeBriefingConfig{
+			Enabled:  getEnvAsBool("LOUNGE_BRIEFING_ENABLED", true),
+			SendHour: getEnvAsInt("LOUNGE_BRIEFING_SEND_HOUR", 6),
+		},
+		Retention: RetentionConfig{
+			Enabled:             getEnvAsBool("RETENTION_ENABLED", true),
+			CheckInterval:       time.Duration(getEnvAsInt("RETENTION_CHECK_INTERVAL_MINUTES", 60)) * time.Minute,
+			OTPRetention:        time.Duration(getEnvAsInt("RETENTION_OTP_DAYS", 7)) * 24 * time.Hour,
+			LocationHistory:     time.Duration(getEnvAsInt("RETENTION_LOCATION_HISTORY_DAYS", 90)) * 24 * time.Hour,
+			AuditAnonymizeAfter: time.Duration(getEnvAsInt("RETENTION_AUDIT_ANONYMIZE_DAYS", 180)) * 24 * time.Hour,
+			BatchSize:           getEnvAsInt("RETENTION_BATCH_SIZE", 1000),
+			HashSalt:            getEnv("RETENTION_HASH_SALT", ""),
+		},
+		PaymentReconciliation: PaymentReconciliationConfig{
+			Enabled:       getEnvAsBool("PAYMENT_RECONCILIATION_ENABLED", true),
+			CheckInterval: time.Duration(getEnvAsInt("PAYMENT_RECONCILIATION_CHECK_INTERVAL_SECONDS", 120)) * time.Second,
+			StuckAfter:    time.Duration(getEnvAsInt("PAYMENT_RECONCILIATION_STUCK_AFTER_SECONDS", 300)) * time.Second,
+			AbandonAfter:  time.Duration(getEnvAsInt("PAYMENT_RECONCILIATION_ABANDON_AFTER_MINUTES", 30)) * time.Minute,
+			BatchSize:     getEnvAsInt("PAYMENT_RECONCILIATION_BATCH_SIZE", 50),
+		},
+		UsageAnalytics: UsageAnalyticsConfig{
+			Enabled:       getEnvAsBool("USAGE_ANALYTICS_ENABLED", true),
+			BufferSize:    getEnvAsInt("USAGE_ANALYTICS_BUFFER_SIZE", 10000),
+			BatchSize:     getEnvAsInt("USAGE_ANALYTICS_BATCH_SIZE", 500),
+			FlushInterval: time.Duration(getEnvAsInt("USAGE_ANALYTICS_FLUSH_INTERVAL_SECONDS", 10)) * time.Second,
+			HashSalt:      getEnv("USAGE_ANALYTICS_HASH_SALT", ""),
+		},
+		OTPTestNumbers: OTPTestNumbersConfig{
+			Enabled:  getEnvAsBool("OTP_TEST_NUMBERS_ENABLED", false),
+			DeployID: getEnv("DEPLOY_ID", ""),
+			Secret:   getEnv("OTP_TEST_NUMBERS_SECRET", ""),
+			HashSalt: getEnv("OTP_TEST_NUMBERS_HASH_SALT", ""),
+		},
+		Events: EventsConfig{
+			Publisher:         getEnv("EVENTS_PUBLISHER", "channel"),
+			BufferSize:        getEnvAsInt("EVENTS_BUFFER_SIZE", 1024),
+			OutboxEnabled:     getEnvAsBool("EVENTS_OUTBOX_ENABLED", true),
+			OutboxInterval:    time.Duration(getEnvAsInt("EVENTS_OUTBOX_CHECK_INTERVAL_SECONDS", 2)) * time.Second,
+			OutboxBatchSize:   getEnvAsInt("EVENTS_OUTBOX_BATCH_SIZE", 100),
+			OutboxMaxAttempts: getEnvAsInt("EVENTS_OUTBOX_MAX_ATTEMPTS", 10),
+		},
+		Redis: RedisConfig{
+			URL:       getEnv("REDIS_URL", ""),
+			KeyPrefix: getEnv("REDIS_KEY_PREFIX", "smarttransit:"),
+		},
+		Search: SearchConfig{
+			CacheEnabled:    getEnvAsBool("SEARCH_CACHE_ENABLED", true),
+			CacheTTL:        time.Duration(getEnvAsInt("SEARCH_CACHE_TTL_SECONDS", 60)) * time.Second,
+			CacheMaxEntries: getEnvAsInt("SEARCH_CACHE_MAX_ENTRIES", 5000),
+		},
+		Payout: PayoutConfig{
+			Enabled:           getEnvAsBool("PAYOUT_ENABLED", true),
+			Cycle:             getEnv("PAYOUT_CYCLE", "weekly"),
+			CommissionPercent: getEnvAsInt("PAYOUT_COMMISSION_PERCENT", 0),
+			CheckInterval:     time.Duration(getEnvAsInt("PAYOUT_CHECK_INTERVAL_SECONDS", 3600)) * time.Second,
+		},
+		AdminJobs: AdminJobsConfig{
+			Enabled:       getEnvAsBool("ADMIN_JOBS_ENABLED", true),
+			CheckInterval: time.Duration(getEnvAsInt("ADMIN_JOBS_CHECK_INTERVAL_SECONDS", 5)) * time.Second,
+			MaxItems:      getEnvAsInt("ADMIN_JOBS_MAX_ITEMS", 5000),
+			MaxExportRows: getEnvAsInt("ADMIN_JOBS_MAX_EXPORT_ROWS", 200000),
+			StaleAfter:    time.Duration(getEnvAsInt("ADMIN_JOBS_STALE_MINUTES", 15)) * time.Minute,
+		},
+		Routing: RoutingConfig{
+			Engine:             getEnv("ROUTING_ENGINE", "osrm"),
+			OSRMBaseURL:        getEnv("OSRM_BASE_URL", ""),
+			OSRMProfile:        getEnv("OSRM_PROFILE", "driving"),
+			HTTP:               getEnvAsHTTPClientConfig("OSRM", "osrm"),
+			GoogleAPIKey:       getEnv("GOOGLE_DIRECTIONS_API_KEY", ""),
+			GoogleHTTP:         getEnvAsHTTPClientConfig("GOOGLE_DIRECTIONS", "google_directions"),
+			SegmentCacheTTL:    time.Duration(getEnvAsInt("ROUTING_SEGMENT_CACHE_DAYS", 30)) * 24 * time.Hour,
+			BusDurationPercent: getEnvAsInt("ROUTING_BUS_DURATION_PERCENT", 130),
+		},
+		App: AppConfig{
+			MinVersionAndroid:    getEnv("APP_MIN_VERSION_ANDROID", ""),
+			MinVersionIOS:        getEnv("APP_MIN_VERSION_IOS", ""),
+			LatestVersionAndroid: getEnv("APP_LATEST_VERSION_ANDROID", ""),
+			LatestVersionIOS:     getEnv("APP_LATEST_VERSION_IOS", ""),
+			StoreURLAndroid:      getEnv("APP_STORE_URL_ANDROID", ""),
+			StoreURLIOS:          getEnv("APP_STORE_URL_IOS", ""),
+			CacheTTL:             time.Duration(getEnvAsInt("APP_CONFIG_CACHE_SECONDS", 30)) * time.Second,
+		},
+		GeoIP: GeoIPConfig{
+			CityDBPath: getEnv("GEOIP_CITY_DB_PATH", ""),
+			ASNDBPath:  getEnv("GEOIP_ASN_DB_PATH", ""),
 		},
 	}
+	if config.Retention.HashSalt == "" {
+		config.Retention.HashSalt = config.JWT.Secret
+	}
+	if config.UsageAnalytics.HashSalt == "" {
+		config.UsageAnalytics.HashSalt = config.JWT.Secret
+	}
+	if config.OTPTestNumbers.Secret == "" {
+		config.OTPTestNumbers.Secret = config.JWT.Secret
+	}
+	if config.OTPTestNumbers.HashSalt == "" {
+		config.OTPTestNumbers.HashSalt = config.JWT.Secret
+	}
 
 	// Validate required configuration
 	if err := config.Validate(); err != nil {
@@ -235,6 +1035,26 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	switch c.Booking.PriceDriftPolicy {
+	case "honor_snapshot", "require_reaccept":
+	default:
+		return fmt.Errorf("invalid INTENT_PRICE_DRIFT_POLICY: %s (must be 'honor_snapshot' or 'require_reaccept')", c.Booking.PriceDriftPolicy)
+	}
+
+	switch c.StaffPrivacy.ContactAccess {
+	case "reveal", "none":
+	case "relay":
+		if c.StaffPrivacy.RelayNumber == "" {
+			return fmt.Errorf("STAFF_CONTACT_RELAY_NUMBER is required when STAFF_CONTACT_ACCESS is relay")
+		}
+	default:
+		return fmt.Errorf("invalid STAFF_CONTACT_ACCESS: %s (must be 'reveal', 'relay' or 'none')", c.StaffPrivacy.ContactAccess)
+	}
+
+	if c.LiveTracking.HeartbeatInterval <= 0 {
+		return fmt.Errorf("LIVE_TRACKING_HEARTBEAT_SECONDS must be positive")
+	}
+
 	return nil
 }
 
@@ -293,6 +1113,40 @@ func getEnvAsSlice(key string, defaultValue []string) []string {
 	return result
 }
 
+func getEnvAsDurationSlice(key string, defaultValue []time.Duration) []time.Duration {
+	values := getEnvAsSlice(key, nil)
+	if len(values) == 0 {
+		return defaultValue
+	}
+	var result []time.Duration
+	for _, v := range values {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Printf("Invalid duration value %q for %s, skipping", v, key)
+			continue
+		}
+		result = append(result, d)
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
+// getEnvAsHTTPClientConfig reads <PREFIX>_HTTP_* resilience settings for an external gateway
+func getEnvAsHTTPClientConfig(prefix, name string) httpclient.Config {
+	d := httpclient.DefaultConfig(name)
+	return httpclient.Config{
+		Name:             name,
+		Timeout:          time.Duration(getEnvAsInt(prefix+"_HTTP_TIMEOUT_MS", int(d.Timeout/time.Millisecond))) * time.Millisecond,
+		MaxRetries:       getEnvAsInt(prefix+"_HTTP_MAX_RETRIES", d.MaxRetries),
+		RetryBaseDelay:   time.Duration(getEnvAsInt(prefix+"_HTTP_RETRY_BASE_MS", int(d.RetryBaseDelay/time.Millisecond))) * time.Millisecond,
+		RetryMaxDelay:    time.Duration(getEnvAsInt(prefix+"_HTTP_RETRY_MAX_MS", int(d.RetryMaxDelay/time.Millisecond))) * time.Millisecond,
+		FailureThreshold: getEnvAsInt(prefix+"_BREAKER_FAILURE_THRESHOLD", d.FailureThreshold),
+		OpenDuration:     time.Duration(getEnvAsInt(prefix+"_BREAKER_OPEN_SECONDS", int(d.OpenDuration/time.Second))) * time.Second,
+	}
+}
+
 // Helper to split strings
 func splitString(s, sep string) []string {
 	var result []string
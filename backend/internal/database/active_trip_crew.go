@@ -0,0 +1,105 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// ============================================================================
+// CREW SESSIONS
+// ============================================================================
+
+// TouchCrewSession records that a crew member's device is on the trip, reopening a session that was left
+func (r *ActiveTripRepository) TouchCrewSession(activeTripID, staffID string, role models.ActiveTripCrewRole) error {
+	query := `
+		INSERT INTO active_trip_sessions (active_trip_id, staff_id, role, last_seen_at, left_at)
+		VALUES ($1, $2, $3, NOW(), NULL)
+		ON CONFLICT (active_trip_id, staff_id)
+		DO UPDATE SET role = EXCLUDED.role, last_seen_at = NOW(), left_at = NULL
+	`
+	if _, err := r.db.Exec(query, activeTripID, staffID, role); err != nil {
+		return fmt.Errorf("failed to touch crew session: %w", err)
+	}
+	return nil
+}
+
+// GetCrewSessions returns the sessions of crew members still on the trip
+func (r *ActiveTripRepository) GetCrewSessions(activeTripID string) ([]models.ActiveTripCrewSession, error) {
+	query := `
+		SELECT active_trip_id, staff_id, role, last_seen_at, left_at
+		FROM active_trip_sessions
+		WHERE active_trip_id = $1 AND left_at IS NULL
+		ORDER BY role
+	`
+	sessions := []models.ActiveTripCrewSession{}
+	if err := r.db.Select(&sessions, query, activeTripID); err != nil {
+		return nil, fmt.Errorf("failed to get crew sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// EndCrewSession marks a crew member's session as left, e.g. after they handed over their seat
+func (r *ActiveTripRepository) EndCrewSession(activeTripID, staffID string) error {
+	query := `
+		UPDATE active_trip_sessions
+		SET left_at = NOW()
+		WHERE active_trip_id = $1 AND staff_id = $2 AND left_at IS NULL
+	`
+	if _, err := r.db.Exec(query, activeTripID, staffID); err != nil {
+		return fmt.Errorf("failed to end crew session: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// HANDOVER
+// ============================================================================
+
+// HandOverCrew moves a seat on a running trip to another staff member. The active trip, the
+// scheduled trip's assignment and the handover history are written in one statement so they
+// can't disagree. Returns sql.ErrNoRows if the trip is no longer active.
+func (r *ActiveTripRepository) HandOverCrew(handover *models.ActiveTripHandover) error {
+	if handover.ID == "" {
+		handover.ID = uuid.New().String()
+	}
+
+	query := `
+		WITH updated AS (
+			UPDATE active_trips
+			SET driver_id = CASE WHEN $2::text = 'driver' THEN $3::uuid ELSE driver_id END,
+				conductor_id = CASE WHEN $2::text = 'conductor' THEN $3::uuid ELSE conductor_id END,
+				updated_at = NOW()
+			WHERE id = $1 AND status IN ('not_started', 'in_transit', 'at_stop')
+			RETURNING id, scheduled_trip_id
+		), reassigned AS (
+			UPDATE scheduled_trips st
+			SET assigned_driver_id = CASE WHEN $2::text = 'driver' THEN $3::uuid ELSE st.assigned_driver_id END,
+				assigned_conductor_id = CASE WHEN $2::text = 'conductor' THEN $3::uuid ELSE st.assigned_conductor_id END,
+				updated_at = NOW()
+			FROM updated u
+			WHERE st.id = u.scheduled_trip_id
+		)
+		INSERT INTO active_trip_handovers (
+			id, active_trip_id, role, from_staff_id, to_staff_id, handed_over_by, reason, latitude, longitude
+		)
+		SELECT $4, u.id, $2::text, $5::uuid, $3::uuid, $6, $7, $8, $9
+		FROM updated u
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		handover.ActiveTripID, handover.Role, handover.ToStaffID, handover.ID, handover.FromStaffID,
+		handover.HandedOverBy, handover.Reason, handover.Latitude, handover.Longitude,
+	).Scan(&handover.CreatedAt)
+	if err == sql.ErrNoRows {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to hand over crew: %w", err)
+	}
+	return nil
+}
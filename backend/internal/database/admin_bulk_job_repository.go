@@ -0,0 +1,148 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// adminBulkJobColumns selects a bulk job without its results file
+const adminBulkJobColumns = `
+	id, job_type, status, params, total_items, processed_items, succeeded_items, failed_items,
+	failures, error_message, result_file_name, created_by_user_id,
+	started_at, completed_at, created_at, updated_at`
+
+// AdminBulkJobRepository handles admin_bulk_jobs
+type AdminBulkJobRepository struct {
+	db *sqlx.DB
+}
+
+// NewAdminBulkJobRepository creates a new AdminBulkJobRepository
+func NewAdminBulkJobRepository(db *sqlx.DB) *AdminBulkJobRepository {
+	return &AdminBulkJobRepository{db: db}
+}
+
+// Create inserts a queued job
+func (r *AdminBulkJobRepository) Create(job *models.AdminBulkJob) error {
+	query := `
+		INSERT INTO admin_bulk_jobs (job_type, status, params, failures, created_by_user_id)
+		VALUES ($1, $2, $3, '[]', $4)
+		RETURNING id, created_at, updated_at`
+
+	job.Status = models.AdminBulkJobQueued
+	err := r.db.QueryRow(query, job.JobType, job.Status, job.Params, job.CreatedByUserID).
+		Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create bulk job: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a job; returns nil if it does not exist
+func (r *AdminBulkJobRepository) GetByID(id uuid.UUID) (*models.AdminBulkJob, error) {
+	var job models.AdminBulkJob
+	err := r.db.Get(&job, `SELECT `+adminBulkJobColumns+` FROM admin_bulk_jobs WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk job: %w", err)
+	}
+	return &job, nil
+}
+
+// List returns jobs newest first, optionally filtered by type and status
+func (r *AdminBulkJobRepository) List(jobType, status string, limit, offset int) ([]models.AdminBulkJob, int, error) {
+	where := `WHERE ($1 = '' OR job_type = $1) AND ($2 = '' OR status = $2)`
+
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM admin_bulk_jobs `+where, jobType, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bulk jobs: %w", err)
+	}
+
+	jobs := []models.AdminBulkJob{}
+	query := `SELECT ` + adminBulkJobColumns + ` FROM admin_bulk_jobs ` + where + `
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+	if err := r.db.Select(&jobs, query, jobType, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list bulk jobs: %w", err)
+	}
+	return jobs, total, nil
+}
+
+// ClaimNext marks the oldest queued job as running and returns it; returns nil if there is
+// none. A running job not updated for staleAfter is claimed again, as its worker has died.
+// SKIP LOCKED lets several instances claim jobs without taking the same one.
+func (r *AdminBulkJobRepository) ClaimNext(staleAfter time.Duration) (*models.AdminBulkJob, error) {
+	query := `
+		UPDATE admin_bulk_jobs
+		SET status = 'running', started_at = COALESCE(started_at, NOW()), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM admin_bulk_jobs
+			WHERE status = 'queued'
+			   OR (status = 'running' AND updated_at < NOW() - make_interval(secs => $1))
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + adminBulkJobColumns
+
+	var job models.AdminBulkJob
+	err := r.db.Get(&job, query, staleAfter.Seconds())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim bulk job: %w", err)
+	}
+	return &job, nil
+}
+
+// UpdateProgress records how far a running job has got; this also keeps it from being
+// treated as stale
+func (r *AdminBulkJobRepository) UpdateProgress(job *models.AdminBulkJob) error {
+	_, err := r.db.Exec(`
+		UPDATE admin_bulk_jobs
+		SET total_items = $2, processed_items = $3, succeeded_items = $4, failed_items = $5,
+		    failures = $6, updated_at = NOW()
+		WHERE id = $1 AND status = 'running'`,
+		job.ID, job.TotalItems, job.ProcessedItems, job.SucceededItems, job.FailedItems, job.Failures)
+	if err != nil {
+		return fmt.Errorf("failed to update bulk job progress: %w", err)
+	}
+	return nil
+}
+
+// Finish stores the job's final counts and results file and marks it completed or failed
+func (r *AdminBulkJobRepository) Finish(job *models.AdminBulkJob, resultFile []byte) error {
+	_, err := r.db.Exec(`
+		UPDATE admin_bulk_jobs
+		SET status = $2, total_items = $3, processed_items = $4, succeeded_items = $5,
+		    failed_items = $6, failures = $7, error_message = $8,
+		    result_file_name = $9, result_file = $10,
+		    completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1`,
+		job.ID, job.Status, job.TotalItems, job.ProcessedItems, job.SucceededItems,
+		job.FailedItems, job.Failures, job.ErrorMessage, job.ResultFileName, resultFile)
+	if err != nil {
+		return fmt.Errorf("failed to finish bulk job: %w", err)
+	}
+	return nil
+}
+
+// GetResultFile returns a finished job's results file; returns nil if it has none
+func (r *AdminBulkJobRepository) GetResultFile(id uuid.UUID) ([]byte, error) {
+	var data []byte
+	err := r.db.Get(&data, `SELECT result_file FROM admin_bulk_jobs WHERE id = $1 AND result_file IS NOT NULL`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk job results: %w", err)
+	}
+	return data, nil
+}
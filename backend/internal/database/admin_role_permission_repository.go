@@ -0,0 +1,54 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// AdminRolePermissionRepository stores the permissions each admin role grants. A role with no
+// rows grants its built-in defaults.
+type AdminRolePermissionRepository struct {
+	db *sqlx.DB
+}
+
+// NewAdminRolePermissionRepository creates a new AdminRolePermissionRepository
+func NewAdminRolePermissionRepository(db *sqlx.DB) *AdminRolePermissionRepository {
+	return &AdminRolePermissionRepository{db: db}
+}
+
+// List returns every role permission row
+func (r *AdminRolePermissionRepository) List() ([]models.AdminRolePermission, error) {
+	var rows []models.AdminRolePermission
+	err := r.db.Select(&rows, `
+		SELECT role, permission, updated_by, updated_at
+		FROM admin_role_permissions
+		ORDER BY role, permission`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin role permissions: %w", err)
+	}
+	return rows, nil
+}
+
+// ReplaceRole sets the permissions role grants, replacing the ones it had
+func (r *AdminRolePermissionRepository) ReplaceRole(role models.AdminRole, permissions []models.AdminPermission, updatedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM admin_role_permissions WHERE role = $1`, role); err != nil {
+		return fmt.Errorf("failed to clear admin role permissions: %w", err)
+	}
+	for _, permission := range permissions {
+		if _, err := tx.Exec(`
+			INSERT INTO admin_role_permissions (role, permission, updated_by, updated_at)
+			VALUES ($1, $2, $3, NOW())`, role, permission, updatedBy); err != nil {
+			return fmt.Errorf("failed to add admin role permission: %w", err)
+		}
+	}
+	return tx.Commit()
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +11,9 @@ import (
 	"github.com/smarttransit/sms-auth-backend/internal/models"
 )
 
+// ErrAdminUserNotFound is returned when no admin user matches
+var ErrAdminUserNotFound = errors.New("admin user not found")
+
 // AdminUserRepository handles admin user database operations
 type AdminUserRepository struct {
 	db DB
@@ -23,7 +27,7 @@ func NewAdminUserRepository(db DB) *AdminUserRepository {
 // GetByEmail retrieves an admin user by email
 func (r *AdminUserRepository) GetByEmail(ctx context.Context, email string) (*models.AdminUser, error) {
 	query := `
-		SELECT id, email, password_hash, full_name, is_active, last_login_at,
+		SELECT id, email, password_hash, full_name, COALESCE(role, 'support') AS role, is_active, last_login_at,
 		       created_at, updated_at, created_by
 		FROM admin_users
 		WHERE email = $1
@@ -31,12 +35,12 @@ func (r *AdminUserRepository) GetByEmail(ctx context.Context, email string) (*mo
 
 	var admin models.AdminUser
 	err := r.db.QueryRow(query, email).Scan(
-		&admin.ID, &admin.Email, &admin.PasswordHash, &admin.FullName, &admin.IsActive,
+		&admin.ID, &admin.Email, &admin.PasswordHash, &admin.FullName, &admin.Role, &admin.IsActive,
 		&admin.LastLoginAt, &admin.CreatedAt, &admin.UpdatedAt, &admin.CreatedBy,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("admin user not found")
+			return nil, ErrAdminUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get admin user: %w", err)
 	}
@@ -47,7 +51,7 @@ func (r *AdminUserRepository) GetByEmail(ctx context.Context, email string) (*mo
 // GetByID retrieves an admin user by ID
 func (r *AdminUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AdminUser, error) {
 	query := `
-		SELECT id, email, password_hash, full_name, is_active, last_login_at,
+		SELECT id, email, password_hash, full_name, COALESCE(role, 'support') AS role, is_active, last_login_at,
 		       created_at, updated_at, created_by
 		FROM admin_users
 		WHERE id = $1
@@ -55,12 +59,12 @@ func (r *AdminUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 
 	var admin models.AdminUser
 	err := r.db.QueryRow(query, id).Scan(
-		&admin.ID, &admin.Email, &admin.PasswordHash, &admin.FullName, &admin.IsActive,
+		&admin.ID, &admin.Email, &admin.PasswordHash, &admin.FullName, &admin.Role, &admin.IsActive,
 		&admin.LastLoginAt, &admin.CreatedAt, &admin.UpdatedAt, &admin.CreatedBy,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("admin user not found")
+			return nil, ErrAdminUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get admin user: %w", err)
 	}
@@ -76,8 +80,8 @@ func (r *AdminUserRepository) Create(ctx context.Context, admin *models.AdminUse
 	}
 
 	query := `
-		INSERT INTO admin_users (id, email, password_hash, full_name, is_active, created_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		INSERT INTO admin_users (id, email, password_hash, full_name, role, is_active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
 		RETURNING created_at, updated_at
 	`
 
@@ -86,6 +90,7 @@ func (r *AdminUserRepository) Create(ctx context.Context, admin *models.AdminUse
 		admin.Email,
 		admin.PasswordHash,
 		admin.FullName,
+		admin.Role,
 		admin.IsActive,
 		admin.CreatedBy,
 	).Scan(&admin.CreatedAt, &admin.UpdatedAt)
@@ -133,7 +138,7 @@ func (r *AdminUserRepository) UpdatePassword(ctx context.Context, id uuid.UUID,
 // List retrieves all admin users
 func (r *AdminUserRepository) List(ctx context.Context) ([]*models.AdminUser, error) {
 	query := `
-		SELECT id, email, password_hash, full_name, is_active, last_login_at,
+		SELECT id, email, password_hash, full_name, COALESCE(role, 'support') AS role, is_active, last_login_at,
 		       created_at, updated_at, created_by
 		FROM admin_users
 		ORDER BY created_at DESC
@@ -148,6 +153,25 @@ func (r *AdminUserRepository) List(ctx context.Context) ([]*models.AdminUser, er
 	return admins, nil
 }
 
+// UpdateRole changes the admin user's role
+func (r *AdminUserRepository) UpdateRole(ctx context.Context, id uuid.UUID, role models.AdminRole) error {
+	query := `
+		UPDATE admin_users
+		SET role = $1, updated_at = $2
+		WHERE id = $3
+	`
+
+	result, err := r.db.Exec(query, role, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrAdminUserNotFound
+	}
+
+	return nil
+}
+
 // UpdateActiveStatus updates the active status of an admin user
 func (r *AdminUserRepository) UpdateActiveStatus(ctx context.Context, id uuid.UUID, isActive bool) error {
 	query := `
@@ -2,16 +2,23 @@ package database
 
 import (
 	"crypto/rand"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
 )
 
+// ErrSeatsNotCancellable means a seat to cancel was cancelled, checked in or boarded meanwhile
+var ErrSeatsNotCancellable = errors.New("a seat has already been cancelled, checked in or boarded")
+
 // AppBookingRepository handles booking database operations
 type AppBookingRepository struct {
 	db *sqlx.DB
@@ -158,28 +165,41 @@ func (r *AppBookingRepository) CreateBooking(
 	now := time.Now()
 	busBooking.QRGeneratedAt = &now
 
-	// 4. Insert bus booking (normalized - no duplicate columns)
+	// 4. Insert bus booking (normalized - no duplicate columns). The stops' order is kept so the
+	// seats can be sold again on the route the booking does not cover.
 	busBooking.BookingID = booking.ID
 	busBookingQuery := `
 		INSERT INTO bus_bookings (
 			booking_id, scheduled_trip_id,
 			boarding_stop_id, alighting_stop_id,
+			boarding_stop_order, alighting_stop_order,
 			number_of_seats, fare_per_seat, total_fare,
 			status, qr_code_data, qr_generated_at, special_requests
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
-		) RETURNING id, created_at, updated_at`
+			$1, $2, $3, $4,
+			(SELECT stop_order FROM master_route_stops WHERE id = $3),
+			(SELECT stop_order FROM master_route_stops WHERE id = $4),
+			$5, $6, $7, $8, $9, $10, $11
+		) RETURNING id, boarding_stop_order, alighting_stop_order, created_at, updated_at`
 
 	err = tx.QueryRowx(busBookingQuery,
 		busBooking.BookingID, busBooking.ScheduledTripID,
 		busBooking.BoardingStopID, busBooking.AlightingStopID,
 		busBooking.NumberOfSeats, busBooking.FarePerSeat, busBooking.TotalFare,
 		busBooking.Status, busBooking.QRCodeData, busBooking.QRGeneratedAt, busBooking.SpecialRequests,
-	).Scan(&busBooking.ID, &busBooking.CreatedAt, &busBooking.UpdatedAt)
+	).Scan(&busBooking.ID, &busBooking.BoardingStopOrder, &busBooking.AlightingStopOrder, &busBooking.CreatedAt, &busBooking.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bus booking: %w", err)
 	}
 
+	// The booking belongs to the tenant that runs the trip
+	if _, err := tx.Exec(`
+		UPDATE bookings SET tenant_id = st.tenant_id
+		FROM scheduled_trips st
+		WHERE bookings.id = $1 AND st.id = $2`, booking.ID, busBooking.ScheduledTripID); err != nil {
+		return nil, fmt.Errorf("failed to set booking tenant: %w", err)
+	}
+
 	// 5. Insert bus booking seats (normalized - seat info comes from trip_seats) and update trip_seats
 	createdSeats := make([]models.BusBookingSeat, 0, len(seats))
 	for i := range seats {
@@ -224,6 +244,10 @@ func (r *AppBookingRepository) CreateBooking(
 		createdSeats = append(createdSeats, seats[i])
 	}
 
+	if err := syncTripSeatCounters(tx, busBooking.ScheduledTripID); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -248,8 +272,10 @@ func (r *AppBookingRepository) GetBookingByID(bookingID string) (*models.MasterB
 		       payment_status, payment_method, payment_reference, payment_gateway, paid_at,
 		       booking_status, passenger_name, passenger_phone, passenger_email,
 		       confirmed_at, cancelled_at, cancellation_reason, cancelled_by_user_id,
-		       completed_at, refund_amount, refund_reference, refunded_at,
-		       booking_source, device_info, notes, created_at, updated_at
+		       completed_at, refund_amount, refund_reference, refunded_at, disputed_at,
+		       booking_source, device_info, notes, snapshot, created_at, updated_at,
+		       (SELECT COALESCE(SUM(bsc.paid_amount), 0) FROM booking_seat_cancellations bsc
+		        WHERE bsc.booking_id = bookings.id) AS cancelled_seats_amount
 		FROM bookings WHERE id = $1`
 
 	err := r.db.Get(booking, query, bookingID)
@@ -283,8 +309,10 @@ func (r *AppBookingRepository) GetBookingByReference(reference string) (*models.
 		       payment_status, payment_method, payment_reference, payment_gateway, paid_at,
 		       booking_status, passenger_name, passenger_phone, passenger_email,
 		       confirmed_at, cancelled_at, cancellation_reason, cancelled_by_user_id,
-		       completed_at, refund_amount, refund_reference, refunded_at,
-		       booking_source, device_info, notes, created_at, updated_at
+		       completed_at, refund_amount, refund_reference, refunded_at, disputed_at,
+		       booking_source, device_info, notes, snapshot, created_at, updated_at,
+		       (SELECT COALESCE(SUM(bsc.paid_amount), 0) FROM booking_seat_cancellations bsc
+		        WHERE bsc.booking_id = bookings.id) AS cancelled_seats_amount
 		FROM bookings WHERE booking_reference = $1`
 
 	err := r.db.Get(booking, query, reference)
@@ -307,8 +335,8 @@ func (r *AppBookingRepository) GetBookingByReference(reference string) (*models.
 	return booking, nil
 }
 
-// GetBookingsByUserID retrieves all bookings for a user
-func (r *AppBookingRepository) GetBookingsByUserID(userID string, limit, offset int) ([]models.BookingListItem, error) {
+// GetBookingsByUserID retrieves a user's bookings made in a tenant's app (tenantID nil = SmartTransit)
+func (r *AppBookingRepository) GetBookingsByUserID(userID string, tenantID *string, limit, offset int) ([]models.BookingListItem, error) {
 	query := `
 		SELECT 
 			b.id, b.booking_reference, b.booking_type,
@@ -323,16 +351,17 @@ func (r *AppBookingRepository) GetBookingsByUserID(userID string, limit, offset
 		LEFT JOIN scheduled_trips st ON st.id = bb.scheduled_trip_id
 		LEFT JOIN bus_owner_routes bor ON bor.id = st.bus_owner_route_id
 		WHERE b.user_id = $1
+		  AND b.tenant_id IS NOT DISTINCT FROM $4::uuid
 		ORDER BY b.created_at DESC
 		LIMIT $2 OFFSET $3`
 
 	var bookings []models.BookingListItem
-	err := r.db.Select(&bookings, query, userID, limit, offset)
+	err := r.db.Select(&bookings, query, userID, limit, offset, tenantID)
 	return bookings, err
 }
 
-// GetUpcomingBookingsByUserID retrieves upcoming bookings for a user
-func (r *AppBookingRepository) GetUpcomingBookingsByUserID(userID string) ([]models.BookingListItem, error) {
+// GetUpcomingBookingsByUserID retrieves a user's upcoming bookings in a tenant's app (tenantID nil = SmartTransit)
+func (r *AppBookingRepository) GetUpcomingBookingsByUserID(userID string, tenantID *string) ([]models.BookingListItem, error) {
 	query := `
 		SELECT 
 			b.id, b.booking_reference, b.booking_type,
@@ -347,12 +376,13 @@ func (r *AppBookingRepository) GetUpcomingBookingsByUserID(userID string) ([]mod
 		LEFT JOIN scheduled_trips st ON st.id = bb.scheduled_trip_id
 		LEFT JOIN bus_owner_routes bor ON bor.id = st.bus_owner_route_id
 		WHERE b.user_id = $1
+		  AND b.tenant_id IS NOT DISTINCT FROM $2::uuid
 		  AND b.booking_status IN ('pending', 'confirmed', 'in_progress')
 		  AND (st.departure_datetime IS NULL OR st.departure_datetime >= NOW())
 		ORDER BY st.departure_datetime ASC`
 
 	var bookings []models.BookingListItem
-	err := r.db.Select(&bookings, query, userID)
+	err := r.db.Select(&bookings, query, userID, tenantID)
 	return bookings, err
 }
 
@@ -378,8 +408,9 @@ func (r *AppBookingRepository) UpdatePaymentStatus(
 	return err
 }
 
-// CancelBooking cancels a booking and releases seats
-func (r *AppBookingRepository) CancelBooking(bookingID, userID string, reason *string) error {
+// CancelBooking cancels a booking and releases seats, saving the booking.cancelled event (if
+// given) to the outbox in the same transaction
+func (r *AppBookingRepository) CancelBooking(bookingID, userID string, reason *string, event *models.OutboxEvent) error {
 	tx, err := r.db.Beginx()
 	if err != nil {
 		return err
@@ -419,7 +450,8 @@ func (r *AppBookingRepository) CancelBooking(bookingID, userID string, reason *s
 		SET status = 'cancelled',
 		    cancelled_at = NOW(),
 		    updated_at = NOW()
-		WHERE bus_booking_id IN (SELECT id FROM bus_bookings WHERE booking_id = $1)`,
+		WHERE bus_booking_id IN (SELECT id FROM bus_bookings WHERE booking_id = $1)
+		  AND status <> 'cancelled'`,
 		bookingID)
 	if err != nil {
 		return fmt.Errorf("failed to cancel seat bookings: %w", err)
@@ -442,6 +474,19 @@ func (r *AppBookingRepository) CancelBooking(bookingID, userID string, reason *s
 		return fmt.Errorf("failed to release trip seats: %w", err)
 	}
 
+	tripIDs, err := tripIDsForBooking(tx, bookingID)
+	if err != nil {
+		return err
+	}
+	if err := syncTripSeatCounters(tx, tripIDs...); err != nil {
+		return err
+	}
+
+	if event != nil {
+		if err := insertOutboxEvent(tx, event); err != nil {
+			return err
+		}
+	}
 	return tx.Commit()
 }
 
@@ -648,6 +693,38 @@ func (r *AppBookingRepository) GetBusBookingsByTripID(tripID string) ([]models.B
 	return bookings, err
 }
 
+// GetActiveTripBookingsForPassenger returns the confirmed bookings on a trip made by the user,
+// or made for a passenger whose phone matches phoneKey (see models.PhoneMatchKey). Pending,
+// cancelled and no-show bookings are left out.
+func (r *AppBookingRepository) GetActiveTripBookingsForPassenger(tripID, userID, phoneKey string) ([]models.ExistingTripBooking, error) {
+	query := `
+		SELECT b.id AS booking_id, b.booking_reference, bb.status, bb.created_at AS booked_at,
+		       b.user_id = $2 AS same_user,
+		       COALESCE(array_agg(bbs.trip_seat_id::text) FILTER (WHERE bbs.trip_seat_id IS NOT NULL), '{}') AS trip_seat_ids,
+		       COALESCE(array_agg(ts.seat_number) FILTER (WHERE ts.seat_number IS NOT NULL), '{}') AS seat_numbers
+		FROM bus_bookings bb
+		JOIN bookings b ON b.id = bb.booking_id
+		LEFT JOIN bus_booking_seats bbs ON bbs.bus_booking_id = bb.id AND bbs.status != 'cancelled'
+		LEFT JOIN trip_seats ts ON ts.id = bbs.trip_seat_id
+		WHERE bb.scheduled_trip_id = $1
+		  AND bb.status NOT IN ('pending', 'cancelled', 'no_show')
+		  AND (b.user_id = $2 OR ($3 != '' AND (
+		        RIGHT(regexp_replace(b.passenger_phone, '[^0-9]', '', 'g'), 9) = $3
+		        OR EXISTS (
+		            SELECT 1 FROM bus_booking_seats p
+		            WHERE p.bus_booking_id = bb.id AND p.status != 'cancelled'
+		              AND RIGHT(regexp_replace(p.passenger_phone, '[^0-9]', '', 'g'), 9) = $3
+		        ))))
+		GROUP BY b.id, b.booking_reference, bb.status, bb.created_at, b.user_id
+		ORDER BY bb.created_at`
+
+	bookings := []models.ExistingTripBooking{}
+	if err := r.db.Select(&bookings, query, tripID, userID, phoneKey); err != nil {
+		return nil, fmt.Errorf("failed to get existing trip bookings: %w", err)
+	}
+	return bookings, nil
+}
+
 // populateBusBookingDetails fetches denormalized data via JOINs
 func (r *AppBookingRepository) populateBusBookingDetails(bb *models.BusBooking) {
 	// Get route name, bus info, stop names, departure time
@@ -721,7 +798,7 @@ func (r *AppBookingRepository) GetSeatsByBusBookingID(busBookingID string) ([]mo
 		SELECT bbs.id, bbs.bus_booking_id, bbs.scheduled_trip_id, bbs.trip_seat_id,
 		       bbs.passenger_name, bbs.passenger_phone, bbs.passenger_email,
 		       bbs.passenger_gender, bbs.passenger_nic,
-		       bbs.is_primary_passenger, bbs.status,
+		       bbs.is_primary_passenger, bbs.status, bbs.ticket_code,
 		       bbs.cancelled_at, bbs.created_at, bbs.updated_at,
 		       ts.seat_number, ts.seat_type, ts.seat_price
 		FROM bus_booking_seats bbs
@@ -803,6 +880,143 @@ func (r *AppBookingRepository) CountSeatsByTripID(tripID string) (int, error) {
 	return count, err
 }
 
+// ============================================================================
+// SEAT TICKETS & PARTIAL CANCELLATION
+// ============================================================================
+
+// IssueSeatTickets gives every seat of the bus booking that has none its own ticket code
+// Format: TK-XXXXXXXXXXXXXXXX (16 char hex)
+func (r *AppBookingRepository) IssueSeatTickets(busBookingID string) error {
+	var seatIDs []string
+	err := r.db.Select(&seatIDs, `
+		SELECT id FROM bus_booking_seats WHERE bus_booking_id = $1 AND ticket_code IS NULL`, busBookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get seats without tickets: %w", err)
+	}
+
+	for _, seatID := range seatIDs {
+		randomBytes := make([]byte, 8)
+		if _, err := rand.Read(randomBytes); err != nil {
+			return fmt.Errorf("failed to generate random bytes: %w", err)
+		}
+		code := models.SeatTicketCodePrefix + strings.ToUpper(hex.EncodeToString(randomBytes))
+
+		// A concurrent request may have issued the seat's ticket first; keep that one
+		_, err := r.db.Exec(`
+			UPDATE bus_booking_seats SET ticket_code = $2, updated_at = NOW()
+			WHERE id = $1 AND ticket_code IS NULL`, seatID, code)
+		if err != nil {
+			return fmt.Errorf("failed to issue seat ticket: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetBusBookingBySeatTicket retrieves the bus booking one of whose seats has the ticket code
+func (r *AppBookingRepository) GetBusBookingBySeatTicket(ticketCode string) (*models.BusBooking, error) {
+	var busBookingID string
+	err := r.db.Get(&busBookingID, `SELECT bus_booking_id FROM bus_booking_seats WHERE ticket_code = $1`, ticketCode)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetBusBookingByID(busBookingID)
+}
+
+// CancelSeats cancels some of a booking's seats and releases them, leaving the booking
+// confirmed with the rest. What the seats are refunded is recorded with them, and the
+// booking.seats_cancelled event saved to the outbox, in the same transaction. Fails without
+// changes if any seat has been cancelled, checked in or boarded since it was quoted.
+func (r *AppBookingRepository) CancelSeats(cancellation *models.BookingSeatCancellation, event *models.OutboxEvent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE bus_booking_seats
+		SET status = 'cancelled', cancelled_at = NOW(), updated_at = NOW()
+		WHERE bus_booking_id = $1 AND id = ANY($2) AND status IN ('pending', 'booked')`,
+		cancellation.BusBookingID, pq.Array(cancellation.SeatIDs))
+	if err != nil {
+		return fmt.Errorf("failed to cancel seats: %w", err)
+	}
+	if cancelled, _ := result.RowsAffected(); int(cancelled) != len(cancellation.SeatIDs) {
+		return ErrSeatsNotCancellable
+	}
+
+	_, err = tx.Exec(`
+		UPDATE trip_seats
+		SET status = 'available', booking_type = NULL, bus_booking_seat_id = NULL, updated_at = NOW()
+		WHERE bus_booking_seat_id = ANY($1)`,
+		pq.Array(cancellation.SeatIDs))
+	if err != nil {
+		return fmt.Errorf("failed to release trip seats: %w", err)
+	}
+
+	// What was paid stays on the booking; the cancellation carries the seats' refund
+	var tripID string
+	err = tx.Get(&tripID, `
+		UPDATE bus_bookings
+		SET number_of_seats = number_of_seats - $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING scheduled_trip_id`,
+		cancellation.BusBookingID, len(cancellation.SeatIDs))
+	if err != nil {
+		return fmt.Errorf("failed to update bus booking seats: %w", err)
+	}
+	if err := syncTripSeatCounters(tx, tripID); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO booking_seat_cancellations (
+			id, booking_id, bus_booking_id, seat_ids, paid_amount, fee_percent, cancellation_fee,
+			refundable_amount, currency, reason, cancelled_by_user_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+		RETURNING created_at`,
+		cancellation.ID, cancellation.BookingID, cancellation.BusBookingID, pq.Array(cancellation.SeatIDs),
+		cancellation.PaidAmount, cancellation.FeePercent, cancellation.CancellationFee,
+		cancellation.RefundableAmount, cancellation.Currency, cancellation.Reason, cancellation.CancelledByUserID,
+	).Scan(&cancellation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record seat cancellation: %w", err)
+	}
+
+	if event != nil {
+		if err := insertOutboxEvent(tx, event); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit seat cancellation: %w", err)
+	}
+	return nil
+}
+
+// GetSeatCancellation returns one of a booking's seat cancellations; returns nil if there is
+// no such cancellation of that booking
+func (r *AppBookingRepository) GetSeatCancellation(bookingID string, id uuid.UUID) (*models.BookingSeatCancellation, error) {
+	var row struct {
+		models.BookingSeatCancellation
+		SeatIDs pq.StringArray `db:"seat_ids"`
+	}
+	err := r.db.Get(&row, `
+		SELECT id, booking_id, bus_booking_id, seat_ids, paid_amount, fee_percent, cancellation_fee,
+		       refundable_amount, currency, reason, cancelled_by_user_id, created_at
+		FROM booking_seat_cancellations
+		WHERE id = $1 AND booking_id = $2`, id, bookingID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat cancellation: %w", err)
+	}
+	cancellation := row.BookingSeatCancellation
+	cancellation.SeatIDs = row.SeatIDs
+	return &cancellation, nil
+}
+
 // ============================================================================
 // STAFF OPERATIONS (for conductor/driver app)
 // ============================================================================
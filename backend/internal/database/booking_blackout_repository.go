@@ -0,0 +1,162 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// activeBlackoutForTrip matches the active blackouts covering the scheduled trip aliased st.
+// A blackout covers a trip departing on one of its dates on its schedule, or on its route
+// (the trip's own route override, else its schedule's route).
+const activeBlackoutForTrip = `
+	SELECT bo.id
+	FROM booking_blackouts bo
+	WHERE bo.status = 'active'
+	  AND st.departure_datetime::date BETWEEN bo.start_date AND bo.end_date
+	  AND (
+		bo.trip_schedule_id = st.trip_schedule_id
+		OR bo.bus_owner_route_id = COALESCE(
+			st.bus_owner_route_id,
+			(SELECT bo_ts.bus_owner_route_id FROM trip_schedules bo_ts WHERE bo_ts.id = st.trip_schedule_id)
+		)
+	  )`
+
+// bookingBlackoutColumns selects a booking blackout with its dates as YYYY-MM-DD
+const bookingBlackoutColumns = `
+	id, bus_owner_id, bus_owner_route_id, trip_schedule_id,
+	to_char(start_date, 'YYYY-MM-DD') AS start_date, to_char(end_date, 'YYYY-MM-DD') AS end_date,
+	reason, status, created_by_user_id,
+	overridden_by_user_id, override_reason, overridden_at, lifted_at,
+	created_at, updated_at`
+
+// BookingBlackoutRepository handles booking_blackouts
+type BookingBlackoutRepository struct {
+	db *sqlx.DB
+}
+
+// NewBookingBlackoutRepository creates a new BookingBlackoutRepository
+func NewBookingBlackoutRepository(db *sqlx.DB) *BookingBlackoutRepository {
+	return &BookingBlackoutRepository{db: db}
+}
+
+// Create inserts an active blackout
+func (r *BookingBlackoutRepository) Create(blackout *models.BookingBlackout) error {
+	query := `
+		INSERT INTO booking_blackouts (
+			bus_owner_id, bus_owner_route_id, trip_schedule_id,
+			start_date, end_date, reason, status, created_by_user_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at`
+
+	blackout.Status = models.BookingBlackoutActive
+	err := r.db.QueryRow(query,
+		blackout.BusOwnerID, blackout.BusOwnerRouteID, blackout.TripScheduleID,
+		blackout.StartDate, blackout.EndDate, blackout.Reason, blackout.Status, blackout.CreatedByUserID,
+	).Scan(&blackout.ID, &blackout.CreatedAt, &blackout.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create booking blackout: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a blackout; returns nil if it does not exist
+func (r *BookingBlackoutRepository) GetByID(id string) (*models.BookingBlackout, error) {
+	var blackout models.BookingBlackout
+	err := r.db.Get(&blackout, `SELECT `+bookingBlackoutColumns+` FROM booking_blackouts WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking blackout: %w", err)
+	}
+	return &blackout, nil
+}
+
+// ListByOwner returns an owner's blackouts, newest first. Ended blackouts are only included
+// when includeEnded is set.
+func (r *BookingBlackoutRepository) ListByOwner(busOwnerID string, includeEnded bool) ([]models.BookingBlackout, error) {
+	query := `SELECT ` + bookingBlackoutColumns + `
+		FROM booking_blackouts
+		WHERE bus_owner_id = $1
+		  AND ($2 OR (status = 'active' AND end_date >= CURRENT_DATE))
+		ORDER BY start_date DESC, created_at DESC`
+
+	blackouts := []models.BookingBlackout{}
+	if err := r.db.Select(&blackouts, query, busOwnerID, includeEnded); err != nil {
+		return nil, fmt.Errorf("failed to list booking blackouts: %w", err)
+	}
+	return blackouts, nil
+}
+
+// List returns blackouts across all owners for the admin view, optionally by status
+func (r *BookingBlackoutRepository) List(status string, limit, offset int) ([]models.BookingBlackout, int, error) {
+	where := `WHERE ($1 = '' OR status = $1)`
+
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM booking_blackouts `+where, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count booking blackouts: %w", err)
+	}
+
+	blackouts := []models.BookingBlackout{}
+	query := `SELECT ` + bookingBlackoutColumns + ` FROM booking_blackouts ` + where + `
+		ORDER BY start_date DESC, created_at DESC
+		LIMIT $2 OFFSET $3`
+	if err := r.db.Select(&blackouts, query, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list booking blackouts: %w", err)
+	}
+	return blackouts, total, nil
+}
+
+// Lift ends an owner's active blackout early. Returns false if it is not active.
+func (r *BookingBlackoutRepository) Lift(id, busOwnerID string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE booking_blackouts
+		SET status = 'lifted', lifted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND bus_owner_id = $2 AND status = 'active'`, id, busOwnerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to lift booking blackout: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// Override reopens online sales for an active blackout on an admin's authority. Returns false
+// if it is not active.
+func (r *BookingBlackoutRepository) Override(id, adminUserID, reason string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE booking_blackouts
+		SET status = 'overridden', overridden_by_user_id = $2, override_reason = $3,
+		    overridden_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'active'`, id, adminUserID, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to override booking blackout: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// GetActiveForTrip returns an active blackout covering a scheduled trip; returns nil if sales
+// are open
+func (r *BookingBlackoutRepository) GetActiveForTrip(scheduledTripID string) (*models.BookingBlackout, error) {
+	query := `SELECT ` + bookingBlackoutColumns + `
+		FROM booking_blackouts
+		WHERE id IN (
+			SELECT active.id FROM scheduled_trips st, LATERAL (` + activeBlackoutForTrip + `) active
+			WHERE st.id = $1
+		)
+		ORDER BY created_at
+		LIMIT 1`
+
+	var blackout models.BookingBlackout
+	err := r.db.Get(&blackout, query, scheduledTripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check booking blackouts: %w", err)
+	}
+	return &blackout, nil
+}
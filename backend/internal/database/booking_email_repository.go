@@ -0,0 +1,44 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BookingEmailRepository records the booking emails sent for each outbox event, so an event
+// delivered again does not email the passenger twice
+type BookingEmailRepository struct {
+	db *sqlx.DB
+}
+
+// NewBookingEmailRepository creates a new BookingEmailRepository
+func NewBookingEmailRepository(db *sqlx.DB) *BookingEmailRepository {
+	return &BookingEmailRepository{db: db}
+}
+
+// Claim records that the email of the given type is being sent for the event. Returns false
+// if it already was.
+func (r *BookingEmailRepository) Claim(eventID, emailType, bookingID, recipient string) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO booking_emails (event_id, email_type, booking_id, recipient, sent_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (event_id, email_type) DO NOTHING`, eventID, emailType, bookingID, recipient)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim booking email: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Release removes a claim whose email could not be sent, so the next delivery tries again
+func (r *BookingEmailRepository) Release(eventID, emailType string) error {
+	_, err := r.db.Exec(`DELETE FROM booking_emails WHERE event_id = $1 AND email_type = $2`, eventID, emailType)
+	if err != nil {
+		return fmt.Errorf("failed to release booking email: %w", err)
+	}
+	return nil
+}
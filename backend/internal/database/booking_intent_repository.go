@@ -72,9 +72,10 @@ func (r *BookingIntentRepository) CreateIntent(intent *models.BookingIntent) err
 			bus_intent, pre_trip_lounge_intent, post_trip_lounge_intent,
 			bus_fare, pre_lounge_fare, post_lounge_fare, total_amount, currency,
 			pricing_snapshot, payment_gateway, expires_at,
-			idempotency_key, created_at, updated_at
+			idempotency_key, created_at, updated_at,
+			hold_ttl_seconds, reliability_tier
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
 		)`
 
 	_, err = r.db.Exec(query,
@@ -83,6 +84,7 @@ func (r *BookingIntentRepository) CreateIntent(intent *models.BookingIntent) err
 		intent.BusFare, intent.PreLoungeFare, intent.PostLoungeFare, intent.TotalAmount, intent.Currency,
 		pricingSnapshotJSON, intent.PaymentGateway, intent.ExpiresAt,
 		intent.IdempotencyKey, intent.CreatedAt, intent.UpdatedAt,
+		intent.HoldTTLSeconds, intent.ReliabilityTier,
 	)
 	return err
 }
@@ -101,7 +103,9 @@ func (r *BookingIntentRepository) GetIntentByID(intentID uuid.UUID) (*models.Boo
 			pricing_snapshot, payment_reference, payment_status, payment_gateway,
 			bus_booking_id, pre_lounge_booking_id, post_lounge_booking_id,
 			expires_at, payment_initiated_at, confirmed_at, expired_at,
-			created_at, updated_at, idempotency_key
+			created_at, updated_at, idempotency_key,
+			COALESCE(hold_ttl_seconds, 0), COALESCE(reliability_tier, 'standard'),
+			COALESCE(extension_count, 0), COALESCE(wallet_amount, 0)
 		FROM booking_intents
 		WHERE id = $1`
 
@@ -113,6 +117,8 @@ func (r *BookingIntentRepository) GetIntentByID(intentID uuid.UUID) (*models.Boo
 		&intent.BusBookingID, &intent.PreLoungeBookingID, &intent.PostLoungeBookingID,
 		&intent.ExpiresAt, &intent.PaymentInitiatedAt, &intent.ConfirmedAt, &intent.ExpiredAt,
 		&intent.CreatedAt, &intent.UpdatedAt, &intent.IdempotencyKey,
+		&intent.HoldTTLSeconds, &intent.ReliabilityTier,
+		&intent.ExtensionCount, &intent.WalletAmount,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -210,6 +216,44 @@ func (r *BookingIntentRepository) GetIntentsByUserID(userID uuid.UUID, limit, of
 	return intents, nil
 }
 
+// GetActiveIntentByUserID returns the user's most recent intent that still holds
+// inventory (held or payment_pending and not expired); returns nil if none
+func (r *BookingIntentRepository) GetActiveIntentByUserID(userID uuid.UUID) (*models.BookingIntent, error) {
+	var intentID uuid.UUID
+	query := `
+		SELECT id FROM booking_intents
+		WHERE user_id = $1
+		  AND status IN ('held', 'payment_pending')
+		  AND expires_at > NOW()
+		ORDER BY created_at DESC
+		LIMIT 1`
+	err := r.db.Get(&intentID, query, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetIntentByID(intentID)
+}
+
+// GetUserAbandonmentStats counts a user's abandoned (expired/cancelled) and
+// confirmed intents created since the given time
+func (r *BookingIntentRepository) GetUserAbandonmentStats(userID uuid.UUID, since time.Time) (*models.IntentAbandonmentStats, error) {
+	query := `
+		SELECT 
+			COUNT(*) FILTER (WHERE status IN ('expired', 'cancelled')) AS abandoned,
+			COUNT(*) FILTER (WHERE status = 'confirmed') AS confirmed
+		FROM booking_intents
+		WHERE user_id = $1 AND created_at >= $2`
+
+	var stats models.IntentAbandonmentStats
+	if err := r.db.Get(&stats, query, userID, since); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 // ============================================================================
 // STATUS UPDATE OPERATIONS
 // ============================================================================
@@ -221,6 +265,56 @@ func (r *BookingIntentRepository) UpdateIntentStatus(intentID uuid.UUID, status
 	return err
 }
 
+// ClaimIntentForConfirmation moves a held or payment-pending intent to confirming. It returns
+// false when the intent is in any other status, e.g. because another request claimed it first.
+func (r *BookingIntentRepository) ClaimIntentForConfirmation(intentID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE booking_intents
+		SET status = 'confirming', updated_at = NOW()
+		WHERE id = $1 AND status IN ('held', 'payment_pending')`
+	result, err := r.db.Exec(query, intentID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ReleaseIntentConfirmation returns a confirming intent to status, the one it was claimed from,
+// after its confirmation failed, so the confirmation can be retried
+func (r *BookingIntentRepository) ReleaseIntentConfirmation(intentID uuid.UUID, status models.BookingIntentStatus) error {
+	query := `
+		UPDATE booking_intents
+		SET status = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'confirming'`
+	_, err := r.db.Exec(query, intentID, status)
+	return err
+}
+
+// RecordIntentBookings saves the bookings a confirmation has created so far on its confirming
+// intent, so a retried confirmation reuses them instead of creating them again
+func (r *BookingIntentRepository) RecordIntentBookings(intentID uuid.UUID, busBookingID, preLoungeBookingID, postLoungeBookingID *uuid.UUID) error {
+	query := `
+		UPDATE booking_intents
+		SET bus_booking_id = $2,
+		    pre_lounge_booking_id = $3,
+		    post_lounge_booking_id = $4,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'confirming'`
+	result, err := r.db.Exec(query, intentID, busBookingID, preLoungeBookingID, postLoungeBookingID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("intent not in 'confirming' status or not found")
+	}
+	return nil
+}
+
 // UpdateIntentPaymentPending marks intent as payment pending
 func (r *BookingIntentRepository) UpdateIntentPaymentPending(intentID uuid.UUID, paymentRef string) error {
 	query := `
@@ -275,7 +369,10 @@ func (r *BookingIntentRepository) GetIntentByPaymentUID(uid string) (*models.Boo
 		       payment_uid, payment_status_indicator,
 		       bus_booking_id, pre_lounge_booking_id, post_lounge_booking_id,
 		       expires_at, payment_initiated_at, confirmed_at, expired_at, created_at, updated_at,
-		       idempotency_key, passenger_name, passenger_phone
+		       idempotency_key, passenger_name, passenger_phone,
+		       COALESCE(hold_ttl_seconds, 0) AS hold_ttl_seconds,
+		       COALESCE(reliability_tier, 'standard') AS reliability_tier,
+		       COALESCE(wallet_amount, 0) AS wallet_amount
 		FROM booking_intents 
 		WHERE payment_uid = $1`
 
@@ -290,11 +387,40 @@ func (r *BookingIntentRepository) GetIntentByPaymentUID(uid string) (*models.Boo
 	return &intent, nil
 }
 
-// UpdateIntentConfirmed marks intent as confirmed with booking IDs
+// GetIntentTenantID returns the tenant of the intent's bus trip, whose merchant account took
+// its payment; nil for an intent without a trip or a trip without a tenant
+func (r *BookingIntentRepository) GetIntentTenantID(intentID uuid.UUID) (*string, error) {
+	query := `
+		SELECT st.tenant_id
+		FROM booking_intents bi
+		LEFT JOIN scheduled_trips st ON st.id::text = bi.bus_intent->>'scheduled_trip_id'
+		WHERE bi.id = $1`
+
+	var tenantID *string
+	if err := r.db.Get(&tenantID, query, intentID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return tenantID, nil
+}
+
+// UpdateIntentConfirmed marks intent as confirmed with booking IDs. In the same transaction it
+// confirms the intent's lounge holds, marks its lounge bookings confirmed and paid, and saves
+// the booking.confirmed event (if given) to the outbox, so the event is only ever delivered
+// for a confirmation that was committed.
 func (r *BookingIntentRepository) UpdateIntentConfirmed(
 	intentID uuid.UUID,
 	busBookingID, preLoungeBookingID, postLoungeBookingID *uuid.UUID,
+	event *models.OutboxEvent,
 ) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE booking_intents 
 		SET status = 'confirmed',
@@ -304,7 +430,7 @@ func (r *BookingIntentRepository) UpdateIntentConfirmed(
 		    confirmed_at = NOW(),
 		    updated_at = NOW()
 		WHERE id = $1 AND status IN ('held', 'payment_pending', 'confirming')`
-	result, err := r.db.Exec(query, intentID, busBookingID, preLoungeBookingID, postLoungeBookingID)
+	result, err := tx.Exec(query, intentID, busBookingID, preLoungeBookingID, postLoungeBookingID)
 	if err != nil {
 		return err
 	}
@@ -312,10 +438,36 @@ func (r *BookingIntentRepository) UpdateIntentConfirmed(
 	if rows == 0 {
 		return fmt.Errorf("intent not in valid status for confirmation")
 	}
-	return nil
+
+	if _, err := tx.Exec(`
+		UPDATE lounge_capacity_holds 
+		SET status = 'confirmed'
+		WHERE intent_id = $1 AND status = 'held'`, intentID); err != nil {
+		return fmt.Errorf("failed to confirm lounge holds: %w", err)
+	}
+
+	for _, loungeBookingID := range []*uuid.UUID{preLoungeBookingID, postLoungeBookingID} {
+		if loungeBookingID == nil {
+			continue
+		}
+		if _, err := tx.Exec(`
+			UPDATE lounge_bookings
+			SET status = $2, payment_status = $3, updated_at = NOW()
+			WHERE id = $1`,
+			*loungeBookingID, models.LoungeBookingStatusConfirmed, models.LoungePaymentPaid); err != nil {
+			return fmt.Errorf("failed to confirm lounge booking: %w", err)
+		}
+	}
+
+	if event != nil {
+		if err := insertOutboxEvent(tx, event); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-// UpdateIntentExpired marks intent as expired
+// UpdateIntentExpired marks intent as expired, giving back any promo code use it reserved
 func (r *BookingIntentRepository) UpdateIntentExpired(intentID uuid.UUID) error {
 	query := `
 		UPDATE booking_intents 
@@ -323,19 +475,35 @@ func (r *BookingIntentRepository) UpdateIntentExpired(intentID uuid.UUID) error
 		    expired_at = NOW(),
 		    updated_at = NOW()
 		WHERE id = $1 AND status IN ('held', 'payment_pending')`
-	_, err := r.db.Exec(query, intentID)
-	return err
+	return r.endIntent(query, intentID)
 }
 
-// UpdateIntentCancelled marks intent as cancelled
+// UpdateIntentCancelled marks intent as cancelled, giving back any promo code use it reserved
 func (r *BookingIntentRepository) UpdateIntentCancelled(intentID uuid.UUID) error {
 	query := `
 		UPDATE booking_intents 
 		SET status = 'cancelled',
 		    updated_at = NOW()
 		WHERE id = $1 AND status IN ('held', 'payment_pending')`
-	_, err := r.db.Exec(query, intentID)
-	return err
+	return r.endIntent(query, intentID)
+}
+
+// endIntent runs the status update ending an intent and releases its promo code use in one
+// transaction
+func (r *BookingIntentRepository) endIntent(query string, intentID uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(query, intentID); err != nil {
+		return err
+	}
+	if err := releaseIntentPromoUse(tx, intentID); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // UpdateIntentConfirmationFailed marks intent as confirmation failed (needs refund)
@@ -358,6 +526,7 @@ func (r *BookingIntentRepository) AddLoungeToIntent(
 	postLoungeFare float64,
 	newTotal float64,
 	newExpiresAt time.Time,
+	holdTTLSeconds int,
 ) error {
 	// Convert lounge payloads to JSON - use *string to properly handle JSONB
 	var preLoungeJSON, postLoungeJSON *string
@@ -394,6 +563,7 @@ func (r *BookingIntentRepository) AddLoungeToIntent(
 		    post_lounge_fare = CASE WHEN $6 > 0 THEN $6 ELSE post_lounge_fare END,
 		    total_amount = $7,
 		    expires_at = $8,
+		    hold_ttl_seconds = $9,
 		    updated_at = NOW()
 		WHERE id = $1 AND status = 'held'`
 
@@ -406,6 +576,7 @@ func (r *BookingIntentRepository) AddLoungeToIntent(
 		postLoungeFare,
 		newTotal,
 		newExpiresAt,
+		holdTTLSeconds,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update intent: %w", err)
@@ -419,6 +590,46 @@ func (r *BookingIntentRepository) AddLoungeToIntent(
 	return nil
 }
 
+// UpdateIntentBusPricing re-locks a held intent's bus prices after the passenger accepted a
+// price change
+func (r *BookingIntentRepository) UpdateIntentBusPricing(
+	intentID uuid.UUID,
+	busIntent *models.BusIntentPayload,
+	busFare float64,
+	totalAmount float64,
+	snapshot models.PricingSnapshot,
+) error {
+	busIntentJSON, err := json.Marshal(busIntent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bus_intent: %w", err)
+	}
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pricing_snapshot: %w", err)
+	}
+
+	query := `
+		UPDATE booking_intents
+		SET bus_intent = $2,
+		    bus_fare = $3,
+		    total_amount = $4,
+		    pricing_snapshot = $5,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'held'`
+
+	result, err := r.db.Exec(query, intentID, string(busIntentJSON), busFare, totalAmount, string(snapshotJSON))
+	if err != nil {
+		return fmt.Errorf("failed to update intent pricing: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("intent not found or not in held status")
+	}
+
+	return nil
+}
+
 // ExtendSeatHolds extends the hold time for all seats held by an intent
 func (r *BookingIntentRepository) ExtendSeatHolds(intentID uuid.UUID, newExpiresAt time.Time) error {
 	query := `
@@ -429,6 +640,50 @@ func (r *BookingIntentRepository) ExtendSeatHolds(intentID uuid.UUID, newExpires
 	return err
 }
 
+// ExtendIntentHold moves a live intent's expiry, and its seat and lounge holds, to newExpiresAt
+// and counts the extension, atomically. Returns false if the intent is no longer live or has
+// already used maxExtensions.
+func (r *BookingIntentRepository) ExtendIntentHold(intentID uuid.UUID, newExpiresAt time.Time, maxExtensions int) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE booking_intents
+		SET expires_at = $2, extension_count = COALESCE(extension_count, 0) + 1, updated_at = NOW()
+		WHERE id = $1
+		  AND status IN ('held', 'payment_pending')
+		  AND expires_at > NOW()
+		  AND COALESCE(extension_count, 0) < $3`,
+		intentID, newExpiresAt, maxExtensions)
+	if err != nil {
+		return false, fmt.Errorf("failed to extend intent: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE trip_seats
+		SET held_until = $2, updated_at = NOW()
+		WHERE held_by_intent_id = $1`, intentID, newExpiresAt); err != nil {
+		return false, fmt.Errorf("failed to extend seat holds: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE lounge_capacity_holds
+		SET held_until = $2
+		WHERE intent_id = $1 AND status = 'held'`, intentID, newExpiresAt); err != nil {
+		return false, fmt.Errorf("failed to extend lounge holds: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit intent extension: %w", err)
+	}
+	return true, nil
+}
+
 // ============================================================================
 // SEAT HOLDING OPERATIONS (TTL-based)
 // ============================================================================
@@ -577,16 +832,6 @@ func (r *BookingIntentRepository) ReleaseLoungeHoldsForIntent(intentID uuid.UUID
 	return err
 }
 
-// ConfirmLoungeHoldsForIntent marks lounge holds as confirmed
-func (r *BookingIntentRepository) ConfirmLoungeHoldsForIntent(intentID uuid.UUID) error {
-	query := `
-		UPDATE lounge_capacity_holds 
-		SET status = 'confirmed'
-		WHERE intent_id = $1 AND status = 'held'`
-	_, err := r.db.Exec(query, intentID)
-	return err
-}
-
 // GetLoungeCapacityAvailable calculates available capacity for a lounge at a time
 func (r *BookingIntentRepository) GetLoungeCapacityAvailable(
 	loungeID uuid.UUID,
@@ -645,6 +890,42 @@ func (r *BookingIntentRepository) GetLoungeCapacityAvailable(
 	return available, nil
 }
 
+// ============================================================================
+// CROSS-DEVICE HANDOFF
+// ============================================================================
+
+// CreateIntentHandoff stores a single-use handoff token (by hash) for an intent
+func (r *BookingIntentRepository) CreateIntentHandoff(intentID, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO booking_intent_handoffs (id, intent_id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())`
+	_, err := r.db.Exec(query, uuid.New(), intentID, userID, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create intent handoff: %w", err)
+	}
+	return nil
+}
+
+// ClaimIntentHandoff marks an unexpired, unclaimed handoff token issued to the user as
+// claimed and returns its intent ID; returns nil if there is no such token
+func (r *BookingIntentRepository) ClaimIntentHandoff(tokenHash string, userID uuid.UUID) (*uuid.UUID, error) {
+	query := `
+		UPDATE booking_intent_handoffs
+		SET claimed_at = NOW()
+		WHERE token_hash = $1 AND user_id = $2
+		  AND claimed_at IS NULL AND expires_at > NOW()
+		RETURNING intent_id`
+	var intentID uuid.UUID
+	err := r.db.QueryRow(query, tokenHash, userID).Scan(&intentID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim intent handoff: %w", err)
+	}
+	return &intentID, nil
+}
+
 // ============================================================================
 // TTL EXPIRATION (Background Job Support)
 // ============================================================================
@@ -703,7 +984,48 @@ func (r *BookingIntentRepository) GetPaymentPendingTimedOutIntents(timeout time.
 	return intents, nil
 }
 
-// ExpireIntentAndReleaseHolds atomically expires an intent and releases all its holds
+// GetStuckPaymentIntents returns payment_pending and confirming intents not updated since
+// stuckBefore, oldest first, for payment reconciliation
+func (r *BookingIntentRepository) GetStuckPaymentIntents(stuckBefore time.Time, limit int) ([]*models.StuckPaymentIntent, error) {
+	query := `
+		SELECT bi.id, bi.user_id, bi.status, bi.total_amount,
+		       bi.total_amount - COALESCE(bi.wallet_amount, 0) AS amount_due, bi.currency,
+		       bi.payment_uid, bi.payment_status_indicator, bi.payment_initiated_at, bi.updated_at,
+		       st.tenant_id
+		FROM booking_intents bi
+		LEFT JOIN scheduled_trips st ON st.id::text = bi.bus_intent->>'scheduled_trip_id'
+		WHERE bi.status IN ('payment_pending', 'confirming')
+		  AND bi.updated_at < $1
+		ORDER BY bi.updated_at ASC
+		LIMIT $2`
+
+	var intents []*models.StuckPaymentIntent
+	if err := r.db.Select(&intents, query, stuckBefore, limit); err != nil {
+		return nil, err
+	}
+	return intents, nil
+}
+
+// RequeueStuckConfirmation returns an intent left in confirming since before stuckBefore (e.g.
+// by a crash mid-confirmation) to payment_pending so it can be confirmed again
+func (r *BookingIntentRepository) RequeueStuckConfirmation(intentID uuid.UUID, stuckBefore time.Time) (bool, error) {
+	query := `
+		UPDATE booking_intents
+		SET status = 'payment_pending', updated_at = NOW()
+		WHERE id = $1 AND status = 'confirming' AND updated_at < $2`
+	result, err := r.db.Exec(query, intentID, stuckBefore)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ExpireIntentAndReleaseHolds atomically expires an intent and releases all its holds and its
+// promo code use
 func (r *BookingIntentRepository) ExpireIntentAndReleaseHolds(intentID uuid.UUID) error {
 	tx, err := r.db.Beginx()
 	if err != nil {
@@ -741,6 +1063,11 @@ func (r *BookingIntentRepository) ExpireIntentAndReleaseHolds(intentID uuid.UUID
 		return fmt.Errorf("failed to release lounge holds: %w", err)
 	}
 
+	// 4. Give back any promo code use it reserved
+	if err := releaseIntentPromoUse(tx, intentID); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// BookingReminderRepository handles booking reminder database operations
+type BookingReminderRepository struct {
+	db *sqlx.DB
+}
+
+// NewBookingReminderRepository creates a new BookingReminderRepository
+func NewBookingReminderRepository(db *sqlx.DB) *BookingReminderRepository {
+	return &BookingReminderRepository{db: db}
+}
+
+// dueReminderSelect joins a reminder with booking, trip and stop details needed to deliver it
+const dueReminderSelect = `
+	SELECT
+		r.id, r.booking_id, r.user_id, r.scheduled_trip_id, r.kind, r.offset_minutes,
+		r.send_at, r.status, r.attempts, r.last_error, r.sent_at, r.created_at, r.updated_at,
+		b.booking_reference,
+		b.passenger_name,
+		b.passenger_phone,
+		COALESCE(mr.route_name, bor.custom_route_name, 'your route') as route_name,
+		COALESCE(mrs.stop_name, '') as boarding_stop_name,
+		st.departure_datetime,
+		at.current_latitude as bus_latitude,
+		at.current_longitude as bus_longitude,
+		at.current_speed_kmh as bus_speed_kmh,
+		mrs.latitude as stop_latitude,
+		mrs.longitude as stop_longitude
+	FROM booking_reminders r
+	JOIN bookings b ON r.booking_id = b.id
+	JOIN bus_bookings bb ON bb.booking_id = b.id
+	JOIN scheduled_trips st ON r.scheduled_trip_id = st.id
+	LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+	LEFT JOIN master_routes mr ON bor.master_route_id = mr.id
+	LEFT JOIN master_route_stops mrs ON bb.boarding_stop_id = mrs.id
+	LEFT JOIN active_trips at ON at.scheduled_trip_id = st.id`
+
+// CreateReminders queues reminders for a booking.
+// Reminders that already exist for the same booking, kind and offset are left untouched.
+func (r *BookingReminderRepository) CreateReminders(reminders []*models.BookingReminder) error {
+	if len(reminders) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO booking_reminders (
+			id, booking_id, user_id, scheduled_trip_id, kind, offset_minutes,
+			send_at, status, attempts, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 0, NOW(), NOW())
+		ON CONFLICT (booking_id, kind, offset_minutes) DO NOTHING`
+
+	for _, reminder := range reminders {
+		reminder.ID = uuid.New().String()
+		reminder.Status = models.ReminderStatusPending
+		if _, err := tx.Exec(query,
+			reminder.ID, reminder.BookingID, reminder.UserID, reminder.ScheduledTripID,
+			reminder.Kind, reminder.OffsetMinutes, reminder.SendAt, reminder.Status,
+		); err != nil {
+			return fmt.Errorf("failed to create booking reminder: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetDueDepartureReminders returns pending departure reminders whose send time has passed
+// Only bookings that are still confirmed and trips that have not departed are included
+func (r *BookingReminderRepository) GetDueDepartureReminders(now time.Time, limit int) ([]models.DueBookingReminder, error) {
+	query := dueReminderSelect + `
+		WHERE r.status = 'pending'
+		  AND r.kind = $1
+		  AND r.send_at <= $2
+		  AND st.departure_datetime > $2
+		  AND b.booking_status = 'confirmed'
+		  AND bb.status IN ('pending', 'confirmed', 'checked_in')
+		ORDER BY r.send_at ASC
+		LIMIT $3`
+
+	var reminders []models.DueBookingReminder
+	if err := r.db.Select(&reminders, query, models.ReminderKindDeparture, now, limit); err != nil {
+		return nil, fmt.Errorf("failed to get due departure reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// GetPendingBusApproachingReminders returns pending bus_approaching reminders for trips
+// that are live-tracked and whose boarding stop has coordinates
+func (r *BookingReminderRepository) GetPendingBusApproachingReminders(limit int) ([]models.DueBookingReminder, error) {
+	query := dueReminderSelect + `
+		WHERE r.status = 'pending'
+		  AND r.kind = $1
+		  AND b.booking_status = 'confirmed'
+		  AND bb.status IN ('pending', 'confirmed', 'checked_in')
+		  AND at.status IN ('in_transit', 'at_stop')
+		  AND at.current_latitude IS NOT NULL
+		  AND at.current_longitude IS NOT NULL
+		  AND mrs.latitude IS NOT NULL
+		  AND mrs.longitude IS NOT NULL
+		ORDER BY st.departure_datetime ASC
+		LIMIT $2`
+
+	var reminders []models.DueBookingReminder
+	if err := r.db.Select(&reminders, query, models.ReminderKindBusApproaching, limit); err != nil {
+		return nil, fmt.Errorf("failed to get bus approaching reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// MarkSent marks a reminder as delivered
+func (r *BookingReminderRepository) MarkSent(reminderID string) error {
+	query := `
+		UPDATE booking_reminders
+		SET status = 'sent', sent_at = NOW(), attempts = attempts + 1, last_error = NULL, updated_at = NOW()
+		WHERE id = $1`
+
+	_, err := r.db.Exec(query, reminderID)
+	if err != nil {
+		return fmt.Errorf("failed to mark reminder sent: %w", err)
+	}
+	return nil
+}
+
+// MarkAttemptFailed records a failed delivery; the reminder is marked failed once maxAttempts is reached
+func (r *BookingReminderRepository) MarkAttemptFailed(reminderID string, errMsg string, maxAttempts int) error {
+	query := `
+		UPDATE booking_reminders
+		SET attempts = attempts + 1,
+		    last_error = $2,
+		    status = CASE WHEN attempts + 1 >= $3 THEN 'failed' ELSE status END,
+		    updated_at = NOW()
+		WHERE id = $1`
+
+	_, err := r.db.Exec(query, reminderID, errMsg, maxAttempts)
+	if err != nil {
+		return fmt.Errorf("failed to record reminder failure: %w", err)
+	}
+	return nil
+}
+
+// CancelReminder de-schedules a single pending reminder, recording why
+func (r *BookingReminderRepository) CancelReminder(reminderID, reason string) error {
+	query := `
+		UPDATE booking_reminders
+		SET status = 'cancelled', last_error = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'`
+
+	_, err := r.db.Exec(query, reminderID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to cancel reminder: %w", err)
+	}
+	return nil
+}
+
+// CancelForBooking de-schedules all pending reminders of a booking
+func (r *BookingReminderRepository) CancelForBooking(bookingID string) (int64, error) {
+	query := `
+		UPDATE booking_reminders
+		SET status = 'cancelled', updated_at = NOW()
+		WHERE booking_id = $1 AND status = 'pending'`
+
+	result, err := r.db.Exec(query, bookingID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel booking reminders: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ExpireStaleReminders marks pending reminders as expired once their trip departed before the cutoff,
+// or once their booking is no longer confirmed
+func (r *BookingReminderRepository) ExpireStaleReminders(departedBefore time.Time) (int64, error) {
+	query := `
+		UPDATE booking_reminders r
+		SET status = 'expired', updated_at = NOW()
+		FROM scheduled_trips st, bookings b
+		WHERE r.scheduled_trip_id = st.id
+		  AND r.booking_id = b.id
+		  AND r.status = 'pending'
+		  AND (st.departure_datetime < $1 OR b.booking_status != 'confirmed')`
+
+	result, err := r.db.Exec(query, departedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire stale reminders: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetRemindersByBookingID returns all reminders for a booking
+func (r *BookingReminderRepository) GetRemindersByBookingID(bookingID string) ([]models.BookingReminder, error) {
+	query := `
+		SELECT id, booking_id, user_id, scheduled_trip_id, kind, offset_minutes,
+		       send_at, status, attempts, last_error, sent_at, created_at, updated_at
+		FROM booking_reminders
+		WHERE booking_id = $1
+		ORDER BY send_at ASC NULLS LAST`
+
+	var reminders []models.BookingReminder
+	if err := r.db.Select(&reminders, query, bookingID); err != nil {
+		return nil, fmt.Errorf("failed to get booking reminders: %w", err)
+	}
+	return reminders, nil
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
+)
+
+// BookingSnapshotRepository reads the live trip, route and lounge records a booking points at
+// and stores the frozen copy on bookings.snapshot
+type BookingSnapshotRepository struct {
+	db  *sqlx.DB
+	pii *fieldcrypt.Cipher // Decrypts the operator address copied into the snapshot
+}
+
+// NewBookingSnapshotRepository creates a new BookingSnapshotRepository
+func NewBookingSnapshotRepository(db *sqlx.DB, pii *fieldcrypt.Cipher) *BookingSnapshotRepository {
+	return &BookingSnapshotRepository{db: db, pii: pii}
+}
+
+// bookingTripRow is everything about a bus booking's trip that lives in one row
+type bookingTripRow struct {
+	ScheduledTripID   string    `db:"scheduled_trip_id"`
+	DepartureDatetime time.Time `db:"departure_datetime"`
+	DurationMinutes   *int      `db:"estimated_duration_minutes"`
+	TripBaseFare      float64   `db:"base_fare"`
+
+	BusOwnerID    *string `db:"bus_owner_id"`
+	CompanyName   *string `db:"company_name"`
+	BusinessPhone *string `db:"business_phone"`
+	BusinessEmail *string `db:"business_email"`
+	OwnerAddress  *string `db:"owner_address"`
+
+	BusNumber    *string  `db:"bus_number"`
+	BusType      *string  `db:"bus_type"`
+	PermitNumber *string  `db:"permit_number"`
+	PermitFare   *float64 `db:"approved_fare"`
+
+	MasterRouteID     *string `db:"master_route_id"`
+	BusOwnerRouteID   *string `db:"bus_owner_route_id"`
+	RouteNumber       *string `db:"route_number"`
+	RouteName         string  `db:"route_name"`
+	Direction         *string `db:"direction"`
+	BoardingStopID    *string `db:"boarding_stop_id"`
+	BoardingStopName  *string `db:"boarding_stop_name"`
+	AlightingStopID   *string `db:"alighting_stop_id"`
+	AlightingStopName *string `db:"alighting_stop_name"`
+
+	SeatLayoutID *string `db:"seat_layout_id"`
+}
+
+// FillBusDetails adds the operator, trip, route and seat layout behind a bus booking to
+// snapshot. Leaves it untouched if the bus booking no longer exists.
+func (r *BookingSnapshotRepository) FillBusDetails(busBookingID string, snapshot *models.BookingSnapshot) error {
+	var row bookingTripRow
+	err := r.db.Get(&row, `
+		SELECT st.id AS scheduled_trip_id, st.departure_datetime, st.estimated_duration_minutes, st.base_fare,
+		       bo.id AS bus_owner_id, bo.company_name, bo.business_phone, bo.business_email, bo.address AS owner_address,
+		       b.bus_number, b.bus_type::text AS bus_type, rp.permit_number, rp.approved_fare,
+		       mr.id AS master_route_id, bor.id AS bus_owner_route_id, mr.route_number,
+		       COALESCE(mr.route_name, bor.custom_route_name, 'Unknown Route') AS route_name, bor.direction,
+		       bb.boarding_stop_id, board.stop_name AS boarding_stop_name,
+		       bb.alighting_stop_id, alight.stop_name AS alighting_stop_name,
+		       COALESCE(st.seat_layout_id, b.seat_layout_id) AS seat_layout_id
+		FROM bus_bookings bb
+		JOIN scheduled_trips st ON st.id = bb.scheduled_trip_id
+		LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+		LEFT JOIN bus_owner_routes bor ON bor.id = COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id)
+		LEFT JOIN master_routes mr ON mr.id = bor.master_route_id
+		LEFT JOIN route_permits rp ON rp.id = st.permit_id
+		LEFT JOIN buses b ON b.id = st.bus_id
+		LEFT JOIN bus_owners bo ON bo.id = COALESCE(ts.bus_owner_id, bor.bus_owner_id, b.bus_owner_id)
+		LEFT JOIN master_route_stops board ON board.id = bb.boarding_stop_id
+		LEFT JOIN master_route_stops alight ON alight.id = bb.alighting_stop_id
+		WHERE bb.id = $1`, busBookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to get booking trip details: %w", err)
+	}
+	if err := decryptPII(r.pii, row.OwnerAddress); err != nil {
+		return err
+	}
+
+	if row.BusOwnerID != nil {
+		snapshot.Operator = &models.SnapshotOperator{
+			BusOwnerID:    *row.BusOwnerID,
+			CompanyName:   row.CompanyName,
+			BusinessPhone: row.BusinessPhone,
+			BusinessEmail: row.BusinessEmail,
+			Address:       row.OwnerAddress,
+		}
+	}
+	snapshot.Trip = &models.SnapshotTrip{
+		ScheduledTripID:   row.ScheduledTripID,
+		DepartureDatetime: row.DepartureDatetime,
+		DurationMinutes:   row.DurationMinutes,
+		BusNumber:         row.BusNumber,
+		BusType:           row.BusType,
+		PermitNumber:      row.PermitNumber,
+	}
+	snapshot.Route = &models.SnapshotRoute{
+		MasterRouteID:     row.MasterRouteID,
+		BusOwnerRouteID:   row.BusOwnerRouteID,
+		RouteNumber:       row.RouteNumber,
+		RouteName:         row.RouteName,
+		Direction:         row.Direction,
+		BoardingStopID:    row.BoardingStopID,
+		BoardingStopName:  row.BoardingStopName,
+		AlightingStopID:   row.AlightingStopID,
+		AlightingStopName: row.AlightingStopName,
+		Stops:             []models.SnapshotStop{},
+	}
+	snapshot.Fare.TripBaseFare = row.TripBaseFare
+	if row.PermitFare != nil {
+		snapshot.Fare.PermitFare = *row.PermitFare
+	}
+
+	if row.MasterRouteID != nil {
+		err := r.db.Select(&snapshot.Route.Stops, `
+			SELECT id, stop_name, stop_order, latitude, longitude, arrival_time_offset_minutes
+			FROM master_route_stops
+			WHERE master_route_id = $1
+			ORDER BY stop_order`, *row.MasterRouteID)
+		if err != nil {
+			return fmt.Errorf("failed to get booking route stops: %w", err)
+		}
+	}
+
+	err = r.db.Select(&snapshot.Fare.Seats, `
+		SELECT COALESCE(ts.seat_number, '') AS seat_number, COALESCE(ts.seat_type, '') AS seat_type,
+		       COALESCE(ts.seat_price, 0) AS seat_price, bbs.passenger_name
+		FROM bus_booking_seats bbs
+		LEFT JOIN trip_seats ts ON ts.id = bbs.trip_seat_id
+		WHERE bbs.bus_booking_id = $1
+		ORDER BY ts.seat_number`, busBookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking seats: %w", err)
+	}
+
+	if row.SeatLayoutID != nil {
+		var layout models.SnapshotSeatLayout
+		err := r.db.Get(&layout, `
+			SELECT id AS template_id, template_name, total_seats, updated_at AS version
+			FROM bus_seat_layout_templates WHERE id = $1`, *row.SeatLayoutID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to get booking seat layout: %w", err)
+		}
+		if err == nil {
+			snapshot.SeatLayout = &layout
+		}
+	}
+	return nil
+}
+
+// GetLounges returns the lounge visits attached to a booking with the lounges' current rates
+func (r *BookingSnapshotRepository) GetLounges(bookingID string) ([]models.SnapshotLounge, error) {
+	lounges := []models.SnapshotLounge{}
+	err := r.db.Select(&lounges, `
+		SELECT lb.id AS lounge_booking_id, lb.booking_type::text AS booking_type, l.id AS lounge_id,
+		       l.lounge_name, l.address, lo.business_name AS operator_name,
+		       lb.scheduled_arrival, lb.number_of_guests, lb.pricing_type,
+		       lb.base_price, lb.pre_order_total, lb.total_amount,
+		       l.price_1_hour, l.price_2_hours, l.price_3_hours, l.price_until_bus
+		FROM lounge_bookings lb
+		JOIN lounges l ON l.id = lb.lounge_id
+		LEFT JOIN lounge_owners lo ON lo.id = l.lounge_owner_id
+		WHERE lb.master_booking_id = $1
+		ORDER BY lb.booking_type, lb.created_at`, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking lounges: %w", err)
+	}
+	return lounges, nil
+}
+
+// Save stores a booking's snapshot unless it already has one, so a snapshot can never be
+// overwritten; returns false if the booking was already snapshotted or does not exist
+func (r *BookingSnapshotRepository) Save(bookingID string, snapshot *models.BookingSnapshot) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE bookings SET snapshot = $2
+		WHERE id = $1 AND snapshot IS NULL`, bookingID, snapshot)
+	if err != nil {
+		return false, fmt.Errorf("failed to save booking snapshot: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to save booking snapshot: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// Get returns a booking's snapshot; returns nil if the booking has none or does not exist
+func (r *BookingSnapshotRepository) Get(bookingID string) (*models.BookingSnapshot, error) {
+	var snapshot *models.BookingSnapshot
+	err := r.db.Get(&snapshot, `SELECT snapshot FROM bookings WHERE id = $1`, bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get booking snapshot: %w", err)
+	}
+	return snapshot, nil
+}
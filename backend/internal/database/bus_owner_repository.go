@@ -2,20 +2,34 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
 )
 
+// ErrBusOwnerNotFound is returned by GetByID when no bus owner matches
+var ErrBusOwnerNotFound = errors.New("bus owner not found")
+
 // BusOwnerRepository handles database operations for bus_owners table
 type BusOwnerRepository struct {
-	db DB
+	db  DB
+	pii *fieldcrypt.Cipher
+}
+
+// NewBusOwnerRepository creates a new BusOwnerRepository; pii may be nil to store PII in plaintext
+func NewBusOwnerRepository(db DB, pii *fieldcrypt.Cipher) *BusOwnerRepository {
+	return &BusOwnerRepository{db: db, pii: pii}
 }
 
-// NewBusOwnerRepository creates a new BusOwnerRepository
-func NewBusOwnerRepository(db DB) *BusOwnerRepository {
-	return &BusOwnerRepository{db: db}
+// decryptOwner decrypts the owner's PII columns in place
+func (r *BusOwnerRepository) decryptOwner(owner *models.BusOwner) error {
+	if err := decryptPII(r.pii, owner.IdentityOrIncorporationNo); err != nil {
+		return err
+	}
+	return decryptPII(r.pii, owner.Address)
 }
 
 // CreateWithCompany creates a new bus owner record with company information
@@ -32,6 +46,11 @@ func (r *BusOwnerRepository) CreateWithCompany(userID, companyName, identityNo s
 	owner.IdentityOrIncorporationNo = &identityNo
 	owner.BusinessEmail = businessEmail
 
+	storedIdentityNo, err := encryptPII(r.pii, identityNo)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		INSERT INTO bus_owners (
 			id, user_id, company_name, identity_or_incorporation_no,
@@ -41,12 +60,12 @@ func (r *BusOwnerRepository) CreateWithCompany(userID, companyName, identityNo s
 		RETURNING created_at, updated_at
 	`
 
-	err := r.db.QueryRow(
+	err = r.db.QueryRow(
 		query,
 		owner.ID,
 		owner.UserID,
 		owner.CompanyName,
-		owner.IdentityOrIncorporationNo,
+		storedIdentityNo,
 		owner.BusinessEmail,
 		owner.VerificationStatus,
 		owner.ProfileCompleted,
@@ -85,10 +104,13 @@ func (r *BusOwnerRepository) GetByID(ownerID string) (*models.BusOwner, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("bus owner not found")
+			return nil, ErrBusOwnerNotFound
 		}
 		return nil, err
 	}
+	if err := r.decryptOwner(owner); err != nil {
+		return nil, err
+	}
 
 	return owner, nil
 }
@@ -120,6 +142,9 @@ func (r *BusOwnerRepository) GetByUserID(userID string) (*models.BusOwner, error
 	if err != nil {
 		return nil, err
 	}
+	if err := r.decryptOwner(owner); err != nil {
+		return nil, err
+	}
 
 	return owner, nil
 }
@@ -154,6 +179,9 @@ func (r *BusOwnerRepository) GetByLicenseNumber(licenseNumber string) (*models.B
 		}
 		return nil, err
 	}
+	if err := r.decryptOwner(owner); err != nil {
+		return nil, err
+	}
 
 	return owner, nil
 }
@@ -194,6 +222,9 @@ func (r *BusOwnerRepository) SearchByCompanyName(name string) ([]*models.BusOwne
 		if err != nil {
 			return nil, err
 		}
+		if err := r.decryptOwner(owner); err != nil {
+			return nil, err
+		}
 		owners = append(owners, owner)
 	}
 
@@ -235,6 +266,9 @@ func (r *BusOwnerRepository) GetAllVerified() ([]*models.BusOwner, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := r.decryptOwner(owner); err != nil {
+			return nil, err
+		}
 		owners = append(owners, owner)
 	}
 
@@ -252,7 +286,12 @@ func (r *BusOwnerRepository) UpdateProfile(busOwnerID string, companyName, ident
 		WHERE id = $4
 	`
 
-	result, err := r.db.Exec(query, companyName, identityNo, businessEmail, busOwnerID)
+	storedIdentityNo, err := encryptPII(r.pii, identityNo)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, companyName, storedIdentityNo, businessEmail, busOwnerID)
 	if err != nil {
 		return fmt.Errorf("failed to update bus owner profile: %w", err)
 	}
@@ -268,3 +307,35 @@ func (r *BusOwnerRepository) UpdateProfile(busOwnerID string, companyName, ident
 
 	return nil
 }
+
+// GetOnboardingCounts counts what a bus owner has set up, for the onboarding checklist
+func (r *BusOwnerRepository) GetOnboardingCounts(busOwnerID string) (*models.BusOwnerOnboardingCounts, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM route_permits WHERE bus_owner_id = $1) AS permits_total,
+			(SELECT COUNT(*) FROM route_permits WHERE bus_owner_id = $1
+			    AND status = 'verified' AND expiry_date >= CURRENT_DATE) AS permits_valid,
+			(SELECT COUNT(*) FROM route_permits WHERE bus_owner_id = $1 AND status = 'pending') AS permits_pending,
+			(SELECT COUNT(*) FROM route_permits WHERE bus_owner_id = $1 AND status = 'rejected') AS permits_rejected,
+			(SELECT COUNT(*) FROM route_permits WHERE bus_owner_id = $1
+			    AND status = 'verified' AND expiry_date < CURRENT_DATE) AS permits_expired,
+			(SELECT COUNT(*) FROM buses WHERE bus_owner_id = $1) AS buses_total,
+			(SELECT COUNT(*) FROM buses WHERE bus_owner_id = $1 AND status = 'active') AS buses_active,
+			(SELECT COUNT(*) FROM bus_owner_routes WHERE bus_owner_id = $1) AS routes,
+			(SELECT COUNT(*) FROM trip_schedules WHERE bus_owner_id = $1 AND is_active = true) AS schedules_active,
+			(SELECT COUNT(*) FROM bus_staff_employment bse JOIN bus_staff bs ON bs.id = bse.staff_id
+			    WHERE bse.bus_owner_id = $1 AND bse.is_current = true AND bse.employment_status = 'active'
+			      AND bs.staff_type = 'driver') AS drivers,
+			(SELECT COUNT(*) FROM bus_staff_employment bse JOIN bus_staff bs ON bs.id = bse.staff_id
+			    WHERE bse.bus_owner_id = $1 AND bse.is_current = true AND bse.employment_status = 'active'
+			      AND bs.staff_type = 'conductor') AS conductors,
+			(SELECT COUNT(*) FROM bus_staff_employment
+			    WHERE bus_owner_id = $1 AND is_current = true AND employment_status = 'pending') AS staff_requests
+	`
+
+	var counts models.BusOwnerOnboardingCounts
+	if err := r.db.Get(&counts, query, busOwnerID); err != nil {
+		return nil, fmt.Errorf("failed to get onboarding counts: %w", err)
+	}
+	return &counts, nil
+}
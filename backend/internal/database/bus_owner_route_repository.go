@@ -41,12 +41,12 @@ func (r *BusOwnerRouteRepository) Create(route *models.BusOwnerRoute) error {
 	return err
 }
 
-// GetByID retrieves a bus owner route by ID
+// GetByID retrieves a bus owner route by ID, including a soft-deleted one (see DeletedAt)
 func (r *BusOwnerRouteRepository) GetByID(id string) (*models.BusOwnerRoute, error) {
 	var route models.BusOwnerRoute
 	query := `
 		SELECT id, bus_owner_id, master_route_id, custom_route_name,
-			   direction, selected_stop_ids, created_at, updated_at
+			   direction, selected_stop_ids, created_at, updated_at, deleted_at
 		FROM bus_owner_routes
 		WHERE id = $1
 	`
@@ -66,7 +66,7 @@ func (r *BusOwnerRouteRepository) GetByBusOwnerID(busOwnerID string) ([]models.B
 		SELECT id, bus_owner_id, master_route_id, custom_route_name,
 			   direction, selected_stop_ids, created_at, updated_at
 		FROM bus_owner_routes
-		WHERE bus_owner_id = $1
+		WHERE bus_owner_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -85,7 +85,7 @@ func (r *BusOwnerRouteRepository) GetByMasterRouteID(busOwnerID, masterRouteID s
 		SELECT id, bus_owner_id, master_route_id, custom_route_name,
 			   direction, selected_stop_ids, created_at, updated_at
 		FROM bus_owner_routes
-		WHERE bus_owner_id = $1 AND master_route_id = $2
+		WHERE bus_owner_id = $1 AND master_route_id = $2 AND deleted_at IS NULL
 		ORDER BY direction, created_at DESC
 	`
 
@@ -104,7 +104,7 @@ func (r *BusOwnerRouteRepository) Update(route *models.BusOwnerRoute) error {
 		SET custom_route_name = $1,
 			selected_stop_ids = $2,
 			updated_at = NOW()
-		WHERE id = $3 AND bus_owner_id = $4
+		WHERE id = $3 AND bus_owner_id = $4 AND deleted_at IS NULL
 		RETURNING updated_at
 	`
 
@@ -123,25 +123,10 @@ func (r *BusOwnerRouteRepository) Update(route *models.BusOwnerRoute) error {
 	return err
 }
 
-// Delete deletes a bus owner route
+// Delete soft-deletes a bus owner route. Returns sql.ErrNoRows if the owner has no such
+// route, or an *UpcomingTripsError while future published trips still follow it.
 func (r *BusOwnerRouteRepository) Delete(id, busOwnerID string) error {
-	query := `DELETE FROM bus_owner_routes WHERE id = $1 AND bus_owner_id = $2`
-
-	result, err := r.db.Exec(query, id, busOwnerID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("route not found or unauthorized")
-	}
-
-	return nil
+	return softDelete(r.db, models.DeletedEntityRoute, id, busOwnerID)
 }
 
 // ValidateStopsExist validates that all selected stop IDs exist in the master route
@@ -25,10 +25,10 @@ func (r *BusRepository) Create(bus *models.Bus) error {
 		INSERT INTO buses (
 			id, bus_owner_id, permit_id, bus_number, license_plate,
 			bus_type, manufacturing_year, last_maintenance_date,
-			insurance_expiry, status, seat_layout_id, has_wifi, has_ac, has_charging_ports,
+			insurance_expiry, status, seat_layout_id, seating_capacity, standing_capacity, has_wifi, has_ac, has_charging_ports,
 			has_entertainment, has_refreshments
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
 		)
 		RETURNING created_at, updated_at
 	`
@@ -37,21 +37,21 @@ func (r *BusRepository) Create(bus *models.Bus) error {
 		query,
 		bus.ID, bus.BusOwnerID, bus.PermitID, bus.BusNumber, bus.LicensePlate,
 		bus.BusType, bus.ManufacturingYear, bus.LastMaintenanceDate,
-		bus.InsuranceExpiry, bus.Status, bus.SeatLayoutID, bus.HasWifi, bus.HasAC, bus.HasChargingPorts,
+		bus.InsuranceExpiry, bus.Status, bus.SeatLayoutID, bus.SeatingCapacity, bus.StandingCapacity, bus.HasWifi, bus.HasAC, bus.HasChargingPorts,
 		bus.HasEntertainment, bus.HasRefreshments,
 	).Scan(&bus.CreatedAt, &bus.UpdatedAt)
 
 	return err
 }
 
-// GetByID retrieves a bus by ID
+// GetByID retrieves a bus by ID, including a soft-deleted one (see DeletedAt)
 func (r *BusRepository) GetByID(busID string) (*models.Bus, error) {
 	query := `
 		SELECT
 			id, bus_owner_id, permit_id, bus_number, license_plate,
 			bus_type, manufacturing_year, last_maintenance_date,
-			insurance_expiry, status, seat_layout_id, has_wifi, has_ac, has_charging_ports,
-			has_entertainment, has_refreshments, created_at, updated_at
+			insurance_expiry, status, seat_layout_id, seating_capacity, standing_capacity, has_wifi, has_ac, has_charging_ports,
+			has_entertainment, has_refreshments, created_at, updated_at, deleted_at
 		FROM buses
 		WHERE id = $1
 	`
@@ -61,12 +61,14 @@ func (r *BusRepository) GetByID(busID string) (*models.Bus, error) {
 	var lastMaintenanceDate sql.NullTime
 	var insuranceExpiry sql.NullTime
 	var seatLayoutID sql.NullString
+	var seatingCapacity sql.NullInt64
+	var standingCapacity sql.NullInt64
 
 	err := r.db.QueryRow(query, busID).Scan(
 		&bus.ID, &bus.BusOwnerID, &bus.PermitID, &bus.BusNumber, &bus.LicensePlate,
 		&bus.BusType, &manufacturingYear, &lastMaintenanceDate,
-		&insuranceExpiry, &bus.Status, &seatLayoutID, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
-		&bus.HasEntertainment, &bus.HasRefreshments, &bus.CreatedAt, &bus.UpdatedAt,
+		&insuranceExpiry, &bus.Status, &seatLayoutID, &seatingCapacity, &standingCapacity, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
+		&bus.HasEntertainment, &bus.HasRefreshments, &bus.CreatedAt, &bus.UpdatedAt, &bus.DeletedAt,
 	)
 
 	if err != nil {
@@ -87,6 +89,14 @@ func (r *BusRepository) GetByID(busID string) (*models.Bus, error) {
 	if seatLayoutID.Valid {
 		bus.SeatLayoutID = &seatLayoutID.String
 	}
+	if seatingCapacity.Valid {
+		capacity := int(seatingCapacity.Int64)
+		bus.SeatingCapacity = &capacity
+	}
+	if standingCapacity.Valid {
+		capacity := int(standingCapacity.Int64)
+		bus.StandingCapacity = &capacity
+	}
 
 	return bus, nil
 }
@@ -97,10 +107,10 @@ func (r *BusRepository) GetByOwnerID(busOwnerID string) ([]models.Bus, error) {
 		SELECT
 			id, bus_owner_id, permit_id, bus_number, license_plate,
 			bus_type, manufacturing_year, last_maintenance_date,
-			insurance_expiry, status, seat_layout_id, has_wifi, has_ac, has_charging_ports,
+			insurance_expiry, status, seat_layout_id, seating_capacity, standing_capacity, has_wifi, has_ac, has_charging_ports,
 			has_entertainment, has_refreshments, created_at, updated_at
 		FROM buses
-		WHERE bus_owner_id = $1
+		WHERE bus_owner_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -117,11 +127,13 @@ func (r *BusRepository) GetByOwnerID(busOwnerID string) ([]models.Bus, error) {
 		var lastMaintenanceDate sql.NullTime
 		var insuranceExpiry sql.NullTime
 		var seatLayoutID sql.NullString
+		var seatingCapacity sql.NullInt64
+		var standingCapacity sql.NullInt64
 
 		err := rows.Scan(
 			&bus.ID, &bus.BusOwnerID, &bus.PermitID, &bus.BusNumber, &bus.LicensePlate,
 			&bus.BusType, &manufacturingYear, &lastMaintenanceDate,
-			&insuranceExpiry, &bus.Status, &seatLayoutID, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
+			&insuranceExpiry, &bus.Status, &seatLayoutID, &seatingCapacity, &standingCapacity, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
 			&bus.HasEntertainment, &bus.HasRefreshments, &bus.CreatedAt, &bus.UpdatedAt,
 		)
 		if err != nil {
@@ -142,6 +154,14 @@ func (r *BusRepository) GetByOwnerID(busOwnerID string) ([]models.Bus, error) {
 		if seatLayoutID.Valid {
 			bus.SeatLayoutID = &seatLayoutID.String
 		}
+		if seatingCapacity.Valid {
+			capacity := int(seatingCapacity.Int64)
+			bus.SeatingCapacity = &capacity
+		}
+		if standingCapacity.Valid {
+			capacity := int(standingCapacity.Int64)
+			bus.StandingCapacity = &capacity
+		}
 
 		buses = append(buses, bus)
 	}
@@ -155,7 +175,7 @@ func (r *BusRepository) GetByLicensePlate(licensePlate string) (*models.Bus, err
 		SELECT
 			id, bus_owner_id, permit_id, bus_number, license_plate,
 			bus_type, manufacturing_year, last_maintenance_date,
-			insurance_expiry, status, seat_layout_id, has_wifi, has_ac, has_charging_ports,
+			insurance_expiry, status, seat_layout_id, seating_capacity, standing_capacity, has_wifi, has_ac, has_charging_ports,
 			has_entertainment, has_refreshments, created_at, updated_at
 		FROM buses
 		WHERE license_plate = $1
@@ -166,11 +186,13 @@ func (r *BusRepository) GetByLicensePlate(licensePlate string) (*models.Bus, err
 	var lastMaintenanceDate sql.NullTime
 	var insuranceExpiry sql.NullTime
 	var seatLayoutID sql.NullString
+	var seatingCapacity sql.NullInt64
+	var standingCapacity sql.NullInt64
 
 	err := r.db.QueryRow(query, licensePlate).Scan(
 		&bus.ID, &bus.BusOwnerID, &bus.PermitID, &bus.BusNumber, &bus.LicensePlate,
 		&bus.BusType, &manufacturingYear, &lastMaintenanceDate,
-		&insuranceExpiry, &bus.Status, &seatLayoutID, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
+		&insuranceExpiry, &bus.Status, &seatLayoutID, &seatingCapacity, &standingCapacity, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
 		&bus.HasEntertainment, &bus.HasRefreshments, &bus.CreatedAt, &bus.UpdatedAt,
 	)
 
@@ -195,6 +217,14 @@ func (r *BusRepository) GetByLicensePlate(licensePlate string) (*models.Bus, err
 	if seatLayoutID.Valid {
 		bus.SeatLayoutID = &seatLayoutID.String
 	}
+	if seatingCapacity.Valid {
+		capacity := int(seatingCapacity.Int64)
+		bus.SeatingCapacity = &capacity
+	}
+	if standingCapacity.Valid {
+		capacity := int(standingCapacity.Int64)
+		bus.StandingCapacity = &capacity
+	}
 
 	return bus, nil
 }
@@ -285,6 +315,18 @@ func (r *BusRepository) Update(busID string, req *models.UpdateBusRequest) error
 		argCount++
 	}
 
+	if req.SeatingCapacity != nil {
+		updates = append(updates, fmt.Sprintf("seating_capacity = $%d", argCount))
+		args = append(args, *req.SeatingCapacity)
+		argCount++
+	}
+
+	if req.StandingCapacity != nil {
+		updates = append(updates, fmt.Sprintf("standing_capacity = $%d", argCount))
+		args = append(args, *req.StandingCapacity)
+		argCount++
+	}
+
 	if len(updates) == 0 {
 		return fmt.Errorf("no fields to update")
 	}
@@ -305,36 +347,22 @@ func (r *BusRepository) Update(busID string, req *models.UpdateBusRequest) error
 	return err
 }
 
-// Delete deletes a bus
+// Delete soft-deletes a bus, keeping it for the trips and bookings that reference it. Returns
+// an *UpcomingTripsError while future published trips still use the bus.
 func (r *BusRepository) Delete(busID string, busOwnerID string) error {
-	query := `DELETE FROM buses WHERE id = $1 AND bus_owner_id = $2`
-	result, err := r.db.Exec(query, busID, busOwnerID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+	return softDelete(r.db, models.DeletedEntityBus, busID, busOwnerID)
 }
 
-// GetByPermitID retrieves a bus by permit ID (one permit = one bus)
+// GetByPermitID retrieves the bus on a permit (one permit = one bus); deleted buses free the permit
 func (r *BusRepository) GetByPermitID(permitID string) (*models.Bus, error) {
 	query := `
 		SELECT
 			id, bus_owner_id, permit_id, bus_number, license_plate,
 			bus_type, manufacturing_year, last_maintenance_date,
-			insurance_expiry, status, seat_layout_id, has_wifi, has_ac, has_charging_ports,
+			insurance_expiry, status, seat_layout_id, seating_capacity, standing_capacity, has_wifi, has_ac, has_charging_ports,
 			has_entertainment, has_refreshments, created_at, updated_at
 		FROM buses
-		WHERE permit_id = $1
+		WHERE permit_id = $1 AND deleted_at IS NULL
 	`
 
 	bus := &models.Bus{}
@@ -342,11 +370,13 @@ func (r *BusRepository) GetByPermitID(permitID string) (*models.Bus, error) {
 	var lastMaintenanceDate sql.NullTime
 	var insuranceExpiry sql.NullTime
 	var seatLayoutID sql.NullString
+	var seatingCapacity sql.NullInt64
+	var standingCapacity sql.NullInt64
 
 	err := r.db.QueryRow(query, permitID).Scan(
 		&bus.ID, &bus.BusOwnerID, &bus.PermitID, &bus.BusNumber, &bus.LicensePlate,
 		&bus.BusType, &manufacturingYear, &lastMaintenanceDate,
-		&insuranceExpiry, &bus.Status, &seatLayoutID, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
+		&insuranceExpiry, &bus.Status, &seatLayoutID, &seatingCapacity, &standingCapacity, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
 		&bus.HasEntertainment, &bus.HasRefreshments, &bus.CreatedAt, &bus.UpdatedAt,
 	)
 
@@ -371,6 +401,14 @@ func (r *BusRepository) GetByPermitID(permitID string) (*models.Bus, error) {
 	if seatLayoutID.Valid {
 		bus.SeatLayoutID = &seatLayoutID.String
 	}
+	if seatingCapacity.Valid {
+		capacity := int(seatingCapacity.Int64)
+		bus.SeatingCapacity = &capacity
+	}
+	if standingCapacity.Valid {
+		capacity := int(standingCapacity.Int64)
+		bus.StandingCapacity = &capacity
+	}
 
 	return bus, nil
 }
@@ -381,10 +419,10 @@ func (r *BusRepository) GetByStatus(busOwnerID string, status string) ([]models.
 		SELECT
 			id, bus_owner_id, permit_id, bus_number, license_plate,
 			bus_type, manufacturing_year, last_maintenance_date,
-			insurance_expiry, status, seat_layout_id, has_wifi, has_ac, has_charging_ports,
+			insurance_expiry, status, seat_layout_id, seating_capacity, standing_capacity, has_wifi, has_ac, has_charging_ports,
 			has_entertainment, has_refreshments, created_at, updated_at
 		FROM buses
-		WHERE bus_owner_id = $1 AND status = $2
+		WHERE bus_owner_id = $1 AND status = $2 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -401,11 +439,13 @@ func (r *BusRepository) GetByStatus(busOwnerID string, status string) ([]models.
 		var lastMaintenanceDate sql.NullTime
 		var insuranceExpiry sql.NullTime
 		var seatLayoutID sql.NullString
+		var seatingCapacity sql.NullInt64
+		var standingCapacity sql.NullInt64
 
 		err := rows.Scan(
 			&bus.ID, &bus.BusOwnerID, &bus.PermitID, &bus.BusNumber, &bus.LicensePlate,
 			&bus.BusType, &manufacturingYear, &lastMaintenanceDate,
-			&insuranceExpiry, &bus.Status, &seatLayoutID, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
+			&insuranceExpiry, &bus.Status, &seatLayoutID, &seatingCapacity, &standingCapacity, &bus.HasWifi, &bus.HasAC, &bus.HasChargingPorts,
 			&bus.HasEntertainment, &bus.HasRefreshments, &bus.CreatedAt, &bus.UpdatedAt,
 		)
 		if err != nil {
@@ -426,6 +466,14 @@ func (r *BusRepository) GetByStatus(busOwnerID string, status string) ([]models.
 		if seatLayoutID.Valid {
 			bus.SeatLayoutID = &seatLayoutID.String
 		}
+		if seatingCapacity.Valid {
+			capacity := int(seatingCapacity.Int64)
+			bus.SeatingCapacity = &capacity
+		}
+		if standingCapacity.Valid {
+			capacity := int(standingCapacity.Int64)
+			bus.StandingCapacity = &capacity
+		}
 
 		buses = append(buses, bus)
 	}
@@ -25,9 +25,9 @@ func NewBusSeatLayoutRepository(db DB) *BusSeatLayoutRepository {
 func (r *BusSeatLayoutRepository) CreateTemplate(ctx context.Context, template *models.BusSeatLayoutTemplate) error {
 	query := `
 		INSERT INTO bus_seat_layout_templates (
-			template_name, total_rows, total_seats, description,
+			template_name, total_rows, total_seats, description, layout_metadata,
 			is_active, created_by, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
 		RETURNING id, created_at, updated_at
 	`
 
@@ -37,6 +37,7 @@ func (r *BusSeatLayoutRepository) CreateTemplate(ctx context.Context, template *
 		template.TotalRows,
 		template.TotalSeats,
 		template.Description,
+		template.LayoutMetadata,
 		template.IsActive,
 		template.CreatedBy,
 	).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
@@ -85,7 +86,7 @@ func (r *BusSeatLayoutRepository) GetTemplateByID(ctx context.Context, templateI
 	var template models.BusSeatLayoutTemplate
 
 	query := `
-		SELECT id, template_name, total_rows, total_seats, description,
+		SELECT id, template_name, total_rows, total_seats, description, layout_metadata,
 		       is_active, created_by, created_at, updated_at
 		FROM bus_seat_layout_templates
 		WHERE id = $1
@@ -127,7 +128,7 @@ func (r *BusSeatLayoutRepository) ListTemplates(ctx context.Context, activeOnly
 	var templates []*models.BusSeatLayoutTemplate
 
 	query := `
-		SELECT id, template_name, total_rows, total_seats, description,
+		SELECT id, template_name, total_rows, total_seats, description, layout_metadata,
 		       is_active, created_by, created_at, updated_at
 		FROM bus_seat_layout_templates
 	`
@@ -154,11 +155,12 @@ func (r *BusSeatLayoutRepository) UpdateTemplate(ctx context.Context, templateID
 			template_name = COALESCE($1, template_name),
 			description = COALESCE($2, description),
 			is_active = COALESCE($3, is_active),
+			layout_metadata = COALESCE($4, layout_metadata),
 			updated_at = NOW()
-		WHERE id = $4
+		WHERE id = $5
 	`
 
-	result, err := r.db.Exec(query, req.TemplateName, req.Description, req.IsActive, templateID)
+	result, err := r.db.Exec(query, req.TemplateName, req.Description, req.IsActive, req.LayoutMetadata, templateID)
 	if err != nil {
 		return fmt.Errorf("failed to update template: %w", err)
 	}
@@ -2,20 +2,27 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
 )
 
-// BusStaffRepository handles database operations for bus_staff and bus_staff_employment tables
+// ErrStaffNotFound is returned when no bus_staff record matches
+var ErrStaffNotFound = errors.New("staff not found")
+
+// BusStaffRepository handles database operations for bus_staff and bus_staff_employment tables.
+// License numbers are encrypted at rest when a PII cipher is configured.
 type BusStaffRepository struct {
-	db DB
+	db  DB
+	pii *fieldcrypt.Cipher
 }
 
-// NewBusStaffRepository creates a new BusStaffRepository
-func NewBusStaffRepository(db DB) *BusStaffRepository {
-	return &BusStaffRepository{db: db}
+// NewBusStaffRepository creates a new BusStaffRepository; pii may be nil to store PII in plaintext
+func NewBusStaffRepository(db DB, pii *fieldcrypt.Cipher) *BusStaffRepository {
+	return &BusStaffRepository{db: db, pii: pii}
 }
 
 // GetByUserID retrieves staff record by user_id
@@ -43,10 +50,13 @@ func (r *BusStaffRepository) GetByUserID(userID string) (*models.BusStaff, error
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("staff not found")
+			return nil, ErrStaffNotFound
 		}
 		return nil, err
 	}
+	if err := decryptPII(r.pii, staff.LicenseNumber); err != nil {
+		return nil, err
+	}
 
 	return staff, nil
 }
@@ -76,16 +86,23 @@ func (r *BusStaffRepository) GetByID(staffID string) (*models.BusStaff, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("staff not found")
+			return nil, ErrStaffNotFound
 		}
 		return nil, err
 	}
+	if err := decryptPII(r.pii, staff.LicenseNumber); err != nil {
+		return nil, err
+	}
 
 	return staff, nil
 }
 
 // Create creates a new bus_staff record
 func (r *BusStaffRepository) Create(staff *models.BusStaff) error {
+	licenseNumber, err := encryptPIIPtr(r.pii, staff.LicenseNumber)
+	if err != nil {
+		return err
+	}
 	query := `
 		INSERT INTO bus_staff (
 			user_id, first_name, last_name, staff_type, license_number, 
@@ -95,13 +112,13 @@ func (r *BusStaffRepository) Create(staff *models.BusStaff) error {
 		RETURNING id, created_at, updated_at, is_verified, verification_status
 	`
 
-	err := r.db.QueryRow(
+	err = r.db.QueryRow(
 		query,
 		staff.UserID,
 		staff.FirstName,
 		staff.LastName,
 		staff.StaffType,
-		staff.LicenseNumber,
+		licenseNumber,
 		staff.LicenseExpiryDate,
 		staff.ExperienceYears,
 		staff.EmergencyContact,
@@ -120,6 +137,10 @@ func (r *BusStaffRepository) Create(staff *models.BusStaff) error {
 
 // Update updates an existing bus_staff record
 func (r *BusStaffRepository) Update(staff *models.BusStaff) error {
+	licenseNumber, err := encryptPIIPtr(r.pii, staff.LicenseNumber)
+	if err != nil {
+		return err
+	}
 	query := `
 		UPDATE bus_staff
 		SET 
@@ -142,13 +163,13 @@ func (r *BusStaffRepository) Update(staff *models.BusStaff) error {
 		RETURNING updated_at
 	`
 
-	err := r.db.QueryRow(
+	err = r.db.QueryRow(
 		query,
 		staff.ID,
 		staff.FirstName,
 		staff.LastName,
 		staff.StaffType,
-		staff.LicenseNumber,
+		licenseNumber,
 		staff.LicenseExpiryDate,
 		staff.ExperienceYears,
 		staff.EmergencyContact,
@@ -179,6 +200,13 @@ func (r *BusStaffRepository) UpdateFields(userID string, fields map[string]inter
 		if argPos > 1 {
 			query += ", "
 		}
+		if field == "license_number" {
+			encrypted, err := r.encryptLicenseField(value)
+			if err != nil {
+				return err
+			}
+			value = encrypted
+		}
 		query += fmt.Sprintf("%s = $%d", field, argPos)
 		args = append(args, value)
 		argPos++
@@ -197,6 +225,17 @@ func (r *BusStaffRepository) UpdateFields(userID string, fields map[string]inter
 	return err
 }
 
+// encryptLicenseField encrypts a license_number value passed to UpdateFields
+func (r *BusStaffRepository) encryptLicenseField(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return encryptPII(r.pii, v)
+	case *string:
+		return encryptPIIPtr(r.pii, v)
+	}
+	return value, nil
+}
+
 // GetByPhoneNumber retrieves staff record by phone number (via users table)
 func (r *BusStaffRepository) GetByPhoneNumber(phoneNumber string) (*models.BusStaff, error) {
 	query := `
@@ -227,6 +266,9 @@ func (r *BusStaffRepository) GetByPhoneNumber(phoneNumber string) (*models.BusSt
 		}
 		return nil, err
 	}
+	if err := decryptPII(r.pii, staff.LicenseNumber); err != nil {
+		return nil, err
+	}
 
 	return staff, nil
 }
@@ -403,6 +445,9 @@ func (r *BusStaffRepository) GetAllByBusOwner(busOwnerID string) ([]*models.Staf
 		if err != nil {
 			return nil, err
 		}
+		if err := decryptPII(r.pii, staff.LicenseNumber); err != nil {
+			return nil, err
+		}
 
 		staffList = append(staffList, &models.StaffWithEmployment{
 			Staff:      staff,
@@ -413,6 +458,139 @@ func (r *BusStaffRepository) GetAllByBusOwner(busOwnerID string) ([]*models.Staf
 	return staffList, nil
 }
 
+// GetPendingByBusOwner retrieves staff waiting for a bus owner to approve their join request, oldest first
+func (r *BusStaffRepository) GetPendingByBusOwner(busOwnerID string) ([]*models.StaffWithEmployment, error) {
+	query := `
+		SELECT
+			bs.id, bs.user_id, bs.first_name, bs.last_name, bs.staff_type, bs.license_number,
+			bs.license_expiry_date, bs.experience_years,
+			bs.emergency_contact, bs.emergency_contact_name,
+			bs.profile_completed, bs.is_verified, bs.verification_status,
+			bs.verification_notes, bs.verified_at, bs.verified_by, bs.created_at, bs.updated_at,
+			bse.id, bse.staff_id, bse.bus_owner_id, bse.employment_status, bse.hire_date,
+			bse.termination_date, bse.termination_reason, bse.salary_amount,
+			bse.performance_rating, bse.total_trips_completed, bse.is_current,
+			bse.notes, bse.created_at, bse.updated_at
+		FROM bus_staff bs
+		INNER JOIN bus_staff_employment bse ON bs.id = bse.staff_id
+		WHERE bse.bus_owner_id = $1 AND bse.is_current = true AND bse.employment_status = 'pending'
+		ORDER BY bse.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	staffList := []*models.StaffWithEmployment{}
+	for rows.Next() {
+		staff := &models.BusStaff{}
+		emp := &models.BusStaffEmployment{}
+
+		err := rows.Scan(
+			&staff.ID, &staff.UserID, &staff.FirstName, &staff.LastName, &staff.StaffType,
+			&staff.LicenseNumber, &staff.LicenseExpiryDate,
+			&staff.ExperienceYears, &staff.EmergencyContact, &staff.EmergencyContactName,
+			&staff.ProfileCompleted, &staff.IsVerified, &staff.VerificationStatus,
+			&staff.VerificationNotes, &staff.VerifiedAt,
+			&staff.VerifiedBy, &staff.CreatedAt, &staff.UpdatedAt,
+			&emp.ID, &emp.StaffID, &emp.BusOwnerID, &emp.EmploymentStatus, &emp.HireDate,
+			&emp.TerminationDate, &emp.TerminationReason, &emp.SalaryAmount,
+			&emp.PerformanceRating, &emp.TotalTripsCompleted, &emp.IsCurrent,
+			&emp.Notes, &emp.CreatedAt, &emp.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := decryptPII(r.pii, staff.LicenseNumber); err != nil {
+			return nil, err
+		}
+
+		staffList = append(staffList, &models.StaffWithEmployment{
+			Staff:      staff,
+			Employment: emp,
+		})
+	}
+
+	return staffList, rows.Err()
+}
+
+// GetEmploymentByID retrieves an employment record, or nil if it doesn't exist
+func (r *BusStaffRepository) GetEmploymentByID(employmentID string) (*models.BusStaffEmployment, error) {
+	query := `
+		SELECT
+			id, staff_id, bus_owner_id, employment_status, hire_date,
+			termination_date, termination_reason, salary_amount,
+			performance_rating, total_trips_completed, is_current,
+			notes, created_at, updated_at
+		FROM bus_staff_employment
+		WHERE id = $1
+	`
+
+	emp := &models.BusStaffEmployment{}
+	err := r.db.QueryRow(query, employmentID).Scan(
+		&emp.ID, &emp.StaffID, &emp.BusOwnerID, &emp.EmploymentStatus, &emp.HireDate,
+		&emp.TerminationDate, &emp.TerminationReason, &emp.SalaryAmount,
+		&emp.PerformanceRating, &emp.TotalTripsCompleted, &emp.IsCurrent,
+		&emp.Notes, &emp.CreatedAt, &emp.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return emp, nil
+}
+
+// ApprovePendingEmployment turns a pending join request into active employment starting today.
+// Returns false if the request is no longer pending (already approved, rejected or withdrawn).
+func (r *BusStaffRepository) ApprovePendingEmployment(employmentID string) (bool, error) {
+	query := `
+		UPDATE bus_staff_employment
+		SET employment_status = 'active',
+			hire_date = CURRENT_DATE,
+			updated_at = NOW()
+		WHERE id = $1 AND is_current = true AND employment_status = 'pending'
+	`
+
+	result, err := r.db.Exec(query, employmentID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// RejectPendingEmployment closes a pending join request with the owner's reason.
+// Returns false if the request is no longer pending.
+func (r *BusStaffRepository) RejectPendingEmployment(employmentID, reason string) (bool, error) {
+	query := `
+		UPDATE bus_staff_employment
+		SET is_current = false,
+			employment_status = 'terminated',
+			termination_date = CURRENT_DATE,
+			termination_reason = $2,
+			updated_at = NOW()
+		WHERE id = $1 AND is_current = true AND employment_status = 'pending'
+	`
+
+	result, err := r.db.Exec(query, employmentID, reason)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
 // UpdateEmploymentFields updates specific fields of an employment record
 func (r *BusStaffRepository) UpdateEmploymentFields(employmentID string, fields map[string]interface{}) error {
 	if len(fields) == 0 {
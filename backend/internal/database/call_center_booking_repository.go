@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// CallCenterBookingRepository records bookings call-center agents create for passengers over
+// the phone
+type CallCenterBookingRepository struct {
+	db *sqlx.DB
+}
+
+// NewCallCenterBookingRepository creates a new CallCenterBookingRepository
+func NewCallCenterBookingRepository(db *sqlx.DB) *CallCenterBookingRepository {
+	return &CallCenterBookingRepository{db: db}
+}
+
+const callCenterBookingSelect = `
+	SELECT cb.id, cb.intent_id, cb.agent_user_id, cb.passenger_user_id, cb.passenger_phone,
+	       cb.payment_url, cb.notes, cb.sms_count, cb.last_sms_at, cb.created_at,
+	       bi.status AS intent_status, bi.total_amount, bi.currency, bi.expires_at,
+	       bi.confirmed_at, bi.bus_booking_id
+	FROM call_center_bookings cb
+	JOIN booking_intents bi ON bi.id = cb.intent_id`
+
+// Create records a call-center booking
+func (r *CallCenterBookingRepository) Create(booking *models.CallCenterBooking) error {
+	if booking.ID == uuid.Nil {
+		booking.ID = uuid.New()
+	}
+	err := r.db.QueryRow(`
+		INSERT INTO call_center_bookings (id, intent_id, agent_user_id, passenger_user_id, passenger_phone, payment_url, notes, sms_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 0, NOW())
+		RETURNING created_at`,
+		booking.ID, booking.IntentID, booking.AgentUserID, booking.PassengerUserID,
+		booking.PassengerPhone, booking.PaymentURL, booking.Notes,
+	).Scan(&booking.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create call-center booking: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a call-center booking with its intent's current state; returns nil if not found
+func (r *CallCenterBookingRepository) GetByID(id uuid.UUID) (*models.CallCenterBooking, error) {
+	var booking models.CallCenterBooking
+	err := r.db.Get(&booking, callCenterBookingSelect+` WHERE cb.id = $1`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get call-center booking: %w", err)
+	}
+	return &booking, nil
+}
+
+// List returns call-center bookings, newest first
+func (r *CallCenterBookingRepository) List(filter models.CallCenterBookingFilter) ([]models.CallCenterBooking, error) {
+	query := callCenterBookingSelect + ` WHERE 1=1`
+	args := []interface{}{}
+	if filter.AgentUserID != nil {
+		args = append(args, *filter.AgentUserID)
+		query += fmt.Sprintf(" AND cb.agent_user_id = $%d", len(args))
+	}
+	if filter.Phone != "" {
+		args = append(args, filter.Phone)
+		query += fmt.Sprintf(" AND cb.passenger_phone = $%d", len(args))
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY cb.created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	bookings := []models.CallCenterBooking{}
+	if err := r.db.Select(&bookings, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list call-center bookings: %w", err)
+	}
+	return bookings, nil
+}
+
+// RecordSMS counts a payment link SMS sent to the passenger
+func (r *CallCenterBookingRepository) RecordSMS(booking *models.CallCenterBooking) error {
+	err := r.db.QueryRow(`
+		UPDATE call_center_bookings
+		SET sms_count = sms_count + 1, last_sms_at = NOW()
+		WHERE id = $1
+		RETURNING sms_count, last_sms_at`, booking.ID,
+	).Scan(&booking.SMSCount, &booking.LastSMSAt)
+	if err != nil {
+		return fmt.Errorf("failed to record call-center SMS: %w", err)
+	}
+	return nil
+}
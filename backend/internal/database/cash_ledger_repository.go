@@ -0,0 +1,346 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	// ErrCashLedgerClosed is returned when a trip's cash has been handed in and its sales can no
+	// longer change
+	ErrCashLedgerClosed = errors.New("the trip's cash has been handed in; sales can no longer change")
+	// ErrCashSettlementReconciled is returned when resubmitting a settlement the owner has accepted
+	ErrCashSettlementReconciled = errors.New("the trip's cash settlement has already been reconciled")
+	// ErrOnboardSaleVoided is returned when voiding a sale that is already void
+	ErrOnboardSaleVoided = errors.New("the sale has already been voided")
+)
+
+// CashLedgerRepository handles conductors' onboard cash sales and the end-of-trip cash
+// settlements bus owners reconcile
+type CashLedgerRepository struct {
+	db *sqlx.DB
+}
+
+// NewCashLedgerRepository creates a new CashLedgerRepository
+func NewCashLedgerRepository(db *sqlx.DB) *CashLedgerRepository {
+	return &CashLedgerRepository{db: db}
+}
+
+const onboardSaleColumns = `id, scheduled_trip_id, sold_by_user_id, from_stop_id, to_stop_id, passenger_count, fare,
+	amount, client_reference, status, void_reason, voided_by_user_id, voided_at, sold_at`
+
+const cashSettlementColumns = `id, scheduled_trip_id, submitted_by_user_id, sale_count, passenger_count,
+	onboard_sales_amount, standing_ticket_count, standing_tickets_amount, expected_cash, declared_cash, variance,
+	crew_note, status, owner_note, reviewed_by_user_id, reviewed_at, submitted_at, updated_at`
+
+// GetTrip returns a trip with its owner and crew; returns nil if the trip does not exist
+func (r *CashLedgerRepository) GetTrip(scheduledTripID string) (*models.CashTrip, error) {
+	var trip models.CashTrip
+	err := r.db.Get(&trip, `
+		SELECT st.id AS scheduled_trip_id, st.status, st.departure_datetime, st.base_fare,
+		       COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS bus_owner_id,
+		       drv.user_id AS driver_user_id, cond.user_id AS conductor_user_id, bo.user_id AS owner_user_id
+		FROM scheduled_trips st
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN bus_owners bo ON bo.id = COALESCE(ts.bus_owner_id, bor.bus_owner_id)
+		LEFT JOIN bus_staff drv ON drv.id = st.assigned_driver_id
+		LEFT JOIN bus_staff cond ON cond.id = st.assigned_conductor_id
+		WHERE st.id = $1`, scheduledTripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip for cash ledger: %w", err)
+	}
+	return &trip, nil
+}
+
+// ListSales returns a trip's onboard sales, voided ones included, newest first
+func (r *CashLedgerRepository) ListSales(scheduledTripID string) ([]models.OnboardSale, error) {
+	sales := []models.OnboardSale{}
+	err := r.db.Select(&sales, `
+		SELECT `+onboardSaleColumns+`
+		FROM trip_onboard_sales
+		WHERE scheduled_trip_id = $1
+		ORDER BY sold_at DESC`, scheduledTripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list onboard sales: %w", err)
+	}
+	return sales, nil
+}
+
+// GetTotals adds up the cash a trip's crew has taken
+func (r *CashLedgerRepository) GetTotals(scheduledTripID string) (models.CashTotals, error) {
+	return getCashTotals(r.db, scheduledTripID)
+}
+
+func getCashTotals(q sqlx.Queryer, scheduledTripID string) (models.CashTotals, error) {
+	var totals models.CashTotals
+	err := sqlx.Get(q, &totals, `
+		SELECT s.sale_count, s.passenger_count, s.onboard_sales_amount, s.voided_count,
+		       t.standing_ticket_count, t.standing_tickets_amount
+		FROM (
+			SELECT COUNT(*) FILTER (WHERE status = 'recorded') AS sale_count,
+			       COALESCE(SUM(passenger_count) FILTER (WHERE status = 'recorded'), 0) AS passenger_count,
+			       COALESCE(SUM(amount) FILTER (WHERE status = 'recorded'), 0) AS onboard_sales_amount,
+			       COUNT(*) FILTER (WHERE status = 'voided') AS voided_count
+			FROM trip_onboard_sales
+			WHERE scheduled_trip_id = $1
+		) s, (
+			SELECT COUNT(*) AS standing_ticket_count, COALESCE(SUM(fare), 0) AS standing_tickets_amount
+			FROM standing_tickets
+			WHERE scheduled_trip_id = $1 AND payment_status = 'paid' AND status <> 'cancelled'
+		) t`, scheduledTripID)
+	if err != nil {
+		return totals, fmt.Errorf("failed to total trip cash: %w", err)
+	}
+	return totals, nil
+}
+
+// CreateSale records an onboard sale. A sale whose client reference was already recorded on
+// the trip is not recorded again: sale is filled from the original and created is false.
+// Returns ErrCashLedgerClosed once the trip's cash has been handed in.
+func (r *CashLedgerRepository) CreateSale(sale *models.OnboardSale) (created bool, err error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockCashLedger(tx, sale.ScheduledTripID); err != nil {
+		return false, err
+	}
+
+	if sale.ClientReference != nil {
+		err := tx.Get(sale, `
+			SELECT `+onboardSaleColumns+`
+			FROM trip_onboard_sales
+			WHERE scheduled_trip_id = $1 AND client_reference = $2`,
+			sale.ScheduledTripID, *sale.ClientReference)
+		if err == nil {
+			return false, nil
+		}
+		if err != sql.ErrNoRows {
+			return false, fmt.Errorf("failed to check for a repeated sale: %w", err)
+		}
+	}
+
+	sale.ID = uuid.New().String()
+	err = tx.QueryRow(`
+		INSERT INTO trip_onboard_sales (
+			id, scheduled_trip_id, sold_by_user_id, from_stop_id, to_stop_id, passenger_count, fare, amount,
+			client_reference, status, sold_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING sold_at`,
+		sale.ID, sale.ScheduledTripID, sale.SoldByUserID, sale.FromStopID, sale.ToStopID, sale.PassengerCount,
+		sale.Fare, sale.Amount, sale.ClientReference, sale.Status,
+	).Scan(&sale.SoldAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record onboard sale: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit onboard sale: %w", err)
+	}
+	return true, nil
+}
+
+// VoidSale takes a sale out of the trip's takings; returns nil if the trip has no such sale.
+// Returns ErrOnboardSaleVoided if it is already void, or ErrCashLedgerClosed once the trip's
+// cash has been handed in.
+func (r *CashLedgerRepository) VoidSale(scheduledTripID, saleID, userID, reason string) (*models.OnboardSale, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockCashLedger(tx, scheduledTripID); err != nil {
+		return nil, err
+	}
+
+	var sale models.OnboardSale
+	err = tx.Get(&sale, `
+		SELECT `+onboardSaleColumns+`
+		FROM trip_onboard_sales
+		WHERE id = $1 AND scheduled_trip_id = $2`, saleID, scheduledTripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get onboard sale: %w", err)
+	}
+	if sale.Status == models.OnboardSaleVoided {
+		return nil, ErrOnboardSaleVoided
+	}
+
+	err = tx.Get(&sale, `
+		UPDATE trip_onboard_sales
+		SET status = 'voided', void_reason = $2, voided_by_user_id = $3, voided_at = NOW()
+		WHERE id = $1
+		RETURNING `+onboardSaleColumns, saleID, reason, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to void onboard sale: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit voided sale: %w", err)
+	}
+	return &sale, nil
+}
+
+// lockCashLedger locks the trip so sales and the settlement cannot change concurrently, and
+// returns ErrCashLedgerClosed if its cash has been handed in
+func lockCashLedger(tx *sqlx.Tx, scheduledTripID string) error {
+	if _, err := tx.Exec(`SELECT id FROM scheduled_trips WHERE id = $1 FOR UPDATE`, scheduledTripID); err != nil {
+		return fmt.Errorf("failed to lock trip: %w", err)
+	}
+	settlement, err := getCashSettlement(tx, scheduledTripID)
+	if err != nil {
+		return err
+	}
+	if settlement.LocksLedger() {
+		return ErrCashLedgerClosed
+	}
+	return nil
+}
+
+// GetSettlement returns a trip's cash settlement; returns nil if the crew has not handed in
+func (r *CashLedgerRepository) GetSettlement(scheduledTripID string) (*models.TripCashSettlement, error) {
+	return getCashSettlement(r.db, scheduledTripID)
+}
+
+func getCashSettlement(q sqlx.Queryer, scheduledTripID string) (*models.TripCashSettlement, error) {
+	var settlement models.TripCashSettlement
+	err := sqlx.Get(q, &settlement, `SELECT `+cashSettlementColumns+` FROM trip_cash_settlements WHERE scheduled_trip_id = $1`, scheduledTripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cash settlement: %w", err)
+	}
+	return &settlement, nil
+}
+
+// SubmitSettlement hands in a trip's cash, totalling the ledger in the same transaction so no
+// sale can slip in between. A settlement may be resubmitted until the owner reconciles it,
+// which clears any earlier review. Returns ErrCashSettlementReconciled after that.
+func (r *CashLedgerRepository) SubmitSettlement(settlement *models.TripCashSettlement) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM scheduled_trips WHERE id = $1 FOR UPDATE`, settlement.ScheduledTripID); err != nil {
+		return fmt.Errorf("failed to lock trip: %w", err)
+	}
+	existing, err := getCashSettlement(tx, settlement.ScheduledTripID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Status == models.CashSettlementReconciled {
+		return ErrCashSettlementReconciled
+	}
+
+	totals, err := getCashTotals(tx, settlement.ScheduledTripID)
+	if err != nil {
+		return err
+	}
+	settlement.ApplyTotals(totals)
+	settlement.ID = uuid.New().String()
+	if existing != nil {
+		settlement.ID = existing.ID
+	}
+	settlement.Status = models.CashSettlementSubmitted
+
+	err = tx.Get(settlement, `
+		INSERT INTO trip_cash_settlements (
+			id, scheduled_trip_id, submitted_by_user_id, sale_count, passenger_count, onboard_sales_amount,
+			standing_ticket_count, standing_tickets_amount, expected_cash, declared_cash, variance, crew_note,
+			status, submitted_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())
+		ON CONFLICT (scheduled_trip_id) DO UPDATE
+		SET submitted_by_user_id = EXCLUDED.submitted_by_user_id, sale_count = EXCLUDED.sale_count,
+		    passenger_count = EXCLUDED.passenger_count, onboard_sales_amount = EXCLUDED.onboard_sales_amount,
+		    standing_ticket_count = EXCLUDED.standing_ticket_count,
+		    standing_tickets_amount = EXCLUDED.standing_tickets_amount, expected_cash = EXCLUDED.expected_cash,
+		    declared_cash = EXCLUDED.declared_cash, variance = EXCLUDED.variance, crew_note = EXCLUDED.crew_note,
+		    status = EXCLUDED.status, owner_note = NULL, reviewed_by_user_id = NULL, reviewed_at = NULL,
+		    submitted_at = NOW(), updated_at = NOW()
+		RETURNING `+cashSettlementColumns,
+		settlement.ID, settlement.ScheduledTripID, settlement.SubmittedByUserID, settlement.SaleCount,
+		settlement.PassengerCount, settlement.OnboardSalesAmount, settlement.StandingTicketCount,
+		settlement.StandingTicketsAmount, settlement.ExpectedCash, settlement.DeclaredCash, settlement.Variance,
+		settlement.CrewNote, settlement.Status,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save cash settlement: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cash settlement: %w", err)
+	}
+	return nil
+}
+
+// ReviewSettlement records the owner's decision on a submitted settlement; returns nil if the
+// trip has no settlement awaiting review
+func (r *CashLedgerRepository) ReviewSettlement(scheduledTripID string, status models.TripCashSettlementStatus, note *string, reviewerUserID string) (*models.TripCashSettlement, error) {
+	var settlement models.TripCashSettlement
+	err := r.db.Get(&settlement, `
+		UPDATE trip_cash_settlements
+		SET status = $2, owner_note = $3, reviewed_by_user_id = $4, reviewed_at = NOW(), updated_at = NOW()
+		WHERE scheduled_trip_id = $1 AND status = 'submitted'
+		RETURNING `+cashSettlementColumns, scheduledTripID, status, note, reviewerUserID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review cash settlement: %w", err)
+	}
+	return &settlement, nil
+}
+
+// ListOwnerSettlements returns a page of the settlements of an owner's trips, newest trips
+// first, with the total count
+func (r *CashLedgerRepository) ListOwnerSettlements(filter models.CashSettlementFilter) ([]models.TripCashSettlement, int, error) {
+	where := `
+		FROM trip_cash_settlements cs
+		JOIN scheduled_trips st ON st.id = cs.scheduled_trip_id
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		WHERE COALESCE(ts.bus_owner_id, bor.bus_owner_id) = $1`
+	args := []interface{}{filter.BusOwnerID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(` AND cs.status = $%d`, len(args))
+	}
+
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*)`+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count cash settlements: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT cs.id, cs.scheduled_trip_id, cs.submitted_by_user_id, cs.sale_count, cs.passenger_count,
+		       cs.onboard_sales_amount, cs.standing_ticket_count, cs.standing_tickets_amount, cs.expected_cash,
+		       cs.declared_cash, cs.variance, cs.crew_note, cs.status, cs.owner_note, cs.reviewed_by_user_id,
+		       cs.reviewed_at, cs.submitted_at, cs.updated_at, st.departure_datetime
+		%s
+		ORDER BY st.departure_datetime DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
+
+	settlements := []models.TripCashSettlement{}
+	if err := r.db.Select(&settlements, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list cash settlements: %w", err)
+	}
+	return settlements, total, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// searchOrigin and searchDestination name a search's stops: the matched stop, else the
+// passenger's input
+const (
+	searchOrigin      = `TRIM(COALESCE(fs.stop_name, sl.from_input))`
+	searchDestination = `TRIM(COALESCE(dst.stop_name, sl.to_input))`
+)
+
+// DemandAnalyticsRepository aggregates searches and bookings by origin, destination and hour
+type DemandAnalyticsRepository struct {
+	db *sqlx.DB
+}
+
+// NewDemandAnalyticsRepository creates a new DemandAnalyticsRepository
+func NewDemandAnalyticsRepository(db *sqlx.DB) *DemandAnalyticsRepository {
+	return &DemandAnalyticsRepository{db: db}
+}
+
+// GetSearchDemand returns searches made in [from, to) per origin, destination and local hour
+// of the requested departure (or of the search, when no time was requested). For a bus owner,
+// only searches between two stops on one of the owner's routes are counted.
+func (r *DemandAnalyticsRepository) GetSearchDemand(busOwnerID *string, from, to time.Time) ([]models.DemandCell, error) {
+	cells := []models.DemandCell{}
+	err := r.db.Select(&cells, `
+		SELECT
+			LOWER(`+searchOrigin+`) AS origin_key,
+			LOWER(`+searchDestination+`) AS destination_key,
+			MIN(`+searchOrigin+`) AS origin_stop,
+			MIN(`+searchDestination+`) AS destination_stop,
+			EXTRACT(HOUR FROM COALESCE(sl.requested_departure_at, sl.created_at) AT TIME ZONE 'Asia/Colombo')::int AS hour,
+			COUNT(*) AS searches,
+			COUNT(*) FILTER (WHERE sl.results_count = 0) AS zero_result_searches
+		FROM search_logs sl
+		LEFT JOIN master_route_stops fs ON sl.from_stop_id = fs.id
+		LEFT JOIN master_route_stops dst ON sl.to_stop_id = dst.id
+		WHERE sl.created_at >= $1 AND sl.created_at < $2
+		  AND ($3::uuid IS NULL OR EXISTS (
+			SELECT 1
+			FROM bus_owner_routes bor
+			JOIN master_route_stops a ON a.master_route_id = bor.master_route_id
+			JOIN master_route_stops b ON b.master_route_id = bor.master_route_id
+			WHERE bor.bus_owner_id = $3
+			  AND LOWER(a.stop_name) = LOWER(`+searchOrigin+`)
+			  AND LOWER(b.stop_name) = LOWER(`+searchDestination+`)
+		  ))
+		GROUP BY 1, 2, 5`, from, to, busOwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search demand: %w", err)
+	}
+	return cells, nil
+}
+
+// GetBookingDemand returns bus bookings made in [from, to) per boarding stop, alighting stop
+// and local departure hour, optionally only on one bus owner's trips. Unpaid and cancelled
+// bookings are left out.
+func (r *DemandAnalyticsRepository) GetBookingDemand(busOwnerID *string, from, to time.Time) ([]models.DemandCell, error) {
+	cells := []models.DemandCell{}
+	err := r.db.Select(&cells, `
+		SELECT
+			LOWER(TRIM(bs.stop_name)) AS origin_key,
+			LOWER(TRIM(als.stop_name)) AS destination_key,
+			MIN(TRIM(bs.stop_name)) AS origin_stop,
+			MIN(TRIM(als.stop_name)) AS destination_stop,
+			EXTRACT(HOUR FROM st.departure_datetime AT TIME ZONE 'Asia/Colombo')::int AS hour,
+			COUNT(*) AS bookings,
+			COALESCE(SUM(bb.number_of_seats), 0) AS seats_booked
+		FROM bus_bookings bb
+		JOIN scheduled_trips st ON bb.scheduled_trip_id = st.id
+		JOIN master_route_stops bs ON bb.boarding_stop_id = bs.id
+		JOIN master_route_stops als ON bb.alighting_stop_id = als.id
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		WHERE bb.created_at >= $1 AND bb.created_at < $2
+		  AND bb.status NOT IN ('pending', 'cancelled')
+		  AND ($3::uuid IS NULL OR COALESCE(ts.bus_owner_id, bor.bus_owner_id) = $3)
+		GROUP BY 1, 2, 5`, from, to, busOwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking demand: %w", err)
+	}
+	return cells, nil
+}
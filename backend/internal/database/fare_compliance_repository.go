@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// fareComplianceQuery selects non-cancelled trips priced above their permit's approved fare,
+// by base fare or any seat price override. Extra conditions on st are spliced in at %s.
+const fareComplianceQuery = `
+	SELECT st.id AS trip_id,
+	       COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS bus_owner_id,
+	       bo.company_name AS bus_owner_name,
+	       st.departure_datetime, st.is_bookable,
+	       rp.id AS permit_id, rp.permit_number, rp.approved_fare, st.base_fare,
+	       COALESCE(MAX(seat.seat_price), 0) AS max_seat_price,
+	       COUNT(seat.id) FILTER (WHERE seat.seat_price > rp.approved_fare) AS seats_above_fare
+	FROM scheduled_trips st
+	LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+	LEFT JOIN bus_owner_routes bor ON bor.id = st.bus_owner_route_id
+	LEFT JOIN bus_owners bo ON bo.id = COALESCE(ts.bus_owner_id, bor.bus_owner_id)
+	JOIN route_permits rp ON rp.id = COALESCE(st.permit_id, ts.permit_id)
+	LEFT JOIN trip_seats seat ON seat.scheduled_trip_id = st.id
+	WHERE st.status != 'cancelled'
+	  AND rp.approved_fare > 0
+	  %s
+	GROUP BY st.id, ts.bus_owner_id, bor.bus_owner_id, bo.company_name, rp.id
+	HAVING st.base_fare > rp.approved_fare OR COALESCE(MAX(seat.seat_price), 0) > rp.approved_fare`
+
+// FareComplianceRepository finds trips priced above their route permit's approved fare
+type FareComplianceRepository struct {
+	db *sqlx.DB
+}
+
+// NewFareComplianceRepository creates a new FareComplianceRepository
+func NewFareComplianceRepository(db *sqlx.DB) *FareComplianceRepository {
+	return &FareComplianceRepository{db: db}
+}
+
+// ListNonCompliant returns trips departing in [from, to) priced above their permit's fare,
+// soonest first, optionally for one bus owner and only those published for booking
+func (r *FareComplianceRepository) ListNonCompliant(from, to time.Time, busOwnerID *string, bookableOnly bool, limit, offset int) ([]models.FareComplianceTrip, int, error) {
+	query := fmt.Sprintf(fareComplianceQuery, `
+	  AND st.departure_datetime >= $1 AND st.departure_datetime < $2
+	  AND ($3::uuid IS NULL OR COALESCE(ts.bus_owner_id, bor.bus_owner_id) = $3)
+	  AND (NOT $4 OR st.is_bookable)`)
+
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM (`+query+`) flagged`, from, to, busOwnerID, bookableOnly); err != nil {
+		return nil, 0, fmt.Errorf("failed to count non-compliant trips: %w", err)
+	}
+
+	trips := []models.FareComplianceTrip{}
+	err := r.db.Select(&trips, query+`
+	ORDER BY st.departure_datetime
+	LIMIT $5 OFFSET $6`, from, to, busOwnerID, bookableOnly, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list non-compliant trips: %w", err)
+	}
+	return trips, total, nil
+}
+
+// GetNonCompliantTrips returns which of the given trips are priced above their permit's fare
+func (r *FareComplianceRepository) GetNonCompliantTrips(tripIDs []string) ([]models.FareComplianceTrip, error) {
+	trips := []models.FareComplianceTrip{}
+	if len(tripIDs) == 0 {
+		return trips, nil
+	}
+	query := fmt.Sprintf(fareComplianceQuery, `AND st.id::text = ANY($1::text[])`)
+	if err := r.db.Select(&trips, query+` ORDER BY st.departure_datetime`, pq.Array(tripIDs)); err != nil {
+		return nil, fmt.Errorf("failed to check trip fare compliance: %w", err)
+	}
+	return trips, nil
+}
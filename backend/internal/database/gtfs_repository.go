@@ -0,0 +1,125 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// GTFSRepository reads the routes, stops and published trips exported as the GTFS static feed
+type GTFSRepository struct {
+	db *sqlx.DB
+}
+
+// NewGTFSRepository creates a new GTFSRepository
+func NewGTFSRepository(db *sqlx.DB) *GTFSRepository {
+	return &GTFSRepository{db: db}
+}
+
+// gtfsTripColumns are the models.GTFSTrip columns of a scheduled trip st. Trips without their
+// own route take the schedule's; trips without a bus owner route take their permit's master route.
+const gtfsTripColumns = `
+	st.id AS trip_id, st.departure_datetime, st.estimated_duration_minutes, st.base_fare,
+	COALESCE(bor.master_route_id, rp.master_route_id) AS master_route_id,
+	bor.direction,
+	COALESCE(NULLIF(st.selected_stop_ids::text[], '{}'), bor.selected_stop_ids::text[]) AS stop_ids`
+
+// gtfsActiveTripColumns are the models.GTFSRealtimeTrip columns of an active trip at and its bus b
+const gtfsActiveTripColumns = `
+	at.bus_id, b.license_plate, at.current_latitude, at.current_longitude,
+	at.last_location_update, at.current_speed_kmh, at.heading,
+	at.current_stop_id, at.next_stop_id, at.stops_completed::text[] AS stops_completed,
+	at.actual_departure_time, at.estimated_arrival_time, at.status`
+
+// gtfsTripJoins joins a scheduled trip st to its route; only trips of active master routes match
+const gtfsTripJoins = `
+	LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+	LEFT JOIN bus_owner_routes bor ON bor.id = COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id)
+	LEFT JOIN route_permits rp ON rp.id = st.permit_id
+	JOIN master_routes mr ON mr.id = COALESCE(bor.master_route_id, rp.master_route_id) AND mr.is_active = true`
+
+// GetPublishedTrips returns the core tenant's published trips departing in [from, to) that are
+// not cancelled. Trips already running or done stay in, so realtime updates can refer to them.
+func (r *GTFSRepository) GetPublishedTrips(from, to time.Time) ([]models.GTFSTrip, error) {
+	trips := []models.GTFSTrip{}
+	err := r.db.Select(&trips, `
+		SELECT `+gtfsTripColumns+`
+		FROM scheduled_trips st`+gtfsTripJoins+`
+		WHERE st.is_bookable = true
+		  AND st.status IN ('scheduled', 'confirmed', 'in_progress', 'completed')
+		  AND st.tenant_id IS NULL
+		  AND st.departure_datetime >= $1 AND st.departure_datetime < $2
+		ORDER BY st.departure_datetime, st.id`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get published trips: %w", err)
+	}
+	return trips, nil
+}
+
+// GetTrackedTrips returns the core tenant's published trips with an active trip that has not
+// finished, with the bus's last reported state
+func (r *GTFSRepository) GetTrackedTrips() ([]models.GTFSRealtimeTrip, error) {
+	trips := []models.GTFSRealtimeTrip{}
+	err := r.db.Select(&trips, `
+		SELECT `+gtfsTripColumns+`,`+gtfsActiveTripColumns+`
+		FROM active_trips at
+		JOIN scheduled_trips st ON st.id = at.scheduled_trip_id`+gtfsTripJoins+`
+		LEFT JOIN buses b ON b.id = at.bus_id
+		WHERE at.status IN ('not_started', 'in_transit', 'at_stop')
+		  AND st.is_bookable = true
+		  AND st.tenant_id IS NULL
+		ORDER BY st.departure_datetime, st.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracked trips: %w", err)
+	}
+	return trips, nil
+}
+
+// GetCancelledTrips returns the core tenant's trips departing in [from, to) that were published
+// and then cancelled
+func (r *GTFSRepository) GetCancelledTrips(from, to time.Time) ([]models.GTFSTrip, error) {
+	trips := []models.GTFSTrip{}
+	err := r.db.Select(&trips, `
+		SELECT `+gtfsTripColumns+`
+		FROM scheduled_trips st`+gtfsTripJoins+`
+		WHERE st.status = 'cancelled'
+		  AND st.ever_published = true
+		  AND st.tenant_id IS NULL
+		  AND st.departure_datetime >= $1 AND st.departure_datetime < $2
+		ORDER BY st.departure_datetime, st.id`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cancelled trips: %w", err)
+	}
+	return trips, nil
+}
+
+// GetRoutesWithStops returns the given master routes and all of their stops
+func (r *GTFSRepository) GetRoutesWithStops(routeIDs []string) ([]models.MasterRoute, []models.MasterRouteStop, error) {
+	routes := []models.MasterRoute{}
+	stops := []models.MasterRouteStop{}
+	if len(routeIDs) == 0 {
+		return routes, stops, nil
+	}
+
+	err := r.db.Select(&routes, `
+		SELECT id, route_number, route_name, origin_city, destination_city, total_distance_km,
+			estimated_duration_minutes, encoded_polyline, is_active, created_at, updated_at
+		FROM master_routes
+		WHERE id = ANY($1)`, pq.Array(routeIDs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get master routes: %w", err)
+	}
+	err = r.db.Select(&stops, `
+		SELECT id, master_route_id, stop_name, stop_order, latitude, longitude,
+			arrival_time_offset_minutes, is_major_stop, created_at
+		FROM master_route_stops
+		WHERE master_route_id = ANY($1)
+		ORDER BY master_route_id, stop_order`, pq.Array(routeIDs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get master route stops: %w", err)
+	}
+	return routes, stops, nil
+}
@@ -418,9 +418,9 @@ func (r *LoungeBookingRepository) CreateLoungeBooking(
 			lounge_name, lounge_address, lounge_phone,
 			primary_guest_name, primary_guest_phone, promo_code, special_requests,
 			qr_code_data, qr_generated_at,
-			created_at, updated_at
+			created_at, updated_at, discount_funded_by, bundle_discount_rule_id, promo_discount_amount
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32
 		)
 	`
 	_, err = tx.Exec(bookingQuery,
@@ -433,7 +433,7 @@ func (r *LoungeBookingRepository) CreateLoungeBooking(
 		booking.LoungeName, booking.LoungeAddress, booking.LoungePhone,
 		booking.PrimaryGuestName, booking.PrimaryGuestPhone, booking.PromoCode, booking.SpecialRequests,
 		booking.QRCodeData, booking.QRGeneratedAt,
-		booking.CreatedAt, booking.UpdatedAt,
+		booking.CreatedAt, booking.UpdatedAt, booking.DiscountFundedBy, booking.BundleDiscountRuleID, booking.PromoDiscountAmount,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert booking: %w", err)
@@ -499,7 +499,7 @@ func (r *LoungeBookingRepository) GetLoungeBookingByID(bookingID uuid.UUID) (*mo
 			lb.discount_amount, lb.total_amount, lb.status, lb.payment_status,
 			lb.primary_guest_name, lb.primary_guest_phone, lb.promo_code, lb.special_requests,
 			lb.internal_notes, lb.cancelled_at, lb.cancellation_reason, lb.created_at, lb.updated_at,
-			lb.qr_code_data,
+			lb.qr_code_data, lb.promo_discount_amount,
 			l.lounge_name, l.address as lounge_address
 		FROM lounge_bookings lb
 		JOIN lounges l ON lb.lounge_id = l.id
@@ -515,7 +515,7 @@ func (r *LoungeBookingRepository) GetLoungeBookingByID(bookingID uuid.UUID) (*mo
 		&booking.DiscountAmount, &booking.TotalAmount, &booking.Status, &booking.PaymentStatus,
 		&booking.PrimaryGuestName, &booking.PrimaryGuestPhone, &booking.PromoCode, &booking.SpecialRequests,
 		&booking.InternalNotes, &booking.CancelledAt, &booking.CancellationReason, &booking.CreatedAt, &booking.UpdatedAt,
-		&booking.QRCodeData,
+		&booking.QRCodeData, &booking.PromoDiscountAmount,
 		&booking.LoungeName, &booking.LoungeAddress,
 	)
 	if err == sql.ErrNoRows {
@@ -982,3 +982,93 @@ func (r *LoungeBookingRepository) GetLoungePrice(loungeID uuid.UUID, pricingType
 	}
 	return price.String, nil
 }
+
+// ============================================================================
+// LOUNGE OWNER ANALYTICS
+// ============================================================================
+
+// loungeAnalyticsScope limits analytics to one owner's lounges, or one of them when $4 is set
+const loungeAnalyticsScope = `l.lounge_owner_id = $1 AND ($4::uuid IS NULL OR lb.lounge_id = $4)`
+
+// GetOwnerAnalyticsDays returns an owner's bookings and order revenue per local day of
+// scheduled arrival in [from, to). loungeID narrows it to one lounge.
+func (r *LoungeBookingRepository) GetOwnerAnalyticsDays(ownerID uuid.UUID, loungeID *uuid.UUID, from, to time.Time) ([]models.LoungeAnalyticsDay, error) {
+	days := []models.LoungeAnalyticsDay{}
+	err := r.db.Select(&days, `
+		WITH scoped AS (
+			SELECT lb.id, lb.status, lb.payment_status, lb.number_of_guests,
+				to_char(lb.scheduled_arrival AT TIME ZONE 'Asia/Colombo', 'YYYY-MM-DD') AS day
+			FROM lounge_bookings lb
+			JOIN lounges l ON l.id = lb.lounge_id
+			WHERE lb.scheduled_arrival >= $2 AND lb.scheduled_arrival < $3
+			  AND `+loungeAnalyticsScope+`
+		), pre_orders AS (
+			SELECT s.id, SUM(po.total_price) AS revenue
+			FROM scoped s
+			JOIN lounge_booking_pre_orders po ON po.lounge_booking_id = s.id
+			WHERE s.status <> 'cancelled' AND s.payment_status IN ('paid', 'partial', 'collect_on_site')
+			GROUP BY s.id
+		), orders AS (
+			SELECT s.id, SUM(lo.total_amount) AS revenue
+			FROM scoped s
+			JOIN lounge_orders lo ON lo.lounge_booking_id = s.id
+			WHERE lo.status <> 'cancelled' AND lo.payment_status NOT IN ('failed', 'refunded')
+			GROUP BY s.id
+		)
+		SELECT s.day,
+			COUNT(*) AS bookings,
+			COALESCE(SUM(s.number_of_guests), 0) AS guests,
+			COUNT(*) FILTER (WHERE s.status IN ('checked_in', 'in_lounge', 'checked_out', 'completed')) AS checked_in,
+			COUNT(*) FILTER (WHERE s.status = 'cancelled') AS cancelled,
+			COUNT(*) FILTER (WHERE s.status = 'no_show') AS no_shows,
+			COALESCE(SUM(p.revenue), 0) AS pre_order_revenue,
+			COALESCE(SUM(o.revenue), 0) AS in_lounge_order_revenue
+		FROM scoped s
+		LEFT JOIN pre_orders p ON p.id = s.id
+		LEFT JOIN orders o ON o.id = s.id
+		GROUP BY s.day
+		ORDER BY s.day`, ownerID, from, to, loungeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lounge analytics: %w", err)
+	}
+	return days, nil
+}
+
+// GetOwnerTopProducts returns the owner's best-selling products by order revenue for bookings
+// arriving in [from, to), counting the same pre-orders and in-lounge orders as the daily figures
+func (r *LoungeBookingRepository) GetOwnerTopProducts(ownerID uuid.UUID, loungeID *uuid.UUID, from, to time.Time, limit int) ([]models.LoungeProductPerformance, error) {
+	products := []models.LoungeProductPerformance{}
+	err := r.db.Select(&products, `
+		WITH sold AS (
+			SELECT po.product_id, po.product_name, po.created_at, po.quantity AS pre_order_quantity, 0 AS in_lounge_quantity, po.total_price AS revenue
+			FROM lounge_booking_pre_orders po
+			JOIN lounge_bookings lb ON lb.id = po.lounge_booking_id
+			JOIN lounges l ON l.id = lb.lounge_id
+			WHERE lb.scheduled_arrival >= $2 AND lb.scheduled_arrival < $3
+			  AND `+loungeAnalyticsScope+`
+			  AND lb.status <> 'cancelled' AND lb.payment_status IN ('paid', 'partial', 'collect_on_site')
+			UNION ALL
+			SELECT oi.product_id, oi.product_name, oi.created_at, 0, oi.quantity, oi.total_price
+			FROM lounge_order_items oi
+			JOIN lounge_orders lo ON lo.id = oi.order_id
+			JOIN lounge_bookings lb ON lb.id = lo.lounge_booking_id
+			JOIN lounges l ON l.id = lb.lounge_id
+			WHERE lb.scheduled_arrival >= $2 AND lb.scheduled_arrival < $3
+			  AND `+loungeAnalyticsScope+`
+			  AND lo.status <> 'cancelled' AND lo.payment_status NOT IN ('failed', 'refunded')
+		)
+		SELECT product_id::text AS product_id,
+			-- Names are snapshots; show the latest one the product sold under
+			(ARRAY_AGG(product_name ORDER BY created_at DESC))[1] AS product_name,
+			SUM(pre_order_quantity) AS pre_order_quantity,
+			SUM(in_lounge_quantity) AS in_lounge_quantity,
+			COALESCE(SUM(revenue), 0) AS revenue
+		FROM sold
+		GROUP BY product_id
+		ORDER BY revenue DESC, product_name
+		LIMIT $5`, ownerID, from, to, loungeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lounge top products: %w", err)
+	}
+	return products, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// LoungeBundleDiscountRepository handles discounts on lounge stays booked with a bus ticket
+type LoungeBundleDiscountRepository struct {
+	db *sqlx.DB
+}
+
+// NewLoungeBundleDiscountRepository creates a new LoungeBundleDiscountRepository
+func NewLoungeBundleDiscountRepository(db *sqlx.DB) *LoungeBundleDiscountRepository {
+	return &LoungeBundleDiscountRepository{db: db}
+}
+
+const loungeBundleDiscountColumns = `
+	id, lounge_id, name, funded_by, booking_type, discount_percent, max_discount_amount,
+	valid_from, valid_until, is_active, created_at, updated_at`
+
+// CreateRule stores a bundle discount rule
+func (r *LoungeBundleDiscountRepository) CreateRule(rule *models.LoungeBundleDiscountRule) error {
+	rule.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO lounge_bundle_discount_rules (
+			id, lounge_id, name, funded_by, booking_type, discount_percent, max_discount_amount,
+			valid_from, valid_until, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING created_at, updated_at`,
+		rule.ID, rule.LoungeID, rule.Name, rule.FundedBy, rule.BookingType, rule.DiscountPercent,
+		rule.MaxDiscountAmount, rule.ValidFrom, rule.ValidUntil, rule.IsActive,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle discount rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateRule saves a bundle discount rule; who funds it never changes
+func (r *LoungeBundleDiscountRepository) UpdateRule(rule *models.LoungeBundleDiscountRule) error {
+	err := r.db.QueryRow(`
+		UPDATE lounge_bundle_discount_rules
+		SET lounge_id = $2, name = $3, booking_type = $4, discount_percent = $5, max_discount_amount = $6,
+		    valid_from = $7, valid_until = $8, is_active = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		rule.ID, rule.LoungeID, rule.Name, rule.BookingType, rule.DiscountPercent, rule.MaxDiscountAmount,
+		rule.ValidFrom, rule.ValidUntil, rule.IsActive,
+	).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update bundle discount rule: %w", err)
+	}
+	return nil
+}
+
+// GetRule returns a bundle discount rule; returns nil if not found
+func (r *LoungeBundleDiscountRepository) GetRule(ruleID string) (*models.LoungeBundleDiscountRule, error) {
+	var rule models.LoungeBundleDiscountRule
+	err := r.db.Get(&rule, `SELECT `+loungeBundleDiscountColumns+` FROM lounge_bundle_discount_rules WHERE id = $1`, ruleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get bundle discount rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// ListRules returns the rules funded by fundedBy, including inactive ones. loungeID, when set,
+// limits them to that lounge.
+func (r *LoungeBundleDiscountRepository) ListRules(fundedBy models.BundleDiscountFunder, loungeID *string) ([]models.LoungeBundleDiscountRule, error) {
+	rules := []models.LoungeBundleDiscountRule{}
+	err := r.db.Select(&rules, `
+		SELECT `+loungeBundleDiscountColumns+`
+		FROM lounge_bundle_discount_rules
+		WHERE funded_by = $1 AND ($2::text IS NULL OR lounge_id::text = $2)
+		ORDER BY is_active DESC, created_at DESC`, fundedBy, loungeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bundle discount rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetActiveRules returns the active rules that may apply to a lounge: its own and the platform's
+// lounge-wide ones, leaving out those already ended
+func (r *LoungeBundleDiscountRepository) GetActiveRules(loungeID string) ([]models.LoungeBundleDiscountRule, error) {
+	rules := []models.LoungeBundleDiscountRule{}
+	err := r.db.Select(&rules, `
+		SELECT `+loungeBundleDiscountColumns+`
+		FROM lounge_bundle_discount_rules
+		WHERE is_active = true
+		  AND (lounge_id IS NULL OR lounge_id::text = $1)
+		  AND (valid_until IS NULL OR valid_until > NOW())`, loungeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active bundle discount rules: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteRule removes a bundle discount rule. Bookings keep the discount they were given.
+func (r *LoungeBundleDiscountRepository) DeleteRule(ruleID string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM lounge_bundle_discount_rules WHERE id = $1`, ruleID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete bundle discount rule: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
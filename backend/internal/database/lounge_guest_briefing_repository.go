@@ -0,0 +1,116 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// LoungeGuestBriefingRepository reads the guests lounges are expecting and records which
+// lounges have had their morning briefing
+type LoungeGuestBriefingRepository struct {
+	db *sqlx.DB
+}
+
+// NewLoungeGuestBriefingRepository creates a new LoungeGuestBriefingRepository
+func NewLoungeGuestBriefingRepository(db *sqlx.DB) *LoungeGuestBriefingRepository {
+	return &LoungeGuestBriefingRepository{db: db}
+}
+
+// GetExpectedGuests returns a lounge's pending and confirmed bookings arriving in [from, to),
+// with their linked bus and its live position when the trip is running
+func (r *LoungeGuestBriefingRepository) GetExpectedGuests(loungeID string, from, to time.Time) ([]models.ExpectedLoungeGuest, error) {
+	var guests []models.ExpectedLoungeGuest
+	err := r.db.Select(&guests, `
+		SELECT lb.id AS lounge_booking_id, lb.booking_reference, lb.booking_type, lb.status,
+		       lb.primary_guest_name, lb.primary_guest_phone, lb.number_of_guests,
+		       lb.scheduled_arrival, lb.special_requests,
+		       st.id AS scheduled_trip_id,
+		       COALESCE(mr.route_name, bor.custom_route_name) AS route_name,
+		       st.departure_datetime,
+		       at.status AS bus_status,
+		       at.current_latitude AS bus_latitude,
+		       at.current_longitude AS bus_longitude,
+		       at.current_speed_kmh AS bus_speed_kmh,
+		       l.latitude::float8 AS lounge_latitude,
+		       l.longitude::float8 AS lounge_longitude
+		FROM lounge_bookings lb
+		JOIN lounges l ON lb.lounge_id = l.id
+		LEFT JOIN bus_bookings bb ON lb.bus_booking_id = bb.id
+		LEFT JOIN scheduled_trips st ON bb.scheduled_trip_id = st.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN master_routes mr ON bor.master_route_id = mr.id
+		LEFT JOIN active_trips at ON at.scheduled_trip_id = st.id AND at.status IN ('in_transit', 'at_stop')
+		WHERE lb.lounge_id = $1
+		  AND lb.status IN ('pending', 'confirmed')
+		  AND lb.scheduled_arrival >= $2
+		  AND lb.scheduled_arrival < $3
+		ORDER BY lb.scheduled_arrival`, loungeID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expected lounge guests: %w", err)
+	}
+	return guests, nil
+}
+
+// GetPreOrders returns the pre-ordered items of the given lounge bookings
+func (r *LoungeGuestBriefingRepository) GetPreOrders(bookingIDs []string) ([]models.LoungeBookingPreOrder, error) {
+	if len(bookingIDs) == 0 {
+		return nil, nil
+	}
+	var preOrders []models.LoungeBookingPreOrder
+	err := r.db.Select(&preOrders, `
+		SELECT id, lounge_booking_id, product_id, product_name, product_type, product_image_url, quantity, unit_price, total_price, created_at
+		FROM lounge_booking_pre_orders
+		WHERE lounge_booking_id = ANY($1)
+		ORDER BY created_at ASC`, pq.Array(bookingIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lounge pre-orders: %w", err)
+	}
+	return preOrders, nil
+}
+
+// ListUnbriefedLounges returns operational lounges with guests expected in [from, to) that
+// have not had their briefing for the day
+func (r *LoungeGuestBriefingRepository) ListUnbriefedLounges(day string, from, to time.Time) ([]models.LoungeBriefingTarget, error) {
+	var lounges []models.LoungeBriefingTarget
+	err := r.db.Select(&lounges, `
+		SELECT l.id AS lounge_id, l.lounge_name
+		FROM lounges l
+		WHERE l.is_operational = true
+		  AND EXISTS (
+			SELECT 1 FROM lounge_bookings lb
+			WHERE lb.lounge_id = l.id
+			  AND lb.status IN ('pending', 'confirmed')
+			  AND lb.scheduled_arrival >= $2
+			  AND lb.scheduled_arrival < $3
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM lounge_guest_briefings gb
+			WHERE gb.lounge_id = l.id AND gb.briefing_date = $1
+		  )
+		ORDER BY l.lounge_name`, day, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lounges to brief: %w", err)
+	}
+	return lounges, nil
+}
+
+// ClaimBriefing records that a lounge's briefing for the day is being sent. Returns false if
+// it already was, so a briefing goes out once even with several servers running the job.
+func (r *LoungeGuestBriefingRepository) ClaimBriefing(loungeID, day string, guests int) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO lounge_guest_briefings (lounge_id, briefing_date, guests, sent_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (lounge_id, briefing_date) DO NOTHING`, loungeID, day, guests)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim lounge briefing: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// LoungeNoShowRepository handles lounge no-show policies and no-show marking
+type LoungeNoShowRepository struct {
+	db *sqlx.DB
+}
+
+// NewLoungeNoShowRepository creates a new LoungeNoShowRepository
+func NewLoungeNoShowRepository(db *sqlx.DB) *LoungeNoShowRepository {
+	return &LoungeNoShowRepository{db: db}
+}
+
+// GetPolicy returns a lounge's no-show policy; returns nil if never configured
+func (r *LoungeNoShowRepository) GetPolicy(loungeID string) (*models.LoungeNoShowPolicy, error) {
+	var policy models.LoungeNoShowPolicy
+	err := r.db.Get(&policy, `
+		SELECT lounge_id, grace_minutes, fee_type, fee_value, updated_at
+		FROM lounge_no_show_policies
+		WHERE lounge_id = $1`, loungeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get lounge no-show policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// UpsertPolicy saves a lounge's no-show policy
+func (r *LoungeNoShowRepository) UpsertPolicy(policy *models.LoungeNoShowPolicy) error {
+	err := r.db.QueryRow(`
+		INSERT INTO lounge_no_show_policies (lounge_id, grace_minutes, fee_type, fee_value, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (lounge_id) DO UPDATE
+		SET grace_minutes = EXCLUDED.grace_minutes,
+		    fee_type = EXCLUDED.fee_type,
+		    fee_value = EXCLUDED.fee_value,
+		    updated_at = NOW()
+		RETURNING updated_at`,
+		policy.LoungeID, policy.GraceMinutes, policy.FeeType, policy.FeeValue,
+	).Scan(&policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save lounge no-show policy: %w", err)
+	}
+	return nil
+}
+
+// GetDueNoShows returns confirmed bookings whose guests have not checked in by the end of
+// their lounge's grace period. Lounges without a policy use defaultGraceMinutes and forfeit
+// the full amount.
+func (r *LoungeNoShowRepository) GetDueNoShows(defaultGraceMinutes, limit int) ([]models.DueLoungeNoShow, error) {
+	query := `
+		SELECT lb.id AS lounge_booking_id, lb.booking_reference, lb.user_id, lb.lounge_id,
+			   l.lounge_name, lb.scheduled_arrival, COALESCE(lb.total_amount, 0) AS total_amount,
+			   lb.payment_status, lb.primary_guest_phone,
+			   COALESCE(p.fee_type, 'full') AS fee_type, COALESCE(p.fee_value, 0) AS fee_value
+		FROM lounge_bookings lb
+		JOIN lounges l ON lb.lounge_id = l.id
+		LEFT JOIN lounge_no_show_policies p ON p.lounge_id = lb.lounge_id
+		WHERE lb.status = 'confirmed'
+		  AND lb.actual_arrival IS NULL
+		  AND lb.scheduled_arrival < NOW() - COALESCE(p.grace_minutes, $1::int) * INTERVAL '1 minute'
+		ORDER BY lb.scheduled_arrival
+		LIMIT $2
+	`
+	due := []models.DueLoungeNoShow{}
+	if err := r.db.Select(&due, query, defaultGraceMinutes, limit); err != nil {
+		return nil, fmt.Errorf("failed to get due lounge no-shows: %w", err)
+	}
+	return due, nil
+}
+
+// MarkNoShow marks a still-confirmed booking as no_show with its fee. Capacity is released
+// because availability only counts pending, confirmed and checked-in bookings. Returns false
+// if the guest checked in or the booking changed in the meantime.
+func (r *LoungeNoShowRepository) MarkNoShow(bookingID uuid.UUID, fee float64) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE lounge_bookings
+		SET status = 'no_show', no_show_fee = $2, no_show_marked_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'confirmed' AND actual_arrival IS NULL`,
+		bookingID, fee)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark lounge booking no-show: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
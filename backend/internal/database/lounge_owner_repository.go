@@ -8,16 +8,18 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
 )
 
 // LoungeOwnerRepository handles database operations for lounge owners
 type LoungeOwnerRepository struct {
-	db *sqlx.DB
+	db  *sqlx.DB
+	pii *fieldcrypt.Cipher
 }
 
-// NewLoungeOwnerRepository creates a new lounge owner repository
-func NewLoungeOwnerRepository(db *sqlx.DB) *LoungeOwnerRepository {
-	return &LoungeOwnerRepository{db: db}
+// NewLoungeOwnerRepository creates a new lounge owner repository; pii may be nil to store PII in plaintext
+func NewLoungeOwnerRepository(db *sqlx.DB, pii *fieldcrypt.Cipher) *LoungeOwnerRepository {
+	return &LoungeOwnerRepository{db: db, pii: pii}
 }
 
 // CreateLoungeOwner creates a new lounge owner record after OTP verification (Step 0)
@@ -79,6 +81,9 @@ func (r *LoungeOwnerRepository) GetLoungeOwnerByUserID(userID uuid.UUID) (*model
 	if err != nil {
 		return nil, fmt.Errorf("failed to get lounge owner: %w", err)
 	}
+	if err := decryptPIINull(r.pii, &owner.ManagerNICNumber); err != nil {
+		return nil, err
+	}
 	return &owner, nil
 }
 
@@ -102,6 +107,9 @@ func (r *LoungeOwnerRepository) GetLoungeOwnerByID(id uuid.UUID) (*models.Lounge
 	if err != nil {
 		return nil, fmt.Errorf("failed to get lounge owner: %w", err)
 	}
+	if err := decryptPIINull(r.pii, &owner.ManagerNICNumber); err != nil {
+		return nil, err
+	}
 	return &owner, nil
 }
 
@@ -134,12 +142,17 @@ func (r *LoungeOwnerRepository) UpdateBusinessAndManagerInfo(
 		emailValue = nil
 	}
 
+	storedNIC, err := encryptPII(r.pii, managerNICNumber)
+	if err != nil {
+		return err
+	}
+
 	result, err := r.db.Exec(
 		query,
 		businessName,
 		businessLicense,
 		managerFullName,
-		managerNICNumber,
+		storedNIC,
 		emailValue,
 		models.RegStepBusinessInfo,
 		userID,
@@ -201,12 +214,17 @@ func (r *LoungeOwnerRepository) UpdateBusinessAndManagerInfoWithNIC(
 		emailValue = nil
 	}
 
+	storedNIC, err := encryptPII(r.pii, managerNICNumber)
+	if err != nil {
+		return err
+	}
+
 	result, err := r.db.Exec(
 		query,
 		businessName,
 		businessLicenseValue,
 		managerFullName,
-		managerNICNumber,
+		storedNIC,
 		emailValue,
 		models.RegStepBusinessInfo,
 		userID,
@@ -366,6 +384,11 @@ func (r *LoungeOwnerRepository) GetPendingLoungeOwners(limit int, offset int) ([
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pending lounge owners: %w", err)
 	}
+	for i := range owners {
+		if err := decryptPIINull(r.pii, &owners[i].ManagerNICNumber); err != nil {
+			return nil, err
+		}
+	}
 
 	return owners, nil
 }
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// LoungePricingRepository handles lounge price rules and surge settings
+type LoungePricingRepository struct {
+	db *sqlx.DB
+}
+
+// NewLoungePricingRepository creates a new LoungePricingRepository
+func NewLoungePricingRepository(db *sqlx.DB) *LoungePricingRepository {
+	return &LoungePricingRepository{db: db}
+}
+
+// Times and dates are read back in the "HH:MM" / "YYYY-MM-DD" form the rules are written in
+const loungePriceRuleColumns = `
+	id, lounge_id, name, rule_type, pricing_type, days_of_week,
+	TO_CHAR(start_time, 'HH24:MI') as start_time, TO_CHAR(end_time, 'HH24:MI') as end_time,
+	TO_CHAR(start_date, 'YYYY-MM-DD') as start_date, TO_CHAR(end_date, 'YYYY-MM-DD') as end_date,
+	adjustment_type, value, priority, is_active, created_at, updated_at`
+
+// ============================================================================
+// PRICE RULES
+// ============================================================================
+
+// CreateRule stores a price rule
+func (r *LoungePricingRepository) CreateRule(rule *models.LoungePriceRule) error {
+	rule.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO lounge_price_rules (
+			id, lounge_id, name, rule_type, pricing_type, days_of_week, start_time, end_time,
+			start_date, end_date, adjustment_type, value, priority, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7::time, $8::time, $9::date, $10::date, $11, $12, $13, $14, NOW(), NOW())
+		RETURNING created_at, updated_at`,
+		rule.ID, rule.LoungeID, rule.Name, rule.RuleType, rule.PricingType, rule.DaysOfWeek,
+		rule.StartTime, rule.EndTime, rule.StartDate, rule.EndDate, rule.AdjustmentType,
+		rule.Value, rule.Priority, rule.IsActive,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create lounge price rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateRule saves a price rule
+func (r *LoungePricingRepository) UpdateRule(rule *models.LoungePriceRule) error {
+	err := r.db.QueryRow(`
+		UPDATE lounge_price_rules
+		SET name = $2, rule_type = $3, pricing_type = $4, days_of_week = $5, start_time = $6::time,
+		    end_time = $7::time, start_date = $8::date, end_date = $9::date, adjustment_type = $10,
+		    value = $11, priority = $12, is_active = $13, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		rule.ID, rule.Name, rule.RuleType, rule.PricingType, rule.DaysOfWeek, rule.StartTime,
+		rule.EndTime, rule.StartDate, rule.EndDate, rule.AdjustmentType, rule.Value,
+		rule.Priority, rule.IsActive,
+	).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update lounge price rule: %w", err)
+	}
+	return nil
+}
+
+// GetRule returns a lounge's price rule; returns nil if not found
+func (r *LoungePricingRepository) GetRule(loungeID, ruleID string) (*models.LoungePriceRule, error) {
+	var rule models.LoungePriceRule
+	err := r.db.Get(&rule, `SELECT `+loungePriceRuleColumns+` FROM lounge_price_rules WHERE id = $1 AND lounge_id = $2`, ruleID, loungeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get lounge price rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// ListRules returns a lounge's price rules, including inactive ones
+func (r *LoungePricingRepository) ListRules(loungeID string) ([]models.LoungePriceRule, error) {
+	rules := []models.LoungePriceRule{}
+	err := r.db.Select(&rules, `
+		SELECT `+loungePriceRuleColumns+`
+		FROM lounge_price_rules
+		WHERE lounge_id = $1
+		ORDER BY rule_type, priority DESC, created_at`, loungeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lounge price rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetActiveRules returns a lounge's active price rules
+func (r *LoungePricingRepository) GetActiveRules(loungeID string) ([]models.LoungePriceRule, error) {
+	rules := []models.LoungePriceRule{}
+	err := r.db.Select(&rules, `
+		SELECT `+loungePriceRuleColumns+`
+		FROM lounge_price_rules
+		WHERE lounge_id = $1 AND is_active = true
+		  AND (end_date IS NULL OR end_date >= CURRENT_DATE - 1)`, loungeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active lounge price rules: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteRule removes a lounge's price rule
+func (r *LoungePricingRepository) DeleteRule(loungeID, ruleID string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM lounge_price_rules WHERE id = $1 AND lounge_id = $2`, ruleID, loungeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete lounge price rule: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ============================================================================
+// SURGE SETTINGS
+// ============================================================================
+
+// GetSurgeSettings returns a lounge's surge settings; returns nil if never configured
+func (r *LoungePricingRepository) GetSurgeSettings(loungeID string) (*models.LoungeSurgeSettings, error) {
+	var settings models.LoungeSurgeSettings
+	err := r.db.Get(&settings, `
+		SELECT lounge_id, enabled, occupancy_threshold_percent, max_surge_percent, updated_at
+		FROM lounge_surge_settings
+		WHERE lounge_id = $1`, loungeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get lounge surge settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpsertSurgeSettings saves a lounge's surge settings
+func (r *LoungePricingRepository) UpsertSurgeSettings(settings *models.LoungeSurgeSettings) error {
+	err := r.db.QueryRow(`
+		INSERT INTO lounge_surge_settings (lounge_id, enabled, occupancy_threshold_percent, max_surge_percent, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (lounge_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+		    occupancy_threshold_percent = EXCLUDED.occupancy_threshold_percent,
+		    max_surge_percent = EXCLUDED.max_surge_percent,
+		    updated_at = NOW()
+		RETURNING updated_at`,
+		settings.LoungeID, settings.Enabled, settings.OccupancyThresholdPercent, settings.MaxSurgePercent,
+	).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save lounge surge settings: %w", err)
+	}
+	return nil
+}
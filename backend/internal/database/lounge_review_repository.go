@@ -0,0 +1,141 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// loungeReviewColumns selects a lounge review
+const loungeReviewColumns = `
+	id, lounge_booking_id, lounge_id, lounge_product_id, user_id, rating, comment, created_at`
+
+// LoungeReviewRepository handles lounge_reviews and the rating aggregates kept on lounges and
+// lounge_products
+type LoungeReviewRepository struct {
+	db *sqlx.DB
+}
+
+// NewLoungeReviewRepository creates a new LoungeReviewRepository
+func NewLoungeReviewRepository(db *sqlx.DB) *LoungeReviewRepository {
+	return &LoungeReviewRepository{db: db}
+}
+
+// Create inserts a review and recomputes the reviewed lounge's or product's average rating in
+// the same transaction. The booking row is locked so concurrent submissions cannot both pass
+// the one-review check; returns false without inserting if the booking already has this review.
+func (r *LoungeReviewRepository) Create(review *models.LoungeReview) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM lounge_bookings WHERE id = $1 FOR UPDATE`, review.LoungeBookingID); err != nil {
+		return false, fmt.Errorf("failed to lock lounge booking: %w", err)
+	}
+
+	var exists bool
+	err = tx.Get(&exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM lounge_reviews
+			WHERE lounge_booking_id = $1 AND lounge_product_id IS NOT DISTINCT FROM $2
+		)`, review.LoungeBookingID, review.LoungeProductID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check existing lounge review: %w", err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	review.ID = uuid.New()
+	err = tx.QueryRow(`
+		INSERT INTO lounge_reviews (id, lounge_booking_id, lounge_id, lounge_product_id, user_id, rating, comment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at`,
+		review.ID, review.LoungeBookingID, review.LoungeID, review.LoungeProductID, review.UserID, review.Rating, review.Comment,
+	).Scan(&review.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create lounge review: %w", err)
+	}
+
+	if review.LoungeProductID == nil {
+		_, err = tx.Exec(`
+			UPDATE lounges
+			SET average_rating = (
+				SELECT ROUND(AVG(rating), 2) FROM lounge_reviews
+				WHERE lounge_id = $1 AND lounge_product_id IS NULL
+			), updated_at = NOW()
+			WHERE id = $1`, review.LoungeID)
+	} else {
+		_, err = tx.Exec(`
+			UPDATE lounge_products p
+			SET average_rating = agg.average_rating, total_reviews = agg.total_reviews, updated_at = NOW()
+			FROM (
+				SELECT ROUND(AVG(rating), 2) AS average_rating, COUNT(*) AS total_reviews
+				FROM lounge_reviews WHERE lounge_product_id = $1
+			) agg
+			WHERE p.id = $1`, review.LoungeProductID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update review aggregates: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit lounge review: %w", err)
+	}
+	return true, nil
+}
+
+// BookingHasProduct reports whether a product was pre-ordered or ordered in the lounge on a booking
+func (r *LoungeReviewRepository) BookingHasProduct(bookingID, productID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM lounge_booking_pre_orders WHERE lounge_booking_id = $1 AND product_id = $2
+		) OR EXISTS (
+			SELECT 1 FROM lounge_order_items oi
+			JOIN lounge_orders o ON o.id = oi.order_id
+			WHERE o.lounge_booking_id = $1 AND oi.product_id = $2 AND o.status <> 'cancelled'
+		)`, bookingID, productID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check booking products: %w", err)
+	}
+	return exists, nil
+}
+
+// ListForLounge returns a page of reviews of the lounge itself, newest first, with their
+// aggregate rating
+func (r *LoungeReviewRepository) ListForLounge(loungeID uuid.UUID, limit, offset int) (*models.LoungeReviewPage, error) {
+	return r.list(`lounge_id = $1 AND lounge_product_id IS NULL`, loungeID, limit, offset)
+}
+
+// ListForProduct returns a page of a lounge product's reviews, newest first, with their
+// aggregate rating
+func (r *LoungeReviewRepository) ListForProduct(productID uuid.UUID, limit, offset int) (*models.LoungeReviewPage, error) {
+	return r.list(`lounge_product_id = $1`, productID, limit, offset)
+}
+
+func (r *LoungeReviewRepository) list(where string, id uuid.UUID, limit, offset int) (*models.LoungeReviewPage, error) {
+	page := &models.LoungeReviewPage{Reviews: []models.LoungeReview{}, Limit: limit, Offset: offset}
+
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), ROUND(AVG(rating), 2)::text
+		FROM lounge_reviews WHERE `+where, id).Scan(&page.TotalReviews, &page.AverageRating)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count lounge reviews: %w", err)
+	}
+
+	err = r.db.Select(&page.Reviews, `
+		SELECT `+loungeReviewColumns+`
+		FROM lounge_reviews
+		WHERE `+where+`
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3`, id, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lounge reviews: %w", err)
+	}
+	return page, nil
+}
@@ -8,16 +8,28 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
 )
 
 // LoungeStaffRepository handles database operations for lounge staff
 type LoungeStaffRepository struct {
-	db *sqlx.DB
+	db  *sqlx.DB
+	pii *fieldcrypt.Cipher
 }
 
-// NewLoungeStaffRepository creates a new lounge staff repository
-func NewLoungeStaffRepository(db *sqlx.DB) *LoungeStaffRepository {
-	return &LoungeStaffRepository{db: db}
+// NewLoungeStaffRepository creates a new lounge staff repository; pii may be nil to store PII in plaintext
+func NewLoungeStaffRepository(db *sqlx.DB, pii *fieldcrypt.Cipher) *LoungeStaffRepository {
+	return &LoungeStaffRepository{db: db, pii: pii}
+}
+
+// decryptStaff decrypts the NIC of each staff member in place
+func (r *LoungeStaffRepository) decryptStaff(staff []models.LoungeStaff) error {
+	for i := range staff {
+		if err := decryptPIINull(r.pii, &staff[i].NICNumber); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // AddStaffToLounge adds a staff member to a lounge (owner invites staff)
@@ -74,6 +86,9 @@ func (r *LoungeStaffRepository) GetStaffByID(id uuid.UUID) (*models.LoungeStaff,
 	if err != nil {
 		return nil, fmt.Errorf("failed to get staff: %w", err)
 	}
+	if err := decryptPIINull(r.pii, &staff.NICNumber); err != nil {
+		return nil, err
+	}
 	return &staff, nil
 }
 
@@ -89,6 +104,9 @@ func (r *LoungeStaffRepository) GetStaffByLoungeID(loungeID uuid.UUID) ([]models
 	if err != nil {
 		return nil, fmt.Errorf("failed to get staff: %w", err)
 	}
+	if err := r.decryptStaff(staff); err != nil {
+		return nil, err
+	}
 	return staff, nil
 }
 
@@ -103,6 +121,9 @@ func (r *LoungeStaffRepository) GetStaffByUserID(userID uuid.UUID) (*models.Loun
 	if err != nil {
 		return nil, fmt.Errorf("failed to get staff: %w", err)
 	}
+	if err := decryptPIINull(r.pii, &staff.NICNumber); err != nil {
+		return nil, err
+	}
 	return &staff, nil
 }
 
@@ -131,7 +152,12 @@ func (r *LoungeStaffRepository) UpdateStaffProfile(
 		emailValue = nil
 	}
 
-	result, err := r.db.Exec(query, fullName, nicNumber, emailValue, userID)
+	storedNIC, err := encryptPII(r.pii, nicNumber)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, fullName, storedNIC, emailValue, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update staff profile: %w", err)
 	}
@@ -191,6 +217,9 @@ func (r *LoungeStaffRepository) GetActiveStaffByLoungeID(loungeID uuid.UUID) ([]
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active staff: %w", err)
 	}
+	if err := r.decryptStaff(staff); err != nil {
+		return nil, err
+	}
 	return staff, nil
 }
 
@@ -214,5 +243,14 @@ func (r *LoungeStaffRepository) GetStaffWithUserDetails(staffID uuid.UUID) (map[
 		return nil, fmt.Errorf("failed to get staff with user details: %w", err)
 	}
 
+	// MapScan returns text columns as []byte
+	if raw, ok := result["nic_number"].([]byte); ok {
+		nic := string(raw)
+		if err := decryptPII(r.pii, &nic); err != nil {
+			return nil, err
+		}
+		result["nic_number"] = nic
+	}
+
 	return result, nil
 }
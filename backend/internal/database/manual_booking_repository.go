@@ -94,12 +94,16 @@ func (r *ManualBookingRepository) Create(booking *models.ManualSeatBooking, seat
 			booking_reference, scheduled_trip_id, created_by_user_id, booking_type,
 			passenger_name, passenger_phone, passenger_nic, passenger_notes,
 			boarding_stop_id, alighting_stop_id,
+			boarding_stop_order, alighting_stop_order,
 			departure_datetime, number_of_seats, total_fare,
 			payment_status, amount_paid, payment_method, payment_notes,
 			status, confirmed_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
-		) RETURNING id, created_at, updated_at
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+			(SELECT stop_order FROM master_route_stops WHERE id = $9),
+			(SELECT stop_order FROM master_route_stops WHERE id = $10),
+			$11, $12, $13, $14, $15, $16, $17, $18, $19
+		) RETURNING id, boarding_stop_order, alighting_stop_order, created_at, updated_at
 	`
 
 	now := time.Now()
@@ -123,7 +127,7 @@ func (r *ManualBookingRepository) Create(booking *models.ManualSeatBooking, seat
 		booking.PaymentNotes,
 		models.ManualBookingStatusConfirmed,
 		now,
-	).Scan(&booking.ID, &booking.CreatedAt, &booking.UpdatedAt)
+	).Scan(&booking.ID, &booking.BoardingStopOrder, &booking.AlightingStopOrder, &booking.CreatedAt, &booking.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert manual booking: %w", err)
 	}
@@ -183,6 +187,10 @@ func (r *ManualBookingRepository) Create(booking *models.ManualSeatBooking, seat
 		}
 	}
 
+	if err := syncTripSeatCounters(tx, booking.ScheduledTripID); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -380,18 +388,18 @@ func (r *ManualBookingRepository) Cancel(id, reason string, tripSeatRepo *TripSe
 			cancellation_reason = $2,
 			updated_at = $1
 		WHERE id = $3 AND status NOT IN ('cancelled', 'completed')
+		RETURNING scheduled_trip_id
 	`
 
-	result, err := tx.Exec(updateBookingQuery, now, reason, id)
+	var scheduledTripID string
+	err = tx.QueryRow(updateBookingQuery, now, reason, id).Scan(&scheduledTripID)
+	if err == sql.ErrNoRows {
+		return sql.ErrNoRows
+	}
 	if err != nil {
 		return fmt.Errorf("failed to cancel booking: %w", err)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
-
 	// Release the seats
 	releaseSeatQuery := `
 		UPDATE trip_seats
@@ -407,6 +415,10 @@ func (r *ManualBookingRepository) Cancel(id, reason string, tripSeatRepo *TripSe
 		return fmt.Errorf("failed to release seats: %w", err)
 	}
 
+	if err := syncTripSeatCounters(tx, scheduledTripID); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// MasterRouteImportRepository writes bulk imports of master routes and their stops
+type MasterRouteImportRepository struct {
+	db *sqlx.DB
+}
+
+// NewMasterRouteImportRepository creates a new MasterRouteImportRepository
+func NewMasterRouteImportRepository(db *sqlx.DB) *MasterRouteImportRepository {
+	return &MasterRouteImportRepository{db: db}
+}
+
+// ExistingRouteNumbers returns which of the route numbers are already taken, lower-cased
+func (r *MasterRouteImportRepository) ExistingRouteNumbers(routeNumbers []string) (map[string]bool, error) {
+	lowered := make([]string, len(routeNumbers))
+	for i, number := range routeNumbers {
+		lowered[i] = strings.ToLower(number)
+	}
+	var existing []string
+	err := r.db.Select(&existing, `
+		SELECT LOWER(route_number) FROM master_routes
+		WHERE LOWER(route_number) = ANY($1)`, pq.Array(lowered))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check route numbers: %w", err)
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, number := range existing {
+		taken[number] = true
+	}
+	return taken, nil
+}
+
+// Create inserts the routes and their stops in one transaction, so a failed import leaves
+// nothing behind. Routes are created active.
+func (r *MasterRouteImportRepository) Create(routes []models.MasterRouteImportRoute) ([]models.MasterRouteImportSummary, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created := make([]models.MasterRouteImportSummary, 0, len(routes))
+	for _, route := range routes {
+		routeID := uuid.New().String()
+		if _, err := tx.Exec(`
+			INSERT INTO master_routes (
+				id, route_number, route_name, origin_city, destination_city,
+				total_distance_km, estimated_duration_minutes, is_active, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, true, NOW(), NOW())`,
+			routeID, route.RouteNumber, route.RouteName, route.OriginCity, route.DestinationCity,
+			route.TotalDistanceKm, route.EstimatedDurationMinutes); err != nil {
+			return nil, fmt.Errorf("failed to create master route %s: %w", route.RouteNumber, err)
+		}
+		for _, stop := range route.Stops {
+			if _, err := tx.Exec(`
+				INSERT INTO master_route_stops (
+					id, master_route_id, stop_name, stop_order, latitude, longitude,
+					arrival_time_offset_minutes, is_major_stop, created_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())`,
+				uuid.New().String(), routeID, stop.StopName, stop.StopOrder, stop.Latitude, stop.Longitude,
+				stop.ArrivalTimeOffsetMinutes, stop.IsMajorStop); err != nil {
+				return nil, fmt.Errorf("failed to create stop %d of master route %s: %w", stop.StopOrder, route.RouteNumber, err)
+			}
+		}
+		created = append(created, models.MasterRouteImportSummary{
+			ID:          routeID,
+			RouteNumber: route.RouteNumber,
+			RouteName:   route.RouteName,
+			Stops:       len(route.Stops),
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit master route import: %w", err)
+	}
+	return created, nil
+}
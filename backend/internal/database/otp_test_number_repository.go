@@ -0,0 +1,75 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// OTPTestNumberRepository stores the QA test number allowlist
+type OTPTestNumberRepository struct {
+	db *sqlx.DB
+}
+
+// NewOTPTestNumberRepository creates a new OTPTestNumberRepository
+func NewOTPTestNumberRepository(db *sqlx.DB) *OTPTestNumberRepository {
+	return &OTPTestNumberRepository{db: db}
+}
+
+// List returns the allowlisted numbers, newest first
+func (r *OTPTestNumberRepository) List() ([]models.OTPTestNumber, error) {
+	var numbers []models.OTPTestNumber
+	err := r.db.Select(&numbers, `
+		SELECT id, phone_hash, phone_masked, label, created_by, created_at
+		FROM otp_test_numbers
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OTP test numbers: %w", err)
+	}
+	return numbers, nil
+}
+
+// ListHashes returns the phone hashes of all allowlisted numbers
+func (r *OTPTestNumberRepository) ListHashes() ([]string, error) {
+	var hashes []string
+	if err := r.db.Select(&hashes, `SELECT phone_hash FROM otp_test_numbers`); err != nil {
+		return nil, fmt.Errorf("failed to list OTP test number hashes: %w", err)
+	}
+	return hashes, nil
+}
+
+// Create adds a number. Returns false if the number is already listed.
+func (r *OTPTestNumberRepository) Create(number *models.OTPTestNumber) (bool, error) {
+	err := r.db.QueryRowx(`
+		INSERT INTO otp_test_numbers (phone_hash, phone_masked, label, created_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (phone_hash) DO NOTHING
+		RETURNING id, created_at`,
+		number.PhoneHash, number.PhoneMasked, number.Label, number.CreatedBy,
+	).Scan(&number.ID, &number.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create OTP test number: %w", err)
+	}
+	return true, nil
+}
+
+// Delete removes a number, returning it, or nil if it was not listed
+func (r *OTPTestNumberRepository) Delete(id string) (*models.OTPTestNumber, error) {
+	var number models.OTPTestNumber
+	err := r.db.Get(&number, `
+		DELETE FROM otp_test_numbers
+		WHERE id = $1
+		RETURNING id, phone_hash, phone_masked, label, created_by, created_at`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete OTP test number: %w", err)
+	}
+	return &number, nil
+}
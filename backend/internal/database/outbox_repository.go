@@ -0,0 +1,99 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// OutboxRepository handles delivery of the events in the transactional outbox. Events are
+// written by the repositories making the change they describe, in the same transaction.
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewOutboxRepository creates a new OutboxRepository
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+const outboxEventColumns = `
+	id, event_type, aggregate_id, payload, status, attempts, last_error,
+	available_at, created_at, dispatched_at`
+
+// insertOutboxEvent saves an event within the transaction committing its change
+func insertOutboxEvent(tx *sqlx.Tx, event *models.OutboxEvent) error {
+	_, err := tx.Exec(`
+		INSERT INTO outbox_events (
+			id, event_type, aggregate_id, payload, status, attempts, available_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, 0, $6, $7)`,
+		event.ID, event.EventType, event.AggregateID, string(event.Payload),
+		event.Status, event.AvailableAt, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save %s event: %w", event.EventType, err)
+	}
+	return nil
+}
+
+// ClaimDue takes up to limit pending events that are due, oldest first, counting the attempt
+// and holding them for lease. An event whose dispatcher dies before settling it is claimed
+// again once the lease runs out. SKIP LOCKED lets several instances dispatch at once without
+// taking the same event.
+func (r *OutboxRepository) ClaimDue(limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1,
+		    available_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE status = 'pending' AND available_at <= NOW()
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxEventColumns
+
+	var events []models.OutboxEvent
+	if err := r.db.Select(&events, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// MarkDispatched records that every consumer handled the event
+func (r *OutboxRepository) MarkDispatched(id string) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox_events
+		SET status = 'dispatched', dispatched_at = NOW(), last_error = NULL
+		WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event dispatched: %w", err)
+	}
+	return nil
+}
+
+// MarkRetry records a failed delivery and when to try the event again
+func (r *OutboxRepository) MarkRetry(id, lastError string, retryAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox_events
+		SET last_error = $2, available_at = $3
+		WHERE id = $1`, id, lastError, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to schedule outbox event retry: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed gives up on an event after its last attempt
+func (r *OutboxRepository) MarkFailed(id, lastError string) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox_events
+		SET status = 'failed', last_error = $2
+		WHERE id = $1`, id, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// OwnerAnalyticsRepository handles the bus_owner_daily_stats rollup behind bus owner analytics
+type OwnerAnalyticsRepository struct {
+	db *sqlx.DB
+}
+
+// NewOwnerAnalyticsRepository creates a new OwnerAnalyticsRepository
+func NewOwnerAnalyticsRepository(db *sqlx.DB) *OwnerAnalyticsRepository {
+	return &OwnerAnalyticsRepository{db: db}
+}
+
+// RollUp recomputes the daily stats of every bus owner for trips departing in [from, to), which
+// must be local day boundaries. A trip is on time by the same rules as schedule punctuality.
+// Returns the number of rows written.
+func (r *OwnerAnalyticsRepository) RollUp(from, to time.Time, rules models.PunctualityRules) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Instances running the job at once would otherwise both insert the same days
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('bus_owner_daily_stats'))`); err != nil {
+		return 0, fmt.Errorf("failed to lock owner daily stats: %w", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM bus_owner_daily_stats
+		WHERE stat_date >= ($1::timestamptz AT TIME ZONE 'Asia/Colombo')::date
+		  AND stat_date < ($2::timestamptz AT TIME ZONE 'Asia/Colombo')::date`, from, to); err != nil {
+		return 0, fmt.Errorf("failed to clear owner daily stats: %w", err)
+	}
+
+	result, err := tx.Exec(`
+		WITH trips AS (
+			SELECT st.id, rp.bus_owner_id, st.bus_id,
+				COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id) AS bus_owner_route_id,
+				(st.departure_datetime AT TIME ZONE 'Asia/Colombo')::date AS stat_date,
+				st.status, st.departure_datetime, st.estimated_duration_minutes
+			FROM scheduled_trips st
+			JOIN route_permits rp ON rp.id = st.permit_id
+			LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+			WHERE st.departure_datetime >= $1 AND st.departure_datetime < $2
+		), seats AS (
+			SELECT s.scheduled_trip_id,
+				COUNT(*) AS offered,
+				COUNT(*) FILTER (WHERE s.status = 'booked') AS booked
+			FROM trip_seats s
+			JOIN trips t ON t.id = s.scheduled_trip_id
+			GROUP BY s.scheduled_trip_id
+		), bookings AS (
+			SELECT bb.scheduled_trip_id,
+				COUNT(*) FILTER (WHERE bb.status NOT IN ('cancelled', 'no_show') AND b.payment_status = 'paid') AS paid,
+				COUNT(*) FILTER (WHERE bb.status = 'cancelled') AS cancelled,
+				COALESCE(SUM(bb.total_fare) FILTER (WHERE bb.status NOT IN ('cancelled', 'no_show') AND b.payment_status = 'paid'), 0) AS revenue
+			FROM bus_bookings bb
+			JOIN bookings b ON b.id = bb.booking_id
+			JOIN trips t ON t.id = bb.scheduled_trip_id
+			GROUP BY bb.scheduled_trip_id
+		), measured AS (
+			-- One run per trip, so the joins below keep one row per trip
+			SELECT DISTINCT ON (t.id) t.id,
+				EXTRACT(EPOCH FROM (at.actual_departure_time - t.departure_datetime)) / 60 AS departure_delay,
+				CASE WHEN at.actual_arrival_time IS NOT NULL AND t.estimated_duration_minutes IS NOT NULL
+					THEN EXTRACT(EPOCH FROM (at.actual_arrival_time
+						- (t.departure_datetime + t.estimated_duration_minutes * INTERVAL '1 minute'))) / 60
+				END AS arrival_delay
+			FROM trips t
+			JOIN active_trips at ON at.scheduled_trip_id = t.id
+			WHERE at.status = 'completed' AND at.actual_departure_time IS NOT NULL
+			ORDER BY t.id, at.actual_departure_time
+		)
+		INSERT INTO bus_owner_daily_stats (
+			bus_owner_id, stat_date, bus_id, bus_owner_route_id,
+			trips, cancelled_trips, seats_offered, seats_booked,
+			paid_bookings, cancelled_bookings, revenue,
+			trips_measured, on_time_trips, computed_at
+		)
+		SELECT t.bus_owner_id, t.stat_date, t.bus_id, t.bus_owner_route_id,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE t.status = 'cancelled'),
+			COALESCE(SUM(s.offered) FILTER (WHERE t.status <> 'cancelled'), 0),
+			COALESCE(SUM(s.booked) FILTER (WHERE t.status <> 'cancelled'), 0),
+			COALESCE(SUM(b.paid), 0),
+			COALESCE(SUM(b.cancelled), 0),
+			COALESCE(SUM(b.revenue), 0),
+			COUNT(m.id),
+			COUNT(m.id) FILTER (WHERE m.departure_delay <= $3 AND (m.arrival_delay IS NULL OR m.arrival_delay <= $4)),
+			NOW()
+		FROM trips t
+		LEFT JOIN seats s ON s.scheduled_trip_id = t.id
+		LEFT JOIN bookings b ON b.scheduled_trip_id = t.id
+		LEFT JOIN measured m ON m.id = t.id
+		GROUP BY t.bus_owner_id, t.stat_date, t.bus_id, t.bus_owner_route_id`,
+		from, to, rules.DepartureGraceMinutes, rules.ArrivalGraceMinutes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll up owner daily stats: %w", err)
+	}
+	written, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit owner daily stats: %w", err)
+	}
+	return int(written), nil
+}
+
+// GetDailyStats returns an owner's rolled-up stats for local days in [from, to), with route
+// names and license plates
+func (r *OwnerAnalyticsRepository) GetDailyStats(busOwnerID string, from, to time.Time) ([]models.OwnerDailyStat, error) {
+	stats := []models.OwnerDailyStat{}
+	err := r.db.Select(&stats, `
+		SELECT s.stat_date, s.bus_id, b.license_plate, s.bus_owner_route_id,
+			COALESCE(mr.route_name, bor.custom_route_name) AS route_name,
+			s.trips, s.cancelled_trips, s.seats_offered, s.seats_booked,
+			s.paid_bookings, s.cancelled_bookings, s.revenue,
+			s.trips_measured, s.on_time_trips, s.computed_at
+		FROM bus_owner_daily_stats s
+		LEFT JOIN buses b ON b.id = s.bus_id
+		LEFT JOIN bus_owner_routes bor ON bor.id = s.bus_owner_route_id
+		LEFT JOIN master_routes mr ON mr.id = bor.master_route_id
+		WHERE s.bus_owner_id = $1
+		  AND s.stat_date >= ($2::timestamptz AT TIME ZONE 'Asia/Colombo')::date
+		  AND s.stat_date < ($3::timestamptz AT TIME ZONE 'Asia/Colombo')::date
+		ORDER BY s.stat_date`, busOwnerID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner daily stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,380 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// OwnerPayoutRepository handles owner bank accounts, payout batches and payouts
+type OwnerPayoutRepository struct {
+	db *sqlx.DB
+}
+
+// NewOwnerPayoutRepository creates a new OwnerPayoutRepository
+func NewOwnerPayoutRepository(db *sqlx.DB) *OwnerPayoutRepository {
+	return &OwnerPayoutRepository{db: db}
+}
+
+const ownerBankAccountColumns = `
+	id, owner_type, owner_id, account_holder_name, bank_name, bank_code, branch_name, branch_code,
+	account_number, status, rejection_reason, verified_at, verified_by_user_id, created_at, updated_at`
+
+const ownerPayoutColumns = `
+	p.id, p.batch_id, p.owner_type, p.owner_id, p.bank_account_id, p.period_start, p.period_end,
+	p.booking_count, p.gross_amount, p.commission_amount, p.dispute_deducted_amount, p.dispute_held_amount,
+	p.net_amount, p.status, p.status_reason, p.transfer_reference, p.paid_at, p.created_at, p.updated_at`
+
+// ============================================================================
+// BANK ACCOUNTS
+// ============================================================================
+
+// GetBankAccount returns an owner's bank account; returns nil if none is on file
+func (r *OwnerPayoutRepository) GetBankAccount(ownerType models.PayoutOwnerType, ownerID uuid.UUID) (*models.OwnerBankAccount, error) {
+	var account models.OwnerBankAccount
+	err := r.db.Get(&account, `SELECT `+ownerBankAccountColumns+`
+		FROM owner_bank_accounts
+		WHERE owner_type = $1 AND owner_id = $2`, ownerType, ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get bank account: %w", err)
+	}
+	account.MaskedAccountNumber = models.MaskAccountNumber(account.AccountNumber)
+	return &account, nil
+}
+
+// GetBankAccountByID returns a bank account; returns nil if it does not exist
+func (r *OwnerPayoutRepository) GetBankAccountByID(id uuid.UUID) (*models.OwnerBankAccount, error) {
+	var account models.OwnerBankAccount
+	err := r.db.Get(&account, `SELECT `+ownerBankAccountColumns+` FROM owner_bank_accounts WHERE id = $1`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get bank account: %w", err)
+	}
+	account.MaskedAccountNumber = models.MaskAccountNumber(account.AccountNumber)
+	return &account, nil
+}
+
+// UpsertBankAccount saves an owner's bank account. Any change puts it back to pending verification.
+func (r *OwnerPayoutRepository) UpsertBankAccount(account *models.OwnerBankAccount) error {
+	if account.ID == uuid.Nil {
+		account.ID = uuid.New()
+	}
+	err := r.db.QueryRow(`
+		INSERT INTO owner_bank_accounts (
+			id, owner_type, owner_id, account_holder_name, bank_name, bank_code, branch_name, branch_code,
+			account_number, status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'pending', NOW(), NOW())
+		ON CONFLICT (owner_type, owner_id) DO UPDATE
+		SET account_holder_name = EXCLUDED.account_holder_name,
+		    bank_name = EXCLUDED.bank_name,
+		    bank_code = EXCLUDED.bank_code,
+		    branch_name = EXCLUDED.branch_name,
+		    branch_code = EXCLUDED.branch_code,
+		    account_number = EXCLUDED.account_number,
+		    status = 'pending',
+		    rejection_reason = NULL,
+		    verified_at = NULL,
+		    verified_by_user_id = NULL,
+		    updated_at = NOW()
+		RETURNING id, status, created_at, updated_at`,
+		account.ID, account.OwnerType, account.OwnerID, account.AccountHolderName, account.BankName,
+		account.BankCode, account.BranchName, account.BranchCode, account.AccountNumber,
+	).Scan(&account.ID, &account.Status, &account.CreatedAt, &account.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save bank account: %w", err)
+	}
+	account.RejectionReason, account.VerifiedAt, account.VerifiedByUserID = nil, nil, nil
+	account.MaskedAccountNumber = models.MaskAccountNumber(account.AccountNumber)
+	return nil
+}
+
+// ListBankAccounts returns a page of bank accounts, optionally by status, oldest update first
+func (r *OwnerPayoutRepository) ListBankAccounts(status models.BankAccountStatus, limit, offset int) ([]models.OwnerBankAccount, int, error) {
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM owner_bank_accounts WHERE ($1 = '' OR status = $1)`, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bank accounts: %w", err)
+	}
+
+	accounts := []models.OwnerBankAccount{}
+	err := r.db.Select(&accounts, `SELECT `+ownerBankAccountColumns+`
+		FROM owner_bank_accounts
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY updated_at ASC
+		LIMIT $2 OFFSET $3`, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list bank accounts: %w", err)
+	}
+	for i := range accounts {
+		accounts[i].MaskedAccountNumber = models.MaskAccountNumber(accounts[i].AccountNumber)
+	}
+	return accounts, total, nil
+}
+
+// SetBankAccountStatus records an admin's verification decision on a pending account.
+// Returns false if the account is no longer pending (e.g. the owner edited it meanwhile).
+func (r *OwnerPayoutRepository) SetBankAccountStatus(id uuid.UUID, status models.BankAccountStatus, reason *string, adminUserID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE owner_bank_accounts
+		SET status = $2, rejection_reason = $3, verified_at = NOW(), verified_by_user_id = $4, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'`,
+		id, status, reason, adminUserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update bank account status: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// ReleaseHeldPayouts moves an owner's payouts held for lack of a verified account to pending,
+// paying them into the newly verified account
+func (r *OwnerPayoutRepository) ReleaseHeldPayouts(ownerType models.PayoutOwnerType, ownerID, bankAccountID uuid.UUID) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE owner_payouts
+		SET status = 'pending', status_reason = NULL, bank_account_id = $3, updated_at = NOW()
+		WHERE owner_type = $1 AND owner_id = $2 AND status = 'on_hold' AND bank_account_id IS NULL`,
+		ownerType, ownerID, bankAccountID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release held payouts: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// ============================================================================
+// EARNINGS
+// ============================================================================
+
+// GetBusOwnerEarnings returns each bus owner's paid fares for trips departing in [from, to).
+// Cancelled bookings earn nothing; no-shows keep their fare.
+func (r *OwnerPayoutRepository) GetBusOwnerEarnings(from, to time.Time) ([]models.OwnerEarnings, error) {
+	earnings := []models.OwnerEarnings{}
+	err := r.db.Select(&earnings, `
+		SELECT 'bus_owner' AS owner_type,
+		       COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS owner_id,
+		       COUNT(bb.id) AS booking_count,
+		       COALESCE(SUM(bb.total_fare), 0) AS gross_amount
+		FROM bus_bookings bb
+		JOIN bookings b ON bb.booking_id = b.id AND b.payment_status = 'paid'
+		JOIN scheduled_trips st ON bb.scheduled_trip_id = st.id
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		WHERE st.departure_datetime >= $1 AND st.departure_datetime < $2
+		  AND bb.status NOT IN ('pending', 'cancelled')
+		  AND COALESCE(ts.bus_owner_id, bor.bus_owner_id) IS NOT NULL
+		GROUP BY 2`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bus owner earnings: %w", err)
+	}
+	return earnings, nil
+}
+
+// GetLoungeOwnerEarnings returns each lounge owner's paid lounge bookings scheduled in [from, to).
+// Served bookings earn their total plus any promo code discount and platform-funded bundle
+// discount (owner-funded ones are the owner's to absorb); no-shows earn only the no-show fee.
+func (r *OwnerPayoutRepository) GetLoungeOwnerEarnings(from, to time.Time) ([]models.OwnerEarnings, error) {
+	earnings := []models.OwnerEarnings{}
+	err := r.db.Select(&earnings, `
+		SELECT 'lounge_owner' AS owner_type,
+		       l.lounge_owner_id AS owner_id,
+		       COUNT(lb.id) AS booking_count,
+		       COALESCE(SUM(CASE WHEN lb.status = 'no_show' THEN COALESCE(lb.no_show_fee, 0)
+		                         WHEN lb.discount_funded_by = 'platform' THEN COALESCE(lb.total_amount, 0) + COALESCE(lb.discount_amount, 0) + COALESCE(lb.promo_discount_amount, 0)
+		                         ELSE COALESCE(lb.total_amount, 0) + COALESCE(lb.promo_discount_amount, 0) END), 0) AS gross_amount
+		FROM lounge_bookings lb
+		JOIN lounges l ON lb.lounge_id = l.id
+		WHERE lb.scheduled_arrival >= $1 AND lb.scheduled_arrival < $2
+		  AND lb.payment_status = 'paid'
+		  AND lb.status NOT IN ('pending', 'cancelled')
+		GROUP BY 2`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lounge owner earnings: %w", err)
+	}
+	return earnings, nil
+}
+
+// ============================================================================
+// BATCHES
+// ============================================================================
+
+// GetBatchByPeriod returns the batch for [periodStart, periodEnd); returns nil if not generated yet
+func (r *OwnerPayoutRepository) GetBatchByPeriod(periodStart, periodEnd time.Time) (*models.PayoutBatch, error) {
+	var batch models.PayoutBatch
+	err := r.db.Get(&batch, `
+		SELECT id, period_start, period_end, payout_count, total_net_amount, created_by_user_id, created_at
+		FROM payout_batches
+		WHERE period_start = $1 AND period_end = $2`, periodStart, periodEnd)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payout batch: %w", err)
+	}
+	return &batch, nil
+}
+
+// GetBatchByID returns a batch; returns nil if it does not exist
+func (r *OwnerPayoutRepository) GetBatchByID(id uuid.UUID) (*models.PayoutBatch, error) {
+	var batch models.PayoutBatch
+	err := r.db.Get(&batch, `
+		SELECT id, period_start, period_end, payout_count, total_net_amount, created_by_user_id, created_at
+		FROM payout_batches
+		WHERE id = $1`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payout batch: %w", err)
+	}
+	return &batch, nil
+}
+
+// CreateBatch inserts a batch with its payouts, atomically
+func (r *OwnerPayoutRepository) CreateBatch(batch *models.PayoutBatch, payouts []*models.OwnerPayout) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if batch.ID == uuid.Nil {
+		batch.ID = uuid.New()
+	}
+	err = tx.QueryRow(`
+		INSERT INTO payout_batches (id, period_start, period_end, payout_count, total_net_amount, created_by_user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at`,
+		batch.ID, batch.PeriodStart, batch.PeriodEnd, batch.PayoutCount, batch.TotalNetAmount, batch.CreatedByUserID,
+	).Scan(&batch.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payout batch: %w", err)
+	}
+
+	for _, payout := range payouts {
+		if payout.ID == uuid.Nil {
+			payout.ID = uuid.New()
+		}
+		payout.BatchID = batch.ID
+		err = tx.QueryRow(`
+			INSERT INTO owner_payouts (
+				id, batch_id, owner_type, owner_id, bank_account_id, period_start, period_end,
+				booking_count, gross_amount, commission_amount, dispute_deducted_amount, dispute_held_amount,
+				net_amount, status, status_reason, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW(), NOW())
+			RETURNING created_at, updated_at`,
+			payout.ID, payout.BatchID, payout.OwnerType, payout.OwnerID, payout.BankAccountID,
+			payout.PeriodStart, payout.PeriodEnd, payout.BookingCount, payout.GrossAmount,
+			payout.CommissionAmount, payout.DisputeDeductedAmount, payout.DisputeHeldAmount,
+			payout.NetAmount, payout.Status, payout.StatusReason,
+		).Scan(&payout.CreatedAt, &payout.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create owner payout: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit payout batch: %w", err)
+	}
+	return nil
+}
+
+// ListBatches returns a page of batches, newest period first, with the total count
+func (r *OwnerPayoutRepository) ListBatches(limit, offset int) ([]models.PayoutBatch, int, error) {
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM payout_batches`); err != nil {
+		return nil, 0, fmt.Errorf("failed to count payout batches: %w", err)
+	}
+
+	batches := []models.PayoutBatch{}
+	err := r.db.Select(&batches, `
+		SELECT id, period_start, period_end, payout_count, total_net_amount, created_by_user_id, created_at
+		FROM payout_batches
+		ORDER BY period_start DESC
+		LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list payout batches: %w", err)
+	}
+	return batches, total, nil
+}
+
+// GetBatchTransfers returns a batch's payouts with the owner and bank details needed to pay them
+func (r *OwnerPayoutRepository) GetBatchTransfers(batchID uuid.UUID) ([]models.OwnerPayoutTransfer, error) {
+	transfers := []models.OwnerPayoutTransfer{}
+	err := r.db.Select(&transfers, `SELECT `+ownerPayoutColumns+`,
+		       COALESCE(bo.company_name, lo.business_name) AS owner_name,
+		       ba.account_holder_name, ba.bank_name, ba.branch_name, ba.account_number
+		FROM owner_payouts p
+		LEFT JOIN owner_bank_accounts ba ON ba.id = p.bank_account_id
+		LEFT JOIN bus_owners bo ON p.owner_type = 'bus_owner' AND bo.id = p.owner_id
+		LEFT JOIN lounge_owners lo ON p.owner_type = 'lounge_owner' AND lo.id = p.owner_id
+		WHERE p.batch_id = $1
+		ORDER BY p.status, p.net_amount DESC`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch payouts: %w", err)
+	}
+	return transfers, nil
+}
+
+// ============================================================================
+// PAYOUTS
+// ============================================================================
+
+// GetPayoutByID returns a payout; returns nil if it does not exist
+func (r *OwnerPayoutRepository) GetPayoutByID(id uuid.UUID) (*models.OwnerPayout, error) {
+	var payout models.OwnerPayout
+	err := r.db.Get(&payout, `SELECT `+ownerPayoutColumns+` FROM owner_payouts p WHERE p.id = $1`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get owner payout: %w", err)
+	}
+	return &payout, nil
+}
+
+// ListOwnerPayouts returns a page of one owner's payouts, newest period first, with the total count
+func (r *OwnerPayoutRepository) ListOwnerPayouts(ownerType models.PayoutOwnerType, ownerID uuid.UUID, limit, offset int) ([]models.OwnerPayout, int, error) {
+	var total int
+	err := r.db.Get(&total, `SELECT COUNT(*) FROM owner_payouts WHERE owner_type = $1 AND owner_id = $2`, ownerType, ownerID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count owner payouts: %w", err)
+	}
+
+	payouts := []models.OwnerPayout{}
+	err = r.db.Select(&payouts, `SELECT `+ownerPayoutColumns+`
+		FROM owner_payouts p
+		WHERE p.owner_type = $1 AND p.owner_id = $2
+		ORDER BY p.period_start DESC
+		LIMIT $3 OFFSET $4`, ownerType, ownerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list owner payouts: %w", err)
+	}
+	return payouts, total, nil
+}
+
+// UpdatePayoutStatus moves a payout to status, provided it is still in fromStatus.
+// Returns false if another update got there first.
+func (r *OwnerPayoutRepository) UpdatePayoutStatus(id uuid.UUID, fromStatus, status models.PayoutStatus, transferReference, reason *string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE owner_payouts
+		SET status = $3,
+		    transfer_reference = COALESCE($4, transfer_reference),
+		    status_reason = $5,
+		    paid_at = CASE WHEN $3 = 'paid' THEN NOW() ELSE paid_at END,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = $2`,
+		id, fromStatus, status, transferReference, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to update owner payout: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// PassengerContactRepository finds who may contact a booked passenger and how often they have
+type PassengerContactRepository struct {
+	db *sqlx.DB
+}
+
+// NewPassengerContactRepository creates a new PassengerContactRepository
+func NewPassengerContactRepository(db *sqlx.DB) *PassengerContactRepository {
+	return &PassengerContactRepository{db: db}
+}
+
+// GetTarget returns a booked seat's passenger contact with the trip's driver, conductor and bus
+// owner; returns nil if the seat does not exist
+func (r *PassengerContactRepository) GetTarget(seatID string) (*models.PassengerContactTarget, error) {
+	var target models.PassengerContactTarget
+	err := r.db.Get(&target, `
+		SELECT bbs.id AS seat_id, bbs.bus_booking_id, b.booking_reference,
+		       st.id AS scheduled_trip_id, bbs.passenger_name,
+		       COALESCE(NULLIF(bbs.passenger_phone, ''), b.passenger_phone) AS passenger_phone,
+		       drv.user_id AS driver_user_id, cond.user_id AS conductor_user_id, bo.user_id AS owner_user_id
+		FROM bus_booking_seats bbs
+		JOIN bus_bookings bb ON bbs.bus_booking_id = bb.id
+		JOIN bookings b ON bb.booking_id = b.id
+		JOIN scheduled_trips st ON bb.scheduled_trip_id = st.id
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN bus_owners bo ON bo.id = COALESCE(ts.bus_owner_id, bor.bus_owner_id)
+		LEFT JOIN bus_staff drv ON drv.id = st.assigned_driver_id
+		LEFT JOIN bus_staff cond ON cond.id = st.assigned_conductor_id
+		WHERE bbs.id = $1`, seatID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passenger contact: %w", err)
+	}
+	return &target, nil
+}
+
+// CountRequests returns how many passenger contacts a user has requested on a trip, from the
+// audit log
+func (r *PassengerContactRepository) CountRequests(userID, scheduledTripID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `
+		SELECT COUNT(*)
+		FROM audit_logs
+		WHERE user_id = $1
+		  AND action IN ('passenger_contact_reveal', 'passenger_contact_relay')
+		  AND details->>'scheduled_trip_id' = $2`, userID, scheduledTripID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count passenger contact requests: %w", err)
+	}
+	return count, nil
+}
@@ -7,20 +7,32 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
 )
 
-// PassengerRepository handles passenger database operations
+// PassengerRepository handles passenger database operations. NIC and address are encrypted at
+// rest when a PII cipher is configured.
 type PassengerRepository struct {
-	db DB
+	db  DB
+	pii *fieldcrypt.Cipher
 }
 
-// NewPassengerRepository creates a new passenger repository
-func NewPassengerRepository(db DB) *PassengerRepository {
+// NewPassengerRepository creates a new passenger repository; pii may be nil to store PII in plaintext
+func NewPassengerRepository(db DB, pii *fieldcrypt.Cipher) *PassengerRepository {
 	return &PassengerRepository{
-		db: db,
+		db:  db,
+		pii: pii,
 	}
 }
 
+// decryptPassenger decrypts a passenger's PII columns in place
+func (r *PassengerRepository) decryptPassenger(passenger *models.Passenger) error {
+	if err := decryptPIINull(r.pii, &passenger.NIC.NullString); err != nil {
+		return err
+	}
+	return decryptPIINull(r.pii, &passenger.Address.NullString)
+}
+
 // CreatePassenger creates a new passenger record
 func (r *PassengerRepository) CreatePassenger(userID uuid.UUID) (*models.Passenger, error) {
 	passenger := &models.Passenger{
@@ -81,6 +93,9 @@ func (r *PassengerRepository) GetPassengerByUserID(userID uuid.UUID) (*models.Pa
 		}
 		return nil, fmt.Errorf("failed to get passenger by user ID: %w", err)
 	}
+	if err := r.decryptPassenger(&passenger); err != nil {
+		return nil, err
+	}
 
 	return &passenger, nil
 }
@@ -107,6 +122,9 @@ func (r *PassengerRepository) GetPassengerByID(id uuid.UUID) (*models.Passenger,
 		}
 		return nil, fmt.Errorf("failed to get passenger by ID: %w", err)
 	}
+	if err := r.decryptPassenger(&passenger); err != nil {
+		return nil, err
+	}
 
 	return &passenger, nil
 }
@@ -200,7 +218,11 @@ func (r *PassengerRepository) UpdatePassengerProfile(userID uuid.UUID, firstName
 		WHERE user_id = $8
 	`
 
-	result, err := r.db.Exec(query, firstName, lastName, email, address, city, postalCode, time.Now(), userID)
+	storedAddress, err := encryptPII(r.pii, address)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Exec(query, firstName, lastName, email, storedAddress, city, postalCode, time.Now(), userID)
 	if err != nil {
 		return fmt.Errorf("failed to update passenger profile: %w", err)
 	}
@@ -235,6 +257,28 @@ func (r *PassengerRepository) UpdatePassengerEmergencyContact(userID uuid.UUID,
 	return nil
 }
 
+// UpdatePassengerIdentity updates email and/or NIC; nil values are left unchanged
+func (r *PassengerRepository) UpdatePassengerIdentity(userID uuid.UUID, email, nic *string) error {
+	query := `
+		UPDATE passengers
+		SET email = COALESCE($1, email),
+		    nic = COALESCE($2, nic),
+		    updated_at = $3
+		WHERE user_id = $4
+	`
+
+	storedNIC, err := encryptPIIPtr(r.pii, nic)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, email, storedNIC, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update passenger identity: %w", err)
+	}
+
+	return nil
+}
+
 // UpdatePassengerPreferences updates passenger preferences
 func (r *PassengerRepository) UpdatePassengerPreferences(userID uuid.UUID, preferredSeatType, specialRequirements string) error {
 	query := `
@@ -253,6 +297,24 @@ func (r *PassengerRepository) UpdatePassengerPreferences(userID uuid.UUID, prefe
 	return nil
 }
 
+// UpdatePreferredSeatType sets the passenger's preferred seat type, leaving special requirements
+// alone; nil clears it
+func (r *PassengerRepository) UpdatePreferredSeatType(userID uuid.UUID, seatType *string) error {
+	query := `
+		UPDATE passengers
+		SET preferred_seat_type = $1,
+		    updated_at = $2
+		WHERE user_id = $3
+	`
+
+	_, err := r.db.Exec(query, seatType, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update passenger seat preference: %w", err)
+	}
+
+	return nil
+}
+
 // IncrementTotalTrips increases the total trips count
 func (r *PassengerRepository) IncrementTotalTrips(userID uuid.UUID) error {
 	query := `
@@ -311,6 +373,9 @@ func (r *PassengerRepository) GetPassengerWithUser(userID uuid.UUID) (*models.Pa
 		}
 		return nil, fmt.Errorf("failed to get passenger with user: %w", err)
 	}
+	if err := r.decryptPassenger(&result.Passenger); err != nil {
+		return nil, err
+	}
 
 	return &result, nil
 }
@@ -337,6 +402,11 @@ func (r *PassengerRepository) ListPassengers(limit, offset int) ([]*models.Passe
 	if err != nil {
 		return nil, fmt.Errorf("failed to list passengers: %w", err)
 	}
+	for _, passenger := range passengers {
+		if err := r.decryptPassenger(&passenger.Passenger); err != nil {
+			return nil, err
+		}
+	}
 
 	return passengers, nil
 }
@@ -186,3 +186,70 @@ func (r *PaymentAuditRepository) GetRecentByEventType(ctx context.Context, event
 
 	return audits, nil
 }
+
+// Search returns a page of audit entries matching the filter, newest first,
+// along with the total number of matching entries
+func (r *PaymentAuditRepository) Search(ctx context.Context, filter models.PaymentAuditFilter) ([]*models.PaymentAudit, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.PaymentStatus != "" {
+		where += ` AND pa.payment_status ILIKE ` + arg(filter.PaymentStatus)
+	}
+	if filter.EventType != "" {
+		where += ` AND pa.event_type = ` + arg(filter.EventType)
+	}
+	if filter.Gateway != "" {
+		where += ` AND bi.payment_gateway = ` + arg(filter.Gateway)
+	}
+	if filter.From != nil {
+		where += ` AND pa.created_at >= ` + arg(*filter.From)
+	}
+	if filter.To != nil {
+		where += ` AND pa.created_at < ` + arg(*filter.To)
+	}
+	if filter.MinAmount != nil {
+		where += ` AND COALESCE(pa.received_amount, pa.expected_amount) >= ` + arg(*filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		where += ` AND COALESCE(pa.received_amount, pa.expected_amount) <= ` + arg(*filter.MaxAmount)
+	}
+	if filter.IntentID != nil {
+		where += ` AND pa.intent_id = ` + arg(*filter.IntentID)
+	}
+	if filter.Reference != "" {
+		// Booking references live on the bookings the intent produced
+		ref := arg(filter.Reference)
+		where += ` AND (pa.payment_reference = ` + ref + ` OR pa.payment_uid = ` + ref + `
+			OR pa.gateway_transaction_id = ` + ref + `
+			OR EXISTS (
+				SELECT 1 FROM bus_bookings bb JOIN bookings b ON b.id = bb.booking_id
+				WHERE bb.id = bi.bus_booking_id AND b.booking_reference = ` + ref + `)
+			OR EXISTS (
+				SELECT 1 FROM lounge_bookings lb
+				WHERE lb.id IN (bi.pre_lounge_booking_id, bi.post_lounge_booking_id) AND lb.booking_reference = ` + ref + `))`
+	}
+
+	from := `
+		FROM payment_audits pa
+		LEFT JOIN booking_intents bi ON bi.id = pa.intent_id`
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*)`+from+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count payment audits: %w", err)
+	}
+
+	query := `SELECT pa.*` + from + where +
+		` ORDER BY pa.created_at DESC LIMIT ` + arg(filter.Limit) + ` OFFSET ` + arg(filter.Offset)
+
+	audits := []*models.PaymentAudit{}
+	if err := r.db.SelectContext(ctx, &audits, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to search payment audits: %w", err)
+	}
+
+	return audits, total, nil
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// PaymentDisputeRepository handles chargebacks, their evidence and their settlement
+type PaymentDisputeRepository struct {
+	db *sqlx.DB
+}
+
+// NewPaymentDisputeRepository creates a new PaymentDisputeRepository
+func NewPaymentDisputeRepository(db *sqlx.DB) *PaymentDisputeRepository {
+	return &PaymentDisputeRepository{db: db}
+}
+
+const paymentDisputeColumns = `
+	id, gateway_dispute_id, payment_uid, payment_reference, intent_id, booking_id, booking_reference,
+	bus_owner_id, payout_id, amount, currency, reason, status, source, respond_by,
+	resolution_note, resolved_at, created_by_user_id, created_at, updated_at`
+
+// ResolvePaymentLink finds the intent, booking and bus owner behind a payment. Any empty
+// identifier is ignored; returns nil if nothing matches.
+func (r *PaymentDisputeRepository) ResolvePaymentLink(paymentUID, paymentReference, bookingReference string) (*models.PaymentDisputeLink, error) {
+	query := `
+		SELECT bi.id AS intent_id, bi.payment_uid, bi.payment_reference,
+		       b.id AS booking_id, b.booking_reference,
+		       COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS bus_owner_id
+		FROM booking_intents bi
+		LEFT JOIN bus_bookings bb ON bb.id = bi.bus_booking_id
+		LEFT JOIN lounge_bookings lb ON lb.id = COALESCE(bi.pre_lounge_booking_id, bi.post_lounge_booking_id)
+		LEFT JOIN bookings b ON b.id = COALESCE(bb.booking_id, lb.master_booking_id)
+		LEFT JOIN scheduled_trips st ON st.id = bb.scheduled_trip_id
+		LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+		LEFT JOIN bus_owner_routes bor ON bor.id = st.bus_owner_route_id
+		WHERE ($1 <> '' AND bi.payment_uid = $1)
+		   OR ($2 <> '' AND bi.payment_reference = $2)
+		   OR ($3 <> '' AND b.booking_reference = $3)
+		ORDER BY bi.created_at DESC
+		LIMIT 1
+	`
+
+	var link models.PaymentDisputeLink
+	if err := r.db.Get(&link, query, paymentUID, paymentReference, bookingReference); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to resolve disputed payment: %w", err)
+	}
+	return &link, nil
+}
+
+// Create inserts a dispute and flags the disputed booking, atomically
+func (r *PaymentDisputeRepository) Create(dispute *models.PaymentDispute) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if dispute.ID == uuid.Nil {
+		dispute.ID = uuid.New()
+	}
+	err = tx.QueryRow(`
+		INSERT INTO payment_disputes (
+			id, gateway_dispute_id, payment_uid, payment_reference, intent_id, booking_id, booking_reference,
+			bus_owner_id, amount, currency, reason, status, source, respond_by, created_by_user_id,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW(), NOW())
+		RETURNING created_at, updated_at`,
+		dispute.ID, dispute.GatewayDisputeID, dispute.PaymentUID, dispute.PaymentReference, dispute.IntentID,
+		dispute.BookingID, dispute.BookingReference, dispute.BusOwnerID, dispute.Amount, dispute.Currency,
+		dispute.Reason, dispute.Status, dispute.Source, dispute.RespondBy, dispute.CreatedByUserID,
+	).Scan(&dispute.CreatedAt, &dispute.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment dispute: %w", err)
+	}
+
+	if dispute.BookingID != nil {
+		_, err = tx.Exec(`
+			UPDATE bookings
+			SET disputed_at = COALESCE(disputed_at, NOW()), updated_at = NOW()
+			WHERE id = $1`, *dispute.BookingID)
+		if err != nil {
+			return fmt.Errorf("failed to flag disputed booking: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit payment dispute: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a dispute; returns nil if it does not exist
+func (r *PaymentDisputeRepository) GetByID(id uuid.UUID) (*models.PaymentDispute, error) {
+	var dispute models.PaymentDispute
+	err := r.db.Get(&dispute, `SELECT `+paymentDisputeColumns+` FROM payment_disputes WHERE id = $1`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment dispute: %w", err)
+	}
+	return &dispute, nil
+}
+
+// List returns a page of disputes, most urgent deadlines first, with the total count
+func (r *PaymentDisputeRepository) List(filter models.PaymentDisputeFilter) ([]models.PaymentDispute, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(` AND status = $%d`, len(args))
+	}
+	if filter.BusOwnerID != nil {
+		args = append(args, *filter.BusOwnerID)
+		where += fmt.Sprintf(` AND bus_owner_id = $%d`, len(args))
+	}
+
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM payment_disputes`+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count payment disputes: %w", err)
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM payment_disputes%s
+		ORDER BY (status IN ('won', 'lost')), respond_by ASC NULLS LAST, created_at DESC
+		LIMIT $%d OFFSET $%d`, paymentDisputeColumns, where, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
+
+	disputes := []models.PaymentDispute{}
+	if err := r.db.Select(&disputes, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list payment disputes: %w", err)
+	}
+	return disputes, total, nil
+}
+
+// UpdateStatus moves a dispute to status, provided it is still in fromStatus.
+// Returns false if another update got there first.
+func (r *PaymentDisputeRepository) UpdateStatus(id uuid.UUID, fromStatus, status models.PaymentDisputeStatus, note *string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE payment_disputes
+		SET status = $3,
+		    resolution_note = COALESCE($4, resolution_note),
+		    resolved_at = CASE WHEN $3 IN ('won', 'lost') THEN NOW() ELSE resolved_at END,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = $2`,
+		id, fromStatus, status, note)
+	if err != nil {
+		return false, fmt.Errorf("failed to update payment dispute: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// AddEvidence attaches a document to a dispute
+func (r *PaymentDisputeRepository) AddEvidence(evidence *models.PaymentDisputeEvidence) error {
+	if evidence.ID == uuid.Nil {
+		evidence.ID = uuid.New()
+	}
+	err := r.db.QueryRow(`
+		INSERT INTO payment_dispute_evidence (id, dispute_id, file_url, file_name, description, uploaded_by_user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at`,
+		evidence.ID, evidence.DisputeID, evidence.FileURL, evidence.FileName, evidence.Description, evidence.UploadedByUserID,
+	).Scan(&evidence.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add dispute evidence: %w", err)
+	}
+	return nil
+}
+
+// GetEvidence returns a dispute's evidence, oldest first
+func (r *PaymentDisputeRepository) GetEvidence(disputeID uuid.UUID) ([]models.PaymentDisputeEvidence, error) {
+	evidence := []models.PaymentDisputeEvidence{}
+	err := r.db.Select(&evidence, `
+		SELECT id, dispute_id, file_url, file_name, description, uploaded_by_user_id, created_at
+		FROM payment_dispute_evidence
+		WHERE dispute_id = $1
+		ORDER BY created_at ASC`, disputeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dispute evidence: %w", err)
+	}
+	return evidence, nil
+}
+
+// GetUnsettledForOwner returns a bus owner's disputes that still affect their payouts:
+// unresolved disputes and lost disputes not yet deducted in a payout
+func (r *PaymentDisputeRepository) GetUnsettledForOwner(busOwnerID uuid.UUID) ([]models.PaymentDispute, error) {
+	disputes := []models.PaymentDispute{}
+	err := r.db.Select(&disputes, `SELECT `+paymentDisputeColumns+`
+		FROM payment_disputes
+		WHERE bus_owner_id = $1
+		  AND (status IN ('open', 'evidence_submitted') OR (status = 'lost' AND payout_id IS NULL))
+		ORDER BY created_at ASC`, busOwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unsettled disputes: %w", err)
+	}
+	return disputes, nil
+}
+
+// MarkSettled records the payout that deducted the given lost disputes
+func (r *PaymentDisputeRepository) MarkSettled(disputeIDs []uuid.UUID, payoutID uuid.UUID) error {
+	if len(disputeIDs) == 0 {
+		return nil
+	}
+	ids := make([]string, len(disputeIDs))
+	for i, id := range disputeIDs {
+		ids[i] = id.String()
+	}
+	_, err := r.db.Exec(`
+		UPDATE payment_disputes
+		SET payout_id = $1, updated_at = NOW()
+		WHERE id = ANY($2::uuid[]) AND status = 'lost' AND payout_id IS NULL`,
+		payoutID, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to mark disputes settled: %w", err)
+	}
+	return nil
+}
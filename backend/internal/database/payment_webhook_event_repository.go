@@ -0,0 +1,86 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// webhookInFlightWindow is how long an accepted delivery that never finished still suppresses
+// redeliveries; after that the gateway's retry is processed again
+const webhookInFlightWindow = "10 minutes"
+
+// PaymentWebhookEventRepository stores payment notification deliveries
+type PaymentWebhookEventRepository struct {
+	db *sqlx.DB
+}
+
+// NewPaymentWebhookEventRepository creates a new PaymentWebhookEventRepository
+func NewPaymentWebhookEventRepository(db *sqlx.DB) *PaymentWebhookEventRepository {
+	return &PaymentWebhookEventRepository{db: db}
+}
+
+// Record stores a delivery. An accepted delivery repeating one with the same payment UID and
+// status that was processed (or is still being processed) is stored as a duplicate of it;
+// deliveries for the same payment are serialised so concurrent repeats cannot both be accepted.
+func (r *PaymentWebhookEventRepository) Record(event *models.PaymentWebhookEvent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, "payment_webhook:"+event.PaymentUID); err != nil {
+		return fmt.Errorf("failed to lock payment webhook: %w", err)
+	}
+
+	if event.Outcome == models.PaymentWebhookReceived {
+		var original uuid.UUID
+		err := tx.Get(&original, `
+			SELECT id FROM payment_webhook_events
+			WHERE payment_uid = $1 AND payment_status = $2 AND duplicate_of IS NULL
+			  AND (outcome = 'processed'
+			       OR (outcome = 'received' AND received_at > NOW() - INTERVAL '`+webhookInFlightWindow+`'))
+			ORDER BY received_at ASC
+			LIMIT 1`, event.PaymentUID, event.PaymentStatus)
+		switch {
+		case err == nil:
+			event.DuplicateOf = &original
+			event.Outcome = models.PaymentWebhookDuplicate
+		case err != sql.ErrNoRows:
+			return fmt.Errorf("failed to check webhook duplicates: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO payment_webhook_events (
+			id, payment_uid, payment_status, status_indicator, invoice_id, outcome,
+			duplicate_of, note, raw_body, client_ip, correlation_id, received_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		event.ID, event.PaymentUID, event.PaymentStatus, event.StatusIndicator, event.InvoiceID,
+		event.Outcome, event.DuplicateOf, event.Note, event.RawBody,
+		event.ClientIP, event.CorrelationID, event.ReceivedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record payment webhook: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit payment webhook: %w", err)
+	}
+	return nil
+}
+
+// Finish records the outcome of an accepted delivery
+func (r *PaymentWebhookEventRepository) Finish(id uuid.UUID, outcome models.PaymentWebhookOutcome, note *string) error {
+	_, err := r.db.Exec(`
+		UPDATE payment_webhook_events
+		SET outcome = $2, note = $3, processed_at = NOW()
+		WHERE id = $1`, id, outcome, note)
+	if err != nil {
+		return fmt.Errorf("failed to update payment webhook: %w", err)
+	}
+	return nil
+}
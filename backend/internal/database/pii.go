@@ -0,0 +1,177 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
+)
+
+// PIIColumn is a column holding personal data that is encrypted at rest once PII encryption
+// keys are configured
+type PIIColumn struct {
+	Table  string
+	Column string
+}
+
+func (c PIIColumn) String() string {
+	return c.Table + "." + c.Column
+}
+
+// PIIColumns are the designated PII columns. Repositories encrypt them on write and decrypt them
+// on read; values written before encryption was enabled are read as plaintext until the
+// pii-reencrypt tool rewrites them. Encrypted columns can no longer be matched by value, so
+// bus_owners.license_number, which staff still look owners up by, stays in plaintext.
+var PIIColumns = []PIIColumn{
+	{Table: "users", Column: "nic"},
+	{Table: "users", Column: "address"},
+	{Table: "passengers", Column: "nic"},
+	{Table: "passengers", Column: "address"},
+	{Table: "bus_staff", Column: "license_number"},
+	{Table: "lounge_staff", Column: "nic_number"},
+	{Table: "bus_owners", Column: "identity_or_incorporation_no"},
+	{Table: "bus_owners", Column: "address"},
+	{Table: "lounge_owners", Column: "manager_nic_number"},
+}
+
+// NewPIICipher builds the PII column cipher from configuration; returns nil, leaving PII in
+// plaintext, when no keys are configured
+func NewPIICipher(cfg config.PIIEncryptionConfig) (*fieldcrypt.Cipher, error) {
+	keys, err := fieldcrypt.ParseKeys(cfg.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PII encryption keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return fieldcrypt.New(keys, cfg.ActiveKeyID)
+}
+
+func encryptPII(pii *fieldcrypt.Cipher, value string) (string, error) {
+	stored, err := pii.Encrypt(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt personal data: %w", err)
+	}
+	return stored, nil
+}
+
+func encryptPIIPtr(pii *fieldcrypt.Cipher, value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	stored, err := encryptPII(pii, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func decryptPII(pii *fieldcrypt.Cipher, value *string) error {
+	if value == nil {
+		return nil
+	}
+	plain, err := pii.Decrypt(*value)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt personal data: %w", err)
+	}
+	*value = plain
+	return nil
+}
+
+func decryptPIINull(pii *fieldcrypt.Cipher, value *sql.NullString) error {
+	if !value.Valid {
+		return nil
+	}
+	return decryptPII(pii, &value.String)
+}
+
+// PIIRewriteResult is the outcome of re-encrypting one PII column
+type PIIRewriteResult struct {
+	Column    PIIColumn
+	Scanned   int // Non-empty values looked at
+	Rewritten int // Plaintext or old-key values re-encrypted with the active key (or that would be, on a dry run)
+	Skipped   int // Values changed by someone else while being rewritten; picked up on the next run
+}
+
+// PIIReencryptor rewrites the designated PII columns so every value is encrypted with the
+// active key: plaintext from before encryption was enabled, and values under rotated-out keys
+type PIIReencryptor struct {
+	db  *sqlx.DB
+	pii *fieldcrypt.Cipher
+}
+
+// NewPIIReencryptor creates a new PIIReencryptor
+func NewPIIReencryptor(db *sqlx.DB, pii *fieldcrypt.Cipher) *PIIReencryptor {
+	return &PIIReencryptor{db: db, pii: pii}
+}
+
+// WidenColumn makes a PII column TEXT, since encrypted values are longer than the plaintext
+// the column was sized for. Must run before encryption is enabled for the app.
+func (r *PIIReencryptor) WidenColumn(col PIIColumn) error {
+	_, err := r.db.Exec(fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s TYPE TEXT`, col.Table, col.Column))
+	if err != nil {
+		return fmt.Errorf("failed to widen %s: %w", col, err)
+	}
+	return nil
+}
+
+// Rewrite re-encrypts a PII column in batches of batchSize rows. A row is only updated if its
+// value is unchanged since it was read, so the app can keep running during the rewrite.
+func (r *PIIReencryptor) Rewrite(col PIIColumn, batchSize int, dryRun bool) (*PIIRewriteResult, error) {
+	if r.pii == nil {
+		return nil, fmt.Errorf("no PII encryption keys configured")
+	}
+	result := &PIIRewriteResult{Column: col}
+	type row struct {
+		ID    string `db:"id"`
+		Value string `db:"value"`
+	}
+	selectQuery := fmt.Sprintf(`
+		SELECT id::text AS id, %[2]s AS value
+		FROM %[1]s
+		WHERE %[2]s IS NOT NULL AND %[2]s <> '' AND id::text > $1
+		ORDER BY id::text
+		LIMIT $2`, col.Table, col.Column)
+	updateQuery := fmt.Sprintf(`UPDATE %[1]s SET %[2]s = $1 WHERE id::text = $2 AND %[2]s = $3`, col.Table, col.Column)
+
+	after := ""
+	for {
+		rows := []row{}
+		if err := r.db.Select(&rows, selectQuery, after, batchSize); err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", col, err)
+		}
+		for _, current := range rows {
+			result.Scanned++
+			if !r.pii.NeedsRotation(current.Value) {
+				continue
+			}
+			plain, err := r.pii.Decrypt(current.Value)
+			if err != nil {
+				return result, fmt.Errorf("failed to decrypt %s of row %s: %w", col, current.ID, err)
+			}
+			stored, err := r.pii.Encrypt(plain)
+			if err != nil {
+				return result, fmt.Errorf("failed to encrypt %s of row %s: %w", col, current.ID, err)
+			}
+			if dryRun {
+				result.Rewritten++
+				continue
+			}
+			res, err := r.db.Exec(updateQuery, stored, current.ID, current.Value)
+			if err != nil {
+				return result, fmt.Errorf("failed to rewrite %s of row %s: %w", col, current.ID, err)
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				result.Skipped++
+				continue
+			}
+			result.Rewritten++
+		}
+		if len(rows) < batchSize {
+			return result, nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
@@ -0,0 +1,289 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	// ErrPromoCodeUsedUp indicates a promo code has no uses left
+	ErrPromoCodeUsedUp = errors.New("promo code has reached its usage limit")
+	// ErrPromoCodeUserLimitReached indicates the user has used a promo code as often as allowed
+	ErrPromoCodeUserLimitReached = errors.New("promo code already used by this user")
+)
+
+// releasePromoUseQuery deletes a promo code use still reserved for a booking that was never
+// made, giving the use back to the code. $1 selects the use (see releaseIntentPromoUse).
+const releasePromoUseQuery = `
+	WITH released AS (
+		DELETE FROM promo_code_redemptions
+		WHERE %s AND master_booking_id IS NULL AND lounge_booking_id IS NULL
+		RETURNING promo_code_id
+	)
+	UPDATE promo_codes pc
+	SET current_usage_count = GREATEST(pc.current_usage_count - 1, 0), updated_at = NOW()
+	FROM released
+	WHERE pc.id = released.promo_code_id`
+
+// releaseIntentPromoUse gives back the promo code use reserved for an intent that has expired or
+// been cancelled. It runs in the transaction that ends the intent.
+func releaseIntentPromoUse(tx *sqlx.Tx, intentID uuid.UUID) error {
+	_, err := tx.Exec(fmt.Sprintf(releasePromoUseQuery, `intent_id = $1
+		  AND EXISTS (SELECT 1 FROM booking_intents WHERE id = $1 AND status IN ('expired', 'cancelled'))`), intentID)
+	if err != nil {
+		return fmt.Errorf("failed to release promo code use: %w", err)
+	}
+	return nil
+}
+
+// promoCodeColumns selects a promo code
+const promoCodeColumns = `
+	id, code, description, discount_type, discount_value, max_discount_amount, min_order_amount,
+	applies_to, valid_from, valid_until, max_usage_count, max_uses_per_user, current_usage_count,
+	is_active, created_by_user_id, created_at, updated_at`
+
+// PromoCodeRepository handles promo_codes and their promo_code_redemptions
+type PromoCodeRepository struct {
+	db *sqlx.DB
+}
+
+// NewPromoCodeRepository creates a new PromoCodeRepository
+func NewPromoCodeRepository(db *sqlx.DB) *PromoCodeRepository {
+	return &PromoCodeRepository{db: db}
+}
+
+// Create inserts a promo code
+func (r *PromoCodeRepository) Create(promo *models.PromoCode) error {
+	query := `
+		INSERT INTO promo_codes (
+			code, description, discount_type, discount_value, max_discount_amount, min_order_amount,
+			applies_to, valid_from, valid_until, max_usage_count, max_uses_per_user, is_active, created_by_user_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, current_usage_count, created_at, updated_at`
+
+	err := r.db.QueryRow(query,
+		promo.Code, promo.Description, promo.DiscountType, promo.DiscountValue, promo.MaxDiscountAmount,
+		promo.MinOrderAmount, promo.AppliesTo, promo.ValidFrom, promo.ValidUntil, promo.MaxUsageCount,
+		promo.MaxUsesPerUser, promo.IsActive, promo.CreatedByUserID,
+	).Scan(&promo.ID, &promo.CurrentUsageCount, &promo.CreatedAt, &promo.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create promo code: %w", err)
+	}
+	return nil
+}
+
+// Update saves a promo code's terms; the usage count is left as it is
+func (r *PromoCodeRepository) Update(promo *models.PromoCode) error {
+	query := `
+		UPDATE promo_codes
+		SET code = $2, description = $3, discount_type = $4, discount_value = $5, max_discount_amount = $6,
+		    min_order_amount = $7, applies_to = $8, valid_from = $9, valid_until = $10, max_usage_count = $11,
+		    max_uses_per_user = $12, is_active = $13, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(query,
+		promo.ID, promo.Code, promo.Description, promo.DiscountType, promo.DiscountValue, promo.MaxDiscountAmount,
+		promo.MinOrderAmount, promo.AppliesTo, promo.ValidFrom, promo.ValidUntil, promo.MaxUsageCount,
+		promo.MaxUsesPerUser, promo.IsActive,
+	).Scan(&promo.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update promo code: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a promo code
+func (r *PromoCodeRepository) Delete(id string) error {
+	if _, err := r.db.Exec(`DELETE FROM promo_codes WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete promo code: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a promo code; returns nil if it does not exist
+func (r *PromoCodeRepository) GetByID(id string) (*models.PromoCode, error) {
+	return r.get(`id = $1`, id)
+}
+
+// GetByCode returns the promo code with a normalized code; returns nil if there is none
+func (r *PromoCodeRepository) GetByCode(code string) (*models.PromoCode, error) {
+	return r.get(`code = $1`, code)
+}
+
+func (r *PromoCodeRepository) get(where string, arg interface{}) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	err := r.db.Get(&promo, `SELECT `+promoCodeColumns+` FROM promo_codes WHERE `+where, arg)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo code: %w", err)
+	}
+	return &promo, nil
+}
+
+// CodeExists reports whether another promo code already uses code
+func (r *PromoCodeRepository) CodeExists(code, excludeID string) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, `
+		SELECT EXISTS (SELECT 1 FROM promo_codes WHERE code = $1 AND id::text <> $2)`, code, excludeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check promo code: %w", err)
+	}
+	return exists, nil
+}
+
+// List returns promo codes, newest first, optionally only active ones
+func (r *PromoCodeRepository) List(activeOnly bool, limit, offset int) ([]models.PromoCode, int, error) {
+	promos := []models.PromoCode{}
+	err := r.db.Select(&promos, `
+		SELECT `+promoCodeColumns+`
+		FROM promo_codes
+		WHERE (NOT $1 OR is_active)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`, activeOnly, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list promo codes: %w", err)
+	}
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM promo_codes WHERE (NOT $1 OR is_active)`, activeOnly); err != nil {
+		return nil, 0, fmt.Errorf("failed to count promo codes: %w", err)
+	}
+	return promos, total, nil
+}
+
+// CountUserRedemptions returns how many times a user has redeemed a promo code
+func (r *PromoCodeRepository) CountUserRedemptions(promoCodeID, userID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `
+		SELECT COUNT(*) FROM promo_code_redemptions WHERE promo_code_id = $1 AND user_id = $2`, promoCodeID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count promo code redemptions: %w", err)
+	}
+	return count, nil
+}
+
+// CountRedemptions returns how many times a promo code has been redeemed
+func (r *PromoCodeRepository) CountRedemptions(promoCodeID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM promo_code_redemptions WHERE promo_code_id = $1`, promoCodeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count promo code redemptions: %w", err)
+	}
+	return count, nil
+}
+
+// Reserve takes one of a promo code's uses for a booking being made, recording the redemption
+// without its booking yet. The code's usage count is only raised while under max_usage_count,
+// and that update locks the code, so the user's uses are counted against max_uses_per_user
+// one reservation at a time. Returns ErrPromoCodeUsedUp or ErrPromoCodeUserLimitReached when a
+// limit has been reached.
+func (r *PromoCodeRepository) Reserve(redemption *models.PromoCodeRedemption) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var maxUsesPerUser *int
+	err = tx.QueryRow(`
+		UPDATE promo_codes SET current_usage_count = current_usage_count + 1, updated_at = NOW()
+		WHERE id = $1 AND (max_usage_count IS NULL OR current_usage_count < max_usage_count)
+		RETURNING max_uses_per_user`, redemption.PromoCodeID).Scan(&maxUsesPerUser)
+	if err == sql.ErrNoRows {
+		return ErrPromoCodeUsedUp
+	}
+	if err != nil {
+		return fmt.Errorf("failed to count promo code usage: %w", err)
+	}
+
+	if maxUsesPerUser != nil {
+		var userUses int
+		if err := tx.Get(&userUses, `
+			SELECT COUNT(*) FROM promo_code_redemptions WHERE promo_code_id = $1 AND user_id = $2`,
+			redemption.PromoCodeID, redemption.UserID); err != nil {
+			return fmt.Errorf("failed to count promo code redemptions: %w", err)
+		}
+		if userUses >= *maxUsesPerUser {
+			return ErrPromoCodeUserLimitReached
+		}
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO promo_code_redemptions (
+			promo_code_id, user_id, intent_id, master_booking_id, lounge_booking_id, discount_amount
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		redemption.PromoCodeID, redemption.UserID, redemption.IntentID, redemption.MasterBookingID,
+		redemption.LoungeBookingID, redemption.DiscountAmount).Scan(&redemption.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record promo code redemption: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit promo code reservation: %w", err)
+	}
+	return nil
+}
+
+// Release gives back a reserved use whose booking could not be made
+func (r *PromoCodeRepository) Release(redemptionID string) error {
+	if _, err := r.db.Exec(fmt.Sprintf(releasePromoUseQuery, `id = $1`), redemptionID); err != nil {
+		return fmt.Errorf("failed to release promo code use: %w", err)
+	}
+	return nil
+}
+
+// AttachBookings records the bookings a reserved use was for, found by its ID or else by its
+// intent. Returns false if no reserved use was found.
+func (r *PromoCodeRepository) AttachBookings(redemption *models.PromoCodeRedemption) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE promo_code_redemptions
+		SET master_booking_id = $3, lounge_booking_id = $4
+		WHERE CASE WHEN $1 <> '' THEN id::text = $1 ELSE intent_id::text = $2 END`,
+		redemption.ID, redemption.IntentID, redemption.MasterBookingID, redemption.LoungeBookingID)
+	if err != nil {
+		return false, fmt.Errorf("failed to attach promo code redemption: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// Redeem records a redemption and counts it against the code's usage limit in one transaction
+func (r *PromoCodeRepository) Redeem(redemption *models.PromoCodeRedemption) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO promo_code_redemptions (
+			promo_code_id, user_id, intent_id, master_booking_id, lounge_booking_id, discount_amount
+		) VALUES ($1, $2, $3, $4, $5, $6)`,
+		redemption.PromoCodeID, redemption.UserID, redemption.IntentID, redemption.MasterBookingID,
+		redemption.LoungeBookingID, redemption.DiscountAmount)
+	if err != nil {
+		return fmt.Errorf("failed to record promo code redemption: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE promo_codes SET current_usage_count = current_usage_count + 1, updated_at = NOW()
+		WHERE id = $1`, redemption.PromoCodeID)
+	if err != nil {
+		return fmt.Errorf("failed to count promo code usage: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit promo code redemption: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// PunctualityRepository handles the nightly trip_schedule_punctuality aggregates
+type PunctualityRepository struct {
+	db DB
+}
+
+// NewPunctualityRepository creates a new PunctualityRepository
+func NewPunctualityRepository(db DB) *PunctualityRepository {
+	return &PunctualityRepository{db: db}
+}
+
+// RefreshSchedulePunctuality recomputes every schedule's on-time performance from the completed
+// trips (active trip summaries) in the rolling window and drops schedules with no trips left in it.
+// Returns the number of schedules refreshed.
+func (r *PunctualityRepository) RefreshSchedulePunctuality(rules models.PunctualityRules) (int, error) {
+	query := `
+		WITH measured AS (
+			SELECT st.trip_schedule_id,
+				EXTRACT(EPOCH FROM (at.actual_departure_time - st.departure_datetime)) / 60 AS departure_delay,
+				CASE WHEN at.actual_arrival_time IS NOT NULL AND st.estimated_duration_minutes IS NOT NULL
+					THEN EXTRACT(EPOCH FROM (at.actual_arrival_time
+						- (st.departure_datetime + st.estimated_duration_minutes * INTERVAL '1 minute'))) / 60
+				END AS arrival_delay
+			FROM active_trips at
+			JOIN scheduled_trips st ON st.id = at.scheduled_trip_id
+			WHERE at.status = 'completed'
+			  AND at.actual_departure_time IS NOT NULL
+			  AND st.trip_schedule_id IS NOT NULL
+			  AND st.departure_datetime >= NOW() - $1::int * INTERVAL '1 day'
+		), aggregated AS (
+			SELECT trip_schedule_id,
+				COUNT(*) AS trips_measured,
+				COUNT(*) FILTER (
+					WHERE departure_delay <= $2
+					  AND (arrival_delay IS NULL OR arrival_delay <= $3)
+				) AS on_time_trips,
+				ROUND(AVG(GREATEST(departure_delay, 0))::numeric, 1) AS avg_departure_delay_minutes
+			FROM measured
+			GROUP BY trip_schedule_id
+		), upserted AS (
+			INSERT INTO trip_schedule_punctuality (
+				trip_schedule_id, trips_measured, on_time_trips, on_time_percentage,
+				avg_departure_delay_minutes, computed_at
+			)
+			SELECT trip_schedule_id, trips_measured, on_time_trips,
+				CASE WHEN trips_measured >= $4
+					THEN ROUND(on_time_trips * 100.0 / trips_measured, 1)
+				END,
+				avg_departure_delay_minutes, NOW()
+			FROM aggregated
+			ON CONFLICT (trip_schedule_id) DO UPDATE SET
+				trips_measured = EXCLUDED.trips_measured,
+				on_time_trips = EXCLUDED.on_time_trips,
+				on_time_percentage = EXCLUDED.on_time_percentage,
+				avg_departure_delay_minutes = EXCLUDED.avg_departure_delay_minutes,
+				computed_at = EXCLUDED.computed_at
+			RETURNING trip_schedule_id
+		), pruned AS (
+			DELETE FROM trip_schedule_punctuality
+			WHERE trip_schedule_id NOT IN (SELECT trip_schedule_id FROM aggregated)
+		)
+		SELECT COUNT(*) FROM upserted
+	`
+
+	var refreshed int
+	err := r.db.QueryRow(query, rules.WindowDays, rules.DepartureGraceMinutes, rules.ArrivalGraceMinutes, rules.MinTrips).Scan(&refreshed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh schedule punctuality: %w", err)
+	}
+	return refreshed, nil
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
+)
+
+// QRSigningKeyRepository stores the keys boarding QRs are signed with. Private keys are
+// encrypted with the PII cipher when one is configured.
+type QRSigningKeyRepository struct {
+	db  *sqlx.DB
+	pii *fieldcrypt.Cipher
+}
+
+// NewQRSigningKeyRepository creates a new QRSigningKeyRepository
+func NewQRSigningKeyRepository(db *sqlx.DB, pii *fieldcrypt.Cipher) *QRSigningKeyRepository {
+	return &QRSigningKeyRepository{db: db, pii: pii}
+}
+
+// ListVerifiable returns the active key and the keys retired after retiredSince, newest first
+func (r *QRSigningKeyRepository) ListVerifiable(retiredSince time.Time) ([]models.QRSigningKey, error) {
+	var keys []models.QRSigningKey
+	err := r.db.Select(&keys, `
+		SELECT id, public_key, private_key, status, created_at, retired_at
+		FROM qr_signing_keys
+		WHERE status = 'active' OR retired_at > $1
+		ORDER BY created_at DESC`, retiredSince)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list QR signing keys: %w", err)
+	}
+	for i := range keys {
+		if err := decryptPII(r.pii, &keys[i].PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// Rotate retires the active key, if any, and makes key the active one
+func (r *QRSigningKeyRepository) Rotate(key *models.QRSigningKey) error {
+	privateKey, err := encryptPII(r.pii, key.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE qr_signing_keys SET status = 'retired', retired_at = NOW()
+		WHERE status = 'active'`); err != nil {
+		return fmt.Errorf("failed to retire QR signing key: %w", err)
+	}
+	err = tx.QueryRow(`
+		INSERT INTO qr_signing_keys (id, public_key, private_key, status, created_at)
+		VALUES ($1, $2, $3, 'active', NOW())
+		RETURNING created_at`, key.ID, key.PublicKey, privateKey).Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create QR signing key: %w", err)
+	}
+	key.Status = models.QRKeyActive
+	return tx.Commit()
+}
+
+// CreateIfNone makes key the active key unless there already is one. Returns false if there was.
+func (r *QRSigningKeyRepository) CreateIfNone(key *models.QRSigningKey) (bool, error) {
+	privateKey, err := encryptPII(r.pii, key.PrivateKey)
+	if err != nil {
+		return false, err
+	}
+	// One active key at a time is enforced by a partial unique index on status = 'active'
+	err = r.db.QueryRow(`
+		INSERT INTO qr_signing_keys (id, public_key, private_key, status, created_at)
+		VALUES ($1, $2, $3, 'active', NOW())
+		ON CONFLICT DO NOTHING
+		RETURNING created_at`, key.ID, key.PublicKey, privateKey).Scan(&key.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create QR signing key: %w", err)
+	}
+	key.Status = models.QRKeyActive
+	return true, nil
+}
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+const refundColumns = `id, booking_id, booking_reference, user_id, intent_id, payment_uid, payment_reference,
+	tenant_id, paid_amount, cancellation_fee, amount, currency, COALESCE(destination, 'card') AS destination,
+	status, reason, gateway_refund_id, failure_reason, review_note, reviewed_by_user_id, reviewed_at, completed_at,
+	seat_cancellation_id, created_at, updated_at`
+
+// RefundRepository handles refunds of cancelled bookings and their status history
+type RefundRepository struct {
+	db *sqlx.DB
+}
+
+// NewRefundRepository creates a new RefundRepository
+func NewRefundRepository(db *sqlx.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+// GetPaymentLink returns the latest PAYable or wallet payment for a booking's bus or lounge
+// part; returns nil if the booking was paid neither way
+func (r *RefundRepository) GetPaymentLink(bookingID string) (*models.RefundPaymentLink, error) {
+	var link models.RefundPaymentLink
+	err := r.db.Get(&link, `
+		SELECT bi.id AS intent_id, bi.payment_uid, bi.payment_reference, b.tenant_id::text AS tenant_id,
+		       COALESCE(bi.wallet_amount, 0) AS wallet_amount
+		FROM bookings b
+		JOIN booking_intents bi ON (bi.payment_uid IS NOT NULL OR COALESCE(bi.wallet_amount, 0) > 0)
+		LEFT JOIN bus_bookings bb ON bb.id = bi.bus_booking_id
+		LEFT JOIN lounge_bookings lb ON lb.id = COALESCE(bi.pre_lounge_booking_id, bi.post_lounge_booking_id)
+		WHERE b.id = $1
+		  AND COALESCE(bb.booking_id, lb.master_booking_id) = b.id
+		ORDER BY bi.created_at DESC
+		LIMIT 1`, bookingID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund payment: %w", err)
+	}
+	return &link, nil
+}
+
+// Create inserts a requested refund with its first history entry, atomically
+func (r *RefundRepository) Create(refund *models.Refund, actorUserID *uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if refund.ID == uuid.Nil {
+		refund.ID = uuid.New()
+	}
+	err = tx.QueryRow(`
+		INSERT INTO refunds (
+			id, booking_id, booking_reference, user_id, intent_id, payment_uid, payment_reference, tenant_id,
+			paid_amount, cancellation_fee, amount, currency, destination, status, reason, seat_cancellation_id,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW(), NOW())
+		RETURNING created_at, updated_at`,
+		refund.ID, refund.BookingID, refund.BookingReference, refund.UserID, refund.IntentID, refund.PaymentUID,
+		refund.PaymentReference, refund.TenantID, refund.PaidAmount, refund.CancellationFee, refund.Amount,
+		refund.Currency, refund.Destination, refund.Status, refund.Reason, refund.SeatCancellationID,
+	).Scan(&refund.CreatedAt, &refund.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	if err := insertRefundEvent(tx, refund.ID, nil, refund.Status, refund.Reason, actorUserID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit refund: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a refund; returns nil if it does not exist
+func (r *RefundRepository) GetByID(id uuid.UUID) (*models.Refund, error) {
+	var refund models.Refund
+	err := r.db.Get(&refund, `SELECT `+refundColumns+` FROM refunds WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+	return &refund, nil
+}
+
+// GetByBooking returns the refund of a booking's cancellation; returns nil if none was
+// requested. Refunds of seats cancelled out of the booking are not it.
+func (r *RefundRepository) GetByBooking(bookingID string) (*models.Refund, error) {
+	var refund models.Refund
+	err := r.db.Get(&refund, `SELECT `+refundColumns+` FROM refunds WHERE booking_id = $1 AND seat_cancellation_id IS NULL`, bookingID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking refund: %w", err)
+	}
+	return &refund, nil
+}
+
+// GetBySeatCancellation returns the refund of a seat cancellation; returns nil if none was requested
+func (r *RefundRepository) GetBySeatCancellation(cancellationID uuid.UUID) (*models.Refund, error) {
+	var refund models.Refund
+	err := r.db.Get(&refund, `SELECT `+refundColumns+` FROM refunds WHERE seat_cancellation_id = $1`, cancellationID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat cancellation refund: %w", err)
+	}
+	return &refund, nil
+}
+
+// List returns a page of refunds, those awaiting action first, with the total count
+func (r *RefundRepository) List(filter models.RefundFilter) ([]models.Refund, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(` AND status = $%d`, len(args))
+	}
+
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM refunds`+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count refunds: %w", err)
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM refunds%s
+		ORDER BY (status IN ('completed', 'rejected')), created_at ASC
+		LIMIT $%d OFFSET $%d`, refundColumns, where, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
+
+	refunds := []models.Refund{}
+	if err := r.db.Select(&refunds, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list refunds: %w", err)
+	}
+	return refunds, total, nil
+}
+
+// GetEvents returns a refund's status history, oldest first
+func (r *RefundRepository) GetEvents(refundID uuid.UUID) ([]models.RefundEvent, error) {
+	events := []models.RefundEvent{}
+	err := r.db.Select(&events, `
+		SELECT id, refund_id, from_status, to_status, note, actor_user_id, created_at
+		FROM refund_events
+		WHERE refund_id = $1
+		ORDER BY created_at ASC`, refundID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund events: %w", err)
+	}
+	return events, nil
+}
+
+// Transition saves refund, already moved to its new status, provided it is still in
+// fromStatus, and records the change. A completed refund also marks the booking refunded; the
+// booking's refunded amount adds up its seat refunds and the refund of its cancellation.
+// Returns false if another update got there first.
+func (r *RefundRepository) Transition(refund *models.Refund, fromStatus models.RefundStatus, note *string, actorUserID *uuid.UUID) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		UPDATE refunds
+		SET status = $3, gateway_refund_id = $4, failure_reason = $5, review_note = $6,
+		    reviewed_by_user_id = $7, reviewed_at = $8, completed_at = $9, updated_at = NOW()
+		WHERE id = $1 AND status = $2
+		RETURNING updated_at`,
+		refund.ID, fromStatus, refund.Status, refund.GatewayRefundID, refund.FailureReason, refund.ReviewNote,
+		refund.ReviewedByUserID, refund.ReviewedAt, refund.CompletedAt,
+	).Scan(&refund.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	if err := insertRefundEvent(tx, refund.ID, &fromStatus, refund.Status, note, actorUserID); err != nil {
+		return false, err
+	}
+
+	if refund.Status == models.RefundCompleted {
+		reference := refund.ID.String()
+		if refund.GatewayRefundID != nil {
+			reference = *refund.GatewayRefundID
+		}
+		// A seat refund leaves the booking's payment status as it is: the seats still booked
+		// stay paid for, or were refunded with the booking's own cancellation
+		var paymentStatus *models.MasterPaymentStatus
+		if refund.SeatCancellationID == nil {
+			status := refund.RefundedPaymentStatus()
+			paymentStatus = &status
+		}
+		_, err = tx.Exec(`
+			UPDATE bookings
+			SET payment_status = COALESCE($2, payment_status), refund_amount = COALESCE(refund_amount, 0) + $3, refund_reference = $4,
+			    refunded_at = COALESCE($5, NOW()), updated_at = NOW()
+			WHERE id = $1`,
+			refund.BookingID, paymentStatus, refund.Amount, reference, refund.CompletedAt)
+		if err != nil {
+			return false, fmt.Errorf("failed to mark booking refunded: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit refund update: %w", err)
+	}
+	return true, nil
+}
+
+func insertRefundEvent(tx *sqlx.Tx, refundID uuid.UUID, from *models.RefundStatus, to models.RefundStatus, note *string, actorUserID *uuid.UUID) error {
+	_, err := tx.Exec(`
+		INSERT INTO refund_events (id, refund_id, from_status, to_status, note, actor_user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())`,
+		uuid.New(), refundID, from, to, note, actorUserID)
+	if err != nil {
+		return fmt.Errorf("failed to record refund event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// ReportSubscriptionRepository handles scheduled report subscriptions and the data behind the reports
+type ReportSubscriptionRepository struct {
+	db *sqlx.DB
+}
+
+// NewReportSubscriptionRepository creates a new ReportSubscriptionRepository
+func NewReportSubscriptionRepository(db *sqlx.DB) *ReportSubscriptionRepository {
+	return &ReportSubscriptionRepository{db: db}
+}
+
+const reportSubscriptionColumns = `id, user_id, subscriber_type, bus_owner_id, report_type, email,
+	unsubscribe_token, is_active, next_run_at, last_sent_at, failed_attempts, last_error,
+	unsubscribed_at, created_at, updated_at`
+
+// ============================================================================
+// SUBSCRIPTIONS
+// ============================================================================
+
+// Create stores a new subscription
+func (r *ReportSubscriptionRepository) Create(sub *models.ReportSubscription) error {
+	sub.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO report_subscriptions (
+			id, user_id, subscriber_type, bus_owner_id, report_type, email,
+			unsubscribe_token, is_active, next_run_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING created_at, updated_at`,
+		sub.ID, sub.UserID, sub.SubscriberType, sub.BusOwnerID, sub.ReportType, sub.Email,
+		sub.UnsubscribeToken, sub.IsActive, sub.NextRunAt,
+	).Scan(&sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create report subscription: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a user's subscription; returns nil if not found
+func (r *ReportSubscriptionRepository) GetByID(id, userID string) (*models.ReportSubscription, error) {
+	var sub models.ReportSubscription
+	err := r.db.Get(&sub, `
+		SELECT `+reportSubscriptionColumns+`
+		FROM report_subscriptions
+		WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get report subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// GetByUnsubscribeToken returns the subscription behind an unsubscribe link; returns nil if not found
+func (r *ReportSubscriptionRepository) GetByUnsubscribeToken(token string) (*models.ReportSubscription, error) {
+	var sub models.ReportSubscription
+	err := r.db.Get(&sub, `
+		SELECT `+reportSubscriptionColumns+`
+		FROM report_subscriptions
+		WHERE unsubscribe_token = $1`, token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get report subscription by token: %w", err)
+	}
+	return &sub, nil
+}
+
+// ListByUser returns a user's subscriptions, including paused and unsubscribed ones
+func (r *ReportSubscriptionRepository) ListByUser(userID string) ([]models.ReportSubscription, error) {
+	subs := []models.ReportSubscription{}
+	err := r.db.Select(&subs, `
+		SELECT `+reportSubscriptionColumns+`
+		FROM report_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// CountActiveByUser counts a user's active subscriptions
+func (r *ReportSubscriptionRepository) CountActiveByUser(userID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `
+		SELECT COUNT(*) FROM report_subscriptions WHERE user_id = $1 AND is_active = true`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count report subscriptions: %w", err)
+	}
+	return count, nil
+}
+
+// ExistsActive checks whether the user already receives the report at the address
+func (r *ReportSubscriptionRepository) ExistsActive(userID string, reportType models.ReportType, email string) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM report_subscriptions
+			WHERE user_id = $1 AND report_type = $2 AND LOWER(email) = LOWER($3) AND is_active = true
+		)`, userID, reportType, email)
+	if err != nil {
+		return false, fmt.Errorf("failed to check report subscription: %w", err)
+	}
+	return exists, nil
+}
+
+// Update saves the email, active flag and schedule of a subscription
+func (r *ReportSubscriptionRepository) Update(sub *models.ReportSubscription) error {
+	err := r.db.QueryRow(`
+		UPDATE report_subscriptions
+		SET email = $2, is_active = $3, next_run_at = $4, failed_attempts = $5,
+		    unsubscribed_at = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		sub.ID, sub.Email, sub.IsActive, sub.NextRunAt, sub.FailedAttempts, sub.UnsubscribedAt,
+	).Scan(&sub.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update report subscription: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a user's subscription; returns false if it did not exist
+func (r *ReportSubscriptionRepository) Delete(id, userID string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM report_subscriptions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete report subscription: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ============================================================================
+// DELIVERY
+// ============================================================================
+
+// GetDue returns active subscriptions whose next delivery is at or before now
+func (r *ReportSubscriptionRepository) GetDue(now time.Time, limit int) ([]models.ReportSubscription, error) {
+	subs := []models.ReportSubscription{}
+	err := r.db.Select(&subs, `
+		SELECT `+reportSubscriptionColumns+`
+		FROM report_subscriptions
+		WHERE is_active = true AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due report subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// MarkDelivered records a successful delivery and schedules the next one
+func (r *ReportSubscriptionRepository) MarkDelivered(id string, sentAt, nextRunAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE report_subscriptions
+		SET last_sent_at = $2, next_run_at = $3, failed_attempts = 0, last_error = NULL, updated_at = NOW()
+		WHERE id = $1`, id, sentAt, nextRunAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark report delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed delivery. nextRunAt is nil to retry on the next cycle, or moves
+// the schedule on (resetting the attempt count) once the retries for this period are used up.
+func (r *ReportSubscriptionRepository) MarkFailed(id, reason string, nextRunAt *time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE report_subscriptions
+		SET last_error = $2,
+		    failed_attempts = CASE WHEN $3::timestamptz IS NULL THEN failed_attempts + 1 ELSE 0 END,
+		    next_run_at = COALESCE($3, next_run_at),
+		    updated_at = NOW()
+		WHERE id = $1`, id, reason, nextRunAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark report delivery failed: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// REPORT DATA
+// ============================================================================
+
+// reportTripScope limits a report to one bus owner's trips, or all trips when $3 is NULL
+const reportTripScope = `($3::uuid IS NULL OR rp.bus_owner_id = $3)`
+
+// GetBookingsReport returns bus bookings made in [from, to)
+func (r *ReportSubscriptionRepository) GetBookingsReport(busOwnerID *string, from, to time.Time, limit int) ([]models.ReportBookingRow, error) {
+	rows := []models.ReportBookingRow{}
+	err := r.db.Select(&rows, `
+		SELECT
+			b.booking_reference,
+			b.created_at as booked_at,
+			COALESCE(mr.route_name, bor.custom_route_name, 'Unknown Route') as route_name,
+			st.departure_datetime,
+			b.passenger_name,
+			bb.number_of_seats,
+			bb.total_fare,
+			b.booking_status,
+			b.payment_status,
+			b.booking_source
+		FROM bus_bookings bb
+		JOIN bookings b ON bb.booking_id = b.id
+		JOIN scheduled_trips st ON bb.scheduled_trip_id = st.id
+		LEFT JOIN route_permits rp ON st.permit_id = rp.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN master_routes mr ON bor.master_route_id = mr.id
+		WHERE b.created_at >= $1 AND b.created_at < $2
+		  AND `+reportTripScope+`
+		ORDER BY b.created_at
+		LIMIT $4`, from, to, busOwnerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookings report: %w", err)
+	}
+	return rows, nil
+}
+
+// GetRevenueReport returns paid bus revenue per local day and route for trips departing in [from, to)
+func (r *ReportSubscriptionRepository) GetRevenueReport(busOwnerID *string, from, to time.Time) ([]models.ReportRevenueRow, error) {
+	rows := []models.ReportRevenueRow{}
+	err := r.db.Select(&rows, `
+		SELECT
+			DATE_TRUNC('day', st.departure_datetime AT TIME ZONE 'Asia/Colombo') as day,
+			COALESCE(mr.route_name, bor.custom_route_name, 'Unknown Route') as route_name,
+			COUNT(DISTINCT st.id) as trips,
+			COUNT(bb.id) as bookings,
+			COALESCE(SUM(bb.number_of_seats), 0) as seats,
+			COALESCE(SUM(bb.total_fare), 0) as revenue
+		FROM scheduled_trips st
+		JOIN bus_bookings bb ON bb.scheduled_trip_id = st.id AND bb.status NOT IN ('cancelled', 'no_show')
+		JOIN bookings b ON bb.booking_id = b.id AND b.payment_status = 'paid'
+		LEFT JOIN route_permits rp ON st.permit_id = rp.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN master_routes mr ON bor.master_route_id = mr.id
+		WHERE st.departure_datetime >= $1 AND st.departure_datetime < $2
+		  AND `+reportTripScope+`
+		GROUP BY 1, 2
+		ORDER BY 1, 2`, from, to, busOwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue report: %w", err)
+	}
+	return rows, nil
+}
+
+// GetOccupancyReport returns seat occupancy for each trip departing in [from, to)
+func (r *ReportSubscriptionRepository) GetOccupancyReport(busOwnerID *string, from, to time.Time, limit int) ([]models.ReportOccupancyRow, error) {
+	rows := []models.ReportOccupancyRow{}
+	err := r.db.Select(&rows, `
+		SELECT
+			st.id as scheduled_trip_id,
+			st.departure_datetime,
+			COALESCE(mr.route_name, bor.custom_route_name, 'Unknown Route') as route_name,
+			st.status,
+			COUNT(ts.id) as total_seats,
+			COUNT(ts.id) FILTER (WHERE ts.status = 'booked') as booked_seats,
+			COUNT(ts.id) FILTER (WHERE ts.status = 'blocked') as blocked_seats
+		FROM scheduled_trips st
+		LEFT JOIN trip_seats ts ON ts.scheduled_trip_id = st.id
+		LEFT JOIN route_permits rp ON st.permit_id = rp.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN master_routes mr ON bor.master_route_id = mr.id
+		WHERE st.departure_datetime >= $1 AND st.departure_datetime < $2
+		  AND `+reportTripScope+`
+		GROUP BY st.id, st.departure_datetime, mr.route_name, bor.custom_route_name, st.status
+		ORDER BY st.departure_datetime
+		LIMIT $4`, from, to, busOwnerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get occupancy report: %w", err)
+	}
+	return rows, nil
+}
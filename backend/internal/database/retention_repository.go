@@ -0,0 +1,83 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RetentionRepository purges and anonymizes personal data that is kept past its use
+type RetentionRepository struct {
+	db *sqlx.DB
+}
+
+// NewRetentionRepository creates a new RetentionRepository
+func NewRetentionRepository(db *sqlx.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// PurgeExpiredOTPs deletes up to limit OTP rows that expired before the cutoff, returning how many
+func (r *RetentionRepository) PurgeExpiredOTPs(expiredBefore time.Time, limit int) (int64, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM otp_verifications
+		WHERE id IN (
+			SELECT id FROM otp_verifications
+			WHERE expires_at < $1
+			ORDER BY expires_at
+			LIMIT $2
+		)`, expiredBefore, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired OTPs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// PurgeLocationHistory deletes up to limit trip location points recorded before the cutoff,
+// returning how many
+func (r *RetentionRepository) PurgeLocationHistory(recordedBefore time.Time, limit int) (int64, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM trip_location_history
+		WHERE id IN (
+			SELECT id FROM trip_location_history
+			WHERE recorded_at < $1
+			ORDER BY recorded_at
+			LIMIT $2
+		)`, recordedBefore, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trip location history: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// AnonymizeAuditLogs strips up to limit audit rows created before the cutoff of their phone
+// number, IP address, user agent and location, returning how many. The phone and IP are kept
+// as salted SHA-256 hashes, and the country code is kept, so rows can still be counted and
+// grouped; the action, entity, user and time are untouched. Anonymized rows are marked with
+// details.anonymized_at and skipped afterwards.
+func (r *RetentionRepository) AnonymizeAuditLogs(createdBefore time.Time, salt string, limit int) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE audit_logs
+		SET details = (COALESCE(details, '{}'::jsonb) - 'phone' - 'ip_address' - 'geo')
+		        || jsonb_strip_nulls(jsonb_build_object(
+		            'phone_hash', CASE WHEN details ? 'phone'
+		                THEN encode(sha256(convert_to($2 || (details->>'phone'), 'UTF8')), 'hex') END,
+		            'ip_hash', CASE WHEN ip_address IS NOT NULL
+		                THEN encode(sha256(convert_to($2 || ip_address::text, 'UTF8')), 'hex') END,
+		            'country_code', details->'geo'->>'country_code',
+		            'anonymized_at', NOW()
+		        )),
+		    ip_address = NULL,
+		    user_agent = NULL
+		WHERE id IN (
+			SELECT id FROM audit_logs
+			WHERE created_at < $1
+			  AND NOT (COALESCE(details, '{}'::jsonb) ? 'anonymized_at')
+			ORDER BY created_at
+			LIMIT $3
+		)`, createdBefore, salt, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize audit logs: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -44,7 +44,8 @@ func (r *RoutePermitRepository) Create(permit *models.RoutePermit) error {
 	return err
 }
 
-// GetByID retrieves a route permit by ID with route details from master_routes
+// GetByID retrieves a route permit by ID with route details from master_routes, including a
+// soft-deleted one (see DeletedAt)
 func (r *RoutePermitRepository) GetByID(permitID string) (*models.RoutePermitWithDetails, error) {
 	query := `
 		SELECT
@@ -52,7 +53,7 @@ func (r *RoutePermitRepository) GetByID(permitID string) (*models.RoutePermitWit
 			rp.master_route_id, rp.via,
 			rp.issue_date, rp.expiry_date, rp.permit_type, rp.approved_fare, rp.approved_seating_capacity, rp.max_trips_per_day,
 			rp.allowed_bus_types, rp.restrictions, rp.status, rp.verified_at, rp.permit_document_url,
-			rp.created_at, rp.updated_at,
+			rp.created_at, rp.updated_at, rp.deleted_at,
 			mr.route_number, mr.route_name, mr.origin_city, mr.destination_city,
 			mr.total_distance_km, mr.estimated_duration_minutes, mr.encoded_polyline
 		FROM route_permits rp
@@ -76,7 +77,7 @@ func (r *RoutePermitRepository) GetByID(permitID string) (*models.RoutePermitWit
 		&permit.MasterRouteID, &via,
 		&permit.IssueDate, &permit.ExpiryDate, &permit.PermitType, &permit.ApprovedFare, &permit.ApprovedSeatingCapacity, &maxTripsPerDay,
 		&allowedBusTypes, &restrictions, &permit.Status, &verifiedAt, &permitDocumentURL,
-		&permit.CreatedAt, &permit.UpdatedAt,
+		&permit.CreatedAt, &permit.UpdatedAt, &permit.DeletedAt,
 		&permit.RouteNumber, &permit.RouteName, &permit.FullOriginCity, &permit.FullDestinationCity,
 		&totalDistanceKm, &estimatedDurationMinutes, &encodedPolyline,
 	)
@@ -130,7 +131,7 @@ func (r *RoutePermitRepository) GetByOwnerID(busOwnerID string) ([]models.RouteP
 			mr.total_distance_km, mr.estimated_duration_minutes, mr.encoded_polyline
 		FROM route_permits rp
 		JOIN master_routes mr ON rp.master_route_id = mr.id
-		WHERE rp.bus_owner_id = $1
+		WHERE rp.bus_owner_id = $1 AND rp.deleted_at IS NULL
 		ORDER BY rp.created_at DESC
 	`
 
@@ -433,24 +434,10 @@ func (r *RoutePermitRepository) Update(permitID string, req *models.UpdateRouteP
 	return err
 }
 
-// Delete deletes a route permit
+// Delete soft-deletes a route permit. Returns an *UpcomingTripsError while future published
+// trips still run under it.
 func (r *RoutePermitRepository) Delete(permitID string, busOwnerID string) error {
-	query := `DELETE FROM route_permits WHERE id = $1 AND bus_owner_id = $2`
-	result, err := r.db.Exec(query, permitID, busOwnerID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+	return softDelete(r.db, models.DeletedEntityPermit, permitID, busOwnerID)
 }
 
 // GetValidPermits retrieves all valid permits for a bus owner with route details
@@ -467,6 +454,7 @@ func (r *RoutePermitRepository) GetValidPermits(busOwnerID string) ([]models.Rou
 		FROM route_permits rp
 		JOIN master_routes mr ON rp.master_route_id = mr.id
 		WHERE rp.bus_owner_id = $1
+		  AND rp.deleted_at IS NULL
 		  AND rp.status = 'verified'
 		  AND rp.expiry_date >= CURRENT_DATE
 		ORDER BY rp.expiry_date ASC
@@ -541,7 +529,7 @@ func (r *RoutePermitRepository) GetValidPermits(busOwnerID string) ([]models.Rou
 
 // CountPermits returns the count of permits for a bus owner
 func (r *RoutePermitRepository) CountPermits(busOwnerID string) (int, error) {
-	query := `SELECT COUNT(*) FROM route_permits WHERE bus_owner_id = $1`
+	query := `SELECT COUNT(*) FROM route_permits WHERE bus_owner_id = $1 AND deleted_at IS NULL`
 	var count int
 	err := r.db.QueryRow(query, busOwnerID).Scan(&count)
 	return count, err
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RoutePolylineRepository stores the road geometry of master routes and bus owner routes
+type RoutePolylineRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoutePolylineRepository creates a new RoutePolylineRepository
+func NewRoutePolylineRepository(db *sqlx.DB) *RoutePolylineRepository {
+	return &RoutePolylineRepository{db: db}
+}
+
+// SetMasterRoutePolyline stores a master route's encoded polyline; returns false if the route does not exist
+func (r *RoutePolylineRepository) SetMasterRoutePolyline(masterRouteID, encoded string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE master_routes
+		SET encoded_polyline = $2, updated_at = NOW()
+		WHERE id = $1`, masterRouteID, encoded)
+	if err != nil {
+		return false, fmt.Errorf("failed to save master route polyline: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetBusOwnerRoutePolyline returns a bus owner route's own polyline; returns nil if it has none
+func (r *RoutePolylineRepository) GetBusOwnerRoutePolyline(busOwnerRouteID string) (*string, error) {
+	var encoded sql.NullString
+	err := r.db.QueryRow(`SELECT encoded_polyline FROM bus_owner_routes WHERE id = $1`, busOwnerRouteID).Scan(&encoded)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bus owner route polyline: %w", err)
+	}
+	if !encoded.Valid || encoded.String == "" {
+		return nil, nil
+	}
+	return &encoded.String, nil
+}
+
+// SetBusOwnerRoutePolyline stores a bus owner route's encoded polyline; nil clears it so the
+// route falls back to its master route's. Returns false if the owner has no such route.
+func (r *RoutePolylineRepository) SetBusOwnerRoutePolyline(busOwnerRouteID, busOwnerID string, encoded *string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE bus_owner_routes
+		SET encoded_polyline = $3, updated_at = NOW()
+		WHERE id = $1 AND bus_owner_id = $2`, busOwnerRouteID, busOwnerID, encoded)
+	if err != nil {
+		return false, fmt.Errorf("failed to save bus owner route polyline: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// RouteSegmentRepository caches routing engine results for master route segments
+type RouteSegmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewRouteSegmentRepository creates a new RouteSegmentRepository
+func NewRouteSegmentRepository(db *sqlx.DB) *RouteSegmentRepository {
+	return &RouteSegmentRepository{db: db}
+}
+
+// GetSegments returns a master route's cached segments in travel order
+func (r *RouteSegmentRepository) GetSegments(masterRouteID string) ([]models.RouteSegment, error) {
+	segments := []models.RouteSegment{}
+	err := r.db.Select(&segments, `
+		SELECT rs.master_route_id, rs.from_stop_id, rs.to_stop_id,
+		       rs.from_latitude, rs.from_longitude, rs.to_latitude, rs.to_longitude,
+		       rs.distance_meters, rs.duration_seconds, rs.engine, rs.computed_at
+		FROM route_segments rs
+		JOIN master_route_stops fs ON fs.id = rs.from_stop_id
+		WHERE rs.master_route_id = $1
+		ORDER BY fs.stop_order`, masterRouteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route segments: %w", err)
+	}
+	return segments, nil
+}
+
+// ReplaceSegments swaps a master route's cached segments for newly computed ones
+func (r *RouteSegmentRepository) ReplaceSegments(masterRouteID string, segments []models.RouteSegment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM route_segments WHERE master_route_id = $1`, masterRouteID); err != nil {
+		return fmt.Errorf("failed to clear route segments: %w", err)
+	}
+	for _, segment := range segments {
+		_, err := tx.Exec(`
+			INSERT INTO route_segments (
+				master_route_id, from_stop_id, to_stop_id,
+				from_latitude, from_longitude, to_latitude, to_longitude,
+				distance_meters, duration_seconds, engine, computed_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			masterRouteID, segment.FromStopID, segment.ToStopID,
+			segment.FromLatitude, segment.FromLongitude, segment.ToLatitude, segment.ToLongitude,
+			segment.DistanceMeters, segment.DurationSeconds, segment.Engine, segment.ComputedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save route segment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit route segments: %w", err)
+	}
+	return nil
+}
+
+// ApplyEstimates writes computed stop arrival offsets and the route's total distance and duration
+func (r *RouteSegmentRepository) ApplyEstimates(masterRouteID string, stopOffsets map[string]int, totalDistanceKm float64, totalDurationMinutes int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for stopID, minutes := range stopOffsets {
+		_, err := tx.Exec(`
+			UPDATE master_route_stops SET arrival_time_offset_minutes = $3
+			WHERE id = $1 AND master_route_id = $2`, stopID, masterRouteID, minutes)
+		if err != nil {
+			return fmt.Errorf("failed to update stop arrival offset: %w", err)
+		}
+	}
+	_, err = tx.Exec(`
+		UPDATE master_routes
+		SET total_distance_km = $2, estimated_duration_minutes = $3, updated_at = NOW()
+		WHERE id = $1`, masterRouteID, totalDistanceKm, totalDurationMinutes)
+	if err != nil {
+		return fmt.Errorf("failed to update route estimates: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit route estimates: %w", err)
+	}
+	return nil
+}
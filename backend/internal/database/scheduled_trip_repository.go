@@ -28,9 +28,15 @@ func (r *ScheduledTripRepository) Create(trip *models.ScheduledTrip) error {
 		INSERT INTO scheduled_trips (
 			id, trip_schedule_id, bus_owner_route_id, permit_id, departure_datetime,
 			estimated_duration_minutes, assigned_driver_id, assigned_conductor_id, seat_layout_id,
-			is_bookable, ever_published, base_fare, assignment_deadline, status
+			is_bookable, ever_published, base_fare, assignment_deadline, status, tenant_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+			-- Trips belong to the owner's tenant (NULL = SmartTransit)
+			(SELECT bo.tenant_id FROM bus_owners bo
+			 WHERE bo.id = COALESCE(
+				(SELECT bus_owner_id FROM bus_owner_routes WHERE id = $3),
+				(SELECT bus_owner_id FROM route_permits WHERE id = $4),
+				(SELECT bus_owner_id FROM trip_schedules WHERE id = $2)))
 		)
 		RETURNING created_at, updated_at
 	`
@@ -739,6 +745,27 @@ func (r *ScheduledTripRepository) UnpublishTrip(tripID string, busOwnerID string
 	return nil
 }
 
+// GetMasterRouteIDs returns the distinct master routes (corridors) the trips run on
+func (r *ScheduledTripRepository) GetMasterRouteIDs(tripIDs []string) ([]string, error) {
+	if len(tripIDs) == 0 {
+		return nil, nil
+	}
+
+	var routeIDs []string
+	err := r.db.Select(&routeIDs, `
+		SELECT DISTINCT COALESCE(bor.master_route_id, rp.master_route_id)::text
+		FROM scheduled_trips st
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN route_permits rp ON st.permit_id = rp.id
+		WHERE st.id = ANY($1::text[])
+		  AND COALESCE(bor.master_route_id, rp.master_route_id) IS NOT NULL`,
+		pq.Array(tripIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip master routes: %w", err)
+	}
+	return routeIDs, nil
+}
+
 // BulkPublishTrips publishes multiple trips for booking at once
 func (r *ScheduledTripRepository) BulkPublishTrips(tripIDs []string, busOwnerID string) (int, error) {
 	if len(tripIDs) == 0 {
@@ -883,6 +910,26 @@ func (r *ScheduledTripRepository) AssignStaffAndPermit(tripID string, driverID,
 	return nil
 }
 
+// GetDriverDuties returns the trips other than excludeTripID that a driver is assigned to,
+// departing in [from, to). Cancelled trips are left out.
+func (r *ScheduledTripRepository) GetDriverDuties(driverID, excludeTripID string, from, to time.Time) ([]models.DriverDuty, error) {
+	query := `
+		SELECT id, departure_datetime, estimated_duration_minutes
+		FROM scheduled_trips
+		WHERE assigned_driver_id = $1
+		  AND id <> $2
+		  AND status <> 'cancelled'
+		  AND departure_datetime >= $3 AND departure_datetime < $4
+		ORDER BY departure_datetime
+	`
+
+	duties := []models.DriverDuty{}
+	if err := r.db.Select(&duties, query, driverID, excludeTripID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to get driver duties: %w", err)
+	}
+	return duties, nil
+}
+
 // AssignSeatLayout assigns a seat layout template to a scheduled trip
 func (r *ScheduledTripRepository) AssignSeatLayout(tripID string, seatLayoutID *string) error {
 	query := `UPDATE scheduled_trips SET seat_layout_id = $1, updated_at = $2 WHERE id = $3`
@@ -898,13 +945,34 @@ type scanner interface {
 	Scan(dest ...interface{}) error
 }
 
-// GetAssignedTripsForStaff retrieves trips assigned to a driver or conductor
-// Returns trips where the staff member is assigned as driver OR conductor
-func (r *ScheduledTripRepository) GetAssignedTripsForStaff(staffID string, startDate, endDate time.Time) ([]models.ScheduledTripWithRouteInfo, error) {
-	log.Printf("GetAssignedTripsForStaff: staff_id=%s, dates=%s to %s",
-		staffID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+// GetAssignedTripsForStaff returns a page of trips where the staff member is driver or conductor,
+// along with the total number of matching trips
+func (r *ScheduledTripRepository) GetAssignedTripsForStaff(staffID string, filter models.StaffTripFilter) ([]models.ScheduledTripWithRouteInfo, int, error) {
+	log.Printf("GetAssignedTripsForStaff: staff_id=%s, dates=%s to %s, statuses=%v",
+		staffID, filter.StartDate.Format("2006-01-02"), filter.EndDate.Format("2006-01-02"), filter.Statuses)
 
-	query := `
+	where := `
+		WHERE (st.assigned_driver_id = $1 OR st.assigned_conductor_id = $1)
+		  AND DATE(st.departure_datetime) BETWEEN $2 AND $3`
+	args := []interface{}{staffID, filter.StartDate, filter.EndDate}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		where += ` AND st.status = ANY($4)`
+		args = append(args, pq.Array(statuses))
+	} else {
+		where += ` AND st.status NOT IN ('cancelled', 'completed')`
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM scheduled_trips st`+where, args...).Scan(&total); err != nil {
+		log.Printf("GetAssignedTripsForStaff: Count error: %v", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
 		SELECT 
 			st.id, st.trip_schedule_id, st.permit_id, st.departure_datetime,
 			st.estimated_duration_minutes, st.assigned_driver_id, st.assigned_conductor_id,
@@ -917,25 +985,76 @@ func (r *ScheduledTripRepository) GetAssignedTripsForStaff(staffID string, start
 		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
 		LEFT JOIN bus_owner_routes bor ON COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id) = bor.id
 		LEFT JOIN master_routes mr ON bor.master_route_id = mr.id
-		WHERE (st.assigned_driver_id = $1 OR st.assigned_conductor_id = $1)
-		  AND DATE(st.departure_datetime) BETWEEN $2 AND $3
-		  AND st.status NOT IN ('cancelled', 'completed')
+		%s
 		ORDER BY st.departure_datetime ASC
-	`
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
 
-	rows, err := r.db.Query(query, staffID, startDate, endDate)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		log.Printf("GetAssignedTripsForStaff: Query error: %v", err)
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
 	trips, err := r.scanTripsWithRouteInfo(rows)
 	if err != nil {
 		log.Printf("GetAssignedTripsForStaff: Scan error: %v", err)
-		return nil, err
+		return nil, 0, err
 	}
 
-	log.Printf("GetAssignedTripsForStaff: Found %d trips for staff %s", len(trips), staffID)
-	return trips, nil
+	log.Printf("GetAssignedTripsForStaff: Found %d of %d trips for staff %s", len(trips), total, staffID)
+	return trips, total, nil
+}
+
+// GetNextAssignmentForStaff returns the staff member's trip in progress or, failing that, their
+// next upcoming trip; returns nil if there is none
+func (r *ScheduledTripRepository) GetNextAssignmentForStaff(staffID string) (*models.StaffNextAssignment, error) {
+	query := `
+		SELECT 
+			st.id, st.status, st.departure_datetime, st.assigned_driver_id, st.assigned_conductor_id,
+			mr.route_number, mr.origin_city, mr.destination_city, rp.bus_registration_number
+		FROM scheduled_trips st
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id) = bor.id
+		LEFT JOIN master_routes mr ON bor.master_route_id = mr.id
+		LEFT JOIN route_permits rp ON st.permit_id = rp.id
+		WHERE (st.assigned_driver_id = $1 OR st.assigned_conductor_id = $1)
+		  AND (st.status = 'in_progress'
+		       OR (st.status IN ('scheduled', 'confirmed') AND st.departure_datetime >= NOW()))
+		ORDER BY (st.status = 'in_progress') DESC, st.departure_datetime ASC
+		LIMIT 1
+	`
+
+	var next models.StaffNextAssignment
+	var assignedDriverID, assignedConductorID sql.NullString
+	var routeNumber, originCity, destinationCity, busRegistration sql.NullString
+	err := r.db.QueryRow(query, staffID).Scan(
+		&next.TripID, &next.Status, &next.DepartureDatetime, &assignedDriverID, &assignedConductorID,
+		&routeNumber, &originCity, &destinationCity, &busRegistration,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next assignment: %w", err)
+	}
+
+	next.ReportBy = next.DepartureDatetime.Add(-models.StaffReportLead)
+	next.IsDriver = assignedDriverID.Valid && assignedDriverID.String == staffID
+	next.IsConductor = assignedConductorID.Valid && assignedConductorID.String == staffID
+	if routeNumber.Valid {
+		next.RouteNumber = &routeNumber.String
+	}
+	if originCity.Valid {
+		next.OriginCity = &originCity.String
+	}
+	if destinationCity.Valid {
+		next.DestinationCity = &destinationCity.String
+	}
+	if busRegistration.Valid {
+		next.BusRegistrationNumber = &busRegistration.String
+	}
+	return &next, nil
 }
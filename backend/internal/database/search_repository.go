@@ -78,6 +78,8 @@ type StopPairResult struct {
 	ToID      uuid.UUID
 	RouteID   uuid.UUID
 	RouteName string
+	FromOrder int // Stop orders on the matched route, for segment availability
+	ToOrder   int
 	Matched   bool
 }
 
@@ -170,6 +172,8 @@ func (r *SearchRepository) FindStopPairOnSameRoute(fromName, toName string) (*St
 		ToID:      result.ToID,
 		RouteID:   result.RouteID,
 		RouteName: result.RouteName,
+		FromOrder: result.FromOrder,
+		ToOrder:   result.ToOrder,
 		Matched:   true,
 	}, nil
 }
@@ -179,6 +183,7 @@ func (r *SearchRepository) FindDirectTrips(
 	fromStopID, toStopID uuid.UUID,
 	afterTime time.Time,
 	limit int,
+	tenantID *string, // Only trips run by this tenant's operators (nil = SmartTransit)
 ) ([]models.TripResult, error) {
 	// Log search parameters
 	fmt.Printf("\n🔍 === SEARCH QUERY DEBUG ===\n")
@@ -233,6 +238,8 @@ func (r *SearchRepository) FindDirectTrips(
 			COALESCE(b.has_entertainment, false) as has_entertainment,
 			COALESCE(b.has_refreshments, false) as has_refreshments,
 			st.is_bookable,
+			-- Nightly on-time performance of the trip's schedule
+			tsp.on_time_percentage,
 			-- Route info for fetching stops
 			bor.id as bus_owner_route_id,
 			-- Use bor.master_route_id first, fall back to permit's master_route_id
@@ -249,6 +256,7 @@ func (r *SearchRepository) FindDirectTrips(
 		LEFT JOIN buses b ON rp.bus_registration_number = b.license_plate
 		-- Join seat layout template to get total_seats
 		LEFT JOIN bus_seat_layout_templates bslt ON b.seat_layout_id = bslt.id
+		LEFT JOIN trip_schedule_punctuality tsp ON tsp.trip_schedule_id = st.trip_schedule_id
 		-- Get stop information
 		JOIN master_route_stops from_stop ON from_stop.id = $1
 		JOIN master_route_stops to_stop ON to_stop.id = $2
@@ -266,6 +274,8 @@ func (r *SearchRepository) FindDirectTrips(
 			AND st.status IN ('scheduled', 'confirmed')
 			-- Departure must be in the future
 			AND st.departure_datetime > $3
+			-- Trip must belong to the tenant the app is running as
+			AND st.tenant_id IS NOT DISTINCT FROM $5::uuid
 			-- Stops must be in correct order
 			AND check_from.stop_order < check_to.stop_order
 			-- For bus owner routes, check if stops are selected
@@ -280,6 +290,8 @@ func (r *SearchRepository) FindDirectTrips(
 					AND $2 = ANY(bor.selected_stop_ids)
 				)
 			)
+			-- Online sales must not be blacked out for the trip's route or schedule
+			AND NOT EXISTS (` + activeBlackoutForTrip + `)
 		ORDER BY st.id, st.departure_datetime
 		LIMIT $4
 	`
@@ -303,13 +315,14 @@ func (r *SearchRepository) FindDirectTrips(
 		HasEntertainment bool      `db:"has_entertainment"`
 		HasRefreshments  bool      `db:"has_refreshments"`
 		IsBookable       bool      `db:"is_bookable"`
+		OnTimePercentage *float64  `db:"on_time_percentage"`
 		// Route info for fetching stops
 		BusOwnerRouteID *string `db:"bus_owner_route_id"`
 		MasterRouteID   *string `db:"master_route_id"`
 	}
 
 	var tempTrips []tripWithFeatures
-	err := r.db.Select(&tempTrips, query, fromStopID, toStopID, afterTime, limit)
+	err := r.db.Select(&tempTrips, query, fromStopID, toStopID, afterTime, limit, tenantID)
 	if err != nil {
 		fmt.Printf("❌ SQL Query Error: %v\n", err)
 		return nil, fmt.Errorf("error finding trips: %w", err)
@@ -442,15 +455,52 @@ func (r *SearchRepository) FindDirectTrips(
 				HasEntertainment: temp.HasEntertainment,
 				HasRefreshments:  temp.HasRefreshments,
 			},
-			IsBookable:      temp.IsBookable,
-			BusOwnerRouteID: temp.BusOwnerRouteID,
-			MasterRouteID:   temp.MasterRouteID,
+			IsBookable:       temp.IsBookable,
+			OnTimePercentage: temp.OnTimePercentage,
+			BusOwnerRouteID:  temp.BusOwnerRouteID,
+			MasterRouteID:    temp.MasterRouteID,
 		}
 	}
 
 	return trips, nil
 }
 
+// GetTripSeatOccupancy returns the seats of the given trips with the stop range of the app or
+// manual booking holding each one
+func (r *SearchRepository) GetTripSeatOccupancy(tripIDs []string) ([]models.TripSeatOccupancy, error) {
+	if len(tripIDs) == 0 {
+		return []models.TripSeatOccupancy{}, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT
+			ts.scheduled_trip_id,
+			ts.seat_price,
+			ts.status,
+			(ts.held_by_intent_id IS NOT NULL AND ts.held_until > NOW()) as held,
+			COALESCE(bb_board.stop_order, msb_board.stop_order) as booked_from_order,
+			COALESCE(bb_alight.stop_order, msb_alight.stop_order) as booked_to_order
+		FROM trip_seats ts
+		LEFT JOIN bus_booking_seats bbs ON bbs.id = ts.bus_booking_seat_id
+		LEFT JOIN bus_bookings bb ON bb.id = bbs.bus_booking_id
+		LEFT JOIN master_route_stops bb_board ON bb_board.id::text = bb.boarding_stop_id::text
+		LEFT JOIN master_route_stops bb_alight ON bb_alight.id::text = bb.alighting_stop_id::text
+		LEFT JOIN manual_seat_bookings msb ON msb.id = ts.manual_booking_id
+		LEFT JOIN master_route_stops msb_board ON msb_board.id::text = msb.boarding_stop_id::text
+		LEFT JOIN master_route_stops msb_alight ON msb_alight.id::text = msb.alighting_stop_id::text
+		WHERE ts.scheduled_trip_id IN (?)
+	`, tripIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var seats []models.TripSeatOccupancy
+	if err := r.db.Select(&seats, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("error getting trip seat occupancy: %w", err)
+	}
+	return seats, nil
+}
+
 // LogSearch records a search query for analytics
 func (r *SearchRepository) LogSearch(log *models.SearchLog) error {
 	query := `
@@ -462,8 +512,9 @@ func (r *SearchRepository) LogSearch(log *models.SearchLog) error {
 			results_count,
 			response_time_ms,
 			user_id,
-			ip_address
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ip_address,
+			requested_departure_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	_, err := r.db.Exec(
@@ -476,6 +527,7 @@ func (r *SearchRepository) LogSearch(log *models.SearchLog) error {
 		log.ResponseTimeMs,
 		log.UserID,
 		log.IPAddress,
+		log.RequestedDepartureAt,
 	)
 
 	if err != nil {
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// SeatCapacityRepository reads seat layout sizes alongside bus and permit capacities
+type SeatCapacityRepository struct {
+	db *sqlx.DB
+}
+
+// NewSeatCapacityRepository creates a new SeatCapacityRepository
+func NewSeatCapacityRepository(db *sqlx.DB) *SeatCapacityRepository {
+	return &SeatCapacityRepository{db: db}
+}
+
+// GetTripChecks returns each trip's seat layout with its bus and permit capacities. layoutID,
+// when set, stands in for the trips' own layout (checking an assignment before it is made).
+// Trips without a layout are left out.
+func (r *SeatCapacityRepository) GetTripChecks(tripIDs []string, layoutID *string) ([]models.SeatCapacityCheck, error) {
+	checks := []models.SeatCapacityCheck{}
+	if len(tripIDs) == 0 {
+		return checks, nil
+	}
+	err := r.db.Select(&checks, `
+		SELECT st.id::text AS trip_id,
+		       bslt.id::text AS layout_id, bslt.template_name AS layout_name, bslt.total_seats AS layout_seats,
+		       b.id::text AS bus_id, b.license_plate AS bus_license_plate, b.seating_capacity AS bus_seating_capacity,
+		       rp.id::text AS permit_id, rp.permit_number, rp.approved_seating_capacity AS permit_approved_capacity
+		FROM scheduled_trips st
+		LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+		LEFT JOIN route_permits rp ON rp.id = COALESCE(st.permit_id, ts.permit_id)
+		LEFT JOIN buses b ON b.id = st.bus_id OR (st.bus_id IS NULL AND b.permit_id = rp.id)
+		JOIN bus_seat_layout_templates bslt ON bslt.id = COALESCE($2::uuid, st.seat_layout_id)
+		WHERE st.id::text = ANY($1::text[])
+		ORDER BY st.departure_datetime`,
+		pq.Array(tripIDs), layoutID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip seat capacities: %w", err)
+	}
+	return checks, nil
+}
+
+// GetLayoutCheck returns a seat layout's size with the approved capacity of a permit, or nil if
+// the layout does not exist. The bus capacity is left for the caller to fill in.
+func (r *SeatCapacityRepository) GetLayoutCheck(layoutID, permitID string) (*models.SeatCapacityCheck, error) {
+	var check models.SeatCapacityCheck
+	err := r.db.Get(&check, `
+		SELECT bslt.id::text AS layout_id, bslt.template_name AS layout_name, bslt.total_seats AS layout_seats,
+		       rp.id::text AS permit_id, rp.permit_number, rp.approved_seating_capacity AS permit_approved_capacity
+		FROM bus_seat_layout_templates bslt
+		LEFT JOIN route_permits rp ON rp.id::text = $2
+		WHERE bslt.id::text = $1`,
+		layoutID, permitID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat layout capacity: %w", err)
+	}
+	return &check, nil
+}
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// seatPricingRuleColumns selects a seat pricing rule
+const seatPricingRuleColumns = `
+	id, bus_owner_id, bus_owner_route_id, trip_schedule_id, rule_type, name, multiplier,
+	min_hours_before, max_hours_before, weekdays, min_occupancy_percent, is_active,
+	created_at, updated_at`
+
+// seatPricingTripQuery selects scheduled trips (aliased st) with what their pricing rules are
+// evaluated on. Occupancy counts booked and reserved seats out of those not blocked.
+const seatPricingTripQuery = `
+	SELECT st.id AS trip_id,
+	       COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS bus_owner_id,
+	       st.trip_schedule_id,
+	       COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id) AS bus_owner_route_id,
+	       st.departure_datetime, st.base_fare,
+	       NULLIF(rp.approved_fare, 0) AS fare_cap,
+	       COUNT(seat.id) FILTER (WHERE seat.status <> 'blocked') AS total_seats,
+	       COUNT(seat.id) FILTER (WHERE seat.status IN ('booked', 'reserved')) AS taken_seats
+	FROM scheduled_trips st
+	JOIN trip_seats seat ON seat.scheduled_trip_id = st.id
+	LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+	LEFT JOIN bus_owner_routes bor ON bor.id = st.bus_owner_route_id
+	LEFT JOIN route_permits rp ON rp.id = COALESCE(st.permit_id, ts.permit_id)
+	WHERE st.status IN ('scheduled', 'confirmed')
+	  AND st.departure_datetime > NOW()`
+
+// seatPricingTripGroupBy closes seatPricingTripQuery
+const seatPricingTripGroupBy = `
+	GROUP BY st.id, ts.bus_owner_id, bor.bus_owner_id, ts.bus_owner_route_id, rp.approved_fare`
+
+// SeatPricingRuleRepository handles seat_pricing_rules and reprices trip seats from them
+type SeatPricingRuleRepository struct {
+	db *sqlx.DB
+}
+
+// NewSeatPricingRuleRepository creates a new SeatPricingRuleRepository
+func NewSeatPricingRuleRepository(db *sqlx.DB) *SeatPricingRuleRepository {
+	return &SeatPricingRuleRepository{db: db}
+}
+
+// ============================================================================
+// RULES
+// ============================================================================
+
+// Create inserts a rule
+func (r *SeatPricingRuleRepository) Create(rule *models.SeatPricingRule) error {
+	query := `
+		INSERT INTO seat_pricing_rules (
+			bus_owner_id, bus_owner_route_id, trip_schedule_id, rule_type, name, multiplier,
+			min_hours_before, max_hours_before, weekdays, min_occupancy_percent, is_active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRow(query,
+		rule.BusOwnerID, rule.BusOwnerRouteID, rule.TripScheduleID, rule.RuleType, rule.Name, rule.Multiplier,
+		rule.MinHoursBefore, rule.MaxHoursBefore, rule.Weekdays, rule.MinOccupancyPercent, rule.IsActive,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create seat pricing rule: %w", err)
+	}
+	return nil
+}
+
+// Update saves a rule's condition, multiplier and active flag
+func (r *SeatPricingRuleRepository) Update(rule *models.SeatPricingRule) error {
+	query := `
+		UPDATE seat_pricing_rules
+		SET rule_type = $2, name = $3, multiplier = $4, min_hours_before = $5, max_hours_before = $6,
+		    weekdays = $7, min_occupancy_percent = $8, is_active = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(query,
+		rule.ID, rule.RuleType, rule.Name, rule.Multiplier, rule.MinHoursBefore, rule.MaxHoursBefore,
+		rule.Weekdays, rule.MinOccupancyPercent, rule.IsActive,
+	).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update seat pricing rule: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a rule
+func (r *SeatPricingRuleRepository) Delete(id string) error {
+	if _, err := r.db.Exec(`DELETE FROM seat_pricing_rules WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete seat pricing rule: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a rule; returns nil if it does not exist
+func (r *SeatPricingRuleRepository) GetByID(id string) (*models.SeatPricingRule, error) {
+	var rule models.SeatPricingRule
+	err := r.db.Get(&rule, `SELECT `+seatPricingRuleColumns+` FROM seat_pricing_rules WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat pricing rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// ListByOwner returns an owner's rules, optionally only those of one route or schedule
+func (r *SeatPricingRuleRepository) ListByOwner(busOwnerID string, routeID, scheduleID *string) ([]models.SeatPricingRule, error) {
+	rules := []models.SeatPricingRule{}
+	err := r.db.Select(&rules, `
+		SELECT `+seatPricingRuleColumns+`
+		FROM seat_pricing_rules
+		WHERE bus_owner_id = $1
+		  AND ($2::uuid IS NULL OR bus_owner_route_id = $2::uuid)
+		  AND ($3::uuid IS NULL OR trip_schedule_id = $3::uuid)
+		ORDER BY created_at`, busOwnerID, routeID, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seat pricing rules: %w", err)
+	}
+	return rules, nil
+}
+
+// ListForTrip returns the active rules on a trip's schedule or route
+func (r *SeatPricingRuleRepository) ListForTrip(trip *models.SeatPricingTrip) ([]models.SeatPricingRule, error) {
+	rules := []models.SeatPricingRule{}
+	err := r.db.Select(&rules, `
+		SELECT `+seatPricingRuleColumns+`
+		FROM seat_pricing_rules
+		WHERE is_active
+		  AND (trip_schedule_id = $1::uuid OR bus_owner_route_id = $2::uuid)`,
+		trip.TripScheduleID, trip.BusOwnerRouteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trip pricing rules: %w", err)
+	}
+	return rules, nil
+}
+
+// ============================================================================
+// TRIPS
+// ============================================================================
+
+// GetPricingTrip returns a trip with seats that is still open for booking; returns nil otherwise
+func (r *SeatPricingRuleRepository) GetPricingTrip(tripID string) (*models.SeatPricingTrip, error) {
+	var trip models.SeatPricingTrip
+	err := r.db.Get(&trip, seatPricingTripQuery+` AND st.id = $1`+seatPricingTripGroupBy, tripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip for pricing: %w", err)
+	}
+	return &trip, nil
+}
+
+// ListRuledTrips returns upcoming trips with seats whose schedule or route has an active rule,
+// by departure after the (departure, id) cursor
+func (r *SeatPricingRuleRepository) ListRuledTrips(afterDeparture time.Time, afterID string, limit int) ([]models.SeatPricingTrip, error) {
+	trips := []models.SeatPricingTrip{}
+	err := r.db.Select(&trips, seatPricingTripQuery+`
+		  AND (st.departure_datetime, st.id::text) > ($1, $2)
+		  AND EXISTS (
+			SELECT 1 FROM seat_pricing_rules spr
+			WHERE spr.is_active
+			  AND (spr.trip_schedule_id = st.trip_schedule_id
+			       OR spr.bus_owner_route_id = COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id))
+		  )`+seatPricingTripGroupBy+`
+		ORDER BY st.departure_datetime, st.id::text
+		LIMIT $3`, afterDeparture, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trips to reprice: %w", err)
+	}
+	return trips, nil
+}
+
+// ListTargetTrips returns upcoming trips with seats on a route or schedule, with or without rules
+func (r *SeatPricingRuleRepository) ListTargetTrips(routeID, scheduleID *string) ([]models.SeatPricingTrip, error) {
+	trips := []models.SeatPricingTrip{}
+	err := r.db.Select(&trips, seatPricingTripQuery+`
+		  AND (st.trip_schedule_id = $1::uuid
+		       OR COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id) = $2::uuid)`+seatPricingTripGroupBy+`
+		ORDER BY st.departure_datetime`, scheduleID, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trips to reprice: %w", err)
+	}
+	return trips, nil
+}
+
+// RepriceSeats sets the price of a trip's available seats that the owner has not priced by
+// hand. Booked and reserved seats keep the price they were sold at.
+func (r *SeatPricingRuleRepository) RepriceSeats(tripID string, price float64) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE trip_seats
+		SET seat_price = $2, updated_at = NOW()
+		WHERE scheduled_trip_id = $1
+		  AND status = 'available'
+		  AND NOT price_overridden
+		  AND seat_price <> $2`, tripID, price)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reprice trip seats: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
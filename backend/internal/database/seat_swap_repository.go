@@ -0,0 +1,157 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// ErrSeatSwapSeatUnavailable is returned when the seat a passenger is being moved to is not
+// free on their trip
+var ErrSeatSwapSeatUnavailable = errors.New("target seat is not available on this trip")
+
+// SeatSwapRepository moves booked passengers between seats of a trip
+type SeatSwapRepository struct {
+	db *sqlx.DB
+}
+
+// NewSeatSwapRepository creates a new SeatSwapRepository
+func NewSeatSwapRepository(db *sqlx.DB) *SeatSwapRepository {
+	return &SeatSwapRepository{db: db}
+}
+
+// GetTarget returns a passenger's seat booking with the trip's driver, conductor and bus owner;
+// returns nil if the seat booking does not exist
+func (r *SeatSwapRepository) GetTarget(busBookingSeatID string) (*models.SeatSwapTarget, error) {
+	var target models.SeatSwapTarget
+	err := r.db.Get(&target, `
+		SELECT bbs.id AS bus_booking_seat_id, bbs.bus_booking_id, bb.scheduled_trip_id,
+		       bbs.trip_seat_id, tseat.seat_number, bbs.status,
+		       drv.user_id AS driver_user_id, cond.user_id AS conductor_user_id, bo.user_id AS owner_user_id
+		FROM bus_booking_seats bbs
+		JOIN bus_bookings bb ON bbs.bus_booking_id = bb.id
+		JOIN scheduled_trips st ON bb.scheduled_trip_id = st.id
+		LEFT JOIN trip_seats tseat ON tseat.id = bbs.trip_seat_id
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN bus_owners bo ON bo.id = COALESCE(ts.bus_owner_id, bor.bus_owner_id)
+		LEFT JOIN bus_staff drv ON drv.id = st.assigned_driver_id
+		LEFT JOIN bus_staff cond ON cond.id = st.assigned_conductor_id
+		WHERE bbs.id = $1`, busBookingSeatID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat booking: %w", err)
+	}
+	return &target, nil
+}
+
+// Swap moves a seat booking to another seat of its trip in one transaction: the target seat
+// is locked and must be available, it takes over the booking, the old seat is freed, the seat
+// booking points at its new seat and the swap is written to the audit log with its note.
+// Returns ErrSeatSwapSeatUnavailable if the target seat is taken, held by a live booking intent,
+// blocked or on another trip.
+// The seat price is not changed.
+func (r *SeatSwapRepository) Swap(swap *models.SeatSwap, userID, ipAddress, userAgent string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin seat swap: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Re-read the passenger's seat under lock so concurrent swaps of them queue up
+	var current struct {
+		TripSeatID *string `db:"trip_seat_id"`
+		SeatNumber *string `db:"seat_number"`
+	}
+	if err := tx.Get(&current, `
+		SELECT bbs.trip_seat_id, ts.seat_number
+		FROM bus_booking_seats bbs
+		LEFT JOIN trip_seats ts ON ts.id = bbs.trip_seat_id
+		WHERE bbs.id = $1
+		FOR UPDATE OF bbs`, swap.BusBookingSeatID); err != nil {
+		return fmt.Errorf("failed to lock seat booking: %w", err)
+	}
+	swap.FromTripSeatID, swap.FromSeatNumber = current.TripSeatID, current.SeatNumber
+
+	// A seat held by a live booking intent is as good as taken: that passenger is paying for it
+	err = tx.Get(&swap.ToSeatNumber, `
+		SELECT seat_number FROM trip_seats
+		WHERE id = $1 AND scheduled_trip_id = $2 AND status = 'available'
+		  AND (held_by_intent_id IS NULL OR held_until < NOW())
+		FOR UPDATE`, swap.ToTripSeatID, swap.ScheduledTripID)
+	if err == sql.ErrNoRows {
+		return ErrSeatSwapSeatUnavailable
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock target seat: %w", err)
+	}
+
+	if swap.FromTripSeatID != nil {
+		if _, err := tx.Exec(`
+			UPDATE trip_seats
+			SET status = 'available',
+			    booking_type = NULL,
+			    bus_booking_seat_id = NULL,
+			    updated_at = NOW()
+			WHERE id = $1 AND bus_booking_seat_id = $2`,
+			*swap.FromTripSeatID, swap.BusBookingSeatID); err != nil {
+			return fmt.Errorf("failed to release old seat: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE trip_seats
+		SET status = 'booked',
+		    booking_type = 'app',
+		    bus_booking_seat_id = $1,
+		    held_by_intent_id = NULL,
+		    held_until = NULL,
+		    updated_at = NOW()
+		WHERE id = $2`,
+		swap.BusBookingSeatID, swap.ToTripSeatID); err != nil {
+		return fmt.Errorf("failed to assign new seat: %w", err)
+	}
+
+	if err := tx.QueryRow(`
+		UPDATE bus_booking_seats
+		SET trip_seat_id = $1,
+		    updated_at = NOW()
+		WHERE id = $2
+		RETURNING updated_at`,
+		swap.ToTripSeatID, swap.BusBookingSeatID).Scan(&swap.SwappedAt); err != nil {
+		return fmt.Errorf("failed to update seat booking: %w", err)
+	}
+
+	details, err := json.Marshal(map[string]interface{}{
+		"scheduled_trip_id": swap.ScheduledTripID,
+		"bus_booking_id":    swap.BusBookingID,
+		"from_trip_seat_id": swap.FromTripSeatID,
+		"from_seat_number":  swap.FromSeatNumber,
+		"to_trip_seat_id":   swap.ToTripSeatID,
+		"to_seat_number":    swap.ToSeatNumber,
+		"note":              swap.Note,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal seat swap audit details: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO audit_logs (user_id, action, entity_type, entity_id, ip_address, user_agent, details, created_at)
+		VALUES ($1, 'seat_swap', 'bus_booking_seat', $2, $3, $4, $5, NOW())`,
+		userID, swap.BusBookingSeatID, ipAddress, userAgent, string(details)); err != nil {
+		return fmt.Errorf("failed to audit seat swap: %w", err)
+	}
+
+	if err := syncTripSeatCounters(tx, swap.ScheduledTripID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit seat swap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeatSwap_TargetSeatHeldByIntentIsUnavailable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSeatSwapRepository(sqlx.NewDb(db, "sqlmock"))
+	swap := &models.SeatSwap{
+		BusBookingSeatID: "bbs-1",
+		BusBookingID:     "bb-1",
+		ScheduledTripID:  "trip-1",
+		ToTripSeatID:     "seat-12",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT bbs.trip_seat_id, ts.seat_number").WithArgs("bbs-1").
+		WillReturnRows(sqlmock.NewRows([]string{"trip_seat_id", "seat_number"}).AddRow("seat-4", "4"))
+	// Seat 12 is 'available' but held by another passenger's live intent, so the lock finds nothing
+	mock.ExpectQuery(`SELECT seat_number FROM trip_seats\s+WHERE id = \$1 AND scheduled_trip_id = \$2 AND status = 'available'\s+AND \(held_by_intent_id IS NULL OR held_until < NOW\(\)\)`).
+		WithArgs("seat-12", "trip-1").
+		WillReturnRows(sqlmock.NewRows([]string{"seat_number"}))
+	mock.ExpectRollback()
+
+	err = repo.Swap(swap, "conductor-1", "127.0.0.1", "test")
+	assert.ErrorIs(t, err, ErrSeatSwapSeatUnavailable)
+	assert.NoError(t, mock.ExpectationsWereMet(), "the seats must not be touched")
+}
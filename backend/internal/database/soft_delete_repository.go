@@ -0,0 +1,190 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// ErrDeletedEntityNotFound is returned when restoring something that is not soft-deleted
+var ErrDeletedEntityNotFound = errors.New("deleted entity not found")
+
+// UpcomingTripsError is returned when deleting a bus, permit or route that future published
+// trips still use. Those trips need unpublishing or cancelling first.
+type UpcomingTripsError struct {
+	Count int
+}
+
+func (e *UpcomingTripsError) Error() string {
+	return fmt.Sprintf("%d upcoming published trips still use it", e.Count)
+}
+
+// RestoreConflictError is returned when a restored entity would clash with one created since
+type RestoreConflictError struct {
+	Reason string
+}
+
+func (e *RestoreConflictError) Error() string {
+	return e.Reason
+}
+
+// softDeleteTables maps each entity type to its table and the column shown as its name
+var softDeleteTables = map[models.DeletedEntityType]struct{ table, name string }{
+	models.DeletedEntityBus:    {"buses", "license_plate"},
+	models.DeletedEntityPermit: {"route_permits", "permit_number"},
+	models.DeletedEntityRoute:  {"bus_owner_routes", "custom_route_name"},
+}
+
+// upcomingTripConditions match the scheduled trips that use each entity type. Trips without
+// their own route take the schedule's.
+var upcomingTripConditions = map[models.DeletedEntityType]string{
+	models.DeletedEntityBus:    "st.bus_id = $1",
+	models.DeletedEntityPermit: "st.permit_id = $1",
+	models.DeletedEntityRoute:  "COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id) = $1",
+}
+
+// countUpcomingPublishedTrips counts the future published trips that are not cancelled or done
+func countUpcomingPublishedTrips(db DB, entityType models.DeletedEntityType, id string) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM scheduled_trips st
+		LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+		WHERE %s
+		  AND st.is_bookable = true
+		  AND st.departure_datetime > NOW()
+		  AND st.status NOT IN ('cancelled', 'completed')`, upcomingTripConditions[entityType])
+
+	var count int
+	if err := db.QueryRow(query, id).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count upcoming trips: %w", err)
+	}
+	return count, nil
+}
+
+// softDelete marks an owner's entity deleted unless upcoming published trips use it. Returns
+// sql.ErrNoRows if the owner has no such entity or it is already deleted.
+func softDelete(db DB, entityType models.DeletedEntityType, id, busOwnerID string) error {
+	count, err := countUpcomingPublishedTrips(db, entityType, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return &UpcomingTripsError{Count: count}
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND bus_owner_id = $2 AND deleted_at IS NULL`, softDeleteTables[entityType].table)
+	result, err := db.Exec(query, id, busOwnerID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SoftDeleteRepository lists and restores soft-deleted buses, permits and routes
+type SoftDeleteRepository struct {
+	db DB
+}
+
+// NewSoftDeleteRepository creates a new SoftDeleteRepository
+func NewSoftDeleteRepository(db DB) *SoftDeleteRepository {
+	return &SoftDeleteRepository{db: db}
+}
+
+// ListDeleted returns soft-deleted entities of a type, most recently deleted first, optionally
+// for one bus owner
+func (r *SoftDeleteRepository) ListDeleted(entityType models.DeletedEntityType, busOwnerID string, limit, offset int) ([]models.DeletedEntity, error) {
+	t := softDeleteTables[entityType]
+	query := fmt.Sprintf(`
+		SELECT $1::text AS type, id, bus_owner_id, %s AS name, deleted_at
+		FROM %s
+		WHERE deleted_at IS NOT NULL AND ($2 = '' OR bus_owner_id::text = $2)
+		ORDER BY deleted_at DESC
+		LIMIT $3 OFFSET $4`, t.name, t.table)
+
+	entities := []models.DeletedEntity{}
+	if err := r.db.Select(&entities, query, entityType, busOwnerID, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to list deleted %ss: %w", entityType, err)
+	}
+	return entities, nil
+}
+
+// Restore clears an entity's deletion. A bus cannot come back onto a permit another bus now
+// holds, nor a permit whose number the owner has reused.
+func (r *SoftDeleteRepository) Restore(entityType models.DeletedEntityType, id string) (*models.DeletedEntity, error) {
+	t := softDeleteTables[entityType]
+	var entity models.DeletedEntity
+	err := r.db.Get(&entity, fmt.Sprintf(`
+		SELECT $1::text AS type, id, bus_owner_id, %s AS name, deleted_at
+		FROM %s
+		WHERE id = $2 AND deleted_at IS NOT NULL`, t.name, t.table), entityType, id)
+	if err == sql.ErrNoRows {
+		return nil, ErrDeletedEntityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted %s: %w", entityType, err)
+	}
+
+	if reason, err := r.restoreConflict(entityType, id); err != nil {
+		return nil, err
+	} else if reason != "" {
+		return nil, &RestoreConflictError{Reason: reason}
+	}
+
+	result, err := r.db.Exec(fmt.Sprintf(`
+		UPDATE %s SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL`, t.table), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore %s: %w", entityType, err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return nil, ErrDeletedEntityNotFound
+	}
+	return &entity, nil
+}
+
+// restoreConflict explains why an entity cannot be restored, or returns ""
+func (r *SoftDeleteRepository) restoreConflict(entityType models.DeletedEntityType, id string) (string, error) {
+	var query, reason string
+	switch entityType {
+	case models.DeletedEntityBus:
+		query = `
+			SELECT EXISTS (
+				SELECT 1 FROM buses b
+				JOIN buses other ON other.id <> b.id AND other.deleted_at IS NULL
+				 AND (other.permit_id = b.permit_id OR other.license_plate = b.license_plate)
+				WHERE b.id = $1
+			)`
+		reason = "another bus now has this bus's permit or license plate"
+	case models.DeletedEntityPermit:
+		query = `
+			SELECT EXISTS (
+				SELECT 1 FROM route_permits p
+				JOIN route_permits other ON other.id <> p.id AND other.deleted_at IS NULL
+				 AND other.bus_owner_id = p.bus_owner_id AND other.permit_number = p.permit_number
+				WHERE p.id = $1
+			)`
+		reason = "the bus owner has another permit with this permit number"
+	default:
+		return "", nil
+	}
+
+	var conflict bool
+	if err := r.db.QueryRow(query, id).Scan(&conflict); err != nil {
+		return "", fmt.Errorf("failed to check %s restore: %w", entityType, err)
+	}
+	if conflict {
+		return reason, nil
+	}
+	return "", nil
+}
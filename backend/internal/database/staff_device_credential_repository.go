@@ -0,0 +1,169 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// StaffDeviceCredentialRepository handles staff device credential and challenge database operations
+type StaffDeviceCredentialRepository struct {
+	db *sqlx.DB
+}
+
+// NewStaffDeviceCredentialRepository creates a new StaffDeviceCredentialRepository
+func NewStaffDeviceCredentialRepository(db *sqlx.DB) *StaffDeviceCredentialRepository {
+	return &StaffDeviceCredentialRepository{db: db}
+}
+
+const staffDeviceCredentialColumns = `
+	id, user_id, staff_id, device_id, device_name, public_key, key_algorithm,
+	expires_at, last_used_at, revoked_at, revoked_by, revoke_reason, created_at`
+
+// Create stores a new credential. Any active credential for the same staff member
+// and device is revoked first, so re-registering a device replaces its key.
+func (r *StaffDeviceCredentialRepository) Create(credential *models.StaffDeviceCredential) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE staff_device_credentials
+		SET revoked_at = NOW(), revoked_by = $3, revoke_reason = 'replaced'
+		WHERE staff_id = $1 AND device_id = $2 AND revoked_at IS NULL`,
+		credential.StaffID, credential.DeviceID, credential.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke previous device credential: %w", err)
+	}
+
+	credential.ID = uuid.New().String()
+	err = tx.QueryRow(`
+		INSERT INTO staff_device_credentials (
+			id, user_id, staff_id, device_id, device_name, public_key, key_algorithm, expires_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at`,
+		credential.ID, credential.UserID, credential.StaffID, credential.DeviceID,
+		credential.DeviceName, credential.PublicKey, credential.KeyAlgorithm, credential.ExpiresAt,
+	).Scan(&credential.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create device credential: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves a credential by ID; returns nil if not found
+func (r *StaffDeviceCredentialRepository) GetByID(id string) (*models.StaffDeviceCredential, error) {
+	var credential models.StaffDeviceCredential
+	err := r.db.Get(&credential, `SELECT `+staffDeviceCredentialColumns+` FROM staff_device_credentials WHERE id = $1`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get device credential: %w", err)
+	}
+	return &credential, nil
+}
+
+// ListByStaffID returns all credentials for a staff member, newest first
+func (r *StaffDeviceCredentialRepository) ListByStaffID(staffID string) ([]models.StaffDeviceCredential, error) {
+	credentials := []models.StaffDeviceCredential{}
+	err := r.db.Select(&credentials, `
+		SELECT `+staffDeviceCredentialColumns+`
+		FROM staff_device_credentials
+		WHERE staff_id = $1
+		ORDER BY created_at DESC`, staffID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+// CountActiveByStaffID counts unrevoked, unexpired credentials for a staff member
+func (r *StaffDeviceCredentialRepository) CountActiveByStaffID(staffID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `
+		SELECT COUNT(*) FROM staff_device_credentials
+		WHERE staff_id = $1 AND revoked_at IS NULL AND expires_at > NOW()`, staffID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count device credentials: %w", err)
+	}
+	return count, nil
+}
+
+// MarkUsed records a successful device login
+func (r *StaffDeviceCredentialRepository) MarkUsed(id string) error {
+	_, err := r.db.Exec(`UPDATE staff_device_credentials SET last_used_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to update device credential: %w", err)
+	}
+	return nil
+}
+
+// Revoke revokes a single credential. Returns false if it was not found or already revoked.
+func (r *StaffDeviceCredentialRepository) Revoke(id, revokedBy, reason string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE staff_device_credentials
+		SET revoked_at = NOW(), revoked_by = $2, revoke_reason = NULLIF($3, '')
+		WHERE id = $1 AND revoked_at IS NULL`, id, revokedBy, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke device credential: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// RevokeAllForStaff revokes every active credential of a staff member
+func (r *StaffDeviceCredentialRepository) RevokeAllForStaff(staffID, revokedBy, reason string) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE staff_device_credentials
+		SET revoked_at = NOW(), revoked_by = $2, revoke_reason = NULLIF($3, '')
+		WHERE staff_id = $1 AND revoked_at IS NULL`, staffID, revokedBy, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke device credentials: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CreateChallenge stores a new single-use login challenge
+func (r *StaffDeviceCredentialRepository) CreateChallenge(challenge *models.StaffDeviceChallenge) error {
+	challenge.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO staff_device_challenges (id, credential_id, nonce, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING created_at`,
+		challenge.ID, challenge.CredentialID, challenge.Nonce, challenge.ExpiresAt,
+	).Scan(&challenge.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create device challenge: %w", err)
+	}
+	return nil
+}
+
+// ConsumeChallenge atomically marks an unexpired, unused challenge as used and returns it.
+// Returns nil if the challenge does not exist, belongs to another credential, expired or was already used.
+func (r *StaffDeviceCredentialRepository) ConsumeChallenge(challengeID, credentialID string) (*models.StaffDeviceChallenge, error) {
+	var challenge models.StaffDeviceChallenge
+	err := r.db.Get(&challenge, `
+		UPDATE staff_device_challenges
+		SET used_at = NOW()
+		WHERE id = $1 AND credential_id = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING id, credential_id, nonce, expires_at, used_at, created_at`,
+		challengeID, credentialID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume device challenge: %w", err)
+	}
+	return &challenge, nil
+}
@@ -0,0 +1,211 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
+)
+
+const staffDocumentRenewalColumns = `
+	r.id, r.staff_id, r.document_type, r.document_number, r.expiry_date, r.file_url, r.status,
+	r.reviewed_by, r.reviewed_at, r.rejection_reason, r.created_at, r.updated_at`
+
+// pendingStaffDocumentRenewalQuery selects pending renewals with the staff member, their current
+// employer and the expiry of the document being replaced. Conditions are spliced in at %s.
+const pendingStaffDocumentRenewalQuery = `
+	SELECT ` + staffDocumentRenewalColumns + `,
+	       bs.first_name, bs.last_name, bs.staff_type,
+	       e.bus_owner_id,
+	       CASE WHEN r.document_type IN ('driving_license', 'ntc_license') THEN bs.license_expiry_date
+	            ELSE (SELECT prev.expiry_date FROM staff_document_renewals prev
+	                  WHERE prev.staff_id = r.staff_id AND prev.document_type = r.document_type
+	                    AND prev.status = 'approved'
+	                  ORDER BY prev.created_at DESC LIMIT 1)
+	       END AS current_expiry_date
+	FROM staff_document_renewals r
+	JOIN bus_staff bs ON bs.id = r.staff_id
+	LEFT JOIN bus_staff_employment e ON e.staff_id = r.staff_id AND e.is_current = true
+	WHERE r.status = 'pending'
+	  %s`
+
+// StaffDocumentRenewalRepository stores documents staff upload for review
+type StaffDocumentRenewalRepository struct {
+	db  *sqlx.DB
+	pii *fieldcrypt.Cipher // Encrypts the license copied onto the staff profile; nil stores it in plaintext
+}
+
+// NewStaffDocumentRenewalRepository creates a new StaffDocumentRenewalRepository
+func NewStaffDocumentRenewalRepository(db *sqlx.DB, pii *fieldcrypt.Cipher) *StaffDocumentRenewalRepository {
+	return &StaffDocumentRenewalRepository{db: db, pii: pii}
+}
+
+// Create stores a pending upload, superseding any earlier pending upload of the same document
+func (r *StaffDocumentRenewalRepository) Create(renewal *models.StaffDocumentRenewal) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE staff_document_renewals
+		SET status = 'superseded', updated_at = NOW()
+		WHERE staff_id = $1 AND document_type = $2 AND status = 'pending'`,
+		renewal.StaffID, renewal.DocumentType)
+	if err != nil {
+		return fmt.Errorf("failed to supersede pending document: %w", err)
+	}
+
+	err = tx.QueryRowx(`
+		INSERT INTO staff_document_renewals (
+			staff_id, document_type, document_number, expiry_date, file_url, status
+		) VALUES ($1, $2, $3, $4, $5, 'pending')
+		RETURNING id, status, created_at, updated_at`,
+		renewal.StaffID, renewal.DocumentType, renewal.DocumentNumber, renewal.ExpiryDate, renewal.FileURL,
+	).Scan(&renewal.ID, &renewal.Status, &renewal.CreatedAt, &renewal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create document renewal: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetByID returns an upload, or nil if it doesn't exist
+func (r *StaffDocumentRenewalRepository) GetByID(id string) (*models.PendingStaffDocumentRenewal, error) {
+	query := `
+		SELECT ` + staffDocumentRenewalColumns + `,
+		       bs.first_name, bs.last_name, bs.staff_type, e.bus_owner_id
+		FROM staff_document_renewals r
+		JOIN bus_staff bs ON bs.id = r.staff_id
+		LEFT JOIN bus_staff_employment e ON e.staff_id = r.staff_id AND e.is_current = true
+		WHERE r.id = $1`
+
+	var renewal models.PendingStaffDocumentRenewal
+	if err := r.db.Get(&renewal, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get document renewal: %w", err)
+	}
+	return &renewal, nil
+}
+
+// ListByStaff returns a staff member's uploads, newest first
+func (r *StaffDocumentRenewalRepository) ListByStaff(staffID string) ([]models.StaffDocumentRenewal, error) {
+	renewals := []models.StaffDocumentRenewal{}
+	err := r.db.Select(&renewals, `
+		SELECT `+staffDocumentRenewalColumns+`
+		FROM staff_document_renewals r
+		WHERE r.staff_id = $1 AND r.status != 'superseded'
+		ORDER BY r.created_at DESC`, staffID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document renewals: %w", err)
+	}
+	return renewals, nil
+}
+
+// ListPendingForBusOwner returns pending uploads of the bus owner's current staff, oldest first
+func (r *StaffDocumentRenewalRepository) ListPendingForBusOwner(busOwnerID string) ([]models.PendingStaffDocumentRenewal, error) {
+	renewals := []models.PendingStaffDocumentRenewal{}
+	query := fmt.Sprintf(pendingStaffDocumentRenewalQuery, `AND e.bus_owner_id = $1`)
+	if err := r.db.Select(&renewals, query+` ORDER BY r.created_at`, busOwnerID); err != nil {
+		return nil, fmt.Errorf("failed to list pending document renewals: %w", err)
+	}
+	return renewals, nil
+}
+
+// ListPending returns all pending uploads, oldest first, with the total count
+func (r *StaffDocumentRenewalRepository) ListPending(limit, offset int) ([]models.PendingStaffDocumentRenewal, int, error) {
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM staff_document_renewals WHERE status = 'pending'`); err != nil {
+		return nil, 0, fmt.Errorf("failed to count pending document renewals: %w", err)
+	}
+
+	renewals := []models.PendingStaffDocumentRenewal{}
+	query := fmt.Sprintf(pendingStaffDocumentRenewalQuery, "")
+	if err := r.db.Select(&renewals, query+` ORDER BY r.created_at LIMIT $1 OFFSET $2`, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list pending document renewals: %w", err)
+	}
+	return renewals, total, nil
+}
+
+// Approve accepts a pending upload and supersedes the earlier approved copy of the document.
+// A license renewal also replaces the license on the staff profile. Returns false if the upload
+// is no longer pending.
+func (r *StaffDocumentRenewalRepository) Approve(renewal *models.StaffDocumentRenewal, reviewerID string) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var reviewedAt time.Time
+	err = tx.QueryRowx(`
+		UPDATE staff_document_renewals
+		SET status = 'approved', reviewed_by = $2, reviewed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING reviewed_at`, renewal.ID, reviewerID).Scan(&reviewedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to approve document renewal: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE staff_document_renewals
+		SET status = 'superseded', updated_at = NOW()
+		WHERE staff_id = $1 AND document_type = $2 AND status = 'approved' AND id != $3`,
+		renewal.StaffID, renewal.DocumentType, renewal.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to supersede approved document: %w", err)
+	}
+
+	if renewal.DocumentType == models.StaffDocDrivingLicense || renewal.DocumentType == models.StaffDocNTCLicense {
+		licenseNumber, err := encryptPIIPtr(r.pii, renewal.DocumentNumber)
+		if err != nil {
+			return false, err
+		}
+		_, err = tx.Exec(`
+			UPDATE bus_staff
+			SET license_number = $2, license_expiry_date = $3, updated_at = NOW()
+			WHERE id = $1`, renewal.StaffID, licenseNumber, renewal.ExpiryDate)
+		if err != nil {
+			return false, fmt.Errorf("failed to update staff license: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit document approval: %w", err)
+	}
+	renewal.Status = models.StaffDocumentRenewalApproved
+	renewal.ReviewedBy = &reviewerID
+	renewal.ReviewedAt = &reviewedAt
+	return true, nil
+}
+
+// Reject turns down a pending upload with the reviewer's reason. Returns false if the upload is
+// no longer pending.
+func (r *StaffDocumentRenewalRepository) Reject(renewal *models.StaffDocumentRenewal, reviewerID, reason string) (bool, error) {
+	var reviewedAt time.Time
+	err := r.db.QueryRowx(`
+		UPDATE staff_document_renewals
+		SET status = 'rejected', reviewed_by = $2, reviewed_at = NOW(), rejection_reason = $3, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING reviewed_at`, renewal.ID, reviewerID, reason).Scan(&reviewedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to reject document renewal: %w", err)
+	}
+	renewal.Status = models.StaffDocumentRenewalRejected
+	renewal.ReviewedBy = &reviewerID
+	renewal.ReviewedAt = &reviewedAt
+	renewal.RejectionReason = &reason
+	return true, nil
+}
@@ -0,0 +1,341 @@
+package database
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	// ErrStandingSoldOut is returned when a trip has no standing places left
+	ErrStandingSoldOut = errors.New("no standing places left on this trip")
+	// ErrStandingAlreadyQueued is returned when a passenger already holds a standing place on the trip
+	ErrStandingAlreadyQueued = errors.New("you already have a standing ticket for this trip")
+	// ErrStandingBusFull is returned when boarding another standing passenger would exceed the
+	// bus's licensed standing capacity
+	ErrStandingBusFull = errors.New("the bus has reached its licensed standing capacity")
+	// ErrStandingTicketNotWaiting is returned when a ticket has already boarded, been skipped or cancelled
+	ErrStandingTicketNotWaiting = errors.New("standing ticket is no longer waiting to board")
+)
+
+// StandingQueueOrderError is returned when a standing passenger tries to board ahead of the queue
+type StandingQueueOrderError struct {
+	QueuePosition int
+	NextPosition  int
+	Ahead         int
+}
+
+func (e *StandingQueueOrderError) Error() string {
+	return fmt.Sprintf("standing ticket #%d is not next to board: %d passengers ahead, #%d is next", e.QueuePosition, e.Ahead, e.NextPosition)
+}
+
+// StandingTicketRepository handles trips' standing settings and their queue of standing tickets
+type StandingTicketRepository struct {
+	db *sqlx.DB
+}
+
+// NewStandingTicketRepository creates a new StandingTicketRepository
+func NewStandingTicketRepository(db *sqlx.DB) *StandingTicketRepository {
+	return &StandingTicketRepository{db: db}
+}
+
+const standingTripQuery = `
+	SELECT st.id AS scheduled_trip_id, st.status, st.is_bookable, st.departure_datetime, st.base_fare,
+	       COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS bus_owner_id,
+	       b.id AS bus_id, b.license_plate AS bus_license_plate, b.standing_capacity AS bus_standing_capacity,
+	       ss.cap, ss.fare, ss.updated_at AS settings_updated_at,
+	       drv.user_id AS driver_user_id, cond.user_id AS conductor_user_id, bo.user_id AS owner_user_id,
+	       COUNT(t.id) FILTER (WHERE t.status IN ('waiting', 'boarded')) AS places_held,
+	       COUNT(t.id) FILTER (WHERE t.status = 'boarded') AS boarded,
+	       COUNT(t.id) FILTER (WHERE t.status = 'waiting') AS waiting
+	FROM scheduled_trips st
+	LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+	LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+	LEFT JOIN bus_owners bo ON bo.id = COALESCE(ts.bus_owner_id, bor.bus_owner_id)
+	LEFT JOIN buses b ON b.id = st.bus_id
+	LEFT JOIN trip_standing_settings ss ON ss.scheduled_trip_id = st.id
+	LEFT JOIN bus_staff drv ON drv.id = st.assigned_driver_id
+	LEFT JOIN bus_staff cond ON cond.id = st.assigned_conductor_id
+	LEFT JOIN standing_tickets t ON t.scheduled_trip_id = st.id
+	WHERE st.id = $1
+	GROUP BY st.id, ts.bus_owner_id, bor.bus_owner_id, b.id, ss.scheduled_trip_id, drv.user_id, cond.user_id, bo.user_id`
+
+const standingTicketColumns = `
+	t.id, t.scheduled_trip_id, t.queue_position, t.boarding_token, t.source, t.passenger_user_id, t.passenger_name,
+	t.fare, t.payment_status, t.status, t.issued_by_user_id, t.boarded_by_user_id, t.boarded_at, t.skipped_at,
+	t.cancelled_at, t.created_at, st.departure_datetime`
+
+// GetTrip returns a trip with its standing settings, bus capacity, crew and counts; returns nil
+// if the trip does not exist
+func (r *StandingTicketRepository) GetTrip(scheduledTripID string) (*models.StandingTrip, error) {
+	return getStandingTrip(r.db, scheduledTripID)
+}
+
+func getStandingTrip(q sqlx.Queryer, scheduledTripID string) (*models.StandingTrip, error) {
+	var trip models.StandingTrip
+	if err := sqlx.Get(q, &trip, standingTripQuery, scheduledTripID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get trip standing details: %w", err)
+	}
+	return &trip, nil
+}
+
+// UpsertSettings saves a trip's standing cap and fare
+func (r *StandingTicketRepository) UpsertSettings(settings *models.TripStandingSettings) error {
+	err := r.db.QueryRow(`
+		INSERT INTO trip_standing_settings (scheduled_trip_id, cap, fare, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (scheduled_trip_id) DO UPDATE
+		SET cap = EXCLUDED.cap, fare = EXCLUDED.fare, updated_at = NOW()
+		RETURNING updated_at`,
+		settings.ScheduledTripID, settings.Cap, settings.Fare,
+	).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save standing settings: %w", err)
+	}
+	return nil
+}
+
+// Issue adds a standing ticket to the back of the trip's queue. The trip is locked while the
+// remaining places are counted, so concurrent sales cannot oversell it. Returns
+// ErrStandingSoldOut if it is full, or ErrStandingAlreadyQueued if the passenger already holds
+// a place.
+func (r *StandingTicketRepository) Issue(ticket *models.StandingTicket) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM scheduled_trips WHERE id = $1 FOR UPDATE`, ticket.ScheduledTripID); err != nil {
+		return fmt.Errorf("failed to lock trip: %w", err)
+	}
+	trip, err := getStandingTrip(tx, ticket.ScheduledTripID)
+	if err != nil {
+		return err
+	}
+	if trip == nil || trip.Remaining() == 0 {
+		return ErrStandingSoldOut
+	}
+
+	if ticket.PassengerUserID != nil {
+		var held int
+		err := tx.Get(&held, `
+			SELECT COUNT(*) FROM standing_tickets
+			WHERE scheduled_trip_id = $1 AND passenger_user_id = $2 AND status IN ('waiting', 'boarded')`,
+			ticket.ScheduledTripID, *ticket.PassengerUserID)
+		if err != nil {
+			return fmt.Errorf("failed to check existing standing tickets: %w", err)
+		}
+		if held > 0 {
+			return ErrStandingAlreadyQueued
+		}
+	}
+
+	if err := tx.Get(&ticket.QueuePosition, `
+		SELECT COALESCE(MAX(queue_position), 0) + 1 FROM standing_tickets WHERE scheduled_trip_id = $1`,
+		ticket.ScheduledTripID); err != nil {
+		return fmt.Errorf("failed to get queue position: %w", err)
+	}
+	token, err := generateBoardingToken(ticket.QueuePosition)
+	if err != nil {
+		return err
+	}
+
+	ticket.ID = uuid.New().String()
+	ticket.BoardingToken = token
+	ticket.Status = models.StandingTicketWaiting
+	ticket.DepartureDatetime = trip.DepartureDatetime
+	err = tx.QueryRow(`
+		INSERT INTO standing_tickets (
+			id, scheduled_trip_id, queue_position, boarding_token, source, passenger_user_id, passenger_name,
+			fare, payment_status, status, issued_by_user_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+		RETURNING created_at`,
+		ticket.ID, ticket.ScheduledTripID, ticket.QueuePosition, ticket.BoardingToken, ticket.Source,
+		ticket.PassengerUserID, ticket.PassengerName, ticket.Fare, ticket.PaymentStatus, ticket.Status,
+		ticket.IssuedByUserID,
+	).Scan(&ticket.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create standing ticket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit standing ticket: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a standing ticket; returns nil if it does not exist
+func (r *StandingTicketRepository) GetByID(ticketID string) (*models.StandingTicket, error) {
+	return r.getOne(`t.id = $1`, ticketID)
+}
+
+// GetByToken returns the standing ticket with a boarding token; returns nil if there is none
+func (r *StandingTicketRepository) GetByToken(token string) (*models.StandingTicket, error) {
+	return r.getOne(`t.boarding_token = $1`, strings.ToUpper(strings.TrimSpace(token)))
+}
+
+func (r *StandingTicketRepository) getOne(where string, arg interface{}) (*models.StandingTicket, error) {
+	var ticket models.StandingTicket
+	err := r.db.Get(&ticket, `
+		SELECT `+standingTicketColumns+`
+		FROM standing_tickets t
+		JOIN scheduled_trips st ON st.id = t.scheduled_trip_id
+		WHERE `+where, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get standing ticket: %w", err)
+	}
+	return &ticket, nil
+}
+
+// ListPassengerTickets returns a passenger's standing tickets for trips that have not departed
+// more than a day ago, soonest departure first
+func (r *StandingTicketRepository) ListPassengerTickets(userID string) ([]models.StandingTicket, error) {
+	tickets := []models.StandingTicket{}
+	err := r.db.Select(&tickets, `
+		SELECT `+standingTicketColumns+`
+		FROM standing_tickets t
+		JOIN scheduled_trips st ON st.id = t.scheduled_trip_id
+		WHERE t.passenger_user_id = $1 AND st.departure_datetime > NOW() - INTERVAL '1 day'
+		ORDER BY st.departure_datetime, t.queue_position`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list standing tickets: %w", err)
+	}
+	return tickets, nil
+}
+
+// ListWaiting returns a trip's standing tickets still waiting to board, in queue order
+func (r *StandingTicketRepository) ListWaiting(scheduledTripID string) ([]models.StandingTicket, error) {
+	tickets := []models.StandingTicket{}
+	err := r.db.Select(&tickets, `
+		SELECT `+standingTicketColumns+`
+		FROM standing_tickets t
+		JOIN scheduled_trips st ON st.id = t.scheduled_trip_id
+		WHERE t.scheduled_trip_id = $1 AND t.status = 'waiting'
+		ORDER BY t.queue_position`, scheduledTripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list standing queue: %w", err)
+	}
+	return tickets, nil
+}
+
+// CountAhead returns how many standing passengers are still waiting ahead of a ticket
+func (r *StandingTicketRepository) CountAhead(ticket *models.StandingTicket) (int, error) {
+	var ahead int
+	err := r.db.Get(&ahead, `
+		SELECT COUNT(*) FROM standing_tickets
+		WHERE scheduled_trip_id = $1 AND status = 'waiting' AND queue_position < $2`,
+		ticket.ScheduledTripID, ticket.QueuePosition)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count standing passengers ahead: %w", err)
+	}
+	return ahead, nil
+}
+
+// Board marks a waiting standing ticket as boarded and paid. The trip is locked so boarding
+// follows the queue: a *StandingQueueOrderError is returned while earlier tickets are still
+// waiting, and ErrStandingBusFull once the bus's licensed standing capacity is on board.
+func (r *StandingTicketRepository) Board(ticket *models.StandingTicket, userID string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM scheduled_trips WHERE id = $1 FOR UPDATE`, ticket.ScheduledTripID); err != nil {
+		return fmt.Errorf("failed to lock trip: %w", err)
+	}
+
+	var status models.StandingTicketStatus
+	if err := tx.Get(&status, `SELECT status FROM standing_tickets WHERE id = $1`, ticket.ID); err != nil {
+		return fmt.Errorf("failed to get standing ticket: %w", err)
+	}
+	if status != models.StandingTicketWaiting {
+		return ErrStandingTicketNotWaiting
+	}
+
+	var ahead struct {
+		Count int           `db:"count"`
+		Next  sql.NullInt64 `db:"next"`
+	}
+	err = tx.Get(&ahead, `
+		SELECT COUNT(*) AS count, MIN(queue_position) AS next FROM standing_tickets
+		WHERE scheduled_trip_id = $1 AND status = 'waiting' AND queue_position < $2`,
+		ticket.ScheduledTripID, ticket.QueuePosition)
+	if err != nil {
+		return fmt.Errorf("failed to check standing queue: %w", err)
+	}
+	if ahead.Count > 0 {
+		return &StandingQueueOrderError{QueuePosition: ticket.QueuePosition, NextPosition: int(ahead.Next.Int64), Ahead: ahead.Count}
+	}
+
+	trip, err := getStandingTrip(tx, ticket.ScheduledTripID)
+	if err != nil {
+		return err
+	}
+	if trip == nil || trip.Boarded >= trip.LicensedCapacity() {
+		return ErrStandingBusFull
+	}
+
+	err = tx.QueryRow(`
+		UPDATE standing_tickets
+		SET status = 'boarded', payment_status = 'paid', boarded_by_user_id = $2, boarded_at = NOW()
+		WHERE id = $1
+		RETURNING boarded_at`, ticket.ID, userID,
+	).Scan(&ticket.BoardedAt)
+	if err != nil {
+		return fmt.Errorf("failed to board standing ticket: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit boarding: %w", err)
+	}
+	ticket.Status = models.StandingTicketBoarded
+	ticket.PaymentStatus = models.StandingPaymentPaid
+	ticket.BoardedByUserID = &userID
+	return nil
+}
+
+// Skip moves a waiting passenger who was not there when called out of the queue, freeing
+// their place; returns false if the ticket is no longer waiting
+func (r *StandingTicketRepository) Skip(ticketID string) (bool, error) {
+	return r.endWaiting(`status = 'skipped', skipped_at = NOW()`, ticketID)
+}
+
+// Cancel cancels a waiting standing ticket; returns false if it is no longer waiting
+func (r *StandingTicketRepository) Cancel(ticketID string) (bool, error) {
+	return r.endWaiting(`status = 'cancelled', cancelled_at = NOW()`, ticketID)
+}
+
+func (r *StandingTicketRepository) endWaiting(set, ticketID string) (bool, error) {
+	result, err := r.db.Exec(`UPDATE standing_tickets SET `+set+` WHERE id = $1 AND status = 'waiting'`, ticketID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update standing ticket: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// generateBoardingToken returns a boarding token that shows the queue position, e.g. ST-007-3FA29C1B
+func generateBoardingToken(position int) (string, error) {
+	randomBytes := make([]byte, 4)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return fmt.Sprintf("ST-%03d-%s", position, strings.ToUpper(hex.EncodeToString(randomBytes))), nil
+}
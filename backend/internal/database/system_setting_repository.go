@@ -114,6 +114,19 @@ func (r *SystemSettingRepository) Update(key string, value string) error {
 	return nil
 }
 
+// Upsert sets a system setting's value, creating the setting if it does not exist yet
+func (r *SystemSettingRepository) Upsert(key, value, description string) error {
+	query := `
+		INSERT INTO system_settings (setting_key, setting_value, description)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (setting_key) DO UPDATE
+		SET setting_value = EXCLUDED.setting_value, updated_at = NOW()
+	`
+
+	_, err := r.db.Exec(query, key, value, description)
+	return err
+}
+
 // GetIntValue retrieves a system setting as an integer
 func (r *SystemSettingRepository) GetIntValue(key string, defaultValue int) int {
 	setting, err := r.GetByKey(key)
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TenantRepository handles white-label tenants, their API keys and tenant assignment
+type TenantRepository struct {
+	db *sqlx.DB
+}
+
+// NewTenantRepository creates a new TenantRepository
+func NewTenantRepository(db *sqlx.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+const tenantColumns = `id, slug, name, is_active, app_name, logo_url, primary_color, support_phone,
+	support_email, sms_mask, payment_merchant_key, payment_merchant_token, payment_package_name,
+	created_at, updated_at`
+
+// hashTenantAPIKey returns the stored form of an API key
+func hashTenantAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// ============================================================================
+// TENANTS
+// ============================================================================
+
+// Create stores a new tenant
+func (r *TenantRepository) Create(tenant *models.Tenant) error {
+	tenant.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO tenants (
+			id, slug, name, is_active, app_name, logo_url, primary_color, support_phone,
+			support_email, sms_mask, payment_merchant_key, payment_merchant_token, payment_package_name,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())
+		RETURNING created_at, updated_at`,
+		tenant.ID, tenant.Slug, tenant.Name, tenant.IsActive, tenant.AppName, tenant.LogoURL,
+		tenant.PrimaryColor, tenant.SupportPhone, tenant.SupportEmail, tenant.SMSMask,
+		tenant.PaymentMerchantKey, tenant.PaymentMerchantToken, tenant.PaymentPackageName,
+	).Scan(&tenant.CreatedAt, &tenant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
+}
+
+// Update saves a tenant's configuration
+func (r *TenantRepository) Update(tenant *models.Tenant) error {
+	err := r.db.QueryRow(`
+		UPDATE tenants
+		SET name = $2, is_active = $3, app_name = $4, logo_url = $5, primary_color = $6,
+		    support_phone = $7, support_email = $8, sms_mask = $9, payment_merchant_key = $10,
+		    payment_merchant_token = $11, payment_package_name = $12, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		tenant.ID, tenant.Name, tenant.IsActive, tenant.AppName, tenant.LogoURL, tenant.PrimaryColor,
+		tenant.SupportPhone, tenant.SupportEmail, tenant.SMSMask, tenant.PaymentMerchantKey,
+		tenant.PaymentMerchantToken, tenant.PaymentPackageName,
+	).Scan(&tenant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a tenant; returns nil if not found
+func (r *TenantRepository) GetByID(id string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.db.Get(&tenant, `SELECT `+tenantColumns+` FROM tenants WHERE id = $1`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return &tenant, nil
+}
+
+// SlugExists checks whether a tenant slug is taken
+func (r *TenantRepository) SlugExists(slug string) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM tenants WHERE slug = $1)`, slug)
+	if err != nil {
+		return false, fmt.Errorf("failed to check tenant slug: %w", err)
+	}
+	return exists, nil
+}
+
+// List returns all tenants
+func (r *TenantRepository) List() ([]models.Tenant, error) {
+	tenants := []models.Tenant{}
+	err := r.db.Select(&tenants, `SELECT `+tenantColumns+` FROM tenants ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// ============================================================================
+// API KEYS
+// ============================================================================
+
+// CreateAPIKey stores a new API key for a tenant (only its hash is kept)
+func (r *TenantRepository) CreateAPIKey(key *models.TenantAPIKey, rawKey string) error {
+	key.ID = uuid.New().String()
+	key.KeyHash = hashTenantAPIKey(rawKey)
+	err := r.db.QueryRow(`
+		INSERT INTO tenant_api_keys (id, tenant_id, name, key_prefix, key_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at`,
+		key.ID, key.TenantID, key.Name, key.KeyPrefix, key.KeyHash,
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant API key: %w", err)
+	}
+	return nil
+}
+
+// ListAPIKeys returns a tenant's API keys, including revoked ones
+func (r *TenantRepository) ListAPIKeys(tenantID string) ([]models.TenantAPIKey, error) {
+	keys := []models.TenantAPIKey{}
+	err := r.db.Select(&keys, `
+		SELECT id, tenant_id, name, key_prefix, key_hash, last_used_at, revoked_at, created_at
+		FROM tenant_api_keys
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a tenant's API key; returns false if there was no active key
+func (r *TenantRepository) RevokeAPIKey(tenantID, keyID string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE tenant_api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL`, keyID, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke tenant API key: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetTenantByAPIKey resolves an unrevoked API key of an active tenant; returns nil if there is none.
+// The key's last_used_at is refreshed at most once a minute.
+func (r *TenantRepository) GetTenantByAPIKey(rawKey string) (*models.Tenant, error) {
+	hash := hashTenantAPIKey(rawKey)
+
+	var tenant models.Tenant
+	err := r.db.Get(&tenant, `
+		SELECT t.id, t.slug, t.name, t.is_active, t.app_name, t.logo_url, t.primary_color, t.support_phone,
+			t.support_email, t.sms_mask, t.payment_merchant_key, t.payment_merchant_token, t.payment_package_name,
+			t.created_at, t.updated_at
+		FROM tenant_api_keys k
+		JOIN tenants t ON t.id = k.tenant_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND t.is_active = true`, hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tenant by API key: %w", err)
+	}
+
+	_, _ = r.db.Exec(`
+		UPDATE tenant_api_keys SET last_used_at = NOW()
+		WHERE key_hash = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`, hash)
+	return &tenant, nil
+}
+
+// ============================================================================
+// TENANT ASSIGNMENT
+// ============================================================================
+
+// AssignBusOwner moves a bus owner (and its trips that have not departed) to a tenant.
+// tenantID nil moves the owner back to SmartTransit.
+func (r *TenantRepository) AssignBusOwner(busOwnerID string, tenantID *string) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE bus_owners SET tenant_id = $2, updated_at = NOW() WHERE id = $1`, busOwnerID, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign bus owner tenant: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE scheduled_trips SET tenant_id = $2
+		WHERE departure_datetime > NOW()
+		  AND (bus_owner_route_id IN (SELECT id FROM bus_owner_routes WHERE bus_owner_id = $1)
+		       OR permit_id IN (SELECT id FROM route_permits WHERE bus_owner_id = $1)
+		       OR trip_schedule_id IN (SELECT id FROM trip_schedules WHERE bus_owner_id = $1))`, busOwnerID, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign trip tenant: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit tenant assignment: %w", err)
+	}
+	return true, nil
+}
+
+// SetUserTenantIfUnset records the tenant a user signed up through; existing assignments are kept
+func (r *TenantRepository) SetUserTenantIfUnset(userID uuid.UUID, tenantID string) error {
+	_, err := r.db.Exec(`UPDATE users SET tenant_id = $2 WHERE id = $1 AND tenant_id IS NULL`, userID, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to set user tenant: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// timetableExceptionColumns selects a timetable exception with its date as YYYY-MM-DD and its
+// departure time as HH:MM
+const timetableExceptionColumns = `
+	id, trip_schedule_id, to_char(exception_date, 'YYYY-MM-DD') AS exception_date, recurs_yearly,
+	action, to_char(departure_time, 'HH24:MI') AS departure_time, reason, created_by_user_id,
+	created_at, updated_at`
+
+// TimetableExceptionRepository handles timetable_exceptions, the system holiday calendar and
+// per-schedule overrides used by trip generation
+type TimetableExceptionRepository struct {
+	db *sqlx.DB
+}
+
+// NewTimetableExceptionRepository creates a new TimetableExceptionRepository
+func NewTimetableExceptionRepository(db *sqlx.DB) *TimetableExceptionRepository {
+	return &TimetableExceptionRepository{db: db}
+}
+
+// Create inserts an exception
+func (r *TimetableExceptionRepository) Create(exception *models.TimetableException) error {
+	query := `
+		INSERT INTO timetable_exceptions (
+			trip_schedule_id, exception_date, recurs_yearly, action, departure_time, reason, created_by_user_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRow(query,
+		exception.TripScheduleID, exception.ExceptionDate, exception.RecursYearly, exception.Action,
+		exception.DepartureTime, exception.Reason, exception.CreatedByUserID,
+	).Scan(&exception.ID, &exception.CreatedAt, &exception.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create timetable exception: %w", err)
+	}
+	return nil
+}
+
+// Update saves an exception's date, action and reason
+func (r *TimetableExceptionRepository) Update(exception *models.TimetableException) error {
+	query := `
+		UPDATE timetable_exceptions
+		SET exception_date = $2, recurs_yearly = $3, action = $4, departure_time = $5, reason = $6,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(query,
+		exception.ID, exception.ExceptionDate, exception.RecursYearly, exception.Action,
+		exception.DepartureTime, exception.Reason,
+	).Scan(&exception.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update timetable exception: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an exception
+func (r *TimetableExceptionRepository) Delete(id string) error {
+	if _, err := r.db.Exec(`DELETE FROM timetable_exceptions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete timetable exception: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns an exception; returns nil if it does not exist
+func (r *TimetableExceptionRepository) GetByID(id string) (*models.TimetableException, error) {
+	var exception models.TimetableException
+	err := r.db.Get(&exception, `SELECT `+timetableExceptionColumns+` FROM timetable_exceptions WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timetable exception: %w", err)
+	}
+	return &exception, nil
+}
+
+// ListSystem returns the system holiday calendar. Yearly holidays are always included; one-off
+// dates only from fromDate (YYYY-MM-DD) onwards.
+func (r *TimetableExceptionRepository) ListSystem(fromDate string) ([]models.TimetableException, error) {
+	exceptions := []models.TimetableException{}
+	err := r.db.Select(&exceptions, `
+		SELECT `+timetableExceptionColumns+`
+		FROM timetable_exceptions
+		WHERE trip_schedule_id IS NULL
+		  AND (recurs_yearly OR exception_date >= $1)
+		ORDER BY exception_date`, fromDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list system timetable exceptions: %w", err)
+	}
+	return exceptions, nil
+}
+
+// ListForSchedule returns the exceptions that apply to a schedule: its own and the system
+// holidays. Yearly exceptions are always included; one-off dates only from fromDate onwards.
+func (r *TimetableExceptionRepository) ListForSchedule(scheduleID, fromDate string) ([]models.TimetableException, error) {
+	exceptions := []models.TimetableException{}
+	err := r.db.Select(&exceptions, `
+		SELECT `+timetableExceptionColumns+`
+		FROM timetable_exceptions
+		WHERE (trip_schedule_id = $1 OR trip_schedule_id IS NULL)
+		  AND (recurs_yearly OR exception_date >= $2)
+		ORDER BY exception_date, trip_schedule_id NULLS FIRST`, scheduleID, fromDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timetable exceptions: %w", err)
+	}
+	return exceptions, nil
+}
+
+// CountDuplicate counts exceptions other than excludeID at the same level (a schedule, or
+// system-wide when scheduleID is nil) on the same date; yearly exceptions by day of the year
+func (r *TimetableExceptionRepository) CountDuplicate(scheduleID *string, exceptionDate string, recursYearly bool, excludeID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `
+		SELECT COUNT(*)
+		FROM timetable_exceptions
+		WHERE trip_schedule_id IS NOT DISTINCT FROM $1
+		  AND recurs_yearly = $3
+		  AND CASE WHEN $3 THEN to_char(exception_date, 'MM-DD') = to_char($2::date, 'MM-DD')
+		           ELSE exception_date = $2::date END
+		  AND id::text <> $4`, scheduleID, exceptionDate, recursYearly, excludeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check timetable exception: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,193 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripAutoPublishRepository handles per-schedule auto-publish settings and the alerts raised
+// when a due trip cannot be published
+type TripAutoPublishRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripAutoPublishRepository creates a new TripAutoPublishRepository
+func NewTripAutoPublishRepository(db *sqlx.DB) *TripAutoPublishRepository {
+	return &TripAutoPublishRepository{db: db}
+}
+
+// ============================================================================
+// SETTINGS
+// ============================================================================
+
+// GetSettings returns a schedule's auto-publish settings; returns nil if never configured
+func (r *TripAutoPublishRepository) GetSettings(scheduleID string) (*models.TripAutoPublishSettings, error) {
+	var settings models.TripAutoPublishSettings
+	err := r.db.Get(&settings, `
+		SELECT trip_schedule_id, enabled, days_before_departure, updated_at
+		FROM trip_schedule_auto_publish
+		WHERE trip_schedule_id = $1`, scheduleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get auto-publish settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpsertSettings saves a schedule's auto-publish settings
+func (r *TripAutoPublishRepository) UpsertSettings(settings *models.TripAutoPublishSettings) error {
+	err := r.db.QueryRow(`
+		INSERT INTO trip_schedule_auto_publish (trip_schedule_id, enabled, days_before_departure, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (trip_schedule_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+		    days_before_departure = EXCLUDED.days_before_departure,
+		    updated_at = NOW()
+		RETURNING updated_at`,
+		settings.TripScheduleID, settings.Enabled, settings.DaysBeforeDeparture,
+	).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save auto-publish settings: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// DUE TRIPS
+// ============================================================================
+
+// GetDueTrips returns unpublished trips of active auto-publish schedules departing within their
+// schedule's window, soonest first. Trips the owner published and then unpublished are left alone.
+func (r *TripAutoPublishRepository) GetDueTrips(limit int) ([]models.DueAutoPublishTrip, error) {
+	trips := []models.DueAutoPublishTrip{}
+	err := r.db.Select(&trips, `
+		SELECT st.id AS trip_id, ts.id AS trip_schedule_id, ts.bus_owner_id,
+		       bo.verification_status AS owner_verification_status,
+		       st.bus_id, st.seat_layout_id, st.base_fare, st.departure_datetime
+		FROM trip_schedule_auto_publish ap
+		JOIN trip_schedules ts ON ts.id = ap.trip_schedule_id AND ts.is_active = true
+		JOIN bus_owners bo ON bo.id = ts.bus_owner_id
+		JOIN scheduled_trips st ON st.trip_schedule_id = ts.id
+		WHERE ap.enabled = true
+		  AND st.status = 'scheduled'
+		  AND st.is_bookable = false
+		  AND COALESCE(st.ever_published, false) = false
+		  AND st.departure_datetime > NOW()
+		  AND st.departure_datetime <= NOW() + make_interval(days => ap.days_before_departure)
+		ORDER BY st.departure_datetime
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trips due for auto-publish: %w", err)
+	}
+	return trips, nil
+}
+
+// ============================================================================
+// ALERTS
+// ============================================================================
+
+const tripAutoPublishAlertColumns = `
+	a.id, a.bus_owner_id, a.trip_schedule_id, ts.schedule_name, a.scheduled_trip_id, st.departure_datetime,
+	a.reason, a.message, a.status, a.first_seen_at, a.last_seen_at, a.resolved_at`
+
+// RaiseAlert records why a trip could not be published. An unresolved alert for the trip is
+// updated instead of adding another; a dismissed one reopens only if the reason changed.
+func (r *TripAutoPublishRepository) RaiseAlert(trip *models.DueAutoPublishTrip, reason models.AutoPublishAlertReason, message string) error {
+	result, err := r.db.Exec(`
+		UPDATE trip_auto_publish_alerts
+		SET status = CASE WHEN reason = $2 THEN status ELSE 'open' END,
+		    reason = $2, message = $3, last_seen_at = NOW()
+		WHERE scheduled_trip_id = $1 AND status <> 'resolved'`,
+		trip.TripID, reason, message)
+	if err != nil {
+		return fmt.Errorf("failed to update auto-publish alert: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+		return nil
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO trip_auto_publish_alerts (
+			id, bus_owner_id, trip_schedule_id, scheduled_trip_id, reason, message, status, first_seen_at, last_seen_at
+		) VALUES ($1, $2, $3, $4, $5, $6, 'open', NOW(), NOW())`,
+		uuid.New().String(), trip.BusOwnerID, trip.TripScheduleID, trip.TripID, reason, message)
+	if err != nil {
+		return fmt.Errorf("failed to create auto-publish alert: %w", err)
+	}
+	return nil
+}
+
+// ResolveTripAlerts resolves a trip's alert once it is published
+func (r *TripAutoPublishRepository) ResolveTripAlerts(tripID string) error {
+	_, err := r.db.Exec(`
+		UPDATE trip_auto_publish_alerts
+		SET status = 'resolved', resolved_at = NOW()
+		WHERE scheduled_trip_id = $1 AND status <> 'resolved'`, tripID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve auto-publish alerts: %w", err)
+	}
+	return nil
+}
+
+// ResolveStaleAlerts resolves alerts whose trip no longer needs auto-publishing: published by
+// hand, cancelled, departed, or on a schedule with auto-publish turned off
+func (r *TripAutoPublishRepository) ResolveStaleAlerts() (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE trip_auto_publish_alerts a
+		SET status = 'resolved', resolved_at = NOW()
+		FROM scheduled_trips st
+		WHERE st.id = a.scheduled_trip_id
+		  AND a.status <> 'resolved'
+		  AND (st.is_bookable = true OR st.status <> 'scheduled' OR st.departure_datetime <= NOW()
+		       OR NOT EXISTS (
+		           SELECT 1 FROM trip_schedule_auto_publish ap
+		           WHERE ap.trip_schedule_id = a.trip_schedule_id AND ap.enabled = true))`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve stale auto-publish alerts: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
+// ListOwnerAlerts returns a bus owner's open alerts, soonest departure first. includeDismissed
+// adds the ones the owner has dismissed.
+func (r *TripAutoPublishRepository) ListOwnerAlerts(busOwnerID string, includeDismissed bool) ([]models.TripAutoPublishAlert, error) {
+	alerts := []models.TripAutoPublishAlert{}
+	err := r.db.Select(&alerts, `
+		SELECT `+tripAutoPublishAlertColumns+`
+		FROM trip_auto_publish_alerts a
+		JOIN scheduled_trips st ON st.id = a.scheduled_trip_id
+		LEFT JOIN trip_schedules ts ON ts.id = a.trip_schedule_id
+		WHERE a.bus_owner_id = $1
+		  AND (a.status = 'open' OR ($2 AND a.status = 'dismissed'))
+		ORDER BY st.departure_datetime`, busOwnerID, includeDismissed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auto-publish alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// DismissAlert hides a bus owner's open alert; returns false if there is no such open alert
+func (r *TripAutoPublishRepository) DismissAlert(alertID, busOwnerID string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE trip_auto_publish_alerts
+		SET status = 'dismissed'
+		WHERE id = $1 AND bus_owner_id = $2 AND status = 'open'`, alertID, busOwnerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to dismiss auto-publish alert: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripBoardingWindowRepository handles trip_boarding_windows and finds the trip behind a check-in
+type TripBoardingWindowRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripBoardingWindowRepository creates a new TripBoardingWindowRepository
+func NewTripBoardingWindowRepository(db *sqlx.DB) *TripBoardingWindowRepository {
+	return &TripBoardingWindowRepository{db: db}
+}
+
+// GetWindow returns a trip's boarding window; returns nil if the owner never set one
+func (r *TripBoardingWindowRepository) GetWindow(scheduledTripID string) (*models.TripBoardingWindow, error) {
+	var window models.TripBoardingWindow
+	err := r.db.Get(&window, `
+		SELECT scheduled_trip_id, opens_minutes_before, closes_minutes_after, created_at, updated_at
+		FROM trip_boarding_windows
+		WHERE scheduled_trip_id = $1`, scheduledTripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boarding window: %w", err)
+	}
+	return &window, nil
+}
+
+// UpsertWindow creates or updates a trip's boarding window
+func (r *TripBoardingWindowRepository) UpsertWindow(window *models.TripBoardingWindow) error {
+	query := `
+		INSERT INTO trip_boarding_windows (scheduled_trip_id, opens_minutes_before, closes_minutes_after)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scheduled_trip_id) DO UPDATE SET
+			opens_minutes_before = EXCLUDED.opens_minutes_before,
+			closes_minutes_after = EXCLUDED.closes_minutes_after,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(query, window.ScheduledTripID, window.OpensMinutesBefore, window.ClosesMinutesAfter).
+		Scan(&window.CreatedAt, &window.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save boarding window: %w", err)
+	}
+	return nil
+}
+
+// boardingTargetSelect resolves a bus booking to its trip's departure and bus owner
+const boardingTargetSelect = `
+	SELECT bb.id AS bus_booking_id, st.id AS scheduled_trip_id, st.departure_datetime,
+	       COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS bus_owner_id
+	FROM bus_bookings bb
+	JOIN scheduled_trips st ON bb.scheduled_trip_id = st.id
+	LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+	LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id`
+
+// GetTargetByBusBooking returns the trip a bus booking is on; returns nil if the booking does not exist
+func (r *TripBoardingWindowRepository) GetTargetByBusBooking(busBookingID string) (*models.BoardingTarget, error) {
+	var target models.BoardingTarget
+	err := r.db.Get(&target, boardingTargetSelect+` WHERE bb.id = $1`, busBookingID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boarding target: %w", err)
+	}
+	return &target, nil
+}
+
+// GetTargetBySeat returns the trip a booked seat is on; returns nil if the seat does not exist
+func (r *TripBoardingWindowRepository) GetTargetBySeat(seatID string) (*models.BoardingTarget, error) {
+	var target models.BoardingTarget
+	err := r.db.Get(&target, boardingTargetSelect+`
+		JOIN bus_booking_seats bbs ON bbs.bus_booking_id = bb.id
+		WHERE bbs.id = $1`, seatID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boarding target: %w", err)
+	}
+	return &target, nil
+}
+
+// GetTripOwner returns the bus owner of a trip, via its schedule or route; returns nil if the
+// trip does not exist or has no owner
+func (r *TripBoardingWindowRepository) GetTripOwner(scheduledTripID string) (*string, error) {
+	var owner struct {
+		BusOwnerID *string `db:"bus_owner_id"`
+	}
+	err := r.db.Get(&owner, `
+		SELECT COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS bus_owner_id
+		FROM scheduled_trips st
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		WHERE st.id = $1`, scheduledTripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip owner: %w", err)
+	}
+	return owner.BusOwnerID, nil
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripBookingListRepository reads every booking on a trip, across app bookings, manual
+// (phone/agent/walk-in) bookings and standing tickets
+type TripBookingListRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripBookingListRepository creates a new TripBookingListRepository
+func NewTripBookingListRepository(db *sqlx.DB) *TripBookingListRepository {
+	return &TripBookingListRepository{db: db}
+}
+
+// GetTrip returns a trip with its bus owner, via its schedule or route; returns nil if not found
+func (r *TripBookingListRepository) GetTrip(scheduledTripID string) (*models.TripBookingListTrip, error) {
+	var trip models.TripBookingListTrip
+	err := r.db.Get(&trip, `
+		SELECT st.id AS scheduled_trip_id, st.departure_datetime, st.status,
+		       COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS bus_owner_id
+		FROM scheduled_trips st
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		WHERE st.id = $1`, scheduledTripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip: %w", err)
+	}
+	return &trip, nil
+}
+
+// ListByTrip returns all bookings on a trip, cancelled ones included. App bookings count as
+// collected once paid, manual bookings by the amount paid so far, and standing tickets once the
+// conductor has taken the fare.
+func (r *TripBookingListRepository) ListByTrip(scheduledTripID string) ([]models.TripBookingEntry, error) {
+	entries := []models.TripBookingEntry{}
+	err := r.db.Select(&entries, `
+		SELECT 'app' AS source, b.booking_source::text AS channel, b.id::text AS booking_id, b.booking_reference,
+		       b.passenger_name, b.passenger_phone,
+		       bb.boarding_stop_id::text AS boarding_stop_id, bs.stop_name AS boarding_stop_name, als.stop_name AS alighting_stop_name,
+		       COALESCE((SELECT array_agg(tse.seat_number ORDER BY tse.seat_number)
+		                 FROM bus_booking_seats bbs
+		                 JOIN trip_seats tse ON tse.id = bbs.trip_seat_id
+		                 WHERE bbs.bus_booking_id = bb.id AND bbs.status != 'cancelled')::text[], '{}') AS seat_numbers,
+		       bb.number_of_seats, bb.status::text AS status, b.payment_status::text AS payment_status,
+		       bb.total_fare AS fare,
+		       CASE WHEN b.payment_status = 'paid' THEN bb.total_fare ELSE 0 END AS amount_collected,
+		       bb.created_at
+		FROM bus_bookings bb
+		JOIN bookings b ON b.id = bb.booking_id
+		LEFT JOIN master_route_stops bs ON bs.id = bb.boarding_stop_id
+		LEFT JOIN master_route_stops als ON als.id = bb.alighting_stop_id
+		WHERE bb.scheduled_trip_id = $1
+
+		UNION ALL
+
+		SELECT CASE WHEN mb.booking_type = 'walk_in' THEN 'onboard' ELSE 'manual' END AS source,
+		       mb.booking_type::text AS channel, mb.id::text AS booking_id, mb.booking_reference,
+		       mb.passenger_name, mb.passenger_phone,
+		       mb.boarding_stop_id::text AS boarding_stop_id, bs.stop_name AS boarding_stop_name, als.stop_name AS alighting_stop_name,
+		       COALESCE((SELECT array_agg(mbs.seat_number ORDER BY mbs.seat_number)
+		                 FROM manual_booking_seats mbs
+		                 WHERE mbs.manual_booking_id = mb.id)::text[], '{}') AS seat_numbers,
+		       mb.number_of_seats, mb.status::text AS status, mb.payment_status::text AS payment_status,
+		       mb.total_fare AS fare, mb.amount_paid AS amount_collected,
+		       mb.created_at
+		FROM manual_seat_bookings mb
+		LEFT JOIN master_route_stops bs ON bs.id = mb.boarding_stop_id
+		LEFT JOIN master_route_stops als ON als.id = mb.alighting_stop_id
+		WHERE mb.scheduled_trip_id = $1
+
+		UNION ALL
+
+		SELECT 'onboard' AS source, 'standing_' || t.source AS channel, t.id::text AS booking_id,
+		       'STANDING-' || t.queue_position AS booking_reference,
+		       COALESCE(t.passenger_name, '') AS passenger_name, NULL::text AS passenger_phone,
+		       NULL::text AS boarding_stop_id, NULL::text AS boarding_stop_name, NULL::text AS alighting_stop_name,
+		       '{}'::text[] AS seat_numbers,
+		       1 AS number_of_seats, t.status::text AS status, t.payment_status::text AS payment_status,
+		       t.fare, CASE WHEN t.payment_status = 'paid' THEN t.fare ELSE 0 END AS amount_collected,
+		       t.created_at
+		FROM standing_tickets t
+		WHERE t.scheduled_trip_id = $1`, scheduledTripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trip bookings: %w", err)
+	}
+	return entries, nil
+}
+
+// GetManifestTrip returns a trip with its route, bus and crew, and the users allowed to print
+// its manifest; returns nil if not found
+func (r *TripBookingListRepository) GetManifestTrip(scheduledTripID string) (*models.TripManifestTrip, error) {
+	var trip models.TripManifestTrip
+	err := r.db.Get(&trip, `
+		SELECT st.id AS scheduled_trip_id, st.departure_datetime, st.status,
+		       COALESCE(mr.route_name, bor.custom_route_name) AS route_name, mr.route_number,
+		       b.bus_number,
+		       NULLIF(TRIM(COALESCE(drv.first_name, '') || ' ' || COALESCE(drv.last_name, '')), '') AS driver_name,
+		       NULLIF(TRIM(COALESCE(cond.first_name, '') || ' ' || COALESCE(cond.last_name, '')), '') AS conductor_name,
+		       bo.user_id::text AS owner_user_id, drv.user_id::text AS driver_user_id, cond.user_id::text AS conductor_user_id
+		FROM scheduled_trips st
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON bor.id = COALESCE(st.bus_owner_route_id, ts.bus_owner_route_id)
+		LEFT JOIN master_routes mr ON mr.id = bor.master_route_id
+		LEFT JOIN buses b ON b.id = st.bus_id
+		LEFT JOIN bus_owners bo ON bo.id = COALESCE(ts.bus_owner_id, bor.bus_owner_id)
+		LEFT JOIN bus_staff drv ON drv.id = st.assigned_driver_id
+		LEFT JOIN bus_staff cond ON cond.id = st.assigned_conductor_id
+		WHERE st.id = $1`, scheduledTripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest trip: %w", err)
+	}
+	return &trip, nil
+}
+
+// ListManifestPassengers returns one row per seat booked on a trip, from app and manual
+// bookings, and one per standing ticket, cancelled ones included
+func (r *TripBookingListRepository) ListManifestPassengers(scheduledTripID string) ([]models.TripManifestPassenger, error) {
+	passengers := []models.TripManifestPassenger{}
+	err := r.db.Select(&passengers, `
+		SELECT 'app' AS source, b.booking_reference,
+		       COALESCE(NULLIF(bbs.passenger_name, ''), b.passenger_name) AS passenger_name,
+		       COALESCE(NULLIF(bbs.passenger_phone, ''), b.passenger_phone) AS passenger_phone,
+		       tse.seat_number,
+		       bb.boarding_stop_id::text AS boarding_stop_id, bs.stop_name AS boarding_stop_name, bs.stop_order AS boarding_stop_order,
+		       als.stop_name AS alighting_stop_name,
+		       CASE WHEN bb.status = 'cancelled' THEN 'cancelled' ELSE bbs.status::text END AS status,
+		       b.payment_status::text AS payment_status
+		FROM bus_booking_seats bbs
+		JOIN bus_bookings bb ON bb.id = bbs.bus_booking_id
+		JOIN bookings b ON b.id = bb.booking_id
+		LEFT JOIN trip_seats tse ON tse.id = bbs.trip_seat_id
+		LEFT JOIN master_route_stops bs ON bs.id = bb.boarding_stop_id
+		LEFT JOIN master_route_stops als ON als.id = bb.alighting_stop_id
+		WHERE bb.scheduled_trip_id = $1
+
+		UNION ALL
+
+		SELECT CASE WHEN mb.booking_type = 'walk_in' THEN 'onboard' ELSE 'manual' END AS source,
+		       mb.booking_reference,
+		       COALESCE(NULLIF(mbs.passenger_name, ''), mb.passenger_name) AS passenger_name,
+		       mb.passenger_phone, mbs.seat_number,
+		       mb.boarding_stop_id::text AS boarding_stop_id, bs.stop_name AS boarding_stop_name, bs.stop_order AS boarding_stop_order,
+		       als.stop_name AS alighting_stop_name,
+		       mb.status::text AS status, mb.payment_status::text AS payment_status
+		FROM manual_booking_seats mbs
+		JOIN manual_seat_bookings mb ON mb.id = mbs.manual_booking_id
+		LEFT JOIN master_route_stops bs ON bs.id = mb.boarding_stop_id
+		LEFT JOIN master_route_stops als ON als.id = mb.alighting_stop_id
+		WHERE mb.scheduled_trip_id = $1
+
+		UNION ALL
+
+		SELECT 'onboard' AS source, 'STANDING-' || t.queue_position AS booking_reference,
+		       COALESCE(t.passenger_name, '') AS passenger_name, NULL::text AS passenger_phone,
+		       NULL::text AS seat_number,
+		       NULL::text AS boarding_stop_id, NULL::text AS boarding_stop_name, NULL::int AS boarding_stop_order,
+		       NULL::text AS alighting_stop_name,
+		       t.status::text AS status, t.payment_status::text AS payment_status
+		FROM standing_tickets t
+		WHERE t.scheduled_trip_id = $1`, scheduledTripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list manifest passengers: %w", err)
+	}
+	return passengers, nil
+}
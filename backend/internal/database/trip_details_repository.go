@@ -0,0 +1,132 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripDetailsRepository reads what passengers see about a bookable trip before booking, and the
+// operators' travel policies shown with it
+type TripDetailsRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripDetailsRepository creates a new TripDetailsRepository
+func NewTripDetailsRepository(db *sqlx.DB) *TripDetailsRepository {
+	return &TripDetailsRepository{db: db}
+}
+
+// GetBookableTrip returns a trip open for booking in the tenant's scope; returns nil if not found
+// or no longer bookable
+func (r *TripDetailsRepository) GetBookableTrip(tripID string, tenantID *string) (*models.BookableTripDetailsRow, error) {
+	var trip models.BookableTripDetailsRow
+	err := r.db.Get(&trip, `
+		SELECT st.id AS trip_id,
+		       COALESCE(bor.custom_route_name, mr_bor.route_name, mr_permit.route_name) AS route_name,
+		       COALESCE(mr_bor.route_number, mr_permit.route_number) AS route_number,
+		       b.bus_type, st.departure_datetime,
+		       COALESCE(st.estimated_duration_minutes, 0) AS duration_minutes,
+		       COALESCE(rp.approved_fare, st.base_fare, 0) AS fare,
+		       bo.company_name AS operator_name, bo.id::text AS bus_owner_id,
+		       COALESCE(bor.master_route_id, rp.master_route_id)::text AS master_route_id,
+		       bor.id::text AS bus_owner_route_id,
+		       COALESCE(b.has_wifi, false) AS has_wifi,
+		       COALESCE(b.has_ac, false) AS has_ac,
+		       COALESCE(b.has_charging_ports, false) AS has_charging_ports,
+		       COALESCE(b.has_entertainment, false) AS has_entertainment,
+		       COALESCE(b.has_refreshments, false) AS has_refreshments
+		FROM scheduled_trips st
+		LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN master_routes mr_bor ON bor.master_route_id = mr_bor.id
+		LEFT JOIN route_permits rp ON st.permit_id = rp.id
+		LEFT JOIN master_routes mr_permit ON rp.master_route_id = mr_permit.id
+		LEFT JOIN buses b ON rp.bus_registration_number = b.license_plate
+		LEFT JOIN bus_owners bo ON bo.id = COALESCE(ts.bus_owner_id, bor.bus_owner_id, rp.bus_owner_id)
+		WHERE st.id = $1
+		  AND st.is_bookable = true
+		  AND st.status IN ('scheduled', 'confirmed')
+		  AND st.departure_datetime > NOW()
+		  AND st.tenant_id IS NOT DISTINCT FROM $2::uuid`, tripID, tenantID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookable trip: %w", err)
+	}
+	return &trip, nil
+}
+
+// GetTripStops returns the stops a trip serves in order: the owner's selected stops, or every
+// stop on the master route when the owner has not picked any
+func (r *TripDetailsRepository) GetTripStops(masterRouteID string, busOwnerRouteID *string) ([]models.RouteStop, error) {
+	stops := []models.RouteStop{}
+	err := r.db.Select(&stops, `
+		SELECT mrs.id, mrs.stop_name, mrs.stop_order, mrs.latitude, mrs.longitude,
+		       mrs.arrival_time_offset_minutes, mrs.is_major_stop
+		FROM master_route_stops mrs
+		LEFT JOIN bus_owner_routes bor ON bor.id = $2::uuid
+		WHERE mrs.master_route_id = $1
+		  AND (bor.selected_stop_ids IS NULL
+		       OR COALESCE(array_length(bor.selected_stop_ids, 1), 0) = 0
+		       OR mrs.id = ANY(bor.selected_stop_ids))
+		ORDER BY mrs.stop_order ASC`, masterRouteID, busOwnerRouteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip stops: %w", err)
+	}
+	return stops, nil
+}
+
+// GetSeatPriceRanges returns the price range of the trip's available seats per seat type
+func (r *TripDetailsRepository) GetSeatPriceRanges(tripID string) ([]models.SeatPriceRange, error) {
+	ranges := []models.SeatPriceRange{}
+	err := r.db.Select(&ranges, `
+		SELECT seat_type, MIN(seat_price) AS min_price, MAX(seat_price) AS max_price,
+		       COUNT(*) AS available_seats
+		FROM trip_seats
+		WHERE scheduled_trip_id = $1 AND status = 'available'
+		GROUP BY seat_type
+		ORDER BY MIN(seat_price), seat_type`, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seat price ranges: %w", err)
+	}
+	return ranges, nil
+}
+
+// GetTravelPolicy returns a bus owner's travel policy; returns nil if never configured
+func (r *TripDetailsRepository) GetTravelPolicy(busOwnerID string) (*models.OperatorTravelPolicy, error) {
+	var policy models.OperatorTravelPolicy
+	err := r.db.Get(&policy, `
+		SELECT bus_owner_id, luggage_allowance_kg, luggage_pieces, luggage_notes, updated_at
+		FROM bus_owner_travel_policies
+		WHERE bus_owner_id = $1`, busOwnerID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get travel policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// UpsertTravelPolicy saves a bus owner's travel policy
+func (r *TripDetailsRepository) UpsertTravelPolicy(policy *models.OperatorTravelPolicy) error {
+	err := r.db.QueryRow(`
+		INSERT INTO bus_owner_travel_policies (bus_owner_id, luggage_allowance_kg, luggage_pieces, luggage_notes, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (bus_owner_id) DO UPDATE
+		SET luggage_allowance_kg = EXCLUDED.luggage_allowance_kg,
+		    luggage_pieces = EXCLUDED.luggage_pieces,
+		    luggage_notes = EXCLUDED.luggage_notes,
+		    updated_at = NOW()
+		RETURNING updated_at`,
+		policy.BusOwnerID, policy.LuggageAllowanceKg, policy.LuggagePieces, policy.LuggageNotes,
+	).Scan(&policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save travel policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripETARepository reads active trips with their stops, and when recent trips of the same
+// route reached each stop, for arrival estimates
+type TripETARepository struct {
+	db *sqlx.DB
+}
+
+// NewTripETARepository creates a new TripETARepository
+func NewTripETARepository(db *sqlx.DB) *TripETARepository {
+	return &TripETARepository{db: db}
+}
+
+const tripETATripQuery = `
+	SELECT at.id AS active_trip_id,` + gtfsTripColumns + `,` + gtfsActiveTripColumns + `
+	FROM active_trips at
+	JOIN scheduled_trips st ON st.id = at.scheduled_trip_id` + gtfsTripJoins + `
+	LEFT JOIN buses b ON b.id = at.bus_id`
+
+// GetTrip returns an active trip with its scheduled trip's route, or nil when there is none
+func (r *TripETARepository) GetTrip(activeTripID string) (*models.TripETATrip, error) {
+	return r.getTrip(`WHERE at.id = $1`, activeTripID)
+}
+
+// GetTripByScheduledTripID returns the latest active trip of a scheduled trip, or nil when it
+// has not started
+func (r *TripETARepository) GetTripByScheduledTripID(scheduledTripID string) (*models.TripETATrip, error) {
+	return r.getTrip(`WHERE at.scheduled_trip_id = $1 ORDER BY at.created_at DESC LIMIT 1`, scheduledTripID)
+}
+
+func (r *TripETARepository) getTrip(where string, id string) (*models.TripETATrip, error) {
+	var trip models.TripETATrip
+	err := r.db.Get(&trip, tripETATripQuery+`
+	`+where, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active trip: %w", err)
+	}
+	return &trip, nil
+}
+
+// GetRouteStops returns a master route's stops in stop order
+func (r *TripETARepository) GetRouteStops(masterRouteID string) ([]models.MasterRouteStop, error) {
+	stops := []models.MasterRouteStop{}
+	err := r.db.Select(&stops, `
+		SELECT id, master_route_id, stop_name, stop_order, latitude, longitude,
+			arrival_time_offset_minutes, is_major_stop, created_at
+		FROM master_route_stops
+		WHERE master_route_id = $1
+		ORDER BY stop_order`, masterRouteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route stops: %w", err)
+	}
+	return stops, nil
+}
+
+// GetStopPassages returns when each of the route's last completed trips departing since the
+// cutoff first came within models.TripETAStopRadiusDegrees of each of its stops, from their
+// location history
+func (r *TripETARepository) GetStopPassages(masterRouteID string, since time.Time, trips int) ([]models.TripStopPassage, error) {
+	passages := []models.TripStopPassage{}
+	err := r.db.Select(&passages, `
+		WITH past AS (
+			SELECT at.id
+			FROM active_trips at
+			JOIN scheduled_trips st ON st.id = at.scheduled_trip_id`+gtfsTripJoins+`
+			WHERE at.status = 'completed'
+			  AND mr.id = $1
+			  AND at.actual_departure_time >= $2
+			ORDER BY at.actual_departure_time DESC
+			LIMIT $3
+		)
+		SELECT h.active_trip_id, s.id AS stop_id, MIN(h.recorded_at) AS passed_at
+		FROM past
+		JOIN trip_location_history h ON h.active_trip_id = past.id
+		JOIN master_route_stops s ON s.master_route_id = $1
+		WHERE h.latitude BETWEEN s.latitude - $4 AND s.latitude + $4
+		  AND h.longitude BETWEEN s.longitude - $4 AND s.longitude + $4
+		GROUP BY h.active_trip_id, s.id`, masterRouteID, since, trips, models.TripETAStopRadiusDegrees)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stop passages: %w", err)
+	}
+	return passages, nil
+}
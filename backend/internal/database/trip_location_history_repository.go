@@ -0,0 +1,56 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripLocationHistoryRepository stores every position reported during active trips, so a trip's
+// route can be played back after the live location has moved on
+type TripLocationHistoryRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripLocationHistoryRepository creates a new TripLocationHistoryRepository
+func NewTripLocationHistoryRepository(db *sqlx.DB) *TripLocationHistoryRepository {
+	return &TripLocationHistoryRepository{db: db}
+}
+
+// InsertBatch stores the records in one statement
+func (r *TripLocationHistoryRepository) InsertBatch(records []models.TripLocationRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(records))
+	args := make([]interface{}, 0, len(records)*6)
+	for i, record := range records {
+		n := i * 6
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6))
+		args = append(args, record.ActiveTripID, record.Latitude, record.Longitude, record.SpeedKmh, record.Heading, record.RecordedAt)
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO trip_location_history (active_trip_id, latitude, longitude, speed_kmh, heading, recorded_at)
+		VALUES `+strings.Join(values, ", "), args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert trip location history: %w", err)
+	}
+	return nil
+}
+
+// GetByActiveTripID returns up to limit points of an active trip in the order they were recorded
+func (r *TripLocationHistoryRepository) GetByActiveTripID(activeTripID string, limit int) ([]models.TripLocationPoint, error) {
+	points := []models.TripLocationPoint{}
+	err := r.db.Select(&points, `
+		SELECT latitude, longitude, speed_kmh, heading, recorded_at
+		FROM trip_location_history
+		WHERE active_trip_id = $1
+		ORDER BY recorded_at, id
+		LIMIT $2`, activeTripID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip location history: %w", err)
+	}
+	return points, nil
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripMessageRepository handles per-trip message threads between owners and staff
+type TripMessageRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripMessageRepository creates a new TripMessageRepository
+func NewTripMessageRepository(db *sqlx.DB) *TripMessageRepository {
+	return &TripMessageRepository{db: db}
+}
+
+// GetParticipants resolves the owner (via the trip's permit) and assigned staff of a trip.
+// Returns nil if the trip does not exist.
+func (r *TripMessageRepository) GetParticipants(scheduledTripID string) (*models.TripThreadParticipants, error) {
+	var participants models.TripThreadParticipants
+	err := r.db.Get(&participants, `
+		SELECT
+			st.id as scheduled_trip_id,
+			st.departure_datetime,
+			bo.user_id as owner_user_id,
+			COALESCE(bo.company_name, bo.contact_person) as owner_name,
+			d.user_id as driver_user_id,
+			NULLIF(CONCAT_WS(' ', d.first_name, d.last_name), '') as driver_name,
+			cd.user_id as conductor_user_id,
+			NULLIF(CONCAT_WS(' ', cd.first_name, cd.last_name), '') as conductor_name
+		FROM scheduled_trips st
+		LEFT JOIN route_permits rp ON st.permit_id = rp.id
+		LEFT JOIN bus_owners bo ON rp.bus_owner_id = bo.id
+		LEFT JOIN bus_staff d ON st.assigned_driver_id = d.id
+		LEFT JOIN bus_staff cd ON st.assigned_conductor_id = cd.id
+		WHERE st.id = $1`, scheduledTripID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get trip participants: %w", err)
+	}
+	return &participants, nil
+}
+
+// CreateMessage stores a message
+func (r *TripMessageRepository) CreateMessage(message *models.TripMessage) error {
+	message.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO trip_messages (id, scheduled_trip_id, sender_user_id, sender_role, body, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at`,
+		message.ID, message.ScheduledTripID, message.SenderUserID, message.SenderRole, message.Body,
+	).Scan(&message.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create trip message: %w", err)
+	}
+	return nil
+}
+
+// ListMessages returns up to limit messages of a trip, newest first, optionally older than before
+func (r *TripMessageRepository) ListMessages(scheduledTripID string, before *time.Time, limit int) ([]models.TripMessage, error) {
+	messages := []models.TripMessage{}
+	err := r.db.Select(&messages, `
+		SELECT id, scheduled_trip_id, sender_user_id, sender_role, body, created_at
+		FROM trip_messages
+		WHERE scheduled_trip_id = $1
+		  AND ($2::timestamptz IS NULL OR created_at < $2)
+		ORDER BY created_at DESC
+		LIMIT $3`, scheduledTripID, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trip messages: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkRead moves the user's read marker for a trip forward to now
+func (r *TripMessageRepository) MarkRead(scheduledTripID, userID string) (time.Time, error) {
+	var readAt time.Time
+	err := r.db.QueryRow(`
+		INSERT INTO trip_message_reads (scheduled_trip_id, user_id, last_read_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (scheduled_trip_id, user_id)
+		DO UPDATE SET last_read_at = GREATEST(trip_message_reads.last_read_at, EXCLUDED.last_read_at)
+		RETURNING last_read_at`, scheduledTripID, userID).Scan(&readAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to mark trip messages read: %w", err)
+	}
+	return readAt, nil
+}
+
+// GetReadMarkers returns each participant's last read time for a trip
+func (r *TripMessageRepository) GetReadMarkers(scheduledTripID string) (map[string]time.Time, error) {
+	var rows []struct {
+		UserID     string    `db:"user_id"`
+		LastReadAt time.Time `db:"last_read_at"`
+	}
+	err := r.db.Select(&rows, `
+		SELECT user_id, last_read_at FROM trip_message_reads WHERE scheduled_trip_id = $1`, scheduledTripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip read markers: %w", err)
+	}
+
+	markers := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		markers[row.UserID] = row.LastReadAt
+	}
+	return markers, nil
+}
+
+// CountUnread counts messages from others posted after the user's read marker
+func (r *TripMessageRepository) CountUnread(scheduledTripID, userID string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `
+		SELECT COUNT(*)
+		FROM trip_messages m
+		LEFT JOIN trip_message_reads tr ON tr.scheduled_trip_id = m.scheduled_trip_id AND tr.user_id = $2
+		WHERE m.scheduled_trip_id = $1
+		  AND m.sender_user_id <> $2
+		  AND (tr.last_read_at IS NULL OR m.created_at > tr.last_read_at)`, scheduledTripID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread trip messages: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// tripPriceAdjustmentQuery selects adjustments with the fare approved on the trip's permit
+const tripPriceAdjustmentQuery = `
+	SELECT a.id, a.scheduled_trip_id, a.bus_owner_id, a.kind, a.mode, a.value, a.label,
+	       a.valid_from, a.valid_until, a.created_by, a.created_at, a.revoked_at,
+	       NULLIF(rp.approved_fare, 0) AS fare_cap
+	FROM trip_price_adjustments a
+	JOIN scheduled_trips st ON st.id = a.scheduled_trip_id
+	LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+	LEFT JOIN route_permits rp ON rp.id = COALESCE(st.permit_id, ts.permit_id)`
+
+// TripPriceAdjustmentRepository stores owners' trip surcharges and discounts
+type TripPriceAdjustmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripPriceAdjustmentRepository creates a new TripPriceAdjustmentRepository
+func NewTripPriceAdjustmentRepository(db *sqlx.DB) *TripPriceAdjustmentRepository {
+	return &TripPriceAdjustmentRepository{db: db}
+}
+
+// GetTrips returns the given trips with their owner, base fare and permit fare cap
+func (r *TripPriceAdjustmentRepository) GetTrips(tripIDs []string) ([]models.PriceAdjustmentTrip, error) {
+	trips := []models.PriceAdjustmentTrip{}
+	err := r.db.Select(&trips, `
+		SELECT st.id AS trip_id,
+		       COALESCE(ts.bus_owner_id, bor.bus_owner_id) AS bus_owner_id,
+		       st.status, st.departure_datetime, st.base_fare,
+		       NULLIF(rp.approved_fare, 0) AS fare_cap
+		FROM scheduled_trips st
+		LEFT JOIN trip_schedules ts ON ts.id = st.trip_schedule_id
+		LEFT JOIN bus_owner_routes bor ON bor.id = st.bus_owner_route_id
+		LEFT JOIN route_permits rp ON rp.id = COALESCE(st.permit_id, ts.permit_id)
+		WHERE st.id::text = ANY($1::text[])`, pq.Array(tripIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trips to adjust: %w", err)
+	}
+	return trips, nil
+}
+
+// ReplaceForTrips revokes the trips' current and upcoming adjustments and stores the new ones
+func (r *TripPriceAdjustmentRepository) ReplaceForTrips(adjustments []*models.TripPriceAdjustment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, a := range adjustments {
+		_, err := tx.Exec(`
+			UPDATE trip_price_adjustments
+			SET revoked_at = NOW()
+			WHERE scheduled_trip_id = $1 AND revoked_at IS NULL AND valid_until > NOW()`, a.ScheduledTripID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke previous adjustment: %w", err)
+		}
+
+		err = tx.QueryRowx(`
+			INSERT INTO trip_price_adjustments (
+				scheduled_trip_id, bus_owner_id, kind, mode, value, label, valid_from, valid_until, created_by
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id, created_at`,
+			a.ScheduledTripID, a.BusOwnerID, a.Kind, a.Mode, a.Value, a.Label, a.ValidFrom, a.ValidUntil, a.CreatedBy,
+		).Scan(&a.ID, &a.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create price adjustment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit price adjustments: %w", err)
+	}
+	return nil
+}
+
+// GetActiveForTrips returns the adjustment pricing each trip's bookings at the given time, keyed by trip ID
+func (r *TripPriceAdjustmentRepository) GetActiveForTrips(tripIDs []string, at time.Time) (map[string]*models.TripPriceAdjustment, error) {
+	active := make(map[string]*models.TripPriceAdjustment)
+	if len(tripIDs) == 0 {
+		return active, nil
+	}
+
+	adjustments := []models.TripPriceAdjustment{}
+	err := r.db.Select(&adjustments, tripPriceAdjustmentQuery+`
+		WHERE a.scheduled_trip_id::text = ANY($1::text[])
+		  AND a.revoked_at IS NULL AND a.valid_from <= $2 AND a.valid_until > $2
+		ORDER BY a.created_at`, pq.Array(tripIDs), at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active price adjustments: %w", err)
+	}
+	for i := range adjustments {
+		active[adjustments[i].ScheduledTripID] = &adjustments[i] // Latest wins
+	}
+	return active, nil
+}
+
+// ListForBusOwner returns the owner's adjustments that have not ended, soonest ending first
+func (r *TripPriceAdjustmentRepository) ListForBusOwner(busOwnerID string, tripID *string) ([]models.TripPriceAdjustment, error) {
+	adjustments := []models.TripPriceAdjustment{}
+	err := r.db.Select(&adjustments, tripPriceAdjustmentQuery+`
+		WHERE a.bus_owner_id = $1
+		  AND ($2::text IS NULL OR a.scheduled_trip_id::text = $2)
+		  AND a.revoked_at IS NULL AND a.valid_until > NOW()
+		ORDER BY a.valid_until, a.created_at`, busOwnerID, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price adjustments: %w", err)
+	}
+	return adjustments, nil
+}
+
+// Revoke ends one of the owner's adjustments now. Returns false if it doesn't exist, isn't the
+// owner's or has already ended.
+func (r *TripPriceAdjustmentRepository) Revoke(id, busOwnerID string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE trip_price_adjustments
+		SET revoked_at = NOW()
+		WHERE id::text = $1 AND bus_owner_id = $2 AND revoked_at IS NULL AND valid_until > NOW()`, id, busOwnerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke price adjustment: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -229,6 +229,42 @@ func (r *TripScheduleRepository) GetAllActiveTimetables() ([]models.TripSchedule
 	return r.scanTimetables(rows)
 }
 
+// GetPublishedTimetableByBusOwnerID retrieves an operator's active timetables that have
+// upcoming published trips, joined with route details (for public operator pages)
+func (r *TripScheduleRepository) GetPublishedTimetableByBusOwnerID(busOwnerID string) ([]models.PublishedTimetableEntry, error) {
+	query := `
+		SELECT ts.id AS schedule_id, bor.id AS route_id, bor.custom_route_name, bor.direction,
+			   mr.route_number, mr.origin_city, mr.destination_city,
+			   ts.departure_time::text AS departure_time, ts.recurrence_type,
+			   COALESCE(ts.recurrence_days, '') AS recurrence_days, ts.recurrence_interval,
+			   ts.estimated_duration_minutes, ts.base_fare, ts.valid_from, ts.valid_until,
+			   COALESCE(tsp.trips_measured, 0) AS trips_measured,
+			   COALESCE(tsp.on_time_trips, 0) AS on_time_trips,
+			   tsp.on_time_percentage
+		FROM trip_schedules ts
+		JOIN bus_owner_routes bor ON bor.id = ts.bus_owner_route_id
+		JOIN master_routes mr ON mr.id = bor.master_route_id
+		LEFT JOIN trip_schedule_punctuality tsp ON tsp.trip_schedule_id = ts.id
+		WHERE ts.bus_owner_id = $1
+		  AND ts.is_active = true
+		  AND (ts.valid_until IS NULL OR ts.valid_until >= CURRENT_DATE)
+		  AND EXISTS (
+			SELECT 1 FROM scheduled_trips st
+			WHERE st.trip_schedule_id = ts.id
+			  AND st.is_bookable = true
+			  AND st.status IN ('scheduled', 'confirmed')
+			  AND st.departure_datetime >= NOW()
+		  )
+		ORDER BY mr.route_number, bor.direction, ts.departure_time
+	`
+
+	entries := []models.PublishedTimetableEntry{}
+	if err := r.db.Select(&entries, query, busOwnerID); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 // GetActiveSchedulesForDate retrieves all active schedules for a specific date
 func (r *TripScheduleRepository) GetActiveSchedulesForDate(date time.Time) ([]models.TripSchedule, error) {
 	query := `
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// sqlExecer is satisfied by both *sqlx.DB and *sqlx.Tx
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// tripSeatCounterSyncSQL recomputes the cached seat counters on scheduled_trips from trip_seats
+// for the trips selected by idSource (a subquery returning a single "id" column)
+func tripSeatCounterSyncSQL(idSource string) string {
+	return `
+		UPDATE scheduled_trips st
+		SET available_seats = c.available_seats, booked_seats = c.booked_seats
+		FROM (
+			SELECT t.id,
+				COUNT(ts.id) FILTER (WHERE ts.status = 'available') AS available_seats,
+				COUNT(ts.id) FILTER (WHERE ts.status = 'booked') AS booked_seats
+			FROM (` + idSource + `) t
+			LEFT JOIN trip_seats ts ON ts.scheduled_trip_id = t.id
+			GROUP BY t.id
+		) c
+		WHERE st.id = c.id`
+}
+
+var (
+	syncTripSeatCountersByTripSQL = tripSeatCounterSyncSQL(`SELECT unnest($1::uuid[]) AS id`)
+	syncTripSeatCountersBySeatSQL = tripSeatCounterSyncSQL(`SELECT DISTINCT scheduled_trip_id AS id FROM trip_seats WHERE id = ANY($1::uuid[])`)
+)
+
+// syncTripSeatCounters refreshes scheduled_trips.available_seats/booked_seats for the given trips.
+// Every write that changes a trip seat's status calls it (inside the same transaction where
+// there is one) so the cached counters follow trip_seats.
+func syncTripSeatCounters(db sqlExecer, tripIDs ...string) error {
+	if len(tripIDs) == 0 {
+		return nil
+	}
+	if _, err := db.Exec(syncTripSeatCountersByTripSQL, pq.Array(tripIDs)); err != nil {
+		return fmt.Errorf("failed to sync trip seat counters: %w", err)
+	}
+	return nil
+}
+
+// syncTripSeatCountersForSeats refreshes the counters of the trips the given seats belong to
+func syncTripSeatCountersForSeats(db sqlExecer, seatIDs []string) error {
+	if len(seatIDs) == 0 {
+		return nil
+	}
+	if _, err := db.Exec(syncTripSeatCountersBySeatSQL, pq.Array(seatIDs)); err != nil {
+		return fmt.Errorf("failed to sync trip seat counters: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// COUNTER REPAIR
+// ============================================================================
+
+// FindSeatCounterDrift compares the cached counters of the trips matching the filter with
+// trip_seats and returns the trips that are off, plus how many trips were checked
+func (r *TripSeatRepository) FindSeatCounterDrift(filter models.SeatCounterRepairFilter) ([]models.TripSeatCounterDrift, int, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.ScheduledTripID != nil {
+		args = append(args, *filter.ScheduledTripID)
+		conditions = append(conditions, fmt.Sprintf("st.id = $%d", len(args)))
+	}
+	if filter.StartDate != nil {
+		args = append(args, *filter.StartDate)
+		conditions = append(conditions, fmt.Sprintf("DATE(st.departure_datetime) >= $%d", len(args)))
+	}
+	if filter.EndDate != nil {
+		args = append(args, *filter.EndDate)
+		conditions = append(conditions, fmt.Sprintf("DATE(st.departure_datetime) <= $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := `
+		WITH counters AS (
+			SELECT
+				st.id AS scheduled_trip_id,
+				st.departure_datetime,
+				st.available_seats AS cached_available_seats,
+				st.booked_seats AS cached_booked_seats,
+				COUNT(ts.id) FILTER (WHERE ts.status = 'available') AS actual_available_seats,
+				COUNT(ts.id) FILTER (WHERE ts.status = 'booked') AS actual_booked_seats
+			FROM scheduled_trips st
+			LEFT JOIN trip_seats ts ON ts.scheduled_trip_id = st.id
+			` + where + `
+			GROUP BY st.id
+		)
+		SELECT
+			scheduled_trip_id, departure_datetime,
+			cached_available_seats, actual_available_seats,
+			cached_booked_seats, actual_booked_seats,
+			actual_available_seats - COALESCE(cached_available_seats, 0) AS available_seats_delta,
+			actual_booked_seats - COALESCE(cached_booked_seats, 0) AS booked_seats_delta,
+			COUNT(*) OVER () AS trips_checked,
+			(cached_available_seats IS DISTINCT FROM actual_available_seats
+			 OR cached_booked_seats IS DISTINCT FROM actual_booked_seats) AS drifted
+		FROM counters
+		ORDER BY departure_datetime`
+
+	var rows []struct {
+		models.TripSeatCounterDrift
+		TripsChecked int  `db:"trips_checked"`
+		Drifted      bool `db:"drifted"`
+	}
+	if err := r.db.Select(&rows, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to compare trip seat counters: %w", err)
+	}
+
+	drift := []models.TripSeatCounterDrift{}
+	checked := 0
+	for _, row := range rows {
+		checked = row.TripsChecked
+		if row.Drifted {
+			drift = append(drift, row.TripSeatCounterDrift)
+		}
+	}
+	return drift, checked, nil
+}
+
+// SyncSeatCounters recomputes the cached counters of the given trips from trip_seats
+func (r *TripSeatRepository) SyncSeatCounters(tripIDs []string) error {
+	return syncTripSeatCounters(r.db, tripIDs...)
+}
+
+// tripIDsForBooking returns the trips of an app booking's bus legs
+func tripIDsForBooking(tx *sqlx.Tx, bookingID string) ([]string, error) {
+	var tripIDs []string
+	if err := tx.Select(&tripIDs, `SELECT scheduled_trip_id FROM bus_bookings WHERE booking_id = $1`, bookingID); err != nil {
+		return nil, fmt.Errorf("failed to get booking trips: %w", err)
+	}
+	return tripIDs, nil
+}
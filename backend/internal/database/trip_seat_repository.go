@@ -85,6 +85,10 @@ func (r *TripSeatRepository) CreateTripSeatsFromLayout(scheduledTripID, seatLayo
 		count++
 	}
 
+	if err := syncTripSeatCounters(r.db, scheduledTripID); err != nil {
+		return count, err
+	}
+
 	return count, nil
 }
 
@@ -238,6 +242,9 @@ func (r *TripSeatRepository) BlockSeats(seatIDs []string, blockedByUserID, reaso
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	if err := syncTripSeatCountersForSeats(r.db, seatIDs); err != nil {
+		return int(rowsAffected), err
+	}
 	return int(rowsAffected), nil
 }
 
@@ -268,10 +275,14 @@ func (r *TripSeatRepository) UnblockSeats(seatIDs []string) (int, error) {
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	if err := syncTripSeatCountersForSeats(r.db, seatIDs); err != nil {
+		return int(rowsAffected), err
+	}
 	return int(rowsAffected), nil
 }
 
 // UpdateSeatPrices updates the price for multiple seats
+// Seats priced by hand are left alone by dynamic pricing rules
 func (r *TripSeatRepository) UpdateSeatPrices(seatIDs []string, newPrice float64) (int, error) {
 	if len(seatIDs) == 0 {
 		return 0, nil
@@ -280,6 +291,7 @@ func (r *TripSeatRepository) UpdateSeatPrices(seatIDs []string, newPrice float64
 	query, args, err := sqlx.In(`
 		UPDATE trip_seats
 		SET seat_price = ?,
+			price_overridden = TRUE,
 			updated_at = ?
 		WHERE id IN (?)
 	`, newPrice, time.Now(), seatIDs)
@@ -322,6 +334,9 @@ func (r *TripSeatRepository) BookSeatsForManualBooking(seatIDs []string, manualB
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	if err := syncTripSeatCountersForSeats(r.db, seatIDs); err != nil {
+		return err
+	}
 	if int(rowsAffected) != len(seatIDs) {
 		return fmt.Errorf("some seats are not available, expected %d, updated %d", len(seatIDs), rowsAffected)
 	}
@@ -331,6 +346,12 @@ func (r *TripSeatRepository) BookSeatsForManualBooking(seatIDs []string, manualB
 
 // ReleaseSeatsFromManualBooking releases seats when a manual booking is cancelled
 func (r *TripSeatRepository) ReleaseSeatsFromManualBooking(manualBookingID string) error {
+	var tripIDs []string
+	err := r.db.Select(&tripIDs, `SELECT DISTINCT scheduled_trip_id FROM trip_seats WHERE manual_booking_id = $1`, manualBookingID)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE trip_seats
 		SET status = 'available',
@@ -340,8 +361,10 @@ func (r *TripSeatRepository) ReleaseSeatsFromManualBooking(manualBookingID strin
 		WHERE manual_booking_id = $2
 	`
 
-	_, err := r.db.Exec(query, time.Now(), manualBookingID)
-	return err
+	if _, err := r.db.Exec(query, time.Now(), manualBookingID); err != nil {
+		return err
+	}
+	return syncTripSeatCounters(r.db, tripIDs...)
 }
 
 // CheckSeatsAvailable checks if all specified seats are available
@@ -371,8 +394,10 @@ func (r *TripSeatRepository) CheckSeatsAvailable(seatIDs []string) (bool, error)
 
 // DeleteByScheduledTripID deletes all trip seats for a scheduled trip
 func (r *TripSeatRepository) DeleteByScheduledTripID(scheduledTripID string) error {
-	_, err := r.db.Exec(`DELETE FROM trip_seats WHERE scheduled_trip_id = $1`, scheduledTripID)
-	return err
+	if _, err := r.db.Exec(`DELETE FROM trip_seats WHERE scheduled_trip_id = $1`, scheduledTripID); err != nil {
+		return err
+	}
+	return syncTripSeatCounters(r.db, scheduledTripID)
 }
 
 // GetAvailableSeats returns only available seats for a trip
@@ -393,3 +418,54 @@ func (r *TripSeatRepository) GetAvailableSeats(scheduledTripID string) ([]models
 
 	return seats, nil
 }
+
+// GetSegmentSeats returns a trip's seats with the stop orders their booking boards and alights
+// at. Bookings made before stop orders were kept take them from their stops' current order.
+func (r *TripSeatRepository) GetSegmentSeats(scheduledTripID string) ([]models.SegmentSeat, error) {
+	query := `
+		SELECT ts.id, ts.seat_number, ts.seat_type, ts.row_number, ts.position,
+			   ts.scheduled_trip_id, ts.seat_price, ts.status,
+			   (ts.held_by_intent_id IS NOT NULL AND ts.held_until > NOW()) AS held,
+			   COALESCE(bb.boarding_stop_order, bb_board.stop_order, msb.boarding_stop_order, msb_board.stop_order) AS booked_from_order,
+			   COALESCE(bb.alighting_stop_order, bb_alight.stop_order, msb.alighting_stop_order, msb_alight.stop_order) AS booked_to_order
+		FROM trip_seats ts
+		LEFT JOIN bus_booking_seats bbs ON bbs.id = ts.bus_booking_seat_id
+		LEFT JOIN bus_bookings bb ON bb.id = bbs.bus_booking_id
+		LEFT JOIN master_route_stops bb_board ON bb_board.id = bb.boarding_stop_id
+		LEFT JOIN master_route_stops bb_alight ON bb_alight.id = bb.alighting_stop_id
+		LEFT JOIN manual_seat_bookings msb ON msb.id = ts.manual_booking_id
+		LEFT JOIN master_route_stops msb_board ON msb_board.id = msb.boarding_stop_id
+		LEFT JOIN master_route_stops msb_alight ON msb_alight.id = msb.alighting_stop_id
+		WHERE ts.scheduled_trip_id = $1
+		ORDER BY ts.row_number, ts.position
+	`
+
+	seats := []models.SegmentSeat{}
+	if err := r.db.Select(&seats, query, scheduledTripID); err != nil {
+		return nil, fmt.Errorf("failed to get segment seats: %w", err)
+	}
+	return seats, nil
+}
+
+// GetTripStops returns the stops a trip calls at in stop order: its own or its route's selected
+// stops, or all of its master route's
+func (r *TripSeatRepository) GetTripStops(scheduledTripID string) ([]models.MasterRouteStop, error) {
+	query := `
+		SELECT mrs.id, mrs.master_route_id, mrs.stop_name, mrs.stop_order, mrs.latitude, mrs.longitude,
+			   mrs.arrival_time_offset_minutes, mrs.is_major_stop, mrs.created_at
+		FROM scheduled_trips st` + gtfsTripJoins + `
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(NULLIF(st.selected_stop_ids::text[], '{}'), NULLIF(bor.selected_stop_ids::text[], '{}')) AS ids
+		) selected
+		JOIN master_route_stops mrs ON mrs.master_route_id = mr.id
+			AND (selected.ids IS NULL OR mrs.id::text = ANY(selected.ids))
+		WHERE st.id = $1
+		ORDER BY mrs.stop_order
+	`
+
+	stops := []models.MasterRouteStop{}
+	if err := r.db.Select(&stops, query, scheduledTripID); err != nil {
+		return nil, fmt.Errorf("failed to get trip stops: %w", err)
+	}
+	return stops, nil
+}
@@ -0,0 +1,277 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripSharingRepository handles emergency contacts, trip share tokens and trip incidents
+type TripSharingRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripSharingRepository creates a new TripSharingRepository
+func NewTripSharingRepository(db *sqlx.DB) *TripSharingRepository {
+	return &TripSharingRepository{db: db}
+}
+
+// sharedTripBusBookingStatuses are bus booking statuses of passengers still travelling on a trip
+const sharedTripBusBookingStatuses = `('pending', 'confirmed', 'checked_in', 'boarded', 'in_transit')`
+
+// ============================================================================
+// EMERGENCY CONTACTS
+// ============================================================================
+
+const emergencyContactColumns = `id, user_id, name, phone, relationship, auto_share_trips, created_at, updated_at`
+
+// ListEmergencyContacts returns a passenger's emergency contacts, oldest first
+func (r *TripSharingRepository) ListEmergencyContacts(userID string) ([]models.EmergencyContact, error) {
+	contacts := []models.EmergencyContact{}
+	err := r.db.Select(&contacts, `
+		SELECT `+emergencyContactColumns+`
+		FROM passenger_emergency_contacts
+		WHERE user_id = $1
+		ORDER BY created_at ASC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list emergency contacts: %w", err)
+	}
+	return contacts, nil
+}
+
+// CountEmergencyContacts counts a passenger's emergency contacts
+func (r *TripSharingRepository) CountEmergencyContacts(userID string) (int, error) {
+	var count int
+	if err := r.db.Get(&count, `SELECT COUNT(*) FROM passenger_emergency_contacts WHERE user_id = $1`, userID); err != nil {
+		return 0, fmt.Errorf("failed to count emergency contacts: %w", err)
+	}
+	return count, nil
+}
+
+// GetEmergencyContact returns a contact owned by the user; returns nil if not found
+func (r *TripSharingRepository) GetEmergencyContact(id, userID string) (*models.EmergencyContact, error) {
+	var contact models.EmergencyContact
+	err := r.db.Get(&contact, `
+		SELECT `+emergencyContactColumns+`
+		FROM passenger_emergency_contacts
+		WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get emergency contact: %w", err)
+	}
+	return &contact, nil
+}
+
+// CreateEmergencyContact stores a new emergency contact
+func (r *TripSharingRepository) CreateEmergencyContact(contact *models.EmergencyContact) error {
+	contact.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO passenger_emergency_contacts (
+			id, user_id, name, phone, relationship, auto_share_trips, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING created_at, updated_at`,
+		contact.ID, contact.UserID, contact.Name, contact.Phone, contact.Relationship, contact.AutoShareTrips,
+	).Scan(&contact.CreatedAt, &contact.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create emergency contact: %w", err)
+	}
+	return nil
+}
+
+// UpdateEmergencyContact saves all editable fields of a contact
+func (r *TripSharingRepository) UpdateEmergencyContact(contact *models.EmergencyContact) error {
+	err := r.db.QueryRow(`
+		UPDATE passenger_emergency_contacts
+		SET name = $1, phone = $2, relationship = $3, auto_share_trips = $4, updated_at = NOW()
+		WHERE id = $5 AND user_id = $6
+		RETURNING updated_at`,
+		contact.Name, contact.Phone, contact.Relationship, contact.AutoShareTrips, contact.ID, contact.UserID,
+	).Scan(&contact.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update emergency contact: %w", err)
+	}
+	return nil
+}
+
+// DeleteEmergencyContact removes a contact. Returns false if it was not found.
+func (r *TripSharingRepository) DeleteEmergencyContact(id, userID string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM passenger_emergency_contacts WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete emergency contact: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetTripShareRecipients returns the emergency contacts of every passenger still booked on a trip
+func (r *TripSharingRepository) GetTripShareRecipients(scheduledTripID string) ([]models.TripShareRecipient, error) {
+	recipients := []models.TripShareRecipient{}
+	err := r.db.Select(&recipients, `
+		SELECT DISTINCT
+			ec.user_id,
+			COALESCE(p.first_name, b.passenger_name) as passenger_name,
+			ec.name as contact_name,
+			ec.phone as contact_phone,
+			ec.auto_share_trips
+		FROM bus_bookings bb
+		JOIN bookings b ON bb.booking_id = b.id
+		JOIN passenger_emergency_contacts ec ON ec.user_id = b.user_id
+		LEFT JOIN passengers p ON p.user_id = b.user_id
+		WHERE bb.scheduled_trip_id = $1
+		  AND b.booking_status = 'confirmed'
+		  AND bb.status IN `+sharedTripBusBookingStatuses, scheduledTripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip share recipients: %w", err)
+	}
+	return recipients, nil
+}
+
+// IsPassengerBookedOnTrip checks whether the user holds a live booking on the trip
+func (r *TripSharingRepository) IsPassengerBookedOnTrip(userID, scheduledTripID string) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM bus_bookings bb
+			JOIN bookings b ON bb.booking_id = b.id
+			WHERE b.user_id = $1
+			  AND bb.scheduled_trip_id = $2
+			  AND b.booking_status = 'confirmed'
+			  AND bb.status IN `+sharedTripBusBookingStatuses+`
+		)`, userID, scheduledTripID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check trip booking: %w", err)
+	}
+	return exists, nil
+}
+
+// ============================================================================
+// SHARE TOKENS
+// ============================================================================
+
+const tripShareTokenColumns = `id, token, user_id, scheduled_trip_id, expires_at, revoked_at, created_at`
+
+// CreateShareToken stores a new share token
+func (r *TripSharingRepository) CreateShareToken(token *models.TripShareToken) error {
+	token.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO trip_share_tokens (id, token, user_id, scheduled_trip_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at`,
+		token.ID, token.Token, token.UserID, token.ScheduledTripID, token.ExpiresAt,
+	).Scan(&token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create trip share token: %w", err)
+	}
+	return nil
+}
+
+// GetActiveShareToken returns the user's newest unexpired token for a trip; returns nil if none
+func (r *TripSharingRepository) GetActiveShareToken(userID, scheduledTripID string) (*models.TripShareToken, error) {
+	var token models.TripShareToken
+	err := r.db.Get(&token, `
+		SELECT `+tripShareTokenColumns+`
+		FROM trip_share_tokens
+		WHERE user_id = $1 AND scheduled_trip_id = $2
+		  AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+		LIMIT 1`, userID, scheduledTripID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get trip share token: %w", err)
+	}
+	return &token, nil
+}
+
+// RevokeShareTokens revokes all of a user's tokens for a trip
+func (r *TripSharingRepository) RevokeShareTokens(userID, scheduledTripID string) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE trip_share_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND scheduled_trip_id = $2 AND revoked_at IS NULL`, userID, scheduledTripID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke trip share tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetSharedTripView resolves an active token to its public tracking data; returns nil if the
+// token is unknown, revoked or expired
+func (r *TripSharingRepository) GetSharedTripView(token string) (*models.SharedTripView, error) {
+	var view models.SharedTripView
+	err := r.db.Get(&view, `
+		SELECT
+			st.id as scheduled_trip_id,
+			p.first_name as passenger_first_name,
+			COALESCE(mr.route_name, bor.custom_route_name, 'Unknown Route') as route_name,
+			st.departure_datetime,
+			at.status as trip_status,
+			at.current_latitude,
+			at.current_longitude,
+			at.current_speed_kmh,
+			at.heading,
+			at.last_location_update,
+			at.estimated_arrival_time,
+			t.expires_at as link_expires_at
+		FROM trip_share_tokens t
+		JOIN scheduled_trips st ON t.scheduled_trip_id = st.id
+		LEFT JOIN passengers p ON p.user_id = t.user_id
+		LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+		LEFT JOIN master_routes mr ON bor.master_route_id = mr.id
+		LEFT JOIN active_trips at ON at.scheduled_trip_id = st.id
+		WHERE t.token = $1 AND t.revoked_at IS NULL AND t.expires_at > $2`, token, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get shared trip: %w", err)
+	}
+	return &view, nil
+}
+
+// ============================================================================
+// INCIDENTS
+// ============================================================================
+
+// CreateIncident stores an incident flagged on an active trip
+func (r *TripSharingRepository) CreateIncident(incident *models.TripIncident) error {
+	incident.ID = uuid.New().String()
+	err := r.db.QueryRow(`
+		INSERT INTO trip_incidents (
+			id, active_trip_id, scheduled_trip_id, reported_by_staff_id,
+			incident_type, description, latitude, longitude, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at`,
+		incident.ID, incident.ActiveTripID, incident.ScheduledTripID, incident.ReportedByStaffID,
+		incident.IncidentType, incident.Description, incident.Latitude, incident.Longitude,
+	).Scan(&incident.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create trip incident: %w", err)
+	}
+	return nil
+}
+
+// ListIncidents returns the incidents of a scheduled trip, newest first
+func (r *TripSharingRepository) ListIncidents(scheduledTripID string) ([]models.TripIncident, error) {
+	incidents := []models.TripIncident{}
+	err := r.db.Select(&incidents, `
+		SELECT id, active_trip_id, scheduled_trip_id, reported_by_staff_id,
+		       incident_type, description, latitude, longitude, created_at
+		FROM trip_incidents
+		WHERE scheduled_trip_id = $1
+		ORDER BY created_at DESC`, scheduledTripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trip incidents: %w", err)
+	}
+	return incidents, nil
+}
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripWaitingRoomRepository handles trip_waiting_rooms and their trip_queue_tokens
+type TripWaitingRoomRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripWaitingRoomRepository creates a new TripWaitingRoomRepository
+func NewTripWaitingRoomRepository(db *sqlx.DB) *TripWaitingRoomRepository {
+	return &TripWaitingRoomRepository{db: db}
+}
+
+const waitingRoomColumns = `
+	scheduled_trip_id, is_enabled, batch_size, admit_interval_seconds,
+	admission_window_seconds, idle_timeout_seconds, last_admitted_at, created_at, updated_at`
+
+const queueTokenColumns = `
+	id, scheduled_trip_id, user_id, status, last_seen_at,
+	admitted_at, admission_expires_at, created_at`
+
+// ============================================================================
+// WAITING ROOMS
+// ============================================================================
+
+// GetRoom returns a trip's waiting room; returns nil if the trip never had one
+func (r *TripWaitingRoomRepository) GetRoom(scheduledTripID string) (*models.TripWaitingRoom, error) {
+	var room models.TripWaitingRoom
+	err := r.db.Get(&room, `SELECT `+waitingRoomColumns+` FROM trip_waiting_rooms WHERE scheduled_trip_id = $1`, scheduledTripID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waiting room: %w", err)
+	}
+	return &room, nil
+}
+
+// UpsertRoom creates or updates a trip's waiting room settings
+func (r *TripWaitingRoomRepository) UpsertRoom(room *models.TripWaitingRoom) error {
+	query := `
+		INSERT INTO trip_waiting_rooms (
+			scheduled_trip_id, is_enabled, batch_size, admit_interval_seconds,
+			admission_window_seconds, idle_timeout_seconds
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (scheduled_trip_id) DO UPDATE SET
+			is_enabled = EXCLUDED.is_enabled,
+			batch_size = EXCLUDED.batch_size,
+			admit_interval_seconds = EXCLUDED.admit_interval_seconds,
+			admission_window_seconds = EXCLUDED.admission_window_seconds,
+			idle_timeout_seconds = EXCLUDED.idle_timeout_seconds,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(query,
+		room.ScheduledTripID, room.IsEnabled, room.BatchSize, room.AdmitIntervalSeconds,
+		room.AdmissionWindowSeconds, room.IdleTimeoutSeconds,
+	).Scan(&room.CreatedAt, &room.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save waiting room: %w", err)
+	}
+	return nil
+}
+
+// IsTripOwnedBy reports whether the trip belongs to the bus owner, via its schedule or route
+func (r *TripWaitingRoomRepository) IsTripOwnedBy(scheduledTripID, busOwnerID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM scheduled_trips st
+			LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+			LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+			WHERE st.id = $1
+			  AND (ts.bus_owner_id = $2 OR bor.bus_owner_id = $2)
+		)
+	`
+	var owned bool
+	if err := r.db.Get(&owned, query, scheduledTripID, busOwnerID); err != nil {
+		return false, fmt.Errorf("failed to check trip ownership: %w", err)
+	}
+	return owned, nil
+}
+
+// GetRoomsDueForAdmission returns enabled rooms of upcoming trips whose next batch is due
+func (r *TripWaitingRoomRepository) GetRoomsDueForAdmission() ([]models.TripWaitingRoom, error) {
+	query := `
+		SELECT wr.scheduled_trip_id, wr.is_enabled, wr.batch_size, wr.admit_interval_seconds,
+			   wr.admission_window_seconds, wr.idle_timeout_seconds, wr.last_admitted_at,
+			   wr.created_at, wr.updated_at
+		FROM trip_waiting_rooms wr
+		JOIN scheduled_trips st ON st.id = wr.scheduled_trip_id
+		WHERE wr.is_enabled = true
+		  AND st.departure_datetime > NOW()
+		  AND (wr.last_admitted_at IS NULL
+		       OR wr.last_admitted_at <= NOW() - wr.admit_interval_seconds * INTERVAL '1 second')
+	`
+	rooms := []models.TripWaitingRoom{}
+	if err := r.db.Select(&rooms, query); err != nil {
+		return nil, fmt.Errorf("failed to get waiting rooms due for admission: %w", err)
+	}
+	return rooms, nil
+}
+
+// AdmitNextBatch admits the longest-waiting users of a room, keeping at most batch_size users
+// admitted at once. Returns how many were admitted.
+func (r *TripWaitingRoomRepository) AdmitNextBatch(room *models.TripWaitingRoom) (int, error) {
+	query := `
+		WITH slots AS (
+			SELECT GREATEST($2::int - COUNT(*), 0) AS free
+			FROM trip_queue_tokens
+			WHERE scheduled_trip_id = $1 AND status = 'admitted' AND admission_expires_at > NOW()
+		), next AS (
+			SELECT id FROM trip_queue_tokens
+			WHERE scheduled_trip_id = $1 AND status = 'waiting'
+			ORDER BY created_at, id
+			LIMIT (SELECT free FROM slots)
+			FOR UPDATE SKIP LOCKED
+		), admitted AS (
+			UPDATE trip_queue_tokens q
+			SET status = 'admitted', admitted_at = NOW(),
+				admission_expires_at = NOW() + $3::int * INTERVAL '1 second'
+			FROM next
+			WHERE q.id = next.id
+			RETURNING q.id
+		), stamped AS (
+			UPDATE trip_waiting_rooms SET last_admitted_at = NOW() WHERE scheduled_trip_id = $1
+		)
+		SELECT COUNT(*) FROM admitted
+	`
+	var admitted int
+	if err := r.db.Get(&admitted, query, room.ScheduledTripID, room.BatchSize, room.AdmissionWindowSeconds); err != nil {
+		return 0, fmt.Errorf("failed to admit queue batch: %w", err)
+	}
+	return admitted, nil
+}
+
+// ExpireStaleTokens expires waiting tokens that stopped polling and admissions that were not used in time
+func (r *TripWaitingRoomRepository) ExpireStaleTokens() (int64, error) {
+	query := `
+		UPDATE trip_queue_tokens q
+		SET status = 'expired'
+		FROM trip_waiting_rooms wr
+		WHERE wr.scheduled_trip_id = q.scheduled_trip_id
+		  AND (
+			(q.status = 'waiting' AND q.last_seen_at < NOW() - wr.idle_timeout_seconds * INTERVAL '1 second')
+			OR (q.status = 'admitted' AND q.admission_expires_at <= NOW())
+		  )
+	`
+	result, err := r.db.Exec(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire queue tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ============================================================================
+// QUEUE TOKENS
+// ============================================================================
+
+// JoinQueue returns the user's live token for the trip, issuing one at the back of the queue if
+// they have none. Rejoining keeps the original place.
+func (r *TripWaitingRoomRepository) JoinQueue(scheduledTripID, userID string) (*models.TripQueueToken, error) {
+	query := `
+		INSERT INTO trip_queue_tokens (scheduled_trip_id, user_id, status, last_seen_at)
+		VALUES ($1, $2, 'waiting', NOW())
+		ON CONFLICT (scheduled_trip_id, user_id) WHERE status IN ('waiting', 'admitted')
+		DO UPDATE SET last_seen_at = NOW()
+		RETURNING ` + queueTokenColumns
+	var token models.TripQueueToken
+	if err := r.db.Get(&token, query, scheduledTripID, userID); err != nil {
+		return nil, fmt.Errorf("failed to join queue: %w", err)
+	}
+	return &token, nil
+}
+
+// TouchToken records a poll and returns the token; returns nil if it does not belong to the user
+func (r *TripWaitingRoomRepository) TouchToken(tokenID, userID string) (*models.TripQueueToken, error) {
+	query := `
+		UPDATE trip_queue_tokens
+		SET last_seen_at = CASE WHEN status = 'waiting' THEN NOW() ELSE last_seen_at END
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + queueTokenColumns
+	var token models.TripQueueToken
+	err := r.db.Get(&token, query, tokenID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue token: %w", err)
+	}
+	return &token, nil
+}
+
+// GetToken returns a queue token; returns nil if not found
+func (r *TripWaitingRoomRepository) GetToken(tokenID string) (*models.TripQueueToken, error) {
+	var token models.TripQueueToken
+	err := r.db.Get(&token, `SELECT `+queueTokenColumns+` FROM trip_queue_tokens WHERE id = $1`, tokenID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue token: %w", err)
+	}
+	return &token, nil
+}
+
+// CountAhead returns how many waiting users joined the trip's queue before the token
+func (r *TripWaitingRoomRepository) CountAhead(token *models.TripQueueToken) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM trip_queue_tokens
+		WHERE scheduled_trip_id = $1 AND status = 'waiting'
+		  AND (created_at, id) < ($2, $3::uuid)
+	`
+	var ahead int
+	if err := r.db.Get(&ahead, query, token.ScheduledTripID, token.CreatedAt, token.ID); err != nil {
+		return 0, fmt.Errorf("failed to get queue position: %w", err)
+	}
+	return ahead, nil
+}
+
+// MarkTokenUsed closes an admitted token once an intent has been created with it
+func (r *TripWaitingRoomRepository) MarkTokenUsed(tokenID string) error {
+	_, err := r.db.Exec(`UPDATE trip_queue_tokens SET status = 'used' WHERE id = $1 AND status = 'admitted'`, tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to mark queue token used: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TripWaitlistRepository handles trip_waitlist_entries
+type TripWaitlistRepository struct {
+	db *sqlx.DB
+}
+
+// NewTripWaitlistRepository creates a new TripWaitlistRepository
+func NewTripWaitlistRepository(db *sqlx.DB) *TripWaitlistRepository {
+	return &TripWaitlistRepository{db: db}
+}
+
+const waitlistEntryColumns = `
+	id, scheduled_trip_id, user_id, seat_count, status, offered_at,
+	offer_expires_at, accepted_intent_id, created_at, updated_at`
+
+// IsTripOpen reports whether the trip is bookable and has not departed, so it can take a waitlist
+func (r *TripWaitlistRepository) IsTripOpen(scheduledTripID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM scheduled_trips
+			WHERE id = $1 AND is_bookable = true AND departure_datetime > NOW()
+			  AND status NOT IN ('cancelled', 'completed')
+		)
+	`
+	var open bool
+	if err := r.db.Get(&open, query, scheduledTripID); err != nil {
+		return false, fmt.Errorf("failed to check trip: %w", err)
+	}
+	return open, nil
+}
+
+// IsTripOwnedBy reports whether the trip belongs to the bus owner, via its schedule or route
+func (r *TripWaitlistRepository) IsTripOwnedBy(scheduledTripID, busOwnerID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM scheduled_trips st
+			LEFT JOIN trip_schedules ts ON st.trip_schedule_id = ts.id
+			LEFT JOIN bus_owner_routes bor ON st.bus_owner_route_id = bor.id
+			WHERE st.id = $1
+			  AND (ts.bus_owner_id = $2 OR bor.bus_owner_id = $2)
+		)
+	`
+	var owned bool
+	if err := r.db.Get(&owned, query, scheduledTripID, busOwnerID); err != nil {
+		return false, fmt.Errorf("failed to check trip ownership: %w", err)
+	}
+	return owned, nil
+}
+
+// CountFreeSeats returns how many of the trip's seats are available and not held by an intent
+func (r *TripWaitlistRepository) CountFreeSeats(scheduledTripID string) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM trip_seats
+		WHERE scheduled_trip_id = $1 AND status = 'available'
+		  AND (held_by_intent_id IS NULL OR held_until < NOW())
+	`
+	var free int
+	if err := r.db.Get(&free, query, scheduledTripID); err != nil {
+		return 0, fmt.Errorf("failed to count free seats: %w", err)
+	}
+	return free, nil
+}
+
+// CountOfferedSeats returns how many seats are kept for the trip's open offers
+func (r *TripWaitlistRepository) CountOfferedSeats(scheduledTripID string) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(seat_count), 0) FROM trip_waitlist_entries
+		WHERE scheduled_trip_id = $1 AND status = 'offered' AND offer_expires_at > NOW()
+	`
+	var offered int
+	if err := r.db.Get(&offered, query, scheduledTripID); err != nil {
+		return 0, fmt.Errorf("failed to count offered seats: %w", err)
+	}
+	return offered, nil
+}
+
+// Join puts the user on the trip's waitlist, or returns their live entry if they are already on
+// it. Rejoining keeps the original place.
+func (r *TripWaitlistRepository) Join(scheduledTripID, userID string, seatCount int) (*models.TripWaitlistEntry, error) {
+	query := `
+		INSERT INTO trip_waitlist_entries (scheduled_trip_id, user_id, seat_count, status)
+		VALUES ($1, $2, $3, 'waiting')
+		ON CONFLICT (scheduled_trip_id, user_id) WHERE status IN ('waiting', 'offered')
+		DO UPDATE SET updated_at = trip_waitlist_entries.updated_at
+		RETURNING ` + waitlistEntryColumns
+	var entry models.TripWaitlistEntry
+	if err := r.db.Get(&entry, query, scheduledTripID, userID, seatCount); err != nil {
+		return nil, fmt.Errorf("failed to join waitlist: %w", err)
+	}
+	return &entry, nil
+}
+
+// GetLiveEntry returns the user's waiting or offered entry for the trip; returns nil if they have none
+func (r *TripWaitlistRepository) GetLiveEntry(scheduledTripID, userID string) (*models.TripWaitlistEntry, error) {
+	query := `SELECT ` + waitlistEntryColumns + ` FROM trip_waitlist_entries
+		WHERE scheduled_trip_id = $1 AND user_id = $2 AND status IN ('waiting', 'offered')`
+	var entry models.TripWaitlistEntry
+	err := r.db.Get(&entry, query, scheduledTripID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// GetLatestEntry returns the user's most recent entry for the trip, whatever its status;
+// returns nil if they never joined
+func (r *TripWaitlistRepository) GetLatestEntry(scheduledTripID, userID string) (*models.TripWaitlistEntry, error) {
+	query := `SELECT ` + waitlistEntryColumns + ` FROM trip_waitlist_entries
+		WHERE scheduled_trip_id = $1 AND user_id = $2
+		ORDER BY created_at DESC LIMIT 1`
+	var entry models.TripWaitlistEntry
+	err := r.db.Get(&entry, query, scheduledTripID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// CountAhead returns how many waiting entries joined the trip's waitlist before the entry
+func (r *TripWaitlistRepository) CountAhead(entry *models.TripWaitlistEntry) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM trip_waitlist_entries
+		WHERE scheduled_trip_id = $1 AND status = 'waiting'
+		  AND (created_at, id) < ($2, $3::uuid)
+	`
+	var ahead int
+	if err := r.db.Get(&ahead, query, entry.ScheduledTripID, entry.CreatedAt, entry.ID); err != nil {
+		return 0, fmt.Errorf("failed to get waitlist position: %w", err)
+	}
+	return ahead, nil
+}
+
+// Leave cancels the user's live entry for the trip. Returns false if they had none.
+func (r *TripWaitlistRepository) Leave(scheduledTripID, userID string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE trip_waitlist_entries SET status = 'cancelled', updated_at = NOW()
+		WHERE scheduled_trip_id = $1 AND user_id = $2 AND status IN ('waiting', 'offered')
+	`, scheduledTripID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to leave waitlist: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// MarkAccepted closes an open offer once an intent has been created with it
+func (r *TripWaitlistRepository) MarkAccepted(entryID, intentID string) error {
+	_, err := r.db.Exec(`
+		UPDATE trip_waitlist_entries
+		SET status = 'accepted', accepted_intent_id = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'offered'
+	`, entryID, intentID)
+	if err != nil {
+		return fmt.Errorf("failed to mark waitlist offer accepted: %w", err)
+	}
+	return nil
+}
+
+// GetWaiting returns the trip's waiting entries in line order
+func (r *TripWaitlistRepository) GetWaiting(scheduledTripID string) ([]models.TripWaitlistEntry, error) {
+	query := `SELECT ` + waitlistEntryColumns + ` FROM trip_waitlist_entries
+		WHERE scheduled_trip_id = $1 AND status = 'waiting'
+		ORDER BY created_at, id`
+	entries := []models.TripWaitlistEntry{}
+	if err := r.db.Select(&entries, query, scheduledTripID); err != nil {
+		return nil, fmt.Errorf("failed to get waiting entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Offer gives the waiting entries an offer that lasts until expiresAt and returns those offered.
+// Entries that left the line meanwhile are skipped.
+func (r *TripWaitlistRepository) Offer(entryIDs []string, expiresAt time.Time) ([]models.TripWaitlistEntry, error) {
+	if len(entryIDs) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In(`
+		UPDATE trip_waitlist_entries
+		SET status = 'offered', offered_at = NOW(), offer_expires_at = ?, updated_at = NOW()
+		WHERE id IN (?) AND status = 'waiting'
+		RETURNING `+waitlistEntryColumns, expiresAt, entryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build offer query: %w", err)
+	}
+	offered := []models.TripWaitlistEntry{}
+	if err := r.db.Select(&offered, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to offer waitlist seats: %w", err)
+	}
+	return offered, nil
+}
+
+// ExpireStale expires offers that were not booked in time and every live entry of trips that
+// departed or were cancelled
+func (r *TripWaitlistRepository) ExpireStale() (int64, error) {
+	query := `
+		UPDATE trip_waitlist_entries w
+		SET status = 'expired', updated_at = NOW()
+		FROM scheduled_trips st
+		WHERE st.id = w.scheduled_trip_id
+		  AND (
+			(w.status = 'offered' AND w.offer_expires_at <= NOW())
+			OR (w.status IN ('waiting', 'offered')
+			    AND (st.departure_datetime <= NOW() OR st.status IN ('cancelled', 'completed')))
+		  )
+	`
+	result, err := r.db.Exec(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire waitlist entries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetTripsWithWaiting returns upcoming trips that have passengers waiting
+func (r *TripWaitlistRepository) GetTripsWithWaiting() ([]string, error) {
+	query := `
+		SELECT DISTINCT w.scheduled_trip_id
+		FROM trip_waitlist_entries w
+		JOIN scheduled_trips st ON st.id = w.scheduled_trip_id
+		WHERE w.status = 'waiting' AND st.departure_datetime > NOW()
+	`
+	tripIDs := []string{}
+	if err := r.db.Select(&tripIDs, query); err != nil {
+		return nil, fmt.Errorf("failed to get trips with waitlists: %w", err)
+	}
+	return tripIDs, nil
+}
+
+// GetCounts returns the trip's entries and seats by status
+func (r *TripWaitlistRepository) GetCounts(scheduledTripID string) (*models.TripWaitlistCounts, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'waiting') AS waiting_entries,
+			COALESCE(SUM(seat_count) FILTER (WHERE status = 'waiting'), 0) AS waiting_seats,
+			COUNT(*) FILTER (WHERE status = 'offered' AND offer_expires_at > NOW()) AS offered_entries,
+			COALESCE(SUM(seat_count) FILTER (WHERE status = 'offered' AND offer_expires_at > NOW()), 0) AS offered_seats,
+			COUNT(*) FILTER (WHERE status = 'accepted') AS accepted_entries,
+			COUNT(*) FILTER (WHERE status = 'expired') AS expired_entries
+		FROM trip_waitlist_entries
+		WHERE scheduled_trip_id = $1
+	`
+	var counts models.TripWaitlistCounts
+	if err := r.db.Get(&counts, query, scheduledTripID); err != nil {
+		return nil, fmt.Errorf("failed to count waitlist entries: %w", err)
+	}
+	return &counts, nil
+}
+
+// GetLine returns the trip's waiting and offered entries, offered first, each in line order
+func (r *TripWaitlistRepository) GetLine(scheduledTripID string) ([]models.TripWaitlistOwnerEntry, error) {
+	query := `
+		SELECT seat_count, status, offer_expires_at, created_at
+		FROM trip_waitlist_entries
+		WHERE scheduled_trip_id = $1 AND status IN ('waiting', 'offered')
+		ORDER BY status = 'waiting', created_at, id
+	`
+	entries := []models.TripWaitlistOwnerEntry{}
+	if err := r.db.Select(&entries, query, scheduledTripID); err != nil {
+		return nil, fmt.Errorf("failed to get waitlist: %w", err)
+	}
+	for i := range entries {
+		entries[i].Position = i + 1
+	}
+	return entries, nil
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// UsageAnalyticsRepository stores anonymized usage events and aggregates them for the product team
+type UsageAnalyticsRepository struct {
+	db *sqlx.DB
+}
+
+// NewUsageAnalyticsRepository creates a new UsageAnalyticsRepository
+func NewUsageAnalyticsRepository(db *sqlx.DB) *UsageAnalyticsRepository {
+	return &UsageAnalyticsRepository{db: db}
+}
+
+// InsertEvents writes a batch of events in one statement
+func (r *UsageAnalyticsRepository) InsertEvents(events []models.UsageEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	const columns = 8
+	values := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*columns)
+	for i, e := range events {
+		n := i * columns
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8))
+		args = append(args, e.Event, e.Feature, e.Role, e.AppVersion, e.Platform, e.StatusCode, e.UserHash, e.OccurredAt)
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO usage_events (event, feature, role, app_version, platform, status_code, user_hash, occurred_at)
+		VALUES `+strings.Join(values, ", "), args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert usage events: %w", err)
+	}
+	return nil
+}
+
+// GetAdoption counts events and distinct users in [from, to) per feature or event and role,
+// most used first. Only successful requests count, and feature grouping skips untagged events.
+func (r *UsageAnalyticsRepository) GetAdoption(from, to time.Time, groupBy, role, feature string) ([]models.UsageAdoptionRow, error) {
+	key := "feature"
+	if groupBy == models.UsageGroupByEvent {
+		key = "event"
+	}
+
+	query := `
+		SELECT ` + key + ` AS key, role,
+		       COUNT(*) AS events,
+		       COUNT(DISTINCT user_hash) AS users
+		FROM usage_events
+		WHERE occurred_at >= $1 AND occurred_at < $2
+		  AND status_code < 400
+		  AND ` + key + ` IS NOT NULL`
+	args := []interface{}{from, to}
+	if role != "" {
+		args = append(args, role)
+		query += fmt.Sprintf(" AND role = $%d", len(args))
+	}
+	if feature != "" {
+		args = append(args, feature)
+		query += fmt.Sprintf(" AND feature = $%d", len(args))
+	}
+	query += `
+		GROUP BY ` + key + `, role
+		ORDER BY events DESC, key, role`
+
+	var rows []models.UsageAdoptionRow
+	if err := r.db.Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get usage adoption: %w", err)
+	}
+	return rows, nil
+}
+
+// GetRetention returns weekly cohort activity for a feature: users are grouped by the local
+// week (Monday, Asia/Colombo) they first used it, within [from, to), then counted in each
+// later week they used it again. Rows are ordered by cohort and week offset.
+func (r *UsageAnalyticsRepository) GetRetention(feature, role string, from, to time.Time) ([]models.UsageCohortActivity, error) {
+	roleFilter := ""
+	args := []interface{}{feature, from, to}
+	if role != "" {
+		args = append(args, role)
+		roleFilter = " AND role = $4"
+	}
+
+	var rows []models.UsageCohortActivity
+	err := r.db.Select(&rows, `
+		WITH activity AS (
+			SELECT DISTINCT user_hash, date_trunc('week', occurred_at AT TIME ZONE 'Asia/Colombo')::date AS week
+			FROM usage_events
+			WHERE feature = $1
+			  AND user_hash IS NOT NULL
+			  AND status_code < 400`+roleFilter+`
+		),
+		cohorts AS (
+			SELECT user_hash, MIN(week) AS cohort_week
+			FROM activity
+			GROUP BY user_hash
+		)
+		SELECT c.cohort_week,
+		       ((a.week - c.cohort_week) / 7)::int AS week_offset,
+		       COUNT(*) AS users
+		FROM cohorts c
+		JOIN activity a ON a.user_hash = c.user_hash
+		WHERE c.cohort_week >= date_trunc('week', $2::timestamptz AT TIME ZONE 'Asia/Colombo')::date
+		  AND c.cohort_week < ($3::timestamptz AT TIME ZONE 'Asia/Colombo')::date
+		GROUP BY c.cohort_week, week_offset
+		ORDER BY c.cohort_week, week_offset`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage retention: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// UserPreferencesRepository stores each user's language, notification, messaging and seat preferences
+type UserPreferencesRepository struct {
+	db DB
+}
+
+// NewUserPreferencesRepository creates a new UserPreferencesRepository
+func NewUserPreferencesRepository(db DB) *UserPreferencesRepository {
+	return &UserPreferencesRepository{db: db}
+}
+
+// GetByUserID returns a user's saved preferences, or nil if they never saved any
+func (r *UserPreferencesRepository) GetByUserID(userID uuid.UUID) (*models.UserPreferences, error) {
+	query := `
+		SELECT user_id, language, notification_channels, marketing_opt_in,
+		       COALESCE(messaging_channel, 'sms') AS messaging_channel,
+		       seat_position, seat_area, updated_at
+		FROM user_preferences
+		WHERE user_id = $1`
+
+	var prefs models.UserPreferences
+	if err := r.db.Get(&prefs, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// Upsert saves a user's preferences and sets UpdatedAt
+func (r *UserPreferencesRepository) Upsert(prefs *models.UserPreferences) error {
+	query := `
+		INSERT INTO user_preferences (
+			user_id, language, notification_channels, marketing_opt_in,
+			messaging_channel, seat_position, seat_area, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			language = EXCLUDED.language,
+			notification_channels = EXCLUDED.notification_channels,
+			marketing_opt_in = EXCLUDED.marketing_opt_in,
+			messaging_channel = EXCLUDED.messaging_channel,
+			seat_position = EXCLUDED.seat_position,
+			seat_area = EXCLUDED.seat_area,
+			updated_at = NOW()
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(query,
+		prefs.UserID, prefs.Language, prefs.NotificationChannels, prefs.MarketingOptIn,
+		prefs.MessagingChannel, prefs.Position, prefs.Area,
+	).Scan(&prefs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save user preferences: %w", err)
+	}
+	return nil
+}
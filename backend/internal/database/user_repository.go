@@ -8,20 +8,32 @@ import (
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
 )
 
-// UserRepository handles user database operations
+// UserRepository handles user database operations. NIC and address are encrypted at rest
+// when a PII cipher is configured.
 type UserRepository struct {
-	db DB
+	db  DB
+	pii *fieldcrypt.Cipher
 }
 
-// NewUserRepository creates a new user repository
-func NewUserRepository(db DB) *UserRepository {
+// NewUserRepository creates a new user repository; pii may be nil to store PII in plaintext
+func NewUserRepository(db DB, pii *fieldcrypt.Cipher) *UserRepository {
 	return &UserRepository{
-		db: db,
+		db:  db,
+		pii: pii,
 	}
 }
 
+// decryptUser decrypts a user's PII columns in place
+func (r *UserRepository) decryptUser(user *models.User) error {
+	if err := decryptPIINull(r.pii, &user.NIC.NullString); err != nil {
+		return err
+	}
+	return decryptPIINull(r.pii, &user.Address.NullString)
+}
+
 // CreateUser creates a new user in the database with default passenger role
 func (r *UserRepository) CreateUser(phone string) (*models.User, error) {
 	user := &models.User{
@@ -207,6 +219,9 @@ func (r *UserRepository) GetUserByPhone(phone string) (*models.User, error) {
 		}
 		return nil, fmt.Errorf("failed to get user by phone: %w", err)
 	}
+	if err := r.decryptUser(&user); err != nil {
+		return nil, err
+	}
 
 	return &user, nil
 }
@@ -232,6 +247,9 @@ func (r *UserRepository) GetUserByID(id uuid.UUID) (*models.User, error) {
 		}
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
 	}
+	if err := r.decryptUser(&user); err != nil {
+		return nil, err
+	}
 
 	return &user, nil
 }
@@ -250,7 +268,11 @@ func (r *UserRepository) UpdateProfile(id uuid.UUID, firstName, lastName, email,
 		WHERE id = $8
 	`
 
-	result, err := r.db.Exec(query, firstName, lastName, email, address, city, postalCode, time.Now(), id)
+	storedAddress, err := encryptPII(r.pii, address)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Exec(query, firstName, lastName, email, storedAddress, city, postalCode, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to update profile: %w", err)
 	}
@@ -501,6 +523,11 @@ func (r *UserRepository) ListUsers(limit, offset int) ([]*models.User, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
+	for _, user := range users {
+		if err := r.decryptUser(user); err != nil {
+			return nil, err
+		}
+	}
 
 	return users, nil
 }
@@ -518,3 +545,34 @@ func (r *UserRepository) CountUsers() (int, error) {
 
 	return count, nil
 }
+
+// ListUsersByFilter retrieves users oldest first, optionally only those with a role and/or
+// status. Used to page through users for bulk exports and notifications.
+func (r *UserRepository) ListUsersByFilter(role, status string, limit, offset int) ([]*models.User, error) {
+	var users []*models.User
+
+	query := `
+		SELECT id, phone, email, first_name, last_name, nic,
+		       date_of_birth, address, city, postal_code, roles,
+		       profile_photo_url, profile_completed, status,
+		       phone_verified, email_verified, last_login_at,
+		       metadata, created_at, updated_at
+		FROM users
+		WHERE ($1 = '' OR $1 = ANY(roles))
+		  AND ($2 = '' OR status = $2)
+		ORDER BY created_at, id
+		LIMIT $3 OFFSET $4
+	`
+
+	err := r.db.Select(&users, query, role, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	for _, user := range users {
+		if err := r.decryptUser(user); err != nil {
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
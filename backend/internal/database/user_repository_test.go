@@ -1,13 +1,16 @@
 package database
 
 import (
+	"bytes"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/pkg/fieldcrypt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,7 +21,7 @@ func TestCreateUser(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		phone := "+94712345678"
@@ -87,7 +90,7 @@ func TestGetUserByPhone(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		phone := "+94712345678"
@@ -157,7 +160,7 @@ func TestGetUserByID(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		userID := uuid.New()
@@ -210,7 +213,7 @@ func TestUpdateProfile(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		userID := uuid.New()
@@ -261,6 +264,38 @@ func TestUpdateProfile(t *testing.T) {
 		err = mock.ExpectationsWereMet()
 		assert.NoError(t, err)
 	})
+
+	t.Run("Encrypts Address", func(t *testing.T) {
+		pii, err := fieldcrypt.New(map[string][]byte{"k1": bytes.Repeat([]byte{1}, fieldcrypt.KeySize)}, "k1")
+		require.NoError(t, err)
+		encryptingRepo := NewUserRepository(mockDB, pii)
+		userID := uuid.New()
+
+		mock.ExpectExec(`UPDATE users SET`).
+			WithArgs("John", "Doe", "john@example.com", encryptedArg{pii, "123 Main St"}, "Colombo", "10100", sqlmock.AnyArg(), userID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err = encryptingRepo.UpdateProfile(userID, "John", "Doe", "john@example.com", "123 Main St", "Colombo", "10100")
+		require.NoError(t, err)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+// encryptedArg matches a query argument holding plaintext encrypted with pii
+type encryptedArg struct {
+	pii       *fieldcrypt.Cipher
+	plaintext string
+}
+
+func (a encryptedArg) Match(v driver.Value) bool {
+	stored, ok := v.(string)
+	if !ok || !fieldcrypt.IsEncrypted(stored) {
+		return false
+	}
+	plain, err := a.pii.Decrypt(stored)
+	return err == nil && plain == a.plaintext
 }
 
 func TestIsProfileComplete(t *testing.T) {
@@ -269,7 +304,7 @@ func TestIsProfileComplete(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Complete Profile", func(t *testing.T) {
 		userID := uuid.New()
@@ -341,7 +376,7 @@ func TestUpdateProfileCompletion(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Mark Complete", func(t *testing.T) {
 		userID := uuid.New()
@@ -407,7 +442,7 @@ func TestGetOrCreateUser(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Get Existing User", func(t *testing.T) {
 		phone := "+94712345678"
@@ -477,7 +512,7 @@ func TestUpdateUserStatus(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Update to Active", func(t *testing.T) {
 		userID := uuid.New()
@@ -528,7 +563,7 @@ func TestAddUserRole(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		userID := uuid.New()
@@ -566,7 +601,7 @@ func TestRemoveUserRole(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		userID := uuid.New()
@@ -604,7 +639,7 @@ func TestListUsers(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		now := time.Now()
@@ -670,7 +705,7 @@ func TestCountUsers(t *testing.T) {
 	defer db.Close()
 
 	mockDB := &mockDatabase{db: db}
-	repo := NewUserRepository(mockDB)
+	repo := NewUserRepository(mockDB, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users`).
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/geoip"
 )
 
 // UserSessionRepository handles user session database operations
@@ -258,6 +259,72 @@ func (r *UserSessionRepository) UpdateFCMToken(userID uuid.UUID, deviceID, fcmTo
 	return nil
 }
 
+// GetActivePushTokens returns the FCM tokens of a user's active sessions
+func (r *UserSessionRepository) GetActivePushTokens(userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT DISTINCT fcm_token
+		FROM user_sessions
+		WHERE user_id = $1 AND is_active = true
+		  AND fcm_token IS NOT NULL AND fcm_token <> ''
+	`
+
+	tokens := []string{}
+	if err := r.db.Select(&tokens, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get push tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// ClearFCMToken removes a token the push provider reported as no longer registered
+func (r *UserSessionRepository) ClearFCMToken(fcmToken string) error {
+	query := `
+		UPDATE user_sessions
+		SET fcm_token = NULL,
+		    updated_at = $1
+		WHERE fcm_token = $2
+	`
+
+	_, err := r.db.Exec(query, time.Now(), fcmToken)
+	if err != nil {
+		return fmt.Errorf("failed to clear FCM token: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSessionLocation stores the geolocation of the session's current IP address.
+// A nil location clears it, so a stale location never outlives an IP change.
+func (r *UserSessionRepository) UpdateSessionLocation(sessionID uuid.UUID, location *geoip.Location) error {
+	query := `
+		UPDATE user_sessions
+		SET geo_country_code = $1,
+		    geo_country = $2,
+		    geo_city = $3,
+		    geo_asn = $4,
+		    geo_as_org = $5,
+		    updated_at = $6
+		WHERE id = $7
+	`
+
+	var countryCode, country, city, asOrg string
+	var asn *int64
+	if location != nil {
+		countryCode, country, city, asOrg = location.CountryCode, location.Country, location.City, location.ASOrg
+		if location.ASN > 0 {
+			value := int64(location.ASN)
+			asn = &value
+		}
+	}
+
+	_, err := r.db.Exec(query, nullString(countryCode), nullString(country), nullString(city), asn, nullString(asOrg), time.Now(), sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to update session location: %w", err)
+	}
+
+	return nil
+}
+
 // DeactivateSession marks a session as inactive (logout)
 func (r *UserSessionRepository) DeactivateSession(userID uuid.UUID, deviceID string) error {
 	query := `
@@ -296,7 +363,8 @@ func (r *UserSessionRepository) DeactivateAllUserSessions(userID uuid.UUID) erro
 func (r *UserSessionRepository) GetActiveSessions(userID uuid.UUID) ([]*models.UserSession, error) {
 	query := `
 		SELECT id, user_id, device_id, device_type, device_model, app_version, os_version,
-			fcm_token, ip_address, location_permission, notification_permission,
+			fcm_token, ip_address, geo_country_code, geo_country, geo_city, geo_asn, geo_as_org,
+			location_permission, notification_permission,
 			last_activity_at, is_active, created_at, updated_at
 		FROM user_sessions
 		WHERE user_id = $1 AND is_active = true
@@ -322,6 +390,11 @@ func (r *UserSessionRepository) GetActiveSessions(userID uuid.UUID) ([]*models.U
 			&session.OSVersion,
 			&session.FCMToken,
 			&session.IPAddress,
+			&session.GeoCountryCode,
+			&session.GeoCountry,
+			&session.GeoCity,
+			&session.GeoASN,
+			&session.GeoASOrg,
 			&session.LocationPermission,
 			&session.NotificationPermission,
 			&session.LastActivityAt,
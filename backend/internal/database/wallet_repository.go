@@ -0,0 +1,357 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	// ErrWalletInsufficientBalance is returned when a debit would take a wallet below zero
+	ErrWalletInsufficientBalance = errors.New("wallet balance is too low")
+	// ErrWalletIntentNotPayable is returned when an intent is no longer awaiting payment or
+	// already has a wallet payment
+	ErrWalletIntentNotPayable = errors.New("intent cannot be paid from the wallet")
+)
+
+const walletColumns = `id, user_id, balance, currency, created_at, updated_at`
+
+const walletEntryColumns = `id, wallet_id, entry_type, amount, balance_after, intent_id, top_up_id, refund_id,
+	description, created_at`
+
+const walletTopUpColumns = `id, user_id, amount, currency, status, invoice_id, payment_uid, status_indicator,
+	failure_reason, completed_at, created_at, updated_at`
+
+// WalletRepository handles passenger wallets, their ledger and top-ups. Every balance change
+// is written with its wallet_entries row in one transaction, holding the wallet row locked.
+type WalletRepository struct {
+	db *sqlx.DB
+}
+
+// NewWalletRepository creates a new WalletRepository
+func NewWalletRepository(db *sqlx.DB) *WalletRepository {
+	return &WalletRepository{db: db}
+}
+
+// GetByUser returns a user's wallet; returns nil if they have none yet
+func (r *WalletRepository) GetByUser(userID string) (*models.Wallet, error) {
+	var wallet models.Wallet
+	err := r.db.Get(&wallet, `SELECT `+walletColumns+` FROM wallets WHERE user_id = $1`, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	return &wallet, nil
+}
+
+// GetOrCreate returns a user's wallet, opening an empty one if they have none
+func (r *WalletRepository) GetOrCreate(userID, currency string) (*models.Wallet, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO wallets (user_id, balance, currency, created_at, updated_at)
+		VALUES ($1, 0, $2, NOW(), NOW())
+		ON CONFLICT (user_id) DO NOTHING`, userID, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wallet: %w", err)
+	}
+	return r.GetByUser(userID)
+}
+
+// ListEntries returns a page of a wallet's ledger, newest first, with the total count
+func (r *WalletRepository) ListEntries(walletID string, limit, offset int) ([]models.WalletEntry, int, error) {
+	entries := []models.WalletEntry{}
+	err := r.db.Select(&entries, `
+		SELECT `+walletEntryColumns+`
+		FROM wallet_entries
+		WHERE wallet_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`, walletID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list wallet entries: %w", err)
+	}
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) FROM wallet_entries WHERE wallet_id = $1`, walletID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count wallet entries: %w", err)
+	}
+	return entries, total, nil
+}
+
+// lockWallet locks a user's wallet row for the rest of tx
+func lockWallet(tx *sqlx.Tx, userID string) (*models.Wallet, error) {
+	var wallet models.Wallet
+	err := tx.Get(&wallet, `SELECT `+walletColumns+` FROM wallets WHERE user_id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock wallet: %w", err)
+	}
+	return &wallet, nil
+}
+
+// applyWalletEntry adds entry.Amount to the locked wallet's balance and records the entry
+func applyWalletEntry(tx *sqlx.Tx, wallet *models.Wallet, entry *models.WalletEntry) error {
+	balance := wallet.Balance.Add(entry.Amount)
+	if balance.IsNegative() {
+		return ErrWalletInsufficientBalance
+	}
+	entry.WalletID = wallet.ID
+	entry.BalanceAfter = balance
+	err := tx.QueryRow(`
+		INSERT INTO wallet_entries (
+			wallet_id, entry_type, amount, balance_after, intent_id, top_up_id, refund_id, description, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING id, created_at`,
+		entry.WalletID, entry.EntryType, entry.Amount, entry.BalanceAfter, entry.IntentID, entry.TopUpID,
+		entry.RefundID, entry.Description,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record wallet entry: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE wallets SET balance = $2, updated_at = NOW() WHERE id = $1`, wallet.ID, balance); err != nil {
+		return fmt.Errorf("failed to update wallet balance: %w", err)
+	}
+	wallet.Balance = balance
+	return nil
+}
+
+// PayIntent takes amount from the user's wallet towards a held or payment_pending intent and
+// records it on the intent. An intent is paid from the wallet at most once.
+func (r *WalletRepository) PayIntent(userID, intentID string, amount models.Money, description string) (*models.WalletEntry, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	wallet, err := lockWallet(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := tx.Exec(`
+		UPDATE booking_intents
+		SET wallet_amount = $2, updated_at = NOW()
+		WHERE id = $1 AND status IN ('held', 'payment_pending') AND COALESCE(wallet_amount, 0) = 0`,
+		intentID, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record wallet payment on intent: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, ErrWalletIntentNotPayable
+	}
+
+	entry := &models.WalletEntry{
+		EntryType:   models.WalletEntryBookingPayment,
+		Amount:      amount.Neg(),
+		IntentID:    &intentID,
+		Description: &description,
+	}
+	if err := applyWalletEntry(tx, wallet, entry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit wallet payment: %w", err)
+	}
+	return entry, nil
+}
+
+// ReleaseIntent returns an unconfirmed intent's wallet payment to the wallet and clears it from
+// the intent. Returns nil if there was nothing to return, so it is safe to call more than once.
+func (r *WalletRepository) ReleaseIntent(userID, intentID string) (*models.WalletEntry, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	wallet, err := lockWallet(tx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil // No wallet, so nothing was paid from one
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var amount models.Money
+	err = tx.Get(&amount, `
+		SELECT COALESCE(wallet_amount, 0) FROM booking_intents
+		WHERE id = $1 AND status NOT IN ('confirming', 'confirmed')
+		FOR UPDATE`, intentID)
+	if err == sql.ErrNoRows || (err == nil && !amount.IsPositive()) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get intent wallet payment: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE booking_intents SET wallet_amount = 0, updated_at = NOW() WHERE id = $1`, intentID); err != nil {
+		return nil, fmt.Errorf("failed to clear intent wallet payment: %w", err)
+	}
+
+	description := "Returned from an unconfirmed booking"
+	entry := &models.WalletEntry{
+		EntryType:   models.WalletEntryBookingRelease,
+		Amount:      amount,
+		IntentID:    &intentID,
+		Description: &description,
+	}
+	if err := applyWalletEntry(tx, wallet, entry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit wallet release: %w", err)
+	}
+	return entry, nil
+}
+
+// CreditRefund adds a completed refund to the user's wallet. A refund is credited at most
+// once; crediting it again returns the original entry.
+func (r *WalletRepository) CreditRefund(userID, refundID string, amount models.Money, description string) (*models.WalletEntry, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	wallet, err := lockWallet(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var existing models.WalletEntry
+	err = tx.Get(&existing, `
+		SELECT `+walletEntryColumns+` FROM wallet_entries
+		WHERE wallet_id = $1 AND refund_id = $2 AND entry_type = $3`,
+		wallet.ID, refundID, models.WalletEntryRefund)
+	if err == nil {
+		return &existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check wallet refund: %w", err)
+	}
+
+	entry := &models.WalletEntry{
+		EntryType:   models.WalletEntryRefund,
+		Amount:      amount,
+		RefundID:    &refundID,
+		Description: &description,
+	}
+	if err := applyWalletEntry(tx, wallet, entry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit wallet refund: %w", err)
+	}
+	return entry, nil
+}
+
+// ============================================================================
+// TOP-UPS
+// ============================================================================
+
+// CreateTopUp inserts a pending top-up
+func (r *WalletRepository) CreateTopUp(topUp *models.WalletTopUp) error {
+	err := r.db.QueryRow(`
+		INSERT INTO wallet_top_ups (user_id, amount, currency, status, invoice_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at`,
+		topUp.UserID, topUp.Amount, topUp.Currency, topUp.Status, topUp.InvoiceID,
+	).Scan(&topUp.ID, &topUp.CreatedAt, &topUp.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create wallet top-up: %w", err)
+	}
+	return nil
+}
+
+// SetTopUpPayment stores the PAYable UID and status indicator of a top-up's payment
+func (r *WalletRepository) SetTopUpPayment(topUpID, uid, statusIndicator string) error {
+	_, err := r.db.Exec(`
+		UPDATE wallet_top_ups SET payment_uid = $2, status_indicator = $3, updated_at = NOW()
+		WHERE id = $1`, topUpID, uid, statusIndicator)
+	if err != nil {
+		return fmt.Errorf("failed to store wallet top-up payment: %w", err)
+	}
+	return nil
+}
+
+// GetTopUpByPaymentUID returns the top-up paid with a PAYable UID; returns nil if there is none
+func (r *WalletRepository) GetTopUpByPaymentUID(uid string) (*models.WalletTopUp, error) {
+	var topUp models.WalletTopUp
+	err := r.db.Get(&topUp, `SELECT `+walletTopUpColumns+` FROM wallet_top_ups WHERE payment_uid = $1`, uid)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet top-up: %w", err)
+	}
+	return &topUp, nil
+}
+
+// ListTopUps returns a user's top-ups, newest first
+func (r *WalletRepository) ListTopUps(userID string, limit, offset int) ([]models.WalletTopUp, error) {
+	topUps := []models.WalletTopUp{}
+	err := r.db.Select(&topUps, `
+		SELECT `+walletTopUpColumns+`
+		FROM wallet_top_ups
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallet top-ups: %w", err)
+	}
+	return topUps, nil
+}
+
+// CompleteTopUp marks a pending top-up completed and credits the wallet in one transaction.
+// Returns nil if the top-up was no longer pending.
+func (r *WalletRepository) CompleteTopUp(topUpID string) (*models.WalletEntry, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var topUp models.WalletTopUp
+	err = tx.Get(&topUp, `
+		UPDATE wallet_top_ups
+		SET status = $2, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $3
+		RETURNING `+walletTopUpColumns,
+		topUpID, models.WalletTopUpCompleted, models.WalletTopUpPending)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete wallet top-up: %w", err)
+	}
+
+	wallet, err := lockWallet(tx, topUp.UserID)
+	if err != nil {
+		return nil, err
+	}
+	description := "Top-up " + topUp.InvoiceID
+	entry := &models.WalletEntry{
+		EntryType:   models.WalletEntryTopUp,
+		Amount:      topUp.Amount,
+		TopUpID:     &topUp.ID,
+		Description: &description,
+	}
+	if err := applyWalletEntry(tx, wallet, entry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit wallet top-up: %w", err)
+	}
+	return entry, nil
+}
+
+// FailTopUp marks a pending top-up failed
+func (r *WalletRepository) FailTopUp(topUpID, reason string) error {
+	_, err := r.db.Exec(`
+		UPDATE wallet_top_ups SET status = $2, failure_reason = $3, updated_at = NOW()
+		WHERE id = $1 AND status = $4`,
+		topUpID, models.WalletTopUpFailed, reason, models.WalletTopUpPending)
+	if err != nil {
+		return fmt.Errorf("failed to fail wallet top-up: %w", err)
+	}
+	return nil
+}
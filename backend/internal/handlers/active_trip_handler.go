@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/realtime"
 	"github.com/smarttransit/sms-auth-backend/internal/services"
 )
 
@@ -13,16 +20,22 @@ import (
 type ActiveTripHandler struct {
 	activeTripService *services.ActiveTripService
 	staffRepo         *database.BusStaffRepository
+	busOwnerRepo      *database.BusOwnerRepository
+	liveTracking      config.LiveTrackingConfig
 }
 
 // NewActiveTripHandler creates a new ActiveTripHandler
 func NewActiveTripHandler(
 	activeTripService *services.ActiveTripService,
 	staffRepo *database.BusStaffRepository,
+	busOwnerRepo *database.BusOwnerRepository,
+	liveTracking config.LiveTrackingConfig,
 ) *ActiveTripHandler {
 	return &ActiveTripHandler{
 		activeTripService: activeTripService,
 		staffRepo:         staffRepo,
+		busOwnerRepo:      busOwnerRepo,
+		liveTracking:      liveTracking,
 	}
 }
 
@@ -155,10 +168,7 @@ func (h *ActiveTripHandler) UpdateLocation(c *gin.Context) {
 	})
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "update_location_failed",
-			"message": err.Error(),
-		})
+		h.respondCrewError(c, "update_location_failed", err)
 		return
 	}
 
@@ -227,10 +237,7 @@ func (h *ActiveTripHandler) EndTrip(c *gin.Context) {
 	})
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "end_trip_failed",
-			"message": err.Error(),
-		})
+		h.respondCrewError(c, "end_trip_failed", err)
 		return
 	}
 
@@ -257,7 +264,7 @@ func (h *ActiveTripHandler) GetActiveTrip(c *gin.Context) {
 	userIDStr := userCtx.UserID.String()
 
 	// Get staff profile
-	_, err := h.staffRepo.GetByUserID(userIDStr)
+	staff, err := h.staffRepo.GetByUserID(userIDStr)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "not_staff",
@@ -286,7 +293,14 @@ func (h *ActiveTripHandler) GetActiveTrip(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, activeTrip)
+	// Only the trip's crew see it, with the actions open to their role
+	view, err := h.activeTripService.GetCrewView(activeTrip, staff.ID)
+	if err != nil {
+		h.respondCrewError(c, "get_active_trip_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
 }
 
 // GetMyActiveTrip gets the current active trip for the authenticated staff member
@@ -324,9 +338,15 @@ func (h *ActiveTripHandler) GetMyActiveTrip(c *gin.Context) {
 		return
 	}
 
+	view, err := h.activeTripService.GetCrewView(activeTrip, staff.ID)
+	if err != nil {
+		h.respondCrewError(c, "get_active_trip_failed", err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"has_active_trip": true,
-		"active_trip":     activeTrip,
+		"active_trip":     view,
 	})
 }
 
@@ -361,6 +381,125 @@ func (h *ActiveTripHandler) GetActiveTripByScheduledTripID(c *gin.Context) {
 	})
 }
 
+// StreamLocation streams the live position of an active trip as server-sent events: the current
+// position first, then a "location" event for every update from the crew app, and a final
+// "trip_ended" event when the trip is over. Comment lines are sent as keep-alives.
+// GET /api/v1/active-trips/:id/location/stream
+func (h *ActiveTripHandler) StreamLocation(c *gin.Context) {
+	activeTripID := c.Param("id")
+	if activeTripID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "missing_id",
+			"message": "Active trip ID is required",
+		})
+		return
+	}
+
+	activeTrip, sub, err := h.activeTripService.SubscribeLocation(activeTripID)
+	switch {
+	case errors.Is(err, services.ErrActiveTripNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Active trip not found",
+		})
+		return
+	case errors.Is(err, services.ErrTripNotActive):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":           "trip_not_active",
+			"message":         err.Error(),
+			"has_active_trip": false,
+		})
+		return
+	case errors.Is(err, realtime.ErrTooManySubscribers):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "stream_unavailable",
+			"message": "Too many passengers are following this trip, please try again later",
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "stream_failed",
+			"message": "Failed to follow trip",
+		})
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Stop reverse proxies from buffering the stream
+
+	// The server's write timeout would cut the stream off; push the deadline back before each write
+	heartbeat := h.liveTracking.HeartbeatInterval
+	controller := http.NewResponseController(c.Writer)
+	extendDeadline := func() {
+		_ = controller.SetWriteDeadline(time.Now().Add(2 * heartbeat))
+	}
+
+	var lastSent time.Time
+	sendLocation := func(update *services.TripLocationUpdate) {
+		extendDeadline()
+		c.SSEvent(services.TripStreamEventLocation, update)
+		lastSent = update.UpdatedAt
+	}
+
+	extendDeadline()
+	c.Status(http.StatusOK)
+	if current := services.TripLocation(activeTrip); current != nil {
+		sendLocation(current)
+	} else {
+		_, _ = io.WriteString(c.Writer, ": waiting for first position\n\n")
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case msg, open := <-sub.C:
+			if !open {
+				return
+			}
+			if update, ok := msg.Data.(*services.TripLocationUpdate); ok {
+				sendLocation(update)
+			} else {
+				extendDeadline()
+				c.SSEvent(msg.Event, msg.Data)
+			}
+			if msg.Event == services.TripStreamEventEnded {
+				c.Writer.Flush()
+				return
+			}
+
+		case <-ticker.C:
+			// Updates sent to other instances only reach this one through the database
+			var update *services.TripLocationUpdate
+			latest, err := h.activeTripService.GetActiveTrip(activeTripID)
+			if err == nil {
+				update = services.TripLocation(latest)
+			}
+			switch {
+			case err == nil && !latest.IsActive():
+				extendDeadline()
+				c.SSEvent(services.TripStreamEventEnded, &services.TripEndedUpdate{ActiveTripID: latest.ID, Status: latest.Status})
+				c.Writer.Flush()
+				return
+			case update != nil && update.UpdatedAt.After(lastSent):
+				sendLocation(update)
+			default:
+				extendDeadline()
+				_, _ = io.WriteString(c.Writer, ": keep-alive\n\n")
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
 // UpdatePassengerCountRequest represents the request body for updating passenger count
 type UpdatePassengerCountRequest struct {
 	PassengerCount int `json:"passenger_count" binding:"required,min=0"`
@@ -414,15 +553,197 @@ func (h *ActiveTripHandler) UpdatePassengerCount(c *gin.Context) {
 	// Update passenger count
 	err = h.activeTripService.UpdatePassengerCount(activeTripID, staff.ID, req.PassengerCount)
 	if err != nil {
+		h.respondCrewError(c, "update_passengers_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Passenger count updated successfully",
+		"passenger_count": req.PassengerCount,
+	})
+}
+
+// ReportIncident flags an incident on an active trip; passengers' emergency contacts are alerted
+// POST /api/v1/staff/trips/:id/incident
+func (h *ActiveTripHandler) ReportIncident(c *gin.Context) {
+	// Get user context
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	// Get staff profile
+	staff, err := h.staffRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_staff",
+			"message": "User is not registered as staff",
+		})
+		return
+	}
+
+	// Get active trip ID from URL
+	activeTripID := c.Param("id")
+	if activeTripID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "missing_id",
+			"message": "Active trip ID is required",
+		})
+		return
+	}
+
+	// Parse request
+	var req models.ReportTripIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation_error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	incident, err := h.activeTripService.ReportIncident(activeTripID, staff.ID, &req)
+	if err != nil {
+		h.respondCrewError(c, "report_incident_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Incident reported. Passengers' emergency contacts are being notified.",
+		"incident": incident,
+	})
+}
+
+// HandOverTrip hands the driver or conductor seat of a running trip to another staff member
+// POST /api/v1/staff/trips/:id/handover
+func (h *ActiveTripHandler) HandOverTrip(c *gin.Context) {
+	// Get user context
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	// Get staff profile
+	staff, err := h.staffRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_staff",
+			"message": "User is not registered as staff",
+		})
+		return
+	}
+
+	// Get active trip ID from URL
+	activeTripID := c.Param("id")
+	if activeTripID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "update_passengers_failed",
+			"error":   "missing_id",
+			"message": "Active trip ID is required",
+		})
+		return
+	}
+
+	// Parse request
+	var req models.ActiveTripHandoverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation_error",
 			"message": err.Error(),
 		})
 		return
 	}
 
+	handover, err := h.activeTripService.HandOverCrew(activeTripID, staff.ID, &req)
+	if err != nil {
+		h.respondCrewError(c, "handover_failed", err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":         "Passenger count updated successfully",
-		"passenger_count": req.PassengerCount,
+		"message":  "Trip handed over successfully",
+		"handover": handover,
 	})
 }
+
+// respondCrewError answers 403 when the caller isn't crew or their role can't do the action, 400 otherwise
+// GetLocationHistory returns the positions an active trip's bus reported, in order, with the
+// distance travelled, so the bus owner can replay the route
+// GET /api/v1/active-trips/:id/location-history
+func (h *ActiveTripHandler) GetLocationHistory(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "not_bus_owner",
+				"message": "Only bus owners can view trip location history",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "Failed to fetch profile",
+		})
+		return
+	}
+
+	history, err := h.activeTripService.GetLocationHistory(c.Param("id"), busOwner.ID)
+	switch {
+	case errors.Is(err, services.ErrActiveTripNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Active trip not found",
+		})
+		return
+	case errors.Is(err, services.ErrNotTripOwner):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "not_trip_owner",
+			"message": err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "location_history_failed",
+			"message": "Failed to get trip location history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *ActiveTripHandler) respondCrewError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotTripCrew):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "not_trip_crew",
+			"message": err.Error(),
+		})
+	case errors.Is(err, services.ErrCrewActionNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "action_not_allowed",
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   code,
+			"message": err.Error(),
+		})
+	}
+}
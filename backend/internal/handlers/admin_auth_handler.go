@@ -1,26 +1,39 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
 	"github.com/smarttransit/sms-auth-backend/internal/services"
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
 )
 
 // AdminAuthHandler handles admin authentication HTTP requests
 type AdminAuthHandler struct {
-	adminAuthService *services.AdminAuthService
-	logger           *logrus.Logger
+	adminAuthService  *services.AdminAuthService
+	permissionService *services.AdminPermissionService
+	auditService      *services.AuditService
+	logger            *logrus.Logger
 }
 
 // NewAdminAuthHandler creates a new admin auth handler
-func NewAdminAuthHandler(adminAuthService *services.AdminAuthService, logger *logrus.Logger) *AdminAuthHandler {
+func NewAdminAuthHandler(
+	adminAuthService *services.AdminAuthService,
+	permissionService *services.AdminPermissionService,
+	auditService *services.AuditService,
+	logger *logrus.Logger,
+) *AdminAuthHandler {
 	return &AdminAuthHandler{
-		adminAuthService: adminAuthService,
-		logger:           logger,
+		adminAuthService:  adminAuthService,
+		permissionService: permissionService,
+		auditService:      auditService,
+		logger:            logger,
 	}
 }
 
@@ -56,6 +69,7 @@ func (h *AdminAuthHandler) Login(c *gin.Context) {
 		"admin_id": response.AdminUser.ID,
 		"email":    response.AdminUser.Email,
 	}).Info("Admin login successful")
+	h.withPermissions(response.AdminUser)
 
 	c.JSON(http.StatusOK, response)
 }
@@ -126,15 +140,8 @@ func (h *AdminAuthHandler) Logout(c *gin.Context) {
 // @Router /admin/auth/profile [get]
 func (h *AdminAuthHandler) GetProfile(c *gin.Context) {
 	// Get admin ID from context (set by auth middleware)
-	adminID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	adminUUID, err := uuid.Parse(adminID.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+	adminUUID, ok := h.adminID(c)
+	if !ok {
 		return
 	}
 
@@ -143,6 +150,7 @@ func (h *AdminAuthHandler) GetProfile(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
 		return
 	}
+	h.withPermissions(admin)
 
 	c.JSON(http.StatusOK, admin)
 }
@@ -161,15 +169,8 @@ func (h *AdminAuthHandler) GetProfile(c *gin.Context) {
 // @Router /admin/auth/change-password [post]
 func (h *AdminAuthHandler) ChangePassword(c *gin.Context) {
 	// Get admin ID from context
-	adminID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	adminUUID, err := uuid.Parse(adminID.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+	adminUUID, ok := h.adminID(c)
+	if !ok {
 		return
 	}
 
@@ -188,9 +189,9 @@ func (h *AdminAuthHandler) ChangePassword(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
 }
 
-// CreateAdmin creates a new admin user (only accessible by existing admins)
+// CreateAdmin creates a new admin user with a role (super admins only)
 // @Summary Create new admin user
-// @Description Create a new admin user (requires admin authentication)
+// @Description Create a new admin user with one of the roles super_admin, operations, finance or support (requires admins.manage)
 // @Tags Admin Auth
 // @Accept json
 // @Produce json
@@ -199,18 +200,12 @@ func (h *AdminAuthHandler) ChangePassword(c *gin.Context) {
 // @Success 201 {object} models.AdminUser
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /admin/auth/create [post]
 func (h *AdminAuthHandler) CreateAdmin(c *gin.Context) {
 	// Get creator admin ID from context
-	creatorID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	creatorUUID, err := uuid.Parse(creatorID.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+	creatorUUID, ok := h.adminID(c)
+	if !ok {
 		return
 	}
 
@@ -220,7 +215,7 @@ func (h *AdminAuthHandler) CreateAdmin(c *gin.Context) {
 		return
 	}
 
-	admin, err := h.adminAuthService.CreateAdmin(c.Request.Context(), req.Email, req.Password, req.FullName, creatorUUID)
+	admin, err := h.adminAuthService.CreateAdmin(c.Request.Context(), req.Email, req.Password, req.FullName, req.Role, creatorUUID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -229,15 +224,20 @@ func (h *AdminAuthHandler) CreateAdmin(c *gin.Context) {
 	h.logger.WithFields(logrus.Fields{
 		"admin_id":   admin.ID,
 		"email":      admin.Email,
+		"role":       admin.Role,
 		"created_by": creatorUUID,
 	}).Info("New admin user created")
+	h.auditPrivilegeChange(c, creatorUUID, models.AuditAdminCreated, "admin_user", &admin.ID, map[string]interface{}{
+		"email": admin.Email,
+		"role":  admin.Role,
+	})
 
 	c.JSON(http.StatusCreated, admin)
 }
 
 // ListAdmins retrieves all admin users
 // @Summary List all admin users
-// @Description Get a list of all admin users (requires admin authentication)
+// @Description Get a list of all admin users with their roles (requires admins.view)
 // @Tags Admin Auth
 // @Accept json
 // @Produce json
@@ -254,3 +254,171 @@ func (h *AdminAuthHandler) ListAdmins(c *gin.Context) {
 
 	c.JSON(http.StatusOK, admins)
 }
+
+// UpdateAdminRole changes another admin's role
+// @Summary Change admin role
+// @Description Move an admin to another role; admins cannot change their own (requires admins.manage)
+// @Tags Admin Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Admin user ID"
+// @Param request body models.AdminUpdateRoleRequest true "New role"
+// @Success 200 {object} models.AdminUser
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/auth/admins/{id}/role [put]
+func (h *AdminAuthHandler) UpdateAdminRole(c *gin.Context) {
+	changerID, ok := h.adminID(c)
+	if !ok {
+		return
+	}
+	adminID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	var req models.AdminUpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	admin, previous, err := h.permissionService.ChangeRole(c.Request.Context(), adminID, req.Role, changerID)
+	if err != nil {
+		var validationErr *models.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+		case errors.Is(err, database.ErrAdminUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+		default:
+			h.logger.WithError(err).WithField("admin_id", adminID).Error("Failed to change admin role")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change admin role"})
+		}
+		return
+	}
+
+	if previous != admin.Role {
+		h.logger.WithFields(logrus.Fields{
+			"admin_id":   admin.ID,
+			"from_role":  previous,
+			"to_role":    admin.Role,
+			"changed_by": changerID,
+		}).Info("Admin role changed")
+		h.auditPrivilegeChange(c, changerID, models.AuditAdminRoleChanged, "admin_user", &admin.ID, map[string]interface{}{
+			"email":     admin.Email,
+			"from_role": previous,
+			"to_role":   admin.Role,
+		})
+	}
+
+	c.JSON(http.StatusOK, admin)
+}
+
+// ListRoles lists the admin roles and the permissions each grants
+// @Summary List admin roles
+// @Description Get every admin role with its permissions; is_default marks roles still on the built-in defaults (requires admins.view)
+// @Tags Admin Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/auth/roles [get]
+func (h *AdminAuthHandler) ListRoles(c *gin.Context) {
+	roles, err := h.permissionService.Roles()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list admin roles")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve admin roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles, "permissions": models.AdminPermissions})
+}
+
+// UpdateRolePermissions sets the permissions a role grants
+// @Summary Set admin role permissions
+// @Description Replace the permissions of the operations, finance or support role. Super admin permissions are fixed and admins.manage and security.manage cannot be granted (requires admins.manage)
+// @Tags Admin Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role"
+// @Param request body models.AdminRolePermissionsRequest true "Permissions"
+// @Success 200 {object} models.AdminRoleInfo
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/auth/roles/{role}/permissions [put]
+func (h *AdminAuthHandler) UpdateRolePermissions(c *gin.Context) {
+	changerID, ok := h.adminID(c)
+	if !ok {
+		return
+	}
+	role := models.AdminRole(c.Param("role"))
+
+	var req models.AdminRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	before, err := h.permissionService.Roles()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list admin roles")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role permissions"})
+		return
+	}
+
+	info, err := h.permissionService.SetRolePermissions(role, req.Permissions, changerID)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+			return
+		}
+		h.logger.WithError(err).WithField("role", role).Error("Failed to update role permissions")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role permissions"})
+		return
+	}
+
+	details := map[string]interface{}{"role": role, "permissions": info.Permissions}
+	for _, previous := range before {
+		if previous.Role == role {
+			details["previous_permissions"] = previous.Permissions
+		}
+	}
+	h.logger.WithFields(logrus.Fields{"role": role, "changed_by": changerID}).Info("Admin role permissions changed")
+	h.auditPrivilegeChange(c, changerID, models.AuditAdminRolePermissionsChanged, "admin_role", nil, details)
+
+	c.JSON(http.StatusOK, info)
+}
+
+// adminID returns the authenticated admin's ID, writing the error response if there is none
+func (h *AdminAuthHandler) adminID(c *gin.Context) (uuid.UUID, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return uuid.Nil, false
+	}
+	return userCtx.UserID, true
+}
+
+// withPermissions adds the admin's effective permissions, so the dashboard can hide what they
+// cannot use. Failing to load them leaves the list empty rather than failing the request.
+func (h *AdminAuthHandler) withPermissions(admin *models.AdminUser) {
+	permissions, err := h.permissionService.Permissions(admin.ID, admin.Email)
+	if err != nil {
+		h.logger.WithError(err).WithField("admin_id", admin.ID).Warn("Failed to load admin permissions")
+		return
+	}
+	admin.Permissions = permissions
+}
+
+// auditPrivilegeChange records a change to who holds which admin privileges
+func (h *AdminAuthHandler) auditPrivilegeChange(c *gin.Context, adminID uuid.UUID, action, entityType string, entityID *uuid.UUID, details map[string]interface{}) {
+	if err := h.auditService.LogAdminPrivilegeChange(adminID, action, entityType, entityID, utils.GetRealIP(c), utils.GetUserAgent(c), details); err != nil {
+		h.logger.WithError(err).Warn("Failed to audit admin privilege change")
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// AdminBulkJobHandler handles asynchronous admin bulk operations
+type AdminBulkJobHandler struct {
+	bulkJobService *services.AdminBulkJobService
+	logger         *logrus.Logger
+}
+
+// NewAdminBulkJobHandler creates a new AdminBulkJobHandler
+func NewAdminBulkJobHandler(bulkJobService *services.AdminBulkJobService, logger *logrus.Logger) *AdminBulkJobHandler {
+	return &AdminBulkJobHandler{
+		bulkJobService: bulkJobService,
+		logger:         logger,
+	}
+}
+
+// CreateJob queues a bulk job and returns its ID for polling
+// POST /api/v1/admin/bulk-jobs
+func (h *AdminBulkJobHandler) CreateJob(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateAdminBulkJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	job, err := h.bulkJobService.Submit(userCtx.UserID, &req)
+	if err != nil {
+		h.respondBulkJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Bulk job queued", "job": job})
+}
+
+// ListJobs lists bulk jobs, newest first
+// GET /api/v1/admin/bulk-jobs?job_type=&status=&limit=50&offset=0
+func (h *AdminBulkJobHandler) ListJobs(c *gin.Context) {
+	jobType := c.Query("job_type")
+	if jobType != "" && !models.AdminBulkJobType(jobType).IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "job_type must be one of lounge_approval, user_export, notification_send"})
+		return
+	}
+	status := models.AdminBulkJobStatus(c.Query("status"))
+	switch status {
+	case "", models.AdminBulkJobQueued, models.AdminBulkJobRunning, models.AdminBulkJobCompleted, models.AdminBulkJobFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "status must be queued, running, completed or failed"})
+		return
+	}
+	limit, offset := payoutPagination(c)
+
+	jobs, total, err := h.bulkJobService.ListJobs(jobType, string(status), limit, offset)
+	if err != nil {
+		h.respondBulkJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs, "total": total, "limit": limit, "offset": offset})
+}
+
+// GetJob returns a bulk job's status and progress
+// GET /api/v1/admin/bulk-jobs/:id
+func (h *AdminBulkJobHandler) GetJob(c *gin.Context) {
+	job, err := h.bulkJobService.GetJob(c.Param("id"))
+	if err != nil {
+		h.respondBulkJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// DownloadResults returns a finished job's results CSV
+// GET /api/v1/admin/bulk-jobs/:id/results
+func (h *AdminBulkJobHandler) DownloadResults(c *gin.Context) {
+	job, data, err := h.bulkJobService.GetResults(c.Param("id"))
+	if err != nil {
+		h.respondBulkJobError(c, err)
+		return
+	}
+
+	filename := "bulk-job-results.csv"
+	if job.ResultFileName != nil {
+		filename = *job.ResultFileName
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+func (h *AdminBulkJobHandler) respondBulkJobError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrBulkJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrBulkJobNotFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": "job_not_finished", "message": err.Error()})
+	case errors.Is(err, services.ErrBulkJobNoResults):
+		c.JSON(http.StatusNotFound, gin.H{"error": "no_results", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Admin bulk job request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Admin bulk job request failed"})
+	}
+}
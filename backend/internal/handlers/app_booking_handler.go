@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +14,7 @@ import (
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
 )
 
 // AppBookingHandler handles passenger app booking operations
@@ -20,6 +23,11 @@ type AppBookingHandler struct {
 	tripRepo     *database.ScheduledTripRepository
 	tripSeatRepo *database.TripSeatRepository
 	routeRepo    *database.BusOwnerRouteRepository
+	reminders    *services.ReminderSchedulerService
+	snapshots    *services.BookingSnapshotService
+	emails       *services.BookingEmailService
+	qrService    *services.BookingQRService
+	cancellation models.CancellationPolicy
 	logger       *logrus.Logger
 }
 
@@ -29,6 +37,11 @@ func NewAppBookingHandler(
 	tripRepo *database.ScheduledTripRepository,
 	tripSeatRepo *database.TripSeatRepository,
 	routeRepo *database.BusOwnerRouteRepository,
+	reminders *services.ReminderSchedulerService,
+	snapshots *services.BookingSnapshotService,
+	emails *services.BookingEmailService,
+	qrService *services.BookingQRService,
+	cancellation models.CancellationPolicy,
 	logger *logrus.Logger,
 ) *AppBookingHandler {
 	return &AppBookingHandler{
@@ -36,6 +49,11 @@ func NewAppBookingHandler(
 		tripRepo:     tripRepo,
 		tripSeatRepo: tripSeatRepo,
 		routeRepo:    routeRepo,
+		reminders:    reminders,
+		snapshots:    snapshots,
+		emails:       emails,
+		qrService:    qrService,
+		cancellation: cancellation,
 		logger:       logger,
 	}
 }
@@ -202,6 +220,18 @@ func (h *AppBookingHandler) CreateBooking(c *gin.Context) {
 		return
 	}
 
+	// Queue boarding reminders (non-blocking for the booking itself)
+	if h.reminders != nil {
+		if err := h.reminders.ScheduleForBooking(response.Booking.ID, booking.UserID, trip.ID, trip.DepartureDatetime); err != nil {
+			h.logger.WithError(err).WithField("booking_id", response.Booking.ID).Warn("Failed to schedule boarding reminders")
+		}
+	}
+
+	// Freeze what was booked for receipts and disputes (also non-blocking)
+	if h.snapshots != nil {
+		h.snapshots.CaptureBestEffort(response.Booking.ID)
+	}
+
 	c.JSON(http.StatusCreated, response)
 }
 
@@ -227,7 +257,7 @@ func (h *AppBookingHandler) GetMyBookings(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	bookings, err := h.bookingRepo.GetBookingsByUserID(userCtx.UserID.String(), limit, offset)
+	bookings, err := h.bookingRepo.GetBookingsByUserID(userCtx.UserID.String(), middleware.GetTenant(c).ScopeID(), limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bookings"})
 		return
@@ -257,7 +287,7 @@ func (h *AppBookingHandler) GetUpcomingBookings(c *gin.Context) {
 		return
 	}
 
-	bookings, err := h.bookingRepo.GetUpcomingBookingsByUserID(userCtx.UserID.String())
+	bookings, err := h.bookingRepo.GetUpcomingBookingsByUserID(userCtx.UserID.String(), middleware.GetTenant(c).ScopeID())
 	if err != nil {
 		if h.logger != nil {
 			h.logger.WithError(err).WithField("user_id", userCtx.UserID.String()).Error("Failed to get upcoming bookings")
@@ -474,31 +504,252 @@ func (h *AppBookingHandler) CancelBooking(c *gin.Context) {
 		return
 	}
 
-	// Cancel booking
+	// Price the refund before cancelling, exactly as the preview does
+	quote, err := h.cancellationQuote(booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get booking"})
+		return
+	}
+
+	// Cancel booking, saving booking.cancelled to the outbox with it; the pending boarding
+	// reminders are de-scheduled by its consumer
 	reason := &req.Reason
 	if req.Reason == "" {
 		reason = nil
 	}
-	err = h.bookingRepo.CancelBooking(bookingID, userCtx.UserID.String(), reason)
+	data := models.BookingEventData{
+		BookingID:        booking.ID,
+		BookingReference: booking.BookingReference,
+		UserID:           booking.UserID,
+		TotalAmount:      booking.TotalAmount,
+		Currency:         quote.Currency,
+		Reason:           reason,
+		RefundAmount:     quote.RefundableAmount,
+		CancellationFee:  quote.Fee,
+	}
+	if booking.BusBooking != nil {
+		// The trip's waitlist is offered the released seats
+		data.ScheduledTripID = booking.BusBooking.ScheduledTripID
+		data.BusBookingID = booking.BusBooking.ID
+	}
+	cancelled, err := models.NewBookingCancelledEvent(data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking", "details": err.Error()})
+		return
+	}
+	err = h.bookingRepo.CancelBooking(bookingID, userCtx.UserID.String(), reason, cancelled)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking", "details": err.Error()})
 		return
 	}
 
-	// Check if refund is needed
-	refundNeeded := booking.IsPaid()
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Booking cancelled successfully",
+		"booking_id":       bookingID,
+		"refund_needed":    quote.RefundableAmount > 0,
+		"refund_amount":    quote.RefundableAmount,
+		"cancellation_fee": quote.Fee,
+	})
+}
+
+// CancelSeats cancels some seats of a group booking
+// @Summary Cancel seats of a booking
+// @Description Cancel some of a booking's seats and release them; the booking stays confirmed
+// @Description with the rest. Each seat is refunded its share of what was paid less the
+// @Description cancellation fee, which is requested with the returned seat cancellation's ID.
+// @Tags App Bookings
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body models.CancelSeatsRequest true "Seats to cancel"
+// @Success 200 {object} map[string]interface{} "Seats cancelled"
+// @Failure 400 {object} map[string]interface{} "Cannot cancel"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Failure 409 {object} map[string]interface{} "Seat changed meanwhile"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/bookings/{id}/seats/cancel [post]
+func (h *AppBookingHandler) CancelSeats(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.CancelSeatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	booking, err := h.bookingRepo.GetBookingByID(c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get booking"})
+		return
+	}
+
+	if booking.UserID != userCtx.UserID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return
+	}
+
+	if !booking.CanBeCancelled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Booking cannot be cancelled"})
+		return
+	}
+
+	seats, err := models.SeatsToCancel(booking.BusBooking, req.SeatIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	departure, err := h.bookingDeparture(booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get booking"})
+		return
+	}
+	quote := h.cancellation.QuoteSeats(booking, seats, departure, time.Now())
+
+	// The seats' refund is recorded with them; booking.seats_cancelled offers them to the
+	// trip's waitlist
+	reason := &req.Reason
+	if req.Reason == "" {
+		reason = nil
+	}
+	cancellation := models.NewBookingSeatCancellation(booking, quote, userCtx.UserID.String(), reason)
+	event, err := models.NewBookingSeatsCancelledEvent(models.BookingEventData{
+		BookingID:          booking.ID,
+		BookingReference:   booking.BookingReference,
+		UserID:             booking.UserID,
+		ScheduledTripID:    booking.BusBooking.ScheduledTripID,
+		BusBookingID:       booking.BusBooking.ID,
+		TotalAmount:        booking.TotalAmount,
+		Currency:           quote.Currency,
+		Reason:             reason,
+		RefundAmount:       quote.RefundableAmount,
+		CancellationFee:    quote.Fee,
+		SeatIDs:            cancellation.SeatIDs,
+		SeatCancellationID: cancellation.ID.String(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel seats", "details": err.Error()})
+		return
+	}
+	if err := h.bookingRepo.CancelSeats(cancellation, event); err != nil {
+		if errors.Is(err, database.ErrSeatsNotCancellable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel seats", "details": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "Booking cancelled successfully",
-		"booking_id":    bookingID,
-		"refund_needed": refundNeeded,
-		"refund_amount": booking.TotalAmount,
+		"message":           "Seats cancelled successfully",
+		"booking_id":        booking.ID,
+		"seat_cancellation": cancellation,
+		"seats":             quote.Seats,
+		"refund_needed":     quote.RefundableAmount > 0,
+		"refund_amount":     quote.RefundableAmount,
+		"cancellation_fee":  quote.Fee,
 	})
 }
 
+// GetCancellationPreview shows what cancelling a booking now would refund
+// @Summary Preview booking cancellation
+// @Description Refundable amount, fee and when the next fee tier starts if the booking were
+// @Description cancelled now. With seat_ids, previews cancelling only those seats instead.
+// @Tags App Bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param seat_ids query string false "Comma-separated bus booking seat IDs to cancel"
+// @Success 200 {object} models.CancellationQuote "Cancellation preview"
+// @Failure 400 {object} map[string]interface{} "Invalid seats"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/bookings/{id}/cancellation-preview [get]
+func (h *AppBookingHandler) GetCancellationPreview(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	booking, err := h.bookingRepo.GetBookingByID(c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get booking"})
+		return
+	}
+
+	if booking.UserID != userCtx.UserID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return
+	}
+
+	if seatIDs := c.Query("seat_ids"); seatIDs != "" {
+		seats, err := models.SeatsToCancel(booking.BusBooking, strings.Split(seatIDs, ","))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		departure, err := h.bookingDeparture(booking)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get booking"})
+			return
+		}
+		c.JSON(http.StatusOK, h.cancellation.QuoteSeats(booking, seats, departure, time.Now()))
+		return
+	}
+
+	quote, err := h.cancellationQuote(booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get booking"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// cancellationQuote prices cancelling the booking now against its bus trip's departure
+func (h *AppBookingHandler) cancellationQuote(booking *models.MasterBooking) (*models.CancellationQuote, error) {
+	departure, err := h.bookingDeparture(booking)
+	if err != nil {
+		return nil, err
+	}
+	return h.cancellation.Quote(booking, departure, time.Now()), nil
+}
+
+// bookingDeparture is the booking's bus trip departure, nil for bookings without a bus trip
+func (h *AppBookingHandler) bookingDeparture(booking *models.MasterBooking) (*time.Time, error) {
+	if booking.BusBooking == nil {
+		return nil, nil
+	}
+	trip, err := h.tripRepo.GetByID(booking.BusBooking.ScheduledTripID)
+	if err != nil {
+		h.logger.WithError(err).WithField("booking_id", booking.ID).Error("Failed to get trip for cancellation quote")
+		return nil, err
+	}
+	return &trip.DepartureDatetime, nil
+}
+
 // GetBookingQR retrieves QR code for a booking
 // @Summary Get booking QR code
-// @Description Get QR code data for boarding
+// @Description Get QR code data for boarding. signed_qr_code is the QR to display: conductor
+// @Description apps can verify it offline. qr_code is the unsigned code older apps show; it is
+// @Description also what to show if signed_qr_code is empty.
 // @Tags App Bookings
 // @Produce json
 // @Param id path string true "Booking ID"
@@ -536,8 +787,15 @@ func (h *AppBookingHandler) GetBookingQR(c *gin.Context) {
 		return
 	}
 
+	// Without a signature the app falls back to the unsigned code, verified online
+	signedQR, err := h.qrService.Sign(booking)
+	if err != nil {
+		h.logger.WithError(err).WithField("booking_id", booking.ID).Error("Failed to sign boarding QR")
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"qr_code":            *booking.BusBooking.QRCodeData,
+		"signed_qr_code":     signedQR,
 		"booking_reference":  booking.BookingReference,
 		"passenger_name":     booking.PassengerName,
 		"route_name":         booking.BusBooking.RouteName,
@@ -545,3 +803,182 @@ func (h *AppBookingHandler) GetBookingQR(c *gin.Context) {
 		"seats":              len(booking.BusBooking.Seats),
 	})
 }
+
+// GetBookingTickets retrieves one ticket per seat of a booking
+// @Summary Get per-passenger tickets
+// @Description Every seat of the booking with its passenger and its own boarding QR, so each
+// @Description passenger of a group can board on their own. signed_qr_code is the QR to show;
+// @Description ticket_code is shown if it is empty. Tickets are issued on first request.
+// @Tags App Bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} models.BookingTicketsResponse "Seat tickets"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/bookings/{id}/tickets [get]
+func (h *AppBookingHandler) GetBookingTickets(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	booking, err := h.bookingRepo.GetBookingByID(c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get booking"})
+		return
+	}
+
+	if booking.UserID != userCtx.UserID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return
+	}
+
+	bus := booking.BusBooking
+	if bus == nil || len(bus.Seats) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tickets not available"})
+		return
+	}
+
+	for _, seat := range bus.Seats {
+		if seat.TicketCode != nil {
+			continue
+		}
+		if err := h.bookingRepo.IssueSeatTickets(bus.ID); err != nil {
+			h.logger.WithError(err).WithField("booking_id", booking.ID).Error("Failed to issue seat tickets")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue tickets"})
+			return
+		}
+		if bus.Seats, err = h.bookingRepo.GetSeatsByBusBookingID(bus.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tickets"})
+			return
+		}
+		break
+	}
+
+	response := models.BookingTicketsResponse{
+		BookingID:         booking.ID,
+		BookingReference:  booking.BookingReference,
+		RouteName:         bus.RouteName,
+		DepartureDatetime: bus.DepartureDatetime,
+		Tickets:           make([]models.SeatTicket, 0, len(bus.Seats)),
+	}
+	for i := range bus.Seats {
+		seat := &bus.Seats[i]
+		ticket := models.SeatTicket{
+			SeatID:             seat.ID,
+			SeatNumber:         seat.SeatNumber,
+			SeatType:           seat.SeatType,
+			PassengerName:      seat.PassengerName,
+			IsPrimaryPassenger: seat.IsPrimaryPassenger,
+			Status:             seat.Status,
+		}
+		if seat.TicketCode != nil {
+			ticket.TicketCode = *seat.TicketCode
+		}
+		if seat.Status != models.SeatBookingCancelled && seat.TicketCode != nil {
+			// Without a signature the app falls back to the ticket code, verified online
+			if ticket.SignedQRCode, err = h.qrService.SignSeat(booking, seat); err != nil {
+				h.logger.WithError(err).WithField("seat_id", seat.ID).Error("Failed to sign seat ticket QR")
+			}
+		}
+		response.Tickets = append(response.Tickets, ticket)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBookingReceipt retrieves the receipt for a booking
+// @Summary Get booking receipt
+// @Description Get the receipt for a booking, with trip, route and fare details as they were at booking time
+// @Tags App Bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} models.BookingReceipt "Booking receipt"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Security BearerAuth
+// @Router /api/v1/bookings/{id}/receipt [get]
+func (h *AppBookingHandler) GetBookingReceipt(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	bookingID := c.Param("id")
+	booking, err := h.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get booking"})
+		return
+	}
+
+	if booking.UserID != userCtx.UserID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewBookingReceipt(booking))
+}
+
+// EmailBookingReceipt emails the receipt for a booking
+// @Summary Email booking receipt
+// @Description Email the receipt for a booking, with the PDF e-ticket unless the booking is cancelled, to the booking's email address or else the passenger profile's
+// @Tags App Bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} map[string]interface{} "Receipt sent"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Not found"
+// @Failure 422 {object} map[string]interface{} "No email address"
+// @Security BearerAuth
+// @Router /api/v1/bookings/{id}/receipt/email [post]
+func (h *AppBookingHandler) EmailBookingReceipt(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	bookingID := c.Param("id")
+	booking, err := h.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get booking"})
+		return
+	}
+
+	if booking.UserID != userCtx.UserID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+		return
+	}
+
+	sentTo, err := h.emails.SendReceipt(booking)
+	if err != nil {
+		if errors.Is(err, services.ErrNoBookingEmail) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Add an email address to your profile to receive receipts"})
+			return
+		}
+		h.logger.WithError(err).WithField("booking_id", booking.ID).Error("Failed to email booking receipt")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send receipt email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Receipt sent", "email": sentTo})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// AppConfigHandler serves the passenger app's remote config
+type AppConfigHandler struct {
+	appConfigService *services.AppConfigService
+	logger           *logrus.Logger
+}
+
+// NewAppConfigHandler creates a new AppConfigHandler
+func NewAppConfigHandler(appConfigService *services.AppConfigService, logger *logrus.Logger) *AppConfigHandler {
+	return &AppConfigHandler{
+		appConfigService: appConfigService,
+		logger:           logger,
+	}
+}
+
+// GetConfig returns minimum supported versions per platform, feature toggles and maintenance
+// flags. Apps sending X-Device-Type and X-App-Version also get their own version status.
+// GET /api/v1/app/config
+func (h *AppConfigHandler) GetConfig(c *gin.Context) {
+	cfg, err := h.appConfigService.Get()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to load app config")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to load app config"})
+		return
+	}
+
+	response := *cfg // The service's copy is shared, don't modify it
+	status, err := h.appConfigService.CheckAppVersion(c.GetHeader(middleware.AppPlatformHeader), c.GetHeader(middleware.AppVersionHeader))
+	if err == nil {
+		response.VersionStatus = status
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMaintenance returns the current maintenance mode
+// GET /api/v1/admin/maintenance
+func (h *AppConfigHandler) GetMaintenance(c *gin.Context) {
+	maintenance, err := h.appConfigService.MaintenanceStatus()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to load maintenance mode")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to load maintenance mode"})
+		return
+	}
+	c.JSON(http.StatusOK, maintenance)
+}
+
+// UpdateMaintenance switches maintenance mode on or off
+// PUT /api/v1/admin/maintenance
+func (h *AppConfigHandler) UpdateMaintenance(c *gin.Context) {
+	var req models.UpdateMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	maintenance, err := h.appConfigService.SetMaintenance(&req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update maintenance mode")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to update maintenance mode"})
+		return
+	}
+	c.JSON(http.StatusOK, maintenance)
+}
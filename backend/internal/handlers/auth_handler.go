@@ -1,18 +1,24 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/smarttransit/sms-auth-backend/internal/config"
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
 	"github.com/smarttransit/sms-auth-backend/internal/services"
 	"github.com/smarttransit/sms-auth-backend/internal/utils"
+	"github.com/smarttransit/sms-auth-backend/pkg/geoip"
 	"github.com/smarttransit/sms-auth-backend/pkg/jwt"
 	"github.com/smarttransit/sms-auth-backend/pkg/sms"
 	"github.com/smarttransit/sms-auth-backend/pkg/validator"
@@ -23,13 +29,20 @@ type AuthHandler struct {
 	jwtService             *jwt.Service
 	otpService             *services.OTPService
 	phoneValidator         *validator.PhoneValidator
-	rateLimitService       *services.RateLimitService
+	rateLimitService       services.OTPRateLimiter
 	auditService           *services.AuditService
 	userRepository         *database.UserRepository
 	passengerRepository    *database.PassengerRepository
 	refreshTokenRepository *database.RefreshTokenRepository
 	userSessionRepository  *database.UserSessionRepository
+	tenantRepository       *database.TenantRepository
 	smsGateway             sms.SMSGateway
+	smsQueue               *services.SMSQueueService
+	otpResend              *services.OTPResendService
+	otpTestNumbers         *services.OTPTestNumberService
+	geoLocator             *geoip.Locator
+	claimsCache            *services.UserClaimsCache
+	userPreferences        *services.UserPreferencesService
 	config                 *config.Config
 }
 
@@ -38,13 +51,20 @@ func NewAuthHandler(
 	jwtService *jwt.Service,
 	otpService *services.OTPService,
 	phoneValidator *validator.PhoneValidator,
-	rateLimitService *services.RateLimitService,
+	rateLimitService services.OTPRateLimiter,
 	auditService *services.AuditService,
 	userRepository *database.UserRepository,
 	passengerRepository *database.PassengerRepository,
 	refreshTokenRepository *database.RefreshTokenRepository,
 	userSessionRepository *database.UserSessionRepository,
+	tenantRepository *database.TenantRepository,
 	smsGateway sms.SMSGateway,
+	smsQueue *services.SMSQueueService,
+	otpResend *services.OTPResendService,
+	otpTestNumbers *services.OTPTestNumberService,
+	geoLocator *geoip.Locator,
+	claimsCache *services.UserClaimsCache,
+	userPreferences *services.UserPreferencesService,
 	cfg *config.Config,
 ) *AuthHandler {
 	return &AuthHandler{
@@ -57,7 +77,13 @@ func NewAuthHandler(
 		passengerRepository:    passengerRepository,
 		refreshTokenRepository: refreshTokenRepository,
 		userSessionRepository:  userSessionRepository,
+		tenantRepository:       tenantRepository,
 		smsGateway:             smsGateway,
+		smsQueue:               smsQueue,
+		otpResend:              otpResend,
+		otpTestNumbers:         otpTestNumbers,
+		geoLocator:             geoLocator,
+		userPreferences:        userPreferences,
 		config:                 cfg,
 	}
 }
@@ -65,7 +91,8 @@ func NewAuthHandler(
 // SendOTPRequest represents the request to send OTP
 type SendOTPRequest struct {
 	Phone   string `json:"phone_number" binding:"required"`
-	AppType string `json:"app_type"` // "passenger", "driver", "conductor", "lounge_owner"
+	AppType string `json:"app_type"`                                       // "passenger", "driver", "conductor", "lounge_owner"
+	Channel string `json:"channel" binding:"omitempty,oneof=sms whatsapp"` // Defaults to the user's preferred messaging_channel
 }
 
 // SendOTPResponse represents the response after sending OTP
@@ -128,24 +155,7 @@ func (h *AuthHandler) SendOTP(c *gin.Context) {
 	userAgent := utils.GetUserAgent(c)
 
 	// Check rate limiting
-	if err := h.rateLimitService.CheckOTPRateLimit(phone, clientIP); err != nil {
-		if rateLimitErr, ok := err.(*services.RateLimitError); ok {
-			// Log rate limit violation
-			h.auditService.LogRateLimitViolation(phone, clientIP, userAgent, rateLimitErr.Type, rateLimitErr.RetryAfter)
-
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":       "rate_limit_exceeded",
-				"message":     rateLimitErr.Message,
-				"retry_after": rateLimitErr.RetryAfter,
-				"type":        rateLimitErr.Type,
-			})
-			return
-		}
-		// Other errors
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "rate_limit_check_failed",
-			Message: "Failed to check rate limit",
-		})
+	if !h.checkOTPRateLimit(c, phone, clientIP, userAgent) {
 		return
 	}
 
@@ -153,7 +163,7 @@ func (h *AuthHandler) SendOTP(c *gin.Context) {
 	otp, err := h.otpService.GenerateOTP(phone, clientIP, userAgent)
 	if err != nil {
 		// Log failed OTP request
-		h.auditService.LogOTPRequest(phone, clientIP, userAgent, false, "generation_failed")
+		h.logOTPRequest(phone, clientIP, userAgent, false, "generation_failed")
 
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "otp_generation_failed",
@@ -163,76 +173,46 @@ func (h *AuthHandler) SendOTP(c *gin.Context) {
 	}
 
 	// Record rate limit request
-	if err := h.rateLimitService.RecordOTPRequest(phone, clientIP); err != nil {
-		// Log error but don't fail the request
-		// The OTP is already generated and stored
-		c.Error(err) // This logs the error in Gin
-	}
+	h.recordOTPRequest(c, phone, clientIP)
 
 	// Log successful OTP request
-	h.auditService.LogOTPRequest(phone, clientIP, userAgent, true, "")
+	h.logOTPRequest(phone, clientIP, userAgent, true, "")
 
 	// Get expiry time
 	expiresAt, _ := h.otpService.GetOTPExpiry(phone)
 	expiresIn := int(time.Until(expiresAt).Seconds())
 
-	// Check SMS configuration before attempting to send
-	if h.config.SMS.Mode == "production" {
-		// Validate SMS configuration
-		if h.config.SMS.Method == "url" && h.config.SMS.ESMSQK == "" {
-			log.Printf("❌ ERROR: SMS API key (DIALOG_SMS_ESMSQK) is not configured")
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "sms_not_configured",
-				"message": "SMS gateway is not properly configured. Please contact support.",
-				"details": "Dialog API key not set",
-			})
-			return
-		}
-
-		if h.config.SMS.Mask == "" {
-			log.Printf("❌ ERROR: SMS Mask (DIALOG_SMS_MASK) is not configured")
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "sms_not_configured",
-				"message": "SMS gateway is not properly configured. Please contact support.",
-				"details": "SMS Mask not set",
-			})
-			return
-		}
-	}
+	// Resend cooldowns are counted from this send
+	h.otpResend.RecordSend(phone)
 
 	// Send SMS based on mode
 	if h.config.SMS.Mode == "production" {
-		// Production mode: Send actual SMS via Dialog gateway
-		log.Printf("🔵 Attempting to send SMS to %s via Dialog gateway (App: %s)...", phone, req.AppType)
-		log.Printf("📝 SMS Method: %s", h.config.SMS.Method)
-		if h.config.SMS.Method == "url" {
-			log.Printf("📝 Using API Key: %s****", h.config.SMS.ESMSQK[:3])
-		}
-		log.Printf("📝 SMS Mask: %s", h.config.SMS.Mask)
-
-		transactionID, err := h.smsGateway.SendOTP(phone, otp, req.AppType)
-		if err != nil {
-			log.Printf("❌ ERROR: Failed to send SMS to %s: %v", phone, err)
-			log.Printf("❌ Error type: %T", err)
-			log.Printf("❌ Full error details: %+v", err)
-			errorMsg := fmt.Sprintf("Failed to send OTP: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "sms_send_failed",
-				"message": "Failed to send OTP via SMS. Please try again.",
-				"details": errorMsg,
+		// QA test numbers get no SMS; they sign in with the deploy's fixed OTP
+		if h.otpTestNumbers.IsTestNumber(phone) {
+			c.JSON(http.StatusOK, gin.H{
+				"message":    "OTP is being sent to your phone",
+				"phone":      phone,
+				"expires_at": expiresAt,
+				"expires_in": expiresIn,
+				"mode":       "production",
 			})
 			return
 		}
 
-		log.Printf("✅ SMS sent successfully to %s, transaction_id: %d", phone, transactionID)
+		jobID, ok := h.queueOTPDelivery(c, phone, otp, req.AppType, h.otpChannel(phone, req.Channel))
+		if !ok {
+			return
+		}
 
 		// Production response (without OTP)
 		c.JSON(http.StatusOK, gin.H{
-			"message":    "OTP sent successfully to your phone",
-			"phone":      phone,
-			"expires_at": expiresAt,
-			"expires_in": expiresIn,
-			"mode":       "production",
+			"message":         "OTP is being sent to your phone",
+			"phone":           phone,
+			"expires_at":      expiresAt,
+			"expires_in":      expiresIn,
+			"mode":            "production",
+			"delivery_id":     jobID,
+			"delivery_status": services.SMSDeliveryQueued,
 		})
 		return
 	}
@@ -248,6 +228,288 @@ func (h *AuthHandler) SendOTP(c *gin.Context) {
 	})
 }
 
+// otpChannel picks the channel to deliver an OTP over: the one asked for, else the messaging
+// channel an existing user chose in their preferences. The SMS queue falls back to SMS when WhatsApp is
+// not available.
+func (h *AuthHandler) otpChannel(phone, requested string) services.OTPChannel {
+	if requested != "" {
+		return services.OTPChannel(requested)
+	}
+	user, err := h.userRepository.GetUserByPhone(phone)
+	if err != nil {
+		log.Printf("WARNING: Failed to look up messaging channel for %s: %v", models.MaskPhone(phone, 3), err)
+		return services.OTPChannelSMS
+	}
+	if user != nil && h.userPreferences.PrefersWhatsApp(user.ID) {
+		return services.OTPChannelWhatsApp
+	}
+	return services.OTPChannelSMS
+}
+
+// checkOTPRateLimit applies the per-phone and per-IP OTP send limits, writing the response and
+// returning false when the request may not send
+func (h *AuthHandler) checkOTPRateLimit(c *gin.Context, phone, clientIP, userAgent string) bool {
+	err := h.rateLimitService.CheckOTPRateLimit(phone, clientIP)
+	if err == nil {
+		return true
+	}
+	if rateLimitErr, ok := err.(*services.RateLimitError); ok {
+		// Log rate limit violation
+		h.auditService.LogRateLimitViolation(phone, clientIP, userAgent, rateLimitErr.Type, rateLimitErr.RetryAfter)
+
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       "rate_limit_exceeded",
+			"message":     rateLimitErr.Message,
+			"retry_after": rateLimitErr.RetryAfter,
+			"type":        rateLimitErr.Type,
+		})
+		return false
+	}
+	// Other errors
+	c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "rate_limit_check_failed",
+		Message: "Failed to check rate limit",
+	})
+	return false
+}
+
+// recordOTPRequest counts an OTP send against the rate limits
+func (h *AuthHandler) recordOTPRequest(c *gin.Context, phone, clientIP string) {
+	if err := h.rateLimitService.RecordOTPRequest(phone, clientIP); err != nil {
+		// Log error but don't fail the request
+		// The OTP is already generated and stored
+		c.Error(err) // This logs the error in Gin
+	}
+}
+
+// logOTPRequest audits an OTP request, under the QA action for test numbers so they never
+// mix with real sign-ins
+func (h *AuthHandler) logOTPRequest(phone, ipAddress, userAgent string, success bool, reason string) {
+	if !h.otpTestNumbers.IsTestNumber(phone) {
+		h.auditService.LogOTPRequest(phone, ipAddress, userAgent, success, reason)
+		return
+	}
+	details := map[string]interface{}{"phone_masked": models.MaskPhone(phone, 3), "success": success}
+	if reason != "" {
+		details["reason"] = reason
+	}
+	h.auditService.LogOTPTestNumberEvent(nil, models.AuditQAOTPRequest, nil, ipAddress, userAgent, details)
+}
+
+// logOTPVerification audits an OTP verification, under the QA actions for test numbers
+func (h *AuthHandler) logOTPVerification(userID *uuid.UUID, phone string, success bool, attempts int, ipAddress, userAgent, failureReason string) {
+	if !h.otpTestNumbers.IsTestNumber(phone) {
+		h.auditService.LogOTPVerification(userID, phone, success, attempts, ipAddress, userAgent, failureReason)
+		return
+	}
+	action := models.AuditQAOTPVerifyFailed
+	details := map[string]interface{}{"phone_masked": models.MaskPhone(phone, 3), "attempts": attempts}
+	if success {
+		action = models.AuditQAOTPVerifySuccess
+	} else if failureReason != "" {
+		details["failure_reason"] = failureReason
+	}
+	h.auditService.LogOTPTestNumberEvent(userID, action, nil, ipAddress, userAgent, details)
+}
+
+// queueOTPDelivery validates the SMS configuration and queues the OTP for delivery.
+// On failure it writes the error response and returns false.
+func (h *AuthHandler) queueOTPDelivery(c *gin.Context, phone, otp, appType string, channel services.OTPChannel) (string, bool) {
+	// Validate SMS configuration
+	if h.config.SMS.Method == "url" && h.config.SMS.ESMSQK == "" {
+		log.Printf("❌ ERROR: SMS API key (DIALOG_SMS_ESMSQK) is not configured")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "sms_not_configured",
+			"message": "SMS gateway is not properly configured. Please contact support.",
+			"details": "Dialog API key not set",
+		})
+		return "", false
+	}
+
+	if h.config.SMS.Mask == "" {
+		log.Printf("❌ ERROR: SMS Mask (DIALOG_SMS_MASK) is not configured")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "sms_not_configured",
+			"message": "SMS gateway is not properly configured. Please contact support.",
+			"details": "SMS Mask not set",
+		})
+		return "", false
+	}
+
+	// Production mode: Send actual SMS via Dialog gateway
+	log.Printf("🔵 Queueing OTP to %s via Dialog gateway (App: %s, Channel: %s)...", phone, appType, channel)
+	log.Printf("📝 SMS Method: %s", h.config.SMS.Method)
+	if h.config.SMS.Method == "url" {
+		log.Printf("📝 Using API Key: %s****", h.config.SMS.ESMSQK[:3])
+	}
+	log.Printf("📝 SMS Mask: %s", h.config.SMS.Mask)
+
+	// Delivery happens on the SMS queue so a slow gateway never holds up the request;
+	// clients poll otp-status for sms_delivery progress
+	// White-label tenants send from their own mask and name
+	var branding sms.OTPBranding
+	if tenant := middleware.GetTenant(c); !tenant.IsDefault() {
+		branding = sms.OTPBranding{Mask: tenant.SenderMask(), AppName: tenant.AppName}
+	}
+	jobID, err := h.smsQueue.EnqueueBrandedOTP(phone, otp, appType, channel, branding)
+	if err != nil {
+		log.Printf("❌ ERROR: Failed to queue SMS to %s: %v", phone, err)
+		c.Header("Retry-After", "30")
+		if errors.Is(err, services.ErrSMSQueueFull) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "sms_queue_full",
+				"message": "We are sending a lot of codes right now. Please try again in a minute.",
+			})
+			return "", false
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "sms_unavailable",
+			"message": "SMS service is temporarily unavailable. Please try again in a minute.",
+		})
+		return "", false
+	}
+
+	log.Printf("✅ SMS queued for %s, job_id: %s", phone, jobID)
+	return jobID, true
+}
+
+// ResendOTPRequest represents the request to resend an OTP
+type ResendOTPRequest struct {
+	Phone   string `json:"phone_number" binding:"required"`
+	AppType string `json:"app_type"`
+}
+
+// ResendOTP handles POST /api/v1/auth/resend-otp
+// The active OTP is re-sent while it is still fresh and regenerated only once it is about
+// to expire; with no active OTP nothing is sent. Resends count against the send-otp rate
+// limits and are also spaced by an escalating cooldown.
+func (h *AuthHandler) ResendOTP(c *gin.Context) {
+	var req ResendOTPRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	// Validate phone number
+	phone, err := h.phoneValidator.Validate(req.Phone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_phone",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	clientIP := utils.GetRealIP(c)
+	userAgent := utils.GetUserAgent(c)
+
+	// A resend sends an SMS like send-otp does, so it counts against the same per-phone and
+	// per-IP limits
+	if !h.checkOTPRateLimit(c, phone, clientIP, userAgent) {
+		return
+	}
+
+	resendNumber, err := h.otpResend.Reserve(phone)
+	if err != nil {
+		var cooldownErr *services.ResendCooldownError
+		switch {
+		case errors.As(err, &cooldownErr):
+			c.Header("Retry-After", strconv.Itoa(cooldownErr.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "resend_cooldown",
+				"message":     cooldownErr.Error(),
+				"retry_after": cooldownErr.RetryAfter,
+			})
+		case errors.Is(err, services.ErrMaxResendsExceeded):
+			h.logOTPRequest(phone, clientIP, userAgent, false, "max_resends_exceeded")
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":   "max_resends_exceeded",
+				"message": "Too many resend requests. Please request a new code.",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "resend_failed",
+				Message: "Failed to resend OTP",
+			})
+		}
+		return
+	}
+
+	otp, reused, err := h.otpService.ResendOTP(phone, clientIP, userAgent)
+	if errors.Is(err, services.ErrNoActiveOTP) {
+		h.otpResend.Release(phone)
+		h.logOTPRequest(phone, clientIP, userAgent, false, "resend_no_active_otp")
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "no_active_otp",
+			Message: "There is no code to resend. Please request a new code.",
+		})
+		return
+	}
+	if err != nil {
+		h.otpResend.Release(phone)
+		h.logOTPRequest(phone, clientIP, userAgent, false, "resend_generation_failed")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "otp_generation_failed",
+			Message: "Failed to generate OTP",
+		})
+		return
+	}
+
+	h.recordOTPRequest(c, phone, clientIP)
+	h.logOTPRequest(phone, clientIP, userAgent, true, fmt.Sprintf("resend_%d", resendNumber))
+
+	expiresAt, _ := h.otpService.GetOTPExpiry(phone)
+	expiresIn := int(time.Until(expiresAt).Seconds())
+
+	// From the second resend on, try WhatsApp in case SMS is not getting through
+	channel := services.OTPChannelSMS
+	if h.config.OTP.ResendWhatsAppFallback && resendNumber >= 2 && h.smsQueue.SupportsWhatsApp() {
+		channel = services.OTPChannelWhatsApp
+	}
+
+	response := gin.H{
+		"phone":          phone,
+		"expires_at":     expiresAt,
+		"expires_in":     expiresIn,
+		"reused":         reused,
+		"resend_count":   resendNumber,
+		"channel":        channel,
+		"next_resend_in": int(h.otpResend.NextResendIn(phone).Seconds()),
+	}
+
+	if h.config.SMS.Mode == "production" {
+		if h.otpTestNumbers.IsTestNumber(phone) {
+			response["message"] = "OTP is being resent to your phone"
+			response["mode"] = "production"
+			c.JSON(http.StatusOK, response)
+			return
+		}
+
+		jobID, ok := h.queueOTPDelivery(c, phone, otp, req.AppType, channel)
+		if !ok {
+			h.otpResend.Release(phone)
+			return
+		}
+
+		response["message"] = "OTP is being resent to your phone"
+		response["mode"] = "production"
+		response["delivery_id"] = jobID
+		response["delivery_status"] = services.SMSDeliveryQueued
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Development mode: Return OTP in response (no actual SMS sent)
+	response["message"] = "OTP resent successfully (dev mode - no SMS sent)"
+	response["otp"] = otp // Only in development mode
+	response["mode"] = "development"
+	c.JSON(http.StatusOK, response)
+}
+
 // VerifyOTP handles POST /api/v1/auth/verify-otp
 func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	var req VerifyOTPRequest
@@ -282,7 +544,7 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	if err != nil {
 		// Log failed verification
 		attempts := 3 - remainingBefore + 1 // Calculated attempts made
-		h.auditService.LogOTPVerification(nil, phone, false, attempts, clientIP, userAgent, err.Error())
+		h.logOTPVerification(nil, phone, false, attempts, clientIP, userAgent, err.Error())
 
 		// Check specific error types
 		switch err {
@@ -330,7 +592,7 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	if !valid {
 		// Log invalid OTP
 		attempts := 3 - remainingBefore + 1
-		h.auditService.LogOTPVerification(nil, phone, false, attempts, clientIP, userAgent, "invalid_code")
+		h.logOTPVerification(nil, phone, false, attempts, clientIP, userAgent, "invalid_code")
 
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "otp_invalid",
@@ -350,6 +612,13 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 		return
 	}
 
+	// Users who sign up through a white-label app belong to that tenant
+	if tenant := middleware.GetTenant(c); isNew && !tenant.IsDefault() {
+		if err := h.tenantRepository.SetUserTenantIfUnset(user.ID, tenant.ID); err != nil {
+			log.Printf("WARNING: Failed to assign user %s to tenant %s: %v", user.ID, tenant.Slug, err)
+		}
+	}
+
 	// For users with passenger role, ensure passenger record exists
 	// This creates the passenger profile record in the passengers table
 	if h.userRepository.HasRole(user, "passenger") {
@@ -412,7 +681,7 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	}
 
 	// Log successful OTP verification and login
-	h.auditService.LogOTPVerification(&user.ID, phone, true, 3-remainingBefore+1, clientIP, userAgent, "")
+	h.logOTPVerification(&user.ID, phone, true, 3-remainingBefore+1, clientIP, userAgent, "")
 	h.auditService.LogLogin(user.ID, phone, clientIP, userAgent, deviceID, deviceType)
 
 	// Create or update user session
@@ -422,7 +691,7 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	fcmToken := c.GetHeader("X-FCM-Token")
 
 	if deviceID != "" && deviceType != "" {
-		_, err = h.userSessionRepository.CreateOrUpdateSession(
+		session, err := h.userSessionRepository.CreateOrUpdateSession(
 			user.ID,
 			deviceID,
 			deviceType,
@@ -435,6 +704,8 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 		if err != nil {
 			// Log error but don't fail the login
 			log.Printf("WARNING: Failed to create/update session for user %s: %v", user.ID, err)
+		} else {
+			h.recordSessionLocation(session, clientIP)
 		}
 	}
 
@@ -485,7 +756,7 @@ func (h *AuthHandler) VerifyOTPStaff(c *gin.Context) {
 	if err != nil {
 		// Log failed verification
 		attempts := 3 - remainingBefore + 1
-		h.auditService.LogOTPVerification(nil, phone, false, attempts, clientIP, userAgent, err.Error())
+		h.logOTPVerification(nil, phone, false, attempts, clientIP, userAgent, err.Error())
 
 		// Check specific error types
 		switch err {
@@ -533,7 +804,7 @@ func (h *AuthHandler) VerifyOTPStaff(c *gin.Context) {
 	if !valid {
 		// Log invalid OTP
 		attempts := 3 - remainingBefore + 1
-		h.auditService.LogOTPVerification(nil, phone, false, attempts, clientIP, userAgent, "invalid_code")
+		h.logOTPVerification(nil, phone, false, attempts, clientIP, userAgent, "invalid_code")
 
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "otp_invalid",
@@ -627,7 +898,7 @@ func (h *AuthHandler) VerifyOTPStaff(c *gin.Context) {
 	}
 
 	// Log successful OTP verification and login (staff app)
-	h.auditService.LogOTPVerification(&user.ID, phone, true, 3-remainingBefore+1, clientIP, userAgent, "")
+	h.logOTPVerification(&user.ID, phone, true, 3-remainingBefore+1, clientIP, userAgent, "")
 	h.auditService.LogLogin(user.ID, phone, clientIP, userAgent, deviceID, deviceType)
 
 	// Create or update user session
@@ -637,7 +908,7 @@ func (h *AuthHandler) VerifyOTPStaff(c *gin.Context) {
 	fcmToken := c.GetHeader("X-FCM-Token")
 
 	if deviceID != "" && deviceType != "" {
-		_, err = h.userSessionRepository.CreateOrUpdateSession(
+		session, err := h.userSessionRepository.CreateOrUpdateSession(
 			user.ID,
 			deviceID,
 			deviceType,
@@ -650,6 +921,8 @@ func (h *AuthHandler) VerifyOTPStaff(c *gin.Context) {
 		if err != nil {
 			// Log error but don't fail the login
 			log.Printf("WARNING: Failed to create/update session for user %s: %v", user.ID, err)
+		} else {
+			h.recordSessionLocation(session, clientIP)
 		}
 	}
 
@@ -700,7 +973,7 @@ func (h *AuthHandler) VerifyOTPLoungeOwner(c *gin.Context, loungeOwnerRepo *data
 	if err != nil {
 		// Log failed verification
 		attempts := 3 - remainingBefore + 1
-		h.auditService.LogOTPVerification(nil, phone, false, attempts, clientIP, userAgent, err.Error())
+		h.logOTPVerification(nil, phone, false, attempts, clientIP, userAgent, err.Error())
 
 		// Check specific error types
 		switch err {
@@ -748,7 +1021,7 @@ func (h *AuthHandler) VerifyOTPLoungeOwner(c *gin.Context, loungeOwnerRepo *data
 	if !valid {
 		// Log invalid OTP
 		attempts := 3 - remainingBefore + 1
-		h.auditService.LogOTPVerification(nil, phone, false, attempts, clientIP, userAgent, "invalid_code")
+		h.logOTPVerification(nil, phone, false, attempts, clientIP, userAgent, "invalid_code")
 
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "otp_invalid",
@@ -881,7 +1154,7 @@ func (h *AuthHandler) VerifyOTPLoungeOwner(c *gin.Context, loungeOwnerRepo *data
 	}
 
 	// Log successful OTP verification and login (lounge owner app)
-	h.auditService.LogOTPVerification(&user.ID, phone, true, 3-remainingBefore+1, clientIP, userAgent, "")
+	h.logOTPVerification(&user.ID, phone, true, 3-remainingBefore+1, clientIP, userAgent, "")
 	h.auditService.LogLogin(user.ID, phone, clientIP, userAgent, deviceID, deviceType)
 
 	// Create or update user session
@@ -891,7 +1164,7 @@ func (h *AuthHandler) VerifyOTPLoungeOwner(c *gin.Context, loungeOwnerRepo *data
 	fcmToken := c.GetHeader("X-FCM-Token")
 
 	if deviceID != "" && deviceType != "" {
-		_, err = h.userSessionRepository.CreateOrUpdateSession(
+		session, err := h.userSessionRepository.CreateOrUpdateSession(
 			user.ID,
 			deviceID,
 			deviceType,
@@ -904,6 +1177,8 @@ func (h *AuthHandler) VerifyOTPLoungeOwner(c *gin.Context, loungeOwnerRepo *data
 		if err != nil {
 			// Log error but don't fail the login
 			log.Printf("WARNING: Failed to create/update session for user %s: %v", user.ID, err)
+		} else {
+			h.recordSessionLocation(session, clientIP)
 		}
 	}
 
@@ -943,6 +1218,11 @@ func (h *AuthHandler) GetOTPStatus(c *gin.Context) {
 		return
 	}
 
+	// Delivery progress of the latest queued SMS (production mode only)
+	if delivery, ok := h.smsQueue.GetStatus(phone); ok {
+		stats["sms_delivery"] = delivery
+	}
+
 	c.JSON(http.StatusOK, stats)
 }
 
@@ -964,6 +1244,9 @@ type ProfileResponse struct {
 	Status           string   `json:"status"`
 	PhoneVerified    bool     `json:"phone_verified"`
 	EmailVerified    bool     `json:"email_verified"`
+
+	// Onboarding progress and next-step hint (passengers only)
+	Completeness *models.ProfileCompleteness `json:"completeness,omitempty"`
 }
 
 // UpdateProfileRequest represents the request to update profile
@@ -1084,6 +1367,8 @@ func (h *AuthHandler) CompleteBasicProfile(c *gin.Context) {
 	if passenger.Email.Valid {
 		response.Email = &passenger.Email.String
 	}
+	completeness := passenger.Completeness()
+	response.Completeness = &completeness
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Profile completed successfully",
@@ -1180,6 +1465,10 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 			// No passenger record yet, profile not completed
 			response.ProfileCompleted = false
 		}
+
+		// A nil passenger scores as an empty profile
+		completeness := passenger.Completeness()
+		response.Completeness = &completeness
 	} else {
 		// For non-passenger roles, use legacy user table data (will be migrated later)
 		response.ProfileCompleted = user.ProfileCompleted
@@ -1367,6 +1656,123 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	})
 }
 
+// nicPattern matches old (9 digits + V/X) and new (12 digits) Sri Lankan NIC numbers
+var nicPattern = regexp.MustCompile(`^([0-9]{9}[VX]|[0-9]{12})$`)
+
+// UpdateOnboardingDetailsRequest carries the optional passenger onboarding steps.
+// Only provided fields are updated.
+type UpdateOnboardingDetailsRequest struct {
+	Email                 *string `json:"email" binding:"omitempty,email"`
+	NIC                   *string `json:"nic"`
+	EmergencyContactName  *string `json:"emergency_contact_name" binding:"omitempty,min=1,max=100"`
+	EmergencyContactPhone *string `json:"emergency_contact_phone"`
+}
+
+// UpdateOnboardingDetails handles PATCH /api/v1/user/profile
+// Lets passengers fill in optional profile steps (email, NIC, emergency contact) one at a time
+func (h *AuthHandler) UpdateOnboardingDetails(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User context not found",
+		})
+		return
+	}
+
+	var req UpdateOnboardingDetailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.NIC != nil {
+		nic := strings.ToUpper(strings.TrimSpace(*req.NIC))
+		if !nicPattern.MatchString(nic) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_nic",
+				Message: "NIC must be 9 digits followed by V or X, or 12 digits",
+			})
+			return
+		}
+		req.NIC = &nic
+	}
+
+	// Emergency contact is a single step, so name and phone must come together
+	if (req.EmergencyContactName == nil) != (req.EmergencyContactPhone == nil) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "emergency_contact_name and emergency_contact_phone must be provided together",
+		})
+		return
+	}
+	var contactPhone string
+	if req.EmergencyContactPhone != nil {
+		phone, err := h.phoneValidator.Validate(*req.EmergencyContactPhone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_emergency_contact_phone",
+				Message: err.Error(),
+			})
+			return
+		}
+		contactPhone = phone
+	}
+
+	if req.Email == nil && req.NIC == nil && req.EmergencyContactName == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "At least one field must be provided",
+		})
+		return
+	}
+
+	if _, _, err := h.passengerRepository.GetOrCreatePassenger(userCtx.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "passenger_creation_failed",
+			Message: "Failed to create passenger record",
+		})
+		return
+	}
+
+	if req.Email != nil || req.NIC != nil {
+		if err := h.passengerRepository.UpdatePassengerIdentity(userCtx.UserID, req.Email, req.NIC); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "profile_update_failed",
+				Message: "Failed to update passenger record",
+			})
+			return
+		}
+	}
+	if req.EmergencyContactName != nil {
+		name := strings.TrimSpace(*req.EmergencyContactName)
+		if err := h.passengerRepository.UpdatePassengerEmergencyContact(userCtx.UserID, name, contactPhone); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "profile_update_failed",
+				Message: "Failed to update emergency contact",
+			})
+			return
+		}
+	}
+
+	passenger, err := h.passengerRepository.GetPassengerByUserID(userCtx.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "profile_retrieval_failed",
+			Message: "Failed to retrieve passenger profile",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Profile updated successfully",
+		"completeness": passenger.Completeness(),
+	})
+}
+
 // RefreshTokenRequest represents the request to refresh access token
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
@@ -1544,6 +1950,71 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
+// RefreshClaimsResponse is a re-issued access token carrying the user's current roles
+type RefreshClaimsResponse struct {
+	AccessToken string   `json:"access_token"`
+	ExpiresIn   int      `json:"expires_in_seconds"`
+	TokenType   string   `json:"token_type"`
+	Roles       []string `json:"roles"`
+}
+
+// RefreshClaims exchanges a valid access token for a new one with the user's current roles, e.g.
+// after a bus owner approves a driver. Profile completion is carried over from the current token,
+// since which profile it reflects depends on the app the user signed in to. The refresh token is
+// untouched.
+// POST /api/v1/auth/refresh-claims
+func (h *AuthHandler) RefreshClaims(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User context not found",
+		})
+		return
+	}
+
+	h.claimsCache.Invalidate(userCtx.UserID)
+	user, err := h.userRepository.GetUserByID(userCtx.UserID)
+	if err != nil {
+		log.Printf("❌ REFRESH CLAIMS ERROR: Failed to fetch user %s - %v", userCtx.UserID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "user_fetch_failed",
+			Message: "Failed to fetch user information",
+		})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "user_not_found",
+			Message: "User no longer exists",
+		})
+		return
+	}
+	if user.Status != "active" {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "user_inactive",
+			Message: "User account is not active",
+		})
+		return
+	}
+
+	accessToken, err := h.jwtService.GenerateAccessToken(user.ID, user.Phone, user.Roles, userCtx.ProfileCompleted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "token_generation_failed",
+			Message: "Failed to generate new access token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RefreshClaimsResponse{
+		AccessToken: accessToken,
+		ExpiresIn:   int(h.jwtService.AccessTokenExpiry().Seconds()),
+		TokenType:   "Bearer",
+		Roles:       user.Roles,
+	})
+}
+
 // LogoutRequest represents the request to logout
 type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token"`
@@ -1671,3 +2142,76 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		"message": "Successfully logged out",
 	})
 }
+
+// recordSessionLocation stores where a session's IP address is located (best-effort)
+func (h *AuthHandler) recordSessionLocation(session *models.UserSession, clientIP string) {
+	if !h.geoLocator.Enabled() {
+		return
+	}
+	if err := h.userSessionRepository.UpdateSessionLocation(session.ID, h.geoLocator.Lookup(clientIP)); err != nil {
+		log.Printf("WARNING: Failed to record location of session %s: %v", session.ID, err)
+	}
+}
+
+// ListSessions handles GET /api/v1/auth/sessions
+// Lists the caller's active sessions (devices) with a readable location for each
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User context not found",
+		})
+		return
+	}
+
+	h.respondSessions(c, userCtx.UserID)
+}
+
+// ListUserSessions handles GET /api/v1/admin/users/:id/sessions
+// Lets support see where a user's active sessions are located
+func (h *AuthHandler) ListUserSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_user_id",
+			Message: "Invalid user ID",
+		})
+		return
+	}
+
+	h.respondSessions(c, userID)
+}
+
+func (h *AuthHandler) respondSessions(c *gin.Context, userID uuid.UUID) {
+	sessions, err := h.userSessionRepository.GetActiveSessions(userID)
+	if err != nil {
+		log.Printf("ERROR: Failed to list sessions for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "sessions_error",
+			Message: "Failed to list sessions",
+		})
+		return
+	}
+
+	for _, session := range sessions {
+		session.FCMToken = models.NullString{} // Push tokens are not for display
+		session.Location = sessionLocation(session).String()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions, "count": len(sessions)})
+}
+
+// sessionLocation rebuilds the stored geolocation of a session
+func sessionLocation(session *models.UserSession) *geoip.Location {
+	location := &geoip.Location{
+		CountryCode: session.GeoCountryCode.String,
+		Country:     session.GeoCountry.String,
+		City:        session.GeoCity.String,
+		ASOrg:       session.GeoASOrg.String,
+	}
+	if session.GeoASN != nil {
+		location.ASN = uint(*session.GeoASN)
+	}
+	return location
+}
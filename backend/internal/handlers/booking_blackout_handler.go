@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// BookingBlackoutHandler handles owner blackouts of online sales and the admin override
+type BookingBlackoutHandler struct {
+	blackoutService *services.BookingBlackoutService
+	busOwnerRepo    *database.BusOwnerRepository
+	logger          *logrus.Logger
+}
+
+// NewBookingBlackoutHandler creates a new BookingBlackoutHandler
+func NewBookingBlackoutHandler(
+	blackoutService *services.BookingBlackoutService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *BookingBlackoutHandler {
+	return &BookingBlackoutHandler{
+		blackoutService: blackoutService,
+		busOwnerRepo:    busOwnerRepo,
+		logger:          logger,
+	}
+}
+
+// ============================================================================
+// OWNER ENDPOINTS
+// ============================================================================
+
+// GetBlackouts lists the owner's current and upcoming blackouts
+// GET /api/v1/bus-owner/booking-blackouts?include_ended=true
+func (h *BookingBlackoutHandler) GetBlackouts(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	blackouts, err := h.blackoutService.ListOwnerBlackouts(busOwnerID, c.Query("include_ended") == "true")
+	if err != nil {
+		h.respondBlackoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blackouts": blackouts})
+}
+
+// CreateBlackout pauses online sales for one of the owner's routes or schedules
+// POST /api/v1/bus-owner/booking-blackouts
+func (h *BookingBlackoutHandler) CreateBlackout(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := middleware.GetUserContext(c)
+
+	var req models.CreateBookingBlackoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	blackout, err := h.blackoutService.CreateBlackout(busOwnerID, userCtx.UserID, &req)
+	if err != nil {
+		h.respondBlackoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Online booking paused. Existing bookings are kept.", "blackout": blackout})
+}
+
+// LiftBlackout ends one of the owner's blackouts early
+// POST /api/v1/bus-owner/booking-blackouts/:id/lift
+func (h *BookingBlackoutHandler) LiftBlackout(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	blackout, err := h.blackoutService.LiftBlackout(busOwnerID, c.Param("id"))
+	if err != nil {
+		h.respondBlackoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Online booking reopened", "blackout": blackout})
+}
+
+func (h *BookingBlackoutHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+// ============================================================================
+// ADMIN ENDPOINTS
+// ============================================================================
+
+// ListBlackouts lists blackouts across all owners
+// GET /api/v1/admin/booking-blackouts?status=active&limit=50&offset=0
+func (h *BookingBlackoutHandler) ListBlackouts(c *gin.Context) {
+	status := models.BookingBlackoutStatus(c.Query("status"))
+	switch status {
+	case "", models.BookingBlackoutActive, models.BookingBlackoutLifted, models.BookingBlackoutOverridden:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "status must be active, lifted or overridden"})
+		return
+	}
+	limit, offset := payoutPagination(c)
+
+	blackouts, total, err := h.blackoutService.ListBlackouts(string(status), limit, offset)
+	if err != nil {
+		h.respondBlackoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blackouts": blackouts, "total": total, "limit": limit, "offset": offset})
+}
+
+// OverrideBlackout reopens online sales for a blacked out route or schedule
+// POST /api/v1/admin/booking-blackouts/:id/override
+func (h *BookingBlackoutHandler) OverrideBlackout(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.OverrideBookingBlackoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	blackout, err := h.blackoutService.OverrideBlackout(c.Param("id"), userCtx.UserID, req.Reason)
+	if err != nil {
+		h.respondBlackoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Blackout overridden, online booking reopened", "blackout": blackout})
+}
+
+func (h *BookingBlackoutHandler) respondBlackoutError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrBlackoutNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "blackout_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrBlackoutTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "target_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrBlackoutNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": "blackout_not_active", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Booking blackout request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Booking blackout request failed"})
+	}
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -15,6 +17,7 @@ import (
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
 	"github.com/smarttransit/sms-auth-backend/internal/services"
+	"github.com/smarttransit/sms-auth-backend/pkg/loadshed"
 )
 
 // BookingOrchestratorHandler handles booking intent and confirmation endpoints
@@ -22,6 +25,9 @@ type BookingOrchestratorHandler struct {
 	orchestratorService *services.BookingOrchestratorService
 	payableService      *services.PAYableService
 	paymentAuditRepo    *database.PaymentAuditRepository
+	webhookEventRepo    *database.PaymentWebhookEventRepository
+	walletService       *services.WalletService
+	intentLimiter       *loadshed.Limiter
 	logger              *logrus.Logger
 }
 
@@ -30,12 +36,18 @@ func NewBookingOrchestratorHandler(
 	orchestratorService *services.BookingOrchestratorService,
 	payableService *services.PAYableService,
 	paymentAuditRepo *database.PaymentAuditRepository,
+	webhookEventRepo *database.PaymentWebhookEventRepository,
+	walletService *services.WalletService,
+	intentLimiter *loadshed.Limiter,
 	logger *logrus.Logger,
 ) *BookingOrchestratorHandler {
 	return &BookingOrchestratorHandler{
 		orchestratorService: orchestratorService,
 		payableService:      payableService,
 		paymentAuditRepo:    paymentAuditRepo,
+		webhookEventRepo:    webhookEventRepo,
+		walletService:       walletService,
+		intentLimiter:       intentLimiter,
 		logger:              logger,
 	}
 }
@@ -55,7 +67,9 @@ func NewBookingOrchestratorHandler(
 // @Success 201 {object} models.BookingIntentResponse
 // @Failure 400 {object} map[string]interface{} "Validation error or seats unavailable"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
-// @Failure 409 {object} models.PartialAvailabilityError "Partial availability"
+// @Failure 403 {object} map[string]interface{} "Trip has a waiting room and the user is not admitted"
+// @Failure 409 {object} models.PartialAvailabilityError "Partial availability, online booking blacked out for the trip, or the passenger already has a booking on it"
+// @Failure 429 {object} map[string]interface{} "Too many concurrent intents for the trip, or intent creation is shedding load"
 // @Router /booking/intent [post]
 func (h *BookingOrchestratorHandler) CreateIntent(c *gin.Context) {
 	// Get user context from middleware
@@ -73,6 +87,32 @@ func (h *BookingOrchestratorHandler) CreateIntent(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
 		return
 	}
+	if req.PreTripLounge != nil || req.PostTripLounge != nil {
+		if req.Bus != nil {
+			middleware.TagUsageFeature(c, models.UsageFeatureLoungeAddOn)
+		} else {
+			middleware.TagUsageFeature(c, models.UsageFeatureLoungeBooking)
+		}
+	}
+
+	// Limit concurrent holds on one trip and shed load when intent creation is backed up
+	if h.intentLimiter != nil {
+		tripID := ""
+		if req.Bus != nil {
+			tripID = req.Bus.ScheduledTripID
+		}
+		release, err := h.intentLimiter.Acquire(c.Request.Context(), tripID)
+		if err != nil {
+			c.Header("Retry-After", strconv.Itoa(int(h.intentLimiter.RetryAfter().Seconds())))
+			code, message := "overloaded", "Booking is very busy right now. Please try again in a few seconds."
+			if errors.Is(err, loadshed.ErrKeyBusy) {
+				code, message = "trip_busy", "Too many people are booking this trip right now. Please try again in a few seconds."
+			}
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": code, "message": message})
+			return
+		}
+		defer release()
+	}
 
 	// Create intent
 	response, err := h.orchestratorService.CreateIntent(userID, &req)
@@ -87,6 +127,29 @@ func (h *BookingOrchestratorHandler) CreateIntent(c *gin.Context) {
 			})
 			return
 		}
+		var duplicateErr *models.DuplicateBookingError
+		if errors.As(err, &duplicateErr) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":                 "duplicate_booking",
+				"confirmation_required": len(duplicateErr.OverlappingSeats) == 0,
+				"existing_bookings":     duplicateErr.Existing,
+				"overlapping_seats":     duplicateErr.OverlappingSeats,
+				"message":               duplicateErr.Message,
+			})
+			return
+		}
+		if errors.Is(err, services.ErrQueueAdmissionRequired) || errors.Is(err, services.ErrQueueTokenNotAdmitted) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "queue_admission_required", "message": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrTripBlackedOut) {
+			c.JSON(http.StatusConflict, gin.H{"error": "booking_blackout", "message": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrSeatsHeldForWaitlist) {
+			c.JSON(http.StatusConflict, gin.H{"error": "seats_held_for_waitlist", "message": err.Error()})
+			return
+		}
 
 		h.logger.WithError(err).Error("Failed to create booking intent")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -102,16 +165,20 @@ func (h *BookingOrchestratorHandler) CreateIntent(c *gin.Context) {
 
 // InitiatePayment initiates payment for a booking intent
 // @Summary Initiate payment for intent
-// @Description Returns payment gateway URL and details
+// @Description Returns payment gateway URL and details. With use_wallet, part or all of the total is taken from the passenger's wallet first; when it covers everything, paid_in_full is set and the intent can be confirmed without a gateway payment.
 // @Tags Booking Orchestration
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param intent_id path string true "Intent ID"
+// @Param request body models.InitiatePaymentRequest false "Wallet payment"
 // @Success 200 {object} models.InitiatePaymentResponse
 // @Failure 400 {object} map[string]interface{} "Intent expired or invalid state"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Intent is for another operator's trip"
 // @Failure 404 {object} map[string]interface{} "Intent not found"
+// @Failure 409 {object} models.PriceDriftError "Seat prices changed and must be accepted"
+// @Failure 503 {object} map[string]interface{} "Payment gateway temporarily unavailable"
 // @Router /booking/intent/{intent_id}/initiate-payment [post]
 func (h *BookingOrchestratorHandler) InitiatePayment(c *gin.Context) {
 	// Get user context from middleware
@@ -131,8 +198,16 @@ func (h *BookingOrchestratorHandler) InitiatePayment(c *gin.Context) {
 		return
 	}
 
+	var req models.InitiatePaymentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
 	// Initiate payment
-	response, err := h.orchestratorService.InitiatePayment(intentID, userID)
+	response, err := h.orchestratorService.InitiatePayment(intentID, userID, middleware.GetTenant(c), &req)
 	if err != nil {
 		if err.Error() == "intent not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -142,6 +217,25 @@ func (h *BookingOrchestratorHandler) InitiatePayment(c *gin.Context) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
+		if h.respondPriceDrift(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrWalletInsufficientBalance) {
+			c.JSON(http.StatusConflict, gin.H{"error": "insufficient_wallet_balance", "message": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrIntentTenantMismatch) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "tenant_mismatch", "message": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrPaymentUnavailable) {
+			c.Header("Retry-After", "30")
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "payment_unavailable",
+				"message": "Online payment is temporarily unavailable. Your seats are still held - please try again shortly.",
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -149,6 +243,65 @@ func (h *BookingOrchestratorHandler) InitiatePayment(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// ============================================================================
+// ACCEPT PRICES - POST /api/v1/booking/intent/:intent_id/accept-prices
+// ============================================================================
+
+// AcceptIntentPrices re-locks a held intent at the current seat prices
+// @Summary Accept changed seat prices
+// @Description Used after initiate-payment or confirm returns price_changed. Updates the held intent to the trip's current seat prices so payment can continue.
+// @Tags Booking Orchestration
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param intent_id path string true "Intent ID"
+// @Success 200 {object} models.BookingIntentResponse "Intent with updated prices"
+// @Failure 400 {object} map[string]interface{} "Intent expired or payment already started"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Intent not found"
+// @Router /booking/intent/{intent_id}/accept-prices [post]
+func (h *BookingOrchestratorHandler) AcceptIntentPrices(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	intentID, err := uuid.Parse(c.Param("intent_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid intent_id"})
+		return
+	}
+
+	response, err := h.orchestratorService.AcceptIntentPrices(intentID, userCtx.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "intent not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "unauthorized: intent belongs to another user":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondPriceDrift writes a 409 price_changed response if err is a price drift
+func (h *BookingOrchestratorHandler) respondPriceDrift(c *gin.Context, err error) bool {
+	driftErr, ok := err.(*models.PriceDriftError)
+	if !ok {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error":       "price_changed",
+		"message":     driftErr.Message,
+		"price_drift": driftErr.Drift,
+	})
+	return true
+}
+
 // ============================================================================
 // CONFIRM BOOKING - POST /api/v1/booking/confirm
 // ============================================================================
@@ -167,6 +320,7 @@ func (h *BookingOrchestratorHandler) InitiatePayment(c *gin.Context) {
 // @Failure 402 {object} map[string]interface{} "Payment not verified"
 // @Failure 404 {object} map[string]interface{} "Intent not found"
 // @Failure 409 {object} map[string]interface{} "Seats no longer available"
+// @Failure 503 {object} map[string]interface{} "Payment gateway temporarily unavailable"
 // @Router /booking/confirm [post]
 func (h *BookingOrchestratorHandler) ConfirmBooking(c *gin.Context) {
 	// Get user context from middleware
@@ -210,6 +364,21 @@ func (h *BookingOrchestratorHandler) ConfirmBooking(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, services.ErrIntentPaymentNotVerified) {
+			c.JSON(http.StatusPaymentRequired, gin.H{
+				"error":   "payment_not_verified",
+				"message": "Payment has not been completed yet. Please try again once it has gone through.",
+			})
+			return
+		}
+		if errors.Is(err, services.ErrPaymentUnavailable) {
+			c.Header("Retry-After", "30")
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "payment_unavailable",
+				"message": "Your payment could not be checked right now - please try again shortly.",
+			})
+			return
+		}
 
 		h.logger.WithError(err).Error("Failed to confirm booking")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -407,8 +576,9 @@ func (h *BookingOrchestratorHandler) AddLoungeToIntent(c *gin.Context) {
 // ============================================================================
 // PAYMENT WEBHOOK - POST /api/v1/payments/webhook
 // Industry-standard implementation with:
-// - Audit logging of ALL events
-// - Idempotency (duplicate detection)
+// - Authentication by re-querying the payment with CheckStatus (notifications are not signed)
+// - Audit logging of ALL events, and every delivery kept in payment_webhook_events
+// - Idempotency (duplicate detection on UID + status, single-claim confirmation)
 // - Amount verification
 // - Proper error handling without silent failures
 // ============================================================================
@@ -417,24 +587,44 @@ func (h *BookingOrchestratorHandler) AddLoungeToIntent(c *gin.Context) {
 // @Summary Payment webhook callback
 // @Description Called by payment gateway (PAYable) to notify of payment status.
 //
-//	This endpoint verifies payment with the gateway, validates amounts,
-//	and confirms bookings with full audit trail.
+//	The notification is not signature checked: it is authenticated by re-querying the payment
+//	with the gateway's CheckStatus API, and only that answer is acted on. Amounts are validated
+//	and bookings confirmed with full audit trail. Repeated deliveries of the
+//	same UID and status are acknowledged with duplicate=true and not processed again.
 //
 // @Tags Booking Orchestration
 // @Accept json
 // @Produce json
-// @Param uid query string true "Payment UID from PAYable"
-// @Param statusIndicator query string true "Status indicator from PAYable"
-// @Success 200 {object} map[string]interface{} "Webhook processed"
+// @Param uid query string false "Payment UID from PAYable, when not in the body"
+// @Param statusIndicator query string false "Status indicator from PAYable, when not in the body"
+// @Param request body services.PAYableWebhookPayload false "Payment notification"
+// @Success 200 {object} map[string]interface{} "Webhook processed, or duplicate acknowledged"
 // @Failure 400 {object} map[string]interface{} "Invalid webhook"
+// @Failure 500 {object} map[string]interface{} "Merchant account could not be determined; delivered again"
 // @Router /payments/webhook [post]
 func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 	ctx := context.Background()
 	startTime := time.Now()
 
-	// Extract request metadata
+	// Extract request metadata. Notifications carry uid and statusIndicator in the body or in
+	// the query string.
+	body, _ := io.ReadAll(c.Request.Body)
+	var payload *services.PAYableWebhookPayload
+	if len(body) > 0 && h.payableService != nil {
+		payload, _ = h.payableService.ParseWebhook(body)
+	}
 	uid := c.Query("uid")
 	statusIndicator := c.Query("statusIndicator")
+	notifiedStatus := ""
+	if payload != nil {
+		if payload.UID != "" {
+			uid = payload.UID
+		}
+		if payload.StatusIndicator != "" {
+			statusIndicator = payload.StatusIndicator
+		}
+		notifiedStatus = strings.ToUpper(payload.PaymentStatus)
+	}
 	clientIP := c.ClientIP()
 	userAgent := c.GetHeader("User-Agent")
 	correlationID := c.GetHeader("X-Correlation-ID")
@@ -451,12 +641,13 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 	h.logger.WithFields(logrus.Fields{
 		"uid":              uid,
 		"status_indicator": statusIndicator,
+		"payment_status":   notifiedStatus,
 		"correlation_id":   correlationID,
 	}).Info("PAYable webhook received")
 
 	// Validate query params
 	if uid == "" || statusIndicator == "" {
-		h.logger.Warn("Webhook missing uid or statusIndicator query params")
+		h.logger.Warn("Webhook missing uid or statusIndicator")
 		webhookAudit.SetError("missing uid or statusIndicator", nil)
 		h.logAudit(ctx, webhookAudit, startTime)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -466,33 +657,10 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		return
 	}
 
-	// Check for duplicate webhook (idempotency)
-	if h.paymentAuditRepo != nil {
-		isDuplicate, err := h.paymentAuditRepo.CheckDuplicate(ctx, uid, models.PaymentEventSuccess, fmt.Sprintf("%s-success", uid))
-		if err != nil {
-			h.logger.WithError(err).Warn("Failed to check for duplicate webhook")
-		} else if isDuplicate {
-			h.logger.WithFields(logrus.Fields{
-				"uid":            uid,
-				"correlation_id": correlationID,
-			}).Info("Duplicate webhook detected - already processed successfully")
-			webhookAudit.MarkAsDuplicate()
-			h.logAudit(ctx, webhookAudit, startTime)
-			c.JSON(http.StatusOK, gin.H{
-				"message":        "webhook already processed",
-				"duplicate":      true,
-				"correlation_id": correlationID,
-			})
-			return
-		}
-	}
-
-	// Log the webhook receipt
-	h.logAudit(ctx, webhookAudit, startTime)
-
 	// Verify PAYable service is configured
 	if h.payableService == nil {
 		h.logger.Error("PAYable service not configured")
+		h.logAudit(ctx, webhookAudit, startTime)
 		errorAudit := models.NewPaymentAudit(models.PaymentEventError, models.PaymentSourceBackend)
 		errorAudit.SetPaymentUID(uid)
 		errorAudit.SetError("payment service not configured", nil)
@@ -505,6 +673,74 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		return
 	}
 
+	// The payment is checked with the merchant account it was taken on. The notification does
+	// not say which, so it is that of the intent paid for (found by UID, else by invoice ID);
+	// wallet top-ups are paid into the platform account.
+	intent, err := h.orchestratorService.GetIntentByPaymentUID(uid)
+	if err == nil && intent == nil && payload != nil && payload.InvoiceID != "" {
+		intent, err = h.orchestratorService.GetIntentByPaymentReference(payload.InvoiceID)
+	}
+	payable := h.payableService
+	if err == nil && intent != nil {
+		payable, err = h.orchestratorService.PayableForIntent(intent.ID)
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("uid", uid).Error("Failed to get merchant account for webhook")
+		webhookAudit.SetError("failed to get merchant account: "+err.Error(), nil)
+		h.logAudit(ctx, webhookAudit, startTime)
+		// Not acknowledged, so the gateway delivers it again
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":          "failed to get merchant account",
+			"correlation_id": correlationID,
+		})
+		return
+	}
+
+	event := models.NewPaymentWebhookEvent(uid, notifiedStatus)
+	event.StatusIndicator = &statusIndicator
+	event.ClientIP = &clientIP
+	event.CorrelationID = &correlationID
+	if payload != nil && payload.InvoiceID != "" {
+		event.InvoiceID = &payload.InvoiceID
+	}
+	if len(body) > 0 {
+		rawBody := string(body)
+		event.RawBody = &rawBody
+	}
+
+	// Record the delivery; a repeat of one already processed is acknowledged without
+	// being processed again (idempotency)
+	recorded := h.recordWebhookEvent(event)
+	if recorded && event.IsDuplicate() {
+		h.logger.WithFields(logrus.Fields{
+			"uid":            uid,
+			"payment_status": notifiedStatus,
+			"duplicate_of":   event.DuplicateOf,
+			"correlation_id": correlationID,
+		}).Info("Duplicate webhook detected - already processed")
+		webhookAudit.MarkAsDuplicate()
+		h.logAudit(ctx, webhookAudit, startTime)
+		c.JSON(http.StatusOK, gin.H{
+			"message":        "webhook already processed",
+			"duplicate":      true,
+			"correlation_id": correlationID,
+		})
+		return
+	}
+
+	// Whatever path this delivery takes, record how it ended. Failed deliveries are
+	// processed again when the gateway retries them.
+	outcome := models.PaymentWebhookFailed
+	var outcomeNote string
+	if recorded {
+		defer func() {
+			h.finishWebhookEvent(event, outcome, outcomeNote)
+		}()
+	}
+
+	// Log the webhook receipt
+	h.logAudit(ctx, webhookAudit, startTime)
+
 	// Call PAYable CheckStatus API to get actual payment result
 	statusCheckAudit := models.NewPaymentAudit(models.PaymentEventStatusCheckRequest, models.PaymentSourceBackend)
 	statusCheckAudit.SetPaymentUID(uid)
@@ -517,11 +753,10 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 	// Try status check with retry for sandbox (sometimes returns empty status)
 	var statusResp *services.PAYableStatusResponse
 	var rawBody string
-	var err error
 	maxRetries := 3
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		statusResp, rawBody, err = h.payableService.CheckStatusWithRawResponse(uid, statusIndicator)
+		statusResp, rawBody, err = payable.CheckStatusWithRawResponse(uid, statusIndicator)
 		if err != nil {
 			break // Fatal error, don't retry
 		}
@@ -554,6 +789,7 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		h.logger.WithError(err).Error("Failed to check payment status from PAYable")
 		statusRespAudit.SetError(err.Error(), nil)
 		h.logAudit(ctx, statusRespAudit, startTime)
+		outcomeNote = "status check failed: " + err.Error()
 		c.JSON(http.StatusOK, gin.H{
 			"error":          "failed to verify payment status",
 			"acknowledged":   true,
@@ -583,8 +819,21 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		"correlation_id": correlationID,
 	}).Info("PAYable status check response")
 
-	// FIRST: Look up intent by payment UID to check if already confirmed
-	intent, err := h.orchestratorService.GetIntentByPaymentUID(uid)
+	// Wallet top-ups are paid through the same merchant account and notified here too
+	if err == nil && intent == nil && h.walletService != nil {
+		topUp, topUpErr := h.walletService.GetTopUpByPaymentUID(uid)
+		if topUpErr != nil {
+			h.logger.WithError(topUpErr).WithField("uid", uid).Error("Failed to look up wallet top-up for webhook")
+		} else if topUp != nil {
+			outcome, outcomeNote = h.settleTopUp(ctx, uid, topUp, statusResp, startTime)
+			c.JSON(http.StatusOK, gin.H{
+				"message":        "webhook acknowledged",
+				"note":           outcomeNote,
+				"correlation_id": correlationID,
+			})
+			return
+		}
+	}
 
 	// Check if booking was already confirmed (by Flutter via return URL)
 	// This handles the race condition where Flutter confirms before webhook completes
@@ -602,6 +851,7 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		alreadyConfirmedAudit.SetPaymentStatus("ALREADY_CONFIRMED")
 		alreadyConfirmedAudit.SetIdempotencyKey(fmt.Sprintf("%s-already-confirmed", uid))
 		h.logAudit(ctx, alreadyConfirmedAudit, startTime)
+		outcome, outcomeNote = models.PaymentWebhookProcessed, "booking already confirmed"
 
 		c.JSON(http.StatusOK, gin.H{
 			"message":        "webhook acknowledged",
@@ -626,12 +876,15 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		switch paymentStatus {
 		case "FAILED":
 			eventType = models.PaymentEventFailed
+			outcome = models.PaymentWebhookProcessed
 		case "CANCELLED":
 			eventType = models.PaymentEventCancelled
+			outcome = models.PaymentWebhookProcessed
 		default:
-			// Still pending or unknown
+			// Still pending or unknown; a later notification is processed again
 			eventType = models.PaymentEventError
 		}
+		outcomeNote = "payment status " + statusResp.GetPaymentStatus()
 		failAudit := models.NewPaymentAudit(eventType, models.PaymentSourcePayableAPI)
 		failAudit.SetPaymentUID(uid)
 		failAudit.SetPaymentStatus(statusResp.GetPaymentStatus())
@@ -658,6 +911,7 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		notFoundAudit.SetPaymentUID(uid)
 		notFoundAudit.SetError("intent not found - may be duplicate or already processed", nil)
 		h.logAudit(ctx, notFoundAudit, startTime)
+		outcome, outcomeNote = models.PaymentWebhookProcessed, "intent not found"
 
 		c.JSON(http.StatusOK, gin.H{
 			"message":        "webhook acknowledged",
@@ -667,13 +921,11 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		return
 	}
 
-	// CRITICAL: Verify amount matches what we expect
-	expectedAmount := intent.TotalAmount
-	var receivedAmount float64
-	receivedAmountStr := statusResp.GetAmount()
-	if receivedAmountStr != "" {
-		receivedAmount, _ = strconv.ParseFloat(receivedAmountStr, 64)
-	}
+	// CRITICAL: Verify amount matches what we expect; any wallet part was never sent to the gateway
+	expectedAmount := intent.AmountDue().Float64()
+	// An amount missing or unreadable reads as zero, which never matches
+	received, _ := models.ParseMoney(statusResp.GetAmount())
+	receivedAmount := received.Float64()
 
 	// Create success audit BEFORE confirming
 	successAudit := models.NewPaymentAudit(models.PaymentEventSuccess, models.PaymentSourcePayableAPI)
@@ -705,6 +957,7 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 			nil,
 		)
 		h.logAudit(ctx, successAudit, startTime)
+		outcome, outcomeNote = models.PaymentWebhookProcessed, "amount mismatch - requires review"
 
 		c.JSON(http.StatusOK, gin.H{
 			"error":           "amount verification failed",
@@ -726,18 +979,32 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		"correlation_id": correlationID,
 	}).Info("Confirming booking from webhook - amount verified")
 
-	bookingResult, err := h.orchestratorService.ConfirmBooking(
-		intent.ID,
-		intent.UserID,
-		&statusResp.TransactionID,
-	)
+	bookingResult, err := h.orchestratorService.ConfirmPaidBooking(intent.ID, intent.UserID)
 
+	if errors.Is(err, services.ErrIntentConfirmationInProgress) {
+		// The client's return-URL confirmation got there first
+		h.logger.WithFields(logrus.Fields{
+			"intent_id":      intent.ID,
+			"uid":            uid,
+			"correlation_id": correlationID,
+		}).Info("Booking confirmation already in progress - acknowledging webhook")
+		outcome, outcomeNote = models.PaymentWebhookProcessed, "confirmation already in progress"
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":        "webhook acknowledged",
+			"note":           "booking confirmation already in progress",
+			"intent_id":      intent.ID,
+			"correlation_id": correlationID,
+		})
+		return
+	}
 	if err != nil {
 		h.logger.WithError(err).WithFields(logrus.Fields{
 			"intent_id":      intent.ID,
 			"uid":            uid,
 			"correlation_id": correlationID,
 		}).Error("CRITICAL: Failed to confirm booking from webhook - payment received but booking failed")
+		outcomeNote = "booking confirmation failed: " + err.Error()
 
 		// Log the confirmation failure - THIS NEEDS MANUAL INTERVENTION
 		failAudit := models.NewPaymentAudit(models.PaymentEventBookingConfirmFailed, models.PaymentSourceBackend)
@@ -770,6 +1037,7 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 		"transaction_id": statusResp.TransactionID,
 		"correlation_id": correlationID,
 	}).Info("Booking confirmed via webhook successfully")
+	outcome = models.PaymentWebhookProcessed
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":           "webhook processed successfully",
@@ -779,6 +1047,81 @@ func (h *BookingOrchestratorHandler) PaymentWebhook(c *gin.Context) {
 	})
 }
 
+// recordWebhookEvent stores a webhook delivery, reporting whether it was stored
+func (h *BookingOrchestratorHandler) recordWebhookEvent(event *models.PaymentWebhookEvent) bool {
+	if h.webhookEventRepo == nil {
+		return false
+	}
+	if err := h.webhookEventRepo.Record(event); err != nil {
+		h.logger.WithError(err).WithField("payment_uid", event.PaymentUID).Error("Failed to record payment webhook delivery")
+		return false
+	}
+	return true
+}
+
+// settleTopUp credits a wallet top-up PAYable reports paid, or fails one it reports failed or
+// cancelled, and returns how the delivery ended. A top-up whose amount does not match is left
+// pending for review.
+func (h *BookingOrchestratorHandler) settleTopUp(
+	ctx context.Context,
+	uid string,
+	topUp *models.WalletTopUp,
+	statusResp *services.PAYableStatusResponse,
+	startTime time.Time,
+) (models.PaymentWebhookOutcome, string) {
+	paymentStatus := strings.ToUpper(statusResp.GetPaymentStatus())
+	switch paymentStatus {
+	case "SUCCESS":
+	case "FAILED", "CANCELLED":
+		if err := h.walletService.FailTopUp(topUp, "payment "+strings.ToLower(paymentStatus)); err != nil {
+			h.logger.WithError(err).WithField("top_up_id", topUp.ID).Error("Failed to record failed wallet top-up")
+			return models.PaymentWebhookFailed, "failed to record wallet top-up failure: " + err.Error()
+		}
+		return models.PaymentWebhookProcessed, "wallet top-up payment " + strings.ToLower(paymentStatus)
+	default:
+		// Still pending or unknown; a later notification is processed again
+		return models.PaymentWebhookFailed, "wallet top-up payment status " + statusResp.GetPaymentStatus()
+	}
+
+	received, _ := models.ParseMoney(statusResp.GetAmount())
+	receivedAmount := received.Float64()
+	audit := models.NewPaymentAudit(models.PaymentEventSuccess, models.PaymentSourcePayableAPI)
+	audit.SetPaymentUID(uid)
+	audit.SetPaymentReference(topUp.InvoiceID)
+	audit.SetPaymentStatus(statusResp.GetPaymentStatus())
+	audit.SetIdempotencyKey(fmt.Sprintf("%s-success", uid))
+	if !audit.SetAmounts(topUp.Amount.Float64(), receivedAmount, topUp.Currency) {
+		h.logger.WithFields(logrus.Fields{
+			"uid":             uid,
+			"top_up_id":       topUp.ID,
+			"expected_amount": topUp.Amount,
+			"received_amount": receivedAmount,
+		}).Error("CRITICAL: Amount mismatch in wallet top-up - BLOCKING credit")
+		audit.EventType = models.PaymentEventError
+		audit.SetError(fmt.Sprintf("wallet top-up amount mismatch: expected %s, received %.2f", topUp.Amount, receivedAmount), nil)
+		h.logAudit(ctx, audit, startTime)
+		return models.PaymentWebhookProcessed, "wallet top-up amount mismatch - requires review"
+	}
+	h.logAudit(ctx, audit, startTime)
+
+	if _, err := h.walletService.CompleteTopUp(topUp); err != nil {
+		h.logger.WithError(err).WithField("top_up_id", topUp.ID).Error("CRITICAL: Failed to credit paid wallet top-up")
+		return models.PaymentWebhookFailed, "wallet top-up credit failed: " + err.Error()
+	}
+	return models.PaymentWebhookProcessed, "wallet top-up credited"
+}
+
+// finishWebhookEvent records how a webhook delivery ended
+func (h *BookingOrchestratorHandler) finishWebhookEvent(event *models.PaymentWebhookEvent, outcome models.PaymentWebhookOutcome, note string) {
+	var notePtr *string
+	if note != "" {
+		notePtr = &note
+	}
+	if err := h.webhookEventRepo.Finish(event.ID, outcome, notePtr); err != nil {
+		h.logger.WithError(err).WithField("payment_uid", event.PaymentUID).Error("Failed to update payment webhook delivery")
+	}
+}
+
 // logAudit is a helper to log audit entries without blocking
 func (h *BookingOrchestratorHandler) logAudit(ctx context.Context, audit *models.PaymentAudit, startTime time.Time) {
 	if h.paymentAuditRepo == nil {
@@ -902,3 +1245,219 @@ func (h *BookingOrchestratorHandler) GetMyIntents(c *gin.Context) {
 		"offset":  offset,
 	})
 }
+
+// ============================================================================
+// RESUME ON ANOTHER DEVICE - GET /api/v1/booking/intent/active
+// ============================================================================
+
+// GetActiveIntent returns the user's most recent live intent so checkout can resume on any device
+// @Summary Get my active booking intent
+// @Description Returns the most recent held or payment_pending intent that has not expired, with its full payload
+// @Tags Booking Orchestration
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} models.ActiveIntentResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "No active intent"
+// @Router /booking/intent/active [get]
+func (h *BookingOrchestratorHandler) GetActiveIntent(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	response, err := h.orchestratorService.GetActiveIntent(userCtx.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get active intent")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get active intent"})
+		return
+	}
+	if response == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no active intent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateIntentHandoff issues a single-use QR/deep-link token for continuing an intent on another device
+// @Summary Hand off booking intent to another device
+// @Description Returns a short-lived token, deep link and QR payload. The token can only be claimed by the same user.
+// @Tags Booking Orchestration
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param intent_id path string true "Intent ID"
+// @Success 201 {object} models.IntentHandoffResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Intent not found"
+// @Failure 409 {object} map[string]interface{} "Intent no longer holding seats"
+// @Router /booking/intent/{intent_id}/handoff [post]
+func (h *BookingOrchestratorHandler) CreateIntentHandoff(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	intentID, err := uuid.Parse(c.Param("intent_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid intent_id"})
+		return
+	}
+
+	response, err := h.orchestratorService.CreateIntentHandoff(intentID, userCtx.UserID)
+	if err != nil {
+		h.respondHandoffError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ClaimIntentHandoff resumes an intent on this device from a handoff token
+// @Summary Claim booking intent handoff
+// @Description Consumes a handoff token issued to the same user and returns the intent for resuming checkout
+// @Tags Booking Orchestration
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body models.ClaimIntentHandoffRequest true "Handoff token"
+// @Success 200 {object} models.ActiveIntentResponse
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 410 {object} map[string]interface{} "Token invalid, expired or used"
+// @Router /booking/intent/handoff/claim [post]
+func (h *BookingOrchestratorHandler) ClaimIntentHandoff(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req models.ClaimIntentHandoffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	response, err := h.orchestratorService.ClaimIntentHandoff(req.Token, userCtx.UserID)
+	if err != nil {
+		h.respondHandoffError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *BookingOrchestratorHandler) respondHandoffError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrIntentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIntentNotResumable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidHandoffToken):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Booking intent handoff failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process handoff"})
+	}
+}
+
+// ============================================================================
+// EXTEND HOLD - POST /api/v1/booking/intent/{intent_id}/extend
+// ============================================================================
+
+// ExtendIntent keeps an intent's seat and lounge holds alive while the passenger finishes paying
+// @Summary Extend booking intent hold
+// @Description Adds the configured extension to the hold's expiry. Each intent can only be extended a limited number of times.
+// @Tags Booking Orchestration
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param intent_id path string true "Intent ID"
+// @Success 200 {object} models.IntentExtensionResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Intent not found"
+// @Failure 409 {object} map[string]interface{} "Intent no longer holding seats"
+// @Failure 422 {object} map[string]interface{} "Extension limit reached"
+// @Router /booking/intent/{intent_id}/extend [post]
+func (h *BookingOrchestratorHandler) ExtendIntent(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	intentID, err := uuid.Parse(c.Param("intent_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid intent_id"})
+		return
+	}
+
+	response, err := h.orchestratorService.ExtendIntent(intentID, userCtx.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrIntentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrIntentNotResumable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrIntentExtensionLimit):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			h.logger.WithError(err).Error("Failed to extend booking intent")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to extend intent"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ============================================================================
+// FARE QUOTE - GET /api/v1/scheduled-trips/:id/fare-quote
+// ============================================================================
+
+// GetFareQuote previews the bus fare for a trip before login, without creating an intent
+// @Summary Get a non-binding fare quote
+// @Description Prices a seat count or picked trip seats the same way intents are priced; nothing is held
+// @Tags Booking Orchestration
+// @Produce json
+// @Param id path string true "Scheduled trip ID"
+// @Param boarding_stop query string false "Boarding stop ID"
+// @Param alighting_stop query string false "Alighting stop ID"
+// @Param seats query string false "Seat count (default 1) or comma-separated trip seat IDs"
+// @Param seat_type query string false "Seat type to quote when seats is a count"
+// @Success 200 {object} models.FareQuote
+// @Failure 400 {object} map[string]interface{} "Invalid seats or stops"
+// @Failure 404 {object} map[string]interface{} "Trip not found or not bookable"
+// @Failure 409 {object} map[string]interface{} "Trip departed, closed or short of seats"
+// @Router /scheduled-trips/{id}/fare-quote [get]
+func (h *BookingOrchestratorHandler) GetFareQuote(c *gin.Context) {
+	var req models.FareQuoteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	quote, err := h.orchestratorService.QuoteBusFare(c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidFareQuoteSeats),
+			errors.Is(err, services.ErrFareQuoteSeatsNotFound),
+			errors.Is(err, services.ErrInvalidFareQuoteStops):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrFareQuoteTripNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTripNotBookable),
+			errors.Is(err, services.ErrTripDeparted),
+			errors.Is(err, services.ErrFareQuoteNotEnoughSeats):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.WithError(err).Error("Failed to quote bus fare")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to quote fare"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
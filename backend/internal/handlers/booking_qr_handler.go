@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
+)
+
+// BookingQRHandler publishes the boarding QR verification keys and rotates the signing key
+type BookingQRHandler struct {
+	qrService    *services.BookingQRService
+	auditService *services.AuditService
+	logger       *logrus.Logger
+}
+
+// NewBookingQRHandler creates a new BookingQRHandler
+func NewBookingQRHandler(qrService *services.BookingQRService, auditService *services.AuditService, logger *logrus.Logger) *BookingQRHandler {
+	return &BookingQRHandler{
+		qrService:    qrService,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// GetVerificationKeys returns the public keys conductor apps verify boarding QRs with offline.
+// Apps should refresh them at the start of each shift.
+// GET /api/v1/staff/qr-keys
+func (h *BookingQRHandler) GetVerificationKeys(c *gin.Context) {
+	keys, err := h.qrService.VerificationKeys()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to load boarding QR verification keys")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to load verification keys"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+// RotateKey retires the active signing key and starts signing with a new one. QRs signed
+// with the old key stay valid for BOOKING_QR_KEY_RETENTION_DAYS.
+// POST /api/v1/admin/qr-keys/rotate
+func (h *BookingQRHandler) RotateKey(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	key, err := h.qrService.RotateKey()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to rotate boarding QR signing key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to rotate signing key"})
+		return
+	}
+
+	if err := h.auditService.LogQRSigningKeyRotated(userCtx.UserID, key.KeyID, utils.GetRealIP(c), utils.GetUserAgent(c)); err != nil {
+		h.logger.WithError(err).Warn("Failed to audit boarding QR key rotation")
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Signing key rotated", "key": key})
+}
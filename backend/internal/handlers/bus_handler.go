@@ -10,19 +10,22 @@ import (
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
 )
 
 type BusHandler struct {
 	busRepo      *database.BusRepository
 	permitRepo   *database.RoutePermitRepository
 	busOwnerRepo *database.BusOwnerRepository
+	seatCapacity *services.SeatCapacityService
 }
 
-func NewBusHandler(busRepo *database.BusRepository, permitRepo *database.RoutePermitRepository, busOwnerRepo *database.BusOwnerRepository) *BusHandler {
+func NewBusHandler(busRepo *database.BusRepository, permitRepo *database.RoutePermitRepository, busOwnerRepo *database.BusOwnerRepository, seatCapacity *services.SeatCapacityService) *BusHandler {
 	return &BusHandler{
 		busRepo:      busRepo,
 		permitRepo:   permitRepo,
 		busOwnerRepo: busOwnerRepo,
+		seatCapacity: seatCapacity,
 	}
 }
 
@@ -98,6 +101,9 @@ func (h *BusHandler) GetBusByID(c *gin.Context) {
 
 	// Get bus
 	bus, err := h.busRepo.GetByID(busID)
+	if err == nil && bus.DeletedAt != nil {
+		err = sql.ErrNoRows // A deleted bus is only visible to admins
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Bus not found"})
@@ -156,6 +162,9 @@ func (h *BusHandler) CreateBus(c *gin.Context) {
 
 	// Verify permit exists and belongs to this owner
 	permit, err := h.permitRepo.GetByID(req.PermitID)
+	if err == nil && permit.DeletedAt != nil {
+		err = sql.ErrNoRows // A deleted permit is only visible to admins
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Permit not found"})
@@ -225,6 +234,8 @@ func (h *BusHandler) CreateBus(c *gin.Context) {
 		InsuranceExpiry:     insuranceExpiry,
 		Status:              status,
 		SeatLayoutID:        req.SeatLayoutID,
+		SeatingCapacity:     req.SeatingCapacity,
+		StandingCapacity:    req.StandingCapacity,
 		HasWifi:             req.HasWifi,
 		HasAC:               req.HasAC,
 		HasChargingPorts:    req.HasChargingPorts,
@@ -232,6 +243,11 @@ func (h *BusHandler) CreateBus(c *gin.Context) {
 		HasRefreshments:     req.HasRefreshments,
 	}
 
+	// The seat layout must fit the bus's registered and the permit's approved capacity
+	if respondSeatCapacity(c, h.seatCapacity.CheckBus(bus)) {
+		return
+	}
+
 	err = h.busRepo.Create(bus)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bus: " + err.Error()})
@@ -283,6 +299,9 @@ func (h *BusHandler) UpdateBus(c *gin.Context) {
 
 	// Verify bus exists and belongs to this owner
 	bus, err := h.busRepo.GetByID(busID)
+	if err == nil && bus.DeletedAt != nil {
+		err = sql.ErrNoRows // A deleted bus is only visible to admins
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Bus not found"})
@@ -298,6 +317,20 @@ func (h *BusHandler) UpdateBus(c *gin.Context) {
 		return
 	}
 
+	// The seat layout must still fit the bus's registered and the permit's approved capacity
+	if req.SeatLayoutID != nil || req.SeatingCapacity != nil {
+		updated := *bus
+		if req.SeatLayoutID != nil {
+			updated.SeatLayoutID = req.SeatLayoutID
+		}
+		if req.SeatingCapacity != nil {
+			updated.SeatingCapacity = req.SeatingCapacity
+		}
+		if respondSeatCapacity(c, h.seatCapacity.CheckBus(&updated)) {
+			return
+		}
+	}
+
 	// Update bus
 	err = h.busRepo.Update(busID, &req)
 	if err != nil {
@@ -315,7 +348,7 @@ func (h *BusHandler) UpdateBus(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedBus)
 }
 
-// DeleteBus deletes a bus
+// DeleteBus soft-deletes a bus; refused while upcoming published trips use it
 // DELETE /api/v1/buses/:id
 func (h *BusHandler) DeleteBus(c *gin.Context) {
 	// Get user context from JWT middleware
@@ -350,6 +383,9 @@ func (h *BusHandler) DeleteBus(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Bus not found or you don't have permission to delete it"})
 			return
 		}
+		if respondUpcomingTrips(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bus"})
 		return
 	}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
 )
 
 type BusOwnerHandler struct {
@@ -18,14 +20,18 @@ type BusOwnerHandler struct {
 	permitRepo   *database.RoutePermitRepository
 	userRepo     *database.UserRepository
 	staffRepo    *database.BusStaffRepository
+	staffService *services.StaffService
+	claimsCache  *services.UserClaimsCache
 }
 
-func NewBusOwnerHandler(busOwnerRepo *database.BusOwnerRepository, permitRepo *database.RoutePermitRepository, userRepo *database.UserRepository, staffRepo *database.BusStaffRepository) *BusOwnerHandler {
+func NewBusOwnerHandler(busOwnerRepo *database.BusOwnerRepository, permitRepo *database.RoutePermitRepository, userRepo *database.UserRepository, staffRepo *database.BusStaffRepository, staffService *services.StaffService, claimsCache *services.UserClaimsCache) *BusOwnerHandler {
 	return &BusOwnerHandler{
 		busOwnerRepo: busOwnerRepo,
 		permitRepo:   permitRepo,
 		userRepo:     userRepo,
 		staffRepo:    staffRepo,
+		staffService: staffService,
+		claimsCache:  claimsCache,
 	}
 }
 
@@ -122,6 +128,37 @@ func (h *BusOwnerHandler) CheckProfileStatus(c *gin.Context) {
 	})
 }
 
+// GetOnboardingProgress returns the onboarding checklist: profile, verification, permits,
+// buses, routes, schedules and staff, with what is blocking each step
+// GET /api/v1/bus-owner/onboarding/progress
+func (h *BusOwnerHandler) GetOnboardingProgress(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Not started yet - everything is still to do
+			c.JSON(http.StatusOK, models.NewBusOwnerOnboardingProgress(nil, nil))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch profile"})
+		return
+	}
+
+	counts, err := h.busOwnerRepo.GetOnboardingCounts(busOwner.ID)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to get onboarding counts for bus_owner_id=%s: %v\n", busOwner.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch onboarding progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewBusOwnerOnboardingProgress(busOwner, counts))
+}
+
 // CompleteOnboardingRequest represents the onboarding request payload
 type CompleteOnboardingRequest struct {
 	CompanyName               string                            `json:"company_name" binding:"required"`
@@ -229,6 +266,7 @@ func (h *BusOwnerHandler) CompleteOnboarding(c *gin.Context) {
 		// Log error but don't fail the request
 		// Role might already exist (AddUserRole prevents duplicates)
 	}
+	h.claimsCache.Invalidate(userCtx.UserID)
 
 	// Fetch updated profile (should have profile_completed = true now)
 	updatedProfile, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
@@ -237,10 +275,13 @@ func (h *BusOwnerHandler) CompleteOnboarding(c *gin.Context) {
 		return
 	}
 
+	// The current access token predates the bus_owner role. Requests pick it up anyway when
+	// claim enrichment is on; otherwise the rest of /bus-owner needs a refreshed token.
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Onboarding completed successfully",
-		"profile": updatedProfile,
-		"permits": createdPermits,
+		"message":                "Onboarding completed successfully",
+		"profile":                updatedProfile,
+		"permits":                createdPermits,
+		"token_refresh_required": true,
 	})
 }
 
@@ -350,6 +391,26 @@ func (h *BusOwnerHandler) VerifyStaff(c *gin.Context) {
 		return
 	}
 
+	// Staff who asked to join this bus owner are reviewed through their request instead
+	currentEmployment, _ := h.staffRepo.GetCurrentEmployment(staff.ID)
+	if currentEmployment != nil && currentEmployment.EmploymentStatus == models.EmploymentStatusPending && currentEmployment.BusOwnerID == busOwner.ID {
+		c.JSON(http.StatusOK, &models.VerifyStaffResponse{
+			Found:            true,
+			Eligible:         false,
+			StaffID:          staff.ID,
+			StaffType:        &staff.StaffType,
+			FirstName:        staffFirstName,
+			LastName:         staffLastName,
+			ProfileCompleted: true,
+			IsVerified:       staff.IsVerified,
+			AlreadyLinked:    false,
+			CurrentOwnerID:   &currentEmployment.BusOwnerID,
+			Message:          "This staff member has asked to join your organization. Review their request to approve them.",
+			Reason:           "pending_request",
+		})
+		return
+	}
+
 	// Check if verified by admin (is_verified = true and verification_status = 'approved')
 	if !staff.IsVerified || staff.VerificationStatus != models.StaffVerificationApproved {
 		c.JSON(http.StatusOK, &models.VerifyStaffResponse{
@@ -369,7 +430,6 @@ func (h *BusOwnerHandler) VerifyStaff(c *gin.Context) {
 	}
 
 	// Check if already has active employment (via bus_staff_employment table)
-	currentEmployment, _ := h.staffRepo.GetCurrentEmployment(staff.ID)
 	if currentEmployment != nil {
 		if currentEmployment.BusOwnerID != busOwner.ID {
 			c.JSON(http.StatusOK, &models.VerifyStaffResponse{
@@ -709,6 +769,7 @@ func (h *BusOwnerHandler) AddStaff(c *gin.Context) {
 		// Log but don't fail - staff record is created
 		fmt.Printf("WARNING: Failed to add role %s to user %s: %v\n", roleToAdd, userID, err)
 	}
+	h.claimsCache.Invalidate(userID)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":       fmt.Sprintf("%s added successfully", req.StaffType),
@@ -884,8 +945,8 @@ func (h *BusOwnerHandler) UnlinkStaff(c *gin.Context) {
 		status = models.EmploymentStatusResigned
 	}
 
-	// End the employment
-	err = h.staffRepo.EndEmployment(req.StaffID, status, req.TerminationReason)
+	// End the employment, revoking the staff member's devices
+	err = h.staffService.UnlinkStaff(req.StaffID, busOwner.ID, userCtx.UserID.String(), status, req.TerminationReason)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to unlink staff: %v", err)})
 		return
@@ -896,3 +957,102 @@ func (h *BusOwnerHandler) UnlinkStaff(c *gin.Context) {
 		"staff_id": req.StaffID,
 	})
 }
+
+// GetStaffRequests lists staff who asked to join the bus owner and are waiting for review,
+// with the documents they registered
+// GET /api/v1/bus-owner/staff/requests
+func (h *BusOwnerHandler) GetStaffRequests(c *gin.Context) {
+	busOwner, ok := h.currentBusOwner(c)
+	if !ok {
+		return
+	}
+
+	requests, err := h.staffService.GetPendingStaffRequests(busOwner.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get staff requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"requests": requests,
+		"total":    len(requests),
+	})
+}
+
+// ApproveStaffRequest approves a staff member's request to join; they are notified and get
+// their driver/conductor role on their next token refresh
+// POST /api/v1/bus-owner/staff/requests/:employment_id/approve
+func (h *BusOwnerHandler) ApproveStaffRequest(c *gin.Context) {
+	busOwner, ok := h.currentBusOwner(c)
+	if !ok {
+		return
+	}
+
+	employment, err := h.staffService.ApproveStaffRequest(busOwner, c.Param("employment_id"))
+	if err != nil {
+		h.staffRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Staff request approved",
+		"employment": employment,
+	})
+}
+
+// RejectStaffRequest turns down a staff member's request to join; the reason is sent to them
+// POST /api/v1/bus-owner/staff/requests/:employment_id/reject
+func (h *BusOwnerHandler) RejectStaffRequest(c *gin.Context) {
+	busOwner, ok := h.currentBusOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.RejectStaffRequestInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.staffService.RejectStaffRequest(busOwner, c.Param("employment_id"), req.Reason); err != nil {
+		h.staffRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Staff request rejected",
+		"employment_id": c.Param("employment_id"),
+	})
+}
+
+// currentBusOwner loads the authenticated bus owner, or sends an error response and returns false
+func (h *BusOwnerHandler) currentBusOwner(c *gin.Context) (*models.BusOwner, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bus owner profile not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bus owner profile"})
+		return nil, false
+	}
+	return busOwner, true
+}
+
+// staffRequestError maps staff request review errors to responses
+func (h *BusOwnerHandler) staffRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrStaffRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff request not found"})
+	case errors.Is(err, services.ErrStaffRequestResolved):
+		c.JSON(http.StatusConflict, gin.H{"error": "Staff request has already been reviewed or withdrawn"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to review staff request: %v", err)})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"database/sql"
 	"log"
 	"net/http"
 
@@ -195,6 +196,9 @@ func (h *BusOwnerRouteHandler) GetRouteByID(c *gin.Context) {
 	routeID := c.Param("id")
 
 	route, err := h.routeRepo.GetByID(routeID)
+	if err == nil && route.DeletedAt != nil {
+		err = sql.ErrNoRows // A deleted route is only visible to admins
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
 		return
@@ -265,6 +269,9 @@ func (h *BusOwnerRouteHandler) UpdateRoute(c *gin.Context) {
 
 	// Get existing route
 	existingRoute, err := h.routeRepo.GetByID(routeID)
+	if err == nil && existingRoute.DeletedAt != nil {
+		err = sql.ErrNoRows // A deleted route is only visible to admins
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
 		return
@@ -329,7 +336,7 @@ func (h *BusOwnerRouteHandler) UpdateRoute(c *gin.Context) {
 	c.JSON(http.StatusOK, existingRoute)
 }
 
-// DeleteRoute deletes a custom route
+// DeleteRoute soft-deletes a custom route; refused while upcoming published trips follow it
 // DELETE /api/v1/bus-owner-routes/:id
 func (h *BusOwnerRouteHandler) DeleteRoute(c *gin.Context) {
 	userCtx, exists := middleware.GetUserContext(c)
@@ -353,6 +360,13 @@ func (h *BusOwnerRouteHandler) DeleteRoute(c *gin.Context) {
 	}
 
 	if err := h.routeRepo.Delete(routeID, busOwner.ID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+			return
+		}
+		if respondUpcomingTrips(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete route"})
 		return
 	}
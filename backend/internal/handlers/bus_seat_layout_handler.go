@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -65,6 +66,10 @@ func (h *BusSeatLayoutHandler) CreateTemplate(c *gin.Context) {
 	// Create template
 	template, err := h.service.CreateTemplate(c.Request.Context(), &req, adminID)
 	if err != nil {
+		if strings.Contains(err.Error(), "invalid layout_metadata") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid layout metadata", "details": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to create template", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template", "details": err.Error()})
 		return
@@ -162,6 +167,14 @@ func (h *BusSeatLayoutHandler) UpdateTemplate(c *gin.Context) {
 	}
 
 	if err := h.service.UpdateTemplate(c.Request.Context(), templateID, &req); err != nil {
+		if strings.Contains(err.Error(), "invalid layout_metadata") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid layout metadata", "details": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "template not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
 		h.logger.Error("Failed to update template", "template_id", templateID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
 		return
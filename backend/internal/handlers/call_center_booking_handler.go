@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// CallCenterBookingHandler handles bookings admins take over the phone for passengers, who pay
+// from an SMS payment link
+type CallCenterBookingHandler struct {
+	callCenterService *services.CallCenterBookingService
+	logger            *logrus.Logger
+}
+
+// NewCallCenterBookingHandler creates a new CallCenterBookingHandler
+func NewCallCenterBookingHandler(callCenterService *services.CallCenterBookingService, logger *logrus.Logger) *CallCenterBookingHandler {
+	return &CallCenterBookingHandler{callCenterService: callCenterService, logger: logger}
+}
+
+// CreateBooking holds seats for a passenger on the phone and texts them the payment link
+// POST /api/v1/admin/call-center/bookings
+func (h *CallCenterBookingHandler) CreateBooking(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateCallCenterBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	booking, err := h.callCenterService.Create(userCtx.UserID, &req, middleware.GetTenant(c))
+	if err != nil {
+		// Like app intents, anything else the orchestrator rejects is a bad booking request
+		if !h.respondKnownCallCenterError(c, err) {
+			h.logger.WithError(err).Error("Failed to create call-center booking")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "booking_failed", "message": err.Error()})
+		}
+		return
+	}
+
+	message := "Booking held and payment link sent to the passenger"
+	if booking.SMSCount == 0 {
+		message = "Booking held but the payment link SMS failed; resend it"
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": message, "booking": booking})
+}
+
+// ListBookings lists call-center bookings, newest first
+// GET /api/v1/admin/call-center/bookings?mine=true&phone=&limit=&offset=
+func (h *CallCenterBookingHandler) ListBookings(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	filter := models.CallCenterBookingFilter{Phone: c.Query("phone"), Limit: limit, Offset: offset}
+	if c.Query("mine") == "true" {
+		if userCtx, exists := middleware.GetUserContext(c); exists {
+			filter.AgentUserID = &userCtx.UserID
+		}
+	}
+
+	bookings, err := h.callCenterService.List(filter)
+	if err != nil {
+		h.respondCallCenterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bookings": bookings, "limit": limit, "offset": offset})
+}
+
+// GetBooking returns a call-center booking and whether the passenger has paid
+// GET /api/v1/admin/call-center/bookings/:id
+func (h *CallCenterBookingHandler) GetBooking(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid booking ID"})
+		return
+	}
+
+	booking, err := h.callCenterService.Get(id)
+	if err != nil {
+		h.respondCallCenterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"booking": booking})
+}
+
+// ResendPaymentLink texts the payment link to the passenger again
+// POST /api/v1/admin/call-center/bookings/:id/resend-link
+func (h *CallCenterBookingHandler) ResendPaymentLink(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid booking ID"})
+		return
+	}
+
+	booking, err := h.callCenterService.ResendPaymentLink(id)
+	if err != nil {
+		h.respondCallCenterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Payment link sent", "booking": booking})
+}
+
+func (h *CallCenterBookingHandler) respondCallCenterError(c *gin.Context, err error) {
+	if !h.respondKnownCallCenterError(c, err) {
+		h.logger.WithError(err).Error("Call-center booking request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Call-center booking request failed"})
+	}
+}
+
+// respondKnownCallCenterError writes the response for errors with a known meaning; reports
+// whether it did
+func (h *CallCenterBookingHandler) respondKnownCallCenterError(c *gin.Context, err error) bool {
+	var validationErr *models.ValidationError
+	var partialErr *models.PartialAvailabilityError
+	var duplicateErr *models.DuplicateBookingError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.As(err, &partialErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":       "partial_availability",
+			"available":   partialErr.Available,
+			"unavailable": partialErr.Unavailable,
+			"message":     partialErr.Message,
+		})
+	case errors.As(err, &duplicateErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":                 "duplicate_booking",
+			"confirmation_required": len(duplicateErr.OverlappingSeats) == 0,
+			"existing_bookings":     duplicateErr.Existing,
+			"overlapping_seats":     duplicateErr.OverlappingSeats,
+			"message":               duplicateErr.Message,
+		})
+	case errors.Is(err, services.ErrTripBlackedOut):
+		c.JSON(http.StatusConflict, gin.H{"error": "booking_blackout", "message": err.Error()})
+	case errors.Is(err, services.ErrSeatsHeldForWaitlist):
+		c.JSON(http.StatusConflict, gin.H{"error": "seats_held_for_waitlist", "message": err.Error()})
+	case errors.Is(err, services.ErrPaymentUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "payment_unavailable", "message": err.Error()})
+	case errors.Is(err, services.ErrCallCenterBookingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrCallCenterLinkClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "not_awaiting_payment", "message": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
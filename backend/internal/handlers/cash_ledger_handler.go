@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// CashLedgerHandler handles the crew's onboard cash sales and end-of-trip handover, and the bus
+// owner's reconciliation of it
+type CashLedgerHandler struct {
+	cashService  *services.CashLedgerService
+	busOwnerRepo *database.BusOwnerRepository
+	logger       *logrus.Logger
+}
+
+// NewCashLedgerHandler creates a new CashLedgerHandler
+func NewCashLedgerHandler(
+	cashService *services.CashLedgerService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *CashLedgerHandler {
+	return &CashLedgerHandler{
+		cashService:  cashService,
+		busOwnerRepo: busOwnerRepo,
+		logger:       logger,
+	}
+}
+
+// ============================================================================
+// STAFF ENDPOINTS
+// ============================================================================
+
+// GetSales returns the trip's cash ledger: its onboard sales and the cash expected
+// GET /api/v1/staff/trips/:id/sales
+func (h *CashLedgerHandler) GetSales(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	ledger, err := h.cashService.GetCrewLedger(c.Param("id"), userCtx.UserID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// RecordSale records a ticket sold for cash on board
+// POST /api/v1/staff/trips/:id/sales
+func (h *CashLedgerHandler) RecordSale(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	var req models.RecordOnboardSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	sale, created, err := h.cashService.RecordSale(c.Param("id"), userCtx.UserID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, gin.H{"message": "Sale already recorded", "sale": sale})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Sale recorded", "sale": sale})
+}
+
+// VoidSale takes a mistaken sale out of the trip's takings
+// POST /api/v1/staff/trips/:id/sales/:sale_id/void
+func (h *CashLedgerHandler) VoidSale(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	var req models.VoidOnboardSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	sale, err := h.cashService.VoidSale(c.Param("id"), c.Param("sale_id"), userCtx.UserID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sale voided", "sale": sale})
+}
+
+// GetSettlement returns the trip's cash handover and the owner's review of it
+// GET /api/v1/staff/trips/:id/settlement
+func (h *CashLedgerHandler) GetSettlement(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	settlement, err := h.cashService.GetCrewSettlement(c.Param("id"), userCtx.UserID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settlement": settlement})
+}
+
+// SubmitSettlement hands in the trip's cash with the amount counted
+// POST /api/v1/staff/trips/:id/settlement
+func (h *CashLedgerHandler) SubmitSettlement(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	var req models.SubmitCashSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	settlement, err := h.cashService.SubmitSettlement(c.Param("id"), userCtx.UserID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cash handed in", "settlement": settlement})
+}
+
+// ============================================================================
+// OWNER ENDPOINTS
+// ============================================================================
+
+// GetTripLedger returns the cash ledger of one of the owner's trips
+// GET /api/v1/scheduled-trips/:id/cash-ledger
+func (h *CashLedgerHandler) GetTripLedger(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	ledger, err := h.cashService.GetOwnerLedger(c.Param("id"), busOwnerID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// ReviewSettlement reconciles or disputes the cash handed in for one of the owner's trips
+// POST /api/v1/scheduled-trips/:id/cash-settlement/review
+func (h *CashLedgerHandler) ReviewSettlement(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := middleware.GetUserContext(c)
+
+	var req models.ReviewCashSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	settlement, err := h.cashService.ReviewSettlement(c.Param("id"), busOwnerID, userCtx.UserID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cash settlement " + string(settlement.Status), "settlement": settlement})
+}
+
+// ListSettlements lists the cash handovers of the owner's trips
+// GET /api/v1/bus-owner/cash-settlements?status=submitted&limit=&offset=
+func (h *CashLedgerHandler) ListSettlements(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+	limit, offset := payoutPagination(c)
+
+	status := models.TripCashSettlementStatus(c.Query("status"))
+	switch status {
+	case "", models.CashSettlementSubmitted, models.CashSettlementReconciled, models.CashSettlementDisputed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "status must be submitted, reconciled or disputed"})
+		return
+	}
+
+	settlements, total, err := h.cashService.ListSettlements(models.CashSettlementFilter{
+		BusOwnerID: busOwnerID,
+		Status:     status,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settlements": settlements, "total": total, "limit": limit, "offset": offset})
+}
+
+func (h *CashLedgerHandler) userContext(c *gin.Context) (middleware.UserContext, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return userCtx, false
+	}
+	return userCtx, true
+}
+
+func (h *CashLedgerHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+func (h *CashLedgerHandler) respondError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrCashTripNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "trip_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrOnboardSaleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "sale_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrCashSettlementNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "settlement_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrCashDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden", "message": err.Error()})
+	case errors.Is(err, services.ErrCashSalesClosed), errors.Is(err, database.ErrCashLedgerClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "ledger_closed", "message": err.Error()})
+	case errors.Is(err, services.ErrCashTripNotUnderWay):
+		c.JSON(http.StatusConflict, gin.H{"error": "trip_not_under_way", "message": err.Error()})
+	case errors.Is(err, services.ErrCashSettlementNotPending), errors.Is(err, database.ErrCashSettlementReconciled):
+		c.JSON(http.StatusConflict, gin.H{"error": "settlement_not_pending", "message": err.Error()})
+	case errors.Is(err, database.ErrOnboardSaleVoided):
+		c.JSON(http.StatusConflict, gin.H{"error": "sale_voided", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Cash ledger request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Cash ledger request failed"})
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
+)
+
+// DeletedEntityHandler lets admins list and restore the buses, permits and routes bus owners
+// deleted
+type DeletedEntityHandler struct {
+	repo         *database.SoftDeleteRepository
+	auditService *services.AuditService
+	logger       *logrus.Logger
+}
+
+// NewDeletedEntityHandler creates a new DeletedEntityHandler
+func NewDeletedEntityHandler(repo *database.SoftDeleteRepository, auditService *services.AuditService, logger *logrus.Logger) *DeletedEntityHandler {
+	return &DeletedEntityHandler{repo: repo, auditService: auditService, logger: logger}
+}
+
+// ListDeleted handles GET /api/v1/admin/deleted-entities
+// Query: type (bus, permit or route; required), bus_owner_id, limit, offset
+func (h *DeletedEntityHandler) ListDeleted(c *gin.Context) {
+	entityType := models.DeletedEntityType(c.Query("type"))
+	if !models.ValidDeletedEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "type must be bus, permit or route"})
+		return
+	}
+
+	// Parse pagination (default 50, max 100)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	entities, err := h.repo.ListDeleted(entityType, c.Query("bus_owner_id"), limit, offset)
+	if err != nil {
+		h.logger.WithError(err).WithField("type", entityType).Error("Failed to list deleted entities")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to list deleted entities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entities": entities, "limit": limit, "offset": offset})
+}
+
+// Restore handles POST /api/v1/admin/deleted-entities/:type/:id/restore
+func (h *DeletedEntityHandler) Restore(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+	entityType := models.DeletedEntityType(c.Param("type"))
+	if !models.ValidDeletedEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "type must be bus, permit or route"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid ID"})
+		return
+	}
+
+	entity, err := h.repo.Restore(entityType, id.String())
+	if err != nil {
+		var conflict *database.RestoreConflictError
+		switch {
+		case errors.Is(err, database.ErrDeletedEntityNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "No deleted " + string(entityType) + " with this ID"})
+		case errors.As(err, &conflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "restore_conflict", "message": conflict.Reason})
+		default:
+			h.logger.WithError(err).WithFields(logrus.Fields{"type": entityType, "id": id}).Error("Failed to restore deleted entity")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to restore"})
+		}
+		return
+	}
+
+	if err := h.auditService.LogEntityRestored(userCtx.UserID, string(entityType), id, utils.GetRealIP(c), utils.GetUserAgent(c), map[string]interface{}{
+		"bus_owner_id": entity.BusOwnerID,
+		"name":         entity.Name,
+		"deleted_at":   entity.DeletedAt,
+	}); err != nil {
+		h.logger.WithError(err).Warn("Failed to audit restore")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Restored", "entity": entity})
+}
+
+// respondUpcomingTrips writes 409 if a delete was refused because upcoming published trips
+// still use the entity. Returns true if it did.
+func respondUpcomingTrips(c *gin.Context, err error) bool {
+	var upcoming *database.UpcomingTripsError
+	if !errors.As(err, &upcoming) {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error":          "Unpublish or cancel its upcoming trips first",
+		"code":           "HAS_UPCOMING_TRIPS",
+		"upcoming_trips": upcoming.Count,
+	})
+	return true
+}
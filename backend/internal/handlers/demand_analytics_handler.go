@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// DemandAnalyticsHandler serves the origin/destination/hour demand heatmap
+type DemandAnalyticsHandler struct {
+	demandService *services.DemandAnalyticsService
+	busOwnerRepo  *database.BusOwnerRepository
+	logger        *logrus.Logger
+}
+
+// NewDemandAnalyticsHandler creates a new DemandAnalyticsHandler
+func NewDemandAnalyticsHandler(
+	demandService *services.DemandAnalyticsService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *DemandAnalyticsHandler {
+	return &DemandAnalyticsHandler{
+		demandService: demandService,
+		busOwnerRepo:  busOwnerRepo,
+		logger:        logger,
+	}
+}
+
+// GetPlatformDemand returns the platform-wide demand heatmap
+// GET /api/v1/admin/analytics/demand?from=YYYY-MM-DD&to=YYYY-MM-DD&origin=&limit=
+func (h *DemandAnalyticsHandler) GetPlatformDemand(c *gin.Context) {
+	h.respondHeatmap(c, nil)
+}
+
+// GetOwnerDemand returns the demand heatmap for the bus owner's routes
+// GET /api/v1/bus-owner/analytics/demand?from=YYYY-MM-DD&to=YYYY-MM-DD&origin=&limit=
+func (h *DemandAnalyticsHandler) GetOwnerDemand(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return
+	}
+
+	h.respondHeatmap(c, &busOwner.ID)
+}
+
+func (h *DemandAnalyticsHandler) respondHeatmap(c *gin.Context, busOwnerID *string) {
+	var req models.DemandHeatmapRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	heatmap, err := h.demandService.GetHeatmap(busOwnerID, &req)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to build demand heatmap")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to build demand heatmap"})
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
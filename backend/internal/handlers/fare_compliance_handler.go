@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// FareComplianceHandler serves the admin report of trips priced above their permit's fare
+type FareComplianceHandler struct {
+	complianceService *services.FareComplianceService
+	logger            *logrus.Logger
+}
+
+// NewFareComplianceHandler creates a new FareComplianceHandler
+func NewFareComplianceHandler(complianceService *services.FareComplianceService, logger *logrus.Logger) *FareComplianceHandler {
+	return &FareComplianceHandler{
+		complianceService: complianceService,
+		logger:            logger,
+	}
+}
+
+// GetReport lists trips priced above their route permit's approved fare
+// GET /api/v1/admin/fare-compliance?from=YYYY-MM-DD&to=YYYY-MM-DD&bus_owner_id=&bookable_only=&limit=50&offset=0
+func (h *FareComplianceHandler) GetReport(c *gin.Context) {
+	var req models.FareComplianceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+	limit, offset := payoutPagination(c)
+
+	report, err := h.complianceService.GetReport(&req, limit, offset)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to build fare compliance report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to build fare compliance report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// gtfsCacheControl lets journey planners and CDNs reuse the feed for a while; it only changes
+// when regenerated
+const gtfsCacheControl = "public, max-age=900"
+
+// gtfsRealtimeContentType is the media type GTFS Realtime consumers expect
+const gtfsRealtimeContentType = "application/x-protobuf"
+
+// GTFSHandler serves the public GTFS static feed
+type GTFSHandler struct {
+	gtfsService *services.GTFSService
+	logger      *logrus.Logger
+}
+
+// NewGTFSHandler creates a new GTFSHandler
+func NewGTFSHandler(gtfsService *services.GTFSService, logger *logrus.Logger) *GTFSHandler {
+	return &GTFSHandler{gtfsService: gtfsService, logger: logger}
+}
+
+// GetStaticFeed returns the GTFS static zip of published trips
+// GET /api/v1/gtfs/static
+func (h *GTFSHandler) GetStaticFeed(c *gin.Context) {
+	feed, err := h.gtfsService.Feed()
+	if errors.Is(err, services.ErrGTFSDisabled) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "The GTFS feed is not available"})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to generate GTFS feed")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "unavailable", "message": "The GTFS feed could not be generated, try again shortly"})
+		return
+	}
+
+	c.Header("Last-Modified", feed.GeneratedAt.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", gtfsCacheControl)
+	c.Header("Content-Disposition", `attachment; filename="gtfs.zip"`)
+	c.Data(http.StatusOK, "application/zip", feed.Data)
+}
+
+// GetRealtimeFeed returns the GTFS Realtime vehicle positions and trip updates of tracked trips
+// GET /api/v1/gtfs/realtime
+func (h *GTFSHandler) GetRealtimeFeed(c *gin.Context) {
+	h.serveRealtime(c, models.GTFSRealtimeAll)
+}
+
+// GetVehiclePositions returns the GTFS Realtime vehicle positions feed
+// GET /api/v1/gtfs/realtime/vehicle-positions
+func (h *GTFSHandler) GetVehiclePositions(c *gin.Context) {
+	h.serveRealtime(c, models.GTFSRealtimeVehiclePositions)
+}
+
+// GetTripUpdates returns the GTFS Realtime trip updates feed
+// GET /api/v1/gtfs/realtime/trip-updates
+func (h *GTFSHandler) GetTripUpdates(c *gin.Context) {
+	h.serveRealtime(c, models.GTFSRealtimeTripUpdates)
+}
+
+func (h *GTFSHandler) serveRealtime(c *gin.Context, kind models.GTFSRealtimeFeedKind) {
+	data, err := h.gtfsService.RealtimeFeed(kind)
+	if errors.Is(err, services.ErrGTFSDisabled) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "The GTFS feed is not available"})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("feed", kind).Error("Failed to build GTFS Realtime feed")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "unavailable", "message": "The realtime feed could not be built, try again shortly"})
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, gtfsRealtimeContentType, data)
+}
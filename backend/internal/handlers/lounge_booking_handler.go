@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
 )
 
 // LoungeBookingHandler handles lounge booking-related HTTP requests
@@ -19,6 +21,8 @@ type LoungeBookingHandler struct {
 	bookingRepo     *database.LoungeBookingRepository
 	loungeRepo      *database.LoungeRepository
 	loungeOwnerRepo *database.LoungeOwnerRepository
+	pricingService  *services.LoungePricingService
+	promoCodes      *services.PromoCodeService
 }
 
 // NewLoungeBookingHandler creates a new lounge booking handler
@@ -26,11 +30,15 @@ func NewLoungeBookingHandler(
 	bookingRepo *database.LoungeBookingRepository,
 	loungeRepo *database.LoungeRepository,
 	loungeOwnerRepo *database.LoungeOwnerRepository,
+	pricingService *services.LoungePricingService,
+	promoCodes *services.PromoCodeService,
 ) *LoungeBookingHandler {
 	return &LoungeBookingHandler{
 		bookingRepo:     bookingRepo,
 		loungeRepo:      loungeRepo,
 		loungeOwnerRepo: loungeOwnerRepo,
+		pricingService:  pricingService,
+		promoCodes:      promoCodes,
 	}
 }
 
@@ -693,8 +701,8 @@ func (h *LoungeBookingHandler) CreateLoungeBooking(c *gin.Context) {
 		return
 	}
 
-	// Get base price for the pricing type
-	basePrice, err := h.bookingRepo.GetLoungePrice(loungeID, req.PricingType)
+	// Get the price for the pricing type at the arrival time (slot/date overrides and surge)
+	quote, err := h.pricingService.Quote(lounge, req.PricingType, scheduledArrival)
 	if err != nil {
 		log.Printf("ERROR: Failed to get lounge price: %v", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -703,11 +711,10 @@ func (h *LoungeBookingHandler) CreateLoungeBooking(c *gin.Context) {
 		})
 		return
 	}
+	basePrice := models.NewMoney(quote.PricePerGuest)
 
 	// Calculate price per guest
-	var basePriceVal float64
-	basePriceVal, _ = strconv.ParseFloat(basePrice, 64)
-	pricePerGuest := strconv.FormatFloat(basePriceVal/float64(req.NumberOfGuests), 'f', 2, 64)
+	pricePerGuest := basePrice.Div(req.NumberOfGuests)
 
 	// Build booking with denormalized lounge info
 	booking := &models.LoungeBooking{
@@ -717,8 +724,8 @@ func (h *LoungeBookingHandler) CreateLoungeBooking(c *gin.Context) {
 		ScheduledArrival:  scheduledArrival,
 		NumberOfGuests:    req.NumberOfGuests,
 		PricingType:       req.PricingType,
-		BasePrice:         basePrice,
-		PricePerGuest:     pricePerGuest,
+		BasePrice:         basePrice.String(),
+		PricePerGuest:     pricePerGuest.String(),
 		PreOrderTotal:     "0.00",
 		DiscountAmount:    "0.00",
 		PrimaryGuestName:  req.PrimaryGuestName,
@@ -756,13 +763,6 @@ func (h *LoungeBookingHandler) CreateLoungeBooking(c *gin.Context) {
 		booking.SpecialRequests.Valid = true
 	}
 
-	// Handle promo code
-	if req.PromoCode != nil {
-		booking.PromoCode.String = *req.PromoCode
-		booking.PromoCode.Valid = true
-		// TODO: Validate promo code and calculate discount
-	}
-
 	// Build guests
 	guests := make([]models.LoungeBookingGuest, len(req.Guests))
 	for i, g := range req.Guests {
@@ -778,7 +778,7 @@ func (h *LoungeBookingHandler) CreateLoungeBooking(c *gin.Context) {
 
 	// Build pre-orders and calculate total
 	var preOrders []models.LoungeBookingPreOrder
-	preOrderTotal := 0.0
+	var preOrderTotal models.Money
 
 	for _, po := range req.PreOrders {
 		productID, err := uuid.Parse(po.ProductID)
@@ -808,12 +808,17 @@ func (h *LoungeBookingHandler) CreateLoungeBooking(c *gin.Context) {
 		}
 
 		// Calculate total price
-		unitPrice := product.Price
-		// Parse price and calculate total (simplified - proper decimal handling recommended)
-		var priceFloat float64
-		_, _ = strconv.ParseFloat(unitPrice, 64)
-		totalFloat := priceFloat * float64(po.Quantity)
-		preOrderTotal += totalFloat
+		unitPrice, err := models.ParseMoney(product.Price)
+		if err != nil {
+			log.Printf("ERROR: Invalid price for product %s: %v", productID, err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "pricing_error",
+				Message: "Failed to price pre-orders",
+			})
+			return
+		}
+		lineTotal := unitPrice.Mul(po.Quantity)
+		preOrderTotal = preOrderTotal.Add(lineTotal)
 
 		preOrders = append(preOrders, models.LoungeBookingPreOrder{
 			ProductID:       productID,
@@ -821,23 +826,75 @@ func (h *LoungeBookingHandler) CreateLoungeBooking(c *gin.Context) {
 			ProductType:     string(product.ProductType), // Snapshot - required NOT NULL
 			ProductImageURL: product.ImageURL,            // Snapshot
 			Quantity:        po.Quantity,
-			UnitPrice:       unitPrice, // Snapshot
-			TotalPrice:      strconv.FormatFloat(totalFloat, 'f', 2, 64),
+			UnitPrice:       unitPrice.String(), // Snapshot
+			TotalPrice:      lineTotal.String(),
 		})
 	}
 
-	booking.PreOrderTotal = strconv.FormatFloat(preOrderTotal, 'f', 2, 64)
+	booking.PreOrderTotal = preOrderTotal.String()
 
 	// Calculate total amount (basePrice + preOrderTotal - discount)
-	var basePriceFloat, discountFloat float64
-	basePriceFloat, _ = strconv.ParseFloat(basePrice, 64)
-	discountFloat, _ = strconv.ParseFloat(booking.DiscountAmount, 64)
-	totalAmount := basePriceFloat + preOrderTotal - discountFloat
-	booking.TotalAmount = strconv.FormatFloat(totalAmount, 'f', 2, 64)
+	discount, _ := models.ParseMoney(booking.DiscountAmount)
+	totalAmount := basePrice.Add(preOrderTotal).Sub(discount)
+
+	// Take a promo code's discount off the stay and its pre-orders
+	var promoQuote *models.PromoQuote
+	var promoUse *models.PromoCodeRedemption
+	if req.PromoCode != nil && strings.TrimSpace(*req.PromoCode) != "" {
+		promoQuote, err = h.promoCodes.Quote(userCtx.UserID.String(), *req.PromoCode, []models.PromoLine{
+			{ProductType: models.PromoProductLounge, Amount: totalAmount.Float64()},
+		})
+		if err != nil {
+			var validationErr *models.ValidationError
+			if errors.As(err, &validationErr) || errors.Is(err, services.ErrPromoCodeNotFound) {
+				c.JSON(http.StatusBadRequest, ErrorResponse{
+					Error:   "invalid_promo_code",
+					Message: err.Error(),
+				})
+				return
+			}
+			log.Printf("ERROR: Failed to check promo code: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "promo_code_error",
+				Message: "Failed to check promo code",
+			})
+			return
+		}
+		// Reserve the code's use now so its limits hold; given back if the booking fails
+		promoUse = &models.PromoCodeRedemption{
+			PromoCodeID:    promoQuote.PromoCodeID,
+			UserID:         userCtx.UserID.String(),
+			DiscountAmount: promoQuote.DiscountAmount,
+		}
+		if err := h.promoCodes.Reserve(promoUse); err != nil {
+			var validationErr *models.ValidationError
+			if errors.As(err, &validationErr) {
+				c.JSON(http.StatusBadRequest, ErrorResponse{
+					Error:   "invalid_promo_code",
+					Message: err.Error(),
+				})
+				return
+			}
+			log.Printf("ERROR: Failed to reserve promo code: %v", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "promo_code_error",
+				Message: "Failed to check promo code",
+			})
+			return
+		}
+		promoDiscount := models.NewMoney(promoQuote.DiscountAmount)
+		promoDiscountStr := promoDiscount.String()
+		booking.PromoCode.String = promoQuote.Code
+		booking.PromoCode.Valid = true
+		booking.PromoDiscountAmount = &promoDiscountStr
+		totalAmount = totalAmount.Sub(promoDiscount)
+	}
+	booking.TotalAmount = totalAmount.String()
 
 	// Create booking
 	createdBooking, err := h.bookingRepo.CreateLoungeBooking(booking, guests, preOrders)
 	if err != nil {
+		h.promoCodes.Release(promoUse)
 		log.Printf("ERROR: Failed to create lounge booking: %v", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "creation_failed",
@@ -850,6 +907,12 @@ func (h *LoungeBookingHandler) CreateLoungeBooking(c *gin.Context) {
 	_ = h.bookingRepo.ConfirmLoungeBooking(createdBooking.ID)
 	createdBooking.Status = models.LoungeBookingStatusConfirmed
 
+	if promoUse != nil {
+		bookingID := createdBooking.ID.String()
+		promoUse.LoungeBookingID = &bookingID
+		h.promoCodes.Redeem(promoUse)
+	}
+
 	log.Printf("INFO: Lounge booking created - Ref: %s, User: %s, Lounge: %s",
 		createdBooking.BookingReference, userCtx.UserID, loungeID)
 
@@ -1199,6 +1262,94 @@ func (h *LoungeBookingHandler) GetLoungeBookingsForOwner(c *gin.Context) {
 	})
 }
 
+// GetOwnerAnalytics handles GET /api/v1/lounge-owner/analytics?from=YYYY-MM-DD&to=YYYY-MM-DD&lounge_id=
+// Bookings per day, check-in conversion, pre-order vs in-lounge order revenue and top
+// products across the owner's lounges, or one of them
+func (h *LoungeBookingHandler) GetOwnerAnalytics(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User context not found",
+		})
+		return
+	}
+
+	owner, err := h.loungeOwnerRepo.GetLoungeOwnerByUserID(userCtx.UserID)
+	if err != nil || owner == nil {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: "Not a lounge owner",
+		})
+		return
+	}
+
+	var req models.LoungeOwnerAnalyticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: err.Error()})
+		return
+	}
+	from, to, err := req.Range(time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: err.Error()})
+		return
+	}
+
+	var loungeID *uuid.UUID
+	if req.LoungeID != "" {
+		id, err := uuid.Parse(req.LoungeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_id",
+				Message: "Invalid lounge ID format",
+			})
+			return
+		}
+		lounge, err := h.loungeRepo.GetLoungeByID(id)
+		if err != nil || lounge == nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Lounge not found",
+			})
+			return
+		}
+		if lounge.LoungeOwnerID != owner.ID {
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "forbidden",
+				Message: "You don't own this lounge",
+			})
+			return
+		}
+		loungeID = &id
+	}
+
+	days, err := h.bookingRepo.GetOwnerAnalyticsDays(owner.ID, loungeID, from, to)
+	if err != nil {
+		log.Printf("ERROR: Failed to get lounge analytics for owner %s: %v", owner.ID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to retrieve analytics",
+		})
+		return
+	}
+	products, err := h.bookingRepo.GetOwnerTopProducts(owner.ID, loungeID, from, to, models.LoungeAnalyticsTopProducts)
+	if err != nil {
+		log.Printf("ERROR: Failed to get lounge top products for owner %s: %v", owner.ID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to retrieve analytics",
+		})
+		return
+	}
+
+	report := models.BuildLoungeOwnerAnalytics(days, products, from, to)
+	if loungeID != nil {
+		id := loungeID.String()
+		report.LoungeID = &id
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 // GetTodaysBookings handles GET /api/v1/lounges/:id/bookings/today
 func (h *LoungeBookingHandler) GetTodaysBookings(c *gin.Context) {
 	userCtx, exists := middleware.GetUserContext(c)
@@ -1486,7 +1637,7 @@ func (h *LoungeBookingHandler) CreateLoungeOrder(c *gin.Context) {
 
 	// Build items and calculate totals
 	var items []models.LoungeOrderItem
-	subtotal := 0.0
+	var subtotal models.Money
 
 	for _, item := range req.Items {
 		productID, err := uuid.Parse(item.ProductID)
@@ -1515,21 +1666,29 @@ func (h *LoungeBookingHandler) CreateLoungeOrder(c *gin.Context) {
 			return
 		}
 
-		priceFloat, _ := strconv.ParseFloat(product.Price, 64)
-		totalFloat := priceFloat * float64(item.Quantity)
-		subtotal += totalFloat
+		unitPrice, err := models.ParseMoney(product.Price)
+		if err != nil {
+			log.Printf("ERROR: Invalid price for product %s: %v", productID, err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "pricing_error",
+				Message: "Failed to price order items",
+			})
+			return
+		}
+		lineTotal := unitPrice.Mul(item.Quantity)
+		subtotal = subtotal.Add(lineTotal)
 
 		items = append(items, models.LoungeOrderItem{
 			ProductID:   productID,
 			ProductName: product.Name,
 			Quantity:    item.Quantity,
-			UnitPrice:   product.Price,
-			TotalPrice:  strconv.FormatFloat(totalFloat, 'f', 2, 64),
+			UnitPrice:   unitPrice.String(),
+			TotalPrice:  lineTotal.String(),
 		})
 	}
 
-	order.Subtotal = strconv.FormatFloat(subtotal, 'f', 2, 64)
-	order.TotalAmount = strconv.FormatFloat(subtotal, 'f', 2, 64)
+	order.Subtotal = subtotal.String()
+	order.TotalAmount = subtotal.String()
 
 	// Create order
 	createdOrder, err := h.bookingRepo.CreateLoungeOrder(order, items)
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// LoungeBundleDiscountHandler handles "lounge off with your bus ticket" discounts: owner-funded
+// ones on the owner's lounges and platform-funded ones managed by admins
+type LoungeBundleDiscountHandler struct {
+	discountService *services.LoungeBundleDiscountService
+	loungeRepo      *database.LoungeRepository
+	loungeOwnerRepo *database.LoungeOwnerRepository
+}
+
+// NewLoungeBundleDiscountHandler creates a new LoungeBundleDiscountHandler
+func NewLoungeBundleDiscountHandler(
+	discountService *services.LoungeBundleDiscountService,
+	loungeRepo *database.LoungeRepository,
+	loungeOwnerRepo *database.LoungeOwnerRepository,
+) *LoungeBundleDiscountHandler {
+	return &LoungeBundleDiscountHandler{
+		discountService: discountService,
+		loungeRepo:      loungeRepo,
+		loungeOwnerRepo: loungeOwnerRepo,
+	}
+}
+
+// ============================================================================
+// LOUNGE OWNER
+// ============================================================================
+
+// GetLoungeDiscounts handles GET /api/v1/lounges/:id/bundle-discounts
+func (h *LoungeBundleDiscountHandler) GetLoungeDiscounts(c *gin.Context) {
+	lounge, ok := resolveOwnedLounge(c, h.loungeRepo, h.loungeOwnerRepo)
+	if !ok {
+		return
+	}
+
+	rules, err := h.discountService.ListLoungeRules(lounge.ID.String())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules, "total": len(rules)})
+}
+
+// CreateLoungeDiscount handles POST /api/v1/lounges/:id/bundle-discounts
+func (h *LoungeBundleDiscountHandler) CreateLoungeDiscount(c *gin.Context) {
+	lounge, ok := resolveOwnedLounge(c, h.loungeRepo, h.loungeOwnerRepo)
+	if !ok {
+		return
+	}
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.discountService.CreateLoungeRule(lounge.ID.String(), req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Bundle discount created", "rule": rule})
+}
+
+// UpdateLoungeDiscount handles PUT /api/v1/lounges/:id/bundle-discounts/:rule_id
+func (h *LoungeBundleDiscountHandler) UpdateLoungeDiscount(c *gin.Context) {
+	lounge, ok := resolveOwnedLounge(c, h.loungeRepo, h.loungeOwnerRepo)
+	if !ok {
+		return
+	}
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.discountService.UpdateLoungeRule(lounge.ID.String(), ruleID, req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle discount updated", "rule": rule})
+}
+
+// DeleteLoungeDiscount handles DELETE /api/v1/lounges/:id/bundle-discounts/:rule_id
+func (h *LoungeBundleDiscountHandler) DeleteLoungeDiscount(c *gin.Context) {
+	lounge, ok := resolveOwnedLounge(c, h.loungeRepo, h.loungeOwnerRepo)
+	if !ok {
+		return
+	}
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.discountService.DeleteLoungeRule(lounge.ID.String(), ruleID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle discount deleted"})
+}
+
+// ============================================================================
+// ADMIN (PLATFORM-FUNDED)
+// ============================================================================
+
+// ListPlatformDiscounts handles GET /api/v1/admin/lounge-bundle-discounts
+func (h *LoungeBundleDiscountHandler) ListPlatformDiscounts(c *gin.Context) {
+	rules, err := h.discountService.ListPlatformRules()
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules, "total": len(rules)})
+}
+
+// CreatePlatformDiscount handles POST /api/v1/admin/lounge-bundle-discounts
+func (h *LoungeBundleDiscountHandler) CreatePlatformDiscount(c *gin.Context) {
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.discountService.CreatePlatformRule(req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Bundle discount created", "rule": rule})
+}
+
+// UpdatePlatformDiscount handles PUT /api/v1/admin/lounge-bundle-discounts/:rule_id
+func (h *LoungeBundleDiscountHandler) UpdatePlatformDiscount(c *gin.Context) {
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.discountService.UpdatePlatformRule(ruleID, req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle discount updated", "rule": rule})
+}
+
+// DeletePlatformDiscount handles DELETE /api/v1/admin/lounge-bundle-discounts/:rule_id
+func (h *LoungeBundleDiscountHandler) DeletePlatformDiscount(c *gin.Context) {
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.discountService.DeletePlatformRule(ruleID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle discount deleted"})
+}
+
+func (h *LoungeBundleDiscountHandler) bindRequest(c *gin.Context) (*models.SaveLoungeBundleDiscountRequest, bool) {
+	var req models.SaveLoungeBundleDiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: "Invalid request body: " + err.Error()})
+		return nil, false
+	}
+	return &req, true
+}
+
+func (h *LoungeBundleDiscountHandler) respondError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: validationErr.Message})
+	case errors.Is(err, services.ErrBundleDiscountRuleNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "not_found", Message: err.Error()})
+	default:
+		log.Printf("ERROR: Lounge bundle discount request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "bundle_discount_error", Message: "Failed to process bundle discount"})
+	}
+}
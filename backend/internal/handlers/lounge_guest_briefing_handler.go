@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// LoungeGuestBriefingHandler serves the lounge staff's expected arrivals screen
+type LoungeGuestBriefingHandler struct {
+	briefingService *services.LoungeGuestBriefingService
+	loungeRepo      *database.LoungeRepository
+	loungeOwnerRepo *database.LoungeOwnerRepository
+	staffRepo       *database.LoungeStaffRepository
+}
+
+// NewLoungeGuestBriefingHandler creates a new LoungeGuestBriefingHandler
+func NewLoungeGuestBriefingHandler(
+	briefingService *services.LoungeGuestBriefingService,
+	loungeRepo *database.LoungeRepository,
+	loungeOwnerRepo *database.LoungeOwnerRepository,
+	staffRepo *database.LoungeStaffRepository,
+) *LoungeGuestBriefingHandler {
+	return &LoungeGuestBriefingHandler{
+		briefingService: briefingService,
+		loungeRepo:      loungeRepo,
+		loungeOwnerRepo: loungeOwnerRepo,
+		staffRepo:       staffRepo,
+	}
+}
+
+// GetExpectedArrivals handles GET /api/v1/lounges/:id/expected-arrivals
+// Returns the guests the lounge still expects today, soonest first, with their bus ETAs and
+// pre-orders. Clients poll it; ETAs follow the buses' latest positions.
+func (h *LoungeGuestBriefingHandler) GetExpectedArrivals(c *gin.Context) {
+	lounge, ok := h.resolveStaffedLounge(c)
+	if !ok {
+		return
+	}
+
+	briefing, err := h.briefingService.ExpectedArrivals(lounge, time.Now())
+	if err != nil {
+		log.Printf("ERROR: Failed to get expected lounge arrivals: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "database_error", Message: "Failed to retrieve expected arrivals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, briefing)
+}
+
+// resolveStaffedLounge loads the :id lounge for its owner or one of its active staff, writing
+// the error response otherwise
+func (h *LoungeGuestBriefingHandler) resolveStaffedLounge(c *gin.Context) (*models.Lounge, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized", Message: "User context not found"})
+		return nil, false
+	}
+
+	loungeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_id", Message: "Invalid lounge ID format"})
+		return nil, false
+	}
+
+	lounge, err := h.loungeRepo.GetLoungeByID(loungeID)
+	if err != nil || lounge == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "not_found", Message: "Lounge not found"})
+		return nil, false
+	}
+
+	if owner, err := h.loungeOwnerRepo.GetLoungeOwnerByUserID(userCtx.UserID); err == nil && owner != nil && owner.ID == lounge.LoungeOwnerID {
+		return lounge, true
+	}
+	staff, err := h.staffRepo.GetStaffByUserID(userCtx.UserID)
+	if err == nil && staff != nil && staff.LoungeID == lounge.ID && staff.EmploymentStatus == models.LoungeStaffEmploymentActive {
+		return lounge, true
+	}
+
+	c.JSON(http.StatusForbidden, ErrorResponse{Error: "forbidden", Message: "Not this lounge's owner or staff"})
+	return nil, false
+}
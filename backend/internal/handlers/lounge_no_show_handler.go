@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// LoungeNoShowHandler handles the lounge owner's no-show policy
+type LoungeNoShowHandler struct {
+	noShowService   *services.LoungeNoShowService
+	loungeRepo      *database.LoungeRepository
+	loungeOwnerRepo *database.LoungeOwnerRepository
+}
+
+// NewLoungeNoShowHandler creates a new LoungeNoShowHandler
+func NewLoungeNoShowHandler(
+	noShowService *services.LoungeNoShowService,
+	loungeRepo *database.LoungeRepository,
+	loungeOwnerRepo *database.LoungeOwnerRepository,
+) *LoungeNoShowHandler {
+	return &LoungeNoShowHandler{
+		noShowService:   noShowService,
+		loungeRepo:      loungeRepo,
+		loungeOwnerRepo: loungeOwnerRepo,
+	}
+}
+
+// GetNoShowPolicy handles GET /api/v1/lounges/:id/no-show-policy
+func (h *LoungeNoShowHandler) GetNoShowPolicy(c *gin.Context) {
+	lounge, ok := resolveOwnedLounge(c, h.loungeRepo, h.loungeOwnerRepo)
+	if !ok {
+		return
+	}
+
+	policy, err := h.noShowService.GetPolicy(lounge.ID.String())
+	if err != nil {
+		h.respondNoShowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"no_show_policy": policy, "default_grace_minutes": h.noShowService.DefaultGraceMinutes()})
+}
+
+// UpdateNoShowPolicy handles PUT /api/v1/lounges/:id/no-show-policy
+func (h *LoungeNoShowHandler) UpdateNoShowPolicy(c *gin.Context) {
+	lounge, ok := resolveOwnedLounge(c, h.loungeRepo, h.loungeOwnerRepo)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateLoungeNoShowPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	policy, err := h.noShowService.UpdatePolicy(lounge.ID.String(), &req)
+	if err != nil {
+		h.respondNoShowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "No-show policy updated", "no_show_policy": policy})
+}
+
+func (h *LoungeNoShowHandler) respondNoShowError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: validationErr.Message})
+		return
+	}
+	log.Printf("ERROR: Lounge no-show policy request failed: %v", err)
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "no_show_policy_error", Message: "Failed to process no-show policy"})
+}
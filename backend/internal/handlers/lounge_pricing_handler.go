@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// LoungePricingHandler handles lounge price quotes and the owner's price rules and surge settings
+type LoungePricingHandler struct {
+	pricingService  *services.LoungePricingService
+	loungeRepo      *database.LoungeRepository
+	loungeOwnerRepo *database.LoungeOwnerRepository
+}
+
+// NewLoungePricingHandler creates a new LoungePricingHandler
+func NewLoungePricingHandler(
+	pricingService *services.LoungePricingService,
+	loungeRepo *database.LoungeRepository,
+	loungeOwnerRepo *database.LoungeOwnerRepository,
+) *LoungePricingHandler {
+	return &LoungePricingHandler{
+		pricingService:  pricingService,
+		loungeRepo:      loungeRepo,
+		loungeOwnerRepo: loungeOwnerRepo,
+	}
+}
+
+// GetPriceQuote handles GET /api/v1/lounges/:id/price?pricing_type=2_hours&check_in_time=RFC3339
+// Quotes are indicative; the price is locked when a booking intent is created.
+func (h *LoungePricingHandler) GetPriceQuote(c *gin.Context) {
+	loungeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_id", Message: "Invalid lounge ID format"})
+		return
+	}
+
+	checkIn := time.Now()
+	if value := c.Query("check_in_time"); value != "" {
+		checkIn, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: "Invalid check_in_time format. Use ISO 8601 (RFC3339)"})
+			return
+		}
+	}
+
+	lounge, err := h.loungeRepo.GetLoungeByID(loungeID)
+	if err != nil || lounge == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "not_found", Message: "Lounge not found"})
+		return
+	}
+
+	quote, err := h.pricingService.Quote(lounge, c.Query("pricing_type"), checkIn)
+	if err != nil {
+		h.respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quote": quote})
+}
+
+// GetPriceRules handles GET /api/v1/lounges/:id/price-rules
+func (h *LoungePricingHandler) GetPriceRules(c *gin.Context) {
+	lounge, ok := h.ownedLounge(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.pricingService.ListRules(lounge.ID.String())
+	if err != nil {
+		h.respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules, "total": len(rules)})
+}
+
+// CreatePriceRule handles POST /api/v1/lounges/:id/price-rules
+func (h *LoungePricingHandler) CreatePriceRule(c *gin.Context) {
+	lounge, ok := h.ownedLounge(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateLoungePriceRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rule, err := h.pricingService.CreateRule(lounge.ID.String(), &req)
+	if err != nil {
+		h.respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Price rule created", "rule": rule})
+}
+
+// UpdatePriceRule handles PUT /api/v1/lounges/:id/price-rules/:rule_id
+func (h *LoungePricingHandler) UpdatePriceRule(c *gin.Context) {
+	lounge, ok := h.ownedLounge(c)
+	if !ok {
+		return
+	}
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateLoungePriceRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rule, err := h.pricingService.UpdateRule(lounge.ID.String(), ruleID, &req)
+	if err != nil {
+		h.respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price rule updated", "rule": rule})
+}
+
+// DeletePriceRule handles DELETE /api/v1/lounges/:id/price-rules/:rule_id
+func (h *LoungePricingHandler) DeletePriceRule(c *gin.Context) {
+	lounge, ok := h.ownedLounge(c)
+	if !ok {
+		return
+	}
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.pricingService.DeleteRule(lounge.ID.String(), ruleID); err != nil {
+		h.respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price rule deleted"})
+}
+
+// GetSurgeSettings handles GET /api/v1/lounges/:id/surge-pricing
+func (h *LoungePricingHandler) GetSurgeSettings(c *gin.Context) {
+	lounge, ok := h.ownedLounge(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.pricingService.GetSurgeSettings(lounge.ID.String())
+	if err != nil {
+		h.respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"surge": settings})
+}
+
+// UpdateSurgeSettings handles PUT /api/v1/lounges/:id/surge-pricing
+func (h *LoungePricingHandler) UpdateSurgeSettings(c *gin.Context) {
+	lounge, ok := h.ownedLounge(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateLoungeSurgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	settings, err := h.pricingService.UpdateSurgeSettings(lounge.ID.String(), &req)
+	if err != nil {
+		h.respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Surge pricing updated", "surge": settings})
+}
+
+// ownedLounge loads the lounge in the path and checks the caller owns it, responding on failure
+func (h *LoungePricingHandler) ownedLounge(c *gin.Context) (*models.Lounge, bool) {
+	return resolveOwnedLounge(c, h.loungeRepo, h.loungeOwnerRepo)
+}
+
+// resolveOwnedLounge loads the lounge in the :id path param and checks the caller owns it,
+// responding on failure. Shared by the lounge owner settings handlers.
+func resolveOwnedLounge(c *gin.Context, loungeRepo *database.LoungeRepository, loungeOwnerRepo *database.LoungeOwnerRepository) (*models.Lounge, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized", Message: "User context not found"})
+		return nil, false
+	}
+
+	loungeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_id", Message: "Invalid lounge ID format"})
+		return nil, false
+	}
+
+	owner, err := loungeOwnerRepo.GetLoungeOwnerByUserID(userCtx.UserID)
+	if err != nil || owner == nil {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "forbidden", Message: "Not a lounge owner"})
+		return nil, false
+	}
+
+	lounge, err := loungeRepo.GetLoungeByID(loungeID)
+	if err != nil || lounge == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "not_found", Message: "Lounge not found"})
+		return nil, false
+	}
+	if lounge.LoungeOwnerID != owner.ID {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "forbidden", Message: "You don't own this lounge"})
+		return nil, false
+	}
+	return lounge, true
+}
+
+func parseRuleID(c *gin.Context) (string, bool) {
+	ruleID := c.Param("rule_id")
+	if _, err := uuid.Parse(ruleID); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_id", Message: "Invalid rule ID format"})
+		return "", false
+	}
+	return ruleID, true
+}
+
+func (h *LoungePricingHandler) respondPricingError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: validationErr.Message})
+	case errors.Is(err, services.ErrInvalidLoungePricing):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: "pricing_type must be one of 1_hour, 2_hours, 3_hours, until_bus"})
+	case errors.Is(err, services.ErrLoungePriceRuleNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "not_found", Message: err.Error()})
+	default:
+		log.Printf("ERROR: Lounge pricing request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "pricing_error", Message: "Failed to process lounge pricing"})
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// LoungeReviewHandler handles guests' lounge and product reviews
+type LoungeReviewHandler struct {
+	reviewService *services.LoungeReviewService
+}
+
+// NewLoungeReviewHandler creates a new LoungeReviewHandler
+func NewLoungeReviewHandler(reviewService *services.LoungeReviewService) *LoungeReviewHandler {
+	return &LoungeReviewHandler{reviewService: reviewService}
+}
+
+// ReviewLounge handles POST /api/v1/lounge-bookings/:id/review
+func (h *LoungeReviewHandler) ReviewLounge(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized", Message: "User context not found"})
+		return
+	}
+	bookingID, ok := parseUUIDParam(c, "id", "Invalid booking ID format")
+	if !ok {
+		return
+	}
+
+	var req models.CreateLoungeReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.ReviewLounge(userCtx.UserID, bookingID, &req)
+	if err != nil {
+		h.respondReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Thank you for your review", "review": review})
+}
+
+// ReviewProduct handles POST /api/v1/lounge-bookings/:id/products/:product_id/review
+func (h *LoungeReviewHandler) ReviewProduct(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized", Message: "User context not found"})
+		return
+	}
+	bookingID, ok := parseUUIDParam(c, "id", "Invalid booking ID format")
+	if !ok {
+		return
+	}
+	productID, ok := parseUUIDParam(c, "product_id", "Invalid product ID format")
+	if !ok {
+		return
+	}
+
+	var req models.CreateLoungeReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.ReviewProduct(userCtx.UserID, bookingID, productID, &req)
+	if err != nil {
+		h.respondReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Thank you for your review", "review": review})
+}
+
+// GetLoungeReviews handles GET /api/v1/lounges/:id/reviews?limit=&offset=
+func (h *LoungeReviewHandler) GetLoungeReviews(c *gin.Context) {
+	loungeID, ok := parseUUIDParam(c, "id", "Invalid lounge ID format")
+	if !ok {
+		return
+	}
+	limit, offset := payoutPagination(c)
+
+	page, err := h.reviewService.ListLoungeReviews(loungeID, limit, offset)
+	if err != nil {
+		h.respondReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetProductReviews handles GET /api/v1/lounges/:id/products/:product_id/reviews?limit=&offset=
+func (h *LoungeReviewHandler) GetProductReviews(c *gin.Context) {
+	loungeID, ok := parseUUIDParam(c, "id", "Invalid lounge ID format")
+	if !ok {
+		return
+	}
+	productID, ok := parseUUIDParam(c, "product_id", "Invalid product ID format")
+	if !ok {
+		return
+	}
+	limit, offset := payoutPagination(c)
+
+	page, err := h.reviewService.ListProductReviews(loungeID, productID, limit, offset)
+	if err != nil {
+		h.respondReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func parseUUIDParam(c *gin.Context, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_id", Message: message})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *LoungeReviewHandler) respondReviewError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "validation_error", Message: validationErr.Message})
+	case errors.Is(err, services.ErrLoungeReviewBookingNotFound),
+		errors.Is(err, services.ErrLoungeReviewLoungeNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "not_found", Message: err.Error()})
+	case errors.Is(err, services.ErrLoungeReviewProductNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "product_not_ordered", Message: err.Error()})
+	case errors.Is(err, services.ErrLoungeReviewNotAllowed):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "review_not_allowed", Message: err.Error()})
+	case errors.Is(err, services.ErrLoungeReviewDuplicate):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "already_reviewed", Message: err.Error()})
+	default:
+		log.Printf("ERROR: Lounge review request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "review_error", Message: "Failed to process review"})
+	}
+}
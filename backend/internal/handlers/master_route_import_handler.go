@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// masterRouteImportMaxBytes bounds an import upload
+const masterRouteImportMaxBytes = 5 << 20
+
+// MasterRouteImportHandler lets admins seed master routes and their stops from a file
+type MasterRouteImportHandler struct {
+	importService *services.MasterRouteImportService
+	logger        *logrus.Logger
+}
+
+// NewMasterRouteImportHandler creates a new MasterRouteImportHandler
+func NewMasterRouteImportHandler(importService *services.MasterRouteImportService, logger *logrus.Logger) *MasterRouteImportHandler {
+	return &MasterRouteImportHandler{importService: importService, logger: logger}
+}
+
+// ImportMasterRoutes creates master routes with their stops from a CSV or JSON upload, either
+// as a multipart "file" field or as the request body (Content-Type text/csv or
+// application/json). Every row-level problem is reported and nothing is written unless the
+// whole upload is valid; dry_run=true only validates.
+// POST /api/v1/admin/master-routes/import?dry_run=true
+func (h *MasterRouteImportHandler) ImportMasterRoutes(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, masterRouteImportMaxBytes)
+
+	body, isJSON, err := masterRouteImportUpload(c)
+	if err != nil {
+		respondImportUploadError(c, err)
+		return
+	}
+	defer body.Close()
+
+	var imp *models.MasterRouteImport
+	if isJSON {
+		imp, err = services.ParseMasterRouteImportJSON(body)
+	} else {
+		imp, err = services.ParseMasterRouteImportCSV(body)
+	}
+	if err != nil {
+		respondImportUploadError(c, err)
+		return
+	}
+
+	result, err := h.importService.Import(imp, dryRun)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to import master routes")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to import master routes"})
+		return
+	}
+	if !result.Valid {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	if !dryRun {
+		fields := logrus.Fields{"routes": len(result.Routes)}
+		if userCtx, ok := middleware.GetUserContext(c); ok {
+			fields["admin_id"] = userCtx.UserID
+		}
+		h.logger.WithFields(fields).Info("Admin imported master routes")
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// respondImportUploadError writes 413 when the upload hit the size limit and 400 otherwise.
+// Parse errors only carry the body reader's error text, so the limit is matched by message.
+func respondImportUploadError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "request body too large") {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "validation_error", "message": "the upload is larger than 5 MB"})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+}
+
+// masterRouteImportUpload returns the uploaded file and whether it is JSON rather than CSV
+func masterRouteImportUpload(c *gin.Context) (io.ReadCloser, bool, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			return nil, false, err
+		}
+		if err != nil {
+			return nil, false, errors.New("a CSV or JSON file is required in the \"file\" field")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, false, errors.New("the uploaded file could not be read")
+		}
+		isJSON := strings.EqualFold(filepath.Ext(header.Filename), ".json") ||
+			strings.HasPrefix(header.Header.Get("Content-Type"), "application/json")
+		return file, isJSON, nil
+	}
+
+	switch c.ContentType() {
+	case "application/json":
+		return c.Request.Body, true, nil
+	case "text/csv", "application/csv":
+		return c.Request.Body, false, nil
+	default:
+		return nil, false, errors.New("upload a multipart \"file\", or send text/csv or application/json")
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// MeHandler serves the unified, role-aware view of the signed-in user
+type MeHandler struct {
+	meService *services.MeService
+	logger    *logrus.Logger
+}
+
+// NewMeHandler creates a new MeHandler
+func NewMeHandler(meService *services.MeService, logger *logrus.Logger) *MeHandler {
+	return &MeHandler{
+		meService: meService,
+		logger:    logger,
+	}
+}
+
+// GetMe returns the user, a profile summary per role and each role's onboarding state
+// GET /api/v1/me
+func (h *MeHandler) GetMe(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	me, err := h.meService.GetMe(userCtx.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrMeUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user_not_found", "message": "User not found"})
+			return
+		}
+		h.logger.WithError(err).WithField("user_id", userCtx.UserID).Error("Failed to load user roles and profiles")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to load user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, me)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// operatorTimetableCacheControl lets CDNs and browsers cache public timetables briefly
+const operatorTimetableCacheControl = "public, max-age=300"
+
+// OperatorHandler handles public (unauthenticated) bus operator pages
+type OperatorHandler struct {
+	busOwnerRepo     *database.BusOwnerRepository
+	tripScheduleRepo *database.TripScheduleRepository
+}
+
+// NewOperatorHandler creates a new operator handler
+func NewOperatorHandler(
+	busOwnerRepo *database.BusOwnerRepository,
+	tripScheduleRepo *database.TripScheduleRepository,
+) *OperatorHandler {
+	return &OperatorHandler{
+		busOwnerRepo:     busOwnerRepo,
+		tripScheduleRepo: tripScheduleRepo,
+	}
+}
+
+// GetOperatorTimetable returns an operator's published routes and recurring departure times
+// GET /api/v1/operators/:id/timetable
+func (h *OperatorHandler) GetOperatorTimetable(c *gin.Context) {
+	operatorID := c.Param("id")
+	if _, err := uuid.Parse(operatorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid operator ID"})
+		return
+	}
+
+	// Only verified operators have public pages
+	owner, err := h.busOwnerRepo.GetByID(operatorID)
+	if err != nil || owner.VerificationStatus != models.VerificationVerified {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Operator not found"})
+		return
+	}
+
+	entries, err := h.tripScheduleRepo.GetPublishedTimetableByBusOwnerID(owner.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timetable"})
+		return
+	}
+
+	response := models.OperatorTimetableResponse{
+		Operator: models.OperatorPublicProfile{
+			ID:          owner.ID,
+			CompanyName: owner.CompanyName,
+			City:        owner.City,
+			TotalBuses:  owner.TotalBuses,
+			// Weighted across the timetables shown on the page
+			OnTimePercentage: models.OperatorOnTimePercentage(entries),
+		},
+		Routes:      buildOperatorTimetableRoutes(entries),
+		GeneratedAt: time.Now(),
+	}
+
+	c.Header("Cache-Control", operatorTimetableCacheControl)
+	c.JSON(http.StatusOK, response)
+}
+
+// buildOperatorTimetableRoutes groups timetable rows by route, keeping query order
+func buildOperatorTimetableRoutes(entries []models.PublishedTimetableEntry) []models.OperatorTimetableRoute {
+	routes := make([]models.OperatorTimetableRoute, 0)
+	routeIndex := make(map[string]int)
+
+	for _, entry := range entries {
+		idx, exists := routeIndex[entry.RouteID]
+		if !exists {
+			routes = append(routes, models.OperatorTimetableRoute{
+				RouteID:         entry.RouteID,
+				RouteNumber:     entry.RouteNumber,
+				RouteName:       entry.CustomRouteName,
+				OriginCity:      entry.OriginCity,
+				DestinationCity: entry.DestinationCity,
+				Direction:       entry.Direction,
+				Departures:      []models.OperatorDeparture{},
+			})
+			idx = len(routes) - 1
+			routeIndex[entry.RouteID] = idx
+		}
+
+		routes[idx].Departures = append(routes[idx].Departures, buildOperatorDeparture(entry))
+	}
+
+	return routes
+}
+
+// buildOperatorDeparture converts a timetable row into a public departure entry
+func buildOperatorDeparture(entry models.PublishedTimetableEntry) models.OperatorDeparture {
+	departure := models.OperatorDeparture{
+		ScheduleID:      entry.ScheduleID,
+		DepartureTime:   entry.DepartureTime,
+		DurationMinutes: entry.EstimatedDurationMinutes,
+		RecurrenceType:  entry.RecurrenceType,
+		BaseFare:        entry.BaseFare,
+
+		OnTimePercentage: entry.OnTimePercentage,
+	}
+
+	// Normalize HH:MM:SS to HH:MM and derive arrival time from duration
+	depTime, err := time.Parse("15:04:05", entry.DepartureTime)
+	if err != nil {
+		depTime, err = time.Parse("15:04", entry.DepartureTime)
+	}
+	if err == nil {
+		departure.DepartureTime = depTime.Format("15:04")
+		if entry.EstimatedDurationMinutes != nil {
+			arrival := depTime.Add(time.Duration(*entry.EstimatedDurationMinutes) * time.Minute).Format("15:04")
+			departure.ArrivalTime = &arrival
+		}
+	}
+
+	switch entry.RecurrenceType {
+	case models.RecurrenceWeekly:
+		days, err := models.StringToIntSlice(entry.RecurrenceDays)
+		if err == nil {
+			departure.DaysOfWeek = make([]string, 0, len(days))
+			for _, day := range days {
+				if day >= 0 && day <= 6 {
+					departure.DaysOfWeek = append(departure.DaysOfWeek, time.Weekday(day).String()[:3])
+				}
+			}
+		}
+	case models.RecurrenceInterval:
+		departure.IntervalDays = entry.RecurrenceInterval
+	}
+
+	if entry.ValidFrom != nil {
+		validFrom := entry.ValidFrom.Format("2006-01-02")
+		departure.ValidFrom = &validFrom
+	}
+	if entry.ValidUntil != nil {
+		validUntil := entry.ValidUntil.Format("2006-01-02")
+		departure.ValidUntil = &validUntil
+	}
+
+	return departure
+}
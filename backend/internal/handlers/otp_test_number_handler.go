@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
+	"github.com/smarttransit/sms-auth-backend/pkg/validator"
+)
+
+// OTPTestNumberHandler lets super admins manage the QA test number allowlist
+type OTPTestNumberHandler struct {
+	testNumberService *services.OTPTestNumberService
+	auditService      *services.AuditService
+	phoneValidator    *validator.PhoneValidator
+	logger            *logrus.Logger
+}
+
+// NewOTPTestNumberHandler creates a new OTPTestNumberHandler
+func NewOTPTestNumberHandler(
+	testNumberService *services.OTPTestNumberService,
+	auditService *services.AuditService,
+	phoneValidator *validator.PhoneValidator,
+	logger *logrus.Logger,
+) *OTPTestNumberHandler {
+	return &OTPTestNumberHandler{
+		testNumberService: testNumberService,
+		auditService:      auditService,
+		phoneValidator:    phoneValidator,
+		logger:            logger,
+	}
+}
+
+// ListNumbers lists the QA test numbers, masked
+// GET /api/v1/admin/otp-test-numbers
+func (h *OTPTestNumberHandler) ListNumbers(c *gin.Context) {
+	numbers, err := h.testNumberService.List()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list QA test numbers")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to list QA test numbers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"numbers": numbers, "enabled": h.testNumberService.Enabled()})
+}
+
+// AddNumber puts a phone number on the QA allowlist
+// POST /api/v1/admin/otp-test-numbers
+func (h *OTPTestNumberHandler) AddNumber(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.AddOTPTestNumberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+	phone, err := h.phoneValidator.Validate(req.Phone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_phone", "message": err.Error()})
+		return
+	}
+
+	number, err := h.testNumberService.Add(phone, &req, userCtx.UserID.String())
+	if err != nil {
+		var validationErr *models.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+		case errors.Is(err, services.ErrOTPTestNumberExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "already_listed", "message": "This number is already a QA test number"})
+		case errors.Is(err, services.ErrOTPTestNumberHasAccount):
+			c.JSON(http.StatusConflict, gin.H{"error": "number_in_use", "message": "This number belongs to an existing account. Use a dedicated QA number."})
+		default:
+			h.logger.WithError(err).Error("Failed to add QA test number")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to add QA test number"})
+		}
+		return
+	}
+
+	h.audit(c, userCtx.UserID, models.AuditQATestNumberAdded, number)
+	c.JSON(http.StatusCreated, gin.H{"message": "QA test number added", "number": number})
+}
+
+// RemoveNumber takes a phone number off the QA allowlist
+// DELETE /api/v1/admin/otp-test-numbers/:id
+func (h *OTPTestNumberHandler) RemoveNumber(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+	if _, err := uuid.Parse(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_id", "message": "Invalid QA test number ID"})
+		return
+	}
+
+	number, err := h.testNumberService.Remove(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrOTPTestNumberNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "QA test number not found"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to remove QA test number")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to remove QA test number"})
+		return
+	}
+
+	h.audit(c, userCtx.UserID, models.AuditQATestNumberRemoved, number)
+	c.JSON(http.StatusOK, gin.H{"message": "QA test number removed"})
+}
+
+// GetCode returns the fixed OTP QA test numbers accept on this deploy
+// GET /api/v1/admin/otp-test-numbers/code
+func (h *OTPTestNumberHandler) GetCode(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+	if !h.testNumberService.Enabled() {
+		c.JSON(http.StatusConflict, gin.H{"error": "disabled", "message": "QA test numbers are disabled on this deploy"})
+		return
+	}
+
+	code := h.testNumberService.Code()
+	h.audit(c, userCtx.UserID, models.AuditQAOTPCodeViewed, nil)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, code)
+}
+
+// audit records a super admin's allowlist action
+func (h *OTPTestNumberHandler) audit(c *gin.Context, adminID uuid.UUID, action string, number *models.OTPTestNumber) {
+	var numberID *uuid.UUID
+	details := map[string]interface{}{"deploy_id": h.testNumberService.Code().DeployID}
+	if number != nil {
+		if id, err := uuid.Parse(number.ID); err == nil {
+			numberID = &id
+		}
+		details["phone_masked"] = number.PhoneMasked
+		details["label"] = number.Label
+	}
+	if err := h.auditService.LogOTPTestNumberEvent(&adminID, action, numberID, utils.GetRealIP(c), utils.GetUserAgent(c), details); err != nil {
+		h.logger.WithError(err).Warn("Failed to audit QA test number change")
+	}
+}
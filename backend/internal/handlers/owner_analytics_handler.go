@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// OwnerAnalyticsHandler serves the bus owner analytics dashboard
+type OwnerAnalyticsHandler struct {
+	analyticsService *services.OwnerAnalyticsService
+	busOwnerRepo     *database.BusOwnerRepository
+	logger           *logrus.Logger
+}
+
+// NewOwnerAnalyticsHandler creates a new OwnerAnalyticsHandler
+func NewOwnerAnalyticsHandler(
+	analyticsService *services.OwnerAnalyticsService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *OwnerAnalyticsHandler {
+	return &OwnerAnalyticsHandler{
+		analyticsService: analyticsService,
+		busOwnerRepo:     busOwnerRepo,
+		logger:           logger,
+	}
+}
+
+// GetAnalytics returns the owner's revenue per route and bus, seat occupancy, cancellation
+// rates and on-time performance, with daily figures. Today's figures lag by up to the rollup
+// interval; rolled_up_at tells how fresh they are.
+// GET /api/v1/bus-owner/analytics?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *OwnerAnalyticsHandler) GetAnalytics(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return
+	}
+
+	var req models.OwnerAnalyticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	report, err := h.analyticsService.GetAnalytics(busOwner.ID, &req)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+			return
+		}
+		h.logger.WithError(err).WithField("bus_owner_id", busOwner.ID).Error("Failed to build owner analytics")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to build analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// OwnerPayoutHandler handles owner bank accounts and payout history, and the admin payout workflow
+type OwnerPayoutHandler struct {
+	payoutService   *services.OwnerPayoutService
+	busOwnerRepo    *database.BusOwnerRepository
+	loungeOwnerRepo *database.LoungeOwnerRepository
+}
+
+// NewOwnerPayoutHandler creates a new OwnerPayoutHandler
+func NewOwnerPayoutHandler(
+	payoutService *services.OwnerPayoutService,
+	busOwnerRepo *database.BusOwnerRepository,
+	loungeOwnerRepo *database.LoungeOwnerRepository,
+) *OwnerPayoutHandler {
+	return &OwnerPayoutHandler{
+		payoutService:   payoutService,
+		busOwnerRepo:    busOwnerRepo,
+		loungeOwnerRepo: loungeOwnerRepo,
+	}
+}
+
+// ============================================================================
+// OWNER ENDPOINTS
+// ============================================================================
+
+// GetPayouts handles GET /api/v1/payouts
+// Query: owner_type (bus_owner|lounge_owner, only needed if the user is both), limit, offset
+func (h *OwnerPayoutHandler) GetPayouts(c *gin.Context) {
+	ownerType, ownerID, ok := h.resolveOwner(c)
+	if !ok {
+		return
+	}
+	limit, offset := payoutPagination(c)
+
+	payouts, total, err := h.payoutService.ListOwnerPayouts(ownerType, ownerID, limit, offset)
+	if err != nil {
+		h.respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payouts": payouts, "total": total, "limit": limit, "offset": offset})
+}
+
+// GetBankAccount handles GET /api/v1/payouts/bank-account
+func (h *OwnerPayoutHandler) GetBankAccount(c *gin.Context) {
+	ownerType, ownerID, ok := h.resolveOwner(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.payoutService.GetBankAccount(ownerType, ownerID)
+	if err != nil {
+		h.respondPayoutError(c, err)
+		return
+	}
+	if account == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bank_account_not_found", "message": "No bank account on file"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bank_account": account})
+}
+
+// UpdateBankAccount handles PUT /api/v1/payouts/bank-account
+// Saving puts the account back to pending until an admin verifies it
+func (h *OwnerPayoutHandler) UpdateBankAccount(c *gin.Context) {
+	ownerType, ownerID, ok := h.resolveOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpsertBankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	account, err := h.payoutService.SaveBankAccount(ownerType, ownerID, &req)
+	if err != nil {
+		h.respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bank account saved and pending verification", "bank_account": account})
+}
+
+// resolveOwner finds the bus or lounge owner profile of the caller
+func (h *OwnerPayoutHandler) resolveOwner(c *gin.Context) (models.PayoutOwnerType, uuid.UUID, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User context not found"})
+		return "", uuid.Nil, false
+	}
+	requested := models.PayoutOwnerType(c.Query("owner_type"))
+
+	if requested == "" || requested == models.PayoutOwnerBus {
+		busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+		if err != nil && err != sql.ErrNoRows {
+			log.Printf("ERROR: Failed to get bus owner for payouts: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "payout_error", "message": "Failed to get owner profile"})
+			return "", uuid.Nil, false
+		}
+		if err == nil && busOwner != nil {
+			ownerID, err := uuid.Parse(busOwner.ID)
+			if err == nil {
+				return models.PayoutOwnerBus, ownerID, true
+			}
+		}
+	}
+
+	if requested == "" || requested == models.PayoutOwnerLounge {
+		loungeOwner, err := h.loungeOwnerRepo.GetLoungeOwnerByUserID(userCtx.UserID)
+		if err != nil {
+			log.Printf("ERROR: Failed to get lounge owner for payouts: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "payout_error", "message": "Failed to get owner profile"})
+			return "", uuid.Nil, false
+		}
+		if loungeOwner != nil {
+			return models.PayoutOwnerLounge, loungeOwner.ID, true
+		}
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "not_an_owner", "message": "Payouts are only available to bus and lounge owners"})
+	return "", uuid.Nil, false
+}
+
+// ============================================================================
+// ADMIN ENDPOINTS
+// ============================================================================
+
+// ListBankAccounts handles GET /api/v1/admin/payouts/bank-accounts
+// Query: status (pending|verified|rejected), limit, offset
+func (h *OwnerPayoutHandler) ListBankAccounts(c *gin.Context) {
+	limit, offset := payoutPagination(c)
+
+	accounts, total, err := h.payoutService.ListBankAccounts(models.BankAccountStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		h.respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bank_accounts": accounts, "total": total, "limit": limit, "offset": offset})
+}
+
+// VerifyBankAccount handles PUT /api/v1/admin/payouts/bank-accounts/:id/verify
+func (h *OwnerPayoutHandler) VerifyBankAccount(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User context not found"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_bank_account_id", "message": "Invalid bank account ID"})
+		return
+	}
+
+	var req models.VerifyBankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	account, err := h.payoutService.VerifyBankAccount(id, &req, userCtx.UserID)
+	if err != nil {
+		h.respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bank account " + string(account.Status), "bank_account": account})
+}
+
+// GenerateBatch handles POST /api/v1/admin/payouts/batches
+// Generates the last finished cycle's batch unless period_start/period_end are given
+func (h *OwnerPayoutHandler) GenerateBatch(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User context not found"})
+		return
+	}
+
+	var req models.GeneratePayoutBatchRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+
+	periodStart, periodEnd := h.payoutService.CurrentPeriod(time.Now())
+	if req.PeriodStart != nil || req.PeriodEnd != nil {
+		if req.PeriodStart == nil || req.PeriodEnd == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "period_start and period_end must be given together"})
+			return
+		}
+		periodStart, periodEnd = *req.PeriodStart, *req.PeriodEnd
+	}
+
+	batch, err := h.payoutService.GenerateBatch(periodStart, periodEnd, &userCtx.UserID)
+	if err != nil {
+		h.respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"batch": batch})
+}
+
+// ListBatches handles GET /api/v1/admin/payouts/batches
+func (h *OwnerPayoutHandler) ListBatches(c *gin.Context) {
+	limit, offset := payoutPagination(c)
+
+	batches, total, err := h.payoutService.ListBatches(limit, offset)
+	if err != nil {
+		h.respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batches": batches, "total": total, "limit": limit, "offset": offset})
+}
+
+// GetBatch handles GET /api/v1/admin/payouts/batches/:id
+// Includes each payout's full bank details for making the transfers
+func (h *OwnerPayoutHandler) GetBatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_batch_id", "message": "Invalid batch ID"})
+		return
+	}
+
+	batch, payouts, err := h.payoutService.GetBatch(id)
+	if err != nil {
+		h.respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batch": batch, "payouts": payouts})
+}
+
+// UpdatePayoutStatus handles PUT /api/v1/admin/payouts/:id/status
+func (h *OwnerPayoutHandler) UpdatePayoutStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_payout_id", "message": "Invalid payout ID"})
+		return
+	}
+
+	var req models.UpdatePayoutStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	payout, err := h.payoutService.UpdatePayoutStatus(id, &req)
+	if err != nil {
+		h.respondPayoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Payout updated", "payout": payout})
+}
+
+func payoutPagination(c *gin.Context) (int, int) {
+	// Parse pagination (default 50, max 100)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+func (h *OwnerPayoutHandler) respondPayoutError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrBankAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "bank_account_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrPayoutBatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "batch_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrPayoutNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "payout_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrBankAccountNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": "bank_account_not_pending", "message": err.Error()})
+	case errors.Is(err, services.ErrPayoutBatchExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "batch_exists", "message": err.Error()})
+	case errors.Is(err, services.ErrPayoutPeriodNotFinished):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "period_not_finished", "message": err.Error()})
+	case errors.Is(err, services.ErrPayoutInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": "invalid_payout_state", "message": err.Error()})
+	default:
+		log.Printf("ERROR: Payout request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "payout_error", "message": "Failed to process payout request"})
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// PaymentAuditHandler lets finance search and export payment audit entries
+type PaymentAuditHandler struct {
+	paymentAuditRepo *database.PaymentAuditRepository
+}
+
+// NewPaymentAuditHandler creates a new PaymentAuditHandler
+func NewPaymentAuditHandler(paymentAuditRepo *database.PaymentAuditRepository) *PaymentAuditHandler {
+	return &PaymentAuditHandler{paymentAuditRepo: paymentAuditRepo}
+}
+
+// SearchPaymentAudits handles GET /api/v1/admin/payments/audit
+// Query: status, event_type, gateway, from, to, min_amount, max_amount, intent_id, reference,
+// limit, offset, format=csv
+func (h *PaymentAuditHandler) SearchPaymentAudits(c *gin.Context) {
+	filter, err := parsePaymentAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_filter", "message": err.Error()})
+		return
+	}
+
+	exportCSV := strings.EqualFold(c.Query("format"), "csv")
+	if exportCSV {
+		filter.Limit = models.PaymentAuditExportLimit
+		filter.Offset = 0
+	}
+
+	audits, total, err := h.paymentAuditRepo.Search(c.Request.Context(), filter)
+	if err != nil {
+		log.Printf("ERROR: Failed to search payment audits: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch_failed", "message": "Failed to search payment audits"})
+		return
+	}
+
+	if exportCSV {
+		h.writeCSV(c, audits, total)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"audits": audits,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+func (h *PaymentAuditHandler) writeCSV(c *gin.Context, audits []*models.PaymentAudit, total int) {
+	filename := fmt.Sprintf("payment-audit-%s.csv", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	if total > len(audits) {
+		// Finance should narrow the filters rather than silently miss rows
+		c.Header("X-Export-Truncated", "true")
+	}
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(models.PaymentAuditCSVHeader)
+	for _, audit := range audits {
+		_ = w.Write(audit.CSVRecord())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("ERROR: Failed to write payment audit export: %v", err)
+	}
+}
+
+// parsePaymentAuditFilter reads the search filters from the query string.
+// Dates may be YYYY-MM-DD (a "to" date includes that whole day) or RFC3339 timestamps.
+func parsePaymentAuditFilter(c *gin.Context) (models.PaymentAuditFilter, error) {
+	filter := models.PaymentAuditFilter{
+		PaymentStatus: strings.TrimSpace(c.Query("status")),
+		EventType:     models.PaymentEventType(strings.TrimSpace(c.Query("event_type"))),
+		Gateway:       strings.TrimSpace(c.Query("gateway")),
+		Reference:     strings.TrimSpace(c.Query("reference")),
+	}
+
+	var err error
+	if filter.From, err = parseAuditTime(c.Query("from"), false); err != nil {
+		return filter, errors.New("invalid from: use YYYY-MM-DD or RFC3339")
+	}
+	if filter.To, err = parseAuditTime(c.Query("to"), true); err != nil {
+		return filter, errors.New("invalid to: use YYYY-MM-DD or RFC3339")
+	}
+	if filter.MinAmount, err = parseAuditAmount(c.Query("min_amount")); err != nil {
+		return filter, errors.New("invalid min_amount")
+	}
+	if filter.MaxAmount, err = parseAuditAmount(c.Query("max_amount")); err != nil {
+		return filter, errors.New("invalid max_amount")
+	}
+	if value := c.Query("intent_id"); value != "" {
+		intentID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, errors.New("invalid intent_id")
+		}
+		filter.IntentID = &intentID
+	}
+
+	// Parse pagination (default 50, max 200)
+	filter.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || filter.Limit < 1 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 200 {
+		filter.Limit = 200
+	}
+	filter.Offset, err = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return filter, filter.Validate()
+}
+
+func parseAuditTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, models.ReportTimezone)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func parseAuditAmount(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &amount, nil
+}
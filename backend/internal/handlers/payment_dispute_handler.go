@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// PaymentDisputeHandler handles the finance dispute workflow. PAYable documents no signed
+// chargeback notification, so there is no dispute webhook; finance records chargebacks from
+// the merchant portal.
+type PaymentDisputeHandler struct {
+	disputeService *services.PaymentDisputeService
+}
+
+// NewPaymentDisputeHandler creates a new PaymentDisputeHandler
+func NewPaymentDisputeHandler(disputeService *services.PaymentDisputeService) *PaymentDisputeHandler {
+	return &PaymentDisputeHandler{
+		disputeService: disputeService,
+	}
+}
+
+// ListDisputes handles GET /api/v1/admin/payments/disputes
+// Query: status, bus_owner_id, limit, offset
+func (h *PaymentDisputeHandler) ListDisputes(c *gin.Context) {
+	filter := models.PaymentDisputeFilter{Status: models.PaymentDisputeStatus(c.Query("status"))}
+	if value := c.Query("bus_owner_id"); value != "" {
+		ownerID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_bus_owner_id", "message": "Invalid bus owner ID"})
+			return
+		}
+		filter.BusOwnerID = &ownerID
+	}
+
+	// Parse pagination (default 50, max 100)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	filter.Limit, filter.Offset = limit, offset
+
+	disputes, total, err := h.disputeService.List(filter)
+	if err != nil {
+		h.respondDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"disputes": disputes, "total": total, "limit": limit, "offset": offset})
+}
+
+// CreateDispute handles POST /api/v1/admin/payments/disputes
+// Records a chargeback PAYable reported to finance (merchant portal or email)
+func (h *PaymentDisputeHandler) CreateDispute(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User context not found"})
+		return
+	}
+
+	var req models.CreatePaymentDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	dispute, err := h.disputeService.CreateManual(&req, userCtx.UserID)
+	if err != nil {
+		h.respondDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"dispute": dispute})
+}
+
+// GetDispute handles GET /api/v1/admin/payments/disputes/:id
+func (h *PaymentDisputeHandler) GetDispute(c *gin.Context) {
+	id, ok := disputeIDParam(c)
+	if !ok {
+		return
+	}
+
+	dispute, err := h.disputeService.Get(id)
+	if err != nil {
+		h.respondDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dispute": dispute})
+}
+
+// UpdateDisputeStatus handles PUT /api/v1/admin/payments/disputes/:id/status
+func (h *PaymentDisputeHandler) UpdateDisputeStatus(c *gin.Context) {
+	id, ok := disputeIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePaymentDisputeStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	dispute, err := h.disputeService.UpdateStatus(id, &req)
+	if err != nil {
+		h.respondDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dispute updated", "dispute": dispute})
+}
+
+// AddDisputeEvidence handles POST /api/v1/admin/payments/disputes/:id/evidence
+// The file is uploaded to storage by the client first; the body carries its URL
+func (h *PaymentDisputeHandler) AddDisputeEvidence(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User context not found"})
+		return
+	}
+	id, ok := disputeIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddPaymentDisputeEvidenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	evidence, err := h.disputeService.AddEvidence(id, &req, userCtx.UserID)
+	if err != nil {
+		h.respondDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"evidence": evidence})
+}
+
+// GetOwnerSettlement handles GET /api/v1/admin/payments/dispute-settlements/:bus_owner_id
+// Shows how unsettled disputes adjust a bus owner's next payout
+func (h *PaymentDisputeHandler) GetOwnerSettlement(c *gin.Context) {
+	ownerID, err := uuid.Parse(c.Param("bus_owner_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_bus_owner_id", "message": "Invalid bus owner ID"})
+		return
+	}
+
+	summary, err := h.disputeService.SettlementSummary(ownerID)
+	if err != nil {
+		h.respondDisputeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settlement": summary})
+}
+
+func disputeIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_dispute_id", "message": "Invalid dispute ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *PaymentDisputeHandler) respondDisputeError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrDisputeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "dispute_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrDisputePaymentNotFound):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "payment_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrDisputeResolved), errors.Is(err, services.ErrDisputeInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": "invalid_dispute_state", "message": err.Error()})
+	default:
+		log.Printf("ERROR: Payment dispute request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "dispute_error", "message": "Failed to process dispute"})
+	}
+}
@@ -131,6 +131,9 @@ func (h *PermitHandler) GetPermitByID(c *gin.Context) {
 
 	// Get permit
 	permit, err := h.permitRepo.GetByID(permitID)
+	if err == nil && permit.DeletedAt != nil {
+		err = sql.ErrNoRows // A deleted permit is only visible to admins
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Permit not found"})
@@ -241,6 +244,9 @@ func (h *PermitHandler) UpdatePermit(c *gin.Context) {
 
 	// Verify ownership first
 	existingPermit, err := h.permitRepo.GetByID(permitID)
+	if err == nil && existingPermit.DeletedAt != nil {
+		err = sql.ErrNoRows // A deleted permit is only visible to admins
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Permit not found"})
@@ -279,7 +285,7 @@ func (h *PermitHandler) UpdatePermit(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedPermit)
 }
 
-// DeletePermit deletes a permit
+// DeletePermit soft-deletes a permit; refused while upcoming published trips run under it
 // DELETE /api/v1/permits/:id
 func (h *PermitHandler) DeletePermit(c *gin.Context) {
 	// Get user context from JWT middleware
@@ -315,6 +321,9 @@ func (h *PermitHandler) DeletePermit(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Permit not found or access denied"})
 			return
 		}
+		if respondUpcomingTrips(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete permit: " + err.Error()})
 		return
 	}
@@ -348,6 +357,9 @@ func (h *PermitHandler) GetRouteDetails(c *gin.Context) {
 
 	// Get permit
 	permit, err := h.permitRepo.GetByID(permitID)
+	if err == nil && permit.DeletedAt != nil {
+		err = sql.ErrNoRows // A deleted permit is only visible to admins
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Permit not found"})
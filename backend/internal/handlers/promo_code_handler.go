@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// PromoCodeHandler handles admin promo code management and passengers checking a code
+type PromoCodeHandler struct {
+	promoService *services.PromoCodeService
+	logger       *logrus.Logger
+}
+
+// NewPromoCodeHandler creates a new PromoCodeHandler
+func NewPromoCodeHandler(promoService *services.PromoCodeService, logger *logrus.Logger) *PromoCodeHandler {
+	return &PromoCodeHandler{promoService: promoService, logger: logger}
+}
+
+// ListCodes lists promo codes
+// GET /api/v1/admin/promo-codes?active=true&limit=&offset=
+func (h *PromoCodeHandler) ListCodes(c *gin.Context) {
+	limit, offset := payoutPagination(c)
+
+	codes, total, err := h.promoService.ListCodes(c.Query("active") == "true", limit, offset)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promo_codes": codes, "total": total, "limit": limit, "offset": offset})
+}
+
+// GetCode returns a promo code
+// GET /api/v1/admin/promo-codes/:id
+func (h *PromoCodeHandler) GetCode(c *gin.Context) {
+	promo, err := h.promoService.GetCode(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promo_code": promo})
+}
+
+// CreateCode adds a promo code
+// POST /api/v1/admin/promo-codes
+func (h *PromoCodeHandler) CreateCode(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.SavePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	promo, err := h.promoService.CreateCode(userCtx.UserID.String(), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Promo code created", "promo_code": promo})
+}
+
+// UpdateCode replaces a promo code's terms
+// PUT /api/v1/admin/promo-codes/:id
+func (h *PromoCodeHandler) UpdateCode(c *gin.Context) {
+	var req models.SavePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	promo, err := h.promoService.UpdateCode(c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code updated", "promo_code": promo})
+}
+
+// DeleteCode removes a promo code that has never been redeemed
+// DELETE /api/v1/admin/promo-codes/:id
+func (h *PromoCodeHandler) DeleteCode(c *gin.Context) {
+	if err := h.promoService.DeleteCode(c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code removed"})
+}
+
+// ValidateCode tells a passenger what a code takes off the bus and lounge amounts of a booking
+// POST /api/v1/promo-codes/validate
+func (h *PromoCodeHandler) ValidateCode(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.ValidatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	quote, err := h.promoService.Quote(userCtx.UserID.String(), req.Code, req.Lines())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": true, "quote": quote})
+}
+
+func (h *PromoCodeHandler) respondError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrPromoCodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "promo_code_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrPromoCodeExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "promo_code_exists", "message": err.Error()})
+	case errors.Is(err, services.ErrPromoCodeRedeemed):
+		c.JSON(http.StatusConflict, gin.H{"error": "promo_code_redeemed", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Promo code request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Promo code request failed"})
+	}
+}
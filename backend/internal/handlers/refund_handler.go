@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// RefundHandler handles passenger refund requests and the admin refund approval workflow
+type RefundHandler struct {
+	refundService *services.RefundService
+}
+
+// NewRefundHandler creates a new RefundHandler
+func NewRefundHandler(refundService *services.RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+// RequestRefund handles POST /api/v1/bookings/:id/refund
+// Requests a refund of a cancelled, PAYable-paid booking; repeating the request returns the existing refund
+func (h *RefundHandler) RequestRefund(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User context not found"})
+		return
+	}
+
+	var req models.RequestRefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+
+	refund, created, err := h.refundService.RequestRefund(c.Param("id"), userCtx.UserID, &req)
+	if err != nil {
+		h.respondRefundError(c, err)
+		return
+	}
+
+	if created {
+		c.JSON(http.StatusCreated, gin.H{"message": "Refund requested", "refund": refund})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Refund already requested", "refund": refund})
+}
+
+// RequestSeatRefund handles POST /api/v1/bookings/:id/seat-cancellations/:cancellation_id/refund
+// Requests a refund of seats cancelled out of a booking; repeating the request returns the existing refund
+func (h *RefundHandler) RequestSeatRefund(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User context not found"})
+		return
+	}
+
+	cancellationID, err := uuid.Parse(c.Param("cancellation_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_cancellation_id", "message": "Invalid seat cancellation ID"})
+		return
+	}
+	var req models.RequestRefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+
+	refund, created, err := h.refundService.RequestSeatRefund(c.Param("id"), cancellationID, userCtx.UserID, &req)
+	if err != nil {
+		h.respondRefundError(c, err)
+		return
+	}
+
+	if created {
+		c.JSON(http.StatusCreated, gin.H{"message": "Refund requested", "refund": refund})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Refund already requested", "refund": refund})
+}
+
+// ListRefunds handles GET /api/v1/admin/payments/refunds
+// Query: status, limit, offset
+func (h *RefundHandler) ListRefunds(c *gin.Context) {
+	filter := models.RefundFilter{Status: models.RefundStatus(c.Query("status"))}
+
+	// Parse pagination (default 50, max 100)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	filter.Limit, filter.Offset = limit, offset
+
+	refunds, total, err := h.refundService.List(filter)
+	if err != nil {
+		h.respondRefundError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refunds": refunds, "total": total, "limit": limit, "offset": offset})
+}
+
+// GetRefund handles GET /api/v1/admin/payments/refunds/:id
+func (h *RefundHandler) GetRefund(c *gin.Context) {
+	id, ok := refundIDParam(c)
+	if !ok {
+		return
+	}
+
+	refund, err := h.refundService.Get(id)
+	if err != nil {
+		h.respondRefundError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refund": refund})
+}
+
+// ApproveRefund handles POST /api/v1/admin/payments/refunds/:id/approve
+// Sends the refund to PAYable; the response carries the outcome (completed or failed)
+func (h *RefundHandler) ApproveRefund(c *gin.Context) {
+	h.review(c, h.refundService.Approve, "Refund processed")
+}
+
+// RejectRefund handles POST /api/v1/admin/payments/refunds/:id/reject
+func (h *RefundHandler) RejectRefund(c *gin.Context) {
+	h.review(c, h.refundService.Reject, "Refund rejected")
+}
+
+func (h *RefundHandler) review(c *gin.Context, action func(uuid.UUID, uuid.UUID, *models.ReviewRefundRequest) (*models.Refund, error), message string) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User context not found"})
+		return
+	}
+	id, ok := refundIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReviewRefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+
+	refund, err := action(id, userCtx.UserID, &req)
+	if err != nil {
+		h.respondRefundError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message, "refund": refund})
+}
+
+// ResolveRefund handles PUT /api/v1/admin/payments/refunds/:id/status
+// Records the outcome of a refund stuck processing after the gateway's answer was lost
+func (h *RefundHandler) ResolveRefund(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User context not found"})
+		return
+	}
+	id, ok := refundIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.ResolveRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	refund, err := h.refundService.Resolve(id, userCtx.UserID, &req)
+	if err != nil {
+		h.respondRefundError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Refund updated", "refund": refund})
+}
+
+func refundIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_refund_id", "message": "Invalid refund ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *RefundHandler) respondRefundError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRefundNotFound), errors.Is(err, services.ErrRefundBookingNotFound),
+		errors.Is(err, services.ErrRefundSeatsNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrRefundNotAuthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden", "message": err.Error()})
+	case errors.Is(err, services.ErrRefundNotCancelled), errors.Is(err, services.ErrRefundNotPaid),
+		errors.Is(err, services.ErrRefundPaymentNotFound), errors.Is(err, services.ErrRefundNothingDue):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "not_refundable", "message": err.Error()})
+	case errors.Is(err, services.ErrRefundInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": "invalid_refund_state", "message": err.Error()})
+	case errors.Is(err, services.ErrRefundGatewayUnset), errors.Is(err, services.ErrRefundWalletUnset):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "payment_unavailable", "message": err.Error()})
+	default:
+		log.Printf("ERROR: Refund request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "refund_error", "message": "Failed to process refund"})
+	}
+}
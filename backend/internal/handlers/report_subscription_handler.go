@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// ReportSubscriptionHandler handles scheduled report email subscriptions for bus owners and admins
+type ReportSubscriptionHandler struct {
+	reportService *services.ReportSubscriptionService
+}
+
+// NewReportSubscriptionHandler creates a new ReportSubscriptionHandler
+func NewReportSubscriptionHandler(reportService *services.ReportSubscriptionService) *ReportSubscriptionHandler {
+	return &ReportSubscriptionHandler{
+		reportService: reportService,
+	}
+}
+
+// GetSubscriptions lists the caller's report subscriptions
+// GET /api/v1/bus-owner/report-subscriptions
+// GET /api/v1/admin/report-subscriptions
+func (h *ReportSubscriptionHandler) GetSubscriptions(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	subs, err := h.reportService.List(userCtx.UserID.String())
+	if err != nil {
+		log.Printf("ERROR: Failed to list report subscriptions for user %s: %v", userCtx.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "subscriptions_retrieval_failed", "message": "Failed to retrieve report subscriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subs})
+}
+
+// CreateOwnerSubscription subscribes a bus owner to a report on their own trips
+// POST /api/v1/bus-owner/report-subscriptions
+func (h *ReportSubscriptionHandler) CreateOwnerSubscription(c *gin.Context) {
+	h.create(c, models.ReportSubscriberBusOwner)
+}
+
+// CreateAdminSubscription subscribes an admin to a platform-wide report
+// POST /api/v1/admin/report-subscriptions
+func (h *ReportSubscriptionHandler) CreateAdminSubscription(c *gin.Context) {
+	h.create(c, models.ReportSubscriberAdmin)
+}
+
+func (h *ReportSubscriptionHandler) create(c *gin.Context, subscriberType models.ReportSubscriberType) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateReportSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	sub, err := h.reportService.Subscribe(userCtx.UserID.String(), subscriberType, &req)
+	if err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Subscribed to report", "subscription": sub})
+}
+
+// UpdateSubscription changes the delivery address or pauses/resumes a subscription
+// PATCH /api/v1/bus-owner/report-subscriptions/:id
+// PATCH /api/v1/admin/report-subscriptions/:id
+func (h *ReportSubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.UpdateReportSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	sub, err := h.reportService.Update(userCtx.UserID.String(), c.Param("id"), &req)
+	if err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report subscription updated", "subscription": sub})
+}
+
+// DeleteSubscription removes a subscription
+// DELETE /api/v1/bus-owner/report-subscriptions/:id
+// DELETE /api/v1/admin/report-subscriptions/:id
+func (h *ReportSubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	if err := h.reportService.Delete(userCtx.UserID.String(), c.Param("id")); err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report subscription removed"})
+}
+
+// Unsubscribe stops a subscription from the link in a report email (public, the token is the credential).
+// POST supports one-click unsubscribe from mail clients (RFC 8058).
+// GET/POST /api/v1/reports/unsubscribe/:token
+func (h *ReportSubscriptionHandler) Unsubscribe(c *gin.Context) {
+	sub, err := h.reportService.Unsubscribe(c.Param("token"))
+	if err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "You have been unsubscribed from this report",
+		"report_type": sub.ReportType,
+		"email":       sub.Email,
+	})
+}
+
+func (h *ReportSubscriptionHandler) respondReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrReportSubscriptionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrInvalidReportType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_report_type", "message": err.Error()})
+	case errors.Is(err, services.ErrTooManyReportSubscriptions):
+		c.JSON(http.StatusConflict, gin.H{"error": "too_many_subscriptions", "message": err.Error()})
+	case errors.Is(err, services.ErrDuplicateReportSubscription):
+		c.JSON(http.StatusConflict, gin.H{"error": "already_subscribed", "message": err.Error()})
+	case errors.Is(err, services.ErrReportOwnerProfileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "bus_owner_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrReportUnsubscribeLinkInvalid):
+		c.JSON(http.StatusNotFound, gin.H{"error": "unsubscribe_link_invalid", "message": err.Error()})
+	default:
+		log.Printf("ERROR: Report subscription request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Report subscription request failed"})
+	}
+}
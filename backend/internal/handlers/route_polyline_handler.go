@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// RoutePolylineHandler serves route road geometry for map rendering and lets admins and bus
+// owners upload or generate it
+type RoutePolylineHandler struct {
+	polylineService *services.RoutePolylineService
+	busOwnerRepo    *database.BusOwnerRepository
+	logger          *logrus.Logger
+}
+
+// NewRoutePolylineHandler creates a new RoutePolylineHandler
+func NewRoutePolylineHandler(
+	polylineService *services.RoutePolylineService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *RoutePolylineHandler {
+	return &RoutePolylineHandler{
+		polylineService: polylineService,
+		busOwnerRepo:    busOwnerRepo,
+		logger:          logger,
+	}
+}
+
+// ============================================================================
+// MASTER ROUTES
+// ============================================================================
+
+// GetMasterRoutePolyline returns a master route's polyline at each zoom level
+// GET /api/v1/master-routes/:id/polyline?zoom=low
+func (h *RoutePolylineHandler) GetMasterRoutePolyline(c *gin.Context) {
+	result, err := h.polylineService.GetMasterRoutePolyline(c.Param("id"))
+	if err != nil {
+		h.respondPolylineError(c, err)
+		return
+	}
+	h.respondPolyline(c, result)
+}
+
+// SetMasterRoutePolyline uploads a master route's polyline
+// PUT /api/v1/admin/master-routes/:id/polyline
+func (h *RoutePolylineHandler) SetMasterRoutePolyline(c *gin.Context) {
+	var req models.UpdateRoutePolylineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	result, err := h.polylineService.SetMasterRoutePolyline(c.Param("id"), &req)
+	if err != nil {
+		h.respondPolylineError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GenerateMasterRoutePolyline generates a master route's polyline from its stops with the routing engine
+// POST /api/v1/admin/master-routes/:id/polyline/generate
+func (h *RoutePolylineHandler) GenerateMasterRoutePolyline(c *gin.Context) {
+	result, err := h.polylineService.GenerateMasterRoutePolyline(c.Param("id"))
+	if err != nil {
+		h.respondPolylineError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ============================================================================
+// BUS OWNER ROUTES
+// ============================================================================
+
+// GetBusOwnerRoutePolyline returns an owner's route polyline, or its master route's
+// GET /api/v1/bus-owner-routes/:id/polyline?zoom=low
+func (h *RoutePolylineHandler) GetBusOwnerRoutePolyline(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.polylineService.GetBusOwnerRoutePolyline(c.Param("id"), busOwnerID)
+	if err != nil {
+		h.respondPolylineError(c, err)
+		return
+	}
+	h.respondPolyline(c, result)
+}
+
+// SetBusOwnerRoutePolyline uploads an owner's route polyline
+// PUT /api/v1/bus-owner-routes/:id/polyline
+func (h *RoutePolylineHandler) SetBusOwnerRoutePolyline(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRoutePolylineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	result, err := h.polylineService.SetBusOwnerRoutePolyline(c.Param("id"), busOwnerID, &req)
+	if err != nil {
+		h.respondPolylineError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GenerateBusOwnerRoutePolyline generates an owner's route polyline from its selected stops with the routing engine
+// POST /api/v1/bus-owner-routes/:id/polyline/generate
+func (h *RoutePolylineHandler) GenerateBusOwnerRoutePolyline(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.polylineService.GenerateBusOwnerRoutePolyline(c.Param("id"), busOwnerID)
+	if err != nil {
+		h.respondPolylineError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ClearBusOwnerRoutePolyline removes an owner's route polyline so its master route's is used
+// DELETE /api/v1/bus-owner-routes/:id/polyline
+func (h *RoutePolylineHandler) ClearBusOwnerRoutePolyline(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.polylineService.ClearBusOwnerRoutePolyline(c.Param("id"), busOwnerID); err != nil {
+		h.respondPolylineError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Route polyline removed; the master route polyline will be used"})
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+// respondPolyline writes the polyline, keeping only the level named by ?zoom when given
+func (h *RoutePolylineHandler) respondPolyline(c *gin.Context, result *models.RoutePolyline) {
+	zoom := c.Query("zoom")
+	if zoom == "" {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	for _, level := range result.Levels {
+		if level.Zoom == zoom {
+			result.Levels = []models.RoutePolylineLevel{level}
+			c.JSON(http.StatusOK, result)
+			return
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "zoom must be one of full, medium, low"})
+}
+
+func (h *RoutePolylineHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+func (h *RoutePolylineHandler) respondPolylineError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrPolylineRouteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "route_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrPolylineNotSet):
+		c.JSON(http.StatusNotFound, gin.H{"error": "polyline_not_set", "message": err.Error()})
+	case errors.Is(err, services.ErrPolylineStopsMissingCoords):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "stops_missing_coordinates", "message": err.Error()})
+	case errors.Is(err, services.ErrPolylineNoRoadRoute):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "no_road_route", "message": err.Error()})
+	case errors.Is(err, services.ErrPolylineRoutingFailed):
+		h.logger.WithError(err).Warn("Route polyline generation failed")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "routing_failed", "message": "The routing engine is unavailable, try again later"})
+	case errors.Is(err, services.ErrPolylineGenerationDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "generation_disabled", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Route polyline request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Route polyline request failed"})
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// RouteSegmentHandler serves routing engine distances and durations between route stops and
+// the fare matrices priced from them
+type RouteSegmentHandler struct {
+	segmentService *services.RouteSegmentService
+	logger         *logrus.Logger
+}
+
+// NewRouteSegmentHandler creates a new RouteSegmentHandler
+func NewRouteSegmentHandler(segmentService *services.RouteSegmentService, logger *logrus.Logger) *RouteSegmentHandler {
+	return &RouteSegmentHandler{
+		segmentService: segmentService,
+		logger:         logger,
+	}
+}
+
+// GetSegments returns a master route's computed stop-to-stop distances and durations
+// GET /api/v1/master-routes/:id/segments
+func (h *RouteSegmentHandler) GetSegments(c *gin.Context) {
+	result, err := h.segmentService.GetSegments(c.Param("id"))
+	if err != nil {
+		h.respondSegmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ComputeSegments computes a master route's segments with the routing engine
+// POST /api/v1/admin/master-routes/:id/segments/compute
+func (h *RouteSegmentHandler) ComputeSegments(c *gin.Context) {
+	var req models.ComputeRouteSegmentsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+
+	result, err := h.segmentService.ComputeSegments(c.Param("id"), &req)
+	if err != nil {
+		h.respondSegmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetFareMatrix prices every stop-to-stop journey on a master route from its segment distances
+// GET /api/v1/master-routes/:id/fare-matrix?per_km_rate=2.5&base_fare=30&minimum_fare=40&round_to=10
+func (h *RouteSegmentHandler) GetFareMatrix(c *gin.Context) {
+	var params models.FareMatrixParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	matrix, err := h.segmentService.GetFareMatrix(c.Param("id"), &params)
+	if err != nil {
+		h.respondSegmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, matrix)
+}
+
+func (h *RouteSegmentHandler) respondSegmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSegmentsRouteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "route_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrSegmentsNotComputed):
+		c.JSON(http.StatusNotFound, gin.H{"error": "segments_not_computed", "message": err.Error()})
+	case errors.Is(err, services.ErrSegmentsStopsMissingCoords):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "stops_missing_coordinates", "message": err.Error()})
+	case errors.Is(err, services.ErrSegmentsEndpointsUnlocated):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "endpoints_missing_coordinates", "message": err.Error()})
+	case errors.Is(err, services.ErrSegmentsNoRoadRoute):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "no_road_route", "message": err.Error()})
+	case errors.Is(err, services.ErrSegmentsRoutingFailed):
+		h.logger.WithError(err).Warn("Route segment computation failed")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "routing_failed", "message": "The routing engine is unavailable, try again later"})
+	case errors.Is(err, services.ErrSegmentsRoutingDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "routing_disabled", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Route segment request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Route segment request failed"})
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
 )
 
 type ScheduledTripHandler struct {
@@ -23,6 +25,12 @@ type ScheduledTripHandler struct {
 	staffRepo    *database.BusStaffRepository
 	settingRepo  *database.SystemSettingRepository
 	tripSeatRepo *database.TripSeatRepository
+
+	fatigueService *services.DriverFatigueService
+	fareCompliance *services.FareComplianceService
+	seatCapacity   *services.SeatCapacityService
+	searchCache    *services.SearchCache
+	seatPricing    *services.SeatPricingService
 }
 
 func NewScheduledTripHandler(
@@ -35,6 +43,11 @@ func NewScheduledTripHandler(
 	staffRepo *database.BusStaffRepository,
 	settingRepo *database.SystemSettingRepository,
 	tripSeatRepo *database.TripSeatRepository,
+	fatigueService *services.DriverFatigueService,
+	fareCompliance *services.FareComplianceService,
+	seatCapacity *services.SeatCapacityService,
+	searchCache *services.SearchCache,
+	seatPricing *services.SeatPricingService,
 ) *ScheduledTripHandler {
 	return &ScheduledTripHandler{
 		tripRepo:     tripRepo,
@@ -46,7 +59,85 @@ func NewScheduledTripHandler(
 		staffRepo:    staffRepo,
 		settingRepo:  settingRepo,
 		tripSeatRepo: tripSeatRepo,
+
+		fatigueService: fatigueService,
+		fareCompliance: fareCompliance,
+		seatCapacity:   seatCapacity,
+		searchCache:    searchCache,
+		seatPricing:    seatPricing,
+	}
+}
+
+// invalidateSearches drops cached trip searches on the corridors of trips that were just
+// published, unpublished or cancelled
+func (h *ScheduledTripHandler) invalidateSearches(tripIDs ...string) {
+	if h.searchCache == nil {
+		return
+	}
+	routeIDs, err := h.tripRepo.GetMasterRouteIDs(tripIDs)
+	if err != nil {
+		log.Printf("Search cache: failed to find routes of trips %v: %v", tripIDs, err)
+		return
+	}
+	if dropped := h.searchCache.InvalidateRoutes(routeIDs...); dropped > 0 {
+		log.Printf("Search cache: dropped %d cached searches on routes %v", dropped, routeIDs)
+	}
+}
+
+// checkFareCompliance responds 409 if any of the trips is priced above its permit's approved
+// fare while enforcement is on. Returns true if the caller should return.
+func (h *ScheduledTripHandler) checkFareCompliance(c *gin.Context, tripIDs []string) bool {
+	if h.fareCompliance == nil {
+		return false
+	}
+	err := h.fareCompliance.CheckPublish(tripIDs)
+	if err == nil {
+		return false
+	}
+	var complianceErr *models.FareComplianceError
+	if errors.As(err, &complianceErr) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Fare exceeds permit approved fare",
+			"code":    "FARE_ABOVE_PERMIT",
+			"message": complianceErr.Message,
+			"trips":   complianceErr.Trips,
+		})
+		return true
+	}
+	log.Printf("Fare compliance check failed: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check trip fares"})
+	return true
+}
+
+// checkSeatCapacity responds 409 if any of the trips' seat layout (or layoutID, when one is being
+// assigned) has more seats than the bus is registered for or the permit approves. Returns true
+// if the caller should return.
+func (h *ScheduledTripHandler) checkSeatCapacity(c *gin.Context, tripIDs []string, layoutID *string) bool {
+	if h.seatCapacity == nil {
+		return false
+	}
+	return respondSeatCapacity(c, h.seatCapacity.CheckTrips(tripIDs, layoutID))
+}
+
+// respondSeatCapacity writes the response for a failed seat capacity check. Returns true if a
+// response was written.
+func respondSeatCapacity(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	var capacityErr *models.SeatCapacityError
+	if errors.As(err, &capacityErr) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Seat layout exceeds capacity",
+			"code":    "SEAT_LAYOUT_OVER_CAPACITY",
+			"message": capacityErr.Message,
+			"details": capacityErr.Checks,
+		})
+		return true
 	}
+	log.Printf("Seat capacity check failed: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check seat capacity"})
+	return true
 }
 
 // checkBusOwnerVerified checks if the bus owner is verified and returns 403 if not.
@@ -388,6 +479,9 @@ func (h *ScheduledTripHandler) UpdateTrip(c *gin.Context) {
 
 		// Get new route being proposed
 		newRoute, err := h.routeRepo.GetByID(*req.BusOwnerRouteID)
+		if err == nil && newRoute.DeletedAt != nil {
+			err = sql.ErrNoRows // Deleted routes cannot take new trips
+		}
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "New route not found"})
 			return
@@ -529,12 +623,13 @@ func (h *ScheduledTripHandler) CancelTrip(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel trip"})
 		return
 	}
+	h.invalidateSearches(tripID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Trip cancelled successfully"})
 }
 
 // GetBookableTrips retrieves bookable trips (public endpoint for passengers)
-// GET /api/v1/bookable-trips?start_date=2024-01-01&end_date=2024-01-31
+// GET /api/v1/bookable-trips?start_date=2024-01-01&end_date=2024-01-31&fields=id,departure_datetime,base_fare
 func (h *ScheduledTripHandler) GetBookableTrips(c *gin.Context) {
 	// Parse query parameters
 	startDateStr := c.Query("start_date")
@@ -557,13 +652,25 @@ func (h *ScheduledTripHandler) GetBookableTrips(c *gin.Context) {
 		return
 	}
 
+	// Optional trimming of each trip to the attributes a list view renders
+	fields, err := models.ParseFieldSelection(c.Query("fields"), models.BookableTripFields, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	trips, err := h.tripRepo.GetBookableTrips(startDate, endDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trips"})
 		return
 	}
 
-	c.JSON(http.StatusOK, trips)
+	selected, err := fields.Select(trips)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trips"})
+		return
+	}
+	c.JSON(http.StatusOK, selected)
 }
 
 // CreateSpecialTrip creates a special one-time trip (not from timetable)
@@ -604,6 +711,9 @@ func (h *ScheduledTripHandler) CreateSpecialTrip(c *gin.Context) {
 
 	// Verify custom route ownership
 	customRoute, err := h.routeRepo.GetByID(req.CustomRouteID)
+	if err == nil && customRoute.DeletedAt != nil {
+		err = sql.ErrNoRows // Deleted routes cannot take new trips
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Custom route not found"})
@@ -621,6 +731,9 @@ func (h *ScheduledTripHandler) CreateSpecialTrip(c *gin.Context) {
 	// Verify permit ownership (optional)
 	if req.PermitID != nil {
 		permit, err := h.permitRepo.GetByID(*req.PermitID)
+		if err == nil && permit.DeletedAt != nil {
+			err = sql.ErrNoRows // Deleted permits cannot take new trips
+		}
 		if err != nil {
 			if err == sql.ErrNoRows {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Permit not found"})
@@ -710,6 +823,9 @@ func (h *ScheduledTripHandler) CreateSpecialTrip(c *gin.Context) {
 
 		// Verify bus ownership
 		bus, err := h.busRepo.GetByID(*req.BusID)
+		if err == nil && bus.DeletedAt != nil {
+			err = sql.ErrNoRows // Deleted buss cannot take new trips
+		}
 		if err != nil {
 			if err == sql.ErrNoRows {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Bus not found"})
@@ -829,6 +945,19 @@ func (h *ScheduledTripHandler) PublishTrip(c *gin.Context) {
 			return
 		}
 		log.Printf("PublishTrip: Auto-created %d seats for trip %s", seatsCreated, tripID)
+		if err := h.seatPricing.RepriceTrip(tripID); err != nil {
+			log.Printf("PublishTrip: Failed to apply pricing rules to trip %s: %v", tripID, err)
+		}
+	}
+
+	// Check fares against the permit's approved fare (blocks only when enforcement is on)
+	if h.checkFareCompliance(c, []string{tripID}) {
+		return
+	}
+
+	// Check the seat layout against the bus's registered and the permit's approved capacity
+	if h.checkSeatCapacity(c, []string{tripID}, nil) {
+		return
 	}
 
 	// Publish the trip
@@ -847,6 +976,7 @@ func (h *ScheduledTripHandler) PublishTrip(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish trip for booking", "details": err.Error()})
 		return
 	}
+	h.invalidateSearches(tripID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Trip published for booking successfully",
@@ -894,6 +1024,7 @@ func (h *ScheduledTripHandler) UnpublishTrip(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove trip from booking"})
 		return
 	}
+	h.invalidateSearches(tripID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Trip removed from booking successfully",
@@ -951,6 +1082,16 @@ func (h *ScheduledTripHandler) BulkPublishTrips(c *gin.Context) {
 	log.Printf("Bulk publish: Bus owner found - ID: %s, User ID: %s",
 		busOwner.ID, userCtx.UserID.String())
 
+	// Check fares against the permits' approved fares (blocks only when enforcement is on)
+	if h.checkFareCompliance(c, req.TripIDs) {
+		return
+	}
+
+	// Check the seat layouts against the buses' registered and the permits' approved capacity
+	if h.checkSeatCapacity(c, req.TripIDs, nil) {
+		return
+	}
+
 	// Bulk publish trips
 	publishedCount, err := h.tripRepo.BulkPublishTrips(req.TripIDs, busOwner.ID)
 	if err != nil {
@@ -974,6 +1115,7 @@ func (h *ScheduledTripHandler) BulkPublishTrips(c *gin.Context) {
 
 	log.Printf("Bulk publish: Success - Published %d/%d trips for booking",
 		publishedCount, len(req.TripIDs))
+	h.invalidateSearches(req.TripIDs...)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":         "Trips published for booking successfully",
@@ -1043,6 +1185,7 @@ func (h *ScheduledTripHandler) BulkUnpublishTrips(c *gin.Context) {
 
 	log.Printf("Bulk unpublish: Success - Unpublished %d/%d trips from booking",
 		unpublishedCount, len(req.TripIDs))
+	h.invalidateSearches(req.TripIDs...)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":           "Trips removed from booking successfully",
@@ -1149,6 +1292,10 @@ func (h *ScheduledTripHandler) AssignStaffAndPermit(c *gin.Context) {
 		DriverID    *string `json:"driver_id"`
 		ConductorID *string `json:"conductor_id"`
 		PermitID    *string `json:"permit_id"`
+
+		// Assign the driver despite hours-of-service violations; the justification is audited
+		OverrideFatigue       bool   `json:"override_fatigue"`
+		OverrideJustification string `json:"override_justification"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1163,6 +1310,7 @@ func (h *ScheduledTripHandler) AssignStaffAndPermit(c *gin.Context) {
 	}
 
 	// Validate driver if provided
+	var fatigueOverride *services.DriverFatigueOverride
 	if req.DriverID != nil && *req.DriverID != "" {
 		staff, err := h.staffRepo.GetByID(*req.DriverID)
 		if err != nil {
@@ -1199,6 +1347,39 @@ func (h *ScheduledTripHandler) AssignStaffAndPermit(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Driver's license will be expired on trip date"})
 			return
 		}
+
+		// Check hours-of-service rules against the driver's other trips
+		if h.fatigueService != nil {
+			violations, err := h.fatigueService.CheckAssignment(*req.DriverID, trip)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check driver hours of service"})
+				return
+			}
+			if len(violations) > 0 {
+				if !req.OverrideFatigue {
+					c.JSON(http.StatusConflict, gin.H{
+						"error":      "Driver assignment breaks hours-of-service rules",
+						"code":       "DRIVER_FATIGUE_VIOLATION",
+						"violations": violations,
+						"message":    "Set override_fatigue with an override_justification to assign the driver anyway",
+					})
+					return
+				}
+				fatigueOverride = &services.DriverFatigueOverride{
+					UserID:        userCtx.UserID,
+					TripID:        tripID,
+					DriverID:      *req.DriverID,
+					Justification: req.OverrideJustification,
+					Violations:    violations,
+					IPAddress:     c.ClientIP(),
+					UserAgent:     c.Request.UserAgent(),
+				}
+				if err := h.fatigueService.ValidateOverride(fatigueOverride); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "violations": violations})
+					return
+				}
+			}
+		}
 	}
 
 	// Validate conductor if provided
@@ -1236,6 +1417,9 @@ func (h *ScheduledTripHandler) AssignStaffAndPermit(c *gin.Context) {
 	// Validate permit if provided
 	if req.PermitID != nil && *req.PermitID != "" {
 		permit, err := h.permitRepo.GetByID(*req.PermitID)
+		if err == nil && permit.DeletedAt != nil {
+			err = sql.ErrNoRows // Deleted permits cannot take new trips
+		}
 		if err != nil {
 			if err == sql.ErrNoRows {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Permit not found"})
@@ -1306,6 +1490,9 @@ func (h *ScheduledTripHandler) AssignStaffAndPermit(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign staff and permit", "details": err.Error()})
 		return
 	}
+	if fatigueOverride != nil {
+		h.fatigueService.RecordOverride(fatigueOverride)
+	}
 
 	// Fetch updated trip
 	updatedTrip, err := h.tripRepo.GetByID(tripID)
@@ -1427,6 +1614,11 @@ func (h *ScheduledTripHandler) AssignSeatLayout(c *gin.Context) {
 	// For now, we'll proceed with the assignment
 	// TODO: Add seat layout ownership verification
 
+	// The layout must fit the trip's bus and permit
+	if h.checkSeatCapacity(c, []string{tripID}, req.SeatLayoutID) {
+		return
+	}
+
 	// Perform the assignment
 	err = h.tripRepo.AssignSeatLayout(tripID, req.SeatLayoutID)
 	if err != nil {
@@ -1450,6 +1642,9 @@ func (h *ScheduledTripHandler) AssignSeatLayout(c *gin.Context) {
 			return
 		}
 		fmt.Printf("✅ Created %d trip seats from layout %s\n", seatsCreated, *req.SeatLayoutID)
+		if err := h.seatPricing.RepriceTrip(tripID); err != nil {
+			fmt.Printf("⚠️ Failed to apply pricing rules to trip %s: %v\n", tripID, err)
+		}
 	}
 
 	// Verify seats were actually created
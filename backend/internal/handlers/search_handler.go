@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
 	"github.com/smarttransit/sms-auth-backend/internal/services"
 )
@@ -33,6 +34,7 @@ func NewSearchHandler(service *services.SearchService, logger *logrus.Logger) *S
 // @Accept json
 // @Produce json
 // @Param search body models.SearchRequest true "Search parameters"
+// @Param fields query string false "Comma-separated trip attributes to return (trip_id is always included)"
 // @Success 200 {object} models.SearchResponse
 // @Failure 400 {object} map[string]interface{} "Invalid request"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -64,6 +66,16 @@ func (h *SearchHandler) SearchTrips(c *gin.Context) {
 		return
 	}
 
+	// Optional trimming of each result to the attributes a list view renders
+	fields, err := models.ParseFieldSelection(c.Query("fields"), models.SearchResultFields, "trip_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
 	// Log search parameters
 	h.logger.WithFields(logrus.Fields{
 		"from":     req.From,
@@ -87,7 +99,7 @@ func (h *SearchHandler) SearchTrips(c *gin.Context) {
 
 	// Perform search
 	h.logger.Info("Calling search service...")
-	response, err := h.service.SearchTrips(&req, userID, ipAddress)
+	response, err := h.service.SearchTrips(&req, userID, ipAddress, middleware.GetTenant(c))
 	if err != nil {
 		// Check if it's a validation error
 		if _, ok := err.(*models.ValidationError); ok {
@@ -118,6 +130,26 @@ func (h *SearchHandler) SearchTrips(c *gin.Context) {
 
 	h.logger.Info("=== SEARCH REQUEST COMPLETED ===")
 
+	if fields != nil {
+		results, err := fields.Select(response.Results)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to select search result fields")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to search for trips. Please try again later.",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status":         response.Status,
+			"message":        response.Message,
+			"search_details": response.SearchDetails,
+			"results":        results,
+			"search_time_ms": response.SearchTimeMs,
+		})
+		return
+	}
+
 	// Return successful response
 	c.JSON(http.StatusOK, response)
 }
@@ -244,6 +276,21 @@ func (h *SearchHandler) GetSearchAnalytics(c *gin.Context) {
 	})
 }
 
+// GetCacheStats handles GET /api/v1/admin/search/cache
+// @Summary Get search cache statistics
+// @Description Hit rate, size and invalidations of the trip search result cache (requires admin auth)
+// @Tags Admin, Search
+// @Produce json
+// @Success 200 {object} models.SearchCacheStats
+// @Security Bearer
+// @Router /api/v1/admin/search/cache [get]
+func (h *SearchHandler) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"cache":  h.service.CacheStats(),
+	})
+}
+
 // HealthCheck handles GET /api/v1/search/health
 // @Summary Search service health check
 // @Description Check if search service is healthy and database is accessible
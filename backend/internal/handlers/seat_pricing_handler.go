@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// SeatPricingHandler handles bus owners' dynamic seat pricing rules
+type SeatPricingHandler struct {
+	pricingService *services.SeatPricingService
+	busOwnerRepo   *database.BusOwnerRepository
+	logger         *logrus.Logger
+}
+
+// NewSeatPricingHandler creates a new SeatPricingHandler
+func NewSeatPricingHandler(
+	pricingService *services.SeatPricingService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *SeatPricingHandler {
+	return &SeatPricingHandler{
+		pricingService: pricingService,
+		busOwnerRepo:   busOwnerRepo,
+		logger:         logger,
+	}
+}
+
+// GetRules lists the owner's pricing rules
+// GET /api/v1/bus-owner/pricing-rules?bus_owner_route_id=...&trip_schedule_id=...
+func (h *SeatPricingHandler) GetRules(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+	routeID, scheduleID := c.Query("bus_owner_route_id"), c.Query("trip_schedule_id")
+
+	rules, err := h.pricingService.ListRules(busOwnerID, &routeID, &scheduleID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules, "total": len(rules)})
+}
+
+// CreateRule adds a pricing rule to one of the owner's routes or schedules
+// POST /api/v1/bus-owner/pricing-rules
+func (h *SeatPricingHandler) CreateRule(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateSeatPricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	rule, err := h.pricingService.CreateRule(busOwnerID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Pricing rule created. Available seats on upcoming trips are repriced.", "rule": rule})
+}
+
+// UpdateRule replaces one of the owner's pricing rules
+// PUT /api/v1/bus-owner/pricing-rules/:id
+func (h *SeatPricingHandler) UpdateRule(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SeatPricingRuleFields
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	rule, err := h.pricingService.UpdateRule(busOwnerID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pricing rule updated", "rule": rule})
+}
+
+// DeleteRule removes one of the owner's pricing rules
+// DELETE /api/v1/bus-owner/pricing-rules/:id
+func (h *SeatPricingHandler) DeleteRule(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.pricingService.DeleteRule(busOwnerID, c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pricing rule removed"})
+}
+
+// PreviewTrip shows how the rules price one of the owner's trips right now
+// GET /api/v1/scheduled-trips/:id/seats/pricing
+func (h *SeatPricingHandler) PreviewTrip(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	pricing, err := h.pricingService.PreviewTrip(busOwnerID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pricing": pricing})
+}
+
+func (h *SeatPricingHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+func (h *SeatPricingHandler) respondError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrPricingRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "rule_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrPricingRuleTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "target_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrPricingTripNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "trip_not_found", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Seat pricing request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Seat pricing request failed"})
+	}
+}
@@ -2,21 +2,44 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
 )
 
 // StaffBookingHandler handles conductor/driver booking operations
 type StaffBookingHandler struct {
-	bookingRepo *database.AppBookingRepository
+	bookingRepo     *database.AppBookingRepository
+	boardingService *services.TripBoardingWindowService
+	contactService  *services.PassengerContactService
+	seatSwapService *services.SeatSwapService
+	qrService       *services.BookingQRService
+	etaService      *services.TripETAService
 }
 
 // NewStaffBookingHandler creates a new StaffBookingHandler
-func NewStaffBookingHandler(bookingRepo *database.AppBookingRepository) *StaffBookingHandler {
-	return &StaffBookingHandler{bookingRepo: bookingRepo}
+func NewStaffBookingHandler(
+	bookingRepo *database.AppBookingRepository,
+	boardingService *services.TripBoardingWindowService,
+	contactService *services.PassengerContactService,
+	seatSwapService *services.SeatSwapService,
+	qrService *services.BookingQRService,
+	etaService *services.TripETAService,
+) *StaffBookingHandler {
+	return &StaffBookingHandler{
+		bookingRepo:     bookingRepo,
+		boardingService: boardingService,
+		contactService:  contactService,
+		seatSwapService: seatSwapService,
+		qrService:       qrService,
+		etaService:      etaService,
+	}
 }
 
 // VerifyBookingRequest represents a request to verify a booking by QR
@@ -26,7 +49,9 @@ type VerifyBookingRequest struct {
 
 // VerifyBookingByQR verifies a booking by scanning QR code
 // @Summary Verify booking by QR
-// @Description Conductor/Driver scans QR to verify booking
+// @Description Conductor/Driver scans QR to verify booking. Signed QRs have their signature,
+// @Description key and expiry checked; unsigned QR codes are rejected when signatures are required.
+// @Description A seat's ticket verifies only that seat, and not once the seat is cancelled.
 // @Tags Staff Bookings
 // @Accept json
 // @Produce json
@@ -52,7 +77,30 @@ func (h *StaffBookingHandler) VerifyBookingByQR(c *gin.Context) {
 		return
 	}
 
-	busBooking, err := h.bookingRepo.GetBusBookingByQRCode(req.QRCode)
+	qrCode := req.QRCode
+	var signed *models.BookingQRPayload
+	if models.IsSignedBookingQR(req.QRCode) {
+		payload, err := h.qrService.Verify(req.QRCode)
+		if err != nil {
+			if services.IsBookingQRRejected(err) {
+				c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": "Invalid QR", "details": err.Error()})
+				return
+			}
+			log.Printf("Failed to verify boarding QR signature: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify booking"})
+			return
+		}
+		qrCode, signed = payload.QRCode, payload
+	} else if h.qrService.SignatureRequired() {
+		c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": "Invalid QR", "details": services.ErrBookingQRUnsigned.Error()})
+		return
+	}
+
+	lookup := h.bookingRepo.GetBusBookingByQRCode
+	if models.IsSeatTicketCode(qrCode) {
+		lookup = h.bookingRepo.GetBusBookingBySeatTicket
+	}
+	busBooking, err := lookup(qrCode)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
@@ -61,6 +109,35 @@ func (h *StaffBookingHandler) VerifyBookingByQR(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify booking"})
 		return
 	}
+	if signed != nil && signed.BusBookingID != busBooking.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": "Invalid QR", "details": services.ErrBookingQRSignatureInvalid.Error()})
+		return
+	}
+
+	// A seat ticket admits its own passenger only
+	var ticketSeatID *string
+	if models.IsSeatTicketCode(qrCode) {
+		var seat *models.BusBookingSeat
+		for i := range busBooking.Seats {
+			if code := busBooking.Seats[i].TicketCode; code != nil && *code == qrCode {
+				seat = &busBooking.Seats[i]
+			}
+		}
+		if seat == nil || (signed != nil && signed.SeatID != seat.ID) {
+			c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": "Invalid QR", "details": services.ErrBookingQRSignatureInvalid.Error()})
+			return
+		}
+		if seat.Status == models.SeatBookingCancelled {
+			c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": "Ticket cancelled", "details": "this seat has been cancelled"})
+			return
+		}
+		busBooking.Seats = []models.BusBookingSeat{*seat}
+		busBooking.NumberOfSeats = 1
+		ticketSeatID = &seat.ID
+	}
+	verified := []models.BusBooking{*busBooking}
+	h.contactService.MaskBookings(verified)
+	busBooking = &verified[0]
 
 	c.JSON(http.StatusOK, gin.H{
 		"valid":              true,
@@ -74,6 +151,9 @@ func (h *StaffBookingHandler) VerifyBookingByQR(c *gin.Context) {
 		"is_checked_in":      busBooking.CheckedInAt != nil,
 		"check_in_time":      busBooking.CheckedInAt,
 		"seats":              busBooking.Seats,
+		"contact_policy":     h.contactService.Policy(),
+		"signature_verified": signed != nil,
+		"ticket_seat_id":     ticketSeatID, // Set when a seat's ticket was scanned; check in that seat
 	})
 }
 
@@ -82,11 +162,15 @@ type CheckInRequest struct {
 	BusBookingID string `json:"bus_booking_id" binding:"required"`
 	// Optional: specific seat to check in
 	SeatID string `json:"seat_id,omitempty"`
+	// Bus owner only: check in outside the trip's boarding window
+	Override       bool   `json:"override,omitempty"`
+	OverrideReason string `json:"override_reason,omitempty"`
 }
 
 // CheckInPassenger marks passenger as checked-in
 // @Summary Check in passenger
-// @Description Conductor marks passenger as checked-in (verified ticket)
+// @Description Conductor marks passenger as checked-in (verified ticket). Only allowed within
+// @Description the trip's boarding window unless the bus owner overrides it.
 // @Tags Staff Bookings
 // @Accept json
 // @Produce json
@@ -95,6 +179,8 @@ type CheckInRequest struct {
 // @Failure 400 {object} map[string]interface{} "Invalid request"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 403 {object} map[string]interface{} "Override not allowed"
+// @Failure 409 {object} map[string]interface{} "Outside boarding window"
 // @Security BearerAuth
 // @Router /api/v1/staff/bookings/check-in [post]
 func (h *StaffBookingHandler) CheckInPassenger(c *gin.Context) {
@@ -110,8 +196,21 @@ func (h *StaffBookingHandler) CheckInPassenger(c *gin.Context) {
 		return
 	}
 
+	attempt := &services.BoardingAttempt{
+		Action:         services.BoardingActionCheckIn,
+		UserID:         userCtx.UserID,
+		Override:       req.Override,
+		OverrideReason: req.OverrideReason,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+	}
+
 	// If specific seat, check in that seat
 	if req.SeatID != "" {
+		if err := h.boardingService.AuthorizeSeat(req.SeatID, attempt); err != nil {
+			h.respondBoardingError(c, err)
+			return
+		}
 		err := h.bookingRepo.CheckInPassenger(req.SeatID, userCtx.UserID.String())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in", "details": err.Error()})
@@ -125,6 +224,10 @@ func (h *StaffBookingHandler) CheckInPassenger(c *gin.Context) {
 	}
 
 	// Otherwise check in the whole bus booking
+	if err := h.boardingService.AuthorizeBusBooking(req.BusBookingID, attempt); err != nil {
+		h.respondBoardingError(c, err)
+		return
+	}
 	err := h.bookingRepo.CheckInBusBooking(req.BusBookingID, userCtx.UserID.String())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in", "details": err.Error()})
@@ -140,11 +243,15 @@ func (h *StaffBookingHandler) CheckInPassenger(c *gin.Context) {
 // BoardRequest represents a boarding request
 type BoardRequest struct {
 	SeatID string `json:"seat_id" binding:"required"`
+	// Bus owner only: board outside the trip's boarding window
+	Override       bool   `json:"override,omitempty"`
+	OverrideReason string `json:"override_reason,omitempty"`
 }
 
 // BoardPassenger marks passenger as boarded
 // @Summary Board passenger
-// @Description Conductor marks passenger as boarded (on the bus)
+// @Description Conductor marks passenger as boarded (on the bus). Only allowed within the
+// @Description trip's boarding window unless the bus owner overrides it.
 // @Tags Staff Bookings
 // @Accept json
 // @Produce json
@@ -153,6 +260,8 @@ type BoardRequest struct {
 // @Failure 400 {object} map[string]interface{} "Invalid request"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 403 {object} map[string]interface{} "Override not allowed"
+// @Failure 409 {object} map[string]interface{} "Outside boarding window"
 // @Security BearerAuth
 // @Router /api/v1/staff/bookings/board [post]
 func (h *StaffBookingHandler) BoardPassenger(c *gin.Context) {
@@ -168,6 +277,19 @@ func (h *StaffBookingHandler) BoardPassenger(c *gin.Context) {
 		return
 	}
 
+	attempt := &services.BoardingAttempt{
+		Action:         services.BoardingActionBoard,
+		UserID:         userCtx.UserID,
+		Override:       req.Override,
+		OverrideReason: req.OverrideReason,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+	}
+	if err := h.boardingService.AuthorizeSeat(req.SeatID, attempt); err != nil {
+		h.respondBoardingError(c, err)
+		return
+	}
+
 	err := h.bookingRepo.BoardPassenger(req.SeatID, userCtx.UserID.String())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to board passenger", "details": err.Error()})
@@ -225,15 +347,17 @@ func (h *StaffBookingHandler) MarkNoShow(c *gin.Context) {
 
 // GetTripBookings gets all bookings for a trip
 // @Summary Get trip bookings
-// @Description Get all bookings for a scheduled trip (for staff)
+// @Description Get all bookings for a scheduled trip (for staff). Passenger phone numbers are
+// @Description masked unless masking is switched off; see contact_policy for how to reach them.
+// @Description Once the trip is running, each booking has the bus's ETA at its boarding stop.
 // @Tags Staff Bookings
 // @Produce json
-// @Param trip_id path string true "Scheduled Trip ID"
+// @Param id path string true "Scheduled Trip ID"
 // @Success 200 {array} models.BusBooking "List of bookings"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Security BearerAuth
-// @Router /api/v1/staff/trips/{trip_id}/bookings [get]
+// @Router /api/v1/staff/trips/{id}/bookings [get]
 func (h *StaffBookingHandler) GetTripBookings(c *gin.Context) {
 	_, exists := middleware.GetUserContext(c)
 	if !exists {
@@ -241,7 +365,7 @@ func (h *StaffBookingHandler) GetTripBookings(c *gin.Context) {
 		return
 	}
 
-	tripID := c.Param("trip_id")
+	tripID := c.Param("id")
 	if tripID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Trip ID is required"})
 		return
@@ -252,6 +376,24 @@ func (h *StaffBookingHandler) GetTripBookings(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bookings"})
 		return
 	}
+	for i := range bookings {
+		seats, err := h.bookingRepo.GetSeatsByBusBookingID(bookings[i].ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bookings"})
+			return
+		}
+		bookings[i].Seats = seats
+	}
+	h.contactService.MaskBookings(bookings)
+
+	// ETAs are best effort: the list is still useful before the trip starts or without them
+	if eta, err := h.etaService.GetETAForScheduledTrip(tripID); err == nil {
+		for i := range bookings {
+			if bookings[i].BoardingStopID != nil {
+				bookings[i].BoardingETA = eta.Stop(*bookings[i].BoardingStopID)
+			}
+		}
+	}
 
 	// Calculate stats (boarding is now tracked at bus_bookings level, not seat level)
 	var totalBooked, checkedIn, boarded, noShow int
@@ -271,11 +413,152 @@ func (h *StaffBookingHandler) GetTripBookings(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"bookings":      bookings,
-		"total_booked":  totalBooked,
-		"checked_in":    checkedIn,
-		"boarded":       boarded,
-		"no_show":       noShow,
-		"booking_count": len(bookings),
+		"bookings":       bookings,
+		"total_booked":   totalBooked,
+		"checked_in":     checkedIn,
+		"boarded":        boarded,
+		"no_show":        noShow,
+		"booking_count":  len(bookings),
+		"contact_policy": h.contactService.Policy(),
+	})
+}
+
+// ContactPassenger reveals a masked passenger's phone number, or returns the call relay for it
+// @Summary Contact a passenger
+// @Description The trip's driver, conductor or bus owner gets the passenger's phone number
+// @Description (reveal mode) or the relay number and booking reference to quote (relay mode).
+// @Description Every request is audited and capped per trip.
+// @Tags Staff Bookings
+// @Accept json
+// @Produce json
+// @Param seat_id path string true "Bus booking seat ID"
+// @Param request body models.PassengerContactRequest true "Why the passenger is being contacted"
+// @Success 200 {object} models.PassengerContact
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the trip's crew or owner, or contact disabled"
+// @Failure 404 {object} map[string]interface{} "Booking not found"
+// @Failure 429 {object} map[string]interface{} "Contact limit reached for this trip"
+// @Security BearerAuth
+// @Router /api/v1/staff/bookings/seats/{seat_id}/contact [post]
+func (h *StaffBookingHandler) ContactPassenger(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.PassengerContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	contact, err := h.contactService.RequestContact(c.Param("seat_id"), &services.PassengerContactAttempt{
+		UserID:    userCtx.UserID,
+		Reason:    req.Reason,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
 	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPassengerContactNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		case errors.Is(err, services.ErrPassengerContactDenied), errors.Is(err, services.ErrPassengerContactDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrPassengerContactLimitReached):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		default:
+			log.Printf("ERROR: Passenger contact request failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to contact passenger"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// SwapSeat moves a booked passenger to another free seat on the trip
+// @Summary Swap a passenger's seat
+// @Description The trip's driver, conductor or bus owner resolves an onboard seat conflict by
+// @Description moving a booked passenger to another free seat. The old seat is freed, the
+// @Description booking's seat is updated and the swap is audited with the note, all at once.
+// @Description The fare is not changed.
+// @Tags Staff Bookings
+// @Accept json
+// @Produce json
+// @Param id path string true "Scheduled trip ID"
+// @Param request body models.SeatSwapRequest true "Seat booking, new seat and reason"
+// @Success 200 {object} models.SeatSwap
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the trip's crew or owner"
+// @Failure 404 {object} map[string]interface{} "Seat booking not found on this trip"
+// @Failure 409 {object} map[string]interface{} "Seat not available, or passenger no longer seated"
+// @Security BearerAuth
+// @Router /api/v1/staff/trips/{id}/seat-swap [post]
+func (h *StaffBookingHandler) SwapSeat(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.SeatSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	swap, err := h.seatSwapService.Swap(c.Param("id"), &req, &services.SeatSwapAttempt{
+		UserID:    userCtx.UserID,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		var validationErr *models.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+		case errors.Is(err, services.ErrSeatSwapBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		case errors.Is(err, services.ErrSeatSwapDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrSeatSwapSameSeat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrSeatSwapNotSwappable), errors.Is(err, database.ErrSeatSwapSeatUnavailable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("ERROR: Seat swap failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to swap seat"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, swap)
+}
+
+// respondBoardingError maps boarding window enforcement errors to responses
+func (h *StaffBookingHandler) respondBoardingError(c *gin.Context, err error) {
+	var windowErr *services.BoardingWindowError
+	switch {
+	case errors.As(err, &windowErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":        "Outside boarding window",
+			"code":         "boarding_window_" + string(windowErr.State),
+			"message":      windowErr.Error(),
+			"window_state": windowErr.State,
+			"opens_at":     windowErr.OpensAt,
+			"closes_at":    windowErr.ClosesAt,
+		})
+	case errors.Is(err, services.ErrBoardingBookingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+	case errors.Is(err, services.ErrBoardingOverrideNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrBoardingOverrideReason):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("ERROR: Boarding window check failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check boarding window"})
+	}
 }
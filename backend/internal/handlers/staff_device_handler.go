@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
+	"github.com/smarttransit/sms-auth-backend/pkg/jwt"
+)
+
+// StaffDeviceHandler handles staff device registration, device login and owner revocation
+type StaffDeviceHandler struct {
+	deviceService          *services.StaffDeviceAuthService
+	staffRepo              *database.BusStaffRepository
+	busOwnerRepo           *database.BusOwnerRepository
+	userRepository         *database.UserRepository
+	refreshTokenRepository *database.RefreshTokenRepository
+	jwtService             *jwt.Service
+	auditService           *services.AuditService
+}
+
+// NewStaffDeviceHandler creates a new StaffDeviceHandler
+func NewStaffDeviceHandler(
+	deviceService *services.StaffDeviceAuthService,
+	staffRepo *database.BusStaffRepository,
+	busOwnerRepo *database.BusOwnerRepository,
+	userRepository *database.UserRepository,
+	refreshTokenRepository *database.RefreshTokenRepository,
+	jwtService *jwt.Service,
+	auditService *services.AuditService,
+) *StaffDeviceHandler {
+	return &StaffDeviceHandler{
+		deviceService:          deviceService,
+		staffRepo:              staffRepo,
+		busOwnerRepo:           busOwnerRepo,
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		jwtService:             jwtService,
+		auditService:           auditService,
+	}
+}
+
+// RegisterDevice handles POST /api/v1/staff/devices
+// Called once after an OTP login to bind the device's biometric-protected key
+func (h *StaffDeviceHandler) RegisterDevice(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.RegisterStaffDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	staff, err := h.staffRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "staff_not_found", "message": "Staff profile not found"})
+		return
+	}
+
+	credential, err := h.deviceService.RegisterDevice(staff, &req)
+	if err != nil {
+		h.respondDeviceError(c, err)
+		return
+	}
+
+	log.Printf("INFO: Staff %s registered device %s (credential %s)", staff.ID, req.DeviceID, credential.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Device registered for biometric login",
+		"credential_id": credential.ID,
+		"key_algorithm": credential.KeyAlgorithm,
+		"expires_at":    credential.ExpiresAt,
+	})
+}
+
+// GetMyDevices handles GET /api/v1/staff/devices
+func (h *StaffDeviceHandler) GetMyDevices(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	staff, err := h.staffRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "staff_not_found", "message": "Staff profile not found"})
+		return
+	}
+
+	devices, err := h.deviceService.ListDevices(staff.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get devices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"devices": devices})
+}
+
+// RevokeMyDevice handles DELETE /api/v1/staff/devices/:id
+func (h *StaffDeviceHandler) RevokeMyDevice(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	staff, err := h.staffRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "staff_not_found", "message": "Staff profile not found"})
+		return
+	}
+
+	h.revoke(c, staff, userCtx.UserID.String(), "revoked_by_staff")
+}
+
+// CreateLoginChallenge handles POST /api/v1/auth/staff-device/challenge
+func (h *StaffDeviceHandler) CreateLoginChallenge(c *gin.Context) {
+	var req models.StaffDeviceChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	challenge, err := h.deviceService.IssueChallenge(req.CredentialID)
+	if err != nil {
+		h.respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+// DeviceLogin handles POST /api/v1/auth/staff-device/login
+// Exchanges a challenge signed by the device key for access and refresh tokens
+func (h *StaffDeviceHandler) DeviceLogin(c *gin.Context) {
+	var req models.StaffDeviceLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	clientIP := utils.GetRealIP(c)
+	userAgent := utils.GetUserAgent(c)
+
+	credential, err := h.deviceService.VerifyLogin(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrDeviceSignatureInvalid) {
+			h.auditService.LogSuspiciousActivity(nil, "staff_device_signature_invalid", clientIP, userAgent, map[string]interface{}{
+				"credential_id": req.CredentialID,
+			})
+		}
+		h.respondDeviceError(c, err)
+		return
+	}
+
+	userID, err := uuid.Parse(credential.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "login_failed", "message": "Invalid user for device"})
+		return
+	}
+	user, err := h.userRepository.GetUserByID(userID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_not_found", "message": "User account not found"})
+		return
+	}
+	if user.Status != "active" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account_inactive", "message": "User account is not active"})
+		return
+	}
+
+	accessToken, err := h.jwtService.GenerateAccessToken(user.ID, user.Phone, user.Roles, user.ProfileCompleted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "token_generation_failed",
+			Message: "Failed to generate access token",
+		})
+		return
+	}
+
+	refreshToken, err := h.jwtService.GenerateRefreshToken(user.ID, user.Phone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "token_generation_failed",
+			Message: "Failed to generate refresh token",
+		})
+		return
+	}
+
+	deviceType := c.GetHeader("X-Device-Type")
+	err = h.refreshTokenRepository.StoreRefreshToken(
+		user.ID,
+		refreshToken,
+		credential.DeviceID,
+		deviceType,
+		clientIP,
+		userAgent,
+		time.Now().Add(7*24*time.Hour), // 7 days
+	)
+	if err != nil {
+		// Log error but don't fail the login
+		log.Printf("WARNING: Failed to store refresh token for user %s: %v", user.ID, err)
+	}
+
+	h.auditService.LogLogin(user.ID, user.Phone, clientIP, userAgent, credential.DeviceID, deviceType)
+
+	c.JSON(http.StatusOK, VerifyOTPResponse{
+		Message:         "Device login successful",
+		AccessToken:     accessToken,
+		RefreshToken:    refreshToken,
+		ExpiresIn:       3600, // 1 hour
+		ProfileComplete: user.ProfileCompleted,
+		Roles:           user.Roles,
+	})
+}
+
+// GetStaffDevices handles GET /api/v1/bus-owner/staff/:staff_id/devices
+func (h *StaffDeviceHandler) GetStaffDevices(c *gin.Context) {
+	staff, ok := h.getOwnedStaff(c)
+	if !ok {
+		return
+	}
+
+	devices, err := h.deviceService.ListDevices(staff.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get devices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"staff_id": staff.ID, "devices": devices})
+}
+
+// RevokeStaffDevice handles DELETE /api/v1/bus-owner/staff/:staff_id/devices/:id
+func (h *StaffDeviceHandler) RevokeStaffDevice(c *gin.Context) {
+	staff, ok := h.getOwnedStaff(c)
+	if !ok {
+		return
+	}
+
+	userCtx, _ := middleware.GetUserContext(c)
+	h.revoke(c, staff, userCtx.UserID.String(), "revoked_by_bus_owner")
+}
+
+// revoke revokes the credential in :id and signs the device out
+func (h *StaffDeviceHandler) revoke(c *gin.Context, staff *models.BusStaff, revokedBy, defaultReason string) {
+	var req models.RevokeStaffDeviceRequest
+	_ = c.ShouldBindJSON(&req) // Body is optional
+	if req.Reason == "" {
+		req.Reason = defaultReason
+	}
+
+	credential, err := h.deviceService.RevokeDevice(staff.ID, c.Param("id"), revokedBy, req.Reason)
+	if err != nil {
+		h.respondDeviceError(c, err)
+		return
+	}
+
+	// Also end any session the device already holds
+	if userID, err := uuid.Parse(credential.UserID); err == nil {
+		if err := h.refreshTokenRepository.RevokeDeviceTokens(userID, credential.DeviceID); err != nil {
+			log.Printf("WARNING: Failed to revoke refresh tokens for device %s: %v", credential.DeviceID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Device has been revoked",
+		"credential_id": credential.ID,
+	})
+}
+
+// getOwnedStaff loads :staff_id and checks it is currently employed by the calling bus owner
+func (h *StaffDeviceHandler) getOwnedStaff(c *gin.Context) (*models.BusStaff, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bus owner profile not found"})
+		return nil, false
+	}
+
+	staff, err := h.staffRepo.GetByID(c.Param("staff_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff member not found"})
+		return nil, false
+	}
+
+	employment, err := h.staffRepo.GetCurrentEmployment(staff.ID)
+	if err != nil || employment == nil || employment.BusOwnerID != busOwner.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This staff member is not employed by your organization"})
+		return nil, false
+	}
+
+	return staff, true
+}
+
+// respondDeviceError maps device auth errors to HTTP responses
+func (h *StaffDeviceHandler) respondDeviceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidDevicePublicKey):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_public_key", "message": err.Error()})
+	case errors.Is(err, services.ErrTooManyStaffDevices):
+		c.JSON(http.StatusConflict, gin.H{"error": "too_many_devices", "message": "Revoke an existing device before registering a new one"})
+	case errors.Is(err, services.ErrStaffNotEmployed):
+		c.JSON(http.StatusForbidden, gin.H{"error": "staff_not_employed", "message": "Device login requires an active employment with a bus owner"})
+	case errors.Is(err, services.ErrDeviceCredentialNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "device_not_found", "message": "Device credential not found"})
+	case errors.Is(err, services.ErrDeviceCredentialInactive):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "device_revoked", "message": "This device can no longer log in. Please log in with OTP."})
+	case errors.Is(err, services.ErrDeviceChallengeInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "challenge_invalid", "message": "Login challenge expired or already used. Request a new one."})
+	case errors.Is(err, services.ErrDeviceSignatureInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "signature_invalid", "message": "Device signature could not be verified"})
+	default:
+		log.Printf("ERROR: staff device auth: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Device authentication failed"})
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// StaffDocumentHandler serves staff document renewals: staff upload them, and their bus owner
+// or an admin approves or rejects them
+type StaffDocumentHandler struct {
+	documentService *services.StaffDocumentService
+	ownerRepo       *database.BusOwnerRepository
+	logger          *logrus.Logger
+}
+
+// NewStaffDocumentHandler creates a new StaffDocumentHandler
+func NewStaffDocumentHandler(documentService *services.StaffDocumentService, ownerRepo *database.BusOwnerRepository, logger *logrus.Logger) *StaffDocumentHandler {
+	return &StaffDocumentHandler{
+		documentService: documentService,
+		ownerRepo:       ownerRepo,
+		logger:          logger,
+	}
+}
+
+// GetMyDocuments reports each of the staff member's documents and any renewal in review
+// GET /api/v1/staff/documents
+func (h *StaffDocumentHandler) GetMyDocuments(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	documents, err := h.documentService.GetMyDocuments(userCtx.UserID.String())
+	if err != nil {
+		h.respondError(c, err, "Failed to get documents")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documents": documents})
+}
+
+// SubmitRenewal uploads a renewed license, NIC or medical certificate for review
+// POST /api/v1/staff/documents
+func (h *StaffDocumentHandler) SubmitRenewal(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.SubmitStaffDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	renewal, err := h.documentService.SubmitRenewal(userCtx.UserID.String(), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to submit document")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Document submitted for review",
+		"document": renewal,
+	})
+}
+
+// GetOwnerPendingRenewals lists document renewals from the bus owner's staff waiting for review
+// GET /api/v1/bus-owner/staff/documents/pending
+func (h *StaffDocumentHandler) GetOwnerPendingRenewals(c *gin.Context) {
+	owner, ok := h.currentBusOwner(c)
+	if !ok {
+		return
+	}
+
+	renewals, err := h.documentService.ListPendingForBusOwner(owner.ID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get pending documents")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documents": renewals, "total": len(renewals)})
+}
+
+// OwnerApproveRenewal approves a staff member's document; an approved license replaces the one on their profile
+// POST /api/v1/bus-owner/staff/documents/:id/approve
+func (h *StaffDocumentHandler) OwnerApproveRenewal(c *gin.Context) {
+	owner, ok := h.currentBusOwner(c)
+	if !ok {
+		return
+	}
+	h.review(c, owner.UserID, owner.ID, true)
+}
+
+// OwnerRejectRenewal rejects a staff member's document with a reason sent to them
+// POST /api/v1/bus-owner/staff/documents/:id/reject
+func (h *StaffDocumentHandler) OwnerRejectRenewal(c *gin.Context) {
+	owner, ok := h.currentBusOwner(c)
+	if !ok {
+		return
+	}
+	h.review(c, owner.UserID, owner.ID, false)
+}
+
+// GetPendingRenewals lists every document renewal waiting for review
+// GET /api/v1/admin/staff-documents?limit=50&offset=0
+func (h *StaffDocumentHandler) GetPendingRenewals(c *gin.Context) {
+	limit, offset := payoutPagination(c)
+
+	renewals, total, err := h.documentService.ListPending(limit, offset)
+	if err != nil {
+		h.respondError(c, err, "Failed to get pending documents")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"documents": renewals,
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
+
+// AdminApproveRenewal approves any staff member's document
+// POST /api/v1/admin/staff-documents/:id/approve
+func (h *StaffDocumentHandler) AdminApproveRenewal(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+	h.review(c, userCtx.UserID.String(), "", true)
+}
+
+// AdminRejectRenewal rejects any staff member's document
+// POST /api/v1/admin/staff-documents/:id/reject
+func (h *StaffDocumentHandler) AdminRejectRenewal(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+	h.review(c, userCtx.UserID.String(), "", false)
+}
+
+// review applies a reviewer's decision; rejections need a reason in the body
+func (h *StaffDocumentHandler) review(c *gin.Context, reviewerUserID, busOwnerID string, approve bool) {
+	reason := ""
+	if !approve {
+		var req models.RejectStaffRequestInput
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+			return
+		}
+		reason = req.Reason
+	}
+
+	renewal, err := h.documentService.Review(c.Param("id"), reviewerUserID, busOwnerID, approve, reason)
+	if err != nil {
+		h.respondError(c, err, "Failed to review document")
+		return
+	}
+
+	message := "Document approved"
+	if !approve {
+		message = "Document rejected"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "document": renewal})
+}
+
+// currentBusOwner loads the authenticated bus owner, or sends an error response and returns false
+func (h *StaffDocumentHandler) currentBusOwner(c *gin.Context) (*models.BusOwner, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return nil, false
+	}
+	owner, err := h.ownerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil || owner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+		return nil, false
+	}
+	return owner, true
+}
+
+func (h *StaffDocumentHandler) respondError(c *gin.Context, err error, message string) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrStaffProfileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Staff profile not found"})
+	case errors.Is(err, services.ErrStaffDocumentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Document not found"})
+	case errors.Is(err, services.ErrStaffDocumentReviewed):
+		c.JSON(http.StatusConflict, gin.H{"error": "already_reviewed", "message": "Document has already been reviewed or replaced"})
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": message})
+	}
+}
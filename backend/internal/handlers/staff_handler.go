@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -84,6 +86,13 @@ func (h *StaffHandler) RegisterStaff(c *gin.Context) {
 	// Register staff
 	staff, err := h.staffService.RegisterStaff(&input)
 	if err != nil {
+		if errors.Is(err, services.ErrJoinBusOwnerNotFound) || errors.Is(err, services.ErrJoinBusOwnerNotVerified) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_bus_owner",
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "registration_failed",
 			"message": err.Error(),
@@ -91,6 +100,11 @@ func (h *StaffHandler) RegisterStaff(c *gin.Context) {
 		return
 	}
 
+	nextStep := "Wait for a bus owner to link you or search for a bus owner to join"
+	if input.BusOwnerID != nil && *input.BusOwnerID != "" {
+		nextStep = "Wait for the bus owner to review your request"
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message":           "Registration successful",
 		"staff_id":          staff.ID,
@@ -99,7 +113,7 @@ func (h *StaffHandler) RegisterStaff(c *gin.Context) {
 		"last_name":         staff.LastName,
 		"profile_completed": staff.ProfileCompleted,
 		"is_employed":       false, // New registrations are not yet employed
-		"next_step":         "Wait for a bus owner to link you or search for a bus owner to join",
+		"next_step":         nextStep,
 	})
 }
 
@@ -173,6 +187,52 @@ func (h *StaffHandler) UpdateProfile(c *gin.Context) {
 	})
 }
 
+// RequestToJoinBusOwner asks a bus owner to take on the authenticated staff member
+// POST /api/v1/staff/join-request
+func (h *StaffHandler) RequestToJoinBusOwner(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	var req models.JoinBusOwnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation_error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	employment, err := h.staffService.RequestToJoinBusOwner(userCtx.UserID.String(), req.BusOwnerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrStaffNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_staff", "message": "User is not registered as staff"})
+		case errors.Is(err, services.ErrJoinBusOwnerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "bus_owner_not_found", "message": err.Error()})
+		case errors.Is(err, services.ErrJoinBusOwnerNotVerified):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bus_owner_not_verified", "message": err.Error()})
+		case errors.Is(err, services.ErrStaffRequestPending), errors.Is(err, services.ErrStaffAlreadyEmployed):
+			c.JSON(http.StatusConflict, gin.H{"error": "already_requested", "message": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "request_failed", "message": "Failed to send join request"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":           "Request sent. You'll be notified when the bus owner reviews it.",
+		"employment_id":     employment.ID,
+		"bus_owner_id":      employment.BusOwnerID,
+		"employment_status": employment.EmploymentStatus,
+	})
+}
+
 // SearchBusOwners searches for bus owners
 // GET /api/v1/staff/bus-owners/search?code=ABC123
 // GET /api/v1/staff/bus-owners/search?bus_number=WP-1234
@@ -211,8 +271,8 @@ func (h *StaffHandler) SearchBusOwners(c *gin.Context) {
 	})
 }
 
-// GetMyTrips gets trips assigned to the authenticated staff member
-// GET /api/v1/staff/my-trips?start_date=2024-01-01&end_date=2024-01-31
+// GetMyTrips gets trips assigned to the authenticated staff member, plus a summary of their next assignment
+// GET /api/v1/staff/my-trips?start_date=2024-01-01&end_date=2024-01-31&status=scheduled,in_progress&limit=20&offset=0
 func (h *StaffHandler) GetMyTrips(c *gin.Context) {
 	// Get user context from Gin (set by auth middleware)
 	userCtx, exists := middleware.GetUserContext(c)
@@ -270,8 +330,44 @@ func (h *StaffHandler) GetMyTrips(c *gin.Context) {
 		}
 	}
 
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_date",
+			"message": "end_date must not be before start_date",
+		})
+		return
+	}
+
+	statuses, err := models.ParseScheduledTripStatuses(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_status",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Parse pagination (default 50, max 100)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
 	// Get assigned trips
-	trips, err := h.scheduledTripRepo.GetAssignedTripsForStaff(staff.ID, startDate, endDate)
+	trips, total, err := h.scheduledTripRepo.GetAssignedTripsForStaff(staff.ID, models.StaffTripFilter{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Statuses:  statuses,
+		Limit:     limit,
+		Offset:    offset,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "fetch_failed",
@@ -280,6 +376,16 @@ func (h *StaffHandler) GetMyTrips(c *gin.Context) {
 		return
 	}
 
+	// Next assignment is independent of the filters so the home screen always has it
+	nextAssignment, err := h.scheduledTripRepo.GetNextAssignmentForStaff(staff.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "fetch_failed",
+			"message": "Failed to fetch next assignment",
+		})
+		return
+	}
+
 	// Enrich trips with role information (is_driver, is_conductor)
 	type TripWithRole struct {
 		models.ScheduledTripWithRouteInfo
@@ -297,11 +403,15 @@ func (h *StaffHandler) GetMyTrips(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"trips":      enrichedTrips,
-		"count":      len(enrichedTrips),
-		"staff_id":   staff.ID,
-		"staff_type": staff.StaffType,
-		"start_date": startDate.Format("2006-01-02"),
-		"end_date":   endDate.Format("2006-01-02"),
+		"trips":           enrichedTrips,
+		"count":           len(enrichedTrips),
+		"total":           total,
+		"limit":           limit,
+		"offset":          offset,
+		"next_assignment": nextAssignment,
+		"staff_id":        staff.ID,
+		"staff_type":      staff.StaffType,
+		"start_date":      startDate.Format("2006-01-02"),
+		"end_date":        endDate.Format("2006-01-02"),
 	})
 }
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// StandingTicketHandler handles unreserved standing tickets: the owner's per-trip cap, the
+// passenger's place in the queue and the crew's sales and boarding validation
+type StandingTicketHandler struct {
+	standingService *services.StandingTicketService
+	busOwnerRepo    *database.BusOwnerRepository
+	logger          *logrus.Logger
+}
+
+// NewStandingTicketHandler creates a new StandingTicketHandler
+func NewStandingTicketHandler(
+	standingService *services.StandingTicketService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *StandingTicketHandler {
+	return &StandingTicketHandler{
+		standingService: standingService,
+		busOwnerRepo:    busOwnerRepo,
+		logger:          logger,
+	}
+}
+
+// ============================================================================
+// OWNER ENDPOINTS
+// ============================================================================
+
+// GetTripStanding returns a trip's standing cap, fare and counts
+// GET /api/v1/scheduled-trips/:id/standing-tickets
+func (h *StandingTicketHandler) GetTripStanding(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.standingService.GetTripStanding(c.Param("id"), busOwnerID)
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"standing": summary})
+}
+
+// UpdateTripStanding sets how many standing tickets a trip sells and at what fare
+// PUT /api/v1/scheduled-trips/:id/standing-tickets
+func (h *StandingTicketHandler) UpdateTripStanding(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTripStandingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	summary, err := h.standingService.UpdateTripStanding(c.Param("id"), busOwnerID, &req)
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Standing tickets updated", "standing": summary})
+}
+
+// ============================================================================
+// PASSENGER ENDPOINTS
+// ============================================================================
+
+// GetAvailability returns how many standing places a trip has left
+// GET /api/v1/standing-tickets/trips/:trip_id
+func (h *StandingTicketHandler) GetAvailability(c *gin.Context) {
+	summary, err := h.standingService.GetAvailability(c.Param("trip_id"))
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scheduled_trip_id": summary.ScheduledTripID,
+		"on_sale":           summary.EffectiveCap > 0,
+		"remaining":         summary.Remaining,
+		"waiting":           summary.Waiting,
+		"fare":              summary.Fare,
+	})
+}
+
+// ReserveTicket puts the passenger at the back of a trip's standing queue
+// POST /api/v1/standing-tickets
+func (h *StandingTicketHandler) ReserveTicket(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReserveStandingTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	ticket, err := h.standingService.Reserve(userCtx.UserID, &req)
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "You're in the standing queue. Pay the conductor when you board.", "ticket": ticket})
+}
+
+// GetMyTickets lists the passenger's standing tickets
+// GET /api/v1/standing-tickets
+func (h *StandingTicketHandler) GetMyTickets(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	tickets, err := h.standingService.ListMyTickets(userCtx.UserID)
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tickets": tickets, "total": len(tickets)})
+}
+
+// GetMyTicket returns one of the passenger's standing tickets with their place in the queue
+// GET /api/v1/standing-tickets/:id
+func (h *StandingTicketHandler) GetMyTicket(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	ticket, err := h.standingService.GetMyTicket(c.Param("id"), userCtx.UserID)
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ticket": ticket})
+}
+
+// CancelMyTicket gives up the passenger's place in the standing queue
+// POST /api/v1/standing-tickets/:id/cancel
+func (h *StandingTicketHandler) CancelMyTicket(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.standingService.CancelMyTicket(c.Param("id"), userCtx.UserID); err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Standing ticket cancelled"})
+}
+
+// ============================================================================
+// STAFF ENDPOINTS
+// ============================================================================
+
+// GetQueue returns the trip's standing passengers waiting to board, in order
+// GET /api/v1/staff/trips/:id/standing-queue
+func (h *StandingTicketHandler) GetQueue(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	queue, err := h.standingService.GetQueue(c.Param("id"), userCtx.UserID)
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// IssueTicket sells a standing ticket on the spot
+// POST /api/v1/staff/trips/:id/standing-tickets
+func (h *StandingTicketHandler) IssueTicket(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	var req models.IssueStandingTicketRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+			return
+		}
+	}
+
+	ticket, err := h.standingService.Issue(c.Param("id"), userCtx.UserID, &req)
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Standing ticket issued", "ticket": ticket})
+}
+
+// ValidateTicket checks a standing passenger's boarding token and boards them
+// POST /api/v1/staff/standing-tickets/validate
+func (h *StandingTicketHandler) ValidateTicket(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	var req models.ValidateStandingTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	ticket, err := h.standingService.Validate(req.BoardingToken, userCtx.UserID)
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": true, "message": "Standing passenger boarded", "ticket": ticket})
+}
+
+// SkipTicket takes a waiting passenger who was not there when called out of the queue
+// POST /api/v1/staff/standing-tickets/:id/skip
+func (h *StandingTicketHandler) SkipTicket(c *gin.Context) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return
+	}
+
+	ticket, err := h.standingService.Skip(c.Param("id"), userCtx.UserID)
+	if err != nil {
+		h.respondStandingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Standing passenger skipped", "ticket": ticket})
+}
+
+func (h *StandingTicketHandler) userContext(c *gin.Context) (middleware.UserContext, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return userCtx, false
+	}
+	return userCtx, true
+}
+
+func (h *StandingTicketHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, ok := h.userContext(c)
+	if !ok {
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+func (h *StandingTicketHandler) respondStandingError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	var orderErr *database.StandingQueueOrderError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.As(err, &orderErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":            "not_next_in_queue",
+			"message":          err.Error(),
+			"queue_position":   orderErr.QueuePosition,
+			"next_position":    orderErr.NextPosition,
+			"passengers_ahead": orderErr.Ahead,
+		})
+	case errors.Is(err, services.ErrStandingTripNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "trip_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrStandingTicketNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "ticket_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrStandingDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden", "message": err.Error()})
+	case errors.Is(err, services.ErrStandingNotOnSale):
+		c.JSON(http.StatusConflict, gin.H{"error": "not_on_sale", "message": err.Error()})
+	case errors.Is(err, services.ErrStandingTripClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "sales_closed", "message": err.Error()})
+	case errors.Is(err, database.ErrStandingSoldOut):
+		c.JSON(http.StatusConflict, gin.H{"error": "sold_out", "message": err.Error()})
+	case errors.Is(err, database.ErrStandingAlreadyQueued):
+		c.JSON(http.StatusConflict, gin.H{"error": "already_queued", "message": err.Error()})
+	case errors.Is(err, database.ErrStandingBusFull):
+		c.JSON(http.StatusConflict, gin.H{"error": "standing_capacity_reached", "message": err.Error()})
+	case errors.Is(err, database.ErrStandingTicketNotWaiting):
+		c.JSON(http.StatusConflict, gin.H{"error": "ticket_not_waiting", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Standing ticket request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Standing ticket request failed"})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
 )
 
@@ -50,11 +51,20 @@ func (h *SystemSettingHandler) GetSettingByKey(c *gin.Context) {
 }
 
 // UpdateSetting updates a system setting's value
-// PUT /api/v1/system-settings/:key
-// NOTE: In production, this should be admin-only
+// PUT /api/v1/system-settings/:key (admins with operations.manage)
 func (h *SystemSettingHandler) UpdateSetting(c *gin.Context) {
 	key := c.Param("key")
 
+	// App version policy and maintenance keys gate every client; never let a non-admin
+	// write them, even if the route is wired without the admin permission check
+	if models.IsAppConfigSettingKey(key) {
+		userCtx, exists := middleware.GetUserContext(c)
+		if !exists || !userCtx.HasRole("admin") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change this setting"})
+			return
+		}
+	}
+
 	var req models.UpdateSystemSettingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateSetting_AppConfigKeysAreAdminOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewSystemSettingHandler(database.NewSystemSettingRepository(&database.PostgresDB{DB: sqlx.NewDb(db, "sqlmock")}))
+
+	request := func(key string, roles ...string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.PUT("/system-settings/:key", func(c *gin.Context) {
+			c.Set(middleware.UserContextKey, middleware.UserContext{UserID: uuid.New(), Roles: roles})
+		}, handler.UpdateSetting)
+
+		req := httptest.NewRequest("PUT", "/system-settings/"+key, strings.NewReader(`{"setting_value":"99.0.0"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A passenger must not be able to force every client to upgrade or switch on maintenance
+	assert.Equal(t, http.StatusForbidden, request("app_min_version_android", "passenger").Code)
+	assert.Equal(t, http.StatusForbidden, request("maintenance_mode", "passenger").Code)
+	require.NoError(t, mock.ExpectationsWereMet(), "rejected writes must not reach the database")
+
+	columns := []string{"id", "setting_key", "setting_value", "description", "created_at", "updated_at"}
+	mock.ExpectQuery("SELECT (.+) FROM system_settings").WithArgs("app_min_version_android").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "app_min_version_android", "1.0.0", nil, time.Now(), time.Now()))
+	mock.ExpectExec("UPDATE system_settings").WithArgs("99.0.0", "app_min_version_android").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM system_settings").WithArgs("app_min_version_android").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "app_min_version_android", "99.0.0", nil, time.Now(), time.Now()))
+
+	assert.Equal(t, http.StatusOK, request("app_min_version_android", "admin").Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
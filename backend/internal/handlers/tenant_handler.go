@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TenantHandler handles white-label tenant configuration and admin tenant management
+type TenantHandler struct {
+	tenantService *services.TenantService
+}
+
+// NewTenantHandler creates a new TenantHandler
+func NewTenantHandler(tenantService *services.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+	}
+}
+
+// AssignBusOwnerTenantRequest moves a bus owner to a tenant
+type AssignBusOwnerTenantRequest struct {
+	TenantID *string `json:"tenant_id"` // null moves the owner back to SmartTransit
+}
+
+// GetConfig returns the branding of the tenant identified by X-Tenant-Key (SmartTransit without it)
+// GET /api/v1/tenant/config
+func (h *TenantHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tenant": middleware.GetTenant(c).Branding()})
+}
+
+// ListTenants lists all tenants
+// GET /api/v1/admin/tenants
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.tenantService.ListTenants()
+	if err != nil {
+		h.respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tenants": tenants})
+}
+
+// CreateTenant registers a white-label tenant
+// POST /api/v1/admin/tenants
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var req models.CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	tenant, err := h.tenantService.CreateTenant(&req)
+	if err != nil {
+		h.respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Tenant created", "tenant": tenant})
+}
+
+// GetTenant returns a tenant
+// GET /api/v1/admin/tenants/:id
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	tenantID, ok := parseTenantParam(c, "id")
+	if !ok {
+		return
+	}
+
+	tenant, err := h.tenantService.GetTenant(tenantID)
+	if err != nil {
+		h.respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tenant": tenant})
+}
+
+// UpdateTenant changes a tenant's branding, delivery or payment configuration
+// PATCH /api/v1/admin/tenants/:id
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	tenantID, ok := parseTenantParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	tenant, err := h.tenantService.UpdateTenant(tenantID, &req)
+	if err != nil {
+		h.respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tenant updated", "tenant": tenant})
+}
+
+// ListAPIKeys lists a tenant's API keys
+// GET /api/v1/admin/tenants/:id/api-keys
+func (h *TenantHandler) ListAPIKeys(c *gin.Context) {
+	tenantID, ok := parseTenantParam(c, "id")
+	if !ok {
+		return
+	}
+
+	keys, err := h.tenantService.ListAPIKeys(tenantID)
+	if err != nil {
+		h.respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// CreateAPIKey issues an API key for a tenant's app. The key is only shown in this response.
+// POST /api/v1/admin/tenants/:id/api-keys
+func (h *TenantHandler) CreateAPIKey(c *gin.Context) {
+	tenantID, ok := parseTenantParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateTenantAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	key, rawKey, err := h.tenantService.IssueAPIKey(tenantID, &req)
+	if err != nil {
+		h.respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created. Store it now; it cannot be shown again.",
+		"api_key": key,
+		"key":     rawKey,
+	})
+}
+
+// RevokeAPIKey revokes a tenant API key
+// DELETE /api/v1/admin/tenants/:id/api-keys/:key_id
+func (h *TenantHandler) RevokeAPIKey(c *gin.Context) {
+	tenantID, ok := parseTenantParam(c, "id")
+	if !ok {
+		return
+	}
+	keyID, ok := parseTenantParam(c, "key_id")
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService.RevokeAPIKey(tenantID, keyID); err != nil {
+		h.respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+// AssignBusOwner moves a bus owner, and its trips that have not departed, to a tenant
+// PUT /api/v1/admin/tenants/bus-owners/:owner_id
+func (h *TenantHandler) AssignBusOwner(c *gin.Context) {
+	ownerID, ok := parseTenantParam(c, "owner_id")
+	if !ok {
+		return
+	}
+
+	var req AssignBusOwnerTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+	if req.TenantID != nil {
+		if _, err := uuid.Parse(*req.TenantID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_tenant_id", "message": "tenant_id must be a UUID"})
+			return
+		}
+	}
+
+	if err := h.tenantService.AssignBusOwner(ownerID, req.TenantID); err != nil {
+		h.respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bus owner tenant updated", "bus_owner_id": ownerID, "tenant_id": req.TenantID})
+}
+
+// parseTenantParam reads a UUID path parameter, responding 400 if it is malformed
+func parseTenantParam(c *gin.Context, name string) (string, bool) {
+	value := c.Param(name)
+	if _, err := uuid.Parse(value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_" + name, "message": name + " must be a UUID"})
+		return "", false
+	}
+	return value, true
+}
+
+func (h *TenantHandler) respondTenantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTenantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "tenant_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrTenantAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "api_key_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrTenantBusOwnerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "bus_owner_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrTenantSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "slug_taken", "message": err.Error()})
+	case errors.Is(err, services.ErrInvalidTenantSlug):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_slug", "message": err.Error()})
+	case errors.Is(err, services.ErrIncompleteMerchant):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "incomplete_merchant_account", "message": err.Error()})
+	default:
+		log.Printf("ERROR: Tenant request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Tenant request failed"})
+	}
+}
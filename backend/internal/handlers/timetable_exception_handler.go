@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TimetableExceptionHandler handles the holiday calendar used by trip generation: a schedule's
+// own exceptions for its owner, and system holidays for admins
+type TimetableExceptionHandler struct {
+	exceptionService *services.TimetableExceptionService
+	busOwnerRepo     *database.BusOwnerRepository
+	logger           *logrus.Logger
+}
+
+// NewTimetableExceptionHandler creates a new TimetableExceptionHandler
+func NewTimetableExceptionHandler(
+	exceptionService *services.TimetableExceptionService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *TimetableExceptionHandler {
+	return &TimetableExceptionHandler{
+		exceptionService: exceptionService,
+		busOwnerRepo:     busOwnerRepo,
+		logger:           logger,
+	}
+}
+
+// GetScheduleExceptions lists a schedule's upcoming exceptions together with the system holidays
+// GET /api/v1/trip-schedules/:id/exceptions
+func (h *TimetableExceptionHandler) GetScheduleExceptions(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	exceptions, err := h.exceptionService.ListScheduleExceptions(c.Param("id"), busOwnerID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exceptions": exceptions, "total": len(exceptions)})
+}
+
+// CreateScheduleException skips, moves or runs a schedule's trip on a date
+// POST /api/v1/trip-schedules/:id/exceptions
+func (h *TimetableExceptionHandler) CreateScheduleException(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := middleware.GetUserContext(c)
+
+	var req models.TimetableExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	result, err := h.exceptionService.CreateScheduleException(c.Param("id"), busOwnerID, userCtx.UserID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": scheduleExceptionMessage(result), "exception": result.Exception, "already_generated_trip_id": result.AlreadyGeneratedTripID})
+}
+
+// UpdateScheduleException replaces one of a schedule's exceptions
+// PUT /api/v1/trip-schedules/:id/exceptions/:exceptionId
+func (h *TimetableExceptionHandler) UpdateScheduleException(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TimetableExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	result, err := h.exceptionService.UpdateScheduleException(c.Param("id"), c.Param("exceptionId"), busOwnerID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": scheduleExceptionMessage(result), "exception": result.Exception, "already_generated_trip_id": result.AlreadyGeneratedTripID})
+}
+
+// DeleteScheduleException removes one of a schedule's exceptions
+// DELETE /api/v1/trip-schedules/:id/exceptions/:exceptionId
+func (h *TimetableExceptionHandler) DeleteScheduleException(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.exceptionService.DeleteScheduleException(c.Param("id"), c.Param("exceptionId"), busOwnerID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Exception removed"})
+}
+
+// scheduleExceptionMessage warns the owner when a trip already generated for the date is not
+// changed by the exception
+func scheduleExceptionMessage(result *services.TimetableExceptionResult) string {
+	if result.AlreadyGeneratedTripID != nil {
+		return "Exception saved. A trip was already generated for this date; cancel or reschedule it from the trip list."
+	}
+	return "Exception saved"
+}
+
+func (h *TimetableExceptionHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+// ============================================================================
+// ADMIN ENDPOINTS
+// ============================================================================
+
+// ListSystemHolidays lists the upcoming and yearly system holidays
+// GET /api/v1/admin/timetable-exceptions
+func (h *TimetableExceptionHandler) ListSystemHolidays(c *gin.Context) {
+	holidays, err := h.exceptionService.ListSystemHolidays()
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exceptions": holidays, "total": len(holidays)})
+}
+
+// CreateSystemHoliday adds a date on which no schedule generates trips unless it opts out
+// POST /api/v1/admin/timetable-exceptions
+func (h *TimetableExceptionHandler) CreateSystemHoliday(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.TimetableExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	holiday, err := h.exceptionService.CreateSystemHoliday(userCtx.UserID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Holiday added. Trips already generated for the date are not changed.", "exception": holiday})
+}
+
+// UpdateSystemHoliday replaces a system holiday
+// PUT /api/v1/admin/timetable-exceptions/:id
+func (h *TimetableExceptionHandler) UpdateSystemHoliday(c *gin.Context) {
+	var req models.TimetableExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	holiday, err := h.exceptionService.UpdateSystemHoliday(c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday updated", "exception": holiday})
+}
+
+// DeleteSystemHoliday removes a system holiday
+// DELETE /api/v1/admin/timetable-exceptions/:id
+func (h *TimetableExceptionHandler) DeleteSystemHoliday(c *gin.Context) {
+	if err := h.exceptionService.DeleteSystemHoliday(c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday removed"})
+}
+
+func (h *TimetableExceptionHandler) respondError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrTimetableExceptionScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrTimetableExceptionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "exception_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrTimetableExceptionDuplicate):
+		c.JSON(http.StatusConflict, gin.H{"error": "duplicate_exception", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Timetable exception request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Timetable exception request failed"})
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TripAutoPublishHandler handles per-schedule auto-publish settings and the owner's dashboard
+// alerts for trips that could not be published
+type TripAutoPublishHandler struct {
+	autoPublishService *services.TripAutoPublishService
+	busOwnerRepo       *database.BusOwnerRepository
+	logger             *logrus.Logger
+}
+
+// NewTripAutoPublishHandler creates a new TripAutoPublishHandler
+func NewTripAutoPublishHandler(
+	autoPublishService *services.TripAutoPublishService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *TripAutoPublishHandler {
+	return &TripAutoPublishHandler{
+		autoPublishService: autoPublishService,
+		busOwnerRepo:       busOwnerRepo,
+		logger:             logger,
+	}
+}
+
+// GetSettings returns a schedule's auto-publish settings
+// GET /api/v1/trip-schedules/:id/auto-publish
+func (h *TripAutoPublishHandler) GetSettings(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.autoPublishService.GetSettings(c.Param("id"), busOwnerID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// UpdateSettings turns auto-publish on or off for a schedule
+// PUT /api/v1/trip-schedules/:id/auto-publish
+func (h *TripAutoPublishHandler) UpdateSettings(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTripAutoPublishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	settings, err := h.autoPublishService.UpdateSettings(c.Param("id"), busOwnerID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	message := "Auto-publish turned off"
+	if settings.Enabled {
+		message = "Trips will be published automatically once a bus and seat layout are assigned"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "settings": settings})
+}
+
+// GetAlerts lists the owner's trips that are due but could not be published
+// GET /api/v1/bus-owner/auto-publish-alerts?include_dismissed=true
+func (h *TripAutoPublishHandler) GetAlerts(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.autoPublishService.ListAlerts(busOwnerID, c.Query("include_dismissed") == "true")
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"alerts": alerts, "total": len(alerts)})
+}
+
+// DismissAlert hides an alert until the reason the trip cannot be published changes
+// POST /api/v1/bus-owner/auto-publish-alerts/:id/dismiss
+func (h *TripAutoPublishHandler) DismissAlert(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.autoPublishService.DismissAlert(c.Param("id"), busOwnerID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert dismissed"})
+}
+
+func (h *TripAutoPublishHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+func (h *TripAutoPublishHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAutoPublishScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrAutoPublishAlertNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "alert_not_found", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Trip auto-publish request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Trip auto-publish request failed"})
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TripBoardingWindowHandler handles the owner settings of a trip's boarding window
+type TripBoardingWindowHandler struct {
+	boardingService *services.TripBoardingWindowService
+	busOwnerRepo    *database.BusOwnerRepository
+	logger          *logrus.Logger
+}
+
+// NewTripBoardingWindowHandler creates a new TripBoardingWindowHandler
+func NewTripBoardingWindowHandler(
+	boardingService *services.TripBoardingWindowService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *TripBoardingWindowHandler {
+	return &TripBoardingWindowHandler{
+		boardingService: boardingService,
+		busOwnerRepo:    busOwnerRepo,
+		logger:          logger,
+	}
+}
+
+// GetBoardingWindow returns when boarding opens and closes for the owner's trip
+// GET /api/v1/scheduled-trips/:id/boarding-window
+func (h *TripBoardingWindowHandler) GetBoardingWindow(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	window, err := h.boardingService.GetWindow(c.Param("id"), busOwnerID)
+	if err != nil {
+		h.respondBoardingWindowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// UpdateBoardingWindow sets when boarding opens and closes for the owner's trip
+// PUT /api/v1/scheduled-trips/:id/boarding-window
+func (h *TripBoardingWindowHandler) UpdateBoardingWindow(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTripBoardingWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	window, err := h.boardingService.ConfigureWindow(c.Param("id"), busOwnerID, &req)
+	if err != nil {
+		h.respondBoardingWindowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+func (h *TripBoardingWindowHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+func (h *TripBoardingWindowHandler) respondBoardingWindowError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrBoardingTripNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "trip_not_found", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Boarding window request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Boarding window request failed"})
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TripBookingListHandler serves a bus owner's consolidated list of a trip's bookings
+type TripBookingListHandler struct {
+	listService  *services.TripBookingListService
+	busOwnerRepo *database.BusOwnerRepository
+	logger       *logrus.Logger
+}
+
+// NewTripBookingListHandler creates a new TripBookingListHandler
+func NewTripBookingListHandler(
+	listService *services.TripBookingListService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *TripBookingListHandler {
+	return &TripBookingListHandler{
+		listService:  listService,
+		busOwnerRepo: busOwnerRepo,
+		logger:       logger,
+	}
+}
+
+// GetTripBookings returns every booking on the owner's trip with per-source revenue subtotals
+// GET /api/v1/scheduled-trips/:id/bookings?status=&source=&boarding_stop_id=&format=csv
+func (h *TripBookingListHandler) GetTripBookings(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return
+	}
+
+	filter, err := models.NewTripBookingListFilter(c.Query("status"), c.Query("source"), c.Query("boarding_stop_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	list, err := h.listService.GetTripBookings(c.Param("id"), busOwner.ID, filter)
+	if err != nil {
+		if errors.Is(err, services.ErrTripBookingsNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to list trip bookings")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to list trip bookings"})
+		return
+	}
+
+	if strings.EqualFold(c.Query("format"), "csv") {
+		h.writeCSV(c, list)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *TripBookingListHandler) writeCSV(c *gin.Context, list *models.TripBookingList) {
+	filename := fmt.Sprintf("trip-bookings-%s-%s.csv", list.Trip.DepartureDatetime.In(models.ReportTimezone).Format("20060102-1504"), list.Trip.ScheduledTripID)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(models.TripBookingCSVHeader)
+	for i := range list.Bookings {
+		_ = w.Write(list.Bookings[i].CSVRecord())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		h.logger.WithError(err).Error("Failed to write trip bookings export")
+	}
+}
+
+// GetTripManifest downloads a trip's passenger manifest, grouped by boarding stop, for its bus
+// owner, driver or conductor
+// GET /api/v1/scheduled-trips/:id/manifest?format=pdf|csv|json
+func (h *TripBookingListHandler) GetTripManifest(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	format, err := models.ParseTripManifestFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	manifest, err := h.listService.GetTripManifest(c.Param("id"), userCtx.UserID.String())
+	if err != nil {
+		if errors.Is(err, services.ErrTripBookingsNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to build trip manifest")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to build trip manifest"})
+		return
+	}
+
+	filename := fmt.Sprintf("manifest-%s-%s", manifest.Trip.DepartureDatetime.In(models.ReportTimezone).Format("20060102-1504"), manifest.Trip.ScheduledTripID)
+	switch format {
+	case models.TripManifestJSON:
+		c.JSON(http.StatusOK, manifest)
+	case models.TripManifestCSV:
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write(models.TripManifestCSVHeader)
+		_ = w.WriteAll(manifest.CSVRecords())
+		if err := w.Error(); err != nil {
+			h.logger.WithError(err).Error("Failed to write trip manifest export")
+		}
+	default:
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, filename))
+		c.Data(http.StatusOK, "application/pdf", services.TripManifestPDF(manifest))
+	}
+}
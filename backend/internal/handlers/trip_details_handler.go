@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TripDetailsHandler serves the public details of bookable trips and the bus owner's travel
+// policy shown in them
+type TripDetailsHandler struct {
+	detailsService *services.TripDetailsService
+	busOwnerRepo   *database.BusOwnerRepository
+	logger         *logrus.Logger
+}
+
+// NewTripDetailsHandler creates a new TripDetailsHandler
+func NewTripDetailsHandler(
+	detailsService *services.TripDetailsService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *TripDetailsHandler {
+	return &TripDetailsHandler{
+		detailsService: detailsService,
+		busOwnerRepo:   busOwnerRepo,
+		logger:         logger,
+	}
+}
+
+// GetTripDetails returns a bookable trip's stops, amenities, policies, seat prices and lounges
+// GET /api/v1/bookable-trips/:id/details
+func (h *TripDetailsHandler) GetTripDetails(c *gin.Context) {
+	details, err := h.detailsService.GetTripDetails(c.Param("id"), middleware.GetTenant(c))
+	if err != nil {
+		if errors.Is(err, services.ErrBookableTripNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get trip details")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to get trip details"})
+		return
+	}
+	c.JSON(http.StatusOK, details)
+}
+
+// GetTravelPolicy returns the bus owner's luggage policy
+// GET /api/v1/bus-owner/travel-policy
+func (h *TripDetailsHandler) GetTravelPolicy(c *gin.Context) {
+	busOwner, ok := h.getBusOwner(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.detailsService.GetTravelPolicy(busOwner.ID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get travel policy")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to get travel policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"travel_policy": policy})
+}
+
+// UpdateTravelPolicy replaces the bus owner's luggage policy
+// PUT /api/v1/bus-owner/travel-policy
+func (h *TripDetailsHandler) UpdateTravelPolicy(c *gin.Context) {
+	busOwner, ok := h.getBusOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateOperatorTravelPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	policy, err := h.detailsService.UpdateTravelPolicy(busOwner.ID, &req)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to update travel policy")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to update travel policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Travel policy updated", "travel_policy": policy})
+}
+
+func (h *TripDetailsHandler) getBusOwner(c *gin.Context) (*models.BusOwner, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return nil, false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return nil, false
+	}
+	return busOwner, true
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TripETAHandler serves arrival estimates at the stops of active trips
+type TripETAHandler struct {
+	etaService *services.TripETAService
+	logger     *logrus.Logger
+}
+
+// NewTripETAHandler creates a new TripETAHandler
+func NewTripETAHandler(etaService *services.TripETAService, logger *logrus.Logger) *TripETAHandler {
+	return &TripETAHandler{etaService: etaService, logger: logger}
+}
+
+// GetETA returns when the bus is expected at each stop of an active trip, or only at stop_id
+// GET /api/v1/active-trips/:id/eta?stop_id=...
+func (h *TripETAHandler) GetETA(c *gin.Context) {
+	activeTripID := c.Param("id")
+	if _, err := uuid.Parse(activeTripID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_id", "message": "Invalid active trip ID"})
+		return
+	}
+
+	eta, err := h.etaService.GetETA(activeTripID)
+	switch {
+	case errors.Is(err, services.ErrActiveTripNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Active trip not found"})
+		return
+	case errors.Is(err, services.ErrTripNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": "trip_not_active", "message": err.Error()})
+		return
+	case errors.Is(err, services.ErrETAUnavailable):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "eta_unavailable", "message": err.Error()})
+		return
+	case err != nil:
+		h.logger.WithError(err).WithField("active_trip_id", activeTripID).Error("Failed to estimate trip arrivals")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "eta_failed", "message": "Failed to estimate arrival times"})
+		return
+	}
+
+	if stopID := c.Query("stop_id"); stopID != "" {
+		stop := eta.Stop(stopID)
+		if stop == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "stop_not_on_trip", "message": "This trip does not call at the stop"})
+			return
+		}
+		eta.Stops = append(eta.Stops[:0:0], *stop)
+	}
+	c.JSON(http.StatusOK, eta)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TripMessageHandler handles per-trip message threads between bus owners and staff.
+// The same endpoints are mounted under the owner's scheduled trips and the staff trip routes.
+type TripMessageHandler struct {
+	tripMessageService *services.TripMessageService
+}
+
+// NewTripMessageHandler creates a new TripMessageHandler
+func NewTripMessageHandler(tripMessageService *services.TripMessageService) *TripMessageHandler {
+	return &TripMessageHandler{
+		tripMessageService: tripMessageService,
+	}
+}
+
+// GetTripMessages returns a page of the trip's thread, newest first
+// GET /api/v1/scheduled-trips/:id/messages?before=<RFC3339>&limit=50
+// GET /api/v1/staff/trips/:id/messages
+func (h *TripMessageHandler) GetTripMessages(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var before *time.Time
+	if raw := c.Query("before"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_before", "message": "before must be an RFC3339 timestamp"})
+			return
+		}
+		before = &parsed
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_limit", "message": "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+
+	thread, err := h.tripMessageService.GetThread(c.Param("id"), userCtx.UserID.String(), before, limit)
+	if err != nil {
+		h.respondTripMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, thread)
+}
+
+// SendTripMessage posts a message to the trip's thread
+// POST /api/v1/scheduled-trips/:id/messages
+// POST /api/v1/staff/trips/:id/messages
+func (h *TripMessageHandler) SendTripMessage(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.SendTripMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	message, err := h.tripMessageService.SendMessage(c.Param("id"), userCtx.UserID.String(), req.Body)
+	if err != nil {
+		h.respondTripMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// MarkTripMessagesRead records that the caller has read the thread up to now
+// POST /api/v1/scheduled-trips/:id/messages/read
+// POST /api/v1/staff/trips/:id/messages/read
+func (h *TripMessageHandler) MarkTripMessagesRead(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	readAt, err := h.tripMessageService.MarkRead(c.Param("id"), userCtx.UserID.String())
+	if err != nil {
+		h.respondTripMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"last_read_at": readAt})
+}
+
+func (h *TripMessageHandler) respondTripMessageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTripThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "trip_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrNotTripParticipant):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not_trip_participant", "message": err.Error()})
+	default:
+		log.Printf("ERROR: Trip message request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Trip message request failed"})
+	}
+}
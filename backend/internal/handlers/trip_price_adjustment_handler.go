@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TripPriceAdjustmentHandler handles owner surcharges and discounts on their trips
+type TripPriceAdjustmentHandler struct {
+	adjustmentService *services.TripPriceAdjustmentService
+	busOwnerRepo      *database.BusOwnerRepository
+	logger            *logrus.Logger
+}
+
+// NewTripPriceAdjustmentHandler creates a new TripPriceAdjustmentHandler
+func NewTripPriceAdjustmentHandler(
+	adjustmentService *services.TripPriceAdjustmentService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *TripPriceAdjustmentHandler {
+	return &TripPriceAdjustmentHandler{
+		adjustmentService: adjustmentService,
+		busOwnerRepo:      busOwnerRepo,
+		logger:            logger,
+	}
+}
+
+// GetAdjustments lists the owner's current and upcoming adjustments
+// GET /api/v1/bus-owner/price-adjustments?trip_id=
+func (h *TripPriceAdjustmentHandler) GetAdjustments(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	var tripID *string
+	if id := c.Query("trip_id"); id != "" {
+		tripID = &id
+	}
+	adjustments, err := h.adjustmentService.List(busOwnerID, tripID)
+	if err != nil {
+		h.respondAdjustmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"adjustments": adjustments})
+}
+
+// ApplyAdjustment puts a surcharge or discount on a set of the owner's trips
+// POST /api/v1/bus-owner/price-adjustments
+func (h *TripPriceAdjustmentHandler) ApplyAdjustment(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+	userCtx, _ := middleware.GetUserContext(c)
+
+	var req models.ApplyTripPriceAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	results, err := h.adjustmentService.Apply(busOwnerID, userCtx.UserID.String(), &req)
+	if err != nil {
+		h.respondAdjustmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Price adjustment applied. Seats already held or booked keep their price.",
+		"trips":   results,
+	})
+}
+
+// RevokeAdjustment ends an adjustment; the trip's seats go back to their usual price
+// DELETE /api/v1/bus-owner/price-adjustments/:id
+func (h *TripPriceAdjustmentHandler) RevokeAdjustment(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adjustmentService.Revoke(busOwnerID, c.Param("id")); err != nil {
+		h.respondAdjustmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price adjustment removed"})
+}
+
+func (h *TripPriceAdjustmentHandler) respondAdjustmentError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrPriceAdjustmentTripNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "trip_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrPriceAdjustmentTripNotEditable):
+		c.JSON(http.StatusConflict, gin.H{"error": "trip_not_editable", "message": err.Error()})
+	case errors.Is(err, services.ErrPriceAdjustmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Price adjustment not found or already ended"})
+	default:
+		h.logger.WithError(err).Error("Trip price adjustment request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to process price adjustment"})
+	}
+}
+
+func (h *TripPriceAdjustmentHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
@@ -207,6 +207,9 @@ func (h *TripScheduleHandler) CreateSchedule(c *gin.Context) {
 
 	// Verify permit ownership
 	permit, err := h.permitRepo.GetByID(req.PermitID)
+	if err == nil && permit.DeletedAt != nil {
+		err = sql.ErrNoRows // Deleted permits cannot take new schedules
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Permit not found"})
@@ -230,6 +233,9 @@ func (h *TripScheduleHandler) CreateSchedule(c *gin.Context) {
 	// Verify bus ownership if bus is specified
 	if req.BusID != nil {
 		bus, err := h.busRepo.GetByID(*req.BusID)
+		if err == nil && bus.DeletedAt != nil {
+			err = sql.ErrNoRows // Deleted buss cannot take new schedules
+		}
 		if err != nil {
 			if err == sql.ErrNoRows {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Bus not found"})
@@ -587,6 +593,9 @@ func (h *TripScheduleHandler) CreateTimetable(c *gin.Context) {
 
 	// Verify custom route ownership
 	customRoute, err := h.routeRepo.GetByID(req.CustomRouteID)
+	if err == nil && customRoute.DeletedAt != nil {
+		err = sql.ErrNoRows // Deleted routes cannot take new schedules
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Custom route not found"})
@@ -605,6 +614,9 @@ func (h *TripScheduleHandler) CreateTimetable(c *gin.Context) {
 	// If provided, validate ownership only (no fare/seat limits enforcement)
 	if req.PermitID != nil && *req.PermitID != "" {
 		permit, err := h.permitRepo.GetByID(*req.PermitID)
+		if err == nil && permit.DeletedAt != nil {
+			err = sql.ErrNoRows // Deleted permits cannot take new schedules
+		}
 		if err != nil {
 			if err == sql.ErrNoRows {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Permit not found"})
@@ -1,14 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/middleware"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
 )
 
 // TripSeatHandler handles trip seats and manual bookings API endpoints
@@ -18,6 +21,10 @@ type TripSeatHandler struct {
 	tripRepo          *database.ScheduledTripRepository
 	busOwnerRepo      *database.BusOwnerRepository
 	routeRepo         *database.BusOwnerRouteRepository
+	seatLayoutRepo    *database.BusSeatLayoutRepository
+	priceAdjustments  *services.TripPriceAdjustmentService
+	seatPricing       *services.SeatPricingService
+	waitlist          *services.TripWaitlistService
 }
 
 // NewTripSeatHandler creates a new TripSeatHandler
@@ -27,6 +34,10 @@ func NewTripSeatHandler(
 	tripRepo *database.ScheduledTripRepository,
 	busOwnerRepo *database.BusOwnerRepository,
 	routeRepo *database.BusOwnerRouteRepository,
+	seatLayoutRepo *database.BusSeatLayoutRepository,
+	priceAdjustments *services.TripPriceAdjustmentService,
+	seatPricing *services.SeatPricingService,
+	waitlist *services.TripWaitlistService,
 ) *TripSeatHandler {
 	return &TripSeatHandler{
 		tripSeatRepo:      tripSeatRepo,
@@ -34,6 +45,10 @@ func NewTripSeatHandler(
 		tripRepo:          tripRepo,
 		busOwnerRepo:      busOwnerRepo,
 		routeRepo:         routeRepo,
+		seatLayoutRepo:    seatLayoutRepo,
+		priceAdjustments:  priceAdjustments,
+		seatPricing:       seatPricing,
+		waitlist:          waitlist,
 	}
 }
 
@@ -73,6 +88,18 @@ func (h *TripSeatHandler) GetTripSeats(c *gin.Context) {
 		return
 	}
 
+	// Price seats with any surcharge or discount the owner put on the trip
+	adjustment, err := h.priceAdjustments.ActiveForTrip(tripID)
+	if err != nil {
+		fmt.Printf("Error getting trip price adjustment: %v\n", err)
+	}
+	if adjustment != nil {
+		for i := range seats {
+			price := adjustment.Apply(seats[i].SeatPrice)
+			seats[i].AdjustedPrice = &price
+		}
+	}
+
 	// Get summary
 	summary, err := h.tripSeatRepo.GetSummary(tripID)
 	if err != nil {
@@ -80,11 +107,74 @@ func (h *TripSeatHandler) GetTripSeats(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"seats":   seats,
-		"summary": summary,
+		"seats":            seats,
+		"summary":          summary,
+		"layout":           h.getTripLayoutMetadata(tripID),
+		"price_adjustment": adjustment,
 	})
 }
 
+// GetSeatAvailability returns which seats can be booked between two stops of the trip. Seats
+// booked only for another part of the route are flagged segment_free but are not bookable yet.
+// GET /api/v1/scheduled-trips/:id/seats/availability?from_stop=&to_stop=
+func (h *TripSeatHandler) GetSeatAvailability(c *gin.Context) {
+	tripID := c.Param("id")
+	if _, err := uuid.Parse(tripID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trip ID"})
+		return
+	}
+	if _, err := h.tripRepo.GetByID(tripID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trip"})
+		return
+	}
+
+	stops, err := h.tripSeatRepo.GetTripStops(tripID)
+	if err != nil {
+		fmt.Printf("Error getting trip stops: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get route stops"})
+		return
+	}
+	from, to, err := models.ResolveSegment(stops, c.Query("from_stop"), c.Query("to_stop"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	seats, err := h.tripSeatRepo.GetSegmentSeats(tripID)
+	if err != nil {
+		fmt.Printf("Error getting segment seats: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trip seats"})
+		return
+	}
+	c.JSON(http.StatusOK, models.NewSegmentSeatAvailability(tripID, seats, from, to))
+}
+
+// getTripLayoutMetadata returns rendering hints for the trip's seat layout (nil if no layout assigned)
+func (h *TripSeatHandler) getTripLayoutMetadata(tripID string) *models.SeatLayoutMetadata {
+	trip, err := h.tripRepo.GetByID(tripID)
+	if err != nil || trip.SeatLayoutID == nil {
+		return nil
+	}
+
+	layoutID, err := uuid.Parse(*trip.SeatLayoutID)
+	if err != nil {
+		return nil
+	}
+
+	template, err := h.seatLayoutRepo.GetTemplateByID(context.Background(), layoutID)
+	if err != nil {
+		fmt.Printf("Error getting seat layout metadata: %v\n", err)
+		return nil
+	}
+
+	metadata := template.ResolvedLayoutMetadata()
+	return &metadata
+}
+
 // GetTripSeatSummary returns seat availability summary for a trip
 // GET /api/v1/scheduled-trips/:id/seats/summary
 func (h *TripSeatHandler) GetTripSeatSummary(c *gin.Context) {
@@ -148,6 +238,9 @@ func (h *TripSeatHandler) CreateTripSeats(c *gin.Context) {
 	}
 
 	fmt.Printf("Created %d trip seats for trip %s by user %s\n", count, tripID, userCtx.UserID)
+	if err := h.seatPricing.RepriceTrip(tripID); err != nil {
+		fmt.Printf("Failed to apply pricing rules to trip %s: %v\n", tripID, err)
+	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":     "Trip seats created successfully",
@@ -266,6 +359,10 @@ func (h *TripSeatHandler) UnblockSeats(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock seats"})
 		return
 	}
+	if count > 0 {
+		// Unblocked seats go to the trip's waitlist first
+		h.waitlist.PromoteTripAsync(tripID)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":         "Seats unblocked successfully",
@@ -665,3 +762,53 @@ func (h *TripSeatHandler) SearchManualBookingsByPhone(c *gin.Context) {
 
 	c.JSON(http.StatusOK, bookings)
 }
+
+// ===========================================================================
+// ADMIN: SEAT COUNTER REPAIR
+// ===========================================================================
+
+// RepairSeatCounters recomputes the cached available/booked seat counters on scheduled trips
+// from trip_seats and reports how far off they were
+// POST /api/v1/admin/trip-seats/repair-counters
+func (h *TripSeatHandler) RepairSeatCounters(c *gin.Context) {
+	var req models.RepairSeatCountersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := req.Filter()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	drift, checked, err := h.tripSeatRepo.FindSeatCounterDrift(filter)
+	if err != nil {
+		fmt.Printf("Error checking seat counters: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check seat counters"})
+		return
+	}
+
+	result := models.SeatCounterRepairResult{
+		DryRun:       req.DryRun,
+		TripsChecked: checked,
+		TripsDrifted: len(drift),
+		Drift:        drift,
+	}
+
+	if !req.DryRun && len(drift) > 0 {
+		tripIDs := make([]string, len(drift))
+		for i, d := range drift {
+			tripIDs[i] = d.ScheduledTripID
+		}
+		if err := h.tripSeatRepo.SyncSeatCounters(tripIDs); err != nil {
+			fmt.Printf("Error repairing seat counters: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to repair seat counters"})
+			return
+		}
+		result.TripsRepaired = len(tripIDs)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+	"github.com/smarttransit/sms-auth-backend/pkg/validator"
+)
+
+// TripSharingHandler handles emergency contacts and live trip sharing
+type TripSharingHandler struct {
+	tripSharingService *services.TripSharingService
+	phoneValidator     *validator.PhoneValidator
+}
+
+// NewTripSharingHandler creates a new TripSharingHandler
+func NewTripSharingHandler(
+	tripSharingService *services.TripSharingService,
+	phoneValidator *validator.PhoneValidator,
+) *TripSharingHandler {
+	return &TripSharingHandler{
+		tripSharingService: tripSharingService,
+		phoneValidator:     phoneValidator,
+	}
+}
+
+// GetEmergencyContacts lists the passenger's emergency contacts
+// GET /api/v1/user/emergency-contacts
+func (h *TripSharingHandler) GetEmergencyContacts(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	contacts, err := h.tripSharingService.ListContacts(userCtx.UserID.String())
+	if err != nil {
+		log.Printf("ERROR: Failed to list emergency contacts for user %s: %v", userCtx.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "contacts_retrieval_failed", "message": "Failed to retrieve emergency contacts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"contacts": contacts})
+}
+
+// AddEmergencyContact registers a new emergency contact
+// POST /api/v1/user/emergency-contacts
+func (h *TripSharingHandler) AddEmergencyContact(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateEmergencyContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	phone, err := h.phoneValidator.Validate(req.Phone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_phone", "message": err.Error()})
+		return
+	}
+	if phone == userCtx.Phone {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_phone", "message": "You cannot add your own number as an emergency contact"})
+		return
+	}
+	req.Phone = phone
+
+	contact, err := h.tripSharingService.AddContact(userCtx.UserID.String(), &req)
+	if err != nil {
+		h.respondTripSharingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Emergency contact added", "contact": contact})
+}
+
+// UpdateEmergencyContact edits a contact or toggles auto-sharing
+// PUT /api/v1/user/emergency-contacts/:id
+func (h *TripSharingHandler) UpdateEmergencyContact(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.UpdateEmergencyContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	if req.Phone != nil {
+		phone, err := h.phoneValidator.Validate(*req.Phone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_phone", "message": err.Error()})
+			return
+		}
+		if phone == userCtx.Phone {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_phone", "message": "You cannot add your own number as an emergency contact"})
+			return
+		}
+		req.Phone = &phone
+	}
+
+	contact, err := h.tripSharingService.UpdateContact(userCtx.UserID.String(), c.Param("id"), &req)
+	if err != nil {
+		h.respondTripSharingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Emergency contact updated", "contact": contact})
+}
+
+// DeleteEmergencyContact removes a contact
+// DELETE /api/v1/user/emergency-contacts/:id
+func (h *TripSharingHandler) DeleteEmergencyContact(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	if err := h.tripSharingService.DeleteContact(userCtx.UserID.String(), c.Param("id")); err != nil {
+		h.respondTripSharingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Emergency contact removed"})
+}
+
+// ShareTrip creates (or returns) a public tracking link for a booked trip
+// POST /api/v1/trip-sharing
+func (h *TripSharingHandler) ShareTrip(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateTripShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	token, link, err := h.tripSharingService.ShareTrip(userCtx.UserID.String(), req.ScheduledTripID, req.NotifyContacts)
+	if err != nil {
+		h.respondTripSharingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":             token.Token,
+		"tracking_url":      link,
+		"scheduled_trip_id": token.ScheduledTripID,
+		"expires_at":        token.ExpiresAt,
+	})
+}
+
+// StopSharingTrip revokes the passenger's tracking links for a trip
+// DELETE /api/v1/trip-sharing/:scheduled_trip_id
+func (h *TripSharingHandler) StopSharingTrip(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	if err := h.tripSharingService.StopSharing(userCtx.UserID.String(), c.Param("scheduled_trip_id")); err != nil {
+		h.respondTripSharingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trip sharing stopped"})
+}
+
+// GetSharedTrip returns live tracking data behind a share token (public, no auth)
+// GET /api/v1/track/:token
+func (h *TripSharingHandler) GetSharedTrip(c *gin.Context) {
+	view, err := h.tripSharingService.GetSharedTrip(c.Param("token"))
+	if err != nil {
+		h.respondTripSharingError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, view)
+}
+
+func (h *TripSharingHandler) respondTripSharingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrEmergencyContactNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "contact_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrTooManyEmergencyContacts):
+		c.JSON(http.StatusConflict, gin.H{"error": "too_many_contacts", "message": err.Error()})
+	case errors.Is(err, services.ErrNotBookedOnTrip):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not_booked_on_trip", "message": err.Error()})
+	case errors.Is(err, services.ErrShareLinkInvalid):
+		c.JSON(http.StatusNotFound, gin.H{"error": "share_link_invalid", "message": err.Error()})
+	default:
+		log.Printf("ERROR: Trip sharing request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Trip sharing request failed"})
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TripWaitingRoomHandler handles trip waiting rooms: owner settings under the scheduled trip
+// routes and the passenger queue under the booking routes
+type TripWaitingRoomHandler struct {
+	waitingRoomService *services.TripWaitingRoomService
+	busOwnerRepo       *database.BusOwnerRepository
+	logger             *logrus.Logger
+}
+
+// NewTripWaitingRoomHandler creates a new TripWaitingRoomHandler
+func NewTripWaitingRoomHandler(
+	waitingRoomService *services.TripWaitingRoomService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *TripWaitingRoomHandler {
+	return &TripWaitingRoomHandler{
+		waitingRoomService: waitingRoomService,
+		busOwnerRepo:       busOwnerRepo,
+		logger:             logger,
+	}
+}
+
+// GetWaitingRoom returns the waiting room settings of the owner's trip
+// GET /api/v1/scheduled-trips/:id/waiting-room
+func (h *TripWaitingRoomHandler) GetWaitingRoom(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	room, err := h.waitingRoomService.GetRoom(c.Param("id"), busOwnerID)
+	if err != nil {
+		h.respondWaitingRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, room)
+}
+
+// UpdateWaitingRoom enables, disables or tunes the waiting room of the owner's trip
+// PUT /api/v1/scheduled-trips/:id/waiting-room
+func (h *TripWaitingRoomHandler) UpdateWaitingRoom(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTripWaitingRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	room, err := h.waitingRoomService.ConfigureRoom(c.Param("id"), busOwnerID, &req)
+	if err != nil {
+		h.respondWaitingRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, room)
+}
+
+// JoinQueue issues the caller a queue token for the trip, or reports that no queue is needed
+// POST /api/v1/booking/trips/:trip_id/queue
+func (h *TripWaitingRoomHandler) JoinQueue(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	status, err := h.waitingRoomService.JoinQueue(c.Param("trip_id"), userCtx.UserID)
+	if err != nil {
+		h.respondWaitingRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetQueueStatus returns the caller's queue position; clients poll it until admitted
+// GET /api/v1/booking/queue/:token
+func (h *TripWaitingRoomHandler) GetQueueStatus(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	status, err := h.waitingRoomService.GetQueueStatus(c.Param("token"), userCtx.UserID)
+	if err != nil {
+		h.respondWaitingRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *TripWaitingRoomHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+func (h *TripWaitingRoomHandler) respondWaitingRoomError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrWaitingRoomTripNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "trip_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrQueueTokenNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "queue_token_not_found", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Waiting room request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Waiting room request failed"})
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// TripWaitlistHandler handles the waitlists of sold-out trips: passengers join and follow their
+// place, owners see how deep a trip's waitlist is
+type TripWaitlistHandler struct {
+	waitlistService *services.TripWaitlistService
+	busOwnerRepo    *database.BusOwnerRepository
+	logger          *logrus.Logger
+}
+
+// NewTripWaitlistHandler creates a new TripWaitlistHandler
+func NewTripWaitlistHandler(
+	waitlistService *services.TripWaitlistService,
+	busOwnerRepo *database.BusOwnerRepository,
+	logger *logrus.Logger,
+) *TripWaitlistHandler {
+	return &TripWaitlistHandler{
+		waitlistService: waitlistService,
+		busOwnerRepo:    busOwnerRepo,
+		logger:          logger,
+	}
+}
+
+// JoinWaitlist puts the caller on the trip's waitlist
+// POST /api/v1/scheduled-trips/:id/waitlist
+func (h *TripWaitlistHandler) JoinWaitlist(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.JoinWaitlistRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+			return
+		}
+	}
+
+	status, err := h.waitlistService.Join(c.Param("id"), userCtx.UserID, &req)
+	if err != nil {
+		h.respondWaitlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetMyWaitlistStatus returns the caller's waitlist entry for the trip and place in line;
+// clients poll it until offered seats
+// GET /api/v1/scheduled-trips/:id/waitlist/me
+func (h *TripWaitlistHandler) GetMyWaitlistStatus(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	status, err := h.waitlistService.GetStatus(c.Param("id"), userCtx.UserID)
+	if err != nil {
+		h.respondWaitlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// LeaveWaitlist takes the caller off the trip's waitlist
+// DELETE /api/v1/scheduled-trips/:id/waitlist/me
+func (h *TripWaitlistHandler) LeaveWaitlist(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	if err := h.waitlistService.Leave(c.Param("id"), userCtx.UserID); err != nil {
+		h.respondWaitlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left the waitlist"})
+}
+
+// GetWaitlist returns how deep the waitlist of the owner's trip is
+// GET /api/v1/scheduled-trips/:id/waitlist
+func (h *TripWaitlistHandler) GetWaitlist(c *gin.Context) {
+	busOwnerID, ok := h.resolveBusOwnerID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.waitlistService.GetSummary(c.Param("id"), busOwnerID)
+	if err != nil {
+		h.respondWaitlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *TripWaitlistHandler) resolveBusOwnerID(c *gin.Context) (string, bool) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return "", false
+	}
+
+	busOwner, err := h.busOwnerRepo.GetByUserID(userCtx.UserID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Bus owner profile not found"})
+			return "", false
+		}
+		h.logger.WithError(err).Error("Failed to fetch bus owner")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to fetch profile"})
+		return "", false
+	}
+	return busOwner.ID, true
+}
+
+func (h *TripWaitlistHandler) respondWaitlistError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrWaitlistTripNotFound), errors.Is(err, services.ErrWaitlistTripClosed):
+		c.JSON(http.StatusNotFound, gin.H{"error": "trip_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrWaitlistEntryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "waitlist_entry_not_found", "message": err.Error()})
+	case errors.Is(err, services.ErrWaitlistSeatsAvailable):
+		c.JSON(http.StatusConflict, gin.H{"error": "seats_available", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Waitlist request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Waitlist request failed"})
+	}
+}
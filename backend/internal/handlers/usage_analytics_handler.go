@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// UsageAnalyticsHandler serves the product team's feature adoption and retention reports
+type UsageAnalyticsHandler struct {
+	usageService *services.UsageAnalyticsService
+	logger       *logrus.Logger
+}
+
+// NewUsageAnalyticsHandler creates a new UsageAnalyticsHandler
+func NewUsageAnalyticsHandler(usageService *services.UsageAnalyticsService, logger *logrus.Logger) *UsageAnalyticsHandler {
+	return &UsageAnalyticsHandler{
+		usageService: usageService,
+		logger:       logger,
+	}
+}
+
+// GetUsage returns feature or endpoint adoption by role
+// GET /api/v1/admin/analytics/usage?from=YYYY-MM-DD&to=YYYY-MM-DD&group_by=feature|event&role=&feature=
+func (h *UsageAnalyticsHandler) GetUsage(c *gin.Context) {
+	var req models.UsageReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	report, err := h.usageService.GetUsageReport(&req)
+	if err != nil {
+		h.respondError(c, err, "Failed to build usage report")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetRetention returns weekly cohort retention for one feature
+// GET /api/v1/admin/analytics/usage/retention?feature=&from=YYYY-MM-DD&to=YYYY-MM-DD&role=
+func (h *UsageAnalyticsHandler) GetRetention(c *gin.Context) {
+	var req models.UsageRetentionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	retention, err := h.usageService.GetRetention(&req)
+	if err != nil {
+		h.respondError(c, err, "Failed to build usage retention")
+		return
+	}
+
+	c.JSON(http.StatusOK, retention)
+}
+
+func (h *UsageAnalyticsHandler) respondError(c *gin.Context, err error, message string) {
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+		return
+	}
+	h.logger.WithError(err).Error(message)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": message})
+}
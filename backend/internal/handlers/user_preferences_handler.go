@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// UserPreferencesHandler lets users read and change their language, notification and seat preferences
+type UserPreferencesHandler struct {
+	preferencesService *services.UserPreferencesService
+	logger             *logrus.Logger
+}
+
+// NewUserPreferencesHandler creates a new UserPreferencesHandler
+func NewUserPreferencesHandler(preferencesService *services.UserPreferencesService, logger *logrus.Logger) *UserPreferencesHandler {
+	return &UserPreferencesHandler{
+		preferencesService: preferencesService,
+		logger:             logger,
+	}
+}
+
+// GetPreferences returns the user's preferences, or the defaults if they never saved any
+// GET /api/v1/user/preferences
+func (h *UserPreferencesHandler) GetPreferences(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	prefs, err := h.preferencesService.Get(userCtx.UserID)
+	if err != nil {
+		h.logger.WithError(err).WithField("user_id", userCtx.UserID).Error("Failed to get user preferences")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to get preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences changes the user's preferences; omitted fields keep their value
+// PUT /api/v1/user/preferences
+func (h *UserPreferencesHandler) UpdatePreferences(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.UpdateUserPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	prefs, err := h.preferencesService.Update(userCtx.UserID, &req)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+			return
+		}
+		h.logger.WithError(err).WithField("user_id", userCtx.UserID).Error("Failed to update user preferences")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to update preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/middleware"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/services"
+)
+
+// WalletHandler handles passengers viewing and topping up their wallet
+type WalletHandler struct {
+	walletService *services.WalletService
+	logger        *logrus.Logger
+}
+
+// NewWalletHandler creates a new WalletHandler
+func NewWalletHandler(walletService *services.WalletService, logger *logrus.Logger) *WalletHandler {
+	return &WalletHandler{walletService: walletService, logger: logger}
+}
+
+// GetWallet returns the passenger's balance with a page of their ledger
+// GET /api/v1/wallet?limit=&offset=
+func (h *WalletHandler) GetWallet(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+	limit, offset := payoutPagination(c)
+
+	page, err := h.walletService.GetWallet(userCtx.UserID, limit, offset)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// ListTopUps lists the passenger's top-ups, newest first
+// GET /api/v1/wallet/top-ups?limit=&offset=
+func (h *WalletHandler) ListTopUps(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+	limit, offset := payoutPagination(c)
+
+	topUps, err := h.walletService.ListTopUps(userCtx.UserID, limit, offset)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"top_ups": topUps, "limit": limit, "offset": offset})
+}
+
+// TopUp starts a PAYable payment that adds money to the passenger's wallet. The wallet is
+// credited when the payment webhook reports the payment made.
+// POST /api/v1/wallet/top-ups
+func (h *WalletHandler) TopUp(c *gin.Context) {
+	userCtx, exists := middleware.GetUserContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User not authenticated"})
+		return
+	}
+
+	var req models.WalletTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": err.Error()})
+		return
+	}
+
+	resp, err := h.walletService.InitiateTopUp(userCtx.UserID, userCtx.Phone, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *WalletHandler) respondError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": validationErr.Message})
+	case errors.Is(err, services.ErrWalletTopUpUnavailable), errors.Is(err, services.ErrPaymentUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "payment_unavailable", "message": err.Error()})
+	default:
+		h.logger.WithError(err).Error("Wallet request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Wallet request failed"})
+	}
+}
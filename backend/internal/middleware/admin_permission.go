@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// AdminPermissionChecker reports whether an admin holds a permission; implemented by
+// services.AdminPermissionService
+type AdminPermissionChecker interface {
+	HasAdminPermission(adminID uuid.UUID, email string, permission models.AdminPermission) (bool, error)
+}
+
+// RequireAdminPermission creates a middleware that only lets through admins whose role grants
+// permission. Admin tokens carry the email in place of a phone number. Lookup failures are
+// refused, unlike maintenance checks, since the permission guards admin-only data.
+func RequireAdminPermission(checker AdminPermissionChecker, permission models.AdminPermission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userCtx, exists := GetUserContext(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"message": "User context not found. Auth middleware may not be applied.",
+				"code":    "MISSING_USER_CONTEXT",
+			})
+			c.Abort()
+			return
+		}
+
+		allowed := false
+		if userCtx.HasRole("admin") {
+			var err error
+			allowed, err = checker.HasAdminPermission(userCtx.UserID, userCtx.Phone, permission)
+			if err != nil {
+				log.Printf("AUTH ERROR: Admin permission check failed - User: %s, Permission: %s, Error: %v", userCtx.UserID, permission, err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "internal_error",
+					"message": "Failed to check permissions",
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		if !allowed {
+			log.Printf("AUTH FORBIDDEN: Missing admin permission %s - User: %s, Path: %s", permission, userCtx.UserID, c.Request.URL.Path)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":               "forbidden",
+				"message":             "You don't have permission to access this resource",
+				"code":                "INSUFFICIENT_PERMISSIONS",
+				"required_permission": permission,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAdminPermissionChecker struct {
+	granted map[string][]models.AdminPermission // By email
+	err     error
+}
+
+func (f *fakeAdminPermissionChecker) HasAdminPermission(adminID uuid.UUID, email string, permission models.AdminPermission) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	for _, granted := range f.granted[email] {
+		if granted == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRequireAdminPermission(t *testing.T) {
+	jwtService := setupTestJWTService()
+	router := setupTestRouter(jwtService)
+	checker := &fakeAdminPermissionChecker{granted: map[string][]models.AdminPermission{
+		"finance@smarttransit.lk": {models.AdminPermPaymentsManage},
+		"support@smarttransit.lk": {models.AdminPermUsersSupport},
+	}}
+	router.GET("/refunds", AuthMiddleware(jwtService), RequireAdminPermission(checker, models.AdminPermPaymentsManage), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	request := func(email string, roles ...string) *httptest.ResponseRecorder {
+		token, err := jwtService.GenerateAccessToken(uuid.New(), email, roles, true)
+		require.NoError(t, err)
+		req := httptest.NewRequest("GET", "/refunds", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request("finance@smarttransit.lk", "admin").Code)
+
+	w := request("support@smarttransit.lk", "admin")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "payments.manage")
+
+	assert.Equal(t, http.StatusForbidden, request("finance@smarttransit.lk", "passenger").Code, "only admin tokens qualify")
+
+	checker.err = errors.New("database down")
+	assert.Equal(t, http.StatusInternalServerError, request("finance@smarttransit.lk", "admin").Code, "lookup failures are refused")
+}
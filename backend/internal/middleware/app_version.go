@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// AppVersionHeader carries the passenger app's release version, e.g. "1.4.2"
+const AppVersionHeader = "X-App-Version"
+
+// AppPlatformHeader carries the app's platform ("android" or "ios")
+const AppPlatformHeader = "X-Device-Type"
+
+// AppVersionChecker judges an app version; implemented by services.AppConfigService.
+// Returns nil when the client is not a gated app.
+type AppVersionChecker interface {
+	CheckAppVersion(platform, version string) (*models.AppVersionStatus, error)
+}
+
+// AppVersionMiddleware rejects requests from app versions below the platform minimum or on
+// the blocked list with 426 upgrade_required. Requests without version headers (web, admin
+// dashboards) pass through, as do the exempt paths, so a blocked app can still load its
+// config and show the upgrade screen. Lookup failures fail open.
+func AppVersionMiddleware(checker AppVersionChecker, exemptPaths ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return func(c *gin.Context) {
+		version := c.GetHeader(AppVersionHeader)
+		if version == "" || exempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		status, err := checker.CheckAppVersion(c.GetHeader(AppPlatformHeader), version)
+		if err != nil {
+			log.Printf("ERROR: Failed to check app version %q: %v", version, err)
+			c.Next()
+			return
+		}
+
+		if status != nil && status.UpgradeRequired {
+			c.JSON(http.StatusUpgradeRequired, gin.H{
+				"error":                 "upgrade_required",
+				"message":               "This version of the app is no longer supported. Please update to continue.",
+				"reason":                status.Reason,
+				"min_supported_version": status.MinVersion,
+				"latest_version":        status.LatestVersion,
+				"store_url":             status.StoreURL,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
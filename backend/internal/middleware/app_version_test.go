@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeVersionChecker struct {
+	policy *models.AppPlatformConfig
+	err    error
+}
+
+func (f *fakeVersionChecker) CheckAppVersion(platform, version string) (*models.AppVersionStatus, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if _, ok := models.ParseAppPlatform(platform); !ok {
+		return nil, nil
+	}
+	status := f.policy.CheckVersion(version)
+	return &status, nil
+}
+
+func TestAppVersionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	checker := &fakeVersionChecker{policy: &models.AppPlatformConfig{Platform: models.AppPlatformAndroid, MinSupportedVersion: "2.0.0"}}
+	router := gin.New()
+	router.Use(AppVersionMiddleware(checker, "/app/config"))
+	router.GET("/search", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/app/config", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(path, platform, version string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if platform != "" {
+			req.Header.Set(AppPlatformHeader, platform)
+		}
+		if version != "" {
+			req.Header.Set(AppVersionHeader, version)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUpgradeRequired, request("/search", "android", "1.9.4"))
+	assert.Equal(t, http.StatusOK, request("/search", "android", "2.0.1"))
+	assert.Equal(t, http.StatusOK, request("/search", "", ""), "no version headers")
+	assert.Equal(t, http.StatusOK, request("/search", "web", "1.0.0"), "ungated platform")
+	assert.Equal(t, http.StatusOK, request("/app/config", "android", "1.9.4"), "exempt path")
+
+	checker.err = errors.New("settings unavailable")
+	assert.Equal(t, http.StatusOK, request("/search", "android", "1.9.4"), "fails open")
+}
@@ -21,6 +21,18 @@ type UserContext struct {
 	ProfileCompleted bool      `json:"profile_completed"`
 }
 
+// HasRole reports whether the user's token carries any of roles
+func (u UserContext) HasRole(roles ...string) bool {
+	for _, required := range roles {
+		for _, role := range u.Roles {
+			if role == required {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // AuthMiddleware creates a middleware that validates JWT tokens
 func AuthMiddleware(jwtService *jwt.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -87,6 +99,12 @@ func AuthMiddleware(jwtService *jwt.Service) gin.HandlerFunc {
 			return
 		}
 
+		// Use current roles where configured, so role changes apply before the token expires.
+		// If they cannot be loaded the token's own claims still hold.
+		if err := jwtService.EnrichClaims(claims); err != nil {
+			log.Printf("AUTH WARNING: Using token claims, current claims unavailable - User: %s, Error: %v", claims.UserID, err)
+		}
+
 		// Create user context (UserID is already uuid.UUID type)
 		userContext := UserContext{
 			UserID:           claims.UserID,
@@ -103,7 +121,10 @@ func AuthMiddleware(jwtService *jwt.Service) gin.HandlerFunc {
 	}
 }
 
-// RequireRole creates a middleware that checks if user has required role
+// RequireRole creates a middleware that only lets through users whose token carries any of
+// roles, e.g. RequireRole("bus_owner", "admin"). With claim enrichment the token's roles are
+// replaced by the user's current ones; without it a role granted after sign-in takes effect
+// once the client refreshes its token.
 func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user context
@@ -118,25 +139,13 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 			return
 		}
 
-		// Check if user has any of the required roles
-		hasRole := false
-		for _, requiredRole := range roles {
-			for _, userRole := range userCtx.Roles {
-				if userRole == requiredRole {
-					hasRole = true
-					break
-				}
-			}
-			if hasRole {
-				break
-			}
-		}
-
-		if !hasRole {
+		if !userCtx.HasRole(roles...) {
+			log.Printf("AUTH FORBIDDEN: Missing role %v - User: %s, Roles: %v, Path: %s", roles, userCtx.UserID, userCtx.Roles, c.Request.URL.Path)
 			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "forbidden",
-				"message": "You don't have permission to access this resource",
-				"code":    "INSUFFICIENT_PERMISSIONS",
+				"error":          "forbidden",
+				"message":        "You don't have permission to access this resource",
+				"code":           "INSUFFICIENT_PERMISSIONS",
+				"required_roles": roles,
 			})
 			c.Abort()
 			return
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -322,6 +323,7 @@ func TestRequireRole(t *testing.T) {
 
 		assert.Equal(t, http.StatusForbidden, w.Code)
 		assert.Contains(t, w.Body.String(), "INSUFFICIENT_PERMISSIONS")
+		assert.Contains(t, w.Body.String(), `"required_roles":["admin"]`)
 	})
 
 	t.Run("Multiple roles allowed", func(t *testing.T) {
@@ -361,6 +363,71 @@ func TestRequireRole(t *testing.T) {
 	})
 }
 
+// rolesEnricher replaces token roles with fixed current roles, or fails
+type rolesEnricher struct {
+	roles []string
+	err   error
+}
+
+func (e rolesEnricher) EnrichClaims(claims *jwt.Claims) error {
+	if e.err != nil {
+		return e.err
+	}
+	claims.Roles = e.roles
+	return nil
+}
+
+func TestAuthMiddleware_ClaimsEnrichment(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Role granted after sign-in", func(t *testing.T) {
+		jwtService := setupTestJWTService()
+		jwtService.SetClaimsEnricher(rolesEnricher{roles: []string{"passenger", "driver"}})
+		token, err := jwtService.GenerateAccessToken(userID, "+94712345678", []string{"passenger"}, true)
+		require.NoError(t, err)
+
+		router := setupTestRouter(jwtService)
+		router.GET("/driver-only", AuthMiddleware(jwtService), RequireRole("driver"), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "success"})
+		})
+
+		req := httptest.NewRequest("GET", "/driver-only", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Current roles unavailable", func(t *testing.T) {
+		jwtService := setupTestJWTService()
+		jwtService.SetClaimsEnricher(rolesEnricher{err: errors.New("database down")})
+		token, err := jwtService.GenerateAccessToken(userID, "+94712345678", []string{"driver"}, true)
+		require.NoError(t, err)
+
+		router := setupTestRouter(jwtService)
+		router.GET("/driver-only", AuthMiddleware(jwtService), RequireRole("driver"), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "success"})
+		})
+
+		req := httptest.NewRequest("GET", "/driver-only", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "falls back to the token's roles")
+	})
+}
+
+func TestUserContext_HasRole(t *testing.T) {
+	userCtx := UserContext{Roles: []string{"passenger", "bus_owner"}}
+	assert.True(t, userCtx.HasRole("bus_owner"))
+	assert.True(t, userCtx.HasRole("admin", "bus_owner"), "any of the roles")
+	assert.False(t, userCtx.HasRole("admin"))
+	assert.False(t, userCtx.HasRole(), "no roles required is not a match")
+	assert.False(t, UserContext{}.HasRole("passenger"))
+}
+
 func TestRequireProfileComplete(t *testing.T) {
 	jwtService := setupTestJWTService()
 	router := setupTestRouter(jwtService)
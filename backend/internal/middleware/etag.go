@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETagMiddleware adds content-hash ETags to successful GET responses and answers 304 Not
+// Modified when the request's If-None-Match already holds the current ETag, so apps polling
+// trips, seat maps and lounges only download a payload when it has changed. The handler still
+// runs in full; only the response body is saved. Responses without a Cache-Control header get
+// "no-cache" so clients revalidate before reusing them.
+func ETagMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		writer := &etagWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.status != http.StatusOK {
+			writer.flush()
+			return
+		}
+
+		sum := sha256.Sum256(writer.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		header := writer.ResponseWriter.Header()
+		header.Set("ETag", etag)
+		if header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", "no-cache")
+		}
+
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			header.Del("Content-Length")
+			writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+			writer.ResponseWriter.WriteHeaderNow()
+			return
+		}
+		writer.flush()
+	}
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag. Weak validators
+// (W/"...") match their strong equivalent, as If-None-Match uses weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter holds back the status and body until the ETag is known
+type etagWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *etagWriter) WriteHeaderNow() {}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *etagWriter) Status() int {
+	return w.status
+}
+
+func (w *etagWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *etagWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// flush sends the saved status and body to the client
+func (w *etagWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
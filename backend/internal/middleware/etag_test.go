@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETagMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	payload := gin.H{"trips": []string{"a", "b"}}
+
+	router := gin.New()
+	router.Use(ETagMiddleware())
+	router.GET("/trips", func(c *gin.Context) { c.JSON(http.StatusOK, payload) })
+	router.GET("/missing", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{"error": "not_found"}) })
+	router.GET("/cached", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, payload)
+	})
+
+	request := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := request("/trips", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "no-cache", first.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"trips":["a","b"]}`, first.Body.String())
+
+	notModified := request("/trips", etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+	assert.Equal(t, etag, notModified.Header().Get("ETag"))
+
+	assert.Equal(t, http.StatusNotModified, request("/trips", `"other", W/`+etag).Code, "weak and listed validators match")
+	assert.Equal(t, http.StatusOK, request("/trips", `"stale"`).Code)
+
+	payload = gin.H{"trips": []string{"a"}}
+	changed := request("/trips", etag)
+	assert.Equal(t, http.StatusOK, changed.Code, "changed payload is sent again")
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+
+	missing := request("/missing", "*")
+	assert.Equal(t, http.StatusNotFound, missing.Code, "errors are never turned into 304")
+	assert.Empty(t, missing.Header().Get("ETag"))
+	assert.Contains(t, missing.Body.String(), "not_found")
+
+	assert.Equal(t, "public, max-age=300", request("/cached", "").Header().Get("Cache-Control"))
+}
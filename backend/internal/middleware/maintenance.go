@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/jwt"
+)
+
+// MaintenanceChecker reports the current maintenance mode; implemented by services.AppConfigService
+type MaintenanceChecker interface {
+	MaintenanceStatus() (*models.AppMaintenance, error)
+}
+
+// MaintenanceMiddleware makes the API read-only while maintenance mode is on. GET, HEAD and
+// OPTIONS requests are always served, as are the read-only or must-keep-working POST paths in
+// allowedPaths (e.g. trip search, token refresh and payment webhooks). Other requests get 503
+// with Retry-After, unless they carry a valid admin access token so admins can keep working
+// and switch maintenance off. Lookup failures fail open.
+func MaintenanceMiddleware(checker MaintenanceChecker, jwtService *jwt.Service, allowedPaths ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(allowedPaths))
+	for _, path := range allowedPaths {
+		allowed[path] = true
+	}
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if allowed[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		maintenance, err := checker.MaintenanceStatus()
+		if err != nil {
+			log.Printf("ERROR: Failed to check maintenance mode: %v", err)
+			c.Next()
+			return
+		}
+		if maintenance == nil || !maintenance.Enabled || isAdminRequest(c, jwtService) {
+			c.Next()
+			return
+		}
+
+		message := maintenance.Message
+		if message == "" {
+			message = "SmartTransit is undergoing maintenance. Bookings and other changes are paused; please try again shortly."
+		}
+		c.Header("Retry-After", strconv.Itoa(maintenance.RetryAfterSeconds))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":               "maintenance_mode",
+			"message":             message,
+			"retry_after_seconds": maintenance.RetryAfterSeconds,
+		})
+		c.Abort()
+	}
+}
+
+// isAdminRequest reports whether the request carries a valid access token with the admin role
+func isAdminRequest(c *gin.Context, jwtService *jwt.Service) bool {
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return false
+	}
+	claims, err := jwtService.ValidateAccessToken(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return false
+	}
+	for _, role := range claims.Roles {
+		if role == "admin" {
+			return true
+		}
+	}
+	return false
+}
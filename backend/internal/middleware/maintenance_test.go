@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMaintenanceChecker struct {
+	maintenance models.AppMaintenance
+}
+
+func (f *fakeMaintenanceChecker) MaintenanceStatus() (*models.AppMaintenance, error) {
+	return &f.maintenance, nil
+}
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtService := jwt.NewService("access-secret", "refresh-secret", time.Hour, time.Hour)
+	checker := &fakeMaintenanceChecker{maintenance: models.AppMaintenance{Enabled: true, RetryAfterSeconds: 120}}
+
+	router := gin.New()
+	router.Use(MaintenanceMiddleware(checker, jwtService, "/search"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/bookings", ok)
+	router.POST("/bookings", ok)
+	router.POST("/search", ok)
+
+	request := func(method, path string, roles ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if len(roles) > 0 {
+			token, err := jwtService.GenerateAccessToken(uuid.New(), "+94771234567", roles, true)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	blocked := request(http.MethodPost, "/bookings")
+	assert.Equal(t, http.StatusServiceUnavailable, blocked.Code)
+	assert.Equal(t, "120", blocked.Header().Get("Retry-After"))
+	assert.Contains(t, blocked.Body.String(), "maintenance_mode")
+
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/bookings").Code, "reads stay available")
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/search").Code, "allowed read-only POST")
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/bookings", "admin").Code, "admins are exempt")
+	assert.Equal(t, http.StatusServiceUnavailable, request(http.MethodPost, "/bookings", "passenger").Code)
+
+	checker.maintenance.Enabled = false
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/bookings").Code)
+}
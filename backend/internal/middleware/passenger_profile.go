@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// RequirePassengerProfile blocks a feature until the passenger has completed the profile
+// steps it needs (e.g. lounge bookings need a name). The response carries the missing
+// steps and a hint so the app can take the passenger straight to the right screen.
+// Must be used after AuthMiddleware to have userCtx available
+func RequirePassengerProfile(passengerRepo *database.PassengerRepository, feature models.ProfileFeature) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userCtx, exists := GetUserContext(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"message": "User context not found",
+			})
+			c.Abort()
+			return
+		}
+
+		passenger, err := passengerRepo.GetPassengerByUserID(userCtx.UserID)
+		if err != nil {
+			log.Printf("ERROR: Failed to get passenger for profile check: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "profile_check_failed",
+				"message": "Failed to check passenger profile",
+			})
+			c.Abort()
+			return
+		}
+
+		// A missing passenger row is treated as an empty profile
+		if err := passenger.CheckFeatureAccess(feature); err != nil {
+			var incomplete *models.ProfileIncompleteError
+			if errors.As(err, &incomplete) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":         "profile_incomplete",
+					"message":       "Complete your profile to use this feature",
+					"code":          incomplete.Code(),
+					"feature":       incomplete.Feature,
+					"missing_steps": incomplete.Missing,
+					"next_step":     incomplete.Hint(),
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// TenantAPIKeyHeader carries a white-label app's tenant API key
+const TenantAPIKeyHeader = "X-Tenant-Key"
+
+const tenantContextKey = "tenant"
+
+// tenantCacheTTL bounds how long a revoked key or changed tenant configuration keeps working
+const tenantCacheTTL = time.Minute
+
+// TenantLookup resolves a tenant API key; implemented by database.TenantRepository
+type TenantLookup interface {
+	GetTenantByAPIKey(rawKey string) (*models.Tenant, error)
+}
+
+type cachedTenant struct {
+	tenant    *models.Tenant
+	expiresAt time.Time
+}
+
+// TenantMiddleware resolves the tenant a request is made for from the X-Tenant-Key header.
+// Requests without the header belong to the core SmartTransit tenant; an unknown or revoked key is rejected.
+func TenantMiddleware(lookup TenantLookup) gin.HandlerFunc {
+	var (
+		mu    sync.Mutex
+		cache = map[string]cachedTenant{}
+	)
+
+	return func(c *gin.Context) {
+		rawKey := c.GetHeader(TenantAPIKeyHeader)
+		if rawKey == "" {
+			c.Set(tenantContextKey, models.DefaultTenant())
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		mu.Lock()
+		entry, ok := cache[rawKey]
+		mu.Unlock()
+
+		if !ok || now.After(entry.expiresAt) {
+			tenant, err := lookup.GetTenantByAPIKey(rawKey)
+			if err != nil {
+				log.Printf("ERROR: Failed to resolve tenant API key: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "tenant_lookup_failed",
+					"message": "Failed to resolve tenant",
+				})
+				c.Abort()
+				return
+			}
+
+			entry = cachedTenant{tenant: tenant, expiresAt: now.Add(tenantCacheTTL)}
+			mu.Lock()
+			for key, cached := range cache {
+				if now.After(cached.expiresAt) {
+					delete(cache, key)
+				}
+			}
+			cache[rawKey] = entry
+			mu.Unlock()
+		}
+
+		if entry.tenant == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "invalid_tenant_key",
+				"message": "Tenant API key is invalid or has been revoked",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(tenantContextKey, entry.tenant)
+		c.Next()
+	}
+}
+
+// GetTenant returns the tenant resolved by TenantMiddleware, or SmartTransit if the middleware did not run
+func GetTenant(c *gin.Context) *models.Tenant {
+	if value, exists := c.Get(tenantContextKey); exists {
+		if tenant, ok := value.(*models.Tenant); ok {
+			return tenant
+		}
+	}
+	return models.DefaultTenant()
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTenantLookup struct {
+	tenants map[string]*models.Tenant
+	calls   int
+}
+
+func (f *fakeTenantLookup) GetTenantByAPIKey(rawKey string) (*models.Tenant, error) {
+	f.calls++
+	return f.tenants[rawKey], nil
+}
+
+func newTenantRouter(lookup TenantLookup) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TenantMiddleware(lookup))
+	router.GET("/whoami", func(c *gin.Context) {
+		c.String(http.StatusOK, GetTenant(c).Slug)
+	})
+	return router
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	lookup := &fakeTenantLookup{tenants: map[string]*models.Tenant{
+		"good-key": {ID: "tenant-1", Slug: "lanka-express", IsActive: true},
+	}}
+	router := newTenantRouter(lookup)
+
+	request := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		if key != "" {
+			req.Header.Set(TenantAPIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("no key is the default tenant", func(t *testing.T) {
+		w := request("")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "smarttransit", w.Body.String())
+	})
+
+	t.Run("valid key resolves and is cached", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			w := request("good-key")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "lanka-express", w.Body.String())
+		}
+		assert.Equal(t, 1, lookup.calls)
+	})
+
+	t.Run("unknown key is rejected", func(t *testing.T) {
+		w := request("bad-key")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_tenant_key")
+	})
+}
+
+func TestTenant_ScopeID(t *testing.T) {
+	assert.Nil(t, models.DefaultTenant().ScopeID())
+	assert.Equal(t, "tenant-1", *(&models.Tenant{ID: "tenant-1"}).ScopeID())
+}
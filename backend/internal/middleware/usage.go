@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// usageFeatureKey is the gin context key handlers set to re-tag a request's feature
+const usageFeatureKey = "usage_feature"
+
+// UsageRecorder takes finished requests for usage analytics; implemented by
+// services.UsageAnalyticsService. Record must not block.
+type UsageRecorder interface {
+	Record(req models.UsageRequest)
+}
+
+// UsageMiddleware reports each routed request to the recorder once it has been handled, so
+// the role comes from the group's auth middleware and the status from the handler.
+func UsageMiddleware(recorder UsageRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if !models.IsTrackedUsageRequest(c.Request.Method, route) {
+			return
+		}
+
+		req := models.UsageRequest{
+			Method:     c.Request.Method,
+			Route:      route,
+			Feature:    c.GetString(usageFeatureKey),
+			AppVersion: c.GetHeader(AppVersionHeader),
+			Platform:   c.GetHeader(AppPlatformHeader),
+			StatusCode: c.Writer.Status(),
+			At:         time.Now(),
+		}
+		if userCtx, ok := GetUserContext(c); ok {
+			req.SignedIn = true
+			req.UserID = userCtx.UserID.String()
+			req.Roles = userCtx.Roles
+		}
+		recorder.Record(req)
+	}
+}
+
+// TagUsageFeature counts the current request toward a feature other than its route's, for
+// endpoints that serve several features depending on the payload
+func TagUsageFeature(c *gin.Context, feature string) {
+	c.Set(usageFeatureKey, feature)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUsageRecorder struct {
+	requests []models.UsageRequest
+}
+
+func (f *fakeUsageRecorder) Record(req models.UsageRequest) {
+	f.requests = append(f.requests, req)
+}
+
+func TestUsageMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &fakeUsageRecorder{}
+	userID := uuid.New()
+
+	router := gin.New()
+	router.Use(UsageMiddleware(recorder))
+	signedIn := func(c *gin.Context) {
+		c.Set(UserContextKey, UserContext{UserID: userID, Roles: []string{"passenger"}})
+	}
+	router.POST("/booking/intent", signedIn, func(c *gin.Context) {
+		TagUsageFeature(c, models.UsageFeatureLoungeAddOn)
+		c.Status(http.StatusCreated)
+	})
+	router.GET("/search/popular", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodPost, "/booking/intent", nil)
+	req.Header.Set(AppVersionHeader, "1.4.2")
+	req.Header.Set(AppPlatformHeader, "ios")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/search/popular", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	require.Len(t, recorder.requests, 2, "unrouted requests are not recorded")
+	first := recorder.requests[0]
+	assert.Equal(t, "/booking/intent", first.Route)
+	assert.Equal(t, models.UsageFeatureLoungeAddOn, first.Feature)
+	assert.True(t, first.SignedIn)
+	assert.Equal(t, userID.String(), first.UserID)
+	assert.Equal(t, []string{"passenger"}, first.Roles)
+	assert.Equal(t, "1.4.2", first.AppVersion)
+	assert.Equal(t, "ios", first.Platform)
+	assert.Equal(t, http.StatusCreated, first.StatusCode)
+
+	second := recorder.requests[1]
+	assert.False(t, second.SignedIn)
+	assert.Empty(t, second.Feature)
+}
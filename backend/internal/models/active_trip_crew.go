@@ -0,0 +1,160 @@
+package models
+
+import (
+	"time"
+)
+
+// ActiveTripCrewRole is the seat a staff member holds on a running trip
+type ActiveTripCrewRole string
+
+const (
+	ActiveTripCrewDriver    ActiveTripCrewRole = "driver"
+	ActiveTripCrewConductor ActiveTripCrewRole = "conductor"
+)
+
+// ActiveTripAction is something a crew device can do on a running trip
+type ActiveTripAction string
+
+const (
+	ActiveTripActionView             ActiveTripAction = "view"
+	ActiveTripActionUpdateLocation   ActiveTripAction = "update_location"
+	ActiveTripActionUpdatePassengers ActiveTripAction = "update_passengers"
+	ActiveTripActionEndTrip          ActiveTripAction = "end_trip"
+	ActiveTripActionReportIncident   ActiveTripAction = "report_incident"
+	ActiveTripActionHandover         ActiveTripAction = "handover"
+)
+
+// CrewSessionLiveWindow is how recently a crew device must have been seen to count as online.
+// Location updates arrive every few seconds, so two minutes of silence means the device is gone.
+const CrewSessionLiveWindow = 2 * time.Minute
+
+// ActiveTripCrewSession tracks a crew member's device on a running trip
+type ActiveTripCrewSession struct {
+	ActiveTripID string             `json:"active_trip_id" db:"active_trip_id"`
+	StaffID      string             `json:"staff_id" db:"staff_id"`
+	Role         ActiveTripCrewRole `json:"role" db:"role"`
+	LastSeenAt   time.Time          `json:"last_seen_at" db:"last_seen_at"`
+	LeftAt       *time.Time         `json:"left_at,omitempty" db:"left_at"`
+	IsLive       bool               `json:"is_live" db:"-"`
+}
+
+// Live reports whether the session's device has been seen within CrewSessionLiveWindow
+func (s *ActiveTripCrewSession) Live(now time.Time) bool {
+	return s.LeftAt == nil && now.Sub(s.LastSeenAt) <= CrewSessionLiveWindow
+}
+
+// ActiveTripHandover records a mid-route crew change
+type ActiveTripHandover struct {
+	ID           string             `json:"id" db:"id"`
+	ActiveTripID string             `json:"active_trip_id" db:"active_trip_id"`
+	Role         ActiveTripCrewRole `json:"role" db:"role"`
+	FromStaffID  *string            `json:"from_staff_id,omitempty" db:"from_staff_id"`
+	ToStaffID    string             `json:"to_staff_id" db:"to_staff_id"`
+	HandedOverBy string             `json:"handed_over_by" db:"handed_over_by"`
+	Reason       *string            `json:"reason,omitempty" db:"reason"`
+	Latitude     *float64           `json:"latitude,omitempty" db:"latitude"`
+	Longitude    *float64           `json:"longitude,omitempty" db:"longitude"`
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`
+}
+
+// ActiveTripHandoverRequest hands the driver or conductor seat to another staff member
+type ActiveTripHandoverRequest struct {
+	Role       ActiveTripCrewRole `json:"role" binding:"required,oneof=driver conductor"`
+	NewStaffID string             `json:"new_staff_id" binding:"required,uuid"`
+	Reason     *string            `json:"reason,omitempty" binding:"omitempty,max=500"`
+	Latitude   *float64           `json:"latitude,omitempty"`
+	Longitude  *float64           `json:"longitude,omitempty"`
+}
+
+// ActiveTripCrewView is an active trip as seen from one crew member's device
+type ActiveTripCrewView struct {
+	*ActiveTrip
+	MyRole         ActiveTripCrewRole      `json:"my_role"`
+	AllowedActions []ActiveTripAction      `json:"allowed_actions"`
+	Crew           []ActiveTripCrewSession `json:"crew_sessions"`
+}
+
+// CrewRoles returns the seats staffID holds on the trip; a staff member can hold both
+func (a *ActiveTrip) CrewRoles(staffID string) []ActiveTripCrewRole {
+	roles := []ActiveTripCrewRole{}
+	if staffID == "" {
+		return roles
+	}
+	if a.DriverID == staffID {
+		roles = append(roles, ActiveTripCrewDriver)
+	}
+	if a.ConductorID != nil && *a.ConductorID == staffID {
+		roles = append(roles, ActiveTripCrewConductor)
+	}
+	return roles
+}
+
+// IsCrew reports whether staffID is the trip's driver or conductor
+func (a *ActiveTrip) IsCrew(staffID string) bool {
+	return len(a.CrewRoles(staffID)) > 0
+}
+
+// PrimaryCrewRole returns the role staffID acts under, preferring driver when they hold both
+func (a *ActiveTrip) PrimaryCrewRole(staffID string) (ActiveTripCrewRole, bool) {
+	roles := a.CrewRoles(staffID)
+	if len(roles) == 0 {
+		return "", false
+	}
+	return roles[0], true
+}
+
+// AllowedActions returns what staffID may do on the trip right now.
+// Drivers own location updates and ending the trip; conductors own the passenger count.
+// When the crew member who owns an action has no live device (or there is no conductor),
+// the other crew member picks it up so the trip never gets stuck on a dead phone.
+func (a *ActiveTrip) AllowedActions(staffID string, sessions []ActiveTripCrewSession, now time.Time) []ActiveTripAction {
+	roles := a.CrewRoles(staffID)
+	if len(roles) == 0 {
+		return []ActiveTripAction{}
+	}
+
+	isDriver, isConductor := false, false
+	for _, role := range roles {
+		switch role {
+		case ActiveTripCrewDriver:
+			isDriver = true
+		case ActiveTripCrewConductor:
+			isConductor = true
+		}
+	}
+
+	conductorOnline := a.ConductorID != nil && crewMemberLive(sessions, *a.ConductorID, now)
+	driverOnline := crewMemberLive(sessions, a.DriverID, now)
+
+	actions := []ActiveTripAction{ActiveTripActionView}
+	if isDriver || !driverOnline {
+		actions = append(actions, ActiveTripActionUpdateLocation)
+	}
+	if isConductor || !conductorOnline {
+		actions = append(actions, ActiveTripActionUpdatePassengers)
+	}
+	if isDriver || !driverOnline {
+		actions = append(actions, ActiveTripActionEndTrip)
+	}
+	return append(actions, ActiveTripActionReportIncident, ActiveTripActionHandover)
+}
+
+// Can reports whether staffID may perform action on the trip right now
+func (a *ActiveTrip) Can(staffID string, action ActiveTripAction, sessions []ActiveTripCrewSession, now time.Time) bool {
+	for _, allowed := range a.AllowedActions(staffID, sessions, now) {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// crewMemberLive reports whether staffID has a live session among sessions
+func crewMemberLive(sessions []ActiveTripCrewSession, staffID string, now time.Time) bool {
+	for i := range sessions {
+		if sessions[i].StaffID == staffID && sessions[i].Live(now) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func crewTrip(conductorID *string) *ActiveTrip {
+	return &ActiveTrip{ID: "trip-1", DriverID: "driver-1", ConductorID: conductorID, Status: ActiveTripStatusInTransit}
+}
+
+func crewSession(staffID string, role ActiveTripCrewRole, lastSeen time.Time) ActiveTripCrewSession {
+	return ActiveTripCrewSession{ActiveTripID: "trip-1", StaffID: staffID, Role: role, LastSeenAt: lastSeen}
+}
+
+func TestActiveTrip_CrewRoles(t *testing.T) {
+	conductor := "conductor-1"
+	trip := crewTrip(&conductor)
+
+	assert.Equal(t, []ActiveTripCrewRole{ActiveTripCrewDriver}, trip.CrewRoles("driver-1"))
+	assert.Equal(t, []ActiveTripCrewRole{ActiveTripCrewConductor}, trip.CrewRoles("conductor-1"))
+	assert.Empty(t, trip.CrewRoles("someone-else"))
+	assert.Empty(t, trip.CrewRoles(""))
+	assert.False(t, trip.IsCrew("someone-else"))
+
+	same := "driver-1"
+	trip.ConductorID = &same
+	role, ok := trip.PrimaryCrewRole("driver-1")
+	assert.True(t, ok)
+	assert.Equal(t, ActiveTripCrewDriver, role, "driver wins when one person holds both seats")
+}
+
+func TestActiveTrip_AllowedActionsBothDevicesOnline(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	conductor := "conductor-1"
+	trip := crewTrip(&conductor)
+	sessions := []ActiveTripCrewSession{
+		crewSession("driver-1", ActiveTripCrewDriver, now.Add(-10*time.Second)),
+		crewSession("conductor-1", ActiveTripCrewConductor, now.Add(-30*time.Second)),
+	}
+
+	assert.Equal(t, []ActiveTripAction{
+		ActiveTripActionView, ActiveTripActionUpdateLocation, ActiveTripActionEndTrip,
+		ActiveTripActionReportIncident, ActiveTripActionHandover,
+	}, trip.AllowedActions("driver-1", sessions, now))
+
+	assert.Equal(t, []ActiveTripAction{
+		ActiveTripActionView, ActiveTripActionUpdatePassengers,
+		ActiveTripActionReportIncident, ActiveTripActionHandover,
+	}, trip.AllowedActions("conductor-1", sessions, now))
+
+	assert.Empty(t, trip.AllowedActions("someone-else", sessions, now))
+}
+
+func TestActiveTrip_AllowedActionsFallBackWhenDeviceOffline(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	conductor := "conductor-1"
+	trip := crewTrip(&conductor)
+
+	// Driver's phone went quiet three minutes ago
+	sessions := []ActiveTripCrewSession{
+		crewSession("driver-1", ActiveTripCrewDriver, now.Add(-3*time.Minute)),
+		crewSession("conductor-1", ActiveTripCrewConductor, now),
+	}
+	assert.True(t, trip.Can("conductor-1", ActiveTripActionUpdateLocation, sessions, now))
+	assert.True(t, trip.Can("conductor-1", ActiveTripActionEndTrip, sessions, now))
+	assert.False(t, trip.Can("driver-1", ActiveTripActionUpdatePassengers, sessions, now))
+
+	// Conductor signed out of the trip
+	left := now.Add(-time.Minute)
+	sessions = []ActiveTripCrewSession{
+		crewSession("driver-1", ActiveTripCrewDriver, now),
+		{ActiveTripID: "trip-1", StaffID: "conductor-1", Role: ActiveTripCrewConductor, LastSeenAt: now, LeftAt: &left},
+	}
+	assert.True(t, trip.Can("driver-1", ActiveTripActionUpdatePassengers, sessions, now))
+	assert.False(t, trip.Can("conductor-1", ActiveTripActionUpdateLocation, sessions, now))
+}
+
+func TestActiveTrip_AllowedActionsWithoutConductor(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	trip := crewTrip(nil)
+	sessions := []ActiveTripCrewSession{crewSession("driver-1", ActiveTripCrewDriver, now)}
+
+	assert.True(t, trip.Can("driver-1", ActiveTripActionUpdateLocation, sessions, now))
+	assert.True(t, trip.Can("driver-1", ActiveTripActionUpdatePassengers, sessions, now), "driver counts passengers on conductor-less trips")
+	assert.True(t, trip.Can("driver-1", ActiveTripActionHandover, sessions, now))
+}
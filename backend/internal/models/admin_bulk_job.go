@@ -0,0 +1,231 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminBulkJobType is the kind of work an admin bulk job does
+type AdminBulkJobType string
+
+const (
+	AdminBulkJobLoungeApproval   AdminBulkJobType = "lounge_approval"   // Approve or reject pending lounges
+	AdminBulkJobUserExport       AdminBulkJobType = "user_export"       // CSV export of users
+	AdminBulkJobNotificationSend AdminBulkJobType = "notification_send" // Push notification to many users
+)
+
+// IsValid reports whether t is a known job type
+func (t AdminBulkJobType) IsValid() bool {
+	switch t {
+	case AdminBulkJobLoungeApproval, AdminBulkJobUserExport, AdminBulkJobNotificationSend:
+		return true
+	}
+	return false
+}
+
+// AdminBulkJobStatus is the processing state of an admin bulk job
+type AdminBulkJobStatus string
+
+const (
+	AdminBulkJobQueued    AdminBulkJobStatus = "queued"
+	AdminBulkJobRunning   AdminBulkJobStatus = "running"
+	AdminBulkJobCompleted AdminBulkJobStatus = "completed" // Finished; individual items may still have failed
+	AdminBulkJobFailed    AdminBulkJobStatus = "failed"    // The job itself could not run
+)
+
+// IsFinished reports whether the job has stopped processing
+func (s AdminBulkJobStatus) IsFinished() bool {
+	return s == AdminBulkJobCompleted || s == AdminBulkJobFailed
+}
+
+// Outcomes of a single item in a bulk job
+const (
+	BulkItemSucceeded = "succeeded"
+	BulkItemSkipped   = "skipped"
+	BulkItemFailed    = "failed"
+)
+
+// AdminBulkJobMaxFailures caps the failed items kept on the job for the status view; the
+// results download always has every item
+const AdminBulkJobMaxFailures = 100
+
+// AdminBulkJobParams holds the input of a bulk job. Which fields apply depends on the type.
+type AdminBulkJobParams struct {
+	// lounge_approval
+	LoungeIDs []string `json:"lounge_ids,omitempty"`
+	Action    string   `json:"action,omitempty"` // "approve" or "reject"
+	Reason    string   `json:"reason,omitempty"` // Rejection reason
+
+	// user_export, and notification_send without user_ids: users by role and status
+	Role   string `json:"role,omitempty"`
+	Status string `json:"status,omitempty"`
+
+	// notification_send
+	UserIDs []string `json:"user_ids,omitempty"`
+	Title   string   `json:"title,omitempty"`
+	Body    string   `json:"body,omitempty"`
+}
+
+func (p AdminBulkJobParams) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *AdminBulkJobParams) Scan(value interface{}) error {
+	if value == nil {
+		*p = AdminBulkJobParams{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed for AdminBulkJobParams")
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+// BulkJobItemResult is the outcome of one item of a bulk job
+type BulkJobItemResult struct {
+	ItemID  string `json:"item_id"`
+	Outcome string `json:"outcome"` // succeeded, skipped or failed
+	Message string `json:"message,omitempty"`
+}
+
+// BulkJobItemResults is a JSONB list of item results
+type BulkJobItemResults []BulkJobItemResult
+
+func (r BulkJobItemResults) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *BulkJobItemResults) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed for BulkJobItemResults")
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// AdminBulkJob is a bulk admin operation processed in the background (admin_bulk_jobs table).
+// Admins submit it, poll its progress and download the results CSV once it has finished.
+type AdminBulkJob struct {
+	ID              uuid.UUID          `json:"id" db:"id"`
+	JobType         AdminBulkJobType   `json:"job_type" db:"job_type"`
+	Status          AdminBulkJobStatus `json:"status" db:"status"`
+	Params          AdminBulkJobParams `json:"params" db:"params"`
+	TotalItems      int                `json:"total_items" db:"total_items"`
+	ProcessedItems  int                `json:"processed_items" db:"processed_items"`
+	SucceededItems  int                `json:"succeeded_items" db:"succeeded_items"`
+	FailedItems     int                `json:"failed_items" db:"failed_items"`
+	Failures        BulkJobItemResults `json:"failures,omitempty" db:"failures"` // First AdminBulkJobMaxFailures failed items
+	ErrorMessage    *string            `json:"error_message,omitempty" db:"error_message"`
+	ResultFileName  *string            `json:"result_file_name,omitempty" db:"result_file_name"`
+	CreatedByUserID uuid.UUID          `json:"created_by_user_id" db:"created_by_user_id"`
+	StartedAt       *time.Time         `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt     *time.Time         `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt       time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// CreateAdminBulkJobRequest submits a bulk job
+type CreateAdminBulkJobRequest struct {
+	JobType AdminBulkJobType   `json:"job_type" binding:"required"`
+	Params  AdminBulkJobParams `json:"params"`
+}
+
+// Validate checks the params needed by the job type and normalizes them. maxItems caps the
+// explicit ID lists.
+func (r *CreateAdminBulkJobRequest) Validate(maxItems int) error {
+	if !r.JobType.IsValid() {
+		return &ValidationError{Message: "job_type must be one of lounge_approval, user_export, notification_send"}
+	}
+	p := &r.Params
+	p.Reason = strings.TrimSpace(p.Reason)
+	p.Title = strings.TrimSpace(p.Title)
+	p.Body = strings.TrimSpace(p.Body)
+
+	switch r.JobType {
+	case AdminBulkJobLoungeApproval:
+		if p.Action != "approve" && p.Action != "reject" {
+			return &ValidationError{Message: "action must be approve or reject"}
+		}
+		ids, err := normalizeBulkIDs(p.LoungeIDs, "lounge_ids", maxItems)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return &ValidationError{Message: "lounge_ids is required"}
+		}
+		p.LoungeIDs = ids
+	case AdminBulkJobNotificationSend:
+		if p.Title == "" || p.Body == "" {
+			return &ValidationError{Message: "title and body are required"}
+		}
+		if len(p.Title) > 100 || len(p.Body) > 500 {
+			return &ValidationError{Message: "title must be at most 100 and body at most 500 characters"}
+		}
+		ids, err := normalizeBulkIDs(p.UserIDs, "user_ids", maxItems)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 && p.Role == "" {
+			return &ValidationError{Message: "user_ids or role is required"}
+		}
+		p.UserIDs = ids
+	}
+	return nil
+}
+
+// normalizeBulkIDs checks every ID is a UUID and removes duplicates, keeping the order
+func normalizeBulkIDs(ids []string, field string, maxItems int) ([]string, error) {
+	seen := make(map[string]bool, len(ids))
+	normalized := make([]string, 0, len(ids))
+	for _, raw := range ids {
+		id, err := uuid.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, &ValidationError{Message: field + " must contain valid UUIDs"}
+		}
+		if seen[id.String()] {
+			continue
+		}
+		seen[id.String()] = true
+		normalized = append(normalized, id.String())
+	}
+	if maxItems > 0 && len(normalized) > maxItems {
+		return nil, &ValidationError{Message: field + " exceeds the bulk job limit"}
+	}
+	return normalized, nil
+}
+
+// UserExportCSVHeader is the header row of a user_export results file
+var UserExportCSVHeader = []string{
+	"user_id", "phone", "first_name", "last_name", "email", "roles", "status",
+	"profile_completed", "created_at", "last_login_at",
+}
+
+// UserExportCSVRecord formats a user as a user_export CSV row
+func UserExportCSVRecord(u *User) []string {
+	lastLogin := ""
+	if u.LastLoginAt.Valid {
+		lastLogin = u.LastLoginAt.Time.Format(time.RFC3339)
+	}
+	completed := "false"
+	if u.ProfileCompleted {
+		completed = "true"
+	}
+	return []string{
+		u.ID.String(), u.Phone, u.FirstName.String, u.LastName.String, u.Email.String,
+		strings.Join(u.Roles, ";"), u.Status, completed,
+		u.CreatedAt.Format(time.RFC3339), lastLogin,
+	}
+}
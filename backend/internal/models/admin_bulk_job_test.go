@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAdminBulkJobRequest_Validate(t *testing.T) {
+	const loungeID = "6f1c2f7e-3c1a-4d8e-9b1a-0c2d3e4f5a6b"
+
+	approval := CreateAdminBulkJobRequest{
+		JobType: AdminBulkJobLoungeApproval,
+		Params:  AdminBulkJobParams{Action: "approve", LoungeIDs: []string{loungeID, " " + loungeID, "0B7E3A52-1F0C-4E4B-8D6F-2A9C5E1D7B30"}},
+	}
+	require.NoError(t, approval.Validate(10))
+	assert.Equal(t, []string{loungeID, "0b7e3a52-1f0c-4e4b-8d6f-2a9c5e1d7b30"}, approval.Params.LoungeIDs, "IDs are normalized and deduplicated")
+	assert.Error(t, approval.Validate(1), "over the item limit")
+
+	cases := map[string]CreateAdminBulkJobRequest{
+		"unknown type":     {JobType: "purge_users"},
+		"no action":        {JobType: AdminBulkJobLoungeApproval, Params: AdminBulkJobParams{LoungeIDs: []string{loungeID}}},
+		"no lounges":       {JobType: AdminBulkJobLoungeApproval, Params: AdminBulkJobParams{Action: "reject"}},
+		"bad lounge id":    {JobType: AdminBulkJobLoungeApproval, Params: AdminBulkJobParams{Action: "approve", LoungeIDs: []string{"42"}}},
+		"no message":       {JobType: AdminBulkJobNotificationSend, Params: AdminBulkJobParams{Role: "passenger", Title: " "}},
+		"no recipients":    {JobType: AdminBulkJobNotificationSend, Params: AdminBulkJobParams{Title: "Strike", Body: "Services may be delayed"}},
+		"bad recipient id": {JobType: AdminBulkJobNotificationSend, Params: AdminBulkJobParams{Title: "Strike", Body: "Delays", UserIDs: []string{"x"}}},
+	}
+	for name, req := range cases {
+		assert.Error(t, req.Validate(10), name)
+	}
+
+	export := CreateAdminBulkJobRequest{JobType: AdminBulkJobUserExport, Params: AdminBulkJobParams{Role: "bus_owner"}}
+	assert.NoError(t, export.Validate(10))
+
+	byRole := CreateAdminBulkJobRequest{JobType: AdminBulkJobNotificationSend, Params: AdminBulkJobParams{Role: "passenger", Title: " Strike ", Body: "Services may be delayed"}}
+	require.NoError(t, byRole.Validate(10))
+	assert.Equal(t, "Strike", byRole.Params.Title)
+}
+
+func TestAdminBulkJobStatus_IsFinished(t *testing.T) {
+	assert.False(t, AdminBulkJobQueued.IsFinished())
+	assert.False(t, AdminBulkJobRunning.IsFinished())
+	assert.True(t, AdminBulkJobCompleted.IsFinished())
+	assert.True(t, AdminBulkJobFailed.IsFinished())
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminRole is the tier of an admin dashboard user; it decides which admin endpoints they reach
+type AdminRole string
+
+const (
+	AdminRoleSuperAdmin AdminRole = "super_admin" // Every permission, including managing admins
+	AdminRoleOperations AdminRole = "operations"  // Approvals, scheduling data, promotions
+	AdminRoleFinance    AdminRole = "finance"     // Payments, refunds, payouts
+	AdminRoleSupport    AdminRole = "support"     // Passenger support and call-center bookings
+)
+
+// AdminRoles lists the roles in order of privilege
+var AdminRoles = []AdminRole{AdminRoleSuperAdmin, AdminRoleOperations, AdminRoleFinance, AdminRoleSupport}
+
+// ValidAdminRole reports whether role is a known admin role
+func ValidAdminRole(role AdminRole) bool {
+	for _, known := range AdminRoles {
+		if role == known {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminPermission grants access to a group of admin endpoints
+type AdminPermission string
+
+const (
+	AdminPermAdminsView       AdminPermission = "admins.view"          // List admins, roles and their permissions
+	AdminPermAdminsManage     AdminPermission = "admins.manage"        // Create admins, change roles and role permissions
+	AdminPermSecurityManage   AdminPermission = "security.manage"      // QA test numbers, boarding QR signing keys
+	AdminPermApprovals        AdminPermission = "approvals.manage"     // Lounge, bus owner and staff approvals, staff documents
+	AdminPermDashboardView    AdminPermission = "dashboard.view"       // Dashboard stats and analytics
+	AdminPermUsersSupport     AdminPermission = "users.support"        // User session lookup
+	AdminPermCallCenter       AdminPermission = "bookings.call_center" // Call-center bookings
+	AdminPermPaymentsManage   AdminPermission = "payments.manage"      // Payment audit, disputes, refunds
+	AdminPermPayoutsManage    AdminPermission = "payouts.manage"       // Owner payouts and bank account verification
+	AdminPermOperationsManage AdminPermission = "operations.manage"    // Seat layouts and counters, blackouts, holidays, routes, fare compliance, maintenance
+	AdminPermPromotionsManage AdminPermission = "promotions.manage"    // Promo codes and bundle discounts
+	AdminPermReportsManage    AdminPermission = "reports.manage"       // Scheduled report emails
+	AdminPermBulkJobs         AdminPermission = "bulk_jobs.manage"     // Bulk approvals, exports and notification sends
+	AdminPermTenantsManage    AdminPermission = "tenants.manage"       // White-label tenants and their API keys
+)
+
+// AdminPermissions lists every permission
+var AdminPermissions = []AdminPermission{
+	AdminPermAdminsView, AdminPermAdminsManage, AdminPermSecurityManage, AdminPermApprovals,
+	AdminPermDashboardView, AdminPermUsersSupport, AdminPermCallCenter, AdminPermPaymentsManage,
+	AdminPermPayoutsManage, AdminPermOperationsManage, AdminPermPromotionsManage,
+	AdminPermReportsManage, AdminPermBulkJobs, AdminPermTenantsManage,
+}
+
+// SuperAdminOnlyPermissions cannot be granted to other roles, so only super admins can change
+// who holds which privileges
+var SuperAdminOnlyPermissions = []AdminPermission{AdminPermAdminsManage, AdminPermSecurityManage}
+
+// DefaultAdminRolePermissions apply to a role until its permissions are set in the
+// admin_role_permissions table. Super admins always hold every permission.
+var DefaultAdminRolePermissions = map[AdminRole][]AdminPermission{
+	AdminRoleOperations: {
+		AdminPermDashboardView, AdminPermApprovals, AdminPermOperationsManage,
+		AdminPermPromotionsManage, AdminPermReportsManage, AdminPermBulkJobs,
+	},
+	AdminRoleFinance: {
+		AdminPermDashboardView, AdminPermPaymentsManage, AdminPermPayoutsManage, AdminPermReportsManage,
+	},
+	AdminRoleSupport: {
+		AdminPermDashboardView, AdminPermUsersSupport, AdminPermCallCenter,
+	},
+}
+
+// ValidAdminPermission reports whether permission is a known permission
+func ValidAdminPermission(permission AdminPermission) bool {
+	for _, known := range AdminPermissions {
+		if permission == known {
+			return true
+		}
+	}
+	return false
+}
+
+// SuperAdminOnlyPermission reports whether permission is reserved to super admins
+func SuperAdminOnlyPermission(permission AdminPermission) bool {
+	for _, reserved := range SuperAdminOnlyPermissions {
+		if permission == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// Audit log actions for admin privilege changes
+const (
+	AuditAdminCreated                = "admin_created"
+	AuditAdminRoleChanged            = "admin_role_changed"
+	AuditAdminRolePermissionsChanged = "admin_role_permissions_changed"
+)
+
+// AdminRolePermission is a row of the admin_role_permissions table
+type AdminRolePermission struct {
+	Role       AdminRole       `db:"role"`
+	Permission AdminPermission `db:"permission"`
+	UpdatedBy  *uuid.UUID      `db:"updated_by"`
+	UpdatedAt  time.Time       `db:"updated_at"`
+}
+
+// AdminRoleInfo is a role with the permissions it currently grants
+type AdminRoleInfo struct {
+	Role        AdminRole         `json:"role"`
+	Permissions []AdminPermission `json:"permissions"`
+	IsDefault   bool              `json:"is_default"` // Permissions are the built-in defaults, not set in the table
+	UpdatedAt   *time.Time        `json:"updated_at,omitempty"`
+}
+
+// AdminUpdateRoleRequest changes an admin's role
+type AdminUpdateRoleRequest struct {
+	Role AdminRole `json:"role" binding:"required"`
+}
+
+// AdminRolePermissionsRequest sets the permissions a role grants
+type AdminRolePermissionsRequest struct {
+	Permissions []AdminPermission `json:"permissions" binding:"required,min=1"`
+}
@@ -8,15 +8,17 @@ import (
 
 // AdminUser represents an admin dashboard user
 type AdminUser struct {
-	ID           uuid.UUID  `json:"id" db:"id"`
-	Email        string     `json:"email" db:"email"`
-	PasswordHash string     `json:"-" db:"password_hash"` // Never expose password hash in JSON
-	FullName     string     `json:"full_name" db:"full_name"`
-	IsActive     bool       `json:"is_active" db:"is_active"`
-	LastLoginAt  *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
-	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	ID           uuid.UUID         `json:"id" db:"id"`
+	Email        string            `json:"email" db:"email"`
+	PasswordHash string            `json:"-" db:"password_hash"` // Never expose password hash in JSON
+	FullName     string            `json:"full_name" db:"full_name"`
+	Role         AdminRole         `json:"role" db:"role"`
+	IsActive     bool              `json:"is_active" db:"is_active"`
+	LastLoginAt  *time.Time        `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+	CreatedBy    *uuid.UUID        `json:"created_by,omitempty" db:"created_by"`
+	Permissions  []AdminPermission `json:"permissions,omitempty" db:"-"` // Effective permissions, on the admin's own profile
 }
 
 // AdminLoginRequest represents the login request payload
@@ -46,7 +48,8 @@ type AdminChangePasswordRequest struct {
 
 // AdminCreateRequest represents the request to create a new admin user
 type AdminCreateRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
-	FullName string `json:"full_name" binding:"required"`
+	Email    string    `json:"email" binding:"required,email"`
+	Password string    `json:"password" binding:"required,min=8"`
+	FullName string    `json:"full_name" binding:"required"`
+	Role     AdminRole `json:"role" binding:"required"`
 }
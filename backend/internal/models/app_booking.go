@@ -154,11 +154,20 @@ type MasterBooking struct {
 	RefundReference *string    `json:"refund_reference,omitempty" db:"refund_reference"`
 	RefundedAt      *time.Time `json:"refunded_at,omitempty" db:"refunded_at"`
 
+	// Paid share of the seats cancelled out of the booking (see BookingSeatCancellation)
+	CancelledSeatsAmount float64 `json:"cancelled_seats_amount,omitempty" db:"cancelled_seats_amount"`
+
+	// Dispute
+	DisputedAt *time.Time `json:"disputed_at,omitempty" db:"disputed_at"` // Set when a chargeback is raised against the payment
+
 	// Metadata
 	BookingSource BookingSource `json:"booking_source" db:"booking_source"`
 	DeviceInfo    DeviceInfo    `json:"device_info,omitempty" db:"device_info"`
 	Notes         *string       `json:"notes,omitempty" db:"notes"`
 
+	// What was booked, frozen at booking time (see BookingSnapshot)
+	Snapshot *BookingSnapshot `json:"snapshot,omitempty" db:"snapshot"`
+
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 
@@ -177,9 +186,11 @@ type BusBooking struct {
 	BookingID       string `json:"booking_id" db:"booking_id"`
 	ScheduledTripID string `json:"scheduled_trip_id" db:"scheduled_trip_id"`
 
-	// Stops (IDs only - names fetched via JOIN)
-	BoardingStopID  *string `json:"boarding_stop_id,omitempty" db:"boarding_stop_id"`
-	AlightingStopID *string `json:"alighting_stop_id,omitempty" db:"alighting_stop_id"`
+	// Stops (IDs only - names fetched via JOIN), with their order on the route when booked
+	BoardingStopID     *string `json:"boarding_stop_id,omitempty" db:"boarding_stop_id"`
+	AlightingStopID    *string `json:"alighting_stop_id,omitempty" db:"alighting_stop_id"`
+	BoardingStopOrder  *int    `json:"boarding_stop_order,omitempty" db:"boarding_stop_order"`
+	AlightingStopOrder *int    `json:"alighting_stop_order,omitempty" db:"alighting_stop_order"`
 
 	// Seats & Fare
 	NumberOfSeats int     `json:"number_of_seats" db:"number_of_seats"`
@@ -219,6 +230,9 @@ type BusBooking struct {
 	BoardingStopName  string     `json:"boarding_stop_name,omitempty" db:"-"`
 	AlightingStopName string     `json:"alighting_stop_name,omitempty" db:"-"`
 	DepartureDatetime *time.Time `json:"departure_datetime,omitempty" db:"-"`
+
+	// When the bus is expected at the boarding stop, while the trip is running
+	BoardingETA *StopETA `json:"boarding_eta,omitempty" db:"-"`
 }
 
 // ============================================================================
@@ -243,6 +257,9 @@ type BusBookingSeat struct {
 	// Status
 	Status SeatBookingStatus `json:"status" db:"status"`
 
+	// Per-passenger ticket, issued when the booking's tickets are first asked for
+	TicketCode *string `json:"ticket_code,omitempty" db:"ticket_code"`
+
 	// Timestamps
 	CancelledAt *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
 
@@ -0,0 +1,203 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AppPlatform is a passenger app platform, sent by the app in X-Device-Type
+type AppPlatform string
+
+const (
+	AppPlatformAndroid AppPlatform = "android"
+	AppPlatformIOS     AppPlatform = "ios"
+)
+
+// AppPlatforms lists the platforms served by the app config
+var AppPlatforms = []AppPlatform{AppPlatformAndroid, AppPlatformIOS}
+
+// ParseAppPlatform normalizes an X-Device-Type value; returns false for web and unknown clients
+func ParseAppPlatform(value string) (AppPlatform, bool) {
+	switch AppPlatform(strings.ToLower(strings.TrimSpace(value))) {
+	case AppPlatformAndroid:
+		return AppPlatformAndroid, true
+	case AppPlatformIOS:
+		return AppPlatformIOS, true
+	}
+	return "", false
+}
+
+// System setting keys read by the app config. Per-platform keys end in the platform name,
+// e.g. app_min_version_android.
+const (
+	AppSettingMinVersionPrefix      = "app_min_version_"
+	AppSettingLatestVersionPrefix   = "app_latest_version_"
+	AppSettingStoreURLPrefix        = "app_store_url_"
+	AppSettingBlockedVersionsPrefix = "app_blocked_versions_" // Comma-separated releases to block outright
+	AppSettingFeaturePrefix         = "app_feature_"          // app_feature_<name> = "true" or "false"
+	AppSettingMaintenanceMode       = "maintenance_mode"
+	AppSettingMaintenanceMessage    = "maintenance_message"
+	AppSettingMaintenanceRetryAfter = "maintenance_retry_after_seconds"
+)
+
+// IsAppConfigSettingKey reports whether key feeds the app config. These keys can lock every
+// client out (version gating, maintenance), so only admins may write them.
+func IsAppConfigSettingKey(key string) bool {
+	switch key {
+	case AppSettingMaintenanceMode, AppSettingMaintenanceMessage, AppSettingMaintenanceRetryAfter:
+		return true
+	}
+	for _, prefix := range []string{
+		AppSettingMinVersionPrefix,
+		AppSettingLatestVersionPrefix,
+		AppSettingStoreURLPrefix,
+		AppSettingBlockedVersionsPrefix,
+		AppSettingFeaturePrefix,
+	} {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultMaintenanceRetryAfterSeconds is the Retry-After sent while no retry hint is set
+const DefaultMaintenanceRetryAfterSeconds = 300
+
+// AppPlatformConfig is the version policy for one platform
+type AppPlatformConfig struct {
+	Platform            AppPlatform `json:"platform"`
+	MinSupportedVersion string      `json:"min_supported_version,omitempty"`
+	LatestVersion       string      `json:"latest_version,omitempty"`
+	StoreURL            string      `json:"store_url,omitempty"`
+	BlockedVersions     []string    `json:"blocked_versions"`
+}
+
+// AppMaintenance tells the app whether the platform is under maintenance. While enabled the
+// API is read-only: writes get 503 with Retry-After, except for admins.
+type AppMaintenance struct {
+	Enabled           bool   `json:"enabled"`
+	Message           string `json:"message,omitempty"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+}
+
+// UpdateMaintenanceRequest switches maintenance mode on or off
+type UpdateMaintenanceRequest struct {
+	Enabled           *bool  `json:"enabled" binding:"required"`
+	Message           string `json:"message" binding:"max=500"`
+	RetryAfterSeconds int    `json:"retry_after_seconds" binding:"min=0,max=86400"`
+}
+
+// AppConfig is the remote configuration the passenger app loads on start
+type AppConfig struct {
+	Platforms   []AppPlatformConfig `json:"platforms"`
+	Features    map[string]bool     `json:"features"`
+	Maintenance AppMaintenance      `json:"maintenance"`
+
+	// The caller's own version status, when it sent X-Device-Type and X-App-Version
+	VersionStatus *AppVersionStatus `json:"version_status,omitempty"`
+}
+
+// Platform returns the policy for platform, or nil if there is none
+func (c *AppConfig) Platform(platform AppPlatform) *AppPlatformConfig {
+	for i := range c.Platforms {
+		if c.Platforms[i].Platform == platform {
+			return &c.Platforms[i]
+		}
+	}
+	return nil
+}
+
+// AppVersionStatus is the verdict on one app version
+type AppVersionStatus struct {
+	Platform         AppPlatform `json:"platform"`
+	Version          string      `json:"version"`
+	Supported        bool        `json:"supported"`
+	UpgradeRequired  bool        `json:"upgrade_required"`
+	UpgradeAvailable bool        `json:"upgrade_available"`
+	Reason           string      `json:"reason,omitempty"` // "below_minimum" or "blocked"
+	MinVersion       string      `json:"min_supported_version,omitempty"`
+	LatestVersion    string      `json:"latest_version,omitempty"`
+	StoreURL         string      `json:"store_url,omitempty"`
+}
+
+// CheckVersion judges version against the platform's policy. Unparseable versions are treated
+// as supported so a malformed header never locks users out.
+func (p *AppPlatformConfig) CheckVersion(version string) AppVersionStatus {
+	version = strings.TrimSpace(version)
+	status := AppVersionStatus{
+		Platform:      p.Platform,
+		Version:       version,
+		Supported:     true,
+		MinVersion:    p.MinSupportedVersion,
+		LatestVersion: p.LatestVersion,
+		StoreURL:      p.StoreURL,
+	}
+
+	for _, blocked := range p.BlockedVersions {
+		if cmp, ok := CompareAppVersions(version, blocked); ok && cmp == 0 {
+			status.Supported, status.UpgradeRequired, status.Reason = false, true, "blocked"
+			break
+		}
+	}
+	if status.Supported && p.MinSupportedVersion != "" {
+		if cmp, ok := CompareAppVersions(version, p.MinSupportedVersion); ok && cmp < 0 {
+			status.Supported, status.UpgradeRequired, status.Reason = false, true, "below_minimum"
+		}
+	}
+	if p.LatestVersion != "" {
+		if cmp, ok := CompareAppVersions(version, p.LatestVersion); ok && cmp < 0 {
+			status.UpgradeAvailable = true
+		}
+	}
+	return status
+}
+
+// CompareAppVersions compares dotted version strings numerically ("1.10.0" > "1.9.2"),
+// ignoring a leading "v" and any build or pre-release suffix ("1.4.0+52", "1.4.0-beta").
+// Missing parts count as zero. Returns false if either version is not numeric.
+func CompareAppVersions(a, b string) (int, bool) {
+	pa, ok := parseAppVersion(a)
+	if !ok {
+		return 0, false
+	}
+	pb, ok := parseAppVersion(b)
+	if !ok {
+		return 0, false
+	}
+	for len(pa) < len(pb) {
+		pa = append(pa, 0)
+	}
+	for len(pb) < len(pa) {
+		pb = append(pb, 0)
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+func parseAppVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "+- "); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, false
+	}
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
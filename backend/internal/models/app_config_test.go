@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareAppVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10.0", "1.9.2", 1},
+		{"1.4", "1.4.0", 0},
+		{"v2.0.0", "2.0.0", 0},
+		{"1.4.0+52", "1.4.0", 0},
+		{"1.4.0-beta", "1.4.1", -1},
+	}
+	for _, tc := range cases {
+		got, ok := CompareAppVersions(tc.a, tc.b)
+		assert.True(t, ok, tc.a)
+		assert.Equal(t, tc.want, got, "%s vs %s", tc.a, tc.b)
+	}
+
+	_, ok := CompareAppVersions("latest", "1.0.0")
+	assert.False(t, ok)
+	_, ok = CompareAppVersions("", "1.0.0")
+	assert.False(t, ok)
+}
+
+func TestAppPlatformConfig_CheckVersion(t *testing.T) {
+	policy := &AppPlatformConfig{
+		Platform:            AppPlatformAndroid,
+		MinSupportedVersion: "2.1.0",
+		LatestVersion:       "2.3.0",
+		BlockedVersions:     []string{"2.2.0"},
+	}
+
+	old := policy.CheckVersion("2.0.9")
+	assert.False(t, old.Supported)
+	assert.True(t, old.UpgradeRequired)
+	assert.Equal(t, "below_minimum", old.Reason)
+
+	blocked := policy.CheckVersion("2.2.0")
+	assert.True(t, blocked.UpgradeRequired)
+	assert.Equal(t, "blocked", blocked.Reason)
+
+	current := policy.CheckVersion("2.2.1")
+	assert.True(t, current.Supported)
+	assert.True(t, current.UpgradeAvailable)
+
+	latest := policy.CheckVersion("2.3.0")
+	assert.False(t, latest.UpgradeAvailable)
+
+	assert.True(t, policy.CheckVersion("garbage").Supported, "malformed versions are never locked out")
+	assert.True(t, (&AppPlatformConfig{Platform: AppPlatformIOS}).CheckVersion("0.1").Supported, "no minimum set")
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookableTripDetailsRow is a bookable trip with its route, bus and operator, as read for the
+// passenger-facing details view
+type BookableTripDetailsRow struct {
+	TripID            string    `db:"trip_id"`
+	RouteName         *string   `db:"route_name"`
+	RouteNumber       *string   `db:"route_number"`
+	BusType           *string   `db:"bus_type"`
+	DepartureDatetime time.Time `db:"departure_datetime"`
+	DurationMinutes   int       `db:"duration_minutes"`
+	Fare              float64   `db:"fare"`
+	OperatorName      *string   `db:"operator_name"`
+	BusOwnerID        *string   `db:"bus_owner_id"`
+	MasterRouteID     *string   `db:"master_route_id"`
+	BusOwnerRouteID   *string   `db:"bus_owner_route_id"`
+	BusFeatures
+}
+
+// BookableTripStop is a stop the trip serves, with its estimated time
+type BookableTripStop struct {
+	ID            string     `json:"id"`
+	StopName      string     `json:"stop_name"`
+	StopOrder     int        `json:"stop_order"`
+	Latitude      *float64   `json:"latitude,omitempty"`
+	Longitude     *float64   `json:"longitude,omitempty"`
+	IsMajorStop   bool       `json:"is_major_stop"`
+	EstimatedTime *time.Time `json:"estimated_time,omitempty"` // Omitted when the route has no timing for the stop
+}
+
+// TripStopTimes estimates when the trip reaches each stop. Route offsets count from the master
+// route's origin, so when the owner's route starts part-way along it the first stop served is
+// taken as the departure.
+func TripStopTimes(departure time.Time, stops []RouteStop) []BookableTripStop {
+	result := make([]BookableTripStop, 0, len(stops))
+	var base *int
+	for _, stop := range stops {
+		if stop.ArrivalTimeOffsetMinutes != nil {
+			base = stop.ArrivalTimeOffsetMinutes
+			break
+		}
+	}
+	for _, stop := range stops {
+		s := BookableTripStop{
+			ID:          stop.ID,
+			StopName:    stop.StopName,
+			StopOrder:   stop.StopOrder,
+			Latitude:    stop.Latitude,
+			Longitude:   stop.Longitude,
+			IsMajorStop: stop.IsMajorStop,
+		}
+		if stop.ArrivalTimeOffsetMinutes != nil {
+			at := departure.Add(time.Duration(*stop.ArrivalTimeOffsetMinutes-*base) * time.Minute)
+			s.EstimatedTime = &at
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// SeatPriceRange is the price range of one seat type's available seats on a trip
+type SeatPriceRange struct {
+	SeatType       string  `json:"seat_type" db:"seat_type"`
+	MinPrice       float64 `json:"min_price" db:"min_price"`
+	MaxPrice       float64 `json:"max_price" db:"max_price"`
+	AvailableSeats int     `json:"available_seats" db:"available_seats"`
+}
+
+// CancellationTierSummary is one fee tier of the cancellation policy, for passengers
+type CancellationTierSummary struct {
+	NoticeMinutes int     `json:"notice_minutes"` // Applies when cancelling at least this long before departure
+	FeePercent    float64 `json:"fee_percent"`
+}
+
+// CancellationPolicySummary describes the cancellation policy for passengers. Cancelling after
+// the last tier's notice refunds nothing.
+type CancellationPolicySummary struct {
+	FreeCancellation bool                      `json:"free_cancellation"` // No tiers: cancelling refunds everything
+	Tiers            []CancellationTierSummary `json:"tiers"`
+	Currency         string                    `json:"currency,omitempty"`
+}
+
+// Summary returns the policy as shown to passengers
+func (p CancellationPolicy) Summary() CancellationPolicySummary {
+	summary := CancellationPolicySummary{
+		FreeCancellation: len(p.Tiers) == 0,
+		Tiers:            []CancellationTierSummary{},
+		Currency:         p.Currency,
+	}
+	for _, tier := range p.Tiers {
+		summary.Tiers = append(summary.Tiers, CancellationTierSummary{
+			NoticeMinutes: int(tier.Before / time.Minute),
+			FeePercent:    tier.FeePercent,
+		})
+	}
+	return summary
+}
+
+// OperatorTravelPolicy is what a bus owner allows passengers to bring on board
+type OperatorTravelPolicy struct {
+	BusOwnerID         string    `json:"-" db:"bus_owner_id"`
+	LuggageAllowanceKg *float64  `json:"luggage_allowance_kg,omitempty" db:"luggage_allowance_kg"` // Per passenger; nil when not stated
+	LuggagePieces      *int      `json:"luggage_pieces,omitempty" db:"luggage_pieces"`
+	LuggageNotes       *string   `json:"luggage_notes,omitempty" db:"luggage_notes"` // e.g. "No bicycles or gas cylinders"
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateOperatorTravelPolicyRequest sets a bus owner's travel policy
+type UpdateOperatorTravelPolicyRequest struct {
+	LuggageAllowanceKg *float64 `json:"luggage_allowance_kg" binding:"omitempty,min=0,max=200"`
+	LuggagePieces      *int     `json:"luggage_pieces" binding:"omitempty,min=0,max=20"`
+	LuggageNotes       *string  `json:"luggage_notes" binding:"omitempty,max=500"`
+}
+
+// Validate trims the notes; blank notes are cleared
+func (r *UpdateOperatorTravelPolicyRequest) Validate() error {
+	if r.LuggageNotes != nil {
+		notes := strings.TrimSpace(*r.LuggageNotes)
+		if notes == "" {
+			r.LuggageNotes = nil
+		} else {
+			r.LuggageNotes = &notes
+		}
+	}
+	if r.LuggageAllowanceKg == nil && r.LuggagePieces == nil && r.LuggageNotes == nil {
+		return &ValidationError{Message: "set at least one of luggage_allowance_kg, luggage_pieces or luggage_notes"}
+	}
+	return nil
+}
+
+// TripLoungeOption is a lounge passengers can book around a trip's origin or destination
+type TripLoungeOption struct {
+	ID            uuid.UUID `json:"id"`
+	LoungeName    string    `json:"lounge_name"`
+	Address       string    `json:"address"`
+	Price1Hour    string    `json:"price_1_hour,omitempty"`
+	PriceUntilBus string    `json:"price_until_bus,omitempty"`
+	AverageRating string    `json:"average_rating,omitempty"`
+	Amenities     []string  `json:"amenities"`
+}
+
+// NewTripLoungeOption summarises a lounge for the trip details view
+func NewTripLoungeOption(lounge *Lounge) TripLoungeOption {
+	option := TripLoungeOption{
+		ID:            lounge.ID,
+		LoungeName:    lounge.LoungeName,
+		Address:       lounge.Address,
+		Price1Hour:    lounge.Price1Hour.String,
+		PriceUntilBus: lounge.PriceUntilBus.String,
+		AverageRating: lounge.AverageRating.String,
+	}
+	if len(lounge.Amenities) > 0 {
+		_ = json.Unmarshal(lounge.Amenities, &option.Amenities)
+	}
+	if option.Amenities == nil {
+		option.Amenities = []string{}
+	}
+	return option
+}
+
+// TripLoungeOptions are the lounges near a trip's first and last stops
+type TripLoungeOptions struct {
+	Origin      []TripLoungeOption `json:"origin"`
+	Destination []TripLoungeOption `json:"destination"`
+}
+
+// TripOperator is the bus owner running a trip and their travel policy
+type TripOperator struct {
+	Name         *string               `json:"name,omitempty"`
+	TravelPolicy *OperatorTravelPolicy `json:"travel_policy,omitempty"` // Omitted when the operator has not set one
+}
+
+// BookableTripDetails is the public view of a bookable trip: where and when it stops, what the
+// bus offers, the operator's policies, what seats cost and lounges at either end
+type BookableTripDetails struct {
+	TripID             string                    `json:"trip_id"`
+	RouteName          *string                   `json:"route_name,omitempty"`
+	RouteNumber        *string                   `json:"route_number,omitempty"`
+	BusType            *string                   `json:"bus_type,omitempty"`
+	DepartureDatetime  time.Time                 `json:"departure_datetime"`
+	EstimatedArrival   time.Time                 `json:"estimated_arrival"`
+	DurationMinutes    int                       `json:"duration_minutes"`
+	Fare               float64                   `json:"fare"`
+	Stops              []BookableTripStop        `json:"stops"`
+	Amenities          BusFeatures               `json:"amenities"`
+	Operator           TripOperator              `json:"operator"`
+	CancellationPolicy CancellationPolicySummary `json:"cancellation_policy"`
+	SeatPrices         []SeatPriceRange          `json:"seat_prices"` // Empty when the trip has no seat map
+	Lounges            TripLoungeOptions         `json:"lounges"`
+}
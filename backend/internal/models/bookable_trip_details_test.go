@@ -0,0 +1,69 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTripStopTimes_FromFirstServedStop(t *testing.T) {
+	departure := time.Date(2026, 3, 14, 6, 0, 0, 0, time.UTC)
+	offset := func(m int) *int { return &m }
+	stops := TripStopTimes(departure, []RouteStop{
+		{ID: "kadawatha", StopOrder: 3, ArrivalTimeOffsetMinutes: offset(40)},
+		{ID: "nittambuwa", StopOrder: 5},
+		{ID: "kandy", StopOrder: 9, ArrivalTimeOffsetMinutes: offset(190)},
+	})
+
+	require.Len(t, stops, 3)
+	require.NotNil(t, stops[0].EstimatedTime)
+	assert.Equal(t, departure, *stops[0].EstimatedTime, "owner's route starts part-way along the master route")
+	assert.Nil(t, stops[1].EstimatedTime, "no timing for the stop")
+	assert.Equal(t, departure.Add(150*time.Minute), *stops[2].EstimatedTime)
+}
+
+func TestCancellationPolicy_Summary(t *testing.T) {
+	policy, err := ParseCancellationPolicy("6h:25,24h:0,0s:50")
+	require.NoError(t, err)
+	policy.Currency = "LKR"
+
+	summary := policy.Summary()
+	assert.False(t, summary.FreeCancellation)
+	assert.Equal(t, []CancellationTierSummary{
+		{NoticeMinutes: 1440, FeePercent: 0},
+		{NoticeMinutes: 360, FeePercent: 25},
+		{NoticeMinutes: 0, FeePercent: 50},
+	}, summary.Tiers)
+	assert.Equal(t, "LKR", summary.Currency)
+
+	free := CancellationPolicy{}.Summary()
+	assert.True(t, free.FreeCancellation)
+	assert.Empty(t, free.Tiers)
+}
+
+func TestUpdateOperatorTravelPolicyRequest_Validate(t *testing.T) {
+	blank := "   "
+	assert.Error(t, (&UpdateOperatorTravelPolicyRequest{LuggageNotes: &blank}).Validate(), "nothing left to set")
+
+	kg := 20.0
+	notes := "  No gas cylinders "
+	req := &UpdateOperatorTravelPolicyRequest{LuggageAllowanceKg: &kg, LuggageNotes: &notes}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, "No gas cylinders", *req.LuggageNotes)
+}
+
+func TestNewTripLoungeOption(t *testing.T) {
+	option := NewTripLoungeOption(&Lounge{
+		LoungeName:  "Kandy Transit Lounge",
+		Price1Hour:  sql.NullString{String: "500.00", Valid: true},
+		Amenities:   []byte(`["wifi","ac"]`),
+		Description: sql.NullString{String: "Near the clock tower", Valid: true},
+	})
+	assert.Equal(t, "500.00", option.Price1Hour)
+	assert.Equal(t, []string{"wifi", "ac"}, option.Amenities)
+
+	assert.Equal(t, []string{}, NewTripLoungeOption(&Lounge{}).Amenities)
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// BookingBlackoutStatus is the state of a booking blackout
+type BookingBlackoutStatus string
+
+const (
+	BookingBlackoutActive     BookingBlackoutStatus = "active"
+	BookingBlackoutLifted     BookingBlackoutStatus = "lifted"     // Ended early by the owner
+	BookingBlackoutOverridden BookingBlackoutStatus = "overridden" // Online sales reopened by an admin
+)
+
+// bookingBlackoutMaxDays caps how long a single blackout can run
+const bookingBlackoutMaxDays = 366
+
+// BookingBlackout stops online sales for a bus owner's route or schedule over a date range
+// (charter commitments, strikes, school runs). Trips stay scheduled and existing bookings are
+// kept; the trips are only hidden from search and refuse new booking intents.
+type BookingBlackout struct {
+	ID              string                `json:"id" db:"id"`
+	BusOwnerID      string                `json:"bus_owner_id" db:"bus_owner_id"`
+	BusOwnerRouteID *string               `json:"bus_owner_route_id,omitempty" db:"bus_owner_route_id"`
+	TripScheduleID  *string               `json:"trip_schedule_id,omitempty" db:"trip_schedule_id"`
+	StartDate       string                `json:"start_date" db:"start_date"` // YYYY-MM-DD, by departure date
+	EndDate         string                `json:"end_date" db:"end_date"`     // YYYY-MM-DD, inclusive
+	Reason          string                `json:"reason" db:"reason"`
+	Status          BookingBlackoutStatus `json:"status" db:"status"`
+	CreatedByUserID string                `json:"created_by_user_id" db:"created_by_user_id"`
+
+	// Set when an admin overrides the blackout
+	OverriddenByUserID *string    `json:"overridden_by_user_id,omitempty" db:"overridden_by_user_id"`
+	OverrideReason     *string    `json:"override_reason,omitempty" db:"override_reason"`
+	OverriddenAt       *time.Time `json:"overridden_at,omitempty" db:"overridden_at"`
+	LiftedAt           *time.Time `json:"lifted_at,omitempty" db:"lifted_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateBookingBlackoutRequest blacks out one of the owner's routes or schedules
+type CreateBookingBlackoutRequest struct {
+	BusOwnerRouteID *string `json:"bus_owner_route_id,omitempty" binding:"omitempty,uuid"`
+	TripScheduleID  *string `json:"trip_schedule_id,omitempty" binding:"omitempty,uuid"`
+	StartDate       string  `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate         string  `json:"end_date" binding:"required"`   // YYYY-MM-DD, inclusive
+	Reason          string  `json:"reason" binding:"required,min=3,max=500"`
+}
+
+// Validate checks the request against today (the owner's local date). Exactly one of a route
+// or a schedule is required, and the range may not end in the past or run over a year.
+func (r *CreateBookingBlackoutRequest) Validate(today time.Time) error {
+	hasRoute := r.BusOwnerRouteID != nil && *r.BusOwnerRouteID != ""
+	hasSchedule := r.TripScheduleID != nil && *r.TripScheduleID != ""
+	if hasRoute == hasSchedule {
+		return &ValidationError{Message: "exactly one of bus_owner_route_id or trip_schedule_id is required"}
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		return &ValidationError{Message: "reason is required"}
+	}
+
+	start, err := time.Parse("2006-01-02", r.StartDate)
+	if err != nil {
+		return &ValidationError{Message: "start_date must be in YYYY-MM-DD format"}
+	}
+	end, err := time.Parse("2006-01-02", r.EndDate)
+	if err != nil {
+		return &ValidationError{Message: "end_date must be in YYYY-MM-DD format"}
+	}
+	if end.Before(start) {
+		return &ValidationError{Message: "end_date must not be before start_date"}
+	}
+	if end.Format("2006-01-02") < today.Format("2006-01-02") {
+		return &ValidationError{Message: "end_date must not be in the past"}
+	}
+	if end.Sub(start) >= bookingBlackoutMaxDays*24*time.Hour {
+		return &ValidationError{Message: "a blackout must not run longer than 366 days"}
+	}
+	return nil
+}
+
+// OverrideBookingBlackoutRequest reopens online sales for a blacked out route or schedule
+type OverrideBookingBlackoutRequest struct {
+	Reason string `json:"reason" binding:"required,min=3,max=500"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateBookingBlackoutRequest_Validate(t *testing.T) {
+	str := func(value string) *string { return &value }
+	today := time.Date(2026, 3, 10, 9, 0, 0, 0, ReportTimezone)
+	valid := CreateBookingBlackoutRequest{
+		BusOwnerRouteID: str("6f1c2f7e-3c1a-4d8e-9b1a-0c2d3e4f5a6b"),
+		StartDate:       "2026-03-10",
+		EndDate:         "2026-03-12",
+		Reason:          "Charter for school sports meet",
+	}
+	assert.NoError(t, valid.Validate(today))
+
+	schedule := valid
+	schedule.BusOwnerRouteID = nil
+	schedule.TripScheduleID = str("0b7e3a52-1f0c-4e4b-8d6f-2a9c5e1d7b30")
+	assert.NoError(t, schedule.Validate(today))
+
+	started := valid
+	started.StartDate = "2026-03-01"
+	assert.NoError(t, started.Validate(today), "a blackout may have started already")
+
+	cases := map[string]func(r *CreateBookingBlackoutRequest){
+		"no target":    func(r *CreateBookingBlackoutRequest) { r.BusOwnerRouteID = nil },
+		"both targets": func(r *CreateBookingBlackoutRequest) { r.TripScheduleID = str("0b7e3a52-1f0c-4e4b-8d6f-2a9c5e1d7b30") },
+		"blank reason": func(r *CreateBookingBlackoutRequest) { r.Reason = "   " },
+		"bad date":     func(r *CreateBookingBlackoutRequest) { r.StartDate = "10/03/2026" },
+		"reversed":     func(r *CreateBookingBlackoutRequest) { r.StartDate, r.EndDate = "2026-03-12", "2026-03-10" },
+		"ended":        func(r *CreateBookingBlackoutRequest) { r.StartDate, r.EndDate = "2026-03-01", "2026-03-09" },
+		"over a year":  func(r *CreateBookingBlackoutRequest) { r.EndDate = "2027-03-11" },
+	}
+	for name, mutate := range cases {
+		req := valid
+		mutate(&req)
+		assert.Error(t, req.Validate(today), name)
+	}
+}
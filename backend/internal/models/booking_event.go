@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Booking lifecycle event types published for internal services
+const (
+	EventIntentCreated    = "intent.created"
+	EventIntentExpired    = "intent.expired"
+	EventBookingConfirmed = "booking.confirmed"
+	EventBookingCancelled = "booking.cancelled"
+	// Some seats of a group booking were cancelled; the booking stays confirmed
+	EventBookingSeatsCancelled = "booking.seats_cancelled"
+)
+
+// IntentEventData is the payload of intent.created and intent.expired. It carries no
+// passenger contact details.
+type IntentEventData struct {
+	IntentID        string            `json:"intent_id"`
+	UserID          string            `json:"user_id"`
+	IntentType      BookingIntentType `json:"intent_type"`
+	ScheduledTripID string            `json:"scheduled_trip_id,omitempty"`
+	SeatCount       int               `json:"seat_count"`
+	TotalAmount     float64           `json:"total_amount"`
+	Currency        string            `json:"currency"`
+	ExpiresAt       time.Time         `json:"expires_at"`
+}
+
+// NewIntentEventData builds the event payload for an intent
+func NewIntentEventData(intent *BookingIntent) IntentEventData {
+	data := IntentEventData{
+		IntentID:    intent.ID.String(),
+		UserID:      intent.UserID.String(),
+		IntentType:  intent.IntentType,
+		TotalAmount: intent.TotalAmount,
+		Currency:    intent.Currency,
+		ExpiresAt:   intent.ExpiresAt,
+	}
+	if intent.BusIntent != nil {
+		data.ScheduledTripID = intent.BusIntent.ScheduledTripID
+		data.SeatCount = len(intent.BusIntent.Seats)
+	}
+	return data
+}
+
+// BookingEventData is the payload of booking.confirmed, booking.cancelled and
+// booking.seats_cancelled
+type BookingEventData struct {
+	BookingID           string  `json:"booking_id,omitempty"`
+	BookingReference    string  `json:"booking_reference,omitempty"`
+	UserID              string  `json:"user_id"`
+	IntentID            string  `json:"intent_id,omitempty"`
+	ScheduledTripID     string  `json:"scheduled_trip_id,omitempty"`
+	BusBookingID        string  `json:"bus_booking_id,omitempty"`
+	PreLoungeBookingID  string  `json:"pre_lounge_booking_id,omitempty"`
+	PostLoungeBookingID string  `json:"post_lounge_booking_id,omitempty"`
+	TotalAmount         float64 `json:"total_amount"`
+	Currency            string  `json:"currency,omitempty"`
+
+	// Cancellation only
+	Reason          *string `json:"reason,omitempty"`
+	RefundAmount    float64 `json:"refund_amount,omitempty"`
+	CancellationFee float64 `json:"cancellation_fee,omitempty"`
+
+	// Seat cancellation only
+	SeatIDs            []string `json:"seat_ids,omitempty"`
+	SeatCancellationID string   `json:"seat_cancellation_id,omitempty"`
+}
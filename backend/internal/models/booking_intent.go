@@ -4,9 +4,12 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // ============================================================================
@@ -50,6 +53,16 @@ const (
 	IntentTypeCombined   BookingIntentType = "combined"
 )
 
+// ReliabilityTier classifies a user by their hold abandonment history
+// Used to pick the hold TTL for new intents
+type ReliabilityTier string
+
+const (
+	ReliabilityTierTrusted    ReliabilityTier = "trusted"    // Consistently completes bookings
+	ReliabilityTierStandard   ReliabilityTier = "standard"   // Default tier
+	ReliabilityTierRestricted ReliabilityTier = "restricted" // Repeatedly abandons holds
+)
+
 // ============================================================================
 // JSONB PAYLOAD TYPES
 // ============================================================================
@@ -67,6 +80,8 @@ type BusIntentPayload struct {
 	PassengerEmail    *string            `json:"passenger_email,omitempty"`
 	SpecialRequests   *string            `json:"special_requests,omitempty"`
 	TripInfo          *BusIntentTripInfo `json:"trip_info,omitempty"` // Denormalized for display
+
+	PriceAdjustment *AppliedPriceAdjustment `json:"price_adjustment,omitempty"` // Owner surcharge/discount in the seat prices
 }
 
 // BusIntentSeat represents a seat selection in bus intent
@@ -91,20 +106,22 @@ type BusIntentTripInfo struct {
 
 // LoungeIntentPayload stores lounge booking intent data in JSONB
 type LoungeIntentPayload struct {
-	LoungeID      string                 `json:"lounge_id"`
-	LoungeName    string                 `json:"lounge_name"`
-	LoungeAddress *string                `json:"lounge_address,omitempty"`
-	PricingType   string                 `json:"pricing_type"`  // "1_hour", "2_hours", "3_hours", "until_bus"
-	Date          string                 `json:"date"`          // "2025-12-15"
-	CheckInTime   string                 `json:"check_in_time"` // "09:00"
-	CheckOutTime  *string                `json:"check_out_time,omitempty"`
-	GuestCount    int                    `json:"guest_count"` // Total: primary + additional guests
-	Guests        []LoungeIntentGuest    `json:"guests"`
-	PreOrders     []LoungeIntentPreOrder `json:"pre_orders,omitempty"`
-	PricePerGuest float64                `json:"price_per_guest"`
-	BasePrice     float64                `json:"base_price"` // price_per_guest * guest_count
-	PreOrderTotal float64                `json:"pre_order_total"`
-	TotalPrice    float64                `json:"total_price"` // base_price + pre_order_total
+	LoungeID       string                 `json:"lounge_id"`
+	LoungeName     string                 `json:"lounge_name"`
+	LoungeAddress  *string                `json:"lounge_address,omitempty"`
+	PricingType    string                 `json:"pricing_type"`  // "1_hour", "2_hours", "3_hours", "until_bus"
+	Date           string                 `json:"date"`          // "2025-12-15"
+	CheckInTime    string                 `json:"check_in_time"` // "09:00"
+	CheckOutTime   *string                `json:"check_out_time,omitempty"`
+	GuestCount     int                    `json:"guest_count"` // Total: primary + additional guests
+	Guests         []LoungeIntentGuest    `json:"guests"`
+	PreOrders      []LoungeIntentPreOrder `json:"pre_orders,omitempty"`
+	PricePerGuest  float64                `json:"price_per_guest"`
+	PriceQuote     *LoungePriceQuote      `json:"price_quote,omitempty"` // How price_per_guest was reached; locked for the intent
+	BasePrice      float64                `json:"base_price"`            // price_per_guest * guest_count
+	PreOrderTotal  float64                `json:"pre_order_total"`
+	TotalPrice     float64                `json:"total_price"`               // base_price + pre_order_total - bundle_discount.amount
+	BundleDiscount *LoungeBundleDiscount  `json:"bundle_discount,omitempty"` // Set when booked with a bus ticket and a bundle rule applies
 }
 
 // LoungeIntentGuest represents a guest in lounge intent
@@ -125,24 +142,48 @@ type LoungeIntentPreOrder struct {
 	TotalPrice  float64 `json:"total_price"`
 }
 
-// PricingSnapshot stores server-calculated prices at intent creation
+// PricingSnapshot stores server-calculated prices at intent creation, as exact amounts
 type PricingSnapshot struct {
-	BusFare         float64             `json:"bus_fare"`
-	PreLoungeFare   float64             `json:"pre_lounge_fare"`
-	PostLoungeFare  float64             `json:"post_lounge_fare"`
-	Total           float64             `json:"total"`
+	BusFare         Money               `json:"bus_fare"`
+	PreLoungeFare   Money               `json:"pre_lounge_fare"`
+	PostLoungeFare  Money               `json:"post_lounge_fare"`
+	Total           Money               `json:"total"`
 	Currency        string              `json:"currency"`
 	CalculatedAt    time.Time           `json:"calculated_at"`
-	SeatPrices      map[string]float64  `json:"seat_prices,omitempty"` // seat_id -> price
+	SeatPrices      map[string]Money    `json:"seat_prices,omitempty"` // seat_id -> price
 	DiscountApplied *IntentDiscountInfo `json:"discount_applied,omitempty"`
+
+	PriceAdjustment *AppliedPriceAdjustment `json:"price_adjustment,omitempty"` // Owner surcharge/discount in BusFare
 }
 
-// IntentDiscountInfo stores discount information
+// IntentDiscountInfo stores discount information. Amounts are exact, like the fares they are
+// taken off.
 type IntentDiscountInfo struct {
 	Code           string  `json:"code"`
-	DiscountType   string  `json:"discount_type"` // "percentage" or "fixed"
-	DiscountValue  float64 `json:"discount_value"`
-	DiscountAmount float64 `json:"discount_amount"` // Actual amount discounted
+	DiscountType   string  `json:"discount_type"`   // "percentage" or "fixed"
+	DiscountValue  float64 `json:"discount_value"`  // Percentage, or fixed LKR amount, of the promo code
+	DiscountAmount Money   `json:"discount_amount"` // Actual amount discounted
+
+	// Promo code the discount came from and its share of each fare, which are undiscounted
+	PromoCodeID        string `json:"promo_code_id,omitempty"`
+	BusDiscount        Money  `json:"bus_discount,omitzero"`
+	PreLoungeDiscount  Money  `json:"pre_lounge_discount,omitzero"`
+	PostLoungeDiscount Money  `json:"post_lounge_discount,omitzero"`
+}
+
+// NewPromoDiscountInfo records a promo quote on bus, pre-trip and post-trip lounge lines. This
+// is where the quote's float amounts become Money.
+func NewPromoDiscountInfo(quote *PromoQuote) *IntentDiscountInfo {
+	return &IntentDiscountInfo{
+		Code:               quote.Code,
+		DiscountType:       string(quote.DiscountType),
+		DiscountValue:      quote.DiscountValue,
+		DiscountAmount:     NewMoney(quote.DiscountAmount),
+		PromoCodeID:        quote.PromoCodeID,
+		BusDiscount:        NewMoney(quote.Lines[0].Discount),
+		PreLoungeDiscount:  NewMoney(quote.Lines[1].Discount),
+		PostLoungeDiscount: NewMoney(quote.Lines[2].Discount),
+	}
 }
 
 // ============================================================================
@@ -227,6 +268,7 @@ type BookingIntent struct {
 	PaymentGateway         string               `json:"payment_gateway" db:"payment_gateway"`
 	PaymentUID             *string              `json:"payment_uid,omitempty" db:"payment_uid"`                           // PAYable unique transaction ID
 	PaymentStatusIndicator *string              `json:"payment_status_indicator,omitempty" db:"payment_status_indicator"` // PAYable status check token
+	WalletAmount           Money                `json:"wallet_amount,omitzero" db:"wallet_amount"`                        // Paid from the passenger's wallet
 
 	// Passenger info (extracted from bus_intent for convenience)
 	PassengerName  string `json:"passenger_name,omitempty" db:"passenger_name"`
@@ -238,7 +280,10 @@ type BookingIntent struct {
 	PostLoungeBookingID *uuid.UUID `json:"post_lounge_booking_id,omitempty" db:"post_lounge_booking_id"`
 
 	// TTL Management
-	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	ExpiresAt       time.Time       `json:"expires_at" db:"expires_at"`
+	HoldTTLSeconds  int             `json:"hold_ttl_seconds" db:"hold_ttl_seconds"` // TTL chosen at intent creation
+	ReliabilityTier ReliabilityTier `json:"reliability_tier" db:"reliability_tier"` // User tier used to choose the TTL
+	ExtensionCount  int             `json:"extension_count" db:"extension_count"`   // Explicit hold extensions used
 
 	// Timestamps
 	PaymentInitiatedAt *time.Time `json:"payment_initiated_at,omitempty" db:"payment_initiated_at"`
@@ -256,6 +301,38 @@ func (i *BookingIntent) IsExpired() bool {
 	return time.Now().After(i.ExpiresAt)
 }
 
+// BundleDiscountTotal returns the bundle discounts taken off the intent's lounge stays
+func (i *BookingIntent) BundleDiscountTotal() float64 {
+	var total Money
+	for _, lounge := range []*LoungeIntentPayload{i.PreTripLoungeIntent, i.PostTripLoungeIntent} {
+		if lounge != nil && lounge.BundleDiscount != nil {
+			total = total.Add(NewMoney(lounge.BundleDiscount.Amount))
+		}
+	}
+	return total.Float64()
+}
+
+// PromoDiscount returns the promo code discount taken off the intent's total
+func (i *BookingIntent) PromoDiscount() Money {
+	if i.PricingSnapshot.DiscountApplied == nil {
+		return Money{}
+	}
+	return i.PricingSnapshot.DiscountApplied.DiscountAmount
+}
+
+// PromoCode returns the promo code applied to the intent, if any
+func (i *BookingIntent) PromoCode() *string {
+	if i.PricingSnapshot.DiscountApplied == nil {
+		return nil
+	}
+	return &i.PricingSnapshot.DiscountApplied.Code
+}
+
+// AmountDue is what is left to pay through the gateway after the wallet payment
+func (i *BookingIntent) AmountDue() Money {
+	return NewMoney(i.TotalAmount).Sub(i.WalletAmount).Max(Money{})
+}
+
 // CanInitiatePayment checks if payment can be initiated
 // Allows both 'held' (first time) and 'payment_pending' (retry)
 func (i *BookingIntent) CanInitiatePayment() bool {
@@ -267,6 +344,12 @@ func (i *BookingIntent) CanConfirm() bool {
 	return (i.Status == IntentStatusPaymentPending || i.Status == IntentStatusHeld) && !i.IsExpired()
 }
 
+// IntentAbandonmentStats summarizes a user's recent intent outcomes
+type IntentAbandonmentStats struct {
+	Abandoned int `db:"abandoned"` // Expired or cancelled intents
+	Confirmed int `db:"confirmed"`
+}
+
 // ============================================================================
 // LOUNGE CAPACITY HOLD MODEL (lounge_capacity_holds table)
 // ============================================================================
@@ -302,6 +385,15 @@ type CreateBookingIntentRequest struct {
 
 	// Idempotency key (optional)
 	IdempotencyKey *string `json:"idempotency_key,omitempty"`
+
+	// Admitted waiting room token; required while the trip's waiting room is enabled
+	QueueToken *string `json:"queue_token,omitempty"`
+
+	// Book even though the user or passenger phone already has a booking on the trip
+	ConfirmDuplicate bool `json:"confirm_duplicate,omitempty"`
+
+	// Promo code to discount the bus and lounge fares with
+	PromoCode *string `json:"promo_code,omitempty"`
 }
 
 // BusIntentRequest represents bus booking request data
@@ -332,6 +424,7 @@ type BusIntentSeatRequest struct {
 type LoungeIntentRequest struct {
 	LoungeID    string                        `json:"lounge_id" binding:"required"`
 	PricingType string                        `json:"pricing_type" binding:"required"` // "1_hour", "2_hours", "3_hours", "until_bus"
+	CheckInTime *string                       `json:"check_in_time,omitempty"`         // RFC3339; prices and holds use now when omitted
 	Guests      []LoungeIntentGuestRequest    `json:"guests" binding:"required,min=1"`
 	PreOrders   []LoungeIntentPreOrderRequest `json:"pre_orders,omitempty"`
 }
@@ -348,6 +441,14 @@ type LoungeIntentPreOrderRequest struct {
 	Quantity  int    `json:"quantity" binding:"required,min=1"`
 }
 
+// CheckIn returns the requested check-in time, or now when none was given
+func (r *LoungeIntentRequest) CheckIn(now time.Time) (time.Time, error) {
+	if r.CheckInTime == nil || *r.CheckInTime == "" {
+		return now, nil
+	}
+	return time.Parse(time.RFC3339, *r.CheckInTime)
+}
+
 // Validate validates the booking intent request
 func (r *CreateBookingIntentRequest) Validate() error {
 	switch r.IntentType {
@@ -426,25 +527,56 @@ type PriceBreakdown struct {
 	BusFare        float64 `json:"bus_fare"`
 	PreLoungeFare  float64 `json:"pre_lounge_fare"`
 	PostLoungeFare float64 `json:"post_lounge_fare"`
+	BundleDiscount float64 `json:"bundle_discount,omitempty"` // Already taken off the lounge fares
+	PromoCode      *string `json:"promo_code,omitempty"`
+	PromoDiscount  float64 `json:"promo_discount,omitempty"` // Taken off the fares above to reach total
 	Total          float64 `json:"total"`
 	Currency       string  `json:"currency"`
 }
 
+// InitiatePaymentRequest optionally pays part or all of an intent from the wallet
+type InitiatePaymentRequest struct {
+	UseWallet    bool     `json:"use_wallet"`
+	WalletAmount *float64 `json:"wallet_amount,omitempty"` // Defaults to as much of the total as the balance covers
+}
+
+// WalletPayment returns how much of total to take from a wallet holding balance: the requested
+// amount if given, else as much as possible, never more than the balance or the total
+func (r *InitiatePaymentRequest) WalletPayment(balance, total Money) (Money, error) {
+	if r == nil || !r.UseWallet {
+		return Money{}, nil
+	}
+	amount := balance.Min(total)
+	if r.WalletAmount != nil {
+		requested := NewMoney(*r.WalletAmount)
+		if !requested.IsPositive() {
+			return Money{}, &ValidationError{Message: "wallet_amount must be positive"}
+		}
+		if requested.Cmp(balance) > 0 {
+			return Money{}, &ValidationError{Message: "wallet balance is too low"}
+		}
+		amount = requested.Min(total)
+	}
+	return amount.Max(Money{}), nil
+}
+
 // InitiatePaymentResponse is returned when initiating payment
 type InitiatePaymentResponse struct {
 	PaymentURL      string    `json:"payment_url"`
 	InvoiceID       string    `json:"invoice_id"`
-	Amount          string    `json:"amount"`
+	Amount          string    `json:"amount"` // Charged through the gateway
 	Currency        string    `json:"currency"`
 	UID             string    `json:"uid,omitempty"`              // PAYable unique transaction ID
 	StatusIndicator string    `json:"status_indicator,omitempty"` // PAYable status check token
+	WalletAmount    Money     `json:"wallet_amount,omitzero"`     // Paid from the wallet
+	PaidInFull      bool      `json:"paid_in_full,omitempty"`     // The wallet covered everything; confirm the intent next
 	ExpiresAt       time.Time `json:"expires_at"`
 }
 
 // ConfirmBookingRequest is the request to confirm a booking after payment
 type ConfirmBookingRequest struct {
 	IntentID         string  `json:"intent_id" binding:"required"`
-	PaymentReference *string `json:"payment_reference,omitempty"` // Optional if webhook already set it
+	PaymentReference *string `json:"payment_reference,omitempty"` // Only used without PAYable configured; payments are verified with the gateway
 }
 
 // ConfirmBookingResponse is returned after successful confirmation
@@ -455,8 +587,12 @@ type ConfirmBookingResponse struct {
 	PreLoungeBooking  *ConfirmedLoungeBooking `json:"pre_lounge_booking,omitempty"`
 	PostLoungeBooking *ConfirmedLoungeBooking `json:"post_lounge_booking,omitempty"`
 
-	TotalPaid float64 `json:"total_paid"`
-	Currency  string  `json:"currency"`
+	TotalPaid      float64 `json:"total_paid"`
+	PaidFromWallet Money   `json:"paid_from_wallet,omitzero"` // Part of total_paid
+	Currency       string  `json:"currency"`
+
+	// Set when seat prices changed during the hold and the held prices were honored
+	PriceDrift *IntentPriceDrift `json:"price_drift,omitempty"`
 }
 
 // ConfirmedBusBooking represents the confirmed bus booking details
@@ -482,11 +618,44 @@ type GetIntentStatusResponse struct {
 	PriceBreakdown PriceBreakdown       `json:"price_breakdown"`
 	ExpiresAt      time.Time            `json:"expires_at"`
 	IsExpired      bool                 `json:"is_expired"`
+	HoldTTLSeconds int                  `json:"hold_ttl_seconds"`
 
 	// Booking results (if confirmed)
 	Bookings *ConfirmBookingResponse `json:"bookings,omitempty"`
 }
 
+// ActiveIntentResponse is the user's live intent with everything needed to resume checkout
+type ActiveIntentResponse struct {
+	Intent           *BookingIntent `json:"intent"`
+	PriceBreakdown   PriceBreakdown `json:"price_breakdown"`
+	TTLSeconds       int            `json:"ttl_seconds"`       // Remaining TTL for countdown
+	PaymentInitiated bool           `json:"payment_initiated"` // Resume at payment instead of review
+}
+
+// IntentHandoffResponse carries a single-use token for continuing an intent on another device
+type IntentHandoffResponse struct {
+	IntentID   uuid.UUID `json:"intent_id"`
+	Token      string    `json:"token"`
+	DeepLink   string    `json:"deep_link"`
+	QRPayload  string    `json:"qr_payload"` // Rendered as a QR code for the other device to scan
+	ExpiresAt  time.Time `json:"expires_at"`
+	TTLSeconds int       `json:"ttl_seconds"`
+}
+
+// IntentExtensionResponse is an intent's hold after an explicit extension
+type IntentExtensionResponse struct {
+	IntentID            uuid.UUID `json:"intent_id"`
+	ExpiresAt           time.Time `json:"expires_at"`
+	TTLSeconds          int       `json:"ttl_seconds"`
+	ExtensionsUsed      int       `json:"extensions_used"`
+	ExtensionsRemaining int       `json:"extensions_remaining"`
+}
+
+// ClaimIntentHandoffRequest is the request to resume an intent from a handoff token
+type ClaimIntentHandoffRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 // ============================================================================
 // PARTIAL AVAILABILITY ERROR
 // ============================================================================
@@ -538,3 +707,227 @@ type Alternative struct {
 func (e *PartialAvailabilityError) Error() string {
 	return e.Message
 }
+
+// ============================================================================
+// PRICE DRIFT (seat prices changed while an intent is held)
+// ============================================================================
+
+// Price drift policies, chosen by INTENT_PRICE_DRIFT_POLICY
+const (
+	PriceDriftHonorSnapshot   = "honor_snapshot"   // Keep the price locked at hold time
+	PriceDriftRequireReaccept = "require_reaccept" // Passenger must accept the new prices before paying
+)
+
+// SeatPriceChange is one held seat whose price changed after the hold
+type SeatPriceChange struct {
+	TripSeatID   string  `json:"trip_seat_id"`
+	SeatNumber   string  `json:"seat_number"`
+	HeldPrice    float64 `json:"held_price"`
+	CurrentPrice float64 `json:"current_price"`
+}
+
+// IntentPriceDrift compares an intent's locked bus prices with the trip's current seat prices
+type IntentPriceDrift struct {
+	Policy         string            `json:"policy"`
+	Seats          []SeatPriceChange `json:"seats"`
+	HeldBusFare    float64           `json:"held_bus_fare"`
+	CurrentBusFare float64           `json:"current_bus_fare"`
+	HeldTotal      float64           `json:"held_total"`
+	CurrentTotal   float64           `json:"current_total"`
+
+	PriceAdjustment *AppliedPriceAdjustment `json:"price_adjustment,omitempty"` // Trip surcharge/discount in the current prices
+}
+
+// HeldSeatPrice returns the price locked for a seat at hold time. The pricing snapshot is
+// authoritative; intents created before it recorded seat prices fall back to the bus payload.
+func (i *BookingIntent) HeldSeatPrice(seat BusIntentSeat) float64 {
+	if price, ok := i.PricingSnapshot.SeatPrices[seat.TripSeatID]; ok {
+		return price.Float64()
+	}
+	return seat.SeatPrice
+}
+
+// DetectPriceDrift compares the intent's held seat prices with currentPrices (trip seat ID ->
+// price). Seats missing from currentPrices are left out of the comparison. Returns nil when
+// nothing changed.
+func DetectPriceDrift(intent *BookingIntent, currentPrices map[string]float64) *IntentPriceDrift {
+	if intent.BusIntent == nil {
+		return nil
+	}
+
+	var changes []SeatPriceChange
+	var delta Money
+	for _, seat := range intent.BusIntent.Seats {
+		price, ok := currentPrices[seat.TripSeatID]
+		if !ok {
+			continue
+		}
+		current, held := NewMoney(price), NewMoney(intent.HeldSeatPrice(seat))
+		if current.Equal(held) {
+			continue
+		}
+		changes = append(changes, SeatPriceChange{
+			TripSeatID:   seat.TripSeatID,
+			SeatNumber:   seat.SeatNumber,
+			HeldPrice:    held.Float64(),
+			CurrentPrice: current.Float64(),
+		})
+		delta = delta.Add(current.Sub(held))
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return &IntentPriceDrift{
+		Seats:          changes,
+		HeldBusFare:    intent.BusFare,
+		CurrentBusFare: NewMoney(intent.BusFare).Add(delta).Float64(),
+		HeldTotal:      intent.TotalAmount,
+		CurrentTotal:   NewMoney(intent.TotalAmount).Add(delta).Float64(),
+	}
+}
+
+// PriceDriftError is returned when the passenger must accept changed prices before paying
+type PriceDriftError struct {
+	Drift   *IntentPriceDrift `json:"price_drift"`
+	Message string            `json:"message"`
+}
+
+func (e *PriceDriftError) Error() string {
+	return e.Message
+}
+
+// ============================================================================
+// DUPLICATE BOOKINGS
+// ============================================================================
+
+// ExistingTripBooking is a confirmed booking the user, or someone booking for the same
+// passenger phone, already has on a trip
+type ExistingTripBooking struct {
+	BookingID        string         `json:"booking_id" db:"booking_id"`
+	BookingReference string         `json:"booking_reference" db:"booking_reference"`
+	Status           string         `json:"status" db:"status"`
+	SameUser         bool           `json:"same_user" db:"same_user"` // False when only the passenger phone matched
+	TripSeatIDs      pq.StringArray `json:"trip_seat_ids" db:"trip_seat_ids"`
+	SeatNumbers      pq.StringArray `json:"seat_numbers" db:"seat_numbers"`
+	BookedAt         time.Time      `json:"booked_at" db:"booked_at"`
+}
+
+// DuplicateBookingError is returned when an intent would book a trip the passenger already
+// has a booking on; resending with confirm_duplicate books it anyway
+type DuplicateBookingError struct {
+	Existing         []ExistingTripBooking `json:"existing_bookings"`
+	OverlappingSeats []string              `json:"overlapping_seats"` // Requested seats already in those bookings
+	Message          string                `json:"message"`
+}
+
+func (e *DuplicateBookingError) Error() string {
+	return e.Message
+}
+
+// NewDuplicateBookingError describes the existing bookings and which of the requested seats
+// they already hold
+func NewDuplicateBookingError(existing []ExistingTripBooking, requested []BusIntentSeatRequest) *DuplicateBookingError {
+	booked := map[string]bool{}
+	for _, booking := range existing {
+		for _, seatID := range booking.TripSeatIDs {
+			booked[seatID] = true
+		}
+	}
+	overlapping := []string{}
+	for _, seat := range requested {
+		if booked[seat.TripSeatID] {
+			overlapping = append(overlapping, seat.SeatNumber)
+		}
+	}
+
+	references := make([]string, len(existing))
+	who := "This passenger already has"
+	for i, booking := range existing {
+		references[i] = booking.BookingReference
+		if booking.SameUser {
+			who = "You already have"
+		}
+	}
+	message := fmt.Sprintf("%s a booking on this trip (%s). Confirm to book again.", who, strings.Join(references, ", "))
+	if len(overlapping) > 0 {
+		message = fmt.Sprintf("Seats %s are already booked on this trip (%s).", strings.Join(overlapping, ", "), strings.Join(references, ", "))
+	}
+	return &DuplicateBookingError{Existing: existing, OverlappingSeats: overlapping, Message: message}
+}
+
+// PhoneMatchKey returns the last nine digits of a phone number, so 0771234567,
+// +94771234567 and 94 77 123 4567 match; returns "" when there are too few digits
+func PhoneMatchKey(phone string) string {
+	digits := make([]byte, 0, len(phone))
+	for i := 0; i < len(phone); i++ {
+		if phone[i] >= '0' && phone[i] <= '9' {
+			digits = append(digits, phone[i])
+		}
+	}
+	if len(digits) < 9 {
+		return ""
+	}
+	return string(digits[len(digits)-9:])
+}
+
+// ============================================================================
+// FARE QUOTE (pre-login price preview)
+// ============================================================================
+
+// FareQuoteRequest asks for a bus fare preview. Seats is either a seat count ("2") or a
+// comma-separated list of trip seat IDs the passenger has picked on the seat map.
+type FareQuoteRequest struct {
+	BoardingStopID  string `form:"boarding_stop"`
+	AlightingStopID string `form:"alighting_stop"`
+	Seats           string `form:"seats"`
+	SeatType        string `form:"seat_type"` // Only used with a seat count
+}
+
+// FareQuoteStop is a boarding or alighting stop on a quoted trip
+type FareQuoteStop struct {
+	StopID    string `json:"stop_id"`
+	StopName  string `json:"stop_name"`
+	StopOrder int    `json:"stop_order"`
+}
+
+// FareQuoteSeat is one priced seat in a fare quote
+type FareQuoteSeat struct {
+	TripSeatID string  `json:"trip_seat_id"`
+	SeatNumber string  `json:"seat_number"`
+	SeatType   string  `json:"seat_type"`
+	SeatPrice  float64 `json:"seat_price"`
+	Available  bool    `json:"available"`
+}
+
+// FareQuote is a non-binding bus fare preview. Seats are priced exactly as CreateIntent prices
+// them, but nothing is held, so the price and seats are only guaranteed once an intent is created.
+type FareQuote struct {
+	ScheduledTripID   string                  `json:"scheduled_trip_id"`
+	DepartureDatetime time.Time               `json:"departure_datetime"`
+	BoardingStop      *FareQuoteStop          `json:"boarding_stop,omitempty"`
+	AlightingStop     *FareQuoteStop          `json:"alighting_stop,omitempty"`
+	SeatCount         int                     `json:"seat_count"`
+	Seats             []FareQuoteSeat         `json:"seats"`
+	AllAvailable      bool                    `json:"all_available"` // Every quoted seat can be held right now
+	PriceBreakdown    PriceBreakdown          `json:"price_breakdown"`
+	PriceAdjustment   *AppliedPriceAdjustment `json:"price_adjustment,omitempty"` // Owner surcharge/discount in the seat prices
+	Binding           bool                    `json:"binding"`                    // Always false
+	QuotedAt          time.Time               `json:"quoted_at"`
+}
+
+// StuckPaymentIntent is a payment_pending or confirming intent whose payment outcome never
+// reached the backend, as checked by payment reconciliation
+type StuckPaymentIntent struct {
+	ID                     uuid.UUID           `db:"id"`
+	UserID                 uuid.UUID           `db:"user_id"`
+	Status                 BookingIntentStatus `db:"status"`
+	TotalAmount            float64             `db:"total_amount"`
+	AmountDue              float64             `db:"amount_due"` // Total less the wallet payment, charged through PAYable
+	Currency               string              `db:"currency"`
+	PaymentUID             *string             `db:"payment_uid"`
+	PaymentStatusIndicator *string             `db:"payment_status_indicator"`
+	PaymentInitiatedAt     *time.Time          `db:"payment_initiated_at"`
+	UpdatedAt              time.Time           `db:"updated_at"`
+	TenantID               *string             `db:"tenant_id"` // Tenant of the bus trip, whose merchant account took the payment
+}
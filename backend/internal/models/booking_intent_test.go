@@ -0,0 +1,87 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func priceDriftIntent() *BookingIntent {
+	return &BookingIntent{
+		BusIntent: &BusIntentPayload{
+			Seats: []BusIntentSeat{
+				{TripSeatID: "seat-1", SeatNumber: "A1", SeatPrice: 1000},
+				{TripSeatID: "seat-2", SeatNumber: "A2", SeatPrice: 1000},
+			},
+		},
+		BusFare:         2000,
+		PreLoungeFare:   500,
+		TotalAmount:     2500,
+		PricingSnapshot: PricingSnapshot{SeatPrices: map[string]Money{"seat-1": NewMoney(1000), "seat-2": NewMoney(1000)}},
+	}
+}
+
+func TestDetectPriceDrift(t *testing.T) {
+	intent := priceDriftIntent()
+
+	assert.Nil(t, DetectPriceDrift(intent, map[string]float64{"seat-1": 1000, "seat-2": 1000}), "unchanged prices")
+	assert.Nil(t, DetectPriceDrift(intent, map[string]float64{"seat-1": 1000.001}), "rounding noise")
+	assert.Nil(t, DetectPriceDrift(&BookingIntent{}, map[string]float64{"seat-1": 1200}), "no bus intent")
+
+	drift := DetectPriceDrift(intent, map[string]float64{"seat-1": 1200, "seat-2": 1000})
+	require.NotNil(t, drift)
+	require.Len(t, drift.Seats, 1)
+	assert.Equal(t, SeatPriceChange{TripSeatID: "seat-1", SeatNumber: "A1", HeldPrice: 1000, CurrentPrice: 1200}, drift.Seats[0])
+	assert.Equal(t, 2000.0, drift.HeldBusFare)
+	assert.Equal(t, 2200.0, drift.CurrentBusFare)
+	assert.Equal(t, 2500.0, drift.HeldTotal)
+	assert.Equal(t, 2700.0, drift.CurrentTotal, "lounge fares carry over")
+
+	drift = DetectPriceDrift(intent, map[string]float64{"seat-2": 800})
+	require.NotNil(t, drift, "missing seats are skipped, not treated as drift")
+	assert.Equal(t, 1800.0, drift.CurrentBusFare)
+}
+
+func TestHeldSeatPricePrefersSnapshot(t *testing.T) {
+	intent := priceDriftIntent()
+	intent.PricingSnapshot.SeatPrices["seat-1"] = NewMoney(900)
+
+	assert.Equal(t, 900.0, intent.HeldSeatPrice(intent.BusIntent.Seats[0]))
+
+	intent.PricingSnapshot.SeatPrices = nil
+	assert.Equal(t, 1000.0, intent.HeldSeatPrice(intent.BusIntent.Seats[0]), "older intents fall back to the payload")
+}
+
+func TestNewDuplicateBookingError(t *testing.T) {
+	existing := []ExistingTripBooking{{
+		BookingReference: "BK-1001",
+		SameUser:         true,
+		TripSeatIDs:      []string{"seat-a", "seat-b"},
+		SeatNumbers:      []string{"A1", "A2"},
+	}}
+
+	err := NewDuplicateBookingError(existing, []BusIntentSeatRequest{{TripSeatID: "seat-c", SeatNumber: "A3"}})
+	assert.Empty(t, err.OverlappingSeats)
+	assert.Contains(t, err.Message, "BK-1001")
+	assert.Contains(t, err.Message, "You already have a booking")
+
+	existing[0].SameUser = false
+	err = NewDuplicateBookingError(existing, nil)
+	assert.Contains(t, err.Message, "This passenger already has a booking")
+
+	err = NewDuplicateBookingError(existing, []BusIntentSeatRequest{
+		{TripSeatID: "seat-b", SeatNumber: "A2"},
+		{TripSeatID: "seat-c", SeatNumber: "A3"},
+	})
+	assert.Equal(t, []string{"A2"}, err.OverlappingSeats)
+	assert.Contains(t, err.Message, "Seats A2 are already booked")
+}
+
+func TestPhoneMatchKey(t *testing.T) {
+	assert.Equal(t, "771234567", PhoneMatchKey("0771234567"))
+	assert.Equal(t, "771234567", PhoneMatchKey("+94 77 123 4567"))
+	assert.Equal(t, "771234567", PhoneMatchKey("94771234567"))
+	assert.Equal(t, "", PhoneMatchKey("12345"))
+	assert.Equal(t, "", PhoneMatchKey(""))
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+)
+
+// SignedBookingQRPrefix starts every signed boarding QR: "ST1.<payload>.<signature>", both
+// parts unpadded base64url. Older boarding QRs are the bare bus booking QR code.
+const SignedBookingQRPrefix = "ST1."
+
+// QRKeyStatus is the lifecycle state of a QR signing key
+type QRKeyStatus string
+
+const (
+	QRKeyActive  QRKeyStatus = "active"  // Signs new QRs
+	QRKeyRetired QRKeyStatus = "retired" // Replaced; still verifies QRs issued before the rotation
+)
+
+// QRSigningKey is an Ed25519 key boarding QRs are signed with (qr_signing_keys table). The
+// private key is held encrypted at rest when PII encryption is configured.
+type QRSigningKey struct {
+	ID         string      `json:"key_id" db:"id"`
+	PublicKey  string      `json:"public_key" db:"public_key"` // Base64 raw 32-byte Ed25519 public key
+	PrivateKey string      `json:"-" db:"private_key"`         // Base64 32-byte seed
+	Status     QRKeyStatus `json:"status" db:"status"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+	RetiredAt  *time.Time  `json:"retired_at,omitempty" db:"retired_at"`
+}
+
+// QRVerificationKey is a public key conductor apps cache to verify boarding QRs offline
+type QRVerificationKey struct {
+	KeyID      string      `json:"key_id"`
+	Algorithm  string      `json:"algorithm"` // Always "ed25519"
+	PublicKey  string      `json:"public_key"`
+	Status     QRKeyStatus `json:"status"`
+	CreatedAt  time.Time   `json:"created_at"`
+	ValidUntil *time.Time  `json:"valid_until,omitempty"` // Retired keys: QRs signed with it are rejected after this
+}
+
+// BookingQRPayload is what a signed boarding QR carries. Field names are short to keep the
+// QR small; the passenger is only identified by a hash.
+type BookingQRPayload struct {
+	KeyID           string   `json:"k"`
+	QRCode          string   `json:"q"` // The bus booking's QR code, or a seat's ticket code, as looked up online
+	BusBookingID    string   `json:"b"`
+	SeatID          string   `json:"e,omitempty"` // Seat tickets only: the one bus booking seat admitted
+	ScheduledTripID string   `json:"t"`
+	Seats           []string `json:"s,omitempty"` // Seat numbers
+	PassengerHash   string   `json:"p"`
+	DepartureAt     int64    `json:"d,omitempty"` // Unix seconds
+	IssuedAt        int64    `json:"i"`
+	ExpiresAt       int64    `json:"x,omitempty"` // Unix seconds; the QR is rejected afterwards
+}
+
+// PassengerHash identifies a passenger in a boarding QR without carrying their details: the
+// first 16 bytes of SHA-256 over the lower-cased name and phone, unpadded base64url. Conductor
+// apps hash the manifest the same way to match a QR to a passenger offline.
+func PassengerHash(name, phone string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(name)) + "|" + strings.TrimSpace(phone)))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// IsSignedBookingQR reports whether scanned QR data is a signed boarding QR
+func IsSignedBookingQR(data string) bool {
+	return strings.HasPrefix(data, SignedBookingQRPrefix)
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// ReminderKind represents what triggers a booking reminder
+type ReminderKind string
+
+const (
+	ReminderKindDeparture      ReminderKind = "departure"       // Fixed offset before scheduled departure
+	ReminderKindBusApproaching ReminderKind = "bus_approaching" // Bus is close to the boarding stop (live tracking)
+)
+
+// ReminderStatus represents the delivery status of a booking reminder
+type ReminderStatus string
+
+const (
+	ReminderStatusPending   ReminderStatus = "pending"
+	ReminderStatusSent      ReminderStatus = "sent"
+	ReminderStatusCancelled ReminderStatus = "cancelled"
+	ReminderStatusFailed    ReminderStatus = "failed"
+	ReminderStatusExpired   ReminderStatus = "expired"
+)
+
+// BookingReminder is a queued passenger notification for a confirmed booking (booking_reminders table)
+type BookingReminder struct {
+	ID              string         `json:"id" db:"id"`
+	BookingID       string         `json:"booking_id" db:"booking_id"`
+	UserID          string         `json:"user_id" db:"user_id"`
+	ScheduledTripID string         `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	Kind            ReminderKind   `json:"kind" db:"kind"`
+	OffsetMinutes   int            `json:"offset_minutes" db:"offset_minutes"` // Minutes before departure, or ETA threshold for bus_approaching
+	SendAt          *time.Time     `json:"send_at,omitempty" db:"send_at"`     // NULL for bus_approaching (triggered by live location)
+	Status          ReminderStatus `json:"status" db:"status"`
+	Attempts        int            `json:"attempts" db:"attempts"`
+	LastError       *string        `json:"last_error,omitempty" db:"last_error"`
+	SentAt          *time.Time     `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// DueBookingReminder is a pending reminder joined with what is needed to deliver it
+type DueBookingReminder struct {
+	BookingReminder
+
+	BookingReference  string    `db:"booking_reference"`
+	PassengerName     string    `db:"passenger_name"`
+	PassengerPhone    string    `db:"passenger_phone"`
+	RouteName         string    `db:"route_name"`
+	BoardingStopName  string    `db:"boarding_stop_name"`
+	DepartureDatetime time.Time `db:"departure_datetime"`
+
+	// Live tracking (bus_approaching only)
+	BusLatitude   *float64 `db:"bus_latitude"`
+	BusLongitude  *float64 `db:"bus_longitude"`
+	BusSpeedKmh   *float64 `db:"bus_speed_kmh"`
+	StopLatitude  *float64 `db:"stop_latitude"`
+	StopLongitude *float64 `db:"stop_longitude"`
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SeatTicketCodePrefix starts every seat ticket code: "TK-<16 hex>". A group booking's bus
+// booking QR admits the whole group; each seat's ticket admits only its passenger.
+const SeatTicketCodePrefix = "TK-"
+
+// IsSeatTicketCode reports whether a scanned or signed QR code is one seat's ticket
+func IsSeatTicketCode(code string) bool {
+	return strings.HasPrefix(code, SeatTicketCodePrefix)
+}
+
+// SeatTicket is one passenger's ticket within a booking, with its own boarding QR
+type SeatTicket struct {
+	SeatID             string            `json:"seat_id"`
+	SeatNumber         string            `json:"seat_number"`
+	SeatType           string            `json:"seat_type,omitempty"`
+	PassengerName      string            `json:"passenger_name"`
+	IsPrimaryPassenger bool              `json:"is_primary_passenger"`
+	Status             SeatBookingStatus `json:"status"`
+	TicketCode         string            `json:"ticket_code"`
+	// Verifiable offline; empty when signing failed, in which case ticket_code is shown
+	SignedQRCode string `json:"signed_qr_code,omitempty"`
+}
+
+// BookingTicketsResponse is every seat ticket of a booking
+type BookingTicketsResponse struct {
+	BookingID         string       `json:"booking_id"`
+	BookingReference  string       `json:"booking_reference"`
+	RouteName         string       `json:"route_name,omitempty"`
+	DepartureDatetime *time.Time   `json:"departure_datetime,omitempty"`
+	Tickets           []SeatTicket `json:"tickets"` // Cancelled seats included, without a signed QR
+}
+
+// CancelSeatsRequest cancels some of a group booking's seats
+type CancelSeatsRequest struct {
+	SeatIDs []string `json:"seat_ids" binding:"required,min=1"`
+	Reason  string   `json:"reason" binding:"max=500"`
+}
+
+// SeatRefundShare is what one seat cost of what was paid for the booking
+type SeatRefundShare struct {
+	SeatID        string  `json:"seat_id"`
+	SeatNumber    string  `json:"seat_number"`
+	PassengerName string  `json:"passenger_name"`
+	PaidAmount    float64 `json:"paid_amount"`
+}
+
+// SeatCancellationQuote is what cancelling some of a booking's seats now would cost and refund
+type SeatCancellationQuote struct {
+	CancellationQuote
+	Seats []SeatRefundShare `json:"seats"`
+}
+
+// BookingSeatCancellation records seats cancelled out of a booking that stays confirmed, with the
+// refund they were priced at (booking_seat_cancellations table)
+type BookingSeatCancellation struct {
+	ID                uuid.UUID `json:"id" db:"id"`
+	BookingID         string    `json:"booking_id" db:"booking_id"`
+	BusBookingID      string    `json:"bus_booking_id" db:"bus_booking_id"`
+	SeatIDs           []string  `json:"seat_ids" db:"-"`
+	PaidAmount        float64   `json:"paid_amount" db:"paid_amount"`
+	FeePercent        float64   `json:"fee_percent" db:"fee_percent"`
+	CancellationFee   float64   `json:"cancellation_fee" db:"cancellation_fee"`
+	RefundableAmount  float64   `json:"refundable_amount" db:"refundable_amount"`
+	Currency          string    `json:"currency" db:"currency"`
+	Reason            *string   `json:"reason,omitempty" db:"reason"`
+	CancelledByUserID string    `json:"-" db:"cancelled_by_user_id"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewBookingSeatCancellation records cancelling the quoted seats
+func NewBookingSeatCancellation(booking *MasterBooking, quote *SeatCancellationQuote, userID string, reason *string) *BookingSeatCancellation {
+	cancellation := &BookingSeatCancellation{
+		ID:                uuid.New(),
+		BookingID:         booking.ID,
+		PaidAmount:        quote.PaidAmount,
+		FeePercent:        quote.FeePercent,
+		CancellationFee:   quote.Fee,
+		RefundableAmount:  quote.RefundableAmount,
+		Currency:          quote.Currency,
+		Reason:            reason,
+		CancelledByUserID: userID,
+	}
+	if booking.BusBooking != nil {
+		cancellation.BusBookingID = booking.BusBooking.ID
+	}
+	for _, seat := range quote.Seats {
+		cancellation.SeatIDs = append(cancellation.SeatIDs, seat.SeatID)
+	}
+	return cancellation
+}
+
+// SeatsToCancel picks the booking's seats to cancel out of it. Each seat must be one of the
+// booking's and not yet cancelled, and at least one seat must stay booked: cancelling every
+// remaining seat is cancelling the booking.
+func SeatsToCancel(bus *BusBooking, seatIDs []string) ([]BusBookingSeat, error) {
+	if bus == nil {
+		return nil, &ValidationError{Message: "booking has no bus seats"}
+	}
+	wanted := map[string]bool{}
+	for _, id := range seatIDs {
+		wanted[id] = true
+	}
+
+	seats := []BusBookingSeat{}
+	remaining := 0
+	for _, seat := range bus.Seats {
+		if seat.Status == SeatBookingCancelled {
+			if wanted[seat.ID] {
+				return nil, &ValidationError{Message: "seat " + seat.SeatNumber + " is already cancelled"}
+			}
+			continue
+		}
+		if !wanted[seat.ID] {
+			remaining++
+			continue
+		}
+		if seat.Status != SeatBookingPending && seat.Status != SeatBookingBooked {
+			return nil, &ValidationError{Message: "seat " + seat.SeatNumber + " has already been checked in or boarded"}
+		}
+		seats = append(seats, seat)
+		delete(wanted, seat.ID)
+	}
+	if len(wanted) > 0 {
+		return nil, &ValidationError{Message: "seat_ids must be seats of this booking"}
+	}
+	if remaining == 0 {
+		return nil, &ValidationError{Message: "cancelling every remaining seat cancels the booking; cancel the booking instead"}
+	}
+	return seats, nil
+}
+
+// SeatPaidShares splits what was paid for the booking's bus seats across every seat it was
+// booked with, cancelled or not, by seat fare. The bus part of the payment is the bus total's
+// share of the subtotal, so discounts and taxes are shared the same way. Fares come from the
+// booking snapshot, else the seat's trip price; with neither, seats share equally. Rounding is
+// settled on the last seat so the shares add up to the bus part exactly.
+func SeatPaidShares(booking *MasterBooking) map[string]float64 {
+	shares := map[string]float64{}
+	bus := booking.BusBooking
+	if bus == nil || len(bus.Seats) == 0 || booking.Subtotal <= 0 {
+		return shares
+	}
+	busPaid := booking.TotalAmount * booking.BusTotal / booking.Subtotal
+
+	snapshotFares := map[string]float64{}
+	if booking.Snapshot != nil {
+		for _, seat := range booking.Snapshot.Fare.Seats {
+			snapshotFares[seat.SeatNumber] = seat.SeatPrice
+		}
+	}
+	fares := make([]float64, len(bus.Seats))
+	fareTotal := 0.0
+	for i, seat := range bus.Seats {
+		fare, ok := snapshotFares[seat.SeatNumber]
+		if !ok {
+			fare = seat.SeatPrice
+		}
+		fares[i] = fare
+		fareTotal += fare
+	}
+
+	allocated := 0.0
+	for i, seat := range bus.Seats {
+		share := busPaid / float64(len(bus.Seats))
+		if fareTotal > 0 {
+			share = busPaid * fares[i] / fareTotal
+		}
+		if i == len(bus.Seats)-1 {
+			shares[seat.ID] = RoundMoney(busPaid - allocated)
+			break
+		}
+		shares[seat.ID] = RoundMoney(share)
+		allocated += shares[seat.ID]
+	}
+	return shares
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGroupBooking() *MasterBooking {
+	return &MasterBooking{
+		ID:            "b1",
+		BusTotal:      3000,
+		LoungeTotal:   1000,
+		Subtotal:      4000,
+		TotalAmount:   3600, // 10% off everything
+		PaymentStatus: MasterPaymentPaid,
+		BookingStatus: MasterBookingConfirmed,
+		Snapshot: &BookingSnapshot{Fare: SnapshotFare{Seats: []SnapshotSeat{
+			{SeatNumber: "A1", SeatPrice: 1500},
+			{SeatNumber: "A2", SeatPrice: 750},
+		}}},
+		BusBooking: &BusBooking{ID: "bb1", Seats: []BusBookingSeat{
+			{ID: "s1", SeatNumber: "A1", SeatPrice: 1000, Status: SeatBookingBooked, PassengerName: "Nimal"},
+			{ID: "s2", SeatNumber: "A2", SeatPrice: 1000, Status: SeatBookingBooked, PassengerName: "Kamala"},
+			{ID: "s3", SeatNumber: "A3", SeatPrice: 750, Status: SeatBookingBooked, PassengerName: "Sunil"},
+		}},
+	}
+}
+
+func TestSeatsToCancel(t *testing.T) {
+	bus := testGroupBooking().BusBooking
+
+	seats, err := SeatsToCancel(bus, []string{"s2", "s2"})
+	require.NoError(t, err)
+	require.Len(t, seats, 1)
+	assert.Equal(t, "A2", seats[0].SeatNumber)
+
+	_, err = SeatsToCancel(bus, []string{"s1", "s2", "s3"})
+	assert.Error(t, err, "every remaining seat is the whole booking")
+	_, err = SeatsToCancel(bus, []string{"other"})
+	assert.Error(t, err)
+	_, err = SeatsToCancel(nil, []string{"s1"})
+	assert.Error(t, err)
+
+	bus.Seats[0].Status = SeatBookingCheckedIn
+	_, err = SeatsToCancel(bus, []string{"s1"})
+	assert.Error(t, err, "checked in seats stay")
+
+	bus.Seats[0].Status = SeatBookingCancelled
+	_, err = SeatsToCancel(bus, []string{"s1"})
+	assert.Error(t, err, "already cancelled")
+	_, err = SeatsToCancel(bus, []string{"s2", "s3"})
+	assert.Error(t, err, "the cancelled seat does not count as remaining")
+}
+
+func TestSeatPaidShares(t *testing.T) {
+	booking := testGroupBooking()
+
+	// The bus part is 3600 * 3000/4000 = 2700, split 1500:750:750 (snapshot fares, then the trip price)
+	shares := SeatPaidShares(booking)
+	assert.Equal(t, 1350.0, shares["s1"])
+	assert.Equal(t, 675.0, shares["s2"])
+	assert.Equal(t, 675.0, shares["s3"])
+
+	booking.Snapshot = nil
+	for i := range booking.BusBooking.Seats {
+		booking.BusBooking.Seats[i].SeatPrice = 0
+	}
+	booking.TotalAmount, booking.BusTotal, booking.LoungeTotal, booking.Subtotal = 1000, 1000, 0, 1000
+	shares = SeatPaidShares(booking)
+	assert.Equal(t, 333.33, shares["s1"], "no fares splits equally")
+	assert.Equal(t, 333.34, shares["s3"], "rounding is settled on the last seat")
+	assert.InDelta(t, 1000, shares["s1"]+shares["s2"]+shares["s3"], 1e-9)
+
+	booking.Subtotal = 0
+	assert.Empty(t, SeatPaidShares(booking))
+}
+
+func TestNewBookingSeatCancellation(t *testing.T) {
+	booking := testGroupBooking()
+	departure := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	seats, err := SeatsToCancel(booking.BusBooking, []string{"s1", "s3"})
+	require.NoError(t, err)
+
+	quote := CancellationPolicy{Currency: "LKR"}.QuoteSeats(booking, seats, &departure, departure.Add(-48*time.Hour))
+	cancellation := NewBookingSeatCancellation(booking, quote, "user-1", nil)
+	assert.Equal(t, "bb1", cancellation.BusBookingID)
+	assert.Equal(t, []string{"s1", "s3"}, cancellation.SeatIDs)
+	assert.Equal(t, 2025.0, cancellation.PaidAmount)
+	assert.Equal(t, 2025.0, cancellation.RefundableAmount)
+	assert.Equal(t, "LKR", cancellation.Currency)
+}
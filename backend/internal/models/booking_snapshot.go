@@ -0,0 +1,205 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// BookingSnapshotVersion is bumped whenever the snapshot's shape changes so readers can tell
+// old records apart
+const BookingSnapshotVersion = 1
+
+// BookingSnapshot freezes what a passenger bought at booking time: the operator, the route and
+// its stops, the fares charged and the seat layout. Trips, routes and lounges can be edited or
+// deleted afterwards, so receipts and dispute evidence read the snapshot instead of the live
+// records. Stored as JSON on bookings.snapshot and written only once.
+type BookingSnapshot struct {
+	Version    int       `json:"version"`
+	CapturedAt time.Time `json:"captured_at"`
+
+	Operator   *SnapshotOperator   `json:"operator,omitempty"`
+	Trip       *SnapshotTrip       `json:"trip,omitempty"`
+	Route      *SnapshotRoute      `json:"route,omitempty"`
+	Fare       SnapshotFare        `json:"fare"`
+	SeatLayout *SnapshotSeatLayout `json:"seat_layout,omitempty"`
+	Lounges    []SnapshotLounge    `json:"lounges,omitempty"`
+}
+
+// SnapshotOperator is the bus owner running the trip
+type SnapshotOperator struct {
+	BusOwnerID    string  `json:"bus_owner_id"`
+	CompanyName   *string `json:"company_name,omitempty"`
+	BusinessPhone *string `json:"business_phone,omitempty"`
+	BusinessEmail *string `json:"business_email,omitempty"`
+	Address       *string `json:"address,omitempty"`
+}
+
+// SnapshotTrip is the scheduled trip and the bus assigned when the booking was made
+type SnapshotTrip struct {
+	ScheduledTripID   string    `json:"scheduled_trip_id"`
+	DepartureDatetime time.Time `json:"departure_datetime"`
+	DurationMinutes   *int      `json:"estimated_duration_minutes,omitempty"`
+	BusNumber         *string   `json:"bus_number,omitempty"`
+	BusType           *string   `json:"bus_type,omitempty"`
+	PermitNumber      *string   `json:"permit_number,omitempty"`
+}
+
+// SnapshotRoute is the route travelled, with every stop and the passenger's boarding and
+// alighting points
+type SnapshotRoute struct {
+	MasterRouteID     *string        `json:"master_route_id,omitempty"`
+	BusOwnerRouteID   *string        `json:"bus_owner_route_id,omitempty"`
+	RouteNumber       *string        `json:"route_number,omitempty"`
+	RouteName         string         `json:"route_name"`
+	Direction         *string        `json:"direction,omitempty"`
+	BoardingStopID    *string        `json:"boarding_stop_id,omitempty"`
+	BoardingStopName  *string        `json:"boarding_stop_name,omitempty"`
+	AlightingStopID   *string        `json:"alighting_stop_id,omitempty"`
+	AlightingStopName *string        `json:"alighting_stop_name,omitempty"`
+	Stops             []SnapshotStop `json:"stops"`
+}
+
+// SnapshotStop is a route stop as it stood at booking time
+type SnapshotStop struct {
+	StopID                   string   `json:"stop_id" db:"id"`
+	StopName                 string   `json:"stop_name" db:"stop_name"`
+	StopOrder                int      `json:"stop_order" db:"stop_order"`
+	Latitude                 *float64 `json:"latitude,omitempty" db:"latitude"`
+	Longitude                *float64 `json:"longitude,omitempty" db:"longitude"`
+	ArrivalTimeOffsetMinutes *int     `json:"arrival_time_offset_minutes,omitempty" db:"arrival_time_offset_minutes"`
+}
+
+// SnapshotFare records the fare rules that priced the booking and what was charged
+type SnapshotFare struct {
+	Currency       string  `json:"currency"`
+	TripBaseFare   float64 `json:"trip_base_fare"`
+	PermitFare     float64 `json:"permit_approved_fare,omitempty"`
+	FarePerSeat    float64 `json:"fare_per_seat"`
+	BusTotal       float64 `json:"bus_total"`
+	LoungeTotal    float64 `json:"lounge_total"`
+	PreOrderTotal  float64 `json:"pre_order_total"`
+	DiscountAmount float64 `json:"discount_amount"`
+	TaxAmount      float64 `json:"tax_amount"`
+	TotalAmount    float64 `json:"total_amount"`
+	PromoCode      *string `json:"promo_code,omitempty"`
+
+	Seats []SnapshotSeat `json:"seats,omitempty"`
+}
+
+// SnapshotSeat is a booked seat and its price
+type SnapshotSeat struct {
+	SeatNumber    string  `json:"seat_number" db:"seat_number"`
+	SeatType      string  `json:"seat_type" db:"seat_type"`
+	SeatPrice     float64 `json:"seat_price" db:"seat_price"`
+	PassengerName string  `json:"passenger_name" db:"passenger_name"`
+}
+
+// SnapshotSeatLayout identifies the seat layout template the trip used. Templates have no
+// version number, so the template's last update time stands in for one.
+type SnapshotSeatLayout struct {
+	TemplateID   string    `json:"template_id" db:"template_id"`
+	TemplateName string    `json:"template_name" db:"template_name"`
+	TotalSeats   int       `json:"total_seats" db:"total_seats"`
+	Version      time.Time `json:"version" db:"version"`
+}
+
+// SnapshotLounge is a lounge visit booked with the trip and the lounge's rates at the time
+type SnapshotLounge struct {
+	LoungeBookingID  string    `json:"lounge_booking_id" db:"lounge_booking_id"`
+	BookingType      string    `json:"booking_type" db:"booking_type"`
+	LoungeID         string    `json:"lounge_id" db:"lounge_id"`
+	LoungeName       string    `json:"lounge_name" db:"lounge_name"`
+	Address          string    `json:"address" db:"address"`
+	OperatorName     *string   `json:"operator_name,omitempty" db:"operator_name"`
+	ScheduledArrival time.Time `json:"scheduled_arrival" db:"scheduled_arrival"`
+	NumberOfGuests   int       `json:"number_of_guests" db:"number_of_guests"`
+	PricingType      string    `json:"pricing_type" db:"pricing_type"`
+	BasePrice        string    `json:"base_price" db:"base_price"`
+	PreOrderTotal    string    `json:"pre_order_total" db:"pre_order_total"`
+	TotalAmount      string    `json:"total_amount" db:"total_amount"`
+	Price1Hour       *string   `json:"price_1_hour,omitempty" db:"price_1_hour"`
+	Price2Hours      *string   `json:"price_2_hours,omitempty" db:"price_2_hours"`
+	Price3Hours      *string   `json:"price_3_hours,omitempty" db:"price_3_hours"`
+	PriceUntilBus    *string   `json:"price_until_bus,omitempty" db:"price_until_bus"`
+}
+
+func (s BookingSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *BookingSnapshot) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// SnapshotBookingFare fills the charged amounts from the booking itself
+func SnapshotBookingFare(booking *MasterBooking, currency string) SnapshotFare {
+	fare := SnapshotFare{
+		Currency:       currency,
+		BusTotal:       booking.BusTotal,
+		LoungeTotal:    booking.LoungeTotal,
+		PreOrderTotal:  booking.PreOrderTotal,
+		DiscountAmount: booking.DiscountAmount,
+		TaxAmount:      booking.TaxAmount,
+		TotalAmount:    booking.TotalAmount,
+		PromoCode:      booking.PromoCode,
+	}
+	if booking.BusBooking != nil {
+		fare.FarePerSeat = booking.BusBooking.FarePerSeat
+	}
+	return fare
+}
+
+// BookingReceipt is a passenger's proof of purchase. Trip, route and fare details come from
+// the booking snapshot, so the receipt reads the same after owners edit their records;
+// bookings made before snapshots were captured have none.
+type BookingReceipt struct {
+	BookingID        string              `json:"booking_id"`
+	BookingReference string              `json:"booking_reference"`
+	BookingType      BookingType         `json:"booking_type"`
+	BookingStatus    MasterBookingStatus `json:"booking_status"`
+	PassengerName    string              `json:"passenger_name"`
+	PassengerPhone   string              `json:"passenger_phone"`
+	PassengerEmail   *string             `json:"passenger_email,omitempty"`
+
+	PaymentStatus    MasterPaymentStatus `json:"payment_status"`
+	PaymentMethod    *string             `json:"payment_method,omitempty"`
+	PaymentReference *string             `json:"payment_reference,omitempty"`
+	PaidAt           *time.Time          `json:"paid_at,omitempty"`
+	TotalAmount      float64             `json:"total_amount"`
+	RefundAmount     float64             `json:"refund_amount"`
+
+	BookedAt    time.Time        `json:"booked_at"`
+	CancelledAt *time.Time       `json:"cancelled_at,omitempty"`
+	Snapshot    *BookingSnapshot `json:"snapshot,omitempty"`
+}
+
+// NewBookingReceipt builds the receipt for a booking
+func NewBookingReceipt(booking *MasterBooking) *BookingReceipt {
+	return &BookingReceipt{
+		BookingID:        booking.ID,
+		BookingReference: booking.BookingReference,
+		BookingType:      booking.BookingType,
+		BookingStatus:    booking.BookingStatus,
+		PassengerName:    booking.PassengerName,
+		PassengerPhone:   booking.PassengerPhone,
+		PassengerEmail:   booking.PassengerEmail,
+		PaymentStatus:    booking.PaymentStatus,
+		PaymentMethod:    booking.PaymentMethod,
+		PaymentReference: booking.PaymentReference,
+		PaidAt:           booking.PaidAt,
+		TotalAmount:      booking.TotalAmount,
+		RefundAmount:     booking.RefundAmount,
+		BookedAt:         booking.CreatedAt,
+		CancelledAt:      booking.CancelledAt,
+		Snapshot:         booking.Snapshot,
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingSnapshot_ValueScanRoundTrip(t *testing.T) {
+	routeName := "Colombo - Kandy"
+	snapshot := BookingSnapshot{
+		Version:    BookingSnapshotVersion,
+		CapturedAt: time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC),
+		Route:      &SnapshotRoute{RouteName: routeName, Stops: []SnapshotStop{{StopID: "s1", StopName: "Colombo", StopOrder: 1}}},
+		Fare:       SnapshotFare{Currency: "LKR", TotalAmount: 1200, Seats: []SnapshotSeat{{SeatNumber: "A1", SeatPrice: 600}}},
+	}
+
+	value, err := snapshot.Value()
+	require.NoError(t, err)
+
+	var scanned BookingSnapshot
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, snapshot, scanned)
+
+	var empty BookingSnapshot
+	assert.NoError(t, empty.Scan(nil))
+	assert.Error(t, empty.Scan("not bytes"))
+}
+
+func TestSnapshotBookingFare(t *testing.T) {
+	promo := "SAVE10"
+	booking := &MasterBooking{
+		BusTotal: 1200, LoungeTotal: 500, DiscountAmount: 100, TotalAmount: 1600, PromoCode: &promo,
+		BusBooking: &BusBooking{FarePerSeat: 600},
+	}
+
+	fare := SnapshotBookingFare(booking, "LKR")
+	assert.Equal(t, "LKR", fare.Currency)
+	assert.Equal(t, 600.0, fare.FarePerSeat)
+	assert.Equal(t, 1600.0, fare.TotalAmount)
+	assert.Equal(t, &promo, fare.PromoCode)
+
+	assert.Zero(t, SnapshotBookingFare(&MasterBooking{LoungeTotal: 500}, "LKR").FarePerSeat, "lounge-only bookings have no seat fare")
+}
+
+func TestNewBookingReceipt_UsesSnapshot(t *testing.T) {
+	snapshot := &BookingSnapshot{Version: BookingSnapshotVersion, Trip: &SnapshotTrip{ScheduledTripID: "trip-1"}}
+	booking := &MasterBooking{ID: "b1", BookingReference: "BL-1", TotalAmount: 900, Snapshot: snapshot}
+
+	receipt := NewBookingReceipt(booking)
+	assert.Equal(t, "BL-1", receipt.BookingReference)
+	assert.Equal(t, 900.0, receipt.TotalAmount)
+	assert.Same(t, snapshot, receipt.Snapshot)
+
+	assert.Nil(t, NewBookingReceipt(&MasterBooking{ID: "legacy"}).Snapshot)
+}
@@ -38,7 +38,9 @@ type Bus struct {
 	Status              BusStatus  `json:"status" db:"status"`
 
 	// Seat Layout
-	SeatLayoutID *string `json:"seat_layout_id,omitempty" db:"seat_layout_id"`
+	SeatLayoutID     *string `json:"seat_layout_id,omitempty" db:"seat_layout_id"`
+	SeatingCapacity  *int    `json:"seating_capacity,omitempty" db:"seating_capacity"`   // Passenger seats on the vehicle registration
+	StandingCapacity *int    `json:"standing_capacity,omitempty" db:"standing_capacity"` // Standing passengers the bus is licensed for
 
 	// Amenities
 	HasWifi          bool `json:"has_wifi" db:"has_wifi"`
@@ -47,8 +49,9 @@ type Bus struct {
 	HasEntertainment bool `json:"has_entertainment" db:"has_entertainment"`
 	HasRefreshments  bool `json:"has_refreshments" db:"has_refreshments"`
 
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // Soft-deleted; past trips still reference the row
 }
 
 // CreateBusRequest represents the request to create a new bus
@@ -61,6 +64,8 @@ type CreateBusRequest struct {
 	InsuranceExpiry     *string `json:"insurance_expiry,omitempty"`      // Format: YYYY-MM-DD
 	Status              *string `json:"status,omitempty"`
 	SeatLayoutID        *string `json:"seat_layout_id,omitempty"`
+	SeatingCapacity     *int    `json:"seating_capacity,omitempty"`  // Passenger seats on the vehicle registration
+	StandingCapacity    *int    `json:"standing_capacity,omitempty"` // Standing passengers the bus is licensed for
 
 	// Amenities
 	HasWifi          bool `json:"has_wifi"`
@@ -79,6 +84,8 @@ type UpdateBusRequest struct {
 	InsuranceExpiry     *string `json:"insurance_expiry,omitempty"`      // Format: YYYY-MM-DD
 	Status              *string `json:"status,omitempty"`
 	SeatLayoutID        *string `json:"seat_layout_id,omitempty"`
+	SeatingCapacity     *int    `json:"seating_capacity,omitempty"`  // Passenger seats on the vehicle registration
+	StandingCapacity    *int    `json:"standing_capacity,omitempty"` // Standing passengers the bus is licensed for
 
 	// Amenities
 	HasWifi          *bool `json:"has_wifi,omitempty"`
@@ -105,6 +112,13 @@ func (req *CreateBusRequest) Validate() error {
 		}
 	}
 
+	if req.SeatingCapacity != nil && (*req.SeatingCapacity < 1 || *req.SeatingCapacity > 120) {
+		return errors.New("invalid seating_capacity: must be between 1 and 120")
+	}
+	if req.StandingCapacity != nil && (*req.StandingCapacity < 0 || *req.StandingCapacity > 100) {
+		return errors.New("invalid standing_capacity: must be between 0 and 100")
+	}
+
 	// Validate status if provided
 	if req.Status != nil {
 		status := BusStatus(*req.Status)
@@ -135,6 +149,13 @@ func (req *UpdateBusRequest) Validate() error {
 		}
 	}
 
+	if req.SeatingCapacity != nil && (*req.SeatingCapacity < 1 || *req.SeatingCapacity > 120) {
+		return errors.New("invalid seating_capacity: must be between 1 and 120")
+	}
+	if req.StandingCapacity != nil && (*req.StandingCapacity < 0 || *req.StandingCapacity > 100) {
+		return errors.New("invalid standing_capacity: must be between 0 and 100")
+	}
+
 	// Validate status if provided
 	if req.Status != nil {
 		status := BusStatus(*req.Status)
@@ -0,0 +1,218 @@
+package models
+
+import "fmt"
+
+// OnboardingStep is one step of setting up a bus owner account to sell trips
+type OnboardingStep string
+
+const (
+	OnboardingStepProfile      OnboardingStep = "profile"
+	OnboardingStepVerification OnboardingStep = "verification"
+	OnboardingStepPermits      OnboardingStep = "permits"
+	OnboardingStepBuses        OnboardingStep = "buses"
+	OnboardingStepRoutes       OnboardingStep = "routes"
+	OnboardingStepSchedules    OnboardingStep = "schedules"
+	OnboardingStepStaff        OnboardingStep = "staff"
+)
+
+// onboardingStepDefinition describes a step, in the order new owners are guided through them
+type onboardingStepDefinition struct {
+	step     OnboardingStep
+	title    string
+	message  string
+	deepLink string // Owner app screen for this step
+	endpoint string // Where the app sends the data for this step
+}
+
+var onboardingSteps = []onboardingStepDefinition{
+	{OnboardingStepProfile, "Complete your business profile", "Add your company details and at least one route permit.", "smarttransit-owner://onboarding/profile", "POST /api/v1/bus-owner/complete-onboarding"},
+	{OnboardingStepVerification, "Get your account verified", "SmartTransit checks your business details before you can operate.", "smarttransit-owner://onboarding/verification", "GET /api/v1/bus-owner/profile-status"},
+	{OnboardingStepPermits, "Have a route permit verified", "Trips can only run on a verified, unexpired route permit.", "smarttransit-owner://permits", "POST /api/v1/permits"},
+	{OnboardingStepBuses, "Add a bus", "Register an active bus under one of your permits.", "smarttransit-owner://buses/new", "POST /api/v1/buses"},
+	{OnboardingStepRoutes, "Set up a route", "Pick the stops your bus serves on a permitted route.", "smarttransit-owner://routes/new", "POST /api/v1/bus-owner-routes"},
+	{OnboardingStepSchedules, "Create a trip schedule", "Schedules generate the trips passengers can book.", "smarttransit-owner://schedules/new", "POST /api/v1/trip-schedules"},
+	{OnboardingStepStaff, "Add a driver and a conductor", "Trips need crew assigned before they start.", "smarttransit-owner://staff", "POST /api/v1/bus-owner/staff"},
+}
+
+// BusOwnerOnboardingCounts is what a bus owner has set up so far
+type BusOwnerOnboardingCounts struct {
+	PermitsTotal    int `db:"permits_total"`
+	PermitsValid    int `db:"permits_valid"` // Verified and not expired
+	PermitsPending  int `db:"permits_pending"`
+	PermitsRejected int `db:"permits_rejected"`
+	PermitsExpired  int `db:"permits_expired"`
+	BusesTotal      int `db:"buses_total"`
+	BusesActive     int `db:"buses_active"`
+	Routes          int `db:"routes"`
+	SchedulesActive int `db:"schedules_active"`
+	Drivers         int `db:"drivers"`
+	Conductors      int `db:"conductors"`
+	StaffRequests   int `db:"staff_requests"` // Join requests awaiting the owner's approval
+}
+
+// OnboardingStepProgress reports one checklist item
+type OnboardingStepProgress struct {
+	Step            OnboardingStep `json:"step"`
+	Title           string         `json:"title"`
+	Message         string         `json:"message"`
+	Completed       bool           `json:"completed"`
+	Blocked         bool           `json:"blocked"`                    // Cannot be done until the blocking reasons are resolved
+	BlockingReasons []string       `json:"blocking_reasons,omitempty"` // Why the step is not complete or cannot be started
+	DeepLink        string         `json:"deep_link"`
+	Endpoint        string         `json:"endpoint"`
+}
+
+// BusOwnerOnboardingProgress is the owner's onboarding checklist
+type BusOwnerOnboardingProgress struct {
+	Steps          []OnboardingStepProgress `json:"steps"`
+	CompletedSteps int                      `json:"completed_steps"`
+	TotalSteps     int                      `json:"total_steps"`
+	Percent        int                      `json:"percent"` // 0-100
+	IsComplete     bool                     `json:"is_complete"`
+	NextStep       *OnboardingStep          `json:"next_step,omitempty"` // First step that can be worked on now
+}
+
+// NewBusOwnerOnboardingProgress builds the checklist for an owner; a nil owner has not started
+// onboarding and nil counts mean nothing has been set up
+func NewBusOwnerOnboardingProgress(owner *BusOwner, counts *BusOwnerOnboardingCounts) *BusOwnerOnboardingProgress {
+	if counts == nil {
+		counts = &BusOwnerOnboardingCounts{}
+	}
+	profileDone := owner != nil && owner.ProfileCompleted
+	verified := owner != nil && owner.VerificationStatus == VerificationVerified
+
+	var needsVerification []string
+	if !verified {
+		needsVerification = []string{"Your account must be verified first"}
+	}
+
+	result := &BusOwnerOnboardingProgress{
+		Steps:      make([]OnboardingStepProgress, 0, len(onboardingSteps)),
+		TotalSteps: len(onboardingSteps),
+	}
+	for _, def := range onboardingSteps {
+		item := OnboardingStepProgress{
+			Step:     def.step,
+			Title:    def.title,
+			Message:  def.message,
+			DeepLink: def.deepLink,
+			Endpoint: def.endpoint,
+		}
+
+		switch def.step {
+		case OnboardingStepProfile:
+			item.Completed = profileDone
+
+		case OnboardingStepVerification:
+			item.Completed = verified
+			switch {
+			case !profileDone:
+				item.Blocked = true
+				item.BlockingReasons = []string{"Complete your business profile first"}
+			case owner.VerificationStatus == VerificationRejected:
+				item.Blocked = true
+				item.BlockingReasons = []string{"Verification was rejected; contact support to resubmit your details"}
+			case !verified:
+				item.Blocked = true
+				item.BlockingReasons = []string{"Awaiting review by SmartTransit"}
+			}
+
+		case OnboardingStepPermits:
+			item.Completed = counts.PermitsValid > 0
+			switch {
+			case item.Completed:
+			case !profileDone:
+				// The first permits are submitted with the business profile
+				item.Blocked = true
+				item.BlockingReasons = []string{"Complete your business profile first"}
+			default:
+				// Permits under review wait on SmartTransit, and adding more needs a verified account
+				item.BlockingReasons = permitBlockingReasons(counts)
+				item.Blocked = counts.PermitsPending > 0 || !verified
+				if counts.PermitsPending == 0 && !verified {
+					item.BlockingReasons = append(item.BlockingReasons, needsVerification...)
+				}
+			}
+
+		case OnboardingStepBuses:
+			item.Completed = counts.BusesActive > 0
+			if !item.Completed {
+				item.Blocked, item.BlockingReasons = blockedUnless(verified, needsVerification,
+					counts.PermitsValid > 0, "Add a bus once a route permit is verified")
+				if counts.BusesTotal > 0 {
+					item.BlockingReasons = append(item.BlockingReasons, "None of your buses is active")
+				}
+			}
+
+		case OnboardingStepRoutes:
+			item.Completed = counts.Routes > 0
+			if !item.Completed {
+				item.Blocked, item.BlockingReasons = blockedUnless(verified, needsVerification,
+					counts.PermitsValid > 0, "Set up a route once a route permit is verified")
+			}
+
+		case OnboardingStepSchedules:
+			item.Completed = counts.SchedulesActive > 0
+			if !item.Completed {
+				item.Blocked, item.BlockingReasons = blockedUnless(verified, needsVerification,
+					counts.Routes > 0, "Set up a route before creating schedules")
+			}
+
+		case OnboardingStepStaff:
+			item.Completed = counts.Drivers > 0 && counts.Conductors > 0
+			if !item.Completed {
+				item.Blocked, item.BlockingReasons = blockedUnless(verified, needsVerification, true, "")
+				if counts.Drivers == 0 {
+					item.BlockingReasons = append(item.BlockingReasons, "No driver added yet")
+				}
+				if counts.Conductors == 0 {
+					item.BlockingReasons = append(item.BlockingReasons, "No conductor added yet")
+				}
+				if counts.StaffRequests > 0 {
+					item.BlockingReasons = append(item.BlockingReasons, fmt.Sprintf("%d staff request(s) awaiting your approval", counts.StaffRequests))
+				}
+			}
+		}
+
+		if item.Completed {
+			result.CompletedSteps++
+		} else if !item.Blocked && result.NextStep == nil {
+			step := def.step
+			result.NextStep = &step
+		}
+		result.Steps = append(result.Steps, item)
+	}
+
+	result.Percent = result.CompletedSteps * 100 / result.TotalSteps
+	result.IsComplete = result.CompletedSteps == result.TotalSteps
+	return result
+}
+
+// permitBlockingReasons explains why none of the owner's permits is usable
+func permitBlockingReasons(counts *BusOwnerOnboardingCounts) []string {
+	if counts.PermitsTotal == 0 {
+		return []string{"No route permit added yet"}
+	}
+	var reasons []string
+	if counts.PermitsPending > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d permit(s) pending verification", counts.PermitsPending))
+	}
+	if counts.PermitsRejected > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d permit(s) rejected", counts.PermitsRejected))
+	}
+	if counts.PermitsExpired > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d permit(s) expired", counts.PermitsExpired))
+	}
+	return reasons
+}
+
+// blockedUnless blocks a step until the account is verified and its prerequisite is met
+func blockedUnless(verified bool, needsVerification []string, prerequisiteMet bool, prerequisite string) (bool, []string) {
+	if !verified {
+		return true, append([]string(nil), needsVerification...)
+	}
+	if !prerequisiteMet {
+		return true, []string{prerequisite}
+	}
+	return false, nil
+}
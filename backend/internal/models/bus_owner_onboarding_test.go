@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func onboardingStep(t *testing.T, progress *BusOwnerOnboardingProgress, step OnboardingStep) OnboardingStepProgress {
+	t.Helper()
+	for _, item := range progress.Steps {
+		if item.Step == step {
+			return item
+		}
+	}
+	t.Fatalf("step %s missing", step)
+	return OnboardingStepProgress{}
+}
+
+func TestOnboardingProgress_NotStarted(t *testing.T) {
+	progress := NewBusOwnerOnboardingProgress(nil, nil)
+
+	assert.Len(t, progress.Steps, 7)
+	assert.Equal(t, 0, progress.Percent)
+	require.NotNil(t, progress.NextStep)
+	assert.Equal(t, OnboardingStepProfile, *progress.NextStep)
+	assert.True(t, onboardingStep(t, progress, OnboardingStepVerification).Blocked)
+	assert.True(t, onboardingStep(t, progress, OnboardingStepBuses).Blocked)
+}
+
+func TestOnboardingProgress_AwaitingVerification(t *testing.T) {
+	owner := &BusOwner{ProfileCompleted: true, VerificationStatus: VerificationPending}
+	progress := NewBusOwnerOnboardingProgress(owner, &BusOwnerOnboardingCounts{PermitsTotal: 1, PermitsPending: 1})
+
+	assert.Equal(t, 1, progress.CompletedSteps)
+	assert.Nil(t, progress.NextStep, "everything waits on SmartTransit")
+	verification := onboardingStep(t, progress, OnboardingStepVerification)
+	assert.True(t, verification.Blocked)
+	assert.Equal(t, []string{"Awaiting review by SmartTransit"}, verification.BlockingReasons)
+	assert.Equal(t, []string{"1 permit(s) pending verification"}, onboardingStep(t, progress, OnboardingStepPermits).BlockingReasons)
+}
+
+func TestOnboardingProgress_VerifiedOwnerSettingUp(t *testing.T) {
+	owner := &BusOwner{ProfileCompleted: true, VerificationStatus: VerificationVerified}
+	counts := &BusOwnerOnboardingCounts{PermitsTotal: 2, PermitsValid: 1, PermitsExpired: 1, BusesTotal: 1, BusesActive: 1, Drivers: 1, StaffRequests: 2}
+	progress := NewBusOwnerOnboardingProgress(owner, counts)
+
+	assert.Equal(t, 4, progress.CompletedSteps)
+	assert.Equal(t, 57, progress.Percent)
+	require.NotNil(t, progress.NextStep)
+	assert.Equal(t, OnboardingStepRoutes, *progress.NextStep)
+
+	schedules := onboardingStep(t, progress, OnboardingStepSchedules)
+	assert.True(t, schedules.Blocked)
+	assert.Equal(t, []string{"Set up a route before creating schedules"}, schedules.BlockingReasons)
+
+	staff := onboardingStep(t, progress, OnboardingStepStaff)
+	assert.False(t, staff.Blocked)
+	assert.Equal(t, []string{"No conductor added yet", "2 staff request(s) awaiting your approval"}, staff.BlockingReasons)
+}
+
+func TestOnboardingProgress_Complete(t *testing.T) {
+	owner := &BusOwner{ProfileCompleted: true, VerificationStatus: VerificationVerified}
+	counts := &BusOwnerOnboardingCounts{PermitsTotal: 1, PermitsValid: 1, BusesTotal: 1, BusesActive: 1, Routes: 1, SchedulesActive: 3, Drivers: 2, Conductors: 1}
+	progress := NewBusOwnerOnboardingProgress(owner, counts)
+
+	assert.True(t, progress.IsComplete)
+	assert.Equal(t, 100, progress.Percent)
+	assert.Nil(t, progress.NextStep)
+}
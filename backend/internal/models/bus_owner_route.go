@@ -17,6 +17,7 @@ type BusOwnerRoute struct {
 	SelectedStopIDs  pq.StringArray `json:"selected_stop_ids" db:"selected_stop_ids"`
 	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
+	DeletedAt        *time.Time     `json:"deleted_at,omitempty" db:"deleted_at"` // Hidden from the owner's routes once set
 }
 
 // CreateBusOwnerRouteRequest represents the request to create a custom route
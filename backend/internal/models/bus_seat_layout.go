@@ -1,6 +1,10 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +17,7 @@ type BusSeatLayoutTemplate struct {
 	TotalRows    int        `json:"total_rows" db:"total_rows"`
 	TotalSeats   int        `json:"total_seats" db:"total_seats"`
 	Description  *string    `json:"description,omitempty" db:"description"`
+	LayoutMetadata *SeatLayoutMetadata `json:"layout_metadata,omitempty" db:"layout_metadata"`
 	IsActive     bool       `json:"is_active" db:"is_active"`
 	CreatedBy    uuid.UUID  `json:"created_by" db:"created_by"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
@@ -39,6 +44,7 @@ type CreateBusSeatLayoutTemplateRequest struct {
 	TotalRows    int                          `json:"total_rows" binding:"required,min=1,max=20"`
 	Description  *string                      `json:"description"`
 	SeatMap      [][]bool                     `json:"seat_map" binding:"required"` // 2D array: [row][position] true=seat exists
+	LayoutMetadata *SeatLayoutMetadata        `json:"layout_metadata"`             // Optional rendering hints (defaults applied if omitted)
 }
 
 // UpdateBusSeatLayoutTemplateRequest represents the request to update a layout template
@@ -46,6 +52,7 @@ type UpdateBusSeatLayoutTemplateRequest struct {
 	TemplateName *string  `json:"template_name"`
 	Description  *string  `json:"description"`
 	IsActive     *bool    `json:"is_active"`
+	LayoutMetadata *SeatLayoutMetadata `json:"layout_metadata"`
 }
 
 // BusSeatLayoutTemplateResponse represents the detailed response with seats
@@ -60,6 +67,7 @@ type BusSeatLayoutTemplateResponse struct {
 	CreatedAt    time.Time           `json:"created_at"`
 	UpdatedAt    time.Time           `json:"updated_at"`
 	Seats        []BusSeatLayoutSeat `json:"seats"`
+	LayoutMetadata SeatLayoutMetadata `json:"layout_metadata"`
 	LayoutPreview BusLayoutPreview   `json:"layout_preview"`
 }
 
@@ -72,6 +80,7 @@ type BusLayoutPreview struct {
 type BusRow struct {
 	RowNumber int        `json:"row_number"`
 	RowLabel  string     `json:"row_label"`
+	Deck      int        `json:"deck"`
 	LeftSeats []SeatInfo `json:"left_seats"`
 	RightSeats []SeatInfo `json:"right_seats"`
 }
@@ -83,3 +92,214 @@ type SeatInfo struct {
 	IsWindowSeat bool   `json:"is_window_seat"`
 	IsAisleSeat  bool   `json:"is_aisle_seat"`
 }
+
+// ============================================================================
+// RENDERING METADATA (layout_metadata JSONB)
+// ============================================================================
+
+// SeatLayoutPositionsPerRow is the number of seat positions in every layout row (3 left + 3 right)
+const SeatLayoutPositionsPerRow = 6
+
+// SeatLayoutOrientation tells clients which way the front of the bus faces on screen
+type SeatLayoutOrientation string
+
+const (
+	SeatLayoutOrientationFrontTop    SeatLayoutOrientation = "front_top"    // Driver at the top, rows go down
+	SeatLayoutOrientationFrontBottom SeatLayoutOrientation = "front_bottom" // Driver at the bottom, rows go up
+	SeatLayoutOrientationFrontLeft   SeatLayoutOrientation = "front_left"   // Landscape, driver on the left
+	SeatLayoutOrientationFrontRight  SeatLayoutOrientation = "front_right"  // Landscape, driver on the right
+)
+
+// BusSide is a side of the bus, as seen facing the front
+type BusSide string
+
+const (
+	BusSideLeft  BusSide = "left"
+	BusSideRight BusSide = "right"
+)
+
+// BusDoorType identifies the purpose of a door
+type BusDoorType string
+
+const (
+	BusDoorTypeFront     BusDoorType = "front"
+	BusDoorTypeMiddle    BusDoorType = "middle"
+	BusDoorTypeRear      BusDoorType = "rear"
+	BusDoorTypeEmergency BusDoorType = "emergency"
+)
+
+// SeatLayoutMetadata holds presentation hints so every client renders the same seat map
+type SeatLayoutMetadata struct {
+	Orientation    SeatLayoutOrientation `json:"orientation"`
+	DriverSide     BusSide               `json:"driver_side"`
+	AislePositions []int                 `json:"aisle_positions"` // Aisle gap comes after each listed position (3 = between positions 3 and 4)
+	Decks          []SeatLayoutDeck      `json:"decks"`
+	Doors          []SeatLayoutDoor      `json:"doors"`
+}
+
+// SeatLayoutDeck maps a contiguous range of rows to a deck
+type SeatLayoutDeck struct {
+	Deck     int    `json:"deck"` // 1 = lower/main deck, 2 = upper deck
+	Label    string `json:"label"`
+	StartRow int    `json:"start_row"`
+	EndRow   int    `json:"end_row"`
+}
+
+// SeatLayoutDoor marks a door next to a row
+type SeatLayoutDoor struct {
+	Deck int         `json:"deck"`
+	Row  int         `json:"row"` // Door is located before this row
+	Side BusSide     `json:"side"`
+	Type BusDoorType `json:"type"`
+}
+
+// Value implements driver.Valuer for JSONB storage
+func (m SeatLayoutMetadata) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner for JSONB storage
+func (m *SeatLayoutMetadata) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, m)
+}
+
+// DefaultSeatLayoutMetadata returns the standard single-deck layout used when no hints are given:
+// driver on the right, one aisle in the middle and a front door on the kerb (left) side
+func DefaultSeatLayoutMetadata(totalRows int) SeatLayoutMetadata {
+	return SeatLayoutMetadata{
+		Orientation:    SeatLayoutOrientationFrontTop,
+		DriverSide:     BusSideRight,
+		AislePositions: []int{3},
+		Decks: []SeatLayoutDeck{
+			{Deck: 1, Label: "Main Deck", StartRow: 1, EndRow: totalRows},
+		},
+		Doors: []SeatLayoutDoor{
+			{Deck: 1, Row: 1, Side: BusSideLeft, Type: BusDoorTypeFront},
+		},
+	}
+}
+
+// ApplyDefaults fills in any hints that were left empty
+func (m *SeatLayoutMetadata) ApplyDefaults(totalRows int) {
+	defaults := DefaultSeatLayoutMetadata(totalRows)
+	if m.Orientation == "" {
+		m.Orientation = defaults.Orientation
+	}
+	if m.DriverSide == "" {
+		m.DriverSide = defaults.DriverSide
+	}
+	if m.AislePositions == nil {
+		m.AislePositions = defaults.AislePositions
+	}
+	if len(m.Decks) == 0 {
+		m.Decks = defaults.Decks
+	}
+	if m.Doors == nil {
+		m.Doors = defaults.Doors
+	}
+	for i := range m.Doors {
+		if m.Doors[i].Deck == 0 {
+			m.Doors[i].Deck = 1
+		}
+	}
+}
+
+// Validate checks the hints against the template's row count
+func (m *SeatLayoutMetadata) Validate(totalRows int) error {
+	switch m.Orientation {
+	case SeatLayoutOrientationFrontTop, SeatLayoutOrientationFrontBottom,
+		SeatLayoutOrientationFrontLeft, SeatLayoutOrientationFrontRight:
+	default:
+		return fmt.Errorf("invalid orientation: %s", m.Orientation)
+	}
+
+	if !isValidBusSide(m.DriverSide) {
+		return fmt.Errorf("invalid driver_side: %s", m.DriverSide)
+	}
+
+	seenAisle := make(map[int]bool)
+	for _, pos := range m.AislePositions {
+		if pos < 1 || pos >= SeatLayoutPositionsPerRow {
+			return fmt.Errorf("aisle position %d must be between 1 and %d", pos, SeatLayoutPositionsPerRow-1)
+		}
+		if seenAisle[pos] {
+			return fmt.Errorf("duplicate aisle position %d", pos)
+		}
+		seenAisle[pos] = true
+	}
+
+	// Decks must cover every row exactly once, in order
+	decks := make(map[int]bool)
+	nextRow := 1
+	for _, deck := range m.Decks {
+		if deck.Deck < 1 || deck.Deck > 2 {
+			return fmt.Errorf("deck must be 1 or 2, got %d", deck.Deck)
+		}
+		if decks[deck.Deck] {
+			return fmt.Errorf("deck %d is defined more than once", deck.Deck)
+		}
+		if deck.StartRow != nextRow || deck.EndRow < deck.StartRow {
+			return fmt.Errorf("deck %d rows %d-%d must continue from row %d", deck.Deck, deck.StartRow, deck.EndRow, nextRow)
+		}
+		decks[deck.Deck] = true
+		nextRow = deck.EndRow + 1
+	}
+	if nextRow != totalRows+1 {
+		return fmt.Errorf("decks must cover all %d rows", totalRows)
+	}
+
+	for _, door := range m.Doors {
+		if !decks[door.Deck] {
+			return fmt.Errorf("door references unknown deck %d", door.Deck)
+		}
+		if door.Row < 1 || door.Row > totalRows+1 {
+			return fmt.Errorf("door row %d is out of range (1-%d)", door.Row, totalRows+1)
+		}
+		if !isValidBusSide(door.Side) {
+			return fmt.Errorf("invalid door side: %s", door.Side)
+		}
+		switch door.Type {
+		case BusDoorTypeFront, BusDoorTypeMiddle, BusDoorTypeRear, BusDoorTypeEmergency:
+		default:
+			return fmt.Errorf("invalid door type: %s", door.Type)
+		}
+	}
+
+	return nil
+}
+
+// ResolvedLayoutMetadata returns the template's rendering hints with defaults filled in
+func (t *BusSeatLayoutTemplate) ResolvedLayoutMetadata() SeatLayoutMetadata {
+	if t.LayoutMetadata == nil {
+		return DefaultSeatLayoutMetadata(t.TotalRows)
+	}
+	meta := *t.LayoutMetadata
+	meta.ApplyDefaults(t.TotalRows)
+	return meta
+}
+
+// DeckForRow returns the deck a row belongs to (1 if unmapped)
+func (m *SeatLayoutMetadata) DeckForRow(row int) int {
+	for _, deck := range m.Decks {
+		if row >= deck.StartRow && row <= deck.EndRow {
+			return deck.Deck
+		}
+	}
+	return 1
+}
+
+func isValidBusSide(side BusSide) bool {
+	return side == BusSideLeft || side == BusSideRight
+}
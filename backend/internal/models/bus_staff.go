@@ -89,6 +89,7 @@ type StaffRegistrationInput struct {
 	ExperienceYears      int       `json:"experience_years"`
 	EmergencyContact     string    `json:"emergency_contact" binding:"required"`
 	EmergencyContactName string    `json:"emergency_contact_name" binding:"required"`
+	BusOwnerID           *string   `json:"bus_owner_id"` // Bus owner to ask to join; the owner approves or rejects
 }
 
 // StaffProfileUpdate represents input for profile updates
@@ -155,3 +156,57 @@ type UnlinkStaffRequest struct {
 	TerminationReason string `json:"termination_reason"`
 	Status            string `json:"status"` // "terminated" or "resigned"
 }
+
+// JoinBusOwnerRequest is a registered staff member asking to join a bus owner
+type JoinBusOwnerRequest struct {
+	BusOwnerID string `json:"bus_owner_id" binding:"required"`
+}
+
+// RejectStaffRequestInput is a bus owner's reason for turning down a join request
+type RejectStaffRequestInput struct {
+	Reason string `json:"reason" binding:"required,min=3,max=500"`
+}
+
+// StaffDocument is a credential a staff member submitted at registration; owners review
+// these before approving a join request
+type StaffDocument struct {
+	Type       string     `json:"type"` // "driving_license" or "ntc_license"
+	Number     *string    `json:"number,omitempty"`
+	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
+	Expired    bool       `json:"expired"`
+}
+
+// PendingStaffRequest is a staff member waiting for a bus owner to approve them
+type PendingStaffRequest struct {
+	EmploymentID         string                  `json:"employment_id"`
+	StaffID              string                  `json:"staff_id"`
+	UserID               string                  `json:"user_id"`
+	FirstName            *string                 `json:"first_name,omitempty"`
+	LastName             *string                 `json:"last_name,omitempty"`
+	Phone                string                  `json:"phone"`
+	StaffType            StaffType               `json:"staff_type"`
+	ExperienceYears      int                     `json:"experience_years"`
+	EmergencyContact     *string                 `json:"emergency_contact,omitempty"`
+	EmergencyContactName *string                 `json:"emergency_contact_name,omitempty"`
+	VerificationStatus   StaffVerificationStatus `json:"verification_status"`
+	Documents            []StaffDocument         `json:"documents"`
+	RequestedAt          time.Time               `json:"requested_at"`
+}
+
+// StaffDocuments lists the credentials on a staff profile as of now. Drivers hold a driving
+// license; conductors hold an NTC conductor license.
+func StaffDocuments(staff *BusStaff, now time.Time) []StaffDocument {
+	if staff.LicenseNumber == nil && staff.LicenseExpiryDate == nil {
+		return []StaffDocument{}
+	}
+	doc := StaffDocument{
+		Type:       "driving_license",
+		Number:     staff.LicenseNumber,
+		ExpiryDate: staff.LicenseExpiryDate,
+		Expired:    staff.LicenseExpiryDate != nil && staff.LicenseExpiryDate.Before(now),
+	}
+	if staff.StaffType == StaffTypeConductor {
+		doc.Type = "ntc_license"
+	}
+	return []StaffDocument{doc}
+}
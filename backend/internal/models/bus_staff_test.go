@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaffDocuments(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	license := "B1234567"
+	expired := now.AddDate(0, -1, 0)
+	valid := now.AddDate(1, 0, 0)
+
+	assert.Empty(t, StaffDocuments(&BusStaff{StaffType: StaffTypeDriver}, now))
+
+	docs := StaffDocuments(&BusStaff{StaffType: StaffTypeDriver, LicenseNumber: &license, LicenseExpiryDate: &valid}, now)
+	if assert.Len(t, docs, 1) {
+		assert.Equal(t, "driving_license", docs[0].Type)
+		assert.Equal(t, &license, docs[0].Number)
+		assert.False(t, docs[0].Expired)
+	}
+
+	docs = StaffDocuments(&BusStaff{StaffType: StaffTypeConductor, LicenseNumber: &license, LicenseExpiryDate: &expired}, now)
+	if assert.Len(t, docs, 1) {
+		assert.Equal(t, "ntc_license", docs[0].Type)
+		assert.True(t, docs[0].Expired)
+	}
+}
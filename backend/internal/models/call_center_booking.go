@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateCallCenterBookingRequest is a call-center agent booking for a passenger over the phone.
+// The passenger gets the payment link by SMS and the booking confirms once they pay.
+type CreateCallCenterBookingRequest struct {
+	Phone   string                     `json:"phone" binding:"required"` // Passenger's mobile; the booking is made on their account
+	Booking CreateBookingIntentRequest `json:"booking" binding:"required"`
+	Notes   *string                    `json:"notes,omitempty" binding:"omitempty,max=500"`
+}
+
+// CallCenterBooking records an intent an agent created on a passenger's behalf, with the
+// payment link sent to them. Intent fields are joined in so agents can follow up on unpaid links.
+type CallCenterBooking struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	IntentID        uuid.UUID  `json:"intent_id" db:"intent_id"`
+	AgentUserID     uuid.UUID  `json:"agent_user_id" db:"agent_user_id"`
+	PassengerUserID uuid.UUID  `json:"passenger_user_id" db:"passenger_user_id"`
+	PassengerPhone  string     `json:"passenger_phone" db:"passenger_phone"`
+	PaymentURL      string     `json:"payment_url" db:"payment_url"`
+	Notes           *string    `json:"notes,omitempty" db:"notes"`
+	SMSCount        int        `json:"sms_count" db:"sms_count"` // Payment link SMS sent, including resends
+	LastSMSAt       *time.Time `json:"last_sms_at,omitempty" db:"last_sms_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+
+	IntentStatus BookingIntentStatus `json:"intent_status" db:"intent_status"`
+	TotalAmount  float64             `json:"total_amount" db:"total_amount"`
+	Currency     string              `json:"currency" db:"currency"`
+	ExpiresAt    time.Time           `json:"expires_at" db:"expires_at"`
+	ConfirmedAt  *time.Time          `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	BusBookingID *uuid.UUID          `json:"bus_booking_id,omitempty" db:"bus_booking_id"`
+}
+
+// AwaitingPayment reports whether the payment link can still be paid at the given time
+func (b *CallCenterBooking) AwaitingPayment(at time.Time) bool {
+	return b.IntentStatus == IntentStatusPaymentPending && b.ExpiresAt.After(at)
+}
+
+// PaymentLinkSMS is the text sent to a passenger with the payment link for a booking made for
+// them over the phone
+func (b *CallCenterBooking) PaymentLinkSMS() string {
+	return fmt.Sprintf("SmartTransit: Pay %s %.2f for the booking you made by phone by %s to secure your seats: %s",
+		b.Currency, b.TotalAmount, b.ExpiresAt.In(ReportTimezone).Format("15:04"), b.PaymentURL)
+}
+
+// CallCenterBookingFilter narrows the call-center booking list
+type CallCenterBookingFilter struct {
+	AgentUserID *uuid.UUID // Only this agent's bookings
+	Phone       string     // Only bookings for this passenger phone
+	Limit       int
+	Offset      int
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallCenterBooking_AwaitingPayment(t *testing.T) {
+	now := time.Date(2026, 11, 3, 8, 0, 0, 0, time.UTC)
+	booking := CallCenterBooking{IntentStatus: IntentStatusPaymentPending, ExpiresAt: now.Add(20 * time.Minute)}
+	assert.True(t, booking.AwaitingPayment(now))
+	assert.False(t, booking.AwaitingPayment(now.Add(20*time.Minute)), "hold expired")
+
+	booking.IntentStatus = IntentStatusConfirmed
+	assert.False(t, booking.AwaitingPayment(now), "already paid")
+}
+
+func TestCallCenterBooking_PaymentLinkSMS(t *testing.T) {
+	booking := CallCenterBooking{
+		Currency:    "LKR",
+		TotalAmount: 1850,
+		ExpiresAt:   time.Date(2026, 11, 3, 8, 30, 0, 0, time.UTC),
+		PaymentURL:  "https://gateway.payable.lk/pay/INT-1a2b3c4d",
+	}
+	sms := booking.PaymentLinkSMS()
+	assert.Contains(t, sms, "LKR 1850.00")
+	assert.Contains(t, sms, "by 14:00", "expiry shown in local time")
+	assert.Contains(t, sms, booking.PaymentURL)
+}
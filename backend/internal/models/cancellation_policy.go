@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CancellationFeeTier charges FeePercent of the paid amount when a booking is cancelled at least
+// Before ahead of departure (and after the previous, earlier-cutoff tier has ended)
+type CancellationFeeTier struct {
+	Before     time.Duration
+	FeePercent float64
+}
+
+// CancellationPolicy decides what a passenger gets back when they cancel a booking. Tiers are
+// ordered from the longest notice to the shortest; cancelling after the last tier's cutoff, or
+// after departure, refunds nothing. A policy without tiers refunds everything.
+type CancellationPolicy struct {
+	Tiers    []CancellationFeeTier
+	Currency string // Reported in quotes
+}
+
+// ParseCancellationPolicy reads tiers written as "<notice>:<fee percent>" pairs, e.g.
+// "24h:0,6h:25,0s:50" is free up to a day ahead, 25% up to six hours ahead and 50% until departure
+func ParseCancellationPolicy(value string) (CancellationPolicy, error) {
+	policy := CancellationPolicy{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		notice, percent, ok := strings.Cut(part, ":")
+		if !ok {
+			return CancellationPolicy{}, fmt.Errorf("invalid cancellation fee tier %q (want <notice>:<fee percent>)", part)
+		}
+		before, err := time.ParseDuration(strings.TrimSpace(notice))
+		if err != nil || before < 0 {
+			return CancellationPolicy{}, fmt.Errorf("invalid cancellation notice %q", notice)
+		}
+		fee, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil || fee < 0 || fee > 100 {
+			return CancellationPolicy{}, fmt.Errorf("invalid cancellation fee percent %q (0 to 100)", percent)
+		}
+		policy.Tiers = append(policy.Tiers, CancellationFeeTier{Before: before, FeePercent: fee})
+	}
+	sort.SliceStable(policy.Tiers, func(i, j int) bool { return policy.Tiers[i].Before > policy.Tiers[j].Before })
+	return policy, nil
+}
+
+// CancellationQuote is what cancelling a booking now would cost and refund
+type CancellationQuote struct {
+	BookingID         string     `json:"booking_id"`
+	Cancellable       bool       `json:"cancellable"`
+	DepartureDatetime *time.Time `json:"departure_datetime,omitempty"`
+	PaidAmount        float64    `json:"paid_amount"`
+	FeePercent        float64    `json:"fee_percent"`
+	Fee               float64    `json:"fee"`
+	RefundableAmount  float64    `json:"refundable_amount"`
+	Currency          string     `json:"currency"`
+	// When the next, higher fee starts; omitted once no later tier applies
+	NextTierAt         *time.Time `json:"next_tier_at,omitempty"`
+	NextTierFeePercent *float64   `json:"next_tier_fee_percent,omitempty"`
+	EvaluatedAt        time.Time  `json:"evaluated_at"`
+}
+
+// Quote prices cancelling the booking at now. departure is the bus trip's departure, nil for
+// bookings without one (no fee applies). Unpaid bookings cancel without a fee or refund. Seats
+// already cancelled out of the booking were priced when they were, so are not paid for again.
+func (p CancellationPolicy) Quote(booking *MasterBooking, departure *time.Time, now time.Time) *CancellationQuote {
+	quote := p.newQuote(booking, departure, now)
+	if booking.IsPaid() {
+		quote.PaidAmount = RoundMoney(booking.TotalAmount - booking.CancelledSeatsAmount)
+	}
+	p.price(quote, departure, now)
+	return quote
+}
+
+// QuoteSeats prices cancelling some of the booking's seats at now, refunding their share of
+// what was paid (see SeatPaidShares) less the fee of the tier the notice falls in
+func (p CancellationPolicy) QuoteSeats(booking *MasterBooking, seats []BusBookingSeat, departure *time.Time, now time.Time) *SeatCancellationQuote {
+	quote := &SeatCancellationQuote{CancellationQuote: *p.newQuote(booking, departure, now), Seats: []SeatRefundShare{}}
+	shares := SeatPaidShares(booking)
+	for _, seat := range seats {
+		share := SeatRefundShare{SeatID: seat.ID, SeatNumber: seat.SeatNumber, PassengerName: seat.PassengerName}
+		if booking.IsPaid() {
+			share.PaidAmount = shares[seat.ID]
+		}
+		quote.PaidAmount += share.PaidAmount
+		quote.Seats = append(quote.Seats, share)
+	}
+	quote.PaidAmount = RoundMoney(quote.PaidAmount)
+	p.price(&quote.CancellationQuote, departure, now)
+	return quote
+}
+
+func (p CancellationPolicy) newQuote(booking *MasterBooking, departure *time.Time, now time.Time) *CancellationQuote {
+	return &CancellationQuote{
+		BookingID:         booking.ID,
+		Cancellable:       booking.CanBeCancelled(),
+		DepartureDatetime: departure,
+		Currency:          p.Currency,
+		EvaluatedAt:       now,
+	}
+}
+
+// price sets the quote's fee, refund and next tier from its paid amount
+func (p CancellationPolicy) price(quote *CancellationQuote, departure *time.Time, now time.Time) {
+	if departure != nil && len(p.Tiers) > 0 {
+		notice := departure.Sub(now)
+		current := len(p.Tiers) // Past the last cutoff
+		quote.FeePercent = 100
+		for i, tier := range p.Tiers {
+			if notice >= tier.Before {
+				current = i
+				quote.FeePercent = tier.FeePercent
+				break
+			}
+		}
+		// The next tier that costs more starts when the notice drops below the cutoff before it
+		for j := current + 1; j <= len(p.Tiers); j++ {
+			next := 100.0
+			if j < len(p.Tiers) {
+				next = p.Tiers[j].FeePercent
+			}
+			if next > quote.FeePercent {
+				at := departure.Add(-p.Tiers[j-1].Before)
+				quote.NextTierAt = &at
+				quote.NextTierFeePercent = &next
+				break
+			}
+		}
+	}
+
+	quote.Fee = RoundMoney(quote.PaidAmount * quote.FeePercent / 100)
+	quote.RefundableAmount = RoundMoney(quote.PaidAmount - quote.Fee)
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCancellationPolicy(t *testing.T) {
+	policy, err := ParseCancellationPolicy("6h:25, 0s:50 ,24h:0")
+	require.NoError(t, err)
+	require.Len(t, policy.Tiers, 3)
+	assert.Equal(t, 24*time.Hour, policy.Tiers[0].Before, "longest notice first")
+	assert.Equal(t, 50.0, policy.Tiers[2].FeePercent)
+
+	empty, err := ParseCancellationPolicy("")
+	require.NoError(t, err)
+	assert.Empty(t, empty.Tiers)
+
+	for _, value := range []string{"24h", "soon:10", "-1h:10", "1h:120", "1h:x"} {
+		_, err := ParseCancellationPolicy(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestCancellationPolicyQuote(t *testing.T) {
+	policy, err := ParseCancellationPolicy("24h:0,6h:25,0s:50")
+	require.NoError(t, err)
+	departure := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	paid := &MasterBooking{ID: "b1", TotalAmount: 1250, PaymentStatus: MasterPaymentPaid, BookingStatus: MasterBookingConfirmed}
+
+	quote := policy.Quote(paid, &departure, departure.Add(-48*time.Hour))
+	assert.True(t, quote.Cancellable)
+	assert.Equal(t, 0.0, quote.Fee)
+	assert.Equal(t, 1250.0, quote.RefundableAmount)
+	require.NotNil(t, quote.NextTierAt)
+	assert.Equal(t, departure.Add(-24*time.Hour), *quote.NextTierAt)
+	assert.Equal(t, 25.0, *quote.NextTierFeePercent)
+
+	quote = policy.Quote(paid, &departure, departure.Add(-10*time.Hour))
+	assert.Equal(t, 312.5, quote.Fee)
+	assert.Equal(t, 937.5, quote.RefundableAmount)
+	assert.Equal(t, departure.Add(-6*time.Hour), *quote.NextTierAt)
+
+	quote = policy.Quote(paid, &departure, departure.Add(-time.Hour))
+	assert.Equal(t, 625.0, quote.RefundableAmount)
+	assert.Equal(t, departure, *quote.NextTierAt)
+	assert.Equal(t, 100.0, *quote.NextTierFeePercent)
+
+	quote = policy.Quote(paid, &departure, departure.Add(time.Minute))
+	assert.Equal(t, 100.0, quote.FeePercent)
+	assert.Equal(t, 0.0, quote.RefundableAmount)
+	assert.Nil(t, quote.NextTierAt)
+
+	unpaid := &MasterBooking{ID: "b2", TotalAmount: 1250, PaymentStatus: MasterPaymentPending, BookingStatus: MasterBookingPending}
+	quote = policy.Quote(unpaid, &departure, departure.Add(-time.Hour))
+	assert.Equal(t, 0.0, quote.Fee)
+	assert.Equal(t, 0.0, quote.RefundableAmount)
+
+	quote = CancellationPolicy{}.Quote(paid, &departure, departure.Add(-time.Hour))
+	assert.Equal(t, 1250.0, quote.RefundableAmount, "no tiers refunds in full")
+	assert.Nil(t, quote.NextTierAt)
+}
+
+func TestCancellationPolicyQuoteSeats(t *testing.T) {
+	policy, err := ParseCancellationPolicy("24h:0,6h:25,0s:50")
+	require.NoError(t, err)
+	departure := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	booking := testGroupBooking()
+	seats, err := SeatsToCancel(booking.BusBooking, []string{"s2"})
+	require.NoError(t, err)
+
+	quote := policy.QuoteSeats(booking, seats, &departure, departure.Add(-10*time.Hour))
+	assert.True(t, quote.Cancellable)
+	assert.Equal(t, 675.0, quote.PaidAmount)
+	assert.Equal(t, 25.0, quote.FeePercent)
+	assert.Equal(t, 168.75, quote.Fee)
+	assert.Equal(t, 506.25, quote.RefundableAmount)
+	require.Len(t, quote.Seats, 1)
+	assert.Equal(t, "Kamala", quote.Seats[0].PassengerName)
+	assert.Equal(t, departure.Add(-6*time.Hour), *quote.NextTierAt)
+
+	// Cancelling the rest of the booking later only prices what is still booked
+	booking.CancelledSeatsAmount = quote.PaidAmount
+	assert.Equal(t, 2925.0, policy.Quote(booking, &departure, departure.Add(-48*time.Hour)).PaidAmount)
+
+	booking.PaymentStatus = MasterPaymentPending
+	quote = policy.QuoteSeats(booking, seats, &departure, departure.Add(-10*time.Hour))
+	assert.Equal(t, 0.0, quote.RefundableAmount, "unpaid seats refund nothing")
+	assert.Equal(t, 0.0, quote.Seats[0].PaidAmount)
+}
@@ -0,0 +1,217 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// OnboardSaleStatus is whether a conductor's cash sale still counts towards the trip's takings
+type OnboardSaleStatus string
+
+const (
+	OnboardSaleRecorded OnboardSaleStatus = "recorded"
+	OnboardSaleVoided   OnboardSaleStatus = "voided" // Entered by mistake; kept in the ledger but not counted
+)
+
+// OnboardSale is a ticket (or a group of identical tickets) a conductor sold for cash on board
+type OnboardSale struct {
+	ID              string            `json:"id" db:"id"`
+	ScheduledTripID string            `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	SoldByUserID    string            `json:"sold_by_user_id" db:"sold_by_user_id"`
+	FromStopID      *string           `json:"from_stop_id,omitempty" db:"from_stop_id"`
+	ToStopID        *string           `json:"to_stop_id,omitempty" db:"to_stop_id"`
+	PassengerCount  int               `json:"passenger_count" db:"passenger_count"`
+	Fare            float64           `json:"fare" db:"fare"`     // Per passenger
+	Amount          float64           `json:"amount" db:"amount"` // Cash taken: fare x passengers
+	ClientReference *string           `json:"client_reference,omitempty" db:"client_reference"`
+	Status          OnboardSaleStatus `json:"status" db:"status"`
+	VoidReason      *string           `json:"void_reason,omitempty" db:"void_reason"`
+	VoidedByUserID  *string           `json:"voided_by_user_id,omitempty" db:"voided_by_user_id"`
+	VoidedAt        *time.Time        `json:"voided_at,omitempty" db:"voided_at"`
+	SoldAt          time.Time         `json:"sold_at" db:"sold_at"`
+}
+
+// RecordOnboardSaleRequest is a conductor recording a cash sale. ClientReference lets the
+// conductor app retry a sale it is unsure was saved without recording it twice.
+type RecordOnboardSaleRequest struct {
+	Fare            float64 `json:"fare" binding:"required,gt=0"`
+	PassengerCount  int     `json:"passenger_count" binding:"omitempty,min=1,max=50"` // Defaults to 1
+	FromStopID      *string `json:"from_stop_id,omitempty" binding:"omitempty,uuid"`
+	ToStopID        *string `json:"to_stop_id,omitempty" binding:"omitempty,uuid"`
+	ClientReference *string `json:"client_reference,omitempty" binding:"omitempty,max=64"`
+}
+
+// Sale builds the sale the request records on a trip with the given seat fare. A cash fare may
+// be below the seat fare for a shorter journey, but never above it.
+func (r *RecordOnboardSaleRequest) Sale(scheduledTripID, soldByUserID string, baseFare float64) (*OnboardSale, error) {
+	if baseFare > 0 && r.Fare > baseFare {
+		return nil, &ValidationError{Message: fmt.Sprintf("fare cannot be above the trip's seat fare of %.2f", baseFare)}
+	}
+	if r.FromStopID != nil && r.ToStopID != nil && *r.FromStopID == *r.ToStopID {
+		return nil, &ValidationError{Message: "from_stop_id and to_stop_id must be different stops"}
+	}
+	count := r.PassengerCount
+	if count == 0 {
+		count = 1
+	}
+	fare := RoundMoney(r.Fare)
+	return &OnboardSale{
+		ScheduledTripID: scheduledTripID,
+		SoldByUserID:    soldByUserID,
+		FromStopID:      r.FromStopID,
+		ToStopID:        r.ToStopID,
+		PassengerCount:  count,
+		Fare:            fare,
+		Amount:          RoundMoney(fare * float64(count)),
+		ClientReference: r.ClientReference,
+		Status:          OnboardSaleRecorded,
+	}, nil
+}
+
+// VoidOnboardSaleRequest takes a mistaken sale out of the trip's takings
+type VoidOnboardSaleRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// CashTrip is a trip with what decides who may record its cash and whether they still can
+type CashTrip struct {
+	ScheduledTripID   string              `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	Status            ScheduledTripStatus `json:"status" db:"status"`
+	DepartureDatetime time.Time           `json:"departure_datetime" db:"departure_datetime"`
+	BaseFare          float64             `json:"base_fare" db:"base_fare"`
+	BusOwnerID        *string             `json:"-" db:"bus_owner_id"`
+	DriverUserID      *string             `json:"-" db:"driver_user_id"`
+	ConductorUserID   *string             `json:"-" db:"conductor_user_id"`
+	OwnerUserID       *string             `json:"-" db:"owner_user_id"`
+}
+
+// IsCrew reports whether the user is the trip's driver, conductor or bus owner
+func (t *CashTrip) IsCrew(userID string) bool {
+	for _, allowed := range []*string{t.DriverUserID, t.ConductorUserID, t.OwnerUserID} {
+		if allowed != nil && *allowed == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsSales reports whether the crew can still record cash sales: until the trip ends
+func (t *CashTrip) AcceptsSales() bool {
+	switch t.Status {
+	case ScheduledTripStatusScheduled, ScheduledTripStatusConfirmed, ScheduledTripStatusInProgress:
+		return true
+	}
+	return false
+}
+
+// CanSettle reports whether the crew can hand in the trip's cash: once it is under way
+func (t *CashTrip) CanSettle() bool {
+	return t.Status == ScheduledTripStatusInProgress || t.Status == ScheduledTripStatusCompleted
+}
+
+// CashTotals is what a trip's crew took in cash: onboard sales, and standing tickets sold on
+// the spot or paid for on boarding
+type CashTotals struct {
+	SaleCount             int     `json:"sale_count" db:"sale_count"`
+	PassengerCount        int     `json:"passenger_count" db:"passenger_count"`
+	OnboardSalesAmount    float64 `json:"onboard_sales_amount" db:"onboard_sales_amount"`
+	StandingTicketCount   int     `json:"standing_ticket_count" db:"standing_ticket_count"`
+	StandingTicketsAmount float64 `json:"standing_tickets_amount" db:"standing_tickets_amount"`
+	VoidedCount           int     `json:"voided_count" db:"voided_count"`
+}
+
+// ExpectedCash is the cash the crew should hand over
+func (t CashTotals) ExpectedCash() float64 {
+	return RoundMoney(t.OnboardSalesAmount + t.StandingTicketsAmount)
+}
+
+// TripCashSettlementStatus tracks the handover of a trip's cash to the bus owner
+type TripCashSettlementStatus string
+
+const (
+	CashSettlementSubmitted  TripCashSettlementStatus = "submitted"  // Handed in by the crew, awaiting the owner
+	CashSettlementReconciled TripCashSettlementStatus = "reconciled" // Accepted by the owner; final
+	CashSettlementDisputed   TripCashSettlementStatus = "disputed"   // Sent back to the crew to correct and resubmit
+)
+
+// TripCashSettlement is the end-of-trip cash handover: what the ledger says the crew took, what
+// they counted, and the owner's review
+type TripCashSettlement struct {
+	ID                    string                   `json:"id" db:"id"`
+	ScheduledTripID       string                   `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	SubmittedByUserID     string                   `json:"submitted_by_user_id" db:"submitted_by_user_id"`
+	SaleCount             int                      `json:"sale_count" db:"sale_count"`
+	PassengerCount        int                      `json:"passenger_count" db:"passenger_count"`
+	OnboardSalesAmount    float64                  `json:"onboard_sales_amount" db:"onboard_sales_amount"`
+	StandingTicketCount   int                      `json:"standing_ticket_count" db:"standing_ticket_count"`
+	StandingTicketsAmount float64                  `json:"standing_tickets_amount" db:"standing_tickets_amount"`
+	ExpectedCash          float64                  `json:"expected_cash" db:"expected_cash"`
+	DeclaredCash          float64                  `json:"declared_cash" db:"declared_cash"`
+	Variance              float64                  `json:"variance" db:"variance"` // Declared less expected; negative is a shortfall
+	CrewNote              *string                  `json:"crew_note,omitempty" db:"crew_note"`
+	Status                TripCashSettlementStatus `json:"status" db:"status"`
+	OwnerNote             *string                  `json:"owner_note,omitempty" db:"owner_note"`
+	ReviewedByUserID      *string                  `json:"reviewed_by_user_id,omitempty" db:"reviewed_by_user_id"`
+	ReviewedAt            *time.Time               `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	SubmittedAt           time.Time                `json:"submitted_at" db:"submitted_at"`
+	UpdatedAt             time.Time                `json:"updated_at" db:"updated_at"`
+
+	// Filled in when listing an owner's settlements
+	DepartureDatetime *time.Time `json:"departure_datetime,omitempty" db:"departure_datetime"`
+}
+
+// LocksLedger reports whether the trip's sales can no longer change: once handed in, unless the
+// owner sent the settlement back
+func (s *TripCashSettlement) LocksLedger() bool {
+	return s != nil && s.Status != CashSettlementDisputed
+}
+
+// ApplyTotals records the ledger totals the settlement was made against and the variance from
+// the declared cash
+func (s *TripCashSettlement) ApplyTotals(totals CashTotals) {
+	s.SaleCount = totals.SaleCount
+	s.PassengerCount = totals.PassengerCount
+	s.OnboardSalesAmount = RoundMoney(totals.OnboardSalesAmount)
+	s.StandingTicketCount = totals.StandingTicketCount
+	s.StandingTicketsAmount = RoundMoney(totals.StandingTicketsAmount)
+	s.ExpectedCash = totals.ExpectedCash()
+	s.Variance = RoundMoney(s.DeclaredCash - s.ExpectedCash)
+}
+
+// SubmitCashSettlementRequest is the crew handing in a trip's cash at the end of the trip
+type SubmitCashSettlementRequest struct {
+	DeclaredCash *float64 `json:"declared_cash" binding:"required,min=0"` // Cash counted at handover
+	Note         string   `json:"note" binding:"max=500"`
+}
+
+// ReviewCashSettlementRequest is the bus owner accepting a cash handover or sending it back
+type ReviewCashSettlementRequest struct {
+	Status TripCashSettlementStatus `json:"status" binding:"required,oneof=reconciled disputed"`
+	Note   string                   `json:"note" binding:"max=500"`
+}
+
+// Validate requires a note when the owner disputes a handover, so the crew knows what to fix
+func (r *ReviewCashSettlementRequest) Validate() error {
+	if r.Status == CashSettlementDisputed && r.Note == "" {
+		return &ValidationError{Message: "note is required when disputing a settlement"}
+	}
+	return nil
+}
+
+// TripCashLedger is a trip's cash sales, newest first, with their totals and the settlement
+// if the crew has handed the cash in
+type TripCashLedger struct {
+	Trip         *CashTrip           `json:"trip"`
+	Sales        []OnboardSale       `json:"sales"`
+	Totals       CashTotals          `json:"totals"`
+	ExpectedCash float64             `json:"expected_cash"`
+	Settlement   *TripCashSettlement `json:"settlement,omitempty"`
+}
+
+// CashSettlementFilter narrows the settlements listed for a bus owner
+type CashSettlementFilter struct {
+	BusOwnerID string
+	Status     TripCashSettlementStatus
+	Limit      int
+	Offset     int
+}
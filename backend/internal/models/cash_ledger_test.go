@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordOnboardSaleRequest_Sale(t *testing.T) {
+	sale, err := (&RecordOnboardSaleRequest{Fare: 80}).Sale("trip-1", "conductor-1", 250)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sale.PassengerCount, "one passenger unless given")
+	assert.Equal(t, 80.0, sale.Amount)
+	assert.Equal(t, OnboardSaleRecorded, sale.Status)
+
+	sale, err = (&RecordOnboardSaleRequest{Fare: 33.335, PassengerCount: 3}).Sale("trip-1", "conductor-1", 250)
+	require.NoError(t, err)
+	assert.Equal(t, 33.34, sale.Fare)
+	assert.Equal(t, 100.02, sale.Amount)
+
+	_, err = (&RecordOnboardSaleRequest{Fare: 300}).Sale("trip-1", "conductor-1", 250)
+	assert.Error(t, err, "above the seat fare")
+	_, err = (&RecordOnboardSaleRequest{Fare: 300}).Sale("trip-1", "conductor-1", 0)
+	assert.NoError(t, err, "no seat fare to compare against")
+
+	stop := "3f6c1a52-2c1e-4f0e-9d7e-3b5a1c2d4e6f"
+	_, err = (&RecordOnboardSaleRequest{Fare: 80, FromStopID: &stop, ToStopID: &stop}).Sale("trip-1", "conductor-1", 250)
+	assert.Error(t, err, "same boarding and alighting stop")
+}
+
+func TestCashTrip_Permissions(t *testing.T) {
+	conductor, owner := "conductor-1", "owner-1"
+	trip := &CashTrip{Status: ScheduledTripStatusConfirmed, ConductorUserID: &conductor, OwnerUserID: &owner}
+	assert.True(t, trip.IsCrew(conductor))
+	assert.True(t, trip.IsCrew(owner))
+	assert.False(t, trip.IsCrew("passenger-1"))
+
+	assert.True(t, trip.AcceptsSales())
+	assert.False(t, trip.CanSettle(), "not departed yet")
+
+	trip.Status = ScheduledTripStatusInProgress
+	assert.True(t, trip.AcceptsSales())
+	assert.True(t, trip.CanSettle())
+
+	trip.Status = ScheduledTripStatusCompleted
+	assert.False(t, trip.AcceptsSales())
+	assert.True(t, trip.CanSettle())
+
+	trip.Status = ScheduledTripStatusCancelled
+	assert.False(t, trip.AcceptsSales())
+	assert.False(t, trip.CanSettle())
+}
+
+func TestTripCashSettlement_ApplyTotals(t *testing.T) {
+	totals := CashTotals{SaleCount: 12, PassengerCount: 15, OnboardSalesAmount: 1800.5, StandingTicketCount: 4, StandingTicketsAmount: 400}
+	assert.Equal(t, 2200.5, totals.ExpectedCash())
+
+	settlement := &TripCashSettlement{DeclaredCash: 2150}
+	settlement.ApplyTotals(totals)
+	assert.Equal(t, 2200.5, settlement.ExpectedCash)
+	assert.Equal(t, -50.5, settlement.Variance, "a shortfall is negative")
+	assert.Equal(t, 15, settlement.PassengerCount)
+
+	var none *TripCashSettlement
+	assert.False(t, none.LocksLedger(), "nothing handed in yet")
+	assert.True(t, (&TripCashSettlement{Status: CashSettlementSubmitted}).LocksLedger())
+	assert.True(t, (&TripCashSettlement{Status: CashSettlementReconciled}).LocksLedger())
+	assert.False(t, (&TripCashSettlement{Status: CashSettlementDisputed}).LocksLedger(), "sent back for correction")
+}
+
+func TestReviewCashSettlementRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&ReviewCashSettlementRequest{Status: CashSettlementReconciled}).Validate())
+	assert.Error(t, (&ReviewCashSettlementRequest{Status: CashSettlementDisputed}).Validate(), "disputes need a note")
+	assert.NoError(t, (&ReviewCashSettlementRequest{Status: CashSettlementDisputed, Note: "LKR 50 short"}).Validate())
+}
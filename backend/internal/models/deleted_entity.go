@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// DeletedEntityType is a kind of bus owner data that is soft-deleted rather than removed
+type DeletedEntityType string
+
+const (
+	DeletedEntityBus    DeletedEntityType = "bus"
+	DeletedEntityPermit DeletedEntityType = "permit"
+	DeletedEntityRoute  DeletedEntityType = "route" // Bus owner route
+)
+
+// ValidDeletedEntityType reports whether t is a soft-deleted entity type
+func ValidDeletedEntityType(t DeletedEntityType) bool {
+	return t == DeletedEntityBus || t == DeletedEntityPermit || t == DeletedEntityRoute
+}
+
+// DeletedEntity is a soft-deleted bus, permit or route, as listed for admins
+type DeletedEntity struct {
+	Type       DeletedEntityType `json:"type" db:"type"`
+	ID         string            `json:"id" db:"id"`
+	BusOwnerID string            `json:"bus_owner_id" db:"bus_owner_id"`
+	Name       string            `json:"name" db:"name"` // License plate, permit number or route name
+	DeletedAt  time.Time         `json:"deleted_at" db:"deleted_at"`
+}
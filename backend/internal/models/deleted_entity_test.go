@@ -0,0 +1,16 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidDeletedEntityType(t *testing.T) {
+	for _, entityType := range []DeletedEntityType{DeletedEntityBus, DeletedEntityPermit, DeletedEntityRoute} {
+		assert.True(t, ValidDeletedEntityType(entityType), entityType)
+	}
+	for _, entityType := range []DeletedEntityType{"", "buses", "trip", "Bus"} {
+		assert.False(t, ValidDeletedEntityType(entityType), entityType)
+	}
+}
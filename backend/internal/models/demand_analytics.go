@@ -0,0 +1,182 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DemandHeatmapMaxDays     = 92  // Longest date range a heatmap may cover
+	DemandHeatmapDefaultDays = 30  // Range used when none is given
+	DemandMaxCorridors       = 200 // Most corridors returned at once
+
+	// A corridor is underserved when at least demandUnderservedMinSearches searches were made
+	// for it and at least half of them found no trips
+	demandUnderservedMinSearches = 10
+	demandUnderservedUnmetRate   = 0.5
+)
+
+// DemandCell is the demand for one origin/destination pair in one local hour (Asia/Colombo).
+// Search hours are the requested departure time, or the time of the search when none was
+// given; booking hours are the trip's departure time.
+type DemandCell struct {
+	OriginKey          string `json:"-" db:"origin_key"` // Lower-cased stop name used for matching
+	DestinationKey     string `json:"-" db:"destination_key"`
+	OriginStop         string `json:"-" db:"origin_stop"`
+	DestinationStop    string `json:"-" db:"destination_stop"`
+	Hour               int    `json:"hour" db:"hour"`
+	Searches           int    `json:"searches" db:"searches"`
+	ZeroResultSearches int    `json:"zero_result_searches" db:"zero_result_searches"`
+	Bookings           int    `json:"bookings" db:"bookings"`
+	SeatsBooked        int    `json:"seats_booked" db:"seats_booked"`
+}
+
+// DemandCorridor is the demand between two stops, with its hourly cells
+type DemandCorridor struct {
+	OriginStop         string       `json:"origin_stop"`
+	DestinationStop    string       `json:"destination_stop"`
+	Searches           int          `json:"searches"`
+	ZeroResultSearches int          `json:"zero_result_searches"`
+	Bookings           int          `json:"bookings"`
+	SeatsBooked        int          `json:"seats_booked"`
+	UnmetSearchRate    float64      `json:"unmet_search_rate"` // Share of searches that found no trips
+	PeakHour           int          `json:"peak_hour"`
+	Underserved        bool         `json:"underserved"`
+	Hours              []DemandCell `json:"hours"` // Only hours with demand, in order
+}
+
+// DemandHeatmap is origin/destination/hour demand from searches and bookings
+type DemandHeatmap struct {
+	From       string           `json:"from"` // YYYY-MM-DD, inclusive
+	To         string           `json:"to"`   // YYYY-MM-DD, inclusive
+	Timezone   string           `json:"timezone"`
+	BusOwnerID *string          `json:"bus_owner_id,omitempty"` // Set for an owner's own view
+	Corridors  []DemandCorridor `json:"corridors"`
+}
+
+// DemandHeatmapRequest holds the heatmap query parameters
+type DemandHeatmapRequest struct {
+	From   string `form:"from"` // YYYY-MM-DD, defaults to 30 days before to
+	To     string `form:"to"`   // YYYY-MM-DD, defaults to today
+	Origin string `form:"origin"`
+	Limit  int    `form:"limit"`
+}
+
+// Range returns the requested local dates as [start, end) instants, checking the range
+func (r *DemandHeatmapRequest) Range(now time.Time) (time.Time, time.Time, error) {
+	local := now.In(ReportTimezone)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ReportTimezone)
+
+	to := today
+	if r.To != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", r.To, ReportTimezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: "to must be in YYYY-MM-DD format"}
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(DemandHeatmapDefaultDays - 1))
+	if r.From != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", r.From, ReportTimezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: "from must be in YYYY-MM-DD format"}
+		}
+		from = parsed
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, &ValidationError{Message: "to must not be before from"}
+	}
+	if to.AddDate(0, 0, 1).Sub(from) > DemandHeatmapMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, &ValidationError{Message: "the date range must not exceed 92 days"}
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// BuildDemandCorridors merges search and booking cells into corridors, most demanded first,
+// keeping at most limit. An origin, if given, keeps only corridors from that stop.
+func BuildDemandCorridors(searches, bookings []DemandCell, origin string, limit int) []DemandCorridor {
+	if limit <= 0 || limit > DemandMaxCorridors {
+		limit = DemandMaxCorridors
+	}
+	origin = strings.ToLower(strings.TrimSpace(origin))
+
+	type corridorKey struct{ origin, destination string }
+	corridors := map[corridorKey]*DemandCorridor{}
+	hours := map[corridorKey]map[int]*DemandCell{}
+
+	add := func(cell DemandCell) {
+		if cell.OriginKey == "" || cell.DestinationKey == "" || cell.OriginKey == cell.DestinationKey {
+			return
+		}
+		if origin != "" && cell.OriginKey != origin {
+			return
+		}
+		key := corridorKey{cell.OriginKey, cell.DestinationKey}
+		corridor, ok := corridors[key]
+		if !ok {
+			corridor = &DemandCorridor{OriginStop: cell.OriginStop, DestinationStop: cell.DestinationStop}
+			corridors[key] = corridor
+			hours[key] = map[int]*DemandCell{}
+		}
+		corridor.Searches += cell.Searches
+		corridor.ZeroResultSearches += cell.ZeroResultSearches
+		corridor.Bookings += cell.Bookings
+		corridor.SeatsBooked += cell.SeatsBooked
+
+		hour, ok := hours[key][cell.Hour]
+		if !ok {
+			hour = &DemandCell{Hour: cell.Hour}
+			hours[key][cell.Hour] = hour
+		}
+		hour.Searches += cell.Searches
+		hour.ZeroResultSearches += cell.ZeroResultSearches
+		hour.Bookings += cell.Bookings
+		hour.SeatsBooked += cell.SeatsBooked
+	}
+	for _, cell := range searches {
+		add(cell)
+	}
+	for _, cell := range bookings {
+		add(cell)
+	}
+
+	result := make([]DemandCorridor, 0, len(corridors))
+	for key, corridor := range corridors {
+		peak := -1
+		for hour := 0; hour < 24; hour++ {
+			cell, ok := hours[key][hour]
+			if !ok {
+				continue
+			}
+			corridor.Hours = append(corridor.Hours, *cell)
+			if peak < 0 || cell.Searches+cell.Bookings > hours[key][peak].Searches+hours[key][peak].Bookings {
+				peak = hour
+			}
+		}
+		corridor.PeakHour = peak
+		if corridor.Searches > 0 {
+			corridor.UnmetSearchRate = math.Round(float64(corridor.ZeroResultSearches)/float64(corridor.Searches)*100) / 100
+		}
+		corridor.Underserved = corridor.Searches >= demandUnderservedMinSearches &&
+			corridor.UnmetSearchRate >= demandUnderservedUnmetRate
+		result = append(result, *corridor)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Searches+a.Bookings != b.Searches+b.Bookings {
+			return a.Searches+a.Bookings > b.Searches+b.Bookings
+		}
+		if a.OriginStop != b.OriginStop {
+			return a.OriginStop < b.OriginStop
+		}
+		return a.DestinationStop < b.DestinationStop
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDemandCorridors(t *testing.T) {
+	cell := func(origin, destination string, hour, searches, zero, bookings, seats int) DemandCell {
+		return DemandCell{
+			OriginKey: strings.ToLower(origin), DestinationKey: strings.ToLower(destination),
+			OriginStop: origin, DestinationStop: destination,
+			Hour: hour, Searches: searches, ZeroResultSearches: zero, Bookings: bookings, SeatsBooked: seats,
+		}
+	}
+	searches := []DemandCell{
+		cell("Colombo Fort", "Kandy", 7, 30, 2, 0, 0),
+		cell("Colombo Fort", "Kandy", 17, 12, 0, 0, 0),
+		cell("Kurunegala", "Jaffna", 21, 14, 9, 0, 0),
+		cell("Galle", "Galle", 9, 50, 0, 0, 0), // Same stop, ignored
+	}
+	bookings := []DemandCell{
+		cell("Colombo Fort", "Kandy", 7, 0, 0, 20, 31),
+		cell("Kurunegala", "Jaffna", 22, 0, 0, 1, 1),
+		cell("Matara", "Colombo Fort", 5, 0, 0, 3, 3),
+	}
+
+	corridors := BuildDemandCorridors(searches, bookings, "", 0)
+	require.Len(t, corridors, 3)
+
+	kandy := corridors[0]
+	assert.Equal(t, "Colombo Fort", kandy.OriginStop)
+	assert.Equal(t, 42, kandy.Searches)
+	assert.Equal(t, 20, kandy.Bookings)
+	assert.Equal(t, 31, kandy.SeatsBooked)
+	assert.Equal(t, 7, kandy.PeakHour)
+	assert.Equal(t, 0.05, kandy.UnmetSearchRate)
+	assert.False(t, kandy.Underserved)
+	require.Len(t, kandy.Hours, 2)
+	assert.Equal(t, DemandCell{Hour: 7, Searches: 30, ZeroResultSearches: 2, Bookings: 20, SeatsBooked: 31}, kandy.Hours[0])
+
+	jaffna := corridors[1]
+	assert.Equal(t, "Jaffna", jaffna.DestinationStop)
+	assert.Equal(t, 0.64, jaffna.UnmetSearchRate)
+	assert.True(t, jaffna.Underserved, "most searches found nothing")
+	assert.Equal(t, []int{21, 22}, []int{jaffna.Hours[0].Hour, jaffna.Hours[1].Hour})
+
+	assert.False(t, corridors[2].Underserved, "bookings alone are never underserved")
+
+	fromKurunegala := BuildDemandCorridors(searches, bookings, " KURUNEGALA ", 0)
+	require.Len(t, fromKurunegala, 1)
+	assert.Equal(t, "Jaffna", fromKurunegala[0].DestinationStop)
+
+	assert.Len(t, BuildDemandCorridors(searches, bookings, "", 1), 1)
+}
+
+func TestDemandHeatmapRequest_Range(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC) // 01:30 on 11 March in Colombo
+
+	from, to, err := (&DemandHeatmapRequest{}).Range(now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 2, 10, 0, 0, 0, 0, ReportTimezone), from, "defaults to the last 30 local days")
+	assert.Equal(t, time.Date(2026, 3, 12, 0, 0, 0, 0, ReportTimezone), to)
+
+	from, to, err = (&DemandHeatmapRequest{From: "2026-01-01", To: "2026-01-31"}).Range(now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, ReportTimezone), from)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, ReportTimezone), to)
+
+	for _, req := range []DemandHeatmapRequest{
+		{From: "01/01/2026"},
+		{From: "2026-02-01", To: "2026-01-01"},
+		{From: "2025-01-01", To: "2026-01-01"},
+	} {
+		_, _, err := req.Range(now)
+		assert.Error(t, err, req)
+	}
+}
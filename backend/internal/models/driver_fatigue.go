@@ -0,0 +1,140 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Hours-of-service rules a driver assignment can break
+const (
+	FatigueRuleOverlappingTrip = "overlapping_trip" // The driver is already on another trip at the time
+	FatigueRuleMinRest         = "min_rest"         // Too little rest before or after another trip
+	FatigueRuleMaxDailyDriving = "max_daily_driving"
+
+	// FatigueOverrideMinJustification is the shortest justification accepted for an override
+	FatigueOverrideMinJustification = 10
+)
+
+// HoursOfServiceRules are the limits on a driver's assigned trips
+type HoursOfServiceRules struct {
+	MaxDailyDrivingMinutes int `json:"max_daily_driving_minutes"` // Driving allowed per local day (Asia/Colombo); 0 = no limit
+	MinRestMinutes         int `json:"min_rest_minutes"`          // Rest required between consecutive trips; 0 = trips need only not overlap
+	DefaultTripMinutes     int `json:"default_trip_minutes"`      // Duration assumed for trips without an estimated duration
+}
+
+// DriverDuty is a trip a driver is, or would be, assigned to
+type DriverDuty struct {
+	TripID                   string    `json:"trip_id" db:"id"`
+	DepartureDatetime        time.Time `json:"departure_datetime" db:"departure_datetime"`
+	EstimatedDurationMinutes *int      `json:"estimated_duration_minutes,omitempty" db:"estimated_duration_minutes"`
+}
+
+// FatigueViolation is one hours-of-service rule an assignment breaks
+type FatigueViolation struct {
+	Rule              string  `json:"rule"`
+	Message           string  `json:"message"`
+	ConflictingTripID *string `json:"conflicting_trip_id,omitempty"`
+	Date              *string `json:"date,omitempty"`            // YYYY-MM-DD, for max_daily_driving
+	DrivingMinutes    *int    `json:"driving_minutes,omitempty"` // Total for the day, for max_daily_driving
+}
+
+// DutyFromTrip returns the duty a scheduled trip represents
+func DutyFromTrip(trip *ScheduledTrip) DriverDuty {
+	return DriverDuty{
+		TripID:                   trip.ID,
+		DepartureDatetime:        trip.DepartureDatetime,
+		EstimatedDurationMinutes: trip.EstimatedDurationMinutes,
+	}
+}
+
+// span returns when the duty starts and ends
+func (d DriverDuty) span(defaultMinutes int) (time.Time, time.Time) {
+	minutes := defaultMinutes
+	if d.EstimatedDurationMinutes != nil && *d.EstimatedDurationMinutes > 0 {
+		minutes = *d.EstimatedDurationMinutes
+	}
+	return d.DepartureDatetime, d.DepartureDatetime.Add(time.Duration(minutes) * time.Minute)
+}
+
+// CheckHoursOfService returns the rules assigning the driver to candidate would break, given
+// the other trips they are assigned to. Driving time is counted per local day, so a trip over
+// midnight counts towards both days.
+func CheckHoursOfService(candidate DriverDuty, assigned []DriverDuty, rules HoursOfServiceRules) []FatigueViolation {
+	violations := []FatigueViolation{}
+	start, end := candidate.span(rules.DefaultTripMinutes)
+	rest := time.Duration(rules.MinRestMinutes) * time.Minute
+
+	others := make([]DriverDuty, 0, len(assigned))
+	for _, duty := range assigned {
+		if duty.TripID != candidate.TripID {
+			others = append(others, duty)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].DepartureDatetime.Before(others[j].DepartureDatetime)
+	})
+
+	for _, duty := range others {
+		tripID := duty.TripID
+		otherStart, otherEnd := duty.span(rules.DefaultTripMinutes)
+		switch {
+		case otherStart.Before(end) && start.Before(otherEnd):
+			violations = append(violations, FatigueViolation{
+				Rule:              FatigueRuleOverlappingTrip,
+				Message:           fmt.Sprintf("Driver is already assigned to a trip departing %s", otherStart.In(ReportTimezone).Format("2006-01-02 15:04")),
+				ConflictingTripID: &tripID,
+			})
+		case !otherEnd.After(start) && start.Sub(otherEnd) < rest:
+			violations = append(violations, FatigueViolation{
+				Rule:              FatigueRuleMinRest,
+				Message:           fmt.Sprintf("Only %d minutes of rest after the previous trip; %d required", int(start.Sub(otherEnd).Minutes()), rules.MinRestMinutes),
+				ConflictingTripID: &tripID,
+			})
+		case !end.After(otherStart) && otherStart.Sub(end) < rest:
+			violations = append(violations, FatigueViolation{
+				Rule:              FatigueRuleMinRest,
+				Message:           fmt.Sprintf("Only %d minutes of rest before the next trip; %d required", int(otherStart.Sub(end).Minutes()), rules.MinRestMinutes),
+				ConflictingTripID: &tripID,
+			})
+		}
+	}
+
+	if rules.MaxDailyDrivingMinutes <= 0 {
+		return violations
+	}
+	local := start.In(ReportTimezone)
+	for day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ReportTimezone); day.Before(end); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		total := overlapMinutes(start, end, day, dayEnd)
+		for _, duty := range others {
+			otherStart, otherEnd := duty.span(rules.DefaultTripMinutes)
+			total += overlapMinutes(otherStart, otherEnd, day, dayEnd)
+		}
+		if total > rules.MaxDailyDrivingMinutes {
+			date := day.Format("2006-01-02")
+			minutes := total
+			violations = append(violations, FatigueViolation{
+				Rule:           FatigueRuleMaxDailyDriving,
+				Message:        fmt.Sprintf("Driver would drive %d minutes on %s; at most %d allowed", total, date, rules.MaxDailyDrivingMinutes),
+				Date:           &date,
+				DrivingMinutes: &minutes,
+			})
+		}
+	}
+	return violations
+}
+
+// overlapMinutes returns the whole minutes [start, end) shares with [from, to)
+func overlapMinutes(start, end, from, to time.Time) int {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return int(end.Sub(start).Minutes())
+}
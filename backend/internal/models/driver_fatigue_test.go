@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fatigueDuty(id string, departure time.Time, minutes int) DriverDuty {
+	return DriverDuty{TripID: id, DepartureDatetime: departure, EstimatedDurationMinutes: &minutes}
+}
+
+func TestCheckHoursOfService(t *testing.T) {
+	rules := HoursOfServiceRules{MaxDailyDrivingMinutes: 600, MinRestMinutes: 30, DefaultTripMinutes: 120}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 5, 4, hour, minute, 0, 0, ReportTimezone)
+	}
+
+	t.Run("no conflicts", func(t *testing.T) {
+		violations := CheckHoursOfService(fatigueDuty("new", at(12, 0), 120), []DriverDuty{
+			fatigueDuty("morning", at(8, 0), 180),
+		}, rules)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("overlapping trip", func(t *testing.T) {
+		violations := CheckHoursOfService(fatigueDuty("new", at(9, 0), 120), []DriverDuty{
+			fatigueDuty("morning", at(8, 0), 180),
+		}, rules)
+		require.Len(t, violations, 1)
+		assert.Equal(t, FatigueRuleOverlappingTrip, violations[0].Rule)
+		assert.Equal(t, "morning", *violations[0].ConflictingTripID)
+	})
+
+	t.Run("too little rest after and before", func(t *testing.T) {
+		violations := CheckHoursOfService(fatigueDuty("new", at(11, 10), 60), []DriverDuty{
+			fatigueDuty("before", at(8, 0), 180),
+			fatigueDuty("after", at(12, 20), 60),
+		}, rules)
+		require.Len(t, violations, 2)
+		assert.Equal(t, FatigueRuleMinRest, violations[0].Rule)
+		assert.Equal(t, "before", *violations[0].ConflictingTripID)
+		assert.Equal(t, FatigueRuleMinRest, violations[1].Rule)
+		assert.Equal(t, "after", *violations[1].ConflictingTripID)
+	})
+
+	t.Run("exact rest is allowed", func(t *testing.T) {
+		violations := CheckHoursOfService(fatigueDuty("new", at(11, 30), 60), []DriverDuty{
+			fatigueDuty("before", at(8, 0), 180),
+		}, rules)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("daily driving limit", func(t *testing.T) {
+		violations := CheckHoursOfService(fatigueDuty("new", at(17, 0), 180), []DriverDuty{
+			fatigueDuty("a", at(5, 0), 240),
+			fatigueDuty("b", at(10, 0), 240),
+		}, rules)
+		require.Len(t, violations, 1)
+		assert.Equal(t, FatigueRuleMaxDailyDriving, violations[0].Rule)
+		assert.Equal(t, "2026-05-04", *violations[0].Date)
+		assert.Equal(t, 660, *violations[0].DrivingMinutes)
+	})
+
+	t.Run("trip over midnight counts per day", func(t *testing.T) {
+		violations := CheckHoursOfService(fatigueDuty("new", at(22, 0), 240), []DriverDuty{
+			fatigueDuty("today", at(8, 0), 500),
+			fatigueDuty("tomorrow", at(8, 0).AddDate(0, 0, 1), 480),
+		}, rules)
+		require.Len(t, violations, 1)
+		assert.Equal(t, "2026-05-04", *violations[0].Date)
+		assert.Equal(t, 620, *violations[0].DrivingMinutes)
+	})
+
+	t.Run("default duration and same trip ignored", func(t *testing.T) {
+		candidate := DriverDuty{TripID: "new", DepartureDatetime: at(10, 0)}
+		violations := CheckHoursOfService(candidate, []DriverDuty{
+			candidate,
+			fatigueDuty("later", at(12, 10), 60),
+		}, rules)
+		require.Len(t, violations, 1)
+		assert.Equal(t, FatigueRuleMinRest, violations[0].Rule)
+	})
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	FareComplianceMaxDays     = 92 // Longest departure range a report may cover
+	FareComplianceDefaultDays = 30 // Range used when none is given, starting today
+)
+
+// FareComplianceTrip is a trip priced above the fare approved on its route permit. The permit
+// is the trip's own, else its schedule's.
+type FareComplianceTrip struct {
+	TripID            string    `json:"trip_id" db:"trip_id"`
+	BusOwnerID        *string   `json:"bus_owner_id,omitempty" db:"bus_owner_id"`
+	BusOwnerName      *string   `json:"bus_owner_name,omitempty" db:"bus_owner_name"`
+	DepartureDatetime time.Time `json:"departure_datetime" db:"departure_datetime"`
+	IsBookable        bool      `json:"is_bookable" db:"is_bookable"`
+	PermitID          string    `json:"permit_id" db:"permit_id"`
+	PermitNumber      string    `json:"permit_number" db:"permit_number"`
+	ApprovedFare      float64   `json:"approved_fare" db:"approved_fare"`
+	BaseFare          float64   `json:"base_fare" db:"base_fare"`
+	MaxSeatPrice      float64   `json:"max_seat_price" db:"max_seat_price"`
+	SeatsAboveFare    int       `json:"seats_above_fare" db:"seats_above_fare"` // Seats whose price override exceeds the approved fare
+	ExcessAmount      float64   `json:"excess_amount" db:"-"`                   // Highest price minus the approved fare
+}
+
+// SetExcess fills in ExcessAmount from the trip's fares
+func (t *FareComplianceTrip) SetExcess() {
+	highest := math.Max(t.BaseFare, t.MaxSeatPrice)
+	t.ExcessAmount = math.Max(0, RoundMoney(highest-t.ApprovedFare))
+}
+
+// FareComplianceReport lists trips priced above their permit's approved fare
+type FareComplianceReport struct {
+	From    string               `json:"from"` // YYYY-MM-DD, inclusive
+	To      string               `json:"to"`   // YYYY-MM-DD, inclusive
+	Trips   []FareComplianceTrip `json:"trips"`
+	Total   int                  `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+	Enforce bool                 `json:"enforced_on_publish"` // Publishing such trips is blocked
+}
+
+// FareComplianceRequest holds the report's query parameters
+type FareComplianceRequest struct {
+	From         string `form:"from"` // Departure date YYYY-MM-DD, defaults to today
+	To           string `form:"to"`   // Departure date YYYY-MM-DD, defaults to 30 days after from
+	BusOwnerID   string `form:"bus_owner_id"`
+	BookableOnly bool   `form:"bookable_only"` // Only trips published for booking
+}
+
+// Range returns the requested local departure dates as [start, end) instants, checking the range
+func (r *FareComplianceRequest) Range(now time.Time) (time.Time, time.Time, error) {
+	local := now.In(ReportTimezone)
+	from := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ReportTimezone)
+	if r.From != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", r.From, ReportTimezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: "from must be in YYYY-MM-DD format"}
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 0, FareComplianceDefaultDays-1)
+	if r.To != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", r.To, ReportTimezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: "to must be in YYYY-MM-DD format"}
+		}
+		to = parsed
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, &ValidationError{Message: "to must not be before from"}
+	}
+	if to.AddDate(0, 0, 1).Sub(from) > FareComplianceMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, &ValidationError{Message: fmt.Sprintf("the date range must not exceed %d days", FareComplianceMaxDays)}
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// FareComplianceError is returned when publishing trips priced above their permit's approved fare
+type FareComplianceError struct {
+	Trips   []FareComplianceTrip `json:"trips"`
+	Message string               `json:"message"`
+}
+
+func (e *FareComplianceError) Error() string {
+	return e.Message
+}
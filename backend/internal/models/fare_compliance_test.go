@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFareComplianceTripSetExcess(t *testing.T) {
+	trip := FareComplianceTrip{ApprovedFare: 500, BaseFare: 550, MaxSeatPrice: 620.5}
+	trip.SetExcess()
+	assert.Equal(t, 120.5, trip.ExcessAmount)
+
+	trip = FareComplianceTrip{ApprovedFare: 500, BaseFare: 450}
+	trip.SetExcess()
+	assert.Equal(t, 0.0, trip.ExcessAmount)
+}
+
+func TestFareComplianceRequestRange(t *testing.T) {
+	now := time.Date(2026, 6, 10, 20, 0, 0, 0, time.UTC) // 11 June in Colombo
+
+	from, to, err := (&FareComplianceRequest{}).Range(now)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-06-11", from.Format("2006-01-02"))
+	assert.Equal(t, "2026-07-11", to.Format("2006-01-02"))
+
+	from, to, err = (&FareComplianceRequest{From: "2026-06-01", To: "2026-06-01"}).Range(now)
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, to.Sub(from))
+
+	_, _, err = (&FareComplianceRequest{From: "2026-06-10", To: "2026-06-01"}).Range(now)
+	assert.Error(t, err)
+
+	_, _, err = (&FareComplianceRequest{From: "2026-01-01", To: "2026-06-01"}).Range(now)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, _, err = (&FareComplianceRequest{From: "10/06/2026"}).Range(now)
+	assert.Error(t, err)
+}
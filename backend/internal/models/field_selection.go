@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SearchResultFields are the trip attributes search results can be trimmed to with fields=
+var SearchResultFields = []string{
+	"trip_id", "route_name", "route_number", "bus_type", "departure_time", "estimated_arrival",
+	"duration_minutes", "available_seats", "cheapest_seat_price", "total_seats", "fare",
+	"price_adjustment", "boarding_point", "dropping_point", "bus_features", "is_bookable",
+	"on_time_percentage", "route_stops", "master_route_id",
+}
+
+// BookableTripFields are the trip attributes bookable trip lists can be trimmed to with fields=
+var BookableTripFields = []string{
+	"id", "trip_schedule_id", "bus_owner_route_id", "permit_id", "bus_id", "departure_datetime",
+	"estimated_duration_minutes", "assigned_driver_id", "assigned_conductor_id", "seat_layout_id",
+	"is_bookable", "ever_published", "total_seats", "base_fare", "booking_advance_hours",
+	"assignment_deadline", "status", "cancellation_reason", "cancelled_at", "selected_stop_ids",
+	"created_at", "updated_at",
+}
+
+// FieldSelection is the top-level attributes a list client asked for, so low-bandwidth clients
+// get only what their list view renders. Nil selects everything.
+type FieldSelection []string
+
+// ParseFieldSelection reads a comma-separated fields parameter against the allowed attributes.
+// The identifying attribute is always included so clients can fetch a trip's full detail.
+func ParseFieldSelection(value string, allowed []string, id string) (FieldSelection, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	known := make(map[string]bool, len(allowed))
+	for _, field := range allowed {
+		known[field] = true
+	}
+	selection := FieldSelection{id}
+	seen := map[string]bool{id: true}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		if !known[field] {
+			return nil, &ValidationError{Message: fmt.Sprintf("unknown field %q (allowed: %s)", field, strings.Join(allowed, ", "))}
+		}
+		seen[field] = true
+		selection = append(selection, field)
+	}
+	return selection, nil
+}
+
+// Select trims each item of a slice to the selected attributes. Attributes an item omits stay
+// omitted. A nil selection returns the items unchanged.
+func (f FieldSelection) Select(items interface{}) (interface{}, error) {
+	if f == nil {
+		return items, nil
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results: %w", err)
+	}
+	var full []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &full); err != nil {
+		return nil, fmt.Errorf("failed to select fields: %w", err)
+	}
+
+	selected := make([]map[string]json.RawMessage, len(full))
+	for i, item := range full {
+		selected[i] = make(map[string]json.RawMessage, len(f))
+		for _, field := range f {
+			if value, ok := item[field]; ok {
+				selected[i][field] = value
+			}
+		}
+	}
+	return selected, nil
+}
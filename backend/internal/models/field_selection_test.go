@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldSelection(t *testing.T) {
+	fields, err := ParseFieldSelection("", SearchResultFields, "trip_id")
+	require.NoError(t, err)
+	assert.Nil(t, fields, "no fields selects everything")
+
+	fields, err = ParseFieldSelection(" fare, departure_time,,fare,trip_id ", SearchResultFields, "trip_id")
+	require.NoError(t, err)
+	assert.Equal(t, FieldSelection{"trip_id", "fare", "departure_time"}, fields)
+
+	_, err = ParseFieldSelection("fare,driver_phone", SearchResultFields, "trip_id")
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestFieldSelectionSelect(t *testing.T) {
+	trips := []TripResult{{
+		TripID:        uuid.MustParse("7f1d2c3b-0000-4000-8000-000000000001"),
+		RouteName:     "Colombo - Kandy",
+		Fare:          450,
+		DepartureTime: time.Date(2026, 5, 1, 6, 30, 0, 0, time.UTC),
+	}}
+
+	all, err := FieldSelection(nil).Select(trips)
+	require.NoError(t, err)
+	assert.Equal(t, trips, all)
+
+	selected, err := FieldSelection{"trip_id", "fare", "departure_time", "available_seats"}.Select(trips)
+	require.NoError(t, err)
+	data, err := json.Marshal(selected)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"trip_id":"7f1d2c3b-0000-4000-8000-000000000001","fare":450,"departure_time":"2026-05-01T06:30:00+05:30"}]`, string(data),
+		"custom-marshalled attributes are selectable and omitted ones stay omitted")
+}
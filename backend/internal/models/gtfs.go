@@ -0,0 +1,307 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	GTFSRouteTypeBus = "3"   // GTFS route_type for bus services
+	GTFSCurrency     = "LKR" // Fares are charged in rupees
+)
+
+// GTFSAgency is the agency every exported route belongs to: the platform selling the trips
+type GTFSAgency struct {
+	ID    string
+	Name  string
+	URL   string
+	Phone string
+	Lang  string
+}
+
+// GTFSTrip is a published scheduled trip of the core tenant to export, with its master route.
+// StopIDs are the trip's or its route's selected stops, or empty for every stop of the route.
+type GTFSTrip struct {
+	TripID                   string         `db:"trip_id"`
+	DepartureDatetime        time.Time      `db:"departure_datetime"`
+	EstimatedDurationMinutes *int           `db:"estimated_duration_minutes"`
+	BaseFare                 float64        `db:"base_fare"`
+	MasterRouteID            string         `db:"master_route_id"`
+	Direction                *string        `db:"direction"` // Bus owner route direction, UP or DOWN
+	StopIDs                  pq.StringArray `db:"stop_ids"`
+}
+
+// GTFSFeedSource is everything a GTFS static feed is built from
+type GTFSFeedSource struct {
+	Agency GTFSAgency
+	Routes []MasterRoute
+	Stops  []MasterRouteStop // Stops of the routes, in any order
+	Trips  []GTFSTrip
+}
+
+// GTFSTable is one file of a GTFS feed, such as stops.txt
+type GTFSTable struct {
+	Name   string
+	Header []string
+	Rows   [][]string
+}
+
+// GTFSFeed is a rendered GTFS static feed, one table per file
+type GTFSFeed struct {
+	Tables       []GTFSTable
+	Trips        int // Trips exported
+	SkippedTrips int // Trips left out for lack of located stops or stop times
+}
+
+// gtfsStopTime is a trip's call at a stop, in seconds after the service day's midnight
+type gtfsStopTime struct {
+	stop      MasterRouteStop
+	seconds   int
+	known     bool
+	timepoint bool
+}
+
+// BuildGTFSFeed renders the feed tables. Each trip runs on the local service day it departs
+// (calendar_dates.txt, one service per date) and is timed at its stops from the route's
+// arrival offsets, counted from the trip's first stop. Stops without coordinates cannot be
+// placed on a map and are left out; a trip whose first or last remaining stop has no time
+// is left out too. Each route gets its lowest base fare, since GTFS fares attach to routes.
+func BuildGTFSFeed(src *GTFSFeedSource, generatedAt time.Time) *GTFSFeed {
+	routes := map[string]MasterRoute{}
+	for _, route := range src.Routes {
+		routes[route.ID] = route
+	}
+	routeStops := gtfsRouteStops(src.Stops)
+
+	trips := append([]GTFSTrip(nil), src.Trips...)
+	sort.Slice(trips, func(i, j int) bool {
+		if !trips[i].DepartureDatetime.Equal(trips[j].DepartureDatetime) {
+			return trips[i].DepartureDatetime.Before(trips[j].DepartureDatetime)
+		}
+		return trips[i].TripID < trips[j].TripID
+	})
+
+	feed := &GTFSFeed{}
+	tripRows := [][]string{}
+	stopTimeRows := [][]string{}
+	usedRoutes := map[string]bool{}
+	usedStops := map[string]MasterRouteStop{}
+	serviceDates := map[string]bool{}
+	fares := map[string]float64{}
+
+	for _, trip := range trips {
+		route, ok := routes[trip.MasterRouteID]
+		if !ok {
+			feed.SkippedTrips++
+			continue
+		}
+		calls := gtfsTripStopTimes(trip, routeStops[route.ID])
+		if calls == nil {
+			feed.SkippedTrips++
+			continue
+		}
+
+		serviceID := trip.DepartureDatetime.In(ReportTimezone).Format("20060102")
+		serviceDates[serviceID] = true
+		usedRoutes[route.ID] = true
+		if fare, ok := fares[route.ID]; !ok || trip.BaseFare < fare {
+			fares[route.ID] = trip.BaseFare
+		}
+
+		direction := ""
+		if id, ok := gtfsDirectionID(trip.Direction); ok {
+			direction = fmt.Sprint(id)
+		}
+		tripRows = append(tripRows, []string{
+			route.ID, serviceID, trip.TripID, calls[len(calls)-1].stop.StopName, direction,
+		})
+
+		for _, call := range calls {
+			usedStops[call.stop.ID] = call.stop
+			at, timepoint := "", "0"
+			if call.known {
+				at = gtfsTime(call.seconds)
+			}
+			if call.timepoint {
+				timepoint = "1"
+			}
+			stopTimeRows = append(stopTimeRows, []string{
+				trip.TripID, at, at, call.stop.ID, fmt.Sprint(call.stop.StopOrder), timepoint,
+			})
+		}
+		feed.Trips++
+	}
+
+	routeRows := [][]string{}
+	fareRows := [][]string{}
+	fareRuleRows := [][]string{}
+	for _, id := range sortedKeys(usedRoutes) {
+		route := routes[id]
+		routeRows = append(routeRows, []string{
+			route.ID, src.Agency.ID, route.RouteNumber, route.RouteName, GTFSRouteTypeBus,
+		})
+		fareID := "fare-" + route.ID
+		fareRows = append(fareRows, []string{
+			fareID, fmt.Sprintf("%.2f", fares[id]), GTFSCurrency, "1", "0", src.Agency.ID, // Paid before boarding, no transfers
+		})
+		fareRuleRows = append(fareRuleRows, []string{fareID, route.ID})
+	}
+
+	stopRows := [][]string{}
+	for _, id := range sortedKeys(usedStops) {
+		stop := usedStops[id]
+		stopRows = append(stopRows, []string{
+			stop.ID, stop.StopName, fmt.Sprintf("%.6f", *stop.Latitude), fmt.Sprintf("%.6f", *stop.Longitude),
+		})
+	}
+
+	dates := sortedKeys(serviceDates)
+	calendarRows := make([][]string, 0, len(dates))
+	for _, date := range dates {
+		calendarRows = append(calendarRows, []string{date, date, "1"})
+	}
+	startDate, endDate := "", ""
+	if len(dates) > 0 {
+		startDate, endDate = dates[0], dates[len(dates)-1]
+	}
+
+	feed.Tables = []GTFSTable{
+		{
+			Name:   "agency.txt",
+			Header: []string{"agency_id", "agency_name", "agency_url", "agency_timezone", "agency_lang", "agency_phone"},
+			Rows: [][]string{{
+				src.Agency.ID, src.Agency.Name, src.Agency.URL, ReportTimezone.String(), src.Agency.Lang, src.Agency.Phone,
+			}},
+		},
+		{Name: "routes.txt", Header: []string{"route_id", "agency_id", "route_short_name", "route_long_name", "route_type"}, Rows: routeRows},
+		{Name: "stops.txt", Header: []string{"stop_id", "stop_name", "stop_lat", "stop_lon"}, Rows: stopRows},
+		{Name: "calendar_dates.txt", Header: []string{"service_id", "date", "exception_type"}, Rows: calendarRows},
+		{Name: "trips.txt", Header: []string{"route_id", "service_id", "trip_id", "trip_headsign", "direction_id"}, Rows: tripRows},
+		{
+			Name:   "stop_times.txt",
+			Header: []string{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence", "timepoint"},
+			Rows:   stopTimeRows,
+		},
+		{
+			Name:   "fare_attributes.txt",
+			Header: []string{"fare_id", "price", "currency_type", "payment_method", "transfers", "agency_id"},
+			Rows:   fareRows,
+		},
+		{Name: "fare_rules.txt", Header: []string{"fare_id", "route_id"}, Rows: fareRuleRows},
+		{
+			Name:   "feed_info.txt",
+			Header: []string{"feed_publisher_name", "feed_publisher_url", "feed_lang", "feed_start_date", "feed_end_date", "feed_version"},
+			Rows: [][]string{{
+				src.Agency.Name, src.Agency.URL, src.Agency.Lang, startDate, endDate, generatedAt.UTC().Format("20060102T150405Z"),
+			}},
+		},
+	}
+	return feed
+}
+
+// gtfsRouteStops groups stops by route, in stop order
+func gtfsRouteStops(stops []MasterRouteStop) map[string][]MasterRouteStop {
+	routeStops := map[string][]MasterRouteStop{}
+	for _, stop := range stops {
+		routeStops[stop.MasterRouteID] = append(routeStops[stop.MasterRouteID], stop)
+	}
+	for _, stops := range routeStops {
+		sort.Slice(stops, func(i, j int) bool { return stops[i].StopOrder < stops[j].StopOrder })
+	}
+	return routeStops
+}
+
+// gtfsDirectionID maps a bus owner route direction to direction_id: UP is 0, DOWN is 1
+func gtfsDirectionID(direction *string) (uint32, bool) {
+	if direction == nil {
+		return 0, false
+	}
+	switch strings.ToUpper(*direction) {
+	case "UP":
+		return 0, true
+	case "DOWN":
+		return 1, true
+	}
+	return 0, false
+}
+
+// gtfsServiceDay is the local midnight GTFS stop times of a trip count from
+func gtfsServiceDay(departure time.Time) time.Time {
+	local := departure.In(ReportTimezone)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ReportTimezone)
+}
+
+// gtfsTripStopTimes times a trip's located stops, or returns nil when the trip cannot be
+// exported. The trip departs its first stop at its departure time; later stops are reached
+// after their offset from that stop, and the last stop of the trip after its duration when it
+// has no offset. Times never go backwards, as offsets entered by hand sometimes do.
+func gtfsTripStopTimes(trip GTFSTrip, routeStops []MasterRouteStop) []gtfsStopTime {
+	stops := routeStops
+	if len(trip.StopIDs) > 0 {
+		selected := map[string]bool{}
+		for _, id := range trip.StopIDs {
+			selected[id] = true
+		}
+		stops = []MasterRouteStop{}
+		for _, stop := range routeStops {
+			if selected[stop.ID] {
+				stops = append(stops, stop)
+			}
+		}
+	}
+	if len(stops) < 2 {
+		return nil
+	}
+
+	departure := int(trip.DepartureDatetime.Sub(gtfsServiceDay(trip.DepartureDatetime)) / time.Second)
+	baseOffset := 0
+	if stops[0].ArrivalTimeOffsetMinutes != nil {
+		baseOffset = *stops[0].ArrivalTimeOffsetMinutes
+	}
+
+	calls := []gtfsStopTime{}
+	previous := departure
+	for i, stop := range stops {
+		call := gtfsStopTime{stop: stop}
+		switch {
+		case i == 0:
+			call.seconds, call.known, call.timepoint = departure, true, true
+		case stop.ArrivalTimeOffsetMinutes != nil:
+			call.seconds, call.known = departure+(*stop.ArrivalTimeOffsetMinutes-baseOffset)*60, true
+		case i == len(stops)-1 && trip.EstimatedDurationMinutes != nil:
+			call.seconds, call.known = departure+*trip.EstimatedDurationMinutes*60, true
+		}
+		if call.known {
+			if call.seconds < previous {
+				call.seconds = previous
+			}
+			previous = call.seconds
+		}
+		if stop.Latitude != nil && stop.Longitude != nil {
+			calls = append(calls, call)
+		}
+	}
+	if len(calls) < 2 || !calls[0].known || !calls[len(calls)-1].known {
+		return nil
+	}
+	return calls
+}
+
+// gtfsTime formats seconds after the service day's midnight as HH:MM:SS, past 24:00:00 for
+// trips running after midnight
+func gtfsTime(seconds int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
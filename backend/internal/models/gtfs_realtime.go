@@ -0,0 +1,230 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/smarttransit/sms-auth-backend/pkg/gtfsrt"
+)
+
+// GTFSRealtimeStaleAfter is how old a bus's last location may be and still be published
+const GTFSRealtimeStaleAfter = 10 * time.Minute
+
+// GTFSRealtimeFeedKind selects the entities of a GTFS Realtime feed
+type GTFSRealtimeFeedKind string
+
+const (
+	GTFSRealtimeAll              GTFSRealtimeFeedKind = "all"
+	GTFSRealtimeVehiclePositions GTFSRealtimeFeedKind = "vehicle_positions"
+	GTFSRealtimeTripUpdates      GTFSRealtimeFeedKind = "trip_updates"
+)
+
+// GTFSRealtimeTrip is a published trip that is being tracked, with its active trip's state
+type GTFSRealtimeTrip struct {
+	GTFSTrip
+	BusID                *string          `db:"bus_id"`
+	LicensePlate         *string          `db:"license_plate"`
+	Latitude             *float64         `db:"current_latitude"`
+	Longitude            *float64         `db:"current_longitude"`
+	LastLocationUpdate   *time.Time       `db:"last_location_update"`
+	SpeedKmh             *float64         `db:"current_speed_kmh"`
+	Heading              *float64         `db:"heading"`
+	CurrentStopID        *string          `db:"current_stop_id"`
+	NextStopID           *string          `db:"next_stop_id"`
+	StopsCompleted       pq.StringArray   `db:"stops_completed"`
+	ActualDepartureTime  *time.Time       `db:"actual_departure_time"`
+	EstimatedArrivalTime *time.Time       `db:"estimated_arrival_time"`
+	Status               ActiveTripStatus `db:"status"`
+}
+
+// GTFSRealtimeSource is everything a GTFS Realtime feed is built from
+type GTFSRealtimeSource struct {
+	Active    []GTFSRealtimeTrip
+	Cancelled []GTFSTrip        // Published trips cancelled before running
+	Stops     []MasterRouteStop // Stops of the trips' routes
+}
+
+// BuildGTFSRealtimeFeed renders vehicle positions and trip updates for the trips of the static
+// feed. Stops and times are those of the static feed, so trips it leaves out are left out
+// here too. A trip's delay comes from its estimated arrival when the crew app reports one,
+// else from how late it left its first stop; a trip not yet gone is as late as it is overdue.
+// Positions older than GTFSRealtimeStaleAfter are not published.
+func BuildGTFSRealtimeFeed(src *GTFSRealtimeSource, kind GTFSRealtimeFeedKind, now time.Time) *gtfsrt.FeedMessage {
+	feed := &gtfsrt.FeedMessage{Timestamp: uint64(now.Unix())}
+	routeStops := gtfsRouteStops(src.Stops)
+	vehicles := kind == GTFSRealtimeAll || kind == GTFSRealtimeVehiclePositions
+	updates := kind == GTFSRealtimeAll || kind == GTFSRealtimeTripUpdates
+
+	for _, trip := range src.Active {
+		calls := gtfsTripStopTimes(trip.GTFSTrip, routeStops[trip.MasterRouteID])
+		if calls == nil {
+			continue
+		}
+		descriptor := gtfsRealtimeTripDescriptor(trip.GTFSTrip, calls)
+		var vehicle *gtfsrt.VehicleDescriptor
+		if trip.BusID != nil {
+			vehicle = &gtfsrt.VehicleDescriptor{ID: *trip.BusID}
+			if trip.LicensePlate != nil {
+				vehicle.Label = *trip.LicensePlate
+				vehicle.LicensePlate = *trip.LicensePlate
+			}
+		}
+		next := gtfsRealtimeNextCall(trip, calls)
+
+		if vehicles {
+			if position := gtfsRealtimeVehiclePosition(trip, calls, next, now); position != nil {
+				position.Trip = &descriptor
+				position.Vehicle = vehicle
+				feed.Entities = append(feed.Entities, gtfsrt.FeedEntity{ID: "vehicle-" + trip.TripID, Vehicle: position})
+			}
+		}
+		if updates {
+			update := gtfsRealtimeTripUpdate(trip, calls, next, now)
+			update.Trip = descriptor
+			update.Vehicle = vehicle
+			feed.Entities = append(feed.Entities, gtfsrt.FeedEntity{ID: "trip-" + trip.TripID, TripUpdate: update})
+		}
+	}
+
+	if updates {
+		for _, trip := range src.Cancelled {
+			calls := gtfsTripStopTimes(trip, routeStops[trip.MasterRouteID])
+			if calls == nil {
+				continue
+			}
+			descriptor := gtfsRealtimeTripDescriptor(trip, calls)
+			descriptor.ScheduleRelationship = gtfsrt.TripCanceled
+			feed.Entities = append(feed.Entities, gtfsrt.FeedEntity{
+				ID:         "trip-" + trip.TripID,
+				TripUpdate: &gtfsrt.TripUpdate{Trip: descriptor, Timestamp: feed.Timestamp},
+			})
+		}
+	}
+	return feed
+}
+
+func gtfsRealtimeTripDescriptor(trip GTFSTrip, calls []gtfsStopTime) gtfsrt.TripDescriptor {
+	descriptor := gtfsrt.TripDescriptor{
+		TripID:    trip.TripID,
+		RouteID:   trip.MasterRouteID,
+		StartTime: gtfsTime(calls[0].seconds),
+		StartDate: trip.DepartureDatetime.In(ReportTimezone).Format("20060102"),
+	}
+	if id, ok := gtfsDirectionID(trip.Direction); ok {
+		descriptor.DirectionID = &id
+	}
+	return descriptor
+}
+
+// gtfsRealtimeNextCall is the index of the stop the bus is at or heading to: the crew app's
+// next stop when it is still ahead, else the first stop not yet reached. Returns -1 once
+// every stop is done.
+func gtfsRealtimeNextCall(trip GTFSRealtimeTrip, calls []gtfsStopTime) int {
+	completed := map[string]bool{}
+	for _, id := range trip.StopsCompleted {
+		completed[id] = true
+	}
+	first := 0
+	if trip.ActualDepartureTime != nil {
+		first = 1 // It has left its first stop
+	}
+	if trip.NextStopID != nil && !completed[*trip.NextStopID] {
+		for i := first; i < len(calls); i++ {
+			if calls[i].stop.ID == *trip.NextStopID {
+				return i
+			}
+		}
+	}
+	for i := first; i < len(calls); i++ {
+		if !completed[calls[i].stop.ID] {
+			return i
+		}
+	}
+	return -1
+}
+
+// gtfsRealtimeDelay is how many seconds late the trip is running
+func gtfsRealtimeDelay(trip GTFSRealtimeTrip, calls []gtfsStopTime, now time.Time) int32 {
+	serviceDay := gtfsServiceDay(trip.DepartureDatetime)
+	var delay time.Duration
+	switch {
+	case trip.EstimatedArrivalTime != nil && trip.ActualDepartureTime != nil:
+		delay = trip.EstimatedArrivalTime.Sub(serviceDay.Add(time.Duration(calls[len(calls)-1].seconds) * time.Second))
+	case trip.ActualDepartureTime != nil:
+		delay = trip.ActualDepartureTime.Sub(trip.DepartureDatetime)
+	case now.After(trip.DepartureDatetime):
+		delay = now.Sub(trip.DepartureDatetime)
+	}
+	return int32(delay / time.Second)
+}
+
+func gtfsRealtimeTripUpdate(trip GTFSRealtimeTrip, calls []gtfsStopTime, next int, now time.Time) *gtfsrt.TripUpdate {
+	delay := gtfsRealtimeDelay(trip, calls, now)
+	update := &gtfsrt.TripUpdate{Timestamp: uint64(now.Unix()), Delay: &delay}
+	if trip.LastLocationUpdate != nil {
+		update.Timestamp = uint64(trip.LastLocationUpdate.Unix())
+	}
+	if next < 0 {
+		return update
+	}
+
+	call := calls[next]
+	sequence := uint32(call.stop.StopOrder)
+	event := &gtfsrt.StopTimeEvent{Delay: &delay}
+	if call.known {
+		predicted := gtfsServiceDay(trip.DepartureDatetime).
+			Add(time.Duration(call.seconds)*time.Second + time.Duration(delay)*time.Second).Unix()
+		event.Time = &predicted
+	}
+	stopUpdate := gtfsrt.StopTimeUpdate{StopSequence: &sequence, StopID: call.stop.ID}
+	if next == 0 {
+		stopUpdate.Departure = event // Still to leave its first stop
+	} else {
+		stopUpdate.Arrival = event
+	}
+	update.StopTimeUpdates = []gtfsrt.StopTimeUpdate{stopUpdate}
+	return update
+}
+
+func gtfsRealtimeVehiclePosition(trip GTFSRealtimeTrip, calls []gtfsStopTime, next int, now time.Time) *gtfsrt.VehiclePosition {
+	if trip.Latitude == nil || trip.Longitude == nil || trip.LastLocationUpdate == nil ||
+		now.Sub(*trip.LastLocationUpdate) > GTFSRealtimeStaleAfter {
+		return nil
+	}
+	position := &gtfsrt.VehiclePosition{
+		Position:  &gtfsrt.Position{Latitude: float32(*trip.Latitude), Longitude: float32(*trip.Longitude)},
+		Timestamp: uint64(trip.LastLocationUpdate.Unix()),
+	}
+	if trip.Heading != nil {
+		bearing := float32(*trip.Heading)
+		position.Position.Bearing = &bearing
+	}
+	if trip.SpeedKmh != nil {
+		speed := float32(*trip.SpeedKmh / 3.6)
+		position.Position.Speed = &speed
+	}
+
+	status := gtfsrt.VehicleInTransitTo
+	stop := -1
+	if trip.Status == ActiveTripStatusAtStop && trip.CurrentStopID != nil {
+		for i, call := range calls {
+			if call.stop.ID == *trip.CurrentStopID {
+				status, stop = gtfsrt.VehicleStoppedAt, i
+				break
+			}
+		}
+	}
+	if stop < 0 {
+		stop = next
+		if stop == 0 {
+			status = gtfsrt.VehicleStoppedAt // Waiting at its first stop
+		}
+	}
+	if stop >= 0 {
+		sequence := uint32(calls[stop].stop.StopOrder)
+		position.CurrentStopSequence = &sequence
+		position.StopID = calls[stop].stop.ID
+		position.CurrentStatus = &status
+	}
+	return position
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/gtfsrt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGTFSRealtimeFeed(t *testing.T) {
+	intp := func(v int) *int { return &v }
+	floatp := func(v float64) *float64 { return &v }
+	strp := func(v string) *string { return &v }
+	timep := func(v time.Time) *time.Time { return &v }
+
+	stops := []MasterRouteStop{
+		{ID: "s1", MasterRouteID: "r1", StopOrder: 1, Latitude: floatp(6.93), Longitude: floatp(79.86), ArrivalTimeOffsetMinutes: intp(0)},
+		{ID: "s2", MasterRouteID: "r1", StopOrder: 2, Latitude: floatp(7.25), Longitude: floatp(80.35), ArrivalTimeOffsetMinutes: intp(90)},
+		{ID: "s3", MasterRouteID: "r1", StopOrder: 3, Latitude: floatp(7.29), Longitude: floatp(80.63), ArrivalTimeOffsetMinutes: intp(180)},
+	}
+	departure := time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC) // 08:00 in Colombo
+	now := departure.Add(time.Hour)
+	running := GTFSRealtimeTrip{
+		GTFSTrip:            GTFSTrip{TripID: "t1", DepartureDatetime: departure, MasterRouteID: "r1", Direction: strp("DOWN")},
+		BusID:               strp("bus-1"),
+		LicensePlate:        strp("NB-1234"),
+		Latitude:            floatp(7.1),
+		Longitude:           floatp(80.1),
+		LastLocationUpdate:  timep(now.Add(-time.Minute)),
+		SpeedKmh:            floatp(36),
+		ActualDepartureTime: timep(departure.Add(5 * time.Minute)),
+		Status:              ActiveTripStatusInTransit,
+	}
+	waiting := GTFSRealtimeTrip{
+		GTFSTrip: GTFSTrip{TripID: "t2", DepartureDatetime: now.Add(-10 * time.Minute), MasterRouteID: "r1"},
+		Status:   ActiveTripStatusNotStarted,
+		// Never reported a location
+	}
+	src := &GTFSRealtimeSource{
+		Active:    []GTFSRealtimeTrip{running, waiting},
+		Cancelled: []GTFSTrip{{TripID: "t3", DepartureDatetime: now.Add(2 * time.Hour), MasterRouteID: "r1"}},
+		Stops:     stops,
+	}
+
+	feed := BuildGTFSRealtimeFeed(src, GTFSRealtimeAll, now)
+	assert.Equal(t, uint64(now.Unix()), feed.Timestamp)
+	require.Len(t, feed.Entities, 4)
+
+	vehicle := feed.Entities[0].Vehicle
+	require.NotNil(t, vehicle)
+	assert.Equal(t, "t1", vehicle.Trip.TripID)
+	assert.Equal(t, "08:00:00", vehicle.Trip.StartTime)
+	assert.Equal(t, "20260301", vehicle.Trip.StartDate)
+	assert.Equal(t, uint32(1), *vehicle.Trip.DirectionID)
+	assert.Equal(t, "s2", vehicle.StopID)
+	assert.Equal(t, gtfsrt.VehicleInTransitTo, *vehicle.CurrentStatus)
+	assert.InDelta(t, 10, *vehicle.Position.Speed, 1e-4, "speed is in meters per second")
+	assert.Equal(t, "NB-1234", vehicle.Vehicle.LicensePlate)
+
+	update := feed.Entities[1].TripUpdate
+	require.NotNil(t, update)
+	assert.Equal(t, int32(300), *update.Delay, "left its first stop 5 minutes late")
+	require.Len(t, update.StopTimeUpdates, 1)
+	assert.Equal(t, "s2", update.StopTimeUpdates[0].StopID)
+	assert.Equal(t, departure.Add(95*time.Minute).Unix(), *update.StopTimeUpdates[0].Arrival.Time)
+
+	waitingUpdate := feed.Entities[2].TripUpdate
+	require.NotNil(t, waitingUpdate, "trips without a position have no vehicle entity")
+	assert.Equal(t, int32(600), *waitingUpdate.Delay, "an overdue departure is as late as it is overdue")
+	assert.NotNil(t, waitingUpdate.StopTimeUpdates[0].Departure)
+
+	cancelled := feed.Entities[3].TripUpdate
+	assert.Equal(t, "t3", cancelled.Trip.TripID)
+	assert.Equal(t, gtfsrt.TripCanceled, cancelled.Trip.ScheduleRelationship)
+
+	positions := BuildGTFSRealtimeFeed(src, GTFSRealtimeVehiclePositions, now)
+	require.Len(t, positions.Entities, 1)
+	assert.Nil(t, BuildGTFSRealtimeFeed(src, GTFSRealtimeVehiclePositions, now.Add(time.Hour)).Entities, "stale positions are left out")
+}
+
+func TestBuildGTFSRealtimeFeed_EstimatedArrival(t *testing.T) {
+	intp := func(v int) *int { return &v }
+	floatp := func(v float64) *float64 { return &v }
+	strp := func(v string) *string { return &v }
+	departure := time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC)
+	estimated := departure.Add(170 * time.Minute)
+	actual := departure
+	src := &GTFSRealtimeSource{
+		Active: []GTFSRealtimeTrip{{
+			GTFSTrip:             GTFSTrip{TripID: "t1", DepartureDatetime: departure, MasterRouteID: "r1"},
+			NextStopID:           strp("s3"),
+			StopsCompleted:       []string{"s1", "s2"},
+			ActualDepartureTime:  &actual,
+			EstimatedArrivalTime: &estimated,
+			Status:               ActiveTripStatusInTransit,
+		}},
+		Stops: []MasterRouteStop{
+			{ID: "s1", MasterRouteID: "r1", StopOrder: 1, Latitude: floatp(6.9), Longitude: floatp(79.8)},
+			{ID: "s2", MasterRouteID: "r1", StopOrder: 2, Latitude: floatp(7.2), Longitude: floatp(80.3), ArrivalTimeOffsetMinutes: intp(90)},
+			{ID: "s3", MasterRouteID: "r1", StopOrder: 3, Latitude: floatp(7.3), Longitude: floatp(80.6), ArrivalTimeOffsetMinutes: intp(180)},
+		},
+	}
+
+	update := BuildGTFSRealtimeFeed(src, GTFSRealtimeTripUpdates, departure.Add(2*time.Hour)).Entities[0].TripUpdate
+	assert.Equal(t, int32(-600), *update.Delay, "running 10 minutes early by its estimated arrival")
+	assert.Equal(t, uint32(3), *update.StopTimeUpdates[0].StopSequence)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gtfsTable(t *testing.T, feed *GTFSFeed, name string) GTFSTable {
+	for _, table := range feed.Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	t.Fatalf("feed has no %s", name)
+	return GTFSTable{}
+}
+
+func TestBuildGTFSFeed(t *testing.T) {
+	intp := func(v int) *int { return &v }
+	floatp := func(v float64) *float64 { return &v }
+	up := "UP"
+	stops := []MasterRouteStop{
+		{ID: "s3", MasterRouteID: "r1", StopName: "Kandy", StopOrder: 3, Latitude: floatp(7.2906), Longitude: floatp(80.6337)},
+		{ID: "s1", MasterRouteID: "r1", StopName: "Colombo", StopOrder: 1, Latitude: floatp(6.9271), Longitude: floatp(79.8612), ArrivalTimeOffsetMinutes: intp(0)},
+		{ID: "s2", MasterRouteID: "r1", StopName: "Kegalle", StopOrder: 2, ArrivalTimeOffsetMinutes: intp(90)}, // Not located
+		{ID: "s4", MasterRouteID: "r1", StopName: "Peradeniya", StopOrder: 4, Latitude: floatp(7.2667), Longitude: floatp(80.6)},
+	}
+	// 23:30 in Colombo, arriving after midnight
+	departure := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	src := &GTFSFeedSource{
+		Agency: GTFSAgency{ID: "smarttransit", Name: "SmartTransit", URL: "https://smarttransit.lk", Lang: "en"},
+		Routes: []MasterRoute{{ID: "r1", RouteNumber: "1", RouteName: "Colombo - Kandy"}},
+		Stops:  stops,
+		Trips: []GTFSTrip{
+			{TripID: "t2", DepartureDatetime: departure.Add(time.Hour), EstimatedDurationMinutes: intp(180), BaseFare: 900,
+				MasterRouteID: "r1", Direction: &up, StopIDs: []string{"s1", "s2", "s3"}},
+			{TripID: "t1", DepartureDatetime: departure, EstimatedDurationMinutes: intp(200), BaseFare: 750, MasterRouteID: "r1"},
+			{TripID: "t3", DepartureDatetime: departure, MasterRouteID: "r1"},      // Last stop has no time
+			{TripID: "t4", DepartureDatetime: departure, MasterRouteID: "missing"}, // Route not exported
+		},
+	}
+
+	feed := BuildGTFSFeed(src, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, 2, feed.Trips)
+	assert.Equal(t, 2, feed.SkippedTrips)
+
+	trips := gtfsTable(t, feed, "trips.txt")
+	require.Len(t, trips.Rows, 2)
+	assert.Equal(t, []string{"r1", "20260301", "t1", "Peradeniya", ""}, trips.Rows[0], "trips are in departure order")
+	assert.Equal(t, []string{"r1", "20260302", "t2", "Kandy", "0"}, trips.Rows[1], "service day is the local departure date")
+
+	stopTimes := gtfsTable(t, feed, "stop_times.txt")
+	require.Len(t, stopTimes.Rows, 5, "unlocated stops are left out")
+	assert.Equal(t, []string{"t1", "23:30:00", "23:30:00", "s1", "1", "1"}, stopTimes.Rows[0])
+	assert.Equal(t, []string{"t1", "", "", "s3", "3", "0"}, stopTimes.Rows[1])
+	assert.Equal(t, []string{"t1", "26:50:00", "26:50:00", "s4", "4", "0"}, stopTimes.Rows[2])
+	assert.Equal(t, []string{"t2", "03:30:00", "03:30:00", "s3", "3", "0"}, stopTimes.Rows[4], "the last selected stop arrives after the trip's duration")
+
+	assert.Len(t, gtfsTable(t, feed, "stops.txt").Rows, 3)
+	assert.Equal(t, [][]string{{"20260301", "20260301", "1"}, {"20260302", "20260302", "1"}}, gtfsTable(t, feed, "calendar_dates.txt").Rows)
+	assert.Equal(t, [][]string{{"fare-r1", "750.00", "LKR", "1", "0", "smarttransit"}}, gtfsTable(t, feed, "fare_attributes.txt").Rows)
+	assert.Equal(t, [][]string{{"r1", "smarttransit", "1", "Colombo - Kandy", "3"}}, gtfsTable(t, feed, "routes.txt").Rows)
+	info := gtfsTable(t, feed, "feed_info.txt").Rows[0]
+	assert.Equal(t, "20260301", info[3])
+	assert.Equal(t, "20260302", info[4])
+}
+
+func TestBuildGTFSFeed_TimesNeverGoBackwards(t *testing.T) {
+	intp := func(v int) *int { return &v }
+	floatp := func(v float64) *float64 { return &v }
+	src := &GTFSFeedSource{
+		Routes: []MasterRoute{{ID: "r1"}},
+		Stops: []MasterRouteStop{
+			{ID: "a", MasterRouteID: "r1", StopOrder: 1, Latitude: floatp(7), Longitude: floatp(80), ArrivalTimeOffsetMinutes: intp(10)},
+			{ID: "b", MasterRouteID: "r1", StopOrder: 2, Latitude: floatp(7), Longitude: floatp(80), ArrivalTimeOffsetMinutes: intp(60)},
+			{ID: "c", MasterRouteID: "r1", StopOrder: 3, Latitude: floatp(7), Longitude: floatp(80), ArrivalTimeOffsetMinutes: intp(40)},
+		},
+		Trips: []GTFSTrip{{TripID: "t", DepartureDatetime: time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC), MasterRouteID: "r1"}},
+	}
+
+	rows := gtfsTable(t, BuildGTFSFeed(src, time.Now()), "stop_times.txt").Rows
+	require.Len(t, rows, 3)
+	assert.Equal(t, "08:00:00", rows[0][1])
+	assert.Equal(t, "08:50:00", rows[1][1], "offsets count from the trip's first stop")
+	assert.Equal(t, "08:50:00", rows[2][1])
+}
@@ -243,6 +243,13 @@ type LoungeBooking struct {
 	DiscountAmount string `db:"discount_amount" json:"discount_amount"`
 	TotalAmount    string `db:"total_amount" json:"total_amount"`
 
+	// Bundle discount (booked with a bus ticket); the funder is who settlement charges it to
+	DiscountFundedBy     *BundleDiscountFunder `db:"discount_funded_by" json:"discount_funded_by,omitempty"`
+	BundleDiscountRuleID *string               `db:"bundle_discount_rule_id" json:"bundle_discount_rule_id,omitempty"`
+
+	// Platform-funded promo code discount, kept apart from discount_amount and taken off total_amount
+	PromoDiscountAmount *string `db:"promo_discount_amount" json:"promo_discount_amount,omitempty"` // DECIMAL
+
 	// Status & Payment
 	Status        LoungeBookingStatus `db:"status" json:"status"`
 	PaymentStatus LoungePaymentStatus `db:"payment_status" json:"payment_status"`
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BundleDiscountFunder is the party that pays for a bundle discount at settlement
+type BundleDiscountFunder string
+
+const (
+	BundleFundedByLoungeOwner BundleDiscountFunder = "lounge_owner" // Comes out of the lounge owner's earnings
+	BundleFundedByPlatform    BundleDiscountFunder = "platform"     // The platform makes it up to the lounge owner
+)
+
+// LoungeBundleDiscountRule takes a percentage off a lounge stay booked together with a bus ticket.
+// Lounge owners fund rules for their own lounges; platform rules may cover every lounge.
+type LoungeBundleDiscountRule struct {
+	ID                string               `json:"id" db:"id"`
+	LoungeID          *string              `json:"lounge_id,omitempty" db:"lounge_id"` // nil = every lounge (platform rules only)
+	Name              string               `json:"name" db:"name"`
+	FundedBy          BundleDiscountFunder `json:"funded_by" db:"funded_by"`
+	BookingType       *string              `json:"booking_type,omitempty" db:"booking_type"` // pre_trip or post_trip, nil = both
+	DiscountPercent   float64              `json:"discount_percent" db:"discount_percent"`
+	MaxDiscountAmount *float64             `json:"max_discount_amount,omitempty" db:"max_discount_amount"` // LKR per stay, nil = no cap
+	ValidFrom         *time.Time           `json:"valid_from,omitempty" db:"valid_from"`
+	ValidUntil        *time.Time           `json:"valid_until,omitempty" db:"valid_until"`
+	IsActive          bool                 `json:"is_active" db:"is_active"`
+	CreatedAt         time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the rule's discount, scope and validity window
+func (r *LoungeBundleDiscountRule) Validate() error {
+	switch r.FundedBy {
+	case BundleFundedByLoungeOwner:
+		if r.LoungeID == nil {
+			return errors.New("lounge owner discounts need a lounge")
+		}
+	case BundleFundedByPlatform:
+	default:
+		return errors.New("funded_by must be lounge_owner or platform")
+	}
+	if r.LoungeID != nil {
+		if _, err := uuid.Parse(*r.LoungeID); err != nil {
+			return errors.New("lounge_id must be a UUID")
+		}
+	}
+	if r.BookingType != nil && *r.BookingType != "pre_trip" && *r.BookingType != "post_trip" {
+		return errors.New("booking_type must be pre_trip or post_trip")
+	}
+	if r.DiscountPercent <= 0 || r.DiscountPercent > 100 {
+		return errors.New("discount_percent must be greater than 0 and at most 100")
+	}
+	if r.MaxDiscountAmount != nil && *r.MaxDiscountAmount <= 0 {
+		return errors.New("max_discount_amount must be positive")
+	}
+	if r.ValidFrom != nil && r.ValidUntil != nil && !r.ValidUntil.After(*r.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to a stay at the lounge of the booking type, booked at
+func (r *LoungeBundleDiscountRule) Matches(loungeID, bookingType string, at time.Time) bool {
+	if !r.IsActive {
+		return false
+	}
+	if r.LoungeID != nil && *r.LoungeID != loungeID {
+		return false
+	}
+	if r.BookingType != nil && *r.BookingType != bookingType {
+		return false
+	}
+	if r.ValidFrom != nil && at.Before(*r.ValidFrom) {
+		return false
+	}
+	if r.ValidUntil != nil && !at.Before(*r.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// Discount returns the amount taken off a stay price, capped at the rule's maximum
+func (r *LoungeBundleDiscountRule) Discount(price float64) float64 {
+	amount := price * r.DiscountPercent / 100
+	if r.MaxDiscountAmount != nil && amount > *r.MaxDiscountAmount {
+		amount = *r.MaxDiscountAmount
+	}
+	return RoundMoney(amount)
+}
+
+// LoungeBundleDiscount is the bundle discount applied to a lounge stay in a booking intent.
+// The funder is carried into the lounge booking so settlement charges the right party.
+type LoungeBundleDiscount struct {
+	RuleID   string               `json:"rule_id"`
+	RuleName string               `json:"rule_name"`
+	FundedBy BundleDiscountFunder `json:"funded_by"`
+	Percent  float64              `json:"percent"`
+	Amount   float64              `json:"amount"` // Taken off base_price; pre-orders are full price
+}
+
+// BestBundleDiscount picks the matching rule that takes the most off the stay price. On a tie
+// the lounge owner's rule is used, so the platform does not pay for a discount the owner offers
+// anyway. Returns nil if no rule matches.
+func BestBundleDiscount(rules []LoungeBundleDiscountRule, loungeID, bookingType string, price float64, at time.Time) *LoungeBundleDiscount {
+	var best *LoungeBundleDiscount
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(loungeID, bookingType, at) {
+			continue
+		}
+		amount := rule.Discount(price)
+		if amount <= 0 {
+			continue
+		}
+		if best == nil || amount > best.Amount ||
+			(amount == best.Amount && rule.FundedBy == BundleFundedByLoungeOwner && best.FundedBy != BundleFundedByLoungeOwner) {
+			best = &LoungeBundleDiscount{
+				RuleID:   rule.ID,
+				RuleName: rule.Name,
+				FundedBy: rule.FundedBy,
+				Percent:  rule.DiscountPercent,
+				Amount:   amount,
+			}
+		}
+	}
+	return best
+}
+
+// SaveLoungeBundleDiscountRequest creates or replaces a bundle discount rule. Owners' rules are
+// for the lounge in the path; lounge_id only scopes platform rules.
+type SaveLoungeBundleDiscountRequest struct {
+	Name              string     `json:"name" binding:"required,max=100"`
+	LoungeID          *string    `json:"lounge_id,omitempty"`
+	BookingType       *string    `json:"booking_type,omitempty"`
+	DiscountPercent   float64    `json:"discount_percent" binding:"required"`
+	MaxDiscountAmount *float64   `json:"max_discount_amount,omitempty"`
+	ValidFrom         *time.Time `json:"valid_from,omitempty"`
+	ValidUntil        *time.Time `json:"valid_until,omitempty"`
+	IsActive          *bool      `json:"is_active,omitempty"` // Defaults to true
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoungeBundleDiscountRule_Validate(t *testing.T) {
+	lounge := "6f1c7a52-3b8e-4d2a-9c41-0e5b7d9a1f23"
+	rule := LoungeBundleDiscountRule{Name: "20% off", FundedBy: BundleFundedByLoungeOwner, LoungeID: &lounge, DiscountPercent: 20}
+	assert.NoError(t, rule.Validate())
+
+	rule.LoungeID = nil
+	assert.Error(t, rule.Validate(), "owner discounts belong to a lounge")
+
+	rule.FundedBy = BundleFundedByPlatform
+	assert.NoError(t, rule.Validate(), "platform discounts may cover every lounge")
+
+	rule.DiscountPercent = 120
+	assert.Error(t, rule.Validate())
+	rule.DiscountPercent = 20
+
+	rule.BookingType = loungeStr("layover")
+	assert.Error(t, rule.Validate())
+	rule.BookingType = nil
+
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, LoungeTimezone)
+	rule.ValidFrom, rule.ValidUntil = &from, &from
+	assert.Error(t, rule.Validate())
+}
+
+func TestLoungeBundleDiscountRule_MatchesAndDiscount(t *testing.T) {
+	lounge := "lounge-1"
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, LoungeTimezone)
+	until := from.AddDate(0, 1, 0)
+	maxAmount := 300.0
+	rule := LoungeBundleDiscountRule{
+		LoungeID: &lounge, BookingType: loungeStr("pre_trip"), DiscountPercent: 20,
+		MaxDiscountAmount: &maxAmount, ValidFrom: &from, ValidUntil: &until, IsActive: true,
+	}
+
+	assert.True(t, rule.Matches("lounge-1", "pre_trip", from))
+	assert.False(t, rule.Matches("lounge-2", "pre_trip", from))
+	assert.False(t, rule.Matches("lounge-1", "post_trip", from))
+	assert.False(t, rule.Matches("lounge-1", "pre_trip", from.Add(-time.Minute)))
+	assert.False(t, rule.Matches("lounge-1", "pre_trip", until), "valid_until is exclusive")
+
+	assert.Equal(t, 200.0, rule.Discount(1000))
+	assert.Equal(t, 300.0, rule.Discount(2000), "capped")
+}
+
+func TestBestBundleDiscount(t *testing.T) {
+	lounge := "lounge-1"
+	at := time.Now()
+	owner := LoungeBundleDiscountRule{ID: "owner", Name: "Owner 20%", LoungeID: &lounge, FundedBy: BundleFundedByLoungeOwner, DiscountPercent: 20, IsActive: true}
+	platform := LoungeBundleDiscountRule{ID: "platform", Name: "Platform 20%", FundedBy: BundleFundedByPlatform, DiscountPercent: 20, IsActive: true}
+
+	best := BestBundleDiscount([]LoungeBundleDiscountRule{platform, owner}, lounge, "pre_trip", 1500, at)
+	require.NotNil(t, best)
+	assert.Equal(t, "owner", best.RuleID, "owner's rule wins a tie")
+	assert.Equal(t, BundleFundedByLoungeOwner, best.FundedBy)
+	assert.Equal(t, 300.0, best.Amount)
+
+	platform.DiscountPercent = 25
+	best = BestBundleDiscount([]LoungeBundleDiscountRule{owner, platform}, lounge, "pre_trip", 1500, at)
+	require.NotNil(t, best)
+	assert.Equal(t, BundleFundedByPlatform, best.FundedBy, "the larger discount applies")
+	assert.Equal(t, 375.0, best.Amount)
+
+	owner.IsActive, platform.IsActive = false, false
+	assert.Nil(t, BestBundleDiscount([]LoungeBundleDiscountRule{owner, platform}, lounge, "pre_trip", 1500, at))
+}
+
+func TestBookingIntent_BundleDiscountTotal(t *testing.T) {
+	intent := BookingIntent{
+		PreTripLoungeIntent:  &LoungeIntentPayload{BundleDiscount: &LoungeBundleDiscount{Amount: 300}},
+		PostTripLoungeIntent: &LoungeIntentPayload{},
+	}
+	assert.Equal(t, 300.0, intent.BundleDiscountTotal())
+	assert.Equal(t, 0.0, (&BookingIntent{}).BundleDiscountTotal())
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// ExpectedLoungeGuest is a lounge booking whose guests have not arrived yet, with the bus
+// they are linked to and what they pre-ordered
+type ExpectedLoungeGuest struct {
+	LoungeBookingID   string              `json:"lounge_booking_id" db:"lounge_booking_id"`
+	BookingReference  string              `json:"booking_reference" db:"booking_reference"`
+	BookingType       LoungeBookingType   `json:"booking_type" db:"booking_type"`
+	Status            LoungeBookingStatus `json:"status" db:"status"`
+	PrimaryGuestName  string              `json:"primary_guest_name" db:"primary_guest_name"`
+	PrimaryGuestPhone string              `json:"primary_guest_phone" db:"primary_guest_phone"`
+	NumberOfGuests    int                 `json:"number_of_guests" db:"number_of_guests"`
+	ScheduledArrival  time.Time           `json:"scheduled_arrival" db:"scheduled_arrival"`
+	SpecialRequests   *string             `json:"special_requests,omitempty" db:"special_requests"`
+
+	// Linked bus (pre_trip and post_trip bookings)
+	ScheduledTripID   *string    `json:"scheduled_trip_id,omitempty" db:"scheduled_trip_id"`
+	RouteName         *string    `json:"route_name,omitempty" db:"route_name"`
+	DepartureDatetime *time.Time `json:"departure_datetime,omitempty" db:"departure_datetime"`
+	BusStatus         *string    `json:"bus_status,omitempty" db:"bus_status"` // Live trip status, when the bus is running
+
+	// Live tracking, for the bus ETA at the lounge
+	BusLatitude     *float64 `json:"-" db:"bus_latitude"`
+	BusLongitude    *float64 `json:"-" db:"bus_longitude"`
+	BusSpeedKmh     *float64 `json:"-" db:"bus_speed_kmh"`
+	LoungeLatitude  *float64 `json:"-" db:"lounge_latitude"`
+	LoungeLongitude *float64 `json:"-" db:"lounge_longitude"`
+
+	// Computed when the list is built
+	BusETA     *time.Time              `json:"bus_eta,omitempty" db:"-"` // When the live bus reaches the lounge
+	ExpectedAt time.Time               `json:"expected_at" db:"-"`       // When the guests are expected to walk in
+	PreOrders  []LoungeBookingPreOrder `json:"pre_orders" db:"-"`
+}
+
+// UpdateExpectedAt sets when the guests are expected. Guests arriving off a post_trip bus are
+// expected when the bus reaches the lounge, once it is live-tracked; everyone else is expected
+// at their booked arrival time.
+func (g *ExpectedLoungeGuest) UpdateExpectedAt(busETA *time.Time) {
+	g.BusETA = busETA
+	g.ExpectedAt = g.ScheduledArrival
+	if busETA != nil && g.BookingType == LoungeBookingPostTrip {
+		g.ExpectedAt = *busETA
+	}
+}
+
+// LoungeBriefingTarget is a lounge that has guests expected on a day
+type LoungeBriefingTarget struct {
+	LoungeID   string `db:"lounge_id"`
+	LoungeName string `db:"lounge_name"`
+}
+
+// LoungeGuestBriefing is a lounge's expected guests for a day, soonest first, as shown on the
+// staff's expected arrivals screen and sent as the morning briefing
+type LoungeGuestBriefing struct {
+	LoungeID       string                `json:"lounge_id"`
+	LoungeName     string                `json:"lounge_name"`
+	Date           string                `json:"date"` // YYYY-MM-DD, Asia/Colombo
+	Bookings       int                   `json:"bookings"`
+	Guests         int                   `json:"guests"`
+	PreOrderItems  int                   `json:"pre_order_items"`
+	ExpectedGuests []ExpectedLoungeGuest `json:"expected_guests"`
+	GeneratedAt    time.Time             `json:"generated_at"`
+}
+
+// NewLoungeGuestBriefing totals and orders a lounge's expected guests for the day of now
+func NewLoungeGuestBriefing(loungeID, loungeName string, guests []ExpectedLoungeGuest, now time.Time) *LoungeGuestBriefing {
+	if guests == nil {
+		guests = []ExpectedLoungeGuest{}
+	}
+	sort.SliceStable(guests, func(i, j int) bool { return guests[i].ExpectedAt.Before(guests[j].ExpectedAt) })
+
+	briefing := &LoungeGuestBriefing{
+		LoungeID:       loungeID,
+		LoungeName:     loungeName,
+		Date:           now.In(ReportTimezone).Format("2006-01-02"),
+		Bookings:       len(guests),
+		ExpectedGuests: guests,
+		GeneratedAt:    now,
+	}
+	for i := range guests {
+		briefing.Guests += guests[i].NumberOfGuests
+		for _, item := range guests[i].PreOrders {
+			briefing.PreOrderItems += item.Quantity
+		}
+	}
+	return briefing
+}
+
+// LocalDayBounds returns the start and end of the Asia/Colombo day containing t
+func LocalDayBounds(t time.Time) (time.Time, time.Time) {
+	local := t.In(ReportTimezone)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ReportTimezone)
+	return start, start.AddDate(0, 0, 1)
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectedLoungeGuest_UpdateExpectedAt(t *testing.T) {
+	booked := time.Date(2026, 5, 1, 9, 0, 0, 0, ReportTimezone)
+	eta := booked.Add(25 * time.Minute)
+
+	postTrip := &ExpectedLoungeGuest{BookingType: LoungeBookingPostTrip, ScheduledArrival: booked}
+	postTrip.UpdateExpectedAt(&eta)
+	assert.Equal(t, eta, postTrip.ExpectedAt, "guests off a live bus arrive with it")
+
+	postTrip.UpdateExpectedAt(nil)
+	assert.Equal(t, booked, postTrip.ExpectedAt, "without tracking the booked time is used")
+	assert.Nil(t, postTrip.BusETA)
+
+	preTrip := &ExpectedLoungeGuest{BookingType: LoungeBookingPreTrip, ScheduledArrival: booked}
+	preTrip.UpdateExpectedAt(&eta)
+	assert.Equal(t, booked, preTrip.ExpectedAt, "pre-trip guests come before their bus")
+	assert.Equal(t, &eta, preTrip.BusETA)
+}
+
+func TestNewLoungeGuestBriefing(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 30, 0, 0, time.UTC) // 06:00 in Colombo
+	guests := []ExpectedLoungeGuest{
+		{BookingReference: "LB-2", NumberOfGuests: 3, ExpectedAt: now.Add(2 * time.Hour),
+			PreOrders: []LoungeBookingPreOrder{{Quantity: 2}, {Quantity: 1}}},
+		{BookingReference: "LB-1", NumberOfGuests: 1, ExpectedAt: now.Add(time.Hour)},
+	}
+
+	briefing := NewLoungeGuestBriefing("lounge-1", "Kandy Lounge", guests, now)
+
+	assert.Equal(t, "2026-05-01", briefing.Date)
+	assert.Equal(t, 2, briefing.Bookings)
+	assert.Equal(t, 4, briefing.Guests)
+	assert.Equal(t, 3, briefing.PreOrderItems)
+	assert.Equal(t, "LB-1", briefing.ExpectedGuests[0].BookingReference, "soonest first")
+
+	empty := NewLoungeGuestBriefing("lounge-1", "Kandy Lounge", nil, now)
+	assert.NotNil(t, empty.ExpectedGuests)
+	assert.Zero(t, empty.Guests)
+}
+
+func TestLocalDayBounds(t *testing.T) {
+	from, to := LocalDayBounds(time.Date(2026, 4, 30, 20, 0, 0, 0, time.UTC)) // 01:30 on May 1 in Colombo
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, ReportTimezone), from)
+	assert.Equal(t, 24*time.Hour, to.Sub(from))
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoungeNoShowFeeType is how a lounge charges guests who never arrive
+type LoungeNoShowFeeType string
+
+const (
+	LoungeNoShowFeeNone       LoungeNoShowFeeType = "none"       // No fee
+	LoungeNoShowFeeFull       LoungeNoShowFeeType = "full"       // The whole booking amount is forfeited
+	LoungeNoShowFeePercentage LoungeNoShowFeeType = "percentage" // fee_value percent of the booking amount
+	LoungeNoShowFeeFixed      LoungeNoShowFeeType = "fixed"      // fee_value LKR, capped at the booking amount
+)
+
+// LoungeNoShowPolicy is a lounge's rule for bookings whose guests never check in
+type LoungeNoShowPolicy struct {
+	LoungeID     string              `json:"lounge_id" db:"lounge_id"`
+	GraceMinutes *int                `json:"grace_minutes,omitempty" db:"grace_minutes"` // Minutes after scheduled arrival before marking; nil uses the platform default
+	FeeType      LoungeNoShowFeeType `json:"fee_type" db:"fee_type"`
+	FeeValue     float64             `json:"fee_value" db:"fee_value"`
+	UpdatedAt    time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultLoungeNoShowPolicy is applied to lounges that never configured one: the full amount is forfeited
+func DefaultLoungeNoShowPolicy(loungeID string) *LoungeNoShowPolicy {
+	return &LoungeNoShowPolicy{LoungeID: loungeID, FeeType: LoungeNoShowFeeFull}
+}
+
+// NoShowFee returns the fee for a booking of the given amount, never more than the amount
+func NoShowFee(feeType LoungeNoShowFeeType, feeValue, bookingAmount float64) float64 {
+	var fee float64
+	switch feeType {
+	case LoungeNoShowFeeFull:
+		fee = bookingAmount
+	case LoungeNoShowFeePercentage:
+		fee = bookingAmount * feeValue / 100
+	case LoungeNoShowFeeFixed:
+		fee = feeValue
+	}
+	fee = math.Min(math.Max(fee, 0), bookingAmount)
+	return RoundMoney(fee)
+}
+
+// UpdateLoungeNoShowPolicyRequest configures a lounge's no-show policy
+type UpdateLoungeNoShowPolicyRequest struct {
+	GraceMinutes *int                `json:"grace_minutes,omitempty" binding:"omitempty,min=0,max=720"`
+	FeeType      LoungeNoShowFeeType `json:"fee_type" binding:"required,oneof=none full percentage fixed"`
+	FeeValue     float64             `json:"fee_value" binding:"min=0"`
+}
+
+// Validate checks the fee value makes sense for the fee type
+func (r *UpdateLoungeNoShowPolicyRequest) Validate() error {
+	if r.FeeType == LoungeNoShowFeePercentage && r.FeeValue > 100 {
+		return &ValidationError{Message: "fee_value cannot exceed 100 for percentage fees"}
+	}
+	if r.FeeType == LoungeNoShowFeeFixed && r.FeeValue <= 0 {
+		return &ValidationError{Message: "fee_value must be greater than 0 for fixed fees"}
+	}
+	return nil
+}
+
+// DueLoungeNoShow is a confirmed lounge booking past its grace period, with the lounge's policy
+type DueLoungeNoShow struct {
+	BookingID         uuid.UUID           `db:"lounge_booking_id"`
+	BookingReference  string              `db:"booking_reference"`
+	UserID            uuid.UUID           `db:"user_id"`
+	LoungeID          uuid.UUID           `db:"lounge_id"`
+	LoungeName        string              `db:"lounge_name"`
+	ScheduledArrival  time.Time           `db:"scheduled_arrival"`
+	TotalAmount       float64             `db:"total_amount"`
+	PaymentStatus     LoungePaymentStatus `db:"payment_status"`
+	PrimaryGuestPhone string              `db:"primary_guest_phone"`
+	FeeType           LoungeNoShowFeeType `db:"fee_type"`
+	FeeValue          float64             `db:"fee_value"`
+}
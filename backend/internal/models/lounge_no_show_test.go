@@ -0,0 +1,23 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoShowFee(t *testing.T) {
+	assert.Equal(t, 0.0, NoShowFee(LoungeNoShowFeeNone, 0, 2500))
+	assert.Equal(t, 2500.0, NoShowFee(LoungeNoShowFeeFull, 0, 2500))
+	assert.Equal(t, 833.33, NoShowFee(LoungeNoShowFeePercentage, 33.3333, 2500), "rounded to cents")
+	assert.Equal(t, 500.0, NoShowFee(LoungeNoShowFeeFixed, 500, 2500))
+	assert.Equal(t, 1200.0, NoShowFee(LoungeNoShowFeeFixed, 1500, 1200), "capped at the booking amount")
+	assert.Equal(t, 0.0, NoShowFee(LoungeNoShowFeeFull, 0, 0))
+}
+
+func TestUpdateLoungeNoShowPolicyRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&UpdateLoungeNoShowPolicyRequest{FeeType: LoungeNoShowFeePercentage, FeeValue: 100}).Validate())
+	assert.Error(t, (&UpdateLoungeNoShowPolicyRequest{FeeType: LoungeNoShowFeePercentage, FeeValue: 120}).Validate())
+	assert.Error(t, (&UpdateLoungeNoShowPolicyRequest{FeeType: LoungeNoShowFeeFixed}).Validate())
+	assert.NoError(t, (&UpdateLoungeNoShowPolicyRequest{FeeType: LoungeNoShowFeeFull}).Validate())
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// LoungeAnalyticsTopProducts is how many products the lounge owner analytics ranks
+const LoungeAnalyticsTopProducts = 10
+
+// LoungeAnalyticsDay is one local day (Asia/Colombo) of a lounge owner's bookings, by scheduled
+// arrival. Order revenue counts pre-orders of bookings that were not cancelled and whose payment
+// went through or is collected on site, and in-lounge orders that were not cancelled, failed or
+// refunded, both on the day of the booking they belong to.
+type LoungeAnalyticsDay struct {
+	Date                 string `json:"date" db:"day"` // YYYY-MM-DD
+	Bookings             int    `json:"bookings" db:"bookings"`
+	Guests               int    `json:"guests" db:"guests"`
+	CheckedIn            int    `json:"checked_in" db:"checked_in"` // Bookings whose guests arrived
+	Cancelled            int    `json:"cancelled" db:"cancelled"`
+	NoShows              int    `json:"no_shows" db:"no_shows"`
+	PreOrderRevenue      Money  `json:"pre_order_revenue" db:"pre_order_revenue"`
+	InLoungeOrderRevenue Money  `json:"in_lounge_order_revenue" db:"in_lounge_order_revenue"`
+}
+
+// LoungeProductPerformance is how much of one product was ordered, ahead and in the lounge
+type LoungeProductPerformance struct {
+	ProductID        string `json:"product_id" db:"product_id"`
+	ProductName      string `json:"product_name" db:"product_name"`
+	PreOrderQuantity int    `json:"pre_order_quantity" db:"pre_order_quantity"`
+	InLoungeQuantity int    `json:"in_lounge_quantity" db:"in_lounge_quantity"`
+	Revenue          Money  `json:"revenue" db:"revenue"`
+}
+
+// LoungeAnalyticsTotals are the totals of the period, with rates as percentages rounded to
+// one decimal, or nil when there is nothing to divide by
+type LoungeAnalyticsTotals struct {
+	Bookings              int      `json:"bookings"`
+	Guests                int      `json:"guests"`
+	CheckedIn             int      `json:"checked_in"`
+	Cancelled             int      `json:"cancelled"`
+	NoShows               int      `json:"no_shows"`
+	CheckInConversionRate *float64 `json:"check_in_conversion_rate"` // Checked in out of bookings not cancelled
+	CancellationRate      *float64 `json:"cancellation_rate"`
+	PreOrderRevenue       Money    `json:"pre_order_revenue"`
+	InLoungeOrderRevenue  Money    `json:"in_lounge_order_revenue"`
+	OrderRevenue          Money    `json:"order_revenue"`
+	PreOrderRevenueShare  *float64 `json:"pre_order_revenue_share"` // Pre-orders out of all order revenue
+}
+
+// LoungeOwnerAnalytics is a lounge owner's bookings, check-ins, order revenue and top products
+type LoungeOwnerAnalytics struct {
+	From        string                     `json:"from"` // YYYY-MM-DD, inclusive
+	To          string                     `json:"to"`   // YYYY-MM-DD, inclusive
+	Timezone    string                     `json:"timezone"`
+	LoungeID    *string                    `json:"lounge_id,omitempty"` // Set when one lounge was asked for
+	Totals      LoungeAnalyticsTotals      `json:"totals"`
+	Daily       []LoungeAnalyticsDay       `json:"daily"`        // Days with bookings, in order
+	TopProducts []LoungeProductPerformance `json:"top_products"` // Highest revenue first
+}
+
+// LoungeOwnerAnalyticsRequest holds the analytics query parameters
+type LoungeOwnerAnalyticsRequest struct {
+	From     string `form:"from"` // YYYY-MM-DD, defaults to 30 days before to
+	To       string `form:"to"`   // YYYY-MM-DD, defaults to today
+	LoungeID string `form:"lounge_id"`
+}
+
+// Range returns the requested local dates as [start, end) instants, with the same defaults
+// and limits as bus owner analytics
+func (r *LoungeOwnerAnalyticsRequest) Range(now time.Time) (time.Time, time.Time, error) {
+	return (&OwnerAnalyticsRequest{From: r.From, To: r.To}).Range(now)
+}
+
+// BuildLoungeOwnerAnalytics totals the days and works out the rates
+func BuildLoungeOwnerAnalytics(days []LoungeAnalyticsDay, products []LoungeProductPerformance, from, to time.Time) *LoungeOwnerAnalytics {
+	report := &LoungeOwnerAnalytics{
+		From:        from.Format("2006-01-02"),
+		To:          to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Timezone:    ReportTimezone.String(),
+		Daily:       days,
+		TopProducts: products,
+	}
+	if report.Daily == nil {
+		report.Daily = []LoungeAnalyticsDay{}
+	}
+	if report.TopProducts == nil {
+		report.TopProducts = []LoungeProductPerformance{}
+	}
+
+	totals := &report.Totals
+	for _, day := range days {
+		totals.Bookings += day.Bookings
+		totals.Guests += day.Guests
+		totals.CheckedIn += day.CheckedIn
+		totals.Cancelled += day.Cancelled
+		totals.NoShows += day.NoShows
+		totals.PreOrderRevenue = totals.PreOrderRevenue.Add(day.PreOrderRevenue)
+		totals.InLoungeOrderRevenue = totals.InLoungeOrderRevenue.Add(day.InLoungeOrderRevenue)
+	}
+	totals.OrderRevenue = totals.PreOrderRevenue.Add(totals.InLoungeOrderRevenue)
+	totals.CheckInConversionRate = analyticsRate(totals.CheckedIn, totals.Bookings-totals.Cancelled)
+	totals.CancellationRate = analyticsRate(totals.Cancelled, totals.Bookings)
+	if !totals.OrderRevenue.IsZero() {
+		share := math.Round(totals.PreOrderRevenue.Float64()/totals.OrderRevenue.Float64()*1000) / 10
+		totals.PreOrderRevenueShare = &share
+	}
+	return report
+}
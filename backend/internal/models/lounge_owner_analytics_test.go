@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLoungeOwnerAnalytics(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, ReportTimezone)
+	to := from.AddDate(0, 0, 7)
+	days := []LoungeAnalyticsDay{
+		{Date: "2026-03-01", Bookings: 10, Guests: 18, CheckedIn: 6, Cancelled: 2, NoShows: 1,
+			PreOrderRevenue: NewMoney(4500), InLoungeOrderRevenue: NewMoney(1500)},
+		{Date: "2026-03-03", Bookings: 5, Guests: 5, CheckedIn: 4, Cancelled: 1,
+			InLoungeOrderRevenue: NewMoney(2000)},
+	}
+	products := []LoungeProductPerformance{
+		{ProductID: "p-1", ProductName: "Rice and curry", PreOrderQuantity: 6, InLoungeQuantity: 2, Revenue: NewMoney(4000)},
+	}
+
+	report := BuildLoungeOwnerAnalytics(days, products, from, to)
+	assert.Equal(t, "2026-03-01", report.From)
+	assert.Equal(t, "2026-03-07", report.To, "to is inclusive in the report")
+	assert.Equal(t, "Asia/Colombo", report.Timezone)
+
+	totals := report.Totals
+	assert.Equal(t, 15, totals.Bookings)
+	assert.Equal(t, 23, totals.Guests)
+	assert.Equal(t, 10, totals.CheckedIn)
+	assert.Equal(t, 3, totals.Cancelled)
+	assert.Equal(t, 1, totals.NoShows)
+	require.NotNil(t, totals.CheckInConversionRate)
+	assert.Equal(t, 83.3, *totals.CheckInConversionRate, "cancelled bookings are left out")
+	require.NotNil(t, totals.CancellationRate)
+	assert.Equal(t, 20.0, *totals.CancellationRate)
+	assert.True(t, totals.OrderRevenue.Equal(NewMoney(8000)))
+	require.NotNil(t, totals.PreOrderRevenueShare)
+	assert.Equal(t, 56.3, *totals.PreOrderRevenueShare)
+	assert.Len(t, report.TopProducts, 1)
+}
+
+func TestBuildLoungeOwnerAnalytics_Empty(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, ReportTimezone)
+	report := BuildLoungeOwnerAnalytics(nil, nil, from, from.AddDate(0, 0, 1))
+	assert.NotNil(t, report.Daily)
+	assert.NotNil(t, report.TopProducts)
+	assert.Nil(t, report.Totals.CheckInConversionRate)
+	assert.Nil(t, report.Totals.CancellationRate)
+	assert.Nil(t, report.Totals.PreOrderRevenueShare)
+	assert.True(t, report.Totals.OrderRevenue.IsZero())
+}
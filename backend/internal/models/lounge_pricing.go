@@ -0,0 +1,312 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// LoungeTimezone is the local time lounge price rules are written in
+var LoungeTimezone = time.FixedZone("Asia/Colombo", 5*3600+30*60)
+
+// LoungePriceRuleType selects when a price rule applies
+type LoungePriceRuleType string
+
+const (
+	LoungePriceRuleTimeSlot  LoungePriceRuleType = "time_slot"  // Daily hours, optionally limited to weekdays (peak hours)
+	LoungePriceRuleDateRange LoungePriceRuleType = "date_range" // Calendar dates, optionally limited to hours (festivals)
+)
+
+// LoungePriceAdjustment is how a rule changes the flat price
+type LoungePriceAdjustment string
+
+const (
+	LoungePriceFixed   LoungePriceAdjustment = "fixed"   // Replaces the price
+	LoungePricePercent LoungePriceAdjustment = "percent" // Adds a percentage (negative for discounts)
+)
+
+// LoungePricingTypes are the pricing types a lounge sells
+var LoungePricingTypes = []string{"1_hour", "2_hours", "3_hours", "until_bus"}
+
+// LoungePriceRule overrides a lounge's flat price for a time slot or date range
+type LoungePriceRule struct {
+	ID             string                `json:"id" db:"id"`
+	LoungeID       string                `json:"lounge_id" db:"lounge_id"`
+	Name           string                `json:"name" db:"name"`
+	RuleType       LoungePriceRuleType   `json:"rule_type" db:"rule_type"`
+	PricingType    *string               `json:"pricing_type,omitempty" db:"pricing_type"` // nil = all pricing types
+	DaysOfWeek     *string               `json:"days_of_week,omitempty" db:"days_of_week"` // Comma-separated 0 (Sunday) - 6, nil = every day
+	StartTime      *string               `json:"start_time,omitempty" db:"start_time"`     // "HH:MM", inclusive
+	EndTime        *string               `json:"end_time,omitempty" db:"end_time"`         // "HH:MM", exclusive; before start_time wraps past midnight
+	StartDate      *string               `json:"start_date,omitempty" db:"start_date"`     // "YYYY-MM-DD", inclusive
+	EndDate        *string               `json:"end_date,omitempty" db:"end_date"`         // "YYYY-MM-DD", inclusive
+	AdjustmentType LoungePriceAdjustment `json:"adjustment_type" db:"adjustment_type"`
+	Value          float64               `json:"value" db:"value"` // Price in LKR or percentage, per adjustment_type
+	Priority       int                   `json:"priority" db:"priority"`
+	IsActive       bool                  `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks that the rule is complete for its type
+func (r *LoungePriceRule) Validate() error {
+	switch r.RuleType {
+	case LoungePriceRuleTimeSlot:
+		if r.StartTime == nil || r.EndTime == nil {
+			return errors.New("time_slot rules need start_time and end_time")
+		}
+	case LoungePriceRuleDateRange:
+		if r.StartDate == nil || r.EndDate == nil {
+			return errors.New("date_range rules need start_date and end_date")
+		}
+		start, err := time.Parse("2006-01-02", *r.StartDate)
+		if err != nil {
+			return errors.New("start_date must be YYYY-MM-DD")
+		}
+		end, err := time.Parse("2006-01-02", *r.EndDate)
+		if err != nil {
+			return errors.New("end_date must be YYYY-MM-DD")
+		}
+		if end.Before(start) {
+			return errors.New("end_date must not be before start_date")
+		}
+	default:
+		return errors.New("rule_type must be time_slot or date_range")
+	}
+
+	if (r.StartTime == nil) != (r.EndTime == nil) {
+		return errors.New("start_time and end_time must be set together")
+	}
+	if r.StartTime != nil {
+		if _, err := parseClock(*r.StartTime); err != nil {
+			return errors.New("start_time must be HH:MM")
+		}
+		if _, err := parseClock(*r.EndTime); err != nil {
+			return errors.New("end_time must be HH:MM")
+		}
+		if *r.StartTime == *r.EndTime {
+			return errors.New("start_time and end_time must differ")
+		}
+	}
+	if r.DaysOfWeek != nil {
+		if _, err := parseDaysOfWeek(*r.DaysOfWeek); err != nil {
+			return err
+		}
+	}
+	if r.PricingType != nil && !IsLoungePricingType(*r.PricingType) {
+		return fmt.Errorf("invalid pricing type: %s", *r.PricingType)
+	}
+
+	switch r.AdjustmentType {
+	case LoungePriceFixed:
+		if r.Value < 0 {
+			return errors.New("fixed price must not be negative")
+		}
+	case LoungePricePercent:
+		if r.Value <= -100 {
+			return errors.New("percent adjustment must be greater than -100")
+		}
+	default:
+		return errors.New("adjustment_type must be fixed or percent")
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to a stay of the pricing type starting at checkIn
+func (r *LoungePriceRule) Matches(pricingType string, checkIn time.Time) bool {
+	if !r.IsActive {
+		return false
+	}
+	if r.PricingType != nil && *r.PricingType != pricingType {
+		return false
+	}
+
+	local := checkIn.In(LoungeTimezone)
+	if r.StartDate != nil && r.EndDate != nil {
+		day := local.Format("2006-01-02")
+		if day < *r.StartDate || day > *r.EndDate {
+			return false
+		}
+	}
+	if r.DaysOfWeek != nil {
+		days, err := parseDaysOfWeek(*r.DaysOfWeek)
+		if err != nil || !days[local.Weekday()] {
+			return false
+		}
+	}
+	if r.StartTime != nil && r.EndTime != nil {
+		start, err1 := parseClock(*r.StartTime)
+		end, err2 := parseClock(*r.EndTime)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		minute := local.Hour()*60 + local.Minute()
+		if start < end {
+			return minute >= start && minute < end
+		}
+		return minute >= start || minute < end
+	}
+	return true
+}
+
+// Apply returns the price after the rule's adjustment
+func (r *LoungePriceRule) Apply(price float64) float64 {
+	if r.AdjustmentType == LoungePriceFixed {
+		return r.Value
+	}
+	return price * (1 + r.Value/100)
+}
+
+// LoungeSurgeSettings raises prices as a lounge fills up
+type LoungeSurgeSettings struct {
+	LoungeID                  string    `json:"lounge_id" db:"lounge_id"`
+	Enabled                   bool      `json:"enabled" db:"enabled"`
+	OccupancyThresholdPercent int       `json:"occupancy_threshold_percent" db:"occupancy_threshold_percent"` // Surge starts above this occupancy
+	MaxSurgePercent           float64   `json:"max_surge_percent" db:"max_surge_percent"`                     // Reached when the lounge is full
+	UpdatedAt                 time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SurgePercent returns the surcharge for an occupancy: zero up to the threshold, then rising
+// linearly to the cap when the lounge is full
+func (s *LoungeSurgeSettings) SurgePercent(occupancyPercent float64) float64 {
+	if s == nil || !s.Enabled || s.MaxSurgePercent <= 0 {
+		return 0
+	}
+	threshold := float64(s.OccupancyThresholdPercent)
+	if occupancyPercent <= threshold {
+		return 0
+	}
+	if threshold >= 100 || occupancyPercent >= 100 {
+		return s.MaxSurgePercent
+	}
+	return s.MaxSurgePercent * (occupancyPercent - threshold) / (100 - threshold)
+}
+
+// LoungePriceQuote is the per-guest price for a stay and how it was reached
+type LoungePriceQuote struct {
+	LoungeID         string    `json:"lounge_id"`
+	PricingType      string    `json:"pricing_type"`
+	CheckInTime      time.Time `json:"check_in_time"`
+	BasePrice        float64   `json:"base_price"` // Flat price for the pricing type
+	RuleID           *string   `json:"rule_id,omitempty"`
+	RuleName         *string   `json:"rule_name,omitempty"`
+	OccupancyPercent float64   `json:"occupancy_percent"`
+	SurgePercent     float64   `json:"surge_percent"`
+	PricePerGuest    float64   `json:"price_per_guest"`
+}
+
+// QuoteLoungePrice prices a stay. Date range rules (festivals) win over time slot rules, then the
+// highest priority; only one rule applies. Surge is added on top of the rule price.
+func QuoteLoungePrice(basePrice float64, pricingType string, checkIn time.Time, rules []LoungePriceRule, surge *LoungeSurgeSettings, occupancyPercent float64) LoungePriceQuote {
+	quote := LoungePriceQuote{
+		PricingType:      pricingType,
+		CheckInTime:      checkIn,
+		BasePrice:        basePrice,
+		OccupancyPercent: math.Round(occupancyPercent*10) / 10,
+	}
+
+	price := basePrice
+	var best *LoungePriceRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(pricingType, checkIn) {
+			continue
+		}
+		if best == nil || ruleOutranks(rule, best) {
+			best = rule
+		}
+	}
+	if best != nil {
+		price = best.Apply(price)
+		quote.RuleID = &best.ID
+		quote.RuleName = &best.Name
+	}
+
+	quote.SurgePercent = math.Round(surge.SurgePercent(occupancyPercent)*100) / 100
+	price *= 1 + quote.SurgePercent/100
+	quote.PricePerGuest = RoundMoney(price)
+	return quote
+}
+
+// ruleOutranks reports whether a should be applied instead of b
+func ruleOutranks(a, b *LoungePriceRule) bool {
+	if a.RuleType != b.RuleType {
+		return a.RuleType == LoungePriceRuleDateRange
+	}
+	return a.Priority > b.Priority
+}
+
+// CreateLoungePriceRuleRequest adds a price rule to a lounge
+type CreateLoungePriceRuleRequest struct {
+	Name           string                `json:"name" binding:"required,max=100"`
+	RuleType       LoungePriceRuleType   `json:"rule_type" binding:"required"`
+	PricingType    *string               `json:"pricing_type,omitempty"`
+	DaysOfWeek     []int                 `json:"days_of_week,omitempty"`
+	StartTime      *string               `json:"start_time,omitempty"`
+	EndTime        *string               `json:"end_time,omitempty"`
+	StartDate      *string               `json:"start_date,omitempty"`
+	EndDate        *string               `json:"end_date,omitempty"`
+	AdjustmentType LoungePriceAdjustment `json:"adjustment_type" binding:"required"`
+	Value          float64               `json:"value"`
+	Priority       int                   `json:"priority"`
+	IsActive       *bool                 `json:"is_active,omitempty"` // Defaults to true
+}
+
+// UpdateLoungeSurgeRequest configures occupancy-based surge pricing for a lounge
+type UpdateLoungeSurgeRequest struct {
+	Enabled                   bool    `json:"enabled"`
+	OccupancyThresholdPercent int     `json:"occupancy_threshold_percent" binding:"min=0,max=100"`
+	MaxSurgePercent           float64 `json:"max_surge_percent" binding:"min=0,max=200"`
+}
+
+// FormatDaysOfWeek stores weekdays in the rule's comma-separated form; empty means every day
+func FormatDaysOfWeek(days []int) *string {
+	if len(days) == 0 {
+		return nil
+	}
+	parts := make([]string, len(days))
+	for i, day := range days {
+		parts[i] = fmt.Sprint(day)
+	}
+	value := strings.Join(parts, ",")
+	return &value
+}
+
+func parseDaysOfWeek(value string) (map[time.Weekday]bool, error) {
+	days := map[time.Weekday]bool{}
+	var day int
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		if _, err := fmt.Sscanf(part, "%d", &day); err != nil || day < 0 || day > 6 {
+			return nil, errors.New("days_of_week must be numbers from 0 (Sunday) to 6")
+		}
+		days[time.Weekday(day)] = true
+	}
+	if len(days) == 0 {
+		return nil, errors.New("days_of_week must not be empty")
+	}
+	return days, nil
+}
+
+// parseClock converts "HH:MM" to minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// IsLoungePricingType reports whether pricingType is one a lounge sells
+func IsLoungePricingType(pricingType string) bool {
+	for _, t := range LoungePricingTypes {
+		if t == pricingType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loungeStr(value string) *string {
+	return &value
+}
+
+func TestLoungePriceRule_MatchesTimeSlotAndWeekdays(t *testing.T) {
+	// Weekday evening peak, 17:00-20:00 Monday to Friday
+	rule := LoungePriceRule{
+		RuleType:   LoungePriceRuleTimeSlot,
+		DaysOfWeek: FormatDaysOfWeek([]int{1, 2, 3, 4, 5}),
+		StartTime:  loungeStr("17:00"),
+		EndTime:    loungeStr("20:00"),
+		IsActive:   true,
+	}
+
+	// Wednesday 2026-03-04
+	assert.True(t, rule.Matches("1_hour", colombo(2026, time.March, 4, 17)))
+	assert.True(t, rule.Matches("until_bus", colombo(2026, time.March, 4, 19)))
+	assert.False(t, rule.Matches("1_hour", colombo(2026, time.March, 4, 20)), "end time is exclusive")
+	assert.False(t, rule.Matches("1_hour", colombo(2026, time.March, 4, 16)))
+	assert.False(t, rule.Matches("1_hour", colombo(2026, time.March, 7, 18)), "Saturday")
+
+	// Check-in times are compared in local time
+	assert.True(t, rule.Matches("1_hour", time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)), "12:00 UTC is 17:30 in Colombo")
+
+	rule.IsActive = false
+	assert.False(t, rule.Matches("1_hour", colombo(2026, time.March, 4, 18)))
+}
+
+func TestLoungePriceRule_MatchesOvernightSlotAndPricingType(t *testing.T) {
+	rule := LoungePriceRule{
+		RuleType:    LoungePriceRuleTimeSlot,
+		PricingType: loungeStr("3_hours"),
+		StartTime:   loungeStr("22:00"),
+		EndTime:     loungeStr("05:00"),
+		IsActive:    true,
+	}
+
+	assert.True(t, rule.Matches("3_hours", colombo(2026, time.March, 4, 23)))
+	assert.True(t, rule.Matches("3_hours", colombo(2026, time.March, 4, 2)))
+	assert.False(t, rule.Matches("3_hours", colombo(2026, time.March, 4, 12)))
+	assert.False(t, rule.Matches("1_hour", colombo(2026, time.March, 4, 23)), "limited to 3_hours")
+}
+
+func TestLoungePriceRule_MatchesDateRange(t *testing.T) {
+	rule := LoungePriceRule{
+		RuleType:  LoungePriceRuleDateRange,
+		StartDate: loungeStr("2026-04-12"),
+		EndDate:   loungeStr("2026-04-15"),
+		IsActive:  true,
+	}
+
+	assert.True(t, rule.Matches("1_hour", colombo(2026, time.April, 12, 0)))
+	assert.True(t, rule.Matches("1_hour", colombo(2026, time.April, 15, 23)), "end date is inclusive")
+	assert.False(t, rule.Matches("1_hour", colombo(2026, time.April, 16, 0)))
+	assert.False(t, rule.Matches("1_hour", colombo(2026, time.April, 11, 23)))
+}
+
+func TestLoungePriceRule_Validate(t *testing.T) {
+	valid := LoungePriceRule{
+		RuleType:       LoungePriceRuleTimeSlot,
+		StartTime:      loungeStr("17:00"),
+		EndTime:        loungeStr("20:00"),
+		AdjustmentType: LoungePricePercent,
+		Value:          25,
+	}
+	require.NoError(t, valid.Validate())
+
+	cases := map[string]func(r *LoungePriceRule){
+		"missing times":      func(r *LoungePriceRule) { r.EndTime = nil },
+		"bad time":           func(r *LoungePriceRule) { r.StartTime = loungeStr("5pm") },
+		"equal times":        func(r *LoungePriceRule) { r.EndTime = loungeStr("17:00") },
+		"bad weekday":        func(r *LoungePriceRule) { r.DaysOfWeek = loungeStr("1,7") },
+		"bad pricing type":   func(r *LoungePriceRule) { r.PricingType = loungeStr("4_hours") },
+		"bad adjustment":     func(r *LoungePriceRule) { r.AdjustmentType = "multiply" },
+		"free via percent":   func(r *LoungePriceRule) { r.Value = -100 },
+		"negative fixed":     func(r *LoungePriceRule) { r.AdjustmentType = LoungePriceFixed; r.Value = -1 },
+		"unknown rule type":  func(r *LoungePriceRule) { r.RuleType = "holiday" },
+		"date range no date": func(r *LoungePriceRule) { r.RuleType = LoungePriceRuleDateRange },
+		"reversed dates": func(r *LoungePriceRule) {
+			r.RuleType = LoungePriceRuleDateRange
+			r.StartDate = loungeStr("2026-04-15")
+			r.EndDate = loungeStr("2026-04-12")
+		},
+	}
+	for name, mutate := range cases {
+		rule := valid
+		mutate(&rule)
+		assert.Error(t, rule.Validate(), name)
+	}
+}
+
+func TestLoungeSurgeSettings_SurgePercent(t *testing.T) {
+	surge := &LoungeSurgeSettings{Enabled: true, OccupancyThresholdPercent: 60, MaxSurgePercent: 40}
+
+	assert.Equal(t, 0.0, surge.SurgePercent(60))
+	assert.InDelta(t, 20.0, surge.SurgePercent(80), 0.001)
+	assert.Equal(t, 40.0, surge.SurgePercent(100))
+	assert.Equal(t, 40.0, surge.SurgePercent(120), "capped when overbooked")
+
+	surge.Enabled = false
+	assert.Equal(t, 0.0, surge.SurgePercent(100))
+
+	var none *LoungeSurgeSettings
+	assert.Equal(t, 0.0, none.SurgePercent(100))
+}
+
+func TestQuoteLoungePrice_FestivalBeatsPeakAndSurgeStacks(t *testing.T) {
+	rules := []LoungePriceRule{
+		{ID: "peak", Name: "Evening peak", RuleType: LoungePriceRuleTimeSlot, StartTime: loungeStr("17:00"), EndTime: loungeStr("20:00"),
+			AdjustmentType: LoungePricePercent, Value: 50, Priority: 10, IsActive: true},
+		{ID: "avurudu", Name: "Avurudu", RuleType: LoungePriceRuleDateRange, StartDate: loungeStr("2026-04-12"), EndDate: loungeStr("2026-04-15"),
+			AdjustmentType: LoungePriceFixed, Value: 1500, IsActive: true},
+		{ID: "peak-low", Name: "Low priority peak", RuleType: LoungePriceRuleTimeSlot, StartTime: loungeStr("17:00"), EndTime: loungeStr("20:00"),
+			AdjustmentType: LoungePricePercent, Value: 10, Priority: 1, IsActive: true},
+	}
+	surge := &LoungeSurgeSettings{Enabled: true, OccupancyThresholdPercent: 50, MaxSurgePercent: 20}
+
+	offPeak := QuoteLoungePrice(1000, "1_hour", colombo(2026, time.March, 4, 10), rules, nil, 0)
+	assert.Nil(t, offPeak.RuleID)
+	assert.Equal(t, 1000.0, offPeak.PricePerGuest)
+
+	peak := QuoteLoungePrice(1000, "1_hour", colombo(2026, time.March, 4, 18), rules, nil, 0)
+	require.NotNil(t, peak.RuleID)
+	assert.Equal(t, "peak", *peak.RuleID, "higher priority wins")
+	assert.Equal(t, 1500.0, peak.PricePerGuest)
+
+	festival := QuoteLoungePrice(1000, "1_hour", colombo(2026, time.April, 13, 18), rules, surge, 75)
+	require.NotNil(t, festival.RuleID)
+	assert.Equal(t, "avurudu", *festival.RuleID, "date rules win over time slots")
+	assert.Equal(t, 10.0, festival.SurgePercent)
+	assert.Equal(t, 1650.0, festival.PricePerGuest)
+	assert.Equal(t, 1000.0, festival.BasePrice)
+}
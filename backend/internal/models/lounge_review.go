@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// LOUNGE REVIEW (lounge_reviews table)
+// ============================================================================
+
+// LoungeReviewMaxCommentLength caps a review's comment
+const LoungeReviewMaxCommentLength = 1000
+
+// LoungeReview is a guest's rating of a lounge visit, or of a product they ordered on it. A
+// booking has at most one lounge review and one review per product.
+type LoungeReview struct {
+	ID              uuid.UUID  `db:"id" json:"id"`
+	LoungeBookingID uuid.UUID  `db:"lounge_booking_id" json:"lounge_booking_id"`
+	LoungeID        uuid.UUID  `db:"lounge_id" json:"lounge_id"`
+	LoungeProductID *uuid.UUID `db:"lounge_product_id" json:"lounge_product_id,omitempty"` // NULL = review of the lounge itself
+	UserID          uuid.UUID  `db:"user_id" json:"-"`
+	Rating          int        `db:"rating" json:"rating"`
+	Comment         *string    `db:"comment" json:"comment,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+}
+
+// CreateLoungeReviewRequest is the body of a lounge or product review
+type CreateLoungeReviewRequest struct {
+	Rating  int     `json:"rating" binding:"required"`
+	Comment *string `json:"comment,omitempty"`
+}
+
+// Validate checks the rating is 1-5 and trims the comment, dropping it when blank
+func (r *CreateLoungeReviewRequest) Validate() error {
+	if r.Rating < 1 || r.Rating > 5 {
+		return &ValidationError{Message: "rating must be between 1 and 5"}
+	}
+	if r.Comment != nil {
+		comment := strings.TrimSpace(*r.Comment)
+		if len(comment) > LoungeReviewMaxCommentLength {
+			return &ValidationError{Message: "comment must be at most 1000 characters"}
+		}
+		if comment == "" {
+			r.Comment = nil
+		} else {
+			r.Comment = &comment
+		}
+	}
+	return nil
+}
+
+// LoungeReviewPage is one page of a lounge's or product's reviews with its aggregate rating
+type LoungeReviewPage struct {
+	Reviews       []LoungeReview `json:"reviews"`
+	AverageRating *string        `json:"average_rating,omitempty"` // DECIMAL(3,2)
+	TotalReviews  int            `json:"total_reviews"`
+	Limit         int            `json:"limit"`
+	Offset        int            `json:"offset"`
+}
+
+// CanBeReviewed reports whether the guest has used the booking, so it can be reviewed
+func (b *LoungeBooking) CanBeReviewed() bool {
+	switch b.Status {
+	case LoungeBookingStatusCheckedIn, LoungeBookingStatusInLounge,
+		LoungeBookingStatusCheckedOut, LoungeBookingStatusCompleted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateLoungeReviewRequest_Validate(t *testing.T) {
+	comment := "  Clean lounge, quick service  "
+	req := &CreateLoungeReviewRequest{Rating: 5, Comment: &comment}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, "Clean lounge, quick service", *req.Comment)
+
+	blank := "   "
+	req = &CreateLoungeReviewRequest{Rating: 3, Comment: &blank}
+	require.NoError(t, req.Validate())
+	assert.Nil(t, req.Comment, "blank comments are dropped")
+
+	assert.Error(t, (&CreateLoungeReviewRequest{Rating: 0}).Validate())
+	assert.Error(t, (&CreateLoungeReviewRequest{Rating: 6}).Validate())
+
+	long := strings.Repeat("a", LoungeReviewMaxCommentLength+1)
+	assert.Error(t, (&CreateLoungeReviewRequest{Rating: 4, Comment: &long}).Validate())
+}
+
+func TestLoungeBooking_CanBeReviewed(t *testing.T) {
+	for status, want := range map[LoungeBookingStatus]bool{
+		LoungeBookingStatusPending:    false,
+		LoungeBookingStatusConfirmed:  false,
+		LoungeBookingStatusCheckedIn:  true,
+		LoungeBookingStatusInLounge:   true,
+		LoungeBookingStatusCheckedOut: true,
+		LoungeBookingStatusCompleted:  true,
+		LoungeBookingStatusCancelled:  false,
+		LoungeBookingStatusNoShow:     false,
+	} {
+		booking := &LoungeBooking{Status: status}
+		assert.Equal(t, want, booking.CanBeReviewed(), status)
+	}
+}
@@ -50,6 +50,8 @@ type ManualSeatBooking struct {
 	PassengerNotes     *string                    `json:"passenger_notes,omitempty" db:"passenger_notes"`
 	BoardingStopID     *string                    `json:"boarding_stop_id,omitempty" db:"boarding_stop_id"`
 	AlightingStopID    *string                    `json:"alighting_stop_id,omitempty" db:"alighting_stop_id"`
+	BoardingStopOrder  *int                       `json:"boarding_stop_order,omitempty" db:"boarding_stop_order"` // Stop orders on the route when booked
+	AlightingStopOrder *int                       `json:"alighting_stop_order,omitempty" db:"alighting_stop_order"`
 	DepartureDatetime  time.Time                  `json:"departure_datetime" db:"departure_datetime"`
 	NumberOfSeats      int                        `json:"number_of_seats" db:"number_of_seats"`
 	TotalFare          float64                    `json:"total_fare" db:"total_fare"`
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MasterRouteImportMaxStops bounds the stops of one import; larger files are split by the admin
+const MasterRouteImportMaxStops = 5000
+
+// MasterRouteImportRef locates an imported route or stop in the upload: the line of a CSV
+// file, or the 1-based positions of a route and its stop in a JSON body
+type MasterRouteImportRef struct {
+	Line  int `json:"line,omitempty"`
+	Route int `json:"route,omitempty"`
+	Stop  int `json:"stop,omitempty"`
+}
+
+// MasterRouteImport is an upload of master routes with their stops. CSV uploads have one row
+// per stop, repeating the route columns; JSON uploads nest the stops under each route.
+type MasterRouteImport struct {
+	Routes      []MasterRouteImportRoute `json:"routes"`
+	ParseErrors []MasterRouteImportError `json:"-"` // Cells of a CSV upload that did not parse
+}
+
+// MasterRouteImportRoute is an imported master route
+type MasterRouteImportRoute struct {
+	RouteNumber              string                  `json:"route_number"`
+	RouteName                string                  `json:"route_name"`
+	OriginCity               string                  `json:"origin_city"`
+	DestinationCity          string                  `json:"destination_city"`
+	TotalDistanceKm          *float64                `json:"total_distance_km,omitempty"`
+	EstimatedDurationMinutes *int                    `json:"estimated_duration_minutes,omitempty"`
+	Stops                    []MasterRouteImportStop `json:"stops"`
+	Ref                      MasterRouteImportRef    `json:"-"`
+}
+
+// MasterRouteImportStop is a stop of an imported route
+type MasterRouteImportStop struct {
+	StopOrder                int                  `json:"stop_order"`
+	StopName                 string               `json:"stop_name"`
+	Latitude                 *float64             `json:"latitude,omitempty"`
+	Longitude                *float64             `json:"longitude,omitempty"`
+	ArrivalTimeOffsetMinutes *int                 `json:"arrival_time_offset_minutes,omitempty"`
+	IsMajorStop              bool                 `json:"is_major_stop"`
+	Ref                      MasterRouteImportRef `json:"-"`
+}
+
+// MasterRouteImportError is a problem with one route or stop of an upload
+type MasterRouteImportError struct {
+	MasterRouteImportRef
+	RouteNumber string `json:"route_number,omitempty"`
+	Field       string `json:"field,omitempty"`
+	Message     string `json:"message"`
+}
+
+// MasterRouteImportSummary is a route an import created, or would create on a dry run
+type MasterRouteImportSummary struct {
+	ID          string `json:"id,omitempty"` // Empty on a dry run
+	RouteNumber string `json:"route_number"`
+	RouteName   string `json:"route_name"`
+	Stops       int    `json:"stops"`
+}
+
+// MasterRouteImportResult reports an import. Nothing is written when errors are reported.
+type MasterRouteImportResult struct {
+	DryRun bool                       `json:"dry_run"`
+	Valid  bool                       `json:"valid"`
+	Routes []MasterRouteImportSummary `json:"routes"`
+	Errors []MasterRouteImportError   `json:"errors"`
+}
+
+// Validate checks every route and stop of the import and puts each route's stops in sequence.
+// Route numbers must be unique, each route needs at least two stops, and stop orders must be
+// unique and positive with arrival offsets that never go backwards.
+func (imp *MasterRouteImport) Validate() []MasterRouteImportError {
+	errs := append([]MasterRouteImportError{}, imp.ParseErrors...)
+	routeErr := func(route *MasterRouteImportRoute, field, message string) {
+		errs = append(errs, MasterRouteImportError{MasterRouteImportRef: route.Ref, RouteNumber: route.RouteNumber, Field: field, Message: message})
+	}
+	stopErr := func(route *MasterRouteImportRoute, stop *MasterRouteImportStop, field, message string) {
+		errs = append(errs, MasterRouteImportError{MasterRouteImportRef: stop.Ref, RouteNumber: route.RouteNumber, Field: field, Message: message})
+	}
+
+	if len(imp.Routes) == 0 {
+		return append(errs, MasterRouteImportError{Message: "the upload has no routes"})
+	}
+	stops := 0
+	for _, route := range imp.Routes {
+		stops += len(route.Stops)
+	}
+	if stops > MasterRouteImportMaxStops {
+		return append(errs, MasterRouteImportError{Message: fmt.Sprintf("the upload has %d stops; at most %d are imported at once", stops, MasterRouteImportMaxStops)})
+	}
+
+	seenRoutes := make(map[string]bool, len(imp.Routes))
+	for i := range imp.Routes {
+		route := &imp.Routes[i]
+		route.RouteNumber = strings.TrimSpace(route.RouteNumber)
+		route.RouteName = strings.TrimSpace(route.RouteName)
+		route.OriginCity = strings.TrimSpace(route.OriginCity)
+		route.DestinationCity = strings.TrimSpace(route.DestinationCity)
+
+		switch key := strings.ToLower(route.RouteNumber); {
+		case key == "":
+			routeErr(route, "route_number", "route_number is required")
+		case seenRoutes[key]:
+			routeErr(route, "route_number", "route_number appears more than once in the upload")
+		default:
+			seenRoutes[key] = true
+		}
+		if route.RouteName == "" {
+			routeErr(route, "route_name", "route_name is required")
+		}
+		if route.OriginCity == "" {
+			routeErr(route, "origin_city", "origin_city is required")
+		}
+		if route.DestinationCity == "" {
+			routeErr(route, "destination_city", "destination_city is required")
+		}
+		if route.TotalDistanceKm != nil && (*route.TotalDistanceKm <= 0 || *route.TotalDistanceKm > 2000) {
+			routeErr(route, "total_distance_km", "total_distance_km must be between 0 and 2000")
+		}
+		if route.EstimatedDurationMinutes != nil && (*route.EstimatedDurationMinutes <= 0 || *route.EstimatedDurationMinutes > 24*60) {
+			routeErr(route, "estimated_duration_minutes", "estimated_duration_minutes must be between 1 and 1440")
+		}
+		if len(route.Stops) < 2 {
+			routeErr(route, "stops", "a route needs at least two stops")
+		}
+
+		seenOrders := make(map[int]bool, len(route.Stops))
+		for j := range route.Stops {
+			stop := &route.Stops[j]
+			stop.StopName = strings.TrimSpace(stop.StopName)
+			if stop.StopName == "" {
+				stopErr(route, stop, "stop_name", "stop_name is required")
+			}
+			switch {
+			case stop.StopOrder < 1:
+				stopErr(route, stop, "stop_order", "stop_order must be a whole number of 1 or more")
+			case seenOrders[stop.StopOrder]:
+				stopErr(route, stop, "stop_order", fmt.Sprintf("stop_order %d appears more than once on the route", stop.StopOrder))
+			default:
+				seenOrders[stop.StopOrder] = true
+			}
+			if (stop.Latitude == nil) != (stop.Longitude == nil) {
+				stopErr(route, stop, "latitude", "latitude and longitude must be given together")
+			}
+			if stop.Latitude != nil && (*stop.Latitude < -90 || *stop.Latitude > 90) {
+				stopErr(route, stop, "latitude", "latitude must be between -90 and 90")
+			}
+			if stop.Longitude != nil && (*stop.Longitude < -180 || *stop.Longitude > 180) {
+				stopErr(route, stop, "longitude", "longitude must be between -180 and 180")
+			}
+			if stop.ArrivalTimeOffsetMinutes != nil && *stop.ArrivalTimeOffsetMinutes < 0 {
+				stopErr(route, stop, "arrival_time_offset_minutes", "arrival_time_offset_minutes must not be negative")
+			}
+		}
+
+		sort.SliceStable(route.Stops, func(a, b int) bool { return route.Stops[a].StopOrder < route.Stops[b].StopOrder })
+		lastOffset := -1
+		for j := range route.Stops {
+			stop := &route.Stops[j]
+			if stop.ArrivalTimeOffsetMinutes == nil || *stop.ArrivalTimeOffsetMinutes < 0 {
+				continue
+			}
+			if *stop.ArrivalTimeOffsetMinutes < lastOffset {
+				stopErr(route, stop, "arrival_time_offset_minutes", "arrival_time_offset_minutes is earlier than a previous stop's")
+			}
+			lastOffset = *stop.ArrivalTimeOffsetMinutes
+		}
+	}
+	return errs
+}
+
+// SortMasterRouteImportErrors orders errors as they appear in the upload
+func SortMasterRouteImportErrors(errs []MasterRouteImportError) []MasterRouteImportError {
+	sort.SliceStable(errs, func(a, b int) bool {
+		x, y := errs[a].MasterRouteImportRef, errs[b].MasterRouteImportRef
+		if x.Line != y.Line {
+			return x.Line < y.Line
+		}
+		if x.Route != y.Route {
+			return x.Route < y.Route
+		}
+		return x.Stop < y.Stop
+	})
+	return errs
+}
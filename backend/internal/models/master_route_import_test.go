@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasterRouteImport_Validate(t *testing.T) {
+	offset := func(minutes int) *int { return &minutes }
+	coord := func(value float64) *float64 { return &value }
+	valid := func() *MasterRouteImport {
+		return &MasterRouteImport{Routes: []MasterRouteImportRoute{{
+			RouteNumber:     " 02 ",
+			RouteName:       "Colombo - Matara",
+			OriginCity:      "Colombo",
+			DestinationCity: "Matara",
+			Stops: []MasterRouteImportStop{
+				{StopOrder: 2, StopName: "Galle", ArrivalTimeOffsetMinutes: offset(150)},
+				{StopOrder: 1, StopName: "Colombo Fort", ArrivalTimeOffsetMinutes: offset(0), Latitude: coord(6.93), Longitude: coord(79.85)},
+				{StopOrder: 3, StopName: "Matara", ArrivalTimeOffsetMinutes: offset(210)},
+			},
+		}}}
+	}
+
+	imp := valid()
+	assert.Empty(t, imp.Validate())
+	assert.Equal(t, "02", imp.Routes[0].RouteNumber)
+	assert.Equal(t, []string{"Colombo Fort", "Galle", "Matara"}, []string{imp.Routes[0].Stops[0].StopName, imp.Routes[0].Stops[1].StopName, imp.Routes[0].Stops[2].StopName}, "stops are put in sequence")
+
+	cases := map[string]struct {
+		change func(imp *MasterRouteImport)
+		field  string
+	}{
+		"no route number":      {func(imp *MasterRouteImport) { imp.Routes[0].RouteNumber = "" }, "route_number"},
+		"duplicate route":      {func(imp *MasterRouteImport) { imp.Routes = append(imp.Routes, imp.Routes[0]) }, "route_number"},
+		"no origin":            {func(imp *MasterRouteImport) { imp.Routes[0].OriginCity = " " }, "origin_city"},
+		"bad distance":         {func(imp *MasterRouteImport) { imp.Routes[0].TotalDistanceKm = coord(-5) }, "total_distance_km"},
+		"one stop":             {func(imp *MasterRouteImport) { imp.Routes[0].Stops = imp.Routes[0].Stops[:1] }, "stops"},
+		"duplicate stop order": {func(imp *MasterRouteImport) { imp.Routes[0].Stops[2].StopOrder = 2 }, "stop_order"},
+		"zero stop order":      {func(imp *MasterRouteImport) { imp.Routes[0].Stops[0].StopOrder = 0 }, "stop_order"},
+		"latitude alone":       {func(imp *MasterRouteImport) { imp.Routes[0].Stops[0].Latitude = coord(7) }, "latitude"},
+		"longitude range":      {func(imp *MasterRouteImport) { imp.Routes[0].Stops[1].Longitude = coord(200) }, "longitude"},
+		"offsets go backwards": {func(imp *MasterRouteImport) { imp.Routes[0].Stops[2].ArrivalTimeOffsetMinutes = offset(100) }, "arrival_time_offset_minutes"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			imp := valid()
+			tc.change(imp)
+			errs := imp.Validate()
+			require.NotEmpty(t, errs)
+			assert.Equal(t, tc.field, errs[0].Field)
+		})
+	}
+
+	assert.NotEmpty(t, (&MasterRouteImport{}).Validate(), "an empty upload is rejected")
+}
+
+func TestSortMasterRouteImportErrors(t *testing.T) {
+	errs := SortMasterRouteImportErrors([]MasterRouteImportError{
+		{MasterRouteImportRef: MasterRouteImportRef{Line: 7}},
+		{MasterRouteImportRef: MasterRouteImportRef{Line: 2}},
+		{MasterRouteImportRef: MasterRouteImportRef{Line: 4}},
+	})
+	assert.Equal(t, []int{2, 4, 7}, []int{errs[0].Line, errs[1].Line, errs[2].Line})
+}
@@ -0,0 +1,233 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+// Roles reported by GET /api/v1/me. Lounge staff have no user role; they are found through
+// their lounge_staff record.
+const (
+	MeRolePassenger   = "passenger"
+	MeRoleStaff       = "staff" // Driver and/or conductor
+	MeRoleBusOwner    = "bus_owner"
+	MeRoleLoungeOwner = "lounge_owner"
+	MeRoleLoungeStaff = "lounge_staff"
+)
+
+// Onboarding next steps shared by the non-passenger roles
+const (
+	OnboardingStepRegister          = "register"
+	OnboardingStepCompleteProfile   = "complete_profile"
+	OnboardingStepAwaitVerification = "await_verification"
+	OnboardingStepContactSupport    = "contact_support" // Rejected or suspended
+	OnboardingStepJoinBusOwner      = "join_bus_owner"  // Verified staff not employed by any owner
+	OnboardingStepAddLounge         = "add_lounge"
+)
+
+// RoleOnboarding is where a user stands in one role's onboarding
+type RoleOnboarding struct {
+	Role               string `json:"role"`
+	ProfileExists      bool   `json:"profile_exists"`
+	ProfileCompleted   bool   `json:"profile_completed"`
+	VerificationStatus string `json:"verification_status,omitempty"`
+	Ready              bool   `json:"ready"`               // Can use the role's app without further steps
+	NextStep           string `json:"next_step,omitempty"` // What the app should ask for next
+}
+
+// MeUser is the core account in a /me response
+type MeUser struct {
+	ID            uuid.UUID `json:"id"`
+	Phone         string    `json:"phone"`
+	Roles         []string  `json:"roles"`
+	Status        string    `json:"status"`
+	PhoneVerified bool      `json:"phone_verified"`
+}
+
+// MePassengerProfile summarises the passenger profile
+type MePassengerProfile struct {
+	ID           uuid.UUID           `json:"id"`
+	FirstName    NullString          `json:"first_name"`
+	LastName     NullString          `json:"last_name"`
+	Email        NullString          `json:"email"`
+	Completeness ProfileCompleteness `json:"completeness"`
+}
+
+// MeStaffProfile summarises the driver/conductor profile and current employer
+type MeStaffProfile struct {
+	ID                 string                  `json:"id"`
+	FirstName          *string                 `json:"first_name,omitempty"`
+	LastName           *string                 `json:"last_name,omitempty"`
+	StaffType          StaffType               `json:"staff_type"`
+	VerificationStatus StaffVerificationStatus `json:"verification_status"`
+	BusOwnerID         *string                 `json:"bus_owner_id,omitempty"`
+	EmploymentStatus   *EmploymentStatus       `json:"employment_status,omitempty"`
+}
+
+// MeBusOwnerProfile summarises the bus owner profile
+type MeBusOwnerProfile struct {
+	ID                 string             `json:"id"`
+	CompanyName        *string            `json:"company_name,omitempty"`
+	VerificationStatus VerificationStatus `json:"verification_status"`
+	TotalBuses         int                `json:"total_buses"`
+}
+
+// MeLoungeOwnerProfile summarises the lounge owner profile
+type MeLoungeOwnerProfile struct {
+	ID                 uuid.UUID                     `json:"id"`
+	BusinessName       *string                       `json:"business_name,omitempty"`
+	RegistrationStep   LoungeOwnerRegistrationStep   `json:"registration_step"`
+	VerificationStatus LoungeOwnerVerificationStatus `json:"verification_status"`
+}
+
+// MeLoungeStaffProfile summarises the lounge staff record
+type MeLoungeStaffProfile struct {
+	ID               uuid.UUID                   `json:"id"`
+	LoungeID         uuid.UUID                   `json:"lounge_id"`
+	FullName         *string                     `json:"full_name,omitempty"`
+	EmploymentStatus LoungeStaffEmploymentStatus `json:"employment_status"`
+}
+
+// MeResponse is everything the apps need to know about the signed-in user: the account, a
+// summary of each role's profile and the onboarding state of every role they hold
+type MeResponse struct {
+	User        MeUser                `json:"user"`
+	Passenger   *MePassengerProfile   `json:"passenger,omitempty"`
+	Staff       *MeStaffProfile       `json:"staff,omitempty"`
+	BusOwner    *MeBusOwnerProfile    `json:"bus_owner,omitempty"`
+	LoungeOwner *MeLoungeOwnerProfile `json:"lounge_owner,omitempty"`
+	LoungeStaff *MeLoungeStaffProfile `json:"lounge_staff,omitempty"`
+	Onboarding  []RoleOnboarding      `json:"onboarding"`
+	Preferences *UserPreferences      `json:"preferences,omitempty"`
+}
+
+// PassengerOnboarding reports passenger onboarding. Passengers can book with just a verified
+// phone, so they are always ready; the next step nudges them through the profile.
+func PassengerOnboarding(passenger *Passenger) RoleOnboarding {
+	onboarding := RoleOnboarding{Role: MeRolePassenger, Ready: true}
+	completeness := passenger.Completeness()
+	if passenger != nil {
+		onboarding.ProfileExists = true
+		onboarding.ProfileCompleted = passenger.ProfileCompleted
+	}
+	if completeness.NextStep != nil {
+		onboarding.NextStep = string(completeness.NextStep.Step)
+	}
+	return onboarding
+}
+
+// StaffOnboarding reports driver/conductor onboarding; staff are ready once verified and
+// actively employed by a bus owner
+func StaffOnboarding(staff *BusStaff, employment *BusStaffEmployment) RoleOnboarding {
+	onboarding := RoleOnboarding{Role: MeRoleStaff}
+	switch {
+	case staff == nil:
+		onboarding.NextStep = OnboardingStepRegister
+		return onboarding
+	case !staff.ProfileCompleted:
+		onboarding.NextStep = OnboardingStepCompleteProfile
+	case staff.VerificationStatus == StaffVerificationRejected:
+		onboarding.NextStep = OnboardingStepContactSupport
+	case staff.VerificationStatus != StaffVerificationApproved:
+		onboarding.NextStep = OnboardingStepAwaitVerification
+	case employment == nil || employment.EmploymentStatus != EmploymentStatusActive:
+		onboarding.NextStep = OnboardingStepJoinBusOwner
+	default:
+		onboarding.Ready = true
+	}
+	onboarding.ProfileExists = true
+	onboarding.ProfileCompleted = staff.ProfileCompleted
+	onboarding.VerificationStatus = string(staff.VerificationStatus)
+	return onboarding
+}
+
+// BusOwnerOnboarding reports bus owner onboarding; owners are ready once verified
+func BusOwnerOnboarding(owner *BusOwner) RoleOnboarding {
+	onboarding := RoleOnboarding{Role: MeRoleBusOwner}
+	switch {
+	case owner == nil:
+		onboarding.NextStep = OnboardingStepRegister
+		return onboarding
+	case !owner.ProfileCompleted:
+		onboarding.NextStep = OnboardingStepCompleteProfile
+	case owner.VerificationStatus == VerificationRejected:
+		onboarding.NextStep = OnboardingStepContactSupport
+	case owner.VerificationStatus != VerificationVerified:
+		onboarding.NextStep = OnboardingStepAwaitVerification
+	default:
+		onboarding.Ready = true
+	}
+	onboarding.ProfileExists = true
+	onboarding.ProfileCompleted = owner.ProfileCompleted
+	onboarding.VerificationStatus = string(owner.VerificationStatus)
+	return onboarding
+}
+
+// LoungeOwnerOnboarding reports lounge owner onboarding, following the registration steps;
+// owners are ready once registration is complete and approved
+func LoungeOwnerOnboarding(owner *LoungeOwner) RoleOnboarding {
+	onboarding := RoleOnboarding{Role: MeRoleLoungeOwner}
+	if owner == nil {
+		onboarding.NextStep = OnboardingStepRegister
+		return onboarding
+	}
+	switch {
+	case owner.RegistrationStep == LoungeOwnerRegStepPhoneVerified:
+		onboarding.NextStep = OnboardingStepCompleteProfile
+	case owner.RegistrationStep == LoungeOwnerRegStepProfileSubmitted:
+		onboarding.NextStep = OnboardingStepAddLounge
+	case owner.VerificationStatus == LoungeOwnerVerificationRejected, owner.VerificationStatus == LoungeOwnerVerificationSuspended:
+		onboarding.NextStep = OnboardingStepContactSupport
+	case owner.VerificationStatus != LoungeOwnerVerificationApproved:
+		onboarding.NextStep = OnboardingStepAwaitVerification
+	default:
+		onboarding.Ready = true
+	}
+	onboarding.ProfileExists = true
+	onboarding.ProfileCompleted = owner.ProfileCompleted
+	onboarding.VerificationStatus = string(owner.VerificationStatus)
+	return onboarding
+}
+
+// LoungeStaffOnboarding reports lounge staff onboarding; staff are ready once registered and active
+func LoungeStaffOnboarding(staff *LoungeStaff) RoleOnboarding {
+	onboarding := RoleOnboarding{Role: MeRoleLoungeStaff, ProfileExists: true, ProfileCompleted: staff.ProfileCompleted}
+	switch {
+	case !staff.ProfileCompleted:
+		onboarding.NextStep = OnboardingStepCompleteProfile
+	case staff.EmploymentStatus != LoungeStaffEmploymentActive:
+		onboarding.NextStep = OnboardingStepContactSupport
+	default:
+		onboarding.Ready = true
+	}
+	return onboarding
+}
+
+// nullStringPtr returns the string, or nil if it is NULL
+func nullStringPtr(ns sql.NullString) *string {
+	if !ns.Valid {
+		return nil
+	}
+	return &ns.String
+}
+
+// NewMeLoungeOwnerProfile summarises a lounge owner
+func NewMeLoungeOwnerProfile(owner *LoungeOwner) *MeLoungeOwnerProfile {
+	return &MeLoungeOwnerProfile{
+		ID:                 owner.ID,
+		BusinessName:       nullStringPtr(owner.BusinessName),
+		RegistrationStep:   owner.RegistrationStep,
+		VerificationStatus: owner.VerificationStatus,
+	}
+}
+
+// NewMeLoungeStaffProfile summarises a lounge staff member
+func NewMeLoungeStaffProfile(staff *LoungeStaff) *MeLoungeStaffProfile {
+	return &MeLoungeStaffProfile{
+		ID:               staff.ID,
+		LoungeID:         staff.LoungeID,
+		FullName:         nullStringPtr(staff.FullName),
+		EmploymentStatus: staff.EmploymentStatus,
+	}
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassengerOnboarding(t *testing.T) {
+	missing := PassengerOnboarding(nil)
+	assert.True(t, missing.Ready, "a verified phone is enough to book")
+	assert.False(t, missing.ProfileExists)
+	assert.Equal(t, string(ProfileStepName), missing.NextStep)
+
+	named := PassengerOnboarding(&Passenger{
+		FirstName: NullString{sql.NullString{String: "Nimal", Valid: true}},
+		LastName:  NullString{sql.NullString{String: "Perera", Valid: true}},
+	})
+	assert.True(t, named.ProfileExists)
+	assert.NotEqual(t, string(ProfileStepName), named.NextStep)
+}
+
+func TestStaffOnboarding(t *testing.T) {
+	assert.Equal(t, RoleOnboarding{Role: MeRoleStaff, NextStep: OnboardingStepRegister}, StaffOnboarding(nil, nil))
+
+	staff := &BusStaff{VerificationStatus: StaffVerificationPending}
+	assert.Equal(t, OnboardingStepCompleteProfile, StaffOnboarding(staff, nil).NextStep)
+
+	staff.ProfileCompleted = true
+	pending := StaffOnboarding(staff, nil)
+	assert.Equal(t, OnboardingStepAwaitVerification, pending.NextStep)
+	assert.Equal(t, "pending", pending.VerificationStatus)
+
+	staff.VerificationStatus = StaffVerificationRejected
+	assert.Equal(t, OnboardingStepContactSupport, StaffOnboarding(staff, nil).NextStep)
+
+	staff.VerificationStatus = StaffVerificationApproved
+	assert.Equal(t, OnboardingStepJoinBusOwner, StaffOnboarding(staff, nil).NextStep)
+	assert.Equal(t, OnboardingStepJoinBusOwner, StaffOnboarding(staff, &BusStaffEmployment{EmploymentStatus: EmploymentStatusTerminated}).NextStep)
+
+	ready := StaffOnboarding(staff, &BusStaffEmployment{EmploymentStatus: EmploymentStatusActive})
+	assert.True(t, ready.Ready)
+	assert.Empty(t, ready.NextStep)
+}
+
+func TestBusOwnerOnboarding(t *testing.T) {
+	assert.Equal(t, OnboardingStepRegister, BusOwnerOnboarding(nil).NextStep)
+
+	owner := &BusOwner{VerificationStatus: VerificationPending}
+	assert.Equal(t, OnboardingStepCompleteProfile, BusOwnerOnboarding(owner).NextStep)
+
+	owner.ProfileCompleted = true
+	assert.Equal(t, OnboardingStepAwaitVerification, BusOwnerOnboarding(owner).NextStep)
+
+	owner.VerificationStatus = VerificationVerified
+	ready := BusOwnerOnboarding(owner)
+	assert.True(t, ready.Ready)
+	assert.Equal(t, "verified", ready.VerificationStatus)
+}
+
+func TestLoungeOwnerOnboarding(t *testing.T) {
+	assert.Equal(t, OnboardingStepRegister, LoungeOwnerOnboarding(nil).NextStep)
+
+	owner := &LoungeOwner{RegistrationStep: LoungeOwnerRegStepPhoneVerified, VerificationStatus: LoungeOwnerVerificationPending}
+	assert.Equal(t, OnboardingStepCompleteProfile, LoungeOwnerOnboarding(owner).NextStep)
+
+	owner.RegistrationStep = LoungeOwnerRegStepProfileSubmitted
+	assert.Equal(t, OnboardingStepAddLounge, LoungeOwnerOnboarding(owner).NextStep)
+
+	owner.RegistrationStep = LoungeOwnerRegStepCompleted
+	assert.Equal(t, OnboardingStepAwaitVerification, LoungeOwnerOnboarding(owner).NextStep)
+
+	owner.VerificationStatus = LoungeOwnerVerificationSuspended
+	assert.Equal(t, OnboardingStepContactSupport, LoungeOwnerOnboarding(owner).NextStep)
+
+	owner.VerificationStatus = LoungeOwnerVerificationApproved
+	assert.True(t, LoungeOwnerOnboarding(owner).Ready)
+}
+
+func TestLoungeStaffOnboarding(t *testing.T) {
+	staff := &LoungeStaff{EmploymentStatus: LoungeStaffEmploymentActive}
+	assert.Equal(t, OnboardingStepCompleteProfile, LoungeStaffOnboarding(staff).NextStep)
+
+	staff.ProfileCompleted = true
+	assert.True(t, LoungeStaffOnboarding(staff).Ready)
+
+	staff.EmploymentStatus = LoungeStaffEmploymentSuspended
+	assert.Equal(t, OnboardingStepContactSupport, LoungeStaffOnboarding(staff).NextStep)
+}
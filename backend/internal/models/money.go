@@ -0,0 +1,170 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// moneyScale is the number of decimal places money is kept to (cents)
+const moneyScale = 2
+
+// Money is an LKR amount held as an exact decimal, always rounded to cents (half away from
+// zero). Prices are stored as numeric strings; parse them into Money before doing arithmetic
+// so fares and lounge totals add up to the cent. It marshals to a JSON number with two
+// decimal places, so it can replace a float64 field without changing the API.
+type Money struct {
+	d decimal.Decimal
+}
+
+// NewMoney converts a float amount to Money, rounded to cents
+func NewMoney(amount float64) Money {
+	return Money{d: decimal.NewFromFloat(amount).Round(moneyScale)}
+}
+
+// ParseMoney parses a stored price such as "1500.00"
+func ParseMoney(s string) (Money, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return Money{d: d.Round(moneyScale)}, nil
+}
+
+// RoundMoney rounds a float amount to cents using decimal rounding, so that e.g. 1.005 rounds
+// up to 1.01 rather than down as math.Round(1.005*100) does
+func RoundMoney(amount float64) float64 {
+	return NewMoney(amount).Float64()
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) Money {
+	return Money{d: m.d.Add(o.d)}
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) Money {
+	return Money{d: m.d.Sub(o.d)}
+}
+
+// Mul returns m times a quantity
+func (m Money) Mul(quantity int) Money {
+	return Money{d: m.d.Mul(decimal.NewFromInt(int64(quantity)))}
+}
+
+// Div returns m split n ways, rounded to cents. n must be positive.
+func (m Money) Div(n int) Money {
+	return Money{d: m.d.Div(decimal.NewFromInt(int64(n))).Round(moneyScale)}
+}
+
+// Max returns the larger of m and o
+func (m Money) Max(o Money) Money {
+	if m.d.GreaterThanOrEqual(o.d) {
+		return m
+	}
+	return o
+}
+
+// Min returns the smaller of m and o
+func (m Money) Min(o Money) Money {
+	if m.d.LessThanOrEqual(o.d) {
+		return m
+	}
+	return o
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{d: m.d.Neg()}
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than o
+func (m Money) Cmp(o Money) int {
+	return m.d.Cmp(o.d)
+}
+
+// Equal reports whether m and o are the same amount
+func (m Money) Equal(o Money) bool {
+	return m.d.Equal(o.d)
+}
+
+// IsZero reports whether m is zero
+func (m Money) IsZero() bool {
+	return m.d.IsZero()
+}
+
+// IsPositive reports whether m is above zero
+func (m Money) IsPositive() bool {
+	return m.d.IsPositive()
+}
+
+// IsNegative reports whether m is below zero
+func (m Money) IsNegative() bool {
+	return m.d.IsNegative()
+}
+
+// Float64 returns m as a float, for the fields and APIs that still carry amounts as floats
+func (m Money) Float64() float64 {
+	f, _ := m.d.Float64()
+	return f
+}
+
+// String formats m with two decimal places, as prices are stored
+func (m Money) String() string {
+	return m.d.StringFixed(moneyScale)
+}
+
+// MarshalJSON writes m as a number with two decimal places
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads a number or a numeric string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*m = Money{}
+		return nil
+	}
+	parsed, err := ParseMoney(string(data))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores m as a numeric string
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads a numeric column
+func (m *Money) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case float64:
+		*m = NewMoney(v)
+		return nil
+	case int64:
+		*m = Money{d: decimal.NewFromInt(v)}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Money", value)
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
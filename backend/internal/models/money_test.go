@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	m, err := ParseMoney("1500.00")
+	require.NoError(t, err)
+	assert.Equal(t, "1500.00", m.String())
+
+	m, err = ParseMoney("99.995")
+	require.NoError(t, err)
+	assert.Equal(t, "100.00", m.String(), "rounded half away from zero")
+
+	_, err = ParseMoney("")
+	assert.Error(t, err)
+	_, err = ParseMoney("abc")
+	assert.Error(t, err)
+}
+
+func TestMoneyArithmeticIsExact(t *testing.T) {
+	// 0.1 + 0.2 as floats is 0.30000000000000004
+	assert.Equal(t, "0.30", NewMoney(0.1).Add(NewMoney(0.2)).String())
+
+	unit, err := ParseMoney("450.10")
+	require.NoError(t, err)
+	assert.Equal(t, "1350.30", unit.Mul(3).String())
+	assert.Equal(t, "1000.00", NewMoney(1350.30).Sub(NewMoney(350.30)).String())
+
+	assert.Equal(t, "333.33", NewMoney(1000).Div(3).String())
+	assert.True(t, NewMoney(-5).Max(Money{}).IsZero())
+	assert.Equal(t, "9.99", NewMoney(10).Min(NewMoney(9.99)).String())
+	assert.Equal(t, "-9.99", NewMoney(9.99).Neg().String())
+	assert.True(t, NewMoney(0.01).IsPositive())
+	assert.False(t, Money{}.IsPositive())
+	assert.Equal(t, 1, NewMoney(10).Cmp(NewMoney(9.99)))
+}
+
+func TestRoundMoney(t *testing.T) {
+	assert.Equal(t, 1.01, RoundMoney(1.005), "math.Round(1.005*100)/100 gives 1.00")
+	assert.Equal(t, 2.68, RoundMoney(2.675))
+	assert.Equal(t, -1.01, RoundMoney(-1.005))
+	assert.Equal(t, 1200.0, RoundMoney(1200))
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Fare Money `json:"fare"`
+	}{NewMoney(1500)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"fare": 1500.00}`, string(data))
+
+	var snapshot PricingSnapshot
+	require.NoError(t, json.Unmarshal([]byte(`{"bus_fare": 1200.5, "total": "1700.50", "seat_prices": {"s1": 600.25}}`), &snapshot))
+	assert.Equal(t, "1200.50", snapshot.BusFare.String(), "snapshots stored as floats still read")
+	assert.Equal(t, "1700.50", snapshot.Total.String())
+	assert.Equal(t, "600.25", snapshot.SeatPrices["s1"].String())
+	assert.True(t, snapshot.PreLoungeFare.IsZero())
+}
+
+func TestMoneyScan(t *testing.T) {
+	var m Money
+	require.NoError(t, m.Scan([]byte("2500.50")))
+	assert.Equal(t, "2500.50", m.String())
+	require.NoError(t, m.Scan(int64(7)))
+	assert.Equal(t, "7.00", m.String())
+	require.NoError(t, m.Scan(nil))
+	assert.True(t, m.IsZero())
+	assert.Error(t, m.Scan(true))
+
+	value, err := NewMoney(12.3).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "12.30", value)
+}
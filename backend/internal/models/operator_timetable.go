@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// PublishedTimetableEntry is one active timetable row joined with its route
+// Only timetables with upcoming published (bookable) trips are returned
+type PublishedTimetableEntry struct {
+	ScheduleID               string         `db:"schedule_id"`
+	RouteID                  string         `db:"route_id"`
+	CustomRouteName          string         `db:"custom_route_name"`
+	Direction                string         `db:"direction"`
+	RouteNumber              string         `db:"route_number"`
+	OriginCity               string         `db:"origin_city"`
+	DestinationCity          string         `db:"destination_city"`
+	DepartureTime            string         `db:"departure_time"`
+	RecurrenceType           RecurrenceType `db:"recurrence_type"`
+	RecurrenceDays           string         `db:"recurrence_days"`
+	RecurrenceInterval       *int           `db:"recurrence_interval"`
+	EstimatedDurationMinutes *int           `db:"estimated_duration_minutes"`
+	BaseFare                 float64        `db:"base_fare"`
+	ValidFrom                *time.Time     `db:"valid_from"`
+	ValidUntil               *time.Time     `db:"valid_until"`
+	TripsMeasured            int            `db:"trips_measured"`
+	OnTimeTrips              int            `db:"on_time_trips"`
+	OnTimePercentage         *float64       `db:"on_time_percentage"`
+}
+
+// OperatorTimetableResponse is the public timetable of a bus operator
+type OperatorTimetableResponse struct {
+	Operator    OperatorPublicProfile    `json:"operator"`
+	Routes      []OperatorTimetableRoute `json:"routes"`
+	GeneratedAt time.Time                `json:"generated_at"`
+}
+
+// OperatorPublicProfile is the public, non-sensitive subset of a bus owner
+type OperatorPublicProfile struct {
+	ID               string   `json:"id"`
+	CompanyName      *string  `json:"company_name,omitempty"`
+	City             *string  `json:"city,omitempty"`
+	TotalBuses       int      `json:"total_buses"`
+	OnTimePercentage *float64 `json:"on_time_percentage,omitempty"` // Across published schedules, nightly
+}
+
+// OperatorTimetableRoute groups recurring departures by route
+type OperatorTimetableRoute struct {
+	RouteID         string              `json:"route_id"`
+	RouteNumber     string              `json:"route_number"`
+	RouteName       string              `json:"route_name"`
+	OriginCity      string              `json:"origin_city"`
+	DestinationCity string              `json:"destination_city"`
+	Direction       string              `json:"direction"`
+	Departures      []OperatorDeparture `json:"departures"`
+}
+
+// OperatorDeparture is a recurring departure time on a route
+type OperatorDeparture struct {
+	ScheduleID      string         `json:"schedule_id"`
+	DepartureTime   string         `json:"departure_time"`         // HH:MM
+	ArrivalTime     *string        `json:"arrival_time,omitempty"` // HH:MM, next day if overnight
+	DurationMinutes *int           `json:"duration_minutes,omitempty"`
+	RecurrenceType  RecurrenceType `json:"recurrence_type"`
+	DaysOfWeek      []string       `json:"days_of_week,omitempty"`  // For weekly: ["Mon", "Wed"]
+	IntervalDays    *int           `json:"interval_days,omitempty"` // For interval: every N days
+	BaseFare        float64        `json:"base_fare"`
+	ValidFrom       *string        `json:"valid_from,omitempty"`
+	ValidUntil      *string        `json:"valid_until,omitempty"`
+	// Share of recent trips that ran on time; omitted until enough trips have been measured
+	OnTimePercentage *float64 `json:"on_time_percentage,omitempty"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// OTPPurposeQATest marks OTP rows issued to QA test numbers, so they can be told apart from
+// real sign-ins
+const OTPPurposeQATest = "qa_test"
+
+// Audit log actions for QA test numbers, distinct from the real otp_request and otp_verify_* ones
+const (
+	AuditQAOTPRequest        = "qa_otp_request"
+	AuditQAOTPVerifySuccess  = "qa_otp_verify_success"
+	AuditQAOTPVerifyFailed   = "qa_otp_verify_failed"
+	AuditQATestNumberAdded   = "qa_test_number_added"
+	AuditQATestNumberRemoved = "qa_test_number_removed"
+	AuditQAOTPCodeViewed     = "qa_otp_code_viewed"
+)
+
+// OTPTestNumber is an internal QA phone number that signs in with the deploy's fixed OTP
+// instead of an SMS. Only a salted hash of the number is stored, with a masked copy for display.
+type OTPTestNumber struct {
+	ID          string    `json:"id" db:"id"`
+	PhoneHash   string    `json:"-" db:"phone_hash"`
+	PhoneMasked string    `json:"phone_masked" db:"phone_masked"` // e.g. *********567
+	Label       string    `json:"label" db:"label"`               // Whose device or which test suite
+	CreatedBy   string    `json:"created_by" db:"created_by"`     // Admin user ID
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// AddOTPTestNumberRequest adds a number to the QA allowlist
+type AddOTPTestNumberRequest struct {
+	Phone string `json:"phone_number" binding:"required"`
+	Label string `json:"label" binding:"required,max=100"`
+}
+
+// Validate trims the label
+func (r *AddOTPTestNumberRequest) Validate() error {
+	r.Label = strings.TrimSpace(r.Label)
+	if len(r.Label) < 3 {
+		return &ValidationError{Message: "label must be at least 3 characters"}
+	}
+	return nil
+}
+
+// OTPTestCode is the fixed OTP QA test numbers accept on the running deploy
+type OTPTestCode struct {
+	Code     string `json:"code"`
+	DeployID string `json:"deploy_id"`
+	Shared   bool   `json:"shared"` // False when no DEPLOY_ID is set, so each server instance has its own code
+}
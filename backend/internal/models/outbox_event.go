@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEventStatus tracks delivery of an event written to the transactional outbox
+type OutboxEventStatus string
+
+const (
+	OutboxEventPending    OutboxEventStatus = "pending"    // Awaiting delivery, or a retry after a consumer failed
+	OutboxEventDispatched OutboxEventStatus = "dispatched" // Every consumer handled it
+	OutboxEventFailed     OutboxEventStatus = "failed"     // Gave up after the maximum attempts; needs a look
+)
+
+// Retry backoff for events a consumer failed to handle
+const (
+	outboxRetryBase = 30 * time.Second
+	outboxRetryMax  = time.Hour
+)
+
+// OutboxEvent is a domain event saved in the same transaction as the change it describes, so
+// the event exists if and only if the change was committed. The dispatcher delivers it to its
+// consumers afterwards, at least once.
+type OutboxEvent struct {
+	ID           string            `json:"id" db:"id"`
+	EventType    string            `json:"event_type" db:"event_type"` // e.g. "booking.confirmed"
+	AggregateID  string            `json:"aggregate_id" db:"aggregate_id"`
+	Payload      json.RawMessage   `json:"payload" db:"payload"`
+	Status       OutboxEventStatus `json:"status" db:"status"`
+	Attempts     int               `json:"attempts" db:"attempts"`
+	LastError    *string           `json:"last_error,omitempty" db:"last_error"`
+	AvailableAt  time.Time         `json:"available_at" db:"available_at"` // Not delivered before this
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	DispatchedAt *time.Time        `json:"dispatched_at,omitempty" db:"dispatched_at"`
+}
+
+// NewOutboxEvent builds a pending event about an aggregate (e.g. an intent or booking),
+// encoding data as its payload
+func NewOutboxEvent(eventType, aggregateID string, data interface{}) (*OutboxEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	now := time.Now().UTC()
+	return &OutboxEvent{
+		ID:          uuid.New().String(),
+		EventType:   eventType,
+		AggregateID: aggregateID,
+		Payload:     payload,
+		Status:      OutboxEventPending,
+		AvailableAt: now,
+		CreatedAt:   now,
+	}, nil
+}
+
+// OutboxRetryDelay is how long to wait before redelivering an event that has failed attempts
+// times: 30 seconds, doubling each time, at most an hour
+func OutboxRetryDelay(attempts int) time.Duration {
+	delay := outboxRetryBase
+	for i := 1; i < attempts && delay < outboxRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > outboxRetryMax {
+		return outboxRetryMax
+	}
+	return delay
+}
+
+// NewBookingConfirmedEvent builds the booking.confirmed outbox event of a confirmed intent
+func NewBookingConfirmedEvent(data BookingEventData) (*OutboxEvent, error) {
+	aggregateID := data.BookingID
+	if aggregateID == "" {
+		aggregateID = data.IntentID
+	}
+	return NewOutboxEvent(EventBookingConfirmed, aggregateID, data)
+}
+
+// NewBookingCancelledEvent builds the booking.cancelled outbox event of a cancelled booking
+func NewBookingCancelledEvent(data BookingEventData) (*OutboxEvent, error) {
+	return NewOutboxEvent(EventBookingCancelled, data.BookingID, data)
+}
+
+// NewBookingSeatsCancelledEvent builds the booking.seats_cancelled outbox event of seats
+// cancelled out of a booking
+func NewBookingSeatsCancelledEvent(data BookingEventData) (*OutboxEvent, error) {
+	return NewOutboxEvent(EventBookingSeatsCancelled, data.BookingID, data)
+}
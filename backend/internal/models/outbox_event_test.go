@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBookingConfirmedEvent(t *testing.T) {
+	event, err := NewBookingConfirmedEvent(BookingEventData{BookingID: "booking-1", IntentID: "intent-1", UserID: "user-1", TotalAmount: 1500})
+	require.NoError(t, err)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, EventBookingConfirmed, event.EventType)
+	assert.Equal(t, "booking-1", event.AggregateID)
+	assert.Equal(t, OutboxEventPending, event.Status)
+	assert.False(t, event.AvailableAt.After(time.Now()), "due straight away")
+
+	var data BookingEventData
+	require.NoError(t, json.Unmarshal(event.Payload, &data))
+	assert.Equal(t, "user-1", data.UserID)
+	assert.Equal(t, 1500.0, data.TotalAmount)
+
+	event, err = NewBookingConfirmedEvent(BookingEventData{IntentID: "intent-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "intent-2", event.AggregateID, "lounge-only bookings have no master booking")
+}
+
+func TestNewOutboxEventRejectsUnencodablePayload(t *testing.T) {
+	_, err := NewOutboxEvent("bad", "x", map[string]interface{}{"ch": make(chan int)})
+	assert.Error(t, err)
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, OutboxRetryDelay(1))
+	assert.Equal(t, time.Minute, OutboxRetryDelay(2))
+	assert.Equal(t, 4*time.Minute, OutboxRetryDelay(4))
+	assert.Equal(t, time.Hour, OutboxRetryDelay(8), "capped")
+	assert.Equal(t, time.Hour, OutboxRetryDelay(50))
+}
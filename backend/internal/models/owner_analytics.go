@@ -0,0 +1,212 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	OwnerAnalyticsMaxDays     = 366 // Longest date range one query may cover
+	OwnerAnalyticsDefaultDays = 30  // Range used when none is given
+)
+
+// OwnerDailyStat is one bus owner's trips for one local day (Asia/Colombo), bus and route, as
+// rolled up into bus_owner_daily_stats. Trips count by departure day, bookings by their trip.
+type OwnerDailyStat struct {
+	StatDate          time.Time `db:"stat_date"`
+	BusID             *string   `db:"bus_id"`
+	LicensePlate      *string   `db:"license_plate"`
+	BusOwnerRouteID   *string   `db:"bus_owner_route_id"`
+	RouteName         *string   `db:"route_name"`
+	Trips             int       `db:"trips"`
+	CancelledTrips    int       `db:"cancelled_trips"`
+	SeatsOffered      int       `db:"seats_offered"` // Seats of trips that were not cancelled
+	SeatsBooked       int       `db:"seats_booked"`
+	PaidBookings      int       `db:"paid_bookings"`
+	CancelledBookings int       `db:"cancelled_bookings"`
+	Revenue           Money     `db:"revenue"` // Paid bus bookings that were not cancelled
+	TripsMeasured     int       `db:"trips_measured"`
+	OnTimeTrips       int       `db:"on_time_trips"`
+	ComputedAt        time.Time `db:"computed_at"`
+}
+
+// OwnerAnalyticsFigures are the totals and rates of a group of trips. Rates are percentages
+// rounded to one decimal, or nil when there is nothing to divide by.
+type OwnerAnalyticsFigures struct {
+	Trips                   int      `json:"trips"`
+	CancelledTrips          int      `json:"cancelled_trips"`
+	TripCancellationRate    *float64 `json:"trip_cancellation_rate"`
+	SeatsOffered            int      `json:"seats_offered"`
+	SeatsBooked             int      `json:"seats_booked"`
+	OccupancyRate           *float64 `json:"occupancy_rate"`
+	PaidBookings            int      `json:"paid_bookings"`
+	CancelledBookings       int      `json:"cancelled_bookings"`
+	BookingCancellationRate *float64 `json:"booking_cancellation_rate"` // Cancelled out of paid and cancelled bookings
+	Revenue                 Money    `json:"revenue"`
+	TripsMeasured           int      `json:"trips_measured"` // Completed trips with a recorded start
+	OnTimeTrips             int      `json:"on_time_trips"`
+	OnTimePercentage        *float64 `json:"on_time_percentage"`
+}
+
+// OwnerAnalyticsGroup is the figures of one route or bus
+type OwnerAnalyticsGroup struct {
+	ID   *string `json:"id"`   // Nil for trips without a route or bus assigned
+	Name string  `json:"name"` // Route name or license plate
+	OwnerAnalyticsFigures
+}
+
+// OwnerAnalyticsDay is the figures of one local day
+type OwnerAnalyticsDay struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	OwnerAnalyticsFigures
+}
+
+// OwnerAnalytics is a bus owner's revenue, occupancy, cancellation and punctuality report
+type OwnerAnalytics struct {
+	From       string                `json:"from"` // YYYY-MM-DD, inclusive
+	To         string                `json:"to"`   // YYYY-MM-DD, inclusive
+	Timezone   string                `json:"timezone"`
+	Totals     OwnerAnalyticsFigures `json:"totals"`
+	ByRoute    []OwnerAnalyticsGroup `json:"by_route"` // Highest revenue first
+	ByBus      []OwnerAnalyticsGroup `json:"by_bus"`
+	Daily      []OwnerAnalyticsDay   `json:"daily"`                  // Days with trips, in order
+	RolledUpAt *time.Time            `json:"rolled_up_at,omitempty"` // Oldest rollup of the days shown
+}
+
+// OwnerAnalyticsRequest holds the analytics query parameters
+type OwnerAnalyticsRequest struct {
+	From string `form:"from"` // YYYY-MM-DD, defaults to 30 days before to
+	To   string `form:"to"`   // YYYY-MM-DD, defaults to today
+}
+
+// Range returns the requested local dates as [start, end) instants, checking the range
+func (r *OwnerAnalyticsRequest) Range(now time.Time) (time.Time, time.Time, error) {
+	local := now.In(ReportTimezone)
+	to := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ReportTimezone)
+	if r.To != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", r.To, ReportTimezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: "to must be in YYYY-MM-DD format"}
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(OwnerAnalyticsDefaultDays - 1))
+	if r.From != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", r.From, ReportTimezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: "from must be in YYYY-MM-DD format"}
+		}
+		from = parsed
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, &ValidationError{Message: "to must not be before from"}
+	}
+	if to.AddDate(0, 0, 1).Sub(from) > OwnerAnalyticsMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, &ValidationError{Message: "the date range must not exceed 366 days"}
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// add accumulates a daily stat; rates are filled in by finish
+func (f *OwnerAnalyticsFigures) add(stat OwnerDailyStat) {
+	f.Trips += stat.Trips
+	f.CancelledTrips += stat.CancelledTrips
+	f.SeatsOffered += stat.SeatsOffered
+	f.SeatsBooked += stat.SeatsBooked
+	f.PaidBookings += stat.PaidBookings
+	f.CancelledBookings += stat.CancelledBookings
+	f.Revenue = f.Revenue.Add(stat.Revenue)
+	f.TripsMeasured += stat.TripsMeasured
+	f.OnTimeTrips += stat.OnTimeTrips
+}
+
+func (f *OwnerAnalyticsFigures) finish() {
+	f.TripCancellationRate = analyticsRate(f.CancelledTrips, f.Trips)
+	f.OccupancyRate = analyticsRate(f.SeatsBooked, f.SeatsOffered)
+	f.BookingCancellationRate = analyticsRate(f.CancelledBookings, f.PaidBookings+f.CancelledBookings)
+	f.OnTimePercentage = analyticsRate(f.OnTimeTrips, f.TripsMeasured)
+}
+
+func analyticsRate(part, whole int) *float64 {
+	if whole <= 0 {
+		return nil
+	}
+	rate := math.Round(float64(part)/float64(whole)*1000) / 10
+	return &rate
+}
+
+// BuildOwnerAnalytics combines an owner's daily stats into totals, per-route, per-bus and per-day figures
+func BuildOwnerAnalytics(stats []OwnerDailyStat, from, to time.Time) *OwnerAnalytics {
+	report := &OwnerAnalytics{
+		From:     from.Format("2006-01-02"),
+		To:       to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Timezone: ReportTimezone.String(),
+		ByRoute:  []OwnerAnalyticsGroup{},
+		ByBus:    []OwnerAnalyticsGroup{},
+		Daily:    []OwnerAnalyticsDay{},
+	}
+
+	routes := map[string]*OwnerAnalyticsGroup{}
+	buses := map[string]*OwnerAnalyticsGroup{}
+	days := map[string]*OwnerAnalyticsDay{}
+	group := func(groups map[string]*OwnerAnalyticsGroup, id, name *string, unassigned string) *OwnerAnalyticsGroup {
+		key := ""
+		if id != nil {
+			key = *id
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &OwnerAnalyticsGroup{ID: id, Name: unassigned}
+			if name != nil && *name != "" {
+				g.Name = *name
+			}
+			groups[key] = g
+		}
+		return g
+	}
+
+	for _, stat := range stats {
+		report.Totals.add(stat)
+		group(routes, stat.BusOwnerRouteID, stat.RouteName, "No route").add(stat)
+		group(buses, stat.BusID, stat.LicensePlate, "No bus assigned").add(stat)
+
+		date := stat.StatDate.Format("2006-01-02")
+		day, ok := days[date]
+		if !ok {
+			day = &OwnerAnalyticsDay{Date: date}
+			days[date] = day
+		}
+		day.add(stat)
+
+		if report.RolledUpAt == nil || stat.ComputedAt.Before(*report.RolledUpAt) {
+			computedAt := stat.ComputedAt
+			report.RolledUpAt = &computedAt
+		}
+	}
+
+	report.Totals.finish()
+	sortedGroups := func(groups map[string]*OwnerAnalyticsGroup) []OwnerAnalyticsGroup {
+		out := make([]OwnerAnalyticsGroup, 0, len(groups))
+		for _, g := range groups {
+			g.finish()
+			out = append(out, *g)
+		}
+		sort.Slice(out, func(i, j int) bool {
+			if c := out[i].Revenue.Cmp(out[j].Revenue); c != 0 {
+				return c > 0
+			}
+			return out[i].Name < out[j].Name
+		})
+		return out
+	}
+	report.ByRoute = sortedGroups(routes)
+	report.ByBus = sortedGroups(buses)
+	for _, day := range days {
+		day.finish()
+		report.Daily = append(report.Daily, *day)
+	}
+	sort.Slice(report.Daily, func(i, j int) bool { return report.Daily[i].Date < report.Daily[j].Date })
+	return report
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnerAnalyticsRequest_Range(t *testing.T) {
+	now := time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC) // 11 March in Colombo
+
+	from, to, err := (&OwnerAnalyticsRequest{}).Range(now)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-02-10", from.Format("2006-01-02"))
+	assert.Equal(t, "2026-03-12", to.Format("2006-01-02"), "to is exclusive")
+
+	_, _, err = (&OwnerAnalyticsRequest{From: "2026-03-05", To: "2026-03-01"}).Range(now)
+	assert.Error(t, err)
+	_, _, err = (&OwnerAnalyticsRequest{From: "2025-01-01", To: "2026-03-01"}).Range(now)
+	assert.Error(t, err)
+	_, _, err = (&OwnerAnalyticsRequest{From: "03/01/2026"}).Range(now)
+	assert.Error(t, err)
+}
+
+func TestBuildOwnerAnalytics(t *testing.T) {
+	str := func(value string) *string { return &value }
+	day1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	computed := time.Date(2026, 3, 3, 1, 0, 0, 0, time.UTC)
+	stats := []OwnerDailyStat{
+		{StatDate: day1, BusID: str("bus-1"), LicensePlate: str("NB-1234"), BusOwnerRouteID: str("route-1"), RouteName: str("Colombo - Kandy"),
+			Trips: 4, CancelledTrips: 1, SeatsOffered: 150, SeatsBooked: 120, PaidBookings: 60, CancelledBookings: 5,
+			Revenue: NewMoney(60000), TripsMeasured: 3, OnTimeTrips: 2, ComputedAt: computed},
+		{StatDate: day2, BusID: str("bus-1"), LicensePlate: str("NB-1234"), BusOwnerRouteID: str("route-2"), RouteName: str("Colombo - Galle"),
+			Trips: 2, SeatsOffered: 100, SeatsBooked: 30, PaidBookings: 20, Revenue: NewMoney(18000.50),
+			TripsMeasured: 2, OnTimeTrips: 2, ComputedAt: computed.Add(-time.Hour)},
+		{StatDate: day2, Trips: 1, CancelledTrips: 1, ComputedAt: computed},
+	}
+
+	report := BuildOwnerAnalytics(stats, day1, day2.AddDate(0, 0, 1))
+	assert.Equal(t, "2026-03-01", report.From)
+	assert.Equal(t, "2026-03-02", report.To)
+
+	totals := report.Totals
+	assert.Equal(t, 7, totals.Trips)
+	assert.Equal(t, 2, totals.CancelledTrips)
+	assert.Equal(t, 28.6, *totals.TripCancellationRate)
+	assert.Equal(t, 60.0, *totals.OccupancyRate)
+	assert.Equal(t, 5.9, *totals.BookingCancellationRate)
+	assert.True(t, totals.Revenue.Equal(NewMoney(78000.50)))
+	assert.Equal(t, 80.0, *totals.OnTimePercentage)
+	assert.Equal(t, computed.Add(-time.Hour), *report.RolledUpAt, "the oldest rollup is reported")
+
+	require.Len(t, report.ByRoute, 3)
+	assert.Equal(t, "Colombo - Kandy", report.ByRoute[0].Name, "highest revenue first")
+	assert.Equal(t, "No route", report.ByRoute[2].Name)
+	assert.Nil(t, report.ByRoute[2].ID)
+	assert.Nil(t, report.ByRoute[2].OccupancyRate, "no seats offered gives no rate")
+
+	require.Len(t, report.ByBus, 2)
+	assert.Equal(t, "NB-1234", report.ByBus[0].Name)
+	assert.Equal(t, 6, report.ByBus[0].Trips)
+	assert.Equal(t, "No bus assigned", report.ByBus[1].Name)
+
+	require.Len(t, report.Daily, 2)
+	assert.Equal(t, "2026-03-01", report.Daily[0].Date)
+	assert.Equal(t, 3, report.Daily[1].Trips)
+	assert.Equal(t, 30.0, *report.Daily[1].OccupancyRate)
+}
+
+func TestBuildOwnerAnalytics_Empty(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, ReportTimezone)
+	report := BuildOwnerAnalytics(nil, day, day.AddDate(0, 0, 7))
+	assert.Empty(t, report.ByRoute)
+	assert.NotNil(t, report.Daily)
+	assert.Nil(t, report.Totals.OccupancyRate)
+	assert.Nil(t, report.RolledUpAt)
+}
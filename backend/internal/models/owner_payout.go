@@ -0,0 +1,215 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PayoutOwnerType is the kind of owner a bank account or payout belongs to
+type PayoutOwnerType string
+
+const (
+	PayoutOwnerBus    PayoutOwnerType = "bus_owner"
+	PayoutOwnerLounge PayoutOwnerType = "lounge_owner"
+)
+
+// BankAccountStatus tracks admin verification of an owner's bank account
+type BankAccountStatus string
+
+const (
+	BankAccountPending  BankAccountStatus = "pending"
+	BankAccountVerified BankAccountStatus = "verified"
+	BankAccountRejected BankAccountStatus = "rejected"
+)
+
+// OwnerBankAccount is the account an owner's payouts are transferred to. The full account
+// number is only ever shown to admins preparing transfers.
+type OwnerBankAccount struct {
+	ID                  uuid.UUID         `json:"id" db:"id"`
+	OwnerType           PayoutOwnerType   `json:"owner_type" db:"owner_type"`
+	OwnerID             uuid.UUID         `json:"owner_id" db:"owner_id"`
+	AccountHolderName   string            `json:"account_holder_name" db:"account_holder_name"`
+	BankName            string            `json:"bank_name" db:"bank_name"`
+	BankCode            *string           `json:"bank_code,omitempty" db:"bank_code"`
+	BranchName          *string           `json:"branch_name,omitempty" db:"branch_name"`
+	BranchCode          *string           `json:"branch_code,omitempty" db:"branch_code"`
+	AccountNumber       string            `json:"-" db:"account_number"`
+	MaskedAccountNumber string            `json:"masked_account_number" db:"-"`
+	Status              BankAccountStatus `json:"status" db:"status"`
+	RejectionReason     *string           `json:"rejection_reason,omitempty" db:"rejection_reason"`
+	VerifiedAt          *time.Time        `json:"verified_at,omitempty" db:"verified_at"`
+	VerifiedByUserID    *uuid.UUID        `json:"verified_by_user_id,omitempty" db:"verified_by_user_id"`
+	CreatedAt           time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// MaskAccountNumber hides all but the last four digits, e.g. "********4521"
+func MaskAccountNumber(accountNumber string) string {
+	if len(accountNumber) <= 4 {
+		return strings.Repeat("*", len(accountNumber))
+	}
+	return strings.Repeat("*", len(accountNumber)-4) + accountNumber[len(accountNumber)-4:]
+}
+
+var bankAccountNumberPattern = regexp.MustCompile(`^[0-9]{6,20}$`)
+
+// UpsertBankAccountRequest sets the owner's payout bank account. Any change needs re-verification.
+type UpsertBankAccountRequest struct {
+	AccountHolderName string `json:"account_holder_name" binding:"required,max=150"`
+	BankName          string `json:"bank_name" binding:"required,max=100"`
+	BankCode          string `json:"bank_code" binding:"max=10"`
+	BranchName        string `json:"branch_name" binding:"max=100"`
+	BranchCode        string `json:"branch_code" binding:"max=10"`
+	AccountNumber     string `json:"account_number" binding:"required"`
+}
+
+// Validate normalizes the account number (spaces and dashes removed) and checks it is numeric
+func (r *UpsertBankAccountRequest) Validate() error {
+	r.AccountNumber = strings.NewReplacer(" ", "", "-", "").Replace(r.AccountNumber)
+	if !bankAccountNumberPattern.MatchString(r.AccountNumber) {
+		return &ValidationError{Message: "account_number must be 6 to 20 digits"}
+	}
+	if strings.TrimSpace(r.AccountHolderName) == "" || strings.TrimSpace(r.BankName) == "" {
+		return &ValidationError{Message: "account_holder_name and bank_name are required"}
+	}
+	return nil
+}
+
+// VerifyBankAccountRequest is an admin's decision on a bank account
+type VerifyBankAccountRequest struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason"` // Required when rejecting
+}
+
+// PayoutCycle is how often owners are settled
+type PayoutCycle string
+
+const (
+	PayoutCycleWeekly  PayoutCycle = "weekly"  // Monday-Sunday
+	PayoutCycleMonthly PayoutCycle = "monthly" // Calendar month
+)
+
+// PeriodEnding returns the last cycle [start, end) that finished by at, in local time
+func (c PayoutCycle) PeriodEnding(at time.Time) (time.Time, time.Time) {
+	if c == PayoutCycleMonthly {
+		return ReportMonthlyOccupancy.PeriodEnding(at)
+	}
+	return ReportWeeklyRevenue.PeriodEnding(at)
+}
+
+// PayoutStatus tracks a payout from generation to transfer
+type PayoutStatus string
+
+const (
+	PayoutPending    PayoutStatus = "pending"    // Ready to transfer
+	PayoutOnHold     PayoutStatus = "on_hold"    // Not payable yet (e.g. no verified bank account)
+	PayoutProcessing PayoutStatus = "processing" // Transfer initiated
+	PayoutPaid       PayoutStatus = "paid"
+	PayoutFailed     PayoutStatus = "failed" // Transfer bounced; can be retried
+)
+
+// CanTransitionTo reports whether a payout may move from s to next. Paid payouts are final.
+func (s PayoutStatus) CanTransitionTo(next PayoutStatus) bool {
+	switch s {
+	case PayoutPending:
+		return next == PayoutProcessing || next == PayoutPaid || next == PayoutOnHold
+	case PayoutOnHold:
+		return next == PayoutPending
+	case PayoutProcessing:
+		return next == PayoutPaid || next == PayoutFailed
+	case PayoutFailed:
+		return next == PayoutPending || next == PayoutProcessing
+	default:
+		return false
+	}
+}
+
+// PayoutBatch is the set of payouts generated for one settlement period
+type PayoutBatch struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	PeriodStart     time.Time  `json:"period_start" db:"period_start"`
+	PeriodEnd       time.Time  `json:"period_end" db:"period_end"`
+	PayoutCount     int        `json:"payout_count" db:"payout_count"`
+	TotalNetAmount  float64    `json:"total_net_amount" db:"total_net_amount"`
+	CreatedByUserID *uuid.UUID `json:"created_by_user_id,omitempty" db:"created_by_user_id"` // Nil when generated by the scheduler
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// OwnerPayout is what one owner is owed for one settlement period
+type OwnerPayout struct {
+	ID                    uuid.UUID       `json:"id" db:"id"`
+	BatchID               uuid.UUID       `json:"batch_id" db:"batch_id"`
+	OwnerType             PayoutOwnerType `json:"owner_type" db:"owner_type"`
+	OwnerID               uuid.UUID       `json:"owner_id" db:"owner_id"`
+	BankAccountID         *uuid.UUID      `json:"bank_account_id,omitempty" db:"bank_account_id"`
+	PeriodStart           time.Time       `json:"period_start" db:"period_start"`
+	PeriodEnd             time.Time       `json:"period_end" db:"period_end"`
+	BookingCount          int             `json:"booking_count" db:"booking_count"`
+	GrossAmount           float64         `json:"gross_amount" db:"gross_amount"`
+	CommissionAmount      float64         `json:"commission_amount" db:"commission_amount"`
+	DisputeDeductedAmount float64         `json:"dispute_deducted_amount" db:"dispute_deducted_amount"`
+	DisputeHeldAmount     float64         `json:"dispute_held_amount" db:"dispute_held_amount"` // Open disputes that may be deducted later
+	NetAmount             float64         `json:"net_amount" db:"net_amount"`
+	Status                PayoutStatus    `json:"status" db:"status"`
+	StatusReason          *string         `json:"status_reason,omitempty" db:"status_reason"`
+	TransferReference     *string         `json:"transfer_reference,omitempty" db:"transfer_reference"`
+	PaidAt                *time.Time      `json:"paid_at,omitempty" db:"paid_at"`
+	CreatedAt             time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// OwnerPayoutTransfer is a payout with the bank details an admin needs to make the transfer
+type OwnerPayoutTransfer struct {
+	OwnerPayout
+	OwnerName         *string `json:"owner_name,omitempty" db:"owner_name"`
+	AccountHolderName *string `json:"account_holder_name,omitempty" db:"account_holder_name"`
+	BankName          *string `json:"bank_name,omitempty" db:"bank_name"`
+	BranchName        *string `json:"branch_name,omitempty" db:"branch_name"`
+	AccountNumber     *string `json:"account_number,omitempty" db:"account_number"`
+}
+
+// OwnerEarnings is an owner's earnings from bookings in a settlement period
+type OwnerEarnings struct {
+	OwnerType    PayoutOwnerType `db:"owner_type"`
+	OwnerID      uuid.UUID       `db:"owner_id"`
+	BookingCount int             `db:"booking_count"`
+	GrossAmount  float64         `db:"gross_amount"`
+}
+
+// CalculatePayout works out the payout amounts: commission comes off the gross, then lost
+// disputes. The net never goes negative; a shortfall is left for finance to recover.
+func CalculatePayout(grossAmount float64, commissionPercent int, disputeDeducted float64) (commission, net float64) {
+	commission = RoundMoney(grossAmount * float64(commissionPercent) / 100)
+	net = RoundMoney(grossAmount - commission - disputeDeducted)
+	if net < 0 {
+		net = 0
+	}
+	return commission, net
+}
+
+// GeneratePayoutBatchRequest generates the batch for a period; the last finished cycle by default
+type GeneratePayoutBatchRequest struct {
+	PeriodStart *time.Time `json:"period_start,omitempty"`
+	PeriodEnd   *time.Time `json:"period_end,omitempty"`
+}
+
+// UpdatePayoutStatusRequest records transfer progress on a payout
+type UpdatePayoutStatusRequest struct {
+	Status            PayoutStatus `json:"status" binding:"required,oneof=pending on_hold processing paid failed"`
+	TransferReference string       `json:"transfer_reference"`
+	Reason            string       `json:"reason"`
+}
+
+// Validate requires a transfer reference for paid payouts and a reason for failed or held ones
+func (r *UpdatePayoutStatusRequest) Validate() error {
+	if r.Status == PayoutPaid && strings.TrimSpace(r.TransferReference) == "" {
+		return &ValidationError{Message: "transfer_reference is required when marking a payout paid"}
+	}
+	if (r.Status == PayoutFailed || r.Status == PayoutOnHold) && strings.TrimSpace(r.Reason) == "" {
+		return &ValidationError{Message: "reason is required for failed or held payouts"}
+	}
+	return nil
+}
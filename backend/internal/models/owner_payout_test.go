@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskAccountNumber(t *testing.T) {
+	assert.Equal(t, "********4521", MaskAccountNumber("100200304521"))
+	assert.Equal(t, "***", MaskAccountNumber("123"))
+}
+
+func TestUpsertBankAccountRequest_Validate(t *testing.T) {
+	req := &UpsertBankAccountRequest{AccountHolderName: "K. Perera", BankName: "Commercial Bank", AccountNumber: "1002 0030-4521"}
+	assert.NoError(t, req.Validate())
+	assert.Equal(t, "100200304521", req.AccountNumber, "spaces and dashes are stripped")
+
+	assert.Error(t, (&UpsertBankAccountRequest{AccountHolderName: "K. Perera", BankName: "BOC", AccountNumber: "12AB3456"}).Validate())
+	assert.Error(t, (&UpsertBankAccountRequest{AccountHolderName: "K. Perera", BankName: "BOC", AccountNumber: "12345"}).Validate())
+}
+
+func TestPayoutStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, PayoutPending.CanTransitionTo(PayoutProcessing))
+	assert.True(t, PayoutOnHold.CanTransitionTo(PayoutPending))
+	assert.True(t, PayoutFailed.CanTransitionTo(PayoutProcessing), "failed transfers can be retried")
+	assert.False(t, PayoutOnHold.CanTransitionTo(PayoutPaid), "held payouts must be released first")
+	assert.False(t, PayoutPaid.CanTransitionTo(PayoutFailed), "paid payouts are final")
+}
+
+func TestPayoutCycle_PeriodEnding(t *testing.T) {
+	at := colombo(2026, time.March, 4, 6)
+
+	from, to := PayoutCycleWeekly.PeriodEnding(at)
+	assert.Equal(t, colombo(2026, time.February, 23, 0), from)
+	assert.Equal(t, colombo(2026, time.March, 2, 0), to)
+
+	from, to = PayoutCycleMonthly.PeriodEnding(at)
+	assert.Equal(t, colombo(2026, time.February, 1, 0), from)
+	assert.Equal(t, colombo(2026, time.March, 1, 0), to)
+}
+
+func TestCalculatePayout(t *testing.T) {
+	commission, net := CalculatePayout(25000, 10, 1200)
+	assert.Equal(t, 2500.0, commission)
+	assert.Equal(t, 21300.0, net)
+
+	commission, net = CalculatePayout(1000.5, 0, 0)
+	assert.Equal(t, 0.0, commission)
+	assert.Equal(t, 1000.5, net)
+
+	_, net = CalculatePayout(500, 10, 2000)
+	assert.Equal(t, 0.0, net, "net never goes negative")
+}
+
+func TestUpdatePayoutStatusRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&UpdatePayoutStatusRequest{Status: PayoutPaid, TransferReference: "CEFT-88231"}).Validate())
+	assert.Error(t, (&UpdatePayoutStatusRequest{Status: PayoutPaid}).Validate())
+	assert.Error(t, (&UpdatePayoutStatusRequest{Status: PayoutFailed}).Validate())
+	assert.NoError(t, (&UpdatePayoutStatusRequest{Status: PayoutProcessing}).Validate())
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// How staff can reach a passenger whose contact details are masked
+const (
+	ContactAccessReveal = "reveal" // Staff can reveal the number; every reveal is audited
+	ContactAccessRelay  = "relay"  // Staff call the relay number and quote the booking reference
+	ContactAccessNone   = "none"
+)
+
+// StaffContactPolicy tells the staff app how passenger contacts are shown in booking views
+type StaffContactPolicy struct {
+	Masked      bool   `json:"masked"`
+	Access      string `json:"access"` // "reveal", "relay" or "none"
+	RelayNumber string `json:"relay_number,omitempty"`
+}
+
+// MaskPhone hides all but the last visible digits of a phone number, keeping its length,
+// e.g. "+94771234567" becomes "*********567"
+func MaskPhone(phone string, visible int) string {
+	phone = strings.TrimSpace(phone)
+	if visible < 0 {
+		visible = 0
+	}
+	runes := []rune(phone)
+	if len(runes) <= visible {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-visible) + string(runes[len(runes)-visible:])
+}
+
+// MaskBusBookingContacts masks the seat passengers' phone numbers and hides their email and NIC
+func MaskBusBookingContacts(booking *BusBooking, visible int) {
+	for i := range booking.Seats {
+		seat := &booking.Seats[i]
+		if seat.PassengerPhone != nil {
+			masked := MaskPhone(*seat.PassengerPhone, visible)
+			seat.PassengerPhone = &masked
+		}
+		seat.PassengerEmail = nil
+		seat.PassengerNIC = nil
+	}
+}
+
+// PassengerContactTarget is a booked seat with the people allowed to contact its passenger
+type PassengerContactTarget struct {
+	SeatID           string  `db:"seat_id"`
+	BusBookingID     string  `db:"bus_booking_id"`
+	BookingReference string  `db:"booking_reference"`
+	ScheduledTripID  string  `db:"scheduled_trip_id"`
+	PassengerName    string  `db:"passenger_name"`
+	PassengerPhone   string  `db:"passenger_phone"` // The seat's phone, or the booking contact's
+	DriverUserID     *string `db:"driver_user_id"`
+	ConductorUserID  *string `db:"conductor_user_id"`
+	OwnerUserID      *string `db:"owner_user_id"`
+}
+
+// CanBeContactedBy reports whether the user is the trip's driver, conductor or bus owner
+func (t *PassengerContactTarget) CanBeContactedBy(userID string) bool {
+	for _, allowed := range []*string{t.DriverUserID, t.ConductorUserID, t.OwnerUserID} {
+		if allowed != nil && *allowed == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// PassengerContactRequest asks to reach a masked passenger
+type PassengerContactRequest struct {
+	Reason string `json:"reason" binding:"required,min=3,max=200"` // e.g. "Passenger not at boarding stop"
+}
+
+// PassengerContact is how staff can reach a passenger: the number itself when revealed, or the
+// relay number and the reference to quote
+type PassengerContact struct {
+	SeatID           string    `json:"seat_id"`
+	BusBookingID     string    `json:"bus_booking_id"`
+	PassengerName    string    `json:"passenger_name"`
+	Access           string    `json:"access"`
+	PassengerPhone   string    `json:"passenger_phone,omitempty"` // Reveal only
+	RelayNumber      string    `json:"relay_number,omitempty"`    // Relay only
+	BookingReference string    `json:"booking_reference,omitempty"`
+	RequestsLeft     *int      `json:"requests_left,omitempty"` // For this trip, when capped
+	GrantedAt        time.Time `json:"granted_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskPhone(t *testing.T) {
+	assert.Equal(t, "*********567", MaskPhone("+94771234567", 3))
+	assert.Equal(t, "******4567", MaskPhone(" 0771234567 ", 4), "surrounding spaces are ignored")
+	assert.Equal(t, "***", MaskPhone("567", 3), "too short to show anything")
+	assert.Equal(t, "****", MaskPhone("1234", -1))
+	assert.Equal(t, "", MaskPhone("", 3))
+}
+
+func TestMaskBusBookingContacts(t *testing.T) {
+	phone, email, nic := "+94771234567", "a@example.com", "200012345678"
+	booking := &BusBooking{Seats: []BusBookingSeat{
+		{PassengerName: "Nimal", PassengerPhone: &phone, PassengerEmail: &email, PassengerNIC: &nic},
+		{PassengerName: "Kamala"},
+	}}
+
+	MaskBusBookingContacts(booking, 3)
+
+	assert.Equal(t, "*********567", *booking.Seats[0].PassengerPhone)
+	assert.Nil(t, booking.Seats[0].PassengerEmail)
+	assert.Nil(t, booking.Seats[0].PassengerNIC)
+	assert.Equal(t, "Nimal", booking.Seats[0].PassengerName)
+	assert.Nil(t, booking.Seats[1].PassengerPhone)
+	assert.Equal(t, "+94771234567", phone, "the caller's string is not modified")
+}
+
+func TestPassengerContactTarget_CanBeContactedBy(t *testing.T) {
+	driver, owner := "user-driver", "user-owner"
+	target := &PassengerContactTarget{DriverUserID: &driver, OwnerUserID: &owner}
+
+	assert.True(t, target.CanBeContactedBy("user-driver"))
+	assert.True(t, target.CanBeContactedBy("user-owner"))
+	assert.False(t, target.CanBeContactedBy("user-other"))
+	assert.False(t, target.CanBeContactedBy(""))
+}
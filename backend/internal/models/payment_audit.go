@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -128,9 +130,8 @@ func (pa *PaymentAudit) SetAmounts(expected, received float64, currency string)
 	pa.ReceivedAmount = &received
 	pa.Currency = &currency
 
-	// Compare with tolerance for floating point
-	const tolerance = 0.01
-	match := abs(expected-received) < tolerance
+	// Compare to the cent
+	match := NewMoney(expected).Equal(NewMoney(received))
 	pa.AmountsMatch = &match
 	return match
 }
@@ -209,10 +210,96 @@ func (pa *PaymentAudit) SetIdempotencyKey(key string) *PaymentAudit {
 	return pa
 }
 
-// abs returns absolute value of float64
-func abs(x float64) float64 {
-	if x < 0 {
-		return -x
+// PaymentAuditExportLimit caps the rows in a single CSV export
+const PaymentAuditExportLimit = 10000
+
+// PaymentAuditFilter narrows the payment audit entries finance searches through
+type PaymentAuditFilter struct {
+	PaymentStatus string           // Gateway payment status, e.g. "SUCCESS"
+	EventType     PaymentEventType // Empty means every event type
+	Gateway       string           // Payment gateway of the intent, e.g. "payable"
+	From          *time.Time       // created_at >= From
+	To            *time.Time       // created_at < To
+	MinAmount     *float64         // Received amount, falling back to the expected amount
+	MaxAmount     *float64
+	IntentID      *uuid.UUID
+	Reference     string // Payment reference, payment UID, gateway transaction ID or booking reference
+	Limit         int
+	Offset        int
+}
+
+// Validate checks that the ranges in the filter are not inverted
+func (f *PaymentAuditFilter) Validate() error {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return &ValidationError{Message: "from must be before to"}
+	}
+	if f.MinAmount != nil && *f.MinAmount < 0 {
+		return &ValidationError{Message: "min_amount cannot be negative"}
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MinAmount > *f.MaxAmount {
+		return &ValidationError{Message: "min_amount cannot exceed max_amount"}
+	}
+	return nil
+}
+
+// PaymentAuditCSVHeader is the header row of the finance CSV export
+var PaymentAuditCSVHeader = []string{
+	"created_at", "event_type", "event_source", "intent_id", "payment_reference", "payment_uid",
+	"gateway_transaction_id", "payment_status", "expected_amount", "received_amount", "currency",
+	"amounts_match", "http_status_code", "error_code", "error_message", "is_duplicate", "correlation_id",
+}
+
+// CSVRecord returns the entry as a row matching PaymentAuditCSVHeader; payloads are left out
+func (pa *PaymentAudit) CSVRecord() []string {
+	intentID := ""
+	if pa.IntentID != nil {
+		intentID = pa.IntentID.String()
+	}
+	return []string{
+		pa.CreatedAt.UTC().Format(time.RFC3339),
+		string(pa.EventType),
+		string(pa.EventSource),
+		intentID,
+		stringOrEmpty(pa.PaymentReference),
+		stringOrEmpty(pa.PaymentUID),
+		stringOrEmpty(pa.GatewayTransactionID),
+		stringOrEmpty(pa.PaymentStatus),
+		amountOrEmpty(pa.ExpectedAmount),
+		amountOrEmpty(pa.ReceivedAmount),
+		stringOrEmpty(pa.Currency),
+		boolOrEmpty(pa.AmountsMatch),
+		intOrEmpty(pa.HTTPStatusCode),
+		stringOrEmpty(pa.ErrorCode),
+		stringOrEmpty(pa.ErrorMessage),
+		strconv.FormatBool(pa.IsDuplicate),
+		stringOrEmpty(pa.CorrelationID),
+	}
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func amountOrEmpty(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
+
+func boolOrEmpty(v *bool) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatBool(*v)
+}
+
+func intOrEmpty(v *int) string {
+	if v == nil {
+		return ""
 	}
-	return x
+	return strconv.Itoa(*v)
 }
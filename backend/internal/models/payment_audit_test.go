@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentAuditFilter_Validate(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	low, high := 500.0, 2500.0
+
+	assert.NoError(t, (&PaymentAuditFilter{From: &from, To: &to, MinAmount: &low, MaxAmount: &high}).Validate())
+	assert.Error(t, (&PaymentAuditFilter{From: &to, To: &from}).Validate())
+	assert.Error(t, (&PaymentAuditFilter{MinAmount: &high, MaxAmount: &low}).Validate())
+}
+
+func TestPaymentAudit_CSVRecord(t *testing.T) {
+	intentID := uuid.MustParse("8f5b4c1e-2a47-4c9e-9d61-3e0f2b7a1c55")
+	audit := NewPaymentAudit(PaymentEventSuccess, PaymentSourcePayableAPI).
+		SetIntent(intentID).
+		SetPaymentReference("INV-1042").
+		SetPaymentStatus("SUCCESS")
+	audit.SetAmounts(2500, 2500, "LKR")
+	audit.CreatedAt = time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+	record := audit.CSVRecord()
+	assert.Len(t, record, len(PaymentAuditCSVHeader))
+	assert.Equal(t, "2026-03-14T09:30:00Z", record[0])
+	assert.Equal(t, intentID.String(), record[3])
+	assert.Equal(t, "INV-1042", record[4])
+	assert.Equal(t, "", record[5], "payment_uid not set")
+	assert.Equal(t, "2500.00", record[9])
+	assert.Equal(t, "true", record[11])
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentDisputeStatus tracks a chargeback from the gateway's notice to its outcome
+type PaymentDisputeStatus string
+
+const (
+	PaymentDisputeOpen              PaymentDisputeStatus = "open"               // Notice received, funds on hold
+	PaymentDisputeEvidenceSubmitted PaymentDisputeStatus = "evidence_submitted" // Evidence sent to the gateway, awaiting decision
+	PaymentDisputeWon               PaymentDisputeStatus = "won"                // Decided in our favour, funds released
+	PaymentDisputeLost              PaymentDisputeStatus = "lost"               // Amount returned to the cardholder
+)
+
+// PaymentDisputeSource is how a dispute entered the system. PAYable documents no signed
+// chargeback notification, so disputes are recorded by finance.
+type PaymentDisputeSource string
+
+const (
+	PaymentDisputeSourceManual PaymentDisputeSource = "manual"
+)
+
+// IsResolved reports whether the dispute has an outcome
+func (s PaymentDisputeStatus) IsResolved() bool {
+	return s == PaymentDisputeWon || s == PaymentDisputeLost
+}
+
+// CanTransitionTo reports whether a dispute may move from s to next.
+// Resolved disputes are final.
+func (s PaymentDisputeStatus) CanTransitionTo(next PaymentDisputeStatus) bool {
+	switch s {
+	case PaymentDisputeOpen:
+		return next == PaymentDisputeEvidenceSubmitted || next == PaymentDisputeWon || next == PaymentDisputeLost
+	case PaymentDisputeEvidenceSubmitted:
+		return next == PaymentDisputeWon || next == PaymentDisputeLost
+	default:
+		return false
+	}
+}
+
+// ParsePaymentDisputeStatus maps gateway statuses (e.g. "OPEN", "WON", "LOST") to ours
+func ParsePaymentDisputeStatus(value string) (PaymentDisputeStatus, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "open", "opened", "received":
+		return PaymentDisputeOpen, true
+	case "evidence_submitted", "under_review":
+		return PaymentDisputeEvidenceSubmitted, true
+	case "won", "reversed":
+		return PaymentDisputeWon, true
+	case "lost", "charged_back":
+		return PaymentDisputeLost, true
+	}
+	return "", false
+}
+
+// PaymentDispute is a chargeback against a payment, linked to the booking it paid for and,
+// through the trip, to the bus owner whose payout absorbs it
+type PaymentDispute struct {
+	ID               uuid.UUID            `json:"id" db:"id"`
+	GatewayDisputeID *string              `json:"gateway_dispute_id,omitempty" db:"gateway_dispute_id"`
+	PaymentUID       *string              `json:"payment_uid,omitempty" db:"payment_uid"`
+	PaymentReference *string              `json:"payment_reference,omitempty" db:"payment_reference"`
+	IntentID         *uuid.UUID           `json:"intent_id,omitempty" db:"intent_id"`
+	BookingID        *uuid.UUID           `json:"booking_id,omitempty" db:"booking_id"`
+	BookingReference *string              `json:"booking_reference,omitempty" db:"booking_reference"`
+	BusOwnerID       *uuid.UUID           `json:"bus_owner_id,omitempty" db:"bus_owner_id"`
+	PayoutID         *uuid.UUID           `json:"payout_id,omitempty" db:"payout_id"` // Payout the outcome was settled in
+	Amount           float64              `json:"amount" db:"amount"`
+	Currency         string               `json:"currency" db:"currency"`
+	Reason           *string              `json:"reason,omitempty" db:"reason"`
+	Status           PaymentDisputeStatus `json:"status" db:"status"`
+	Source           PaymentDisputeSource `json:"source" db:"source"`
+	RespondBy        *time.Time           `json:"respond_by,omitempty" db:"respond_by"` // Gateway deadline for evidence
+	ResolutionNote   *string              `json:"resolution_note,omitempty" db:"resolution_note"`
+	ResolvedAt       *time.Time           `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedByUserID  *uuid.UUID           `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	CreatedAt        time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at" db:"updated_at"`
+
+	Evidence        []PaymentDisputeEvidence `json:"evidence,omitempty" db:"-"`
+	BookingSnapshot *BookingSnapshot         `json:"booking_snapshot,omitempty" db:"-"` // What the cardholder bought, as it was at booking time
+}
+
+// PaymentDisputeEvidence is a document supporting our side of a dispute. Files are uploaded
+// to storage by the client; only the URL is kept here.
+type PaymentDisputeEvidence struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	DisputeID        uuid.UUID  `json:"dispute_id" db:"dispute_id"`
+	FileURL          string     `json:"file_url" db:"file_url"`
+	FileName         *string    `json:"file_name,omitempty" db:"file_name"`
+	Description      *string    `json:"description,omitempty" db:"description"`
+	UploadedByUserID *uuid.UUID `json:"uploaded_by_user_id,omitempty" db:"uploaded_by_user_id"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DisputeSettlementImpact is how a dispute affects the bus owner's payout: unresolved
+// disputes hold the amount back, lost disputes deduct it, won disputes release it
+func DisputeSettlementImpact(status PaymentDisputeStatus, amount float64) (held, deducted float64) {
+	switch status {
+	case PaymentDisputeOpen, PaymentDisputeEvidenceSubmitted:
+		return amount, 0
+	case PaymentDisputeLost:
+		return 0, amount
+	default:
+		return 0, 0
+	}
+}
+
+// DisputeSettlementSummary is the dispute adjustment to one bus owner's next payout
+type DisputeSettlementSummary struct {
+	BusOwnerID     uuid.UUID   `json:"bus_owner_id"`
+	HeldAmount     float64     `json:"held_amount"`     // Unresolved disputes that may still be deducted
+	DeductedAmount float64     `json:"deducted_amount"` // Lost disputes not yet settled in a payout
+	DisputeIDs     []uuid.UUID `json:"dispute_ids"`     // Lost disputes the deduction covers
+}
+
+// SummarizeDisputeSettlement totals the settlement impact of a bus owner's unsettled disputes
+func SummarizeDisputeSettlement(busOwnerID uuid.UUID, disputes []PaymentDispute) *DisputeSettlementSummary {
+	summary := &DisputeSettlementSummary{BusOwnerID: busOwnerID, DisputeIDs: []uuid.UUID{}}
+	for _, dispute := range disputes {
+		held, deducted := DisputeSettlementImpact(dispute.Status, dispute.Amount)
+		summary.HeldAmount += held
+		summary.DeductedAmount += deducted
+		if deducted > 0 {
+			summary.DisputeIDs = append(summary.DisputeIDs, dispute.ID)
+		}
+	}
+	summary.HeldAmount = RoundMoney(summary.HeldAmount)
+	summary.DeductedAmount = RoundMoney(summary.DeductedAmount)
+	return summary
+}
+
+// PaymentDisputeLink is what a payment identifier resolves to
+type PaymentDisputeLink struct {
+	IntentID         *uuid.UUID `db:"intent_id"`
+	PaymentUID       *string    `db:"payment_uid"`
+	PaymentReference *string    `db:"payment_reference"`
+	BookingID        *uuid.UUID `db:"booking_id"`
+	BookingReference *string    `db:"booking_reference"`
+	BusOwnerID       *uuid.UUID `db:"bus_owner_id"`
+}
+
+// PaymentDisputeFilter narrows the disputes listed for finance
+type PaymentDisputeFilter struct {
+	Status     PaymentDisputeStatus
+	BusOwnerID *uuid.UUID
+	Limit      int
+	Offset     int
+}
+
+// CreatePaymentDisputeRequest records a dispute by hand (e.g. from a gateway email).
+// At least one of payment_uid, payment_reference or booking_reference identifies the payment.
+type CreatePaymentDisputeRequest struct {
+	GatewayDisputeID string     `json:"gateway_dispute_id"`
+	PaymentUID       string     `json:"payment_uid"`
+	PaymentReference string     `json:"payment_reference"`
+	BookingReference string     `json:"booking_reference"`
+	Amount           float64    `json:"amount" binding:"required,gt=0"`
+	Currency         string     `json:"currency"`
+	Reason           string     `json:"reason"`
+	RespondBy        *time.Time `json:"respond_by,omitempty"`
+}
+
+// Validate checks the request identifies a payment
+func (r *CreatePaymentDisputeRequest) Validate() error {
+	if strings.TrimSpace(r.PaymentUID) == "" && strings.TrimSpace(r.PaymentReference) == "" && strings.TrimSpace(r.BookingReference) == "" {
+		return &ValidationError{Message: "one of payment_uid, payment_reference or booking_reference is required"}
+	}
+	if r.Amount <= 0 {
+		return &ValidationError{Message: "amount must be greater than 0"}
+	}
+	return nil
+}
+
+// UpdatePaymentDisputeStatusRequest moves a dispute along its workflow
+type UpdatePaymentDisputeStatusRequest struct {
+	Status PaymentDisputeStatus `json:"status" binding:"required,oneof=evidence_submitted won lost"`
+	Note   string               `json:"note"`
+}
+
+// AddPaymentDisputeEvidenceRequest attaches an uploaded document to a dispute
+type AddPaymentDisputeEvidenceRequest struct {
+	FileURL     string `json:"file_url" binding:"required,url"`
+	FileName    string `json:"file_name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentDisputeStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, PaymentDisputeOpen.CanTransitionTo(PaymentDisputeEvidenceSubmitted))
+	assert.True(t, PaymentDisputeOpen.CanTransitionTo(PaymentDisputeLost))
+	assert.True(t, PaymentDisputeEvidenceSubmitted.CanTransitionTo(PaymentDisputeWon))
+	assert.False(t, PaymentDisputeEvidenceSubmitted.CanTransitionTo(PaymentDisputeOpen))
+	assert.False(t, PaymentDisputeWon.CanTransitionTo(PaymentDisputeLost), "resolved disputes are final")
+}
+
+func TestParsePaymentDisputeStatus(t *testing.T) {
+	status, ok := ParsePaymentDisputeStatus("LOST")
+	assert.True(t, ok)
+	assert.Equal(t, PaymentDisputeLost, status)
+
+	status, ok = ParsePaymentDisputeStatus("")
+	assert.True(t, ok)
+	assert.Equal(t, PaymentDisputeOpen, status)
+
+	_, ok = ParsePaymentDisputeStatus("pending_arbitration")
+	assert.False(t, ok)
+}
+
+func TestSummarizeDisputeSettlement(t *testing.T) {
+	ownerID := uuid.New()
+	lost := PaymentDispute{ID: uuid.New(), Status: PaymentDisputeLost, Amount: 1200}
+	disputes := []PaymentDispute{
+		{ID: uuid.New(), Status: PaymentDisputeOpen, Amount: 800.5},
+		{ID: uuid.New(), Status: PaymentDisputeEvidenceSubmitted, Amount: 300},
+		lost,
+		{ID: uuid.New(), Status: PaymentDisputeWon, Amount: 5000},
+	}
+
+	summary := SummarizeDisputeSettlement(ownerID, disputes)
+	assert.Equal(t, 1100.5, summary.HeldAmount)
+	assert.Equal(t, 1200.0, summary.DeductedAmount)
+	assert.Equal(t, []uuid.UUID{lost.ID}, summary.DisputeIDs)
+}
+
+func TestCreatePaymentDisputeRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&CreatePaymentDisputeRequest{BookingReference: "BK-20260314-0042", Amount: 1500}).Validate())
+	assert.Error(t, (&CreatePaymentDisputeRequest{Amount: 1500}).Validate(), "no payment identifier")
+	assert.Error(t, (&CreatePaymentDisputeRequest{PaymentUID: "uid-1"}).Validate(), "no amount")
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentWebhookOutcome records what became of a payment notification
+type PaymentWebhookOutcome string
+
+const (
+	PaymentWebhookReceived  PaymentWebhookOutcome = "received"  // Accepted and being processed
+	PaymentWebhookDuplicate PaymentWebhookOutcome = "duplicate" // Same UID and status already delivered
+	PaymentWebhookProcessed PaymentWebhookOutcome = "processed" // Handled, including payments that did not succeed
+	PaymentWebhookFailed    PaymentWebhookOutcome = "failed"    // Could not be handled; a redelivery is processed again
+)
+
+// PaymentWebhookEvent is one delivery of a PAYable payment notification. Every delivery is
+// kept, including duplicate ones, for replay investigations.
+type PaymentWebhookEvent struct {
+	ID              uuid.UUID             `json:"id" db:"id"`
+	PaymentUID      string                `json:"payment_uid" db:"payment_uid"`
+	PaymentStatus   string                `json:"payment_status" db:"payment_status"` // As notified; empty when the body has none
+	StatusIndicator *string               `json:"status_indicator,omitempty" db:"status_indicator"`
+	InvoiceID       *string               `json:"invoice_id,omitempty" db:"invoice_id"`
+	Outcome         PaymentWebhookOutcome `json:"outcome" db:"outcome"`
+	DuplicateOf     *uuid.UUID            `json:"duplicate_of,omitempty" db:"duplicate_of"` // The delivery this one repeats
+	Note            *string               `json:"note,omitempty" db:"note"`
+	RawBody         *string               `json:"raw_body,omitempty" db:"raw_body"`
+	ClientIP        *string               `json:"client_ip,omitempty" db:"client_ip"`
+	CorrelationID   *string               `json:"correlation_id,omitempty" db:"correlation_id"`
+	ReceivedAt      time.Time             `json:"received_at" db:"received_at"`
+	ProcessedAt     *time.Time            `json:"processed_at,omitempty" db:"processed_at"`
+}
+
+// NewPaymentWebhookEvent records a notification delivery as received
+func NewPaymentWebhookEvent(paymentUID, paymentStatus string) *PaymentWebhookEvent {
+	return &PaymentWebhookEvent{
+		ID:            uuid.New(),
+		PaymentUID:    paymentUID,
+		PaymentStatus: paymentStatus,
+		Outcome:       PaymentWebhookReceived,
+		ReceivedAt:    time.Now(),
+	}
+}
+
+// IsDuplicate reports whether the delivery repeats one already accepted
+func (e *PaymentWebhookEvent) IsDuplicate() bool {
+	return e.DuplicateOf != nil
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProfileStep is one onboarding step of a passenger profile
+type ProfileStep string
+
+const (
+	ProfileStepName             ProfileStep = "name"
+	ProfileStepEmergencyContact ProfileStep = "emergency_contact"
+	ProfileStepEmail            ProfileStep = "email"
+	ProfileStepNIC              ProfileStep = "nic"
+)
+
+// ProfileStage is the passenger's position in onboarding, derived from completed steps
+type ProfileStage string
+
+const (
+	ProfileStageNew      ProfileStage = "new"      // Name not provided yet
+	ProfileStageBasic    ProfileStage = "basic"    // Name provided
+	ProfileStageSafety   ProfileStage = "safety"   // Name and emergency contact provided
+	ProfileStageComplete ProfileStage = "complete" // All steps done
+)
+
+// ProfileFeature is a passenger feature that can require profile steps
+type ProfileFeature string
+
+const (
+	ProfileFeatureLoungeBooking ProfileFeature = "lounge_booking"
+)
+
+// profileStepDefinition describes a step, in the order passengers are nudged through them
+type profileStepDefinition struct {
+	step     ProfileStep
+	weight   int // Share of the 100-point completeness score
+	title    string
+	message  string
+	endpoint string // Where the app sends the data for this step
+}
+
+var profileSteps = []profileStepDefinition{
+	{ProfileStepName, 40, "Add your name", "Your name appears on tickets and is required for lounge bookings.", "POST /api/v1/user/complete-basic-profile"},
+	{ProfileStepEmergencyContact, 20, "Add an emergency contact", "Someone we can reach if something happens during your trip.", "PATCH /api/v1/user/profile"},
+	{ProfileStepEmail, 20, "Add your email", "Get e-tickets and receipts by email.", "PATCH /api/v1/user/profile"},
+	{ProfileStepNIC, 20, "Add your NIC", "Speeds up identity checks at boarding and lounges.", "PATCH /api/v1/user/profile"},
+}
+
+// profileFeatureRequirements lists the steps each gated feature needs
+var profileFeatureRequirements = map[ProfileFeature][]ProfileStep{
+	ProfileFeatureLoungeBooking: {ProfileStepName},
+}
+
+// ProfileStepStatus reports whether a single step is done
+type ProfileStepStatus struct {
+	Step      ProfileStep `json:"step"`
+	Completed bool        `json:"completed"`
+	Weight    int         `json:"weight"`
+}
+
+// ProfileStepHint tells the app what to ask the passenger for next
+type ProfileStepHint struct {
+	Step     ProfileStep `json:"step"`
+	Title    string      `json:"title"`
+	Message  string      `json:"message"`
+	Endpoint string      `json:"endpoint"`
+}
+
+// ProfileCompleteness summarises onboarding progress for /user/profile
+type ProfileCompleteness struct {
+	Score    int                 `json:"score"` // 0-100
+	Stage    ProfileStage        `json:"stage"`
+	Steps    []ProfileStepStatus `json:"steps"`
+	NextStep *ProfileStepHint    `json:"next_step,omitempty"`
+}
+
+// ProfileIncompleteError is returned when a feature needs profile steps the passenger has not done
+type ProfileIncompleteError struct {
+	Feature ProfileFeature
+	Missing []ProfileStep
+}
+
+func (e *ProfileIncompleteError) Error() string {
+	steps := make([]string, len(e.Missing))
+	for i, step := range e.Missing {
+		steps[i] = string(step)
+	}
+	return fmt.Sprintf("%s requires profile steps: %s", e.Feature, strings.Join(steps, ", "))
+}
+
+// Code is the API error code for the first missing step (e.g. PROFILE_NAME_REQUIRED)
+func (e *ProfileIncompleteError) Code() string {
+	if len(e.Missing) == 0 {
+		return "PROFILE_INCOMPLETE"
+	}
+	return "PROFILE_" + strings.ToUpper(string(e.Missing[0])) + "_REQUIRED"
+}
+
+// Hint returns the nudge for the first missing step
+func (e *ProfileIncompleteError) Hint() *ProfileStepHint {
+	if len(e.Missing) == 0 {
+		return nil
+	}
+	for _, def := range profileSteps {
+		if def.step == e.Missing[0] {
+			return def.hint()
+		}
+	}
+	return nil
+}
+
+func (d profileStepDefinition) hint() *ProfileStepHint {
+	return &ProfileStepHint{Step: d.step, Title: d.title, Message: d.message, Endpoint: d.endpoint}
+}
+
+// HasCompletedStep reports whether the passenger has provided the data for a step.
+// A nil passenger (no passengers row yet) has completed nothing.
+func (p *Passenger) HasCompletedStep(step ProfileStep) bool {
+	if p == nil {
+		return false
+	}
+	switch step {
+	case ProfileStepName:
+		return nonBlank(p.FirstName) && nonBlank(p.LastName)
+	case ProfileStepEmergencyContact:
+		return nonBlank(p.EmergencyContactName) && nonBlank(p.EmergencyContactPhone)
+	case ProfileStepEmail:
+		return nonBlank(p.Email)
+	case ProfileStepNIC:
+		return nonBlank(p.NIC)
+	}
+	return false
+}
+
+// Completeness scores the passenger's profile and picks the next step to nudge
+func (p *Passenger) Completeness() ProfileCompleteness {
+	result := ProfileCompleteness{Steps: make([]ProfileStepStatus, 0, len(profileSteps))}
+	for _, def := range profileSteps {
+		done := p.HasCompletedStep(def.step)
+		result.Steps = append(result.Steps, ProfileStepStatus{Step: def.step, Completed: done, Weight: def.weight})
+		if done {
+			result.Score += def.weight
+		} else if result.NextStep == nil {
+			result.NextStep = def.hint()
+		}
+	}
+
+	switch {
+	case result.NextStep == nil:
+		result.Stage = ProfileStageComplete
+	case !p.HasCompletedStep(ProfileStepName):
+		result.Stage = ProfileStageNew
+	case !p.HasCompletedStep(ProfileStepEmergencyContact):
+		result.Stage = ProfileStageBasic
+	default:
+		result.Stage = ProfileStageSafety
+	}
+	return result
+}
+
+// CheckFeatureAccess returns *ProfileIncompleteError if the feature needs steps the passenger has not done
+func (p *Passenger) CheckFeatureAccess(feature ProfileFeature) error {
+	var missing []ProfileStep
+	for _, step := range profileFeatureRequirements[feature] {
+		if !p.HasCompletedStep(step) {
+			missing = append(missing, step)
+		}
+	}
+	if len(missing) > 0 {
+		return &ProfileIncompleteError{Feature: feature, Missing: missing}
+	}
+	return nil
+}
+
+func nonBlank(s NullString) bool {
+	return s.Valid && strings.TrimSpace(s.String) != ""
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validString(s string) NullString {
+	return NullString{NullString: sql.NullString{String: s, Valid: true}}
+}
+
+func TestCompleteness_NilPassengerStartsAtName(t *testing.T) {
+	var passenger *Passenger
+	result := passenger.Completeness()
+
+	assert.Equal(t, 0, result.Score)
+	assert.Equal(t, ProfileStageNew, result.Stage)
+	require.NotNil(t, result.NextStep)
+	assert.Equal(t, ProfileStepName, result.NextStep.Step)
+	assert.Len(t, result.Steps, 4)
+}
+
+func TestCompleteness_ProgressesThroughStages(t *testing.T) {
+	passenger := &Passenger{FirstName: validString("Nimal"), LastName: validString("Perera")}
+	result := passenger.Completeness()
+	assert.Equal(t, 40, result.Score)
+	assert.Equal(t, ProfileStageBasic, result.Stage)
+	assert.Equal(t, ProfileStepEmergencyContact, result.NextStep.Step)
+
+	passenger.EmergencyContactName = validString("Kamala")
+	passenger.EmergencyContactPhone = validString("+94771234567")
+	result = passenger.Completeness()
+	assert.Equal(t, 60, result.Score)
+	assert.Equal(t, ProfileStageSafety, result.Stage)
+	assert.Equal(t, ProfileStepEmail, result.NextStep.Step)
+
+	passenger.Email = validString("nimal@example.com")
+	passenger.NIC = validString("199012345678")
+	result = passenger.Completeness()
+	assert.Equal(t, 100, result.Score)
+	assert.Equal(t, ProfileStageComplete, result.Stage)
+	assert.Nil(t, result.NextStep)
+}
+
+func TestCompleteness_BlankValuesDoNotCount(t *testing.T) {
+	passenger := &Passenger{FirstName: validString("  "), LastName: validString("Perera"), Email: validString("a@b.lk")}
+	result := passenger.Completeness()
+
+	assert.Equal(t, 20, result.Score)
+	assert.Equal(t, ProfileStageNew, result.Stage)
+	assert.Equal(t, ProfileStepName, result.NextStep.Step)
+}
+
+func TestCheckFeatureAccess_LoungeBookingRequiresName(t *testing.T) {
+	var incomplete *ProfileIncompleteError
+
+	err := (&Passenger{}).CheckFeatureAccess(ProfileFeatureLoungeBooking)
+	require.ErrorAs(t, err, &incomplete)
+	assert.Equal(t, "PROFILE_NAME_REQUIRED", incomplete.Code())
+	assert.Equal(t, []ProfileStep{ProfileStepName}, incomplete.Missing)
+	assert.Equal(t, ProfileStepName, incomplete.Hint().Step)
+
+	passenger := &Passenger{FirstName: validString("Nimal"), LastName: validString("Perera")}
+	assert.NoError(t, passenger.CheckFeatureAccess(ProfileFeatureLoungeBooking))
+}
@@ -0,0 +1,267 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PromoDiscountType is how a promo code's discount is worked out
+type PromoDiscountType string
+
+const (
+	PromoDiscountPercentage PromoDiscountType = "percentage" // discount_value percent of the eligible amount
+	PromoDiscountFixed      PromoDiscountType = "fixed"      // discount_value LKR, up to the eligible amount
+)
+
+// PromoProductType is a kind of purchase a promo code can be applied to
+type PromoProductType string
+
+const (
+	PromoProductBus    PromoProductType = "bus"    // Bus seats
+	PromoProductLounge PromoProductType = "lounge" // Lounge stays, including their pre-orders
+)
+
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// PromoCode is a platform promotion passengers enter when booking. The platform funds the
+// discount, so owners are settled on the undiscounted price.
+type PromoCode struct {
+	ID                string            `json:"id" db:"id"`
+	Code              string            `json:"code" db:"code"`
+	Description       *string           `json:"description,omitempty" db:"description"`
+	DiscountType      PromoDiscountType `json:"discount_type" db:"discount_type"`
+	DiscountValue     float64           `json:"discount_value" db:"discount_value"`
+	MaxDiscountAmount *float64          `json:"max_discount_amount,omitempty" db:"max_discount_amount"` // LKR per booking, nil = no cap
+	MinOrderAmount    *float64          `json:"min_order_amount,omitempty" db:"min_order_amount"`       // Minimum eligible amount
+	AppliesTo         pq.StringArray    `json:"applies_to" db:"applies_to"`
+	ValidFrom         time.Time         `json:"valid_from" db:"valid_from"`
+	ValidUntil        time.Time         `json:"valid_until" db:"valid_until"`
+	MaxUsageCount     *int              `json:"max_usage_count,omitempty" db:"max_usage_count"`     // nil = unlimited
+	MaxUsesPerUser    *int              `json:"max_uses_per_user,omitempty" db:"max_uses_per_user"` // nil = unlimited
+	CurrentUsageCount int               `json:"current_usage_count" db:"current_usage_count"`
+	IsActive          bool              `json:"is_active" db:"is_active"`
+	CreatedByUserID   *string           `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// NormalizePromoCode upper-cases and trims a code the way it is stored
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// AppliesToProduct reports whether the code discounts the product type
+func (p *PromoCode) AppliesToProduct(productType PromoProductType) bool {
+	for _, t := range p.AppliesTo {
+		if PromoProductType(t) == productType {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckUsable returns why the code cannot be used at now by a user who has redeemed it
+// userUses times, or nil if it can
+func (p *PromoCode) CheckUsable(now time.Time, userUses int) error {
+	switch {
+	case !p.IsActive:
+		return &ValidationError{Message: "promo code is no longer active"}
+	case now.Before(p.ValidFrom):
+		return &ValidationError{Message: "promo code is not valid yet"}
+	case !now.Before(p.ValidUntil):
+		return &ValidationError{Message: "promo code has expired"}
+	case p.MaxUsageCount != nil && p.CurrentUsageCount >= *p.MaxUsageCount:
+		return &ValidationError{Message: "promo code has reached its usage limit"}
+	case p.MaxUsesPerUser != nil && userUses >= *p.MaxUsesPerUser:
+		return &ValidationError{Message: "you have already used this promo code"}
+	}
+	return nil
+}
+
+// PromoLine is one priced part of a booking a promo code may discount
+type PromoLine struct {
+	ProductType PromoProductType `json:"product_type"`
+	Amount      float64          `json:"amount"`
+	Discount    float64          `json:"discount"`
+}
+
+// PromoQuote is a promo code's discount on a booking, split across its lines
+type PromoQuote struct {
+	PromoCodeID    string            `json:"promo_code_id"`
+	Code           string            `json:"code"`
+	DiscountType   PromoDiscountType `json:"discount_type"`
+	DiscountValue  float64           `json:"discount_value"`
+	EligibleAmount float64           `json:"eligible_amount"`
+	DiscountAmount float64           `json:"discount_amount"`
+	Lines          []PromoLine       `json:"lines"`
+}
+
+// Quote works out the discount on the lines the code applies to. The total is capped at the
+// code's maximum and the eligible amount, then split across the eligible lines in proportion to
+// their amounts, with rounding left on the last one.
+func (p *PromoCode) Quote(lines []PromoLine) (*PromoQuote, error) {
+	quote := &PromoQuote{
+		PromoCodeID:   p.ID,
+		Code:          p.Code,
+		DiscountType:  p.DiscountType,
+		DiscountValue: p.DiscountValue,
+		Lines:         make([]PromoLine, len(lines)),
+	}
+	last := -1
+	for i, line := range lines {
+		line.Discount = 0
+		quote.Lines[i] = line
+		if line.Amount > 0 && p.AppliesToProduct(line.ProductType) {
+			quote.EligibleAmount += line.Amount
+			last = i
+		}
+	}
+	quote.EligibleAmount = RoundMoney(quote.EligibleAmount)
+	if last < 0 {
+		return nil, &ValidationError{Message: "promo code does not apply to this booking"}
+	}
+	if p.MinOrderAmount != nil && quote.EligibleAmount < *p.MinOrderAmount {
+		return nil, &ValidationError{Message: fmt.Sprintf("promo code needs a minimum spend of LKR %.2f", *p.MinOrderAmount)}
+	}
+
+	amount := p.DiscountValue
+	if p.DiscountType == PromoDiscountPercentage {
+		amount = quote.EligibleAmount * p.DiscountValue / 100
+	}
+	if p.MaxDiscountAmount != nil && amount > *p.MaxDiscountAmount {
+		amount = *p.MaxDiscountAmount
+	}
+	amount = math.Min(RoundMoney(amount), quote.EligibleAmount)
+	quote.DiscountAmount = amount
+
+	remaining := amount
+	for i := range quote.Lines {
+		line := &quote.Lines[i]
+		if line.Amount <= 0 || !p.AppliesToProduct(line.ProductType) {
+			continue
+		}
+		if i == last {
+			line.Discount = RoundMoney(remaining)
+			break
+		}
+		line.Discount = RoundMoney(amount * line.Amount / quote.EligibleAmount)
+		remaining -= line.Discount
+	}
+	return quote, nil
+}
+
+// PromoCodeRedemption records a promo code used on a booking. The use is reserved, without its
+// booking, when the intent or lounge booking is made, and its booking is added once confirmed.
+type PromoCodeRedemption struct {
+	ID              string  `db:"id"`
+	PromoCodeID     string  `db:"promo_code_id"`
+	UserID          string  `db:"user_id"`
+	IntentID        *string `db:"intent_id"`
+	MasterBookingID *string `db:"master_booking_id"`
+	LoungeBookingID *string `db:"lounge_booking_id"`
+	DiscountAmount  float64 `db:"discount_amount"`
+}
+
+// SavePromoCodeRequest creates or replaces a promo code
+type SavePromoCodeRequest struct {
+	Code              string             `json:"code" binding:"required"`
+	Description       *string            `json:"description,omitempty"`
+	DiscountType      PromoDiscountType  `json:"discount_type" binding:"required"`
+	DiscountValue     float64            `json:"discount_value" binding:"required"`
+	MaxDiscountAmount *float64           `json:"max_discount_amount,omitempty"`
+	MinOrderAmount    *float64           `json:"min_order_amount,omitempty"`
+	AppliesTo         []PromoProductType `json:"applies_to" binding:"required"`
+	ValidFrom         time.Time          `json:"valid_from" binding:"required"`
+	ValidUntil        time.Time          `json:"valid_until" binding:"required"`
+	MaxUsageCount     *int               `json:"max_usage_count,omitempty"`
+	MaxUsesPerUser    *int               `json:"max_uses_per_user,omitempty"`
+	IsActive          *bool              `json:"is_active,omitempty"` // defaults to true
+}
+
+// Validate normalizes the code and checks the discount, limits and validity window
+func (r *SavePromoCodeRequest) Validate() error {
+	r.Code = NormalizePromoCode(r.Code)
+	if !promoCodePattern.MatchString(r.Code) {
+		return &ValidationError{Message: "code must be 3-32 letters, digits, '-' or '_'"}
+	}
+	switch r.DiscountType {
+	case PromoDiscountPercentage:
+		if r.DiscountValue <= 0 || r.DiscountValue > 100 {
+			return &ValidationError{Message: "percentage discount_value must be greater than 0 and at most 100"}
+		}
+	case PromoDiscountFixed:
+		if r.DiscountValue <= 0 {
+			return &ValidationError{Message: "fixed discount_value must be positive"}
+		}
+	default:
+		return &ValidationError{Message: "discount_type must be percentage or fixed"}
+	}
+	if r.MaxDiscountAmount != nil && *r.MaxDiscountAmount <= 0 {
+		return &ValidationError{Message: "max_discount_amount must be positive"}
+	}
+	if r.MinOrderAmount != nil && *r.MinOrderAmount < 0 {
+		return &ValidationError{Message: "min_order_amount cannot be negative"}
+	}
+	if len(r.AppliesTo) == 0 {
+		return &ValidationError{Message: "applies_to needs at least one of bus, lounge"}
+	}
+	seen := make(map[PromoProductType]bool, len(r.AppliesTo))
+	for _, t := range r.AppliesTo {
+		if t != PromoProductBus && t != PromoProductLounge {
+			return &ValidationError{Message: "applies_to may only contain bus and lounge"}
+		}
+		if seen[t] {
+			return &ValidationError{Message: "applies_to lists " + string(t) + " more than once"}
+		}
+		seen[t] = true
+	}
+	if !r.ValidUntil.After(r.ValidFrom) {
+		return &ValidationError{Message: "valid_until must be after valid_from"}
+	}
+	if r.MaxUsageCount != nil && *r.MaxUsageCount < 1 {
+		return &ValidationError{Message: "max_usage_count must be at least 1"}
+	}
+	if r.MaxUsesPerUser != nil && *r.MaxUsesPerUser < 1 {
+		return &ValidationError{Message: "max_uses_per_user must be at least 1"}
+	}
+	return nil
+}
+
+// ApplyTo copies the request onto a promo code
+func (r *SavePromoCodeRequest) ApplyTo(p *PromoCode) {
+	p.Code = r.Code
+	p.Description = r.Description
+	p.DiscountType = r.DiscountType
+	p.DiscountValue = r.DiscountValue
+	p.MaxDiscountAmount = r.MaxDiscountAmount
+	p.MinOrderAmount = r.MinOrderAmount
+	p.AppliesTo = make(pq.StringArray, len(r.AppliesTo))
+	for i, t := range r.AppliesTo {
+		p.AppliesTo[i] = string(t)
+	}
+	p.ValidFrom = r.ValidFrom
+	p.ValidUntil = r.ValidUntil
+	p.MaxUsageCount = r.MaxUsageCount
+	p.MaxUsesPerUser = r.MaxUsesPerUser
+	p.IsActive = r.IsActive == nil || *r.IsActive
+}
+
+// ValidatePromoCodeRequest asks what a code takes off a booking before it is made
+type ValidatePromoCodeRequest struct {
+	Code         string  `json:"code" binding:"required"`
+	BusAmount    float64 `json:"bus_amount"`
+	LoungeAmount float64 `json:"lounge_amount"`
+}
+
+// Lines returns the request's amounts as promo lines
+func (r *ValidatePromoCodeRequest) Lines() []PromoLine {
+	return []PromoLine{
+		{ProductType: PromoProductBus, Amount: r.BusAmount},
+		{ProductType: PromoProductLounge, Amount: r.LoungeAmount},
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromoCode_Quote(t *testing.T) {
+	lines := []PromoLine{
+		{ProductType: PromoProductBus, Amount: 1500},
+		{ProductType: PromoProductLounge, Amount: 1000},
+		{ProductType: PromoProductLounge, Amount: 0},
+	}
+
+	promo := &PromoCode{ID: "promo-1", Code: "AVURUDU10", DiscountType: PromoDiscountPercentage, DiscountValue: 10, AppliesTo: pq.StringArray{"bus", "lounge"}}
+	quote, err := promo.Quote(lines)
+	require.NoError(t, err)
+	assert.Equal(t, 2500.0, quote.EligibleAmount)
+	assert.Equal(t, 250.0, quote.DiscountAmount)
+	assert.Equal(t, 150.0, quote.Lines[0].Discount)
+	assert.Equal(t, 100.0, quote.Lines[1].Discount)
+	assert.Zero(t, quote.Lines[2].Discount)
+
+	loungeOnly := &PromoCode{DiscountType: PromoDiscountFixed, DiscountValue: 300, AppliesTo: pq.StringArray{"lounge"}}
+	quote, err = loungeOnly.Quote(lines)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, quote.DiscountAmount)
+	assert.Zero(t, quote.Lines[0].Discount, "bus fare is not eligible")
+	assert.Equal(t, 300.0, quote.Lines[1].Discount)
+
+	capped := &PromoCode{DiscountType: PromoDiscountPercentage, DiscountValue: 50, MaxDiscountAmount: ptrFloat(200), AppliesTo: pq.StringArray{"bus", "lounge"}}
+	quote, err = capped.Quote([]PromoLine{{ProductType: PromoProductBus, Amount: 100}, {ProductType: PromoProductLounge, Amount: 200}})
+	require.NoError(t, err)
+	assert.Equal(t, 150.0, quote.DiscountAmount)
+	assert.Equal(t, 50.0, quote.Lines[0].Discount)
+	assert.Equal(t, 100.0, quote.Lines[1].Discount)
+
+	big := &PromoCode{DiscountType: PromoDiscountFixed, DiscountValue: 5000, AppliesTo: pq.StringArray{"bus"}}
+	quote, err = big.Quote(lines)
+	require.NoError(t, err)
+	assert.Equal(t, 1500.0, quote.DiscountAmount, "never more than the eligible amount")
+
+	thirds := &PromoCode{DiscountType: PromoDiscountFixed, DiscountValue: 100, AppliesTo: pq.StringArray{"bus", "lounge"}}
+	quote, err = thirds.Quote([]PromoLine{{ProductType: PromoProductBus, Amount: 100}, {ProductType: PromoProductLounge, Amount: 100}, {ProductType: PromoProductLounge, Amount: 100}})
+	require.NoError(t, err)
+	assert.Equal(t, 33.33, quote.Lines[0].Discount)
+	assert.Equal(t, 33.33, quote.Lines[1].Discount)
+	assert.Equal(t, 33.34, quote.Lines[2].Discount, "rounding is left on the last line")
+
+	_, err = loungeOnly.Quote([]PromoLine{{ProductType: PromoProductBus, Amount: 1500}})
+	assert.Error(t, err, "nothing eligible")
+
+	minimum := &PromoCode{DiscountType: PromoDiscountFixed, DiscountValue: 100, MinOrderAmount: ptrFloat(3000), AppliesTo: pq.StringArray{"bus", "lounge"}}
+	_, err = minimum.Quote(lines)
+	assert.Error(t, err, "below the minimum spend")
+}
+
+func TestPromoCode_CheckUsable(t *testing.T) {
+	now := time.Date(2026, 4, 14, 10, 0, 0, 0, ReportTimezone)
+	limit := func(n int) *int { return &n }
+	valid := PromoCode{IsActive: true, ValidFrom: now.AddDate(0, 0, -1), ValidUntil: now.AddDate(0, 0, 1), MaxUsageCount: limit(100), CurrentUsageCount: 99, MaxUsesPerUser: limit(1)}
+	assert.NoError(t, valid.CheckUsable(now, 0))
+
+	inactive := valid
+	inactive.IsActive = false
+	early := valid
+	early.ValidFrom = now.Add(time.Hour)
+	expired := valid
+	expired.ValidUntil = now
+	usedUp := valid
+	usedUp.CurrentUsageCount = 100
+
+	for name, promo := range map[string]PromoCode{"inactive": inactive, "not started": early, "expired": expired, "used up": usedUp} {
+		assert.Error(t, promo.CheckUsable(now, 0), name)
+	}
+	assert.Error(t, valid.CheckUsable(now, 1), "per-user limit")
+}
+
+func TestSavePromoCodeRequest_Validate(t *testing.T) {
+	from := time.Date(2026, 4, 1, 0, 0, 0, 0, ReportTimezone)
+	valid := func() *SavePromoCodeRequest {
+		return &SavePromoCodeRequest{Code: " avurudu10 ", DiscountType: PromoDiscountPercentage, DiscountValue: 10, AppliesTo: []PromoProductType{PromoProductBus}, ValidFrom: from, ValidUntil: from.AddDate(0, 1, 0)}
+	}
+
+	req := valid()
+	require.NoError(t, req.Validate())
+	assert.Equal(t, "AVURUDU10", req.Code)
+
+	promo := &PromoCode{}
+	req.ApplyTo(promo)
+	assert.True(t, promo.IsActive, "codes are active unless set otherwise")
+	assert.Equal(t, pq.StringArray{"bus"}, promo.AppliesTo)
+
+	for name, mutate := range map[string]func(*SavePromoCodeRequest){
+		"bad code":           func(r *SavePromoCodeRequest) { r.Code = "10% OFF" },
+		"percentage too big": func(r *SavePromoCodeRequest) { r.DiscountValue = 150 },
+		"unknown type":       func(r *SavePromoCodeRequest) { r.DiscountType = "bogo" },
+		"no products":        func(r *SavePromoCodeRequest) { r.AppliesTo = nil },
+		"unknown product":    func(r *SavePromoCodeRequest) { r.AppliesTo = []PromoProductType{"parcel"} },
+		"repeated product":   func(r *SavePromoCodeRequest) { r.AppliesTo = []PromoProductType{PromoProductBus, PromoProductBus} },
+		"inverted window":    func(r *SavePromoCodeRequest) { r.ValidUntil = r.ValidFrom },
+		"zero usage limit":   func(r *SavePromoCodeRequest) { zero := 0; r.MaxUsageCount = &zero },
+	} {
+		req := valid()
+		mutate(req)
+		assert.Error(t, req.Validate(), name)
+	}
+}
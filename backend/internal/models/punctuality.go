@@ -0,0 +1,50 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// PunctualityRules decide which trips count as on time and how much history is used.
+// A trip is on time when it left no more than DepartureGraceMinutes late and, if it has an
+// estimated duration, arrived no more than ArrivalGraceMinutes after the scheduled arrival.
+type PunctualityRules struct {
+	WindowDays            int
+	DepartureGraceMinutes int
+	ArrivalGraceMinutes   int
+	MinTrips              int // Schedules with fewer measured trips get no percentage
+}
+
+// OnTimePercentage returns onTime/measured as a percentage rounded to one decimal, or nil when
+// fewer than minTrips were measured
+func OnTimePercentage(onTime, measured, minTrips int) *float64 {
+	if measured <= 0 || measured < minTrips {
+		return nil
+	}
+	pct := math.Round(float64(onTime)/float64(measured)*1000) / 10
+	return &pct
+}
+
+// OperatorOnTimePercentage combines an operator's schedules into one figure, weighted by trips
+// measured. Schedules without a published percentage are left out.
+func OperatorOnTimePercentage(entries []PublishedTimetableEntry) *float64 {
+	onTime, measured := 0, 0
+	for _, entry := range entries {
+		if entry.OnTimePercentage == nil {
+			continue
+		}
+		onTime += entry.OnTimeTrips
+		measured += entry.TripsMeasured
+	}
+	return OnTimePercentage(onTime, measured, 1)
+}
+
+// NextNightlyRun returns the next time after `after` that falls on hour:00 local (Asia/Colombo) time
+func NextNightlyRun(after time.Time, hour int) time.Time {
+	local := after.In(ReportTimezone)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, ReportTimezone)
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnTimePercentage(t *testing.T) {
+	pct := OnTimePercentage(17, 20, 5)
+	require.NotNil(t, pct)
+	assert.Equal(t, 85.0, *pct)
+
+	pct = OnTimePercentage(2, 3, 1)
+	require.NotNil(t, pct)
+	assert.Equal(t, 66.7, *pct, "rounded to one decimal")
+
+	assert.Nil(t, OnTimePercentage(4, 4, 5), "below the minimum sample")
+	assert.Nil(t, OnTimePercentage(0, 0, 0))
+}
+
+func TestOperatorOnTimePercentage_WeightedByTripsMeasured(t *testing.T) {
+	ninety, fifty := 90.0, 50.0
+	entries := []PublishedTimetableEntry{
+		{ScheduleID: "a", TripsMeasured: 90, OnTimeTrips: 81, OnTimePercentage: &ninety},
+		{ScheduleID: "b", TripsMeasured: 10, OnTimeTrips: 5, OnTimePercentage: &fifty},
+		// Too few trips to publish; left out of the operator figure
+		{ScheduleID: "c", TripsMeasured: 3, OnTimeTrips: 0},
+	}
+
+	pct := OperatorOnTimePercentage(entries)
+	require.NotNil(t, pct)
+	assert.Equal(t, 86.0, *pct)
+
+	assert.Nil(t, OperatorOnTimePercentage(entries[2:]))
+}
+
+func TestNextNightlyRun(t *testing.T) {
+	// 2026-03-04 01:30 in Colombo
+	before := time.Date(2026, 3, 3, 20, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 4, 2, 0, 0, 0, ReportTimezone), NextNightlyRun(before, 2))
+
+	// 02:00 exactly runs tomorrow
+	at := time.Date(2026, 3, 4, 2, 0, 0, 0, ReportTimezone)
+	assert.Equal(t, time.Date(2026, 3, 5, 2, 0, 0, 0, ReportTimezone), NextNightlyRun(at, 2))
+}
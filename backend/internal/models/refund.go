@@ -0,0 +1,178 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefundStatus tracks a refund from the passenger's request to the gateway's answer
+type RefundStatus string
+
+const (
+	RefundRequested  RefundStatus = "requested"  // Awaiting admin approval
+	RefundProcessing RefundStatus = "processing" // Approved and sent to the gateway
+	RefundCompleted  RefundStatus = "completed"  // The gateway returned the money
+	RefundFailed     RefundStatus = "failed"     // The gateway declined or could not be reached; may be approved again
+	RefundRejected   RefundStatus = "rejected"   // Declined by an admin
+)
+
+// IsFinal reports whether the refund can no longer change
+func (s RefundStatus) IsFinal() bool {
+	return s == RefundCompleted || s == RefundRejected
+}
+
+// CanTransitionTo reports whether a refund may move from s to next
+func (s RefundStatus) CanTransitionTo(next RefundStatus) bool {
+	switch s {
+	case RefundRequested:
+		return next == RefundProcessing || next == RefundRejected
+	case RefundProcessing:
+		return next == RefundCompleted || next == RefundFailed
+	case RefundFailed:
+		return next == RefundProcessing || next == RefundRejected
+	default:
+		return false
+	}
+}
+
+// RefundDestination is where a refund's money goes
+type RefundDestination string
+
+const (
+	RefundToCard   RefundDestination = "card"   // Back through PAYable to the card that paid
+	RefundToWallet RefundDestination = "wallet" // Into the passenger's wallet, immediately on approval
+)
+
+// Refund returns the refundable part of a cancelled booking's payment to the passenger
+type Refund struct {
+	ID               uuid.UUID         `json:"id" db:"id"`
+	BookingID        string            `json:"booking_id" db:"booking_id"`
+	BookingReference string            `json:"booking_reference" db:"booking_reference"`
+	UserID           string            `json:"user_id" db:"user_id"`
+	IntentID         *uuid.UUID        `json:"intent_id,omitempty" db:"intent_id"`
+	PaymentUID       string            `json:"payment_uid" db:"payment_uid"`
+	PaymentReference *string           `json:"payment_reference,omitempty" db:"payment_reference"`
+	TenantID         *string           `json:"-" db:"tenant_id"` // Whose merchant account took the payment; nil for SmartTransit
+	PaidAmount       float64           `json:"paid_amount" db:"paid_amount"`
+	CancellationFee  float64           `json:"cancellation_fee" db:"cancellation_fee"`
+	Amount           float64           `json:"amount" db:"amount"` // What goes back to the passenger
+	Currency         string            `json:"currency" db:"currency"`
+	Destination      RefundDestination `json:"destination" db:"destination"`
+	Status           RefundStatus      `json:"status" db:"status"`
+	Reason           *string           `json:"reason,omitempty" db:"reason"` // Passenger's note
+	GatewayRefundID  *string           `json:"gateway_refund_id,omitempty" db:"gateway_refund_id"`
+	FailureReason    *string           `json:"failure_reason,omitempty" db:"failure_reason"`
+	ReviewNote       *string           `json:"review_note,omitempty" db:"review_note"`
+	ReviewedByUserID *uuid.UUID        `json:"reviewed_by_user_id,omitempty" db:"reviewed_by_user_id"`
+	ReviewedAt       *time.Time        `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CompletedAt      *time.Time        `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at" db:"updated_at"`
+
+	// Set when only some seats of the booking were cancelled (see BookingSeatCancellation)
+	SeatCancellationID *uuid.UUID `json:"seat_cancellation_id,omitempty" db:"seat_cancellation_id"`
+
+	Events []RefundEvent `json:"events,omitempty" db:"-"`
+}
+
+// RefundEvent records one status change of a refund
+type RefundEvent struct {
+	ID          uuid.UUID     `json:"id" db:"id"`
+	RefundID    uuid.UUID     `json:"refund_id" db:"refund_id"`
+	FromStatus  *RefundStatus `json:"from_status,omitempty" db:"from_status"` // Nil for the request itself
+	ToStatus    RefundStatus  `json:"to_status" db:"to_status"`
+	Note        *string       `json:"note,omitempty" db:"note"`
+	ActorUserID *uuid.UUID    `json:"actor_user_id,omitempty" db:"actor_user_id"` // Nil for gateway outcomes
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+}
+
+// RefundPaymentLink is the payment behind a booking: PAYable, the passenger's wallet, or both
+type RefundPaymentLink struct {
+	IntentID         *uuid.UUID `db:"intent_id"`
+	PaymentUID       *string    `db:"payment_uid"`
+	PaymentReference *string    `db:"payment_reference"`
+	TenantID         *string    `db:"tenant_id"`
+	WalletAmount     Money      `db:"wallet_amount"`
+}
+
+// Destination is where the refund of this payment goes. Payments made even partly from the
+// wallet are refunded to it; card payments go back to the card unless requested is wallet.
+func (l *RefundPaymentLink) Destination(requested RefundDestination) RefundDestination {
+	if l.WalletAmount.IsPositive() || l.PaymentUID == nil || requested == RefundToWallet {
+		return RefundToWallet
+	}
+	return RefundToCard
+}
+
+// NewRefund requests a refund of a cancelled booking, priced by its cancellation quote
+func NewRefund(booking *MasterBooking, quote *CancellationQuote, link *RefundPaymentLink, reason *string) *Refund {
+	refund := &Refund{
+		BookingID:        booking.ID,
+		BookingReference: booking.BookingReference,
+		UserID:           booking.UserID,
+		IntentID:         link.IntentID,
+		PaymentReference: link.PaymentReference,
+		TenantID:         link.TenantID,
+		PaidAmount:       quote.PaidAmount,
+		CancellationFee:  quote.Fee,
+		Amount:           quote.RefundableAmount,
+		Currency:         quote.Currency,
+		Destination:      link.Destination(RefundToCard),
+		Status:           RefundRequested,
+		Reason:           reason,
+	}
+	if link.PaymentUID != nil {
+		refund.PaymentUID = *link.PaymentUID
+	}
+	if refund.PaymentReference == nil {
+		refund.PaymentReference = booking.PaymentReference
+	}
+	return refund
+}
+
+// NewSeatRefund requests a refund of seats cancelled out of a booking, priced when they were
+func NewSeatRefund(booking *MasterBooking, cancellation *BookingSeatCancellation, link *RefundPaymentLink, reason *string) *Refund {
+	refund := NewRefund(booking, &CancellationQuote{
+		PaidAmount:       cancellation.PaidAmount,
+		Fee:              cancellation.CancellationFee,
+		RefundableAmount: cancellation.RefundableAmount,
+		Currency:         cancellation.Currency,
+	}, link, reason)
+	refund.SeatCancellationID = &cancellation.ID
+	return refund
+}
+
+// RefundedPaymentStatus is the booking's payment status once the refund has completed
+func (r *Refund) RefundedPaymentStatus() MasterPaymentStatus {
+	if r.Amount < r.PaidAmount {
+		return MasterPaymentPartialRefund
+	}
+	return MasterPaymentRefunded
+}
+
+// RefundFilter narrows the refunds listed for admins
+type RefundFilter struct {
+	Status RefundStatus
+	Limit  int
+	Offset int
+}
+
+// RequestRefundRequest is a passenger's refund request for a cancelled booking
+type RequestRefundRequest struct {
+	Reason   string            `json:"reason" binding:"max=500"`
+	RefundTo RefundDestination `json:"refund_to" binding:"omitempty,oneof=card wallet"` // Defaults to card where the booking allows it
+}
+
+// ReviewRefundRequest carries an admin's note when approving or rejecting a refund
+type ReviewRefundRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// ResolveRefundRequest records the outcome of a refund whose gateway answer never arrived,
+// after checking it in the PAYable merchant portal
+type ResolveRefundRequest struct {
+	Status          RefundStatus `json:"status" binding:"required,oneof=completed failed"`
+	GatewayRefundID string       `json:"gateway_refund_id"`
+	Note            string       `json:"note" binding:"required,max=500"`
+}
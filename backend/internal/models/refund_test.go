@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, RefundRequested.CanTransitionTo(RefundProcessing))
+	assert.True(t, RefundRequested.CanTransitionTo(RefundRejected))
+	assert.False(t, RefundRequested.CanTransitionTo(RefundCompleted), "must go through the gateway")
+	assert.True(t, RefundProcessing.CanTransitionTo(RefundCompleted))
+	assert.True(t, RefundProcessing.CanTransitionTo(RefundFailed))
+	assert.True(t, RefundFailed.CanTransitionTo(RefundProcessing), "failed refunds can be retried")
+	assert.False(t, RefundCompleted.CanTransitionTo(RefundProcessing), "completed refunds are final")
+	assert.False(t, RefundRejected.CanTransitionTo(RefundProcessing))
+}
+
+func TestNewRefund(t *testing.T) {
+	intentID := uuid.New()
+	uid := "pay-uid-1"
+	bookingRef := "PAY-REF-1"
+	booking := &MasterBooking{ID: "booking-1", BookingReference: "BK-1", UserID: "user-1", TotalAmount: 2000, PaymentReference: &bookingRef}
+	quote := &CancellationQuote{PaidAmount: 2000, FeePercent: 25, Fee: 500, RefundableAmount: 1500, Currency: "LKR"}
+
+	refund := NewRefund(booking, quote, &RefundPaymentLink{IntentID: &intentID, PaymentUID: &uid}, nil)
+	assert.Equal(t, RefundRequested, refund.Status)
+	assert.Equal(t, "pay-uid-1", refund.PaymentUID)
+	assert.Equal(t, &bookingRef, refund.PaymentReference, "falls back to the booking's payment reference")
+	assert.Equal(t, 1500.0, refund.Amount)
+	assert.Equal(t, 500.0, refund.CancellationFee)
+	assert.Equal(t, MasterPaymentPartialRefund, refund.RefundedPaymentStatus())
+
+	quote.Fee, quote.RefundableAmount = 0, 2000
+	assert.Equal(t, MasterPaymentRefunded, NewRefund(booking, quote, &RefundPaymentLink{PaymentUID: &uid}, nil).RefundedPaymentStatus())
+}
+
+func TestNewSeatRefund(t *testing.T) {
+	uid := "pay-uid-1"
+	booking := &MasterBooking{ID: "booking-1", BookingReference: "BK-1", UserID: "user-1", TotalAmount: 2000}
+	cancellation := &BookingSeatCancellation{ID: uuid.New(), PaidAmount: 500, CancellationFee: 125, RefundableAmount: 375, Currency: "LKR"}
+
+	refund := NewSeatRefund(booking, cancellation, &RefundPaymentLink{PaymentUID: &uid}, nil)
+	assert.Equal(t, &cancellation.ID, refund.SeatCancellationID)
+	assert.Equal(t, 500.0, refund.PaidAmount, "priced by the seats, not the booking")
+	assert.Equal(t, 375.0, refund.Amount)
+	assert.Equal(t, "LKR", refund.Currency)
+	assert.Equal(t, RefundToCard, refund.Destination)
+}
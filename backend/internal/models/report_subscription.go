@@ -0,0 +1,149 @@
+package models
+
+import "time"
+
+// ReportType identifies a recurring emailed report
+type ReportType string
+
+const (
+	ReportDailyBookings    ReportType = "daily_bookings"    // CSV of yesterday's bookings
+	ReportWeeklyRevenue    ReportType = "weekly_revenue"    // PDF revenue summary for the previous Monday-Sunday
+	ReportMonthlyOccupancy ReportType = "monthly_occupancy" // CSV of seat occupancy per trip for the previous month
+)
+
+// ReportSubscriberType is who a subscription reports on
+type ReportSubscriberType string
+
+const (
+	ReportSubscriberBusOwner ReportSubscriberType = "bus_owner" // Scoped to the owner's own trips
+	ReportSubscriberAdmin    ReportSubscriberType = "admin"     // Platform-wide
+)
+
+// ReportTimezone is the local time reports are scheduled and bucketed in
+var ReportTimezone = time.FixedZone("Asia/Colombo", 5*3600+30*60)
+
+// IsValid checks whether the report type is supported
+func (t ReportType) IsValid() bool {
+	switch t {
+	case ReportDailyBookings, ReportWeeklyRevenue, ReportMonthlyOccupancy:
+		return true
+	}
+	return false
+}
+
+// PeriodEnding returns the reporting period [start, end) that finished at the start of the local day of at.
+// Daily covers the previous day, weekly the previous Monday-Sunday and monthly the previous calendar month.
+func (t ReportType) PeriodEnding(at time.Time) (time.Time, time.Time) {
+	local := at.In(ReportTimezone)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ReportTimezone)
+
+	switch t {
+	case ReportWeeklyRevenue:
+		// Days since Monday (Sunday counts as the 7th day)
+		offset := (int(day.Weekday()) + 6) % 7
+		end := day.AddDate(0, 0, -offset)
+		return end.AddDate(0, 0, -7), end
+	case ReportMonthlyOccupancy:
+		end := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, ReportTimezone)
+		return end.AddDate(0, -1, 0), end
+	default:
+		return day.AddDate(0, 0, -1), day
+	}
+}
+
+// NextRunAfter returns the first delivery time strictly after the given time: the next day, Monday
+// or 1st of the month at sendHour local time
+func (t ReportType) NextRunAfter(after time.Time, sendHour int) time.Time {
+	local := after.In(ReportTimezone)
+	day := time.Date(local.Year(), local.Month(), local.Day(), sendHour, 0, 0, 0, ReportTimezone)
+
+	switch t {
+	case ReportWeeklyRevenue:
+		next := day.AddDate(0, 0, (8-int(day.Weekday()))%7)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	case ReportMonthlyOccupancy:
+		next := time.Date(day.Year(), day.Month(), 1, sendHour, 0, 0, 0, ReportTimezone)
+		if !next.After(after) {
+			next = next.AddDate(0, 1, 0)
+		}
+		return next
+	default:
+		if !day.After(after) {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day
+	}
+}
+
+// ReportSubscription is a user's subscription to a recurring emailed report
+type ReportSubscription struct {
+	ID               string               `json:"id" db:"id"`
+	UserID           string               `json:"user_id" db:"user_id"`
+	SubscriberType   ReportSubscriberType `json:"subscriber_type" db:"subscriber_type"`
+	BusOwnerID       *string              `json:"bus_owner_id,omitempty" db:"bus_owner_id"` // Nil for admin (platform-wide) subscriptions
+	ReportType       ReportType           `json:"report_type" db:"report_type"`
+	Email            string               `json:"email" db:"email"`
+	UnsubscribeToken string               `json:"-" db:"unsubscribe_token"`
+	IsActive         bool                 `json:"is_active" db:"is_active"`
+	NextRunAt        time.Time            `json:"next_run_at" db:"next_run_at"`
+	LastSentAt       *time.Time           `json:"last_sent_at,omitempty" db:"last_sent_at"`
+	FailedAttempts   int                  `json:"-" db:"failed_attempts"`
+	LastError        *string              `json:"-" db:"last_error"`
+	UnsubscribedAt   *time.Time           `json:"unsubscribed_at,omitempty" db:"unsubscribed_at"`
+	CreatedAt        time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// CreateReportSubscriptionRequest subscribes to a report
+type CreateReportSubscriptionRequest struct {
+	ReportType ReportType `json:"report_type" binding:"required"`
+	Email      string     `json:"email" binding:"required,email"`
+}
+
+// UpdateReportSubscriptionRequest changes the delivery address or pauses/resumes a subscription
+type UpdateReportSubscriptionRequest struct {
+	Email    *string `json:"email,omitempty" binding:"omitempty,email"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// ============================================================================
+// REPORT DATA
+// ============================================================================
+
+// ReportBookingRow is a bus booking in the daily bookings report
+type ReportBookingRow struct {
+	BookingReference  string    `db:"booking_reference"`
+	BookedAt          time.Time `db:"booked_at"`
+	RouteName         string    `db:"route_name"`
+	DepartureDatetime time.Time `db:"departure_datetime"`
+	PassengerName     *string   `db:"passenger_name"`
+	Seats             int       `db:"number_of_seats"`
+	TotalFare         float64   `db:"total_fare"`
+	BookingStatus     string    `db:"booking_status"`
+	PaymentStatus     string    `db:"payment_status"`
+	BookingSource     *string   `db:"booking_source"`
+}
+
+// ReportRevenueRow is one route's paid bus revenue on one day
+type ReportRevenueRow struct {
+	Day       time.Time `db:"day"`
+	RouteName string    `db:"route_name"`
+	Trips     int       `db:"trips"`
+	Bookings  int       `db:"bookings"`
+	Seats     int       `db:"seats"`
+	Revenue   float64   `db:"revenue"`
+}
+
+// ReportOccupancyRow is the seat occupancy of one trip
+type ReportOccupancyRow struct {
+	ScheduledTripID   string    `db:"scheduled_trip_id"`
+	DepartureDatetime time.Time `db:"departure_datetime"`
+	RouteName         string    `db:"route_name"`
+	Status            string    `db:"status"`
+	TotalSeats        int       `db:"total_seats"`
+	BookedSeats       int       `db:"booked_seats"`
+	BlockedSeats      int       `db:"blocked_seats"`
+}
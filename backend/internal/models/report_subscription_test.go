@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func colombo(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, ReportTimezone)
+}
+
+func TestReportType_PeriodEnding(t *testing.T) {
+	// Wednesday 2026-03-04, 06:00 local
+	at := colombo(2026, time.March, 4, 6)
+
+	from, to := ReportDailyBookings.PeriodEnding(at)
+	assert.Equal(t, colombo(2026, time.March, 3, 0), from)
+	assert.Equal(t, colombo(2026, time.March, 4, 0), to)
+
+	from, to = ReportWeeklyRevenue.PeriodEnding(at)
+	assert.Equal(t, colombo(2026, time.February, 23, 0), from, "previous Monday")
+	assert.Equal(t, colombo(2026, time.March, 2, 0), to)
+
+	from, to = ReportMonthlyOccupancy.PeriodEnding(at)
+	assert.Equal(t, colombo(2026, time.February, 1, 0), from)
+	assert.Equal(t, colombo(2026, time.March, 1, 0), to)
+}
+
+func TestReportType_PeriodEnding_UsesLocalDay(t *testing.T) {
+	// 20:00 UTC on Mar 3 is already 01:30 on Mar 4 in Colombo
+	from, _ := ReportDailyBookings.PeriodEnding(time.Date(2026, time.March, 3, 20, 0, 0, 0, time.UTC))
+	assert.Equal(t, colombo(2026, time.March, 3, 0), from)
+}
+
+func TestReportType_NextRunAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		reportType ReportType
+		after      time.Time
+		want       time.Time
+	}{
+		{"daily before send hour", ReportDailyBookings, colombo(2026, time.March, 4, 5), colombo(2026, time.March, 4, 6)},
+		{"daily at send hour", ReportDailyBookings, colombo(2026, time.March, 4, 6), colombo(2026, time.March, 5, 6)},
+		{"weekly midweek", ReportWeeklyRevenue, colombo(2026, time.March, 4, 10), colombo(2026, time.March, 9, 6)},
+		{"weekly monday morning", ReportWeeklyRevenue, colombo(2026, time.March, 9, 3), colombo(2026, time.March, 9, 6)},
+		{"weekly sunday", ReportWeeklyRevenue, colombo(2026, time.March, 8, 23), colombo(2026, time.March, 9, 6)},
+		{"monthly", ReportMonthlyOccupancy, colombo(2026, time.March, 1, 6), colombo(2026, time.April, 1, 6)},
+		{"monthly year end", ReportMonthlyOccupancy, colombo(2026, time.December, 15, 6), colombo(2027, time.January, 1, 6)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(tt.reportType.NextRunAfter(tt.after, 6)))
+		})
+	}
+}
@@ -56,6 +56,7 @@ type RoutePermit struct {
 	PermitDocumentURL       *string            `json:"permit_document_url,omitempty" db:"permit_document_url"`
 	CreatedAt               time.Time          `json:"created_at" db:"created_at"`
 	UpdatedAt               time.Time          `json:"updated_at" db:"updated_at"`
+	DeletedAt               *time.Time         `json:"deleted_at,omitempty" db:"deleted_at"` // Set when the owner deleted it; admins can restore
 }
 
 // RoutePermitWithDetails includes route information from master_routes (for API responses)
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/polyline"
+)
+
+// MaxRoutePolylinePoints caps uploaded route geometry; a long intercity route is a few thousand points
+const MaxRoutePolylinePoints = 20000
+
+// Route types a polyline belongs to
+const (
+	RoutePolylineMasterRoute   = "master_route"
+	RoutePolylineBusOwnerRoute = "bus_owner_route"
+)
+
+// RoutePolylineZoom is a resolution of a route polyline, matched to map zoom
+type RoutePolylineZoom struct {
+	Name            string
+	ToleranceMeters float64 // Douglas-Peucker tolerance; 0 keeps every point
+}
+
+// RoutePolylineZooms are served with every polyline, from full detail down to country-level maps
+var RoutePolylineZooms = []RoutePolylineZoom{
+	{Name: "full", ToleranceMeters: 0},
+	{Name: "medium", ToleranceMeters: 25},
+	{Name: "low", ToleranceMeters: 250},
+}
+
+// RoutePolylineLevel is a route polyline simplified for one zoom level
+type RoutePolylineLevel struct {
+	Zoom            string  `json:"zoom"`
+	ToleranceMeters float64 `json:"tolerance_meters"`
+	EncodedPolyline string  `json:"encoded_polyline"`
+	PointCount      int     `json:"point_count"`
+}
+
+// RoutePolyline is the road geometry of a route, for drawing it on a map
+type RoutePolyline struct {
+	RouteID   string               `json:"route_id"`
+	RouteType string               `json:"route_type"`      // master_route or bus_owner_route
+	Inherited bool                 `json:"inherited"`       // A bus owner route without its own polyline uses its master route's
+	SourceID  string               `json:"source_route_id"` // Route the geometry was stored on
+	Levels    []RoutePolylineLevel `json:"levels"`          // One entry per RoutePolylineZooms
+}
+
+// NewRoutePolyline decodes a stored polyline and simplifies it for each zoom level
+func NewRoutePolyline(routeID, routeType, sourceID, encoded string) (*RoutePolyline, error) {
+	points, err := polyline.Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make([]RoutePolylineLevel, 0, len(RoutePolylineZooms))
+	for _, zoom := range RoutePolylineZooms {
+		simplified := points
+		if zoom.ToleranceMeters > 0 {
+			simplified = polyline.Simplify(points, zoom.ToleranceMeters)
+		}
+		levels = append(levels, RoutePolylineLevel{
+			Zoom:            zoom.Name,
+			ToleranceMeters: zoom.ToleranceMeters,
+			EncodedPolyline: polyline.Encode(simplified),
+			PointCount:      len(simplified),
+		})
+	}
+
+	return &RoutePolyline{
+		RouteID:   routeID,
+		RouteType: routeType,
+		Inherited: routeID != sourceID,
+		SourceID:  sourceID,
+		Levels:    levels,
+	}, nil
+}
+
+// UpdateRoutePolylineRequest uploads a route's road geometry, either already encoded or as points
+type UpdateRoutePolylineRequest struct {
+	EncodedPolyline string           `json:"encoded_polyline,omitempty"`
+	Points          []polyline.Point `json:"points,omitempty"`
+}
+
+// Encoded validates the request and returns the polyline to store
+func (r *UpdateRoutePolylineRequest) Encoded() (string, error) {
+	encoded := strings.TrimSpace(r.EncodedPolyline)
+	if (encoded == "") == (len(r.Points) == 0) {
+		return "", &ValidationError{Message: "provide either encoded_polyline or points"}
+	}
+
+	points := r.Points
+	if encoded != "" {
+		decoded, err := polyline.Decode(encoded)
+		if err != nil {
+			return "", &ValidationError{Message: "encoded_polyline is not a valid encoded polyline"}
+		}
+		points = decoded
+	}
+
+	if len(points) < 2 {
+		return "", &ValidationError{Message: "a route polyline needs at least 2 points"}
+	}
+	if len(points) > MaxRoutePolylinePoints {
+		return "", &ValidationError{Message: fmt.Sprintf("a route polyline can have at most %d points", MaxRoutePolylinePoints)}
+	}
+	for _, p := range points {
+		if p.Lat < -90 || p.Lat > 90 || p.Lng < -180 || p.Lng > 180 {
+			return "", &ValidationError{Message: fmt.Sprintf("point (%g, %g) is not a valid coordinate", p.Lat, p.Lng)}
+		}
+	}
+
+	if encoded != "" {
+		return encoded, nil
+	}
+	return polyline.Encode(points), nil
+}
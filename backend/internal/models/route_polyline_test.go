@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/polyline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRoutePolyline_SimplifiesPerZoom(t *testing.T) {
+	// A straight road sampled every ~110 m with one point 60 m off it
+	points := []polyline.Point{}
+	for i := 0; i <= 20; i++ {
+		points = append(points, polyline.Point{Lat: 7.0 + float64(i)*0.001, Lng: 80.0})
+	}
+	points[10].Lng += 0.00055
+	encoded := polyline.Encode(points)
+
+	route, err := NewRoutePolyline("route-1", RoutePolylineMasterRoute, "route-1", encoded)
+	require.NoError(t, err)
+	assert.False(t, route.Inherited)
+	require.Len(t, route.Levels, 3)
+
+	assert.Equal(t, "full", route.Levels[0].Zoom)
+	assert.Equal(t, encoded, route.Levels[0].EncodedPolyline)
+	assert.Equal(t, 21, route.Levels[0].PointCount)
+
+	assert.Equal(t, "medium", route.Levels[1].Zoom)
+	assert.Less(t, route.Levels[1].PointCount, 21)
+	assert.Greater(t, route.Levels[1].PointCount, 2) // Keeps the 60 m detour
+
+	assert.Equal(t, "low", route.Levels[2].Zoom)
+	assert.Equal(t, 2, route.Levels[2].PointCount)
+}
+
+func TestNewRoutePolyline_Inherited(t *testing.T) {
+	route, err := NewRoutePolyline("owner-route", RoutePolylineBusOwnerRoute, "master-route", "_p~iF~ps|U_ulLnnqC")
+	require.NoError(t, err)
+	assert.True(t, route.Inherited)
+	assert.Equal(t, "master-route", route.SourceID)
+}
+
+func TestUpdateRoutePolylineRequest_Encoded(t *testing.T) {
+	points := []polyline.Point{{Lat: 6.9271, Lng: 79.8612}, {Lat: 7.2906, Lng: 80.6337}}
+
+	encoded, err := (&UpdateRoutePolylineRequest{Points: points}).Encoded()
+	require.NoError(t, err)
+	assert.Equal(t, polyline.Encode(points), encoded)
+
+	encoded, err = (&UpdateRoutePolylineRequest{EncodedPolyline: " _p~iF~ps|U_ulLnnqC "}).Encoded()
+	require.NoError(t, err)
+	assert.Equal(t, "_p~iF~ps|U_ulLnnqC", encoded)
+}
+
+func TestUpdateRoutePolylineRequest_Invalid(t *testing.T) {
+	cases := map[string]UpdateRoutePolylineRequest{
+		"empty":        {},
+		"both":         {EncodedPolyline: "_p~iF~ps|U_ulLnnqC", Points: []polyline.Point{{Lat: 7, Lng: 80}, {Lat: 7.1, Lng: 80}}},
+		"malformed":    {EncodedPolyline: "_p~iF~ps|U_ulL"},
+		"single point": {Points: []polyline.Point{{Lat: 7, Lng: 80}}},
+		"out of range": {Points: []polyline.Point{{Lat: 7, Lng: 80}, {Lat: 97, Lng: 80}}},
+	}
+	for name, req := range cases {
+		_, err := req.Encoded()
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr, name)
+	}
+}
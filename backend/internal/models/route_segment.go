@@ -0,0 +1,125 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// RouteSegment is the road distance and driving time between two consecutive located stops of a
+// master route, as computed by the routing engine. Stops without coordinates are skipped, so a
+// segment can span them. The stop coordinates are kept to detect when a stop has moved.
+type RouteSegment struct {
+	MasterRouteID   string    `json:"master_route_id" db:"master_route_id"`
+	FromStopID      string    `json:"from_stop_id" db:"from_stop_id"`
+	ToStopID        string    `json:"to_stop_id" db:"to_stop_id"`
+	FromLatitude    float64   `json:"-" db:"from_latitude"`
+	FromLongitude   float64   `json:"-" db:"from_longitude"`
+	ToLatitude      float64   `json:"-" db:"to_latitude"`
+	ToLongitude     float64   `json:"-" db:"to_longitude"`
+	DistanceMeters  float64   `json:"distance_meters" db:"distance_meters"`
+	DurationSeconds float64   `json:"duration_seconds" db:"duration_seconds"` // Engine (car) driving time
+	Engine          string    `json:"engine" db:"engine"`
+	ComputedAt      time.Time `json:"computed_at" db:"computed_at"`
+
+	// Filled in for responses
+	FromStopName       string  `json:"from_stop_name" db:"-"`
+	ToStopName         string  `json:"to_stop_name" db:"-"`
+	BusDurationMinutes float64 `json:"bus_duration_minutes" db:"-"` // Driving time scaled to bus speed
+}
+
+// RouteSegments is a master route's computed segments with route totals
+type RouteSegments struct {
+	MasterRouteID        string         `json:"master_route_id"`
+	Engine               string         `json:"engine"`
+	Cached               bool           `json:"cached"` // Served from earlier results without calling the engine
+	Applied              bool           `json:"applied"`
+	TotalDistanceKm      float64        `json:"total_distance_km"`
+	TotalDurationMinutes float64        `json:"total_duration_minutes"` // At bus speed
+	SkippedStopIDs       []string       `json:"skipped_stop_ids"`       // Stops without coordinates
+	Segments             []RouteSegment `json:"segments"`
+}
+
+// ComputeRouteSegmentsRequest computes a master route's segments and optionally applies them
+type ComputeRouteSegmentsRequest struct {
+	Refresh bool `json:"refresh"` // Recompute even when cached results are still valid
+	Apply   bool `json:"apply"`   // Write stop arrival offsets and the route's distance and duration
+}
+
+// ============================================================================
+// FARE MATRIX
+// ============================================================================
+
+// FareMatrixParams prices a stop-to-stop fare matrix from segment distances
+type FareMatrixParams struct {
+	BaseFare    float64 `form:"base_fare" binding:"omitempty,min=0"`
+	PerKmRate   float64 `form:"per_km_rate" binding:"required,gt=0"`
+	MinimumFare float64 `form:"minimum_fare" binding:"omitempty,min=0"`
+	RoundTo     float64 `form:"round_to" binding:"omitempty,min=0"` // Fares are rounded up to a multiple of this, e.g. 10 LKR
+}
+
+// Fare prices a journey of distanceKm
+func (p *FareMatrixParams) Fare(distanceKm float64) float64 {
+	fare := p.BaseFare + p.PerKmRate*distanceKm
+	if fare < p.MinimumFare {
+		fare = p.MinimumFare
+	}
+	if p.RoundTo > 0 {
+		// Subtract a tiny epsilon so exact multiples are not pushed up by float error
+		fare = math.Ceil(fare/p.RoundTo-1e-9) * p.RoundTo
+	}
+	return RoundMoney(fare)
+}
+
+// FareMatrixStop is a stop priced in a fare matrix, with its road distance from the first stop
+type FareMatrixStop struct {
+	StopID     string  `json:"stop_id"`
+	StopName   string  `json:"stop_name"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// FareMatrixEntry is the fare between two stops in travel order
+type FareMatrixEntry struct {
+	FromStopID string  `json:"from_stop_id"`
+	ToStopID   string  `json:"to_stop_id"`
+	DistanceKm float64 `json:"distance_km"`
+	Fare       float64 `json:"fare"`
+}
+
+// FareMatrix is a generated stop-to-stop fare table for a master route
+type FareMatrix struct {
+	MasterRouteID string            `json:"master_route_id"`
+	Stops         []FareMatrixStop  `json:"stops"`
+	Entries       []FareMatrixEntry `json:"entries"`
+}
+
+// BuildFareMatrix prices every forward journey between route segment stops
+func BuildFareMatrix(masterRouteID string, segments []RouteSegment, params *FareMatrixParams) *FareMatrix {
+	matrix := &FareMatrix{MasterRouteID: masterRouteID, Stops: []FareMatrixStop{}, Entries: []FareMatrixEntry{}}
+	if len(segments) == 0 {
+		return matrix
+	}
+
+	matrix.Stops = append(matrix.Stops, FareMatrixStop{StopID: segments[0].FromStopID, StopName: segments[0].FromStopName})
+	distance := 0.0
+	for _, segment := range segments {
+		distance += segment.DistanceMeters / 1000
+		matrix.Stops = append(matrix.Stops, FareMatrixStop{
+			StopID:     segment.ToStopID,
+			StopName:   segment.ToStopName,
+			DistanceKm: math.Round(distance*100) / 100,
+		})
+	}
+
+	for i, from := range matrix.Stops {
+		for _, to := range matrix.Stops[i+1:] {
+			km := math.Round((to.DistanceKm-from.DistanceKm)*100) / 100
+			matrix.Entries = append(matrix.Entries, FareMatrixEntry{
+				FromStopID: from.StopID,
+				ToStopID:   to.StopID,
+				DistanceKm: km,
+				Fare:       params.Fare(km),
+			})
+		}
+	}
+	return matrix
+}
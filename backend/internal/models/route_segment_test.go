@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFareMatrixParams_Fare(t *testing.T) {
+	params := &FareMatrixParams{BaseFare: 30, PerKmRate: 2.5, MinimumFare: 40, RoundTo: 10}
+
+	assert.Equal(t, 40.0, params.Fare(1))  // 32.50 raised to the minimum
+	assert.Equal(t, 60.0, params.Fare(10)) // 55.00 rounded up
+	assert.Equal(t, 80.0, params.Fare(20)) // Exactly 80
+	assert.Equal(t, 32.5, (&FareMatrixParams{BaseFare: 30, PerKmRate: 2.5}).Fare(1))
+}
+
+func TestBuildFareMatrix(t *testing.T) {
+	segments := []RouteSegment{
+		{FromStopID: "a", FromStopName: "Colombo", ToStopID: "b", ToStopName: "Kadawatha", DistanceMeters: 16000},
+		{FromStopID: "b", FromStopName: "Kadawatha", ToStopID: "c", ToStopName: "Kandy", DistanceMeters: 99500},
+	}
+	params := &FareMatrixParams{PerKmRate: 2, RoundTo: 5}
+
+	matrix := BuildFareMatrix("route-1", segments, params)
+	require.Len(t, matrix.Stops, 3)
+	assert.Equal(t, FareMatrixStop{StopID: "c", StopName: "Kandy", DistanceKm: 115.5}, matrix.Stops[2])
+
+	assert.Equal(t, []FareMatrixEntry{
+		{FromStopID: "a", ToStopID: "b", DistanceKm: 16, Fare: 35},
+		{FromStopID: "a", ToStopID: "c", DistanceKm: 115.5, Fare: 235},
+		{FromStopID: "b", ToStopID: "c", DistanceKm: 99.5, Fare: 200},
+	}, matrix.Entries)
+}
+
+func TestBuildFareMatrix_NoSegments(t *testing.T) {
+	matrix := BuildFareMatrix("route-1", nil, &FareMatrixParams{PerKmRate: 2})
+	assert.Empty(t, matrix.Stops)
+	assert.Empty(t, matrix.Entries)
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -31,7 +32,7 @@ type ScheduledTrip struct {
 	IsBookable               bool      `json:"is_bookable" db:"is_bookable"`                 // Controls if trip is available for passenger booking
 	EverPublished            bool      `json:"ever_published" db:"ever_published"`           // Tracks if trip was ever made bookable (stays true once set)
 	TotalSeats               int       `json:"total_seats" db:"total_seats"`
-	// AvailableSeats and BookedSeats removed - will be calculated from separate booking tables
+	// available_seats and booked_seats are cached counters kept in sync with trip_seats by the database layer
 	BaseFare            float64             `json:"base_fare" db:"base_fare"`
 	BookingAdvanceHours int                 `json:"booking_advance_hours" db:"booking_advance_hours"`       // NEW: Hours before trip that booking opens
 	AssignmentDeadline  *time.Time          `json:"assignment_deadline,omitempty" db:"assignment_deadline"` // NEW: Deadline to assign resources
@@ -216,6 +217,51 @@ type ScheduledTripWithRouteInfo struct {
 	IsUpDirection   *bool   `json:"is_up_direction,omitempty"`
 }
 
+// StaffReportLead is how long before departure staff are expected to report for duty
+const StaffReportLead = 30 * time.Minute
+
+// StaffTripFilter narrows the trips listed for a staff member
+type StaffTripFilter struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Statuses  []ScheduledTripStatus // Empty means every status except cancelled and completed
+	Limit     int
+	Offset    int
+}
+
+// ParseScheduledTripStatuses parses a comma-separated status list such as "scheduled,in_progress"
+func ParseScheduledTripStatuses(value string) ([]ScheduledTripStatus, error) {
+	var statuses []ScheduledTripStatus
+	for _, part := range strings.Split(value, ",") {
+		status := ScheduledTripStatus(strings.TrimSpace(part))
+		if status == "" {
+			continue
+		}
+		switch status {
+		case ScheduledTripStatusScheduled, ScheduledTripStatusConfirmed, ScheduledTripStatusInProgress,
+			ScheduledTripStatusCompleted, ScheduledTripStatusCancelled:
+			statuses = append(statuses, status)
+		default:
+			return nil, errors.New("invalid trip status: " + string(status))
+		}
+	}
+	return statuses, nil
+}
+
+// StaffNextAssignment is the compact summary of a staff member's next trip for the staff home screen
+type StaffNextAssignment struct {
+	TripID                string              `json:"trip_id"`
+	Status                ScheduledTripStatus `json:"status"`
+	DepartureDatetime     time.Time           `json:"departure_datetime"`
+	ReportBy              time.Time           `json:"report_by"` // Departure minus StaffReportLead
+	RouteNumber           *string             `json:"route_number,omitempty"`
+	OriginCity            *string             `json:"origin_city,omitempty"`
+	DestinationCity       *string             `json:"destination_city,omitempty"`
+	BusRegistrationNumber *string             `json:"bus_registration_number,omitempty"`
+	IsDriver              bool                `json:"is_driver"`
+	IsConductor           bool                `json:"is_conductor"`
+}
+
 // StaffDetails contains basic staff information for trip display
 type StaffDetails struct {
 	ID            string  `json:"id"`
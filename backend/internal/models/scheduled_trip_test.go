@@ -0,0 +1,21 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduledTripStatuses(t *testing.T) {
+	statuses, err := ParseScheduledTripStatuses("scheduled, in_progress,")
+	require.NoError(t, err)
+	assert.Equal(t, []ScheduledTripStatus{ScheduledTripStatusScheduled, ScheduledTripStatusInProgress}, statuses)
+
+	statuses, err = ParseScheduledTripStatuses("")
+	require.NoError(t, err)
+	assert.Empty(t, statuses, "no filter")
+
+	_, err = ParseScheduledTripStatuses("scheduled,delayed")
+	assert.Error(t, err)
+}
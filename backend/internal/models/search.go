@@ -59,13 +59,20 @@ type TripResult struct {
 	DepartureTime    time.Time `json:"-" db:"departure_time"`
 	EstimatedArrival time.Time `json:"-" db:"estimated_arrival"`
 	DurationMinutes  int       `json:"duration_minutes" db:"duration_minutes"`
-	// AvailableSeats removed - will be calculated from booking table in separate query
-	TotalSeats    int         `json:"total_seats" db:"total_seats"`
-	Fare          float64     `json:"fare" db:"fare"`
-	BoardingPoint string      `json:"boarding_point" db:"boarding_point"`
-	DroppingPoint string      `json:"dropping_point" db:"dropping_point"`
-	BusFeatures   BusFeatures `json:"bus_features"`
-	IsBookable    bool        `json:"is_bookable" db:"is_bookable"`
+	// Seats bookable between the searched boarding and alighting stops, and the cheapest of them;
+	// omitted when the trip has no seat map
+	AvailableSeats    *int        `json:"available_seats,omitempty" db:"-"`
+	CheapestSeatPrice *float64    `json:"cheapest_seat_price,omitempty" db:"-"`
+	TotalSeats        int         `json:"total_seats" db:"total_seats"`
+	Fare              float64     `json:"fare" db:"fare"`
+	BoardingPoint     string      `json:"boarding_point" db:"boarding_point"`
+	DroppingPoint     string      `json:"dropping_point" db:"dropping_point"`
+	BusFeatures       BusFeatures `json:"bus_features"`
+	IsBookable        bool        `json:"is_bookable" db:"is_bookable"`
+	// Owner's surcharge or discount, already included in Fare and CheapestSeatPrice; Amount is the change to Fare
+	PriceAdjustment *AppliedPriceAdjustment `json:"price_adjustment,omitempty" db:"-"`
+	// Share of the schedule's recent trips that ran on time (nightly); nil for new schedules and special trips
+	OnTimePercentage *float64 `json:"on_time_percentage" db:"on_time_percentage"`
 	// Route stops for passenger to select boarding/alighting points
 	RouteStops []RouteStop `json:"route_stops,omitempty"`
 	// Route IDs for lounge lookup
@@ -109,6 +116,66 @@ func (tr TripResult) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// TripSeatOccupancy is a trip seat with the stop range of the booking holding it, used to
+// work out availability for one boarding to alighting segment
+type TripSeatOccupancy struct {
+	ScheduledTripID string         `db:"scheduled_trip_id"`
+	SeatPrice       float64        `db:"seat_price"`
+	Status          TripSeatStatus `db:"status"`
+	Held            bool           `db:"held"`              // Held by a live booking intent
+	BookedFromOrder *int           `db:"booked_from_order"` // Booking's boarding stop order
+	BookedToOrder   *int           `db:"booked_to_order"`   // Booking's alighting stop order
+}
+
+// FreeForSegment reports whether the seat is free from stop order from to stop order to. A
+// booked seat is free if its booking gets off at or before from, or boards at or after to; a
+// booking without a boarding stop is taken to board at the start, one without an alighting
+// stop to ride to the end. Held, blocked and reserved seats are never free.
+func (s TripSeatOccupancy) FreeForSegment(from, to int) bool {
+	if s.Held {
+		return false
+	}
+	switch s.Status {
+	case TripSeatStatusAvailable:
+		return true
+	case TripSeatStatusBooked:
+		return (s.BookedToOrder != nil && *s.BookedToOrder <= from) ||
+			(s.BookedFromOrder != nil && *s.BookedFromOrder >= to)
+	}
+	return false
+}
+
+// BookableForSegment reports whether a booking from stop order from to stop order to can take
+// the seat now. Seat holds and bookings still take a seat for the whole trip, so a booked seat
+// that is free for the segment cannot be booked yet.
+func (s TripSeatOccupancy) BookableForSegment(from, to int) bool {
+	return s.Status == TripSeatStatusAvailable && s.FreeForSegment(from, to)
+}
+
+// SegmentAvailability is a trip's seat availability for one boarding to alighting segment
+type SegmentAvailability struct {
+	AvailableSeats    int
+	CheapestSeatPrice *float64
+}
+
+// SummarizeSegmentAvailability counts each trip's seats bookable from stop order from to stop
+// order to, keyed by scheduled trip ID. Trips without seats are left out.
+func SummarizeSegmentAvailability(seats []TripSeatOccupancy, from, to int) map[string]SegmentAvailability {
+	result := make(map[string]SegmentAvailability)
+	for _, seat := range seats {
+		availability := result[seat.ScheduledTripID]
+		if seat.BookableForSegment(from, to) {
+			availability.AvailableSeats++
+			if availability.CheapestSeatPrice == nil || seat.SeatPrice < *availability.CheapestSeatPrice {
+				price := seat.SeatPrice
+				availability.CheapestSeatPrice = &price
+			}
+		}
+		result[seat.ScheduledTripID] = availability
+	}
+	return result
+}
+
 // BusFeatures represents amenities available on the bus
 type BusFeatures struct {
 	HasWiFi          bool `json:"has_wifi" db:"has_wifi"`
@@ -144,7 +211,11 @@ type SearchLog struct {
 	ResponseTimeMs int64      `json:"response_time_ms" db:"response_time_ms"`
 	UserID         *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
 	IPAddress      *string    `json:"ip_address,omitempty" db:"ip_address"`
-	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+
+	// Departure time the passenger asked for, if any (demand analytics)
+	RequestedDepartureAt *time.Time `json:"requested_departure_at,omitempty" db:"requested_departure_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
 // Validate validates the search request
@@ -0,0 +1,13 @@
+package models
+
+// SearchCacheStats reports how well the trip search cache is doing since the server started
+type SearchCacheStats struct {
+	Enabled       bool    `json:"enabled"`
+	TTLSeconds    int     `json:"ttl_seconds"`
+	Entries       int     `json:"entries"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRate       float64 `json:"hit_rate"`      // Hits / (hits + misses), 0 before any search
+	Invalidations int64   `json:"invalidations"` // Entries dropped because their corridor's trips changed
+	Evictions     int64   `json:"evictions"`     // Entries dropped to stay under the size limit
+}
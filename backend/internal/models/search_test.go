@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bookedSeat(from, to int) TripSeatOccupancy {
+	return TripSeatOccupancy{ScheduledTripID: "trip-1", SeatPrice: 1000, Status: TripSeatStatusBooked, BookedFromOrder: &from, BookedToOrder: &to}
+}
+
+func TestTripSeatOccupancyFreeForSegment(t *testing.T) {
+	// Searching stops 3 -> 6
+	assert.True(t, TripSeatOccupancy{Status: TripSeatStatusAvailable}.FreeForSegment(3, 6))
+	assert.False(t, TripSeatOccupancy{Status: TripSeatStatusAvailable, Held: true}.FreeForSegment(3, 6), "held by an intent")
+	assert.False(t, TripSeatOccupancy{Status: TripSeatStatusBlocked}.FreeForSegment(3, 6))
+	assert.False(t, TripSeatOccupancy{Status: TripSeatStatusReserved}.FreeForSegment(3, 6))
+
+	assert.True(t, bookedSeat(1, 3).FreeForSegment(3, 6), "gets off where we board")
+	assert.True(t, bookedSeat(6, 9).FreeForSegment(3, 6), "boards where we get off")
+	assert.False(t, bookedSeat(1, 4).FreeForSegment(3, 6))
+	assert.False(t, bookedSeat(5, 9).FreeForSegment(3, 6))
+	assert.False(t, bookedSeat(4, 5).FreeForSegment(3, 6))
+	assert.False(t, TripSeatOccupancy{Status: TripSeatStatusBooked}.FreeForSegment(3, 6), "unknown stops occupy the whole trip")
+	to := 3
+	assert.True(t, TripSeatOccupancy{Status: TripSeatStatusBooked, BookedToOrder: &to}.FreeForSegment(3, 6), "boards at the start, gets off where we board")
+}
+
+func TestSummarizeSegmentAvailability(t *testing.T) {
+	premium := bookedSeat(1, 3)
+	premium.SeatPrice = 1500
+	seats := []TripSeatOccupancy{
+		{ScheduledTripID: "trip-1", SeatPrice: 1200, Status: TripSeatStatusAvailable},
+		premium,
+		bookedSeat(2, 5),
+		{ScheduledTripID: "trip-1", SeatPrice: 800, Status: TripSeatStatusBlocked},
+		{ScheduledTripID: "trip-2", SeatPrice: 900, Status: TripSeatStatusBooked},
+	}
+
+	result := SummarizeSegmentAvailability(seats, 3, 6)
+
+	require.Contains(t, result, "trip-1")
+	assert.Equal(t, 1, result["trip-1"].AvailableSeats, "the premium seat is free for the segment but still booked")
+	require.NotNil(t, result["trip-1"].CheapestSeatPrice)
+	assert.Equal(t, 1200.0, *result["trip-1"].CheapestSeatPrice, "blocked seats don't set the price")
+
+	require.Contains(t, result, "trip-2", "sold-out trips are still reported")
+	assert.Equal(t, 0, result["trip-2"].AvailableSeats)
+	assert.Nil(t, result["trip-2"].CheapestSeatPrice)
+
+	assert.NotContains(t, result, "trip-3")
+}
+
+func TestSummarizeSegmentAvailability_SegmentFreeBookedSeatsAreNotBookable(t *testing.T) {
+	// The only seats free from stop 3 to 6 are booked for other segments; holds can't take them
+	seats := []TripSeatOccupancy{
+		bookedSeat(1, 3),
+		bookedSeat(6, 9),
+		bookedSeat(2, 5),
+	}
+
+	result := SummarizeSegmentAvailability(seats, 3, 6)
+
+	require.Contains(t, result, "trip-1")
+	assert.Equal(t, 0, result["trip-1"].AvailableSeats)
+	assert.Nil(t, result["trip-1"].CheapestSeatPrice, "no bookable seat to quote a price for")
+}
@@ -0,0 +1,115 @@
+package models
+
+// SegmentSeat is a trip seat with the part of the route it is taken for
+type SegmentSeat struct {
+	ID         string `db:"id"`
+	SeatNumber string `db:"seat_number"`
+	SeatType   string `db:"seat_type"`
+	RowNumber  int    `db:"row_number"`
+	Position   int    `db:"position"`
+	TripSeatOccupancy
+}
+
+// SegmentStop is one end of the part of the route seats are looked up for
+type SegmentStop struct {
+	StopID    string `json:"stop_id"`
+	StopName  string `json:"stop_name"`
+	StopOrder int    `json:"stop_order"`
+}
+
+// SegmentSeatStatus is whether one seat can be had between two stops
+type SegmentSeatStatus struct {
+	SeatID     string         `json:"seat_id"`
+	SeatNumber string         `json:"seat_number"`
+	SeatType   string         `json:"seat_type"`
+	RowNumber  int            `json:"row_number"`
+	Position   int            `json:"position"`
+	SeatPrice  float64        `json:"seat_price"`
+	Available  bool           `json:"available"` // Can be booked between the stops now
+	Status     TripSeatStatus `json:"status"`    // available, or why not: booked, reserved or blocked
+
+	// Not taken between the stops. A seat booked for another part of the route is segment free
+	// but not available: bookings still take a seat for the whole trip.
+	SegmentFree bool `json:"segment_free"`
+}
+
+// SegmentSeatAvailability is which of a trip's seats are free between two of its stops
+type SegmentSeatAvailability struct {
+	ScheduledTripID string              `json:"scheduled_trip_id"`
+	FromStop        SegmentStop         `json:"from_stop"`
+	ToStop          SegmentStop         `json:"to_stop"`
+	TotalSeats      int                 `json:"total_seats"`
+	AvailableSeats  int                 `json:"available_seats"`
+	Seats           []SegmentSeatStatus `json:"seats"`
+}
+
+// ResolveSegment finds the stops a passenger boards and alights at among the stops a trip calls
+// at, in stop order. An empty from or to is the trip's first or last stop.
+func ResolveSegment(stops []MasterRouteStop, fromStopID, toStopID string) (SegmentStop, SegmentStop, error) {
+	if len(stops) < 2 {
+		return SegmentStop{}, SegmentStop{}, &ValidationError{Message: "trip has no route stops"}
+	}
+	find := func(name, id string, fallback MasterRouteStop) (SegmentStop, error) {
+		stop := fallback
+		if id != "" {
+			found := false
+			for _, s := range stops {
+				if s.ID == id {
+					stop, found = s, true
+					break
+				}
+			}
+			if !found {
+				return SegmentStop{}, &ValidationError{Message: name + " is not a stop of this trip"}
+			}
+		}
+		return SegmentStop{StopID: stop.ID, StopName: stop.StopName, StopOrder: stop.StopOrder}, nil
+	}
+
+	from, err := find("from_stop", fromStopID, stops[0])
+	if err != nil {
+		return from, SegmentStop{}, err
+	}
+	to, err := find("to_stop", toStopID, stops[len(stops)-1])
+	if err != nil {
+		return from, to, err
+	}
+	if from.StopOrder >= to.StopOrder {
+		return from, to, &ValidationError{Message: "to_stop must come after from_stop"}
+	}
+	return from, to, nil
+}
+
+// NewSegmentSeatAvailability works out which seats can be booked from one stop to a later one,
+// and which are free for that part of the route (TripSeatOccupancy.FreeForSegment). Only
+// bookable seats are counted as available. Held seats are reported as reserved.
+func NewSegmentSeatAvailability(scheduledTripID string, seats []SegmentSeat, from, to SegmentStop) *SegmentSeatAvailability {
+	availability := &SegmentSeatAvailability{
+		ScheduledTripID: scheduledTripID,
+		FromStop:        from,
+		ToStop:          to,
+		TotalSeats:      len(seats),
+		Seats:           make([]SegmentSeatStatus, 0, len(seats)),
+	}
+	for _, seat := range seats {
+		status := SegmentSeatStatus{
+			SeatID:      seat.ID,
+			SeatNumber:  seat.SeatNumber,
+			SeatType:    seat.SeatType,
+			RowNumber:   seat.RowNumber,
+			Position:    seat.Position,
+			SeatPrice:   seat.SeatPrice,
+			Status:      seat.Status,
+			Available:   seat.BookableForSegment(from.StopOrder, to.StopOrder),
+			SegmentFree: seat.FreeForSegment(from.StopOrder, to.StopOrder),
+		}
+		if seat.Held && seat.Status == TripSeatStatusAvailable {
+			status.Status = TripSeatStatusReserved
+		}
+		if status.Available {
+			availability.AvailableSeats++
+		}
+		availability.Seats = append(availability.Seats, status)
+	}
+	return availability
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSegment(t *testing.T) {
+	stops := []MasterRouteStop{
+		{ID: "s1", StopName: "Colombo", StopOrder: 1},
+		{ID: "s2", StopName: "Kurunegala", StopOrder: 2},
+		{ID: "s3", StopName: "Anuradhapura", StopOrder: 3},
+	}
+
+	from, to, err := ResolveSegment(stops, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", from.StopID, "defaults to the whole trip")
+	assert.Equal(t, "s3", to.StopID)
+
+	from, to, err = ResolveSegment(stops, "s2", "s3")
+	require.NoError(t, err)
+	assert.Equal(t, SegmentStop{StopID: "s2", StopName: "Kurunegala", StopOrder: 2}, from)
+	assert.Equal(t, 3, to.StopOrder)
+
+	var validationErr *ValidationError
+	_, _, err = ResolveSegment(stops, "s3", "s2")
+	assert.ErrorAs(t, err, &validationErr, "backwards")
+	_, _, err = ResolveSegment(stops, "s9", "")
+	assert.ErrorAs(t, err, &validationErr, "not on the trip")
+	_, _, err = ResolveSegment(stops[:1], "", "")
+	assert.ErrorAs(t, err, &validationErr, "no route")
+}
+
+func TestNewSegmentSeatAvailability(t *testing.T) {
+	intp := func(v int) *int { return &v }
+	seat := func(id string, status TripSeatStatus, held bool, from, to *int) SegmentSeat {
+		return SegmentSeat{ID: id, TripSeatOccupancy: TripSeatOccupancy{Status: status, Held: held, BookedFromOrder: from, BookedToOrder: to}}
+	}
+	seats := []SegmentSeat{
+		seat("1", TripSeatStatusAvailable, false, nil, nil),
+		seat("2", TripSeatStatusBooked, false, intp(1), intp(2)), // Colombo to Kurunegala
+		seat("3", TripSeatStatusBooked, false, intp(2), intp(3)), // Kurunegala to Anuradhapura
+		seat("4", TripSeatStatusBooked, false, nil, nil),         // Stops unknown
+		seat("5", TripSeatStatusBlocked, false, nil, nil),
+		seat("6", TripSeatStatusAvailable, true, nil, nil),
+	}
+	byID := func(a *SegmentSeatAvailability) map[string]SegmentSeatStatus {
+		m := map[string]SegmentSeatStatus{}
+		for _, seat := range a.Seats {
+			m[seat.SeatID] = seat
+		}
+		return m
+	}
+
+	later := NewSegmentSeatAvailability("t1", seats, SegmentStop{StopID: "s2", StopOrder: 2}, SegmentStop{StopID: "s3", StopOrder: 3})
+	assert.Equal(t, 6, later.TotalSeats)
+	assert.Equal(t, 1, later.AvailableSeats, "only seats a hold can take are counted")
+	got := byID(later)
+	assert.True(t, got["1"].Available)
+	assert.True(t, got["1"].SegmentFree)
+	// Frees up where the passenger boards, but booking still takes the seat for the whole trip
+	assert.False(t, got["2"].Available)
+	assert.True(t, got["2"].SegmentFree)
+	assert.Equal(t, TripSeatStatusBooked, got["2"].Status)
+	assert.False(t, got["3"].SegmentFree)
+	assert.False(t, got["4"].SegmentFree)
+	assert.Equal(t, TripSeatStatusBlocked, got["5"].Status)
+	assert.False(t, got["6"].Available)
+	assert.Equal(t, TripSeatStatusReserved, got["6"].Status)
+
+	whole := NewSegmentSeatAvailability("t1", seats, SegmentStop{StopOrder: 1}, SegmentStop{StopOrder: 3})
+	assert.Equal(t, 1, whole.AvailableSeats)
+	assert.False(t, byID(whole)["2"].SegmentFree)
+}
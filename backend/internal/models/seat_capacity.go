@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capacity limits a seat layout is checked against
+const (
+	SeatCapacityLimitBus    = "bus_registered_capacity"
+	SeatCapacityLimitPermit = "permit_approved_capacity"
+)
+
+// SeatCapacityCheck compares a seat layout's seats with the registered capacity of the bus and
+// the approved seating capacity of the route permit it is used with. A capacity that was never
+// recorded is not checked.
+type SeatCapacityCheck struct {
+	TripID                 *string `json:"trip_id,omitempty" db:"trip_id"`
+	LayoutID               string  `json:"layout_id" db:"layout_id"`
+	LayoutName             string  `json:"layout_name" db:"layout_name"`
+	LayoutSeats            int     `json:"layout_seats" db:"layout_seats"`
+	BusID                  *string `json:"bus_id,omitempty" db:"bus_id"`
+	BusLicensePlate        *string `json:"bus_license_plate,omitempty" db:"bus_license_plate"`
+	BusSeatingCapacity     *int    `json:"bus_seating_capacity,omitempty" db:"bus_seating_capacity"`
+	PermitID               *string `json:"permit_id,omitempty" db:"permit_id"`
+	PermitNumber           *string `json:"permit_number,omitempty" db:"permit_number"`
+	PermitApprovedCapacity *int    `json:"permit_approved_capacity,omitempty" db:"permit_approved_capacity"`
+
+	Violations []SeatCapacityViolation `json:"violations" db:"-"`
+}
+
+// SeatCapacityViolation is one capacity the layout has more seats than
+type SeatCapacityViolation struct {
+	Limit       string `json:"limit"` // bus_registered_capacity or permit_approved_capacity
+	Capacity    int    `json:"capacity"`
+	LayoutSeats int    `json:"layout_seats"`
+	Excess      int    `json:"excess"`
+	Message     string `json:"message"`
+}
+
+// Evaluate fills in the violations and reports whether there are any
+func (c *SeatCapacityCheck) Evaluate() bool {
+	c.Violations = []SeatCapacityViolation{}
+	if c.BusSeatingCapacity != nil && *c.BusSeatingCapacity > 0 && c.LayoutSeats > *c.BusSeatingCapacity {
+		bus := "the bus"
+		if c.BusLicensePlate != nil {
+			bus = "bus " + *c.BusLicensePlate
+		}
+		c.Violations = append(c.Violations, SeatCapacityViolation{
+			Limit:       SeatCapacityLimitBus,
+			Capacity:    *c.BusSeatingCapacity,
+			LayoutSeats: c.LayoutSeats,
+			Excess:      c.LayoutSeats - *c.BusSeatingCapacity,
+			Message:     fmt.Sprintf("Layout %q has %d seats but %s is registered for %d", c.LayoutName, c.LayoutSeats, bus, *c.BusSeatingCapacity),
+		})
+	}
+	if c.PermitApprovedCapacity != nil && *c.PermitApprovedCapacity > 0 && c.LayoutSeats > *c.PermitApprovedCapacity {
+		permit := "the route permit"
+		if c.PermitNumber != nil {
+			permit = "permit " + *c.PermitNumber
+		}
+		c.Violations = append(c.Violations, SeatCapacityViolation{
+			Limit:       SeatCapacityLimitPermit,
+			Capacity:    *c.PermitApprovedCapacity,
+			LayoutSeats: c.LayoutSeats,
+			Excess:      c.LayoutSeats - *c.PermitApprovedCapacity,
+			Message:     fmt.Sprintf("Layout %q has %d seats but %s approves %d", c.LayoutName, c.LayoutSeats, permit, *c.PermitApprovedCapacity),
+		})
+	}
+	return len(c.Violations) > 0
+}
+
+// SeatCapacityError is returned when a seat layout has more seats than its bus or permit allows
+type SeatCapacityError struct {
+	Checks  []SeatCapacityCheck `json:"checks"`
+	Message string              `json:"message"`
+}
+
+func (e *SeatCapacityError) Error() string {
+	return e.Message
+}
+
+// NewSeatCapacityError evaluates the checks and returns a SeatCapacityError for those over
+// capacity, or nil if none are
+func NewSeatCapacityError(checks []SeatCapacityCheck) error {
+	var over []SeatCapacityCheck
+	var messages []string
+	for _, check := range checks {
+		if check.Evaluate() {
+			over = append(over, check)
+			for _, v := range check.Violations {
+				messages = append(messages, v.Message)
+			}
+		}
+	}
+	if len(over) == 0 {
+		return nil
+	}
+	return &SeatCapacityError{Checks: over, Message: strings.Join(messages, "; ")}
+}
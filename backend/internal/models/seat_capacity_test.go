@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeatCapacityCheck_Evaluate(t *testing.T) {
+	plate, permit := "NB-1234", "RP-2024-001"
+	thirtyTwo, fifty := 32, 50
+
+	check := SeatCapacityCheck{LayoutName: "54 seater", LayoutSeats: 54, BusLicensePlate: &plate, BusSeatingCapacity: &thirtyTwo, PermitNumber: &permit, PermitApprovedCapacity: &fifty}
+	require.True(t, check.Evaluate())
+	require.Len(t, check.Violations, 2)
+	assert.Equal(t, SeatCapacityLimitBus, check.Violations[0].Limit)
+	assert.Equal(t, 22, check.Violations[0].Excess)
+	assert.Contains(t, check.Violations[0].Message, "bus NB-1234 is registered for 32")
+	assert.Equal(t, SeatCapacityLimitPermit, check.Violations[1].Limit)
+	assert.Equal(t, 4, check.Violations[1].Excess)
+
+	fits := SeatCapacityCheck{LayoutSeats: 32, BusSeatingCapacity: &thirtyTwo, PermitApprovedCapacity: &fifty}
+	assert.False(t, fits.Evaluate())
+	assert.Empty(t, fits.Violations)
+
+	unknown := SeatCapacityCheck{LayoutSeats: 54}
+	assert.False(t, unknown.Evaluate(), "capacities never recorded are not checked")
+}
+
+func TestNewSeatCapacityError(t *testing.T) {
+	thirtyTwo := 32
+	assert.NoError(t, NewSeatCapacityError([]SeatCapacityCheck{{LayoutSeats: 30, BusSeatingCapacity: &thirtyTwo}}))
+
+	err := NewSeatCapacityError([]SeatCapacityCheck{
+		{LayoutSeats: 30, BusSeatingCapacity: &thirtyTwo},
+		{LayoutName: "big", LayoutSeats: 40, BusSeatingCapacity: &thirtyTwo},
+	})
+	var capacityErr *SeatCapacityError
+	require.True(t, errors.As(err, &capacityErr))
+	require.Len(t, capacityErr.Checks, 1)
+	assert.Equal(t, "big", capacityErr.Checks[0].LayoutName)
+	assert.Contains(t, err.Error(), "40 seats")
+}
@@ -0,0 +1,287 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SeatPricingRuleType is the condition a dynamic pricing rule is evaluated on
+type SeatPricingRuleType string
+
+const (
+	SeatPricingAdvance   SeatPricingRuleType = "advance"     // Hours left before departure
+	SeatPricingDayOfWeek SeatPricingRuleType = "day_of_week" // Departure weekday, e.g. weekends
+	SeatPricingOccupancy SeatPricingRuleType = "occupancy"   // Share of the trip's seats already taken
+)
+
+// Bounds on a rule's multiplier and on all of a trip's rules combined
+const (
+	MinSeatPriceMultiplier = 0.5
+	MaxSeatPriceMultiplier = 2.0
+)
+
+// SeatPricingRule scales the seat price of a bus owner's trips on a route or schedule
+// (seat_pricing_rules table). At most one rule of each type applies to a trip, and the
+// schedule's rules of a type replace the route's. The multipliers of the applying rules
+// are multiplied together with the trip's base fare.
+type SeatPricingRule struct {
+	ID              string              `json:"id" db:"id"`
+	BusOwnerID      string              `json:"bus_owner_id" db:"bus_owner_id"`
+	BusOwnerRouteID *string             `json:"bus_owner_route_id,omitempty" db:"bus_owner_route_id"`
+	TripScheduleID  *string             `json:"trip_schedule_id,omitempty" db:"trip_schedule_id"`
+	RuleType        SeatPricingRuleType `json:"rule_type" db:"rule_type"`
+	Name            string              `json:"name" db:"name"`
+	Multiplier      float64             `json:"multiplier" db:"multiplier"` // 1.15 adds 15%, 0.9 takes 10% off
+
+	// advance: applies while departure is between MinHoursBefore (inclusive) and MaxHoursBefore hours away
+	MinHoursBefore *int `json:"min_hours_before,omitempty" db:"min_hours_before"`
+	MaxHoursBefore *int `json:"max_hours_before,omitempty" db:"max_hours_before"`
+	// day_of_week: departure weekdays in Sri Lanka time, 0 = Sunday
+	Weekdays pq.Int64Array `json:"weekdays,omitempty" db:"weekdays"`
+	// occupancy: applies once at least this percent of seats are booked or reserved
+	MinOccupancyPercent *int `json:"min_occupancy_percent,omitempty" db:"min_occupancy_percent"`
+
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SeatPricingContext is the state of a trip that rules are evaluated against
+type SeatPricingContext struct {
+	Now               time.Time
+	DepartureDatetime time.Time
+	OccupancyPercent  float64
+}
+
+// Matches reports whether the rule's condition holds for the trip
+func (r *SeatPricingRule) Matches(ctx SeatPricingContext) bool {
+	switch r.RuleType {
+	case SeatPricingAdvance:
+		hoursLeft := ctx.DepartureDatetime.Sub(ctx.Now).Hours()
+		if r.MinHoursBefore != nil && hoursLeft < float64(*r.MinHoursBefore) {
+			return false
+		}
+		return r.MaxHoursBefore == nil || hoursLeft < float64(*r.MaxHoursBefore)
+	case SeatPricingDayOfWeek:
+		weekday := int64(ctx.DepartureDatetime.In(ReportTimezone).Weekday())
+		for _, day := range r.Weekdays {
+			if day == weekday {
+				return true
+			}
+		}
+		return false
+	case SeatPricingOccupancy:
+		return r.MinOccupancyPercent != nil && ctx.OccupancyPercent >= float64(*r.MinOccupancyPercent)
+	}
+	return false
+}
+
+// moreSpecific reports whether r should apply instead of other when both match: the higher
+// occupancy threshold, the nearer departure window, or the fewer weekdays
+func (r *SeatPricingRule) moreSpecific(other *SeatPricingRule) bool {
+	switch r.RuleType {
+	case SeatPricingOccupancy:
+		return *r.MinOccupancyPercent > *other.MinOccupancyPercent
+	case SeatPricingAdvance:
+		rMax, oMax := intOr(r.MaxHoursBefore, math.MaxInt), intOr(other.MaxHoursBefore, math.MaxInt)
+		if rMax != oMax {
+			return rMax < oMax
+		}
+		return intOr(r.MinHoursBefore, 0) > intOr(other.MinHoursBefore, 0)
+	case SeatPricingDayOfWeek:
+		return len(r.Weekdays) < len(other.Weekdays)
+	}
+	return false
+}
+
+func intOr(v *int, fallback int) int {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
+
+// AppliedSeatPricingRule records a rule that priced a trip's seats
+type AppliedSeatPricingRule struct {
+	ID         string              `json:"id"`
+	Name       string              `json:"name"`
+	RuleType   SeatPricingRuleType `json:"rule_type"`
+	Multiplier float64             `json:"multiplier"`
+}
+
+// SeatPriceEvaluation is a trip's seat price after its pricing rules
+type SeatPriceEvaluation struct {
+	BaseFare     float64                  `json:"base_fare"`
+	Price        float64                  `json:"price"`
+	Multiplier   float64                  `json:"multiplier"` // Combined, after the bounds
+	Capped       bool                     `json:"capped"`     // Limited by the permit's approved fare
+	AppliedRules []AppliedSeatPricingRule `json:"applied_rules"`
+}
+
+// EvaluateSeatPricing prices a trip's seats from its base fare. Inactive rules are ignored. The
+// combined multiplier stays within MinSeatPriceMultiplier and MaxSeatPriceMultiplier, and a raise
+// never takes the price above the fare cap (nor raises a base fare already above it).
+func EvaluateSeatPricing(rules []SeatPricingRule, baseFare float64, fareCap *float64, ctx SeatPricingContext) *SeatPriceEvaluation {
+	// The schedule's rules of a type replace the route's
+	scheduleTypes := map[SeatPricingRuleType]bool{}
+	for i := range rules {
+		if rules[i].IsActive && rules[i].TripScheduleID != nil {
+			scheduleTypes[rules[i].RuleType] = true
+		}
+	}
+
+	chosen := map[SeatPricingRuleType]*SeatPricingRule{}
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.IsActive || (rule.TripScheduleID == nil && scheduleTypes[rule.RuleType]) || !rule.Matches(ctx) {
+			continue
+		}
+		if current, ok := chosen[rule.RuleType]; !ok || rule.moreSpecific(current) {
+			chosen[rule.RuleType] = rule
+		}
+	}
+
+	eval := &SeatPriceEvaluation{BaseFare: baseFare, Price: baseFare, Multiplier: 1, AppliedRules: []AppliedSeatPricingRule{}}
+	for _, ruleType := range []SeatPricingRuleType{SeatPricingAdvance, SeatPricingDayOfWeek, SeatPricingOccupancy} {
+		rule, ok := chosen[ruleType]
+		if !ok {
+			continue
+		}
+		eval.Multiplier *= rule.Multiplier
+		eval.AppliedRules = append(eval.AppliedRules, AppliedSeatPricingRule{
+			ID:         rule.ID,
+			Name:       rule.Name,
+			RuleType:   rule.RuleType,
+			Multiplier: rule.Multiplier,
+		})
+	}
+	eval.Multiplier = math.Round(math.Min(MaxSeatPriceMultiplier, math.Max(MinSeatPriceMultiplier, eval.Multiplier))*1000) / 1000
+	if baseFare <= 0 || len(eval.AppliedRules) == 0 {
+		return eval
+	}
+
+	price := baseFare * eval.Multiplier
+	if price > baseFare && fareCap != nil && *fareCap > 0 && price > *fareCap {
+		price = math.Max(baseFare, *fareCap)
+		eval.Capped = true
+	}
+	eval.Price = RoundMoney(price)
+	return eval
+}
+
+// SeatPricingRuleFields are the condition and effect of a pricing rule
+type SeatPricingRuleFields struct {
+	RuleType            SeatPricingRuleType `json:"rule_type" binding:"required"`
+	Name                string              `json:"name" binding:"required,min=2,max=100"`
+	Multiplier          float64             `json:"multiplier" binding:"required"`
+	MinHoursBefore      *int                `json:"min_hours_before,omitempty" binding:"omitempty,min=0"`
+	MaxHoursBefore      *int                `json:"max_hours_before,omitempty" binding:"omitempty,min=1"`
+	Weekdays            []int64             `json:"weekdays,omitempty"`
+	MinOccupancyPercent *int                `json:"min_occupancy_percent,omitempty" binding:"omitempty,min=1,max=100"`
+	IsActive            *bool               `json:"is_active,omitempty"` // Defaults to true
+}
+
+// Validate checks that the fields set are the ones the rule type needs
+func (f *SeatPricingRuleFields) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return &ValidationError{Message: "name is required"}
+	}
+	if f.Multiplier < MinSeatPriceMultiplier || f.Multiplier > MaxSeatPriceMultiplier {
+		return &ValidationError{Message: "multiplier must be between 0.5 and 2.0"}
+	}
+
+	hasHours := f.MinHoursBefore != nil || f.MaxHoursBefore != nil
+	switch f.RuleType {
+	case SeatPricingAdvance:
+		if !hasHours {
+			return &ValidationError{Message: "min_hours_before or max_hours_before is required for an advance rule"}
+		}
+		if f.MinHoursBefore != nil && f.MaxHoursBefore != nil && *f.MaxHoursBefore <= *f.MinHoursBefore {
+			return &ValidationError{Message: "max_hours_before must be greater than min_hours_before"}
+		}
+		if len(f.Weekdays) > 0 || f.MinOccupancyPercent != nil {
+			return &ValidationError{Message: "an advance rule only takes min_hours_before and max_hours_before"}
+		}
+	case SeatPricingDayOfWeek:
+		if len(f.Weekdays) == 0 {
+			return &ValidationError{Message: "weekdays is required for a day_of_week rule"}
+		}
+		seen := map[int64]bool{}
+		for _, day := range f.Weekdays {
+			if day < 0 || day > 6 || seen[day] {
+				return &ValidationError{Message: "weekdays must be distinct days from 0 (Sunday) to 6 (Saturday)"}
+			}
+			seen[day] = true
+		}
+		if hasHours || f.MinOccupancyPercent != nil {
+			return &ValidationError{Message: "a day_of_week rule only takes weekdays"}
+		}
+	case SeatPricingOccupancy:
+		if f.MinOccupancyPercent == nil {
+			return &ValidationError{Message: "min_occupancy_percent is required for an occupancy rule"}
+		}
+		if hasHours || len(f.Weekdays) > 0 {
+			return &ValidationError{Message: "an occupancy rule only takes min_occupancy_percent"}
+		}
+	default:
+		return &ValidationError{Message: "rule_type must be advance, day_of_week or occupancy"}
+	}
+	return nil
+}
+
+// ApplyTo copies the validated fields onto a rule
+func (f *SeatPricingRuleFields) ApplyTo(rule *SeatPricingRule) {
+	rule.RuleType = f.RuleType
+	rule.Name = strings.TrimSpace(f.Name)
+	rule.Multiplier = f.Multiplier
+	rule.MinHoursBefore = f.MinHoursBefore
+	rule.MaxHoursBefore = f.MaxHoursBefore
+	rule.Weekdays = nil
+	if len(f.Weekdays) > 0 {
+		rule.Weekdays = pq.Int64Array(f.Weekdays)
+	}
+	rule.MinOccupancyPercent = f.MinOccupancyPercent
+	rule.IsActive = f.IsActive == nil || *f.IsActive
+}
+
+// CreateSeatPricingRuleRequest adds a pricing rule to one of the owner's routes or schedules
+type CreateSeatPricingRuleRequest struct {
+	BusOwnerRouteID *string `json:"bus_owner_route_id,omitempty" binding:"omitempty,uuid"`
+	TripScheduleID  *string `json:"trip_schedule_id,omitempty" binding:"omitempty,uuid"`
+	SeatPricingRuleFields
+}
+
+// Validate checks the request; exactly one of a route or a schedule is required
+func (r *CreateSeatPricingRuleRequest) Validate() error {
+	hasRoute := r.BusOwnerRouteID != nil && *r.BusOwnerRouteID != ""
+	hasSchedule := r.TripScheduleID != nil && *r.TripScheduleID != ""
+	if hasRoute == hasSchedule {
+		return &ValidationError{Message: "exactly one of bus_owner_route_id or trip_schedule_id is required"}
+	}
+	return r.SeatPricingRuleFields.Validate()
+}
+
+// SeatPricingTrip is a trip whose seats are priced by rules, with what is needed to evaluate them
+type SeatPricingTrip struct {
+	TripID            string    `db:"trip_id"`
+	BusOwnerID        *string   `db:"bus_owner_id"`
+	TripScheduleID    *string   `db:"trip_schedule_id"`
+	BusOwnerRouteID   *string   `db:"bus_owner_route_id"` // The trip's own route, else its schedule's
+	DepartureDatetime time.Time `db:"departure_datetime"`
+	BaseFare          float64   `db:"base_fare"`
+	FareCap           *float64  `db:"fare_cap"`
+	TotalSeats        int       `db:"total_seats"`
+	TakenSeats        int       `db:"taken_seats"` // Booked or reserved
+}
+
+// Context returns the trip's state for evaluating rules at now
+func (t *SeatPricingTrip) Context(now time.Time) SeatPricingContext {
+	ctx := SeatPricingContext{Now: now, DepartureDatetime: t.DepartureDatetime}
+	if t.TotalSeats > 0 {
+		ctx.OccupancyPercent = float64(t.TakenSeats) * 100 / float64(t.TotalSeats)
+	}
+	return ctx
+}
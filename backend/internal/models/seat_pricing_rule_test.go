@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateSeatPricing(t *testing.T) {
+	route, schedule := "route-1", "schedule-1"
+	hours := func(h int) *int { return &h }
+	// Saturday morning in Sri Lanka
+	departure := time.Date(2026, 10, 17, 7, 0, 0, 0, ReportTimezone)
+	ctx := SeatPricingContext{Now: departure.Add(-10 * time.Hour), DepartureDatetime: departure, OccupancyPercent: 85}
+
+	rules := []SeatPricingRule{
+		{ID: "last-day", BusOwnerRouteID: &route, RuleType: SeatPricingAdvance, MaxHoursBefore: hours(24), Multiplier: 1.1, IsActive: true},
+		{ID: "early-bird", BusOwnerRouteID: &route, RuleType: SeatPricingAdvance, MinHoursBefore: hours(168), Multiplier: 0.9, IsActive: true},
+		{ID: "weekend", BusOwnerRouteID: &route, RuleType: SeatPricingDayOfWeek, Weekdays: pq.Int64Array{0, 6}, Multiplier: 1.2, IsActive: true},
+		{ID: "half-full", BusOwnerRouteID: &route, RuleType: SeatPricingOccupancy, MinOccupancyPercent: hours(50), Multiplier: 1.05, IsActive: true},
+		{ID: "nearly-full", BusOwnerRouteID: &route, RuleType: SeatPricingOccupancy, MinOccupancyPercent: hours(80), Multiplier: 1.25, IsActive: true},
+		{ID: "off", BusOwnerRouteID: &route, RuleType: SeatPricingDayOfWeek, Weekdays: pq.Int64Array{6}, Multiplier: 2, IsActive: false},
+	}
+
+	eval := EvaluateSeatPricing(rules, 1000, nil, ctx)
+	require.Len(t, eval.AppliedRules, 3)
+	assert.Equal(t, "last-day", eval.AppliedRules[0].ID)
+	assert.Equal(t, "weekend", eval.AppliedRules[1].ID, "inactive rules are ignored")
+	assert.Equal(t, "nearly-full", eval.AppliedRules[2].ID, "highest occupancy tier reached")
+	assert.Equal(t, 1.65, eval.Multiplier)
+	assert.Equal(t, 1650.0, eval.Price)
+
+	weekdayOnly := append(rules, SeatPricingRule{ID: "schedule-weekdays", TripScheduleID: &schedule, RuleType: SeatPricingDayOfWeek, Weekdays: pq.Int64Array{1, 2, 3}, Multiplier: 1.5, IsActive: true})
+	eval = EvaluateSeatPricing(weekdayOnly, 1000, nil, ctx)
+	for _, applied := range eval.AppliedRules {
+		assert.NotEqual(t, SeatPricingDayOfWeek, applied.RuleType, "the schedule's day rules replace the route's")
+	}
+
+	capped := EvaluateSeatPricing(rules, 1000, ptrFloat(1200), ctx)
+	assert.True(t, capped.Capped)
+	assert.Equal(t, 1200.0, capped.Price)
+
+	early := ctx
+	early.Now = departure.AddDate(0, 0, -10)
+	early.OccupancyPercent = 0
+	eval = EvaluateSeatPricing(rules[:2], 1000, nil, early)
+	assert.Equal(t, 900.0, eval.Price, "discounts are not limited by the cap")
+
+	none := EvaluateSeatPricing(nil, 1000, nil, ctx)
+	assert.Equal(t, 1000.0, none.Price)
+	assert.Empty(t, none.AppliedRules)
+
+	surge := []SeatPricingRule{
+		{BusOwnerRouteID: &route, RuleType: SeatPricingDayOfWeek, Weekdays: pq.Int64Array{6}, Multiplier: 2, IsActive: true},
+		{BusOwnerRouteID: &route, RuleType: SeatPricingOccupancy, MinOccupancyPercent: hours(50), Multiplier: 2, IsActive: true},
+	}
+	assert.Equal(t, 2000.0, EvaluateSeatPricing(surge, 1000, nil, ctx).Price, "combined multiplier is bounded")
+}
+
+func ptrFloat(v float64) *float64 { return &v }
+
+func TestSeatPricingRuleFields_Validate(t *testing.T) {
+	hours := func(h int) *int { return &h }
+
+	assert.NoError(t, (&SeatPricingRuleFields{RuleType: SeatPricingAdvance, Name: "Last day", Multiplier: 1.1, MaxHoursBefore: hours(24)}).Validate())
+	assert.NoError(t, (&SeatPricingRuleFields{RuleType: SeatPricingDayOfWeek, Name: "Weekend", Multiplier: 1.2, Weekdays: []int64{0, 6}}).Validate())
+	assert.NoError(t, (&SeatPricingRuleFields{RuleType: SeatPricingOccupancy, Name: "Surge", Multiplier: 1.3, MinOccupancyPercent: hours(80)}).Validate())
+
+	for name, fields := range map[string]*SeatPricingRuleFields{
+		"unknown type":           {RuleType: "season", Name: "Avurudu", Multiplier: 1.1},
+		"multiplier too high":    {RuleType: SeatPricingOccupancy, Name: "Surge", Multiplier: 3, MinOccupancyPercent: hours(80)},
+		"multiplier too low":     {RuleType: SeatPricingOccupancy, Name: "Surge", Multiplier: 0.2, MinOccupancyPercent: hours(80)},
+		"advance without window": {RuleType: SeatPricingAdvance, Name: "Last day", Multiplier: 1.1},
+		"inverted window":        {RuleType: SeatPricingAdvance, Name: "Window", Multiplier: 1.1, MinHoursBefore: hours(48), MaxHoursBefore: hours(24)},
+		"bad weekday":            {RuleType: SeatPricingDayOfWeek, Name: "Weekend", Multiplier: 1.2, Weekdays: []int64{7}},
+		"repeated weekday":       {RuleType: SeatPricingDayOfWeek, Name: "Weekend", Multiplier: 1.2, Weekdays: []int64{6, 6}},
+		"mixed conditions":       {RuleType: SeatPricingOccupancy, Name: "Surge", Multiplier: 1.3, MinOccupancyPercent: hours(80), Weekdays: []int64{6}},
+	} {
+		assert.Error(t, fields.Validate(), name)
+	}
+
+	route := "route-1"
+	req := &CreateSeatPricingRuleRequest{SeatPricingRuleFields: SeatPricingRuleFields{RuleType: SeatPricingOccupancy, Name: "Surge", Multiplier: 1.3, MinOccupancyPercent: hours(80)}}
+	assert.Error(t, req.Validate(), "a route or schedule is required")
+	req.BusOwnerRouteID = &route
+	assert.NoError(t, req.Validate())
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// SeatSwapRequest moves a booked passenger to another free seat on the same trip
+type SeatSwapRequest struct {
+	BusBookingSeatID string `json:"bus_booking_seat_id" binding:"required"` // The passenger's seat booking
+	ToTripSeatID     string `json:"to_trip_seat_id" binding:"required"`     // The free seat to move them to
+	Note             string `json:"note" binding:"required,max=500"`        // e.g. "Seat A3 double-booked with a walk-in"
+}
+
+// Validate trims the note and checks it says why the passenger is being moved
+func (r *SeatSwapRequest) Validate() error {
+	r.Note = strings.TrimSpace(r.Note)
+	if len(r.Note) < 3 {
+		return &ValidationError{Message: "note must explain the swap"}
+	}
+	return nil
+}
+
+// swappableSeatStatuses are the seat booking statuses a passenger can still be moved from
+var swappableSeatStatuses = map[SeatBookingStatus]bool{
+	SeatBookingBooked:    true,
+	SeatBookingCheckedIn: true,
+	SeatBookingBoarded:   true,
+}
+
+// SeatSwapTarget is a passenger's seat booking with the trip's crew and bus owner, who may
+// move them to another seat
+type SeatSwapTarget struct {
+	BusBookingSeatID string            `db:"bus_booking_seat_id"`
+	BusBookingID     string            `db:"bus_booking_id"`
+	ScheduledTripID  string            `db:"scheduled_trip_id"`
+	TripSeatID       *string           `db:"trip_seat_id"`
+	SeatNumber       *string           `db:"seat_number"`
+	Status           SeatBookingStatus `db:"status"`
+	DriverUserID     *string           `db:"driver_user_id"`
+	ConductorUserID  *string           `db:"conductor_user_id"`
+	OwnerUserID      *string           `db:"owner_user_id"`
+}
+
+// CanBeSwappedBy reports whether the user is the trip's driver, conductor or bus owner
+func (t *SeatSwapTarget) CanBeSwappedBy(userID string) bool {
+	for _, allowed := range []*string{t.DriverUserID, t.ConductorUserID, t.OwnerUserID} {
+		if allowed != nil && *allowed == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSwappable reports whether the passenger still holds their seat
+func (t *SeatSwapTarget) IsSwappable() bool {
+	return swappableSeatStatuses[t.Status]
+}
+
+// SeatSwap is a completed move of a passenger from one seat to another
+type SeatSwap struct {
+	BusBookingSeatID string    `json:"bus_booking_seat_id"`
+	BusBookingID     string    `json:"bus_booking_id"`
+	ScheduledTripID  string    `json:"scheduled_trip_id"`
+	FromTripSeatID   *string   `json:"from_trip_seat_id,omitempty"`
+	FromSeatNumber   *string   `json:"from_seat_number,omitempty"`
+	ToTripSeatID     string    `json:"to_trip_seat_id"`
+	ToSeatNumber     string    `json:"to_seat_number"`
+	Note             string    `json:"note"`
+	SwappedAt        time.Time `json:"swapped_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeatSwapRequest_Validate(t *testing.T) {
+	req := &SeatSwapRequest{BusBookingSeatID: "seat", ToTripSeatID: "b3", Note: "  A3 double-booked with a walk-in "}
+	assert.NoError(t, req.Validate())
+	assert.Equal(t, "A3 double-booked with a walk-in", req.Note)
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, (&SeatSwapRequest{Note: "   "}).Validate(), &validationErr, "a blank note is not an explanation")
+}
+
+func TestSeatSwapTarget(t *testing.T) {
+	conductor, owner := "user-conductor", "user-owner"
+	target := &SeatSwapTarget{ConductorUserID: &conductor, OwnerUserID: &owner, Status: SeatBookingCheckedIn}
+
+	assert.True(t, target.CanBeSwappedBy("user-conductor"))
+	assert.True(t, target.CanBeSwappedBy("user-owner"))
+	assert.False(t, target.CanBeSwappedBy("user-other"))
+	assert.False(t, (&SeatSwapTarget{}).CanBeSwappedBy(""), "a trip without crew admits nobody")
+
+	assert.True(t, target.IsSwappable())
+	for _, status := range []SeatBookingStatus{SeatBookingCancelled, SeatBookingNoShow, SeatBookingCompleted} {
+		target.Status = status
+		assert.False(t, target.IsSwappable(), status)
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// DeviceKeyAlgorithm is the signature scheme of a device credential's public key
+type DeviceKeyAlgorithm string
+
+const (
+	DeviceKeyECDSAP256 DeviceKeyAlgorithm = "ecdsa-p256" // Android Keystore / iOS Secure Enclave (ASN.1 DER signature over SHA-256)
+	DeviceKeyEd25519   DeviceKeyAlgorithm = "ed25519"
+)
+
+// StaffDeviceCredential is a long-lived login credential for a driver/conductor device
+// (staff_device_credentials table). The private key never leaves the device and is
+// unlocked by the device's biometric prompt; the server only stores the public key.
+type StaffDeviceCredential struct {
+	ID           string             `json:"id" db:"id"`
+	UserID       string             `json:"user_id" db:"user_id"`
+	StaffID      string             `json:"staff_id" db:"staff_id"`
+	DeviceID     string             `json:"device_id" db:"device_id"`
+	DeviceName   *string            `json:"device_name,omitempty" db:"device_name"`
+	PublicKey    string             `json:"-" db:"public_key"` // Base64 PKIX (SubjectPublicKeyInfo) DER
+	KeyAlgorithm DeviceKeyAlgorithm `json:"key_algorithm" db:"key_algorithm"`
+	ExpiresAt    time.Time          `json:"expires_at" db:"expires_at"`
+	LastUsedAt   *time.Time         `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt    *time.Time         `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedBy    *string            `json:"revoked_by,omitempty" db:"revoked_by"` // User ID of the staff member or bus owner
+	RevokeReason *string            `json:"revoke_reason,omitempty" db:"revoke_reason"`
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`
+}
+
+// IsActive reports whether the credential can still be used to log in
+func (c *StaffDeviceCredential) IsActive() bool {
+	return c.RevokedAt == nil && time.Now().Before(c.ExpiresAt)
+}
+
+// StaffDeviceChallenge is a single-use nonce the device signs to log in (staff_device_challenges table)
+type StaffDeviceChallenge struct {
+	ID           string     `json:"challenge_id" db:"id"`
+	CredentialID string     `json:"-" db:"credential_id"`
+	Nonce        string     `json:"challenge" db:"nonce"` // Base64; the device signs these exact bytes
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt       *time.Time `json:"-" db:"used_at"`
+	CreatedAt    time.Time  `json:"-" db:"created_at"`
+}
+
+// RegisterStaffDeviceRequest registers a device key after an OTP login
+type RegisterStaffDeviceRequest struct {
+	DeviceID     string             `json:"device_id" binding:"required"`
+	DeviceName   *string            `json:"device_name"`
+	PublicKey    string             `json:"public_key" binding:"required"` // Base64 PKIX DER
+	KeyAlgorithm DeviceKeyAlgorithm `json:"key_algorithm" binding:"required"`
+}
+
+// StaffDeviceChallengeRequest asks for a login challenge for a registered device
+type StaffDeviceChallengeRequest struct {
+	CredentialID string `json:"credential_id" binding:"required"`
+}
+
+// StaffDeviceLoginRequest exchanges a signed challenge for tokens
+type StaffDeviceLoginRequest struct {
+	CredentialID string `json:"credential_id" binding:"required"`
+	ChallengeID  string `json:"challenge_id" binding:"required"`
+	Signature    string `json:"signature" binding:"required"` // Base64 signature over the decoded challenge bytes
+}
+
+// RevokeStaffDeviceRequest optionally explains why a device was revoked
+type RevokeStaffDeviceRequest struct {
+	Reason string `json:"reason"`
+}
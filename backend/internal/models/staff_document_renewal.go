@@ -0,0 +1,183 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Staff document types. The license a staff member holds depends on their type: drivers a
+// driving license, conductors an NTC conductor license.
+const (
+	StaffDocDrivingLicense     = "driving_license"
+	StaffDocNTCLicense         = "ntc_license"
+	StaffDocNIC                = "nic"
+	StaffDocMedicalCertificate = "medical_certificate"
+)
+
+// StaffDocumentExpiryWarningDays is how long before expiry a document is reported as expiring soon
+const StaffDocumentExpiryWarningDays = 30
+
+// StaffDocumentRenewalStatus is where an uploaded document is in review
+type StaffDocumentRenewalStatus string
+
+const (
+	StaffDocumentRenewalPending    StaffDocumentRenewalStatus = "pending"
+	StaffDocumentRenewalApproved   StaffDocumentRenewalStatus = "approved"
+	StaffDocumentRenewalRejected   StaffDocumentRenewalStatus = "rejected"
+	StaffDocumentRenewalSuperseded StaffDocumentRenewalStatus = "superseded" // Replaced by a newer upload or approval
+)
+
+// StaffDocumentRenewal is a document a staff member uploaded for their bus owner or an admin to
+// review (staff_document_renewals table). Approving a license renewal updates the license on the
+// staff profile, which trip assignment checks against the departure date.
+type StaffDocumentRenewal struct {
+	ID              string                     `json:"id" db:"id"`
+	StaffID         string                     `json:"staff_id" db:"staff_id"`
+	DocumentType    string                     `json:"document_type" db:"document_type"`
+	DocumentNumber  *string                    `json:"document_number,omitempty" db:"document_number"`
+	ExpiryDate      *time.Time                 `json:"expiry_date,omitempty" db:"expiry_date"`
+	FileURL         string                     `json:"file_url" db:"file_url"`
+	Status          StaffDocumentRenewalStatus `json:"status" db:"status"`
+	ReviewedBy      *string                    `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt      *time.Time                 `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	RejectionReason *string                    `json:"rejection_reason,omitempty" db:"rejection_reason"`
+	CreatedAt       time.Time                  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time                  `json:"updated_at" db:"updated_at"`
+}
+
+// PendingStaffDocumentRenewal is a renewal in a reviewer's queue with who submitted it
+type PendingStaffDocumentRenewal struct {
+	StaffDocumentRenewal
+	FirstName         *string    `json:"first_name,omitempty" db:"first_name"`
+	LastName          *string    `json:"last_name,omitempty" db:"last_name"`
+	StaffType         StaffType  `json:"staff_type" db:"staff_type"`
+	BusOwnerID        *string    `json:"bus_owner_id,omitempty" db:"bus_owner_id"`               // Current employer, if any
+	CurrentExpiryDate *time.Time `json:"current_expiry_date,omitempty" db:"current_expiry_date"` // Expiry of the document being replaced
+}
+
+// SubmitStaffDocumentRequest is a staff member's renewal upload. The file is uploaded to storage
+// by the app first.
+type SubmitStaffDocumentRequest struct {
+	DocumentType   string `json:"document_type" binding:"required"`
+	DocumentNumber string `json:"document_number"`
+	ExpiryDate     string `json:"expiry_date"` // YYYY-MM-DD
+	FileURL        string `json:"file_url" binding:"required,url"`
+}
+
+// Validate checks the upload against the staff member's type and returns the parsed expiry date.
+// Licenses and medical certificates must carry a future expiry date; NICs need not expire.
+func (r *SubmitStaffDocumentRequest) Validate(staffType StaffType, now time.Time) (*time.Time, error) {
+	r.DocumentType = strings.TrimSpace(r.DocumentType)
+	r.DocumentNumber = strings.TrimSpace(r.DocumentNumber)
+
+	required := RequiredStaffDocuments(staffType)
+	allowed := false
+	for _, docType := range required {
+		if docType == r.DocumentType {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, &ValidationError{Message: "document_type must be one of " + strings.Join(required, ", ")}
+	}
+	if r.DocumentType != StaffDocMedicalCertificate && r.DocumentNumber == "" {
+		return nil, &ValidationError{Message: "document_number is required"}
+	}
+
+	if r.ExpiryDate == "" {
+		if r.DocumentType == StaffDocNIC {
+			return nil, nil
+		}
+		return nil, &ValidationError{Message: "expiry_date is required"}
+	}
+	expiry, err := time.Parse("2006-01-02", r.ExpiryDate)
+	if err != nil {
+		return nil, &ValidationError{Message: "expiry_date must be in YYYY-MM-DD format"}
+	}
+	if !expiry.After(now) {
+		return nil, &ValidationError{Message: "expiry_date must be in the future"}
+	}
+	return &expiry, nil
+}
+
+// RequiredStaffDocuments lists the documents a staff member of the type keeps on file
+func RequiredStaffDocuments(staffType StaffType) []string {
+	if staffType == StaffTypeConductor {
+		return []string{StaffDocNTCLicense, StaffDocNIC}
+	}
+	return []string{StaffDocDrivingLicense, StaffDocNIC, StaffDocMedicalCertificate}
+}
+
+// Staff document states as shown to the staff member
+const (
+	StaffDocumentValid        = "valid"
+	StaffDocumentExpiringSoon = "expiring_soon"
+	StaffDocumentExpired      = "expired"
+	StaffDocumentMissing      = "missing"
+)
+
+// StaffDocumentStatus is the state of one of a staff member's documents and any renewal in review
+type StaffDocumentStatus struct {
+	Type           string                `json:"type"`
+	Status         string                `json:"status"` // valid, expiring_soon, expired or missing
+	Number         *string               `json:"number,omitempty"`
+	ExpiryDate     *time.Time            `json:"expiry_date,omitempty"`
+	PendingRenewal *StaffDocumentRenewal `json:"pending_renewal,omitempty"`
+	LastRejection  *StaffDocumentRenewal `json:"last_rejection,omitempty"` // Most recent rejected upload, if newer than the approved document
+}
+
+// StaffDocumentStatuses reports each required document as of now. The license comes from the
+// staff profile; other documents from their latest approved upload. renewals is the staff
+// member's uploads, newest first.
+func StaffDocumentStatuses(staff *BusStaff, renewals []StaffDocumentRenewal, now time.Time) []StaffDocumentStatus {
+	required := RequiredStaffDocuments(staff.StaffType)
+	statuses := make([]StaffDocumentStatus, 0, len(required))
+	for _, docType := range required {
+		status := StaffDocumentStatus{Type: docType}
+		seenApproved, haveDocument := false, false
+
+		if docType == StaffDocDrivingLicense || docType == StaffDocNTCLicense {
+			haveDocument = staff.LicenseNumber != nil || staff.LicenseExpiryDate != nil
+			status.Number, status.ExpiryDate = staff.LicenseNumber, staff.LicenseExpiryDate
+		}
+		for i := range renewals {
+			renewal := &renewals[i]
+			if renewal.DocumentType != docType {
+				continue
+			}
+			switch renewal.Status {
+			case StaffDocumentRenewalApproved:
+				if !seenApproved {
+					seenApproved = true
+					if !haveDocument {
+						haveDocument = true
+						status.Number, status.ExpiryDate = renewal.DocumentNumber, renewal.ExpiryDate
+					}
+				}
+			case StaffDocumentRenewalPending:
+				if status.PendingRenewal == nil {
+					status.PendingRenewal = renewal
+				}
+			case StaffDocumentRenewalRejected:
+				if status.LastRejection == nil && !seenApproved {
+					status.LastRejection = renewal
+				}
+			}
+		}
+
+		switch {
+		case !haveDocument:
+			status.Status = StaffDocumentMissing
+		case status.ExpiryDate == nil:
+			status.Status = StaffDocumentValid
+		case status.ExpiryDate.Before(now):
+			status.Status = StaffDocumentExpired
+		case status.ExpiryDate.Before(now.AddDate(0, 0, StaffDocumentExpiryWarningDays)):
+			status.Status = StaffDocumentExpiringSoon
+		default:
+			status.Status = StaffDocumentValid
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
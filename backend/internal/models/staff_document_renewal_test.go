@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitStaffDocumentRequestValidate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	req := SubmitStaffDocumentRequest{DocumentType: " driving_license ", DocumentNumber: "B123", ExpiryDate: "2031-02-28"}
+	expiry, err := req.Validate(StaffTypeDriver, now)
+	require.NoError(t, err)
+	assert.Equal(t, "2031-02-28", expiry.Format("2006-01-02"))
+	assert.Equal(t, StaffDocDrivingLicense, req.DocumentType)
+
+	req = SubmitStaffDocumentRequest{DocumentType: StaffDocNIC, DocumentNumber: "199012345678"}
+	expiry, err = req.Validate(StaffTypeConductor, now)
+	require.NoError(t, err)
+	assert.Nil(t, expiry, "NICs need not expire")
+
+	var validationErr *ValidationError
+	for name, tc := range map[string]struct {
+		staffType StaffType
+		req       SubmitStaffDocumentRequest
+	}{
+		"conductor driving license": {StaffTypeConductor, SubmitStaffDocumentRequest{DocumentType: StaffDocDrivingLicense, DocumentNumber: "B1", ExpiryDate: "2030-01-01"}},
+		"conductor medical":         {StaffTypeConductor, SubmitStaffDocumentRequest{DocumentType: StaffDocMedicalCertificate, ExpiryDate: "2030-01-01"}},
+		"missing number":            {StaffTypeDriver, SubmitStaffDocumentRequest{DocumentType: StaffDocDrivingLicense, ExpiryDate: "2030-01-01"}},
+		"missing expiry":            {StaffTypeDriver, SubmitStaffDocumentRequest{DocumentType: StaffDocMedicalCertificate}},
+		"past expiry":               {StaffTypeDriver, SubmitStaffDocumentRequest{DocumentType: StaffDocMedicalCertificate, ExpiryDate: "2026-02-01"}},
+		"bad date":                  {StaffTypeDriver, SubmitStaffDocumentRequest{DocumentType: StaffDocMedicalCertificate, ExpiryDate: "01/02/2030"}},
+	} {
+		_, err := tc.req.Validate(tc.staffType, now)
+		assert.ErrorAs(t, err, &validationErr, name)
+	}
+}
+
+func TestStaffDocumentStatuses(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	license := "B1234567"
+	soon := now.AddDate(0, 0, 10)
+	nextYear := now.AddDate(1, 0, 0)
+	lastYear := now.AddDate(-1, 0, 0)
+	staff := &BusStaff{StaffType: StaffTypeDriver, LicenseNumber: &license, LicenseExpiryDate: &soon}
+
+	renewals := []StaffDocumentRenewal{ // newest first
+		{ID: "lic-new", DocumentType: StaffDocDrivingLicense, Status: StaffDocumentRenewalPending, ExpiryDate: &nextYear},
+		{ID: "med-rejected", DocumentType: StaffDocMedicalCertificate, Status: StaffDocumentRenewalRejected},
+		{ID: "med-old", DocumentType: StaffDocMedicalCertificate, Status: StaffDocumentRenewalApproved, ExpiryDate: &lastYear},
+		{ID: "med-older-rejected", DocumentType: StaffDocMedicalCertificate, Status: StaffDocumentRenewalRejected},
+	}
+
+	statuses := StaffDocumentStatuses(staff, renewals, now)
+	require.Len(t, statuses, 3)
+
+	assert.Equal(t, StaffDocDrivingLicense, statuses[0].Type)
+	assert.Equal(t, StaffDocumentExpiringSoon, statuses[0].Status)
+	assert.Equal(t, &license, statuses[0].Number)
+	require.NotNil(t, statuses[0].PendingRenewal)
+	assert.Equal(t, "lic-new", statuses[0].PendingRenewal.ID)
+
+	assert.Equal(t, StaffDocNIC, statuses[1].Type)
+	assert.Equal(t, StaffDocumentMissing, statuses[1].Status)
+
+	assert.Equal(t, StaffDocMedicalCertificate, statuses[2].Type)
+	assert.Equal(t, StaffDocumentExpired, statuses[2].Status)
+	require.NotNil(t, statuses[2].LastRejection)
+	assert.Equal(t, "med-rejected", statuses[2].LastRejection.ID)
+
+	conductor := StaffDocumentStatuses(&BusStaff{StaffType: StaffTypeConductor}, nil, now)
+	require.Len(t, conductor, 2)
+	assert.Equal(t, StaffDocNTCLicense, conductor[0].Type)
+}
@@ -0,0 +1,245 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// StandingTicketStatus tracks a standing passenger through the boarding queue
+type StandingTicketStatus string
+
+const (
+	StandingTicketWaiting   StandingTicketStatus = "waiting"
+	StandingTicketBoarded   StandingTicketStatus = "boarded"
+	StandingTicketSkipped   StandingTicketStatus = "skipped" // Not there when called; the place is freed
+	StandingTicketCancelled StandingTicketStatus = "cancelled"
+)
+
+// StandingTicketSource is who issued a standing ticket
+type StandingTicketSource string
+
+const (
+	StandingTicketSourceApp       StandingTicketSource = "app"       // Reserved by the passenger, paid to the conductor on boarding
+	StandingTicketSourceConductor StandingTicketSource = "conductor" // Sold by the crew for cash
+)
+
+// StandingPaymentStatus is whether a standing ticket has been paid for
+type StandingPaymentStatus string
+
+const (
+	StandingPaymentCollectOnBus StandingPaymentStatus = "collect_on_bus"
+	StandingPaymentPaid         StandingPaymentStatus = "paid"
+)
+
+// TripStandingSettings lets a trip sell unreserved standing tickets on top of its seats. The cap
+// can never exceed the standing capacity the bus is licensed for.
+type TripStandingSettings struct {
+	ScheduledTripID string    `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	Cap             int       `json:"cap" db:"cap"` // 0 stops standing sales
+	Fare            float64   `json:"fare" db:"fare"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateTripStandingRequest sets how many standing tickets a trip sells and at what fare
+type UpdateTripStandingRequest struct {
+	Cap  int      `json:"cap" binding:"min=0,max=100"`
+	Fare *float64 `json:"fare,omitempty" binding:"omitempty,gt=0"` // Defaults to the trip's base fare
+}
+
+// StandingTicket is an unreserved standing place on a trip. Passengers board in queue order,
+// showing their boarding token to the conductor.
+type StandingTicket struct {
+	ID                string                `json:"id" db:"id"`
+	ScheduledTripID   string                `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	QueuePosition     int                   `json:"queue_position" db:"queue_position"`
+	BoardingToken     string                `json:"boarding_token" db:"boarding_token"`
+	Source            StandingTicketSource  `json:"source" db:"source"`
+	PassengerUserID   *string               `json:"passenger_user_id,omitempty" db:"passenger_user_id"`
+	PassengerName     *string               `json:"passenger_name,omitempty" db:"passenger_name"`
+	Fare              float64               `json:"fare" db:"fare"`
+	PaymentStatus     StandingPaymentStatus `json:"payment_status" db:"payment_status"`
+	Status            StandingTicketStatus  `json:"status" db:"status"`
+	IssuedByUserID    *string               `json:"issued_by_user_id,omitempty" db:"issued_by_user_id"`
+	BoardedByUserID   *string               `json:"boarded_by_user_id,omitempty" db:"boarded_by_user_id"`
+	BoardedAt         *time.Time            `json:"boarded_at,omitempty" db:"boarded_at"`
+	SkippedAt         *time.Time            `json:"skipped_at,omitempty" db:"skipped_at"`
+	CancelledAt       *time.Time            `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt         time.Time             `json:"created_at" db:"created_at"`
+	DepartureDatetime time.Time             `json:"departure_datetime" db:"departure_datetime"`
+
+	// Filled in for the passenger's view of their place in the queue
+	PassengersAhead *int `json:"passengers_ahead,omitempty" db:"-"`
+}
+
+// IssueStandingTicketRequest is a conductor selling a standing ticket on the spot
+type IssueStandingTicketRequest struct {
+	PassengerName *string `json:"passenger_name,omitempty" binding:"omitempty,max=100"`
+}
+
+// ReserveStandingTicketRequest is a passenger joining a trip's standing queue
+type ReserveStandingTicketRequest struct {
+	ScheduledTripID string `json:"scheduled_trip_id" binding:"required,uuid"`
+}
+
+// ValidateStandingTicketRequest is a conductor scanning a standing passenger's boarding token
+type ValidateStandingTicketRequest struct {
+	BoardingToken string `json:"boarding_token" binding:"required"`
+}
+
+// StandingTrip is a trip with what decides whether it can sell and board standing passengers:
+// its standing settings, the bus's licensed standing capacity, and who may validate tickets
+type StandingTrip struct {
+	ScheduledTripID     string              `db:"scheduled_trip_id"`
+	Status              ScheduledTripStatus `db:"status"`
+	IsBookable          bool                `db:"is_bookable"`
+	DepartureDatetime   time.Time           `db:"departure_datetime"`
+	BaseFare            float64             `db:"base_fare"`
+	BusOwnerID          *string             `db:"bus_owner_id"`
+	BusID               *string             `db:"bus_id"`
+	BusLicensePlate     *string             `db:"bus_license_plate"`
+	BusStandingCapacity *int                `db:"bus_standing_capacity"`
+	Cap                 *int                `db:"cap"`
+	Fare                *float64            `db:"fare"`
+	DriverUserID        *string             `db:"driver_user_id"`
+	ConductorUserID     *string             `db:"conductor_user_id"`
+	OwnerUserID         *string             `db:"owner_user_id"`
+	PlacesHeld          int                 `db:"places_held"`
+	Boarded             int                 `db:"boarded"`
+	Waiting             int                 `db:"waiting"`
+	SettingsUpdatedAt   *time.Time          `db:"settings_updated_at"`
+}
+
+// LicensedCapacity is the standing capacity of the trip's bus; 0 if no bus is assigned or its
+// standing capacity was never recorded, in which case no standing tickets can be sold
+func (t *StandingTrip) LicensedCapacity() int {
+	if t.BusID == nil || t.BusStandingCapacity == nil || *t.BusStandingCapacity < 0 {
+		return 0
+	}
+	return *t.BusStandingCapacity
+}
+
+// EffectiveCap is how many standing places the trip sells: the owner's cap, but never more
+// than the bus is licensed for
+func (t *StandingTrip) EffectiveCap() int {
+	if t.Cap == nil {
+		return 0
+	}
+	return min(*t.Cap, t.LicensedCapacity())
+}
+
+// StandingFare is what a standing ticket on the trip costs
+func (t *StandingTrip) StandingFare() float64 {
+	if t.Fare != nil {
+		return *t.Fare
+	}
+	return t.BaseFare
+}
+
+// Remaining is how many more standing tickets the trip can sell
+func (t *StandingTrip) Remaining() int {
+	return max(t.EffectiveCap()-t.PlacesHeld, 0)
+}
+
+// AcceptsReservations reports whether passengers can still join the standing queue from the
+// app: the trip is published and has not departed
+func (t *StandingTrip) AcceptsReservations(at time.Time) bool {
+	if !t.IsBookable || !t.DepartureDatetime.After(at) {
+		return false
+	}
+	return t.Status == ScheduledTripStatusScheduled || t.Status == ScheduledTripStatusConfirmed
+}
+
+// AcceptsCrewSales reports whether the crew can still sell standing tickets, which they can
+// keep doing while the trip is under way
+func (t *StandingTrip) AcceptsCrewSales() bool {
+	switch t.Status {
+	case ScheduledTripStatusScheduled, ScheduledTripStatusConfirmed, ScheduledTripStatusInProgress:
+		return true
+	}
+	return false
+}
+
+// IsCrew reports whether the user is the trip's driver, conductor or bus owner
+func (t *StandingTrip) IsCrew(userID string) bool {
+	for _, allowed := range []*string{t.DriverUserID, t.ConductorUserID, t.OwnerUserID} {
+		if allowed != nil && *allowed == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCap validates an owner's standing cap against the bus's licensed capacity and the
+// places already sold
+func (t *StandingTrip) CheckCap(cap int) error {
+	if cap == 0 {
+		return nil
+	}
+	licensed := t.LicensedCapacity()
+	if licensed == 0 {
+		if t.BusID == nil {
+			return &ValidationError{Message: "assign a bus to the trip before selling standing tickets"}
+		}
+		return &ValidationError{Message: "record the bus's licensed standing capacity before selling standing tickets"}
+	}
+	if cap > licensed {
+		bus := "the bus"
+		if t.BusLicensePlate != nil {
+			bus = "bus " + *t.BusLicensePlate
+		}
+		return &ValidationError{Message: fmt.Sprintf("cap of %d exceeds the %d standing passengers %s is licensed for", cap, licensed, bus)}
+	}
+	if cap < t.PlacesHeld {
+		return &ValidationError{Message: fmt.Sprintf("%d standing tickets are already sold; the cap cannot be lower", t.PlacesHeld)}
+	}
+	return nil
+}
+
+// CheckFare validates a standing fare; it may not be above the trip's seat fare
+func (t *StandingTrip) CheckFare(fare float64) error {
+	if fare > t.BaseFare {
+		return &ValidationError{Message: fmt.Sprintf("standing fare cannot be above the trip's seat fare of %.2f", t.BaseFare)}
+	}
+	return nil
+}
+
+// TripStandingSummary is a trip's standing settings with how many places are sold and boarded
+type TripStandingSummary struct {
+	ScheduledTripID  string     `json:"scheduled_trip_id"`
+	Cap              int        `json:"cap"`
+	EffectiveCap     int        `json:"effective_cap"`     // Cap limited by the bus's licensed standing capacity
+	LicensedCapacity int        `json:"licensed_capacity"` // 0 if unknown; standing tickets are not sold
+	Fare             float64    `json:"fare"`
+	Sold             int        `json:"sold"` // Waiting and boarded
+	Waiting          int        `json:"waiting"`
+	Boarded          int        `json:"boarded"`
+	Remaining        int        `json:"remaining"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// Summary returns the trip's standing settings and counts
+func (t *StandingTrip) Summary() *TripStandingSummary {
+	cap := 0
+	if t.Cap != nil {
+		cap = *t.Cap
+	}
+	return &TripStandingSummary{
+		ScheduledTripID:  t.ScheduledTripID,
+		Cap:              cap,
+		EffectiveCap:     t.EffectiveCap(),
+		LicensedCapacity: t.LicensedCapacity(),
+		Fare:             t.StandingFare(),
+		Sold:             t.PlacesHeld,
+		Waiting:          t.Waiting,
+		Boarded:          t.Boarded,
+		Remaining:        t.Remaining(),
+		UpdatedAt:        t.SettingsUpdatedAt,
+	}
+}
+
+// StandingQueue is a trip's standing passengers still waiting to board, in boarding order
+type StandingQueue struct {
+	Summary *TripStandingSummary `json:"summary"`
+	Waiting []StandingTicket     `json:"waiting"`
+	Next    *StandingTicket      `json:"next,omitempty"`
+}
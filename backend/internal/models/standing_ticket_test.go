@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func standingTrip(licensed *int, cap *int) *StandingTrip {
+	bus, plate := "bus-1", "NB-1234"
+	return &StandingTrip{
+		ScheduledTripID:     "trip-1",
+		Status:              ScheduledTripStatusScheduled,
+		IsBookable:          true,
+		DepartureDatetime:   time.Now().Add(2 * time.Hour),
+		BaseFare:            450,
+		BusID:               &bus,
+		BusLicensePlate:     &plate,
+		BusStandingCapacity: licensed,
+		Cap:                 cap,
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestStandingTrip_EffectiveCapAndRemaining(t *testing.T) {
+	trip := standingTrip(intPtr(15), intPtr(20))
+	assert.Equal(t, 15, trip.EffectiveCap(), "never more than the bus is licensed for")
+
+	trip.Cap = intPtr(10)
+	trip.PlacesHeld = 7
+	assert.Equal(t, 10, trip.EffectiveCap())
+	assert.Equal(t, 3, trip.Remaining())
+
+	trip.BusStandingCapacity = intPtr(5)
+	assert.Equal(t, 0, trip.Remaining(), "bus capacity reduced below places sold")
+
+	trip.BusStandingCapacity = nil
+	assert.Equal(t, 0, trip.EffectiveCap(), "unknown licensed capacity sells nothing")
+
+	assert.Equal(t, 0, standingTrip(intPtr(15), nil).EffectiveCap(), "not configured")
+}
+
+func TestStandingTrip_CheckCap(t *testing.T) {
+	trip := standingTrip(intPtr(15), nil)
+	assert.NoError(t, trip.CheckCap(15))
+	assert.NoError(t, trip.CheckCap(0))
+
+	err := trip.CheckCap(16)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NB-1234")
+
+	trip.PlacesHeld = 8
+	assert.Error(t, trip.CheckCap(5), "cannot go below tickets already sold")
+
+	trip.BusStandingCapacity = nil
+	assert.Error(t, trip.CheckCap(5))
+	trip.BusID = nil
+	assert.ErrorContains(t, trip.CheckCap(5), "assign a bus")
+}
+
+func TestStandingTrip_FareAndSales(t *testing.T) {
+	trip := standingTrip(intPtr(15), intPtr(10))
+	assert.Equal(t, 450.0, trip.StandingFare(), "defaults to the seat fare")
+	assert.Error(t, trip.CheckFare(500))
+	assert.NoError(t, trip.CheckFare(300))
+
+	now := time.Now()
+	assert.True(t, trip.AcceptsReservations(now))
+	assert.False(t, trip.AcceptsReservations(trip.DepartureDatetime), "closed at departure")
+	assert.True(t, trip.AcceptsCrewSales())
+
+	trip.Status = ScheduledTripStatusInProgress
+	assert.False(t, trip.AcceptsReservations(now))
+	assert.True(t, trip.AcceptsCrewSales(), "crew keeps selling on the road")
+
+	trip.Status = ScheduledTripStatusCancelled
+	assert.False(t, trip.AcceptsCrewSales())
+}
+
+func TestStandingTrip_IsCrew(t *testing.T) {
+	trip := standingTrip(nil, nil)
+	conductor := "user-conductor"
+	trip.ConductorUserID = &conductor
+	assert.True(t, trip.IsCrew(conductor))
+	assert.False(t, trip.IsCrew("someone-else"))
+}
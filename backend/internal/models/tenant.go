@@ -0,0 +1,137 @@
+package models
+
+import "time"
+
+// Tenant is an operator running its own branded passenger app on the platform.
+// The core SmartTransit tenant has no row: a NULL tenant_id means SmartTransit everywhere.
+type Tenant struct {
+	ID       string `json:"id" db:"id"`
+	Slug     string `json:"slug" db:"slug"`
+	Name     string `json:"name" db:"name"`
+	IsActive bool   `json:"is_active" db:"is_active"`
+
+	// Branding returned to the tenant's app
+	AppName      string  `json:"app_name" db:"app_name"`
+	LogoURL      *string `json:"logo_url,omitempty" db:"logo_url"`
+	PrimaryColor *string `json:"primary_color,omitempty" db:"primary_color"`
+	SupportPhone *string `json:"support_phone,omitempty" db:"support_phone"`
+	SupportEmail *string `json:"support_email,omitempty" db:"support_email"`
+
+	// Delivery and payment overrides; empty values fall back to the platform configuration
+	SMSMask              *string `json:"sms_mask,omitempty" db:"sms_mask"`
+	PaymentMerchantKey   *string `json:"-" db:"payment_merchant_key"`
+	PaymentMerchantToken *string `json:"-" db:"payment_merchant_token"`
+	PaymentPackageName   *string `json:"payment_package_name,omitempty" db:"payment_package_name"` // Android package name sent to PAYable
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultTenant returns the core SmartTransit tenant
+func DefaultTenant() *Tenant {
+	return &Tenant{
+		Slug:     "smarttransit",
+		Name:     "SmartTransit",
+		AppName:  "SmartTransit",
+		IsActive: true,
+	}
+}
+
+// IsDefault reports whether this is the core SmartTransit tenant
+func (t *Tenant) IsDefault() bool {
+	return t == nil || t.ID == ""
+}
+
+// ScopeID returns the tenant_id value rows belonging to this tenant carry (nil for SmartTransit)
+func (t *Tenant) ScopeID() *string {
+	if t.IsDefault() {
+		return nil
+	}
+	id := t.ID
+	return &id
+}
+
+// HasPaymentAccount reports whether the tenant collects payments into its own merchant account
+func (t *Tenant) HasPaymentAccount() bool {
+	return t != nil && t.PaymentMerchantKey != nil && *t.PaymentMerchantKey != "" &&
+		t.PaymentMerchantToken != nil && *t.PaymentMerchantToken != ""
+}
+
+// SenderMask returns the SMS sender mask for the tenant, or "" for the platform mask
+func (t *Tenant) SenderMask() string {
+	if t == nil || t.SMSMask == nil {
+		return ""
+	}
+	return *t.SMSMask
+}
+
+// TenantBranding is the public app configuration for a tenant
+type TenantBranding struct {
+	Slug         string  `json:"slug"`
+	Name         string  `json:"name"`
+	AppName      string  `json:"app_name"`
+	LogoURL      *string `json:"logo_url,omitempty"`
+	PrimaryColor *string `json:"primary_color,omitempty"`
+	SupportPhone *string `json:"support_phone,omitempty"`
+	SupportEmail *string `json:"support_email,omitempty"`
+}
+
+// Branding returns the public app configuration
+func (t *Tenant) Branding() TenantBranding {
+	return TenantBranding{
+		Slug:         t.Slug,
+		Name:         t.Name,
+		AppName:      t.AppName,
+		LogoURL:      t.LogoURL,
+		PrimaryColor: t.PrimaryColor,
+		SupportPhone: t.SupportPhone,
+		SupportEmail: t.SupportEmail,
+	}
+}
+
+// TenantAPIKey identifies a tenant's app. Only a hash of the key is stored.
+type TenantAPIKey struct {
+	ID         string     `json:"id" db:"id"`
+	TenantID   string     `json:"tenant_id" db:"tenant_id"`
+	Name       string     `json:"name" db:"name"`
+	KeyPrefix  string     `json:"key_prefix" db:"key_prefix"` // First characters, to recognise a key in listings
+	KeyHash    string     `json:"-" db:"key_hash"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateTenantRequest registers a white-label tenant
+type CreateTenantRequest struct {
+	Slug                 string  `json:"slug" binding:"required,min=2,max=50"`
+	Name                 string  `json:"name" binding:"required,max=200"`
+	AppName              string  `json:"app_name" binding:"required,max=100"`
+	LogoURL              *string `json:"logo_url,omitempty" binding:"omitempty,url"`
+	PrimaryColor         *string `json:"primary_color,omitempty" binding:"omitempty,hexcolor"`
+	SupportPhone         *string `json:"support_phone,omitempty"`
+	SupportEmail         *string `json:"support_email,omitempty" binding:"omitempty,email"`
+	SMSMask              *string `json:"sms_mask,omitempty" binding:"omitempty,max=11"`
+	PaymentMerchantKey   *string `json:"payment_merchant_key,omitempty"`
+	PaymentMerchantToken *string `json:"payment_merchant_token,omitempty"`
+	PaymentPackageName   *string `json:"payment_package_name,omitempty"`
+}
+
+// UpdateTenantRequest changes a tenant's configuration; omitted fields are unchanged
+type UpdateTenantRequest struct {
+	Name                 *string `json:"name,omitempty" binding:"omitempty,max=200"`
+	AppName              *string `json:"app_name,omitempty" binding:"omitempty,max=100"`
+	IsActive             *bool   `json:"is_active,omitempty"`
+	LogoURL              *string `json:"logo_url,omitempty" binding:"omitempty,url"`
+	PrimaryColor         *string `json:"primary_color,omitempty" binding:"omitempty,hexcolor"`
+	SupportPhone         *string `json:"support_phone,omitempty"`
+	SupportEmail         *string `json:"support_email,omitempty" binding:"omitempty,email"`
+	SMSMask              *string `json:"sms_mask,omitempty" binding:"omitempty,max=11"`
+	PaymentMerchantKey   *string `json:"payment_merchant_key,omitempty"`
+	PaymentMerchantToken *string `json:"payment_merchant_token,omitempty"`
+	PaymentPackageName   *string `json:"payment_package_name,omitempty"`
+}
+
+// CreateTenantAPIKeyRequest issues a new API key for a tenant's app
+type CreateTenantAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"` // e.g. "Android app"
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TimetableExceptionAction is what trip generation does on an exception date
+type TimetableExceptionAction string
+
+const (
+	TimetableExceptionSkip   TimetableExceptionAction = "skip"   // No trip is generated
+	TimetableExceptionAdjust TimetableExceptionAction = "adjust" // The trip departs at DepartureTime instead
+	TimetableExceptionRun    TimetableExceptionAction = "run"    // Per-schedule only: run as normal despite a system holiday
+)
+
+// TimetableException changes trip generation on one date, or on the same day every year when
+// RecursYearly is set. An exception without a schedule is a system-level holiday (Poya days,
+// public holidays) applying to every schedule; a schedule's own exception on the same date
+// takes precedence over it.
+type TimetableException struct {
+	ID              string                   `json:"id" db:"id"`
+	TripScheduleID  *string                  `json:"trip_schedule_id,omitempty" db:"trip_schedule_id"` // Empty for system holidays
+	ExceptionDate   string                   `json:"exception_date" db:"exception_date"`               // YYYY-MM-DD
+	RecursYearly    bool                     `json:"recurs_yearly" db:"recurs_yearly"`
+	Action          TimetableExceptionAction `json:"action" db:"action"`
+	DepartureTime   *string                  `json:"departure_time,omitempty" db:"departure_time"` // HH:MM, for adjust only
+	Reason          string                   `json:"reason" db:"reason"`
+	CreatedByUserID string                   `json:"created_by_user_id" db:"created_by_user_id"`
+	CreatedAt       time.Time                `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time                `json:"updated_at" db:"updated_at"`
+}
+
+// IsSystem reports whether the exception is a system-level holiday
+func (e *TimetableException) IsSystem() bool {
+	return e.TripScheduleID == nil
+}
+
+// Matches reports whether the exception falls on the given date
+func (e *TimetableException) Matches(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	if e.RecursYearly {
+		return len(e.ExceptionDate) == len(day) && e.ExceptionDate[4:] == day[4:]
+	}
+	return e.ExceptionDate == day
+}
+
+// TimetableExceptionRequest creates or replaces a timetable exception
+type TimetableExceptionRequest struct {
+	ExceptionDate string                   `json:"exception_date" binding:"required"` // YYYY-MM-DD
+	RecursYearly  bool                     `json:"recurs_yearly"`
+	Action        TimetableExceptionAction `json:"action" binding:"required"`
+	DepartureTime *string                  `json:"departure_time,omitempty"` // HH:MM, required for adjust
+	Reason        string                   `json:"reason" binding:"required,min=3,max=200"`
+}
+
+// Validate checks the request. System holidays may only skip trips; adjusting a departure time
+// or running through a holiday is a per-schedule decision.
+func (r *TimetableExceptionRequest) Validate(system bool) error {
+	if _, err := time.Parse("2006-01-02", r.ExceptionDate); err != nil {
+		return &ValidationError{Message: "exception_date must be in YYYY-MM-DD format"}
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		return &ValidationError{Message: "reason is required"}
+	}
+
+	switch r.Action {
+	case TimetableExceptionSkip, TimetableExceptionRun:
+		if r.DepartureTime != nil && *r.DepartureTime != "" {
+			return &ValidationError{Message: "departure_time is only allowed with the adjust action"}
+		}
+	case TimetableExceptionAdjust:
+		if r.DepartureTime == nil {
+			return &ValidationError{Message: "departure_time is required with the adjust action"}
+		}
+		if _, err := time.Parse("15:04", *r.DepartureTime); err != nil {
+			return &ValidationError{Message: "departure_time must be in HH:MM format"}
+		}
+	default:
+		return &ValidationError{Message: "action must be skip, adjust or run"}
+	}
+	if system && r.Action != TimetableExceptionSkip {
+		return &ValidationError{Message: "system holidays can only skip trips"}
+	}
+	return nil
+}
+
+// ResolveTimetableException returns the exception that decides trip generation for a schedule on
+// a date, or nil if the schedule runs as normal. The schedule's own exceptions win over system
+// holidays, and a one-off date wins over a yearly one at the same level.
+func ResolveTimetableException(exceptions []TimetableException, date time.Time) *TimetableException {
+	var best *TimetableException
+	rank := func(e *TimetableException) int {
+		r := 0
+		if !e.IsSystem() {
+			r += 2
+		}
+		if !e.RecursYearly {
+			r++
+		}
+		return r
+	}
+	for i := range exceptions {
+		e := &exceptions[i]
+		if !e.Matches(date) {
+			continue
+		}
+		if best == nil || rank(e) > rank(best) {
+			best = e
+		}
+	}
+	if best != nil && best.Action == TimetableExceptionRun {
+		return nil
+	}
+	return best
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTimetableException(t *testing.T) {
+	schedule := "schedule-1"
+	evening := "18:30"
+	vesak := time.Date(2026, 5, 1, 0, 0, 0, 0, ReportTimezone)
+	christmas := time.Date(2027, 12, 25, 0, 0, 0, 0, ReportTimezone)
+
+	exceptions := []TimetableException{
+		{ID: "poya", ExceptionDate: "2026-05-01", Action: TimetableExceptionSkip},
+		{ID: "christmas", ExceptionDate: "2025-12-25", RecursYearly: true, Action: TimetableExceptionSkip},
+		{ID: "late-christmas", TripScheduleID: &schedule, ExceptionDate: "2026-12-25", RecursYearly: true, Action: TimetableExceptionAdjust, DepartureTime: &evening},
+	}
+
+	assert.Nil(t, ResolveTimetableException(exceptions, vesak.AddDate(0, 0, 1)), "ordinary day")
+	require.NotNil(t, ResolveTimetableException(exceptions, vesak))
+	assert.Equal(t, "poya", ResolveTimetableException(exceptions, vesak).ID)
+	assert.Equal(t, "late-christmas", ResolveTimetableException(exceptions, christmas).ID, "schedule's own exception wins, in any year")
+
+	runs := append(exceptions, TimetableException{ID: "runs", TripScheduleID: &schedule, ExceptionDate: "2026-05-01", Action: TimetableExceptionRun})
+	assert.Nil(t, ResolveTimetableException(runs, vesak), "schedule opts out of the system holiday")
+
+	oneOff := append(exceptions, TimetableException{ID: "cancel-2027", TripScheduleID: &schedule, ExceptionDate: "2027-12-25", Action: TimetableExceptionSkip})
+	assert.Equal(t, "cancel-2027", ResolveTimetableException(oneOff, christmas).ID, "one-off date wins over a yearly one")
+}
+
+func TestTimetableExceptionRequest_Validate(t *testing.T) {
+	evening := "18:30"
+	late := "25:00"
+
+	assert.NoError(t, (&TimetableExceptionRequest{ExceptionDate: "2026-05-01", Action: TimetableExceptionSkip, Reason: "Vesak"}).Validate(true))
+	assert.NoError(t, (&TimetableExceptionRequest{ExceptionDate: "2026-05-01", Action: TimetableExceptionAdjust, DepartureTime: &evening, Reason: "Vesak"}).Validate(false))
+
+	for name, req := range map[string]*TimetableExceptionRequest{
+		"bad date":             {ExceptionDate: "01/05/2026", Action: TimetableExceptionSkip, Reason: "Vesak"},
+		"unknown action":       {ExceptionDate: "2026-05-01", Action: "cancel", Reason: "Vesak"},
+		"adjust without time":  {ExceptionDate: "2026-05-01", Action: TimetableExceptionAdjust, Reason: "Vesak"},
+		"adjust with bad time": {ExceptionDate: "2026-05-01", Action: TimetableExceptionAdjust, DepartureTime: &late, Reason: "Vesak"},
+		"time on skip":         {ExceptionDate: "2026-05-01", Action: TimetableExceptionSkip, DepartureTime: &evening, Reason: "Vesak"},
+		"blank reason":         {ExceptionDate: "2026-05-01", Action: TimetableExceptionSkip, Reason: "   "},
+	} {
+		assert.Error(t, req.Validate(false), name)
+	}
+
+	assert.Error(t, (&TimetableExceptionRequest{ExceptionDate: "2026-05-01", Action: TimetableExceptionRun, Reason: "Vesak"}).Validate(true), "system holidays only skip")
+}
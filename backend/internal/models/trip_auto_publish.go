@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultAutoPublishDaysBefore is how far ahead trips are published when the owner enables
+// auto-publish without choosing
+const DefaultAutoPublishDaysBefore = 3
+
+// TripAutoPublishSettings makes a schedule's generated trips go live on their own, a set number of
+// days before departure, once a bus and seat layout are assigned
+type TripAutoPublishSettings struct {
+	TripScheduleID      string    `json:"trip_schedule_id" db:"trip_schedule_id"`
+	Enabled             bool      `json:"enabled" db:"enabled"`
+	DaysBeforeDeparture int       `json:"days_before_departure" db:"days_before_departure"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateTripAutoPublishRequest turns auto-publish on or off for a schedule
+type UpdateTripAutoPublishRequest struct {
+	Enabled             bool `json:"enabled"`
+	DaysBeforeDeparture *int `json:"days_before_departure,omitempty" binding:"omitempty,min=1,max=30"`
+}
+
+// AutoPublishAlertReason is why the auto-publish job could not publish a trip
+type AutoPublishAlertReason string
+
+const (
+	AutoPublishNoBus            AutoPublishAlertReason = "bus_not_assigned"
+	AutoPublishNoSeatLayout     AutoPublishAlertReason = "seat_layout_not_assigned"
+	AutoPublishOwnerNotVerified AutoPublishAlertReason = "owner_not_verified"
+	AutoPublishFareAbovePermit  AutoPublishAlertReason = "fare_above_permit"
+	AutoPublishOverCapacity     AutoPublishAlertReason = "seat_layout_over_capacity"
+	AutoPublishSeatsFailed      AutoPublishAlertReason = "seat_creation_failed"
+	AutoPublishFailed           AutoPublishAlertReason = "publish_failed"
+)
+
+// AutoPublishAlertStatus tracks an alert on the owner's dashboard
+type AutoPublishAlertStatus string
+
+const (
+	AutoPublishAlertOpen      AutoPublishAlertStatus = "open"
+	AutoPublishAlertDismissed AutoPublishAlertStatus = "dismissed" // Hidden by the owner until the reason changes
+	AutoPublishAlertResolved  AutoPublishAlertStatus = "resolved"  // Published, or no longer due
+)
+
+// TripAutoPublishAlert is shown on the owner's dashboard when a trip due for auto-publish could
+// not go live. A trip has at most one unresolved alert, updated on every attempt.
+type TripAutoPublishAlert struct {
+	ID                string                 `json:"id" db:"id"`
+	BusOwnerID        string                 `json:"bus_owner_id" db:"bus_owner_id"`
+	TripScheduleID    string                 `json:"trip_schedule_id" db:"trip_schedule_id"`
+	ScheduleName      *string                `json:"schedule_name,omitempty" db:"schedule_name"`
+	ScheduledTripID   string                 `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	DepartureDatetime time.Time              `json:"departure_datetime" db:"departure_datetime"`
+	Reason            AutoPublishAlertReason `json:"reason" db:"reason"`
+	Message           string                 `json:"message" db:"message"`
+	Status            AutoPublishAlertStatus `json:"status" db:"status"`
+	FirstSeenAt       time.Time              `json:"first_seen_at" db:"first_seen_at"`
+	LastSeenAt        time.Time              `json:"last_seen_at" db:"last_seen_at"`
+	ResolvedAt        *time.Time             `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// DueAutoPublishTrip is an unpublished trip inside its schedule's auto-publish window
+type DueAutoPublishTrip struct {
+	TripID                  string             `db:"trip_id"`
+	TripScheduleID          string             `db:"trip_schedule_id"`
+	BusOwnerID              string             `db:"bus_owner_id"`
+	OwnerVerificationStatus VerificationStatus `db:"owner_verification_status"`
+	BusID                   *string            `db:"bus_id"`
+	SeatLayoutID            *string            `db:"seat_layout_id"`
+	BaseFare                float64            `db:"base_fare"`
+	DepartureDatetime       time.Time          `db:"departure_datetime"`
+}
+
+// Blocker returns why the trip cannot be published yet without checking fares or capacity, or
+// an empty reason if it is ready
+func (t *DueAutoPublishTrip) Blocker() (AutoPublishAlertReason, string) {
+	departure := t.DepartureDatetime.In(ReportTimezone).Format("Jan 2, 15:04")
+	switch {
+	case t.OwnerVerificationStatus != VerificationVerified:
+		return AutoPublishOwnerNotVerified, "Your account must be verified before trips can be published"
+	case t.BusID == nil || *t.BusID == "":
+		return AutoPublishNoBus, fmt.Sprintf("Assign a bus to the %s trip so it can be published", departure)
+	case t.SeatLayoutID == nil || *t.SeatLayoutID == "":
+		return AutoPublishNoSeatLayout, fmt.Sprintf("Assign a seat layout to the %s trip so it can be published", departure)
+	}
+	return "", ""
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDueAutoPublishTrip_Blocker(t *testing.T) {
+	bus, layout := "bus-1", "layout-1"
+	trip := DueAutoPublishTrip{
+		OwnerVerificationStatus: VerificationVerified,
+		BusID:                   &bus,
+		SeatLayoutID:            &layout,
+		DepartureDatetime:       time.Date(2026, 11, 3, 1, 30, 0, 0, time.UTC),
+	}
+	reason, _ := trip.Blocker()
+	assert.Empty(t, reason, "bus and seat layout assigned")
+
+	trip.SeatLayoutID = nil
+	reason, message := trip.Blocker()
+	assert.Equal(t, AutoPublishNoSeatLayout, reason)
+	assert.Contains(t, message, "Nov 3, 07:00", "departure shown in local time")
+
+	trip.BusID = nil
+	reason, _ = trip.Blocker()
+	assert.Equal(t, AutoPublishNoBus, reason, "the bus is asked for before the layout")
+
+	trip.OwnerVerificationStatus = VerificationPending
+	reason, _ = trip.Blocker()
+	assert.Equal(t, AutoPublishOwnerNotVerified, reason)
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// Boarding window defaults, used when the bus owner does not set them
+const (
+	DefaultBoardingOpensMinutesBefore = 60 // Check-in opens an hour before departure
+	DefaultBoardingClosesMinutesAfter = 0  // The gate closes at departure
+)
+
+// TripBoardingWindow is when conductors may check in and board passengers for a trip.
+// Outside it only the trip's bus owner can override, and every override is audited.
+type TripBoardingWindow struct {
+	ScheduledTripID    string    `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	OpensMinutesBefore int       `json:"opens_minutes_before" db:"opens_minutes_before"` // Before departure
+	ClosesMinutesAfter int       `json:"closes_minutes_after" db:"closes_minutes_after"` // After departure; 0 closes the gate at departure
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NewTripBoardingWindow returns the default window for a trip
+func NewTripBoardingWindow(scheduledTripID string) *TripBoardingWindow {
+	return &TripBoardingWindow{
+		ScheduledTripID:    scheduledTripID,
+		OpensMinutesBefore: DefaultBoardingOpensMinutesBefore,
+		ClosesMinutesAfter: DefaultBoardingClosesMinutesAfter,
+	}
+}
+
+// BoardingWindowState is where a moment falls relative to a trip's boarding window
+type BoardingWindowState string
+
+const (
+	BoardingWindowNotOpen BoardingWindowState = "not_open"
+	BoardingWindowOpen    BoardingWindowState = "open"
+	BoardingWindowClosed  BoardingWindowState = "closed"
+)
+
+// Bounds returns when boarding opens and closes for a trip departing at departure
+func (w *TripBoardingWindow) Bounds(departure time.Time) (opensAt, closesAt time.Time) {
+	opensAt = departure.Add(-time.Duration(w.OpensMinutesBefore) * time.Minute)
+	closesAt = departure.Add(time.Duration(w.ClosesMinutesAfter) * time.Minute)
+	return opensAt, closesAt
+}
+
+// StateAt reports whether boarding is open at `at`. The window includes its closing minute.
+func (w *TripBoardingWindow) StateAt(departure, at time.Time) BoardingWindowState {
+	opensAt, closesAt := w.Bounds(departure)
+	switch {
+	case at.Before(opensAt):
+		return BoardingWindowNotOpen
+	case at.After(closesAt):
+		return BoardingWindowClosed
+	default:
+		return BoardingWindowOpen
+	}
+}
+
+// UpdateTripBoardingWindowRequest sets a trip's boarding window
+type UpdateTripBoardingWindowRequest struct {
+	OpensMinutesBefore *int `json:"opens_minutes_before,omitempty" binding:"omitempty,min=0,max=720"`
+	ClosesMinutesAfter *int `json:"closes_minutes_after,omitempty" binding:"omitempty,min=0,max=120"`
+}
+
+// ApplyTo copies the request onto window, leaving unset settings as they are
+func (r *UpdateTripBoardingWindowRequest) ApplyTo(window *TripBoardingWindow) {
+	if r.OpensMinutesBefore != nil {
+		window.OpensMinutesBefore = *r.OpensMinutesBefore
+	}
+	if r.ClosesMinutesAfter != nil {
+		window.ClosesMinutesAfter = *r.ClosesMinutesAfter
+	}
+}
+
+// BoardingTarget is the trip a check-in or boarding applies to
+type BoardingTarget struct {
+	BusBookingID      string    `db:"bus_booking_id"`
+	ScheduledTripID   string    `db:"scheduled_trip_id"`
+	DepartureDatetime time.Time `db:"departure_datetime"`
+	BusOwnerID        *string   `db:"bus_owner_id"`
+}
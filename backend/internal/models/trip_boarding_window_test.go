@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTripBoardingWindow_StateAt(t *testing.T) {
+	departure := time.Date(2026, time.March, 4, 8, 0, 0, 0, ReportTimezone)
+	window := &TripBoardingWindow{OpensMinutesBefore: 30, ClosesMinutesAfter: 5}
+
+	assert.Equal(t, BoardingWindowNotOpen, window.StateAt(departure, departure.Add(-31*time.Minute)))
+	assert.Equal(t, BoardingWindowOpen, window.StateAt(departure, departure.Add(-30*time.Minute)))
+	assert.Equal(t, BoardingWindowOpen, window.StateAt(departure, departure.Add(5*time.Minute)), "closing minute is inclusive")
+	assert.Equal(t, BoardingWindowClosed, window.StateAt(departure, departure.Add(6*time.Minute)))
+}
+
+func TestTripBoardingWindow_DefaultClosesAtDeparture(t *testing.T) {
+	departure := time.Date(2026, time.March, 4, 8, 0, 0, 0, ReportTimezone)
+	window := NewTripBoardingWindow("trip-1")
+
+	assert.Equal(t, BoardingWindowOpen, window.StateAt(departure, departure.Add(-time.Hour)))
+	assert.Equal(t, BoardingWindowClosed, window.StateAt(departure, departure.Add(time.Second)))
+}
+
+func TestUpdateTripBoardingWindowRequest_ApplyTo(t *testing.T) {
+	window := NewTripBoardingWindow("trip-1")
+	closes := 10
+	(&UpdateTripBoardingWindowRequest{ClosesMinutesAfter: &closes}).ApplyTo(window)
+
+	assert.Equal(t, DefaultBoardingOpensMinutesBefore, window.OpensMinutesBefore)
+	assert.Equal(t, 10, window.ClosesMinutesAfter)
+}
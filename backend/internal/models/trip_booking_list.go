@@ -0,0 +1,206 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TripBookingSource groups a trip's bookings by how they were sold
+type TripBookingSource string
+
+const (
+	TripBookingSourceApp     TripBookingSource = "app"     // Booked by the passenger (app, web, kiosk) or a call-center agent
+	TripBookingSourceManual  TripBookingSource = "manual"  // Phone and agent bookings entered by the owner
+	TripBookingSourceOnboard TripBookingSource = "onboard" // Walk-ins and standing tickets
+)
+
+// TripBookingSources lists every source, in the order subtotals are reported
+var TripBookingSources = []TripBookingSource{TripBookingSourceApp, TripBookingSourceManual, TripBookingSourceOnboard}
+
+// tripBookingVoidStatuses are statuses whose fare is not counted as revenue
+var tripBookingVoidStatuses = map[string]bool{
+	"cancelled": true,
+	"skipped":   true, // Standing passenger not there when called; the place was freed unpaid
+}
+
+// TripBookingEntry is one booking on a trip, whatever its source. Channel is the finer origin:
+// the app booking source (app, web, agent, kiosk), the manual booking type (phone, agent,
+// walk_in) or standing_app / standing_conductor for standing tickets.
+type TripBookingEntry struct {
+	Source            TripBookingSource `json:"source" db:"source"`
+	Channel           string            `json:"channel" db:"channel"`
+	BookingID         string            `json:"booking_id" db:"booking_id"`
+	BookingReference  string            `json:"booking_reference" db:"booking_reference"`
+	PassengerName     string            `json:"passenger_name" db:"passenger_name"`
+	PassengerPhone    *string           `json:"passenger_phone,omitempty" db:"passenger_phone"`
+	BoardingStopID    *string           `json:"boarding_stop_id,omitempty" db:"boarding_stop_id"`
+	BoardingStopName  *string           `json:"boarding_stop_name,omitempty" db:"boarding_stop_name"`
+	AlightingStopName *string           `json:"alighting_stop_name,omitempty" db:"alighting_stop_name"`
+	SeatNumbers       StringArray       `json:"seat_numbers" db:"seat_numbers"` // Empty for standing tickets
+	NumberOfSeats     int               `json:"number_of_seats" db:"number_of_seats"`
+	Status            string            `json:"status" db:"status"`
+	PaymentStatus     string            `json:"payment_status" db:"payment_status"`
+	Fare              float64           `json:"fare" db:"fare"`
+	AmountCollected   float64           `json:"amount_collected" db:"amount_collected"`
+	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+}
+
+// CountsAsRevenue reports whether the booking's fare counts towards the trip's revenue
+func (e *TripBookingEntry) CountsAsRevenue() bool {
+	return !tripBookingVoidStatuses[e.Status]
+}
+
+// TripBookingListTrip is the trip a bookings list is for
+type TripBookingListTrip struct {
+	ScheduledTripID   string    `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	DepartureDatetime time.Time `json:"departure_datetime" db:"departure_datetime"`
+	Status            string    `json:"status" db:"status"`
+	BusOwnerID        *string   `json:"-" db:"bus_owner_id"`
+}
+
+// TripBookingListFilter narrows a trip's bookings list
+type TripBookingListFilter struct {
+	Statuses       []string // Any of these statuses; empty means all
+	Source         TripBookingSource
+	BoardingStopID string
+}
+
+// NewTripBookingListFilter builds a filter from the query parameters: status may list several
+// statuses separated by commas
+func NewTripBookingListFilter(status, source, boardingStopID string) (*TripBookingListFilter, error) {
+	filter := &TripBookingListFilter{
+		Source:         TripBookingSource(strings.TrimSpace(source)),
+		BoardingStopID: strings.TrimSpace(boardingStopID),
+	}
+	if filter.Source != "" {
+		valid := false
+		for _, s := range TripBookingSources {
+			valid = valid || s == filter.Source
+		}
+		if !valid {
+			return nil, &ValidationError{Message: "source must be app, manual or onboard"}
+		}
+	}
+	for _, s := range strings.Split(status, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			filter.Statuses = append(filter.Statuses, strings.ToLower(s))
+		}
+	}
+	return filter, nil
+}
+
+// Matches reports whether a booking passes the filter
+func (f *TripBookingListFilter) Matches(e *TripBookingEntry) bool {
+	if f.Source != "" && e.Source != f.Source {
+		return false
+	}
+	if f.BoardingStopID != "" && (e.BoardingStopID == nil || *e.BoardingStopID != f.BoardingStopID) {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, s := range f.Statuses {
+		if e.Status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TripBookingSubtotal sums one source's bookings. Cancelled bookings are counted but their fares
+// are left out of the revenue.
+type TripBookingSubtotal struct {
+	Source          TripBookingSource `json:"source,omitempty"` // Empty on the total
+	Bookings        int               `json:"bookings"`
+	Cancelled       int               `json:"cancelled"`
+	Seats           int               `json:"seats"` // Seats and standing places held by bookings that count as revenue
+	Revenue         float64           `json:"revenue"`
+	AmountCollected float64           `json:"amount_collected"`
+}
+
+func (t *TripBookingSubtotal) add(e *TripBookingEntry) {
+	t.Bookings++
+	if !e.CountsAsRevenue() {
+		t.Cancelled++
+		return
+	}
+	t.Seats += e.NumberOfSeats
+	t.Revenue += e.Fare
+	t.AmountCollected += e.AmountCollected
+}
+
+func (t *TripBookingSubtotal) round() {
+	t.Revenue = RoundMoney(t.Revenue)
+	t.AmountCollected = RoundMoney(t.AmountCollected)
+}
+
+// TripBookingList is an owner's consolidated view of everyone booked on a trip
+type TripBookingList struct {
+	Trip      *TripBookingListTrip  `json:"trip"`
+	Bookings  []TripBookingEntry    `json:"bookings"`
+	Subtotals []TripBookingSubtotal `json:"subtotals"` // One per source, filtered like the bookings
+	Total     TripBookingSubtotal   `json:"total"`
+}
+
+// BuildTripBookingList filters a trip's bookings, orders them by booking time and totals them
+// per source
+func BuildTripBookingList(trip *TripBookingListTrip, entries []TripBookingEntry, filter *TripBookingListFilter) *TripBookingList {
+	list := &TripBookingList{Trip: trip, Bookings: []TripBookingEntry{}}
+	bySource := make(map[TripBookingSource]*TripBookingSubtotal, len(TripBookingSources))
+	for _, source := range TripBookingSources {
+		bySource[source] = &TripBookingSubtotal{Source: source}
+	}
+
+	for i := range entries {
+		if filter.Matches(&entries[i]) {
+			list.Bookings = append(list.Bookings, entries[i])
+		}
+	}
+	sort.SliceStable(list.Bookings, func(i, j int) bool {
+		return list.Bookings[i].CreatedAt.Before(list.Bookings[j].CreatedAt)
+	})
+	for i := range list.Bookings {
+		entry := &list.Bookings[i]
+		if subtotal, ok := bySource[entry.Source]; ok {
+			subtotal.add(entry)
+		}
+		list.Total.add(entry)
+	}
+
+	for _, source := range TripBookingSources {
+		bySource[source].round()
+		list.Subtotals = append(list.Subtotals, *bySource[source])
+	}
+	list.Total.round()
+	return list
+}
+
+// TripBookingCSVHeader is the header row of a trip's bookings CSV export
+var TripBookingCSVHeader = []string{
+	"created_at", "source", "channel", "booking_reference", "passenger_name", "passenger_phone",
+	"boarding_stop", "alighting_stop", "seat_numbers", "number_of_seats", "status", "payment_status",
+	"fare", "amount_collected",
+}
+
+// CSVRecord returns the booking as a row matching TripBookingCSVHeader
+func (e *TripBookingEntry) CSVRecord() []string {
+	return []string{
+		e.CreatedAt.UTC().Format(time.RFC3339),
+		string(e.Source),
+		e.Channel,
+		e.BookingReference,
+		e.PassengerName,
+		stringOrEmpty(e.PassengerPhone),
+		stringOrEmpty(e.BoardingStopName),
+		stringOrEmpty(e.AlightingStopName),
+		strings.Join(e.SeatNumbers, " "),
+		strconv.Itoa(e.NumberOfSeats),
+		e.Status,
+		e.PaymentStatus,
+		strconv.FormatFloat(e.Fare, 'f', 2, 64),
+		strconv.FormatFloat(e.AmountCollected, 'f', 2, 64),
+	}
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tripBookingEntries(base time.Time) []TripBookingEntry {
+	stop := "stop-kandy"
+	return []TripBookingEntry{
+		{Source: TripBookingSourceManual, Channel: "phone", BookingReference: "PH-1", Status: "confirmed", NumberOfSeats: 2, Fare: 1800, AmountCollected: 900, BoardingStopID: &stop, CreatedAt: base.Add(2 * time.Minute)},
+		{Source: TripBookingSourceApp, Channel: "app", BookingReference: "BK-1", Status: "confirmed", NumberOfSeats: 1, Fare: 900.005, AmountCollected: 900.005, BoardingStopID: &stop, CreatedAt: base},
+		{Source: TripBookingSourceApp, Channel: "web", BookingReference: "BK-2", Status: "cancelled", NumberOfSeats: 1, Fare: 900, CreatedAt: base.Add(time.Minute)},
+		{Source: TripBookingSourceOnboard, Channel: "standing_conductor", BookingReference: "STANDING-1", Status: "boarded", NumberOfSeats: 1, Fare: 450, AmountCollected: 450, CreatedAt: base.Add(3 * time.Minute)},
+		{Source: TripBookingSourceOnboard, Channel: "standing_app", BookingReference: "STANDING-2", Status: "skipped", NumberOfSeats: 1, Fare: 450, CreatedAt: base.Add(4 * time.Minute)},
+	}
+}
+
+func TestBuildTripBookingList_Subtotals(t *testing.T) {
+	base := time.Date(2026, 3, 14, 6, 0, 0, 0, time.UTC)
+	list := BuildTripBookingList(&TripBookingListTrip{ScheduledTripID: "trip-1"}, tripBookingEntries(base), &TripBookingListFilter{})
+
+	require.Len(t, list.Bookings, 5)
+	assert.Equal(t, "BK-1", list.Bookings[0].BookingReference, "ordered by booking time")
+
+	require.Len(t, list.Subtotals, 3)
+	app := list.Subtotals[0]
+	assert.Equal(t, TripBookingSourceApp, app.Source)
+	assert.Equal(t, 2, app.Bookings)
+	assert.Equal(t, 1, app.Cancelled)
+	assert.Equal(t, 1, app.Seats)
+	assert.Equal(t, 900.01, app.Revenue, "cancelled fare left out, rounded to cents")
+
+	onboard := list.Subtotals[2]
+	assert.Equal(t, 450.0, onboard.Revenue, "skipped standing ticket was never paid")
+	assert.Equal(t, 1, onboard.Cancelled)
+
+	assert.Equal(t, 5, list.Total.Bookings)
+	assert.Equal(t, 3150.01, list.Total.Revenue)
+	assert.Equal(t, 2250.01, list.Total.AmountCollected)
+}
+
+func TestBuildTripBookingList_Filters(t *testing.T) {
+	base := time.Now()
+
+	filter, err := NewTripBookingListFilter("Confirmed, boarded", "", "")
+	require.NoError(t, err)
+	list := BuildTripBookingList(&TripBookingListTrip{}, tripBookingEntries(base), filter)
+	assert.Len(t, list.Bookings, 3)
+	assert.Equal(t, 3, list.Total.Bookings, "subtotals follow the filter")
+
+	filter, err = NewTripBookingListFilter("", "app", "stop-kandy")
+	require.NoError(t, err)
+	list = BuildTripBookingList(&TripBookingListTrip{}, tripBookingEntries(base), filter)
+	require.Len(t, list.Bookings, 1)
+	assert.Equal(t, "BK-1", list.Bookings[0].BookingReference)
+	assert.Equal(t, 0, list.Subtotals[1].Bookings)
+
+	_, err = NewTripBookingListFilter("", "kiosk", "")
+	assert.Error(t, err)
+}
+
+func TestTripBookingEntry_CSVRecord(t *testing.T) {
+	phone := "+94771234567"
+	entry := TripBookingEntry{
+		Source: TripBookingSourceManual, Channel: "agent", BookingReference: "AG-7", PassengerName: "Nimal",
+		PassengerPhone: &phone, SeatNumbers: StringArray{"A1", "A2"}, NumberOfSeats: 2, Status: "confirmed",
+		PaymentStatus: "partial", Fare: 1800, AmountCollected: 500, CreatedAt: time.Date(2026, 3, 14, 6, 0, 0, 0, time.UTC),
+	}
+	record := entry.CSVRecord()
+	require.Len(t, record, len(TripBookingCSVHeader))
+	assert.Equal(t, "2026-03-14T06:00:00Z", record[0])
+	assert.Equal(t, "A1 A2", record[8])
+	assert.Equal(t, "1800.00", record[12])
+	assert.Equal(t, "", record[6], "no boarding stop")
+}
@@ -0,0 +1,258 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
+)
+
+const (
+	// TripETAMinSegmentSamples is how many past trips must have timed a leg before their
+	// median is used instead of the timetable
+	TripETAMinSegmentSamples = 3
+	// TripETAStopRadiusDegrees is how close (about 165 m) a past trip's bus must come to a stop
+	// to count as having reached it
+	TripETAStopRadiusDegrees = 0.0015
+	// tripETADefaultSpeedKmh times legs with neither a timetable nor history
+	tripETADefaultSpeedKmh = 30
+	// tripETAMaxSegment is the longest leg a past trip can plausibly have taken; longer ones
+	// are a bus parked or a stop reached again on the way back
+	tripETAMaxSegment = 6 * time.Hour
+)
+
+// TripETAStopStatus says where a stop is relative to the bus
+type TripETAStopStatus string
+
+const (
+	TripETAStopPassed   TripETAStopStatus = "passed"
+	TripETAStopAtStop   TripETAStopStatus = "at_stop"
+	TripETAStopUpcoming TripETAStopStatus = "upcoming"
+)
+
+// TripETABasis says what an estimate was timed from
+type TripETABasis string
+
+const (
+	TripETABasisHistory  TripETABasis = "history"  // Every leg timed by past trips
+	TripETABasisSchedule TripETABasis = "schedule" // At least one leg timed by the timetable or distance
+)
+
+// TripETATrip is an active trip with the stops and times of its scheduled trip
+type TripETATrip struct {
+	GTFSRealtimeTrip
+	ActiveTripID string `db:"active_trip_id"`
+}
+
+// TripStopPassage is when a past trip's bus first came within reach of a stop
+type TripStopPassage struct {
+	ActiveTripID string    `db:"active_trip_id"`
+	StopID       string    `db:"stop_id"`
+	PassedAt     time.Time `db:"passed_at"`
+}
+
+// TripETASource is everything a trip's arrival estimates are built from
+type TripETASource struct {
+	Trip     TripETATrip
+	Stops    []MasterRouteStop // Stops of the trip's route, in stop order
+	Passages []TripStopPassage // Recent completed trips of the same route
+}
+
+// StopETA is when the bus is expected at one stop of its trip
+type StopETA struct {
+	StopID           string            `json:"stop_id"`
+	StopName         string            `json:"stop_name"`
+	StopOrder        int               `json:"stop_order"`
+	Status           TripETAStopStatus `json:"status"`
+	ScheduledArrival *time.Time        `json:"scheduled_arrival,omitempty"`
+	EstimatedArrival *time.Time        `json:"estimated_arrival,omitempty"` // Not set for passed stops
+	MinutesAway      *int              `json:"minutes_away,omitempty"`
+	DelayMinutes     *int              `json:"delay_minutes,omitempty"` // Negative when early
+	Basis            TripETABasis      `json:"basis,omitempty"`
+}
+
+// TripETA is the arrival estimate of every stop of an active trip
+type TripETA struct {
+	ActiveTripID       string           `json:"active_trip_id"`
+	ScheduledTripID    string           `json:"scheduled_trip_id"`
+	Status             ActiveTripStatus `json:"status"`
+	Live               bool             `json:"live"` // Timed from the bus's position; else from the timetable and its delay
+	LastLocationUpdate *time.Time       `json:"last_location_update,omitempty"`
+	CalculatedAt       time.Time        `json:"calculated_at"`
+	Stops              []StopETA        `json:"stops"`
+}
+
+// Stop returns the estimate of one stop, or nil when the trip does not call there
+func (e *TripETA) Stop(stopID string) *StopETA {
+	for i := range e.Stops {
+		if e.Stops[i].StopID == stopID {
+			return &e.Stops[i]
+		}
+	}
+	return nil
+}
+
+// BuildTripETA estimates when the bus reaches each stop it has not passed, or returns nil when
+// the trip's stops cannot be timed. Each leg between two stops takes the median of recent
+// trips of the route when enough of them timed it, else the timetable's time, else the
+// straight-line distance at an average bus speed. With a fresh position the bus is placed
+// along its current leg by distance; without one it is as late at its next stop as it is
+// running now. Stops are those of the GTFS feed, so unlocated stops have no estimate.
+func BuildTripETA(src *TripETASource, now time.Time) *TripETA {
+	trip := src.Trip
+	calls := gtfsTripStopTimes(trip.GTFSTrip, src.Stops)
+	if calls == nil {
+		return nil
+	}
+
+	serviceDay := gtfsServiceDay(trip.DepartureDatetime)
+	history := tripETASegmentTimes(calls, src.Passages)
+	legs := make([]time.Duration, len(calls))
+	fromHistory := make([]bool, len(calls))
+	planned := make([]time.Time, len(calls)) // Timetable time, or the time legs add up to
+	for i, call := range calls {
+		if i > 0 {
+			previous := calls[i-1]
+			switch {
+			case history[i] > 0:
+				legs[i], fromHistory[i] = history[i], true
+			case call.known && previous.known:
+				legs[i] = time.Duration(call.seconds-previous.seconds) * time.Second
+			default:
+				legs[i] = tripETADistanceTime(previous.stop, call.stop)
+			}
+		}
+		if call.known { // Always so for the first call
+			planned[i] = serviceDay.Add(time.Duration(call.seconds) * time.Second)
+		} else {
+			planned[i] = planned[i-1].Add(legs[i])
+		}
+	}
+
+	eta := &TripETA{
+		ActiveTripID:       trip.ActiveTripID,
+		ScheduledTripID:    trip.TripID,
+		Status:             trip.Status,
+		Live:               trip.Latitude != nil && trip.Longitude != nil && trip.LastLocationUpdate != nil && now.Sub(*trip.LastLocationUpdate) <= GTFSRealtimeStaleAfter,
+		LastLocationUpdate: trip.LastLocationUpdate,
+		CalculatedAt:       now,
+		Stops:              make([]StopETA, 0, len(calls)),
+	}
+	next := gtfsRealtimeNextCall(trip.GTFSRealtimeTrip, calls)
+
+	var at time.Time
+	basis := TripETABasisHistory
+	for i, call := range calls {
+		stop := StopETA{
+			StopID:    call.stop.ID,
+			StopName:  call.stop.StopName,
+			StopOrder: call.stop.StopOrder,
+			Status:    TripETAStopUpcoming,
+		}
+		if call.known {
+			scheduled := planned[i]
+			stop.ScheduledArrival = &scheduled
+		}
+		if next < 0 || i < next {
+			stop.Status = TripETAStopPassed
+			eta.Stops = append(eta.Stops, stop)
+			continue
+		}
+
+		switch {
+		case i > next:
+			at = at.Add(legs[i])
+			if !fromHistory[i] {
+				basis = TripETABasisSchedule
+			}
+		case trip.Status == ActiveTripStatusAtStop && trip.CurrentStopID != nil && *trip.CurrentStopID == call.stop.ID:
+			at = now
+			stop.Status = TripETAStopAtStop
+		case i == 0:
+			at = tripETANotBefore(planned[0], now) // Still to leave its first stop
+		case eta.Live:
+			at = now.Add(time.Duration(float64(legs[i]) * tripETALegRemaining(trip.GTFSRealtimeTrip, calls[i-1].stop, call.stop)))
+			if !fromHistory[i] {
+				basis = TripETABasisSchedule
+			}
+		default:
+			delay := time.Duration(gtfsRealtimeDelay(trip.GTFSRealtimeTrip, calls, now)) * time.Second
+			at = tripETANotBefore(planned[i].Add(delay), now)
+			basis = TripETABasisSchedule
+		}
+
+		estimated := at
+		minutes := int(math.Ceil(estimated.Sub(now).Minutes()))
+		if minutes < 0 {
+			minutes = 0
+		}
+		stop.EstimatedArrival = &estimated
+		stop.MinutesAway = &minutes
+		stop.Basis = basis
+		if stop.ScheduledArrival != nil {
+			delay := int(math.Round(estimated.Sub(*stop.ScheduledArrival).Minutes()))
+			stop.DelayMinutes = &delay
+		}
+		eta.Stops = append(eta.Stops, stop)
+	}
+	return eta
+}
+
+// tripETASegmentTimes is, for each call after the first, the median time recent trips took from
+// the call before it; zero when too few trips timed the leg
+func tripETASegmentTimes(calls []gtfsStopTime, passages []TripStopPassage) []time.Duration {
+	byTrip := map[string]map[string]time.Time{}
+	for _, passage := range passages {
+		if byTrip[passage.ActiveTripID] == nil {
+			byTrip[passage.ActiveTripID] = map[string]time.Time{}
+		}
+		byTrip[passage.ActiveTripID][passage.StopID] = passage.PassedAt
+	}
+
+	segments := make([]time.Duration, len(calls))
+	for i := 1; i < len(calls); i++ {
+		samples := []time.Duration{}
+		for _, passed := range byTrip {
+			from, okFrom := passed[calls[i-1].stop.ID]
+			to, okTo := passed[calls[i].stop.ID]
+			if d := to.Sub(from); okFrom && okTo && d > 0 && d < tripETAMaxSegment {
+				samples = append(samples, d)
+			}
+		}
+		if len(samples) < TripETAMinSegmentSamples {
+			continue
+		}
+		sort.Slice(samples, func(a, b int) bool { return samples[a] < samples[b] })
+		middle := len(samples) / 2
+		segments[i] = samples[middle]
+		if len(samples)%2 == 0 {
+			segments[i] = (samples[middle-1] + samples[middle]) / 2
+		}
+	}
+	return segments
+}
+
+// tripETALegRemaining is the share of the leg between two stops the bus still has to cover,
+// by straight-line distance
+func tripETALegRemaining(trip GTFSRealtimeTrip, from, to MasterRouteStop) float64 {
+	leg := utils.HaversineKm(*from.Latitude, *from.Longitude, *to.Latitude, *to.Longitude)
+	if leg <= 0 {
+		return 0
+	}
+	remaining := utils.HaversineKm(*trip.Latitude, *trip.Longitude, *to.Latitude, *to.Longitude) / leg
+	return math.Min(remaining, 1)
+}
+
+// tripETADistanceTime times a leg neither the timetable nor history does
+func tripETADistanceTime(from, to MasterRouteStop) time.Duration {
+	km := utils.HaversineKm(*from.Latitude, *from.Longitude, *to.Latitude, *to.Longitude)
+	return time.Duration(km / tripETADefaultSpeedKmh * float64(time.Hour))
+}
+
+func tripETANotBefore(t, now time.Time) time.Time {
+	if t.Before(now) {
+		return now
+	}
+	return t
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTripETA(t *testing.T) {
+	intp := func(v int) *int { return &v }
+	floatp := func(v float64) *float64 { return &v }
+	strp := func(v string) *string { return &v }
+	timep := func(v time.Time) *time.Time { return &v }
+
+	stops := []MasterRouteStop{
+		{ID: "s1", StopName: "Colombo", MasterRouteID: "r1", StopOrder: 1, Latitude: floatp(6.90), Longitude: floatp(79.86), ArrivalTimeOffsetMinutes: intp(0)},
+		{ID: "s2", StopName: "Negombo", MasterRouteID: "r1", StopOrder: 2, Latitude: floatp(7.00), Longitude: floatp(79.86), ArrivalTimeOffsetMinutes: intp(60)},
+		{ID: "s3", StopName: "Chilaw", MasterRouteID: "r1", StopOrder: 3, Latitude: floatp(7.10), Longitude: floatp(79.86), ArrivalTimeOffsetMinutes: intp(120)},
+	}
+	departure := time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC) // 08:00 in Colombo
+	now := departure.Add(30 * time.Minute)
+
+	// s1 to s2 took 40, 45 and 50 minutes; s2 to s3 was timed by too few trips
+	past := departure.AddDate(0, 0, -7)
+	passages := []TripStopPassage{}
+	for i, minutes := range []int{40, 45, 50} {
+		id := string(rune('a' + i))
+		passages = append(passages,
+			TripStopPassage{ActiveTripID: id, StopID: "s1", PassedAt: past},
+			TripStopPassage{ActiveTripID: id, StopID: "s2", PassedAt: past.Add(time.Duration(minutes) * time.Minute)})
+		if i < 2 {
+			passages = append(passages, TripStopPassage{ActiveTripID: id, StopID: "s3", PassedAt: past.Add(3 * time.Hour)})
+		}
+	}
+
+	running := TripETATrip{
+		ActiveTripID: "at1",
+		GTFSRealtimeTrip: GTFSRealtimeTrip{
+			GTFSTrip:            GTFSTrip{TripID: "t1", DepartureDatetime: departure, MasterRouteID: "r1"},
+			Latitude:            floatp(6.95), // Half way to s2
+			Longitude:           floatp(79.86),
+			LastLocationUpdate:  timep(now.Add(-time.Minute)),
+			ActualDepartureTime: timep(departure),
+			StopsCompleted:      []string{"s1"},
+			Status:              ActiveTripStatusInTransit,
+		},
+	}
+
+	t.Run("live position", func(t *testing.T) {
+		eta := BuildTripETA(&TripETASource{Trip: running, Stops: stops, Passages: passages}, now)
+		require.NotNil(t, eta)
+		assert.True(t, eta.Live)
+		assert.Equal(t, "at1", eta.ActiveTripID)
+		require.Len(t, eta.Stops, 3)
+
+		assert.Equal(t, TripETAStopPassed, eta.Stops[0].Status)
+		assert.Nil(t, eta.Stops[0].EstimatedArrival)
+
+		s2 := eta.Stop("s2")
+		require.NotNil(t, s2.EstimatedArrival)
+		assert.WithinDuration(t, now.Add(22*time.Minute+30*time.Second), *s2.EstimatedArrival, time.Second, "half the median leg")
+		assert.Equal(t, 23, *s2.MinutesAway)
+		assert.Equal(t, -8, *s2.DelayMinutes)
+		assert.Equal(t, TripETABasisHistory, s2.Basis)
+
+		s3 := eta.Stop("s3")
+		assert.WithinDuration(t, s2.EstimatedArrival.Add(time.Hour), *s3.EstimatedArrival, time.Second, "the timetable's leg")
+		assert.Equal(t, TripETABasisSchedule, s3.Basis)
+	})
+
+	t.Run("stale position", func(t *testing.T) {
+		trip := running
+		trip.LastLocationUpdate = timep(now.Add(-20 * time.Minute))
+		trip.ActualDepartureTime = timep(departure.Add(10 * time.Minute))
+		eta := BuildTripETA(&TripETASource{Trip: trip, Stops: stops, Passages: passages}, now)
+		require.NotNil(t, eta)
+		assert.False(t, eta.Live)
+		s2 := eta.Stop("s2")
+		assert.WithinDuration(t, departure.Add(70*time.Minute), *s2.EstimatedArrival, 0, "left 10 minutes late")
+		assert.Equal(t, 10, *s2.DelayMinutes)
+		assert.Equal(t, TripETABasisSchedule, s2.Basis)
+	})
+
+	t.Run("at stop", func(t *testing.T) {
+		trip := running
+		trip.Status = ActiveTripStatusAtStop
+		trip.CurrentStopID = strp("s2")
+		eta := BuildTripETA(&TripETASource{Trip: trip, Stops: stops}, now)
+		require.NotNil(t, eta)
+		s2 := eta.Stop("s2")
+		assert.Equal(t, TripETAStopAtStop, s2.Status)
+		assert.WithinDuration(t, now, *s2.EstimatedArrival, 0)
+		assert.Equal(t, 0, *s2.MinutesAway)
+	})
+
+	t.Run("not departed", func(t *testing.T) {
+		trip := running
+		trip.ActualDepartureTime = nil
+		trip.StopsCompleted = nil
+		eta := BuildTripETA(&TripETASource{Trip: trip, Stops: stops}, departure.Add(-15*time.Minute))
+		require.NotNil(t, eta)
+		assert.WithinDuration(t, departure, *eta.Stop("s1").EstimatedArrival, 0, "leaves on time")
+		assert.WithinDuration(t, departure.Add(time.Hour), *eta.Stop("s2").EstimatedArrival, 0)
+	})
+
+	t.Run("untimed route", func(t *testing.T) {
+		assert.Nil(t, BuildTripETA(&TripETASource{Trip: running, Stops: stops[:1]}, now))
+		assert.Nil(t, (&TripETA{}).Stop("s1"))
+	})
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
+)
+
+const (
+	// TripLocationMaxSpeedKmh is the fastest a bus can plausibly move between two points; faster
+	// hops are GPS glitches and are not counted as distance travelled
+	TripLocationMaxSpeedKmh = 150
+	// TripLocationHistoryMaxPoints bounds how many points one playback returns
+	TripLocationHistoryMaxPoints = 50000
+)
+
+// TripLocationRecord is one position reported by the crew app, as stored in trip_location_history
+type TripLocationRecord struct {
+	ActiveTripID string    `db:"active_trip_id"`
+	Latitude     float64   `db:"latitude"`
+	Longitude    float64   `db:"longitude"`
+	SpeedKmh     *float64  `db:"speed_kmh"`
+	Heading      *float64  `db:"heading"`
+	RecordedAt   time.Time `db:"recorded_at"`
+}
+
+// TripLocationPoint is one position of a trip's playback
+type TripLocationPoint struct {
+	Latitude   float64   `json:"latitude" db:"latitude"`
+	Longitude  float64   `json:"longitude" db:"longitude"`
+	SpeedKmh   *float64  `json:"speed_kmh,omitempty" db:"speed_kmh"`
+	Heading    *float64  `json:"heading,omitempty" db:"heading"`
+	RecordedAt time.Time `json:"recorded_at" db:"recorded_at"`
+}
+
+// TripLocationHistory is the route a bus actually took on a trip, in the order it was reported
+type TripLocationHistory struct {
+	ActiveTripID    string              `json:"active_trip_id"`
+	ScheduledTripID string              `json:"scheduled_trip_id"`
+	Status          ActiveTripStatus    `json:"status"`
+	StartedAt       *time.Time          `json:"started_at,omitempty"` // First point
+	EndedAt         *time.Time          `json:"ended_at,omitempty"`   // Last point
+	DurationMinutes float64             `json:"duration_minutes"`
+	DistanceKm      float64             `json:"distance_km"` // Along the points, without GPS glitches
+	PointCount      int                 `json:"point_count"`
+	Truncated       bool                `json:"truncated"` // Only the first TripLocationHistoryMaxPoints are returned
+	Points          []TripLocationPoint `json:"points"`
+}
+
+// BuildTripLocationHistory measures a trip's points, which must be in recorded order
+func BuildTripLocationHistory(trip *ActiveTrip, points []TripLocationPoint) *TripLocationHistory {
+	history := &TripLocationHistory{
+		ActiveTripID:    trip.ID,
+		ScheduledTripID: trip.ScheduledTripID,
+		Status:          trip.Status,
+		PointCount:      len(points),
+		Truncated:       len(points) >= TripLocationHistoryMaxPoints,
+		Points:          points,
+	}
+	if history.Points == nil {
+		history.Points = []TripLocationPoint{}
+	}
+	if len(points) == 0 {
+		return history
+	}
+
+	first, last := points[0].RecordedAt, points[len(points)-1].RecordedAt
+	history.StartedAt, history.EndedAt = &first, &last
+	history.DurationMinutes = math.Round(last.Sub(first).Minutes()*10) / 10
+
+	distance := 0.0
+	previous := points[0]
+	for _, point := range points[1:] {
+		km := utils.HaversineKm(previous.Latitude, previous.Longitude, point.Latitude, point.Longitude)
+		hours := point.RecordedAt.Sub(previous.RecordedAt).Hours()
+		if hours > 0 && km/hours > TripLocationMaxSpeedKmh {
+			continue // Keep measuring from the last good point
+		}
+		distance += km
+		previous = point
+	}
+	history.DistanceKm = math.Round(distance*100) / 100
+	return history
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTripLocationHistory(t *testing.T) {
+	trip := &ActiveTrip{ID: "at1", ScheduledTripID: "st1", Status: ActiveTripStatusCompleted}
+	start := time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC)
+	points := []TripLocationPoint{
+		{Latitude: 6.9000, Longitude: 79.8600, RecordedAt: start},
+		{Latitude: 6.9090, Longitude: 79.8600, RecordedAt: start.Add(time.Minute)},     // ~1 km north
+		{Latitude: 7.9000, Longitude: 79.8600, RecordedAt: start.Add(2 * time.Minute)}, // GPS glitch, ~110 km away
+		{Latitude: 6.9180, Longitude: 79.8600, RecordedAt: start.Add(3 * time.Minute)}, // ~1 km on
+	}
+
+	history := BuildTripLocationHistory(trip, points)
+	assert.Equal(t, "at1", history.ActiveTripID)
+	assert.Equal(t, 4, history.PointCount)
+	assert.False(t, history.Truncated)
+	require.NotNil(t, history.StartedAt)
+	assert.Equal(t, start, *history.StartedAt)
+	assert.Equal(t, start.Add(3*time.Minute), *history.EndedAt)
+	assert.Equal(t, 3.0, history.DurationMinutes)
+	assert.InDelta(t, 2.0, history.DistanceKm, 0.01, "the glitch is not counted")
+}
+
+func TestBuildTripLocationHistoryNoPoints(t *testing.T) {
+	history := BuildTripLocationHistory(&ActiveTrip{ID: "at1"}, nil)
+	assert.Equal(t, 0, history.PointCount)
+	assert.NotNil(t, history.Points, "an empty playback is an empty list, not null")
+	assert.Nil(t, history.StartedAt)
+	assert.Zero(t, history.DistanceKm)
+}
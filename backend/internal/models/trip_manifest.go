@@ -0,0 +1,232 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TripManifestFormat is a download format of a trip's passenger manifest
+type TripManifestFormat string
+
+const (
+	TripManifestPDF  TripManifestFormat = "pdf"
+	TripManifestCSV  TripManifestFormat = "csv"
+	TripManifestJSON TripManifestFormat = "json"
+)
+
+// ParseTripManifestFormat reads the format query parameter; the default is pdf
+func ParseTripManifestFormat(format string) (TripManifestFormat, error) {
+	switch TripManifestFormat(strings.ToLower(strings.TrimSpace(format))) {
+	case "", TripManifestPDF:
+		return TripManifestPDF, nil
+	case TripManifestCSV:
+		return TripManifestCSV, nil
+	case TripManifestJSON:
+		return TripManifestJSON, nil
+	}
+	return "", &ValidationError{Message: "format must be pdf, csv or json"}
+}
+
+// TripManifestTrip is the trip a manifest is printed for, with its crew
+type TripManifestTrip struct {
+	ScheduledTripID   string    `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	DepartureDatetime time.Time `json:"departure_datetime" db:"departure_datetime"`
+	Status            string    `json:"status" db:"status"`
+	RouteName         *string   `json:"route_name,omitempty" db:"route_name"`
+	RouteNumber       *string   `json:"route_number,omitempty" db:"route_number"`
+	BusNumber         *string   `json:"bus_number,omitempty" db:"bus_number"`
+	DriverName        *string   `json:"driver_name,omitempty" db:"driver_name"`
+	ConductorName     *string   `json:"conductor_name,omitempty" db:"conductor_name"`
+	OwnerUserID       *string   `json:"-" db:"owner_user_id"`
+	DriverUserID      *string   `json:"-" db:"driver_user_id"`
+	ConductorUserID   *string   `json:"-" db:"conductor_user_id"`
+}
+
+// CanBeViewedBy reports whether the user is the trip's bus owner, driver or conductor
+func (t *TripManifestTrip) CanBeViewedBy(userID string) bool {
+	for _, allowed := range []*string{t.OwnerUserID, t.DriverUserID, t.ConductorUserID} {
+		if allowed != nil && *allowed == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// TripManifestPassenger is one seat (or standing place) on the manifest
+type TripManifestPassenger struct {
+	Source            TripBookingSource `json:"source" db:"source"`
+	BookingReference  string            `json:"booking_reference" db:"booking_reference"`
+	PassengerName     string            `json:"passenger_name" db:"passenger_name"`
+	PassengerPhone    *string           `json:"passenger_phone,omitempty" db:"passenger_phone"`
+	SeatNumber        *string           `json:"seat_number,omitempty" db:"seat_number"` // Empty for standing tickets
+	BoardingStopID    *string           `json:"-" db:"boarding_stop_id"`
+	BoardingStopName  *string           `json:"-" db:"boarding_stop_name"`
+	BoardingStopOrder *int              `json:"-" db:"boarding_stop_order"`
+	AlightingStopName *string           `json:"alighting_stop_name,omitempty" db:"alighting_stop_name"`
+	Status            string            `json:"status" db:"status"`
+	PaymentStatus     string            `json:"payment_status" db:"payment_status"`
+}
+
+// IsPaid reports whether the fare has been collected in full
+func (p *TripManifestPassenger) IsPaid() bool {
+	return p.PaymentStatus == "paid"
+}
+
+// TripManifestStop is the passengers boarding at one stop
+type TripManifestStop struct {
+	BoardingStopID   *string                 `json:"boarding_stop_id,omitempty"` // Empty for passengers without a boarding stop
+	BoardingStopName string                  `json:"boarding_stop_name"`
+	Passengers       []TripManifestPassenger `json:"passengers"`
+	Unpaid           int                     `json:"unpaid"`
+}
+
+// TripManifest is the printable list of everyone travelling on a trip, grouped by boarding stop
+type TripManifest struct {
+	Trip            *TripManifestTrip  `json:"trip"`
+	Stops           []TripManifestStop `json:"stops"` // In route order; passengers without a stop last
+	TotalPassengers int                `json:"total_passengers"`
+	Standing        int                `json:"standing"`
+	Unpaid          int                `json:"unpaid"` // Passengers whose fare is still to be collected
+	GeneratedAt     time.Time          `json:"generated_at"`
+}
+
+// tripManifestUnknownStop labels passengers booked without a boarding stop
+const tripManifestUnknownStop = "Boarding stop not set"
+
+// BuildTripManifest groups a trip's travelling passengers by boarding stop in route order, each
+// stop's passengers by seat number. Cancelled seats and skipped standing tickets are left out.
+func BuildTripManifest(trip *TripManifestTrip, passengers []TripManifestPassenger, now time.Time) *TripManifest {
+	manifest := &TripManifest{Trip: trip, Stops: []TripManifestStop{}, GeneratedAt: now}
+
+	type stopGroup struct {
+		stop  TripManifestStop
+		order int
+	}
+	groups := map[string]*stopGroup{}
+	for _, p := range passengers {
+		if tripBookingVoidStatuses[p.Status] {
+			continue
+		}
+		key := ""
+		if p.BoardingStopID != nil {
+			key = *p.BoardingStopID
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &stopGroup{stop: TripManifestStop{BoardingStopID: p.BoardingStopID, BoardingStopName: tripManifestUnknownStop}, order: math.MaxInt}
+			if p.BoardingStopName != nil {
+				group.stop.BoardingStopName = *p.BoardingStopName
+			}
+			if key != "" && p.BoardingStopOrder != nil {
+				group.order = *p.BoardingStopOrder
+			}
+			groups[key] = group
+		}
+		group.stop.Passengers = append(group.stop.Passengers, p)
+
+		manifest.TotalPassengers++
+		if p.SeatNumber == nil {
+			manifest.Standing++
+		}
+		if !p.IsPaid() {
+			group.stop.Unpaid++
+			manifest.Unpaid++
+		}
+	}
+
+	ordered := make([]*stopGroup, 0, len(groups))
+	for _, group := range groups {
+		ordered = append(ordered, group)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].order != ordered[j].order {
+			return ordered[i].order < ordered[j].order
+		}
+		return ordered[i].stop.BoardingStopName < ordered[j].stop.BoardingStopName
+	})
+	for _, group := range ordered {
+		sort.SliceStable(group.stop.Passengers, func(i, j int) bool {
+			return manifestPassengerLess(&group.stop.Passengers[i], &group.stop.Passengers[j])
+		})
+		manifest.Stops = append(manifest.Stops, group.stop)
+	}
+	return manifest
+}
+
+// manifestPassengerLess orders seated passengers by seat number, then standing passengers by
+// their ticket reference
+func manifestPassengerLess(a, b *TripManifestPassenger) bool {
+	if (a.SeatNumber == nil) != (b.SeatNumber == nil) {
+		return a.SeatNumber != nil
+	}
+	if a.SeatNumber != nil && *a.SeatNumber != *b.SeatNumber {
+		return seatNumberLess(*a.SeatNumber, *b.SeatNumber)
+	}
+	return seatNumberLess(a.BookingReference, b.BookingReference)
+}
+
+// seatNumberLess compares seat numbers so that digit runs order by value: "A2" before "A10"
+func seatNumberLess(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			si, sj := i, j
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			na := strings.TrimLeft(string(ra[si:i]), "0")
+			nb := strings.TrimLeft(string(rb[sj:j]), "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			continue
+		}
+		if ra[i] != rb[j] {
+			return ra[i] < rb[j]
+		}
+		i++
+		j++
+	}
+	return len(ra)-i < len(rb)-j
+}
+
+// TripManifestCSVHeader is the header row of a trip manifest CSV export
+var TripManifestCSVHeader = []string{
+	"boarding_stop", "seat_number", "passenger_name", "passenger_phone", "alighting_stop",
+	"booking_reference", "source", "status", "payment_status",
+}
+
+// CSVRecords returns the manifest as rows matching TripManifestCSVHeader, in manifest order
+func (m *TripManifest) CSVRecords() [][]string {
+	records := make([][]string, 0, m.TotalPassengers)
+	for _, stop := range m.Stops {
+		for _, p := range stop.Passengers {
+			seat := stringOrEmpty(p.SeatNumber)
+			if seat == "" {
+				seat = "standing"
+			}
+			records = append(records, []string{
+				stop.BoardingStopName,
+				seat,
+				p.PassengerName,
+				stringOrEmpty(p.PassengerPhone),
+				stringOrEmpty(p.AlightingStopName),
+				p.BookingReference,
+				string(p.Source),
+				p.Status,
+				p.PaymentStatus,
+			})
+		}
+	}
+	return records
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTripManifest_GroupsByBoardingStop(t *testing.T) {
+	kandy, peradeniya := "stop-kandy", "stop-peradeniya"
+	kandyName, peradeniyaName := "Kandy", "Peradeniya"
+	first, second := 1, 2
+	seat := func(s string) *string { return &s }
+
+	passengers := []TripManifestPassenger{
+		{Source: TripBookingSourceApp, BookingReference: "BK-2", PassengerName: "Nimal", SeatNumber: seat("A10"), BoardingStopID: &kandy, BoardingStopName: &kandyName, BoardingStopOrder: &first, Status: "booked", PaymentStatus: "paid"},
+		{Source: TripBookingSourceManual, BookingReference: "PH-1", PassengerName: "Kamala", SeatNumber: seat("B1"), BoardingStopID: &peradeniya, BoardingStopName: &peradeniyaName, BoardingStopOrder: &second, Status: "confirmed", PaymentStatus: "partial"},
+		{Source: TripBookingSourceApp, BookingReference: "BK-1", PassengerName: "Sunil", SeatNumber: seat("A2"), BoardingStopID: &kandy, BoardingStopName: &kandyName, BoardingStopOrder: &first, Status: "boarded", PaymentStatus: "paid"},
+		{Source: TripBookingSourceApp, BookingReference: "BK-3", PassengerName: "Gone", SeatNumber: seat("A3"), BoardingStopID: &kandy, BoardingStopName: &kandyName, BoardingStopOrder: &first, Status: "cancelled", PaymentStatus: "refunded"},
+		{Source: TripBookingSourceOnboard, BookingReference: "STANDING-1", PassengerName: "", Status: "waiting", PaymentStatus: "pending"},
+		{Source: TripBookingSourceOnboard, BookingReference: "STANDING-2", Status: "skipped", PaymentStatus: "pending"},
+	}
+	manifest := BuildTripManifest(&TripManifestTrip{ScheduledTripID: "trip-1"}, passengers, time.Now())
+
+	assert.Equal(t, 4, manifest.TotalPassengers, "cancelled and skipped left out")
+	assert.Equal(t, 1, manifest.Standing)
+	assert.Equal(t, 2, manifest.Unpaid)
+
+	require.Len(t, manifest.Stops, 3)
+	assert.Equal(t, "Kandy", manifest.Stops[0].BoardingStopName, "route order")
+	assert.Equal(t, "Peradeniya", manifest.Stops[1].BoardingStopName)
+	assert.Equal(t, "Boarding stop not set", manifest.Stops[2].BoardingStopName)
+	assert.Equal(t, 1, manifest.Stops[1].Unpaid)
+
+	kandyStop := manifest.Stops[0].Passengers
+	require.Len(t, kandyStop, 2)
+	assert.Equal(t, "A2", *kandyStop[0].SeatNumber, "seat numbers in natural order")
+	assert.Equal(t, "A10", *kandyStop[1].SeatNumber)
+
+	records := manifest.CSVRecords()
+	require.Len(t, records, 4)
+	assert.Len(t, records[0], len(TripManifestCSVHeader))
+	assert.Equal(t, "standing", records[3][1])
+}
+
+func TestSeatNumberLess(t *testing.T) {
+	assert.True(t, seatNumberLess("2", "10"))
+	assert.True(t, seatNumberLess("A9", "A10"))
+	assert.True(t, seatNumberLess("A10", "B1"))
+	assert.True(t, seatNumberLess("A", "A1"))
+	assert.False(t, seatNumberLess("A01", "A1"))
+	assert.False(t, seatNumberLess("A1", "A1"))
+}
+
+func TestParseTripManifestFormat(t *testing.T) {
+	format, err := ParseTripManifestFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, TripManifestPDF, format)
+
+	format, err = ParseTripManifestFormat("CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, TripManifestCSV, format)
+
+	_, err = ParseTripManifestFormat("xlsx")
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// TripParticipantRole is a participant's role in a trip message thread
+type TripParticipantRole string
+
+const (
+	TripParticipantOwner     TripParticipantRole = "owner"
+	TripParticipantDriver    TripParticipantRole = "driver"
+	TripParticipantConductor TripParticipantRole = "conductor"
+)
+
+// TripMessage is a note posted to a scheduled trip's thread by the owner or assigned staff
+type TripMessage struct {
+	ID              string              `json:"id" db:"id"`
+	ScheduledTripID string              `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	SenderUserID    string              `json:"sender_user_id" db:"sender_user_id"`
+	SenderRole      TripParticipantRole `json:"sender_role" db:"sender_role"`
+	Body            string              `json:"body" db:"body"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+	ReadBy          []string            `json:"read_by" db:"-"` // User IDs of other participants who have read it
+}
+
+// TripThreadParticipant is someone who can read and post in a trip thread
+type TripThreadParticipant struct {
+	UserID     string              `json:"user_id"`
+	Role       TripParticipantRole `json:"role"`
+	Name       *string             `json:"name,omitempty"`
+	LastReadAt *time.Time          `json:"last_read_at,omitempty"`
+}
+
+// TripThreadParticipants is the owner and assigned staff of a scheduled trip, as stored
+type TripThreadParticipants struct {
+	ScheduledTripID   string    `db:"scheduled_trip_id"`
+	DepartureDatetime time.Time `db:"departure_datetime"`
+	OwnerUserID       *string   `db:"owner_user_id"`
+	OwnerName         *string   `db:"owner_name"`
+	DriverUserID      *string   `db:"driver_user_id"`
+	DriverName        *string   `db:"driver_name"`
+	ConductorUserID   *string   `db:"conductor_user_id"`
+	ConductorName     *string   `db:"conductor_name"`
+}
+
+// List returns the participants that are set, in owner, driver, conductor order
+func (p *TripThreadParticipants) List() []TripThreadParticipant {
+	var list []TripThreadParticipant
+	add := func(userID, name *string, role TripParticipantRole) {
+		if userID != nil && *userID != "" {
+			list = append(list, TripThreadParticipant{UserID: *userID, Role: role, Name: name})
+		}
+	}
+	add(p.OwnerUserID, p.OwnerName, TripParticipantOwner)
+	add(p.DriverUserID, p.DriverName, TripParticipantDriver)
+	add(p.ConductorUserID, p.ConductorName, TripParticipantConductor)
+	return list
+}
+
+// RoleOf returns the user's role in the thread, or false if they are not a participant
+func (p *TripThreadParticipants) RoleOf(userID string) (TripParticipantRole, bool) {
+	for _, participant := range p.List() {
+		if participant.UserID == userID {
+			return participant.Role, true
+		}
+	}
+	return "", false
+}
+
+// TripThread is a page of a trip's message thread
+type TripThread struct {
+	ScheduledTripID string                  `json:"scheduled_trip_id"`
+	Participants    []TripThreadParticipant `json:"participants"`
+	Messages        []TripMessage           `json:"messages"` // Newest first
+	UnreadCount     int                     `json:"unread_count"`
+	HasMore         bool                    `json:"has_more"`
+}
+
+// SendTripMessageRequest posts a message to a trip thread
+type SendTripMessageRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=2000"`
+}
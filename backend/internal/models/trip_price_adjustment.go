@@ -0,0 +1,155 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// PriceAdjustmentKind is whether an adjustment raises or lowers a trip's fares
+type PriceAdjustmentKind string
+
+const (
+	PriceAdjustmentSurcharge PriceAdjustmentKind = "surcharge"
+	PriceAdjustmentDiscount  PriceAdjustmentKind = "discount"
+)
+
+// PriceAdjustmentMode is how an adjustment's value is applied to each fare
+type PriceAdjustmentMode string
+
+const (
+	PriceAdjustmentPercentage PriceAdjustmentMode = "percentage" // Value is a percent of the fare
+	PriceAdjustmentFixed      PriceAdjustmentMode = "fixed"      // Value is an amount per seat
+)
+
+// MaxPriceAdjustmentTrips caps how many trips one request adjusts
+const MaxPriceAdjustmentTrips = 200
+
+// TripPriceAdjustment is an owner's surcharge or discount on a trip's fares while bookings are
+// made between ValidFrom and ValidUntil (trip_price_adjustments table). It applies on top of
+// each seat's price; surcharges never take a fare above the trip permit's approved fare.
+type TripPriceAdjustment struct {
+	ID              string              `json:"id" db:"id"`
+	ScheduledTripID string              `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	BusOwnerID      string              `json:"bus_owner_id" db:"bus_owner_id"`
+	Kind            PriceAdjustmentKind `json:"kind" db:"kind"`
+	Mode            PriceAdjustmentMode `json:"mode" db:"mode"`
+	Value           float64             `json:"value" db:"value"`
+	Label           *string             `json:"label,omitempty" db:"label"` // Shown to passengers, e.g. "Avurudu surcharge"
+	ValidFrom       time.Time           `json:"valid_from" db:"valid_from"`
+	ValidUntil      time.Time           `json:"valid_until" db:"valid_until"`
+	CreatedBy       string              `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+	RevokedAt       *time.Time          `json:"revoked_at,omitempty" db:"revoked_at"`
+	FareCap         *float64            `json:"fare_cap,omitempty" db:"fare_cap"` // Permit's approved fare, when set
+}
+
+// ActiveAt reports whether the adjustment prices bookings made at t
+func (a *TripPriceAdjustment) ActiveAt(t time.Time) bool {
+	return a.RevokedAt == nil && !t.Before(a.ValidFrom) && t.Before(a.ValidUntil)
+}
+
+// Apply returns a fare after the adjustment. A nil adjustment leaves the fare unchanged.
+// Discounts stop at zero; surcharges stop at the fare cap, and never raise a fare that is
+// already above it.
+func (a *TripPriceAdjustment) Apply(fare float64) float64 {
+	if a == nil || fare <= 0 {
+		return fare
+	}
+	delta := a.Value
+	if a.Mode == PriceAdjustmentPercentage {
+		delta = fare * a.Value / 100
+	}
+
+	adjusted := fare + delta
+	if a.Kind == PriceAdjustmentDiscount {
+		adjusted = math.Max(0, fare-delta)
+	} else if a.FareCap != nil && *a.FareCap > 0 && adjusted > *a.FareCap {
+		adjusted = math.Max(fare, *a.FareCap)
+	}
+	return RoundMoney(adjusted)
+}
+
+// Applied describes the adjustment in a price breakdown; amount is the total change to the fares
+func (a *TripPriceAdjustment) Applied(amount float64) *AppliedPriceAdjustment {
+	if a == nil {
+		return nil
+	}
+	return &AppliedPriceAdjustment{
+		ID:     a.ID,
+		Kind:   a.Kind,
+		Mode:   a.Mode,
+		Value:  a.Value,
+		Label:  a.Label,
+		Amount: RoundMoney(amount),
+	}
+}
+
+// AppliedPriceAdjustment records a trip surcharge or discount in a quote or pricing snapshot
+type AppliedPriceAdjustment struct {
+	ID     string              `json:"id"`
+	Kind   PriceAdjustmentKind `json:"kind"`
+	Mode   PriceAdjustmentMode `json:"mode"`
+	Value  float64             `json:"value"`
+	Label  *string             `json:"label,omitempty"`
+	Amount float64             `json:"amount"` // Total added (surcharge) or taken off (discount, negative)
+}
+
+// ApplyTripPriceAdjustmentRequest applies one surcharge or discount to several trips. Each trip's
+// existing adjustment is replaced.
+type ApplyTripPriceAdjustmentRequest struct {
+	TripIDs    []string            `json:"trip_ids" binding:"required,min=1"`
+	Kind       PriceAdjustmentKind `json:"kind" binding:"required"`
+	Mode       PriceAdjustmentMode `json:"mode" binding:"required"`
+	Value      float64             `json:"value" binding:"required"`
+	Label      string              `json:"label" binding:"max=100"`
+	ValidFrom  *time.Time          `json:"valid_from"` // Defaults to now
+	ValidUntil time.Time           `json:"valid_until" binding:"required"`
+}
+
+// Validate checks the request and returns when the adjustment starts
+func (r *ApplyTripPriceAdjustmentRequest) Validate(now time.Time) (time.Time, error) {
+	if len(r.TripIDs) > MaxPriceAdjustmentTrips {
+		return time.Time{}, &ValidationError{Message: "at most 200 trips can be adjusted at once"}
+	}
+	if r.Kind != PriceAdjustmentSurcharge && r.Kind != PriceAdjustmentDiscount {
+		return time.Time{}, &ValidationError{Message: "kind must be surcharge or discount"}
+	}
+	if r.Mode != PriceAdjustmentPercentage && r.Mode != PriceAdjustmentFixed {
+		return time.Time{}, &ValidationError{Message: "mode must be percentage or fixed"}
+	}
+	if r.Value <= 0 {
+		return time.Time{}, &ValidationError{Message: "value must be greater than zero"}
+	}
+	if r.Mode == PriceAdjustmentPercentage && r.Value > 100 {
+		return time.Time{}, &ValidationError{Message: "a percentage adjustment must not exceed 100"}
+	}
+	r.Label = strings.TrimSpace(r.Label)
+
+	from := now
+	if r.ValidFrom != nil && r.ValidFrom.After(now) {
+		from = *r.ValidFrom
+	}
+	if !r.ValidUntil.After(from) {
+		return time.Time{}, &ValidationError{Message: "valid_until must be after valid_from and in the future"}
+	}
+	return from, nil
+}
+
+// PriceAdjustmentTrip is a trip considered for an adjustment, with what is needed to check and price it
+type PriceAdjustmentTrip struct {
+	TripID            string              `db:"trip_id"`
+	BusOwnerID        *string             `db:"bus_owner_id"`
+	Status            ScheduledTripStatus `db:"status"`
+	DepartureDatetime time.Time           `db:"departure_datetime"`
+	BaseFare          float64             `db:"base_fare"`
+	FareCap           *float64            `db:"fare_cap"`
+}
+
+// TripPriceAdjustmentResult is an applied adjustment with its effect on the trip's base fare
+type TripPriceAdjustmentResult struct {
+	Adjustment       *TripPriceAdjustment `json:"adjustment"`
+	BaseFare         float64              `json:"base_fare"`
+	AdjustedBaseFare float64              `json:"adjusted_base_fare"`
+	Capped           bool                 `json:"capped"` // The surcharge was limited by the permit's approved fare
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTripPriceAdjustmentApply(t *testing.T) {
+	var none *TripPriceAdjustment
+	assert.Equal(t, 500.0, none.Apply(500))
+	assert.Nil(t, none.Applied(0))
+
+	fareCap := 600.0
+	for name, tc := range map[string]struct {
+		adjustment TripPriceAdjustment
+		fare       float64
+		want       float64
+	}{
+		"percentage surcharge":          {TripPriceAdjustment{Kind: PriceAdjustmentSurcharge, Mode: PriceAdjustmentPercentage, Value: 10}, 500, 550},
+		"fixed surcharge":               {TripPriceAdjustment{Kind: PriceAdjustmentSurcharge, Mode: PriceAdjustmentFixed, Value: 75}, 500, 575},
+		"surcharge capped":              {TripPriceAdjustment{Kind: PriceAdjustmentSurcharge, Mode: PriceAdjustmentPercentage, Value: 50, FareCap: &fareCap}, 500, 600},
+		"surcharge never lowers a fare": {TripPriceAdjustment{Kind: PriceAdjustmentSurcharge, Mode: PriceAdjustmentFixed, Value: 50, FareCap: &fareCap}, 650, 650},
+		"percentage discount":           {TripPriceAdjustment{Kind: PriceAdjustmentDiscount, Mode: PriceAdjustmentPercentage, Value: 15}, 333, 283.05},
+		"discount ignores cap":          {TripPriceAdjustment{Kind: PriceAdjustmentDiscount, Mode: PriceAdjustmentFixed, Value: 50, FareCap: &fareCap}, 700, 650},
+		"discount stops at zero":        {TripPriceAdjustment{Kind: PriceAdjustmentDiscount, Mode: PriceAdjustmentFixed, Value: 900}, 500, 0},
+	} {
+		assert.Equal(t, tc.want, tc.adjustment.Apply(tc.fare), name)
+	}
+}
+
+func TestApplyTripPriceAdjustmentRequestValidate(t *testing.T) {
+	now := time.Date(2026, 4, 10, 8, 0, 0, 0, time.UTC)
+	later := now.Add(48 * time.Hour)
+
+	req := ApplyTripPriceAdjustmentRequest{TripIDs: []string{"t1"}, Kind: PriceAdjustmentSurcharge, Mode: PriceAdjustmentPercentage, Value: 20, Label: " Avurudu ", ValidUntil: later}
+	from, err := req.Validate(now)
+	require.NoError(t, err)
+	assert.Equal(t, now, from, "starts now by default")
+	assert.Equal(t, "Avurudu", req.Label)
+
+	tomorrow := now.Add(24 * time.Hour)
+	req.ValidFrom = &tomorrow
+	from, err = req.Validate(now)
+	require.NoError(t, err)
+	assert.Equal(t, tomorrow, from)
+
+	var validationErr *ValidationError
+	for name, tc := range map[string]ApplyTripPriceAdjustmentRequest{
+		"bad kind":         {TripIDs: []string{"t1"}, Kind: "markup", Mode: PriceAdjustmentFixed, Value: 10, ValidUntil: later},
+		"bad mode":         {TripIDs: []string{"t1"}, Kind: PriceAdjustmentDiscount, Mode: "ratio", Value: 10, ValidUntil: later},
+		"zero value":       {TripIDs: []string{"t1"}, Kind: PriceAdjustmentDiscount, Mode: PriceAdjustmentFixed, ValidUntil: later},
+		"over 100 percent": {TripIDs: []string{"t1"}, Kind: PriceAdjustmentDiscount, Mode: PriceAdjustmentPercentage, Value: 120, ValidUntil: later},
+		"ended":            {TripIDs: []string{"t1"}, Kind: PriceAdjustmentDiscount, Mode: PriceAdjustmentFixed, Value: 10, ValidUntil: now.Add(-time.Hour)},
+		"too many trips":   {TripIDs: make([]string, MaxPriceAdjustmentTrips+1), Kind: PriceAdjustmentDiscount, Mode: PriceAdjustmentFixed, Value: 10, ValidUntil: later},
+	} {
+		_, err := tc.Validate(now)
+		assert.ErrorAs(t, err, &validationErr, name)
+	}
+}
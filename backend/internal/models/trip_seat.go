@@ -67,6 +67,8 @@ type TripSeatWithBookingInfo struct {
 	PassengerPhone *string `json:"passenger_phone,omitempty" db:"passenger_phone"`
 	BookingRef     *string `json:"booking_reference,omitempty" db:"booking_reference"`
 	PaymentStatus  *string `json:"payment_status,omitempty" db:"payment_status"`
+	// SeatPrice after the trip's active surcharge or discount; omitted when the trip has none
+	AdjustedPrice *float64 `json:"adjusted_price,omitempty" db:"-"`
 }
 
 // CreateTripSeatsRequest is used when assigning a seat layout to a trip
@@ -92,3 +94,84 @@ type UpdateSeatPriceRequest struct {
 	SeatIDs  []string `json:"seat_ids" binding:"required,min=1"`
 	NewPrice float64  `json:"new_price" binding:"required,gte=0"`
 }
+
+// seatCounterRepairMaxDays bounds the date range of a single counter repair
+const seatCounterRepairMaxDays = 92
+
+// RepairSeatCountersRequest asks for the cached seat counters of a trip or date range to be recomputed
+type RepairSeatCountersRequest struct {
+	ScheduledTripID *string `json:"scheduled_trip_id,omitempty"`
+	StartDate       *string `json:"start_date,omitempty"` // YYYY-MM-DD
+	EndDate         *string `json:"end_date,omitempty"`   // YYYY-MM-DD, inclusive
+	DryRun          bool    `json:"dry_run"`              // Report drift without fixing it
+}
+
+// SeatCounterRepairFilter selects the trips whose counters are checked
+type SeatCounterRepairFilter struct {
+	ScheduledTripID *string
+	StartDate       *time.Time
+	EndDate         *time.Time
+}
+
+// Filter validates the request and converts it to a repair filter. Either a trip or a
+// complete date range of at most 92 days is required.
+func (r *RepairSeatCountersRequest) Filter() (SeatCounterRepairFilter, error) {
+	var filter SeatCounterRepairFilter
+	if r.ScheduledTripID != nil && *r.ScheduledTripID != "" {
+		filter.ScheduledTripID = r.ScheduledTripID
+	}
+
+	parse := func(name string, value *string) (*time.Time, error) {
+		if value == nil || *value == "" {
+			return nil, nil
+		}
+		date, err := time.Parse("2006-01-02", *value)
+		if err != nil {
+			return nil, &ValidationError{Message: name + " must be in YYYY-MM-DD format"}
+		}
+		return &date, nil
+	}
+	var err error
+	if filter.StartDate, err = parse("start_date", r.StartDate); err != nil {
+		return filter, err
+	}
+	if filter.EndDate, err = parse("end_date", r.EndDate); err != nil {
+		return filter, err
+	}
+
+	if (filter.StartDate == nil) != (filter.EndDate == nil) {
+		return filter, &ValidationError{Message: "start_date and end_date must be given together"}
+	}
+	if filter.StartDate != nil {
+		if filter.EndDate.Before(*filter.StartDate) {
+			return filter, &ValidationError{Message: "end_date must not be before start_date"}
+		}
+		if filter.EndDate.Sub(*filter.StartDate) > seatCounterRepairMaxDays*24*time.Hour {
+			return filter, &ValidationError{Message: "date range must not exceed 92 days"}
+		}
+	} else if filter.ScheduledTripID == nil {
+		return filter, &ValidationError{Message: "scheduled_trip_id or start_date and end_date is required"}
+	}
+	return filter, nil
+}
+
+// TripSeatCounterDrift is a trip whose cached seat counters disagree with its trip_seats
+type TripSeatCounterDrift struct {
+	ScheduledTripID      string    `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	DepartureDatetime    time.Time `json:"departure_datetime" db:"departure_datetime"`
+	CachedAvailableSeats *int      `json:"cached_available_seats" db:"cached_available_seats"`
+	ActualAvailableSeats int       `json:"actual_available_seats" db:"actual_available_seats"`
+	AvailableSeatsDelta  int       `json:"available_seats_delta" db:"available_seats_delta"` // actual - cached
+	CachedBookedSeats    *int      `json:"cached_booked_seats" db:"cached_booked_seats"`
+	ActualBookedSeats    int       `json:"actual_booked_seats" db:"actual_booked_seats"`
+	BookedSeatsDelta     int       `json:"booked_seats_delta" db:"booked_seats_delta"` // actual - cached
+}
+
+// SeatCounterRepairResult reports how far off the cached counters were and what was fixed
+type SeatCounterRepairResult struct {
+	DryRun        bool                   `json:"dry_run"`
+	TripsChecked  int                    `json:"trips_checked"`
+	TripsDrifted  int                    `json:"trips_drifted"`
+	TripsRepaired int                    `json:"trips_repaired"`
+	Drift         []TripSeatCounterDrift `json:"drift"`
+}
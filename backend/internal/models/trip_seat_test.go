@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairSeatCountersRequest_Filter(t *testing.T) {
+	str := func(value string) *string { return &value }
+
+	filter, err := (&RepairSeatCountersRequest{ScheduledTripID: str("trip-1")}).Filter()
+	require.NoError(t, err)
+	assert.Equal(t, "trip-1", *filter.ScheduledTripID)
+	assert.Nil(t, filter.StartDate)
+
+	filter, err = (&RepairSeatCountersRequest{StartDate: str("2026-03-01"), EndDate: str("2026-03-31")}).Filter()
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-31", filter.EndDate.Format("2006-01-02"))
+
+	cases := map[string]RepairSeatCountersRequest{
+		"nothing selected": {},
+		"open range":       {StartDate: str("2026-03-01")},
+		"bad date":         {StartDate: str("01/03/2026"), EndDate: str("2026-03-31")},
+		"reversed":         {StartDate: str("2026-03-31"), EndDate: str("2026-03-01")},
+		"too wide":         {StartDate: str("2026-01-01"), EndDate: str("2026-06-30")},
+	}
+	for name, req := range cases {
+		_, err := req.Filter()
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr, name)
+	}
+}
@@ -0,0 +1,113 @@
+package models
+
+import "time"
+
+// EmergencyContact is someone a passenger wants notified about their trips
+type EmergencyContact struct {
+	ID             string    `json:"id" db:"id"`
+	UserID         string    `json:"user_id" db:"user_id"`
+	Name           string    `json:"name" db:"name"`
+	Phone          string    `json:"phone" db:"phone"`
+	Relationship   *string   `json:"relationship,omitempty" db:"relationship"`
+	AutoShareTrips bool      `json:"auto_share_trips" db:"auto_share_trips"` // SMS a tracking link whenever a booked trip starts
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TripShareToken grants public, read-only tracking of one scheduled trip
+type TripShareToken struct {
+	ID              string     `json:"id" db:"id"`
+	Token           string     `json:"token" db:"token"`
+	UserID          string     `json:"user_id" db:"user_id"`
+	ScheduledTripID string     `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	ExpiresAt       time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsActive returns true if the token is neither revoked nor expired
+func (t *TripShareToken) IsActive() bool {
+	return t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
+
+// TripIncidentType classifies an incident reported by trip staff
+type TripIncidentType string
+
+const (
+	TripIncidentBreakdown TripIncidentType = "breakdown"
+	TripIncidentAccident  TripIncidentType = "accident"
+	TripIncidentMedical   TripIncidentType = "medical"
+	TripIncidentSecurity  TripIncidentType = "security"
+	TripIncidentDelay     TripIncidentType = "delay"
+	TripIncidentOther     TripIncidentType = "other"
+)
+
+// TripIncident is an incident flagged on an active trip
+type TripIncident struct {
+	ID                string           `json:"id" db:"id"`
+	ActiveTripID      string           `json:"active_trip_id" db:"active_trip_id"`
+	ScheduledTripID   string           `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	ReportedByStaffID string           `json:"reported_by_staff_id" db:"reported_by_staff_id"`
+	IncidentType      TripIncidentType `json:"incident_type" db:"incident_type"`
+	Description       *string          `json:"description,omitempty" db:"description"`
+	Latitude          *float64         `json:"latitude,omitempty" db:"latitude"`
+	Longitude         *float64         `json:"longitude,omitempty" db:"longitude"`
+	CreatedAt         time.Time        `json:"created_at" db:"created_at"`
+}
+
+// TripShareRecipient is an emergency contact of a passenger booked on a trip
+type TripShareRecipient struct {
+	UserID         string  `db:"user_id"`
+	PassengerName  *string `db:"passenger_name"`
+	ContactName    string  `db:"contact_name"`
+	ContactPhone   string  `db:"contact_phone"`
+	AutoShareTrips bool    `db:"auto_share_trips"`
+}
+
+// SharedTripView is the public tracking page data behind a share token.
+// It deliberately excludes booking and passenger details beyond a first name.
+type SharedTripView struct {
+	ScheduledTripID      string            `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	PassengerFirstName   *string           `json:"passenger_first_name,omitempty" db:"passenger_first_name"`
+	RouteName            string            `json:"route_name" db:"route_name"`
+	DepartureDatetime    time.Time         `json:"departure_datetime" db:"departure_datetime"`
+	TripStatus           *ActiveTripStatus `json:"trip_status,omitempty" db:"trip_status"` // nil until the trip starts
+	CurrentLatitude      *float64          `json:"current_latitude,omitempty" db:"current_latitude"`
+	CurrentLongitude     *float64          `json:"current_longitude,omitempty" db:"current_longitude"`
+	CurrentSpeedKmh      *float64          `json:"current_speed_kmh,omitempty" db:"current_speed_kmh"`
+	Heading              *float64          `json:"heading,omitempty" db:"heading"`
+	LastLocationUpdate   *time.Time        `json:"last_location_update,omitempty" db:"last_location_update"`
+	EstimatedArrivalTime *time.Time        `json:"estimated_arrival_time,omitempty" db:"estimated_arrival_time"`
+	LinkExpiresAt        time.Time         `json:"link_expires_at" db:"link_expires_at"`
+	Incidents            []TripIncident    `json:"incidents" db:"-"`
+}
+
+// CreateEmergencyContactRequest adds an emergency contact
+type CreateEmergencyContactRequest struct {
+	Name           string  `json:"name" binding:"required,min=1,max=100"`
+	Phone          string  `json:"phone" binding:"required"`
+	Relationship   *string `json:"relationship" binding:"omitempty,max=50"`
+	AutoShareTrips bool    `json:"auto_share_trips"`
+}
+
+// UpdateEmergencyContactRequest updates an emergency contact; only provided fields change
+type UpdateEmergencyContactRequest struct {
+	Name           *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Phone          *string `json:"phone"`
+	Relationship   *string `json:"relationship" binding:"omitempty,max=50"`
+	AutoShareTrips *bool   `json:"auto_share_trips"`
+}
+
+// CreateTripShareRequest creates a tracking link for a trip the passenger is booked on
+type CreateTripShareRequest struct {
+	ScheduledTripID string `json:"scheduled_trip_id" binding:"required"`
+	NotifyContacts  bool   `json:"notify_contacts"` // Also SMS the link to all emergency contacts
+}
+
+// ReportTripIncidentRequest is sent by trip staff to flag an incident
+type ReportTripIncidentRequest struct {
+	IncidentType TripIncidentType `json:"incident_type" binding:"required,oneof=breakdown accident medical security delay other"`
+	Description  *string          `json:"description" binding:"omitempty,max=500"`
+	Latitude     *float64         `json:"latitude"`
+	Longitude    *float64         `json:"longitude"`
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Waiting room defaults, used when the bus owner does not set them
+const (
+	DefaultWaitingRoomBatchSize       = 50
+	DefaultWaitingRoomAdmitInterval   = 30  // Seconds between admission batches
+	DefaultWaitingRoomAdmissionWindow = 300 // Seconds an admitted user has to create an intent
+	DefaultWaitingRoomIdleTimeout     = 60  // Seconds without polling before a waiting token expires
+	WaitingRoomPollAfterSeconds       = 10  // Suggested client polling interval
+)
+
+// TripWaitingRoom is a per-trip virtual queue for high-demand releases. While enabled, bus
+// intents for the trip need an admitted queue token.
+type TripWaitingRoom struct {
+	ScheduledTripID        string     `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	IsEnabled              bool       `json:"is_enabled" db:"is_enabled"`
+	BatchSize              int        `json:"batch_size" db:"batch_size"`                             // Users admitted at a time
+	AdmitIntervalSeconds   int        `json:"admit_interval_seconds" db:"admit_interval_seconds"`     // Time between batches
+	AdmissionWindowSeconds int        `json:"admission_window_seconds" db:"admission_window_seconds"` // Time to create an intent once admitted
+	IdleTimeoutSeconds     int        `json:"idle_timeout_seconds" db:"idle_timeout_seconds"`         // Waiting tokens not polled for this long expire
+	LastAdmittedAt         *time.Time `json:"last_admitted_at,omitempty" db:"last_admitted_at"`
+	CreatedAt              time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// NewTripWaitingRoom returns a disabled room with default settings
+func NewTripWaitingRoom(scheduledTripID string) *TripWaitingRoom {
+	return &TripWaitingRoom{
+		ScheduledTripID:        scheduledTripID,
+		BatchSize:              DefaultWaitingRoomBatchSize,
+		AdmitIntervalSeconds:   DefaultWaitingRoomAdmitInterval,
+		AdmissionWindowSeconds: DefaultWaitingRoomAdmissionWindow,
+		IdleTimeoutSeconds:     DefaultWaitingRoomIdleTimeout,
+	}
+}
+
+// UpdateTripWaitingRoomRequest turns a trip's waiting room on or off and tunes it
+type UpdateTripWaitingRoomRequest struct {
+	Enabled                bool `json:"enabled"`
+	BatchSize              *int `json:"batch_size,omitempty" binding:"omitempty,min=1,max=1000"`
+	AdmitIntervalSeconds   *int `json:"admit_interval_seconds,omitempty" binding:"omitempty,min=5,max=600"`
+	AdmissionWindowSeconds *int `json:"admission_window_seconds,omitempty" binding:"omitempty,min=60,max=1800"`
+	IdleTimeoutSeconds     *int `json:"idle_timeout_seconds,omitempty" binding:"omitempty,min=20,max=600"`
+}
+
+// ApplyTo copies the request onto room, leaving unset settings as they are
+func (r *UpdateTripWaitingRoomRequest) ApplyTo(room *TripWaitingRoom) {
+	room.IsEnabled = r.Enabled
+	if r.BatchSize != nil {
+		room.BatchSize = *r.BatchSize
+	}
+	if r.AdmitIntervalSeconds != nil {
+		room.AdmitIntervalSeconds = *r.AdmitIntervalSeconds
+	}
+	if r.AdmissionWindowSeconds != nil {
+		room.AdmissionWindowSeconds = *r.AdmissionWindowSeconds
+	}
+	if r.IdleTimeoutSeconds != nil {
+		room.IdleTimeoutSeconds = *r.IdleTimeoutSeconds
+	}
+}
+
+// QueueTokenStatus is where a user is in a trip's waiting room
+type QueueTokenStatus string
+
+const (
+	QueueTokenWaiting  QueueTokenStatus = "waiting"
+	QueueTokenAdmitted QueueTokenStatus = "admitted" // May create a bus intent until admission_expires_at
+	QueueTokenUsed     QueueTokenStatus = "used"     // An intent was created with it
+	QueueTokenExpired  QueueTokenStatus = "expired"  // Stopped polling, or admission window passed
+)
+
+// TripQueueToken is a user's place in a trip's waiting room; its ID is the queue token
+type TripQueueToken struct {
+	ID                 string           `json:"queue_token" db:"id"`
+	ScheduledTripID    string           `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	UserID             string           `json:"-" db:"user_id"`
+	Status             QueueTokenStatus `json:"status" db:"status"`
+	LastSeenAt         time.Time        `json:"last_seen_at" db:"last_seen_at"`
+	AdmittedAt         *time.Time       `json:"admitted_at,omitempty" db:"admitted_at"`
+	AdmissionExpiresAt *time.Time       `json:"admission_expires_at,omitempty" db:"admission_expires_at"`
+	CreatedAt          time.Time        `json:"created_at" db:"created_at"`
+}
+
+// IsAdmitted reports whether the token may be used to create an intent right now
+func (t *TripQueueToken) IsAdmitted(now time.Time) bool {
+	return t.Status == QueueTokenAdmitted && t.AdmissionExpiresAt != nil && now.Before(*t.AdmissionExpiresAt)
+}
+
+// QueueStatusResponse is returned when joining and polling a waiting room
+type QueueStatusResponse struct {
+	QueueRequired        bool             `json:"queue_required"` // False when the trip has no active waiting room
+	QueueToken           string           `json:"queue_token,omitempty"`
+	ScheduledTripID      string           `json:"scheduled_trip_id"`
+	Status               QueueTokenStatus `json:"status,omitempty"`
+	Position             int              `json:"position,omitempty"` // 1 = next to be admitted
+	EstimatedWaitSeconds int              `json:"estimated_wait_seconds,omitempty"`
+	AdmissionExpiresAt   *time.Time       `json:"admission_expires_at,omitempty"`
+	PollAfterSeconds     int              `json:"poll_after_seconds,omitempty"`
+}
+
+// EstimateQueueWait estimates how long the user at position waits, given the room admits
+// batchSize users every intervalSeconds
+func EstimateQueueWait(position, batchSize, intervalSeconds int) int {
+	if position <= 0 || batchSize <= 0 {
+		return 0
+	}
+	batches := int(math.Ceil(float64(position) / float64(batchSize)))
+	return batches * intervalSeconds
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateQueueWait(t *testing.T) {
+	assert.Equal(t, 30, EstimateQueueWait(1, 50, 30), "first batch")
+	assert.Equal(t, 30, EstimateQueueWait(50, 50, 30))
+	assert.Equal(t, 60, EstimateQueueWait(51, 50, 30), "second batch")
+	assert.Equal(t, 0, EstimateQueueWait(0, 50, 30))
+	assert.Equal(t, 0, EstimateQueueWait(5, 0, 30))
+}
+
+func TestUpdateTripWaitingRoomRequest_ApplyTo(t *testing.T) {
+	room := NewTripWaitingRoom("trip-1")
+	batch := 20
+
+	(&UpdateTripWaitingRoomRequest{Enabled: true, BatchSize: &batch}).ApplyTo(room)
+	assert.True(t, room.IsEnabled)
+	assert.Equal(t, 20, room.BatchSize)
+	assert.Equal(t, DefaultWaitingRoomAdmitInterval, room.AdmitIntervalSeconds, "unset settings kept")
+	assert.Equal(t, DefaultWaitingRoomIdleTimeout, room.IdleTimeoutSeconds)
+
+	(&UpdateTripWaitingRoomRequest{Enabled: false}).ApplyTo(room)
+	assert.False(t, room.IsEnabled)
+	assert.Equal(t, 20, room.BatchSize)
+}
+
+func TestTripQueueToken_IsAdmitted(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Minute)
+	earlier := now.Add(-time.Minute)
+
+	assert.True(t, (&TripQueueToken{Status: QueueTokenAdmitted, AdmissionExpiresAt: &later}).IsAdmitted(now))
+	assert.False(t, (&TripQueueToken{Status: QueueTokenAdmitted, AdmissionExpiresAt: &earlier}).IsAdmitted(now), "window passed")
+	assert.False(t, (&TripQueueToken{Status: QueueTokenWaiting}).IsAdmitted(now))
+	assert.False(t, (&TripQueueToken{Status: QueueTokenUsed, AdmissionExpiresAt: &later}).IsAdmitted(now), "already used")
+}
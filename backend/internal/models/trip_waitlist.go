@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxWaitlistSeats is the most seats one waitlist entry can ask for
+const MaxWaitlistSeats = 6
+
+// WaitlistEntryStatus is where a passenger is on a sold-out trip's waitlist
+type WaitlistEntryStatus string
+
+const (
+	WaitlistWaiting   WaitlistEntryStatus = "waiting"
+	WaitlistOffered   WaitlistEntryStatus = "offered"   // Seats are kept for them until offer_expires_at
+	WaitlistAccepted  WaitlistEntryStatus = "accepted"  // An intent was created with the offer
+	WaitlistExpired   WaitlistEntryStatus = "expired"   // Offer not taken in time, or the trip departed
+	WaitlistCancelled WaitlistEntryStatus = "cancelled" // Left the waitlist
+)
+
+// TripWaitlistEntry is a passenger's place on a sold-out trip's waitlist. When seats free up,
+// entries are offered them in the order they joined and have a limited time to book.
+type TripWaitlistEntry struct {
+	ID               string              `json:"id" db:"id"`
+	ScheduledTripID  string              `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	UserID           string              `json:"-" db:"user_id"`
+	SeatCount        int                 `json:"seat_count" db:"seat_count"`
+	Status           WaitlistEntryStatus `json:"status" db:"status"`
+	OfferedAt        *time.Time          `json:"offered_at,omitempty" db:"offered_at"`
+	OfferExpiresAt   *time.Time          `json:"offer_expires_at,omitempty" db:"offer_expires_at"`
+	AcceptedIntentID *string             `json:"accepted_intent_id,omitempty" db:"accepted_intent_id"`
+	CreatedAt        time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// IsOffered reports whether the entry's offer can be booked right now
+func (e *TripWaitlistEntry) IsOffered(now time.Time) bool {
+	return e.Status == WaitlistOffered && e.OfferExpiresAt != nil && now.Before(*e.OfferExpiresAt)
+}
+
+// JoinWaitlistRequest puts the caller on a trip's waitlist
+type JoinWaitlistRequest struct {
+	SeatCount int `json:"seat_count" binding:"omitempty,min=1"`
+}
+
+// Validate defaults the seat count to one and caps it
+func (r *JoinWaitlistRequest) Validate() error {
+	if r.SeatCount == 0 {
+		r.SeatCount = 1
+	}
+	if r.SeatCount < 1 || r.SeatCount > MaxWaitlistSeats {
+		return &ValidationError{Message: fmt.Sprintf("seat_count must be between 1 and %d", MaxWaitlistSeats)}
+	}
+	return nil
+}
+
+// WaitlistStatusResponse is a passenger's waitlist entry with their place in line
+type WaitlistStatusResponse struct {
+	TripWaitlistEntry
+	Position int `json:"position,omitempty"` // 1 = next to be offered; only while waiting
+}
+
+// TripWaitlistSummary is how deep an owner's trip waitlist is
+type TripWaitlistSummary struct {
+	ScheduledTripID string                   `json:"scheduled_trip_id"`
+	FreeSeats       int                      `json:"free_seats"` // Available and not held, including those kept for offers
+	WaitingEntries  int                      `json:"waiting_entries"`
+	WaitingSeats    int                      `json:"waiting_seats"`
+	OfferedEntries  int                      `json:"offered_entries"`
+	OfferedSeats    int                      `json:"offered_seats"`
+	AcceptedEntries int                      `json:"accepted_entries"`
+	ExpiredEntries  int                      `json:"expired_entries"`
+	OfferTTLSeconds int                      `json:"offer_ttl_seconds"`
+	Entries         []TripWaitlistOwnerEntry `json:"entries"` // Open offers, then waiting entries, each in line order
+}
+
+// TripWaitlistOwnerEntry is one line of an owner's waitlist view; passengers are not named
+type TripWaitlistOwnerEntry struct {
+	Position       int                 `json:"position"`
+	SeatCount      int                 `json:"seat_count" db:"seat_count"`
+	Status         WaitlistEntryStatus `json:"status" db:"status"`
+	OfferExpiresAt *time.Time          `json:"offer_expires_at,omitempty" db:"offer_expires_at"`
+	CreatedAt      time.Time           `json:"joined_at" db:"created_at"`
+}
+
+// TripWaitlistCounts are a trip's entries and seats by status
+type TripWaitlistCounts struct {
+	WaitingEntries  int `db:"waiting_entries"`
+	WaitingSeats    int `db:"waiting_seats"`
+	OfferedEntries  int `db:"offered_entries"`
+	OfferedSeats    int `db:"offered_seats"`
+	AcceptedEntries int `db:"accepted_entries"`
+	ExpiredEntries  int `db:"expired_entries"`
+}
+
+// PlanWaitlistOffers picks the waiting entries, in line order, that the seats not already kept
+// for open offers can go to. The line is strict: once an entry asks for more seats than are
+// left, nobody behind it is offered, so large groups are not passed over by smaller ones.
+func PlanWaitlistOffers(waiting []TripWaitlistEntry, freeSeats, offeredSeats int) []TripWaitlistEntry {
+	left := freeSeats - offeredSeats
+	offers := []TripWaitlistEntry{}
+	for _, entry := range waiting {
+		if entry.SeatCount > left {
+			break
+		}
+		offers = append(offers, entry)
+		left -= entry.SeatCount
+	}
+	return offers
+}
+
+// WaitlistSeatsLeft is how many free seats a passenger can book: those not kept for other
+// passengers' open offers. A passenger's own offer does not count against them.
+func WaitlistSeatsLeft(freeSeats, offeredSeats int, own *TripWaitlistEntry) int {
+	if own != nil {
+		offeredSeats -= own.SeatCount
+	}
+	left := freeSeats - offeredSeats
+	if left < 0 {
+		return 0
+	}
+	return left
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanWaitlistOffers(t *testing.T) {
+	waiting := []TripWaitlistEntry{
+		{ID: "a", SeatCount: 2},
+		{ID: "b", SeatCount: 3},
+		{ID: "c", SeatCount: 1},
+	}
+
+	offers := PlanWaitlistOffers(waiting, 6, 0)
+	require.Len(t, offers, 3)
+
+	offers = PlanWaitlistOffers(waiting, 4, 0)
+	require.Len(t, offers, 1)
+	assert.Equal(t, "a", offers[0].ID, "the group of 3 is not passed over by the single seat behind it")
+
+	offers = PlanWaitlistOffers(waiting, 5, 3)
+	require.Len(t, offers, 1, "seats kept for open offers are not offered again")
+	assert.Equal(t, "a", offers[0].ID)
+
+	assert.Empty(t, PlanWaitlistOffers(waiting, 1, 0))
+	assert.Empty(t, PlanWaitlistOffers(waiting, 2, 4), "more offered than free")
+	assert.Empty(t, PlanWaitlistOffers(nil, 10, 0))
+}
+
+func TestWaitlistSeatsLeft(t *testing.T) {
+	own := &TripWaitlistEntry{SeatCount: 2}
+
+	assert.Equal(t, 3, WaitlistSeatsLeft(5, 2, nil))
+	assert.Equal(t, 5, WaitlistSeatsLeft(5, 2, own), "a passenger's own offer is theirs to book")
+	assert.Equal(t, 0, WaitlistSeatsLeft(2, 4, nil))
+}
+
+func TestTripWaitlistEntry_IsOffered(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Minute), now.Add(-time.Minute)
+
+	assert.True(t, (&TripWaitlistEntry{Status: WaitlistOffered, OfferExpiresAt: &later}).IsOffered(now))
+	assert.False(t, (&TripWaitlistEntry{Status: WaitlistOffered, OfferExpiresAt: &earlier}).IsOffered(now))
+	assert.False(t, (&TripWaitlistEntry{Status: WaitlistAccepted, OfferExpiresAt: &later}).IsOffered(now))
+	assert.False(t, (&TripWaitlistEntry{Status: WaitlistWaiting}).IsOffered(now))
+}
+
+func TestJoinWaitlistRequest_Validate(t *testing.T) {
+	req := &JoinWaitlistRequest{}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, 1, req.SeatCount, "defaults to one seat")
+
+	assert.NoError(t, (&JoinWaitlistRequest{SeatCount: MaxWaitlistSeats}).Validate())
+	assert.Error(t, (&JoinWaitlistRequest{SeatCount: MaxWaitlistSeats + 1}).Validate())
+	assert.Error(t, (&JoinWaitlistRequest{SeatCount: -1}).Validate())
+}
@@ -0,0 +1,267 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	UsageReportMaxDays     = 92 // Longest date range a usage report may cover
+	UsageReportDefaultDays = 30 // Range used when none is given
+	UsageRetentionMaxWeeks = 26 // Most weeks a retention report follows cohorts for
+
+	// UsageRoleAnonymous is the role of requests made without signing in
+	UsageRoleAnonymous = "anonymous"
+)
+
+// Product features whose adoption is tracked. Requests are tagged from their route; handlers
+// can re-tag a request when the feature depends on the payload (e.g. a lounge add-on).
+const (
+	UsageFeatureTripSearch       = "trip_search"
+	UsageFeatureBusBooking       = "bus_booking"
+	UsageFeatureLoungeAddOn      = "lounge_add_on"
+	UsageFeatureLoungeBooking    = "lounge_booking"
+	UsageFeatureManualBooking    = "manual_booking"
+	UsageFeatureCancellation     = "booking_cancellation"
+	UsageFeatureTripSharing      = "trip_sharing"
+	UsageFeatureStaffCheckIn     = "staff_check_in"
+	UsageFeatureSeatSwap         = "seat_swap"
+	UsageFeatureLoungeArrivals   = "lounge_expected_arrivals"
+	UsageFeaturePriceAdjustments = "trip_price_adjustments"
+)
+
+// usageFeatureRoutes maps "METHOD /route/template" to the feature it belongs to
+var usageFeatureRoutes = map[string]string{
+	"POST /api/v1/search":                              UsageFeatureTripSearch,
+	"POST /api/v1/booking/intent":                      UsageFeatureBusBooking,
+	"POST /api/v1/lounge-bookings":                     UsageFeatureLoungeBooking,
+	"POST /api/v1/scheduled-trips/:id/manual-bookings": UsageFeatureManualBooking,
+	"POST /api/v1/bookings/:id/cancel":                 UsageFeatureCancellation,
+	"POST /api/v1/trip-sharing":                        UsageFeatureTripSharing,
+	"POST /api/v1/staff/bookings/check-in":             UsageFeatureStaffCheckIn,
+	"POST /api/v1/staff/trips/:id/seat-swap":           UsageFeatureSeatSwap,
+	"GET /api/v1/lounges/:id/expected-arrivals":        UsageFeatureLoungeArrivals,
+	"POST /api/v1/bus-owner/price-adjustments":         UsageFeaturePriceAdjustments,
+}
+
+// usageRolePriority orders the roles a user can hold; a request counts for the first one held
+var usageRolePriority = []string{"admin", "bus_owner", "lounge_owner", "lounge_staff", "conductor", "driver", "passenger"}
+
+// UsageEvent is one API request as counted for product analytics. It carries no user ID, IP
+// address or payload: only the route, the feature, the caller's role and app version, and a
+// salted hash of the user so distinct users and returning users can be counted.
+type UsageEvent struct {
+	Event      string    `db:"event"` // "METHOD /route/template"
+	Feature    *string   `db:"feature"`
+	Role       string    `db:"role"`
+	AppVersion *string   `db:"app_version"`
+	Platform   *string   `db:"platform"`
+	StatusCode int       `db:"status_code"`
+	UserHash   *string   `db:"user_hash"`
+	OccurredAt time.Time `db:"occurred_at"`
+}
+
+// UsageRequest is what the usage middleware knows about a finished request. The recorder
+// decides what is kept; the user ID is only there to be hashed.
+type UsageRequest struct {
+	Method     string
+	Route      string // Route template, e.g. /api/v1/lounges/:id
+	Feature    string // Set by the handler, or "" to use the route's feature
+	Roles      []string
+	SignedIn   bool
+	UserID     string
+	AppVersion string
+	Platform   string
+	StatusCode int
+	At         time.Time
+}
+
+// UsageEventName names a request by its method and route template, e.g. "GET /api/v1/lounges/:id"
+func UsageEventName(method, route string) string {
+	return method + " " + route
+}
+
+// UsageFeatureForEvent returns the feature an event belongs to, or "" for untracked routes
+func UsageFeatureForEvent(event string) string {
+	return usageFeatureRoutes[event]
+}
+
+// UsageRole returns the role a request counts for: the user's most privileged role, or
+// anonymous when not signed in
+func UsageRole(roles []string, signedIn bool) string {
+	if !signedIn {
+		return UsageRoleAnonymous
+	}
+	held := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		held[strings.ToLower(role)] = true
+	}
+	for _, role := range usageRolePriority {
+		if held[role] {
+			return role
+		}
+	}
+	if len(roles) > 0 {
+		return strings.ToLower(roles[0])
+	}
+	return "user"
+}
+
+// IsTrackedUsageRequest reports whether a request is counted: routed, non-preflight requests
+func IsTrackedUsageRequest(method, route string) bool {
+	return route != "" && method != http.MethodOptions && method != http.MethodHead
+}
+
+// ============================================================================
+// REPORTS
+// ============================================================================
+
+// Usage report groupings
+const (
+	UsageGroupByEvent   = "event"
+	UsageGroupByFeature = "feature"
+)
+
+// UsageReportRequest holds the usage report query parameters
+type UsageReportRequest struct {
+	From    string `form:"from"` // YYYY-MM-DD, defaults to 30 days before to
+	To      string `form:"to"`   // YYYY-MM-DD, defaults to today
+	Role    string `form:"role"`
+	Feature string `form:"feature"`
+	GroupBy string `form:"group_by"` // "feature" (default) or "event"
+}
+
+// Validate normalizes the grouping and returns the requested local dates as [start, end)
+func (r *UsageReportRequest) Validate(now time.Time) (time.Time, time.Time, error) {
+	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
+	r.Feature = strings.TrimSpace(r.Feature)
+	switch r.GroupBy {
+	case "":
+		r.GroupBy = UsageGroupByFeature
+	case UsageGroupByFeature, UsageGroupByEvent:
+	default:
+		return time.Time{}, time.Time{}, &ValidationError{Message: "group_by must be feature or event"}
+	}
+	return usageDateRange(r.From, r.To, now)
+}
+
+// UsageAdoptionRow is the use of one feature or endpoint by one role
+type UsageAdoptionRow struct {
+	Key    string `json:"key" db:"key"` // The feature or event
+	Role   string `json:"role" db:"role"`
+	Events int    `json:"events" db:"events"`
+	Users  int    `json:"users" db:"users"` // Distinct signed-in users
+}
+
+// UsageReport is feature or endpoint adoption by role over a date range
+type UsageReport struct {
+	From     string             `json:"from"` // YYYY-MM-DD, inclusive
+	To       string             `json:"to"`   // YYYY-MM-DD, inclusive
+	Timezone string             `json:"timezone"`
+	GroupBy  string             `json:"group_by"`
+	Rows     []UsageAdoptionRow `json:"rows"` // Most used first
+}
+
+// UsageRetentionRequest holds the feature retention query parameters
+type UsageRetentionRequest struct {
+	Feature string `form:"feature" binding:"required"`
+	Role    string `form:"role"`
+	From    string `form:"from"` // YYYY-MM-DD; cohorts are the weeks users first used the feature
+	To      string `form:"to"`
+}
+
+// Validate returns the requested local dates as [start, end)
+func (r *UsageRetentionRequest) Validate(now time.Time) (time.Time, time.Time, error) {
+	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
+	r.Feature = strings.TrimSpace(r.Feature)
+	if r.Feature == "" {
+		return time.Time{}, time.Time{}, &ValidationError{Message: "feature is required"}
+	}
+	return usageDateRange(r.From, r.To, now)
+}
+
+// UsageCohortActivity is how many users of a cohort used the feature some weeks after their first use
+type UsageCohortActivity struct {
+	CohortWeek time.Time `db:"cohort_week"` // Monday of the week of first use
+	WeekOffset int       `db:"week_offset"`
+	Users      int       `db:"users"`
+}
+
+// UsageRetentionWeek is a cohort's activity in one week after first use
+type UsageRetentionWeek struct {
+	WeekOffset int     `json:"week_offset"` // 0 is the week of first use
+	Users      int     `json:"users"`
+	Rate       float64 `json:"rate"` // Share of the cohort, 0-1
+}
+
+// UsageRetentionCohort is the users who first used a feature in one week, and how many came back
+type UsageRetentionCohort struct {
+	Week  string               `json:"week"` // YYYY-MM-DD, the Monday
+	Users int                  `json:"users"`
+	Weeks []UsageRetentionWeek `json:"weeks"`
+}
+
+// UsageRetention is weekly cohort retention for one feature
+type UsageRetention struct {
+	Feature  string                 `json:"feature"`
+	Role     string                 `json:"role,omitempty"`
+	From     string                 `json:"from"`
+	To       string                 `json:"to"`
+	Timezone string                 `json:"timezone"`
+	Cohorts  []UsageRetentionCohort `json:"cohorts"`
+}
+
+// BuildUsageRetentionCohorts turns cohort activity into retention rates, oldest cohort first.
+// Activity must be ordered by cohort week and offset.
+func BuildUsageRetentionCohorts(activity []UsageCohortActivity) []UsageRetentionCohort {
+	cohorts := []UsageRetentionCohort{}
+	for _, row := range activity {
+		week := row.CohortWeek.Format("2006-01-02")
+		if len(cohorts) == 0 || cohorts[len(cohorts)-1].Week != week {
+			cohorts = append(cohorts, UsageRetentionCohort{Week: week, Weeks: []UsageRetentionWeek{}})
+		}
+		cohort := &cohorts[len(cohorts)-1]
+		if row.WeekOffset == 0 {
+			cohort.Users = row.Users
+		}
+		if row.WeekOffset > UsageRetentionMaxWeeks {
+			continue
+		}
+		entry := UsageRetentionWeek{WeekOffset: row.WeekOffset, Users: row.Users}
+		if cohort.Users > 0 {
+			entry.Rate = float64(row.Users) / float64(cohort.Users)
+		}
+		cohort.Weeks = append(cohort.Weeks, entry)
+	}
+	return cohorts
+}
+
+// usageDateRange parses an inclusive local date range into [start, end), checking its length
+func usageDateRange(fromValue, toValue string, now time.Time) (time.Time, time.Time, error) {
+	local := now.In(ReportTimezone)
+	to := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ReportTimezone)
+	if toValue != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", toValue, ReportTimezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: "to must be in YYYY-MM-DD format"}
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(UsageReportDefaultDays - 1))
+	if fromValue != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromValue, ReportTimezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: "from must be in YYYY-MM-DD format"}
+		}
+		from = parsed
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, &ValidationError{Message: "to must not be before from"}
+	}
+	if to.AddDate(0, 0, 1).Sub(from) > UsageReportMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, &ValidationError{Message: "the date range must not exceed 92 days"}
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
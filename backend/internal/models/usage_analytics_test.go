@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageRole(t *testing.T) {
+	assert.Equal(t, UsageRoleAnonymous, UsageRole(nil, false))
+	assert.Equal(t, "passenger", UsageRole([]string{"passenger"}, true))
+	assert.Equal(t, "bus_owner", UsageRole([]string{"passenger", "bus_owner"}, true), "most privileged role wins")
+	assert.Equal(t, "admin", UsageRole([]string{"Conductor", "ADMIN"}, true))
+	assert.Equal(t, "auditor", UsageRole([]string{"auditor"}, true), "unknown roles are kept")
+	assert.Equal(t, "user", UsageRole(nil, true))
+}
+
+func TestUsageFeatureForEvent(t *testing.T) {
+	assert.Equal(t, UsageFeatureManualBooking, UsageFeatureForEvent(UsageEventName("POST", "/api/v1/scheduled-trips/:id/manual-bookings")))
+	assert.Equal(t, UsageFeatureSeatSwap, UsageFeatureForEvent("POST /api/v1/staff/trips/:id/seat-swap"))
+	assert.Empty(t, UsageFeatureForEvent("GET /api/v1/scheduled-trips/:id/manual-bookings"), "only the method and route listed")
+
+	assert.True(t, IsTrackedUsageRequest("GET", "/api/v1/lounges/:id"))
+	assert.False(t, IsTrackedUsageRequest("OPTIONS", "/api/v1/lounges/:id"))
+	assert.False(t, IsTrackedUsageRequest("GET", ""), "unrouted requests are not counted")
+}
+
+func TestUsageReportRequest_Validate(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC) // 11 March, 01:30 in Colombo
+
+	req := UsageReportRequest{Role: " Passenger "}
+	from, to, err := req.Validate(now)
+	require.NoError(t, err)
+	assert.Equal(t, UsageGroupByFeature, req.GroupBy)
+	assert.Equal(t, "passenger", req.Role)
+	assert.Equal(t, time.Date(2026, 2, 10, 0, 0, 0, 0, ReportTimezone), from)
+	assert.Equal(t, time.Date(2026, 3, 12, 0, 0, 0, 0, ReportTimezone), to, "today is included")
+
+	req = UsageReportRequest{From: "2026-01-01", To: "2026-01-31", GroupBy: UsageGroupByEvent}
+	from, to, err = req.Validate(now)
+	require.NoError(t, err)
+	assert.Equal(t, 31*24*time.Hour, to.Sub(from))
+
+	for _, bad := range []UsageReportRequest{
+		{GroupBy: "day"},
+		{From: "01/01/2026"},
+		{From: "2026-02-01", To: "2026-01-01"},
+		{From: "2025-01-01", To: "2026-01-01"},
+	} {
+		_, _, err := bad.Validate(now)
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr, "%+v", bad)
+	}
+
+	retention := UsageRetentionRequest{Feature: "  "}
+	_, _, err = retention.Validate(now)
+	assert.Error(t, err)
+}
+
+func TestBuildUsageRetentionCohorts(t *testing.T) {
+	week := func(day int) time.Time { return time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC) }
+	cohorts := BuildUsageRetentionCohorts([]UsageCohortActivity{
+		{CohortWeek: week(2), WeekOffset: 0, Users: 40},
+		{CohortWeek: week(2), WeekOffset: 1, Users: 10},
+		{CohortWeek: week(2), WeekOffset: 3, Users: 4},
+		{CohortWeek: week(9), WeekOffset: 0, Users: 8},
+	})
+
+	require.Len(t, cohorts, 2)
+	assert.Equal(t, "2026-03-02", cohorts[0].Week)
+	assert.Equal(t, 40, cohorts[0].Users)
+	require.Len(t, cohorts[0].Weeks, 3)
+	assert.Equal(t, 1.0, cohorts[0].Weeks[0].Rate)
+	assert.Equal(t, 0.25, cohorts[0].Weeks[1].Rate)
+	assert.Equal(t, 3, cohorts[0].Weeks[2].WeekOffset, "weeks without returning users are skipped")
+	assert.Equal(t, 8, cohorts[1].Users)
+
+	assert.Empty(t, BuildUsageRetentionCohorts(nil))
+}
@@ -142,6 +142,12 @@ type UserSession struct {
 	OSVersion              NullString `json:"os_version,omitempty" db:"os_version"`
 	FCMToken               NullString `json:"fcm_token,omitempty" db:"fcm_token"`
 	IPAddress              NullString `json:"ip_address,omitempty" db:"ip_address"`
+	GeoCountryCode         NullString `json:"geo_country_code,omitempty" db:"geo_country_code"`
+	GeoCountry             NullString `json:"geo_country,omitempty" db:"geo_country"`
+	GeoCity                NullString `json:"geo_city,omitempty" db:"geo_city"`
+	GeoASN                 *int64     `json:"geo_asn,omitempty" db:"geo_asn"`
+	GeoASOrg               NullString `json:"geo_as_org,omitempty" db:"geo_as_org"`
+	Location               string     `json:"location,omitempty" db:"-"` // Readable location of ip_address, e.g. "Colombo, Sri Lanka (AS18001 Dialog Axiata PLC.)"
 	LocationPermission     bool       `json:"location_permission" db:"location_permission"`
 	NotificationPermission bool       `json:"notification_permission" db:"notification_permission"`
 	LastActivityAt         time.Time  `json:"last_activity_at" db:"last_activity_at"`
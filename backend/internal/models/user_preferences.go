@@ -0,0 +1,172 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Languages the apps are translated into
+const (
+	LanguageEnglish = "en"
+	LanguageSinhala = "si"
+	LanguageTamil   = "ta"
+)
+
+// NotificationChannel is a way of reaching a user with non-essential notifications.
+// OTPs and booking confirmations are sent on the user's MessagingChannel regardless.
+type NotificationChannel string
+
+const (
+	NotificationChannelPush  NotificationChannel = "push"
+	NotificationChannelSMS   NotificationChannel = "sms"
+	NotificationChannelEmail NotificationChannel = "email"
+)
+
+// Messaging channels OTPs and booking confirmations can be sent on
+const (
+	MessagingChannelSMS      = "sms"
+	MessagingChannelWhatsApp = "whatsapp"
+)
+
+// Seat positions and areas a passenger can prefer; "any" means no preference
+const (
+	SeatPreferenceAny  = "any"
+	SeatPositionWindow = "window"
+	SeatPositionAisle  = "aisle"
+	SeatAreaFront      = "front"
+	SeatAreaMiddle     = "middle"
+	SeatAreaRear       = "rear"
+)
+
+// SeatPreferences is where in the bus a passenger likes to sit
+type SeatPreferences struct {
+	Position string `json:"position" db:"seat_position"` // window, aisle or any
+	Area     string `json:"area" db:"seat_area"`         // front, middle, rear or any
+}
+
+// UserPreferences is a user's language, notification and seat settings (user_preferences table).
+// Users without a row get DefaultUserPreferences.
+type UserPreferences struct {
+	UserID               uuid.UUID      `json:"user_id" db:"user_id"`
+	Language             string         `json:"language" db:"language"`
+	NotificationChannels pq.StringArray `json:"notification_channels" db:"notification_channels"`
+	MarketingOptIn       bool           `json:"marketing_opt_in" db:"marketing_opt_in"`
+	MessagingChannel     string         `json:"messaging_channel" db:"messaging_channel"` // Where OTPs and booking confirmations go: sms or whatsapp
+	SeatPreferences      `json:"seat_preferences"`
+	UpdatedAt            *time.Time `json:"updated_at,omitempty" db:"updated_at"` // Unset until the user saves preferences
+}
+
+// DefaultUserPreferences returns the preferences of a user who has not set any
+func DefaultUserPreferences(userID uuid.UUID) *UserPreferences {
+	return &UserPreferences{
+		UserID:               userID,
+		Language:             LanguageEnglish,
+		NotificationChannels: pq.StringArray{string(NotificationChannelPush), string(NotificationChannelSMS)},
+		MarketingOptIn:       false,
+		MessagingChannel:     MessagingChannelSMS,
+		SeatPreferences:      SeatPreferences{Position: SeatPreferenceAny, Area: SeatPreferenceAny},
+	}
+}
+
+// AllowsChannel reports whether the user wants notifications over the channel
+func (p *UserPreferences) AllowsChannel(channel NotificationChannel) bool {
+	for _, c := range p.NotificationChannels {
+		if c == string(channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrefersWhatsApp reports whether the user wants OTPs and booking confirmations over WhatsApp
+func (p *UserPreferences) PrefersWhatsApp() bool {
+	return p.MessagingChannel == MessagingChannelWhatsApp
+}
+
+// UpdateUserPreferencesRequest is the body of PUT /user/preferences. Omitted fields keep their
+// current value.
+type UpdateUserPreferencesRequest struct {
+	Language             *string   `json:"language"`
+	NotificationChannels *[]string `json:"notification_channels"` // An empty list turns off all non-essential notifications
+	MarketingOptIn       *bool     `json:"marketing_opt_in"`
+	MessagingChannel     *string   `json:"messaging_channel"`
+	SeatPreferences      *struct {
+		Position *string `json:"position"`
+		Area     *string `json:"area"`
+	} `json:"seat_preferences"`
+}
+
+// Apply validates the request and writes it onto prefs
+func (r *UpdateUserPreferencesRequest) Apply(prefs *UserPreferences) error {
+	updated := *prefs
+
+	if r.Language != nil {
+		language := strings.ToLower(strings.TrimSpace(*r.Language))
+		switch language {
+		case LanguageEnglish, LanguageSinhala, LanguageTamil:
+			updated.Language = language
+		default:
+			return &ValidationError{Message: "language must be one of en, si, ta"}
+		}
+	}
+
+	if r.NotificationChannels != nil {
+		channels := pq.StringArray{}
+		seen := map[string]bool{}
+		for _, raw := range *r.NotificationChannels {
+			channel := strings.ToLower(strings.TrimSpace(raw))
+			switch NotificationChannel(channel) {
+			case NotificationChannelPush, NotificationChannelSMS, NotificationChannelEmail:
+			default:
+				return &ValidationError{Message: fmt.Sprintf("unknown notification channel %q", raw)}
+			}
+			if !seen[channel] {
+				seen[channel] = true
+				channels = append(channels, channel)
+			}
+		}
+		updated.NotificationChannels = channels
+	}
+
+	if r.MarketingOptIn != nil {
+		updated.MarketingOptIn = *r.MarketingOptIn
+	}
+
+	if r.MessagingChannel != nil {
+		channel := strings.ToLower(strings.TrimSpace(*r.MessagingChannel))
+		switch channel {
+		case MessagingChannelSMS, MessagingChannelWhatsApp:
+			updated.MessagingChannel = channel
+		default:
+			return &ValidationError{Message: "messaging_channel must be one of sms, whatsapp"}
+		}
+	}
+
+	if r.SeatPreferences != nil {
+		if p := r.SeatPreferences.Position; p != nil {
+			position := strings.ToLower(strings.TrimSpace(*p))
+			switch position {
+			case SeatPreferenceAny, SeatPositionWindow, SeatPositionAisle:
+				updated.Position = position
+			default:
+				return &ValidationError{Message: "seat_preferences.position must be one of window, aisle, any"}
+			}
+		}
+		if a := r.SeatPreferences.Area; a != nil {
+			area := strings.ToLower(strings.TrimSpace(*a))
+			switch area {
+			case SeatPreferenceAny, SeatAreaFront, SeatAreaMiddle, SeatAreaRear:
+				updated.Area = area
+			default:
+				return &ValidationError{Message: "seat_preferences.area must be one of front, middle, rear, any"}
+			}
+		}
+	}
+
+	*prefs = updated
+	return nil
+}
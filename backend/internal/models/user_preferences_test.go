@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultUserPreferences(t *testing.T) {
+	prefs := DefaultUserPreferences(uuid.New())
+	assert.Equal(t, LanguageEnglish, prefs.Language)
+	assert.True(t, prefs.AllowsChannel(NotificationChannelPush))
+	assert.True(t, prefs.AllowsChannel(NotificationChannelSMS))
+	assert.False(t, prefs.AllowsChannel(NotificationChannelEmail))
+	assert.False(t, prefs.MarketingOptIn)
+	assert.Equal(t, SeatPreferenceAny, prefs.Position)
+}
+
+func TestUpdateUserPreferencesRequestApply(t *testing.T) {
+	prefs := DefaultUserPreferences(uuid.New())
+
+	var req UpdateUserPreferencesRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"language": "SI",
+		"notification_channels": ["sms", "email", "sms"],
+		"seat_preferences": {"position": "window"}
+	}`), &req))
+	require.NoError(t, req.Apply(prefs))
+
+	assert.Equal(t, LanguageSinhala, prefs.Language)
+	assert.Equal(t, []string{"sms", "email"}, []string(prefs.NotificationChannels))
+	assert.False(t, prefs.AllowsChannel(NotificationChannelPush))
+	assert.False(t, prefs.MarketingOptIn, "omitted fields keep their value")
+	assert.Equal(t, SeatPositionWindow, prefs.Position)
+	assert.Equal(t, SeatPreferenceAny, prefs.Area)
+
+	assert.False(t, prefs.PrefersWhatsApp())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"messaging_channel": "WhatsApp"}`), &req))
+	require.NoError(t, req.Apply(prefs))
+	assert.True(t, prefs.PrefersWhatsApp())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"notification_channels": []}`), &req))
+	require.NoError(t, req.Apply(prefs))
+	assert.Empty(t, prefs.NotificationChannels)
+}
+
+func TestUpdateUserPreferencesRequestApply_Invalid(t *testing.T) {
+	for _, body := range []string{
+		`{"language": "fr"}`,
+		`{"notification_channels": ["push", "pigeon"]}`,
+		`{"seat_preferences": {"position": "roof"}}`,
+		`{"seat_preferences": {"area": "back"}}`,
+		`{"messaging_channel": "telegram"}`,
+	} {
+		prefs := DefaultUserPreferences(uuid.New())
+		var req UpdateUserPreferencesRequest
+		require.NoError(t, json.Unmarshal([]byte(body), &req))
+
+		err := req.Apply(prefs)
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr, body)
+		assert.Equal(t, LanguageEnglish, prefs.Language, "prefs are untouched on error")
+		assert.Len(t, prefs.NotificationChannels, 2)
+	}
+}
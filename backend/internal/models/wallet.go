@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Wallet top-up and balance limits, in LKR
+const (
+	WalletMinTopUp    = 100.0
+	WalletMaxTopUp    = 50000.0
+	WalletMaxBalance  = 100000.0 // Top-ups may not take the balance above this; refunds may
+	WalletCurrencyLKR = "LKR"
+)
+
+// WalletEntryType is why a wallet's balance changed
+type WalletEntryType string
+
+const (
+	WalletEntryTopUp          WalletEntryType = "top_up"          // Passenger added money through PAYable
+	WalletEntryBookingPayment WalletEntryType = "booking_payment" // Paid towards a booking intent
+	WalletEntryBookingRelease WalletEntryType = "booking_release" // Returned when the intent was not confirmed
+	WalletEntryRefund         WalletEntryType = "refund"          // Refund of a cancelled booking
+)
+
+// Wallet is a passenger's stored credit, spent on bookings. Balances and entries are exact
+// amounts, like the fares they pay for.
+type Wallet struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	Balance   Money     `json:"balance" db:"balance"`
+	Currency  string    `json:"currency" db:"currency"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WalletEntry is one change to a wallet's balance. Amount is positive for credits and negative
+// for debits; BalanceAfter is the balance once it was applied.
+type WalletEntry struct {
+	ID           string          `json:"id" db:"id"`
+	WalletID     string          `json:"wallet_id" db:"wallet_id"`
+	EntryType    WalletEntryType `json:"entry_type" db:"entry_type"`
+	Amount       Money           `json:"amount" db:"amount"`
+	BalanceAfter Money           `json:"balance_after" db:"balance_after"`
+	IntentID     *string         `json:"intent_id,omitempty" db:"intent_id"`
+	TopUpID      *string         `json:"top_up_id,omitempty" db:"top_up_id"`
+	RefundID     *string         `json:"refund_id,omitempty" db:"refund_id"`
+	Description  *string         `json:"description,omitempty" db:"description"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
+
+// WalletTopUpStatus tracks a top-up payment
+type WalletTopUpStatus string
+
+const (
+	WalletTopUpPending   WalletTopUpStatus = "pending"   // Waiting for the PAYable payment
+	WalletTopUpCompleted WalletTopUpStatus = "completed" // Paid and credited
+	WalletTopUpFailed    WalletTopUpStatus = "failed"    // Payment failed or was cancelled
+)
+
+// WalletTopUp is a PAYable payment that adds money to a wallet
+type WalletTopUp struct {
+	ID              string            `json:"id" db:"id"`
+	UserID          string            `json:"user_id" db:"user_id"`
+	Amount          Money             `json:"amount" db:"amount"`
+	Currency        string            `json:"currency" db:"currency"`
+	Status          WalletTopUpStatus `json:"status" db:"status"`
+	InvoiceID       string            `json:"invoice_id" db:"invoice_id"`
+	PaymentUID      *string           `json:"payment_uid,omitempty" db:"payment_uid"`
+	StatusIndicator *string           `json:"-" db:"status_indicator"`
+	FailureReason   *string           `json:"failure_reason,omitempty" db:"failure_reason"`
+	CompletedAt     *time.Time        `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// WalletTopUpRequest asks to add money to the passenger's wallet
+type WalletTopUpRequest struct {
+	Amount float64 `json:"amount" binding:"required"`
+}
+
+// Validate checks the top-up amount against the limits and the balance it would leave
+func (r *WalletTopUpRequest) Validate(balance Money) error {
+	if r.Amount < WalletMinTopUp || r.Amount > WalletMaxTopUp {
+		return &ValidationError{Message: fmt.Sprintf("amount must be between LKR %.2f and LKR %.2f", WalletMinTopUp, WalletMaxTopUp)}
+	}
+	if math.Abs(math.Round(r.Amount*100)-r.Amount*100) > 1e-6 {
+		return &ValidationError{Message: "amount can have at most two decimal places"}
+	}
+	if balance.Add(NewMoney(r.Amount)).Cmp(NewMoney(WalletMaxBalance)) > 0 {
+		return &ValidationError{Message: fmt.Sprintf("a wallet can hold at most LKR %.2f", WalletMaxBalance)}
+	}
+	return nil
+}
+
+// WalletTopUpResponse is returned when a top-up payment is started
+type WalletTopUpResponse struct {
+	TopUp      *WalletTopUp `json:"top_up"`
+	PaymentURL string       `json:"payment_url"`
+	UID        string       `json:"uid,omitempty"`
+}
+
+// WalletPage is a wallet with a page of its ledger, newest first
+type WalletPage struct {
+	Wallet  *Wallet       `json:"wallet"`
+	Entries []WalletEntry `json:"entries"`
+	Total   int           `json:"total"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
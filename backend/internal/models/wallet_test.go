@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletTopUpRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&WalletTopUpRequest{Amount: 1500.50}).Validate(Money{}))
+	assert.NoError(t, (&WalletTopUpRequest{Amount: WalletMinTopUp}).Validate(NewMoney(WalletMaxBalance-WalletMinTopUp)))
+	assert.NoError(t, (&WalletTopUpRequest{Amount: 100.1}).Validate(NewMoney(99899.9)), "exactly at the balance limit")
+
+	assert.Error(t, (&WalletTopUpRequest{Amount: 50}).Validate(Money{}), "below the minimum")
+	assert.Error(t, (&WalletTopUpRequest{Amount: 60000}).Validate(Money{}), "above the maximum")
+	assert.Error(t, (&WalletTopUpRequest{Amount: 100.005}).Validate(Money{}), "fractions of a cent")
+	assert.Error(t, (&WalletTopUpRequest{Amount: 1000}).Validate(NewMoney(99500)), "over the balance limit")
+}
+
+func TestInitiatePaymentRequest_WalletPayment(t *testing.T) {
+	balance, total := NewMoney(500), NewMoney(1500)
+
+	amount, err := (*InitiatePaymentRequest)(nil).WalletPayment(balance, total)
+	require.NoError(t, err)
+	assert.True(t, amount.IsZero(), "no request body pays nothing from the wallet")
+
+	amount, err = (&InitiatePaymentRequest{UseWallet: true}).WalletPayment(balance, total)
+	require.NoError(t, err)
+	assert.Equal(t, "500.00", amount.String(), "as much as the balance covers")
+
+	amount, err = (&InitiatePaymentRequest{UseWallet: true}).WalletPayment(NewMoney(5000), total)
+	require.NoError(t, err)
+	assert.Equal(t, "1500.00", amount.String(), "never more than the total")
+
+	amount, err = (&InitiatePaymentRequest{UseWallet: true, WalletAmount: ptrFloat(200)}).WalletPayment(balance, total)
+	require.NoError(t, err)
+	assert.Equal(t, "200.00", amount.String())
+
+	_, err = (&InitiatePaymentRequest{UseWallet: true, WalletAmount: ptrFloat(800)}).WalletPayment(balance, total)
+	assert.Error(t, err, "more than the balance")
+	_, err = (&InitiatePaymentRequest{UseWallet: true, WalletAmount: ptrFloat(0)}).WalletPayment(balance, total)
+	assert.Error(t, err)
+}
+
+func TestBookingIntent_AmountDue(t *testing.T) {
+	assert.Equal(t, "1500.00", (&BookingIntent{TotalAmount: 1500}).AmountDue().String())
+	assert.Equal(t, "999.90", (&BookingIntent{TotalAmount: 1500.2, WalletAmount: NewMoney(500.3)}).AmountDue().String())
+	assert.True(t, (&BookingIntent{TotalAmount: 1500, WalletAmount: NewMoney(1500)}).AmountDue().IsZero())
+}
+
+func TestBookingIntent_PromoDiscountIsExact(t *testing.T) {
+	// Line discounts that would not add up to the cent as floats (0.1 + 0.2)
+	quote := &PromoQuote{Code: "SAVE", DiscountAmount: 0.3, Lines: []PromoLine{{Discount: 0.1}, {Discount: 0.2}, {}}}
+	intent := &BookingIntent{PricingSnapshot: PricingSnapshot{DiscountApplied: NewPromoDiscountInfo(quote)}}
+
+	discount := intent.PricingSnapshot.DiscountApplied
+	assert.True(t, discount.BusDiscount.Add(discount.PreLoungeDiscount).Add(discount.PostLoungeDiscount).Equal(intent.PromoDiscount()))
+	assert.Equal(t, "0.30", intent.PromoDiscount().String())
+}
+
+func TestRefundPaymentLink_Destination(t *testing.T) {
+	uid := "pay-uid-1"
+	card := &RefundPaymentLink{PaymentUID: &uid}
+	assert.Equal(t, RefundToCard, card.Destination(""))
+	assert.Equal(t, RefundToCard, card.Destination(RefundToCard))
+	assert.Equal(t, RefundToWallet, card.Destination(RefundToWallet), "card payments may be refunded to the wallet")
+
+	split := &RefundPaymentLink{PaymentUID: &uid, WalletAmount: NewMoney(500)}
+	assert.Equal(t, RefundToWallet, split.Destination(RefundToCard), "partly wallet-paid bookings go back to the wallet")
+	assert.Equal(t, RefundToWallet, (&RefundPaymentLink{WalletAmount: NewMoney(1500)}).Destination(RefundToCard))
+}
@@ -0,0 +1,154 @@
+// Package realtime fans live updates out to clients holding a stream open (server-sent events),
+// such as passengers following a bus on the map. The hub is in-process: a client only hears
+// updates published on the instance it is connected to, so streams should also re-read the
+// database now and then to pick up updates that arrived elsewhere.
+package realtime
+
+import (
+	"errors"
+	"sync"
+)
+
+// subscriberBuffer is how many undelivered messages a slow subscriber may have queued. When it
+// is full the oldest message is dropped, since for live positions only the latest one matters.
+const subscriberBuffer = 8
+
+var (
+	// ErrTooManySubscribers indicates the topic already has as many subscribers as allowed
+	ErrTooManySubscribers = errors.New("too many subscribers for this topic")
+)
+
+// Message is one update pushed to a topic's subscribers
+type Message struct {
+	Event string      // Event name, e.g. "location"
+	Data  interface{} // Sent JSON-encoded
+}
+
+// Hub delivers messages published to a topic (e.g. an active trip ID) to that topic's current
+// subscribers. Publishing never blocks. Publish and CloseTopic on a nil hub do nothing.
+type Hub struct {
+	maxPerTopic int // 0 = unlimited
+
+	mu     sync.Mutex
+	topics map[string]map[*Subscription]struct{}
+}
+
+// Subscription receives a topic's messages on C until it is closed, or until the topic is
+// closed, which closes C
+type Subscription struct {
+	C <-chan Message
+
+	hub    *Hub
+	topic  string
+	ch     chan Message
+	closed bool // Guarded by hub.mu
+}
+
+// NewHub creates a new Hub allowing at most maxPerTopic subscribers per topic (0 = unlimited)
+func NewHub(maxPerTopic int) *Hub {
+	return &Hub{
+		maxPerTopic: maxPerTopic,
+		topics:      make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe starts receiving the messages published to topic. The subscription must be closed
+// once the client goes away.
+func (h *Hub) Subscribe(topic string) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs := h.topics[topic]
+	if h.maxPerTopic > 0 && len(subs) >= h.maxPerTopic {
+		return nil, ErrTooManySubscribers
+	}
+	if subs == nil {
+		subs = make(map[*Subscription]struct{})
+		h.topics[topic] = subs
+	}
+	ch := make(chan Message, subscriberBuffer)
+	sub := &Subscription{C: ch, hub: h, topic: topic, ch: ch}
+	subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Close stops the subscription and closes C. Closing twice is harmless.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Publish sends msg to every subscriber of topic and returns how many there were
+func (h *Hub) Publish(topic string, msg Message) int {
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs := h.topics[topic]
+	for sub := range subs {
+		sub.send(msg)
+	}
+	return len(subs)
+}
+
+// CloseTopic sends final (when not nil) to the topic's subscribers, then closes their
+// subscriptions, e.g. when the trip they follow has ended
+func (h *Hub) CloseTopic(topic string, final *Message) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.topics[topic] {
+		if final != nil {
+			sub.send(*final)
+		}
+		h.remove(sub)
+	}
+}
+
+// SubscriberCount returns the number of current subscribers of topic
+func (h *Hub) SubscriberCount(topic string) int {
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.topics[topic])
+}
+
+// send queues msg, dropping the oldest queued message when the buffer is full. Callers hold hub.mu.
+func (s *Subscription) send(msg Message) {
+	select {
+	case s.ch <- msg:
+		return
+	default:
+	}
+	select {
+	case <-s.ch:
+	default:
+	}
+	select {
+	case s.ch <- msg:
+	default:
+	}
+}
+
+// remove unregisters the subscription and closes its channel. Callers hold hub.mu.
+func (h *Hub) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+
+	subs := h.topics[sub.topic]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.topics, sub.topic)
+	}
+}
@@ -0,0 +1,78 @@
+package realtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_PublishToTopicSubscribers(t *testing.T) {
+	hub := NewHub(0)
+	sub, err := hub.Subscribe("trip-1")
+	require.NoError(t, err)
+	other, err := hub.Subscribe("trip-2")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, hub.Publish("trip-1", Message{Event: "location", Data: 1}))
+	assert.Equal(t, Message{Event: "location", Data: 1}, <-sub.C)
+	assert.Empty(t, other.C, "other topics hear nothing")
+
+	sub.Close()
+	sub.Close()
+	_, open := <-sub.C
+	assert.False(t, open)
+	assert.Equal(t, 0, hub.Publish("trip-1", Message{Event: "location"}))
+	assert.Equal(t, 1, hub.SubscriberCount("trip-2"))
+}
+
+func TestHub_SlowSubscriberKeepsLatest(t *testing.T) {
+	hub := NewHub(0)
+	sub, err := hub.Subscribe("trip-1")
+	require.NoError(t, err)
+
+	for i := 0; i < subscriberBuffer+3; i++ {
+		hub.Publish("trip-1", Message{Event: "location", Data: i})
+	}
+	var last Message
+	for i := 0; i < subscriberBuffer; i++ {
+		last = <-sub.C
+	}
+	assert.Equal(t, subscriberBuffer+2, last.Data, "oldest updates are dropped, not the newest")
+	assert.Empty(t, sub.C)
+}
+
+func TestHub_CloseTopic(t *testing.T) {
+	hub := NewHub(0)
+	sub, err := hub.Subscribe("trip-1")
+	require.NoError(t, err)
+
+	hub.CloseTopic("trip-1", &Message{Event: "trip_ended"})
+	assert.Equal(t, "trip_ended", (<-sub.C).Event)
+	_, open := <-sub.C
+	assert.False(t, open, "closed after the final message")
+	assert.Equal(t, 0, hub.SubscriberCount("trip-1"))
+
+	sub.Close()
+}
+
+func TestHub_SubscriberLimit(t *testing.T) {
+	hub := NewHub(1)
+	sub, err := hub.Subscribe("trip-1")
+	require.NoError(t, err)
+
+	_, err = hub.Subscribe("trip-1")
+	assert.True(t, errors.Is(err, ErrTooManySubscribers))
+
+	sub.Close()
+	_, err = hub.Subscribe("trip-1")
+	assert.NoError(t, err, "slot freed on close")
+}
+
+func TestHub_Nil(t *testing.T) {
+	var hub *Hub
+	assert.Equal(t, 0, hub.Publish("trip-1", Message{Event: "location"}))
+	hub.CloseTopic("trip-1", nil)
+	assert.Equal(t, 0, hub.SubscriberCount("trip-1"))
+}
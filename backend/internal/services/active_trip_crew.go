@@ -0,0 +1,183 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// ============================================================================
+// DUAL-SESSION CREW
+// ============================================================================
+
+// authorizeCrewAction checks staffID may perform action on the trip given which crew devices
+// are online, then records the caller's device as seen
+func (s *ActiveTripService) authorizeCrewAction(activeTrip *models.ActiveTrip, staffID string, action models.ActiveTripAction) error {
+	role, ok := activeTrip.PrimaryCrewRole(staffID)
+	if !ok {
+		return ErrNotTripCrew
+	}
+
+	sessions, err := s.activeTripRepo.GetCrewSessions(activeTrip.ID)
+	if err != nil {
+		return err
+	}
+	if !activeTrip.Can(staffID, action, sessions, time.Now()) {
+		return fmt.Errorf("%w: the %s's device handles %s while it is online", ErrCrewActionNotAllowed, otherCrewRole(role), action)
+	}
+
+	s.touchCrewSession(activeTrip, staffID)
+	return nil
+}
+
+// touchCrewSession records staffID's device as seen on the trip; failures only cost liveness accuracy
+func (s *ActiveTripService) touchCrewSession(activeTrip *models.ActiveTrip, staffID string) {
+	role, ok := activeTrip.PrimaryCrewRole(staffID)
+	if !ok {
+		return
+	}
+	if err := s.activeTripRepo.TouchCrewSession(activeTrip.ID, staffID, role); err != nil {
+		log.Printf("WARNING: Failed to touch crew session for trip %s: %v", activeTrip.ID, err)
+	}
+}
+
+// GetCrewView returns the active trip as seen from staffID's device: their role, the actions
+// open to them right now and which crew devices are online
+func (s *ActiveTripService) GetCrewView(activeTrip *models.ActiveTrip, staffID string) (*models.ActiveTripCrewView, error) {
+	role, ok := activeTrip.PrimaryCrewRole(staffID)
+	if !ok {
+		return nil, ErrNotTripCrew
+	}
+
+	if activeTrip.IsActive() {
+		s.touchCrewSession(activeTrip, staffID)
+	}
+	sessions, err := s.activeTripRepo.GetCrewSessions(activeTrip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range sessions {
+		sessions[i].IsLive = sessions[i].Live(now)
+	}
+
+	actions := []models.ActiveTripAction{models.ActiveTripActionView}
+	if activeTrip.IsActive() {
+		actions = activeTrip.AllowedActions(staffID, sessions, now)
+	}
+
+	return &models.ActiveTripCrewView{
+		ActiveTrip:     activeTrip,
+		MyRole:         role,
+		AllowedActions: actions,
+		Crew:           sessions,
+	}, nil
+}
+
+// HandOverCrew hands the driver or conductor seat of a running trip to another staff member of
+// the same bus owner, e.g. when crews change mid-route. Either current crew member can do it.
+func (s *ActiveTripService) HandOverCrew(activeTripID, staffID string, req *models.ActiveTripHandoverRequest) (*models.ActiveTripHandover, error) {
+	// 1. Get the active trip
+	activeTrip, err := s.activeTripRepo.GetByID(activeTripID)
+	if err != nil {
+		return nil, errors.New("active trip not found")
+	}
+
+	// 2. Verify the trip is still active
+	if !activeTrip.IsActive() {
+		return nil, errors.New("trip is no longer active")
+	}
+
+	// 3. Verify the caller is on the crew
+	if err := s.authorizeCrewAction(activeTrip, staffID, models.ActiveTripActionHandover); err != nil {
+		return nil, err
+	}
+
+	outgoing := activeTrip.ConductorID
+	if req.Role == models.ActiveTripCrewDriver {
+		outgoing = &activeTrip.DriverID
+	}
+	if outgoing != nil && *outgoing == req.NewStaffID {
+		return nil, fmt.Errorf("staff member is already the %s of this trip", req.Role)
+	}
+
+	// 4. Verify the incoming staff member can take the seat
+	if err := s.validateIncomingCrew(activeTrip, req); err != nil {
+		return nil, err
+	}
+
+	// 5. Move the seat
+	handover := &models.ActiveTripHandover{
+		ActiveTripID: activeTrip.ID,
+		Role:         req.Role,
+		FromStaffID:  outgoing,
+		ToStaffID:    req.NewStaffID,
+		HandedOverBy: staffID,
+		Reason:       req.Reason,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+	}
+	if err := s.activeTripRepo.HandOverCrew(handover); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("trip is no longer active")
+		}
+		return nil, errors.New("failed to hand over trip: " + err.Error())
+	}
+
+	// 6. Sign the outgoing device off unless they still hold the other seat
+	if outgoing != nil {
+		stillCrew := (req.Role == models.ActiveTripCrewDriver && activeTrip.ConductorID != nil && *activeTrip.ConductorID == *outgoing) ||
+			(req.Role == models.ActiveTripCrewConductor && activeTrip.DriverID == *outgoing)
+		if !stillCrew {
+			if err := s.activeTripRepo.EndCrewSession(activeTrip.ID, *outgoing); err != nil {
+				log.Printf("WARNING: Failed to end crew session after handover on trip %s: %v", activeTrip.ID, err)
+			}
+		}
+	}
+
+	log.Printf("[HandOverCrew] Trip %s %s seat handed from %v to %s by %s", activeTrip.ID, req.Role, outgoing, req.NewStaffID, staffID)
+	return handover, nil
+}
+
+// validateIncomingCrew applies the same checks as assigning staff to a scheduled trip
+func (s *ActiveTripService) validateIncomingCrew(activeTrip *models.ActiveTrip, req *models.ActiveTripHandoverRequest) error {
+	staff, err := s.staffRepo.GetByID(req.NewStaffID)
+	if err != nil || staff == nil {
+		return errors.New("new staff member not found")
+	}
+	if string(staff.StaffType) != string(req.Role) && staff.StaffType != "both" {
+		return fmt.Errorf("selected staff is not a %s", req.Role)
+	}
+	if req.Role == models.ActiveTripCrewDriver && staff.LicenseExpiryDate != nil && staff.LicenseExpiryDate.Before(time.Now()) {
+		return errors.New("new driver's license has expired")
+	}
+
+	permit, err := s.permitRepo.GetByID(activeTrip.PermitID)
+	if err != nil {
+		return errors.New("failed to get permit information")
+	}
+	employment, err := s.staffRepo.GetCurrentEmployment(req.NewStaffID)
+	if err != nil || employment == nil || employment.BusOwnerID != permit.BusOwnerID {
+		return errors.New("new staff member is not employed by this bus owner")
+	}
+	if employment.EmploymentStatus != models.EmploymentStatusActive {
+		return errors.New("new staff member is not actively employed")
+	}
+
+	if otherTrip, err := s.GetMyActiveTrip(req.NewStaffID); err == nil && otherTrip.ID != activeTrip.ID {
+		return errors.New("new staff member is already on another active trip")
+	}
+	return nil
+}
+
+func otherCrewRole(role models.ActiveTripCrewRole) models.ActiveTripCrewRole {
+	if role == models.ActiveTripCrewDriver {
+		return models.ActiveTripCrewConductor
+	}
+	return models.ActiveTripCrewDriver
+}
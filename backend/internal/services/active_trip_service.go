@@ -2,11 +2,27 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/realtime"
+)
+
+var (
+	ErrNotTripCrew          = errors.New("you are not assigned to this trip")
+	ErrCrewActionNotAllowed = errors.New("action not allowed for your role on this trip")
+	ErrActiveTripNotFound   = errors.New("active trip not found")
+	ErrTripNotActive        = errors.New("trip is not currently active")
+	ErrNotTripOwner         = errors.New("this trip does not belong to you")
+)
+
+// Live location stream event names
+const (
+	TripStreamEventLocation = "location"
+	TripStreamEventEnded    = "trip_ended"
 )
 
 // ActiveTripService handles business logic for active trips (real-time trip tracking)
@@ -16,6 +32,10 @@ type ActiveTripService struct {
 	staffRepo         *database.BusStaffRepository
 	busRepo           *database.BusRepository
 	permitRepo        *database.RoutePermitRepository
+	tripSharing       *TripSharingService
+	liveLocations     *realtime.Hub // Topics are active trip IDs
+	locationHistory   *database.TripLocationHistoryRepository
+	locationRecorder  *TripLocationRecorder
 }
 
 // NewActiveTripService creates a new ActiveTripService
@@ -25,6 +45,10 @@ func NewActiveTripService(
 	staffRepo *database.BusStaffRepository,
 	busRepo *database.BusRepository,
 	permitRepo *database.RoutePermitRepository,
+	tripSharing *TripSharingService,
+	liveLocations *realtime.Hub,
+	locationHistory *database.TripLocationHistoryRepository,
+	locationRecorder *TripLocationRecorder,
 ) *ActiveTripService {
 	return &ActiveTripService{
 		activeTripRepo:    activeTripRepo,
@@ -32,6 +56,10 @@ func NewActiveTripService(
 		staffRepo:         staffRepo,
 		busRepo:           busRepo,
 		permitRepo:        permitRepo,
+		tripSharing:       tripSharing,
+		liveLocations:     liveLocations,
+		locationHistory:   locationHistory,
+		locationRecorder:  locationRecorder,
 	}
 }
 
@@ -97,6 +125,8 @@ func (s *ActiveTripService) StartTrip(input *StartTripInput) (*StartTripResult,
 		// Active trip already exists
 		if existingActiveTrip.IsActive() {
 			log.Printf("[StartTrip] Trip already active, returning existing")
+			// The other crew member's device joins the running trip
+			s.touchCrewSession(existingActiveTrip, input.StaffID)
 			return &StartTripResult{
 				ActiveTrip:      existingActiveTrip,
 				Message:         "Trip already started",
@@ -171,6 +201,13 @@ func (s *ActiveTripService) StartTrip(input *StartTripInput) (*StartTripResult,
 		return nil, errors.New("failed to create active trip: " + err.Error())
 	}
 	log.Printf("[StartTrip] Active trip created successfully: ID=%s", activeTrip.ID)
+	s.touchCrewSession(activeTrip, input.StaffID)
+	s.locationRecorder.Record(models.TripLocationRecord{
+		ActiveTripID: activeTrip.ID,
+		Latitude:     input.InitialLatitude,
+		Longitude:    input.InitialLongitude,
+		RecordedAt:   now,
+	})
 
 	// 7. Update scheduled trip status to in_progress
 	log.Printf("[StartTrip] Updating scheduled trip status to in_progress...")
@@ -180,6 +217,9 @@ func (s *ActiveTripService) StartTrip(input *StartTripInput) (*StartTripResult,
 		// Log but don't fail - active trip was created successfully
 	}
 
+	// 8. Share the live trip with passengers' emergency contacts (in background)
+	go s.tripSharing.NotifyTripStarted(input.ScheduledTripID)
+
 	log.Printf("[StartTrip] === START TRIP COMPLETED SUCCESSFULLY ===")
 	return &StartTripResult{
 		ActiveTrip:      activeTrip,
@@ -211,9 +251,9 @@ func (s *ActiveTripService) UpdateLocation(input *UpdateLocationInput) error {
 		return errors.New("trip is no longer active")
 	}
 
-	// 3. Verify the staff is assigned to this trip
-	if activeTrip.DriverID != input.StaffID && (activeTrip.ConductorID == nil || *activeTrip.ConductorID != input.StaffID) {
-		return errors.New("you are not assigned to this trip")
+	// 3. Verify the staff may report the bus position
+	if err := s.authorizeCrewAction(activeTrip, input.StaffID, models.ActiveTripActionUpdateLocation); err != nil {
+		return err
 	}
 
 	// 4. Update location
@@ -222,9 +262,87 @@ func (s *ActiveTripService) UpdateLocation(input *UpdateLocationInput) error {
 		return errors.New("failed to update location: " + err.Error())
 	}
 
+	// 5. Keep the position for playback
+	now := time.Now()
+	s.locationRecorder.Record(models.TripLocationRecord{
+		ActiveTripID: input.ActiveTripID,
+		Latitude:     input.Latitude,
+		Longitude:    input.Longitude,
+		SpeedKmh:     input.SpeedKmh,
+		Heading:      input.Heading,
+		RecordedAt:   now,
+	})
+
+	// 6. Push the position to passengers following the trip
+	s.liveLocations.Publish(input.ActiveTripID, realtime.Message{
+		Event: TripStreamEventLocation,
+		Data: &TripLocationUpdate{
+			ActiveTripID: input.ActiveTripID,
+			Latitude:     input.Latitude,
+			Longitude:    input.Longitude,
+			SpeedKmh:     input.SpeedKmh,
+			Heading:      input.Heading,
+			UpdatedAt:    now,
+		},
+	})
+
 	return nil
 }
 
+// TripLocationUpdate is a bus position pushed to passengers following an active trip
+type TripLocationUpdate struct {
+	ActiveTripID string    `json:"active_trip_id"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	SpeedKmh     *float64  `json:"speed_kmh,omitempty"`
+	Heading      *float64  `json:"heading,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TripEndedUpdate tells passengers following a trip that it is over
+type TripEndedUpdate struct {
+	ActiveTripID string                  `json:"active_trip_id"`
+	Status       models.ActiveTripStatus `json:"status"`
+}
+
+// TripLocation returns the last reported position of a trip, or nil before the first one
+func TripLocation(activeTrip *models.ActiveTrip) *TripLocationUpdate {
+	if activeTrip.CurrentLatitude == nil || activeTrip.CurrentLongitude == nil || activeTrip.LastLocationUpdate == nil {
+		return nil
+	}
+	return &TripLocationUpdate{
+		ActiveTripID: activeTrip.ID,
+		Latitude:     *activeTrip.CurrentLatitude,
+		Longitude:    *activeTrip.CurrentLongitude,
+		SpeedKmh:     activeTrip.CurrentSpeedKmh,
+		Heading:      activeTrip.Heading,
+		UpdatedAt:    *activeTrip.LastLocationUpdate,
+	}
+}
+
+// SubscribeLocation starts following the live position of an active trip. It returns the trip as
+// it is now, so the caller can send the current position before the pushed updates. The
+// subscription must be closed once the client goes away.
+func (s *ActiveTripService) SubscribeLocation(activeTripID string) (*models.ActiveTrip, *realtime.Subscription, error) {
+	// Subscribe before reading the trip so no update falls in between
+	sub, err := s.liveLocations.Subscribe(activeTripID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	activeTrip, err := s.activeTripRepo.GetByID(activeTripID)
+	if err != nil {
+		sub.Close()
+		return nil, nil, ErrActiveTripNotFound
+	}
+	if !activeTrip.IsActive() {
+		sub.Close()
+		return nil, nil, ErrTripNotActive
+	}
+
+	return activeTrip, sub, nil
+}
+
 // EndTripInput contains data needed to end a trip
 type EndTripInput struct {
 	ActiveTripID   string  `json:"active_trip_id"`
@@ -268,9 +386,9 @@ func (s *ActiveTripService) EndTrip(input *EndTripInput) (*EndTripResult, error)
 		return nil, errors.New("trip is already completed or cancelled")
 	}
 
-	// 3. Verify the staff is assigned to this trip
-	if activeTrip.DriverID != input.StaffID && (activeTrip.ConductorID == nil || *activeTrip.ConductorID != input.StaffID) {
-		return nil, errors.New("you are not assigned to this trip")
+	// 3. Verify the staff may end the trip
+	if err := s.authorizeCrewAction(activeTrip, input.StaffID, models.ActiveTripActionEndTrip); err != nil {
+		return nil, err
 	}
 
 	// 4. Update final location
@@ -293,6 +411,12 @@ func (s *ActiveTripService) EndTrip(input *EndTripInput) (*EndTripResult, error)
 		// TODO: Add proper logging
 	}
 
+	// 8. Close the live location streams of the trip
+	s.liveLocations.CloseTopic(activeTrip.ID, &realtime.Message{
+		Event: TripStreamEventEnded,
+		Data:  &TripEndedUpdate{ActiveTripID: activeTrip.ID, Status: activeTrip.Status},
+	})
+
 	return &EndTripResult{
 		ActiveTrip: activeTrip,
 		Message:    "Trip completed successfully",
@@ -305,6 +429,28 @@ func (s *ActiveTripService) GetActiveTrip(activeTripID string) (*models.ActiveTr
 	return s.activeTripRepo.GetByID(activeTripID)
 }
 
+// GetLocationHistory returns the route an active trip's bus has reported so far, for the bus
+// owner whose permit it runs under. Positions still buffered by the recorder are not included.
+func (s *ActiveTripService) GetLocationHistory(activeTripID string, busOwnerID string) (*models.TripLocationHistory, error) {
+	activeTrip, err := s.activeTripRepo.GetByID(activeTripID)
+	if err != nil {
+		return nil, ErrActiveTripNotFound
+	}
+	permit, err := s.permitRepo.GetByID(activeTrip.PermitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permit: %w", err)
+	}
+	if permit.BusOwnerID != busOwnerID {
+		return nil, ErrNotTripOwner
+	}
+
+	points, err := s.locationHistory.GetByActiveTripID(activeTripID, models.TripLocationHistoryMaxPoints)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildTripLocationHistory(activeTrip, points), nil
+}
+
 // GetActiveTripByScheduledTrip retrieves an active trip by scheduled trip ID
 func (s *ActiveTripService) GetActiveTripByScheduledTrip(scheduledTripID string) (*models.ActiveTrip, error) {
 	return s.activeTripRepo.GetByScheduledTripID(scheduledTripID)
@@ -327,6 +473,33 @@ func (s *ActiveTripService) GetMyActiveTrip(staffID string) (*models.ActiveTrip,
 	return nil, errors.New("no active trip found for this staff member")
 }
 
+// ReportIncident flags an incident on an active trip and alerts passengers' emergency contacts
+func (s *ActiveTripService) ReportIncident(activeTripID string, staffID string, req *models.ReportTripIncidentRequest) (*models.TripIncident, error) {
+	// 1. Get the active trip
+	activeTrip, err := s.activeTripRepo.GetByID(activeTripID)
+	if err != nil {
+		return nil, errors.New("active trip not found")
+	}
+
+	// 2. Verify the trip is still active
+	if !activeTrip.IsActive() {
+		return nil, errors.New("trip is no longer active")
+	}
+
+	// 3. Verify the staff is assigned to this trip
+	if err := s.authorizeCrewAction(activeTrip, staffID, models.ActiveTripActionReportIncident); err != nil {
+		return nil, err
+	}
+
+	// 4. Record the incident and notify contacts
+	incident, err := s.tripSharing.ReportIncident(activeTrip, staffID, req)
+	if err != nil {
+		return nil, errors.New("failed to report incident: " + err.Error())
+	}
+
+	return incident, nil
+}
+
 // UpdatePassengerCount updates the current passenger count
 func (s *ActiveTripService) UpdatePassengerCount(activeTripID string, staffID string, count int) error {
 	// 1. Get the active trip
@@ -340,9 +513,9 @@ func (s *ActiveTripService) UpdatePassengerCount(activeTripID string, staffID st
 		return errors.New("trip is no longer active")
 	}
 
-	// 3. Verify the staff is assigned to this trip
-	if activeTrip.DriverID != staffID && (activeTrip.ConductorID == nil || *activeTrip.ConductorID != staffID) {
-		return errors.New("you are not assigned to this trip")
+	// 3. Verify the staff may update the passenger count
+	if err := s.authorizeCrewAction(activeTrip, staffID, models.ActiveTripActionUpdatePassengers); err != nil {
+		return err
 	}
 
 	// 4. Update passenger count
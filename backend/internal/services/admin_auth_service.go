@@ -182,7 +182,11 @@ func (s *AdminAuthService) ChangePassword(ctx context.Context, adminID uuid.UUID
 }
 
 // CreateAdmin creates a new admin user
-func (s *AdminAuthService) CreateAdmin(ctx context.Context, email, password, fullName string, createdBy uuid.UUID) (*models.AdminUser, error) {
+func (s *AdminAuthService) CreateAdmin(ctx context.Context, email, password, fullName string, role models.AdminRole, createdBy uuid.UUID) (*models.AdminUser, error) {
+	if !models.ValidAdminRole(role) {
+		return nil, &models.ValidationError{Message: fmt.Sprintf("unknown admin role %q", role)}
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -193,6 +197,7 @@ func (s *AdminAuthService) CreateAdmin(ctx context.Context, email, password, ful
 		Email:        email,
 		PasswordHash: string(hashedPassword),
 		FullName:     fullName,
+		Role:         role,
 		IsActive:     true,
 		CreatedBy:    &createdBy,
 	}
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/push"
+)
+
+var (
+	ErrBulkJobNotFound    = errors.New("bulk job not found")
+	ErrBulkJobNotFinished = errors.New("bulk job has not finished yet")
+	ErrBulkJobNoResults   = errors.New("bulk job has no results file")
+)
+
+const (
+	bulkJobProgressEvery = 50  // Items between progress updates
+	bulkJobUserPageSize  = 500 // Users loaded per query for exports and role notifications
+)
+
+// bulkJobItemCSVHeader is the header row of approval and notification results files
+var bulkJobItemCSVHeader = []string{"item_id", "outcome", "message"}
+
+// AdminBulkJobService accepts admin bulk operations (lounge approvals, user exports,
+// notification sends) that would time out over HTTP, and runs the background worker that
+// processes them one at a time, recording progress and a results CSV.
+type AdminBulkJobService struct {
+	repo        *database.AdminBulkJobRepository
+	loungeRepo  *database.LoungeRepository
+	userRepo    *database.UserRepository
+	pushService *PushNotificationService
+	config      config.AdminJobsConfig
+	logger      *logrus.Logger
+	stopCh      chan struct{}
+}
+
+// NewAdminBulkJobService creates a new AdminBulkJobService
+func NewAdminBulkJobService(
+	repo *database.AdminBulkJobRepository,
+	loungeRepo *database.LoungeRepository,
+	userRepo *database.UserRepository,
+	pushService *PushNotificationService,
+	cfg config.AdminJobsConfig,
+	logger *logrus.Logger,
+) *AdminBulkJobService {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 5 * time.Second
+	}
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = 5000
+	}
+	if cfg.MaxExportRows <= 0 {
+		cfg.MaxExportRows = 200000
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = 15 * time.Minute
+	}
+	return &AdminBulkJobService{
+		repo:        repo,
+		loungeRepo:  loungeRepo,
+		userRepo:    userRepo,
+		pushService: pushService,
+		config:      cfg,
+		logger:      logger,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// JOBS
+// ============================================================================
+
+// Submit validates and queues a bulk job; the worker picks it up within the check interval
+func (s *AdminBulkJobService) Submit(adminUserID uuid.UUID, req *models.CreateAdminBulkJobRequest) (*models.AdminBulkJob, error) {
+	if err := req.Validate(s.config.MaxItems); err != nil {
+		return nil, err
+	}
+
+	job := &models.AdminBulkJob{
+		JobType:         req.JobType,
+		Params:          req.Params,
+		CreatedByUserID: adminUserID,
+	}
+	switch req.JobType {
+	case models.AdminBulkJobLoungeApproval:
+		job.TotalItems = len(req.Params.LoungeIDs)
+	case models.AdminBulkJobNotificationSend:
+		job.TotalItems = len(req.Params.UserIDs)
+	}
+	if err := s.repo.Create(job); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"job_id":        job.ID,
+		"job_type":      job.JobType,
+		"admin_user_id": adminUserID,
+	}).Info("Admin bulk job queued")
+	return job, nil
+}
+
+// GetJob returns a job with its progress
+func (s *AdminBulkJobService) GetJob(id string) (*models.AdminBulkJob, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrBulkJobNotFound
+	}
+	job, err := s.repo.GetByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrBulkJobNotFound
+	}
+	return job, nil
+}
+
+// ListJobs returns jobs newest first, optionally filtered by type and status
+func (s *AdminBulkJobService) ListJobs(jobType, status string, limit, offset int) ([]models.AdminBulkJob, int, error) {
+	return s.repo.List(jobType, status, limit, offset)
+}
+
+// GetResults returns a finished job and its results CSV
+func (s *AdminBulkJobService) GetResults(id string) (*models.AdminBulkJob, []byte, error) {
+	job, err := s.GetJob(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !job.Status.IsFinished() {
+		return nil, nil, ErrBulkJobNotFinished
+	}
+	data, err := s.repo.GetResultFile(job.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data == nil {
+		return nil, nil, ErrBulkJobNoResults
+	}
+	return job, data, nil
+}
+
+// ============================================================================
+// BACKGROUND PROCESSING
+// ============================================================================
+
+// Start begins the background worker
+func (s *AdminBulkJobService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Admin bulk job worker disabled (ADMIN_JOBS_ENABLED=false)")
+		return
+	}
+	s.logger.WithField("interval", s.config.CheckInterval.String()).Info("📦 Starting Admin Bulk Job worker")
+	go s.run()
+}
+
+// Stop stops the background worker. A job cut short is picked up again once it is stale.
+func (s *AdminBulkJobService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Admin Bulk Job worker")
+	close(s.stopCh)
+}
+
+func (s *AdminBulkJobService) run() {
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processQueued()
+		case <-s.stopCh:
+			s.logger.Info("Admin Bulk Job worker stopped")
+			return
+		}
+	}
+}
+
+// RunOnce processes the queued jobs (useful for testing or manual trigger)
+func (s *AdminBulkJobService) RunOnce() {
+	s.processQueued()
+}
+
+func (s *AdminBulkJobService) stopping() bool {
+	select {
+	case <-s.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// processQueued works through queued jobs until none are left
+func (s *AdminBulkJobService) processQueued() {
+	for !s.stopping() {
+		job, err := s.repo.ClaimNext(s.config.StaleAfter)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to claim admin bulk job")
+			return
+		}
+		if job == nil {
+			return
+		}
+		s.process(job)
+	}
+}
+
+// process runs a claimed job from the start. A job retried after a crash starts over:
+// approvals skip lounges already decided, but notifications may be sent again.
+func (s *AdminBulkJobService) process(job *models.AdminBulkJob) {
+	log := s.logger.WithFields(logrus.Fields{"job_id": job.ID, "job_type": job.JobType})
+	log.Info("Processing admin bulk job")
+
+	run := newBulkJobRun(job)
+	var err error
+	switch job.JobType {
+	case models.AdminBulkJobLoungeApproval:
+		err = s.runLoungeApproval(run)
+	case models.AdminBulkJobUserExport:
+		err = s.runUserExport(run)
+	case models.AdminBulkJobNotificationSend:
+		err = s.runNotificationSend(run)
+	default:
+		err = fmt.Errorf("unknown job type %q", job.JobType)
+	}
+	if s.stopping() {
+		log.Warn("Admin bulk job interrupted by shutdown; it will be retried")
+		return
+	}
+
+	job.Status = models.AdminBulkJobCompleted
+	if err != nil {
+		log.WithError(err).Error("Admin bulk job failed")
+		job.Status = models.AdminBulkJobFailed
+		message := err.Error()
+		job.ErrorMessage = &message
+	}
+	fileName := fmt.Sprintf("%s-%s.csv", job.JobType, job.ID.String()[:8])
+	job.ResultFileName = &fileName
+
+	if err := s.repo.Finish(job, run.results()); err != nil {
+		log.WithError(err).Error("Failed to save admin bulk job results")
+		return
+	}
+	log.WithFields(logrus.Fields{
+		"status":    job.Status,
+		"processed": job.ProcessedItems,
+		"succeeded": job.SucceededItems,
+		"failed":    job.FailedItems,
+	}).Info("Admin bulk job finished")
+}
+
+// progress saves the job's counts every bulkJobProgressEvery items
+func (s *AdminBulkJobService) progress(run *bulkJobRun) {
+	if run.job.ProcessedItems%bulkJobProgressEvery != 0 {
+		return
+	}
+	if err := s.repo.UpdateProgress(run.job); err != nil {
+		s.logger.WithError(err).WithField("job_id", run.job.ID).Warn("Failed to update admin bulk job progress")
+	}
+}
+
+func (s *AdminBulkJobService) runLoungeApproval(run *bulkJobRun) error {
+	params := run.job.Params
+	status := models.LoungeStatusApproved
+	if params.Action == "reject" {
+		status = models.LoungeStatusRejected
+	}
+
+	run.start(bulkJobItemCSVHeader, len(params.LoungeIDs))
+	for _, id := range params.LoungeIDs {
+		if s.stopping() {
+			return nil
+		}
+		run.record(s.decideLounge(id, status, params.Reason))
+		s.progress(run)
+	}
+	return nil
+}
+
+func (s *AdminBulkJobService) decideLounge(id string, status models.LoungeStatus, reason string) models.BulkJobItemResult {
+	result := models.BulkJobItemResult{ItemID: id}
+	loungeID, err := uuid.Parse(id)
+	if err != nil {
+		result.Outcome, result.Message = models.BulkItemFailed, "invalid lounge ID"
+		return result
+	}
+	lounge, err := s.loungeRepo.GetLoungeByID(loungeID)
+	if err != nil {
+		s.logger.WithError(err).WithField("lounge_id", id).Error("Failed to get lounge for bulk approval")
+		result.Outcome, result.Message = models.BulkItemFailed, "failed to load lounge"
+		return result
+	}
+	if lounge == nil {
+		result.Outcome, result.Message = models.BulkItemFailed, "lounge not found"
+		return result
+	}
+	if lounge.Status != models.LoungeStatusPending {
+		result.Outcome, result.Message = models.BulkItemSkipped, "lounge is already "+string(lounge.Status)
+		return result
+	}
+	if err := s.loungeRepo.UpdateLoungeStatus(loungeID, string(status)); err != nil {
+		s.logger.WithError(err).WithField("lounge_id", id).Error("Failed to update lounge in bulk approval")
+		result.Outcome, result.Message = models.BulkItemFailed, "failed to update lounge"
+		return result
+	}
+	s.logger.WithFields(logrus.Fields{"lounge_id": id, "status": status, "reason": reason}).Info("Lounge decided by bulk job")
+	result.Outcome, result.Message = models.BulkItemSucceeded, string(status)
+	return result
+}
+
+func (s *AdminBulkJobService) runUserExport(run *bulkJobRun) error {
+	params := run.job.Params
+	run.start(models.UserExportCSVHeader, 0)
+
+	for offset := 0; ; offset += bulkJobUserPageSize {
+		if s.stopping() {
+			return nil
+		}
+		users, err := s.userRepo.ListUsersByFilter(params.Role, params.Status, bulkJobUserPageSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			if run.job.ProcessedItems >= s.config.MaxExportRows {
+				message := "Export truncated at " + strconv.Itoa(s.config.MaxExportRows) + " rows; narrow the role or status filter"
+				run.job.ErrorMessage = &message
+				return nil
+			}
+			run.row(models.UserExportCSVRecord(user))
+			s.progress(run)
+		}
+		if len(users) < bulkJobUserPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *AdminBulkJobService) runNotificationSend(run *bulkJobRun) error {
+	params := run.job.Params
+	msg := push.Message{
+		Title: params.Title,
+		Body:  params.Body,
+		Data:  map[string]string{"type": "admin_announcement"},
+	}
+
+	if len(params.UserIDs) > 0 {
+		run.start(bulkJobItemCSVHeader, len(params.UserIDs))
+		for _, id := range params.UserIDs {
+			if s.stopping() {
+				return nil
+			}
+			run.record(s.notifyUser(id, msg))
+			s.progress(run)
+		}
+		return nil
+	}
+
+	run.start(bulkJobItemCSVHeader, 0)
+	for offset := 0; ; offset += bulkJobUserPageSize {
+		if s.stopping() {
+			return nil
+		}
+		users, err := s.userRepo.ListUsersByFilter(params.Role, params.Status, bulkJobUserPageSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			run.record(s.notifyUser(user.ID.String(), msg))
+			s.progress(run)
+		}
+		if len(users) < bulkJobUserPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *AdminBulkJobService) notifyUser(id string, msg push.Message) models.BulkJobItemResult {
+	result := models.BulkJobItemResult{ItemID: id}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		result.Outcome, result.Message = models.BulkItemFailed, "invalid user ID"
+		return result
+	}
+	delivered := s.pushService.NotifyUser(userID, msg)
+	if delivered == 0 {
+		result.Outcome, result.Message = models.BulkItemSkipped, "no device accepted the notification"
+		return result
+	}
+	result.Outcome, result.Message = models.BulkItemSucceeded, "delivered to "+strconv.Itoa(delivered)+" device(s)"
+	return result
+}
+
+// bulkJobRun accumulates a job's counts, first failures and results CSV while it runs
+type bulkJobRun struct {
+	job *models.AdminBulkJob
+	buf bytes.Buffer
+	csv *csv.Writer
+}
+
+func newBulkJobRun(job *models.AdminBulkJob) *bulkJobRun {
+	job.ProcessedItems, job.SucceededItems, job.FailedItems = 0, 0, 0
+	job.Failures = models.BulkJobItemResults{}
+	job.ErrorMessage = nil
+	run := &bulkJobRun{job: job}
+	run.csv = csv.NewWriter(&run.buf)
+	return run
+}
+
+// start writes the results header; total is 0 when it is only known at the end
+func (r *bulkJobRun) start(header []string, total int) {
+	r.job.TotalItems = total
+	_ = r.csv.Write(header)
+}
+
+// record counts an item outcome and writes it to the results
+func (r *bulkJobRun) record(result models.BulkJobItemResult) {
+	r.job.ProcessedItems++
+	switch result.Outcome {
+	case models.BulkItemSucceeded:
+		r.job.SucceededItems++
+	case models.BulkItemFailed:
+		r.job.FailedItems++
+		if len(r.job.Failures) < models.AdminBulkJobMaxFailures {
+			r.job.Failures = append(r.job.Failures, result)
+		}
+	}
+	_ = r.csv.Write([]string{result.ItemID, result.Outcome, result.Message})
+}
+
+// row writes an exported row, counting it as a succeeded item
+func (r *bulkJobRun) row(record []string) {
+	r.job.ProcessedItems++
+	r.job.SucceededItems++
+	_ = r.csv.Write(record)
+}
+
+// results returns the results CSV, fixing the total to the items processed if it was unknown
+func (r *bulkJobRun) results() []byte {
+	if r.job.TotalItems < r.job.ProcessedItems {
+		r.job.TotalItems = r.job.ProcessedItems
+	}
+	r.csv.Flush()
+	return r.buf.Bytes()
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkJobRun(t *testing.T) {
+	job := &models.AdminBulkJob{ProcessedItems: 7, FailedItems: 3} // Left over from an interrupted attempt
+	run := newBulkJobRun(job)
+	run.start(bulkJobItemCSVHeader, 0)
+
+	run.record(models.BulkJobItemResult{ItemID: "a", Outcome: models.BulkItemSucceeded, Message: "approved"})
+	run.record(models.BulkJobItemResult{ItemID: "b", Outcome: models.BulkItemSkipped, Message: "lounge is already approved"})
+	for i := 0; i < models.AdminBulkJobMaxFailures+5; i++ {
+		run.record(models.BulkJobItemResult{ItemID: "f" + strconv.Itoa(i), Outcome: models.BulkItemFailed, Message: "lounge not found"})
+	}
+	csv := string(run.results())
+
+	total := models.AdminBulkJobMaxFailures + 7
+	assert.Equal(t, total, job.ProcessedItems)
+	assert.Equal(t, total, job.TotalItems, "unknown total is set from the items processed")
+	assert.Equal(t, 1, job.SucceededItems)
+	assert.Equal(t, models.AdminBulkJobMaxFailures+5, job.FailedItems)
+	assert.Len(t, job.Failures, models.AdminBulkJobMaxFailures, "kept failures are capped")
+	assert.Contains(t, csv, "item_id,outcome,message\na,succeeded,approved\nb,skipped,lounge is already approved\n")
+	assert.Contains(t, csv, "f104,failed,lounge not found\n", "the results file has every item")
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// adminPermissionCacheTTL bounds how long another instance serves an admin's old role or a
+// role's old permissions; changes made on this instance apply at once
+const adminPermissionCacheTTL = 30 * time.Second
+
+// AdminPermissionService decides which admin endpoints an admin may use. An admin's role
+// comes from admin_users and the permissions each role grants from admin_role_permissions,
+// falling back to models.DefaultAdminRolePermissions. Super admins hold every permission, as
+// do the accounts on the configured super admin email list whatever their stored role, so a
+// deploy always has someone who can assign roles. Inactive admins hold none.
+type AdminPermissionService struct {
+	adminRepo      *database.AdminUserRepository
+	permissionRepo *database.AdminRolePermissionRepository
+	superAdmins    map[string]bool
+
+	mu            sync.Mutex
+	admins        map[uuid.UUID]adminAccessEntry
+	roles         map[models.AdminRole]models.AdminRoleInfo
+	rolesExpireAt time.Time
+}
+
+type adminAccessEntry struct {
+	role      models.AdminRole
+	active    bool
+	expiresAt time.Time
+}
+
+// NewAdminPermissionService creates a new AdminPermissionService
+func NewAdminPermissionService(adminRepo *database.AdminUserRepository, permissionRepo *database.AdminRolePermissionRepository, superAdminEmails []string) *AdminPermissionService {
+	superAdmins := make(map[string]bool, len(superAdminEmails))
+	for _, email := range superAdminEmails {
+		superAdmins[strings.ToLower(strings.TrimSpace(email))] = true
+	}
+	return &AdminPermissionService{
+		adminRepo:      adminRepo,
+		permissionRepo: permissionRepo,
+		superAdmins:    superAdmins,
+		admins:         make(map[uuid.UUID]adminAccessEntry),
+	}
+}
+
+// HasAdminPermission implements middleware.AdminPermissionChecker. Admin tokens carry the
+// admin's email in place of a phone number.
+func (s *AdminPermissionService) HasAdminPermission(adminID uuid.UUID, email string, permission models.AdminPermission) (bool, error) {
+	permissions, err := s.Permissions(adminID, email)
+	if err != nil {
+		return false, err
+	}
+	for _, granted := range permissions {
+		if granted == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Permissions returns the permissions an admin currently holds
+func (s *AdminPermissionService) Permissions(adminID uuid.UUID, email string) ([]models.AdminPermission, error) {
+	if s.superAdmins[strings.ToLower(email)] {
+		return models.AdminPermissions, nil
+	}
+	entry, err := s.admin(adminID)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.active {
+		return nil, nil
+	}
+	if entry.role == models.AdminRoleSuperAdmin {
+		return models.AdminPermissions, nil
+	}
+	roles, err := s.loadRoles()
+	if err != nil {
+		return nil, err
+	}
+	return roles[entry.role].Permissions, nil
+}
+
+// Roles returns every role with the permissions it grants
+func (s *AdminPermissionService) Roles() ([]models.AdminRoleInfo, error) {
+	roles, err := s.loadRoles()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.AdminRoleInfo, 0, len(models.AdminRoles))
+	for _, role := range models.AdminRoles {
+		out = append(out, roles[role])
+	}
+	return out, nil
+}
+
+// SetRolePermissions replaces the permissions a role grants. Super admin permissions are
+// fixed, and the permissions reserved to super admins cannot be granted to other roles.
+func (s *AdminPermissionService) SetRolePermissions(role models.AdminRole, permissions []models.AdminPermission, updatedBy uuid.UUID) (*models.AdminRoleInfo, error) {
+	if !models.ValidAdminRole(role) {
+		return nil, &models.ValidationError{Message: fmt.Sprintf("unknown admin role %q", role)}
+	}
+	if role == models.AdminRoleSuperAdmin {
+		return nil, &models.ValidationError{Message: "super admins always hold every permission"}
+	}
+	permissions = uniqueAdminPermissions(permissions)
+	if len(permissions) == 0 {
+		return nil, &models.ValidationError{Message: "a role needs at least one permission"}
+	}
+	for _, permission := range permissions {
+		if !models.ValidAdminPermission(permission) {
+			return nil, &models.ValidationError{Message: fmt.Sprintf("unknown admin permission %q", permission)}
+		}
+		if models.SuperAdminOnlyPermission(permission) {
+			return nil, &models.ValidationError{Message: fmt.Sprintf("%s is reserved to super admins", permission)}
+		}
+	}
+
+	if err := s.permissionRepo.ReplaceRole(role, permissions, updatedBy); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.rolesExpireAt = time.Time{}
+	s.mu.Unlock()
+
+	roles, err := s.loadRoles()
+	if err != nil {
+		return nil, err
+	}
+	info := roles[role]
+	return &info, nil
+}
+
+// ChangeRole moves an admin to another role and returns their previous one. Admins cannot
+// change their own role.
+func (s *AdminPermissionService) ChangeRole(ctx context.Context, adminID uuid.UUID, role models.AdminRole, changedBy uuid.UUID) (*models.AdminUser, models.AdminRole, error) {
+	if !models.ValidAdminRole(role) {
+		return nil, "", &models.ValidationError{Message: fmt.Sprintf("unknown admin role %q", role)}
+	}
+	if adminID == changedBy {
+		return nil, "", &models.ValidationError{Message: "admins cannot change their own role"}
+	}
+	admin, err := s.adminRepo.GetByID(ctx, adminID)
+	if err != nil {
+		return nil, "", err
+	}
+	previous := admin.Role
+	if previous != role {
+		if err := s.adminRepo.UpdateRole(ctx, adminID, role); err != nil {
+			return nil, "", err
+		}
+		admin.Role = role
+	}
+	s.Invalidate(adminID)
+	return admin, previous, nil
+}
+
+// Invalidate drops the cached role of admins whose role or status just changed
+func (s *AdminPermissionService) Invalidate(adminIDs ...uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, adminID := range adminIDs {
+		delete(s.admins, adminID)
+	}
+}
+
+func (s *AdminPermissionService) admin(adminID uuid.UUID) (adminAccessEntry, error) {
+	now := time.Now()
+	s.mu.Lock()
+	entry, ok := s.admins[adminID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry, nil
+	}
+
+	entry = adminAccessEntry{expiresAt: now.Add(adminPermissionCacheTTL)}
+	admin, err := s.adminRepo.GetByID(context.Background(), adminID)
+	switch {
+	case errors.Is(err, database.ErrAdminUserNotFound):
+		// A deleted admin's token grants nothing
+	case err != nil:
+		return adminAccessEntry{}, fmt.Errorf("failed to load admin role: %w", err)
+	default:
+		entry.role, entry.active = admin.Role, admin.IsActive
+	}
+
+	s.mu.Lock()
+	s.admins[adminID] = entry
+	s.mu.Unlock()
+	return entry, nil
+}
+
+func (s *AdminPermissionService) loadRoles() (map[models.AdminRole]models.AdminRoleInfo, error) {
+	now := time.Now()
+	s.mu.Lock()
+	roles, expiresAt := s.roles, s.rolesExpireAt
+	s.mu.Unlock()
+	if roles != nil && now.Before(expiresAt) {
+		return roles, nil
+	}
+
+	rows, err := s.permissionRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	roles = buildAdminRoles(rows)
+
+	s.mu.Lock()
+	s.roles, s.rolesExpireAt = roles, now.Add(adminPermissionCacheTTL)
+	s.mu.Unlock()
+	return roles, nil
+}
+
+// buildAdminRoles resolves what each role grants from the table rows. Rows naming an unknown
+// role or permission, or a permission reserved to super admins, are ignored.
+func buildAdminRoles(rows []models.AdminRolePermission) map[models.AdminRole]models.AdminRoleInfo {
+	roles := map[models.AdminRole]models.AdminRoleInfo{
+		models.AdminRoleSuperAdmin: {Role: models.AdminRoleSuperAdmin, Permissions: models.AdminPermissions},
+	}
+	for _, row := range rows {
+		if row.Role == models.AdminRoleSuperAdmin || !models.ValidAdminRole(row.Role) ||
+			!models.ValidAdminPermission(row.Permission) || models.SuperAdminOnlyPermission(row.Permission) {
+			continue
+		}
+		info := roles[row.Role]
+		info.Role = row.Role
+		info.Permissions = append(info.Permissions, row.Permission)
+		if info.UpdatedAt == nil || row.UpdatedAt.After(*info.UpdatedAt) {
+			updatedAt := row.UpdatedAt
+			info.UpdatedAt = &updatedAt
+		}
+		roles[row.Role] = info
+	}
+	for _, role := range models.AdminRoles {
+		if _, ok := roles[role]; !ok {
+			roles[role] = models.AdminRoleInfo{Role: role, Permissions: models.DefaultAdminRolePermissions[role], IsDefault: true}
+		}
+	}
+	return roles
+}
+
+func uniqueAdminPermissions(permissions []models.AdminPermission) []models.AdminPermission {
+	seen := make(map[models.AdminPermission]bool, len(permissions))
+	out := make([]models.AdminPermission, 0, len(permissions))
+	for _, permission := range permissions {
+		if !seen[permission] {
+			seen[permission] = true
+			out = append(out, permission)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAdminRoles(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	roles := buildAdminRoles([]models.AdminRolePermission{
+		{Role: models.AdminRoleFinance, Permission: models.AdminPermPaymentsManage, UpdatedAt: updatedAt},
+		{Role: models.AdminRoleFinance, Permission: models.AdminPermAdminsManage, UpdatedAt: updatedAt},
+		{Role: models.AdminRoleFinance, Permission: "payments.delete_everything", UpdatedAt: updatedAt},
+		{Role: models.AdminRoleSuperAdmin, Permission: models.AdminPermPaymentsManage, UpdatedAt: updatedAt},
+		{Role: "auditor", Permission: models.AdminPermDashboardView, UpdatedAt: updatedAt},
+	})
+
+	require.Len(t, roles, len(models.AdminRoles), "unknown roles are dropped")
+	assert.Equal(t, models.AdminPermissions, roles[models.AdminRoleSuperAdmin].Permissions)
+
+	finance := roles[models.AdminRoleFinance]
+	assert.Equal(t, []models.AdminPermission{models.AdminPermPaymentsManage}, finance.Permissions, "reserved and unknown permissions are ignored")
+	assert.False(t, finance.IsDefault)
+	require.NotNil(t, finance.UpdatedAt)
+	assert.Equal(t, updatedAt, *finance.UpdatedAt)
+
+	support := roles[models.AdminRoleSupport]
+	assert.True(t, support.IsDefault)
+	assert.Equal(t, models.DefaultAdminRolePermissions[models.AdminRoleSupport], support.Permissions)
+}
+
+func TestDefaultAdminRolePermissions_NotReserved(t *testing.T) {
+	for role, permissions := range models.DefaultAdminRolePermissions {
+		for _, permission := range permissions {
+			assert.True(t, models.ValidAdminPermission(permission), "%s: %s", role, permission)
+			assert.False(t, models.SuperAdminOnlyPermission(permission), "%s: %s", role, permission)
+		}
+	}
+}
+
+// newTestAdminPermissionService returns a service with the given admins and the default roles
+// already loaded
+func newTestAdminPermissionService(admins map[uuid.UUID]adminAccessEntry, superAdminEmails ...string) *AdminPermissionService {
+	service := NewAdminPermissionService(nil, nil, superAdminEmails)
+	for id, entry := range admins {
+		entry.expiresAt = time.Now().Add(time.Hour)
+		service.admins[id] = entry
+	}
+	service.roles = buildAdminRoles(nil)
+	service.rolesExpireAt = time.Now().Add(time.Hour)
+	return service
+}
+
+func TestAdminPermissionService_HasAdminPermission(t *testing.T) {
+	finance, inactive, superAdmin, bootstrap := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	service := newTestAdminPermissionService(map[uuid.UUID]adminAccessEntry{
+		finance:    {role: models.AdminRoleFinance, active: true},
+		inactive:   {role: models.AdminRoleFinance, active: false},
+		superAdmin: {role: models.AdminRoleSuperAdmin, active: true},
+		bootstrap:  {role: models.AdminRoleSupport, active: true},
+	}, " Root@SmartTransit.lk ")
+
+	check := func(adminID uuid.UUID, email string, permission models.AdminPermission) bool {
+		allowed, err := service.HasAdminPermission(adminID, email, permission)
+		require.NoError(t, err)
+		return allowed
+	}
+
+	assert.True(t, check(finance, "finance@smarttransit.lk", models.AdminPermPayoutsManage))
+	assert.False(t, check(finance, "finance@smarttransit.lk", models.AdminPermApprovals))
+	assert.False(t, check(inactive, "old@smarttransit.lk", models.AdminPermPayoutsManage), "inactive admins hold nothing")
+	assert.True(t, check(superAdmin, "boss@smarttransit.lk", models.AdminPermAdminsManage))
+	assert.True(t, check(bootstrap, "root@smarttransit.lk", models.AdminPermSecurityManage), "configured emails are super admins")
+}
+
+func TestAdminPermissionService_SetRolePermissionsValidation(t *testing.T) {
+	service := newTestAdminPermissionService(nil)
+	adminID := uuid.New()
+
+	for name, tc := range map[string]struct {
+		role        models.AdminRole
+		permissions []models.AdminPermission
+	}{
+		"unknown role":        {role: "auditor", permissions: []models.AdminPermission{models.AdminPermDashboardView}},
+		"super admin":         {role: models.AdminRoleSuperAdmin, permissions: []models.AdminPermission{models.AdminPermDashboardView}},
+		"no permissions":      {role: models.AdminRoleSupport},
+		"unknown permission":  {role: models.AdminRoleSupport, permissions: []models.AdminPermission{"users.delete"}},
+		"reserved permission": {role: models.AdminRoleOperations, permissions: []models.AdminPermission{models.AdminPermAdminsManage}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.SetRolePermissions(tc.role, tc.permissions, adminID)
+			var validationErr *models.ValidationError
+			assert.True(t, errors.As(err, &validationErr), "got %v", err)
+		})
+	}
+
+	_, _, err := service.ChangeRole(context.Background(), adminID, models.AdminRoleSupport, adminID)
+	var validationErr *models.ValidationError
+	assert.True(t, errors.As(err, &validationErr), "admins cannot change their own role")
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// AppConfigService builds the passenger app's remote config (version policy, feature toggles
+// and maintenance flags) from system settings, falling back to the environment defaults.
+// Settings are cached briefly because the version check runs on every request.
+type AppConfigService struct {
+	settingRepo *database.SystemSettingRepository
+	config      config.AppConfig
+	logger      *logrus.Logger
+
+	mu        sync.Mutex
+	cached    *models.AppConfig
+	expiresAt time.Time
+}
+
+// NewAppConfigService creates a new AppConfigService
+func NewAppConfigService(settingRepo *database.SystemSettingRepository, cfg config.AppConfig, logger *logrus.Logger) *AppConfigService {
+	return &AppConfigService{
+		settingRepo: settingRepo,
+		config:      cfg,
+		logger:      logger,
+	}
+}
+
+// Get returns the current app config
+func (s *AppConfigService) Get() (*models.AppConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.cached != nil && now.Before(s.expiresAt) {
+		return s.cached, nil
+	}
+
+	settings, err := s.settingRepo.GetAll()
+	if err != nil {
+		if s.cached != nil {
+			// Keep serving the last known config rather than failing every request
+			s.logger.WithError(err).Warn("Failed to refresh app config, serving cached copy")
+			s.expiresAt = now.Add(s.config.CacheTTL)
+			return s.cached, nil
+		}
+		return nil, err
+	}
+
+	s.cached = buildAppConfig(settings, s.config)
+	s.expiresAt = now.Add(s.config.CacheTTL)
+	return s.cached, nil
+}
+
+// CheckAppVersion judges an app version for a platform. Unknown platforms are always
+// supported (web and dashboard clients are not gated).
+func (s *AppConfigService) CheckAppVersion(platform, version string) (*models.AppVersionStatus, error) {
+	p, ok := models.ParseAppPlatform(platform)
+	if !ok || strings.TrimSpace(version) == "" {
+		return nil, nil
+	}
+	cfg, err := s.Get()
+	if err != nil {
+		return nil, err
+	}
+	status := cfg.Platform(p).CheckVersion(version)
+	return &status, nil
+}
+
+// MaintenanceStatus reports whether maintenance mode is on; used by MaintenanceMiddleware
+func (s *AppConfigService) MaintenanceStatus() (*models.AppMaintenance, error) {
+	cfg, err := s.Get()
+	if err != nil {
+		return nil, err
+	}
+	return &cfg.Maintenance, nil
+}
+
+// SetMaintenance switches maintenance mode and applies it on this instance immediately; other
+// instances follow once their cached settings expire
+func (s *AppConfigService) SetMaintenance(req *models.UpdateMaintenanceRequest) (*models.AppMaintenance, error) {
+	settings := []struct{ key, value, description string }{
+		{models.AppSettingMaintenanceMode, strconv.FormatBool(*req.Enabled), "Read-only maintenance mode: writes return 503 except for admins"},
+		{models.AppSettingMaintenanceMessage, strings.TrimSpace(req.Message), "Message shown to passengers during maintenance"},
+		{models.AppSettingMaintenanceRetryAfter, strconv.Itoa(req.RetryAfterSeconds), "Retry-After seconds sent with maintenance 503s (0 uses the default)"},
+	}
+	for _, setting := range settings {
+		if err := s.settingRepo.Upsert(setting.key, setting.value, setting.description); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", setting.key, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+
+	maintenance, err := s.MaintenanceStatus()
+	if err != nil {
+		return nil, err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"enabled":             maintenance.Enabled,
+		"retry_after_seconds": maintenance.RetryAfterSeconds,
+	}).Warn("Maintenance mode updated")
+	return maintenance, nil
+}
+
+// buildAppConfig overlays system settings on the environment defaults
+func buildAppConfig(settings []models.SystemSetting, defaults config.AppConfig) *models.AppConfig {
+	values := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		values[setting.SettingKey] = strings.TrimSpace(setting.SettingValue)
+	}
+	value := func(key, fallback string) string {
+		if v, ok := values[key]; ok {
+			return v
+		}
+		return fallback
+	}
+
+	platformDefaults := map[models.AppPlatform][3]string{
+		models.AppPlatformAndroid: {defaults.MinVersionAndroid, defaults.LatestVersionAndroid, defaults.StoreURLAndroid},
+		models.AppPlatformIOS:     {defaults.MinVersionIOS, defaults.LatestVersionIOS, defaults.StoreURLIOS},
+	}
+
+	result := &models.AppConfig{
+		Platforms: make([]models.AppPlatformConfig, 0, len(models.AppPlatforms)),
+		Features:  map[string]bool{},
+	}
+	for _, platform := range models.AppPlatforms {
+		d := platformDefaults[platform]
+		blocked := []string{}
+		for _, v := range strings.Split(value(models.AppSettingBlockedVersionsPrefix+string(platform), ""), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				blocked = append(blocked, v)
+			}
+		}
+		result.Platforms = append(result.Platforms, models.AppPlatformConfig{
+			Platform:            platform,
+			MinSupportedVersion: value(models.AppSettingMinVersionPrefix+string(platform), d[0]),
+			LatestVersion:       value(models.AppSettingLatestVersionPrefix+string(platform), d[1]),
+			StoreURL:            value(models.AppSettingStoreURLPrefix+string(platform), d[2]),
+			BlockedVersions:     blocked,
+		})
+	}
+
+	for key, v := range values {
+		if name := strings.TrimPrefix(key, models.AppSettingFeaturePrefix); name != key && name != "" {
+			result.Features[name] = settingEnabled(v)
+		}
+	}
+
+	result.Maintenance = models.AppMaintenance{
+		Enabled: settingEnabled(values[models.AppSettingMaintenanceMode]),
+		Message: values[models.AppSettingMaintenanceMessage],
+	}
+	if result.Maintenance.Enabled {
+		result.Maintenance.RetryAfterSeconds = models.DefaultMaintenanceRetryAfterSeconds
+		if seconds, err := strconv.Atoi(values[models.AppSettingMaintenanceRetryAfter]); err == nil && seconds > 0 {
+			result.Maintenance.RetryAfterSeconds = seconds
+		}
+	}
+	return result
+}
+
+// settingEnabled reads a boolean system setting
+func settingEnabled(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1", "yes", "on", "enabled":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAppConfig_SettingsOverrideDefaults(t *testing.T) {
+	defaults := config.AppConfig{MinVersionAndroid: "1.0.0", MinVersionIOS: "1.2.0", StoreURLIOS: "https://apps.example/ios"}
+	settings := []models.SystemSetting{
+		{SettingKey: "app_min_version_android", SettingValue: " 1.5.0 "},
+		{SettingKey: "app_blocked_versions_android", SettingValue: "1.5.1, 1.6.0,"},
+		{SettingKey: "app_feature_lounge_booking", SettingValue: "true"},
+		{SettingKey: "app_feature_trip_sharing", SettingValue: "off"},
+		{SettingKey: "maintenance_mode", SettingValue: "true"},
+		{SettingKey: "maintenance_message", SettingValue: "Back at 02:00"},
+		{SettingKey: "booking_advance_hours", SettingValue: "72"},
+	}
+
+	cfg := buildAppConfig(settings, defaults)
+
+	android := cfg.Platform(models.AppPlatformAndroid)
+	require.NotNil(t, android)
+	assert.Equal(t, "1.5.0", android.MinSupportedVersion)
+	assert.Equal(t, []string{"1.5.1", "1.6.0"}, android.BlockedVersions)
+
+	ios := cfg.Platform(models.AppPlatformIOS)
+	require.NotNil(t, ios)
+	assert.Equal(t, "1.2.0", ios.MinSupportedVersion)
+	assert.Equal(t, "https://apps.example/ios", ios.StoreURL)
+	assert.Empty(t, ios.BlockedVersions)
+
+	assert.Equal(t, map[string]bool{"lounge_booking": true, "trip_sharing": false}, cfg.Features)
+	assert.Equal(t, models.AppMaintenance{Enabled: true, Message: "Back at 02:00", RetryAfterSeconds: models.DefaultMaintenanceRetryAfterSeconds}, cfg.Maintenance)
+}
+
+func TestBuildAppConfig_MaintenanceRetryAfter(t *testing.T) {
+	settings := []models.SystemSetting{
+		{SettingKey: "maintenance_mode", SettingValue: "true"},
+		{SettingKey: "maintenance_retry_after_seconds", SettingValue: "900"},
+	}
+	assert.Equal(t, 900, buildAppConfig(settings, config.AppConfig{}).Maintenance.RetryAfterSeconds)
+
+	settings[0].SettingValue = "false"
+	assert.Equal(t, models.AppMaintenance{}, buildAppConfig(settings, config.AppConfig{}).Maintenance, "no retry hint while off")
+}
@@ -8,17 +8,23 @@ import (
 	"github.com/google/uuid"
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/utils"
+	"github.com/smarttransit/sms-auth-backend/pkg/geoip"
 )
 
+// loginCountryHistory is how far back logins are checked when flagging a login from a new country
+const loginCountryHistory = 90 * 24 * time.Hour
+
 // AuditService handles audit logging for security events
 type AuditService struct {
-	db database.DB
+	db  database.DB
+	geo *geoip.Locator // May be nil when no GeoIP database is configured
 }
 
 // NewAuditService creates a new audit service
-func NewAuditService(db database.DB) *AuditService {
+func NewAuditService(db database.DB, geo *geoip.Locator) *AuditService {
 	return &AuditService{
-		db: db,
+		db:  db,
+		geo: geo,
 	}
 }
 
@@ -122,6 +128,10 @@ func (s *AuditService) LogLogin(userID uuid.UUID, phone, ipAddress, userAgent, d
 		"device_info": deviceInfo,
 	}
 
+	if location := s.geo.Lookup(ipAddress); location != nil && location.CountryCode != "" {
+		s.checkLoginCountry(userID, location, ipAddress, userAgent)
+	}
+
 	return s.logEvent(AuditEvent{
 		UserID:     &userID,
 		Action:     "login",
@@ -199,8 +209,164 @@ func (s *AuditService) LogSuspiciousActivity(userID *uuid.UUID, activity, ipAddr
 	})
 }
 
+// LogBoardingEvent logs a passenger check-in or boarding by staff, including attempts rejected
+// by the trip's boarding window and owner overrides of it
+func (s *AuditService) LogBoardingEvent(userID uuid.UUID, action string, busBookingID *uuid.UUID, ipAddress, userAgent string, details map[string]interface{}) error {
+	return s.logEvent(AuditEvent{
+		UserID:     &userID,
+		Action:     action,
+		EntityType: "bus_booking",
+		EntityID:   busBookingID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Details:    details,
+	})
+}
+
+// LogPassengerContactAccess logs staff revealing a masked passenger's phone number or being
+// given the call relay for them
+func (s *AuditService) LogPassengerContactAccess(userID uuid.UUID, action string, seatID *uuid.UUID, ipAddress, userAgent string, details map[string]interface{}) error {
+	return s.logEvent(AuditEvent{
+		UserID:     &userID,
+		Action:     action,
+		EntityType: "bus_booking_seat",
+		EntityID:   seatID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Details:    details,
+	})
+}
+
+// LogTripAssignmentOverride logs a bus owner assigning staff to a trip despite a failed
+// assignment rule, with their justification
+func (s *AuditService) LogTripAssignmentOverride(userID uuid.UUID, action string, tripID *uuid.UUID, ipAddress, userAgent string, details map[string]interface{}) error {
+	return s.logEvent(AuditEvent{
+		UserID:     &userID,
+		Action:     action,
+		EntityType: "scheduled_trip",
+		EntityID:   tripID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Details:    details,
+	})
+}
+
+// LogOTPTestNumberEvent logs QA test number activity: sign-ins with the fixed OTP, kept apart
+// from real OTP requests and verifications, and super admins changing the allowlist or
+// reading the code. Details carry the masked number, never the number itself.
+func (s *AuditService) LogOTPTestNumberEvent(userID *uuid.UUID, action string, numberID *uuid.UUID, ipAddress, userAgent string, details map[string]interface{}) error {
+	return s.logEvent(AuditEvent{
+		UserID:     userID,
+		Action:     action,
+		EntityType: "otp_test_number",
+		EntityID:   numberID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Details:    details,
+	})
+}
+
+// LogQRSigningKeyRotated logs a super admin rotating the boarding QR signing key
+func (s *AuditService) LogQRSigningKeyRotated(userID uuid.UUID, keyID, ipAddress, userAgent string) error {
+	return s.logEvent(AuditEvent{
+		UserID:     &userID,
+		Action:     "qr_signing_key_rotated",
+		EntityType: "qr_signing_key",
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Details:    map[string]interface{}{"key_id": keyID},
+	})
+}
+
+// LogAdminPrivilegeChange logs a super admin creating an admin, changing an admin's role or
+// changing what a role grants. Entity is the admin ("admin_user") or the role ("admin_role",
+// named in details).
+func (s *AuditService) LogAdminPrivilegeChange(userID uuid.UUID, action, entityType string, entityID *uuid.UUID, ipAddress, userAgent string, details map[string]interface{}) error {
+	return s.logEvent(AuditEvent{
+		UserID:     &userID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Details:    details,
+	})
+}
+
+// LogEntityRestored logs an admin restoring a bus, permit or route its owner had deleted
+func (s *AuditService) LogEntityRestored(userID uuid.UUID, entityType string, entityID uuid.UUID, ipAddress, userAgent string, details map[string]interface{}) error {
+	return s.logEvent(AuditEvent{
+		UserID:     &userID,
+		Action:     "entity_restored",
+		EntityType: entityType,
+		EntityID:   &entityID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Details:    details,
+	})
+}
+
+// checkLoginCountry logs a suspicious activity when a user logs in from a country none of
+// their recent logins came from. Users without geolocated login history are not flagged.
+func (s *AuditService) checkLoginCountry(userID uuid.UUID, location *geoip.Location, ipAddress, userAgent string) {
+	query := `
+		SELECT DISTINCT details->'geo'->>'country_code'
+		FROM audit_logs
+		WHERE user_id = $1
+		  AND action = 'login'
+		  AND created_at > $2
+		  AND details->'geo'->>'country_code' IS NOT NULL
+	`
+
+	rows, err := s.db.Query(query, userID, time.Now().Add(-loginCountryHistory))
+	if err != nil {
+		return // Detection is best-effort; never block the login
+	}
+	defer rows.Close()
+
+	previousCountries := []string{}
+	for rows.Next() {
+		var country string
+		if err := rows.Scan(&country); err != nil {
+			continue
+		}
+		previousCountries = append(previousCountries, country)
+	}
+
+	if !isNewLoginCountry(location.CountryCode, previousCountries) {
+		return
+	}
+
+	s.LogSuspiciousActivity(&userID, "login_from_new_country", ipAddress, userAgent, map[string]interface{}{
+		"country_code":       location.CountryCode,
+		"country":            location.Country,
+		"previous_countries": previousCountries,
+	})
+}
+
+// isNewLoginCountry reports whether country is missing from a non-empty login history
+func isNewLoginCountry(country string, previousCountries []string) bool {
+	if country == "" || len(previousCountries) == 0 {
+		return false
+	}
+	for _, previous := range previousCountries {
+		if previous == country {
+			return false
+		}
+	}
+	return true
+}
+
 // logEvent is the internal method that writes to the audit_logs table
 func (s *AuditService) logEvent(event AuditEvent) error {
+	// Record where the request came from, when the IP is public and known to the GeoIP databases
+	if location := s.geo.Lookup(event.IPAddress); location != nil {
+		if event.Details == nil {
+			event.Details = make(map[string]interface{})
+		}
+		event.Details["geo"] = location
+	}
+
 	query := `
 		INSERT INTO audit_logs (user_id, action, entity_type, entity_id, ip_address, user_agent, details, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNewLoginCountry(t *testing.T) {
+	assert.True(t, isNewLoginCountry("IN", []string{"LK"}))
+	assert.False(t, isNewLoginCountry("LK", []string{"IN", "LK"}))
+	assert.False(t, isNewLoginCountry("IN", nil), "no history to compare against")
+	assert.False(t, isNewLoginCountry("", []string{"LK"}), "location unknown")
+}
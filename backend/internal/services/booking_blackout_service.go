@@ -0,0 +1,207 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrBlackoutNotFound       = errors.New("booking blackout not found")
+	ErrBlackoutTargetNotFound = errors.New("route or schedule not found or access denied")
+	ErrBlackoutNotActive      = errors.New("booking blackout is no longer active")
+	ErrTripBlackedOut         = errors.New("online booking is paused for this trip")
+)
+
+// BookingBlackoutService lets bus owners pause online sales for a route or schedule over a
+// date range without cancelling trips, and lets admins override a blackout to reopen sales.
+type BookingBlackoutService struct {
+	repo         *database.BookingBlackoutRepository
+	routeRepo    *database.BusOwnerRouteRepository
+	scheduleRepo *database.TripScheduleRepository
+	logger       *logrus.Logger
+}
+
+// NewBookingBlackoutService creates a new BookingBlackoutService
+func NewBookingBlackoutService(
+	repo *database.BookingBlackoutRepository,
+	routeRepo *database.BusOwnerRouteRepository,
+	scheduleRepo *database.TripScheduleRepository,
+	logger *logrus.Logger,
+) *BookingBlackoutService {
+	return &BookingBlackoutService{
+		repo:         repo,
+		routeRepo:    routeRepo,
+		scheduleRepo: scheduleRepo,
+		logger:       logger,
+	}
+}
+
+// ============================================================================
+// OWNER BLACKOUTS
+// ============================================================================
+
+// CreateBlackout pauses online sales for one of the owner's routes or schedules
+func (s *BookingBlackoutService) CreateBlackout(busOwnerID string, userID uuid.UUID, req *models.CreateBookingBlackoutRequest) (*models.BookingBlackout, error) {
+	if err := req.Validate(time.Now().In(models.ReportTimezone)); err != nil {
+		return nil, err
+	}
+	if err := s.checkTargetOwner(busOwnerID, req); err != nil {
+		return nil, err
+	}
+
+	blackout := &models.BookingBlackout{
+		BusOwnerID:      busOwnerID,
+		BusOwnerRouteID: nonEmpty(req.BusOwnerRouteID),
+		TripScheduleID:  nonEmpty(req.TripScheduleID),
+		StartDate:       req.StartDate,
+		EndDate:         req.EndDate,
+		Reason:          strings.TrimSpace(req.Reason),
+		CreatedByUserID: userID.String(),
+	}
+	if err := s.repo.Create(blackout); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"blackout_id":        blackout.ID,
+		"bus_owner_id":       busOwnerID,
+		"bus_owner_route_id": blackout.BusOwnerRouteID,
+		"trip_schedule_id":   blackout.TripScheduleID,
+		"start_date":         blackout.StartDate,
+		"end_date":           blackout.EndDate,
+	}).Info("Online booking blackout created")
+	return blackout, nil
+}
+
+// ListOwnerBlackouts returns the owner's blackouts; ended ones only when includeEnded is set
+func (s *BookingBlackoutService) ListOwnerBlackouts(busOwnerID string, includeEnded bool) ([]models.BookingBlackout, error) {
+	return s.repo.ListByOwner(busOwnerID, includeEnded)
+}
+
+// LiftBlackout ends one of the owner's blackouts early, reopening online sales
+func (s *BookingBlackoutService) LiftBlackout(busOwnerID, blackoutID string) (*models.BookingBlackout, error) {
+	blackout, err := s.getBlackout(blackoutID)
+	if err != nil {
+		return nil, err
+	}
+	if blackout.BusOwnerID != busOwnerID {
+		return nil, ErrBlackoutNotFound
+	}
+
+	lifted, err := s.repo.Lift(blackoutID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !lifted {
+		return nil, ErrBlackoutNotActive
+	}
+
+	s.logger.WithField("blackout_id", blackoutID).Info("Online booking blackout lifted by owner")
+	return s.getBlackout(blackoutID)
+}
+
+// ============================================================================
+// ADMIN
+// ============================================================================
+
+// ListBlackouts returns blackouts across all owners, optionally filtered by status
+func (s *BookingBlackoutService) ListBlackouts(status string, limit, offset int) ([]models.BookingBlackout, int, error) {
+	return s.repo.List(status, limit, offset)
+}
+
+// OverrideBlackout reopens online sales for a blacked out route or schedule on an admin's
+// authority. The blackout is kept, marked overridden, with who did it and why.
+func (s *BookingBlackoutService) OverrideBlackout(blackoutID string, adminUserID uuid.UUID, reason string) (*models.BookingBlackout, error) {
+	if _, err := s.getBlackout(blackoutID); err != nil {
+		return nil, err
+	}
+
+	overridden, err := s.repo.Override(blackoutID, adminUserID.String(), strings.TrimSpace(reason))
+	if err != nil {
+		return nil, err
+	}
+	if !overridden {
+		return nil, ErrBlackoutNotActive
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"blackout_id":   blackoutID,
+		"admin_user_id": adminUserID,
+	}).Warn("Online booking blackout overridden by admin")
+	return s.getBlackout(blackoutID)
+}
+
+// ============================================================================
+// BOOKING CHECK
+// ============================================================================
+
+// CheckTripOpen returns ErrTripBlackedOut, with the owner's reason, if online sales are
+// paused for a trip. Used before holding seats; existing bookings are unaffected.
+func (s *BookingBlackoutService) CheckTripOpen(scheduledTripID string) error {
+	blackout, err := s.repo.GetActiveForTrip(scheduledTripID)
+	if err != nil {
+		return err
+	}
+	if blackout != nil {
+		return fmt.Errorf("%w: %s", ErrTripBlackedOut, blackout.Reason)
+	}
+	return nil
+}
+
+func (s *BookingBlackoutService) getBlackout(blackoutID string) (*models.BookingBlackout, error) {
+	if _, err := uuid.Parse(blackoutID); err != nil {
+		return nil, ErrBlackoutNotFound
+	}
+	blackout, err := s.repo.GetByID(blackoutID)
+	if err != nil {
+		return nil, err
+	}
+	if blackout == nil {
+		return nil, ErrBlackoutNotFound
+	}
+	return blackout, nil
+}
+
+// checkTargetOwner verifies the blacked out route or schedule belongs to the owner
+func (s *BookingBlackoutService) checkTargetOwner(busOwnerID string, req *models.CreateBookingBlackoutRequest) error {
+	var ownerID string
+	if id := nonEmpty(req.BusOwnerRouteID); id != nil {
+		route, err := s.routeRepo.GetByID(*id)
+		if err == sql.ErrNoRows {
+			return ErrBlackoutTargetNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get route: %w", err)
+		}
+		ownerID = route.BusOwnerID
+	} else {
+		schedule, err := s.scheduleRepo.GetByID(*req.TripScheduleID)
+		if err == sql.ErrNoRows {
+			return ErrBlackoutTargetNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get schedule: %w", err)
+		}
+		ownerID = schedule.BusOwnerID
+	}
+	if ownerID != busOwnerID {
+		return ErrBlackoutTargetNotFound
+	}
+	return nil
+}
+
+// nonEmpty returns nil for a missing or empty optional ID
+func nonEmpty(id *string) *string {
+	if id == nil || *id == "" {
+		return nil
+	}
+	return id
+}
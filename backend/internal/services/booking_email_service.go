@@ -0,0 +1,369 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/skip2/go-qrcode"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/email"
+	"github.com/smarttransit/sms-auth-backend/pkg/events"
+	"github.com/smarttransit/sms-auth-backend/pkg/pdf"
+)
+
+// Booking email types, recorded per outbox event so each goes out once
+const (
+	bookingEmailConfirmation = "confirmation"
+	bookingEmailCancellation = "cancellation"
+)
+
+// ErrNoBookingEmail is returned when a receipt is requested for a passenger with no email address
+var ErrNoBookingEmail = errors.New("no email address on the booking or passenger profile")
+
+// BookingEmailService emails passengers their booking confirmation with a PDF e-ticket,
+// cancellation notices and receipts. Confirmations and cancellations are driven by
+// booking.confirmed and booking.cancelled from the outbox.
+type BookingEmailService struct {
+	emailRepo     *database.BookingEmailRepository
+	bookingRepo   *database.AppBookingRepository
+	passengerRepo *database.PassengerRepository
+	sender        email.Sender
+	currency      string
+	logger        *logrus.Logger
+}
+
+// NewBookingEmailService creates a new BookingEmailService
+func NewBookingEmailService(
+	emailRepo *database.BookingEmailRepository,
+	bookingRepo *database.AppBookingRepository,
+	passengerRepo *database.PassengerRepository,
+	sender email.Sender,
+	currency string,
+	logger *logrus.Logger,
+) *BookingEmailService {
+	return &BookingEmailService{
+		emailRepo:     emailRepo,
+		bookingRepo:   bookingRepo,
+		passengerRepo: passengerRepo,
+		sender:        sender,
+		currency:      currency,
+		logger:        logger,
+	}
+}
+
+// Register subscribes the emails to the dispatcher, after the reminder and snapshot
+// consumers. Each email is claimed before it is sent, so a redelivered event is not emailed
+// again; a failed send is released and retried with the event.
+func (s *BookingEmailService) Register(dispatcher *OutboxDispatcher) {
+	dispatcher.Subscribe("booking_confirmation_email", s.sendConfirmation, models.EventBookingConfirmed)
+	dispatcher.Subscribe("booking_cancellation_email", s.sendCancellation, models.EventBookingCancelled)
+}
+
+// sendConfirmation emails the confirmation with the e-ticket attached
+func (s *BookingEmailService) sendConfirmation(event events.Event) error {
+	data, err := decodeBookingEvent(event)
+	if err != nil {
+		return err
+	}
+	if data.BookingID == "" {
+		return nil
+	}
+	booking, err := s.bookingRepo.GetBookingByID(data.BookingID)
+	if err != nil {
+		return fmt.Errorf("failed to load booking for confirmation email: %w", err)
+	}
+
+	view := s.view(booking)
+	return s.sendOnce(event.ID, bookingEmailConfirmation, booking, func(to string) (*email.Message, error) {
+		return bookingEmail(to, "Booking confirmed – "+booking.BookingReference, confirmationEmailTemplate, view, eTicketPDF(booking, view))
+	})
+}
+
+// sendCancellation emails the cancellation notice with the refund due
+func (s *BookingEmailService) sendCancellation(event events.Event) error {
+	data, err := decodeBookingEvent(event)
+	if err != nil {
+		return err
+	}
+	if data.BookingID == "" {
+		return nil
+	}
+	booking, err := s.bookingRepo.GetBookingByID(data.BookingID)
+	if err != nil {
+		return fmt.Errorf("failed to load booking for cancellation email: %w", err)
+	}
+
+	view := s.view(booking)
+	view.Refund = formatAmount(view.Currency, data.RefundAmount)
+	if data.CancellationFee > 0 {
+		view.CancellationFee = formatAmount(view.Currency, data.CancellationFee)
+	}
+	if data.Reason != nil {
+		view.Reason = *data.Reason
+	}
+	return s.sendOnce(event.ID, bookingEmailCancellation, booking, func(to string) (*email.Message, error) {
+		return bookingEmail(to, "Booking cancelled – "+booking.BookingReference, cancellationEmailTemplate, view, nil)
+	})
+}
+
+// SendReceipt emails the receipt for a booking, with the e-ticket attached while the booking
+// is still valid, and returns the address it was sent to
+func (s *BookingEmailService) SendReceipt(booking *models.MasterBooking) (string, error) {
+	to, err := s.recipient(booking)
+	if err != nil {
+		return "", err
+	}
+	if to == "" {
+		return "", ErrNoBookingEmail
+	}
+
+	view := s.view(booking)
+	var ticket *email.Attachment
+	if booking.BookingStatus != models.MasterBookingCancelled {
+		ticket = eTicketPDF(booking, view)
+	}
+	msg, err := bookingEmail(to, "Receipt – "+booking.BookingReference, receiptEmailTemplate, view, ticket)
+	if err != nil {
+		return "", err
+	}
+	if err := s.sender.Send(*msg); err != nil {
+		return "", fmt.Errorf("failed to send receipt email: %w", err)
+	}
+	return to, nil
+}
+
+// sendOnce claims the event's email, then builds and sends it. Passengers without an email
+// address are skipped.
+func (s *BookingEmailService) sendOnce(eventID, emailType string, booking *models.MasterBooking, build func(to string) (*email.Message, error)) error {
+	to, err := s.recipient(booking)
+	if err != nil || to == "" {
+		return err
+	}
+
+	claimed, err := s.emailRepo.Claim(eventID, emailType, booking.ID, to)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil // Sent on an earlier delivery
+	}
+
+	msg, err := build(to)
+	if err == nil {
+		err = s.sender.Send(*msg)
+	}
+	if err != nil {
+		if releaseErr := s.emailRepo.Release(eventID, emailType); releaseErr != nil {
+			s.logger.WithError(releaseErr).WithField("booking_id", booking.ID).Error("Failed to release booking email claim")
+		}
+		return fmt.Errorf("failed to send %s email: %w", emailType, err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"booking_id": booking.ID,
+		"type":       emailType,
+		"provider":   s.sender.GetName(),
+	}).Info("📧 Booking email sent")
+	return nil
+}
+
+// recipient is the address given with the booking, else the one on the passenger's profile
+func (s *BookingEmailService) recipient(booking *models.MasterBooking) (string, error) {
+	if booking.PassengerEmail != nil && strings.TrimSpace(*booking.PassengerEmail) != "" {
+		return strings.TrimSpace(*booking.PassengerEmail), nil
+	}
+	userID, err := uuid.Parse(booking.UserID)
+	if err != nil {
+		return "", nil
+	}
+	passenger, err := s.passengerRepo.GetPassengerByUserID(userID)
+	if err != nil {
+		return "", err
+	}
+	if passenger == nil || !passenger.Email.Valid {
+		return "", nil
+	}
+	return strings.TrimSpace(passenger.Email.String), nil
+}
+
+// ============================================================================
+// MESSAGES
+// ============================================================================
+
+// bookingEmailView is what the email templates and the e-ticket are filled in from
+type bookingEmailView struct {
+	Name             string
+	Reference        string
+	Currency         string
+	Trip             []string // One line each: route, departure, bus, seats, lounges
+	Total            string
+	Discount         string
+	PaymentMethod    string
+	PaymentReference string
+	PaidAt           string
+	Refund           string
+	CancellationFee  string
+	Reason           string
+}
+
+var (
+	confirmationEmailTemplate = template.Must(template.New("confirmation").Parse(`Hello {{.Name}},
+
+Your booking {{.Reference}} is confirmed.
+{{range .Trip}}
+  {{.}}{{end}}
+
+Total paid: {{.Total}}{{with .PaymentReference}} (payment reference {{.}}){{end}}
+
+Your e-ticket is attached. Show its QR code to the conductor when you board.
+
+— SmartTransit
+`))
+
+	cancellationEmailTemplate = template.Must(template.New("cancellation").Parse(`Hello {{.Name}},
+
+Your booking {{.Reference}} has been cancelled.{{with .Reason}}
+Reason: {{.}}{{end}}
+{{range .Trip}}
+  {{.}}{{end}}
+
+Amount paid: {{.Total}}{{with .CancellationFee}}
+Cancellation fee: {{.}}{{end}}
+Refund due: {{.Refund}}
+
+Refunds are returned to the original payment method.
+
+— SmartTransit
+`))
+
+	receiptEmailTemplate = template.Must(template.New("receipt").Parse(`Hello {{.Name}},
+
+Here is the receipt for booking {{.Reference}}.
+{{range .Trip}}
+  {{.}}{{end}}
+{{with .Discount}}
+Discount: {{.}}{{end}}
+Total: {{.Total}}{{with .PaymentMethod}}
+Paid by: {{.}}{{end}}{{with .PaymentReference}}
+Payment reference: {{.}}{{end}}{{with .PaidAt}}
+Paid at: {{.}}{{end}}{{with .Refund}}
+Refunded: {{.}}{{end}}
+
+— SmartTransit
+`))
+)
+
+// view summarises a booking for its emails
+func (s *BookingEmailService) view(booking *models.MasterBooking) bookingEmailView {
+	view := bookingEmailView{
+		Name:      booking.PassengerName,
+		Reference: booking.BookingReference,
+		Currency:  s.currency,
+		Trip:      bookingTripLines(booking),
+	}
+	view.Total = formatAmount(view.Currency, booking.TotalAmount)
+	if booking.DiscountAmount > 0 {
+		view.Discount = formatAmount(view.Currency, booking.DiscountAmount)
+	}
+	if booking.PaymentMethod != nil {
+		view.PaymentMethod = *booking.PaymentMethod
+	}
+	if booking.PaymentReference != nil {
+		view.PaymentReference = *booking.PaymentReference
+	}
+	if booking.PaidAt != nil {
+		view.PaidAt = booking.PaidAt.In(models.ReportTimezone).Format("Jan 2 2006 15:04")
+	}
+	if booking.RefundAmount > 0 {
+		view.Refund = formatAmount(view.Currency, booking.RefundAmount)
+	}
+	return view
+}
+
+// bookingTripLines describes the bus trip and lounge visits of a booking
+func bookingTripLines(booking *models.MasterBooking) []string {
+	var lines []string
+	if bus := booking.BusBooking; bus != nil {
+		switch {
+		case bus.BoardingStopName != "" && bus.AlightingStopName != "":
+			lines = append(lines, "Trip: "+bus.BoardingStopName+" to "+bus.AlightingStopName)
+		case bus.RouteName != "":
+			lines = append(lines, "Route: "+bus.RouteName)
+		}
+		if bus.DepartureDatetime != nil {
+			lines = append(lines, "Departs: "+bus.DepartureDatetime.In(models.ReportTimezone).Format("Mon Jan 2 2006 15:04"))
+		}
+		if bus.BusNumber != "" {
+			lines = append(lines, "Bus: "+bus.BusNumber)
+		}
+		var seats []string
+		for _, seat := range bus.Seats {
+			if seat.SeatNumber != "" && seat.Status != models.SeatBookingCancelled {
+				seats = append(seats, seat.SeatNumber)
+			}
+		}
+		if len(seats) > 0 {
+			lines = append(lines, "Seats: "+strings.Join(seats, ", "))
+		} else {
+			lines = append(lines, fmt.Sprintf("Seats: %d", bus.NumberOfSeats))
+		}
+	}
+	for _, lounge := range booking.LoungeBookings {
+		lines = append(lines, fmt.Sprintf("Lounge: %s, %s, %d guest(s)",
+			lounge.LoungeName, lounge.ScheduledArrival.In(models.ReportTimezone).Format("Jan 2 15:04"), lounge.NumberOfGuests))
+	}
+	return lines
+}
+
+// bookingEmail renders a booking email from its template
+func bookingEmail(to, subject string, tmpl *template.Template, view bookingEmailView, ticket *email.Attachment) (*email.Message, error) {
+	var body strings.Builder
+	if err := tmpl.Execute(&body, view); err != nil {
+		return nil, fmt.Errorf("failed to render %s email: %w", tmpl.Name(), err)
+	}
+	msg := &email.Message{To: to, Subject: subject, Body: body.String()}
+	if ticket != nil {
+		msg.Attachments = []email.Attachment{*ticket}
+	}
+	return msg, nil
+}
+
+// eTicketPDF renders the booking's e-ticket, with the boarding QR when the bus booking has one
+func eTicketPDF(booking *models.MasterBooking, view bookingEmailView) *email.Attachment {
+	doc := pdf.NewDocument()
+	doc.Heading("SmartTransit e-ticket")
+	doc.Linef("Booking reference: %s", view.Reference)
+	doc.Linef("Passenger: %s", view.Name)
+	doc.Line("")
+	for _, line := range view.Trip {
+		doc.Line(line)
+	}
+	doc.Line("")
+	doc.Linef("Total paid: %s", view.Total)
+	if view.PaymentReference != "" {
+		doc.Linef("Payment reference: %s", view.PaymentReference)
+	}
+
+	if bus := booking.BusBooking; bus != nil && bus.QRCodeData != nil && *bus.QRCodeData != "" {
+		if qr, err := qrcode.New(*bus.QRCodeData, qrcode.Medium); err == nil {
+			doc.Line("")
+			doc.Image(qr.Image(256), 180)
+			doc.Line("Show this QR code to the conductor when you board.")
+		}
+	}
+
+	return &email.Attachment{
+		Filename:    fmt.Sprintf("e-ticket-%s.pdf", booking.BookingReference),
+		ContentType: "application/pdf",
+		Data:        doc.Bytes(),
+	}
+}
+
+func formatAmount(currency string, amount float64) string {
+	return fmt.Sprintf("%s %.2f", currency, amount)
+}
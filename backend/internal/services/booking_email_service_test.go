@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEmailSender struct {
+	sent []email.Message
+}
+
+func (s *recordingEmailSender) Send(msg email.Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func (s *recordingEmailSender) GetName() string { return "recording" }
+
+func testEmailBooking() *models.MasterBooking {
+	departure := time.Date(2026, 3, 14, 3, 0, 0, 0, time.UTC)
+	address := " rider@example.com "
+	paymentRef := "PAY-778"
+	qr := "BK-2026-0001|sig"
+	return &models.MasterBooking{
+		ID:               "booking-1",
+		BookingReference: "BK-2026-0001",
+		UserID:           "5f0c2a80-4c1b-4c52-9a8e-2a4f6c8f1d11",
+		BookingStatus:    models.MasterBookingConfirmed,
+		PassengerName:    "Nimal",
+		PassengerEmail:   &address,
+		TotalAmount:      1250,
+		PaymentReference: &paymentRef,
+		BusBooking: &models.BusBooking{
+			NumberOfSeats:     2,
+			BoardingStopName:  "Colombo Fort",
+			AlightingStopName: "Kandy",
+			BusNumber:         "NC-4521",
+			DepartureDatetime: &departure,
+			QRCodeData:        &qr,
+			Seats: []models.BusBookingSeat{
+				{SeatNumber: "A1", Status: models.SeatBookingBooked},
+				{SeatNumber: "A2", Status: models.SeatBookingCancelled},
+			},
+		},
+	}
+}
+
+func TestBookingTripLines(t *testing.T) {
+	assert.Equal(t, []string{
+		"Trip: Colombo Fort to Kandy",
+		"Departs: Sat Mar 14 2026 08:30",
+		"Bus: NC-4521",
+		"Seats: A1",
+	}, bookingTripLines(testEmailBooking()))
+}
+
+func TestBookingEmail_ConfirmationAttachesETicket(t *testing.T) {
+	booking := testEmailBooking()
+	service := NewBookingEmailService(nil, nil, nil, &recordingEmailSender{}, "LKR", logrus.New())
+	view := service.view(booking)
+
+	msg, err := bookingEmail("rider@example.com", "Booking confirmed", confirmationEmailTemplate, view, eTicketPDF(booking, view))
+	require.NoError(t, err)
+	assert.Contains(t, msg.Body, "Your booking BK-2026-0001 is confirmed.")
+	assert.Contains(t, msg.Body, "Total paid: LKR 1250.00 (payment reference PAY-778)")
+
+	require.Len(t, msg.Attachments, 1)
+	ticket := msg.Attachments[0]
+	assert.Equal(t, "e-ticket-BK-2026-0001.pdf", ticket.Filename)
+	assert.True(t, bytes.HasPrefix(ticket.Data, []byte("%PDF-")))
+	assert.Contains(t, string(ticket.Data), "/Subtype /Image", "boarding QR is drawn on the ticket")
+}
+
+func TestBookingEmail_Cancellation(t *testing.T) {
+	service := NewBookingEmailService(nil, nil, nil, &recordingEmailSender{}, "LKR", logrus.New())
+	view := service.view(testEmailBooking())
+	view.Refund = "LKR 1000.00"
+	view.CancellationFee = "LKR 250.00"
+	view.Reason = "Change of plans"
+
+	msg, err := bookingEmail("rider@example.com", "Booking cancelled", cancellationEmailTemplate, view, nil)
+	require.NoError(t, err)
+	assert.Contains(t, msg.Body, "Reason: Change of plans")
+	assert.Contains(t, msg.Body, "Cancellation fee: LKR 250.00\nRefund due: LKR 1000.00")
+	assert.Empty(t, msg.Attachments)
+}
+
+func TestBookingEmailService_SendReceipt(t *testing.T) {
+	sender := &recordingEmailSender{}
+	service := NewBookingEmailService(nil, nil, nil, sender, "LKR", logrus.New())
+
+	to, err := service.SendReceipt(testEmailBooking())
+	require.NoError(t, err)
+	assert.Equal(t, "rider@example.com", to)
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, "Receipt – BK-2026-0001", sender.sent[0].Subject)
+	assert.Contains(t, sender.sent[0].Body, "Payment reference: PAY-778")
+	assert.Len(t, sender.sent[0].Attachments, 1)
+
+	cancelled := testEmailBooking()
+	cancelled.BookingStatus = models.MasterBookingCancelled
+	cancelled.RefundAmount = 1000
+	_, err = service.SendReceipt(cancelled)
+	require.NoError(t, err)
+	assert.Contains(t, sender.sent[1].Body, "Refunded: LKR 1000.00")
+	assert.Empty(t, sender.sent[1].Attachments, "no e-ticket for a cancelled booking")
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/events"
+)
+
+// BookingEventConsumers are the follow-up work driven by booking.confirmed and
+// booking.cancelled from the outbox: boarding reminders and the booking snapshot kept for
+// receipts and disputes. Each is safe to run again for the same event.
+type BookingEventConsumers struct {
+	scheduledTripRepo *database.ScheduledTripRepository
+	reminders         *ReminderSchedulerService
+	snapshots         *BookingSnapshotService
+	logger            *logrus.Logger
+}
+
+// NewBookingEventConsumers creates a new BookingEventConsumers
+func NewBookingEventConsumers(
+	scheduledTripRepo *database.ScheduledTripRepository,
+	reminders *ReminderSchedulerService,
+	snapshots *BookingSnapshotService,
+	logger *logrus.Logger,
+) *BookingEventConsumers {
+	return &BookingEventConsumers{
+		scheduledTripRepo: scheduledTripRepo,
+		reminders:         reminders,
+		snapshots:         snapshots,
+		logger:            logger,
+	}
+}
+
+// Register subscribes the consumers to the dispatcher
+func (c *BookingEventConsumers) Register(dispatcher *OutboxDispatcher) {
+	dispatcher.Subscribe("boarding_reminders", c.scheduleReminders, models.EventBookingConfirmed)
+	dispatcher.Subscribe("booking_snapshot", c.captureSnapshot, models.EventBookingConfirmed)
+	dispatcher.Subscribe("cancel_boarding_reminders", c.cancelReminders, models.EventBookingCancelled)
+}
+
+// scheduleReminders queues boarding reminders for a confirmed bus booking. Reminders already
+// queued for the booking are left as they are.
+func (c *BookingEventConsumers) scheduleReminders(event events.Event) error {
+	data, err := decodeBookingEvent(event)
+	if err != nil {
+		return err
+	}
+	if data.BookingID == "" || data.ScheduledTripID == "" {
+		return nil // Lounge-only booking
+	}
+
+	trip, err := c.scheduledTripRepo.GetByID(data.ScheduledTripID)
+	if err != nil {
+		return fmt.Errorf("failed to load trip for boarding reminders: %w", err)
+	}
+	return c.reminders.ScheduleForBooking(data.BookingID, data.UserID, trip.ID, trip.DepartureDatetime)
+}
+
+// captureSnapshot snapshots a confirmed booking as it was bought; a booking already
+// snapshotted keeps its original
+func (c *BookingEventConsumers) captureSnapshot(event events.Event) error {
+	data, err := decodeBookingEvent(event)
+	if err != nil {
+		return err
+	}
+	if data.BookingID == "" {
+		return nil
+	}
+	return c.snapshots.Capture(data.BookingID)
+}
+
+// cancelReminders de-schedules the pending boarding reminders of a cancelled booking
+func (c *BookingEventConsumers) cancelReminders(event events.Event) error {
+	data, err := decodeBookingEvent(event)
+	if err != nil {
+		return err
+	}
+	return c.reminders.CancelForBooking(data.BookingID)
+}
+
+func decodeBookingEvent(event events.Event) (*models.BookingEventData, error) {
+	var data models.BookingEventData
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %w", event.Type, err)
+	}
+	return &data, nil
+}
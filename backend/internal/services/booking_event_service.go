@@ -0,0 +1,55 @@
+package services
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/events"
+)
+
+// BookingEventService publishes intent lifecycle events for internal consumers. Publishing
+// is best effort: failures are logged and never fail the booking flow. A nil service is a
+// no-op so callers need no checks. booking.confirmed and booking.cancelled are not published
+// here but saved to the outbox with the change, and delivered by OutboxDispatcher.
+type BookingEventService struct {
+	publisher events.Publisher
+	logger    *logrus.Logger
+}
+
+// NewBookingEventService creates a new BookingEventService
+func NewBookingEventService(publisher events.Publisher, logger *logrus.Logger) *BookingEventService {
+	return &BookingEventService{
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// IntentCreated publishes intent.created
+func (s *BookingEventService) IntentCreated(intent *models.BookingIntent) {
+	if s == nil || intent == nil {
+		return
+	}
+	s.publish(models.EventIntentCreated, models.NewIntentEventData(intent))
+}
+
+// IntentExpired publishes intent.expired
+func (s *BookingEventService) IntentExpired(intent *models.BookingIntent) {
+	if s == nil || intent == nil {
+		return
+	}
+	s.publish(models.EventIntentExpired, models.NewIntentEventData(intent))
+}
+
+func (s *BookingEventService) publish(eventType string, data interface{}) {
+	event, err := events.NewEvent(eventType, data)
+	if err != nil {
+		s.logger.WithError(err).WithField("event_type", eventType).Error("Failed to build booking event")
+		return
+	}
+	if err := s.publisher.Publish(event); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"event_type": eventType,
+			"event_id":   event.ID,
+			"publisher":  s.publisher.GetName(),
+		}).Warn("Failed to publish booking event")
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	events []events.Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(event events.Event) error {
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func (p *recordingPublisher) GetName() string { return "recording" }
+
+func TestBookingEventService_IntentCreated(t *testing.T) {
+	publisher := &recordingPublisher{}
+	svc := NewBookingEventService(publisher, logrus.New())
+
+	intent := &models.BookingIntent{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		IntentType:  models.IntentTypeBusOnly,
+		TotalAmount: 1500,
+		Currency:    "LKR",
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+		BusIntent: &models.BusIntentPayload{
+			ScheduledTripID: "trip-1",
+			Seats:           []models.BusIntentSeat{{}, {}},
+			PassengerPhone:  "+94771234567",
+		},
+	}
+	svc.IntentCreated(intent)
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, models.EventIntentCreated, publisher.events[0].Type)
+
+	var data models.IntentEventData
+	require.NoError(t, json.Unmarshal(publisher.events[0].Data, &data))
+	assert.Equal(t, intent.ID.String(), data.IntentID)
+	assert.Equal(t, "trip-1", data.ScheduledTripID)
+	assert.Equal(t, 2, data.SeatCount)
+	assert.NotContains(t, string(publisher.events[0].Data), "771234567", "no passenger contact details")
+}
+
+func TestBookingEventService_BestEffort(t *testing.T) {
+	publisher := &recordingPublisher{err: events.ErrBusFull}
+	svc := NewBookingEventService(publisher, logrus.New())
+	svc.IntentExpired(&models.BookingIntent{})
+	assert.Len(t, publisher.events, 1)
+
+	var nilSvc *BookingEventService
+	nilSvc.IntentExpired(&models.BookingIntent{})
+	nilSvc.IntentCreated(&models.BookingIntent{})
+}
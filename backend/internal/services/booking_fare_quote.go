@@ -0,0 +1,214 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrTripNotBookable         = errors.New("trip is not available for booking")
+	ErrTripDeparted            = errors.New("trip has already departed")
+	ErrFareQuoteTripNotFound   = errors.New("scheduled trip not found")
+	ErrInvalidFareQuoteSeats   = errors.New("seats must be a seat count or a comma-separated list of trip seat IDs")
+	ErrFareQuoteSeatsNotFound  = errors.New("one or more seats do not belong to this trip")
+	ErrInvalidFareQuoteStops   = errors.New("boarding and alighting stops must be stops of this trip, in travel order")
+	ErrFareQuoteNotEnoughSeats = errors.New("not enough seats are available")
+)
+
+// maxFareQuoteSeats caps how many seats one quote prices; the endpoint is public
+const maxFareQuoteSeats = 10
+
+// checkTripBookable reports whether a trip still takes bookings
+func checkTripBookable(trip *models.ScheduledTrip, now time.Time) error {
+	if trip.Status != models.ScheduledTripStatusScheduled && trip.Status != models.ScheduledTripStatusConfirmed {
+		return fmt.Errorf("%w (status: %s)", ErrTripNotBookable, trip.Status)
+	}
+	if trip.DepartureDatetime.Before(now) {
+		return ErrTripDeparted
+	}
+	return nil
+}
+
+// busSeatFare is the fare charged for one trip seat, after the trip's active price adjustment
+// (nil for none). Intents and fare quotes both price seats with it, so a quote matches what
+// CreateIntent will hold.
+func busSeatFare(seat models.TripSeat, adjustment *models.TripPriceAdjustment) float64 {
+	return adjustment.Apply(seat.SeatPrice)
+}
+
+// parseFareQuoteSeats reads the seats parameter: a seat count, or trip seat IDs
+func parseFareQuoteSeats(value string) (int, []string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 1, nil, nil
+	}
+	if count, err := strconv.Atoi(value); err == nil {
+		if count < 1 || count > maxFareQuoteSeats {
+			return 0, nil, fmt.Errorf("%w (1 to %d seats)", ErrInvalidFareQuoteSeats, maxFareQuoteSeats)
+		}
+		return count, nil, nil
+	}
+
+	seen := make(map[string]bool)
+	ids := []string{}
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > maxFareQuoteSeats {
+		return 0, nil, fmt.Errorf("%w (1 to %d seats)", ErrInvalidFareQuoteSeats, maxFareQuoteSeats)
+	}
+	return len(ids), ids, nil
+}
+
+// ============================================================================
+// FARE QUOTE
+// ============================================================================
+
+// QuoteBusFare previews the bus fare for a trip without creating an intent or holding seats.
+// With a seat count the first available seats in seat map order (optionally of one seat type)
+// are priced; with trip seat IDs exactly those seats are priced and flagged if already taken.
+func (s *BookingOrchestratorService) QuoteBusFare(tripID string, req *models.FareQuoteRequest) (*models.FareQuote, error) {
+	count, seatIDs, err := parseFareQuoteSeats(req.Seats)
+	if err != nil {
+		return nil, err
+	}
+
+	trip, err := s.scheduledTripRepo.GetByID(tripID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFareQuoteTripNotFound
+		}
+		return nil, fmt.Errorf("failed to get scheduled trip: %w", err)
+	}
+	if trip == nil || !trip.IsBookable {
+		return nil, ErrFareQuoteTripNotFound
+	}
+	now := time.Now()
+	if err := checkTripBookable(trip, now); err != nil {
+		return nil, err
+	}
+
+	quote := &models.FareQuote{
+		ScheduledTripID:   trip.ID,
+		DepartureDatetime: trip.DepartureDatetime,
+		Seats:             []models.FareQuoteSeat{},
+		QuotedAt:          now,
+	}
+	if req.BoardingStopID != "" || req.AlightingStopID != "" {
+		if quote.BoardingStop, quote.AlightingStop, err = s.fareQuoteStops(trip, req.BoardingStopID, req.AlightingStopID); err != nil {
+			return nil, err
+		}
+	}
+
+	var seats []models.TripSeat
+	if seatIDs != nil {
+		if seats, err = s.tripSeatRepo.GetByIDs(seatIDs); err != nil {
+			return nil, fmt.Errorf("failed to get seat details: %w", err)
+		}
+		if len(seats) != len(seatIDs) {
+			return nil, ErrFareQuoteSeatsNotFound
+		}
+		for _, seat := range seats {
+			if seat.ScheduledTripID != trip.ID {
+				return nil, ErrFareQuoteSeatsNotFound
+			}
+		}
+	} else {
+		available, err := s.tripSeatRepo.GetAvailableSeats(trip.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get available seats: %w", err)
+		}
+		for _, seat := range available {
+			if req.SeatType == "" || strings.EqualFold(seat.SeatType, req.SeatType) {
+				seats = append(seats, seat)
+			}
+		}
+	}
+
+	// Seats held by live intents can't be booked either
+	ids := make([]string, len(seats))
+	for i, seat := range seats {
+		ids[i] = seat.ID
+	}
+	holdable, _, err := s.intentRepo.CheckSeatsAvailableForHold(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check seat availability: %w", err)
+	}
+	canHold := make(map[string]bool, len(holdable))
+	for _, id := range holdable {
+		canHold[id] = true
+	}
+
+	adjustment, err := s.priceAdjustments.ActiveForTrip(trip.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip price adjustment: %w", err)
+	}
+
+	var adjustedBy float64
+	quote.AllAvailable = true
+	for _, seat := range seats {
+		if seatIDs == nil && !canHold[seat.ID] {
+			continue
+		}
+		if len(quote.Seats) == count {
+			break
+		}
+		quote.Seats = append(quote.Seats, models.FareQuoteSeat{
+			TripSeatID: seat.ID,
+			SeatNumber: seat.SeatNumber,
+			SeatType:   seat.SeatType,
+			SeatPrice:  busSeatFare(seat, adjustment),
+			Available:  canHold[seat.ID],
+		})
+		quote.PriceBreakdown.BusFare += busSeatFare(seat, adjustment)
+		adjustedBy += busSeatFare(seat, adjustment) - seat.SeatPrice
+		quote.AllAvailable = quote.AllAvailable && canHold[seat.ID]
+	}
+	if len(quote.Seats) < count {
+		return nil, ErrFareQuoteNotEnoughSeats
+	}
+
+	quote.SeatCount = len(quote.Seats)
+	quote.PriceAdjustment = adjustment.Applied(adjustedBy)
+	quote.PriceBreakdown.Total = quote.PriceBreakdown.BusFare
+	quote.PriceBreakdown.Currency = s.config.DefaultCurrency
+	return quote, nil
+}
+
+// fareQuoteStops resolves the boarding and alighting stops against the trip's route
+func (s *BookingOrchestratorService) fareQuoteStops(trip *models.ScheduledTrip, boardingStopID, alightingStopID string) (*models.FareQuoteStop, *models.FareQuoteStop, error) {
+	if trip.BusOwnerRouteID == nil {
+		return nil, nil, ErrInvalidFareQuoteStops
+	}
+	route, err := s.busOwnerRouteRepo.GetByID(*trip.BusOwnerRouteID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get route: %w", err)
+	}
+	routeStops, err := s.busOwnerRouteRepo.GetRouteStopsWithDetails(route.MasterRouteID, route.SelectedStopIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get route stops: %w", err)
+	}
+
+	stops := make(map[string]*models.FareQuoteStop, len(routeStops))
+	for _, stop := range routeStops {
+		stops[stop.ID] = &models.FareQuoteStop{StopID: stop.ID, StopName: stop.StopName, StopOrder: stop.StopOrder}
+	}
+	boarding, boardingOK := stops[boardingStopID]
+	alighting, alightingOK := stops[alightingStopID]
+	if (boardingStopID != "" && !boardingOK) || (alightingStopID != "" && !alightingOK) {
+		return nil, nil, ErrInvalidFareQuoteStops
+	}
+	if boardingOK && alightingOK && boarding.StopOrder >= alighting.StopOrder {
+		return nil, nil, ErrInvalidFareQuoteStops
+	}
+	return boarding, alighting, nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFareQuoteSeats(t *testing.T) {
+	count, ids, err := parseFareQuoteSeats("")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count, "defaults to one seat")
+	assert.Nil(t, ids)
+
+	count, ids, err = parseFareQuoteSeats("3")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Nil(t, ids)
+
+	count, ids, err = parseFareQuoteSeats(" seat-a, seat-b,seat-a ,")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"seat-a", "seat-b"}, ids, "trimmed and de-duplicated")
+
+	for _, value := range []string{"0", "-1", "11", ",,"} {
+		_, _, err = parseFareQuoteSeats(value)
+		assert.ErrorIs(t, err, ErrInvalidFareQuoteSeats, value)
+	}
+}
+
+func TestCheckTripBookable(t *testing.T) {
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	trip := &models.ScheduledTrip{Status: models.ScheduledTripStatusScheduled, DepartureDatetime: now.Add(time.Hour)}
+	assert.NoError(t, checkTripBookable(trip, now))
+
+	trip.DepartureDatetime = now.Add(-time.Minute)
+	assert.ErrorIs(t, checkTripBookable(trip, now), ErrTripDeparted)
+
+	trip.Status = models.ScheduledTripStatusCancelled
+	err := checkTripBookable(trip, now)
+	assert.ErrorIs(t, err, ErrTripNotBookable)
+	assert.Equal(t, "trip is not available for booking (status: cancelled)", err.Error())
+}
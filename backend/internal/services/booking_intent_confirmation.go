@@ -0,0 +1,234 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// intentConfirmationStore keeps a claimed confirmation's progress on its intent
+type intentConfirmationStore interface {
+	RecordIntentBookings(intentID uuid.UUID, busBookingID, preLoungeBookingID, postLoungeBookingID *uuid.UUID) error
+	UpdateIntentConfirmed(intentID uuid.UUID, busBookingID, preLoungeBookingID, postLoungeBookingID *uuid.UUID, event *models.OutboxEvent) error
+	ReleaseIntentConfirmation(intentID uuid.UUID, status models.BookingIntentStatus) error
+}
+
+// intentBookingCreator creates the bookings an intent stands for, and looks up the ones an
+// earlier attempt at the confirmation created
+type intentBookingCreator interface {
+	createBusBookingFromIntent(intent *models.BookingIntent) (*models.BusBooking, string, *uuid.UUID, error)
+	createLoungeBookingFromIntent(intent *models.BookingIntent, loungeIntent *models.LoungeIntentPayload, bookingType string, promoDiscount models.Money, masterBookingID, busBookingID *uuid.UUID) (*models.LoungeBooking, error)
+	existingBusBooking(busBookingID uuid.UUID) (string, *uuid.UUID, error)
+	existingLoungeBooking(loungeBookingID uuid.UUID) (*models.LoungeBooking, error)
+}
+
+// intentBookings are the bookings a confirmation made for an intent
+type intentBookings struct {
+	masterRef           string
+	masterBookingID     *uuid.UUID
+	busBookingID        *uuid.UUID
+	preLoungeBookingID  *uuid.UUID
+	postLoungeBookingID *uuid.UUID
+}
+
+// intentConfirmer confirms an intent claimed for confirmation. Each booking is recorded on the
+// intent as soon as it is created, and any failure returns the intent to the status it was
+// claimed from, so the confirmation can be retried (by the webhook, the app or payment
+// reconciliation) and the retry reuses the bookings already made instead of making them twice.
+type intentConfirmer struct {
+	store    intentConfirmationStore
+	bookings intentBookingCreator
+	logger   *logrus.Logger
+}
+
+func (s *BookingOrchestratorService) confirmer() *intentConfirmer {
+	return &intentConfirmer{store: s.intentRepo, bookings: s, logger: s.logger}
+}
+
+// confirm creates the intent's bookings and marks it confirmed. intent is as read before the
+// claim, so its status is the one the claim is released back to on failure.
+func (c *intentConfirmer) confirm(intent *models.BookingIntent) (*intentBookings, error) {
+	bookings, err := c.book(intent)
+	if err == nil {
+		err = c.markConfirmed(intent, bookings)
+	}
+	if err != nil {
+		if releaseErr := c.store.ReleaseIntentConfirmation(intent.ID, intent.Status); releaseErr != nil {
+			// Payment reconciliation requeues it once it has been confirming for a while
+			c.logger.WithError(releaseErr).WithField("intent_id", intent.ID).Error("Failed to release intent confirmation")
+		}
+		return nil, err
+	}
+	return bookings, nil
+}
+
+// book creates the intent's bookings, reusing the ones already recorded on it
+func (c *intentConfirmer) book(intent *models.BookingIntent) (*intentBookings, error) {
+	bookings := &intentBookings{
+		busBookingID:        intent.BusBookingID,
+		preLoungeBookingID:  intent.PreLoungeBookingID,
+		postLoungeBookingID: intent.PostLoungeBookingID,
+	}
+	var prePromoDiscount, postPromoDiscount models.Money
+	if promo := intent.PricingSnapshot.DiscountApplied; promo != nil {
+		prePromoDiscount, postPromoDiscount = promo.PreLoungeDiscount, promo.PostLoungeDiscount
+	}
+
+	// Create bus booking if present
+	if intent.BusIntent != nil {
+		if bookings.busBookingID != nil {
+			ref, masterID, err := c.bookings.existingBusBooking(*bookings.busBookingID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get bus booking: %w", err)
+			}
+			bookings.masterRef, bookings.masterBookingID = ref, masterID
+		} else {
+			busBooking, bookingRef, masterID, err := c.bookings.createBusBookingFromIntent(intent)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create bus booking: %w", err)
+			}
+			busBookingUUID, _ := uuid.Parse(busBooking.ID)
+			bookings.busBookingID = &busBookingUUID
+			bookings.masterRef = bookingRef
+			bookings.masterBookingID = masterID
+			if err := c.record(intent, bookings); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Create pre-trip lounge booking if present
+	if intent.PreTripLoungeIntent != nil {
+		// Determine booking type: standalone for lounge-only, pre_trip when with bus
+		loungeBookingType := "pre_trip"
+		if intent.IntentType == models.IntentTypeLoungeOnly {
+			loungeBookingType = "standalone"
+		}
+
+		preLoungeBooking, err := c.loungeBooking(intent, intent.PreTripLoungeIntent, bookings.preLoungeBookingID, loungeBookingType, prePromoDiscount, bookings)
+		if err != nil {
+			c.logger.WithFields(logrus.Fields{
+				"error":        err.Error(),
+				"intent_id":    intent.ID,
+				"lounge_id":    intent.PreTripLoungeIntent.LoungeID,
+				"booking_type": loungeBookingType,
+			}).Error("Failed to create lounge booking")
+
+			// For lounge_only intents, if lounge booking fails, the whole intent fails
+			if intent.IntentType == models.IntentTypeLoungeOnly {
+				return nil, fmt.Errorf("failed to create lounge booking: %w", err)
+			}
+			// For combined intents, continue - at least bus booking is created
+		} else {
+			id := preLoungeBooking.ID
+			bookings.preLoungeBookingID = &id
+			if bookings.masterRef == "" {
+				bookings.masterRef = preLoungeBooking.BookingReference
+			}
+			if err := c.record(intent, bookings); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		c.logger.WithField("intent_id", intent.ID).Info("No pre-trip lounge intent found - skipping lounge booking creation")
+	}
+
+	// Create post-trip lounge booking if present
+	if intent.PostTripLoungeIntent != nil {
+		postLoungeBooking, err := c.loungeBooking(intent, intent.PostTripLoungeIntent, bookings.postLoungeBookingID, "post_trip", postPromoDiscount, bookings)
+		if err != nil {
+			c.logger.WithFields(logrus.Fields{
+				"error":     err.Error(),
+				"intent_id": intent.ID,
+				"lounge_id": intent.PostTripLoungeIntent.LoungeID,
+			}).Error("Failed to create post-trip lounge booking")
+		} else {
+			id := postLoungeBooking.ID
+			bookings.postLoungeBookingID = &id
+			if bookings.masterRef == "" {
+				bookings.masterRef = postLoungeBooking.BookingReference
+			}
+			if err := c.record(intent, bookings); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return bookings, nil
+}
+
+// loungeBooking returns the lounge booking already recorded for the intent, else creates it
+func (c *intentConfirmer) loungeBooking(
+	intent *models.BookingIntent,
+	loungeIntent *models.LoungeIntentPayload,
+	existingID *uuid.UUID,
+	bookingType string,
+	promoDiscount models.Money,
+	bookings *intentBookings,
+) (*models.LoungeBooking, error) {
+	if existingID != nil {
+		return c.bookings.existingLoungeBooking(*existingID)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"intent_id":    intent.ID,
+		"lounge_id":    loungeIntent.LoungeID,
+		"lounge_name":  loungeIntent.LoungeName,
+		"total_price":  loungeIntent.TotalPrice,
+		"booking_type": bookingType,
+	}).Info("Creating lounge booking from intent")
+
+	booking, err := c.bookings.createLoungeBookingFromIntent(intent, loungeIntent, bookingType, promoDiscount, bookings.masterBookingID, bookings.busBookingID)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.WithFields(logrus.Fields{
+		"lounge_booking_id": booking.ID,
+		"booking_reference": booking.BookingReference,
+		"booking_type":      bookingType,
+	}).Info("Lounge booking created successfully")
+	return booking, nil
+}
+
+// record saves the bookings made so far on the intent. A booking that could not be recorded
+// would be made again by a retry, so the confirmation stops here.
+func (c *intentConfirmer) record(intent *models.BookingIntent, bookings *intentBookings) error {
+	if err := c.store.RecordIntentBookings(intent.ID, bookings.busBookingID, bookings.preLoungeBookingID, bookings.postLoungeBookingID); err != nil {
+		c.logger.WithError(err).WithFields(logrus.Fields{
+			"intent_id":              intent.ID,
+			"bus_booking_id":         bookings.busBookingID,
+			"pre_lounge_booking_id":  bookings.preLoungeBookingID,
+			"post_lounge_booking_id": bookings.postLoungeBookingID,
+		}).Error("Failed to record intent bookings")
+		return fmt.Errorf("failed to record intent bookings: %w", err)
+	}
+	return nil
+}
+
+// markConfirmed marks the intent and its lounge bookings confirmed, saving booking.confirmed
+// to the outbox in the same transaction
+func (c *intentConfirmer) markConfirmed(intent *models.BookingIntent, bookings *intentBookings) error {
+	confirmed := models.BookingEventData{
+		BookingReference:    bookings.masterRef,
+		UserID:              intent.UserID.String(),
+		IntentID:            intent.ID.String(),
+		BusBookingID:        optionalUUIDString(bookings.busBookingID),
+		PreLoungeBookingID:  optionalUUIDString(bookings.preLoungeBookingID),
+		PostLoungeBookingID: optionalUUIDString(bookings.postLoungeBookingID),
+		BookingID:           optionalUUIDString(bookings.masterBookingID),
+		TotalAmount:         intent.TotalAmount,
+		Currency:            intent.Currency,
+	}
+	if intent.BusIntent != nil {
+		confirmed.ScheduledTripID = intent.BusIntent.ScheduledTripID
+	}
+	confirmedEvent, err := models.NewBookingConfirmedEvent(confirmed)
+	if err != nil {
+		return err
+	}
+	if err := c.store.UpdateIntentConfirmed(intent.ID, bookings.busBookingID, bookings.preLoungeBookingID, bookings.postLoungeBookingID, confirmedEvent); err != nil {
+		return fmt.Errorf("failed to mark intent as confirmed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIntentStore keeps one intent's confirmation state the way booking_intents would
+type fakeIntentStore struct {
+	intent      *models.BookingIntent
+	failConfirm int // UpdateIntentConfirmed calls left to fail
+	event       *models.OutboxEvent
+}
+
+func (f *fakeIntentStore) RecordIntentBookings(intentID uuid.UUID, bus, pre, post *uuid.UUID) error {
+	f.intent.BusBookingID, f.intent.PreLoungeBookingID, f.intent.PostLoungeBookingID = bus, pre, post
+	return nil
+}
+
+func (f *fakeIntentStore) UpdateIntentConfirmed(intentID uuid.UUID, bus, pre, post *uuid.UUID, event *models.OutboxEvent) error {
+	if f.failConfirm > 0 {
+		f.failConfirm--
+		return errors.New("connection reset")
+	}
+	f.intent.Status = models.IntentStatusConfirmed
+	f.intent.BusBookingID, f.intent.PreLoungeBookingID, f.intent.PostLoungeBookingID = bus, pre, post
+	f.event = event
+	return nil
+}
+
+func (f *fakeIntentStore) ReleaseIntentConfirmation(intentID uuid.UUID, status models.BookingIntentStatus) error {
+	if f.intent.Status == models.IntentStatusConfirming {
+		f.intent.Status = status
+	}
+	return nil
+}
+
+// claim is ClaimIntentForConfirmation, returning the intent as read before the claim
+func (f *fakeIntentStore) claim(t *testing.T) *models.BookingIntent {
+	read := *f.intent
+	require.Contains(t, []models.BookingIntentStatus{models.IntentStatusHeld, models.IntentStatusPaymentPending}, read.Status)
+	f.intent.Status = models.IntentStatusConfirming
+	return &read
+}
+
+type fakeIntentBookings struct {
+	busCreated, loungeCreated int
+	masterID, busID, loungeID uuid.UUID
+}
+
+func (f *fakeIntentBookings) createBusBookingFromIntent(intent *models.BookingIntent) (*models.BusBooking, string, *uuid.UUID, error) {
+	f.busCreated++
+	f.masterID, f.busID = uuid.New(), uuid.New()
+	return &models.BusBooking{ID: f.busID.String(), BookingID: f.masterID.String()}, "BK-1", &f.masterID, nil
+}
+
+func (f *fakeIntentBookings) createLoungeBookingFromIntent(intent *models.BookingIntent, loungeIntent *models.LoungeIntentPayload, bookingType string, promoDiscount models.Money, masterBookingID, busBookingID *uuid.UUID) (*models.LoungeBooking, error) {
+	f.loungeCreated++
+	f.loungeID = uuid.New()
+	return &models.LoungeBooking{ID: f.loungeID, BookingReference: "LB-1"}, nil
+}
+
+func (f *fakeIntentBookings) existingBusBooking(busBookingID uuid.UUID) (string, *uuid.UUID, error) {
+	if busBookingID != f.busID {
+		return "", nil, errors.New("not found")
+	}
+	return "BK-1", &f.masterID, nil
+}
+
+func (f *fakeIntentBookings) existingLoungeBooking(loungeBookingID uuid.UUID) (*models.LoungeBooking, error) {
+	if loungeBookingID != f.loungeID {
+		return nil, errors.New("not found")
+	}
+	return &models.LoungeBooking{ID: f.loungeID, BookingReference: "LB-1"}, nil
+}
+
+func TestIntentConfirmerRetriesFailedConfirmation(t *testing.T) {
+	store := &fakeIntentStore{
+		intent: &models.BookingIntent{
+			ID:                  uuid.New(),
+			UserID:              uuid.New(),
+			IntentType:          models.IntentTypeCombined,
+			Status:              models.IntentStatusPaymentPending,
+			BusIntent:           &models.BusIntentPayload{ScheduledTripID: "trip-1"},
+			PreTripLoungeIntent: &models.LoungeIntentPayload{LoungeID: uuid.NewString()},
+		},
+		failConfirm: 1,
+	}
+	creator := &fakeIntentBookings{}
+	confirmer := &intentConfirmer{store: store, bookings: creator, logger: logrus.New()}
+
+	_, err := confirmer.confirm(store.claim(t))
+	require.Error(t, err)
+	assert.Equal(t, models.IntentStatusPaymentPending, store.intent.Status, "the claim is released so the confirmation can be retried")
+	if assert.NotNil(t, store.intent.BusBookingID) {
+		assert.Equal(t, creator.busID, *store.intent.BusBookingID, "the bookings made are recorded on the intent")
+	}
+
+	bookings, err := confirmer.confirm(store.claim(t))
+	require.NoError(t, err)
+	assert.Equal(t, models.IntentStatusConfirmed, store.intent.Status)
+	assert.Equal(t, 1, creator.busCreated, "the retry reuses the bus booking")
+	assert.Equal(t, 1, creator.loungeCreated, "the retry reuses the lounge booking")
+	assert.Equal(t, "BK-1", bookings.masterRef)
+	assert.Equal(t, creator.masterID, *bookings.masterBookingID)
+	assert.Equal(t, creator.loungeID, *store.intent.PreLoungeBookingID)
+	assert.NotNil(t, store.event, "booking.confirmed is saved with the confirmation")
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var ErrIntentExtensionLimit = errors.New("intent hold cannot be extended any further")
+
+// ============================================================================
+// EXTEND HOLD
+// ============================================================================
+
+// ExtendIntent adds the configured extension to a live intent's hold, keeping its seats and
+// lounges locked while the passenger finishes paying. Each intent may be extended
+// MaxHoldExtensions times; the payment timeout still applies once payment has started.
+func (s *BookingOrchestratorService) ExtendIntent(intentID, userID uuid.UUID) (*models.IntentExtensionResponse, error) {
+	intent, err := s.intentRepo.GetIntentByID(intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get intent: %w", err)
+	}
+	if intent == nil || intent.UserID != userID {
+		return nil, ErrIntentNotFound
+	}
+
+	now := time.Now()
+	expiresAt, err := nextHoldExpiry(intent, s.config, now)
+	if err != nil {
+		return nil, err
+	}
+
+	extended, err := s.intentRepo.ExtendIntentHold(intent.ID, expiresAt, s.config.MaxHoldExtensions)
+	if err != nil {
+		return nil, err
+	}
+	if !extended {
+		// Expired, paid or extended by another request since it was read
+		return nil, ErrIntentNotResumable
+	}
+
+	used := intent.ExtensionCount + 1
+	s.logger.WithFields(logrus.Fields{
+		"intent_id":       intent.ID,
+		"expires_at":      expiresAt,
+		"extensions_used": used,
+	}).Info("Booking intent hold extended")
+
+	return &models.IntentExtensionResponse{
+		IntentID:            intent.ID,
+		ExpiresAt:           expiresAt,
+		TTLSeconds:          int(expiresAt.Sub(now).Seconds()),
+		ExtensionsUsed:      used,
+		ExtensionsRemaining: s.config.MaxHoldExtensions - used,
+	}, nil
+}
+
+// nextHoldExpiry returns the intent's expiry after one more extension, or why it cannot be extended
+func nextHoldExpiry(intent *models.BookingIntent, config BookingOrchestratorConfig, now time.Time) (time.Time, error) {
+	if intent.Status != models.IntentStatusHeld && intent.Status != models.IntentStatusPaymentPending {
+		return time.Time{}, ErrIntentNotResumable
+	}
+	if !now.Before(intent.ExpiresAt) {
+		return time.Time{}, ErrIntentNotResumable
+	}
+	if config.HoldExtension <= 0 || intent.ExtensionCount >= config.MaxHoldExtensions {
+		return time.Time{}, ErrIntentExtensionLimit
+	}
+	return intent.ExpiresAt.Add(config.HoldExtension), nil
+}
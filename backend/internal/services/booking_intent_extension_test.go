@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextHoldExpiry(t *testing.T) {
+	config := DefaultOrchestratorConfig()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(2 * time.Minute)
+
+	held := &models.BookingIntent{Status: models.IntentStatusHeld, ExpiresAt: expiresAt}
+	next, err := nextHoldExpiry(held, config, now)
+	assert.NoError(t, err)
+	assert.Equal(t, expiresAt.Add(5*time.Minute), next, "extends from the current expiry")
+
+	paying := &models.BookingIntent{Status: models.IntentStatusPaymentPending, ExpiresAt: expiresAt, ExtensionCount: 1}
+	_, err = nextHoldExpiry(paying, config, now)
+	assert.NoError(t, err, "holds can be kept alive during payment")
+
+	used := &models.BookingIntent{Status: models.IntentStatusHeld, ExpiresAt: expiresAt, ExtensionCount: 2}
+	_, err = nextHoldExpiry(used, config, now)
+	assert.ErrorIs(t, err, ErrIntentExtensionLimit)
+
+	expired := &models.BookingIntent{Status: models.IntentStatusHeld, ExpiresAt: now.Add(-time.Second)}
+	_, err = nextHoldExpiry(expired, config, now)
+	assert.ErrorIs(t, err, ErrIntentNotResumable)
+
+	confirmed := &models.BookingIntent{Status: models.IntentStatusConfirmed, ExpiresAt: expiresAt}
+	_, err = nextHoldExpiry(confirmed, config, now)
+	assert.ErrorIs(t, err, ErrIntentNotResumable)
+
+	config.MaxHoldExtensions = 0
+	_, err = nextHoldExpiry(held, config, now)
+	assert.ErrorIs(t, err, ErrIntentExtensionLimit, "extensions can be switched off")
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrIntentNotFound      = errors.New("intent not found")
+	ErrIntentNotResumable  = errors.New("intent is no longer holding seats")
+	ErrInvalidHandoffToken = errors.New("handoff token is invalid, expired or already used")
+)
+
+// minHandoffTTL is the shortest handoff worth issuing; anything less can't realistically be scanned and claimed
+const minHandoffTTL = 30 * time.Second
+
+// ============================================================================
+// RESUME CHECKOUT ON ANOTHER DEVICE
+// ============================================================================
+
+// GetActiveIntent returns the user's most recent live intent; returns nil if none
+func (s *BookingOrchestratorService) GetActiveIntent(userID uuid.UUID) (*models.ActiveIntentResponse, error) {
+	intent, err := s.intentRepo.GetActiveIntentByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active intent: %w", err)
+	}
+	if intent == nil {
+		return nil, nil
+	}
+	return buildActiveIntentResponse(intent, time.Now()), nil
+}
+
+// CreateIntentHandoff issues a single-use token that lets another device signed in
+// as the same user pick up the intent. The token never outlives the intent's hold.
+func (s *BookingOrchestratorService) CreateIntentHandoff(intentID, userID uuid.UUID) (*models.IntentHandoffResponse, error) {
+	intent, err := s.intentRepo.GetIntentByID(intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get intent: %w", err)
+	}
+	if intent == nil || intent.UserID != userID {
+		return nil, ErrIntentNotFound
+	}
+
+	now := time.Now()
+	expiresAt := handoffExpiry(now, intent.ExpiresAt, s.config.HandoffTTL)
+	if !intent.CanInitiatePayment() || expiresAt.Sub(now) < minHandoffTTL {
+		return nil, ErrIntentNotResumable
+	}
+
+	token, err := generateHandoffToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.intentRepo.CreateIntentHandoff(intent.ID, userID, hashHandoffToken(token), expiresAt); err != nil {
+		return nil, err
+	}
+
+	link := buildHandoffDeepLink(s.config.HandoffDeepLink, token)
+	return &models.IntentHandoffResponse{
+		IntentID:   intent.ID,
+		Token:      token,
+		DeepLink:   link,
+		QRPayload:  link,
+		ExpiresAt:  expiresAt,
+		TTLSeconds: int(expiresAt.Sub(now).Seconds()),
+	}, nil
+}
+
+// ClaimIntentHandoff consumes a handoff token and returns the intent it was issued for.
+// Tokens only work for the user they were issued to.
+func (s *BookingOrchestratorService) ClaimIntentHandoff(token string, userID uuid.UUID) (*models.ActiveIntentResponse, error) {
+	intentID, err := s.intentRepo.ClaimIntentHandoff(hashHandoffToken(token), userID)
+	if err != nil {
+		return nil, err
+	}
+	if intentID == nil {
+		return nil, ErrInvalidHandoffToken
+	}
+
+	intent, err := s.intentRepo.GetIntentByID(*intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get intent: %w", err)
+	}
+	if intent == nil {
+		return nil, ErrIntentNotFound
+	}
+	if !intent.CanInitiatePayment() {
+		return nil, ErrIntentNotResumable
+	}
+
+	s.logger.WithField("intent_id", intent.ID).Info("Booking intent handed off to another device")
+	return buildActiveIntentResponse(intent, time.Now()), nil
+}
+
+func buildActiveIntentResponse(intent *models.BookingIntent, now time.Time) *models.ActiveIntentResponse {
+	ttl := int(intent.ExpiresAt.Sub(now).Seconds())
+	if ttl < 0 {
+		ttl = 0
+	}
+	return &models.ActiveIntentResponse{
+		Intent: intent,
+		PriceBreakdown: models.PriceBreakdown{
+			BusFare:        intent.BusFare,
+			PreLoungeFare:  intent.PreLoungeFare,
+			PostLoungeFare: intent.PostLoungeFare,
+			Total:          intent.TotalAmount,
+			Currency:       intent.Currency,
+		},
+		TTLSeconds:       ttl,
+		PaymentInitiated: intent.Status == models.IntentStatusPaymentPending,
+	}
+}
+
+// handoffExpiry caps the handoff lifetime at the intent's own expiry
+func handoffExpiry(now, intentExpiresAt time.Time, ttl time.Duration) time.Time {
+	expiresAt := now.Add(ttl)
+	if intentExpiresAt.Before(expiresAt) {
+		return intentExpiresAt
+	}
+	return expiresAt
+}
+
+// generateHandoffToken returns a random URL-safe token
+func generateHandoffToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate handoff token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashHandoffToken returns the SHA-256 hex digest stored in place of the token
+func hashHandoffToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// buildHandoffDeepLink appends the token to the deep link, keeping any existing query parameters
+func buildHandoffDeepLink(base, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
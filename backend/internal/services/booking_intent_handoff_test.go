@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandoffExpiry_CappedByIntentExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now.Add(5*time.Minute), handoffExpiry(now, now.Add(10*time.Minute), 5*time.Minute))
+	assert.Equal(t, now.Add(2*time.Minute), handoffExpiry(now, now.Add(2*time.Minute), 5*time.Minute), "never outlives the hold")
+}
+
+func TestBuildHandoffDeepLink_KeepsExistingQuery(t *testing.T) {
+	assert.Equal(t, "smarttransit://booking/resume?token=abc", buildHandoffDeepLink("smarttransit://booking/resume", "abc"))
+	assert.Equal(t, "https://app.example.lk/resume?src=web&token=abc", buildHandoffDeepLink("https://app.example.lk/resume?src=web", "abc"))
+}
+
+func TestGenerateHandoffToken_UniqueAndHashedDeterministically(t *testing.T) {
+	first, err := generateHandoffToken()
+	assert.NoError(t, err)
+	second, err := generateHandoffToken()
+	assert.NoError(t, err)
+
+	assert.Len(t, first, 48)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, hashHandoffToken(first), hashHandoffToken(first))
+	assert.NotEqual(t, first, hashHandoffToken(first))
+}
+
+func TestBuildActiveIntentResponse(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	intent := &models.BookingIntent{
+		Status:      models.IntentStatusPaymentPending,
+		BusFare:     1200,
+		TotalAmount: 1200,
+		Currency:    "LKR",
+		ExpiresAt:   now.Add(90 * time.Second),
+	}
+
+	resp := buildActiveIntentResponse(intent, now)
+	assert.Equal(t, 90, resp.TTLSeconds)
+	assert.True(t, resp.PaymentInitiated)
+	assert.Equal(t, 1200.0, resp.PriceBreakdown.Total)
+
+	resp = buildActiveIntentResponse(intent, now.Add(time.Hour))
+	assert.Equal(t, 0, resp.TTLSeconds)
+}
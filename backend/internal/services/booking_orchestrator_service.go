@@ -1,7 +1,10 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,17 +15,35 @@ import (
 
 // BookingOrchestratorConfig holds configuration for the orchestrator
 type BookingOrchestratorConfig struct {
-	IntentTTL       time.Duration // How long intents are valid (default 10 min)
-	PaymentTimeout  time.Duration // How long to wait for payment (default 15 min)
-	DefaultCurrency string        // Default currency (default LKR)
+	IntentTTL        time.Duration   // Fallback hold TTL when the policy has none for a type (default 10 min)
+	TTLPolicy        IntentTTLPolicy // Per intent type and reliability tier hold TTLs
+	PaymentTimeout   time.Duration   // How long to wait for payment (default 15 min)
+	DefaultCurrency  string          // Default currency (default LKR)
+	HandoffTTL       time.Duration   // Lifetime of cross-device handoff tokens (default 5 min)
+	HandoffDeepLink  string          // Deep link handoff tokens are appended to
+	PriceDriftPolicy string          // What to do when seat prices change during a hold (default honor_snapshot)
+
+	CallCenterHoldTTL time.Duration // Hold TTL for intents created by call-center agents (default 30 min)
+
+	HoldExtension     time.Duration // Time each explicit extension adds to a hold (default 5 min)
+	MaxHoldExtensions int           // Explicit extensions allowed per intent (default 2, 0 disables)
 }
 
 // DefaultOrchestratorConfig returns default configuration
 func DefaultOrchestratorConfig() BookingOrchestratorConfig {
 	return BookingOrchestratorConfig{
-		IntentTTL:       10 * time.Minute,
-		PaymentTimeout:  15 * time.Minute,
-		DefaultCurrency: "LKR",
+		IntentTTL:        10 * time.Minute,
+		TTLPolicy:        DefaultIntentTTLPolicy(),
+		PaymentTimeout:   15 * time.Minute,
+		DefaultCurrency:  "LKR",
+		HandoffTTL:       5 * time.Minute,
+		HandoffDeepLink:  "smarttransit://booking/resume",
+		PriceDriftPolicy: models.PriceDriftHonorSnapshot,
+
+		CallCenterHoldTTL: 30 * time.Minute,
+
+		HoldExtension:     5 * time.Minute,
+		MaxHoldExtensions: 2,
 	}
 }
 
@@ -34,8 +55,18 @@ type BookingOrchestratorService struct {
 	appBookingRepo    *database.AppBookingRepository
 	loungeBookingRepo *database.LoungeBookingRepository
 	loungeRepo        *database.LoungeRepository
+	loungePricing     *LoungePricingService
+	bundleDiscounts   *LoungeBundleDiscountService
+	promoCodes        *PromoCodeService
+	wallets           *WalletService
 	busOwnerRouteRepo *database.BusOwnerRouteRepository
+	tenantRepo        *database.TenantRepository
 	payableService    *PAYableService
+	waitingRoom       *TripWaitingRoomService
+	waitlist          *TripWaitlistService
+	blackouts         *BookingBlackoutService
+	priceAdjustments  *TripPriceAdjustmentService
+	events            *BookingEventService
 	config            BookingOrchestratorConfig
 	logger            *logrus.Logger
 }
@@ -48,8 +79,18 @@ func NewBookingOrchestratorService(
 	appBookingRepo *database.AppBookingRepository,
 	loungeBookingRepo *database.LoungeBookingRepository,
 	loungeRepo *database.LoungeRepository,
+	loungePricing *LoungePricingService,
+	bundleDiscounts *LoungeBundleDiscountService,
+	promoCodes *PromoCodeService,
+	wallets *WalletService,
 	busOwnerRouteRepo *database.BusOwnerRouteRepository,
+	tenantRepo *database.TenantRepository,
 	payableService *PAYableService,
+	waitingRoom *TripWaitingRoomService,
+	waitlist *TripWaitlistService,
+	blackouts *BookingBlackoutService,
+	priceAdjustments *TripPriceAdjustmentService,
+	events *BookingEventService,
 	config BookingOrchestratorConfig,
 	logger *logrus.Logger,
 ) *BookingOrchestratorService {
@@ -60,8 +101,18 @@ func NewBookingOrchestratorService(
 		appBookingRepo:    appBookingRepo,
 		loungeBookingRepo: loungeBookingRepo,
 		loungeRepo:        loungeRepo,
+		loungePricing:     loungePricing,
+		bundleDiscounts:   bundleDiscounts,
+		promoCodes:        promoCodes,
+		wallets:           wallets,
 		busOwnerRouteRepo: busOwnerRouteRepo,
+		tenantRepo:        tenantRepo,
 		payableService:    payableService,
+		waitingRoom:       waitingRoom,
+		waitlist:          waitlist,
+		blackouts:         blackouts,
+		priceAdjustments:  priceAdjustments,
+		events:            events,
 		config:            config,
 		logger:            logger,
 	}
@@ -75,6 +126,24 @@ func NewBookingOrchestratorService(
 func (s *BookingOrchestratorService) CreateIntent(
 	userID uuid.UUID,
 	req *models.CreateBookingIntentRequest,
+) (*models.BookingIntentResponse, error) {
+	return s.createIntent(userID, req, false)
+}
+
+// CreateCallCenterIntent creates an intent for a passenger booking over the phone. The hold
+// lasts CallCenterHoldTTL so the passenger has time to pay from the SMS link, and the trip's
+// waiting room does not apply since the agent cannot be admitted on the passenger's behalf.
+func (s *BookingOrchestratorService) CreateCallCenterIntent(
+	passengerID uuid.UUID,
+	req *models.CreateBookingIntentRequest,
+) (*models.BookingIntentResponse, error) {
+	return s.createIntent(passengerID, req, true)
+}
+
+func (s *BookingOrchestratorService) createIntent(
+	userID uuid.UUID,
+	req *models.CreateBookingIntentRequest,
+	callCenter bool,
 ) (*models.BookingIntentResponse, error) {
 	// 1. Check idempotency key if provided
 	if req.IdempotencyKey != nil && *req.IdempotencyKey != "" {
@@ -93,17 +162,50 @@ func (s *BookingOrchestratorService) CreateIntent(
 		return nil, err
 	}
 
-	expiresAt := time.Now().Add(s.config.IntentTTL)
+	// Trips with an active waiting room only take intents from admitted users
+	var queueToken string
+	if req.Bus != nil && !callCenter {
+		token, err := s.waitingRoom.RequireAdmission(req.Bus.ScheduledTripID, userID, req.QueueToken)
+		if err != nil {
+			return nil, err
+		}
+		queueToken = token
+	}
+
+	// Seats kept for waitlist offers can only be taken by the passengers offered them
+	var waitlistOffer string
+	if req.Bus != nil {
+		offer, err := s.waitlist.ReserveForIntent(req.Bus.ScheduledTripID, userID, len(req.Bus.Seats))
+		if err != nil {
+			return nil, err
+		}
+		waitlistOffer = offer
+	}
+
+	// Ask before booking a trip the passenger already holds a confirmed booking on
+	if req.Bus != nil && !req.ConfirmDuplicate {
+		if err := s.checkDuplicateBooking(userID, req.Bus); err != nil {
+			return nil, err
+		}
+	}
+
+	holdTTL, tier := s.resolveHoldTTL(userID, req.IntentType)
+	if callCenter && s.config.CallCenterHoldTTL > 0 {
+		holdTTL = s.config.CallCenterHoldTTL
+	}
+	expiresAt := time.Now().Add(holdTTL)
 
 	// 3. Build intent object
 	intent := &models.BookingIntent{
-		UserID:         userID,
-		IntentType:     req.IntentType,
-		Status:         models.IntentStatusHeld,
-		Currency:       s.config.DefaultCurrency,
-		PaymentGateway: "payable",
-		ExpiresAt:      expiresAt,
-		IdempotencyKey: req.IdempotencyKey,
+		UserID:          userID,
+		IntentType:      req.IntentType,
+		Status:          models.IntentStatusHeld,
+		Currency:        s.config.DefaultCurrency,
+		PaymentGateway:  "payable",
+		ExpiresAt:       expiresAt,
+		HoldTTLSeconds:  int(holdTTL.Seconds()),
+		ReliabilityTier: tier,
+		IdempotencyKey:  req.IdempotencyKey,
 	}
 
 	// 4. Process bus intent (if present)
@@ -118,7 +220,7 @@ func (s *BookingOrchestratorService) CreateIntent(
 
 	// 5. Process pre-trip lounge intent (if present)
 	if req.PreTripLounge != nil {
-		loungePayload, loungeFare, err := s.processLoungeIntent(req.PreTripLounge, intent.ID, expiresAt, "pre_trip")
+		loungePayload, loungeFare, err := s.processLoungeIntent(req.PreTripLounge, intent.ID, expiresAt, "pre_trip", req.Bus != nil)
 		if err != nil {
 			return nil, err
 		}
@@ -128,7 +230,7 @@ func (s *BookingOrchestratorService) CreateIntent(
 
 	// 6. Process post-trip lounge intent (if present)
 	if req.PostTripLounge != nil {
-		loungePayload, loungeFare, err := s.processLoungeIntent(req.PostTripLounge, intent.ID, expiresAt, "post_trip")
+		loungePayload, loungeFare, err := s.processLoungeIntent(req.PostTripLounge, intent.ID, expiresAt, "post_trip", req.Bus != nil)
 		if err != nil {
 			return nil, err
 		}
@@ -136,15 +238,34 @@ func (s *BookingOrchestratorService) CreateIntent(
 		intent.PostLoungeFare = loungeFare
 	}
 
-	// 7. Calculate totals
-	intent.TotalAmount = intent.BusFare + intent.PreLoungeFare + intent.PostLoungeFare
+	// 7. Calculate totals, less any promo code discount, which is locked with the prices
+	total := models.NewMoney(intent.BusFare).Add(models.NewMoney(intent.PreLoungeFare)).Add(models.NewMoney(intent.PostLoungeFare))
+	var promo *models.IntentDiscountInfo
+	if req.PromoCode != nil && strings.TrimSpace(*req.PromoCode) != "" {
+		quote, err := s.promoCodes.Quote(userID.String(), *req.PromoCode, []models.PromoLine{
+			{ProductType: models.PromoProductBus, Amount: intent.BusFare},
+			{ProductType: models.PromoProductLounge, Amount: intent.PreLoungeFare},
+			{ProductType: models.PromoProductLounge, Amount: intent.PostLoungeFare},
+		})
+		if err != nil {
+			return nil, err
+		}
+		promo = models.NewPromoDiscountInfo(quote)
+		total = total.Sub(promo.DiscountAmount)
+	}
+	intent.TotalAmount = total.Float64()
 	intent.PricingSnapshot = models.PricingSnapshot{
-		BusFare:        intent.BusFare,
-		PreLoungeFare:  intent.PreLoungeFare,
-		PostLoungeFare: intent.PostLoungeFare,
-		Total:          intent.TotalAmount,
-		Currency:       intent.Currency,
-		CalculatedAt:   time.Now(),
+		BusFare:         models.NewMoney(intent.BusFare),
+		PreLoungeFare:   models.NewMoney(intent.PreLoungeFare),
+		PostLoungeFare:  models.NewMoney(intent.PostLoungeFare),
+		Total:           total,
+		Currency:        intent.Currency,
+		CalculatedAt:    time.Now(),
+		SeatPrices:      busSeatPrices(intent.BusIntent),
+		DiscountApplied: promo,
+	}
+	if intent.BusIntent != nil {
+		intent.PricingSnapshot.PriceAdjustment = intent.BusIntent.PriceAdjustment
 	}
 
 	// 8. Save intent to database
@@ -154,6 +275,22 @@ func (s *BookingOrchestratorService) CreateIntent(
 		return nil, fmt.Errorf("failed to create intent: %w", err)
 	}
 
+	// Reserve the promo code's use with the intent; it is given back if the intent expires or
+	// is cancelled, so limits hold however many passengers are paying with the code at once
+	if promo != nil {
+		intentIDStr := intent.ID.String()
+		if err := s.promoCodes.Reserve(&models.PromoCodeRedemption{
+			PromoCodeID:    promo.PromoCodeID,
+			UserID:         userID.String(),
+			IntentID:       &intentIDStr,
+			DiscountAmount: promo.DiscountAmount.Float64(),
+		}); err != nil {
+			s.rollbackHolds(intent.ID)
+			s.intentRepo.UpdateIntentExpired(intent.ID)
+			return nil, err
+		}
+	}
+
 	// 9. Now that we have the intent ID, hold seats and lounge capacity
 	if req.Bus != nil {
 		seatIDs := make([]string, len(req.Bus.Seats))
@@ -197,17 +334,40 @@ func (s *BookingOrchestratorService) CreateIntent(
 		}
 	}
 
+	if queueToken != "" {
+		s.waitingRoom.MarkAdmissionUsed(queueToken)
+	}
+	if waitlistOffer != "" {
+		s.waitlist.MarkOfferAccepted(waitlistOffer, intent.ID)
+	}
+
 	s.logger.WithFields(logrus.Fields{
-		"intent_id":    intent.ID,
-		"user_id":      userID,
-		"intent_type":  intent.IntentType,
-		"total_amount": intent.TotalAmount,
-		"expires_at":   expiresAt,
+		"intent_id":        intent.ID,
+		"user_id":          userID,
+		"intent_type":      intent.IntentType,
+		"total_amount":     intent.TotalAmount,
+		"expires_at":       expiresAt,
+		"hold_ttl_seconds": intent.HoldTTLSeconds,
+		"reliability_tier": intent.ReliabilityTier,
 	}).Info("Booking intent created successfully")
+	s.events.IntentCreated(intent)
 
 	return s.buildIntentResponse(intent), nil
 }
 
+// checkDuplicateBooking returns a DuplicateBookingError when the user, or anyone booking for
+// the same passenger phone, already has a confirmed booking on the trip
+func (s *BookingOrchestratorService) checkDuplicateBooking(userID uuid.UUID, req *models.BusIntentRequest) error {
+	existing, err := s.appBookingRepo.GetActiveTripBookingsForPassenger(req.ScheduledTripID, userID.String(), models.PhoneMatchKey(req.PassengerPhone))
+	if err != nil {
+		return fmt.Errorf("failed to check existing bookings: %w", err)
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+	return models.NewDuplicateBookingError(existing, req.Seats)
+}
+
 // processBusIntent validates and processes bus intent, returns payload and fare
 func (s *BookingOrchestratorService) processBusIntent(
 	req *models.BusIntentRequest,
@@ -222,12 +382,14 @@ func (s *BookingOrchestratorService) processBusIntent(
 		return nil, 0, fmt.Errorf("scheduled trip not found")
 	}
 
-	// 2. Check trip is still bookable
-	if trip.Status != models.ScheduledTripStatusScheduled && trip.Status != models.ScheduledTripStatusConfirmed {
-		return nil, 0, fmt.Errorf("trip is not available for booking (status: %s)", trip.Status)
+	// 2. Check trip is still bookable and online sales are not blacked out
+	if err := checkTripBookable(trip, time.Now()); err != nil {
+		return nil, 0, err
 	}
-	if trip.DepartureDatetime.Before(time.Now()) {
-		return nil, 0, fmt.Errorf("trip has already departed")
+	if s.blackouts != nil {
+		if err := s.blackouts.CheckTripOpen(trip.ID); err != nil {
+			return nil, 0, err
+		}
 	}
 
 	// 3. Get seat IDs and check availability
@@ -256,8 +418,12 @@ func (s *BookingOrchestratorService) processBusIntent(
 		seatMap[seat.ID] = seat
 	}
 
-	// 5. Build payload with prices
-	var totalFare float64
+	// 5. Build payload with prices, including any surcharge/discount the owner put on the trip
+	adjustment, err := s.priceAdjustments.ActiveForTrip(trip.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get trip price adjustment: %w", err)
+	}
+	var totalFare, adjustedBy models.Money
 	intentSeats := make([]models.BusIntentSeat, len(req.Seats))
 	for i, reqSeat := range req.Seats {
 		seat, exists := seatMap[reqSeat.TripSeatID]
@@ -269,13 +435,15 @@ func (s *BookingOrchestratorService) processBusIntent(
 			TripSeatID:      reqSeat.TripSeatID,
 			SeatNumber:      seat.SeatNumber,
 			SeatType:        seat.SeatType,
-			SeatPrice:       seat.SeatPrice,
+			SeatPrice:       busSeatFare(seat, adjustment),
 			PassengerName:   reqSeat.PassengerName,
 			PassengerPhone:  reqSeat.PassengerPhone,
 			PassengerGender: reqSeat.PassengerGender,
 			IsPrimary:       reqSeat.IsPrimary,
 		}
-		totalFare += seat.SeatPrice
+		price := models.NewMoney(intentSeats[i].SeatPrice)
+		totalFare = totalFare.Add(price)
+		adjustedBy = adjustedBy.Add(price.Sub(models.NewMoney(seat.SeatPrice)))
 	}
 
 	// 6. Get trip info for display
@@ -308,9 +476,10 @@ func (s *BookingOrchestratorService) processBusIntent(
 		PassengerEmail:    req.PassengerEmail,
 		SpecialRequests:   req.SpecialRequests,
 		TripInfo:          tripInfo,
+		PriceAdjustment:   adjustment.Applied(adjustedBy.Float64()),
 	}
 
-	return payload, totalFare, nil
+	return payload, totalFare.Float64(), nil
 }
 
 // processLoungeIntent validates and processes lounge intent, returns payload and fare
@@ -319,6 +488,7 @@ func (s *BookingOrchestratorService) processLoungeIntent(
 	intentID uuid.UUID,
 	expiresAt time.Time,
 	loungeType string, // "pre_trip" or "post_trip"
+	withBusTicket bool,
 ) (*models.LoungeIntentPayload, float64, error) {
 	// 1. Get lounge details
 	loungeID, err := uuid.Parse(req.LoungeID)
@@ -334,14 +504,18 @@ func (s *BookingOrchestratorService) processLoungeIntent(
 		return nil, 0, fmt.Errorf("lounge not found")
 	}
 
-	// 2. Get lounge price based on pricing type
-	priceStr, err := s.loungeBookingRepo.GetLoungePrice(loungeID, req.PricingType)
+	// 2. Price the stay for its check-in time (slot/date overrides and surge); the quote is
+	// stored in the intent so the guest pays this price even if rules or occupancy change
+	checkIn, err := req.CheckIn(time.Now())
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid check_in_time for %s lounge", loungeType)
+	}
+	quote, err := s.loungePricing.Quote(lounge, req.PricingType, checkIn)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get lounge price: %w", err)
 	}
-
-	var pricePerGuest float64
-	fmt.Sscanf(priceStr, "%f", &pricePerGuest)
+	pricePerGuest := models.NewMoney(quote.PricePerGuest)
+	localCheckIn := checkIn.In(models.LoungeTimezone)
 
 	// 3. Build guests list
 	guests := make([]models.LoungeIntentGuest, len(req.Guests))
@@ -355,10 +529,10 @@ func (s *BookingOrchestratorService) processLoungeIntent(
 	guestCount := len(guests)
 
 	// 4. Calculate lounge base price
-	basePrice := pricePerGuest * float64(guestCount)
+	basePrice := pricePerGuest.Mul(guestCount)
 
 	// 5. Process pre-orders if any
-	var preOrderTotal float64
+	var preOrderTotal models.Money
 	preOrders := make([]models.LoungeIntentPreOrder, 0)
 	for _, po := range req.PreOrders {
 		productID, err := uuid.Parse(po.ProductID)
@@ -370,8 +544,11 @@ func (s *BookingOrchestratorService) processLoungeIntent(
 			continue
 		}
 
-		var unitPrice float64
-		fmt.Sscanf(product.Price, "%f", &unitPrice)
+		unitPrice, err := models.ParseMoney(product.Price)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to price pre-order %s: %w", product.Name, err)
+		}
+		lineTotal := unitPrice.Mul(po.Quantity)
 
 		preOrders = append(preOrders, models.LoungeIntentPreOrder{
 			ProductID:   po.ProductID,
@@ -379,29 +556,39 @@ func (s *BookingOrchestratorService) processLoungeIntent(
 			ProductType: string(product.ProductType),
 			ImageURL:    product.ImageURL,
 			Quantity:    po.Quantity,
-			UnitPrice:   unitPrice,
-			TotalPrice:  unitPrice * float64(po.Quantity),
+			UnitPrice:   unitPrice.Float64(),
+			TotalPrice:  lineTotal.Float64(),
 		})
-		preOrderTotal += unitPrice * float64(po.Quantity)
+		preOrderTotal = preOrderTotal.Add(lineTotal)
 	}
 
-	totalPrice := basePrice + preOrderTotal
+	totalPrice := basePrice.Add(preOrderTotal)
 
 	// 6. Build payload
 	payload := &models.LoungeIntentPayload{
 		LoungeID:      req.LoungeID,
 		LoungeName:    lounge.LoungeName,
 		PricingType:   req.PricingType,
+		Date:          localCheckIn.Format("2006-01-02"),
+		CheckInTime:   localCheckIn.Format("15:04"),
 		GuestCount:    guestCount,
 		Guests:        guests,
 		PreOrders:     preOrders,
-		PricePerGuest: pricePerGuest,
-		BasePrice:     basePrice,
-		PreOrderTotal: preOrderTotal,
-		TotalPrice:    totalPrice,
+		PricePerGuest: pricePerGuest.Float64(),
+		PriceQuote:    quote,
+		BasePrice:     basePrice.Float64(),
+		PreOrderTotal: preOrderTotal.Float64(),
+		TotalPrice:    totalPrice.Float64(),
 	}
 
-	return payload, totalPrice, nil
+	// 7. Stays booked with a bus ticket get the bundle discount, recorded with who funds it
+	if withBusTicket {
+		if err := s.bundleDiscounts.Apply(payload, loungeType, time.Now()); err != nil {
+			return nil, 0, fmt.Errorf("failed to apply bundle discount: %w", err)
+		}
+	}
+
+	return payload, payload.TotalPrice, nil
 }
 
 // createLoungeHold creates a lounge capacity hold
@@ -414,10 +601,15 @@ func (s *BookingOrchestratorService) createLoungeHold(
 	loungeID, _ := uuid.Parse(req.LoungeID)
 	guestCount := len(req.Guests)
 
-	// For now, use current date. In production, this would come from trip info
-	date := time.Now()
-	timeSlotStart := "09:00"
-	timeSlotEnd := "12:00"
+	// Hold the same window the stay was priced for
+	checkIn, err := req.CheckIn(time.Now())
+	if err != nil {
+		return fmt.Errorf("invalid check_in_time for %s lounge", loungeType)
+	}
+	slot := LoungeSlotFor(checkIn, req.PricingType)
+	date := slot.Date
+	timeSlotStart := slot.Start
+	timeSlotEnd := slot.End
 
 	// Check capacity
 	available, err := s.intentRepo.GetLoungeCapacityAvailable(loungeID, date, timeSlotStart, timeSlotEnd)
@@ -446,10 +638,14 @@ func (s *BookingOrchestratorService) createLoungeHold(
 // INITIATE PAYMENT (Phase 2)
 // ============================================================================
 
-// InitiatePayment initiates payment for an intent
+// InitiatePayment initiates payment for an intent, taking part or all of it from the passenger's
+// wallet when req asks to. Operators with their own merchant account are paid directly, so an
+// intent for their trip can only be paid through their app.
 func (s *BookingOrchestratorService) InitiatePayment(
 	intentID uuid.UUID,
 	userID uuid.UUID,
+	tenant *models.Tenant,
+	req *models.InitiatePaymentRequest,
 ) (*models.InitiatePaymentResponse, error) {
 	// 1. Get intent
 	intent, err := s.intentRepo.GetIntentByID(intentID)
@@ -465,6 +661,18 @@ func (s *BookingOrchestratorService) InitiatePayment(
 		return nil, fmt.Errorf("unauthorized: intent belongs to another user")
 	}
 
+	// An intent is paid to its trip operator's merchant account, so it can only be paid through
+	// that operator's app
+	if intent.BusIntent != nil {
+		intentTenantID, err := s.intentRepo.GetIntentTenantID(intent.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get intent tenant: %w", err)
+		}
+		if !intentTenantMatches(intentTenantID, tenant) {
+			return nil, ErrIntentTenantMismatch
+		}
+	}
+
 	// 3. Check can initiate payment
 	if !intent.CanInitiatePayment() {
 		if intent.IsExpired() {
@@ -473,15 +681,66 @@ func (s *BookingOrchestratorService) InitiatePayment(
 		return nil, fmt.Errorf("intent is not in valid state for payment (status: %s)", intent.Status)
 	}
 
+	// Prices lock once payment starts, so under require_reaccept a changed price has to be
+	// accepted before the first attempt
+	if intent.Status == models.IntentStatusHeld && s.config.PriceDriftPolicy == models.PriceDriftRequireReaccept {
+		drift, err := s.checkPriceDrift(intent)
+		if err != nil {
+			return nil, err
+		}
+		if drift != nil {
+			return nil, newPriceDriftError(drift)
+		}
+	}
+
+	// Take the wallet part first. An intent keeps the wallet payment it was first given; if
+	// this attempt goes no further, the payment is returned.
+	paidFromWallet, err := s.payFromWallet(intent, req)
+	if err != nil {
+		return nil, err
+	}
+	releaseWallet := func() {
+		if paidFromWallet {
+			s.wallets.ReleaseIntent(intent.ID, intent.UserID)
+		}
+	}
+
+	// Fail fast while the payment gateway is degraded, leaving the hold untouched so the user can retry
+	if intent.AmountDue().IsPositive() && s.payableService != nil && s.payableService.IsConfigured() && !s.payableService.IsAvailable() {
+		releaseWallet()
+		return nil, ErrPaymentUnavailable
+	}
+
 	// 4. Generate payment reference (using intent ID as invoice ID)
 	paymentRef := fmt.Sprintf("INT-%s", intent.ID.String()[:8])
-	amountStr := fmt.Sprintf("%.2f", intent.TotalAmount)
+	amountStr := intent.AmountDue().String()
 
 	// 5. Update intent to payment_pending
 	if err := s.intentRepo.UpdateIntentPaymentPending(intent.ID, paymentRef); err != nil {
+		releaseWallet()
 		return nil, fmt.Errorf("failed to update intent: %w", err)
 	}
 
+	// Paid in full from the wallet: nothing goes to the gateway, and the intent can be confirmed
+	if intent.AmountDue().IsZero() {
+		if err := s.intentRepo.UpdateIntentPaymentSuccess(intent.ID); err != nil {
+			s.logger.WithError(err).WithField("intent_id", intent.ID).Warn("Failed to update payment status")
+		}
+		s.logger.WithFields(logrus.Fields{
+			"intent_id":     intentID,
+			"payment_ref":   paymentRef,
+			"wallet_amount": intent.WalletAmount,
+		}).Info("Booking intent paid in full from wallet")
+		return &models.InitiatePaymentResponse{
+			InvoiceID:    paymentRef,
+			Amount:       amountStr,
+			Currency:     intent.Currency,
+			WalletAmount: intent.WalletAmount,
+			PaidInFull:   true,
+			ExpiresAt:    intent.ExpiresAt,
+		}, nil
+	}
+
 	// 6. Build payment response
 	var response *models.InitiatePaymentResponse
 
@@ -497,7 +756,17 @@ func (s *BookingOrchestratorService) InitiatePayment(
 			OrderDescription: fmt.Sprintf("Bus Booking - %s", paymentRef),
 		}
 
-		payableResp, err := s.payableService.InitiatePayment(payableParams)
+		// Use the same merchant the webhook, reconciliation and refunds resolve for this intent
+		payable, merchantTenant, err := s.payableForIntent(intent.ID)
+		if err != nil {
+			releaseWallet()
+			return nil, err
+		}
+		if merchantTenant != nil && merchantTenant.PaymentPackageName != nil {
+			payableParams.PackageName = *merchantTenant.PaymentPackageName
+		}
+
+		payableResp, err := payable.InitiatePayment(payableParams)
 		if err != nil {
 			s.logger.WithError(err).Error("Failed to initiate PAYable payment")
 			// Don't fail completely - return a response that allows retry
@@ -511,6 +780,7 @@ func (s *BookingOrchestratorService) InitiatePayment(
 			Currency:        intent.Currency,
 			UID:             payableResp.UID,
 			StatusIndicator: payableResp.StatusIndicator,
+			WalletAmount:    intent.WalletAmount,
 			ExpiresAt:       intent.ExpiresAt,
 		}
 
@@ -522,7 +792,7 @@ func (s *BookingOrchestratorService) InitiatePayment(
 		s.logger.WithFields(logrus.Fields{
 			"intent_id":    intentID,
 			"payment_ref":  paymentRef,
-			"amount":       intent.TotalAmount,
+			"amount":       amountStr,
 			"uid":          payableResp.UID,
 			"payment_page": payableResp.PaymentPage,
 			"environment":  s.payableService.GetEnvironment(),
@@ -531,11 +801,12 @@ func (s *BookingOrchestratorService) InitiatePayment(
 		// Development mode - return placeholder URL
 		s.logger.Warn("PAYable service not configured - using placeholder payment URL")
 		response = &models.InitiatePaymentResponse{
-			PaymentURL: fmt.Sprintf("https://gateway.payable.lk/pay/%s", paymentRef),
-			InvoiceID:  paymentRef,
-			Amount:     amountStr,
-			Currency:   intent.Currency,
-			ExpiresAt:  intent.ExpiresAt,
+			PaymentURL:   fmt.Sprintf("https://gateway.payable.lk/pay/%s", paymentRef),
+			InvoiceID:    paymentRef,
+			Amount:       amountStr,
+			Currency:     intent.Currency,
+			WalletAmount: intent.WalletAmount,
+			ExpiresAt:    intent.ExpiresAt,
 		}
 
 		s.logger.WithFields(logrus.Fields{
@@ -549,15 +820,204 @@ func (s *BookingOrchestratorService) InitiatePayment(
 	return response, nil
 }
 
+// payFromWallet takes the wallet part of an intent's payment if req asks for one and the intent
+// has none yet, reporting whether it did
+func (s *BookingOrchestratorService) payFromWallet(intent *models.BookingIntent, req *models.InitiatePaymentRequest) (bool, error) {
+	if req == nil || !req.UseWallet || intent.WalletAmount.IsPositive() {
+		return false, nil
+	}
+	if s.wallets == nil {
+		return false, &models.ValidationError{Message: "wallet payments are not available"}
+	}
+	balance, err := s.wallets.Balance(intent.UserID)
+	if err != nil {
+		return false, err
+	}
+	amount, err := req.WalletPayment(balance, models.NewMoney(intent.TotalAmount))
+	if err != nil || amount.IsZero() {
+		return false, err
+	}
+	if err := s.wallets.PayIntent(intent, amount); err != nil {
+		return false, err
+	}
+	intent.WalletAmount = amount
+	return true, nil
+}
+
+// ============================================================================
+// PRICE REVALIDATION
+// ============================================================================
+
+// AcceptIntentPrices re-locks a held intent at the trip's current seat prices after the
+// passenger reviewed a price change. Returns the intent unchanged if nothing drifted.
+func (s *BookingOrchestratorService) AcceptIntentPrices(intentID uuid.UUID, userID uuid.UUID) (*models.BookingIntentResponse, error) {
+	intent, err := s.intentRepo.GetIntentByID(intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get intent: %w", err)
+	}
+	if intent == nil {
+		return nil, fmt.Errorf("intent not found")
+	}
+	if intent.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: intent belongs to another user")
+	}
+	if intent.IsExpired() {
+		return nil, fmt.Errorf("intent has expired")
+	}
+	if intent.Status != models.IntentStatusHeld {
+		return nil, fmt.Errorf("prices can only be accepted before payment starts (status: %s)", intent.Status)
+	}
+
+	drift, err := s.checkPriceDrift(intent)
+	if err != nil {
+		return nil, err
+	}
+	if drift == nil {
+		return s.buildIntentResponse(intent), nil
+	}
+
+	current := make(map[string]float64, len(drift.Seats))
+	for _, change := range drift.Seats {
+		current[change.TripSeatID] = change.CurrentPrice
+	}
+	busIntent := *intent.BusIntent
+	busIntent.Seats = make([]models.BusIntentSeat, len(intent.BusIntent.Seats))
+	for i, seat := range intent.BusIntent.Seats {
+		seat.SeatPrice = intent.HeldSeatPrice(seat)
+		if price, ok := current[seat.TripSeatID]; ok {
+			seat.SeatPrice = price
+		}
+		busIntent.Seats[i] = seat
+	}
+	busIntent.PriceAdjustment = drift.PriceAdjustment
+
+	snapshot := intent.PricingSnapshot
+	snapshot.BusFare = models.NewMoney(drift.CurrentBusFare)
+	snapshot.Total = models.NewMoney(drift.CurrentTotal)
+	snapshot.SeatPrices = busSeatPrices(&busIntent)
+	snapshot.PriceAdjustment = drift.PriceAdjustment
+	snapshot.CalculatedAt = time.Now()
+
+	if err := s.intentRepo.UpdateIntentBusPricing(intent.ID, &busIntent, drift.CurrentBusFare, drift.CurrentTotal, snapshot); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"intent_id":     intent.ID,
+		"changed_seats": len(drift.Seats),
+		"old_total":     drift.HeldTotal,
+		"new_total":     drift.CurrentTotal,
+	}).Info("Passenger accepted changed seat prices")
+
+	intent.BusIntent = &busIntent
+	intent.BusFare = drift.CurrentBusFare
+	intent.TotalAmount = drift.CurrentTotal
+	intent.PricingSnapshot = snapshot
+	return s.buildIntentResponse(intent), nil
+}
+
+// checkPriceDrift compares the intent's held seat prices with the trip's current prices
+func (s *BookingOrchestratorService) checkPriceDrift(intent *models.BookingIntent) (*models.IntentPriceDrift, error) {
+	if intent.BusIntent == nil || len(intent.BusIntent.Seats) == 0 {
+		return nil, nil
+	}
+
+	seatIDs := make([]string, len(intent.BusIntent.Seats))
+	for i, seat := range intent.BusIntent.Seats {
+		seatIDs[i] = seat.TripSeatID
+	}
+	seats, err := s.tripSeatRepo.GetByIDs(seatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current seat prices: %w", err)
+	}
+
+	adjustment, err := s.priceAdjustments.ActiveForTrip(intent.BusIntent.ScheduledTripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip price adjustment: %w", err)
+	}
+
+	current := make(map[string]float64, len(seats))
+	var adjustedBy models.Money
+	for _, seat := range seats {
+		current[seat.ID] = busSeatFare(seat, adjustment)
+		adjustedBy = adjustedBy.Add(models.NewMoney(current[seat.ID]).Sub(models.NewMoney(seat.SeatPrice)))
+	}
+	drift := models.DetectPriceDrift(intent, current)
+	if drift != nil {
+		drift.Policy = s.config.PriceDriftPolicy
+		drift.PriceAdjustment = adjustment.Applied(adjustedBy.Float64())
+	}
+	return drift, nil
+}
+
+// newPriceDriftError asks the passenger to accept changed prices before paying
+func newPriceDriftError(drift *models.IntentPriceDrift) error {
+	return &models.PriceDriftError{
+		Drift:   drift,
+		Message: "Seat prices have changed since your seats were held. Please review and accept the new prices to continue.",
+	}
+}
+
+// busSeatPrices locks each seat's price in the intent's pricing snapshot
+func busSeatPrices(busIntent *models.BusIntentPayload) map[string]models.Money {
+	if busIntent == nil {
+		return nil
+	}
+	prices := make(map[string]models.Money, len(busIntent.Seats))
+	for _, seat := range busIntent.Seats {
+		prices[seat.TripSeatID] = models.NewMoney(seat.SeatPrice)
+	}
+	return prices
+}
+
 // ============================================================================
 // CONFIRM BOOKING (Phase 3)
 // ============================================================================
 
-// ConfirmBooking confirms a booking intent after payment
+var (
+	// ErrIntentTenantMismatch is returned when an intent is paid through another operator's app
+	ErrIntentTenantMismatch = errors.New("intent belongs to another operator's app")
+
+	// ErrIntentConfirmationInProgress is returned when another request is already confirming the intent
+	ErrIntentConfirmationInProgress = errors.New("intent confirmation already in progress")
+
+	// ErrIntentPaymentNotVerified is returned when the app confirms an intent the gateway has not
+	// reported paid in full
+	ErrIntentPaymentNotVerified = errors.New("payment has not been verified")
+)
+
+// ConfirmBooking confirms a booking intent for the passenger app once it is paid. The client's
+// payment reference is not trusted: the intent must already be marked paid (by the wallet, the
+// webhook or reconciliation), or PAYable's CheckStatus must report its payment paid in full.
+// It is safe to call more than once: only one caller claims the intent, and callers after it
+// get the confirmed bookings.
 func (s *BookingOrchestratorService) ConfirmBooking(
 	intentID uuid.UUID,
 	userID uuid.UUID,
 	paymentReference *string,
+) (*models.ConfirmBookingResponse, error) {
+	return s.confirmBooking(intentID, userID, func(intent *models.BookingIntent) error {
+		return s.verifyIntentPayment(intent, paymentReference)
+	})
+}
+
+// ConfirmPaidBooking confirms an intent whose payment the caller has already verified: the
+// payment webhook and reconciliation, which check the payment with CheckStatus, and
+// reconciliation of intents paid in full from the wallet
+func (s *BookingOrchestratorService) ConfirmPaidBooking(intentID, userID uuid.UUID) (*models.ConfirmBookingResponse, error) {
+	return s.confirmBooking(intentID, userID, func(intent *models.BookingIntent) error {
+		if err := s.intentRepo.UpdateIntentPaymentSuccess(intent.ID); err != nil {
+			s.logger.WithError(err).Warn("Failed to update payment status")
+		}
+		return nil
+	})
+}
+
+// confirmBooking confirms an intent once verifyPayment accepts its payment
+func (s *BookingOrchestratorService) confirmBooking(
+	intentID uuid.UUID,
+	userID uuid.UUID,
+	verifyPayment func(intent *models.BookingIntent) error,
 ) (*models.ConfirmBookingResponse, error) {
 	// 1. Get intent
 	intent, err := s.intentRepo.GetIntentByID(intentID)
@@ -609,152 +1069,159 @@ func (s *BookingOrchestratorService) ConfirmBooking(
 		return nil, fmt.Errorf("intent cannot be confirmed (status: %s)", intent.Status)
 	}
 
-	// 5. Verify payment (in production, would check with gateway)
-	// For now, we trust the payment reference
-	if paymentReference != nil && *paymentReference != "" {
-		if err := s.intentRepo.UpdateIntentPaymentSuccess(intent.ID); err != nil {
-			s.logger.WithError(err).Warn("Failed to update payment status")
-		}
+	// 5. Verify payment
+	if err := verifyPayment(intent); err != nil {
+		return nil, err
 	}
 
-	// 6. Mark as confirming
-	if err := s.intentRepo.UpdateIntentStatus(intent.ID, models.IntentStatusConfirming); err != nil {
-		return nil, fmt.Errorf("failed to update intent status: %w", err)
+	// Revalidate the held seat prices. The passenger has been charged the held price, so it
+	// is always honored; an unpaid hold is sent back for re-acceptance when payment starts.
+	priceDrift, err := s.checkPriceDrift(intent)
+	if err != nil {
+		s.logger.WithError(err).WithField("intent_id", intent.ID).Warn("Failed to revalidate intent prices - honoring held prices")
+	}
+	if priceDrift != nil {
+		s.logger.WithFields(logrus.Fields{
+			"intent_id":        intent.ID,
+			"policy":           priceDrift.Policy,
+			"changed_seats":    len(priceDrift.Seats),
+			"held_bus_fare":    priceDrift.HeldBusFare,
+			"current_bus_fare": priceDrift.CurrentBusFare,
+		}).Warn("Seat prices changed during hold - honoring held prices")
 	}
 
-	// 7. Create actual bookings in a transaction
-	var busBookingID, preLoungeBookingID, postLoungeBookingID *uuid.UUID
-	var masterRef string
-	var masterBookingID *uuid.UUID
-
-	// Create bus booking if present
-	if intent.BusIntent != nil {
-		busBooking, bookingRef, masterID, err := s.createBusBookingFromIntent(intent)
+	// 6. Claim the intent so a concurrent or replayed confirmation cannot create the bookings twice
+	claimed, err := s.intentRepo.ClaimIntentForConfirmation(intent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update intent status: %w", err)
+	}
+	if !claimed {
+		current, err := s.intentRepo.GetIntentByID(intent.ID)
 		if err != nil {
-			// Mark as confirmation failed
-			s.intentRepo.UpdateIntentConfirmationFailed(intent.ID)
-			return nil, fmt.Errorf("failed to create bus booking: %w", err)
+			return nil, fmt.Errorf("failed to get intent: %w", err)
 		}
-		busBookingUUID, _ := uuid.Parse(busBooking.ID)
-		busBookingID = &busBookingUUID
-		masterRef = bookingRef
-		masterBookingID = masterID
-	}
-
-	// Create pre-trip lounge booking if present
-	if intent.PreTripLoungeIntent != nil {
-		// Determine booking type: standalone for lounge-only, pre_trip when with bus
-		loungeBookingType := "pre_trip"
-		if intent.IntentType == models.IntentTypeLoungeOnly {
-			loungeBookingType = "standalone"
+		if current != nil && current.Status == models.IntentStatusConfirmed {
+			return s.buildConfirmResponse(current), nil
 		}
-
-		s.logger.WithFields(logrus.Fields{
-			"intent_id":    intent.ID,
-			"lounge_id":    intent.PreTripLoungeIntent.LoungeID,
-			"lounge_name":  intent.PreTripLoungeIntent.LoungeName,
-			"total_price":  intent.PreTripLoungeIntent.TotalPrice,
-			"booking_type": loungeBookingType,
-		}).Info("Creating lounge booking from intent")
-
-		preLoungeBooking, err := s.createLoungeBookingFromIntent(intent, intent.PreTripLoungeIntent, loungeBookingType, masterBookingID, busBookingID)
-		if err != nil {
-			s.logger.WithFields(logrus.Fields{
-				"error":        err.Error(),
-				"intent_id":    intent.ID,
-				"lounge_id":    intent.PreTripLoungeIntent.LoungeID,
-				"booking_type": loungeBookingType,
-			}).Error("Failed to create lounge booking")
-
-			// For lounge_only intents, if lounge booking fails, the whole intent fails
-			if intent.IntentType == models.IntentTypeLoungeOnly {
-				s.intentRepo.UpdateIntentConfirmationFailed(intent.ID)
-				return nil, fmt.Errorf("failed to create lounge booking: %w", err)
-			}
-			// For combined intents, continue - at least bus booking is created
-		} else {
-			id := preLoungeBooking.ID
-			preLoungeBookingID = &id
-			s.logger.WithFields(logrus.Fields{
-				"pre_lounge_booking_id": id,
-				"booking_reference":     preLoungeBooking.BookingReference,
-			}).Info("Pre-trip lounge booking created successfully")
-			if masterRef == "" {
-				masterRef = preLoungeBooking.BookingReference
-			}
+		if current != nil && current.Status == models.IntentStatusConfirming {
+			return nil, ErrIntentConfirmationInProgress
 		}
-	} else {
-		s.logger.WithField("intent_id", intent.ID).Info("No pre-trip lounge intent found - skipping lounge booking creation")
-	}
-
-	// Create post-trip lounge booking if present
-	if intent.PostTripLoungeIntent != nil {
-		postLoungeBooking, err := s.createLoungeBookingFromIntent(intent, intent.PostTripLoungeIntent, "post_trip", masterBookingID, busBookingID)
-		if err != nil {
-			s.logger.WithFields(logrus.Fields{
-				"error":     err.Error(),
-				"intent_id": intent.ID,
-				"lounge_id": intent.PostTripLoungeIntent.LoungeID,
-			}).Error("Failed to create post-trip lounge booking")
-		} else {
-			id := postLoungeBooking.ID
-			postLoungeBookingID = &id
-			if masterRef == "" {
-				masterRef = postLoungeBooking.BookingReference
-			}
+		status := intent.Status
+		if current != nil {
+			status = current.Status
 		}
+		return nil, fmt.Errorf("intent cannot be confirmed (status: %s)", status)
 	}
 
-	// 8. Mark intent as confirmed
-	if err := s.intentRepo.UpdateIntentConfirmed(intent.ID, busBookingID, preLoungeBookingID, postLoungeBookingID); err != nil {
-		return nil, fmt.Errorf("failed to mark intent as confirmed: %w", err)
+	// 7. Create the bookings and mark the intent confirmed, saving booking.confirmed to the
+	// outbox with it. Its consumers (boarding reminders, the booking snapshot) run from the
+	// outbox dispatcher, and are retried there until they succeed. A failure releases the
+	// claim so the confirmation can be retried.
+	bookings, err := s.confirmer().confirm(intent)
+	if err != nil {
+		return nil, err
 	}
+	masterBookingID, preLoungeBookingID := bookings.masterBookingID, bookings.preLoungeBookingID
 
-	// 9. Confirm lounge holds (convert from held to confirmed)
-	s.intentRepo.ConfirmLoungeHoldsForIntent(intent.ID)
-
-	// 10. Update lounge booking statuses and payment status to confirmed/paid
-	if preLoungeBookingID != nil {
-		if err := s.loungeBookingRepo.UpdateLoungeBookingStatus(*preLoungeBookingID, models.LoungeBookingStatusConfirmed); err != nil {
-			s.logger.WithError(err).WithField("lounge_booking_id", preLoungeBookingID).Error("Failed to update pre-lounge booking status")
-		}
-		if err := s.loungeBookingRepo.UpdatePaymentStatus(*preLoungeBookingID, models.LoungePaymentPaid); err != nil {
-			s.logger.WithError(err).WithField("lounge_booking_id", preLoungeBookingID).Error("Failed to update pre-lounge payment status")
+	// 8. Complete the promo code's reserved use now that the bookings it discounted exist
+	if promo := intent.PricingSnapshot.DiscountApplied; promo != nil && promo.PromoCodeID != "" {
+		intentIDStr := intent.ID.String()
+		redemption := &models.PromoCodeRedemption{
+			PromoCodeID:    promo.PromoCodeID,
+			UserID:         intent.UserID.String(),
+			IntentID:       &intentIDStr,
+			DiscountAmount: promo.DiscountAmount.Float64(),
 		}
-	}
-	if postLoungeBookingID != nil {
-		if err := s.loungeBookingRepo.UpdateLoungeBookingStatus(*postLoungeBookingID, models.LoungeBookingStatusConfirmed); err != nil {
-			s.logger.WithError(err).WithField("lounge_booking_id", postLoungeBookingID).Error("Failed to update post-lounge booking status")
-		}
-		if err := s.loungeBookingRepo.UpdatePaymentStatus(*postLoungeBookingID, models.LoungePaymentPaid); err != nil {
-			s.logger.WithError(err).WithField("lounge_booking_id", postLoungeBookingID).Error("Failed to update post-lounge payment status")
+		if masterBookingID != nil {
+			id := masterBookingID.String()
+			redemption.MasterBookingID = &id
+		} else if preLoungeBookingID != nil {
+			id := preLoungeBookingID.String()
+			redemption.LoungeBookingID = &id
 		}
+		s.promoCodes.Redeem(redemption)
 	}
 
-	// 11. Refresh intent to get booking IDs
+	// 9. Refresh intent to get booking IDs
 	intent, _ = s.intentRepo.GetIntentByID(intentID)
 
 	s.logger.WithFields(logrus.Fields{
 		"intent_id":              intentID,
-		"master_reference":       masterRef,
-		"bus_booking_id":         busBookingID,
+		"master_reference":       bookings.masterRef,
+		"bus_booking_id":         bookings.busBookingID,
 		"pre_lounge_booking_id":  preLoungeBookingID,
-		"post_lounge_booking_id": postLoungeBookingID,
+		"post_lounge_booking_id": bookings.postLoungeBookingID,
 	}).Info("Booking confirmed successfully")
 
-	return s.buildConfirmResponse(intent), nil
+	response := s.buildConfirmResponse(intent)
+	response.PriceDrift = priceDrift
+	return response, nil
+}
+
+// verifyIntentPayment checks that an intent the app confirms has been paid. A payment the
+// webhook or reconciliation has not recorded yet is checked with CheckStatus on the merchant
+// the intent was paid to. Without PAYable configured payments are placeholders, and a payment
+// reference is all there is to go on.
+func (s *BookingOrchestratorService) verifyIntentPayment(intent *models.BookingIntent, paymentReference *string) error {
+	if intent.PaymentStatus != nil && *intent.PaymentStatus == models.IntentPaymentSuccess {
+		return nil
+	}
+
+	if s.payableService == nil || !s.payableService.IsConfigured() {
+		if paymentReference == nil || *paymentReference == "" {
+			return ErrIntentPaymentNotVerified
+		}
+		s.logger.WithField("intent_id", intent.ID).Warn("PAYable service not configured - accepting placeholder payment reference")
+		return s.intentRepo.UpdateIntentPaymentSuccess(intent.ID)
+	}
+
+	if intent.PaymentUID == nil || intent.PaymentStatusIndicator == nil {
+		return ErrIntentPaymentNotVerified
+	}
+	payable, _, err := s.payableForIntent(intent.ID)
+	if err != nil {
+		return err
+	}
+	statusResp, err := payable.CheckStatus(*intent.PaymentUID, *intent.PaymentStatusIndicator)
+	if err != nil {
+		return fmt.Errorf("failed to verify payment: %w", err)
+	}
+	received, _ := models.ParseMoney(statusResp.GetAmount())
+	if !paidInFull(statusResp.GetPaymentStatus(), received, intent.AmountDue()) {
+		s.logger.WithFields(logrus.Fields{
+			"intent_id":       intent.ID,
+			"uid":             *intent.PaymentUID,
+			"payment_status":  statusResp.GetPaymentStatus(),
+			"expected_amount": intent.AmountDue().String(),
+			"received_amount": received.String(),
+		}).Warn("Payment not verified by PAYable - not confirming intent")
+		return ErrIntentPaymentNotVerified
+	}
+	return s.intentRepo.UpdateIntentPaymentSuccess(intent.ID)
+}
+
+// paidInFull reports whether a gateway payment status and amount show amountDue paid
+func paidInFull(paymentStatus string, received, amountDue models.Money) bool {
+	return strings.ToUpper(paymentStatus) == "SUCCESS" && received.Equal(amountDue)
 }
 
 // createBusBookingFromIntent creates a bus booking from intent data
 func (s *BookingOrchestratorService) createBusBookingFromIntent(intent *models.BookingIntent) (*models.BusBooking, string, *uuid.UUID, error) {
 	busIntent := intent.BusIntent
 
-	// Determine booking type based on lounge intents
+	// Determine booking type based on lounge intents; the master booking carries the promo
+	// discount on the fares it totals
+	promo := intent.PricingSnapshot.DiscountApplied
 	bookingType := models.BookingTypeBusOnly
-	totalAmount := intent.BusFare
+	totalAmount := models.NewMoney(intent.BusFare)
+	var discount models.Money
+	if promo != nil {
+		discount = promo.BusDiscount
+	}
 	if intent.PreTripLoungeIntent != nil || intent.PostTripLoungeIntent != nil {
 		bookingType = models.BookingTypeBusWithLounge
-		totalAmount = intent.TotalAmount
+		totalAmount = models.NewMoney(intent.TotalAmount).Add(intent.PromoDiscount())
+		discount = intent.PromoDiscount()
 	}
 
 	// Build master booking
@@ -762,8 +1229,9 @@ func (s *BookingOrchestratorService) createBusBookingFromIntent(intent *models.B
 		UserID:         intent.UserID.String(),
 		BookingType:    bookingType,
 		BusTotal:       intent.BusFare,
-		Subtotal:       totalAmount,
-		TotalAmount:    totalAmount,
+		Subtotal:       totalAmount.Float64(),
+		DiscountAmount: discount.Float64(),
+		TotalAmount:    totalAmount.Sub(discount).Float64(),
 		PaymentStatus:  models.MasterPaymentPaid, // Paid via intent
 		BookingStatus:  models.MasterBookingConfirmed,
 		PassengerName:  busIntent.PassengerName,
@@ -771,6 +1239,11 @@ func (s *BookingOrchestratorService) createBusBookingFromIntent(intent *models.B
 		PassengerEmail: busIntent.PassengerEmail,
 		BookingSource:  models.BookingSourceApp,
 	}
+	if promo != nil {
+		masterBooking.PromoCode = &promo.Code
+		masterBooking.PromoDiscountType = &promo.DiscountType
+		masterBooking.PromoDiscountValue = promo.DiscountValue
+	}
 
 	// Build bus booking
 	busBooking := &models.BusBooking{
@@ -778,7 +1251,7 @@ func (s *BookingOrchestratorService) createBusBookingFromIntent(intent *models.B
 		BoardingStopID:  busIntent.BoardingStopID,
 		AlightingStopID: busIntent.AlightingStopID,
 		NumberOfSeats:   len(busIntent.Seats),
-		FarePerSeat:     intent.BusFare / float64(len(busIntent.Seats)),
+		FarePerSeat:     models.NewMoney(intent.BusFare).Div(len(busIntent.Seats)).Float64(),
 		TotalFare:       intent.BusFare,
 		Status:          models.BusBookingConfirmed,
 	}
@@ -798,7 +1271,7 @@ func (s *BookingOrchestratorService) createBusBookingFromIntent(intent *models.B
 			Status:             models.SeatBookingBooked,
 			SeatNumber:         intentSeat.SeatNumber,
 			SeatType:           intentSeat.SeatType,
-			SeatPrice:          intentSeat.SeatPrice,
+			SeatPrice:          intent.HeldSeatPrice(intentSeat),
 		}
 	}
 
@@ -817,11 +1290,47 @@ func (s *BookingOrchestratorService) createBusBookingFromIntent(intent *models.B
 	return response.BusBooking, response.Booking.BookingReference, &masterID, nil
 }
 
-// createLoungeBookingFromIntent creates a lounge booking from intent data
+// existingBusBooking looks up the reference and master booking of a bus booking an earlier
+// attempt at confirming its intent created
+func (s *BookingOrchestratorService) existingBusBooking(busBookingID uuid.UUID) (string, *uuid.UUID, error) {
+	busBooking, err := s.appBookingRepo.GetBusBookingByID(busBookingID.String())
+	if err != nil {
+		return "", nil, err
+	}
+	if busBooking == nil {
+		return "", nil, fmt.Errorf("bus booking %s not found", busBookingID)
+	}
+	master, err := s.appBookingRepo.GetBookingByID(busBooking.BookingID)
+	if err != nil {
+		return "", nil, err
+	}
+	if master == nil {
+		return "", nil, fmt.Errorf("booking %s not found", busBooking.BookingID)
+	}
+	masterID, _ := uuid.Parse(master.ID)
+	return master.BookingReference, &masterID, nil
+}
+
+// existingLoungeBooking looks up a lounge booking an earlier attempt at confirming its intent
+// created
+func (s *BookingOrchestratorService) existingLoungeBooking(loungeBookingID uuid.UUID) (*models.LoungeBooking, error) {
+	booking, err := s.loungeBookingRepo.GetLoungeBookingByID(loungeBookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking == nil {
+		return nil, fmt.Errorf("lounge booking %s not found", loungeBookingID)
+	}
+	return booking, nil
+}
+
+// createLoungeBookingFromIntent creates a lounge booking from intent data, less its share of
+// any promo discount
 func (s *BookingOrchestratorService) createLoungeBookingFromIntent(
 	intent *models.BookingIntent,
 	loungeIntent *models.LoungeIntentPayload,
 	bookingType string,
+	promoDiscount models.Money,
 	masterBookingID *uuid.UUID,
 	busBookingID *uuid.UUID,
 ) (*models.LoungeBooking, error) {
@@ -853,14 +1362,25 @@ func (s *BookingOrchestratorService) createLoungeBookingFromIntent(
 		ScheduledArrival: scheduledArrival,
 		NumberOfGuests:   loungeIntent.GuestCount,
 		PricingType:      loungeIntent.PricingType,
-		PricePerGuest:    fmt.Sprintf("%.2f", loungeIntent.PricePerGuest),
-		BasePrice:        fmt.Sprintf("%.2f", loungeIntent.BasePrice),
-		PreOrderTotal:    fmt.Sprintf("%.2f", loungeIntent.PreOrderTotal),
+		PricePerGuest:    models.NewMoney(loungeIntent.PricePerGuest).String(),
+		BasePrice:        models.NewMoney(loungeIntent.BasePrice).String(),
+		PreOrderTotal:    models.NewMoney(loungeIntent.PreOrderTotal).String(),
 		DiscountAmount:   "0.00", // Default to zero discount
-		TotalAmount:      fmt.Sprintf("%.2f", loungeIntent.TotalPrice),
+		TotalAmount:      models.NewMoney(loungeIntent.TotalPrice).String(),
 		LoungeName:       loungeIntent.LoungeName,
 		PrimaryGuestName: loungeIntent.Guests[0].GuestName,
 	}
+	if discount := loungeIntent.BundleDiscount; discount != nil {
+		booking.DiscountAmount = models.NewMoney(discount.Amount).String()
+		booking.DiscountFundedBy = &discount.FundedBy
+		booking.BundleDiscountRuleID = &discount.RuleID
+	}
+	if promoDiscount.IsPositive() {
+		amount := promoDiscount.String()
+		booking.PromoDiscountAmount = &amount
+		booking.TotalAmount = models.NewMoney(loungeIntent.TotalPrice).Sub(promoDiscount).String()
+		booking.PromoCode = sql.NullString{String: intent.PricingSnapshot.DiscountApplied.Code, Valid: true}
+	}
 
 	if loungeIntent.Guests[0].GuestPhone != nil {
 		booking.PrimaryGuestPhone = *loungeIntent.Guests[0].GuestPhone
@@ -894,8 +1414,8 @@ func (s *BookingOrchestratorService) createLoungeBookingFromIntent(
 			ProductName: po.ProductName,
 			ProductType: po.ProductType,
 			Quantity:    po.Quantity,
-			UnitPrice:   fmt.Sprintf("%.2f", po.UnitPrice),
-			TotalPrice:  fmt.Sprintf("%.2f", po.TotalPrice),
+			UnitPrice:   models.NewMoney(po.UnitPrice).String(),
+			TotalPrice:  models.NewMoney(po.TotalPrice).String(),
 		}
 	}
 
@@ -933,11 +1453,15 @@ func (s *BookingOrchestratorService) GetIntentStatus(
 			BusFare:        intent.BusFare,
 			PreLoungeFare:  intent.PreLoungeFare,
 			PostLoungeFare: intent.PostLoungeFare,
+			BundleDiscount: intent.BundleDiscountTotal(),
+			PromoCode:      intent.PromoCode(),
+			PromoDiscount:  intent.PromoDiscount().Float64(),
 			Total:          intent.TotalAmount,
 			Currency:       intent.Currency,
 		},
-		ExpiresAt: intent.ExpiresAt,
-		IsExpired: intent.IsExpired(),
+		ExpiresAt:      intent.ExpiresAt,
+		IsExpired:      intent.IsExpired(),
+		HoldTTLSeconds: intent.HoldTTLSeconds,
 	}
 
 	// Include bookings if confirmed
@@ -957,6 +1481,49 @@ func (s *BookingOrchestratorService) GetIntentByPaymentUID(uid string) (*models.
 	return s.intentRepo.GetIntentByPaymentUID(uid)
 }
 
+// GetIntentByPaymentReference retrieves an intent by its payment reference, the invoice ID
+// sent to the gateway
+func (s *BookingOrchestratorService) GetIntentByPaymentReference(paymentRef string) (*models.BookingIntent, error) {
+	return s.intentRepo.GetIntentByPaymentReference(paymentRef)
+}
+
+// PayableForIntent returns the gateway client for the merchant account that took the intent's
+// payment: its trip operator's, when the operator collects payments into its own account
+func (s *BookingOrchestratorService) PayableForIntent(intentID uuid.UUID) (*PAYableService, error) {
+	payable, _, err := s.payableForIntent(intentID)
+	return payable, err
+}
+
+// payableForIntent is PayableForIntent, also returning the tenant whose merchant account is
+// used (nil for the platform account)
+func (s *BookingOrchestratorService) payableForIntent(intentID uuid.UUID) (*PAYableService, *models.Tenant, error) {
+	tenantID, err := s.intentRepo.GetIntentTenantID(intentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get intent tenant: %w", err)
+	}
+	if tenantID == nil || s.tenantRepo == nil {
+		return s.payableService, nil, nil
+	}
+	tenant, err := s.tenantRepo.GetByID(*tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tenant != nil && tenant.HasPaymentAccount() {
+		return s.payableService.WithMerchant(*tenant.PaymentMerchantKey, *tenant.PaymentMerchantToken), tenant, nil
+	}
+	return s.payableService, nil, nil
+}
+
+// intentTenantMatches reports whether an intent whose trip belongs to intentTenantID (nil for
+// SmartTransit) may be paid through tenant's app
+func intentTenantMatches(intentTenantID *string, tenant *models.Tenant) bool {
+	scopeID := tenant.ScopeID()
+	if intentTenantID == nil || scopeID == nil {
+		return intentTenantID == nil && scopeID == nil
+	}
+	return *intentTenantID == *scopeID
+}
+
 // ============================================================================
 // ADD LOUNGE TO EXISTING INTENT
 // ============================================================================
@@ -1037,13 +1604,30 @@ func (s *BookingOrchestratorService) AddLoungeToIntent(
 		return parsed
 	}
 
+	// Lounges added to a bus booking get the bundle discount; it is worked out here rather than
+	// taken from the request
+	if intent.BusIntent != nil && preTripLounge != nil {
+		if err := s.bundleDiscounts.Apply(preTripLounge, "pre_trip", time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to apply bundle discount: %w", err)
+		}
+	}
+	if intent.BusIntent != nil && postTripLounge != nil {
+		if err := s.bundleDiscounts.Apply(postTripLounge, "post_trip", time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to apply bundle discount: %w", err)
+		}
+	}
+
+	// Adding a lounge turns the intent into a combined one, so use the combined TTL
+	// for the tier recorded on the intent
+	holdTTL := s.config.TTLPolicy.TTLFor(models.IntentTypeCombined, intent.ReliabilityTier, s.config.IntentTTL)
+
 	// 2. Calculate additional lounge fares
 	var preLoungeFare, postLoungeFare float64
 
 	if preTripLounge != nil {
 		preLoungeFare = preTripLounge.TotalPrice
 		// Create lounge capacity hold using actual lounge date/time
-		expiresAt := time.Now().Add(s.config.IntentTTL)
+		expiresAt := time.Now().Add(holdTTL)
 		loungeID, _ := uuid.Parse(preTripLounge.LoungeID)
 
 		loungeDate := parseLoungeDate(preTripLounge.Date)
@@ -1073,7 +1657,7 @@ func (s *BookingOrchestratorService) AddLoungeToIntent(
 	if postTripLounge != nil {
 		postLoungeFare = postTripLounge.TotalPrice
 		// Create lounge capacity hold using actual lounge date/time
-		expiresAt := time.Now().Add(s.config.IntentTTL)
+		expiresAt := time.Now().Add(holdTTL)
 		loungeID, _ := uuid.Parse(postTripLounge.LoungeID)
 
 		loungeDate := parseLoungeDate(postTripLounge.Date)
@@ -1100,9 +1684,14 @@ func (s *BookingOrchestratorService) AddLoungeToIntent(
 		}
 	}
 
-	// 3. Update intent with lounge data
-	newTotal := intent.BusFare + preLoungeFare + postLoungeFare
-	newExpiresAt := time.Now().Add(s.config.IntentTTL) // Extend the hold timer
+	// 3. Update intent with lounge data. A promo discount stays as it was quoted when the intent
+	// was created, so added lounges are charged in full.
+	newTotal := models.NewMoney(intent.BusFare).
+		Add(models.NewMoney(preLoungeFare)).
+		Add(models.NewMoney(postLoungeFare)).
+		Sub(intent.PromoDiscount()).
+		Float64()
+	newExpiresAt := time.Now().Add(holdTTL) // Extend the hold timer
 
 	s.logger.WithFields(logrus.Fields{
 		"intent_id":        intent.ID,
@@ -1121,6 +1710,7 @@ func (s *BookingOrchestratorService) AddLoungeToIntent(
 		postLoungeFare,
 		newTotal,
 		newExpiresAt,
+		int(holdTTL.Seconds()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update intent with lounges: %w", err)
@@ -1192,14 +1782,34 @@ func (s *BookingOrchestratorService) CancelIntent(intentID uuid.UUID, userID uui
 	// Release all holds
 	s.rollbackHolds(intentID)
 
-	// Mark as cancelled
-	return s.intentRepo.UpdateIntentCancelled(intentID)
+	// Mark as cancelled and return any wallet payment
+	if err := s.intentRepo.UpdateIntentCancelled(intentID); err != nil {
+		return err
+	}
+	s.wallets.ReleaseIntent(intentID, intent.UserID)
+	return nil
 }
 
 // ============================================================================
 // HELPER METHODS
 // ============================================================================
 
+// resolveHoldTTL picks the hold TTL for a new intent from the user's abandonment history.
+// Falls back to the standard tier if the history cannot be loaded.
+func (s *BookingOrchestratorService) resolveHoldTTL(userID uuid.UUID, intentType models.BookingIntentType) (time.Duration, models.ReliabilityTier) {
+	policy := s.config.TTLPolicy
+	tier := models.ReliabilityTierStandard
+
+	stats, err := s.intentRepo.GetUserAbandonmentStats(userID, time.Now().Add(-policy.AbandonmentLookback))
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Warn("Failed to load intent abandonment stats - using standard tier")
+	} else {
+		tier = policy.TierFor(stats)
+	}
+
+	return policy.TTLFor(intentType, tier, s.config.IntentTTL), tier
+}
+
 func (s *BookingOrchestratorService) rollbackHolds(intentID uuid.UUID) {
 	if err := s.intentRepo.ReleaseSeatHoldsForIntent(intentID); err != nil {
 		s.logger.WithError(err).WithField("intent_id", intentID).Error("Failed to release seat holds")
@@ -1222,6 +1832,9 @@ func (s *BookingOrchestratorService) buildIntentResponse(intent *models.BookingI
 			BusFare:        intent.BusFare,
 			PreLoungeFare:  intent.PreLoungeFare,
 			PostLoungeFare: intent.PostLoungeFare,
+			BundleDiscount: intent.BundleDiscountTotal(),
+			PromoCode:      intent.PromoCode(),
+			PromoDiscount:  intent.PromoDiscount().Float64(),
 			Total:          intent.TotalAmount,
 			Currency:       intent.Currency,
 		},
@@ -1232,10 +1845,19 @@ func (s *BookingOrchestratorService) buildIntentResponse(intent *models.BookingI
 	}
 }
 
+// optionalUUIDString formats an optional ID, empty when unset
+func optionalUUIDString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
 func (s *BookingOrchestratorService) buildConfirmResponse(intent *models.BookingIntent) *models.ConfirmBookingResponse {
 	response := &models.ConfirmBookingResponse{
-		TotalPaid: intent.TotalAmount,
-		Currency:  intent.Currency,
+		TotalPaid:      intent.TotalAmount,
+		PaidFromWallet: intent.WalletAmount,
+		Currency:       intent.Currency,
 	}
 
 	s.logger.WithFields(logrus.Fields{
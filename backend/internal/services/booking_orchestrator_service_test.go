@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaidInFull(t *testing.T) {
+	due := models.NewMoney(1500)
+
+	assert.True(t, paidInFull("SUCCESS", models.NewMoney(1500), due))
+	assert.True(t, paidInFull("success", models.NewMoney(1500.001), due), "compared to the cent")
+	assert.False(t, paidInFull("PENDING", models.NewMoney(1500), due))
+	assert.False(t, paidInFull("FAILED", models.NewMoney(1500), due))
+	assert.False(t, paidInFull("SUCCESS", models.NewMoney(15), due), "a cheaper payment does not pay for the intent")
+	assert.False(t, paidInFull("SUCCESS", models.Money{}, due), "missing amount")
+}
+
+func TestVerifyIntentPayment_DoesNotTrustTheClientReference(t *testing.T) {
+	reference := "TXN-made-up"
+	paid := models.IntentPaymentSuccess
+	gateway := NewPAYableService(&config.PaymentConfig{MerchantKey: "key", MerchantToken: "token"}, logrus.New())
+	s := &BookingOrchestratorService{payableService: gateway, logger: logrus.New()}
+
+	// Held and never sent to the gateway: nothing to check, so nothing is confirmed
+	held := &models.BookingIntent{Status: models.IntentStatusHeld}
+	assert.ErrorIs(t, s.verifyIntentPayment(held, &reference), ErrIntentPaymentNotVerified)
+	assert.ErrorIs(t, s.verifyIntentPayment(held, nil), ErrIntentPaymentNotVerified)
+
+	// Already recorded as paid by the wallet, the webhook or reconciliation
+	recorded := &models.BookingIntent{Status: models.IntentStatusPaymentPending, PaymentStatus: &paid}
+	assert.NoError(t, s.verifyIntentPayment(recorded, nil))
+
+	// Placeholder payments without PAYable still need a reference
+	placeholder := &BookingOrchestratorService{logger: logrus.New()}
+	assert.ErrorIs(t, placeholder.verifyIntentPayment(held, nil), ErrIntentPaymentNotVerified)
+}
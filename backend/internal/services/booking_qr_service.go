@@ -0,0 +1,348 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrBookingQRMalformed        = errors.New("boarding QR is malformed")
+	ErrBookingQRUnknownKey       = errors.New("boarding QR is signed with an unknown or expired key")
+	ErrBookingQRSignatureInvalid = errors.New("boarding QR signature is invalid")
+	ErrBookingQRExpired          = errors.New("boarding QR has expired")
+	ErrBookingQRUnsigned         = errors.New("boarding QR is not signed")
+)
+
+// qrKeyAlgorithm is the signature scheme of boarding QRs
+const qrKeyAlgorithm = "ed25519"
+
+// qrKeyMinReload limits the reloads that QRs naming an unknown key can force
+const qrKeyMinReload = 10 * time.Second
+
+// qrSigningKey is a loaded signing key
+type qrSigningKey struct {
+	record  models.QRSigningKey
+	public  ed25519.PublicKey
+	private ed25519.PrivateKey
+}
+
+// BookingQRService signs boarding QRs so conductor apps can verify them offline with the
+// published public keys, and verifies them on the server. Keys are rotated on demand; a
+// rotated-out key keeps verifying the QRs it signed for the configured retention.
+type BookingQRService struct {
+	repo   *database.QRSigningKeyRepository
+	config config.BookingQRConfig
+	logger *logrus.Logger
+
+	mu       sync.RWMutex
+	keys     []qrSigningKey // Active first, then retired, newest first
+	loadedAt time.Time
+}
+
+// NewBookingQRService creates a new BookingQRService
+func NewBookingQRService(repo *database.QRSigningKeyRepository, cfg config.BookingQRConfig, logger *logrus.Logger) *BookingQRService {
+	return &BookingQRService{repo: repo, config: cfg, logger: logger}
+}
+
+// SignatureRequired reports whether unsigned QR codes are rejected at staff verification
+func (s *BookingQRService) SignatureRequired() bool {
+	return s.config.SignatureRequired
+}
+
+// IsBookingQRRejected reports whether a Verify error means the QR itself is not valid, as
+// opposed to the keys failing to load
+func IsBookingQRRejected(err error) bool {
+	for _, rejected := range []error{ErrBookingQRMalformed, ErrBookingQRUnknownKey, ErrBookingQRSignatureInvalid, ErrBookingQRExpired, ErrBookingQRUnsigned} {
+		if errors.Is(err, rejected) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureKey creates the first signing key if there is none yet
+func (s *BookingQRService) EnsureKey() error {
+	key, err := newQRSigningKey()
+	if err != nil {
+		return err
+	}
+	created, err := s.repo.CreateIfNone(key)
+	if err != nil {
+		return err
+	}
+	if created {
+		s.logger.WithField("key_id", key.ID).Info("🔏 Created boarding QR signing key")
+	}
+	return s.reload()
+}
+
+// RotateKey retires the active signing key and starts signing with a new one
+func (s *BookingQRService) RotateKey() (*models.QRVerificationKey, error) {
+	key, err := newQRSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Rotate(key); err != nil {
+		return nil, err
+	}
+	s.logger.WithField("key_id", key.ID).Info("🔏 Rotated boarding QR signing key")
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	verification := s.verificationKey(*key)
+	return &verification, nil
+}
+
+// VerificationKeys returns the public keys QRs may currently be signed with
+func (s *BookingQRService) VerificationKeys() ([]models.QRVerificationKey, error) {
+	keys, err := s.loadedKeys(false)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.QRVerificationKey, len(keys))
+	for i, key := range keys {
+		out[i] = s.verificationKey(key.record)
+	}
+	return out, nil
+}
+
+// Sign returns the signed boarding QR of a booking's bus booking
+func (s *BookingQRService) Sign(booking *models.MasterBooking) (string, error) {
+	bus := booking.BusBooking
+	if bus == nil || bus.QRCodeData == nil {
+		return "", fmt.Errorf("booking has no bus booking QR code")
+	}
+	payload := models.BookingQRPayload{
+		QRCode:          *bus.QRCodeData,
+		BusBookingID:    bus.ID,
+		ScheduledTripID: bus.ScheduledTripID,
+		PassengerHash:   models.PassengerHash(booking.PassengerName, booking.PassengerPhone),
+	}
+	for _, seat := range bus.Seats {
+		if seat.SeatNumber != "" && seat.Status != models.SeatBookingCancelled {
+			payload.Seats = append(payload.Seats, seat.SeatNumber)
+		}
+	}
+	return s.sign(bus, payload)
+}
+
+// SignSeat returns the signed boarding QR of one seat of a booking's bus booking, which admits
+// only that seat's passenger. Passengers without their own phone are hashed with the booker's.
+func (s *BookingQRService) SignSeat(booking *models.MasterBooking, seat *models.BusBookingSeat) (string, error) {
+	bus := booking.BusBooking
+	if bus == nil || seat.TicketCode == nil {
+		return "", fmt.Errorf("seat has no ticket code")
+	}
+	phone := booking.PassengerPhone
+	if seat.PassengerPhone != nil && *seat.PassengerPhone != "" {
+		phone = *seat.PassengerPhone
+	}
+	payload := models.BookingQRPayload{
+		QRCode:          *seat.TicketCode,
+		BusBookingID:    bus.ID,
+		SeatID:          seat.ID,
+		ScheduledTripID: bus.ScheduledTripID,
+		PassengerHash:   models.PassengerHash(seat.PassengerName, phone),
+	}
+	if seat.SeatNumber != "" {
+		payload.Seats = []string{seat.SeatNumber}
+	}
+	return s.sign(bus, payload)
+}
+
+// sign stamps the payload with the active key and the trip's validity and signs it
+func (s *BookingQRService) sign(bus *models.BusBooking, payload models.BookingQRPayload) (string, error) {
+	keys, err := s.loadedKeys(false)
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 || keys[0].record.Status != models.QRKeyActive {
+		return "", fmt.Errorf("no active boarding QR signing key")
+	}
+	key := keys[0]
+
+	payload.KeyID = key.record.ID
+	payload.IssuedAt = time.Now().Unix()
+	if bus.DepartureDatetime != nil {
+		payload.DepartureAt = bus.DepartureDatetime.Unix()
+		payload.ExpiresAt = bus.DepartureDatetime.Add(s.config.ValidAfterDeparture).Unix()
+	}
+	return signBookingQR(key.private, payload)
+}
+
+// Verify checks a signed boarding QR's signature, key and expiry and returns what it carries
+func (s *BookingQRService) Verify(data string) (*models.BookingQRPayload, error) {
+	if !models.IsSignedBookingQR(data) {
+		return nil, ErrBookingQRUnsigned
+	}
+	payload, signed, signature, err := parseBookingQR(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.key(payload.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(key.public, signed, signature) {
+		return nil, ErrBookingQRSignatureInvalid
+	}
+	if payload.ExpiresAt != 0 && time.Now().Unix() > payload.ExpiresAt {
+		return nil, ErrBookingQRExpired
+	}
+	return payload, nil
+}
+
+// key finds a verifiable key by ID, reloading once in case another server rotated keys
+func (s *BookingQRService) key(id string) (*qrSigningKey, error) {
+	for _, reload := range []bool{false, true} {
+		keys, err := s.loadedKeys(reload)
+		if err != nil {
+			return nil, err
+		}
+		for i := range keys {
+			if keys[i].record.ID == id {
+				return &keys[i], nil
+			}
+		}
+	}
+	return nil, ErrBookingQRUnknownKey
+}
+
+// loadedKeys returns the cached keys, reloading them when forced or stale
+func (s *BookingQRService) loadedKeys(force bool) ([]qrSigningKey, error) {
+	s.mu.RLock()
+	age := time.Since(s.loadedAt)
+	keys := s.keys
+	s.mu.RUnlock()
+	if age < s.config.KeyCacheTTL && (!force || age < qrKeyMinReload) {
+		return keys, nil
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys, nil
+}
+
+func (s *BookingQRService) reload() error {
+	records, err := s.repo.ListVerifiable(time.Now().Add(-s.config.KeyRetention))
+	if err != nil {
+		return err
+	}
+	keys := make([]qrSigningKey, 0, len(records))
+	for _, record := range records {
+		key, err := loadQRSigningKey(record)
+		if err != nil {
+			s.logger.WithError(err).WithField("key_id", record.ID).Error("Skipping unreadable boarding QR signing key")
+			continue
+		}
+		if record.Status == models.QRKeyActive {
+			keys = append([]qrSigningKey{key}, keys...)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+
+	s.mu.Lock()
+	s.keys, s.loadedAt = keys, time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *BookingQRService) verificationKey(record models.QRSigningKey) models.QRVerificationKey {
+	key := models.QRVerificationKey{
+		KeyID:     record.ID,
+		Algorithm: qrKeyAlgorithm,
+		PublicKey: record.PublicKey,
+		Status:    record.Status,
+		CreatedAt: record.CreatedAt,
+	}
+	if record.RetiredAt != nil {
+		until := record.RetiredAt.Add(s.config.KeyRetention)
+		key.ValidUntil = &until
+	}
+	return key
+}
+
+// ============================================================================
+// ENCODING
+// ============================================================================
+
+// signBookingQR encodes the payload and signs the encoded bytes
+func signBookingQR(key ed25519.PrivateKey, payload models.BookingQRPayload) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode boarding QR: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(raw)
+	signature := ed25519.Sign(key, []byte(models.SignedBookingQRPrefix+encoded))
+	return models.SignedBookingQRPrefix + encoded + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseBookingQR splits a signed boarding QR into its payload, the signed bytes and the
+// signature. The signature covers the prefix and the encoded payload.
+func parseBookingQR(data string) (*models.BookingQRPayload, []byte, []byte, error) {
+	dot := strings.LastIndexByte(data, '.')
+	if dot <= len(models.SignedBookingQRPrefix) {
+		return nil, nil, nil, ErrBookingQRMalformed
+	}
+	signed := data[:dot]
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(signed, models.SignedBookingQRPrefix))
+	if err != nil {
+		return nil, nil, nil, ErrBookingQRMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(data[dot+1:])
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, nil, nil, ErrBookingQRMalformed
+	}
+	var payload models.BookingQRPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.KeyID == "" || payload.QRCode == "" {
+		return nil, nil, nil, ErrBookingQRMalformed
+	}
+	return &payload, []byte(signed), signature, nil
+}
+
+// newQRSigningKey generates a key with an ID naming the day it was made
+func newQRSigningKey() (*models.QRSigningKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR signing key: %w", err)
+	}
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate QR signing key ID: %w", err)
+	}
+	return &models.QRSigningKey{
+		ID:         "qk" + time.Now().UTC().Format("20060102") + hex.EncodeToString(suffix),
+		PublicKey:  base64.StdEncoding.EncodeToString(public),
+		PrivateKey: base64.StdEncoding.EncodeToString(private.Seed()),
+	}, nil
+}
+
+func loadQRSigningKey(record models.QRSigningKey) (qrSigningKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(record.PrivateKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return qrSigningKey{}, fmt.Errorf("invalid QR signing key seed")
+	}
+	private := ed25519.NewKeyFromSeed(seed)
+	return qrSigningKey{
+		record:  record,
+		public:  private.Public().(ed25519.PublicKey),
+		private: private,
+	}, nil
+}
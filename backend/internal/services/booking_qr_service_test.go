@@ -0,0 +1,166 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBookingQRService returns a service with the given keys already loaded
+func newTestBookingQRService(t *testing.T, records ...*models.QRSigningKey) *BookingQRService {
+	service := NewBookingQRService(nil, config.BookingQRConfig{
+		KeyRetention:        30 * 24 * time.Hour,
+		ValidAfterDeparture: 12 * time.Hour,
+		KeyCacheTTL:         time.Hour,
+	}, logrus.New())
+	for _, record := range records {
+		key, err := loadQRSigningKey(*record)
+		require.NoError(t, err)
+		service.keys = append(service.keys, key)
+	}
+	service.loadedAt = time.Now()
+	return service
+}
+
+func newTestQRKey(t *testing.T, status models.QRKeyStatus) *models.QRSigningKey {
+	key, err := newQRSigningKey()
+	require.NoError(t, err)
+	key.Status = status
+	return key
+}
+
+func testQRBooking(departure time.Time) *models.MasterBooking {
+	qr := "QR-20260314083000-A1B2C3D4"
+	return &models.MasterBooking{
+		PassengerName:  "Nimal Perera",
+		PassengerPhone: "0771234567",
+		BusBooking: &models.BusBooking{
+			ID:                "bus-booking-1",
+			ScheduledTripID:   "trip-1",
+			QRCodeData:        &qr,
+			DepartureDatetime: &departure,
+			Seats: []models.BusBookingSeat{
+				{SeatNumber: "A1", Status: models.SeatBookingBooked},
+				{SeatNumber: "A2", Status: models.SeatBookingCancelled},
+			},
+		},
+	}
+}
+
+func TestBookingQRService_SignAndVerify(t *testing.T) {
+	active := newTestQRKey(t, models.QRKeyActive)
+	service := newTestBookingQRService(t, active)
+	departure := time.Now().Add(3 * time.Hour).Truncate(time.Second)
+
+	signed, err := service.Sign(testQRBooking(departure))
+	require.NoError(t, err)
+	assert.True(t, models.IsSignedBookingQR(signed))
+
+	payload, err := service.Verify(signed)
+	require.NoError(t, err)
+	assert.Equal(t, active.ID, payload.KeyID)
+	assert.Equal(t, "QR-20260314083000-A1B2C3D4", payload.QRCode)
+	assert.Equal(t, "trip-1", payload.ScheduledTripID)
+	assert.Equal(t, []string{"A1"}, payload.Seats)
+	assert.Equal(t, models.PassengerHash(" nimal perera", "0771234567"), payload.PassengerHash)
+	assert.Equal(t, departure.Add(12*time.Hour).Unix(), payload.ExpiresAt)
+
+	// The signature checks out with nothing but the published public key
+	public, err := base64.StdEncoding.DecodeString(active.PublicKey)
+	require.NoError(t, err)
+	dot := strings.LastIndexByte(signed, '.')
+	signature, err := base64.RawURLEncoding.DecodeString(signed[dot+1:])
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(public, []byte(signed[:dot]), signature))
+}
+
+func TestBookingQRService_SignSeat(t *testing.T) {
+	service := newTestBookingQRService(t, newTestQRKey(t, models.QRKeyActive))
+	booking := testQRBooking(time.Now().Add(3 * time.Hour))
+	ticket := "TK-0123456789ABCDEF"
+	phone := "0719876543"
+	seat := &models.BusBookingSeat{ID: "seat-2", SeatNumber: "A2", PassengerName: "Kamala Perera", PassengerPhone: &phone, TicketCode: &ticket}
+
+	signed, err := service.SignSeat(booking, seat)
+	require.NoError(t, err)
+	payload, err := service.Verify(signed)
+	require.NoError(t, err)
+	assert.Equal(t, ticket, payload.QRCode)
+	assert.Equal(t, "bus-booking-1", payload.BusBookingID)
+	assert.Equal(t, "seat-2", payload.SeatID)
+	assert.Equal(t, []string{"A2"}, payload.Seats)
+	assert.Equal(t, models.PassengerHash("Kamala Perera", phone), payload.PassengerHash)
+
+	seat.PassengerPhone = nil
+	signed, err = service.SignSeat(booking, seat)
+	require.NoError(t, err)
+	payload, err = service.Verify(signed)
+	require.NoError(t, err)
+	assert.Equal(t, models.PassengerHash("Kamala Perera", "0771234567"), payload.PassengerHash, "falls back to the booker's phone")
+
+	seat.TicketCode = nil
+	_, err = service.SignSeat(booking, seat)
+	assert.Error(t, err, "a seat needs its ticket issued first")
+}
+
+func TestBookingQRService_VerifyRejects(t *testing.T) {
+	active := newTestQRKey(t, models.QRKeyActive)
+	service := newTestBookingQRService(t, active)
+
+	signed, err := service.Sign(testQRBooking(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	// Another seat swapped into the payload
+	payload, _, _, err := parseBookingQR(signed)
+	require.NoError(t, err)
+	payload.Seats = []string{"B7"}
+	forged, err := signBookingQR(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)), *payload)
+	require.NoError(t, err)
+	_, err = service.Verify(forged)
+	assert.ErrorIs(t, err, ErrBookingQRSignatureInvalid)
+
+	_, err = service.Verify(signed[:len(signed)-4] + "AAAA")
+	assert.True(t, IsBookingQRRejected(err))
+
+	_, err = service.Verify("QR-20260314083000-A1B2C3D4")
+	assert.ErrorIs(t, err, ErrBookingQRUnsigned)
+
+	_, err = service.Verify("ST1.garbage")
+	assert.ErrorIs(t, err, ErrBookingQRMalformed)
+
+	expired, err := service.Sign(testQRBooking(time.Now().Add(-13 * time.Hour)))
+	require.NoError(t, err)
+	_, err = service.Verify(expired)
+	assert.ErrorIs(t, err, ErrBookingQRExpired)
+}
+
+func TestBookingQRService_RetiredKeyStillVerifies(t *testing.T) {
+	old := newTestQRKey(t, models.QRKeyActive)
+	signed, err := newTestBookingQRService(t, old).Sign(testQRBooking(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	retiredAt := time.Now()
+	old.Status, old.RetiredAt = models.QRKeyRetired, &retiredAt
+	service := newTestBookingQRService(t, newTestQRKey(t, models.QRKeyActive), old)
+
+	_, err = service.Verify(signed)
+	assert.NoError(t, err)
+
+	keys, err := service.VerificationKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, models.QRKeyActive, keys[0].Status)
+	require.NotNil(t, keys[1].ValidUntil)
+	assert.WithinDuration(t, retiredAt.Add(30*24*time.Hour), *keys[1].ValidUntil, time.Second)
+
+	_, err = newTestBookingQRService(t, newTestQRKey(t, models.QRKeyActive)).Verify(signed)
+	assert.ErrorIs(t, err, ErrBookingQRUnknownKey, "keys past their retention are dropped")
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// BookingSnapshotService freezes a booking's trip, route, fare and lounge details when it is
+// made, so receipts and dispute evidence survive owners editing or deleting those records
+type BookingSnapshotService struct {
+	snapshotRepo *database.BookingSnapshotRepository
+	bookingRepo  *database.AppBookingRepository
+	currency     string
+	logger       *logrus.Logger
+}
+
+// NewBookingSnapshotService creates a new BookingSnapshotService
+func NewBookingSnapshotService(
+	snapshotRepo *database.BookingSnapshotRepository,
+	bookingRepo *database.AppBookingRepository,
+	currency string,
+	logger *logrus.Logger,
+) *BookingSnapshotService {
+	return &BookingSnapshotService{
+		snapshotRepo: snapshotRepo,
+		bookingRepo:  bookingRepo,
+		currency:     currency,
+		logger:       logger,
+	}
+}
+
+// Capture snapshots a booking as it stands now. A booking is only ever snapshotted once;
+// capturing it again keeps the original.
+func (s *BookingSnapshotService) Capture(bookingID string) error {
+	booking, err := s.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	snapshot := &models.BookingSnapshot{
+		Version:    models.BookingSnapshotVersion,
+		CapturedAt: time.Now(),
+		Fare:       models.SnapshotBookingFare(booking, s.currency),
+	}
+	if booking.BusBooking != nil {
+		if err := s.snapshotRepo.FillBusDetails(booking.BusBooking.ID, snapshot); err != nil {
+			return err
+		}
+	}
+	lounges, err := s.snapshotRepo.GetLounges(bookingID)
+	if err != nil {
+		return err
+	}
+	if len(lounges) > 0 {
+		snapshot.Lounges = lounges
+	}
+
+	saved, err := s.snapshotRepo.Save(bookingID, snapshot)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		s.logger.WithField("booking_id", bookingID).Debug("Booking already has a snapshot, keeping the original")
+	}
+	return nil
+}
+
+// CaptureBestEffort snapshots a booking, logging rather than returning failures so the
+// booking itself is never rolled back over its snapshot
+func (s *BookingSnapshotService) CaptureBestEffort(bookingID string) {
+	if err := s.Capture(bookingID); err != nil {
+		s.logger.WithError(err).WithField("booking_id", bookingID).Error("Failed to capture booking snapshot")
+	}
+}
+
+// Get returns a booking's snapshot; returns nil for bookings made before snapshots were captured
+func (s *BookingSnapshotService) Get(bookingID string) (*models.BookingSnapshot, error) {
+	return s.snapshotRepo.Get(bookingID)
+}
@@ -28,17 +28,28 @@ func (s *BusSeatLayoutService) CreateTemplate(ctx context.Context, req *models.C
 		return nil, fmt.Errorf("seat map rows (%d) must match total_rows (%d)", len(req.SeatMap), req.TotalRows)
 	}
 
+	// Validate rendering metadata (defaults fill anything omitted)
+	metadata := models.DefaultSeatLayoutMetadata(req.TotalRows)
+	if req.LayoutMetadata != nil {
+		metadata = *req.LayoutMetadata
+		metadata.ApplyDefaults(req.TotalRows)
+	}
+	if err := metadata.Validate(req.TotalRows); err != nil {
+		return nil, fmt.Errorf("invalid layout_metadata: %w", err)
+	}
+
 	// Generate seats from seat map
 	seats := s.generateSeatsFromMap(req.SeatMap)
 
 	// Create template
 	template := &models.BusSeatLayoutTemplate{
-		TemplateName: req.TemplateName,
-		TotalRows:    req.TotalRows,
-		TotalSeats:   len(seats),
-		Description:  req.Description,
-		IsActive:     true,
-		CreatedBy:    adminID,
+		TemplateName:   req.TemplateName,
+		TotalRows:      req.TotalRows,
+		TotalSeats:     len(seats),
+		Description:    req.Description,
+		LayoutMetadata: &metadata,
+		IsActive:       true,
+		CreatedBy:      adminID,
 	}
 
 	if err := s.repo.CreateTemplate(ctx, template); err != nil {
@@ -165,6 +176,16 @@ func (s *BusSeatLayoutService) ListTemplates(ctx context.Context, activeOnly boo
 
 // UpdateTemplate updates a template's basic information
 func (s *BusSeatLayoutService) UpdateTemplate(ctx context.Context, templateID uuid.UUID, req *models.UpdateBusSeatLayoutTemplateRequest) error {
+	if req.LayoutMetadata != nil {
+		template, err := s.repo.GetTemplateByID(ctx, templateID)
+		if err != nil {
+			return err
+		}
+		req.LayoutMetadata.ApplyDefaults(template.TotalRows)
+		if err := req.LayoutMetadata.Validate(template.TotalRows); err != nil {
+			return fmt.Errorf("invalid layout_metadata: %w", err)
+		}
+	}
 	return s.repo.UpdateTemplate(ctx, templateID, req)
 }
 
@@ -175,6 +196,8 @@ func (s *BusSeatLayoutService) DeleteTemplate(ctx context.Context, templateID uu
 
 // buildTemplateResponse builds a complete template response with layout preview
 func (s *BusSeatLayoutService) buildTemplateResponse(template *models.BusSeatLayoutTemplate, seats []models.BusSeatLayoutSeat) *models.BusSeatLayoutTemplateResponse {
+	metadata := template.ResolvedLayoutMetadata()
+
 	// Group seats by row
 	rowMap := make(map[int][]models.BusSeatLayoutSeat)
 	for _, seat := range seats {
@@ -192,6 +215,7 @@ func (s *BusSeatLayoutService) buildTemplateResponse(template *models.BusSeatLay
 		row := models.BusRow{
 			RowNumber:  rowNum,
 			RowLabel:   rowSeats[0].RowLabel,
+			Deck:       metadata.DeckForRow(rowNum),
 			LeftSeats:  []models.SeatInfo{},
 			RightSeats: []models.SeatInfo{},
 		}
@@ -226,6 +250,7 @@ func (s *BusSeatLayoutService) buildTemplateResponse(template *models.BusSeatLay
 		CreatedAt:    template.CreatedAt,
 		UpdatedAt:    template.UpdatedAt,
 		Seats:        seats,
+		LayoutMetadata: metadata,
 		LayoutPreview: models.BusLayoutPreview{
 			Rows: rows,
 		},
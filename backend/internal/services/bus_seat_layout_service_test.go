@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusSeatLayoutService_CreateTemplate_InvalidMetadata(t *testing.T) {
+	s := NewBusSeatLayoutService(nil)
+	seatMap := [][]bool{
+		{true, true, false, true, true, false},
+		{true, true, false, true, true, false},
+	}
+
+	tests := []struct {
+		name     string
+		metadata models.SeatLayoutMetadata
+	}{
+		{"bad orientation", models.SeatLayoutMetadata{Orientation: "sideways"}},
+		{"aisle out of range", models.SeatLayoutMetadata{AislePositions: []int{6}}},
+		{"decks do not cover rows", models.SeatLayoutMetadata{Decks: []models.SeatLayoutDeck{{Deck: 1, StartRow: 1, EndRow: 1}}}},
+		{"door on unknown deck", models.SeatLayoutMetadata{Doors: []models.SeatLayoutDoor{{Deck: 2, Row: 1, Side: models.BusSideLeft, Type: models.BusDoorTypeFront}}}},
+		{"door side invalid", models.SeatLayoutMetadata{Doors: []models.SeatLayoutDoor{{Row: 1, Side: "top", Type: models.BusDoorTypeFront}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := tt.metadata
+			_, err := s.CreateTemplate(context.Background(), &models.CreateBusSeatLayoutTemplateRequest{
+				TemplateName:   "Test",
+				TotalRows:      2,
+				SeatMap:        seatMap,
+				LayoutMetadata: &metadata,
+			}, uuid.New())
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid layout_metadata")
+		})
+	}
+}
+
+func TestBusSeatLayoutService_BuildTemplateResponse_Decks(t *testing.T) {
+	s := NewBusSeatLayoutService(nil)
+	template := &models.BusSeatLayoutTemplate{
+		ID:        uuid.New(),
+		TotalRows: 3,
+		LayoutMetadata: &models.SeatLayoutMetadata{
+			Decks: []models.SeatLayoutDeck{
+				{Deck: 1, Label: "Lower", StartRow: 1, EndRow: 2},
+				{Deck: 2, Label: "Upper", StartRow: 3, EndRow: 3},
+			},
+		},
+	}
+	seats := s.generateSeatsFromMap([][]bool{
+		{true, true, false, true, true, false},
+		{true, true, false, true, true, false},
+		{true, false, false, true, false, false},
+	})
+
+	resp := s.buildTemplateResponse(template, seats)
+
+	assert.Equal(t, models.SeatLayoutOrientationFrontTop, resp.LayoutMetadata.Orientation)
+	assert.Equal(t, []int{3}, resp.LayoutMetadata.AislePositions)
+	assert.Len(t, resp.LayoutPreview.Rows, 3)
+	assert.Equal(t, 1, resp.LayoutPreview.Rows[0].Deck)
+	assert.Equal(t, 2, resp.LayoutPreview.Rows[2].Deck)
+}
+
+func TestBusSeatLayoutService_BuildTemplateResponse_DefaultMetadata(t *testing.T) {
+	s := NewBusSeatLayoutService(nil)
+	template := &models.BusSeatLayoutTemplate{ID: uuid.New(), TotalRows: 2}
+
+	resp := s.buildTemplateResponse(template, nil)
+
+	assert.NoError(t, resp.LayoutMetadata.Validate(2))
+	assert.Equal(t, models.BusSideRight, resp.LayoutMetadata.DriverSide)
+	assert.Len(t, resp.LayoutMetadata.Doors, 1)
+}
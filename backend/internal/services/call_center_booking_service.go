@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/validator"
+)
+
+var (
+	ErrCallCenterBookingNotFound = errors.New("call-center booking not found")
+	ErrCallCenterLinkClosed      = errors.New("the booking is no longer awaiting payment")
+)
+
+// CallCenterBookingService lets call-center agents book for passengers over the phone. The
+// booking is made on the passenger's account (created if the phone number is new), the
+// gateway payment link is sent to them by SMS, and the payment webhook confirms the booking
+// once they pay, as it does for bookings made in the app.
+type CallCenterBookingService struct {
+	repo          *database.CallCenterBookingRepository
+	orchestrator  *BookingOrchestratorService
+	userRepo      *database.UserRepository
+	notifications *NotificationService
+	phones        *validator.PhoneValidator
+	logger        *logrus.Logger
+}
+
+// NewCallCenterBookingService creates a new CallCenterBookingService
+func NewCallCenterBookingService(
+	repo *database.CallCenterBookingRepository,
+	orchestrator *BookingOrchestratorService,
+	userRepo *database.UserRepository,
+	notifications *NotificationService,
+	logger *logrus.Logger,
+) *CallCenterBookingService {
+	return &CallCenterBookingService{
+		repo:          repo,
+		orchestrator:  orchestrator,
+		userRepo:      userRepo,
+		notifications: notifications,
+		phones:        validator.NewPhoneValidator(),
+		logger:        logger,
+	}
+}
+
+// Create books for the passenger on the given phone number and texts them the payment link.
+// The booking is still created if the SMS fails; the agent can resend it.
+func (s *CallCenterBookingService) Create(agentID uuid.UUID, req *models.CreateCallCenterBookingRequest, tenant *models.Tenant) (*models.CallCenterBooking, error) {
+	phone, err := s.phones.Validate(req.Phone)
+	if err != nil {
+		return nil, &models.ValidationError{Message: "invalid passenger phone: " + err.Error()}
+	}
+
+	passenger, created, err := s.userRepo.GetOrCreateUser(phone)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := s.orchestrator.CreateCallCenterIntent(passenger.ID, &req.Booking)
+	if err != nil {
+		return nil, err
+	}
+	payment, err := s.orchestrator.InitiatePayment(intent.IntentID, passenger.ID, tenant, nil)
+	if err != nil {
+		// Without a payment link the hold is of no use to the passenger
+		if cancelErr := s.orchestrator.CancelIntent(intent.IntentID, passenger.ID); cancelErr != nil {
+			s.logger.WithError(cancelErr).WithField("intent_id", intent.IntentID).Warn("Failed to release call-center intent after payment error")
+		}
+		return nil, err
+	}
+
+	record := &models.CallCenterBooking{
+		IntentID:        intent.IntentID,
+		AgentUserID:     agentID,
+		PassengerUserID: passenger.ID,
+		PassengerPhone:  phone,
+		PaymentURL:      payment.PaymentURL,
+		Notes:           req.Notes,
+	}
+	if err := s.repo.Create(record); err != nil {
+		return nil, err
+	}
+	booking, err := s.repo.GetByID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if booking == nil {
+		return nil, ErrCallCenterBookingNotFound
+	}
+	s.sendPaymentLink(booking)
+
+	s.logger.WithFields(logrus.Fields{
+		"call_center_booking_id": booking.ID,
+		"intent_id":              booking.IntentID,
+		"agent_user_id":          agentID,
+		"passenger_user_id":      passenger.ID,
+		"new_passenger":          created,
+		"total_amount":           booking.TotalAmount,
+	}).Info("Call-center booking created")
+	return booking, nil
+}
+
+// Get returns a call-center booking with its intent's current state
+func (s *CallCenterBookingService) Get(id uuid.UUID) (*models.CallCenterBooking, error) {
+	booking, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if booking == nil {
+		return nil, ErrCallCenterBookingNotFound
+	}
+	return booking, nil
+}
+
+// List returns call-center bookings, newest first
+func (s *CallCenterBookingService) List(filter models.CallCenterBookingFilter) ([]models.CallCenterBooking, error) {
+	if filter.Phone != "" {
+		phone, err := s.phones.Validate(filter.Phone)
+		if err != nil {
+			return nil, &models.ValidationError{Message: "invalid passenger phone: " + err.Error()}
+		}
+		filter.Phone = phone
+	}
+	return s.repo.List(filter)
+}
+
+// ResendPaymentLink texts the payment link to the passenger again while it can still be paid
+func (s *CallCenterBookingService) ResendPaymentLink(id uuid.UUID) (*models.CallCenterBooking, error) {
+	booking, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !booking.AwaitingPayment(time.Now()) {
+		return nil, ErrCallCenterLinkClosed
+	}
+	if !s.sendPaymentLink(booking) {
+		return nil, errors.New("failed to send payment link SMS")
+	}
+	return booking, nil
+}
+
+// sendPaymentLink texts the payment link to the passenger and counts it; reports whether it went out
+func (s *CallCenterBookingService) sendPaymentLink(booking *models.CallCenterBooking) bool {
+	if err := s.notifications.SendSMS(booking.PassengerPhone, booking.PaymentLinkSMS()); err != nil {
+		s.logger.WithError(err).WithField("call_center_booking_id", booking.ID).Error("Failed to send call-center payment link SMS")
+		return false
+	}
+	if err := s.repo.RecordSMS(booking); err != nil {
+		s.logger.WithError(err).WithField("call_center_booking_id", booking.ID).Warn("Payment link SMS sent but not recorded")
+	}
+	return true
+}
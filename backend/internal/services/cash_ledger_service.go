@@ -0,0 +1,262 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrCashTripNotFound         = errors.New("trip not found or access denied")
+	ErrCashDenied               = errors.New("only the trip's driver, conductor or bus owner can record its cash")
+	ErrCashSalesClosed          = errors.New("cash sales can no longer be recorded for this trip")
+	ErrCashTripNotUnderWay      = errors.New("cash can only be handed in once the trip is under way")
+	ErrOnboardSaleNotFound      = errors.New("onboard sale not found")
+	ErrCashSettlementNotFound   = errors.New("the trip's cash has not been handed in")
+	ErrCashSettlementNotPending = errors.New("the trip's cash settlement is not awaiting review")
+)
+
+// CashLedgerService keeps the cash a trip's crew takes on board: conductors record each sale
+// as they make it and hand the cash in at the end of the trip, and the bus owner reconciles the
+// handover against the ledger. Standing tickets the crew were paid for count towards the cash
+// expected.
+type CashLedgerService struct {
+	repo   *database.CashLedgerRepository
+	logger *logrus.Logger
+}
+
+// NewCashLedgerService creates a new CashLedgerService
+func NewCashLedgerService(repo *database.CashLedgerRepository, logger *logrus.Logger) *CashLedgerService {
+	return &CashLedgerService{repo: repo, logger: logger}
+}
+
+// ============================================================================
+// CREW
+// ============================================================================
+
+// RecordSale records a cash sale on the crew's trip. Repeating a sale's client reference
+// returns the sale already recorded, with created false.
+func (s *CashLedgerService) RecordSale(scheduledTripID string, userID uuid.UUID, req *models.RecordOnboardSaleRequest) (sale *models.OnboardSale, created bool, err error) {
+	trip, err := s.crewTrip(scheduledTripID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !trip.AcceptsSales() {
+		return nil, false, ErrCashSalesClosed
+	}
+	sale, err = req.Sale(trip.ScheduledTripID, userID.String(), trip.BaseFare)
+	if err != nil {
+		return nil, false, err
+	}
+	created, err = s.repo.CreateSale(sale)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if created {
+		s.logger.WithFields(logrus.Fields{
+			"scheduled_trip_id": sale.ScheduledTripID,
+			"passengers":        sale.PassengerCount,
+			"amount":            sale.Amount,
+			"sold_by":           sale.SoldByUserID,
+		}).Info("Onboard cash sale recorded")
+	}
+	return sale, created, nil
+}
+
+// VoidSale takes a mistaken sale out of the crew's trip's takings
+func (s *CashLedgerService) VoidSale(scheduledTripID, saleID string, userID uuid.UUID, req *models.VoidOnboardSaleRequest) (*models.OnboardSale, error) {
+	if _, err := s.crewTrip(scheduledTripID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(saleID); err != nil {
+		return nil, ErrOnboardSaleNotFound
+	}
+	sale, err := s.repo.VoidSale(scheduledTripID, saleID, userID.String(), req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if sale == nil {
+		return nil, ErrOnboardSaleNotFound
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": sale.ScheduledTripID,
+		"sale_id":           sale.ID,
+		"amount":            sale.Amount,
+		"voided_by":         userID,
+	}).Info("Onboard cash sale voided")
+	return sale, nil
+}
+
+// GetCrewLedger returns the crew's trip's cash ledger
+func (s *CashLedgerService) GetCrewLedger(scheduledTripID string, userID uuid.UUID) (*models.TripCashLedger, error) {
+	trip, err := s.crewTrip(scheduledTripID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger(trip)
+}
+
+// GetCrewSettlement returns the crew's trip's cash settlement
+func (s *CashLedgerService) GetCrewSettlement(scheduledTripID string, userID uuid.UUID) (*models.TripCashSettlement, error) {
+	trip, err := s.crewTrip(scheduledTripID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.settlement(trip)
+}
+
+// SubmitSettlement hands in the crew's trip's cash. The ledger closes until the owner sends the
+// settlement back; it can be resubmitted, e.g. after a recount, until the owner reconciles it.
+func (s *CashLedgerService) SubmitSettlement(scheduledTripID string, userID uuid.UUID, req *models.SubmitCashSettlementRequest) (*models.TripCashSettlement, error) {
+	trip, err := s.crewTrip(scheduledTripID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !trip.CanSettle() {
+		return nil, ErrCashTripNotUnderWay
+	}
+
+	settlement := &models.TripCashSettlement{
+		ScheduledTripID:   trip.ScheduledTripID,
+		SubmittedByUserID: userID.String(),
+		DeclaredCash:      *req.DeclaredCash,
+		CrewNote:          optionalString(req.Note),
+	}
+	if err := s.repo.SubmitSettlement(settlement); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": settlement.ScheduledTripID,
+		"expected_cash":     settlement.ExpectedCash,
+		"declared_cash":     settlement.DeclaredCash,
+		"variance":          settlement.Variance,
+	}).Info("Trip cash handed in")
+	return settlement, nil
+}
+
+func (s *CashLedgerService) crewTrip(scheduledTripID string, userID uuid.UUID) (*models.CashTrip, error) {
+	trip, err := s.getTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if !trip.IsCrew(userID.String()) {
+		return nil, ErrCashDenied
+	}
+	return trip, nil
+}
+
+// ============================================================================
+// BUS OWNER
+// ============================================================================
+
+// GetOwnerLedger returns the cash ledger of an owner's trip
+func (s *CashLedgerService) GetOwnerLedger(scheduledTripID, busOwnerID string) (*models.TripCashLedger, error) {
+	trip, err := s.ownerTrip(scheduledTripID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger(trip)
+}
+
+// ReviewSettlement reconciles the cash handed in for an owner's trip, or disputes it, which
+// reopens the ledger for the crew to correct and resubmit
+func (s *CashLedgerService) ReviewSettlement(scheduledTripID, busOwnerID string, reviewerID uuid.UUID, req *models.ReviewCashSettlementRequest) (*models.TripCashSettlement, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	trip, err := s.ownerTrip(scheduledTripID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	settlement, err := s.repo.ReviewSettlement(trip.ScheduledTripID, req.Status, optionalString(req.Note), reviewerID.String())
+	if err != nil {
+		return nil, err
+	}
+	if settlement == nil {
+		if _, err := s.settlement(trip); err != nil {
+			return nil, err
+		}
+		return nil, ErrCashSettlementNotPending
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": settlement.ScheduledTripID,
+		"status":            settlement.Status,
+		"variance":          settlement.Variance,
+		"reviewed_by":       reviewerID,
+	}).Info("Trip cash settlement reviewed")
+	return settlement, nil
+}
+
+// ListSettlements returns a page of the cash settlements of an owner's trips
+func (s *CashLedgerService) ListSettlements(filter models.CashSettlementFilter) ([]models.TripCashSettlement, int, error) {
+	return s.repo.ListOwnerSettlements(filter)
+}
+
+func (s *CashLedgerService) ownerTrip(scheduledTripID, busOwnerID string) (*models.CashTrip, error) {
+	trip, err := s.getTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.BusOwnerID == nil || *trip.BusOwnerID != busOwnerID {
+		return nil, ErrCashTripNotFound
+	}
+	return trip, nil
+}
+
+// ============================================================================
+// SHARED
+// ============================================================================
+
+func (s *CashLedgerService) ledger(trip *models.CashTrip) (*models.TripCashLedger, error) {
+	sales, err := s.repo.ListSales(trip.ScheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.GetTotals(trip.ScheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	settlement, err := s.repo.GetSettlement(trip.ScheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TripCashLedger{
+		Trip:         trip,
+		Sales:        sales,
+		Totals:       totals,
+		ExpectedCash: totals.ExpectedCash(),
+		Settlement:   settlement,
+	}, nil
+}
+
+func (s *CashLedgerService) settlement(trip *models.CashTrip) (*models.TripCashSettlement, error) {
+	settlement, err := s.repo.GetSettlement(trip.ScheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if settlement == nil {
+		return nil, ErrCashSettlementNotFound
+	}
+	return settlement, nil
+}
+
+func (s *CashLedgerService) getTrip(scheduledTripID string) (*models.CashTrip, error) {
+	if _, err := uuid.Parse(scheduledTripID); err != nil {
+		return nil, ErrCashTripNotFound
+	}
+	trip, err := s.repo.GetTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, ErrCashTripNotFound
+	}
+	return trip, nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// DemandAnalyticsService builds origin/destination/hour demand heatmaps from searches and
+// bookings, for planners (platform-wide) and bus owners (their own routes)
+type DemandAnalyticsService struct {
+	repo   *database.DemandAnalyticsRepository
+	logger *logrus.Logger
+}
+
+// NewDemandAnalyticsService creates a new DemandAnalyticsService
+func NewDemandAnalyticsService(repo *database.DemandAnalyticsRepository, logger *logrus.Logger) *DemandAnalyticsService {
+	return &DemandAnalyticsService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// GetHeatmap returns demand per corridor and hour. busOwnerID limits it to searches between
+// stops on the owner's routes and bookings on the owner's trips; nil is platform-wide.
+func (s *DemandAnalyticsService) GetHeatmap(busOwnerID *string, req *models.DemandHeatmapRequest) (*models.DemandHeatmap, error) {
+	from, to, err := req.Range(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	searches, err := s.repo.GetSearchDemand(busOwnerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.repo.GetBookingDemand(busOwnerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DemandHeatmap{
+		From:       from.Format("2006-01-02"),
+		To:         to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Timezone:   models.ReportTimezone.String(),
+		BusOwnerID: busOwnerID,
+		Corridors:  models.BuildDemandCorridors(searches, bookings, req.Origin, req.Limit),
+	}, nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrFatigueJustificationRequired = fmt.Errorf("override_justification of at least %d characters is required to override hours-of-service rules", models.FatigueOverrideMinJustification)
+	ErrFatigueNoViolations          = errors.New("there are no hours-of-service violations to override")
+)
+
+// maxTripLookback is how long before a day a trip may depart and still run into it, matching
+// the longest estimated duration a trip can have
+const maxTripLookback = 48 * time.Hour
+
+// DriverFatigueOverride is a bus owner's decision to assign a driver despite violations
+type DriverFatigueOverride struct {
+	UserID        uuid.UUID
+	TripID        string
+	DriverID      string
+	Justification string
+	Violations    []models.FatigueViolation
+	IPAddress     string
+	UserAgent     string
+}
+
+// DriverFatigueService checks driver assignments against the hours-of-service rules, using the
+// driver's other assigned trips and their durations. Both manual assignment and any automatic
+// staff proposer should go through CheckAssignment so the rules are applied the same way.
+type DriverFatigueService struct {
+	tripRepo     *database.ScheduledTripRepository
+	auditService *AuditService
+	config       config.DriverFatigueConfig
+	logger       *logrus.Logger
+}
+
+// NewDriverFatigueService creates a new DriverFatigueService
+func NewDriverFatigueService(
+	tripRepo *database.ScheduledTripRepository,
+	auditService *AuditService,
+	cfg config.DriverFatigueConfig,
+	logger *logrus.Logger,
+) *DriverFatigueService {
+	return &DriverFatigueService{
+		tripRepo:     tripRepo,
+		auditService: auditService,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+// Rules returns the configured hours-of-service rules
+func (s *DriverFatigueService) Rules() models.HoursOfServiceRules {
+	return models.HoursOfServiceRules{
+		MaxDailyDrivingMinutes: s.config.MaxDailyDrivingMinutes,
+		MinRestMinutes:         s.config.MinRestMinutes,
+		DefaultTripMinutes:     s.config.DefaultTripMinutes,
+	}
+}
+
+// CheckAssignment returns the rules assigning the driver to trip would break. It returns none
+// when the rules are disabled.
+func (s *DriverFatigueService) CheckAssignment(driverID string, trip *models.ScheduledTrip) ([]models.FatigueViolation, error) {
+	if !s.config.Enabled {
+		return []models.FatigueViolation{}, nil
+	}
+	rules := s.Rules()
+	candidate := models.DutyFromTrip(trip)
+
+	// Every trip that could share a local day with this one, or fall within the rest period
+	// after it
+	duration := rules.DefaultTripMinutes
+	if trip.EstimatedDurationMinutes != nil && *trip.EstimatedDurationMinutes > 0 {
+		duration = *trip.EstimatedDurationMinutes
+	}
+	start := trip.DepartureDatetime.In(models.ReportTimezone)
+	end := start.Add(time.Duration(duration) * time.Minute)
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, models.ReportTimezone).Add(-maxTripLookback)
+	to := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, models.ReportTimezone).
+		AddDate(0, 0, 1).Add(time.Duration(rules.MinRestMinutes) * time.Minute)
+
+	duties, err := s.tripRepo.GetDriverDuties(driverID, trip.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return models.CheckHoursOfService(candidate, duties, rules), nil
+}
+
+// ValidateOverride checks that an override is justified and there is something to override
+func (s *DriverFatigueService) ValidateOverride(override *DriverFatigueOverride) error {
+	override.Justification = strings.TrimSpace(override.Justification)
+	if len(override.Violations) == 0 {
+		return ErrFatigueNoViolations
+	}
+	if len(override.Justification) < models.FatigueOverrideMinJustification {
+		return ErrFatigueJustificationRequired
+	}
+	return nil
+}
+
+// RecordOverride audits an assignment made despite violations; failures are logged and never
+// undo the assignment
+func (s *DriverFatigueService) RecordOverride(override *DriverFatigueOverride) {
+	if s.auditService == nil {
+		return
+	}
+	var tripID *uuid.UUID
+	if id, err := uuid.Parse(override.TripID); err == nil {
+		tripID = &id
+	}
+	details := map[string]interface{}{
+		"driver_id":     override.DriverID,
+		"justification": override.Justification,
+		"violations":    override.Violations,
+		"rules":         s.Rules(),
+	}
+	if err := s.auditService.LogTripAssignmentOverride(override.UserID, "driver_fatigue_override", tripID, override.IPAddress, override.UserAgent, details); err != nil {
+		s.logger.WithError(err).WithField("trip_id", override.TripID).Error("Failed to audit driver fatigue override")
+	}
+}
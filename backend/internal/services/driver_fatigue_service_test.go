@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriverFatigueService_ValidateOverride(t *testing.T) {
+	svc := NewDriverFatigueService(nil, nil, config.DriverFatigueConfig{Enabled: true}, nil)
+	violations := []models.FatigueViolation{{Rule: models.FatigueRuleMinRest}}
+
+	assert.ErrorIs(t, svc.ValidateOverride(&DriverFatigueOverride{Justification: "Relief driver is sick"}), ErrFatigueNoViolations)
+	assert.ErrorIs(t, svc.ValidateOverride(&DriverFatigueOverride{Justification: "   sick   ", Violations: violations}), ErrFatigueJustificationRequired)
+
+	override := &DriverFatigueOverride{Justification: "  Relief driver is sick  ", Violations: violations}
+	assert.NoError(t, svc.ValidateOverride(override))
+	assert.Equal(t, "Relief driver is sick", override.Justification)
+}
+
+func TestDriverFatigueService_CheckAssignmentDisabled(t *testing.T) {
+	svc := NewDriverFatigueService(nil, nil, config.DriverFatigueConfig{Enabled: false}, nil)
+	violations, err := svc.CheckAssignment("driver", &models.ScheduledTrip{ID: "trip"})
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// FareComplianceService checks trip fares and seat price overrides against the fare approved
+// on the trip's route permit. Admins get a report of trips priced above it; with enforcement
+// on, such trips cannot be published for booking.
+type FareComplianceService struct {
+	repo   *database.FareComplianceRepository
+	config config.FareComplianceConfig
+	logger *logrus.Logger
+}
+
+// NewFareComplianceService creates a new FareComplianceService
+func NewFareComplianceService(repo *database.FareComplianceRepository, cfg config.FareComplianceConfig, logger *logrus.Logger) *FareComplianceService {
+	return &FareComplianceService{
+		repo:   repo,
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// GetReport lists trips departing in the requested range priced above their permit's fare
+func (s *FareComplianceService) GetReport(req *models.FareComplianceRequest, limit, offset int) (*models.FareComplianceReport, error) {
+	from, to, err := req.Range(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var busOwnerID *string
+	if req.BusOwnerID != "" {
+		busOwnerID = &req.BusOwnerID
+	}
+
+	trips, total, err := s.repo.ListNonCompliant(from, to, busOwnerID, req.BookableOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for i := range trips {
+		trips[i].SetExcess()
+	}
+
+	return &models.FareComplianceReport{
+		From:    from.Format("2006-01-02"),
+		To:      to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Trips:   trips,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		Enforce: s.config.EnforceOnPublish,
+	}, nil
+}
+
+// CheckPublish returns a FareComplianceError listing the trips priced above their permit's
+// fare when enforcement is on; otherwise such trips are only logged
+func (s *FareComplianceService) CheckPublish(tripIDs []string) error {
+	trips, err := s.repo.GetNonCompliantTrips(tripIDs)
+	if err != nil {
+		return err
+	}
+	if len(trips) == 0 {
+		return nil
+	}
+	for i := range trips {
+		trips[i].SetExcess()
+	}
+
+	if !s.config.EnforceOnPublish {
+		for _, trip := range trips {
+			s.logger.WithFields(logrus.Fields{
+				"trip_id":       trip.TripID,
+				"permit_id":     trip.PermitID,
+				"approved_fare": trip.ApprovedFare,
+				"excess_amount": trip.ExcessAmount,
+			}).Warn("Publishing trip priced above its permit's approved fare")
+		}
+		return nil
+	}
+
+	message := fmt.Sprintf("Trip is priced %.2f above its permit's approved fare of %.2f", trips[0].ExcessAmount, trips[0].ApprovedFare)
+	if len(tripIDs) > 1 {
+		message = fmt.Sprintf("%d trip(s) are priced above their permit's approved fare", len(trips))
+	}
+	return &models.FareComplianceError{Trips: trips, Message: message}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// ErrGTFSDisabled is returned for the feed when GTFS_ENABLED is off
+var ErrGTFSDisabled = errors.New("the GTFS feed is not enabled")
+
+// GTFSFeedFile is a generated GTFS static zip
+type GTFSFeedFile struct {
+	Data        []byte
+	GeneratedAt time.Time
+}
+
+// gtfsRealtimeFeed is a built GTFS Realtime feed and when it was built
+type gtfsRealtimeFeed struct {
+	data    []byte
+	builtAt time.Time
+}
+
+// GTFSService renders published trips into a GTFS static zip for journey planners and
+// regenerates it on a schedule. The zip is kept in memory, so downloads never hit the database.
+// It also builds the GTFS Realtime feeds of tracked trips, reusing each for a few seconds.
+type GTFSService struct {
+	repo   *database.GTFSRepository
+	config config.GTFSConfig
+	logger *logrus.Logger
+	stopCh chan struct{}
+
+	generateMu sync.Mutex // One generation at a time
+	mu         sync.RWMutex
+	feed       *GTFSFeedFile
+
+	realtimeMu sync.Mutex
+	realtime   map[models.GTFSRealtimeFeedKind]gtfsRealtimeFeed
+}
+
+// NewGTFSService creates a new GTFSService
+func NewGTFSService(repo *database.GTFSRepository, cfg config.GTFSConfig, logger *logrus.Logger) *GTFSService {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.HorizonDays <= 0 {
+		cfg.HorizonDays = 30
+	}
+	if cfg.Lang == "" {
+		cfg.Lang = "en"
+	}
+	if cfg.RealtimeTTL <= 0 {
+		cfg.RealtimeTTL = 15 * time.Second
+	}
+	return &GTFSService{
+		repo:     repo,
+		config:   cfg,
+		logger:   logger,
+		stopCh:   make(chan struct{}),
+		realtime: map[models.GTFSRealtimeFeedKind]gtfsRealtimeFeed{},
+	}
+}
+
+// Feed returns the latest feed, generating it first if no run has finished yet
+func (s *GTFSService) Feed() (*GTFSFeedFile, error) {
+	if !s.config.Enabled {
+		return nil, ErrGTFSDisabled
+	}
+	s.mu.RLock()
+	feed := s.feed
+	s.mu.RUnlock()
+	if feed != nil {
+		return feed, nil
+	}
+	return s.generate(false)
+}
+
+// RealtimeFeed returns the GTFS Realtime protobuf of tracked trips: vehicle positions, trip
+// updates or both. Trip updates also cancel published trips that were called off, from those
+// due an hour ago to those departing in the next day.
+func (s *GTFSService) RealtimeFeed(kind models.GTFSRealtimeFeedKind) ([]byte, error) {
+	if !s.config.Enabled {
+		return nil, ErrGTFSDisabled
+	}
+	s.realtimeMu.Lock()
+	defer s.realtimeMu.Unlock()
+	now := time.Now()
+	if cached, ok := s.realtime[kind]; ok && now.Sub(cached.builtAt) < s.config.RealtimeTTL {
+		return cached.data, nil
+	}
+
+	tracked, err := s.repo.GetTrackedTrips()
+	if err != nil {
+		return nil, err
+	}
+	var cancelled []models.GTFSTrip
+	if kind != models.GTFSRealtimeVehiclePositions {
+		cancelled, err = s.repo.GetCancelledTrips(now.Add(-time.Hour), now.Add(24*time.Hour))
+		if err != nil {
+			return nil, err
+		}
+	}
+	seen := map[string]bool{}
+	routeIDs := []string{}
+	addRoute := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			routeIDs = append(routeIDs, id)
+		}
+	}
+	for _, trip := range tracked {
+		addRoute(trip.MasterRouteID)
+	}
+	for _, trip := range cancelled {
+		addRoute(trip.MasterRouteID)
+	}
+	_, stops, err := s.repo.GetRoutesWithStops(routeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	data := models.BuildGTFSRealtimeFeed(&models.GTFSRealtimeSource{
+		Active:    tracked,
+		Cancelled: cancelled,
+		Stops:     stops,
+	}, kind, now).Marshal()
+	s.realtime[kind] = gtfsRealtimeFeed{data: data, builtAt: now}
+	return data, nil
+}
+
+// Start begins regenerating the feed, building the first one straight away
+func (s *GTFSService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("GTFS feed disabled (GTFS_ENABLED=false)")
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"interval":     s.config.Interval.String(),
+		"horizon_days": s.config.HorizonDays,
+	}).Info("🗺️ Starting GTFS feed generator")
+	go s.run()
+}
+
+// Stop stops regenerating the feed
+func (s *GTFSService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping GTFS feed generator")
+	close(s.stopCh)
+}
+
+func (s *GTFSService) run() {
+	s.RunOnce()
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.RunOnce()
+		case <-s.stopCh:
+			s.logger.Info("GTFS feed generator stopped")
+			return
+		}
+	}
+}
+
+// RunOnce regenerates the feed once (useful for testing or manual trigger)
+func (s *GTFSService) RunOnce() {
+	if _, err := s.generate(true); err != nil {
+		s.logger.WithError(err).Error("Failed to generate GTFS feed")
+	}
+}
+
+// generate builds the feed and keeps it. Unless force is set, a feed finished by another
+// caller while this one waited is returned instead.
+func (s *GTFSService) generate(force bool) (*GTFSFeedFile, error) {
+	s.generateMu.Lock()
+	defer s.generateMu.Unlock()
+	if !force {
+		s.mu.RLock()
+		feed := s.feed
+		s.mu.RUnlock()
+		if feed != nil {
+			return feed, nil
+		}
+	}
+
+	started := time.Now()
+	local := started.In(models.ReportTimezone)
+	from := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, models.ReportTimezone)
+	to := from.AddDate(0, 0, s.config.HorizonDays)
+
+	trips, err := s.repo.GetPublishedTrips(from, to)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	routeIDs := []string{}
+	for _, trip := range trips {
+		if !seen[trip.MasterRouteID] {
+			seen[trip.MasterRouteID] = true
+			routeIDs = append(routeIDs, trip.MasterRouteID)
+		}
+	}
+	routes, stops, err := s.repo.GetRoutesWithStops(routeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	built := models.BuildGTFSFeed(&models.GTFSFeedSource{
+		Agency: models.GTFSAgency{
+			ID:    "smarttransit",
+			Name:  s.config.AgencyName,
+			URL:   s.config.AgencyURL,
+			Phone: s.config.AgencyPhone,
+			Lang:  s.config.Lang,
+		},
+		Routes: routes,
+		Stops:  stops,
+		Trips:  trips,
+	}, started)
+	data, err := writeGTFSZip(built, started)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &GTFSFeedFile{
+		Data:        data,
+		GeneratedAt: started,
+	}
+	s.mu.Lock()
+	s.feed = feed
+	s.mu.Unlock()
+
+	s.logger.WithFields(logrus.Fields{
+		"trips":         built.Trips,
+		"skipped_trips": built.SkippedTrips,
+		"routes":        len(routes),
+		"bytes":         len(data),
+		"duration":      time.Since(started).String(),
+	}).Info("GTFS feed generated")
+	return feed, nil
+}
+
+// writeGTFSZip writes each feed table as a CSV file of the zip
+func writeGTFSZip(feed *models.GTFSFeed, modified time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, table := range feed.Tables {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: table.Name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to GTFS feed: %w", table.Name, err)
+		}
+		writer := csv.NewWriter(file)
+		if err := writer.Write(table.Header); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", table.Name, err)
+		}
+		if err := writer.WriteAll(table.Rows); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", table.Name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish GTFS feed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
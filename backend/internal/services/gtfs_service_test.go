@@ -0,0 +1,35 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteGTFSZip(t *testing.T) {
+	feed := &models.GTFSFeed{Tables: []models.GTFSTable{
+		{Name: "stops.txt", Header: []string{"stop_id", "stop_name"}, Rows: [][]string{{"s1", "Fort, Colombo"}}},
+		{Name: "trips.txt", Header: []string{"trip_id"}},
+	}}
+
+	data, err := writeGTFSZip(feed, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 2)
+	assert.Equal(t, "stops.txt", archive.File[0].Name)
+
+	file, err := archive.File[0].Open()
+	require.NoError(t, err)
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"stop_id", "stop_name"}, {"s1", "Fort, Colombo"}}, records)
+}
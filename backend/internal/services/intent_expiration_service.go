@@ -11,6 +11,7 @@ import (
 // IntentExpirationService handles background expiration of booking intents
 type IntentExpirationService struct {
 	intentRepo *database.BookingIntentRepository
+	events     *BookingEventService
 	logger     *logrus.Logger
 	stopCh     chan struct{}
 	interval   time.Duration
@@ -19,10 +20,12 @@ type IntentExpirationService struct {
 // NewIntentExpirationService creates a new intent expiration service
 func NewIntentExpirationService(
 	intentRepo *database.BookingIntentRepository,
+	events *BookingEventService,
 	logger *logrus.Logger,
 ) *IntentExpirationService {
 	return &IntentExpirationService{
 		intentRepo: intentRepo,
+		events:     events,
 		logger:     logger,
 		stopCh:     make(chan struct{}),
 		interval:   1 * time.Minute, // Check every minute
@@ -102,7 +105,11 @@ func (s *IntentExpirationService) processExpiredIntents() {
 
 // expireIntent marks an intent as expired and releases all its holds
 func (s *IntentExpirationService) expireIntent(intent *models.BookingIntent) error {
-	return s.intentRepo.ExpireIntentAndReleaseHolds(intent.ID)
+	if err := s.intentRepo.ExpireIntentAndReleaseHolds(intent.ID); err != nil {
+		return err
+	}
+	s.events.IntentExpired(intent)
+	return nil
 }
 
 // RunOnce runs a single expiration cycle (useful for testing or manual trigger)
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// IntentTTLPolicy decides how long a booking intent holds seats/lounges,
+// based on the intent type and the user's reliability tier
+type IntentTTLPolicy struct {
+	TypeTTL     map[models.BookingIntentType]time.Duration // Base TTL per intent type
+	TierPercent map[models.ReliabilityTier]int             // Percentage of base TTL per tier
+	MinTTL      time.Duration                              // Floor after tier adjustment
+
+	AbandonmentLookback        time.Duration // Window used to compute the tier
+	RestrictedAbandonThreshold int           // Abandons in window that make a user restricted
+	TrustedMinConfirmed        int           // Confirms (with zero abandons) that make a user trusted
+}
+
+// DefaultIntentTTLPolicy returns the default TTL policy
+func DefaultIntentTTLPolicy() IntentTTLPolicy {
+	return IntentTTLPolicy{
+		TypeTTL: map[models.BookingIntentType]time.Duration{
+			models.IntentTypeBusOnly:    10 * time.Minute,
+			models.IntentTypeLoungeOnly: 10 * time.Minute,
+			models.IntentTypeCombined:   15 * time.Minute,
+		},
+		TierPercent: map[models.ReliabilityTier]int{
+			models.ReliabilityTierTrusted:    100,
+			models.ReliabilityTierStandard:   100,
+			models.ReliabilityTierRestricted: 50,
+		},
+		MinTTL:                     3 * time.Minute,
+		AbandonmentLookback:        30 * 24 * time.Hour,
+		RestrictedAbandonThreshold: 3,
+		TrustedMinConfirmed:        3,
+	}
+}
+
+// NewIntentTTLPolicy builds a TTL policy from booking configuration
+func NewIntentTTLPolicy(cfg config.BookingConfig) IntentTTLPolicy {
+	return IntentTTLPolicy{
+		TypeTTL: map[models.BookingIntentType]time.Duration{
+			models.IntentTypeBusOnly:    cfg.BusOnlyTTL,
+			models.IntentTypeLoungeOnly: cfg.LoungeOnlyTTL,
+			models.IntentTypeCombined:   cfg.CombinedTTL,
+		},
+		TierPercent: map[models.ReliabilityTier]int{
+			models.ReliabilityTierTrusted:    cfg.TrustedTTLPercent,
+			models.ReliabilityTierStandard:   100,
+			models.ReliabilityTierRestricted: cfg.RestrictedTTLPercent,
+		},
+		MinTTL:                     cfg.MinTTL,
+		AbandonmentLookback:        cfg.AbandonmentLookback,
+		RestrictedAbandonThreshold: cfg.RestrictedAbandonThreshold,
+		TrustedMinConfirmed:        cfg.TrustedMinConfirmed,
+	}
+}
+
+// TierFor computes the reliability tier from abandonment history
+func (p IntentTTLPolicy) TierFor(stats *models.IntentAbandonmentStats) models.ReliabilityTier {
+	if stats == nil {
+		return models.ReliabilityTierStandard
+	}
+	if p.RestrictedAbandonThreshold > 0 && stats.Abandoned >= p.RestrictedAbandonThreshold {
+		return models.ReliabilityTierRestricted
+	}
+	if stats.Abandoned == 0 && p.TrustedMinConfirmed > 0 && stats.Confirmed >= p.TrustedMinConfirmed {
+		return models.ReliabilityTierTrusted
+	}
+	return models.ReliabilityTierStandard
+}
+
+// TTLFor returns the hold TTL for an intent type and tier.
+// Falls back to the given default when the intent type has no configured TTL.
+func (p IntentTTLPolicy) TTLFor(intentType models.BookingIntentType, tier models.ReliabilityTier, fallback time.Duration) time.Duration {
+	base, ok := p.TypeTTL[intentType]
+	if !ok || base <= 0 {
+		base = fallback
+	}
+
+	percent, ok := p.TierPercent[tier]
+	if !ok || percent <= 0 {
+		percent = 100
+	}
+
+	ttl := base * time.Duration(percent) / 100
+	if p.MinTTL > 0 && ttl < p.MinTTL {
+		ttl = p.MinTTL
+	}
+	return ttl
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntentTTLPolicy_TierFor(t *testing.T) {
+	policy := DefaultIntentTTLPolicy()
+
+	tests := []struct {
+		name     string
+		stats    *models.IntentAbandonmentStats
+		expected models.ReliabilityTier
+	}{
+		{"no history", nil, models.ReliabilityTierStandard},
+		{"new user", &models.IntentAbandonmentStats{}, models.ReliabilityTierStandard},
+		{"repeat abandoner", &models.IntentAbandonmentStats{Abandoned: 3, Confirmed: 5}, models.ReliabilityTierRestricted},
+		{"reliable user", &models.IntentAbandonmentStats{Abandoned: 0, Confirmed: 3}, models.ReliabilityTierTrusted},
+		{"occasional abandon", &models.IntentAbandonmentStats{Abandoned: 1, Confirmed: 10}, models.ReliabilityTierStandard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.TierFor(tt.stats))
+		})
+	}
+}
+
+func TestIntentTTLPolicy_TTLFor(t *testing.T) {
+	policy := DefaultIntentTTLPolicy()
+	fallback := 10 * time.Minute
+
+	assert.Equal(t, 10*time.Minute, policy.TTLFor(models.IntentTypeBusOnly, models.ReliabilityTierStandard, fallback))
+	assert.Equal(t, 15*time.Minute, policy.TTLFor(models.IntentTypeCombined, models.ReliabilityTierStandard, fallback))
+	assert.Equal(t, 5*time.Minute, policy.TTLFor(models.IntentTypeBusOnly, models.ReliabilityTierRestricted, fallback))
+
+	// Unknown intent type uses the fallback
+	assert.Equal(t, fallback, policy.TTLFor(models.BookingIntentType("unknown"), models.ReliabilityTierStandard, fallback))
+}
+
+func TestIntentTTLPolicy_TTLFor_MinFloor(t *testing.T) {
+	policy := DefaultIntentTTLPolicy()
+	policy.TierPercent[models.ReliabilityTierRestricted] = 10
+
+	// 10% of 10 minutes is below the 3 minute floor
+	assert.Equal(t, 3*time.Minute, policy.TTLFor(models.IntentTypeBusOnly, models.ReliabilityTierRestricted, 10*time.Minute))
+}
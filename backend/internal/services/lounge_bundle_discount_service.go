@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var ErrBundleDiscountRuleNotFound = errors.New("bundle discount rule not found")
+
+// LoungeBundleDiscountService discounts lounge stays booked together with a bus ticket. Lounge
+// owners fund discounts on their own lounges; admins set up platform-funded ones.
+type LoungeBundleDiscountService struct {
+	repo       *database.LoungeBundleDiscountRepository
+	loungeRepo *database.LoungeRepository
+}
+
+// NewLoungeBundleDiscountService creates a new LoungeBundleDiscountService
+func NewLoungeBundleDiscountService(repo *database.LoungeBundleDiscountRepository, loungeRepo *database.LoungeRepository) *LoungeBundleDiscountService {
+	return &LoungeBundleDiscountService{
+		repo:       repo,
+		loungeRepo: loungeRepo,
+	}
+}
+
+// Apply gives a lounge stay booked with a bus ticket the best bundle discount at booking time,
+// replacing any discount already on the payload. The discount comes off the stay price only.
+func (s *LoungeBundleDiscountService) Apply(payload *models.LoungeIntentPayload, bookingType string, at time.Time) error {
+	if payload.BundleDiscount != nil {
+		payload.TotalPrice += payload.BundleDiscount.Amount
+		payload.BundleDiscount = nil
+	}
+
+	rules, err := s.repo.GetActiveRules(payload.LoungeID)
+	if err != nil {
+		return err
+	}
+	discount := models.BestBundleDiscount(rules, payload.LoungeID, bookingType, payload.BasePrice, at)
+	if discount == nil {
+		return nil
+	}
+	payload.BundleDiscount = discount
+	payload.TotalPrice = models.RoundMoney(payload.BasePrice + payload.PreOrderTotal - discount.Amount)
+	return nil
+}
+
+// ============================================================================
+// LOUNGE OWNER RULES
+// ============================================================================
+
+// ListLoungeRules returns the owner-funded rules of a lounge
+func (s *LoungeBundleDiscountService) ListLoungeRules(loungeID string) ([]models.LoungeBundleDiscountRule, error) {
+	return s.repo.ListRules(models.BundleFundedByLoungeOwner, &loungeID)
+}
+
+// CreateLoungeRule adds an owner-funded rule to a lounge
+func (s *LoungeBundleDiscountService) CreateLoungeRule(loungeID string, req *models.SaveLoungeBundleDiscountRequest) (*models.LoungeBundleDiscountRule, error) {
+	rule := &models.LoungeBundleDiscountRule{FundedBy: models.BundleFundedByLoungeOwner}
+	applyBundleDiscountRequest(rule, req)
+	rule.LoungeID = &loungeID
+	return rule, s.create(rule)
+}
+
+// UpdateLoungeRule replaces an owner-funded rule of a lounge
+func (s *LoungeBundleDiscountService) UpdateLoungeRule(loungeID, ruleID string, req *models.SaveLoungeBundleDiscountRequest) (*models.LoungeBundleDiscountRule, error) {
+	rule, err := s.getRule(ruleID, models.BundleFundedByLoungeOwner, &loungeID)
+	if err != nil {
+		return nil, err
+	}
+	applyBundleDiscountRequest(rule, req)
+	rule.LoungeID = &loungeID
+	return rule, s.update(rule)
+}
+
+// DeleteLoungeRule removes an owner-funded rule of a lounge
+func (s *LoungeBundleDiscountService) DeleteLoungeRule(loungeID, ruleID string) error {
+	if _, err := s.getRule(ruleID, models.BundleFundedByLoungeOwner, &loungeID); err != nil {
+		return err
+	}
+	return s.delete(ruleID)
+}
+
+// ============================================================================
+// PLATFORM RULES
+// ============================================================================
+
+// ListPlatformRules returns the platform-funded rules
+func (s *LoungeBundleDiscountService) ListPlatformRules() ([]models.LoungeBundleDiscountRule, error) {
+	return s.repo.ListRules(models.BundleFundedByPlatform, nil)
+}
+
+// CreatePlatformRule adds a platform-funded rule, for every lounge unless lounge_id is set
+func (s *LoungeBundleDiscountService) CreatePlatformRule(req *models.SaveLoungeBundleDiscountRequest) (*models.LoungeBundleDiscountRule, error) {
+	rule := &models.LoungeBundleDiscountRule{FundedBy: models.BundleFundedByPlatform}
+	applyBundleDiscountRequest(rule, req)
+	rule.LoungeID = req.LoungeID
+	if err := s.checkLounge(rule.LoungeID); err != nil {
+		return nil, err
+	}
+	return rule, s.create(rule)
+}
+
+// UpdatePlatformRule replaces a platform-funded rule
+func (s *LoungeBundleDiscountService) UpdatePlatformRule(ruleID string, req *models.SaveLoungeBundleDiscountRequest) (*models.LoungeBundleDiscountRule, error) {
+	rule, err := s.getRule(ruleID, models.BundleFundedByPlatform, nil)
+	if err != nil {
+		return nil, err
+	}
+	applyBundleDiscountRequest(rule, req)
+	rule.LoungeID = req.LoungeID
+	if err := s.checkLounge(rule.LoungeID); err != nil {
+		return nil, err
+	}
+	return rule, s.update(rule)
+}
+
+// DeletePlatformRule removes a platform-funded rule
+func (s *LoungeBundleDiscountService) DeletePlatformRule(ruleID string) error {
+	if _, err := s.getRule(ruleID, models.BundleFundedByPlatform, nil); err != nil {
+		return err
+	}
+	return s.delete(ruleID)
+}
+
+// getRule loads a rule funded by fundedBy, and for loungeID when set; other rules are not found
+func (s *LoungeBundleDiscountService) getRule(ruleID string, fundedBy models.BundleDiscountFunder, loungeID *string) (*models.LoungeBundleDiscountRule, error) {
+	rule, err := s.repo.GetRule(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil || rule.FundedBy != fundedBy || (loungeID != nil && (rule.LoungeID == nil || *rule.LoungeID != *loungeID)) {
+		return nil, ErrBundleDiscountRuleNotFound
+	}
+	return rule, nil
+}
+
+func (s *LoungeBundleDiscountService) checkLounge(loungeID *string) error {
+	if loungeID == nil {
+		return nil
+	}
+	id, err := uuid.Parse(*loungeID)
+	if err != nil {
+		return &models.ValidationError{Message: "lounge_id must be a UUID"}
+	}
+	lounge, err := s.loungeRepo.GetLoungeByID(id)
+	if err != nil {
+		return err
+	}
+	if lounge == nil {
+		return &models.ValidationError{Message: "lounge not found"}
+	}
+	return nil
+}
+
+func (s *LoungeBundleDiscountService) create(rule *models.LoungeBundleDiscountRule) error {
+	if err := rule.Validate(); err != nil {
+		return &models.ValidationError{Message: err.Error()}
+	}
+	return s.repo.CreateRule(rule)
+}
+
+func (s *LoungeBundleDiscountService) update(rule *models.LoungeBundleDiscountRule) error {
+	if err := rule.Validate(); err != nil {
+		return &models.ValidationError{Message: err.Error()}
+	}
+	return s.repo.UpdateRule(rule)
+}
+
+func (s *LoungeBundleDiscountService) delete(ruleID string) error {
+	deleted, err := s.repo.DeleteRule(ruleID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrBundleDiscountRuleNotFound
+	}
+	return nil
+}
+
+func applyBundleDiscountRequest(rule *models.LoungeBundleDiscountRule, req *models.SaveLoungeBundleDiscountRequest) {
+	rule.Name = req.Name
+	rule.BookingType = req.BookingType
+	rule.DiscountPercent = req.DiscountPercent
+	rule.MaxDiscountAmount = req.MaxDiscountAmount
+	rule.ValidFrom = req.ValidFrom
+	rule.ValidUntil = req.ValidUntil
+	rule.IsActive = req.IsActive == nil || *req.IsActive
+}
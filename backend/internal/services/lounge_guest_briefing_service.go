@@ -0,0 +1,268 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
+	"github.com/smarttransit/sms-auth-backend/pkg/email"
+	"github.com/smarttransit/sms-auth-backend/pkg/push"
+)
+
+// LoungeGuestBriefingService builds each lounge's list of expected guests, with their linked
+// bus ETAs and pre-orders, for the staff's expected arrivals screen, and runs the morning job
+// that sends the day's list to the lounge's active staff by push and email
+type LoungeGuestBriefingService struct {
+	repo        *database.LoungeGuestBriefingRepository
+	staffRepo   *database.LoungeStaffRepository
+	pushService *PushNotificationService
+	sender      email.Sender
+	config      config.LoungeBriefingConfig
+	logger      *logrus.Logger
+	stopCh      chan struct{}
+}
+
+// NewLoungeGuestBriefingService creates a new LoungeGuestBriefingService
+func NewLoungeGuestBriefingService(
+	repo *database.LoungeGuestBriefingRepository,
+	staffRepo *database.LoungeStaffRepository,
+	pushService *PushNotificationService,
+	sender email.Sender,
+	cfg config.LoungeBriefingConfig,
+	logger *logrus.Logger,
+) *LoungeGuestBriefingService {
+	if cfg.SendHour < 0 || cfg.SendHour > 23 {
+		cfg.SendHour = 6
+	}
+	return &LoungeGuestBriefingService{
+		repo:        repo,
+		staffRepo:   staffRepo,
+		pushService: pushService,
+		sender:      sender,
+		config:      cfg,
+		logger:      logger,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// EXPECTED ARRIVALS
+// ============================================================================
+
+// ExpectedArrivals returns the guests a lounge still expects today, soonest first. Bus ETAs
+// are worked out from the bus's latest position, so repeated calls follow the buses.
+func (s *LoungeGuestBriefingService) ExpectedArrivals(lounge *models.Lounge, now time.Time) (*models.LoungeGuestBriefing, error) {
+	return s.build(lounge.ID.String(), lounge.LoungeName, now)
+}
+
+func (s *LoungeGuestBriefingService) build(loungeID, loungeName string, now time.Time) (*models.LoungeGuestBriefing, error) {
+	from, to := models.LocalDayBounds(now)
+	guests, err := s.repo.GetExpectedGuests(loungeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(guests))
+	for i := range guests {
+		ids[i] = guests[i].LoungeBookingID
+	}
+	preOrders, err := s.repo.GetPreOrders(ids)
+	if err != nil {
+		return nil, err
+	}
+	byBooking := make(map[string][]models.LoungeBookingPreOrder)
+	for _, item := range preOrders {
+		id := item.LoungeBookingID.String()
+		byBooking[id] = append(byBooking[id], item)
+	}
+
+	for i := range guests {
+		guests[i].PreOrders = byBooking[guests[i].LoungeBookingID]
+		if guests[i].PreOrders == nil {
+			guests[i].PreOrders = []models.LoungeBookingPreOrder{}
+		}
+		guests[i].UpdateExpectedAt(EstimateBusETAAtLounge(&guests[i], now))
+	}
+	return models.NewLoungeGuestBriefing(loungeID, loungeName, guests, now), nil
+}
+
+// EstimateBusETAAtLounge estimates when a guest's live-tracked bus reaches the lounge; nil
+// when the bus is not running or either position is unknown
+func EstimateBusETAAtLounge(guest *models.ExpectedLoungeGuest, now time.Time) *time.Time {
+	if guest.BusLatitude == nil || guest.BusLongitude == nil ||
+		guest.LoungeLatitude == nil || guest.LoungeLongitude == nil {
+		return nil
+	}
+
+	distanceKm := utils.HaversineKm(*guest.BusLatitude, *guest.BusLongitude, *guest.LoungeLatitude, *guest.LoungeLongitude)
+
+	speed := defaultBusSpeedKmh
+	if guest.BusSpeedKmh != nil && *guest.BusSpeedKmh >= 5 {
+		speed = *guest.BusSpeedKmh
+	}
+
+	eta := now.Add(time.Duration(distanceKm / speed * float64(time.Hour))).Truncate(time.Minute)
+	return &eta
+}
+
+// ============================================================================
+// MORNING BRIEFING
+// ============================================================================
+
+// Start begins the daily briefing job
+func (s *LoungeGuestBriefingService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Lounge guest briefing job disabled (LOUNGE_BRIEFING_ENABLED=false)")
+		return
+	}
+	s.logger.WithField("send_hour", s.config.SendHour).Info("🛎️ Starting Lounge Guest Briefing job")
+	go s.run()
+}
+
+// Stop stops the daily briefing job
+func (s *LoungeGuestBriefingService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Lounge Guest Briefing job")
+	close(s.stopCh)
+}
+
+func (s *LoungeGuestBriefingService) run() {
+	for {
+		timer := time.NewTimer(time.Until(models.NextNightlyRun(time.Now(), s.config.SendHour)))
+		select {
+		case <-timer.C:
+			s.sendBriefings()
+		case <-s.stopCh:
+			timer.Stop()
+			s.logger.Info("Lounge Guest Briefing job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce sends today's briefings that have not gone out yet (useful for testing or manual trigger)
+func (s *LoungeGuestBriefingService) RunOnce() {
+	s.sendBriefings()
+}
+
+func (s *LoungeGuestBriefingService) sendBriefings() {
+	now := time.Now()
+	from, to := models.LocalDayBounds(now)
+	day := from.Format("2006-01-02")
+
+	lounges, err := s.repo.ListUnbriefedLounges(day, from, to)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list lounges to brief")
+		return
+	}
+
+	sent := 0
+	for _, lounge := range lounges {
+		log := s.logger.WithField("lounge_id", lounge.LoungeID)
+
+		briefing, err := s.build(lounge.LoungeID, lounge.LoungeName, now)
+		if err != nil {
+			log.WithError(err).Error("Failed to build lounge guest briefing")
+			continue
+		}
+		claimed, err := s.repo.ClaimBriefing(lounge.LoungeID, day, briefing.Guests)
+		if err != nil {
+			log.WithError(err).Error("Failed to claim lounge guest briefing")
+			continue
+		}
+		if !claimed {
+			continue // Sent by another server
+		}
+		s.deliver(briefing, log)
+		sent++
+	}
+
+	if sent > 0 {
+		s.logger.WithField("lounges", sent).Info("Lounge guest briefings sent")
+	}
+}
+
+// deliver sends the briefing to the lounge's active staff: a push summary to each, and the
+// full list to those with an email address
+func (s *LoungeGuestBriefingService) deliver(briefing *models.LoungeGuestBriefing, log *logrus.Entry) {
+	loungeID, err := uuid.Parse(briefing.LoungeID)
+	if err != nil {
+		return
+	}
+	staff, err := s.staffRepo.GetActiveStaffByLoungeID(loungeID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get lounge staff for briefing")
+		return
+	}
+
+	userIDs := make([]string, 0, len(staff))
+	for _, member := range staff {
+		userIDs = append(userIDs, member.UserID.String())
+		if s.sender == nil || !member.Email.Valid || strings.TrimSpace(member.Email.String) == "" {
+			continue
+		}
+		if err := s.sender.Send(email.Message{
+			To:      strings.TrimSpace(member.Email.String),
+			Subject: fmt.Sprintf("%s guest list for %s", briefing.LoungeName, briefing.Date),
+			Body:    loungeBriefingEmailBody(briefing),
+		}); err != nil {
+			log.WithError(err).WithField("staff_id", member.ID).Warn("Failed to email lounge guest briefing")
+		}
+	}
+
+	if s.pushService != nil && len(userIDs) > 0 {
+		s.pushService.NotifyUsersAsync(userIDs, push.Message{
+			Title: fmt.Sprintf("Today at %s", briefing.LoungeName),
+			Body:  loungeBriefingSummary(briefing),
+			Data: map[string]string{
+				"type":      "lounge_guest_briefing",
+				"lounge_id": briefing.LoungeID,
+				"date":      briefing.Date,
+			},
+		})
+	}
+}
+
+// ============================================================================
+// MESSAGES
+// ============================================================================
+
+func loungeBriefingSummary(briefing *models.LoungeGuestBriefing) string {
+	summary := fmt.Sprintf("%d bookings, %d guests expected", briefing.Bookings, briefing.Guests)
+	if briefing.PreOrderItems > 0 {
+		summary += fmt.Sprintf(", %d pre-ordered items", briefing.PreOrderItems)
+	}
+	return summary + "."
+}
+
+func loungeBriefingEmailBody(briefing *models.LoungeGuestBriefing) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Good morning,\n\nExpected guests at %s on %s: %s\n",
+		briefing.LoungeName, briefing.Date, loungeBriefingSummary(briefing))
+
+	for _, guest := range briefing.ExpectedGuests {
+		fmt.Fprintf(&b, "\n%s  %s x%d (%s, ref %s)",
+			guest.ExpectedAt.In(models.ReportTimezone).Format("15:04"),
+			guest.PrimaryGuestName, guest.NumberOfGuests, guest.BookingType, guest.BookingReference)
+		if guest.RouteName != nil && guest.DepartureDatetime != nil {
+			fmt.Fprintf(&b, "\n       Bus: %s, departs %s", *guest.RouteName, guest.DepartureDatetime.In(models.ReportTimezone).Format("15:04"))
+		}
+		for _, item := range guest.PreOrders {
+			fmt.Fprintf(&b, "\n       Pre-order: %d x %s", item.Quantity, item.ProductName)
+		}
+		if guest.SpecialRequests != nil && *guest.SpecialRequests != "" {
+			fmt.Fprintf(&b, "\n       Note: %s", *guest.SpecialRequests)
+		}
+	}
+
+	b.WriteString("\n\nTimes follow the buses during the day on the expected arrivals screen.\n\nSmartTransit")
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateBusETAAtLounge(t *testing.T) {
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, models.ReportTimezone)
+	busLat, busLng := 7.2906, 80.6337       // Kandy
+	loungeLat, loungeLng := 7.2906, 80.8337 // ~22 km east
+	speed := 44.0
+
+	guest := &models.ExpectedLoungeGuest{
+		BusLatitude: &busLat, BusLongitude: &busLng, BusSpeedKmh: &speed,
+		LoungeLatitude: &loungeLat, LoungeLongitude: &loungeLng,
+	}
+	eta := EstimateBusETAAtLounge(guest, now)
+	require.NotNil(t, eta)
+	assert.InDelta(t, 30, eta.Sub(now).Minutes(), 1)
+
+	guest.BusLatitude = nil
+	assert.Nil(t, EstimateBusETAAtLounge(guest, now), "no ETA for a bus that is not reporting")
+}
+
+func TestLoungeBriefingMessages(t *testing.T) {
+	route := "Colombo - Kandy"
+	departure := time.Date(2026, 5, 1, 10, 15, 0, 0, models.ReportTimezone)
+	briefing := models.NewLoungeGuestBriefing("lounge-1", "Kandy Lounge", []models.ExpectedLoungeGuest{{
+		BookingReference: "LB-1", BookingType: models.LoungeBookingPreTrip, PrimaryGuestName: "Nimal",
+		NumberOfGuests: 2, ExpectedAt: departure.Add(-time.Hour), RouteName: &route, DepartureDatetime: &departure,
+		PreOrders: []models.LoungeBookingPreOrder{{ProductName: "Tea", Quantity: 2}},
+	}}, departure.Add(-4*time.Hour))
+
+	assert.Equal(t, "1 bookings, 2 guests expected, 2 pre-ordered items.", loungeBriefingSummary(briefing))
+
+	body := loungeBriefingEmailBody(briefing)
+	assert.Contains(t, body, "09:15  Nimal x2 (pre_trip, ref LB-1)")
+	assert.Contains(t, body, "Bus: Colombo - Kandy, departs 10:15")
+	assert.Contains(t, body, "Pre-order: 2 x Tea")
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// LoungeNoShowService manages lounge no-show policies and runs the job that marks confirmed
+// bookings whose guests never checked in as no_show, applying the lounge's fee and telling the passenger
+type LoungeNoShowService struct {
+	repo                *database.LoungeNoShowRepository
+	notificationService *NotificationService
+	config              config.LoungeNoShowConfig
+	logger              *logrus.Logger
+	stopCh              chan struct{}
+}
+
+// NewLoungeNoShowService creates a new LoungeNoShowService
+func NewLoungeNoShowService(
+	repo *database.LoungeNoShowRepository,
+	notificationService *NotificationService,
+	cfg config.LoungeNoShowConfig,
+	logger *logrus.Logger,
+) *LoungeNoShowService {
+	if cfg.GraceMinutes < 0 {
+		cfg.GraceMinutes = 60
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 5 * time.Minute
+	}
+	return &LoungeNoShowService{
+		repo:                repo,
+		notificationService: notificationService,
+		config:              cfg,
+		logger:              logger,
+		stopCh:              make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// POLICY
+// ============================================================================
+
+// GetPolicy returns a lounge's no-show policy, the default if never configured
+func (s *LoungeNoShowService) GetPolicy(loungeID string) (*models.LoungeNoShowPolicy, error) {
+	policy, err := s.repo.GetPolicy(loungeID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = models.DefaultLoungeNoShowPolicy(loungeID)
+	}
+	return policy, nil
+}
+
+// UpdatePolicy configures a lounge's grace period and no-show fee
+func (s *LoungeNoShowService) UpdatePolicy(loungeID string, req *models.UpdateLoungeNoShowPolicyRequest) (*models.LoungeNoShowPolicy, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	policy := &models.LoungeNoShowPolicy{
+		LoungeID:     loungeID,
+		GraceMinutes: req.GraceMinutes,
+		FeeType:      req.FeeType,
+		FeeValue:     req.FeeValue,
+	}
+	if policy.FeeType == models.LoungeNoShowFeeNone || policy.FeeType == models.LoungeNoShowFeeFull {
+		policy.FeeValue = 0
+	}
+	if err := s.repo.UpsertPolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// DefaultGraceMinutes is the grace period used for lounges without their own
+func (s *LoungeNoShowService) DefaultGraceMinutes() int {
+	return s.config.GraceMinutes
+}
+
+// ============================================================================
+// BACKGROUND MARKING
+// ============================================================================
+
+// Start begins the background no-show job
+func (s *LoungeNoShowService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Lounge no-show job disabled (LOUNGE_NO_SHOW_ENABLED=false)")
+		return
+	}
+	s.logger.WithField("interval", s.config.CheckInterval.String()).Info("🛋️ Starting Lounge No-Show job")
+	go s.run()
+}
+
+// Stop stops the background no-show job
+func (s *LoungeNoShowService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Lounge No-Show job")
+	close(s.stopCh)
+}
+
+func (s *LoungeNoShowService) run() {
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processNoShows()
+		case <-s.stopCh:
+			s.logger.Info("Lounge No-Show job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single marking cycle (useful for testing or manual trigger)
+func (s *LoungeNoShowService) RunOnce() {
+	s.processNoShows()
+}
+
+func (s *LoungeNoShowService) processNoShows() {
+	due, err := s.repo.GetDueNoShows(s.config.GraceMinutes, 200)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get due lounge no-shows")
+		return
+	}
+
+	marked := 0
+	for i := range due {
+		booking := &due[i]
+		fee := models.NoShowFee(booking.FeeType, booking.FeeValue, booking.TotalAmount)
+
+		ok, err := s.repo.MarkNoShow(booking.BookingID, fee)
+		if err != nil {
+			s.logger.WithError(err).WithField("booking_reference", booking.BookingReference).Error("Failed to mark lounge no-show")
+			continue
+		}
+		if !ok {
+			// Checked in or changed since it was selected
+			continue
+		}
+		marked++
+
+		if booking.PrimaryGuestPhone != "" {
+			if err := s.notificationService.SendSMS(booking.PrimaryGuestPhone, loungeNoShowMessage(booking, fee)); err != nil {
+				s.logger.WithError(err).WithField("booking_reference", booking.BookingReference).Warn("Failed to send lounge no-show notice")
+			}
+		}
+	}
+
+	if marked > 0 {
+		s.logger.WithField("count", marked).Info("Marked lounge bookings as no-show")
+	}
+}
+
+func loungeNoShowMessage(booking *models.DueLoungeNoShow, fee float64) string {
+	msg := fmt.Sprintf("SmartTransit: Your lounge booking at %s for %s was marked as a no-show as you did not check in.",
+		booking.LoungeName, booking.ScheduledArrival.In(models.ReportTimezone).Format("Jan 2, 15:04"))
+	if fee > 0 {
+		msg += fmt.Sprintf(" A no-show fee of LKR %.2f applies.", fee)
+	}
+	return msg + fmt.Sprintf(" Ref: %s", booking.BookingReference)
+}
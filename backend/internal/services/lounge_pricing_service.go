@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrLoungePriceRuleNotFound = errors.New("lounge price rule not found")
+	ErrInvalidLoungePricing    = errors.New("invalid pricing type")
+)
+
+// defaultLoungeCapacity matches the capacity assumed for lounges without one
+const defaultLoungeCapacity = 50
+
+// LoungeSlot is the capacity window a lounge stay occupies
+type LoungeSlot struct {
+	Date  time.Time
+	Start string // "HH:MM"
+	End   string // "HH:MM"
+}
+
+// LoungePricingService prices lounge stays from the flat per-type price, the owner's
+// time slot and date overrides, and occupancy-based surge
+type LoungePricingService struct {
+	pricingRepo       *database.LoungePricingRepository
+	loungeBookingRepo *database.LoungeBookingRepository
+	intentRepo        *database.BookingIntentRepository
+}
+
+// NewLoungePricingService creates a new LoungePricingService
+func NewLoungePricingService(
+	pricingRepo *database.LoungePricingRepository,
+	loungeBookingRepo *database.LoungeBookingRepository,
+	intentRepo *database.BookingIntentRepository,
+) *LoungePricingService {
+	return &LoungePricingService{
+		pricingRepo:       pricingRepo,
+		loungeBookingRepo: loungeBookingRepo,
+		intentRepo:        intentRepo,
+	}
+}
+
+// Quote returns the per-guest price of a stay of the pricing type starting at checkIn
+func (s *LoungePricingService) Quote(lounge *models.Lounge, pricingType string, checkIn time.Time) (*models.LoungePriceQuote, error) {
+	if !models.IsLoungePricingType(pricingType) {
+		return nil, ErrInvalidLoungePricing
+	}
+	priceStr, err := s.loungeBookingRepo.GetLoungePrice(lounge.ID, pricingType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lounge price: %w", err)
+	}
+	basePrice, err := models.ParseMoney(priceStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse lounge price: %w", err)
+	}
+
+	loungeID := lounge.ID.String()
+	rules, err := s.pricingRepo.GetActiveRules(loungeID)
+	if err != nil {
+		return nil, err
+	}
+	surge, err := s.pricingRepo.GetSurgeSettings(loungeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var occupancy float64
+	if surge != nil && surge.Enabled {
+		capacity := defaultLoungeCapacity
+		if lounge.Capacity.Valid && lounge.Capacity.Int64 > 0 {
+			capacity = int(lounge.Capacity.Int64)
+		}
+		slot := LoungeSlotFor(checkIn, pricingType)
+		available, err := s.intentRepo.GetLoungeCapacityAvailable(lounge.ID, slot.Date, slot.Start, slot.End)
+		if err != nil {
+			return nil, err
+		}
+		occupancy = float64(capacity-available) / float64(capacity) * 100
+	}
+
+	quote := models.QuoteLoungePrice(basePrice.Float64(), pricingType, checkIn, rules, surge, occupancy)
+	quote.LoungeID = loungeID
+	return &quote, nil
+}
+
+// LoungeSlotFor returns the capacity window for a stay: the check-in time plus the length of
+// the pricing type, with "until_bus" counted as three hours. Slots end at midnight ("24:00") at the latest.
+func LoungeSlotFor(checkIn time.Time, pricingType string) LoungeSlot {
+	hours := 3
+	switch pricingType {
+	case "1_hour":
+		hours = 1
+	case "2_hours":
+		hours = 2
+	}
+
+	local := checkIn.In(models.LoungeTimezone)
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, models.LoungeTimezone)
+	end := local.Add(time.Duration(hours) * time.Hour)
+	endStr := end.Format("15:04")
+	if !end.Before(date.AddDate(0, 0, 1)) {
+		endStr = "24:00"
+	}
+	return LoungeSlot{Date: date, Start: local.Format("15:04"), End: endStr}
+}
+
+// ============================================================================
+// OWNER MANAGEMENT
+// ============================================================================
+
+// ListRules returns a lounge's price rules
+func (s *LoungePricingService) ListRules(loungeID string) ([]models.LoungePriceRule, error) {
+	return s.pricingRepo.ListRules(loungeID)
+}
+
+// CreateRule adds a price rule to a lounge
+func (s *LoungePricingService) CreateRule(loungeID string, req *models.CreateLoungePriceRuleRequest) (*models.LoungePriceRule, error) {
+	rule := &models.LoungePriceRule{LoungeID: loungeID}
+	applyLoungePriceRuleRequest(rule, req)
+	if err := rule.Validate(); err != nil {
+		return nil, &models.ValidationError{Message: err.Error()}
+	}
+	if err := s.pricingRepo.CreateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule replaces a price rule
+func (s *LoungePricingService) UpdateRule(loungeID, ruleID string, req *models.CreateLoungePriceRuleRequest) (*models.LoungePriceRule, error) {
+	rule, err := s.pricingRepo.GetRule(loungeID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, ErrLoungePriceRuleNotFound
+	}
+
+	applyLoungePriceRuleRequest(rule, req)
+	if err := rule.Validate(); err != nil {
+		return nil, &models.ValidationError{Message: err.Error()}
+	}
+	if err := s.pricingRepo.UpdateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a price rule
+func (s *LoungePricingService) DeleteRule(loungeID, ruleID string) error {
+	deleted, err := s.pricingRepo.DeleteRule(loungeID, ruleID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrLoungePriceRuleNotFound
+	}
+	return nil
+}
+
+// GetSurgeSettings returns a lounge's surge settings, disabled if never configured
+func (s *LoungePricingService) GetSurgeSettings(loungeID string) (*models.LoungeSurgeSettings, error) {
+	settings, err := s.pricingRepo.GetSurgeSettings(loungeID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &models.LoungeSurgeSettings{LoungeID: loungeID, OccupancyThresholdPercent: 80}
+	}
+	return settings, nil
+}
+
+// UpdateSurgeSettings configures a lounge's occupancy-based surge
+func (s *LoungePricingService) UpdateSurgeSettings(loungeID string, req *models.UpdateLoungeSurgeRequest) (*models.LoungeSurgeSettings, error) {
+	settings := &models.LoungeSurgeSettings{
+		LoungeID:                  loungeID,
+		Enabled:                   req.Enabled,
+		OccupancyThresholdPercent: req.OccupancyThresholdPercent,
+		MaxSurgePercent:           req.MaxSurgePercent,
+	}
+	if err := s.pricingRepo.UpsertSurgeSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func applyLoungePriceRuleRequest(rule *models.LoungePriceRule, req *models.CreateLoungePriceRuleRequest) {
+	rule.Name = req.Name
+	rule.RuleType = req.RuleType
+	rule.PricingType = req.PricingType
+	rule.DaysOfWeek = models.FormatDaysOfWeek(req.DaysOfWeek)
+	rule.StartTime = req.StartTime
+	rule.EndTime = req.EndTime
+	rule.StartDate = req.StartDate
+	rule.EndDate = req.EndDate
+	rule.AdjustmentType = req.AdjustmentType
+	rule.Value = req.Value
+	rule.Priority = req.Priority
+	rule.IsActive = req.IsActive == nil || *req.IsActive
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoungeSlotFor_UsesPricingTypeLengthInLocalTime(t *testing.T) {
+	// 03:30 UTC is 09:00 in Colombo
+	checkIn := time.Date(2026, 3, 4, 3, 30, 0, 0, time.UTC)
+
+	slot := LoungeSlotFor(checkIn, "2_hours")
+	assert.Equal(t, "09:00", slot.Start)
+	assert.Equal(t, "11:00", slot.End)
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, models.LoungeTimezone), slot.Date)
+
+	assert.Equal(t, "12:00", LoungeSlotFor(checkIn, "until_bus").End)
+	assert.Equal(t, "10:00", LoungeSlotFor(checkIn, "1_hour").End)
+}
+
+func TestLoungeSlotFor_EndsAtMidnight(t *testing.T) {
+	checkIn := time.Date(2026, 3, 4, 22, 30, 0, 0, models.LoungeTimezone)
+
+	slot := LoungeSlotFor(checkIn, "3_hours")
+	assert.Equal(t, "22:30", slot.Start)
+	assert.Equal(t, "24:00", slot.End)
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrLoungeReviewBookingNotFound = errors.New("lounge booking not found")
+	ErrLoungeReviewNotAllowed      = errors.New("only a visit the guest has checked in to can be reviewed")
+	ErrLoungeReviewDuplicate       = errors.New("this booking has already been reviewed")
+	ErrLoungeReviewProductNotFound = errors.New("product was not ordered on this booking")
+	ErrLoungeReviewLoungeNotFound  = errors.New("lounge or product not found")
+)
+
+// LoungeReviewService lets guests rate the lounges they visited and the products they ordered
+// there. Each review updates the lounge's or product's average rating.
+type LoungeReviewService struct {
+	reviewRepo  *database.LoungeReviewRepository
+	bookingRepo *database.LoungeBookingRepository
+	loungeRepo  *database.LoungeRepository
+}
+
+// NewLoungeReviewService creates a new LoungeReviewService
+func NewLoungeReviewService(
+	reviewRepo *database.LoungeReviewRepository,
+	bookingRepo *database.LoungeBookingRepository,
+	loungeRepo *database.LoungeRepository,
+) *LoungeReviewService {
+	return &LoungeReviewService{
+		reviewRepo:  reviewRepo,
+		bookingRepo: bookingRepo,
+		loungeRepo:  loungeRepo,
+	}
+}
+
+// ReviewLounge records the guest's rating of the lounge visited on their booking
+func (s *LoungeReviewService) ReviewLounge(userID, bookingID uuid.UUID, req *models.CreateLoungeReviewRequest) (*models.LoungeReview, error) {
+	return s.create(userID, bookingID, nil, req)
+}
+
+// ReviewProduct records the guest's rating of a product pre-ordered or ordered on their booking
+func (s *LoungeReviewService) ReviewProduct(userID, bookingID, productID uuid.UUID, req *models.CreateLoungeReviewRequest) (*models.LoungeReview, error) {
+	return s.create(userID, bookingID, &productID, req)
+}
+
+func (s *LoungeReviewService) create(userID, bookingID uuid.UUID, productID *uuid.UUID, req *models.CreateLoungeReviewRequest) (*models.LoungeReview, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	booking, err := s.bookingRepo.GetLoungeBookingByID(bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lounge booking: %w", err)
+	}
+	if booking == nil || booking.UserID != userID {
+		return nil, ErrLoungeReviewBookingNotFound
+	}
+	if !booking.CanBeReviewed() {
+		return nil, ErrLoungeReviewNotAllowed
+	}
+	if productID != nil {
+		ordered, err := s.reviewRepo.BookingHasProduct(bookingID, *productID)
+		if err != nil {
+			return nil, err
+		}
+		if !ordered {
+			return nil, ErrLoungeReviewProductNotFound
+		}
+	}
+
+	review := &models.LoungeReview{
+		LoungeBookingID: booking.ID,
+		LoungeID:        booking.LoungeID,
+		LoungeProductID: productID,
+		UserID:          userID,
+		Rating:          req.Rating,
+		Comment:         req.Comment,
+	}
+	created, err := s.reviewRepo.Create(review)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrLoungeReviewDuplicate
+	}
+	return review, nil
+}
+
+// ListLoungeReviews returns a page of a lounge's reviews
+func (s *LoungeReviewService) ListLoungeReviews(loungeID uuid.UUID, limit, offset int) (*models.LoungeReviewPage, error) {
+	lounge, err := s.loungeRepo.GetLoungeByID(loungeID)
+	if err != nil {
+		return nil, err
+	}
+	if lounge == nil {
+		return nil, ErrLoungeReviewLoungeNotFound
+	}
+	return s.reviewRepo.ListForLounge(loungeID, limit, offset)
+}
+
+// ListProductReviews returns a page of the reviews of one of a lounge's products
+func (s *LoungeReviewService) ListProductReviews(loungeID, productID uuid.UUID, limit, offset int) (*models.LoungeReviewPage, error) {
+	product, err := s.bookingRepo.GetProductByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lounge product: %w", err)
+	}
+	if product == nil || product.LoungeID != loungeID {
+		return nil, ErrLoungeReviewLoungeNotFound
+	}
+	return s.reviewRepo.ListForProduct(productID, limit, offset)
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// masterRouteImportRequiredColumns must be in the header of a CSV import
+var masterRouteImportRequiredColumns = []string{"route_number", "route_name", "origin_city", "destination_city", "stop_order", "stop_name"}
+
+// MasterRouteImportService seeds master routes and their stops from admin uploads, reporting
+// every problem by row so a file can be fixed in one pass. Imports only add new routes;
+// existing ones are edited in place because bookings, lounges and segments point at their stops.
+type MasterRouteImportService struct {
+	repo   *database.MasterRouteImportRepository
+	logger *logrus.Logger
+}
+
+// NewMasterRouteImportService creates a new MasterRouteImportService
+func NewMasterRouteImportService(repo *database.MasterRouteImportRepository, logger *logrus.Logger) *MasterRouteImportService {
+	return &MasterRouteImportService{repo: repo, logger: logger}
+}
+
+// Import validates the upload and, unless it is a dry run or has errors, creates its routes
+func (s *MasterRouteImportService) Import(imp *models.MasterRouteImport, dryRun bool) (*models.MasterRouteImportResult, error) {
+	result := &models.MasterRouteImportResult{DryRun: dryRun, Routes: []models.MasterRouteImportSummary{}}
+	errs := imp.Validate()
+
+	numbers := make([]string, 0, len(imp.Routes))
+	for _, route := range imp.Routes {
+		if route.RouteNumber != "" {
+			numbers = append(numbers, route.RouteNumber)
+		}
+	}
+	if len(numbers) > 0 {
+		taken, err := s.repo.ExistingRouteNumbers(numbers)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range imp.Routes {
+			if taken[strings.ToLower(route.RouteNumber)] {
+				errs = append(errs, models.MasterRouteImportError{
+					MasterRouteImportRef: route.Ref,
+					RouteNumber:          route.RouteNumber,
+					Field:                "route_number",
+					Message:              "a master route with this route_number already exists",
+				})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		result.Errors = models.SortMasterRouteImportErrors(errs)
+		return result, nil
+	}
+	result.Errors = errs
+	result.Valid = true
+	if dryRun {
+		for _, route := range imp.Routes {
+			result.Routes = append(result.Routes, models.MasterRouteImportSummary{
+				RouteNumber: route.RouteNumber,
+				RouteName:   route.RouteName,
+				Stops:       len(route.Stops),
+			})
+		}
+		return result, nil
+	}
+
+	created, err := s.repo.Create(imp.Routes)
+	if err != nil {
+		return nil, err
+	}
+	result.Routes = created
+	s.logger.WithField("routes", len(created)).Info("Imported master routes")
+	return result, nil
+}
+
+// ParseMasterRouteImportJSON reads a JSON upload: {"routes": [{..., "stops": [...]}]}
+func ParseMasterRouteImportJSON(r io.Reader) (*models.MasterRouteImport, error) {
+	var imp models.MasterRouteImport
+	if err := json.NewDecoder(r).Decode(&imp); err != nil {
+		return nil, &models.ValidationError{Message: "invalid JSON: " + err.Error()}
+	}
+	for i := range imp.Routes {
+		imp.Routes[i].Ref = models.MasterRouteImportRef{Route: i + 1}
+		for j := range imp.Routes[i].Stops {
+			imp.Routes[i].Stops[j].Ref = models.MasterRouteImportRef{Route: i + 1, Stop: j + 1}
+		}
+	}
+	return &imp, nil
+}
+
+// ParseMasterRouteImportCSV reads a CSV upload with one row per stop. Rows are grouped into
+// routes by route_number; a route's columns may be left blank after its first row but must
+// not disagree. Cells that do not parse are kept as parse errors for Validate to report.
+func ParseMasterRouteImportCSV(r io.Reader) (*models.MasterRouteImport, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &models.ValidationError{Message: "the CSV file is empty"}
+	}
+	if err != nil {
+		return nil, &models.ValidationError{Message: "invalid CSV header: " + err.Error()}
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	var missing []string
+	for _, name := range masterRouteImportRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &models.ValidationError{Message: "the CSV header is missing " + strings.Join(missing, ", ")}
+	}
+
+	imp := &models.MasterRouteImport{}
+	routeIndex := map[string]int{}
+	stops := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			imp.ParseErrors = append(imp.ParseErrors, models.MasterRouteImportError{MasterRouteImportRef: models.MasterRouteImportRef{Line: line}, Message: "unreadable row: " + err.Error()})
+			continue
+		}
+
+		row := csvImportRow{record: record, columns: columns, ref: models.MasterRouteImportRef{Line: line}}
+		number := row.text("route_number")
+		if number == "" && row.blank() {
+			continue
+		}
+
+		if stops++; stops > models.MasterRouteImportMaxStops {
+			return nil, &models.ValidationError{Message: fmt.Sprintf("at most %d stops are imported at once", models.MasterRouteImportMaxStops)}
+		}
+
+		key := strings.ToLower(number)
+		i, ok := routeIndex[key]
+		if !ok {
+			i = len(imp.Routes)
+			routeIndex[key] = i
+			imp.Routes = append(imp.Routes, models.MasterRouteImportRoute{RouteNumber: number, Ref: row.ref})
+		}
+		route := &imp.Routes[i]
+		row.route = number
+
+		row.mergeText("route_name", &route.RouteName)
+		row.mergeText("origin_city", &route.OriginCity)
+		row.mergeText("destination_city", &route.DestinationCity)
+		if distance := row.float("total_distance_km"); distance != nil {
+			if route.TotalDistanceKm != nil && *route.TotalDistanceKm != *distance {
+				row.fail("total_distance_km", "differs from an earlier row of this route")
+			}
+			route.TotalDistanceKm = distance
+		}
+		if duration := row.int("estimated_duration_minutes"); duration != nil {
+			if route.EstimatedDurationMinutes != nil && *route.EstimatedDurationMinutes != *duration {
+				row.fail("estimated_duration_minutes", "differs from an earlier row of this route")
+			}
+			route.EstimatedDurationMinutes = duration
+		}
+
+		stop := models.MasterRouteImportStop{
+			StopName:                 row.text("stop_name"),
+			Latitude:                 row.float("latitude"),
+			Longitude:                row.float("longitude"),
+			ArrivalTimeOffsetMinutes: row.int("arrival_time_offset_minutes"),
+			IsMajorStop:              row.bool("is_major_stop"),
+			Ref:                      row.ref,
+		}
+		// A missing or unparsable order stays 0, which Validate reports
+		stop.StopOrder, _ = strconv.Atoi(row.text("stop_order"))
+		route.Stops = append(route.Stops, stop)
+		imp.ParseErrors = append(imp.ParseErrors, row.errs...)
+	}
+	return imp, nil
+}
+
+// csvImportRow reads the cells of one CSV import row, collecting the ones that do not parse
+type csvImportRow struct {
+	record  []string
+	columns map[string]int
+	ref     models.MasterRouteImportRef
+	route   string
+	errs    []models.MasterRouteImportError
+}
+
+func (r *csvImportRow) text(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r *csvImportRow) blank() bool {
+	for _, cell := range r.record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *csvImportRow) fail(column, message string) {
+	r.errs = append(r.errs, models.MasterRouteImportError{MasterRouteImportRef: r.ref, RouteNumber: r.route, Field: column, Message: message})
+}
+
+// mergeText fills a route column from the row, or reports a row that disagrees with it
+func (r *csvImportRow) mergeText(column string, value *string) {
+	text := r.text(column)
+	switch {
+	case text == "":
+	case *value == "":
+		*value = text
+	case !strings.EqualFold(*value, text):
+		r.fail(column, fmt.Sprintf("%q differs from %q on an earlier row of this route", text, *value))
+	}
+}
+
+func (r *csvImportRow) float(column string) *float64 {
+	text := r.text(column)
+	if text == "" {
+		return nil
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		r.fail(column, fmt.Sprintf("%q is not a number", text))
+		return nil
+	}
+	return &value
+}
+
+func (r *csvImportRow) int(column string) *int {
+	text := r.text(column)
+	if text == "" {
+		return nil
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		r.fail(column, fmt.Sprintf("%q is not a whole number", text))
+		return nil
+	}
+	return &value
+}
+
+func (r *csvImportRow) bool(column string) bool {
+	switch text := strings.ToLower(r.text(column)); text {
+	case "", "0", "false", "no", "n":
+		return false
+	case "1", "true", "yes", "y":
+		return true
+	default:
+		r.fail(column, fmt.Sprintf("%q is not true or false", text))
+		return false
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMasterRouteImportCSV(t *testing.T) {
+	csvData := "\ufeffRoute_Number,route_name,origin_city,destination_city,total_distance_km,stop_order,stop_name,latitude,longitude,arrival_time_offset_minutes,is_major_stop\n" +
+		"02,Colombo - Matara,Colombo,Matara,160,1,Colombo Fort,6.93,79.85,0,yes\n" +
+		"02,,,,,2,Galle,,,150,no\n" +
+		"\n" +
+		"02,Colombo - Matara,Colombo,Matara,160,3,Matara,,,210,true\n" +
+		"04,Colombo - Kandy,Colombo,Kandy,,1,Colombo,,,,\n" +
+		"04,Colombo - Kandi,Colombo,Kandy,abc,two,Kandy,,,x,maybe\n"
+
+	imp, err := ParseMasterRouteImportCSV(strings.NewReader(csvData))
+	require.NoError(t, err)
+	require.Len(t, imp.Routes, 2)
+
+	route := imp.Routes[0]
+	assert.Equal(t, "02", route.RouteNumber)
+	assert.Equal(t, "Colombo - Matara", route.RouteName)
+	require.NotNil(t, route.TotalDistanceKm)
+	assert.Equal(t, 160.0, *route.TotalDistanceKm)
+	require.Len(t, route.Stops, 3)
+	assert.True(t, route.Stops[0].IsMajorStop)
+	assert.False(t, route.Stops[1].IsMajorStop)
+	assert.Equal(t, 3, route.Stops[1].Ref.Line)
+	assert.Equal(t, 5, route.Stops[2].Ref.Line, "blank lines keep later rows' line numbers")
+
+	fields := map[string]bool{}
+	for _, e := range imp.ParseErrors {
+		assert.Equal(t, 7, e.Line)
+		assert.Equal(t, "04", e.RouteNumber)
+		fields[e.Field] = true
+	}
+	assert.Equal(t, map[string]bool{"route_name": true, "total_distance_km": true, "arrival_time_offset_minutes": true, "is_major_stop": true}, fields)
+
+	errs := imp.Validate()
+	assert.Contains(t, errs, imp.ParseErrors[0])
+	var stopOrder bool
+	for _, e := range errs {
+		stopOrder = stopOrder || (e.Field == "stop_order" && e.Line == 7)
+	}
+	assert.True(t, stopOrder, "an unparsable stop_order is reported")
+}
+
+func TestParseMasterRouteImportCSV_Header(t *testing.T) {
+	_, err := ParseMasterRouteImportCSV(strings.NewReader("route_number,stop_name\n02,Galle\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "route_name")
+
+	_, err = ParseMasterRouteImportCSV(strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestParseMasterRouteImportJSON(t *testing.T) {
+	imp, err := ParseMasterRouteImportJSON(strings.NewReader(`{"routes":[{"route_number":"02","stops":[{"stop_order":1},{"stop_order":2}]}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, imp.Routes[0].Ref.Route)
+	assert.Equal(t, 2, imp.Routes[0].Stops[1].Ref.Stop)
+
+	_, err = ParseMasterRouteImportJSON(strings.NewReader(`{"routes":`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// ErrMeUserNotFound is returned when the token's user no longer exists
+var ErrMeUserNotFound = errors.New("user not found")
+
+// MeService assembles the signed-in user's account, role profiles and onboarding state so the
+// apps can work out who the user is with one call instead of probing every profile endpoint
+type MeService struct {
+	userRepo        *database.UserRepository
+	passengerRepo   *database.PassengerRepository
+	staffRepo       *database.BusStaffRepository
+	busOwnerRepo    *database.BusOwnerRepository
+	loungeOwnerRepo *database.LoungeOwnerRepository
+	loungeStaffRepo *database.LoungeStaffRepository
+	preferences     *UserPreferencesService
+	logger          *logrus.Logger
+}
+
+// NewMeService creates a new MeService
+func NewMeService(
+	userRepo *database.UserRepository,
+	passengerRepo *database.PassengerRepository,
+	staffRepo *database.BusStaffRepository,
+	busOwnerRepo *database.BusOwnerRepository,
+	loungeOwnerRepo *database.LoungeOwnerRepository,
+	loungeStaffRepo *database.LoungeStaffRepository,
+	preferences *UserPreferencesService,
+	logger *logrus.Logger,
+) *MeService {
+	return &MeService{
+		userRepo:        userRepo,
+		passengerRepo:   passengerRepo,
+		staffRepo:       staffRepo,
+		busOwnerRepo:    busOwnerRepo,
+		loungeOwnerRepo: loungeOwnerRepo,
+		loungeStaffRepo: loungeStaffRepo,
+		preferences:     preferences,
+		logger:          logger,
+	}
+}
+
+// GetMe returns the user and a profile summary and onboarding entry for every role they hold.
+// Lounge staff are not a user role, so they are included whenever a lounge_staff record exists;
+// likewise bus staff still waiting for approval.
+func (s *MeService) GetMe(userID uuid.UUID) (*models.MeResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrMeUserNotFound
+	}
+
+	me := &models.MeResponse{
+		User: models.MeUser{
+			ID:            user.ID,
+			Phone:         user.Phone,
+			Roles:         []string(user.Roles),
+			Status:        user.Status,
+			PhoneVerified: user.PhoneVerified,
+		},
+		Onboarding: []models.RoleOnboarding{},
+	}
+	if me.User.Roles == nil {
+		me.User.Roles = []string{}
+	}
+
+	if s.userRepo.HasRole(user, "passenger") {
+		passenger, err := s.passengerRepo.GetPassengerByUserID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get passenger profile: %w", err)
+		}
+		if passenger != nil {
+			me.Passenger = &models.MePassengerProfile{
+				ID:           passenger.ID,
+				FirstName:    passenger.FirstName,
+				LastName:     passenger.LastName,
+				Email:        passenger.Email,
+				Completeness: passenger.Completeness(),
+			}
+		}
+		me.Onboarding = append(me.Onboarding, models.PassengerOnboarding(passenger))
+	}
+
+	if err := s.addStaff(me, userID, s.userRepo.HasRole(user, "driver") || s.userRepo.HasRole(user, "conductor")); err != nil {
+		return nil, err
+	}
+
+	if s.userRepo.HasRole(user, "bus_owner") {
+		owner, err := s.busOwnerRepo.GetByUserID(userID.String())
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get bus owner profile: %w", err)
+		}
+		if owner != nil {
+			me.BusOwner = &models.MeBusOwnerProfile{
+				ID:                 owner.ID,
+				CompanyName:        owner.CompanyName,
+				VerificationStatus: owner.VerificationStatus,
+				TotalBuses:         owner.TotalBuses,
+			}
+		}
+		me.Onboarding = append(me.Onboarding, models.BusOwnerOnboarding(owner))
+	}
+
+	if s.userRepo.HasRole(user, "lounge_owner") {
+		owner, err := s.loungeOwnerRepo.GetLoungeOwnerByUserID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get lounge owner profile: %w", err)
+		}
+		if owner != nil {
+			me.LoungeOwner = models.NewMeLoungeOwnerProfile(owner)
+		}
+		me.Onboarding = append(me.Onboarding, models.LoungeOwnerOnboarding(owner))
+	}
+
+	loungeStaff, err := s.loungeStaffRepo.GetStaffByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lounge staff profile: %w", err)
+	}
+	if loungeStaff != nil {
+		me.LoungeStaff = models.NewMeLoungeStaffProfile(loungeStaff)
+		me.Onboarding = append(me.Onboarding, models.LoungeStaffOnboarding(loungeStaff))
+	}
+
+	// The apps pick their display language from here
+	if s.preferences != nil {
+		prefs, err := s.preferences.Get(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user preferences: %w", err)
+		}
+		me.Preferences = prefs
+	}
+
+	return me, nil
+}
+
+// addStaff adds the driver/conductor profile, current employer and onboarding state. Staff
+// awaiting a bus owner's approval don't have the role yet but are included all the same.
+func (s *MeService) addStaff(me *models.MeResponse, userID uuid.UUID, hasRole bool) error {
+	staff, err := s.staffRepo.GetByUserID(userID.String())
+	if err != nil && !errors.Is(err, database.ErrStaffNotFound) {
+		return fmt.Errorf("failed to get staff profile: %w", err)
+	}
+	if staff == nil {
+		if hasRole {
+			me.Onboarding = append(me.Onboarding, models.StaffOnboarding(nil, nil))
+		}
+		return nil
+	}
+
+	employment, err := s.staffRepo.GetCurrentEmployment(staff.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get staff employment: %w", err)
+	}
+	me.Staff = &models.MeStaffProfile{
+		ID:                 staff.ID,
+		FirstName:          staff.FirstName,
+		LastName:           staff.LastName,
+		StaffType:          staff.StaffType,
+		VerificationStatus: staff.VerificationStatus,
+	}
+	if employment != nil {
+		me.Staff.BusOwnerID = &employment.BusOwnerID
+		me.Staff.EmploymentStatus = &employment.EmploymentStatus
+	}
+	me.Onboarding = append(me.Onboarding, models.StaffOnboarding(staff, employment))
+	return nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/pkg/sms"
+)
+
+const (
+	maxDeferredSMS      = 500              // Oldest messages are dropped beyond this
+	deferredSMSMaxAge   = 6 * time.Hour    // Messages older than this are no longer worth sending
+	deferredSMSInterval = 30 * time.Second // How often the deferred queue is retried
+)
+
+// TextMessageSender is implemented by SMS gateways that can send free-form (non-OTP) text
+type TextMessageSender interface {
+	SendBulkSMS(phones []string, message string) (int64, error)
+}
+
+// availabilityReporter is implemented by gateways that expose circuit breaker state
+type availabilityReporter interface {
+	Available() bool
+}
+
+// deferredSMS is a notification held back while the SMS gateway is degraded
+type deferredSMS struct {
+	phone    string
+	message  string
+	queuedAt time.Time
+}
+
+// NotificationService delivers passenger notifications (currently over SMS)
+// While the SMS gateway's circuit breaker is open, messages are queued in memory
+// and delivered once the gateway recovers.
+type NotificationService struct {
+	smsGateway sms.SMSGateway
+	smsMode    string // "dev" only logs messages, "production" sends them
+	logger     *logrus.Logger
+
+	mu       sync.Mutex
+	deferred []deferredSMS
+	stopCh   chan struct{}
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(smsGateway sms.SMSGateway, smsMode string, logger *logrus.Logger) *NotificationService {
+	return &NotificationService{
+		smsGateway: smsGateway,
+		smsMode:    smsMode,
+		logger:     logger,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// SendSMS sends a free-form text message to a phone number.
+// If the gateway is temporarily unavailable the message is queued and nil is returned.
+func (s *NotificationService) SendSMS(phone, message string) error {
+	if phone == "" {
+		return fmt.Errorf("no phone number to notify")
+	}
+
+	if s.smsMode != "production" {
+		s.logger.WithFields(logrus.Fields{
+			"phone":   phone,
+			"message": message,
+		}).Info("📨 [DEV] Notification not sent (SMS dev mode)")
+		return nil
+	}
+
+	sender, ok := s.smsGateway.(TextMessageSender)
+	if !ok {
+		return fmt.Errorf("SMS gateway %s does not support text messages", s.smsGateway.GetName())
+	}
+
+	if _, err := sender.SendBulkSMS([]string{phone}, message); err != nil {
+		if sms.IsUnavailable(err) {
+			s.enqueue(deferredSMS{phone: phone, message: message, queuedAt: time.Now()})
+			return nil
+		}
+		return fmt.Errorf("failed to send notification SMS: %w", err)
+	}
+	return nil
+}
+
+// Start begins retrying deferred messages in the background
+func (s *NotificationService) Start() {
+	if s.smsMode != "production" {
+		return
+	}
+	go s.run()
+}
+
+// Stop stops the background retry loop
+func (s *NotificationService) Stop() {
+	if s.smsMode != "production" {
+		return
+	}
+	close(s.stopCh)
+	if pending := s.DeferredCount(); pending > 0 {
+		s.logger.WithField("pending", pending).Warn("Notification service stopped with undelivered deferred SMS")
+	}
+}
+
+func (s *NotificationService) run() {
+	ticker := time.NewTicker(deferredSMSInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.FlushDeferred()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// DeferredCount returns the number of messages waiting for the gateway to recover
+func (s *NotificationService) DeferredCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.deferred)
+}
+
+// FlushDeferred sends queued messages if the gateway is available again.
+// Returns the number of messages delivered.
+func (s *NotificationService) FlushDeferred() int {
+	if reporter, ok := s.smsGateway.(availabilityReporter); ok && !reporter.Available() {
+		return 0
+	}
+	sender, ok := s.smsGateway.(TextMessageSender)
+	if !ok {
+		return 0
+	}
+
+	s.mu.Lock()
+	batch := s.deferred
+	s.deferred = nil
+	s.mu.Unlock()
+
+	sent := 0
+	cutoff := time.Now().Add(-deferredSMSMaxAge)
+	for i, msg := range batch {
+		if msg.queuedAt.Before(cutoff) {
+			s.logger.WithField("phone", msg.phone).Warn("Dropping deferred SMS older than max age")
+			continue
+		}
+
+		if _, err := sender.SendBulkSMS([]string{msg.phone}, msg.message); err != nil {
+			if sms.IsUnavailable(err) {
+				// Gateway tripped again: put the rest back in front of anything queued meanwhile
+				s.requeueFront(batch[i:])
+				break
+			}
+			s.logger.WithError(err).WithField("phone", msg.phone).Error("Failed to deliver deferred SMS")
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		s.logger.WithField("sent", sent).Info("📨 Delivered deferred notification SMS")
+	}
+	return sent
+}
+
+func (s *NotificationService) enqueue(msg deferredSMS) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.deferred) >= maxDeferredSMS {
+		s.deferred = s.deferred[1:]
+		s.logger.Warn("Deferred SMS queue full - dropped oldest message")
+	}
+	s.deferred = append(s.deferred, msg)
+
+	s.logger.WithFields(logrus.Fields{
+		"phone":   msg.phone,
+		"pending": len(s.deferred),
+	}).Warn("SMS gateway unavailable - notification queued for retry")
+}
+
+func (s *NotificationService) requeueFront(msgs []deferredSMS) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deferred = append(append([]deferredSMS{}, msgs...), s.deferred...)
+	if overflow := len(s.deferred) - maxDeferredSMS; overflow > 0 {
+		s.deferred = s.deferred[overflow:]
+	}
+}
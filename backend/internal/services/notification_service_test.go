@@ -0,0 +1,45 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTextGateway struct {
+	available bool
+	sent      []string
+}
+
+func (g *fakeTextGateway) SendOTP(phone, otpCode, appType string) (int64, error) { return 0, nil }
+func (g *fakeTextGateway) GetName() string                                       { return "fake" }
+func (g *fakeTextGateway) Available() bool                                       { return g.available }
+
+func (g *fakeTextGateway) SendBulkSMS(phones []string, message string) (int64, error) {
+	if !g.available {
+		return 0, fmt.Errorf("failed to send SMS request: %w", httpclient.ErrCircuitOpen)
+	}
+	g.sent = append(g.sent, phones[0])
+	return 1, nil
+}
+
+func TestNotificationService_QueuesWhileGatewayUnavailable(t *testing.T) {
+	gateway := &fakeTextGateway{available: false}
+	s := NewNotificationService(gateway, "production", logrus.New())
+
+	assert.NoError(t, s.SendSMS("+94771234567", "Your bus leaves in 2 hours"))
+	assert.NoError(t, s.SendSMS("+94771234568", "Your bus leaves in 2 hours"))
+	assert.Equal(t, 2, s.DeferredCount())
+
+	// Still unavailable: nothing is sent or lost
+	assert.Equal(t, 0, s.FlushDeferred())
+	assert.Equal(t, 2, s.DeferredCount())
+
+	gateway.available = true
+	assert.Equal(t, 2, s.FlushDeferred())
+	assert.Equal(t, 0, s.DeferredCount())
+	assert.Equal(t, []string{"+94771234567", "+94771234568"}, gateway.sent)
+}
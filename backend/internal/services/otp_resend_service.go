@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+)
+
+// ErrMaxResendsExceeded indicates the phone has used all resends for its current OTP request
+var ErrMaxResendsExceeded = errors.New("maximum OTP resends exceeded")
+
+// ResendCooldownError is returned when a resend is requested before the cooldown has elapsed
+type ResendCooldownError struct {
+	RetryAfter int // Seconds until the next resend is allowed
+}
+
+func (e *ResendCooldownError) Error() string {
+	return fmt.Sprintf("please wait %d seconds before requesting another code", e.RetryAfter)
+}
+
+// resendSession tracks resends since the last send-otp for a phone
+type resendSession struct {
+	resends        int
+	lastSentAt     time.Time
+	previousSentAt time.Time // lastSentAt before the latest Reserve, restored by Release
+}
+
+// OTPResendService enforces an escalating cooldown between OTP resends.
+// State is in memory: a restart only forgets cooldowns, the OTP itself lives in the database.
+type OTPResendService struct {
+	cooldowns  []time.Duration
+	maxResends int
+	sessionTTL time.Duration // Idle sessions are forgotten after this
+	now        func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*resendSession
+}
+
+// NewOTPResendService creates a new OTP resend service
+func NewOTPResendService(cfg config.OTPConfig) *OTPResendService {
+	cooldowns := cfg.ResendCooldowns
+	if len(cooldowns) == 0 {
+		cooldowns = []time.Duration{30 * time.Second}
+	}
+	sessionTTL := time.Duration(cfg.RateWindowMinutes) * time.Minute
+	if sessionTTL <= 0 {
+		sessionTTL = 10 * time.Minute
+	}
+
+	return &OTPResendService{
+		cooldowns:  cooldowns,
+		maxResends: cfg.MaxResends,
+		sessionTTL: sessionTTL,
+		now:        time.Now,
+		sessions:   make(map[string]*resendSession),
+	}
+}
+
+// RecordSend starts a fresh resend session after a send-otp
+func (s *OTPResendService) RecordSend(phone string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.pruneLocked(now)
+	s.sessions[phone] = &resendSession{lastSentAt: now}
+}
+
+// Reserve claims the next resend for a phone, returning its number (1 for the first resend).
+// Returns *ResendCooldownError if called too early and ErrMaxResendsExceeded once the budget is spent.
+func (s *OTPResendService) Reserve(phone string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	session, ok := s.sessions[phone]
+	if !ok || now.Sub(session.lastSentAt) > s.sessionTTL {
+		// No send-otp seen recently (or the server restarted): allow the resend, which
+		// OTPService.ResendOTP still refuses unless an OTP is pending
+		session = &resendSession{}
+		s.sessions[phone] = session
+	}
+
+	if s.maxResends > 0 && session.resends >= s.maxResends {
+		return 0, ErrMaxResendsExceeded
+	}
+
+	if !session.lastSentAt.IsZero() {
+		if wait := s.cooldownFor(session.resends+1) - now.Sub(session.lastSentAt); wait > 0 {
+			return 0, &ResendCooldownError{RetryAfter: int((wait + time.Second - 1) / time.Second)}
+		}
+	}
+
+	session.resends++
+	session.previousSentAt = session.lastSentAt
+	session.lastSentAt = now
+	return session.resends, nil
+}
+
+// Release undoes a Reserve whose resend could not be delivered, so the user is not penalised
+func (s *OTPResendService) Release(phone string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[phone]; ok && session.resends > 0 {
+		session.resends--
+		session.lastSentAt = session.previousSentAt
+	}
+}
+
+// NextResendIn returns how long until the next resend is allowed (0 if allowed now)
+func (s *OTPResendService) NextResendIn(phone string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[phone]
+	if !ok || session.lastSentAt.IsZero() {
+		return 0
+	}
+	wait := s.cooldownFor(session.resends+1) - s.now().Sub(session.lastSentAt)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// cooldownFor returns the wait before the nth resend; the last configured value repeats
+func (s *OTPResendService) cooldownFor(resend int) time.Duration {
+	if resend > len(s.cooldowns) {
+		return s.cooldowns[len(s.cooldowns)-1]
+	}
+	return s.cooldowns[resend-1]
+}
+
+// pruneLocked forgets idle sessions; mu must be held
+func (s *OTPResendService) pruneLocked(now time.Time) {
+	for phone, session := range s.sessions {
+		if now.Sub(session.lastSentAt) > s.sessionTTL {
+			delete(s.sessions, phone)
+		}
+	}
+}
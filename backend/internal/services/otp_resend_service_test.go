@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResendService(maxResends int) (*OTPResendService, *time.Time) {
+	now := time.Date(2025, 10, 19, 8, 0, 0, 0, time.UTC)
+	s := NewOTPResendService(config.OTPConfig{
+		RateWindowMinutes: 10,
+		ResendCooldowns:   []time.Duration{30 * time.Second, 60 * time.Second, 120 * time.Second},
+		MaxResends:        maxResends,
+	})
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestOTPResend_EscalatingCooldown(t *testing.T) {
+	s, now := newTestResendService(0)
+	phone := "+94771234567"
+	s.RecordSend(phone)
+
+	_, err := s.Reserve(phone)
+	var cooldownErr *ResendCooldownError
+	require.ErrorAs(t, err, &cooldownErr)
+	assert.Equal(t, 30, cooldownErr.RetryAfter)
+
+	for i, wait := range []time.Duration{30, 60, 120, 120} {
+		*now = now.Add((wait - 1) * time.Second)
+		_, err = s.Reserve(phone)
+		assert.Error(t, err, "resend %d allowed too early", i+1)
+
+		*now = now.Add(time.Second)
+		n, err := s.Reserve(phone)
+		require.NoError(t, err)
+		assert.Equal(t, i+1, n)
+	}
+}
+
+func TestOTPResend_MaxResends(t *testing.T) {
+	s, now := newTestResendService(2)
+	phone := "+94771234567"
+	s.RecordSend(phone)
+
+	for i := 0; i < 2; i++ {
+		*now = now.Add(2 * time.Minute)
+		_, err := s.Reserve(phone)
+		require.NoError(t, err)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	_, err := s.Reserve(phone)
+	assert.ErrorIs(t, err, ErrMaxResendsExceeded)
+
+	// A fresh send-otp resets the budget
+	s.RecordSend(phone)
+	*now = now.Add(30 * time.Second)
+	n, err := s.Reserve(phone)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestOTPResend_ReleaseRestoresCooldown(t *testing.T) {
+	s, now := newTestResendService(0)
+	phone := "+94771234567"
+	s.RecordSend(phone)
+
+	*now = now.Add(30 * time.Second)
+	_, err := s.Reserve(phone)
+	require.NoError(t, err)
+	s.Release(phone)
+
+	// Delivery failed: the same resend can be retried straight away
+	assert.Equal(t, time.Duration(0), s.NextResendIn(phone))
+	n, err := s.Reserve(phone)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 60*time.Second, s.NextResendIn(phone))
+}
@@ -20,6 +20,9 @@ const (
 
 	// MaxOTPAttempts is the maximum number of validation attempts
 	MaxOTPAttempts = 3
+
+	// OTPReuseMinValidity is how long an active OTP must still be valid to be resent as-is
+	OTPReuseMinValidity = 1 * time.Minute
 )
 
 var (
@@ -35,13 +38,22 @@ var (
 	// ErrNoOTPFound indicates no OTP exists for the phone number
 	ErrNoOTPFound = fmt.Errorf("no OTP found for this phone number")
 
+	// ErrNoActiveOTP indicates a resend was asked for with no OTP pending to resend
+	ErrNoActiveOTP = fmt.Errorf("no active OTP to resend")
+
 	// ErrOTPAlreadyUsed indicates the OTP has already been successfully validated
 	ErrOTPAlreadyUsed = fmt.Errorf("OTP has already been used")
 )
 
+// TestOTPProvider supplies the fixed OTP of QA test numbers; implemented by OTPTestNumberService
+type TestOTPProvider interface {
+	TestOTP(phone string) (string, bool)
+}
+
 // OTPService handles OTP generation and validation
 type OTPService struct {
-	db database.DB
+	db          database.DB
+	testNumbers TestOTPProvider // Optional
 }
 
 // NewOTPService creates a new OTP service
@@ -51,6 +63,11 @@ func NewOTPService(db database.DB) *OTPService {
 	}
 }
 
+// SetTestNumbers makes QA test numbers get the deploy's fixed OTP instead of a random one
+func (s *OTPService) SetTestNumbers(provider TestOTPProvider) {
+	s.testNumbers = provider
+}
+
 // GenerateOTP generates a new 6-digit OTP for the given phone number
 // It invalidates any existing OTPs for the phone number and stores IP/User-Agent for security tracking
 func (s *OTPService) GenerateOTP(phone, ipAddress, userAgent string) (string, error) {
@@ -59,11 +76,17 @@ func (s *OTPService) GenerateOTP(phone, ipAddress, userAgent string) (string, er
 		return "", fmt.Errorf("failed to invalidate existing OTP: %w", err)
 	}
 
-	// Generate random 6-digit OTP
+	// Generate random 6-digit OTP; QA test numbers get the fixed one, marked by its purpose
+	purpose := "authentication"
 	otp, err := generateRandomOTP()
 	if err != nil {
 		return "", fmt.Errorf("failed to generate OTP: %w", err)
 	}
+	if s.testNumbers != nil {
+		if code, ok := s.testNumbers.TestOTP(phone); ok {
+			otp, purpose = code, models.OTPPurposeQATest
+		}
+	}
 
 	// Calculate expiry time
 	expiresAt := time.Now().Add(OTPExpiryDuration)
@@ -71,10 +94,10 @@ func (s *OTPService) GenerateOTP(phone, ipAddress, userAgent string) (string, er
 	// Store in database with IP address and user agent for security tracking
 	query := `
 		INSERT INTO otp_verifications (phone, otp_code, purpose, expires_at, attempts, max_attempts, ip_address, user_agent)
-		VALUES ($1, $2, 'authentication', $3, 0, $4, $5, $6)
+		VALUES ($1, $2, $3, $4, 0, $5, $6, $7)
 	`
 
-	_, err = s.db.Exec(query, phone, otp, expiresAt, MaxOTPAttempts, ipAddress, userAgent)
+	_, err = s.db.Exec(query, phone, otp, purpose, expiresAt, MaxOTPAttempts, ipAddress, userAgent)
 	if err != nil {
 		return "", fmt.Errorf("failed to store OTP: %w", err)
 	}
@@ -307,10 +330,30 @@ func generateRandomOTP() (string, error) {
 	return fmt.Sprintf("%06d", n.Int64()), nil
 }
 
-// ResendOTP generates a new OTP for the phone number
-// This is an alias for GenerateOTP for clarity in API handlers
-func (s *OTPService) ResendOTP(phone, ipAddress, userAgent string) (string, error) {
-	return s.GenerateOTP(phone, ipAddress, userAgent)
+// ResendOTP returns the active OTP for the phone number if it is still fresh
+// (valid for at least OTPReuseMinValidity), so a late SMS for the first send still
+// works. An active OTP close to expiry is replaced by a new one. Without an active
+// OTP (none sent, expired or out of attempts) it returns ErrNoActiveOTP: a resend
+// never starts a sign-in, send-otp does.
+// reused reports whether the existing code was returned.
+func (s *OTPService) ResendOTP(phone, ipAddress, userAgent string) (otp string, reused bool, err error) {
+	otpRecord, err := s.getOTPRecord(phone)
+	if err != nil && err != sql.ErrNoRows {
+		return "", false, fmt.Errorf("failed to get OTP record: %w", err)
+	}
+
+	if err == sql.ErrNoRows || time.Until(otpRecord.ExpiresAt) <= 0 || otpRecord.Attempts >= MaxOTPAttempts {
+		return "", false, ErrNoActiveOTP
+	}
+	if time.Until(otpRecord.ExpiresAt) >= OTPReuseMinValidity {
+		return otpRecord.OTPCode, true, nil
+	}
+
+	otp, err = s.GenerateOTP(phone, ipAddress, userAgent)
+	if err != nil {
+		return "", false, err
+	}
+	return otp, false, nil
 }
 
 // VerifyAndInvalidate validates the OTP and immediately invalidates it
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -38,10 +39,10 @@ func TestGenerateOTP(t *testing.T) {
 
 	// Expect insert query
 	mock.ExpectExec("INSERT INTO otp_verifications").
-		WithArgs(phone, sqlmock.AnyArg(), sqlmock.AnyArg(), MaxOTPAttempts).
+		WithArgs(phone, sqlmock.AnyArg(), "authentication", sqlmock.AnyArg(), MaxOTPAttempts, "127.0.0.1", "test-agent").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	otp, err := service.GenerateOTP(phone)
+	otp, err := service.GenerateOTP(phone, "127.0.0.1", "test-agent")
 	require.NoError(t, err)
 	assert.Len(t, otp, 6)
 	assert.Regexp(t, "^[0-9]{6}$", otp)
@@ -49,6 +50,49 @@ func TestGenerateOTP(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// fakeTestNumbers gives testPhone a fixed OTP
+type fakeTestNumbers struct {
+	testPhone string
+	code      string
+}
+
+func (f fakeTestNumbers) TestOTP(phone string) (string, bool) {
+	return f.code, phone == f.testPhone
+}
+
+func TestGenerateOTP_TestNumberGetsFixedCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewOTPService(&mockDatabase{db: db})
+	service.SetTestNumbers(fakeTestNumbers{testPhone: "0770000001", code: "424242"})
+
+	mock.ExpectExec("UPDATE otp_verifications").
+		WithArgs("0770000001").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// Marked with its own purpose so QA sign-ins can be told apart from real ones
+	mock.ExpectExec("INSERT INTO otp_verifications").
+		WithArgs("0770000001", "424242", models.OTPPurposeQATest, sqlmock.AnyArg(), MaxOTPAttempts, "127.0.0.1", "test-agent").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	otp, err := service.GenerateOTP("0770000001", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.Equal(t, "424242", otp)
+
+	// Other numbers still get a random code
+	mock.ExpectExec("UPDATE otp_verifications").
+		WithArgs("0771234567").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO otp_verifications").
+		WithArgs("0771234567", sqlmock.AnyArg(), "authentication", sqlmock.AnyArg(), MaxOTPAttempts, "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	_, err = service.GenerateOTP("0771234567", "", "")
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGenerateOTP_Uniqueness(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -68,10 +112,10 @@ func TestGenerateOTP_Uniqueness(t *testing.T) {
 
 		// Expect insert query
 		mock.ExpectExec("INSERT INTO otp_verifications").
-			WithArgs(phone, sqlmock.AnyArg(), sqlmock.AnyArg(), MaxOTPAttempts).
+			WithArgs(phone, sqlmock.AnyArg(), "authentication", sqlmock.AnyArg(), MaxOTPAttempts, "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		otp, err := service.GenerateOTP(phone)
+		otp, err := service.GenerateOTP(phone, "", "")
 		require.NoError(t, err)
 		otps[otp] = true
 	}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// otpTestNumberCacheTTL is how long the allowlist is served from memory before it is reloaded;
+// changes made on this instance apply at once
+const otpTestNumberCacheTTL = time.Minute
+
+var (
+	// ErrOTPTestNumberExists indicates the number is already on the allowlist
+	ErrOTPTestNumberExists = errors.New("phone number is already a QA test number")
+
+	// ErrOTPTestNumberHasAccount indicates the number belongs to an existing account; the bypass
+	// must never open a real user's account
+	ErrOTPTestNumberHasAccount = errors.New("phone number belongs to an existing account")
+
+	// ErrOTPTestNumberNotFound indicates no listed number has the ID
+	ErrOTPTestNumberNotFound = errors.New("QA test number not found")
+)
+
+// OTPTestNumberService manages the allowlist of internal QA phone numbers. Listed numbers are
+// sent no SMS and accept one fixed OTP, derived from the deploy ID so all instances of a
+// deploy agree on it and every deploy gets a new one. Numbers are stored only as salted hashes.
+type OTPTestNumberService struct {
+	repo     *database.OTPTestNumberRepository
+	userRepo *database.UserRepository
+	config   config.OTPTestNumbersConfig
+	logger   *logrus.Logger
+	code     models.OTPTestCode
+
+	mu       sync.Mutex
+	hashes   map[string]bool
+	loadedAt time.Time
+}
+
+// NewOTPTestNumberService creates a new OTPTestNumberService and derives the deploy's OTP
+func NewOTPTestNumberService(
+	repo *database.OTPTestNumberRepository,
+	userRepo *database.UserRepository,
+	cfg config.OTPTestNumbersConfig,
+	logger *logrus.Logger,
+) *OTPTestNumberService {
+	code := models.OTPTestCode{DeployID: cfg.DeployID, Shared: cfg.DeployID != ""}
+	if !code.Shared {
+		random := make([]byte, 8)
+		if _, err := rand.Read(random); err != nil {
+			panic(fmt.Sprintf("failed to generate deploy ID: %v", err))
+		}
+		code.DeployID = "local-" + hex.EncodeToString(random)
+	}
+	code.Code = deriveTestOTP(cfg.Secret, code.DeployID)
+
+	return &OTPTestNumberService{
+		repo:     repo,
+		userRepo: userRepo,
+		config:   cfg,
+		logger:   logger,
+		code:     code,
+	}
+}
+
+// deriveTestOTP turns the deploy ID into a 6-digit code keyed by the secret
+func deriveTestOTP(secret, deployID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("otp-test-numbers:" + deployID))
+	sum := mac.Sum(nil)
+	return fmt.Sprintf("%06d", binary.BigEndian.Uint32(sum[:4])%1000000)
+}
+
+// otpTestPhoneHash is the salted SHA-256 a test number is stored under
+func otpTestPhoneHash(salt, phone string) string {
+	sum := sha256.Sum256([]byte(salt + phone))
+	return hex.EncodeToString(sum[:])
+}
+
+// Enabled reports whether the bypass is switched on
+func (s *OTPTestNumberService) Enabled() bool {
+	return s != nil && s.config.Enabled
+}
+
+// IsTestNumber reports whether a normalized phone number is on the allowlist. Lookup failures
+// count as not listed, so real SMS delivery is the fallback.
+func (s *OTPTestNumberService) IsTestNumber(phone string) bool {
+	if !s.Enabled() {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hashes == nil || time.Since(s.loadedAt) > otpTestNumberCacheTTL {
+		hashes, err := s.repo.ListHashes()
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to load QA test numbers")
+			return false
+		}
+		s.hashes = make(map[string]bool, len(hashes))
+		for _, hash := range hashes {
+			s.hashes[hash] = true
+		}
+		s.loadedAt = time.Now()
+	}
+	return s.hashes[otpTestPhoneHash(s.config.HashSalt, phone)]
+}
+
+// TestOTP returns the fixed OTP when the phone number is on the allowlist
+func (s *OTPTestNumberService) TestOTP(phone string) (string, bool) {
+	if !s.IsTestNumber(phone) {
+		return "", false
+	}
+	return s.code.Code, true
+}
+
+// Code returns the running deploy's fixed OTP
+func (s *OTPTestNumberService) Code() models.OTPTestCode {
+	return s.code
+}
+
+// List returns the allowlisted numbers, masked
+func (s *OTPTestNumberService) List() ([]models.OTPTestNumber, error) {
+	numbers, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	if numbers == nil {
+		numbers = []models.OTPTestNumber{}
+	}
+	return numbers, nil
+}
+
+// Add puts a normalized phone number on the allowlist
+func (s *OTPTestNumberService) Add(phone string, req *models.AddOTPTestNumberRequest, adminID string) (*models.OTPTestNumber, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByPhone(phone)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return nil, ErrOTPTestNumberHasAccount
+	}
+
+	number := &models.OTPTestNumber{
+		PhoneHash:   otpTestPhoneHash(s.config.HashSalt, phone),
+		PhoneMasked: models.MaskPhone(phone, 3),
+		Label:       req.Label,
+		CreatedBy:   adminID,
+	}
+	created, err := s.repo.Create(number)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrOTPTestNumberExists
+	}
+
+	s.invalidate()
+	return number, nil
+}
+
+// Remove takes a number off the allowlist
+func (s *OTPTestNumberService) Remove(id string) (*models.OTPTestNumber, error) {
+	number, err := s.repo.Delete(id)
+	if err != nil {
+		return nil, err
+	}
+	if number == nil {
+		return nil, ErrOTPTestNumberNotFound
+	}
+
+	s.invalidate()
+	return number, nil
+}
+
+func (s *OTPTestNumberService) invalidate() {
+	s.mu.Lock()
+	s.hashes = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveTestOTP(t *testing.T) {
+	code := deriveTestOTP("secret", "release-2026-10-14")
+	assert.Regexp(t, "^[0-9]{6}$", code)
+	assert.Equal(t, code, deriveTestOTP("secret", "release-2026-10-14"), "every instance of a deploy agrees")
+	assert.NotEqual(t, code, deriveTestOTP("secret", "release-2026-10-15"), "each deploy gets a new code")
+	assert.NotEqual(t, code, deriveTestOTP("other-secret", "release-2026-10-14"))
+}
+
+func TestNewOTPTestNumberService_Code(t *testing.T) {
+	shared := NewOTPTestNumberService(nil, nil, config.OTPTestNumbersConfig{Enabled: true, DeployID: "abc123", Secret: "s"}, logrus.New())
+	assert.True(t, shared.Code().Shared)
+	assert.Equal(t, "abc123", shared.Code().DeployID)
+	assert.Equal(t, deriveTestOTP("s", "abc123"), shared.Code().Code)
+
+	local := NewOTPTestNumberService(nil, nil, config.OTPTestNumbersConfig{Enabled: true, Secret: "s"}, logrus.New())
+	assert.False(t, local.Code().Shared)
+	assert.Contains(t, local.Code().DeployID, "local-")
+
+	disabled := NewOTPTestNumberService(nil, nil, config.OTPTestNumbersConfig{Enabled: false}, logrus.New())
+	_, ok := disabled.TestOTP("+94771234567")
+	assert.False(t, ok, "no lookups while disabled")
+}
+
+func TestOTPTestPhoneHash(t *testing.T) {
+	hash := otpTestPhoneHash("salt", "+94771234567")
+	assert.Len(t, hash, 64)
+	assert.NotContains(t, hash, "771234567")
+	assert.NotEqual(t, hash, otpTestPhoneHash("other", "+94771234567"))
+}
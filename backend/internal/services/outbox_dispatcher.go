@@ -0,0 +1,178 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/events"
+)
+
+// outboxLease is how long a claimed event is held before another dispatcher may take it
+const outboxLease = 5 * time.Minute
+
+// OutboxHandler handles an event delivered from the outbox. Returning an error has the event
+// redelivered later, to every consumer, so handlers must be idempotent; the event ID stays the
+// same across redeliveries.
+type OutboxHandler func(event events.Event) error
+
+type outboxSubscription struct {
+	name    string
+	types   map[string]bool // Empty means every type
+	handler OutboxHandler
+}
+
+// OutboxDispatcher delivers the events saved in the transactional outbox. Each event goes to
+// the subscribed consumers in order; once they have all handled it, it is forwarded to the
+// event bus for best-effort consumers and marked dispatched. An event a consumer fails on is
+// retried with backoff, up to MaxAttempts.
+type OutboxDispatcher struct {
+	repo      *database.OutboxRepository
+	publisher events.Publisher
+	config    config.EventsConfig
+	logger    *logrus.Logger
+	subs      []outboxSubscription
+	stopCh    chan struct{}
+}
+
+// NewOutboxDispatcher creates a new OutboxDispatcher
+func NewOutboxDispatcher(
+	repo *database.OutboxRepository,
+	publisher events.Publisher,
+	cfg config.EventsConfig,
+	logger *logrus.Logger,
+) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		repo:      repo,
+		publisher: publisher,
+		config:    cfg,
+		logger:    logger,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Subscribe registers a consumer for the given event types, or for every type when none are
+// given. Consumers must be registered before Start.
+func (d *OutboxDispatcher) Subscribe(name string, handler OutboxHandler, types ...string) {
+	sub := outboxSubscription{name: name, handler: handler, types: make(map[string]bool, len(types))}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	d.subs = append(d.subs, sub)
+}
+
+// Start begins the background dispatcher
+func (d *OutboxDispatcher) Start() {
+	if !d.config.OutboxEnabled {
+		d.logger.Info("Outbox dispatcher disabled (EVENTS_OUTBOX_ENABLED=false)")
+		return
+	}
+	d.logger.WithField("interval", d.config.OutboxInterval.String()).Info("📮 Starting Outbox Dispatcher")
+	go d.run()
+}
+
+// Stop stops the background dispatcher. Events claimed but not yet settled are delivered
+// again once their lease runs out.
+func (d *OutboxDispatcher) Stop() {
+	if !d.config.OutboxEnabled {
+		return
+	}
+	d.logger.Info("🛑 Stopping Outbox Dispatcher")
+	close(d.stopCh)
+}
+
+func (d *OutboxDispatcher) run() {
+	ticker := time.NewTicker(d.config.OutboxInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.dispatchDue()
+		case <-d.stopCh:
+			d.logger.Info("Outbox Dispatcher stopped")
+			return
+		}
+	}
+}
+
+// RunOnce dispatches the events due now (useful for testing or manual trigger)
+func (d *OutboxDispatcher) RunOnce() {
+	d.dispatchDue()
+}
+
+func (d *OutboxDispatcher) dispatchDue() {
+	due, err := d.repo.ClaimDue(d.config.OutboxBatchSize, outboxLease)
+	if err != nil {
+		d.logger.WithError(err).Error("Failed to claim outbox events")
+		return
+	}
+	for i := range due {
+		d.dispatch(&due[i])
+	}
+}
+
+// dispatch delivers one claimed event and records the outcome
+func (d *OutboxDispatcher) dispatch(outboxEvent *models.OutboxEvent) {
+	event := events.Event{
+		ID:         outboxEvent.ID,
+		Type:       outboxEvent.EventType,
+		OccurredAt: outboxEvent.CreatedAt,
+		Data:       outboxEvent.Payload,
+	}
+	fields := logrus.Fields{
+		"event_id":   event.ID,
+		"event_type": event.Type,
+		"attempt":    outboxEvent.Attempts,
+	}
+
+	if err := d.deliver(event); err != nil {
+		if outboxEvent.Attempts >= d.config.OutboxMaxAttempts {
+			d.logger.WithError(err).WithFields(fields).Error("Giving up on outbox event")
+			if err := d.repo.MarkFailed(event.ID, err.Error()); err != nil {
+				d.logger.WithError(err).WithFields(fields).Error("Failed to mark outbox event failed")
+			}
+			return
+		}
+		retryAt := time.Now().Add(models.OutboxRetryDelay(outboxEvent.Attempts))
+		d.logger.WithError(err).WithFields(fields).WithField("retry_at", retryAt).Warn("Outbox event delivery failed, will retry")
+		if err := d.repo.MarkRetry(event.ID, err.Error(), retryAt); err != nil {
+			d.logger.WithError(err).WithFields(fields).Error("Failed to schedule outbox event retry")
+		}
+		return
+	}
+
+	if err := d.publisher.Publish(event); err != nil {
+		d.logger.WithError(err).WithFields(fields).Warn("Failed to forward outbox event to the event bus")
+	}
+	if err := d.repo.MarkDispatched(event.ID); err != nil {
+		d.logger.WithError(err).WithFields(fields).Error("Failed to mark outbox event dispatched")
+	}
+}
+
+// deliver hands the event to each subscribed consumer, stopping at the first that fails
+func (d *OutboxDispatcher) deliver(event events.Event) error {
+	for _, sub := range d.subs {
+		if len(sub.types) > 0 && !sub.types[event.Type] {
+			continue
+		}
+		if err := callOutboxHandler(sub.handler, event); err != nil {
+			return fmt.Errorf("%s: %w", sub.name, err)
+		}
+	}
+	return nil
+}
+
+// callOutboxHandler runs a consumer, turning a panic into an error so the event is retried
+// rather than the dispatcher stopping
+func callOutboxHandler(handler OutboxHandler, event events.Event) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(event)
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxDispatcher_DeliverByType(t *testing.T) {
+	d := NewOutboxDispatcher(nil, events.NopPublisher{}, config.EventsConfig{}, logrus.New())
+
+	var calls []string
+	d.Subscribe("all", func(e events.Event) error {
+		calls = append(calls, "all:"+e.Type)
+		return nil
+	})
+	d.Subscribe("confirmed", func(e events.Event) error {
+		calls = append(calls, "confirmed:"+e.Type)
+		return nil
+	}, models.EventBookingConfirmed)
+
+	assert.NoError(t, d.deliver(events.Event{Type: models.EventBookingConfirmed}))
+	assert.NoError(t, d.deliver(events.Event{Type: models.EventBookingCancelled}))
+	assert.Equal(t, []string{"all:booking.confirmed", "confirmed:booking.confirmed", "all:booking.cancelled"}, calls)
+}
+
+func TestOutboxDispatcher_DeliverStopsAtFailure(t *testing.T) {
+	d := NewOutboxDispatcher(nil, events.NopPublisher{}, config.EventsConfig{}, logrus.New())
+
+	var reached bool
+	d.Subscribe("failing", func(e events.Event) error { return errors.New("db down") })
+	d.Subscribe("after", func(e events.Event) error {
+		reached = true
+		return nil
+	})
+
+	err := d.deliver(events.Event{Type: models.EventBookingConfirmed})
+	assert.EqualError(t, err, "failing: db down")
+	assert.False(t, reached, "the event is retried as a whole")
+}
+
+func TestOutboxDispatcher_PanicIsAnError(t *testing.T) {
+	d := NewOutboxDispatcher(nil, events.NopPublisher{}, config.EventsConfig{}, logrus.New())
+	d.Subscribe("panicking", func(e events.Event) error { panic("boom") })
+
+	err := d.deliver(events.Event{Type: models.EventBookingCancelled})
+	assert.EqualError(t, err, "panicking: panic: boom")
+}
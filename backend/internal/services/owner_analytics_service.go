@@ -0,0 +1,124 @@
+package services
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// OwnerAnalyticsService serves bus owner analytics from the daily stats rollup and runs the job
+// that keeps it current. Each run rolls up the last few local days again, since bookings,
+// cancellations and trip completions keep changing them after the day itself.
+type OwnerAnalyticsService struct {
+	repo   *database.OwnerAnalyticsRepository
+	config config.OwnerAnalyticsConfig
+	rules  models.PunctualityRules
+	logger *logrus.Logger
+	stopCh chan struct{}
+}
+
+// NewOwnerAnalyticsService creates a new OwnerAnalyticsService. On-time rules come from the
+// punctuality settings so owners see the same figure passengers do.
+func NewOwnerAnalyticsService(repo *database.OwnerAnalyticsRepository, cfg config.OwnerAnalyticsConfig, punctuality config.PunctualityConfig, logger *logrus.Logger) *OwnerAnalyticsService {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.RecomputeDays <= 0 {
+		cfg.RecomputeDays = 3
+	}
+	if cfg.BackfillDays < cfg.RecomputeDays {
+		cfg.BackfillDays = cfg.RecomputeDays
+	}
+	if cfg.BackfillDays > models.OwnerAnalyticsMaxDays {
+		cfg.BackfillDays = models.OwnerAnalyticsMaxDays
+	}
+	return &OwnerAnalyticsService{
+		repo:   repo,
+		config: cfg,
+		rules: models.PunctualityRules{
+			DepartureGraceMinutes: punctuality.DepartureGraceMinutes,
+			ArrivalGraceMinutes:   punctuality.ArrivalGraceMinutes,
+		},
+		logger: logger,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// GetAnalytics returns an owner's revenue, occupancy, cancellation and punctuality figures
+// for the requested local days
+func (s *OwnerAnalyticsService) GetAnalytics(busOwnerID string, req *models.OwnerAnalyticsRequest) (*models.OwnerAnalytics, error) {
+	from, to, err := req.Range(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.GetDailyStats(busOwnerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildOwnerAnalytics(stats, from, to), nil
+}
+
+// Start begins the rollup job, backfilling recent days first
+func (s *OwnerAnalyticsService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Owner analytics rollup disabled (OWNER_ANALYTICS_ENABLED=false)")
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"interval":       s.config.Interval.String(),
+		"recompute_days": s.config.RecomputeDays,
+	}).Info("📊 Starting Owner analytics rollup")
+	go s.run()
+}
+
+// Stop stops the rollup job
+func (s *OwnerAnalyticsService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Owner analytics rollup")
+	close(s.stopCh)
+}
+
+func (s *OwnerAnalyticsService) run() {
+	s.rollUp(s.config.BackfillDays)
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.rollUp(s.config.RecomputeDays)
+		case <-s.stopCh:
+			s.logger.Info("Owner analytics rollup stopped")
+			return
+		}
+	}
+}
+
+// RunOnce rolls up the recent days once (useful for testing or manual trigger)
+func (s *OwnerAnalyticsService) RunOnce() {
+	s.rollUp(s.config.RecomputeDays)
+}
+
+// rollUp recomputes the given number of local days up to and including today
+func (s *OwnerAnalyticsService) rollUp(days int) {
+	started := time.Now()
+	local := started.In(models.ReportTimezone)
+	to := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, models.ReportTimezone).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -days)
+
+	written, err := s.repo.RollUp(from, to, s.rules)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to roll up owner analytics")
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"days":     days,
+		"rows":     written,
+		"duration": time.Since(started).String(),
+	}).Info("Owner analytics rolled up")
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrBankAccountNotFound     = errors.New("bank account not found")
+	ErrBankAccountNotPending   = errors.New("bank account is not awaiting verification")
+	ErrPayoutBatchNotFound     = errors.New("payout batch not found")
+	ErrPayoutBatchExists       = errors.New("a payout batch already exists for this period")
+	ErrPayoutPeriodNotFinished = errors.New("payout period has not ended yet")
+	ErrPayoutNotFound          = errors.New("payout not found")
+	ErrPayoutInvalidTransition = errors.New("payout cannot move to that status")
+)
+
+// OwnerPayoutService manages owners' payout bank accounts and generates a payout batch per
+// settlement cycle: gross earnings less platform commission and lost chargebacks. Transfers
+// are made by finance outside the system and recorded here.
+type OwnerPayoutService struct {
+	repo           *database.OwnerPayoutRepository
+	disputeService *PaymentDisputeService
+	config         config.PayoutConfig
+	logger         *logrus.Logger
+	stopCh         chan struct{}
+}
+
+// NewOwnerPayoutService creates a new OwnerPayoutService
+func NewOwnerPayoutService(
+	repo *database.OwnerPayoutRepository,
+	disputeService *PaymentDisputeService,
+	cfg config.PayoutConfig,
+	logger *logrus.Logger,
+) *OwnerPayoutService {
+	if cfg.Cycle != string(models.PayoutCycleMonthly) {
+		cfg.Cycle = string(models.PayoutCycleWeekly)
+	}
+	if cfg.CommissionPercent < 0 || cfg.CommissionPercent > 100 {
+		cfg.CommissionPercent = 0
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = time.Hour
+	}
+	return &OwnerPayoutService{
+		repo:           repo,
+		disputeService: disputeService,
+		config:         cfg,
+		logger:         logger,
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// BANK ACCOUNTS
+// ============================================================================
+
+// GetBankAccount returns an owner's bank account; returns nil if none is on file
+func (s *OwnerPayoutService) GetBankAccount(ownerType models.PayoutOwnerType, ownerID uuid.UUID) (*models.OwnerBankAccount, error) {
+	return s.repo.GetBankAccount(ownerType, ownerID)
+}
+
+// SaveBankAccount sets an owner's bank account; it must be verified again before payouts use it
+func (s *OwnerPayoutService) SaveBankAccount(ownerType models.PayoutOwnerType, ownerID uuid.UUID, req *models.UpsertBankAccountRequest) (*models.OwnerBankAccount, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	account := &models.OwnerBankAccount{
+		OwnerType:         ownerType,
+		OwnerID:           ownerID,
+		AccountHolderName: req.AccountHolderName,
+		BankName:          req.BankName,
+		BankCode:          optionalString(req.BankCode),
+		BranchName:        optionalString(req.BranchName),
+		BranchCode:        optionalString(req.BranchCode),
+		AccountNumber:     req.AccountNumber,
+	}
+	if err := s.repo.UpsertBankAccount(account); err != nil {
+		return nil, err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"owner_type": ownerType,
+		"owner_id":   ownerID,
+	}).Info("Owner bank account submitted for verification")
+	return account, nil
+}
+
+// ListBankAccounts returns a page of bank accounts, e.g. those awaiting verification
+func (s *OwnerPayoutService) ListBankAccounts(status models.BankAccountStatus, limit, offset int) ([]models.OwnerBankAccount, int, error) {
+	return s.repo.ListBankAccounts(status, limit, offset)
+}
+
+// VerifyBankAccount records an admin's decision on a pending bank account. Approving it
+// releases the owner's payouts that were held for lack of a verified account.
+func (s *OwnerPayoutService) VerifyBankAccount(id uuid.UUID, req *models.VerifyBankAccountRequest, adminUserID uuid.UUID) (*models.OwnerBankAccount, error) {
+	status := models.BankAccountVerified
+	var reason *string
+	if !req.Approved {
+		status = models.BankAccountRejected
+		if reason = optionalString(req.Reason); reason == nil {
+			return nil, &models.ValidationError{Message: "reason is required when rejecting a bank account"}
+		}
+	}
+
+	account, err := s.repo.GetBankAccountByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrBankAccountNotFound
+	}
+
+	updated, err := s.repo.SetBankAccountStatus(id, status, reason, adminUserID)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrBankAccountNotPending
+	}
+
+	if status == models.BankAccountVerified {
+		released, err := s.repo.ReleaseHeldPayouts(account.OwnerType, account.OwnerID, account.ID)
+		if err != nil {
+			s.logger.WithError(err).WithField("bank_account_id", id).Error("Failed to release held payouts")
+		} else if released > 0 {
+			s.logger.WithFields(logrus.Fields{
+				"owner_id": account.OwnerID,
+				"count":    released,
+			}).Info("Released held payouts to verified bank account")
+		}
+	}
+
+	return s.repo.GetBankAccountByID(id)
+}
+
+// ============================================================================
+// BATCHES
+// ============================================================================
+
+// CurrentPeriod returns the last settlement cycle that has finished by at
+func (s *OwnerPayoutService) CurrentPeriod(at time.Time) (time.Time, time.Time) {
+	return models.PayoutCycle(s.config.Cycle).PeriodEnding(at)
+}
+
+// GenerateBatch creates the payouts for every owner who earned in [periodStart, periodEnd).
+// Lost chargebacks on a bus owner's bookings are deducted and marked settled; owners without
+// a verified bank account get their payout put on hold. createdBy is nil for scheduled runs.
+func (s *OwnerPayoutService) GenerateBatch(periodStart, periodEnd time.Time, createdBy *uuid.UUID) (*models.PayoutBatch, error) {
+	if !periodEnd.After(periodStart) {
+		return nil, &models.ValidationError{Message: "period_end must be after period_start"}
+	}
+	if periodEnd.After(time.Now()) {
+		return nil, ErrPayoutPeriodNotFinished
+	}
+
+	existing, err := s.repo.GetBatchByPeriod(periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrPayoutBatchExists
+	}
+
+	busEarnings, err := s.repo.GetBusOwnerEarnings(periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	loungeEarnings, err := s.repo.GetLoungeOwnerEarnings(periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &models.PayoutBatch{PeriodStart: periodStart, PeriodEnd: periodEnd, CreatedByUserID: createdBy}
+	payouts := []*models.OwnerPayout{}
+	settledDisputes := map[*models.OwnerPayout][]uuid.UUID{}
+
+	for _, earnings := range append(busEarnings, loungeEarnings...) {
+		payout := &models.OwnerPayout{
+			OwnerType:    earnings.OwnerType,
+			OwnerID:      earnings.OwnerID,
+			PeriodStart:  periodStart,
+			PeriodEnd:    periodEnd,
+			BookingCount: earnings.BookingCount,
+			GrossAmount:  earnings.GrossAmount,
+			Status:       models.PayoutPending,
+		}
+
+		// Chargebacks are only traced to bus owners
+		if earnings.OwnerType == models.PayoutOwnerBus && s.disputeService != nil {
+			summary, err := s.disputeService.SettlementSummary(earnings.OwnerID)
+			if err != nil {
+				return nil, err
+			}
+			payout.DisputeHeldAmount = summary.HeldAmount
+			payout.DisputeDeductedAmount = summary.DeductedAmount
+			settledDisputes[payout] = summary.DisputeIDs
+		}
+		payout.CommissionAmount, payout.NetAmount = models.CalculatePayout(
+			payout.GrossAmount, s.config.CommissionPercent, payout.DisputeDeductedAmount)
+
+		account, err := s.repo.GetBankAccount(earnings.OwnerType, earnings.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		if account != nil && account.Status == models.BankAccountVerified {
+			payout.BankAccountID = &account.ID
+		} else {
+			payout.Status = models.PayoutOnHold
+			payout.StatusReason = optionalString("No verified bank account on file")
+		}
+
+		payouts = append(payouts, payout)
+		batch.TotalNetAmount += payout.NetAmount
+	}
+	batch.PayoutCount = len(payouts)
+	batch.TotalNetAmount = models.RoundMoney(batch.TotalNetAmount)
+
+	if err := s.repo.CreateBatch(batch, payouts); err != nil {
+		return nil, err
+	}
+
+	for payout, disputeIDs := range settledDisputes {
+		if err := s.disputeService.MarkSettled(disputeIDs, payout.ID); err != nil {
+			s.logger.WithError(err).WithField("payout_id", payout.ID).Error("Failed to mark disputes settled")
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"batch_id":     batch.ID,
+		"period_start": periodStart,
+		"payouts":      batch.PayoutCount,
+		"total_net":    batch.TotalNetAmount,
+	}).Info("💰 Payout batch generated")
+	return batch, nil
+}
+
+// ListBatches returns a page of payout batches with the total count
+func (s *OwnerPayoutService) ListBatches(limit, offset int) ([]models.PayoutBatch, int, error) {
+	return s.repo.ListBatches(limit, offset)
+}
+
+// GetBatch returns a batch with its payouts and the bank details needed to transfer them
+func (s *OwnerPayoutService) GetBatch(id uuid.UUID) (*models.PayoutBatch, []models.OwnerPayoutTransfer, error) {
+	batch, err := s.repo.GetBatchByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if batch == nil {
+		return nil, nil, ErrPayoutBatchNotFound
+	}
+	transfers, err := s.repo.GetBatchTransfers(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return batch, transfers, nil
+}
+
+// ============================================================================
+// PAYOUTS
+// ============================================================================
+
+// ListOwnerPayouts returns a page of an owner's payout history
+func (s *OwnerPayoutService) ListOwnerPayouts(ownerType models.PayoutOwnerType, ownerID uuid.UUID, limit, offset int) ([]models.OwnerPayout, int, error) {
+	return s.repo.ListOwnerPayouts(ownerType, ownerID, limit, offset)
+}
+
+// UpdatePayoutStatus records transfer progress (processing, paid, failed) or holds/releases a payout
+func (s *OwnerPayoutService) UpdatePayoutStatus(id uuid.UUID, req *models.UpdatePayoutStatusRequest) (*models.OwnerPayout, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	payout, err := s.repo.GetPayoutByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if payout == nil {
+		return nil, ErrPayoutNotFound
+	}
+	if !payout.Status.CanTransitionTo(req.Status) {
+		return nil, ErrPayoutInvalidTransition
+	}
+	if req.Status == models.PayoutPending && payout.BankAccountID == nil {
+		return nil, &models.ValidationError{Message: "payout has no verified bank account to pay into"}
+	}
+
+	updated, err := s.repo.UpdatePayoutStatus(id, payout.Status, req.Status, optionalString(req.TransferReference), optionalString(req.Reason))
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrPayoutInvalidTransition // Changed concurrently
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payout_id": id,
+		"from":      payout.Status,
+		"to":        req.Status,
+	}).Info("Owner payout status updated")
+	return s.repo.GetPayoutByID(id)
+}
+
+// ============================================================================
+// BACKGROUND GENERATION
+// ============================================================================
+
+// Start begins the background job that generates each cycle's batch once the cycle ends
+func (s *OwnerPayoutService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Payout batch job disabled (PAYOUT_ENABLED=false)")
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"cycle":    s.config.Cycle,
+		"interval": s.config.CheckInterval.String(),
+	}).Info("💰 Starting Payout Batch job")
+	go s.run()
+}
+
+// Stop stops the background payout job
+func (s *OwnerPayoutService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Payout Batch job")
+	close(s.stopCh)
+}
+
+func (s *OwnerPayoutService) run() {
+	s.generateDue()
+
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.generateDue()
+		case <-s.stopCh:
+			s.logger.Info("Payout Batch job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single generation check (useful for testing or manual trigger)
+func (s *OwnerPayoutService) RunOnce() {
+	s.generateDue()
+}
+
+func (s *OwnerPayoutService) generateDue() {
+	periodStart, periodEnd := s.CurrentPeriod(time.Now())
+	existing, err := s.repo.GetBatchByPeriod(periodStart, periodEnd)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to check payout batch")
+		return
+	}
+	if existing != nil {
+		return
+	}
+	if _, err := s.GenerateBatch(periodStart, periodEnd, nil); err != nil && !errors.Is(err, ErrPayoutBatchExists) {
+		s.logger.WithError(err).WithField("period_start", periodStart).Error("Failed to generate payout batch")
+	}
+}
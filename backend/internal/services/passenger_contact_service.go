@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrPassengerContactNotFound     = errors.New("booking not found")
+	ErrPassengerContactDenied       = errors.New("only the trip's driver, conductor or bus owner can contact its passengers")
+	ErrPassengerContactDisabled     = errors.New("contacting passengers is disabled")
+	ErrPassengerContactLimitReached = errors.New("passenger contact limit reached for this trip")
+)
+
+// PassengerContactAttempt is a staff request to reach a masked passenger
+type PassengerContactAttempt struct {
+	UserID    uuid.UUID
+	Reason    string
+	IPAddress string
+	UserAgent string
+}
+
+// PassengerContactService limits the passenger PII conductors and drivers see. Booking views
+// show only the last digits of phone numbers; the trip's crew and bus owner can then reveal a
+// number or get the call relay for it, and every such request is audited.
+type PassengerContactService struct {
+	repo         *database.PassengerContactRepository
+	auditService *AuditService
+	config       config.StaffPrivacyConfig
+	logger       *logrus.Logger
+}
+
+// NewPassengerContactService creates a new PassengerContactService
+func NewPassengerContactService(
+	repo *database.PassengerContactRepository,
+	auditService *AuditService,
+	cfg config.StaffPrivacyConfig,
+	logger *logrus.Logger,
+) *PassengerContactService {
+	return &PassengerContactService{
+		repo:         repo,
+		auditService: auditService,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+// Policy describes how passenger contacts appear in staff booking views
+func (s *PassengerContactService) Policy() models.StaffContactPolicy {
+	policy := models.StaffContactPolicy{Masked: s.config.MaskPassengerContacts, Access: s.config.ContactAccess}
+	if !policy.Masked {
+		policy.Access = models.ContactAccessNone // Nothing to reveal
+	}
+	if policy.Access == models.ContactAccessRelay {
+		policy.RelayNumber = s.config.RelayNumber
+	}
+	return policy
+}
+
+// MaskBookings masks passenger contacts in bookings shown to staff, when masking is on
+func (s *PassengerContactService) MaskBookings(bookings []models.BusBooking) {
+	if !s.config.MaskPassengerContacts {
+		return
+	}
+	for i := range bookings {
+		models.MaskBusBookingContacts(&bookings[i], s.config.VisibleDigits)
+	}
+}
+
+// MaskManifest masks passenger phone numbers on a manifest printed by staff, when masking is on
+func (s *PassengerContactService) MaskManifest(manifest *models.TripManifest) {
+	if !s.config.MaskPassengerContacts {
+		return
+	}
+	for i := range manifest.Stops {
+		for j := range manifest.Stops[i].Passengers {
+			passenger := &manifest.Stops[i].Passengers[j]
+			if passenger.PassengerPhone != nil {
+				masked := models.MaskPhone(*passenger.PassengerPhone, s.config.VisibleDigits)
+				passenger.PassengerPhone = &masked
+			}
+		}
+	}
+}
+
+// RequestContact lets the trip's crew or bus owner reach a booked passenger. In reveal mode the
+// full number is returned; in relay mode only the relay number and booking reference are. The
+// request is refused if it cannot be audited.
+func (s *PassengerContactService) RequestContact(seatID string, attempt *PassengerContactAttempt) (*models.PassengerContact, error) {
+	policy := s.Policy()
+	if policy.Masked && policy.Access == models.ContactAccessNone {
+		return nil, ErrPassengerContactDisabled
+	}
+	seatUUID, err := uuid.Parse(seatID)
+	if err != nil {
+		return nil, ErrPassengerContactNotFound
+	}
+	target, err := s.repo.GetTarget(seatID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrPassengerContactNotFound
+	}
+	if !target.CanBeContactedBy(attempt.UserID.String()) {
+		return nil, ErrPassengerContactDenied
+	}
+
+	contact := &models.PassengerContact{
+		SeatID:        target.SeatID,
+		BusBookingID:  target.BusBookingID,
+		PassengerName: target.PassengerName,
+		Access:        policy.Access,
+		GrantedAt:     time.Now(),
+	}
+	if !policy.Masked {
+		// Staff already see full numbers; there is nothing to audit
+		contact.Access = models.ContactAccessReveal
+		contact.PassengerPhone = target.PassengerPhone
+		return contact, nil
+	}
+
+	if limit := s.config.MaxRevealsPerTrip; limit > 0 {
+		used, err := s.repo.CountRequests(attempt.UserID.String(), target.ScheduledTripID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= limit {
+			return nil, ErrPassengerContactLimitReached
+		}
+		left := limit - used - 1
+		contact.RequestsLeft = &left
+	}
+
+	action := "passenger_contact_" + policy.Access
+	details := map[string]interface{}{
+		"scheduled_trip_id": target.ScheduledTripID,
+		"bus_booking_id":    target.BusBookingID,
+		"reason":            strings.TrimSpace(attempt.Reason),
+	}
+	if s.auditService == nil {
+		return nil, fmt.Errorf("passenger contact audit is not configured")
+	}
+	if err := s.auditService.LogPassengerContactAccess(attempt.UserID, action, &seatUUID, attempt.IPAddress, attempt.UserAgent, details); err != nil {
+		return nil, fmt.Errorf("failed to audit passenger contact: %w", err)
+	}
+
+	switch policy.Access {
+	case models.ContactAccessReveal:
+		contact.PassengerPhone = target.PassengerPhone
+	case models.ContactAccessRelay:
+		contact.RelayNumber = policy.RelayNumber
+		contact.BookingReference = target.BookingReference
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":           attempt.UserID,
+		"seat_id":           target.SeatID,
+		"scheduled_trip_id": target.ScheduledTripID,
+		"access":            policy.Access,
+	}).Info("Passenger contact requested by staff")
+	return contact, nil
+}
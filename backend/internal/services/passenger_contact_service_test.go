@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPassengerContactService(cfg config.StaffPrivacyConfig) *PassengerContactService {
+	return NewPassengerContactService(nil, nil, cfg, logrus.New())
+}
+
+func TestPassengerContactService_Policy(t *testing.T) {
+	relay := newTestPassengerContactService(config.StaffPrivacyConfig{MaskPassengerContacts: true, ContactAccess: "relay", RelayNumber: "+94112000000"})
+	assert.Equal(t, models.StaffContactPolicy{Masked: true, Access: "relay", RelayNumber: "+94112000000"}, relay.Policy())
+
+	reveal := newTestPassengerContactService(config.StaffPrivacyConfig{MaskPassengerContacts: true, ContactAccess: "reveal", RelayNumber: "+94112000000"})
+	assert.Equal(t, models.StaffContactPolicy{Masked: true, Access: "reveal"}, reveal.Policy(), "relay number only in relay mode")
+
+	unmasked := newTestPassengerContactService(config.StaffPrivacyConfig{ContactAccess: "reveal"})
+	assert.Equal(t, models.StaffContactPolicy{Access: "none"}, unmasked.Policy())
+}
+
+func TestPassengerContactService_MaskBookings(t *testing.T) {
+	phone := "+94771234567"
+	bookings := func() []models.BusBooking {
+		p := phone
+		return []models.BusBooking{{Seats: []models.BusBookingSeat{{PassengerPhone: &p}}}}
+	}
+
+	masked := bookings()
+	newTestPassengerContactService(config.StaffPrivacyConfig{MaskPassengerContacts: true, VisibleDigits: 3}).MaskBookings(masked)
+	assert.Equal(t, "*********567", *masked[0].Seats[0].PassengerPhone)
+
+	unmasked := bookings()
+	newTestPassengerContactService(config.StaffPrivacyConfig{VisibleDigits: 3}).MaskBookings(unmasked)
+	assert.Equal(t, phone, *unmasked[0].Seats[0].PassengerPhone)
+}
+
+func TestPassengerContactService_RequestContactDisabled(t *testing.T) {
+	service := newTestPassengerContactService(config.StaffPrivacyConfig{MaskPassengerContacts: true, ContactAccess: "none"})
+
+	_, err := service.RequestContact(uuid.NewString(), &PassengerContactAttempt{UserID: uuid.New(), Reason: "Not at stop"})
+	assert.ErrorIs(t, err, ErrPassengerContactDisabled)
+}
@@ -5,16 +5,20 @@ import (
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
 )
 
+// ErrPaymentUnavailable is returned while the PAYable circuit breaker is open
+var ErrPaymentUnavailable = errors.New("payment gateway temporarily unavailable")
+
 // PAYableEnvironmentURLs maps environment names to their IPG endpoint URLs
 var PAYableEnvironmentURLs = map[string]string{
 	"dev":        "https://payable-ipg-dev.web.app/ipg/dev",
@@ -26,7 +30,7 @@ var PAYableEnvironmentURLs = map[string]string{
 type PAYableService struct {
 	config *config.PaymentConfig
 	logger *logrus.Logger
-	client *http.Client
+	client *httpclient.Client
 }
 
 // PAYablePaymentRequest represents the request sent to PAYable IPG
@@ -193,16 +197,40 @@ type PAYableWebhookPayload struct {
 	CardType        string `json:"cardType,omitempty"`
 	CardLastFour    string `json:"cardLastFour,omitempty"`
 	StatusIndicator string `json:"statusIndicator"`
+	CheckValue      string `json:"checkValue"` // Not verified; see ParseWebhook
 }
 
 // NewPAYableService creates a new PAYable payment service
 func NewPAYableService(cfg *config.PaymentConfig, logger *logrus.Logger) *PAYableService {
+	httpConfig := cfg.HTTP
+	if httpConfig.Name == "" {
+		httpConfig.Name = "payable"
+	}
+	httpConfig.OnStateChange = func(name string, from, to httpclient.State) {
+		logger.WithFields(logrus.Fields{
+			"gateway": name,
+			"from":    from,
+			"to":      to,
+		}).Warn("Payment gateway circuit breaker state changed")
+	}
+
 	return &PAYableService{
 		config: cfg,
 		logger: logger,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client: httpclient.New(httpConfig),
+	}
+}
+
+// WithMerchant returns a copy of the service that collects payments into another merchant account.
+// The copy shares the HTTP client, so the circuit breaker state is common to all accounts.
+func (s *PAYableService) WithMerchant(merchantKey, merchantToken string) *PAYableService {
+	cfg := *s.config
+	cfg.MerchantKey = merchantKey
+	cfg.MerchantToken = merchantToken
+	return &PAYableService{
+		config: &cfg,
+		logger: s.logger,
+		client: s.client,
 	}
 }
 
@@ -358,8 +386,19 @@ func (s *PAYableService) InitiatePayment(params *InitiatePaymentParams) (*PAYabl
 		"full_request":        string(jsonBody),
 	}).Info("PAYable full request payload")
 
-	resp, err := s.client.Post(endpointURL, "application/json", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequest(http.MethodPost, endpointURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Sent once: a retried initiation could create a second payment session
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrCircuitOpen) {
+			s.logger.WithField("invoice_id", params.InvoiceID).Warn("PAYable circuit open - payment initiation rejected")
+			return nil, ErrPaymentUnavailable
+		}
 		s.logger.WithError(err).Error("Failed to call PAYable endpoint")
 		return nil, fmt.Errorf("failed to call payment gateway: %w", err)
 	}
@@ -455,8 +494,18 @@ func (s *PAYableService) CheckStatusWithRawResponse(uid, statusIndicator string)
 		return nil, "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := s.client.Post(statusURL, "application/json", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequest(http.MethodPost, statusURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Status checks are read-only, so transient failures are retried
+	resp, err := s.client.DoIdempotent(req)
 	if err != nil {
+		if errors.Is(err, httpclient.ErrCircuitOpen) {
+			return nil, "", ErrPaymentUnavailable
+		}
 		return nil, "", fmt.Errorf("failed to check status: %w", err)
 	}
 	defer resp.Body.Close()
@@ -497,31 +546,140 @@ func (s *PAYableService) CheckStatusWithRawResponse(uid, statusIndicator string)
 	return &statusResp, rawBody, nil
 }
 
-// VerifyWebhook validates and parses a webhook payload from PAYable
-// Returns the parsed payload if valid, error otherwise
-func (s *PAYableService) VerifyWebhook(body []byte) (*PAYableWebhookPayload, error) {
-	var payload PAYableWebhookPayload
-	if err := json.Unmarshal(body, &payload); err != nil {
-		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+// PAYableRefundRequest asks PAYable to return part or all of a captured payment
+type PAYableRefundRequest struct {
+	MerchantKey     string `json:"merchantKey"`
+	UID             string `json:"uid"`       // The payment's PAYable UID
+	InvoiceID       string `json:"invoiceId"` // The payment's invoice (our payment reference)
+	RefundAmount    string `json:"refundAmount"`
+	CurrencyCode    string `json:"currencyCode"`
+	RefundReference string `json:"refundReference"` // Our refund ID; PAYable ignores repeats of the same reference
+	Reason          string `json:"reason,omitempty"`
+	CheckValue      string `json:"checkValue"`
+}
+
+// PAYableRefundResponse is PAYable's answer to a refund request
+type PAYableRefundResponse struct {
+	Status  int                `json:"status"` // HTTP-like status code (200 = accepted)
+	Data    *PAYableRefundData `json:"data"`
+	Message string             `json:"message,omitempty"`
+}
+
+// PAYableRefundData is the nested data object of a refund response
+type PAYableRefundData struct {
+	RefundID      string `json:"refundId"`
+	StatusMessage string `json:"statusMessage"` // "SUCCESS", "FAILED", etc.
+}
+
+// IsSuccessful reports whether PAYable returned the money
+func (r *PAYableRefundResponse) IsSuccessful() bool {
+	return r.Status == http.StatusOK && r.Data != nil && strings.ToUpper(r.Data.StatusMessage) == "SUCCESS"
+}
+
+// FailureMessage returns PAYable's reason for not refunding
+func (r *PAYableRefundResponse) FailureMessage() string {
+	if r.Data != nil && r.Data.StatusMessage != "" {
+		return r.Data.StatusMessage
+	}
+	if r.Message != "" {
+		return r.Message
+	}
+	return fmt.Sprintf("refund declined (status %d)", r.Status)
+}
+
+// RefundParams identifies the payment to refund and how much to return
+type RefundParams struct {
+	PaymentUID      string
+	InvoiceID       string
+	Amount          string // e.g. "1200.00"
+	CurrencyCode    string
+	RefundReference string
+	Reason          string
+}
+
+// Refund asks PAYable to return money from a captured payment. The request is sent once,
+// never retried, so a timeout leaves the outcome unknown. Returns the raw response body for
+// the payment audit trail.
+func (s *PAYableService) Refund(params *RefundParams) (*PAYableRefundResponse, string, error) {
+	request := &PAYableRefundRequest{
+		MerchantKey:     s.config.MerchantKey,
+		UID:             params.PaymentUID,
+		InvoiceID:       params.InvoiceID,
+		RefundAmount:    params.Amount,
+		CurrencyCode:    params.CurrencyCode,
+		RefundReference: params.RefundReference,
+		Reason:          params.Reason,
+		CheckValue:      s.GenerateCheckValue(params.InvoiceID, params.Amount, params.CurrencyCode),
+	}
+
+	endpointURL, ok := PAYableEnvironmentURLs[s.config.Environment]
+	if !ok {
+		endpointURL = PAYableEnvironmentURLs["sandbox"]
 	}
+	refundURL := endpointURL + "/refund"
+
+	s.logger.WithFields(logrus.Fields{
+		"uid":              params.PaymentUID,
+		"invoice_id":       params.InvoiceID,
+		"amount":           params.Amount,
+		"refund_reference": params.RefundReference,
+		"environment":      s.config.Environment,
+	}).Info("Requesting PAYable refund")
 
-	// Basic validation
-	if payload.UID == "" || payload.InvoiceID == "" {
-		return nil, fmt.Errorf("webhook missing required fields")
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Additional validation could include:
-	// 1. Verify the statusIndicator matches what we stored
-	// 2. Verify the amount matches our records
-	// 3. Check that the payment hasn't already been processed
+	req, err := http.NewRequest(http.MethodPost, refundURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrCircuitOpen) {
+			return nil, "", ErrPaymentUnavailable
+		}
+		return nil, "", fmt.Errorf("failed to request refund: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response: %w", err)
+	}
+	rawBody := string(body)
+
+	var refundResp PAYableRefundResponse
+	if err := json.Unmarshal(body, &refundResp); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"uid":           params.PaymentUID,
+			"http_status":   resp.StatusCode,
+			"response_body": rawBody,
+		}).Error("Failed to unmarshal PAYable refund response")
+		return nil, rawBody, fmt.Errorf("failed to parse response: %w", err)
+	}
 
 	s.logger.WithFields(logrus.Fields{
-		"uid":            payload.UID,
-		"invoice_id":     payload.InvoiceID,
-		"payment_status": payload.PaymentStatus,
-		"amount":         payload.Amount,
-	}).Info("Webhook payload verified")
+		"uid":         params.PaymentUID,
+		"http_status": resp.StatusCode,
+		"status":      refundResp.Status,
+		"successful":  refundResp.IsSuccessful(),
+	}).Info("PAYable refund response")
+
+	return &refundResp, rawBody, nil
+}
 
+// ParseWebhook parses a payment notification body. Notifications are not signature checked:
+// they are authenticated by re-querying the payment with CheckStatus, and only its answer is
+// acted on.
+func (s *PAYableService) ParseWebhook(body []byte) (*PAYableWebhookPayload, error) {
+	var payload PAYableWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
 	return &payload, nil
 }
 
@@ -547,6 +705,16 @@ func (s *PAYableService) IsConfigured() bool {
 	return s.config.MerchantKey != "" && s.config.MerchantToken != ""
 }
 
+// IsAvailable reports whether the PAYable circuit breaker currently lets requests through
+func (s *PAYableService) IsAvailable() bool {
+	return s.client.Available()
+}
+
+// HTTPStats returns metrics for the PAYable HTTP client
+func (s *PAYableService) HTTPStats() httpclient.Stats {
+	return s.client.Stats()
+}
+
 // GetEnvironment returns the current payment environment
 func (s *PAYableService) GetEnvironment() string {
 	return s.config.Environment
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrDisputeNotFound          = errors.New("dispute not found")
+	ErrDisputePaymentNotFound   = errors.New("no payment matches the given identifiers")
+	ErrDisputeResolved          = errors.New("dispute is already resolved")
+	ErrDisputeInvalidTransition = errors.New("dispute cannot move to that status")
+)
+
+// PaymentDisputeService records chargebacks entered by finance, links them to the booking and
+// bus owner behind the payment, tracks evidence and outcome, and reports how they adjust the
+// owner's payouts
+type PaymentDisputeService struct {
+	repo             *database.PaymentDisputeRepository
+	paymentAuditRepo *database.PaymentAuditRepository
+	snapshotRepo     *database.BookingSnapshotRepository
+	logger           *logrus.Logger
+}
+
+// NewPaymentDisputeService creates a new PaymentDisputeService
+func NewPaymentDisputeService(
+	repo *database.PaymentDisputeRepository,
+	paymentAuditRepo *database.PaymentAuditRepository,
+	snapshotRepo *database.BookingSnapshotRepository,
+	logger *logrus.Logger,
+) *PaymentDisputeService {
+	return &PaymentDisputeService{
+		repo:             repo,
+		paymentAuditRepo: paymentAuditRepo,
+		snapshotRepo:     snapshotRepo,
+		logger:           logger,
+	}
+}
+
+// CreateManual records a dispute entered by finance; the payment must be known
+func (s *PaymentDisputeService) CreateManual(req *models.CreatePaymentDisputeRequest, userID uuid.UUID) (*models.PaymentDispute, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	dispute := &models.PaymentDispute{
+		GatewayDisputeID: optionalString(req.GatewayDisputeID),
+		Amount:           req.Amount,
+		Currency:         req.Currency,
+		Reason:           optionalString(req.Reason),
+		Status:           models.PaymentDisputeOpen,
+		Source:           models.PaymentDisputeSourceManual,
+		RespondBy:        req.RespondBy,
+		CreatedByUserID:  &userID,
+	}
+	if dispute.Currency == "" {
+		dispute.Currency = "LKR"
+	}
+
+	if err := s.create(dispute, strings.TrimSpace(req.PaymentUID), strings.TrimSpace(req.PaymentReference), strings.TrimSpace(req.BookingReference)); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+func (s *PaymentDisputeService) create(dispute *models.PaymentDispute, paymentUID, paymentReference, bookingReference string) error {
+	link, err := s.repo.ResolvePaymentLink(paymentUID, paymentReference, bookingReference)
+	if err != nil {
+		return err
+	}
+	if link == nil {
+		return ErrDisputePaymentNotFound
+	}
+
+	dispute.PaymentUID = optionalString(paymentUID)
+	dispute.PaymentReference = optionalString(paymentReference)
+	dispute.BookingReference = optionalString(bookingReference)
+	if link != nil {
+		dispute.IntentID = link.IntentID
+		dispute.BookingID = link.BookingID
+		dispute.BusOwnerID = link.BusOwnerID
+		if link.PaymentUID != nil {
+			dispute.PaymentUID = link.PaymentUID
+		}
+		if link.PaymentReference != nil {
+			dispute.PaymentReference = link.PaymentReference
+		}
+		if link.BookingReference != nil {
+			dispute.BookingReference = link.BookingReference
+		}
+	} else {
+		s.logger.WithFields(logrus.Fields{
+			"payment_uid":       paymentUID,
+			"payment_reference": paymentReference,
+		}).Warn("Dispute received for an unknown payment - recorded unlinked")
+	}
+
+	if err := s.repo.Create(dispute); err != nil {
+		return err
+	}
+
+	s.logAudit(dispute, models.PaymentEventChargebackReceived)
+	s.logger.WithFields(logrus.Fields{
+		"dispute_id":        dispute.ID,
+		"booking_reference": dispute.BookingReference,
+		"amount":            dispute.Amount,
+		"source":            dispute.Source,
+	}).Warn("💳 Payment dispute opened")
+	return nil
+}
+
+// Get returns a dispute with its evidence and the disputed booking's snapshot
+func (s *PaymentDisputeService) Get(id uuid.UUID) (*models.PaymentDispute, error) {
+	dispute, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute == nil {
+		return nil, ErrDisputeNotFound
+	}
+	evidence, err := s.repo.GetEvidence(id)
+	if err != nil {
+		return nil, err
+	}
+	dispute.Evidence = evidence
+
+	if dispute.BookingID != nil && s.snapshotRepo != nil {
+		snapshot, err := s.snapshotRepo.Get(dispute.BookingID.String())
+		if err != nil {
+			return nil, err
+		}
+		dispute.BookingSnapshot = snapshot
+	}
+	return dispute, nil
+}
+
+// List returns a page of disputes with the total count
+func (s *PaymentDisputeService) List(filter models.PaymentDisputeFilter) ([]models.PaymentDispute, int, error) {
+	return s.repo.List(filter)
+}
+
+// UpdateStatus moves a dispute along its workflow (evidence submitted, won, lost)
+func (s *PaymentDisputeService) UpdateStatus(id uuid.UUID, req *models.UpdatePaymentDisputeStatusRequest) (*models.PaymentDispute, error) {
+	dispute, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute == nil {
+		return nil, ErrDisputeNotFound
+	}
+	if dispute.Status.IsResolved() {
+		return nil, ErrDisputeResolved
+	}
+	if !dispute.Status.CanTransitionTo(req.Status) {
+		return nil, ErrDisputeInvalidTransition
+	}
+	return s.transition(dispute, req.Status, optionalString(req.Note))
+}
+
+func (s *PaymentDisputeService) transition(dispute *models.PaymentDispute, status models.PaymentDisputeStatus, note *string) (*models.PaymentDispute, error) {
+	updated, err := s.repo.UpdateStatus(dispute.ID, dispute.Status, status, note)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrDisputeInvalidTransition // Changed concurrently
+	}
+
+	switch status {
+	case models.PaymentDisputeWon:
+		s.logAudit(dispute, models.PaymentEventChargebackWon)
+	case models.PaymentDisputeLost:
+		s.logAudit(dispute, models.PaymentEventChargebackLost)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"dispute_id": dispute.ID,
+		"from":       dispute.Status,
+		"to":         status,
+	}).Info("Payment dispute status updated")
+
+	return s.repo.GetByID(dispute.ID)
+}
+
+// AddEvidence attaches an uploaded document to an unresolved dispute
+func (s *PaymentDisputeService) AddEvidence(id uuid.UUID, req *models.AddPaymentDisputeEvidenceRequest, userID uuid.UUID) (*models.PaymentDisputeEvidence, error) {
+	dispute, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute == nil {
+		return nil, ErrDisputeNotFound
+	}
+	if dispute.Status.IsResolved() {
+		return nil, ErrDisputeResolved
+	}
+
+	evidence := &models.PaymentDisputeEvidence{
+		DisputeID:        id,
+		FileURL:          req.FileURL,
+		FileName:         optionalString(req.FileName),
+		Description:      optionalString(req.Description),
+		UploadedByUserID: &userID,
+	}
+	if err := s.repo.AddEvidence(evidence); err != nil {
+		return nil, err
+	}
+	return evidence, nil
+}
+
+// SettlementSummary returns how a bus owner's unsettled disputes adjust their next payout
+func (s *PaymentDisputeService) SettlementSummary(busOwnerID uuid.UUID) (*models.DisputeSettlementSummary, error) {
+	disputes, err := s.repo.GetUnsettledForOwner(busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	return models.SummarizeDisputeSettlement(busOwnerID, disputes), nil
+}
+
+// MarkSettled records that a payout deducted the given lost disputes
+func (s *PaymentDisputeService) MarkSettled(disputeIDs []uuid.UUID, payoutID uuid.UUID) error {
+	return s.repo.MarkSettled(disputeIDs, payoutID)
+}
+
+// logAudit adds the dispute event to the payment audit trail (best-effort)
+func (s *PaymentDisputeService) logAudit(dispute *models.PaymentDispute, eventType models.PaymentEventType) {
+	if s.paymentAuditRepo == nil {
+		return
+	}
+	audit := models.NewPaymentAudit(eventType, models.PaymentSourceBackend)
+	if dispute.IntentID != nil {
+		audit.SetIntent(*dispute.IntentID)
+	}
+	audit.PaymentUID = dispute.PaymentUID
+	audit.PaymentReference = dispute.PaymentReference
+	audit.ReceivedAmount = &dispute.Amount
+	audit.Currency = &dispute.Currency
+	audit.SetResponsePayload(map[string]interface{}{
+		"dispute_id":         dispute.ID.String(),
+		"gateway_dispute_id": dispute.GatewayDisputeID,
+	})
+
+	if err := s.paymentAuditRepo.Log(context.Background(), audit); err != nil {
+		s.logger.WithError(err).WithField("dispute_id", dispute.ID).Error("Failed to audit payment dispute event")
+	}
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
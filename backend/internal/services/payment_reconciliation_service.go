@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// reconcileAction is what reconciliation does with a stuck intent
+type reconcileAction string
+
+const (
+	reconcileWait    reconcileAction = "wait"    // Payment still open; check again next run
+	reconcileConfirm reconcileAction = "confirm" // Paid; confirm the booking
+	reconcileExpire  reconcileAction = "expire"  // Failed, cancelled or abandoned; release the holds
+)
+
+// PaymentReconciliationService checks PAYable's status API for intents whose payment webhook
+// never arrived, confirming the paid ones and expiring the rest. It runs alongside
+// IntentExpirationService, which only handles holds that never reached payment.
+type PaymentReconciliationService struct {
+	intentRepo     *database.BookingIntentRepository
+	tenantRepo     *database.TenantRepository
+	auditRepo      *database.PaymentAuditRepository
+	orchestrator   *BookingOrchestratorService
+	payableService *PAYableService
+	events         *BookingEventService
+	wallets        *WalletService
+	config         config.PaymentReconciliationConfig
+	logger         *logrus.Logger
+	stopCh         chan struct{}
+}
+
+// NewPaymentReconciliationService creates a new PaymentReconciliationService
+func NewPaymentReconciliationService(
+	intentRepo *database.BookingIntentRepository,
+	tenantRepo *database.TenantRepository,
+	auditRepo *database.PaymentAuditRepository,
+	orchestrator *BookingOrchestratorService,
+	payableService *PAYableService,
+	events *BookingEventService,
+	wallets *WalletService,
+	cfg config.PaymentReconciliationConfig,
+	logger *logrus.Logger,
+) *PaymentReconciliationService {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 2 * time.Minute
+	}
+	if cfg.StuckAfter <= 0 {
+		cfg.StuckAfter = 5 * time.Minute
+	}
+	if cfg.AbandonAfter <= 0 {
+		cfg.AbandonAfter = 30 * time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	return &PaymentReconciliationService{
+		intentRepo:     intentRepo,
+		tenantRepo:     tenantRepo,
+		auditRepo:      auditRepo,
+		orchestrator:   orchestrator,
+		payableService: payableService,
+		events:         events,
+		wallets:        wallets,
+		config:         cfg,
+		logger:         logger,
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// Start begins the background reconciliation job
+func (s *PaymentReconciliationService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Payment reconciliation disabled (PAYMENT_RECONCILIATION_ENABLED=false)")
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"interval":      s.config.CheckInterval.String(),
+		"stuck_after":   s.config.StuckAfter.String(),
+		"abandon_after": s.config.AbandonAfter.String(),
+	}).Info("💳 Starting Payment Reconciliation job")
+	go s.run()
+}
+
+// Stop stops the background reconciliation job
+func (s *PaymentReconciliationService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Payment Reconciliation job")
+	close(s.stopCh)
+}
+
+func (s *PaymentReconciliationService) run() {
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processStuckIntents()
+		case <-s.stopCh:
+			s.logger.Info("Payment Reconciliation job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single reconciliation cycle (useful for testing or manual trigger)
+func (s *PaymentReconciliationService) RunOnce() {
+	s.processStuckIntents()
+}
+
+func (s *PaymentReconciliationService) processStuckIntents() {
+	stuckBefore := time.Now().Add(-s.config.StuckAfter)
+	intents, err := s.intentRepo.GetStuckPaymentIntents(stuckBefore, s.config.BatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get stuck payment intents")
+		return
+	}
+	if len(intents) == 0 {
+		return
+	}
+
+	s.logger.WithField("count", len(intents)).Info("Reconciling stuck payment intents")
+	for _, intent := range intents {
+		if err := s.reconcile(intent, stuckBefore); err != nil {
+			s.logger.WithError(err).WithField("intent_id", intent.ID).Error("Failed to reconcile payment intent")
+		}
+	}
+}
+
+// reconcile settles one stuck intent from the gateway's view of its payment
+func (s *PaymentReconciliationService) reconcile(intent *models.StuckPaymentIntent, stuckBefore time.Time) error {
+	startTime := time.Now()
+
+	// A confirmation that never finished is retried from payment_pending
+	if intent.Status == models.IntentStatusConfirming {
+		requeued, err := s.intentRepo.RequeueStuckConfirmation(intent.ID, stuckBefore)
+		if err != nil {
+			return fmt.Errorf("failed to requeue stuck confirmation: %w", err)
+		}
+		if !requeued {
+			return nil // Finished or picked up since it was listed
+		}
+		s.logger.WithField("intent_id", intent.ID).Warn("Intent stuck in confirming - retrying confirmation")
+	}
+
+	// Paid in full from the wallet but never confirmed by the app
+	if intent.AmountDue <= 0 && intent.TotalAmount > 0 && intent.PaymentUID == nil {
+		return s.confirmWalletPaid(intent)
+	}
+
+	// Without a gateway payment there is nothing to check; the intent can only be abandoned
+	if intent.PaymentUID == nil || intent.PaymentStatusIndicator == nil || s.payableService == nil {
+		if reconcileActionFor("", intent.PaymentInitiatedAt, s.config.AbandonAfter, startTime) == reconcileExpire {
+			return s.expire(intent, "", "no gateway payment", startTime)
+		}
+		return nil
+	}
+	uid := *intent.PaymentUID
+
+	payable, err := s.payableFor(intent)
+	if err != nil {
+		return err
+	}
+	statusResp, rawBody, err := payable.CheckStatusWithRawResponse(uid, *intent.PaymentStatusIndicator)
+
+	statusAudit := models.NewPaymentAudit(models.PaymentEventStatusCheckResponse, models.PaymentSourceSystem)
+	statusAudit.SetIntent(intent.ID)
+	statusAudit.SetPaymentUID(uid)
+	if rawBody != "" {
+		statusAudit.SetRawBody(rawBody)
+	}
+	if err != nil {
+		statusAudit.SetError(err.Error(), nil)
+		s.logAudit(statusAudit, startTime)
+		return fmt.Errorf("failed to check payment status: %w", err)
+	}
+	paymentStatus := strings.ToUpper(statusResp.GetPaymentStatus())
+	statusAudit.SetPaymentStatus(statusResp.GetPaymentStatus())
+	s.logAudit(statusAudit, startTime)
+
+	switch reconcileActionFor(paymentStatus, intent.PaymentInitiatedAt, s.config.AbandonAfter, startTime) {
+	case reconcileConfirm:
+		return s.confirm(intent, statusResp, startTime)
+	case reconcileExpire:
+		return s.expire(intent, statusResp.GetPaymentStatus(), "payment "+strings.ToLower(paymentStatusOrUnpaid(paymentStatus)), startTime)
+	}
+	return nil
+}
+
+// reconcileActionFor decides what to do with an intent given the gateway's payment status.
+// Payments that are neither paid nor closed are left open until abandonAfter has passed since
+// payment started.
+func reconcileActionFor(paymentStatus string, paymentInitiatedAt *time.Time, abandonAfter time.Duration, now time.Time) reconcileAction {
+	switch strings.ToUpper(paymentStatus) {
+	case "SUCCESS":
+		return reconcileConfirm
+	case "FAILED", "CANCELLED":
+		return reconcileExpire
+	}
+	if paymentInitiatedAt == nil || now.Sub(*paymentInitiatedAt) >= abandonAfter {
+		return reconcileExpire
+	}
+	return reconcileWait
+}
+
+// confirmWalletPaid books an intent paid entirely from the passenger's wallet
+func (s *PaymentReconciliationService) confirmWalletPaid(intent *models.StuckPaymentIntent) error {
+	result, err := s.orchestrator.ConfirmPaidBooking(intent.ID, intent.UserID)
+	if errors.Is(err, ErrIntentConfirmationInProgress) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to confirm wallet-paid intent: %w", err)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"intent_id":         intent.ID,
+		"booking_reference": result.MasterReference,
+	}).Info("Wallet-paid booking confirmed by payment reconciliation")
+	return nil
+}
+
+// confirm books a paid intent, unless the amount paid through the gateway is not the amount due
+func (s *PaymentReconciliationService) confirm(intent *models.StuckPaymentIntent, statusResp *PAYableStatusResponse, startTime time.Time) error {
+	uid := *intent.PaymentUID
+	received, _ := models.ParseMoney(statusResp.GetAmount())
+	receivedAmount := received.Float64()
+
+	successAudit := models.NewPaymentAudit(models.PaymentEventSuccess, models.PaymentSourceSystem)
+	successAudit.SetIntent(intent.ID)
+	successAudit.SetPaymentUID(uid)
+	successAudit.SetPaymentReference(statusResp.GetInvoiceID())
+	successAudit.SetPaymentStatus(statusResp.GetPaymentStatus())
+	successAudit.SetIdempotencyKey(fmt.Sprintf("%s-success", uid))
+	if txnID := statusResp.GetTransactionID(); txnID != "" {
+		successAudit.GatewayTransactionID = &txnID
+	}
+
+	if !successAudit.SetAmounts(intent.AmountDue, receivedAmount, intent.Currency) {
+		// Never book on a mismatched amount; the intent is parked for manual review
+		successAudit.EventType = models.PaymentEventReconciliationMismatch
+		successAudit.SetError(fmt.Sprintf("amount mismatch: expected %.2f, received %.2f", intent.AmountDue, receivedAmount), nil)
+		s.logAudit(successAudit, startTime)
+		s.logger.WithFields(logrus.Fields{
+			"intent_id":       intent.ID,
+			"uid":             uid,
+			"expected_amount": intent.AmountDue,
+			"received_amount": receivedAmount,
+		}).Error("CRITICAL: Amount mismatch found by payment reconciliation - BLOCKING confirmation")
+		if err := s.intentRepo.UpdateIntentConfirmationFailed(intent.ID); err != nil {
+			return fmt.Errorf("failed to park mismatched intent: %w", err)
+		}
+		s.wallets.ReleaseIntent(intent.ID, intent.UserID)
+		return nil
+	}
+	s.logAudit(successAudit, startTime)
+
+	result, err := s.orchestrator.ConfirmPaidBooking(intent.ID, intent.UserID)
+	if errors.Is(err, ErrIntentConfirmationInProgress) {
+		return nil // The webhook or the app got there first
+	}
+	if err != nil {
+		failAudit := models.NewPaymentAudit(models.PaymentEventBookingConfirmFailed, models.PaymentSourceSystem)
+		failAudit.SetIntent(intent.ID)
+		failAudit.SetPaymentUID(uid)
+		failAudit.SetError(err.Error(), nil)
+		failAudit.SetAmounts(intent.AmountDue, receivedAmount, intent.Currency)
+		s.logAudit(failAudit, startTime)
+		return fmt.Errorf("failed to confirm paid intent: %w", err)
+	}
+
+	confirmAudit := models.NewPaymentAudit(models.PaymentEventBookingConfirmed, models.PaymentSourceSystem)
+	confirmAudit.SetIntent(intent.ID)
+	confirmAudit.SetPaymentUID(uid)
+	confirmAudit.SetPaymentStatus("confirmed")
+	confirmAudit.SetAmounts(intent.AmountDue, receivedAmount, intent.Currency)
+	s.logAudit(confirmAudit, startTime)
+
+	s.logger.WithFields(logrus.Fields{
+		"intent_id":         intent.ID,
+		"uid":               uid,
+		"booking_reference": result.MasterReference,
+	}).Info("Booking confirmed by payment reconciliation - webhook never arrived")
+	return nil
+}
+
+// expire releases the holds of an intent whose payment failed or was abandoned
+func (s *PaymentReconciliationService) expire(intent *models.StuckPaymentIntent, gatewayStatus, reason string, startTime time.Time) error {
+	full, err := s.intentRepo.GetIntentByID(intent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get intent: %w", err)
+	}
+	if full == nil || full.Status != models.IntentStatusPaymentPending {
+		return nil // Settled since it was listed
+	}
+	if err := s.intentRepo.ExpireIntentAndReleaseHolds(intent.ID); err != nil {
+		return fmt.Errorf("failed to expire intent: %w", err)
+	}
+	s.events.IntentExpired(full)
+	s.wallets.ReleaseIntent(intent.ID, intent.UserID)
+
+	eventType := models.PaymentEventFailed
+	if strings.EqualFold(gatewayStatus, "CANCELLED") {
+		eventType = models.PaymentEventCancelled
+	}
+	audit := models.NewPaymentAudit(eventType, models.PaymentSourceSystem)
+	audit.SetIntent(intent.ID)
+	if intent.PaymentUID != nil {
+		audit.SetPaymentUID(*intent.PaymentUID)
+	}
+	if gatewayStatus != "" {
+		audit.SetPaymentStatus(gatewayStatus)
+	}
+	audit.SetError("intent expired by payment reconciliation: "+reason, nil)
+	s.logAudit(audit, startTime)
+
+	s.logger.WithFields(logrus.Fields{
+		"intent_id": intent.ID,
+		"reason":    reason,
+	}).Info("Intent expired by payment reconciliation")
+	return nil
+}
+
+// payableFor returns the gateway client for the merchant account that took the payment
+func (s *PaymentReconciliationService) payableFor(intent *models.StuckPaymentIntent) (*PAYableService, error) {
+	if intent.TenantID == nil || s.tenantRepo == nil {
+		return s.payableService, nil
+	}
+	tenant, err := s.tenantRepo.GetByID(*intent.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant != nil && tenant.HasPaymentAccount() {
+		return s.payableService.WithMerchant(*tenant.PaymentMerchantKey, *tenant.PaymentMerchantToken), nil
+	}
+	return s.payableService, nil
+}
+
+func (s *PaymentReconciliationService) logAudit(audit *models.PaymentAudit, startTime time.Time) {
+	if s.auditRepo == nil {
+		return
+	}
+	audit.SetProcessingTime(startTime)
+	if err := s.auditRepo.Log(context.Background(), audit); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"event_type":  audit.EventType,
+			"payment_uid": audit.PaymentUID,
+		}).Error("CRITICAL: Failed to log payment audit")
+	}
+}
+
+// paymentStatusOrUnpaid names an open payment for log messages
+func paymentStatusOrUnpaid(paymentStatus string) string {
+	if paymentStatus == "" {
+		return "UNPAID"
+	}
+	return paymentStatus
+}
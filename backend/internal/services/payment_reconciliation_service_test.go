@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileActionFor(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	recent := now.Add(-10 * time.Minute)
+	old := now.Add(-45 * time.Minute)
+	abandonAfter := 30 * time.Minute
+
+	assert.Equal(t, reconcileConfirm, reconcileActionFor("SUCCESS", &old, abandonAfter, now), "paid intents are confirmed however old")
+	assert.Equal(t, reconcileConfirm, reconcileActionFor("success", &recent, abandonAfter, now))
+	assert.Equal(t, reconcileExpire, reconcileActionFor("FAILED", &recent, abandonAfter, now))
+	assert.Equal(t, reconcileExpire, reconcileActionFor("CANCELLED", &recent, abandonAfter, now))
+
+	assert.Equal(t, reconcileWait, reconcileActionFor("PENDING", &recent, abandonAfter, now), "passenger may still be paying")
+	assert.Equal(t, reconcileWait, reconcileActionFor("", &recent, abandonAfter, now))
+	assert.Equal(t, reconcileExpire, reconcileActionFor("PENDING", &old, abandonAfter, now), "abandoned")
+	assert.Equal(t, reconcileExpire, reconcileActionFor("", nil, abandonAfter, now))
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrPromoCodeNotFound = errors.New("promo code not found")
+	ErrPromoCodeExists   = errors.New("a promo code with this code already exists")
+	ErrPromoCodeRedeemed = errors.New("promo code has been redeemed; deactivate it instead")
+)
+
+// PromoCodeService manages the platform's promo codes and works out their discounts on bus and
+// lounge bookings. Codes are checked when a booking is priced, a use is reserved when the intent
+// or lounge booking is made, and the reserved use is completed when the booking is confirmed.
+type PromoCodeService struct {
+	repo   *database.PromoCodeRepository
+	logger *logrus.Logger
+}
+
+// NewPromoCodeService creates a new PromoCodeService
+func NewPromoCodeService(repo *database.PromoCodeRepository, logger *logrus.Logger) *PromoCodeService {
+	return &PromoCodeService{repo: repo, logger: logger}
+}
+
+// ============================================================================
+// ADMIN
+// ============================================================================
+
+// ListCodes returns a page of promo codes, optionally only active ones
+func (s *PromoCodeService) ListCodes(activeOnly bool, limit, offset int) ([]models.PromoCode, int, error) {
+	return s.repo.List(activeOnly, limit, offset)
+}
+
+// GetCode returns a promo code
+func (s *PromoCodeService) GetCode(id string) (*models.PromoCode, error) {
+	promo, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if promo == nil {
+		return nil, ErrPromoCodeNotFound
+	}
+	return promo, nil
+}
+
+// CreateCode adds a promo code
+func (s *PromoCodeService) CreateCode(adminUserID string, req *models.SavePromoCodeRequest) (*models.PromoCode, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkCodeFree(req.Code, ""); err != nil {
+		return nil, err
+	}
+
+	promo := &models.PromoCode{CreatedByUserID: &adminUserID}
+	req.ApplyTo(promo)
+	if err := s.repo.Create(promo); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"promo_code_id":  promo.ID,
+		"code":           promo.Code,
+		"discount_type":  promo.DiscountType,
+		"discount_value": promo.DiscountValue,
+		"admin_user_id":  adminUserID,
+	}).Info("Promo code created")
+	return promo, nil
+}
+
+// UpdateCode replaces a promo code's terms; redemptions so far still count against its limits
+func (s *PromoCodeService) UpdateCode(id string, req *models.SavePromoCodeRequest) (*models.PromoCode, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	promo, err := s.GetCode(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCodeFree(req.Code, promo.ID); err != nil {
+		return nil, err
+	}
+
+	req.ApplyTo(promo)
+	if err := s.repo.Update(promo); err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// DeleteCode removes a promo code that has never been redeemed
+func (s *PromoCodeService) DeleteCode(id string) error {
+	promo, err := s.GetCode(id)
+	if err != nil {
+		return err
+	}
+	redemptions, err := s.repo.CountRedemptions(promo.ID)
+	if err != nil {
+		return err
+	}
+	if redemptions > 0 {
+		return ErrPromoCodeRedeemed
+	}
+	return s.repo.Delete(promo.ID)
+}
+
+func (s *PromoCodeService) checkCodeFree(code, excludeID string) error {
+	exists, err := s.repo.CodeExists(code, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrPromoCodeExists
+	}
+	return nil
+}
+
+// ============================================================================
+// BOOKINGS
+// ============================================================================
+
+// Quote checks a code can be used by the user now and works out its discount on the lines
+func (s *PromoCodeService) Quote(userID, code string, lines []models.PromoLine) (*models.PromoQuote, error) {
+	promo, err := s.repo.GetByCode(models.NormalizePromoCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if promo == nil {
+		return nil, ErrPromoCodeNotFound
+	}
+	userUses, err := s.repo.CountUserRedemptions(promo.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := promo.CheckUsable(time.Now(), userUses); err != nil {
+		return nil, err
+	}
+	return promo.Quote(lines)
+}
+
+// Reserve takes one of a quoted code's uses for a booking being made, enforcing the code's
+// limits as it does. The reserved use is given back if the intent expires or is cancelled, or
+// by Release.
+func (s *PromoCodeService) Reserve(redemption *models.PromoCodeRedemption) error {
+	err := s.repo.Reserve(redemption)
+	switch {
+	case errors.Is(err, database.ErrPromoCodeUsedUp):
+		return &models.ValidationError{Message: "promo code has reached its usage limit"}
+	case errors.Is(err, database.ErrPromoCodeUserLimitReached):
+		return &models.ValidationError{Message: "you have already used this promo code"}
+	}
+	return err
+}
+
+// Release gives back a reserved use whose booking could not be made; failures are logged
+func (s *PromoCodeService) Release(redemption *models.PromoCodeRedemption) {
+	if s == nil || redemption == nil || redemption.ID == "" {
+		return
+	}
+	if err := s.repo.Release(redemption.ID); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"promo_code_id": redemption.PromoCodeID,
+			"redemption_id": redemption.ID,
+		}).Error("Failed to release promo code use")
+	}
+}
+
+// Redeem adds a confirmed booking to the use of a code reserved for it. The discount has already
+// been charged, so this never fails the booking: a use that was never reserved (an intent made
+// before uses were reserved) is recorded even if the limit was reached in the meantime, and
+// failures are logged.
+func (s *PromoCodeService) Redeem(redemption *models.PromoCodeRedemption) {
+	if s == nil || redemption == nil {
+		return
+	}
+	attached, err := s.repo.AttachBookings(redemption)
+	if err == nil && !attached {
+		err = s.repo.Redeem(redemption)
+	}
+	if err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"promo_code_id": redemption.PromoCodeID,
+			"user_id":       redemption.UserID,
+			"intent_id":     redemption.IntentID,
+		}).Error("Failed to record promo code redemption")
+	}
+}
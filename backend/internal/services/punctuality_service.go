@@ -0,0 +1,100 @@
+package services
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// PunctualityService runs the nightly job that aggregates each schedule's on-time performance
+// from completed trips. Search results and operator pages read the stored aggregates.
+type PunctualityService struct {
+	repo   *database.PunctualityRepository
+	config config.PunctualityConfig
+	logger *logrus.Logger
+	stopCh chan struct{}
+}
+
+// NewPunctualityService creates a new PunctualityService
+func NewPunctualityService(repo *database.PunctualityRepository, cfg config.PunctualityConfig, logger *logrus.Logger) *PunctualityService {
+	if cfg.RunHour < 0 || cfg.RunHour > 23 {
+		cfg.RunHour = 2
+	}
+	if cfg.WindowDays <= 0 {
+		cfg.WindowDays = 90
+	}
+	if cfg.DepartureGraceMinutes < 0 {
+		cfg.DepartureGraceMinutes = 5
+	}
+	if cfg.ArrivalGraceMinutes < 0 {
+		cfg.ArrivalGraceMinutes = 10
+	}
+	if cfg.MinTrips <= 0 {
+		cfg.MinTrips = 5
+	}
+	return &PunctualityService{
+		repo:   repo,
+		config: cfg,
+		logger: logger,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins the nightly aggregation job
+func (s *PunctualityService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Punctuality job disabled (PUNCTUALITY_ENABLED=false)")
+		return
+	}
+	s.logger.WithField("run_hour", s.config.RunHour).Info("⏱️ Starting Punctuality job")
+	go s.run()
+}
+
+// Stop stops the nightly aggregation job
+func (s *PunctualityService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Punctuality job")
+	close(s.stopCh)
+}
+
+func (s *PunctualityService) run() {
+	for {
+		timer := time.NewTimer(time.Until(models.NextNightlyRun(time.Now(), s.config.RunHour)))
+		select {
+		case <-timer.C:
+			s.refresh()
+		case <-s.stopCh:
+			timer.Stop()
+			s.logger.Info("Punctuality job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single aggregation (useful for testing or manual trigger)
+func (s *PunctualityService) RunOnce() {
+	s.refresh()
+}
+
+func (s *PunctualityService) refresh() {
+	started := time.Now()
+	refreshed, err := s.repo.RefreshSchedulePunctuality(models.PunctualityRules{
+		WindowDays:            s.config.WindowDays,
+		DepartureGraceMinutes: s.config.DepartureGraceMinutes,
+		ArrivalGraceMinutes:   s.config.ArrivalGraceMinutes,
+		MinTrips:              s.config.MinTrips,
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to refresh schedule punctuality")
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"schedules": refreshed,
+		"duration":  time.Since(started).String(),
+	}).Info("Schedule punctuality refreshed")
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/push"
+)
+
+// PushNotificationService delivers push notifications to every active device of a user,
+// using the FCM tokens the apps register on their sessions. Users who turned push off in their
+// preferences are skipped.
+type PushNotificationService struct {
+	sender      push.Sender
+	sessionRepo *database.UserSessionRepository
+	preferences *UserPreferencesService
+	logger      *logrus.Logger
+}
+
+// NewPushNotificationService creates a new push notification service
+func NewPushNotificationService(sender push.Sender, sessionRepo *database.UserSessionRepository, preferences *UserPreferencesService, logger *logrus.Logger) *PushNotificationService {
+	return &PushNotificationService{
+		sender:      sender,
+		sessionRepo: sessionRepo,
+		preferences: preferences,
+		logger:      logger,
+	}
+}
+
+// NotifyUser sends a notification to all of a user's devices and returns how many accepted it.
+// Tokens the provider reports as unregistered are removed.
+func (s *PushNotificationService) NotifyUser(userID uuid.UUID, msg push.Message) int {
+	if s.preferences != nil && !s.preferences.AllowsChannel(userID, models.NotificationChannelPush) {
+		return 0
+	}
+	tokens, err := s.sessionRepo.GetActivePushTokens(userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to load push tokens")
+		return 0
+	}
+
+	delivered := 0
+	for _, token := range tokens {
+		err := s.sender.Send(token, msg)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, push.ErrUnregistered):
+			if clearErr := s.sessionRepo.ClearFCMToken(token); clearErr != nil {
+				s.logger.WithError(clearErr).Warn("Failed to clear unregistered push token")
+			}
+		default:
+			s.logger.WithError(err).WithField("user_id", userID).Warn("Failed to send push notification")
+		}
+	}
+	return delivered
+}
+
+// NotifyUsersAsync sends a notification to several users in the background
+func (s *PushNotificationService) NotifyUsersAsync(userIDs []string, msg push.Message) {
+	go func() {
+		for _, id := range userIDs {
+			userID, err := uuid.Parse(id)
+			if err != nil {
+				continue
+			}
+			s.NotifyUser(userID, msg)
+		}
+	}()
+}
@@ -5,18 +5,30 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/smarttransit/sms-auth-backend/internal/config"
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 )
 
-// RateLimitService handles OTP request rate limiting
+// OTPRateLimiter limits how often OTPs are sent to a phone number and from an IP address.
+// CheckOTPRateLimit is called before an OTP is sent and RecordOTPRequest once it has been.
+type OTPRateLimiter interface {
+	CheckOTPRateLimit(phone, ip string) error
+	RecordOTPRequest(phone, ip string) error
+	GetRateLimitStatus(identifier, identifierType string) (int, time.Time, error)
+	IsRateLimited(identifier, identifierType string) (bool, time.Time, error)
+}
+
+// RateLimitService handles OTP request rate limiting, counting requests in the database
 type RateLimitService struct {
-	db database.DB
+	db     database.DB
+	config RateLimitConfig
 }
 
 // NewRateLimitService creates a new rate limit service
-func NewRateLimitService(db database.DB) *RateLimitService {
+func NewRateLimitService(db database.DB, cfg RateLimitConfig) *RateLimitService {
 	return &RateLimitService{
-		db: db,
+		db:     db,
+		config: cfg,
 	}
 }
 
@@ -38,6 +50,16 @@ func DefaultRateLimitConfig() RateLimitConfig {
 	}
 }
 
+// NewRateLimitConfig builds the rate limit configuration from the OTP settings
+func NewRateLimitConfig(cfg config.OTPConfig) RateLimitConfig {
+	return RateLimitConfig{
+		MaxPhoneRequests: cfg.RateLimit,
+		PhoneWindow:      time.Duration(cfg.RateWindowMinutes) * time.Minute,
+		MaxIPRequests:    cfg.IPRateLimit,
+		IPWindow:         time.Duration(cfg.IPRateWindowMinutes) * time.Minute,
+	}
+}
+
 // RateLimitError represents a rate limit exceeded error
 type RateLimitError struct {
 	Message    string
@@ -51,7 +73,7 @@ func (e *RateLimitError) Error() string {
 
 // CheckOTPRateLimit checks if a phone number or IP has exceeded rate limits
 func (s *RateLimitService) CheckOTPRateLimit(phone, ip string) error {
-	config := DefaultRateLimitConfig()
+	config := s.config
 
 	// Check phone-based rate limit
 	if phone != "" {
@@ -147,7 +169,7 @@ func (s *RateLimitService) recordRequest(identifier, identifierType string) erro
 
 // CleanupExpiredRateLimits removes old rate limit records
 func (s *RateLimitService) CleanupExpiredRateLimits() (int64, error) {
-	config := DefaultRateLimitConfig()
+	config := s.config
 
 	// Delete records older than the longest window (IP window is 1 hour)
 	maxWindow := config.IPWindow
@@ -177,7 +199,7 @@ func (s *RateLimitService) CleanupExpiredRateLimits() (int64, error) {
 
 // GetRateLimitStatus returns the current rate limit status for a phone or IP
 func (s *RateLimitService) GetRateLimitStatus(identifier, identifierType string) (int, time.Time, error) {
-	config := DefaultRateLimitConfig()
+	config := s.config
 
 	window := config.PhoneWindow
 	if identifierType == "ip" {
@@ -194,7 +216,7 @@ func (s *RateLimitService) GetRateLimitStatus(identifier, identifierType string)
 
 // IsRateLimited checks if an identifier is currently rate limited
 func (s *RateLimitService) IsRateLimited(identifier, identifierType string) (bool, time.Time, error) {
-	config := DefaultRateLimitConfig()
+	config := s.config
 
 	window := config.PhoneWindow
 	maxRequests := config.MaxPhoneRequests
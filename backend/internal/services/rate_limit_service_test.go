@@ -18,7 +18,7 @@ func setupRateLimitTest(t *testing.T) (*RateLimitService, sqlmock.Sqlmock, func(
 
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	postgresDB := &database.PostgresDB{DB: sqlxDB}
-	service := NewRateLimitService(postgresDB)
+	service := NewRateLimitService(postgresDB, DefaultRateLimitConfig())
 
 	cleanup := func() {
 		db.Close()
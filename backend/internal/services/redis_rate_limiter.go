@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisRateLimitTimeout bounds each call to Redis, so a slow server fails the check rather
+// than holding up send-otp
+const redisRateLimitTimeout = 2 * time.Second
+
+// admitOTPRequestScript checks each sliding window (a sorted set of request timestamps) and,
+// only if every window has room, adds the request to all of them. Redis runs the script
+// atomically and on its own clock, so instances racing on the same phone or IP see one count.
+// Returns {0, 0} when admitted, or {n, retryAfterMs} for the n-th key that is full.
+var admitOTPRequestScript = redis.NewScript(`
+local now = redis.call('TIME')
+local nowMs = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 2])
+	local window = tonumber(ARGV[i * 2 + 1])
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', nowMs - window)
+	local count = redis.call('ZCARD', key)
+	if count >= limit then
+		local freeing = redis.call('ZRANGE', key, count - limit, count - limit, 'WITHSCORES')
+		return {i, tonumber(freeing[2]) + window}
+	end
+end
+for i, key in ipairs(KEYS) do
+	redis.call('ZADD', key, nowMs, ARGV[1])
+	redis.call('PEXPIRE', key, tonumber(ARGV[i * 2 + 1]))
+end
+return {0, 0}
+`)
+
+// RedisRateLimiter limits OTP requests with sliding windows kept in Redis, so every instance
+// shares the same counts. Unlike RateLimitService, a request is counted when it is admitted by
+// CheckOTPRateLimit rather than on RecordOTPRequest; otherwise requests checked at the same
+// time on different instances could all pass before any was recorded.
+type RedisRateLimiter struct {
+	client    *redis.Client
+	keyPrefix string
+	config    RateLimitConfig
+}
+
+// NewRedisRateLimiter creates a new Redis-backed rate limiter
+func NewRedisRateLimiter(client *redis.Client, keyPrefix string, cfg RateLimitConfig) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client:    client,
+		keyPrefix: keyPrefix,
+		config:    cfg,
+	}
+}
+
+// CheckOTPRateLimit admits the request if neither the phone nor the IP has reached its limit,
+// counting it against both
+func (l *RedisRateLimiter) CheckOTPRateLimit(phone, ip string) error {
+	var keys, types []string
+	args := []interface{}{strconv.FormatInt(time.Now().UnixNano(), 10) + ":" + uuid.NewString()}
+	if phone != "" {
+		keys = append(keys, l.key(phone, "phone"))
+		types = append(types, "phone")
+		args = append(args, l.config.MaxPhoneRequests, l.config.PhoneWindow.Milliseconds())
+	}
+	if ip != "" {
+		keys = append(keys, l.key(ip, "ip"))
+		types = append(types, "ip")
+		args = append(args, l.config.MaxIPRequests, l.config.IPWindow.Milliseconds())
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+
+	result, err := admitOTPRequestScript.Run(ctx, l.client, keys, args...).Int64Slice()
+	if err != nil {
+		return fmt.Errorf("failed to check OTP rate limit: %w", err)
+	}
+	if len(result) != 2 || result[0] == 0 {
+		return nil
+	}
+
+	retryAfter := time.UnixMilli(result[1])
+	if types[result[0]-1] == "phone" {
+		return &RateLimitError{
+			Message:    fmt.Sprintf("Too many OTP requests for this phone number. Please try again after %s", retryAfter.Format("15:04:05")),
+			RetryAfter: retryAfter,
+			Type:       "phone",
+		}
+	}
+	return &RateLimitError{
+		Message:    fmt.Sprintf("Too many OTP requests from this IP address. Please try again after %s", retryAfter.Format("15:04:05")),
+		RetryAfter: retryAfter,
+		Type:       "ip",
+	}
+}
+
+// RecordOTPRequest does nothing; the request was counted when CheckOTPRateLimit admitted it
+func (l *RedisRateLimiter) RecordOTPRequest(phone, ip string) error {
+	return nil
+}
+
+// GetRateLimitStatus returns the requests in the current window for a phone or IP, and when
+// the latest was made
+func (l *RedisRateLimiter) GetRateLimitStatus(identifier, identifierType string) (int, time.Time, error) {
+	requests, err := l.window(identifier, identifierType)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if len(requests) == 0 {
+		return 0, time.Now(), nil
+	}
+	return len(requests), time.UnixMilli(int64(requests[len(requests)-1].Score)), nil
+}
+
+// IsRateLimited checks if an identifier is currently rate limited, and until when
+func (l *RedisRateLimiter) IsRateLimited(identifier, identifierType string) (bool, time.Time, error) {
+	maxRequests, window := l.limit(identifierType)
+	requests, err := l.window(identifier, identifierType)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	if len(requests) < maxRequests {
+		return false, time.Time{}, nil
+	}
+	// Room is made once enough of the oldest requests leave the window
+	freeing := requests[len(requests)-maxRequests]
+	return true, time.UnixMilli(int64(freeing.Score)).Add(window), nil
+}
+
+// window returns the requests within the identifier's current window, oldest first
+func (l *RedisRateLimiter) window(identifier, identifierType string) ([]redis.Z, error) {
+	_, window := l.limit(identifierType)
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+
+	since := time.Now().Add(-window).UnixMilli()
+	requests, err := l.client.ZRangeByScoreWithScores(ctx, l.key(identifier, identifierType), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(since, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OTP rate limit: %w", err)
+	}
+	return requests, nil
+}
+
+func (l *RedisRateLimiter) limit(identifierType string) (int, time.Duration) {
+	if identifierType == "ip" {
+		return l.config.MaxIPRequests, l.config.IPWindow
+	}
+	return l.config.MaxPhoneRequests, l.config.PhoneWindow
+}
+
+func (l *RedisRateLimiter) key(identifier, identifierType string) string {
+	return l.keyPrefix + "otp_rate:" + identifierType + ":" + identifier
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRedisRateLimiter(t *testing.T) (*RedisRateLimiter, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := RateLimitConfig{
+		MaxPhoneRequests: 2,
+		PhoneWindow:      10 * time.Minute,
+		MaxIPRequests:    3,
+		IPWindow:         time.Hour,
+	}
+	return NewRedisRateLimiter(client, "test:", cfg), server
+}
+
+func TestRedisRateLimiter_PhoneLimit(t *testing.T) {
+	limiter, server := setupRedisRateLimiter(t)
+	start := time.Now()
+	server.SetTime(start)
+
+	require.NoError(t, limiter.CheckOTPRateLimit("0771234567", "10.0.0.1"))
+	server.SetTime(start.Add(time.Minute))
+	require.NoError(t, limiter.CheckOTPRateLimit("0771234567", "10.0.0.1"))
+
+	err := limiter.CheckOTPRateLimit("0771234567", "10.0.0.1")
+	rateLimitErr, ok := err.(*RateLimitError)
+	require.True(t, ok, "Error should be RateLimitError")
+	assert.Equal(t, "phone", rateLimitErr.Type)
+	assert.WithinDuration(t, start.Add(10*time.Minute), rateLimitErr.RetryAfter, time.Second, "room once the oldest request leaves the window")
+
+	// The rejected request is not counted against the IP
+	count, _, err := limiter.GetRateLimitStatus("10.0.0.1", "ip")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Another phone from the same IP still has room
+	require.NoError(t, limiter.CheckOTPRateLimit("0777654321", "10.0.0.1"))
+
+	// The window slides: the first request has expired
+	server.SetTime(start.Add(10*time.Minute + time.Second))
+	require.NoError(t, limiter.CheckOTPRateLimit("0771234567", ""))
+}
+
+func TestRedisRateLimiter_IPLimit(t *testing.T) {
+	limiter, server := setupRedisRateLimiter(t)
+	server.SetTime(time.Now())
+
+	for _, phone := range []string{"0771000001", "0771000002", "0771000003"} {
+		require.NoError(t, limiter.CheckOTPRateLimit(phone, "10.0.0.2"))
+	}
+
+	err := limiter.CheckOTPRateLimit("0771000004", "10.0.0.2")
+	rateLimitErr, ok := err.(*RateLimitError)
+	require.True(t, ok, "Error should be RateLimitError")
+	assert.Equal(t, "ip", rateLimitErr.Type)
+	assert.Contains(t, rateLimitErr.Message, "Too many OTP requests from this IP address")
+
+	limited, retryAfter, err := limiter.IsRateLimited("10.0.0.2", "ip")
+	require.NoError(t, err)
+	assert.True(t, limited)
+	assert.True(t, retryAfter.After(time.Now().Add(59*time.Minute)))
+
+	limited, _, err = limiter.IsRateLimited("0771000004", "phone")
+	require.NoError(t, err)
+	assert.False(t, limited)
+}
+
+func TestRedisRateLimiter_RedisDown(t *testing.T) {
+	limiter, server := setupRedisRateLimiter(t)
+	server.Close()
+
+	err := limiter.CheckOTPRateLimit("0771234567", "10.0.0.1")
+	require.Error(t, err)
+	_, isRateLimit := err.(*RateLimitError)
+	assert.False(t, isRateLimit)
+}
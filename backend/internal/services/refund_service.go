@@ -0,0 +1,441 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrRefundNotFound          = errors.New("refund not found")
+	ErrRefundBookingNotFound   = errors.New("booking not found")
+	ErrRefundNotAuthorized     = errors.New("not authorized to refund this booking")
+	ErrRefundNotCancelled      = errors.New("only cancelled bookings can be refunded")
+	ErrRefundSeatsNotFound     = errors.New("seat cancellation not found")
+	ErrRefundNotPaid           = errors.New("booking was not paid")
+	ErrRefundPaymentNotFound   = errors.New("booking was not paid through the payment gateway or wallet")
+	ErrRefundNothingDue        = errors.New("nothing is refundable for this cancellation")
+	ErrRefundInvalidTransition = errors.New("refund cannot move to that status")
+	ErrRefundGatewayUnset      = errors.New("payment gateway not configured")
+	ErrRefundWalletUnset       = errors.New("wallet not configured")
+)
+
+// RefundService returns money to passengers who cancelled paid bookings. Passengers request a
+// refund, priced by the cancellation policy as of when they cancelled; an admin approves it,
+// which sends it to PAYable or credits the passenger's wallet, or rejects it. Every status
+// change is recorded.
+type RefundService struct {
+	repo             *database.RefundRepository
+	bookingRepo      *database.AppBookingRepository
+	tripRepo         *database.ScheduledTripRepository
+	tenantRepo       *database.TenantRepository
+	payableService   *PAYableService
+	paymentAuditRepo *database.PaymentAuditRepository
+	wallets          *WalletService
+	cancellation     models.CancellationPolicy
+	logger           *logrus.Logger
+}
+
+// NewRefundService creates a new RefundService
+func NewRefundService(
+	repo *database.RefundRepository,
+	bookingRepo *database.AppBookingRepository,
+	tripRepo *database.ScheduledTripRepository,
+	tenantRepo *database.TenantRepository,
+	payableService *PAYableService,
+	paymentAuditRepo *database.PaymentAuditRepository,
+	wallets *WalletService,
+	cancellation models.CancellationPolicy,
+	logger *logrus.Logger,
+) *RefundService {
+	return &RefundService{
+		repo:             repo,
+		bookingRepo:      bookingRepo,
+		tripRepo:         tripRepo,
+		tenantRepo:       tenantRepo,
+		payableService:   payableService,
+		paymentAuditRepo: paymentAuditRepo,
+		wallets:          wallets,
+		cancellation:     cancellation,
+		logger:           logger,
+	}
+}
+
+// RequestRefund requests a refund of the passenger's cancelled booking. A booking is refunded at
+// most once: asking again returns the existing refund, with created false.
+func (s *RefundService) RequestRefund(bookingID string, userID uuid.UUID, req *models.RequestRefundRequest) (refund *models.Refund, created bool, err error) {
+	booking, err := s.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, ErrRefundBookingNotFound
+		}
+		return nil, false, err
+	}
+	if booking.UserID != userID.String() {
+		return nil, false, ErrRefundNotAuthorized
+	}
+
+	existing, err := s.repo.GetByBooking(booking.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return existing, false, nil
+	}
+
+	if booking.BookingStatus != models.MasterBookingCancelled {
+		return nil, false, ErrRefundNotCancelled
+	}
+	if !booking.IsPaid() {
+		return nil, false, ErrRefundNotPaid
+	}
+	link, err := s.repo.GetPaymentLink(booking.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if link == nil || (link.PaymentUID == nil && !link.WalletAmount.IsPositive()) {
+		return nil, false, ErrRefundPaymentNotFound
+	}
+
+	quote, err := s.cancellationQuote(booking)
+	if err != nil {
+		return nil, false, err
+	}
+	if quote.RefundableAmount <= 0 {
+		return nil, false, ErrRefundNothingDue
+	}
+
+	refund = models.NewRefund(booking, quote, link, optionalString(req.Reason))
+	refund.Destination = link.Destination(req.RefundTo)
+	if err := s.repo.Create(refund, &userID); err != nil {
+		return nil, false, err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"refund_id":         refund.ID,
+		"booking_reference": refund.BookingReference,
+		"amount":            refund.Amount,
+		"destination":       refund.Destination,
+	}).Info("Refund requested")
+	return refund, true, nil
+}
+
+// RequestSeatRefund requests a refund of seats the passenger cancelled out of their booking, at
+// the amount they were priced at when cancelled. Each seat cancellation is refunded at most once.
+func (s *RefundService) RequestSeatRefund(bookingID string, cancellationID uuid.UUID, userID uuid.UUID, req *models.RequestRefundRequest) (refund *models.Refund, created bool, err error) {
+	booking, err := s.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, ErrRefundBookingNotFound
+		}
+		return nil, false, err
+	}
+	if booking.UserID != userID.String() {
+		return nil, false, ErrRefundNotAuthorized
+	}
+	cancellation, err := s.bookingRepo.GetSeatCancellation(booking.ID, cancellationID)
+	if err != nil {
+		return nil, false, err
+	}
+	if cancellation == nil {
+		return nil, false, ErrRefundSeatsNotFound
+	}
+
+	existing, err := s.repo.GetBySeatCancellation(cancellation.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return existing, false, nil
+	}
+
+	// Seats cancelled while the booking was unpaid were priced at nothing
+	if cancellation.RefundableAmount <= 0 {
+		return nil, false, ErrRefundNothingDue
+	}
+	link, err := s.repo.GetPaymentLink(booking.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if link == nil || (link.PaymentUID == nil && !link.WalletAmount.IsPositive()) {
+		return nil, false, ErrRefundPaymentNotFound
+	}
+
+	refund = models.NewSeatRefund(booking, cancellation, link, optionalString(req.Reason))
+	refund.Destination = link.Destination(req.RefundTo)
+	if err := s.repo.Create(refund, &userID); err != nil {
+		return nil, false, err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"refund_id":            refund.ID,
+		"booking_reference":    refund.BookingReference,
+		"seat_cancellation_id": cancellation.ID,
+		"amount":               refund.Amount,
+		"destination":          refund.Destination,
+	}).Info("Seat refund requested")
+	return refund, true, nil
+}
+
+// cancellationQuote prices the booking's cancellation as of when it was cancelled
+func (s *RefundService) cancellationQuote(booking *models.MasterBooking) (*models.CancellationQuote, error) {
+	var departure *time.Time
+	if booking.BusBooking != nil {
+		trip, err := s.tripRepo.GetByID(booking.BusBooking.ScheduledTripID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trip for refund: %w", err)
+		}
+		departure = &trip.DepartureDatetime
+	}
+	cancelledAt := time.Now()
+	if booking.CancelledAt != nil {
+		cancelledAt = *booking.CancelledAt
+	}
+	return s.cancellation.Quote(booking, departure, cancelledAt), nil
+}
+
+// Get returns a refund with its status history
+func (s *RefundService) Get(id uuid.UUID) (*models.Refund, error) {
+	refund, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if refund == nil {
+		return nil, ErrRefundNotFound
+	}
+	if refund.Events, err = s.repo.GetEvents(id); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// List returns a page of refunds with the total count
+func (s *RefundService) List(filter models.RefundFilter) ([]models.Refund, int, error) {
+	return s.repo.List(filter)
+}
+
+// Approve sends a requested (or previously failed) refund to PAYable, or credits it to the
+// passenger's wallet, and records the outcome. A gateway error fails the refund, which can then
+// be approved again.
+func (s *RefundService) Approve(id uuid.UUID, adminID uuid.UUID, req *models.ReviewRefundRequest) (*models.Refund, error) {
+	refund, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if refund == nil {
+		return nil, ErrRefundNotFound
+	}
+	toWallet := refund.Destination == models.RefundToWallet
+	if toWallet && s.wallets == nil {
+		return nil, ErrRefundWalletUnset
+	}
+	if !toWallet && (s.payableService == nil || !s.payableService.IsConfigured()) {
+		return nil, ErrRefundGatewayUnset
+	}
+
+	now := time.Now()
+	refund.ReviewNote = optionalString(req.Note)
+	refund.ReviewedByUserID = &adminID
+	refund.ReviewedAt = &now
+	refund.FailureReason = nil
+	if toWallet {
+		return s.creditWallet(refund, &adminID)
+	}
+
+	payable, err := s.payableFor(refund)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(refund, models.RefundProcessing, refund.ReviewNote, &adminID); err != nil {
+		return nil, err
+	}
+	s.logAudit(refund, models.PaymentEventRefundInitiated, "")
+
+	resp, rawBody, err := payable.Refund(&RefundParams{
+		PaymentUID:      refund.PaymentUID,
+		InvoiceID:       stringOrEmpty(refund.PaymentReference),
+		Amount:          fmt.Sprintf("%.2f", refund.Amount),
+		CurrencyCode:    refund.Currency,
+		RefundReference: refund.ID.String(),
+		Reason:          stringOrEmpty(refund.Reason),
+	})
+	switch {
+	case err != nil:
+		s.logger.WithError(err).WithField("refund_id", refund.ID).Error("PAYable refund request failed")
+		refund.FailureReason = optionalString(err.Error())
+		err = s.transition(refund, models.RefundFailed, refund.FailureReason, nil)
+		s.logAudit(refund, models.PaymentEventError, "")
+	case resp.IsSuccessful():
+		completedAt := time.Now()
+		refund.GatewayRefundID = optionalString(resp.Data.RefundID)
+		refund.CompletedAt = &completedAt
+		err = s.transition(refund, models.RefundCompleted, nil, nil)
+		s.logAudit(refund, s.completedEvent(refund), rawBody)
+	default:
+		refund.FailureReason = optionalString(resp.FailureMessage())
+		err = s.transition(refund, models.RefundFailed, refund.FailureReason, nil)
+		s.logAudit(refund, models.PaymentEventFailed, rawBody)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(refund.ID)
+}
+
+// creditWallet pays an approved refund into the passenger's wallet. Crediting is idempotent per
+// refund, so a refund failed after its credit went through completes on approval without paying twice.
+func (s *RefundService) creditWallet(refund *models.Refund, adminID *uuid.UUID) (*models.Refund, error) {
+	if err := s.transition(refund, models.RefundProcessing, refund.ReviewNote, adminID); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.wallets.CreditRefund(refund)
+	if err != nil {
+		s.logger.WithError(err).WithField("refund_id", refund.ID).Error("Wallet refund credit failed")
+		refund.FailureReason = optionalString(err.Error())
+		err = s.transition(refund, models.RefundFailed, refund.FailureReason, nil)
+	} else {
+		completedAt := time.Now()
+		refund.GatewayRefundID = optionalString(entry.ID)
+		refund.CompletedAt = &completedAt
+		err = s.transition(refund, models.RefundCompleted, optionalString("credited to wallet"), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(refund.ID)
+}
+
+// Reject declines a refund that has not been paid out
+func (s *RefundService) Reject(id uuid.UUID, adminID uuid.UUID, req *models.ReviewRefundRequest) (*models.Refund, error) {
+	refund, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if refund == nil {
+		return nil, ErrRefundNotFound
+	}
+
+	now := time.Now()
+	refund.ReviewNote = optionalString(req.Note)
+	refund.ReviewedByUserID = &adminID
+	refund.ReviewedAt = &now
+	if err := s.transition(refund, models.RefundRejected, refund.ReviewNote, &adminID); err != nil {
+		return nil, err
+	}
+	return s.Get(refund.ID)
+}
+
+// Resolve records the outcome of a refund left processing, e.g. when PAYable's answer was lost
+// to a timeout and an admin has checked the merchant portal
+func (s *RefundService) Resolve(id uuid.UUID, adminID uuid.UUID, req *models.ResolveRefundRequest) (*models.Refund, error) {
+	refund, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if refund == nil {
+		return nil, ErrRefundNotFound
+	}
+
+	note := optionalString(req.Note)
+	if req.Status == models.RefundCompleted {
+		now := time.Now()
+		refund.CompletedAt = &now
+		if gatewayID := optionalString(req.GatewayRefundID); gatewayID != nil {
+			refund.GatewayRefundID = gatewayID
+		}
+	} else {
+		refund.FailureReason = note
+	}
+	if err := s.transition(refund, req.Status, note, &adminID); err != nil {
+		return nil, err
+	}
+	if req.Status == models.RefundCompleted {
+		s.logAudit(refund, s.completedEvent(refund), "")
+	}
+	return s.Get(refund.ID)
+}
+
+// transition moves the refund to status, failing if it cannot move there or was changed concurrently
+func (s *RefundService) transition(refund *models.Refund, status models.RefundStatus, note *string, actorUserID *uuid.UUID) error {
+	from := refund.Status
+	if !from.CanTransitionTo(status) {
+		return ErrRefundInvalidTransition
+	}
+	refund.Status = status
+	updated, err := s.repo.Transition(refund, from, note, actorUserID)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrRefundInvalidTransition
+	}
+	s.logger.WithFields(logrus.Fields{
+		"refund_id": refund.ID,
+		"from":      from,
+		"to":        status,
+	}).Info("Refund status updated")
+	return nil
+}
+
+// payableFor returns the gateway client for the merchant account that took the payment
+func (s *RefundService) payableFor(refund *models.Refund) (*PAYableService, error) {
+	if refund.TenantID == nil || s.tenantRepo == nil {
+		return s.payableService, nil
+	}
+	tenant, err := s.tenantRepo.GetByID(*refund.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.HasPaymentAccount() {
+		return s.payableService.WithMerchant(*tenant.PaymentMerchantKey, *tenant.PaymentMerchantToken), nil
+	}
+	return s.payableService, nil
+}
+
+func (s *RefundService) completedEvent(refund *models.Refund) models.PaymentEventType {
+	if refund.SeatCancellationID != nil || refund.RefundedPaymentStatus() == models.MasterPaymentPartialRefund {
+		return models.PaymentEventPartialRefund
+	}
+	return models.PaymentEventRefundCompleted
+}
+
+// logAudit adds the refund event to the payment audit trail (best-effort)
+func (s *RefundService) logAudit(refund *models.Refund, eventType models.PaymentEventType, rawBody string) {
+	if s.paymentAuditRepo == nil {
+		return
+	}
+	audit := models.NewPaymentAudit(eventType, models.PaymentSourceBackend)
+	if rawBody != "" {
+		audit.EventSource = models.PaymentSourcePayableAPI
+		audit.SetRawBody(rawBody)
+	}
+	if refund.IntentID != nil {
+		audit.SetIntent(*refund.IntentID)
+	}
+	audit.SetPaymentUID(refund.PaymentUID)
+	audit.PaymentReference = refund.PaymentReference
+	audit.ReceivedAmount = &refund.Amount
+	audit.Currency = &refund.Currency
+	audit.SetResponsePayload(map[string]interface{}{
+		"refund_id":         refund.ID.String(),
+		"refund_status":     refund.Status,
+		"gateway_refund_id": refund.GatewayRefundID,
+	})
+
+	if err := s.paymentAuditRepo.Log(context.Background(), audit); err != nil {
+		s.logger.WithError(err).WithField("refund_id", refund.ID).Error("Failed to audit refund event")
+	}
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
+)
+
+// defaultBusSpeedKmh is used for ETA when the bus has not reported a usable speed
+const defaultBusSpeedKmh = 30.0
+
+// ReminderSchedulerService queues boarding reminders for confirmed bookings
+// and delivers them in the background through the notification service. Reminders for
+// passengers who turned SMS off in their preferences are cancelled instead of sent.
+type ReminderSchedulerService struct {
+	reminderRepo        *database.BookingReminderRepository
+	notificationService *NotificationService
+	preferences         *UserPreferencesService
+	config              config.ReminderConfig
+	logger              *logrus.Logger
+	stopCh              chan struct{}
+}
+
+// NewReminderSchedulerService creates a new reminder scheduler service
+func NewReminderSchedulerService(
+	reminderRepo *database.BookingReminderRepository,
+	notificationService *NotificationService,
+	preferences *UserPreferencesService,
+	cfg config.ReminderConfig,
+	logger *logrus.Logger,
+) *ReminderSchedulerService {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	return &ReminderSchedulerService{
+		reminderRepo:        reminderRepo,
+		notificationService: notificationService,
+		preferences:         preferences,
+		config:              cfg,
+		logger:              logger,
+		stopCh:              make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// SCHEDULING
+// ============================================================================
+
+// BuildReminders returns the reminders to queue for a booking departing at the given time.
+// Departure offsets that have already passed are skipped.
+func (s *ReminderSchedulerService) BuildReminders(bookingID, userID, scheduledTripID string, departure, now time.Time) []*models.BookingReminder {
+	reminders := make([]*models.BookingReminder, 0, len(s.config.DepartureOffsets)+1)
+
+	for _, offset := range s.config.DepartureOffsets {
+		sendAt := departure.Add(-offset)
+		if !sendAt.After(now) {
+			continue
+		}
+		reminders = append(reminders, &models.BookingReminder{
+			BookingID:       bookingID,
+			UserID:          userID,
+			ScheduledTripID: scheduledTripID,
+			Kind:            models.ReminderKindDeparture,
+			OffsetMinutes:   int(offset.Minutes()),
+			SendAt:          &sendAt,
+		})
+	}
+
+	if s.config.BusApproachingMinutes > 0 && departure.After(now) {
+		reminders = append(reminders, &models.BookingReminder{
+			BookingID:       bookingID,
+			UserID:          userID,
+			ScheduledTripID: scheduledTripID,
+			Kind:            models.ReminderKindBusApproaching,
+			OffsetMinutes:   s.config.BusApproachingMinutes,
+		})
+	}
+
+	return reminders
+}
+
+// ScheduleForBooking queues boarding reminders for a confirmed booking
+func (s *ReminderSchedulerService) ScheduleForBooking(bookingID, userID, scheduledTripID string, departure time.Time) error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	reminders := s.BuildReminders(bookingID, userID, scheduledTripID, departure, time.Now())
+	if err := s.reminderRepo.CreateReminders(reminders); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"booking_id": bookingID,
+		"count":      len(reminders),
+	}).Info("Boarding reminders scheduled")
+	return nil
+}
+
+// CancelForBooking de-schedules pending reminders when a booking is cancelled
+func (s *ReminderSchedulerService) CancelForBooking(bookingID string) error {
+	cancelled, err := s.reminderRepo.CancelForBooking(bookingID)
+	if err != nil {
+		return err
+	}
+	if cancelled > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"booking_id": bookingID,
+			"count":      cancelled,
+		}).Info("Boarding reminders cancelled")
+	}
+	return nil
+}
+
+// ============================================================================
+// BACKGROUND DELIVERY
+// ============================================================================
+
+// Start begins the background reminder job
+func (s *ReminderSchedulerService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Reminder Scheduler disabled (REMINDER_ENABLED=false)")
+		return
+	}
+	s.logger.WithField("interval", s.config.CheckInterval.String()).Info("🔔 Starting Reminder Scheduler")
+	go s.run()
+}
+
+// Stop stops the background reminder job
+func (s *ReminderSchedulerService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Reminder Scheduler")
+	close(s.stopCh)
+}
+
+func (s *ReminderSchedulerService) run() {
+	s.processReminders()
+
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processReminders()
+		case <-s.stopCh:
+			s.logger.Info("Reminder Scheduler stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single delivery cycle (useful for testing or manual trigger)
+func (s *ReminderSchedulerService) RunOnce() {
+	s.processReminders()
+}
+
+// processReminders delivers due reminders and expires those that can no longer be sent
+func (s *ReminderSchedulerService) processReminders() {
+	now := time.Now()
+
+	// 1. Time-based reminders (e.g. 24h / 2h before departure)
+	due, err := s.reminderRepo.GetDueDepartureReminders(now, 100)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get due departure reminders")
+	} else {
+		for i := range due {
+			s.deliver(&due[i], departureReminderMessage(&due[i], now))
+		}
+	}
+
+	// 2. Live-tracking reminders (bus N minutes from boarding stop)
+	if s.config.BusApproachingMinutes > 0 {
+		approaching, err := s.reminderRepo.GetPendingBusApproachingReminders(200)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to get bus approaching reminders")
+		} else {
+			for i := range approaching {
+				eta, ok := EstimateMinutesToStop(&approaching[i])
+				if !ok || eta > float64(approaching[i].OffsetMinutes) {
+					continue
+				}
+				s.deliver(&approaching[i], busApproachingMessage(&approaching[i], eta))
+			}
+		}
+	}
+
+	// 3. Expire reminders for trips that departed long ago or bookings no longer confirmed
+	expired, err := s.reminderRepo.ExpireStaleReminders(now.Add(-6 * time.Hour))
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to expire stale reminders")
+	} else if expired > 0 {
+		s.logger.WithField("count", expired).Info("Expired stale reminders")
+	}
+}
+
+// deliver sends a single reminder and records the outcome
+func (s *ReminderSchedulerService) deliver(reminder *models.DueBookingReminder, message string) {
+	if userID, err := uuid.Parse(reminder.UserID); err == nil && s.preferences != nil &&
+		!s.preferences.AllowsChannel(userID, models.NotificationChannelSMS) {
+		if err := s.reminderRepo.CancelReminder(reminder.ID, "passenger turned off SMS notifications"); err != nil {
+			s.logger.WithError(err).WithField("reminder_id", reminder.ID).Error("Failed to cancel opted-out reminder")
+		}
+		return
+	}
+
+	if err := s.notificationService.SendSMS(reminder.PassengerPhone, message); err != nil {
+		s.logger.WithError(err).WithField("reminder_id", reminder.ID).Warn("Failed to deliver reminder")
+		if markErr := s.reminderRepo.MarkAttemptFailed(reminder.ID, err.Error(), s.config.MaxAttempts); markErr != nil {
+			s.logger.WithError(markErr).WithField("reminder_id", reminder.ID).Error("Failed to record reminder failure")
+		}
+		return
+	}
+
+	if err := s.reminderRepo.MarkSent(reminder.ID); err != nil {
+		s.logger.WithError(err).WithField("reminder_id", reminder.ID).Error("Failed to mark reminder sent")
+	}
+}
+
+// EstimateMinutesToStop estimates how many minutes the bus is from the passenger's boarding stop
+func EstimateMinutesToStop(reminder *models.DueBookingReminder) (float64, bool) {
+	if reminder.BusLatitude == nil || reminder.BusLongitude == nil ||
+		reminder.StopLatitude == nil || reminder.StopLongitude == nil {
+		return 0, false
+	}
+
+	distanceKm := utils.HaversineKm(*reminder.BusLatitude, *reminder.BusLongitude, *reminder.StopLatitude, *reminder.StopLongitude)
+
+	speed := defaultBusSpeedKmh
+	if reminder.BusSpeedKmh != nil && *reminder.BusSpeedKmh >= 5 {
+		speed = *reminder.BusSpeedKmh
+	}
+
+	return distanceKm / speed * 60, true
+}
+
+// ============================================================================
+// MESSAGES
+// ============================================================================
+
+func departureReminderMessage(reminder *models.DueBookingReminder, now time.Time) string {
+	until := reminder.DepartureDatetime.Sub(now).Round(time.Minute)
+	when := fmt.Sprintf("in %d hours", int(until.Hours()))
+	if until < 2*time.Hour {
+		when = fmt.Sprintf("in %d minutes", int(until.Minutes()))
+	}
+
+	msg := fmt.Sprintf("SmartTransit: Your bus on %s departs %s (%s).",
+		reminder.RouteName, when, reminder.DepartureDatetime.Format("Jan 2, 15:04"))
+	if reminder.BoardingStopName != "" {
+		msg += fmt.Sprintf(" Board at %s.", reminder.BoardingStopName)
+	}
+	return msg + fmt.Sprintf(" Ref: %s", reminder.BookingReference)
+}
+
+func busApproachingMessage(reminder *models.DueBookingReminder, etaMinutes float64) string {
+	stop := reminder.BoardingStopName
+	if stop == "" {
+		stop = "your boarding stop"
+	}
+	minutes := int(etaMinutes + 0.5)
+	if minutes < 1 {
+		return fmt.Sprintf("SmartTransit: Your bus on %s is arriving at %s now. Ref: %s",
+			reminder.RouteName, stop, reminder.BookingReference)
+	}
+	return fmt.Sprintf("SmartTransit: Your bus on %s is about %d min from %s. Ref: %s",
+		reminder.RouteName, minutes, stop, reminder.BookingReference)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReminderScheduler() *ReminderSchedulerService {
+	return NewReminderSchedulerService(nil, nil, nil, config.ReminderConfig{
+		Enabled:               true,
+		DepartureOffsets:      []time.Duration{24 * time.Hour, 2 * time.Hour},
+		BusApproachingMinutes: 15,
+	}, logrus.New())
+}
+
+func TestReminderScheduler_BuildReminders(t *testing.T) {
+	s := newTestReminderScheduler()
+	now := time.Date(2025, 11, 20, 8, 0, 0, 0, time.UTC)
+	departure := now.Add(48 * time.Hour)
+
+	reminders := s.BuildReminders("booking-1", "user-1", "trip-1", departure, now)
+
+	assert.Len(t, reminders, 3)
+	assert.Equal(t, models.ReminderKindDeparture, reminders[0].Kind)
+	assert.Equal(t, 24*60, reminders[0].OffsetMinutes)
+	assert.Equal(t, departure.Add(-24*time.Hour), *reminders[0].SendAt)
+	assert.Equal(t, 2*60, reminders[1].OffsetMinutes)
+	assert.Equal(t, models.ReminderKindBusApproaching, reminders[2].Kind)
+	assert.Equal(t, 15, reminders[2].OffsetMinutes)
+	assert.Nil(t, reminders[2].SendAt)
+}
+
+func TestReminderScheduler_BuildReminders_SkipsPassedOffsets(t *testing.T) {
+	s := newTestReminderScheduler()
+	now := time.Date(2025, 11, 20, 8, 0, 0, 0, time.UTC)
+
+	// Booked 5 hours before departure: the 24h reminder is already in the past
+	reminders := s.BuildReminders("booking-1", "user-1", "trip-1", now.Add(5*time.Hour), now)
+
+	assert.Len(t, reminders, 2)
+	assert.Equal(t, 2*60, reminders[0].OffsetMinutes)
+	assert.Equal(t, models.ReminderKindBusApproaching, reminders[1].Kind)
+
+	// Trip already departed: nothing to schedule
+	assert.Empty(t, s.BuildReminders("booking-1", "user-1", "trip-1", now.Add(-time.Minute), now))
+}
+
+func TestEstimateMinutesToStop(t *testing.T) {
+	busLat, busLng := 6.9271, 79.8612   // Colombo Fort
+	stopLat, stopLng := 6.8649, 79.8997 // ~8 km south
+	speed := 40.0
+
+	eta, ok := EstimateMinutesToStop(&models.DueBookingReminder{
+		BusLatitude: &busLat, BusLongitude: &busLng, BusSpeedKmh: &speed,
+		StopLatitude: &stopLat, StopLongitude: &stopLng,
+	})
+	assert.True(t, ok)
+	assert.InDelta(t, 12, eta, 1.5)
+
+	// Stopped bus falls back to the default speed
+	stopped := 0.0
+	eta, ok = EstimateMinutesToStop(&models.DueBookingReminder{
+		BusLatitude: &busLat, BusLongitude: &busLng, BusSpeedKmh: &stopped,
+		StopLatitude: &stopLat, StopLongitude: &stopLng,
+	})
+	assert.True(t, ok)
+	assert.InDelta(t, 16, eta, 2)
+
+	// Missing stop coordinates
+	_, ok = EstimateMinutesToStop(&models.DueBookingReminder{BusLatitude: &busLat, BusLongitude: &busLng})
+	assert.False(t, ok)
+}
@@ -0,0 +1,567 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/email"
+	"github.com/smarttransit/sms-auth-backend/pkg/pdf"
+)
+
+var (
+	ErrReportSubscriptionNotFound   = errors.New("report subscription not found")
+	ErrInvalidReportType            = errors.New("report_type must be one of daily_bookings, weekly_revenue, monthly_occupancy")
+	ErrTooManyReportSubscriptions   = errors.New("maximum number of report subscriptions reached")
+	ErrDuplicateReportSubscription  = errors.New("this address is already subscribed to the report")
+	ErrReportOwnerProfileNotFound   = errors.New("bus owner profile not found")
+	ErrReportUnsubscribeLinkInvalid = errors.New("unsubscribe link is invalid")
+)
+
+const (
+	unsubscribeTokenBytes = 24
+	reportBatchSize       = 20 // Subscriptions delivered per cycle; large reports are slow to build
+)
+
+// ReportSubscriptionService manages recurring report subscriptions for bus owners and admins,
+// and runs the background job that builds due reports and emails them
+type ReportSubscriptionService struct {
+	repo      *database.ReportSubscriptionRepository
+	ownerRepo *database.BusOwnerRepository
+	sender    email.Sender
+	config    config.ReportConfig
+	logger    *logrus.Logger
+	stopCh    chan struct{}
+}
+
+// NewReportSubscriptionService creates a new ReportSubscriptionService
+func NewReportSubscriptionService(
+	repo *database.ReportSubscriptionRepository,
+	ownerRepo *database.BusOwnerRepository,
+	sender email.Sender,
+	cfg config.ReportConfig,
+	logger *logrus.Logger,
+) *ReportSubscriptionService {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 5 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.MaxCSVRows <= 0 {
+		cfg.MaxCSVRows = 50000
+	}
+	if cfg.SendHour < 0 || cfg.SendHour > 23 {
+		cfg.SendHour = 6
+	}
+	return &ReportSubscriptionService{
+		repo:      repo,
+		ownerRepo: ownerRepo,
+		sender:    sender,
+		config:    cfg,
+		logger:    logger,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// SUBSCRIPTIONS
+// ============================================================================
+
+// Subscribe creates a subscription. Bus owner subscriptions cover the owner's own trips;
+// admin subscriptions are platform-wide.
+func (s *ReportSubscriptionService) Subscribe(userID string, subscriberType models.ReportSubscriberType, req *models.CreateReportSubscriptionRequest) (*models.ReportSubscription, error) {
+	if !req.ReportType.IsValid() {
+		return nil, ErrInvalidReportType
+	}
+	address := strings.TrimSpace(req.Email)
+
+	var busOwnerID *string
+	if subscriberType == models.ReportSubscriberBusOwner {
+		owner, err := s.ownerRepo.GetByUserID(userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrReportOwnerProfileNotFound
+			}
+			return nil, fmt.Errorf("failed to get bus owner: %w", err)
+		}
+		busOwnerID = &owner.ID
+	}
+
+	if s.config.MaxSubscriptions > 0 {
+		count, err := s.repo.CountActiveByUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= s.config.MaxSubscriptions {
+			return nil, ErrTooManyReportSubscriptions
+		}
+	}
+	exists, err := s.repo.ExistsActive(userID, req.ReportType, address)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrDuplicateReportSubscription
+	}
+
+	token, err := generateUnsubscribeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &models.ReportSubscription{
+		UserID:           userID,
+		SubscriberType:   subscriberType,
+		BusOwnerID:       busOwnerID,
+		ReportType:       req.ReportType,
+		Email:            address,
+		UnsubscribeToken: token,
+		IsActive:         true,
+		NextRunAt:        req.ReportType.NextRunAfter(time.Now(), s.config.SendHour),
+	}
+	if err := s.repo.Create(sub); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": sub.ID,
+		"report_type":     sub.ReportType,
+		"next_run_at":     sub.NextRunAt,
+	}).Info("Report subscription created")
+	return sub, nil
+}
+
+// List returns the user's subscriptions
+func (s *ReportSubscriptionService) List(userID string) ([]models.ReportSubscription, error) {
+	return s.repo.ListByUser(userID)
+}
+
+// Update changes the delivery address or pauses/resumes a subscription.
+// Resuming schedules the next regular delivery rather than catching up on missed ones.
+func (s *ReportSubscriptionService) Update(userID, subscriptionID string, req *models.UpdateReportSubscriptionRequest) (*models.ReportSubscription, error) {
+	sub, err := s.repo.GetByID(subscriptionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrReportSubscriptionNotFound
+	}
+
+	if req.Email != nil {
+		sub.Email = strings.TrimSpace(*req.Email)
+	}
+	if req.IsActive != nil && *req.IsActive != sub.IsActive {
+		if *req.IsActive {
+			if s.config.MaxSubscriptions > 0 {
+				count, err := s.repo.CountActiveByUser(userID)
+				if err != nil {
+					return nil, err
+				}
+				if count >= s.config.MaxSubscriptions {
+					return nil, ErrTooManyReportSubscriptions
+				}
+			}
+			sub.NextRunAt = sub.ReportType.NextRunAfter(time.Now(), s.config.SendHour)
+			sub.FailedAttempts = 0
+			sub.UnsubscribedAt = nil
+		}
+		sub.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.Update(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Delete removes a subscription
+func (s *ReportSubscriptionService) Delete(userID, subscriptionID string) error {
+	deleted, err := s.repo.Delete(subscriptionID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrReportSubscriptionNotFound
+	}
+	return nil
+}
+
+// Unsubscribe deactivates the subscription behind an emailed unsubscribe link.
+// Repeating the request is harmless.
+func (s *ReportSubscriptionService) Unsubscribe(token string) (*models.ReportSubscription, error) {
+	sub, err := s.repo.GetByUnsubscribeToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrReportUnsubscribeLinkInvalid
+	}
+	if !sub.IsActive {
+		return sub, nil
+	}
+
+	now := time.Now()
+	sub.IsActive = false
+	sub.UnsubscribedAt = &now
+	if err := s.repo.Update(sub); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("subscription_id", sub.ID).Info("Report subscription unsubscribed via email link")
+	return sub, nil
+}
+
+// UnsubscribeLink returns the public unsubscribe URL for a token
+func (s *ReportSubscriptionService) UnsubscribeLink(token string) string {
+	return strings.TrimRight(s.config.UnsubscribeURL, "/") + "/" + token
+}
+
+// ============================================================================
+// BACKGROUND DELIVERY
+// ============================================================================
+
+// Start begins the background report job
+func (s *ReportSubscriptionService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Report Scheduler disabled (REPORTS_ENABLED=false)")
+		return
+	}
+	s.logger.WithField("interval", s.config.CheckInterval.String()).Info("📊 Starting Report Scheduler")
+	go s.run()
+}
+
+// Stop stops the background report job
+func (s *ReportSubscriptionService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Report Scheduler")
+	close(s.stopCh)
+}
+
+func (s *ReportSubscriptionService) run() {
+	s.processDue()
+
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processDue()
+		case <-s.stopCh:
+			s.logger.Info("Report Scheduler stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single delivery cycle (useful for testing or manual trigger)
+func (s *ReportSubscriptionService) RunOnce() {
+	s.processDue()
+}
+
+// processDue builds and emails every due report
+func (s *ReportSubscriptionService) processDue() {
+	now := time.Now()
+	due, err := s.repo.GetDue(now, reportBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get due report subscriptions")
+		return
+	}
+
+	for i := range due {
+		s.deliver(&due[i], now)
+	}
+}
+
+// deliver sends one subscription's report for the period that ended before its scheduled run
+func (s *ReportSubscriptionService) deliver(sub *models.ReportSubscription, now time.Time) {
+	log := s.logger.WithFields(logrus.Fields{"subscription_id": sub.ID, "report_type": sub.ReportType})
+
+	msg, err := s.BuildReport(sub)
+	if err == nil {
+		err = s.sender.Send(*msg)
+	}
+	if err != nil {
+		log.WithError(err).Warn("Failed to deliver scheduled report")
+
+		// Give up on this period once retries are used up, so one bad period does not block the next
+		var next *time.Time
+		if sub.FailedAttempts+1 >= s.config.MaxAttempts {
+			n := sub.ReportType.NextRunAfter(now, s.config.SendHour)
+			next = &n
+		}
+		if markErr := s.repo.MarkFailed(sub.ID, err.Error(), next); markErr != nil {
+			log.WithError(markErr).Error("Failed to record report delivery failure")
+		}
+		return
+	}
+
+	if err := s.repo.MarkDelivered(sub.ID, now, sub.ReportType.NextRunAfter(now, s.config.SendHour)); err != nil {
+		log.WithError(err).Error("Failed to mark report delivered")
+		return
+	}
+	log.Info("Scheduled report delivered")
+}
+
+// ============================================================================
+// REPORTS
+// ============================================================================
+
+// BuildReport generates the subscription's report as an email with the report attached
+func (s *ReportSubscriptionService) BuildReport(sub *models.ReportSubscription) (*email.Message, error) {
+	from, to := sub.ReportType.PeriodEnding(sub.NextRunAt)
+	scope := "all operators"
+	if sub.BusOwnerID != nil {
+		scope = "your fleet"
+	}
+
+	var (
+		attachment email.Attachment
+		summary    string
+	)
+	switch sub.ReportType {
+	case models.ReportDailyBookings:
+		rows, err := s.repo.GetBookingsReport(sub.BusOwnerID, from, to, s.config.MaxCSVRows)
+		if err != nil {
+			return nil, err
+		}
+		attachment = email.Attachment{
+			Filename:    fmt.Sprintf("bookings-%s.csv", from.Format("2006-01-02")),
+			ContentType: "text/csv",
+			Data:        bookingsCSV(rows),
+		}
+		summary = fmt.Sprintf("%d bookings were made on %s for %s.", len(rows), from.Format("Mon, Jan 2 2006"), scope)
+
+	case models.ReportWeeklyRevenue:
+		rows, err := s.repo.GetRevenueReport(sub.BusOwnerID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		report := summarizeRevenue(rows)
+		attachment = email.Attachment{
+			Filename:    fmt.Sprintf("revenue-%s.pdf", from.Format("2006-01-02")),
+			ContentType: "application/pdf",
+			Data:        revenuePDF(report, from, to, scope),
+		}
+		summary = fmt.Sprintf("Paid bus revenue for %s for the week of %s: LKR %.2f across %d trips.",
+			scope, from.Format("Jan 2"), report.Revenue, report.Trips)
+
+	case models.ReportMonthlyOccupancy:
+		rows, err := s.repo.GetOccupancyReport(sub.BusOwnerID, from, to, s.config.MaxCSVRows)
+		if err != nil {
+			return nil, err
+		}
+		attachment = email.Attachment{
+			Filename:    fmt.Sprintf("occupancy-%s.csv", from.Format("2006-01")),
+			ContentType: "text/csv",
+			Data:        occupancyCSV(rows),
+		}
+		summary = fmt.Sprintf("Seat occupancy for %s across %d trips in %s.", scope, len(rows), from.Format("January 2006"))
+
+	default:
+		return nil, ErrInvalidReportType
+	}
+
+	link := s.UnsubscribeLink(sub.UnsubscribeToken)
+	return &email.Message{
+		To:      sub.Email,
+		Subject: fmt.Sprintf("SmartTransit %s – %s", reportTitle(sub.ReportType), reportPeriodLabel(sub.ReportType, from, to)),
+		Body: fmt.Sprintf("Hello,\n\n%s\nThe full report is attached (%s).\n\n"+
+			"You receive this because %s is subscribed to this report. To stop receiving it, open:\n%s\n\n— SmartTransit\n",
+			summary, attachment.Filename, sub.Email, link),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + link + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+		Attachments: []email.Attachment{attachment},
+	}, nil
+}
+
+func reportTitle(t models.ReportType) string {
+	switch t {
+	case models.ReportWeeklyRevenue:
+		return "weekly revenue report"
+	case models.ReportMonthlyOccupancy:
+		return "monthly occupancy report"
+	default:
+		return "daily bookings report"
+	}
+}
+
+// reportPeriodLabel describes [from, to) for a subject line
+func reportPeriodLabel(t models.ReportType, from, to time.Time) string {
+	switch t {
+	case models.ReportWeeklyRevenue:
+		return fmt.Sprintf("%s to %s", from.Format("Jan 2"), to.AddDate(0, 0, -1).Format("Jan 2, 2006"))
+	case models.ReportMonthlyOccupancy:
+		return from.Format("January 2006")
+	default:
+		return from.Format("Jan 2, 2006")
+	}
+}
+
+func bookingsCSV(rows []models.ReportBookingRow) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"booking_reference", "booked_at", "route", "departure", "passenger", "seats", "total_fare", "booking_status", "payment_status", "source"})
+	for _, r := range rows {
+		_ = w.Write([]string{
+			r.BookingReference,
+			r.BookedAt.In(models.ReportTimezone).Format("2006-01-02 15:04"),
+			r.RouteName,
+			r.DepartureDatetime.In(models.ReportTimezone).Format("2006-01-02 15:04"),
+			derefOr(r.PassengerName, ""),
+			fmt.Sprintf("%d", r.Seats),
+			fmt.Sprintf("%.2f", r.TotalFare),
+			r.BookingStatus,
+			r.PaymentStatus,
+			derefOr(r.BookingSource, ""),
+		})
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+func occupancyCSV(rows []models.ReportOccupancyRow) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"scheduled_trip_id", "departure", "route", "status", "total_seats", "booked_seats", "blocked_seats", "occupancy_percent"})
+	for _, r := range rows {
+		_ = w.Write([]string{
+			r.ScheduledTripID,
+			r.DepartureDatetime.In(models.ReportTimezone).Format("2006-01-02 15:04"),
+			r.RouteName,
+			r.Status,
+			fmt.Sprintf("%d", r.TotalSeats),
+			fmt.Sprintf("%d", r.BookedSeats),
+			fmt.Sprintf("%d", r.BlockedSeats),
+			fmt.Sprintf("%.1f", occupancyPercent(r)),
+		})
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// occupancyPercent is booked seats over sellable (non-blocked) seats
+func occupancyPercent(r models.ReportOccupancyRow) float64 {
+	sellable := r.TotalSeats - r.BlockedSeats
+	if sellable <= 0 {
+		return 0
+	}
+	return float64(r.BookedSeats) / float64(sellable) * 100
+}
+
+// revenueTotals is revenue aggregated over a day, a route or the whole report
+type revenueTotals struct {
+	Label    string
+	Trips    int
+	Bookings int
+	Seats    int
+	Revenue  float64
+}
+
+func (t *revenueTotals) add(row models.ReportRevenueRow) {
+	t.Trips += row.Trips
+	t.Bookings += row.Bookings
+	t.Seats += row.Seats
+	t.Revenue += row.Revenue
+}
+
+// revenueSummary is the weekly revenue report broken down by day and by route
+type revenueSummary struct {
+	revenueTotals
+	Days   []revenueTotals
+	Routes []revenueTotals // Highest revenue first
+}
+
+func summarizeRevenue(rows []models.ReportRevenueRow) revenueSummary {
+	var summary revenueSummary
+	days := map[string]*revenueTotals{}
+	routes := map[string]*revenueTotals{}
+	var dayOrder []string
+
+	for _, row := range rows {
+		summary.add(row)
+
+		day := row.Day.Format("Mon 2006-01-02")
+		if days[day] == nil {
+			days[day] = &revenueTotals{Label: day}
+			dayOrder = append(dayOrder, day)
+		}
+		days[day].add(row)
+
+		if routes[row.RouteName] == nil {
+			routes[row.RouteName] = &revenueTotals{Label: row.RouteName}
+		}
+		routes[row.RouteName].add(row)
+	}
+
+	for _, day := range dayOrder {
+		summary.Days = append(summary.Days, *days[day])
+	}
+	for _, route := range routes {
+		summary.Routes = append(summary.Routes, *route)
+	}
+	sort.Slice(summary.Routes, func(i, j int) bool {
+		if summary.Routes[i].Revenue != summary.Routes[j].Revenue {
+			return summary.Routes[i].Revenue > summary.Routes[j].Revenue
+		}
+		return summary.Routes[i].Label < summary.Routes[j].Label
+	})
+	return summary
+}
+
+func revenuePDF(summary revenueSummary, from, to time.Time, scope string) []byte {
+	doc := pdf.NewDocument()
+	doc.Heading("SmartTransit weekly revenue")
+	doc.Linef("Period: %s to %s (%s)", from.Format("Mon Jan 2"), to.AddDate(0, 0, -1).Format("Mon Jan 2, 2006"), scope)
+	doc.Line("Paid bus bookings for trips departing in the period, excluding cancellations and no-shows.")
+	doc.Line("")
+
+	table := func(title string, rows []revenueTotals) {
+		doc.Heading(title)
+		doc.Linef("%-40s %6s %9s %6s %16s", "", "Trips", "Bookings", "Seats", "Revenue (LKR)")
+		for _, r := range rows {
+			label := r.Label
+			if len([]rune(label)) > 40 {
+				label = string([]rune(label)[:39]) + "~"
+			}
+			doc.Linef("%-40s %6d %9d %6d %16.2f", label, r.Trips, r.Bookings, r.Seats, r.Revenue)
+		}
+		doc.Linef("%-40s %6d %9d %6d %16.2f", "Total", summary.Trips, summary.Bookings, summary.Seats, summary.Revenue)
+		doc.Line("")
+	}
+	table("By day", summary.Days)
+	table("By route", summary.Routes)
+
+	return doc.Bytes()
+}
+
+func generateUnsubscribeToken() (string, error) {
+	b := make([]byte, unsubscribeTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate unsubscribe token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func derefOr(value *string, fallback string) string {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeRevenue_GroupsByDayAndRoute(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	summary := summarizeRevenue([]models.ReportRevenueRow{
+		{Day: monday, RouteName: "Colombo - Kandy", Trips: 2, Bookings: 10, Seats: 14, Revenue: 7000},
+		{Day: monday, RouteName: "Colombo - Galle", Trips: 1, Bookings: 3, Seats: 3, Revenue: 1500},
+		{Day: tuesday, RouteName: "Colombo - Galle", Trips: 1, Bookings: 12, Seats: 15, Revenue: 7500},
+	})
+
+	assert.Equal(t, 4, summary.Trips)
+	assert.Equal(t, 16000.0, summary.Revenue)
+	require.Len(t, summary.Days, 2)
+	assert.Equal(t, "Mon 2026-03-02", summary.Days[0].Label)
+	assert.Equal(t, 8500.0, summary.Days[0].Revenue)
+	require.Len(t, summary.Routes, 2)
+	assert.Equal(t, "Colombo - Galle", summary.Routes[0].Label, "highest revenue first")
+	assert.Equal(t, 9000.0, summary.Routes[0].Revenue)
+}
+
+func TestOccupancyCSV_ExcludesBlockedSeatsFromCapacity(t *testing.T) {
+	out := occupancyCSV([]models.ReportOccupancyRow{
+		{ScheduledTripID: "t1", DepartureDatetime: time.Date(2026, 2, 3, 1, 0, 0, 0, time.UTC), RouteName: "Colombo - Kandy",
+			Status: "completed", TotalSeats: 44, BookedSeats: 30, BlockedSeats: 4},
+		{ScheduledTripID: "t2", RouteName: "Colombo - Galle", Status: "cancelled"},
+	})
+
+	records, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "2026-02-03 06:30", records[1][1], "departure is shown in local time")
+	assert.Equal(t, "75.0", records[1][7])
+	assert.Equal(t, "0.0", records[2][7])
+}
+
+func TestUnsubscribeLink_JoinsBaseURLAndToken(t *testing.T) {
+	s := &ReportSubscriptionService{config: config.ReportConfig{UnsubscribeURL: "https://api.smarttransit.lk/api/v1/reports/unsubscribe/"}}
+	assert.Equal(t, "https://api.smarttransit.lk/api/v1/reports/unsubscribe/tok", s.UnsubscribeLink("tok"))
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+)
+
+// maxRetentionBatches bounds how many batches one table gets per run, so a large backlog is
+// worked off over several runs instead of one long one
+const maxRetentionBatches = 100
+
+// RetentionStats reports the retention job's progress, for /health
+type RetentionStats struct {
+	Enabled             bool       `json:"enabled"`
+	LastRunAt           *time.Time `json:"last_run_at,omitempty"`
+	LastRunDurationMs   int64      `json:"last_run_duration_ms"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastOTPsPurged      int64      `json:"last_otps_purged"`
+	LastLocationsPurged int64      `json:"last_location_points_purged"`
+	LastAuditAnonymized int64      `json:"last_audit_logs_anonymized"`
+	// Totals since the server started
+	OTPsPurged      int64 `json:"otps_purged"`
+	LocationsPurged int64 `json:"location_points_purged"`
+	AuditAnonymized int64 `json:"audit_logs_anonymized"`
+}
+
+// RetentionService runs the job that deletes OTP rows once they have been expired for the
+// retention window and trip location points older than theirs, and anonymizes audit log rows
+// older than theirs
+type RetentionService struct {
+	repo   *database.RetentionRepository
+	config config.RetentionConfig
+	logger *logrus.Logger
+	stopCh chan struct{}
+
+	mu    sync.Mutex
+	stats RetentionStats
+}
+
+// NewRetentionService creates a new RetentionService
+func NewRetentionService(repo *database.RetentionRepository, cfg config.RetentionConfig, logger *logrus.Logger) *RetentionService {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = time.Hour
+	}
+	if cfg.OTPRetention < 0 {
+		cfg.OTPRetention = 7 * 24 * time.Hour
+	}
+	if cfg.LocationHistory <= 0 {
+		cfg.LocationHistory = 90 * 24 * time.Hour
+	}
+	if cfg.AuditAnonymizeAfter <= 0 {
+		cfg.AuditAnonymizeAfter = 180 * 24 * time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	return &RetentionService{
+		repo:   repo,
+		config: cfg,
+		logger: logger,
+		stopCh: make(chan struct{}),
+		stats:  RetentionStats{Enabled: cfg.Enabled},
+	}
+}
+
+// Start begins the background retention job
+func (s *RetentionService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Data retention job disabled (RETENTION_ENABLED=false)")
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"interval":              s.config.CheckInterval.String(),
+		"otp_retention":         s.config.OTPRetention.String(),
+		"location_history":      s.config.LocationHistory.String(),
+		"audit_anonymize_after": s.config.AuditAnonymizeAfter.String(),
+	}).Info("🧹 Starting Data Retention job")
+	go s.run()
+}
+
+// Stop stops the background retention job
+func (s *RetentionService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Data Retention job")
+	close(s.stopCh)
+}
+
+func (s *RetentionService) run() {
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processRetention()
+		case <-s.stopCh:
+			s.logger.Info("Data Retention job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single retention cycle (useful for testing or manual trigger)
+func (s *RetentionService) RunOnce() {
+	s.processRetention()
+}
+
+// Stats returns the job's latest run and totals
+func (s *RetentionService) Stats() RetentionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+func (s *RetentionService) processRetention() {
+	start := time.Now()
+	var lastErr error
+
+	otps, err := s.inBatches(func() (int64, error) {
+		return s.repo.PurgeExpiredOTPs(start.Add(-s.config.OTPRetention), s.config.BatchSize)
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to purge expired OTPs")
+		lastErr = err
+	}
+
+	locations, err := s.inBatches(func() (int64, error) {
+		return s.repo.PurgeLocationHistory(start.Add(-s.config.LocationHistory), s.config.BatchSize)
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to purge trip location history")
+		lastErr = err
+	}
+
+	audits, err := s.inBatches(func() (int64, error) {
+		return s.repo.AnonymizeAuditLogs(start.Add(-s.config.AuditAnonymizeAfter), s.config.HashSalt, s.config.BatchSize)
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to anonymize audit logs")
+		lastErr = err
+	}
+
+	duration := time.Since(start)
+	s.mu.Lock()
+	s.stats.LastRunAt = &start
+	s.stats.LastRunDurationMs = duration.Milliseconds()
+	s.stats.LastError = ""
+	if lastErr != nil {
+		s.stats.LastError = lastErr.Error()
+	}
+	s.stats.LastOTPsPurged = otps
+	s.stats.LastLocationsPurged = locations
+	s.stats.LastAuditAnonymized = audits
+	s.stats.OTPsPurged += otps
+	s.stats.LocationsPurged += locations
+	s.stats.AuditAnonymized += audits
+	s.mu.Unlock()
+
+	if otps > 0 || locations > 0 || audits > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"otps_purged":            otps,
+			"location_points_purged": locations,
+			"audit_logs_anonymized":  audits,
+			"duration_ms":            duration.Milliseconds(),
+		}).Info("Data retention run completed")
+	}
+}
+
+// inBatches repeats a batch until it handles fewer rows than a full batch, returning the total
+func (s *RetentionService) inBatches(batch func() (int64, error)) (int64, error) {
+	var total int64
+	for i := 0; i < maxRetentionBatches; i++ {
+		n, err := batch()
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(s.config.BatchSize) {
+			break
+		}
+	}
+	return total, nil
+}
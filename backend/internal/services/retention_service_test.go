@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionService_InBatches(t *testing.T) {
+	s := NewRetentionService(nil, config.RetentionConfig{Enabled: true, BatchSize: 10}, logrus.New())
+
+	batches := []int64{10, 10, 4, 10}
+	calls := 0
+	total, err := s.inBatches(func() (int64, error) {
+		n := batches[calls]
+		calls++
+		return n, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(24), total, "stops after the first partial batch")
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	total, err = s.inBatches(func() (int64, error) {
+		calls++
+		return 10, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, maxRetentionBatches, calls, "a backlog is bounded per run")
+	assert.Equal(t, int64(10*maxRetentionBatches), total)
+
+	failure := errors.New("db down")
+	calls = 0
+	total, err = s.inBatches(func() (int64, error) {
+		calls++
+		if calls == 2 {
+			return 0, failure
+		}
+		return 10, nil
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, int64(10), total, "rows handled before the failure still count")
+}
+
+func TestRetentionService_Defaults(t *testing.T) {
+	s := NewRetentionService(nil, config.RetentionConfig{}, logrus.New())
+	assert.Equal(t, 1000, s.config.BatchSize)
+	assert.False(t, s.Stats().Enabled)
+	assert.Nil(t, s.Stats().LastRunAt)
+}
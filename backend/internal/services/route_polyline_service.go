@@ -0,0 +1,284 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/polyline"
+	"github.com/smarttransit/sms-auth-backend/pkg/routing"
+)
+
+var (
+	ErrPolylineRouteNotFound      = errors.New("route not found")
+	ErrPolylineNotSet             = errors.New("route has no polyline yet")
+	ErrPolylineGenerationDisabled = errors.New("polyline generation is not configured")
+	ErrPolylineStopsMissingCoords = errors.New("at least 2 route stops need coordinates to generate a polyline")
+	ErrPolylineNoRoadRoute        = errors.New("no road route found through the route's stops")
+	ErrPolylineRoutingFailed      = errors.New("routing engine request failed")
+)
+
+// RoutePolylineService stores route road geometry and serves it simplified for each map zoom.
+// Admins set master route polylines; bus owners may give their routes their own, and routes
+// without one are drawn with their master route's.
+type RoutePolylineService struct {
+	polylineRepo      *database.RoutePolylineRepository
+	masterRouteRepo   *database.MasterRouteRepository
+	busOwnerRouteRepo *database.BusOwnerRouteRepository
+	engine            routing.Engine // nil when no routing engine is configured
+	logger            *logrus.Logger
+}
+
+// NewRoutePolylineService creates a new RoutePolylineService
+func NewRoutePolylineService(
+	polylineRepo *database.RoutePolylineRepository,
+	masterRouteRepo *database.MasterRouteRepository,
+	busOwnerRouteRepo *database.BusOwnerRouteRepository,
+	engine routing.Engine,
+	logger *logrus.Logger,
+) *RoutePolylineService {
+	return &RoutePolylineService{
+		polylineRepo:      polylineRepo,
+		masterRouteRepo:   masterRouteRepo,
+		busOwnerRouteRepo: busOwnerRouteRepo,
+		engine:            engine,
+		logger:            logger,
+	}
+}
+
+// ============================================================================
+// MASTER ROUTES
+// ============================================================================
+
+// GetMasterRoutePolyline returns a master route's polyline at every zoom level
+func (s *RoutePolylineService) GetMasterRoutePolyline(masterRouteID string) (*models.RoutePolyline, error) {
+	route, err := s.getMasterRoute(masterRouteID)
+	if err != nil {
+		return nil, err
+	}
+	if !route.HasPolyline() {
+		return nil, ErrPolylineNotSet
+	}
+	return s.build(route.ID, models.RoutePolylineMasterRoute, route.ID, *route.EncodedPolyline)
+}
+
+// SetMasterRoutePolyline stores an uploaded polyline on a master route
+func (s *RoutePolylineService) SetMasterRoutePolyline(masterRouteID string, req *models.UpdateRoutePolylineRequest) (*models.RoutePolyline, error) {
+	encoded, err := req.Encoded()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getMasterRoute(masterRouteID); err != nil {
+		return nil, err
+	}
+	return s.saveMasterRoutePolyline(masterRouteID, encoded, "upload")
+}
+
+// GenerateMasterRoutePolyline snaps a master route's stops to roads and stores the result
+func (s *RoutePolylineService) GenerateMasterRoutePolyline(masterRouteID string) (*models.RoutePolyline, error) {
+	if s.engine == nil {
+		return nil, ErrPolylineGenerationDisabled
+	}
+	if _, err := s.getMasterRoute(masterRouteID); err != nil {
+		return nil, err
+	}
+	stops, err := s.masterRouteRepo.GetStopsByRouteID(masterRouteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route stops: %w", err)
+	}
+
+	waypoints := make([]polyline.Point, 0, len(stops))
+	for _, stop := range stops {
+		if stop.Latitude != nil && stop.Longitude != nil {
+			waypoints = append(waypoints, polyline.Point{Lat: *stop.Latitude, Lng: *stop.Longitude})
+		}
+	}
+	encoded, err := s.generate(waypoints)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveMasterRoutePolyline(masterRouteID, encoded, s.engine.Name())
+}
+
+func (s *RoutePolylineService) saveMasterRoutePolyline(masterRouteID, encoded, source string) (*models.RoutePolyline, error) {
+	found, err := s.polylineRepo.SetMasterRoutePolyline(masterRouteID, encoded)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrPolylineRouteNotFound
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"master_route_id": masterRouteID,
+		"source":          source,
+	}).Info("Master route polyline updated")
+	return s.build(masterRouteID, models.RoutePolylineMasterRoute, masterRouteID, encoded)
+}
+
+func (s *RoutePolylineService) getMasterRoute(masterRouteID string) (*models.MasterRoute, error) {
+	if _, err := uuid.Parse(masterRouteID); err != nil {
+		return nil, ErrPolylineRouteNotFound
+	}
+	route, err := s.masterRouteRepo.GetByID(masterRouteID)
+	if err == sql.ErrNoRows {
+		return nil, ErrPolylineRouteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get master route: %w", err)
+	}
+	return route, nil
+}
+
+// ============================================================================
+// BUS OWNER ROUTES
+// ============================================================================
+
+// GetBusOwnerRoutePolyline returns an owner's route polyline, falling back to its master route's
+func (s *RoutePolylineService) GetBusOwnerRoutePolyline(busOwnerRouteID, busOwnerID string) (*models.RoutePolyline, error) {
+	route, err := s.getBusOwnerRoute(busOwnerRouteID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := s.polylineRepo.GetBusOwnerRoutePolyline(route.ID)
+	if err != nil {
+		return nil, err
+	}
+	if encoded != nil {
+		return s.build(route.ID, models.RoutePolylineBusOwnerRoute, route.ID, *encoded)
+	}
+
+	masterRoute, err := s.getMasterRoute(route.MasterRouteID)
+	if err != nil {
+		return nil, err
+	}
+	if !masterRoute.HasPolyline() {
+		return nil, ErrPolylineNotSet
+	}
+	return s.build(route.ID, models.RoutePolylineBusOwnerRoute, masterRoute.ID, *masterRoute.EncodedPolyline)
+}
+
+// SetBusOwnerRoutePolyline stores an uploaded polyline on an owner's route
+func (s *RoutePolylineService) SetBusOwnerRoutePolyline(busOwnerRouteID, busOwnerID string, req *models.UpdateRoutePolylineRequest) (*models.RoutePolyline, error) {
+	encoded, err := req.Encoded()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getBusOwnerRoute(busOwnerRouteID, busOwnerID); err != nil {
+		return nil, err
+	}
+	return s.saveBusOwnerRoutePolyline(busOwnerRouteID, busOwnerID, encoded, "upload")
+}
+
+// GenerateBusOwnerRoutePolyline snaps the stops an owner's route serves to roads
+func (s *RoutePolylineService) GenerateBusOwnerRoutePolyline(busOwnerRouteID, busOwnerID string) (*models.RoutePolyline, error) {
+	if s.engine == nil {
+		return nil, ErrPolylineGenerationDisabled
+	}
+	route, err := s.getBusOwnerRoute(busOwnerRouteID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	stops, err := s.busOwnerRouteRepo.GetRouteStopsWithDetails(route.MasterRouteID, route.SelectedStopIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route stops: %w", err)
+	}
+
+	waypoints := make([]polyline.Point, 0, len(stops))
+	for _, stop := range stops {
+		if stop.Latitude != nil && stop.Longitude != nil {
+			waypoints = append(waypoints, polyline.Point{Lat: *stop.Latitude, Lng: *stop.Longitude})
+		}
+	}
+	// Stops are stored in master route order; DOWN routes run them in reverse
+	if route.Direction == "DOWN" {
+		for i, j := 0, len(waypoints)-1; i < j; i, j = i+1, j-1 {
+			waypoints[i], waypoints[j] = waypoints[j], waypoints[i]
+		}
+	}
+
+	encoded, err := s.generate(waypoints)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveBusOwnerRoutePolyline(route.ID, busOwnerID, encoded, s.engine.Name())
+}
+
+// ClearBusOwnerRoutePolyline removes an owner's route polyline so the master route's is used
+func (s *RoutePolylineService) ClearBusOwnerRoutePolyline(busOwnerRouteID, busOwnerID string) error {
+	if _, err := s.getBusOwnerRoute(busOwnerRouteID, busOwnerID); err != nil {
+		return err
+	}
+	found, err := s.polylineRepo.SetBusOwnerRoutePolyline(busOwnerRouteID, busOwnerID, nil)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrPolylineRouteNotFound
+	}
+	return nil
+}
+
+func (s *RoutePolylineService) saveBusOwnerRoutePolyline(busOwnerRouteID, busOwnerID, encoded, source string) (*models.RoutePolyline, error) {
+	found, err := s.polylineRepo.SetBusOwnerRoutePolyline(busOwnerRouteID, busOwnerID, &encoded)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrPolylineRouteNotFound
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"bus_owner_route_id": busOwnerRouteID,
+		"source":             source,
+	}).Info("Bus owner route polyline updated")
+	return s.build(busOwnerRouteID, models.RoutePolylineBusOwnerRoute, busOwnerRouteID, encoded)
+}
+
+func (s *RoutePolylineService) getBusOwnerRoute(busOwnerRouteID, busOwnerID string) (*models.BusOwnerRoute, error) {
+	if _, err := uuid.Parse(busOwnerRouteID); err != nil {
+		return nil, ErrPolylineRouteNotFound
+	}
+	route, err := s.busOwnerRouteRepo.GetByID(busOwnerRouteID)
+	if err == sql.ErrNoRows {
+		return nil, ErrPolylineRouteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bus owner route: %w", err)
+	}
+	if route.BusOwnerID != busOwnerID {
+		return nil, ErrPolylineRouteNotFound
+	}
+	return route, nil
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func (s *RoutePolylineService) generate(waypoints []polyline.Point) (string, error) {
+	if len(waypoints) < 2 {
+		return "", ErrPolylineStopsMissingCoords
+	}
+	route, err := s.engine.Route(waypoints)
+	if errors.Is(err, routing.ErrNoRoute) {
+		return "", ErrPolylineNoRoadRoute
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPolylineRoutingFailed, err)
+	}
+	return route.EncodedPolyline, nil
+}
+
+func (s *RoutePolylineService) build(routeID, routeType, sourceID, encoded string) (*models.RoutePolyline, error) {
+	result, err := models.NewRoutePolyline(routeID, routeType, sourceID, encoded)
+	if err != nil {
+		return nil, fmt.Errorf("stored polyline of route %s is invalid: %w", sourceID, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/polyline"
+	"github.com/smarttransit/sms-auth-backend/pkg/routing"
+)
+
+var (
+	ErrSegmentsRouteNotFound      = errors.New("route not found")
+	ErrSegmentsNotComputed        = errors.New("route segments have not been computed yet")
+	ErrSegmentsStopsMissingCoords = errors.New("at least 2 route stops need coordinates to compute segments")
+	ErrSegmentsEndpointsUnlocated = errors.New("the first and last stops need coordinates to apply segment estimates")
+	ErrSegmentsRoutingDisabled    = errors.New("road routing is not configured")
+	ErrSegmentsNoRoadRoute        = errors.New("no road route found through the route's stops")
+	ErrSegmentsRoutingFailed      = errors.New("routing engine request failed")
+)
+
+// RouteSegmentService computes road distances and driving times between a master route's stops
+// with the routing engine. Results are cached per route until a stop moves or they expire, and
+// can be applied as the stops' arrival offsets and the route's distance and duration, which
+// feed trip ETAs. Segment distances also price generated fare matrices.
+type RouteSegmentService struct {
+	segmentRepo     *database.RouteSegmentRepository
+	masterRouteRepo *database.MasterRouteRepository
+	engine          routing.Engine // nil when no routing engine is configured
+	config          config.RoutingConfig
+	logger          *logrus.Logger
+}
+
+// NewRouteSegmentService creates a new RouteSegmentService
+func NewRouteSegmentService(
+	segmentRepo *database.RouteSegmentRepository,
+	masterRouteRepo *database.MasterRouteRepository,
+	engine routing.Engine,
+	cfg config.RoutingConfig,
+	logger *logrus.Logger,
+) *RouteSegmentService {
+	if cfg.BusDurationPercent <= 0 {
+		cfg.BusDurationPercent = 100
+	}
+	return &RouteSegmentService{
+		segmentRepo:     segmentRepo,
+		masterRouteRepo: masterRouteRepo,
+		engine:          engine,
+		config:          cfg,
+		logger:          logger,
+	}
+}
+
+// GetSegments returns a master route's last computed segments
+func (s *RouteSegmentService) GetSegments(masterRouteID string) (*models.RouteSegments, error) {
+	stops, err := s.getStops(masterRouteID)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := s.segmentRepo.GetSegments(masterRouteID)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, ErrSegmentsNotComputed
+	}
+	return s.result(masterRouteID, stops, segments, true), nil
+}
+
+// ComputeSegments measures the route between each pair of consecutive located stops, reusing
+// cached results while they are still valid, and optionally applies them to the route
+func (s *RouteSegmentService) ComputeSegments(masterRouteID string, req *models.ComputeRouteSegmentsRequest) (*models.RouteSegments, error) {
+	stops, err := s.getStops(masterRouteID)
+	if err != nil {
+		return nil, err
+	}
+	located := locatedStops(stops)
+	if len(located) < 2 {
+		return nil, ErrSegmentsStopsMissingCoords
+	}
+	if req.Apply && (!isLocated(stops[0]) || !isLocated(stops[len(stops)-1])) {
+		return nil, ErrSegmentsEndpointsUnlocated
+	}
+
+	cached, err := s.segmentRepo.GetSegments(masterRouteID)
+	if err != nil {
+		return nil, err
+	}
+
+	engineName := "" // Without an engine, any still-valid cached results are served
+	if s.engine != nil {
+		engineName = s.engine.Name()
+	}
+
+	var result *models.RouteSegments
+	if !req.Refresh && segmentsFresh(cached, located, engineName, time.Now(), s.config.SegmentCacheTTL) {
+		result = s.result(masterRouteID, stops, cached, true)
+	} else {
+		segments, err := s.compute(masterRouteID, located)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.segmentRepo.ReplaceSegments(masterRouteID, segments); err != nil {
+			return nil, err
+		}
+		result = s.result(masterRouteID, stops, segments, false)
+	}
+
+	if req.Apply {
+		if err := s.apply(result); err != nil {
+			return nil, err
+		}
+		result.Applied = true
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"master_route_id":   masterRouteID,
+		"engine":            result.Engine,
+		"cached":            result.Cached,
+		"applied":           result.Applied,
+		"total_km":          result.TotalDistanceKm,
+		"total_bus_minutes": result.TotalDurationMinutes,
+	}).Info("Route segments computed")
+	return result, nil
+}
+
+// GetFareMatrix prices every forward journey between the route's located stops from the
+// computed segment distances
+func (s *RouteSegmentService) GetFareMatrix(masterRouteID string, params *models.FareMatrixParams) (*models.FareMatrix, error) {
+	segments, err := s.GetSegments(masterRouteID)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildFareMatrix(masterRouteID, segments.Segments, params), nil
+}
+
+func (s *RouteSegmentService) compute(masterRouteID string, located []models.MasterRouteStop) ([]models.RouteSegment, error) {
+	if s.engine == nil {
+		return nil, ErrSegmentsRoutingDisabled
+	}
+
+	waypoints := make([]polyline.Point, len(located))
+	for i, stop := range located {
+		waypoints[i] = polyline.Point{Lat: *stop.Latitude, Lng: *stop.Longitude}
+	}
+	route, err := s.engine.Route(waypoints)
+	if errors.Is(err, routing.ErrNoRoute) {
+		return nil, ErrSegmentsNoRoadRoute
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSegmentsRoutingFailed, err)
+	}
+	if len(route.Legs) != len(located)-1 {
+		return nil, fmt.Errorf("%w: expected %d legs, got %d", ErrSegmentsRoutingFailed, len(located)-1, len(route.Legs))
+	}
+
+	now := time.Now()
+	segments := make([]models.RouteSegment, len(route.Legs))
+	for i, leg := range route.Legs {
+		from, to := located[i], located[i+1]
+		segments[i] = models.RouteSegment{
+			MasterRouteID:   masterRouteID,
+			FromStopID:      from.ID,
+			ToStopID:        to.ID,
+			FromLatitude:    *from.Latitude,
+			FromLongitude:   *from.Longitude,
+			ToLatitude:      *to.Latitude,
+			ToLongitude:     *to.Longitude,
+			DistanceMeters:  leg.DistanceMeters,
+			DurationSeconds: leg.DurationSeconds,
+			Engine:          s.engine.Name(),
+			ComputedAt:      now,
+		}
+	}
+	return segments, nil
+}
+
+// apply writes the stops' arrival offsets (minutes from the first stop at bus speed) and the
+// route's total distance and duration
+func (s *RouteSegmentService) apply(result *models.RouteSegments) error {
+	offsets := map[string]int{}
+	elapsed := 0.0
+	if len(result.Segments) > 0 {
+		offsets[result.Segments[0].FromStopID] = 0
+	}
+	for _, segment := range result.Segments {
+		elapsed += segment.BusDurationMinutes
+		offsets[segment.ToStopID] = int(math.Round(elapsed))
+	}
+	return s.segmentRepo.ApplyEstimates(result.MasterRouteID, offsets, result.TotalDistanceKm, int(math.Round(result.TotalDurationMinutes)))
+}
+
+func (s *RouteSegmentService) result(masterRouteID string, stops []models.MasterRouteStop, segments []models.RouteSegment, cached bool) *models.RouteSegments {
+	names := make(map[string]string, len(stops))
+	skipped := []string{}
+	for _, stop := range stops {
+		names[stop.ID] = stop.StopName
+		if !isLocated(stop) {
+			skipped = append(skipped, stop.ID)
+		}
+	}
+
+	result := &models.RouteSegments{
+		MasterRouteID:  masterRouteID,
+		Cached:         cached,
+		SkippedStopIDs: skipped,
+		Segments:       segments,
+	}
+	distance, duration := 0.0, 0.0
+	for i := range result.Segments {
+		segment := &result.Segments[i]
+		segment.FromStopName = names[segment.FromStopID]
+		segment.ToStopName = names[segment.ToStopID]
+		segment.BusDurationMinutes = math.Round(segment.DurationSeconds/60*float64(s.config.BusDurationPercent)/100*10) / 10
+		distance += segment.DistanceMeters
+		duration += segment.BusDurationMinutes
+		result.Engine = segment.Engine
+	}
+	result.TotalDistanceKm = math.Round(distance/1000*100) / 100
+	result.TotalDurationMinutes = math.Round(duration*10) / 10
+	return result
+}
+
+func (s *RouteSegmentService) getStops(masterRouteID string) ([]models.MasterRouteStop, error) {
+	if _, err := uuid.Parse(masterRouteID); err != nil {
+		return nil, ErrSegmentsRouteNotFound
+	}
+	if _, err := s.masterRouteRepo.GetByID(masterRouteID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSegmentsRouteNotFound
+		}
+		return nil, fmt.Errorf("failed to get master route: %w", err)
+	}
+	stops, err := s.masterRouteRepo.GetStopsByRouteID(masterRouteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route stops: %w", err)
+	}
+	return stops, nil
+}
+
+func isLocated(stop models.MasterRouteStop) bool {
+	return stop.Latitude != nil && stop.Longitude != nil
+}
+
+func locatedStops(stops []models.MasterRouteStop) []models.MasterRouteStop {
+	located := make([]models.MasterRouteStop, 0, len(stops))
+	for _, stop := range stops {
+		if isLocated(stop) {
+			located = append(located, stop)
+		}
+	}
+	return located
+}
+
+// segmentsFresh reports whether cached segments still describe the located stops: the same
+// stops in the same order at the same coordinates, not yet expired, and from engine unless
+// it is empty
+func segmentsFresh(cached []models.RouteSegment, located []models.MasterRouteStop, engine string, now time.Time, ttl time.Duration) bool {
+	if len(cached) == 0 || len(cached) != len(located)-1 {
+		return false
+	}
+	for i, segment := range cached {
+		from, to := located[i], located[i+1]
+		if (engine != "" && segment.Engine != engine) || now.Sub(segment.ComputedAt) > ttl ||
+			segment.FromStopID != from.ID || segment.ToStopID != to.ID ||
+			segment.FromLatitude != *from.Latitude || segment.FromLongitude != *from.Longitude ||
+			segment.ToLatitude != *to.Latitude || segment.ToLongitude != *to.Longitude {
+			return false
+		}
+	}
+	return true
+}
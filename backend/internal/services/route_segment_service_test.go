@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func segmentTestStops() []models.MasterRouteStop {
+	lat := func(v float64) *float64 { return &v }
+	return []models.MasterRouteStop{
+		{ID: "colombo", StopName: "Colombo", Latitude: lat(6.9271), Longitude: lat(79.8612)},
+		{ID: "unmapped", StopName: "Unmapped halt"},
+		{ID: "kandy", StopName: "Kandy", Latitude: lat(7.2906), Longitude: lat(80.6337)},
+	}
+}
+
+func TestSegmentsFresh(t *testing.T) {
+	now := time.Now()
+	located := locatedStops(segmentTestStops())
+	cached := []models.RouteSegment{{
+		FromStopID: "colombo", ToStopID: "kandy",
+		FromLatitude: 6.9271, FromLongitude: 79.8612, ToLatitude: 7.2906, ToLongitude: 80.6337,
+		Engine: "osrm", ComputedAt: now.Add(-24 * time.Hour),
+	}}
+	ttl := 30 * 24 * time.Hour
+
+	assert.True(t, segmentsFresh(cached, located, "osrm", now, ttl))
+	assert.True(t, segmentsFresh(cached, located, "", now, ttl), "any engine when none is configured")
+	assert.False(t, segmentsFresh(cached, located, "google", now, ttl), "engine changed")
+	assert.False(t, segmentsFresh(cached, located, "osrm", now.Add(30*24*time.Hour), ttl), "expired")
+	assert.False(t, segmentsFresh(nil, located, "osrm", now, ttl))
+
+	moved := append([]models.RouteSegment(nil), cached...)
+	moved[0].ToLatitude = 7.3
+	assert.False(t, segmentsFresh(moved, located, "osrm", now, ttl), "stop moved")
+}
+
+func TestRouteSegmentService_ResultScalesToBusSpeed(t *testing.T) {
+	svc := NewRouteSegmentService(nil, nil, nil, config.RoutingConfig{BusDurationPercent: 150}, logrus.New())
+	segments := []models.RouteSegment{
+		{FromStopID: "colombo", ToStopID: "kandy", DistanceMeters: 115230, DurationSeconds: 7200, Engine: "osrm"},
+	}
+
+	result := svc.result("route-1", segmentTestStops(), segments, false)
+	assert.Equal(t, "osrm", result.Engine)
+	assert.Equal(t, 115.23, result.TotalDistanceKm)
+	assert.Equal(t, 180.0, result.TotalDurationMinutes) // 2 h by car, 3 h by bus
+	assert.Equal(t, "Colombo", result.Segments[0].FromStopName)
+	assert.Equal(t, "Kandy", result.Segments[0].ToStopName)
+	assert.Equal(t, []string{"unmapped"}, result.SkippedStopIDs)
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// SearchCache holds recent trip search results keyed by origin stop, destination stop, date and
+// a hash of the other filters. Entries are indexed by the master route (corridor) the stops are
+// on, so publishing, unpublishing or cancelling a trip drops every search it could appear in.
+// A nil cache caches nothing.
+type SearchCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]searchCacheEntry
+	routes  map[string]map[string]bool // Master route ID -> cache keys
+	stats   models.SearchCacheStats
+}
+
+type searchCacheEntry struct {
+	routeID   string
+	trips     []models.TripResult
+	expiresAt time.Time
+}
+
+// NewSearchCache creates a new SearchCache, or nil when caching is disabled
+func NewSearchCache(cfg config.SearchConfig) *SearchCache {
+	if !cfg.CacheEnabled || cfg.CacheTTL <= 0 {
+		return nil
+	}
+	if cfg.CacheMaxEntries <= 0 {
+		cfg.CacheMaxEntries = 5000
+	}
+	return &SearchCache{
+		ttl:        cfg.CacheTTL,
+		maxEntries: cfg.CacheMaxEntries,
+		entries:    make(map[string]searchCacheEntry),
+		routes:     make(map[string]map[string]bool),
+	}
+}
+
+// searchCacheKey builds the key for a search between two stops. The departure time is reduced
+// to its date in the key and to the minute in the filters hash, with the result limit and tenant.
+func searchCacheKey(fromStopID, toStopID string, after time.Time, limit int, tenantID *string) string {
+	tenant := ""
+	if tenantID != nil {
+		tenant = *tenantID
+	}
+	filters := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s", after.Truncate(time.Minute).UTC().Format(time.RFC3339), limit, tenant)))
+	date := after.In(models.ReportTimezone).Format("2006-01-02")
+	return fmt.Sprintf("%s|%s|%s|%s", fromStopID, toStopID, date, hex.EncodeToString(filters[:8]))
+}
+
+// Get returns a copy of the cached trips for the key
+func (c *SearchCache) Get(key string, now time.Time) ([]models.TripResult, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		if ok {
+			c.remove(key, entry.routeID)
+		}
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	return append([]models.TripResult(nil), entry.trips...), true
+}
+
+// Set caches the trips found for the key on the given master route
+func (c *SearchCache) Set(key, routeID string, trips []models.TripResult, now time.Time) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = searchCacheEntry{
+		routeID:   routeID,
+		trips:     append([]models.TripResult(nil), trips...),
+		expiresAt: now.Add(c.ttl),
+	}
+	if c.routes[routeID] == nil {
+		c.routes[routeID] = make(map[string]bool)
+	}
+	c.routes[routeID][key] = true
+}
+
+// InvalidateRoutes drops every cached search on the given master routes, returning how many
+func (c *SearchCache) InvalidateRoutes(routeIDs ...string) int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dropped := 0
+	for _, routeID := range routeIDs {
+		for key := range c.routes[routeID] {
+			delete(c.entries, key)
+			dropped++
+		}
+		delete(c.routes, routeID)
+	}
+	c.stats.Invalidations += int64(dropped)
+	return dropped
+}
+
+// Stats returns the cache's hit-rate counters
+func (c *SearchCache) Stats() models.SearchCacheStats {
+	if c == nil {
+		return models.SearchCacheStats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Enabled = true
+	stats.TTLSeconds = int(c.ttl.Seconds())
+	stats.Entries = len(c.entries)
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = math.Round(float64(stats.Hits)/float64(total)*10000) / 10000
+	}
+	return stats
+}
+
+// evict makes room for one entry: expired entries go first, then the one expiring soonest
+func (c *SearchCache) evict(now time.Time) {
+	var soonestKey string
+	var soonest searchCacheEntry
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			c.remove(key, entry.routeID)
+			c.stats.Evictions++
+			continue
+		}
+		if soonestKey == "" || entry.expiresAt.Before(soonest.expiresAt) {
+			soonestKey, soonest = key, entry
+		}
+	}
+	if len(c.entries) >= c.maxEntries && soonestKey != "" {
+		c.remove(soonestKey, soonest.routeID)
+		c.stats.Evictions++
+	}
+}
+
+func (c *SearchCache) remove(key, routeID string) {
+	delete(c.entries, key)
+	if keys := c.routes[routeID]; keys != nil {
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.routes, routeID)
+		}
+	}
+}
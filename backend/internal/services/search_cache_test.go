@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchCacheKey(t *testing.T) {
+	at := time.Date(2026, 10, 14, 8, 30, 15, 0, models.ReportTimezone)
+	tenant := "tenant-1"
+
+	key := searchCacheKey("from", "to", at, 20, nil)
+	assert.Contains(t, key, "from|to|2026-10-14|")
+	assert.Equal(t, key, searchCacheKey("from", "to", at.Add(30*time.Second), 20, nil), "same minute shares a key")
+	assert.NotEqual(t, key, searchCacheKey("from", "to", at.Add(time.Minute), 20, nil))
+	assert.NotEqual(t, key, searchCacheKey("from", "to", at, 10, nil))
+	assert.NotEqual(t, key, searchCacheKey("from", "to", at, 20, &tenant))
+	assert.NotEqual(t, key, searchCacheKey("to", "from", at, 20, nil))
+}
+
+func TestSearchCache_GetSetExpire(t *testing.T) {
+	cache := NewSearchCache(config.SearchConfig{CacheEnabled: true, CacheTTL: time.Minute, CacheMaxEntries: 10})
+	now := time.Now()
+	trips := []models.TripResult{{TripID: uuid.New(), Fare: 500}}
+
+	_, ok := cache.Get("k", now)
+	assert.False(t, ok)
+
+	cache.Set("k", "route-1", trips, now)
+	got, ok := cache.Get("k", now.Add(30*time.Second))
+	require.True(t, ok)
+	assert.Equal(t, trips, got)
+
+	got[0].Fare = 900
+	again, _ := cache.Get("k", now)
+	assert.Equal(t, 500.0, again[0].Fare, "callers get their own copy")
+
+	_, ok = cache.Get("k", now.Add(time.Minute))
+	assert.False(t, ok, "expired")
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, 0.5, stats.HitRate)
+	assert.Equal(t, 0, stats.Entries)
+}
+
+func TestSearchCache_InvalidateRoutes(t *testing.T) {
+	cache := NewSearchCache(config.SearchConfig{CacheEnabled: true, CacheTTL: time.Minute, CacheMaxEntries: 10})
+	now := time.Now()
+	cache.Set("a", "route-1", nil, now)
+	cache.Set("b", "route-1", nil, now)
+	cache.Set("c", "route-2", nil, now)
+
+	assert.Equal(t, 2, cache.InvalidateRoutes("route-1", "route-3"))
+	_, ok := cache.Get("a", now)
+	assert.False(t, ok)
+	_, ok = cache.Get("c", now)
+	assert.True(t, ok, "other corridors keep their searches")
+	assert.Equal(t, int64(2), cache.Stats().Invalidations)
+}
+
+func TestSearchCache_Eviction(t *testing.T) {
+	cache := NewSearchCache(config.SearchConfig{CacheEnabled: true, CacheTTL: time.Minute, CacheMaxEntries: 2})
+	now := time.Now()
+	cache.Set("old", "r", nil, now)
+	cache.Set("mid", "r", nil, now.Add(time.Second))
+	cache.Set("new", "r", nil, now.Add(2*time.Second))
+
+	_, ok := cache.Get("old", now.Add(2*time.Second))
+	assert.False(t, ok, "the entry expiring soonest makes room")
+	assert.Equal(t, 2, cache.Stats().Entries)
+	assert.Equal(t, int64(1), cache.Stats().Evictions)
+}
+
+func TestSearchCache_Disabled(t *testing.T) {
+	cache := NewSearchCache(config.SearchConfig{CacheEnabled: false, CacheTTL: time.Minute})
+	assert.Nil(t, cache)
+
+	cache.Set("k", "r", nil, time.Now())
+	_, ok := cache.Get("k", time.Now())
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.InvalidateRoutes("r"))
+	assert.False(t, cache.Stats().Enabled)
+}
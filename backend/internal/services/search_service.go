@@ -12,15 +12,19 @@ import (
 
 // SearchService handles business logic for trip search
 type SearchService struct {
-	repo   *database.SearchRepository
-	logger *logrus.Logger
+	repo             *database.SearchRepository
+	priceAdjustments *TripPriceAdjustmentService
+	cache            *SearchCache
+	logger           *logrus.Logger
 }
 
-// NewSearchService creates a new search service
-func NewSearchService(repo *database.SearchRepository, logger *logrus.Logger) *SearchService {
+// NewSearchService creates a new search service. cache may be nil to disable result caching.
+func NewSearchService(repo *database.SearchRepository, priceAdjustments *TripPriceAdjustmentService, cache *SearchCache, logger *logrus.Logger) *SearchService {
 	return &SearchService{
-		repo:   repo,
-		logger: logger,
+		repo:             repo,
+		priceAdjustments: priceAdjustments,
+		cache:            cache,
+		logger:           logger,
 	}
 }
 
@@ -29,6 +33,7 @@ func (s *SearchService) SearchTrips(
 	req *models.SearchRequest,
 	userID *uuid.UUID,
 	ipAddress string,
+	tenant *models.Tenant,
 ) (*models.SearchResponse, error) {
 	startTime := time.Now()
 
@@ -107,41 +112,17 @@ func (s *SearchService) SearchTrips(
 	// Step 2: Get search datetime (default to now if not provided)
 	searchTime := req.GetSearchDateTime()
 
-	// Step 3: Find available trips
-	s.logger.WithFields(logrus.Fields{
-		"from_stop_id": stopPair.FromID.String(),
-		"to_stop_id":   stopPair.ToID.String(),
-		"search_time":  searchTime,
-		"limit":        req.Limit,
-	}).Info("Querying database for trips...")
-
-	trips, err := s.repo.FindDirectTrips(stopPair.FromID, stopPair.ToID, searchTime, req.Limit)
+	// Step 3: Find available trips with their route stops, from the cache when an identical
+	// search ran recently
+	trips, err := s.findTrips(stopPair, searchTime, req.Limit, tenant.ScopeID())
 	if err != nil {
-		s.logger.WithError(err).Error("Error finding trips from database")
-		return nil, fmt.Errorf("error searching for trips: %w", err)
+		return nil, err
 	}
 
-	s.logger.WithField("trips_found", len(trips)).Info("Database query completed successfully")
-
-	// Step 4: Fetch route stops for each trip (for passenger to select boarding/alighting)
-	for i := range trips {
-		// Debug: Log master_route_id for each trip
-		if trips[i].MasterRouteID != nil {
-			s.logger.WithFields(logrus.Fields{
-				"trip_id":         trips[i].TripID,
-				"master_route_id": *trips[i].MasterRouteID,
-			}).Info("Trip has master_route_id")
-			stops, err := s.repo.GetRouteStopsForTrip(*trips[i].MasterRouteID, trips[i].BusOwnerRouteID)
-			if err != nil {
-				s.logger.WithError(err).WithField("trip_id", trips[i].TripID).Warn("Failed to fetch route stops for trip")
-				// Continue without stops - not a fatal error
-			} else {
-				trips[i].RouteStops = stops
-			}
-		} else {
-			s.logger.WithField("trip_id", trips[i].TripID).Warn("Trip has NULL master_route_id!")
-		}
-	}
+	// Step 4: Seat availability for the searched segment rather than the whole trip, and
+	// prices; both are always read live
+	s.applySegmentAvailability(trips, stopPair.FromOrder, stopPair.ToOrder)
+	s.applyPriceAdjustments(trips)
 
 	response.Results = trips
 
@@ -182,6 +163,119 @@ func (s *SearchService) SearchTrips(
 	return response, nil
 }
 
+// findTrips finds the trips between the stop pair and fetches each trip's route stops (for the
+// passenger to select boarding/alighting). Results are cached under the search minute, so a hit
+// may include a trip that departed earlier in that minute.
+func (s *SearchService) findTrips(stopPair *database.StopPairResult, searchTime time.Time, limit int, tenantID *string) ([]models.TripResult, error) {
+	now := time.Now()
+	key := searchCacheKey(stopPair.FromID.String(), stopPair.ToID.String(), searchTime, limit, tenantID)
+	if trips, ok := s.cache.Get(key, now); ok {
+		s.logger.WithField("trips_found", len(trips)).Info("Search served from cache")
+		return trips, nil
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"from_stop_id": stopPair.FromID.String(),
+		"to_stop_id":   stopPair.ToID.String(),
+		"search_time":  searchTime,
+		"limit":        limit,
+	}).Info("Querying database for trips...")
+
+	trips, err := s.repo.FindDirectTrips(stopPair.FromID, stopPair.ToID, searchTime, limit, tenantID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error finding trips from database")
+		return nil, fmt.Errorf("error searching for trips: %w", err)
+	}
+
+	s.logger.WithField("trips_found", len(trips)).Info("Database query completed successfully")
+
+	for i := range trips {
+		// Debug: Log master_route_id for each trip
+		if trips[i].MasterRouteID != nil {
+			s.logger.WithFields(logrus.Fields{
+				"trip_id":         trips[i].TripID,
+				"master_route_id": *trips[i].MasterRouteID,
+			}).Info("Trip has master_route_id")
+			stops, err := s.repo.GetRouteStopsForTrip(*trips[i].MasterRouteID, trips[i].BusOwnerRouteID)
+			if err != nil {
+				s.logger.WithError(err).WithField("trip_id", trips[i].TripID).Warn("Failed to fetch route stops for trip")
+				// Continue without stops - not a fatal error
+			} else {
+				trips[i].RouteStops = stops
+			}
+		} else {
+			s.logger.WithField("trip_id", trips[i].TripID).Warn("Trip has NULL master_route_id!")
+		}
+	}
+
+	s.cache.Set(key, stopPair.RouteID.String(), trips, now)
+	return trips, nil
+}
+
+// CacheStats returns the search cache's hit-rate counters
+func (s *SearchService) CacheStats() models.SearchCacheStats {
+	return s.cache.Stats()
+}
+
+// applySegmentAvailability fills in each trip's bookable seats and cheapest seat price between
+// the searched stops. Failures are logged and leave the fields empty.
+func (s *SearchService) applySegmentAvailability(trips []models.TripResult, fromOrder, toOrder int) {
+	if len(trips) == 0 {
+		return
+	}
+
+	tripIDs := make([]string, len(trips))
+	for i := range trips {
+		tripIDs[i] = trips[i].TripID.String()
+	}
+	seats, err := s.repo.GetTripSeatOccupancy(tripIDs)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to get segment seat availability")
+		return
+	}
+
+	availability := models.SummarizeSegmentAvailability(seats, fromOrder, toOrder)
+	for i := range trips {
+		if a, ok := availability[trips[i].TripID.String()]; ok {
+			count := a.AvailableSeats
+			trips[i].AvailableSeats = &count
+			trips[i].CheapestSeatPrice = a.CheapestSeatPrice
+		}
+	}
+}
+
+// applyPriceAdjustments prices each trip's fares with the owner's active surcharge or discount.
+// Failures are logged and leave the usual fares.
+func (s *SearchService) applyPriceAdjustments(trips []models.TripResult) {
+	if len(trips) == 0 {
+		return
+	}
+
+	tripIDs := make([]string, len(trips))
+	for i := range trips {
+		tripIDs[i] = trips[i].TripID.String()
+	}
+	adjustments, err := s.priceAdjustments.ActiveForTrips(tripIDs)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to get trip price adjustments")
+		return
+	}
+
+	for i := range trips {
+		adjustment, ok := adjustments[trips[i].TripID.String()]
+		if !ok {
+			continue
+		}
+		fare := adjustment.Apply(trips[i].Fare)
+		trips[i].PriceAdjustment = adjustment.Applied(fare - trips[i].Fare)
+		trips[i].Fare = fare
+		if trips[i].CheapestSeatPrice != nil {
+			cheapest := adjustment.Apply(*trips[i].CheapestSeatPrice)
+			trips[i].CheapestSeatPrice = &cheapest
+		}
+	}
+}
+
 // GetPopularRoutes returns popular routes for quick selection
 func (s *SearchService) GetPopularRoutes(limit int) ([]models.PopularRoute, error) {
 	if limit <= 0 {
@@ -260,6 +354,8 @@ func (s *SearchService) logSearch(
 		ResponseTimeMs: responseTime.Milliseconds(),
 		UserID:         userID,
 		IPAddress:      ipAddress,
+
+		RequestedDepartureAt: req.DateTime,
 	}
 
 	// Add stop IDs if matched
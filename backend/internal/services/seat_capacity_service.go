@@ -0,0 +1,61 @@
+package services
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// SeatCapacityService stops a seat layout from offering more seats than the bus is registered
+// for or the route permit approves. It is checked when a layout is assigned to a bus or trip and
+// again when trips are published, since the bus or permit may have changed in between.
+type SeatCapacityService struct {
+	repo   *database.SeatCapacityRepository
+	logger *logrus.Logger
+}
+
+// NewSeatCapacityService creates a new SeatCapacityService
+func NewSeatCapacityService(repo *database.SeatCapacityRepository, logger *logrus.Logger) *SeatCapacityService {
+	return &SeatCapacityService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CheckTrips returns a SeatCapacityError listing the trips whose seat layout is over capacity.
+// layoutID, when set, is checked in place of the trips' own layout, before it is assigned.
+func (s *SeatCapacityService) CheckTrips(tripIDs []string, layoutID *string) error {
+	checks, err := s.repo.GetTripChecks(tripIDs, layoutID)
+	if err != nil {
+		return err
+	}
+	return s.result(models.NewSeatCapacityError(checks))
+}
+
+// CheckBus returns a SeatCapacityError if the bus's layout has more seats than its registered
+// capacity or its permit's approved capacity. Buses without a layout pass.
+func (s *SeatCapacityService) CheckBus(bus *models.Bus) error {
+	if bus.SeatLayoutID == nil || *bus.SeatLayoutID == "" {
+		return nil
+	}
+	check, err := s.repo.GetLayoutCheck(*bus.SeatLayoutID, bus.PermitID)
+	if err != nil {
+		return err
+	}
+	if check == nil {
+		return nil // Unknown layouts are reported by the assignment itself
+	}
+	check.BusID = &bus.ID
+	if bus.LicensePlate != "" {
+		check.BusLicensePlate = &bus.LicensePlate
+	}
+	check.BusSeatingCapacity = bus.SeatingCapacity
+	return s.result(models.NewSeatCapacityError([]models.SeatCapacityCheck{*check}))
+}
+
+func (s *SeatCapacityService) result(err error) error {
+	if err != nil {
+		s.logger.WithField("reason", err.Error()).Info("Seat layout rejected: over capacity")
+	}
+	return err
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrPricingRuleNotFound       = errors.New("pricing rule not found")
+	ErrPricingRuleTargetNotFound = errors.New("route or schedule not found or access denied")
+	ErrPricingTripNotFound       = errors.New("trip not found, has no seats or is no longer open for booking")
+)
+
+// SeatPricingService manages owners' dynamic pricing rules and keeps trip seat prices in line
+// with them. Seats are priced when they are created and a background job re-evaluates upcoming
+// trips, since time to departure and occupancy change. Only available seats the owner has not
+// priced by hand are repriced; trip surcharges and discounts still apply on top.
+type SeatPricingService struct {
+	repo         *database.SeatPricingRuleRepository
+	routeRepo    *database.BusOwnerRouteRepository
+	scheduleRepo *database.TripScheduleRepository
+	config       config.SeatPricingConfig
+	logger       *logrus.Logger
+	stopCh       chan struct{}
+}
+
+// NewSeatPricingService creates a new SeatPricingService
+func NewSeatPricingService(
+	repo *database.SeatPricingRuleRepository,
+	routeRepo *database.BusOwnerRouteRepository,
+	scheduleRepo *database.TripScheduleRepository,
+	cfg config.SeatPricingConfig,
+	logger *logrus.Logger,
+) *SeatPricingService {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 30 * time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 200
+	}
+	return &SeatPricingService{
+		repo:         repo,
+		routeRepo:    routeRepo,
+		scheduleRepo: scheduleRepo,
+		config:       cfg,
+		logger:       logger,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// OWNER RULES
+// ============================================================================
+
+// ListRules returns the owner's rules, optionally only those of one route or schedule
+func (s *SeatPricingService) ListRules(busOwnerID string, routeID, scheduleID *string) ([]models.SeatPricingRule, error) {
+	return s.repo.ListByOwner(busOwnerID, nonEmpty(routeID), nonEmpty(scheduleID))
+}
+
+// CreateRule adds a rule to one of the owner's routes or schedules and reprices its trips
+func (s *SeatPricingService) CreateRule(busOwnerID string, req *models.CreateSeatPricingRuleRequest) (*models.SeatPricingRule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkTargetOwner(busOwnerID, nonEmpty(req.BusOwnerRouteID), nonEmpty(req.TripScheduleID)); err != nil {
+		return nil, err
+	}
+
+	rule := &models.SeatPricingRule{
+		BusOwnerID:      busOwnerID,
+		BusOwnerRouteID: nonEmpty(req.BusOwnerRouteID),
+		TripScheduleID:  nonEmpty(req.TripScheduleID),
+	}
+	req.SeatPricingRuleFields.ApplyTo(rule)
+	if err := s.repo.Create(rule); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"rule_id":            rule.ID,
+		"bus_owner_id":       busOwnerID,
+		"bus_owner_route_id": rule.BusOwnerRouteID,
+		"trip_schedule_id":   rule.TripScheduleID,
+		"rule_type":          rule.RuleType,
+		"multiplier":         rule.Multiplier,
+	}).Info("Seat pricing rule created")
+	s.repriceTarget(rule)
+	return rule, nil
+}
+
+// UpdateRule replaces the condition and multiplier of one of the owner's rules and reprices its trips
+func (s *SeatPricingService) UpdateRule(busOwnerID, ruleID string, req *models.SeatPricingRuleFields) (*models.SeatPricingRule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	rule, err := s.getOwnerRule(busOwnerID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	req.ApplyTo(rule)
+	if err := s.repo.Update(rule); err != nil {
+		return nil, err
+	}
+	s.repriceTarget(rule)
+	return rule, nil
+}
+
+// DeleteRule removes one of the owner's rules; its trips go back to the remaining rules' price
+func (s *SeatPricingService) DeleteRule(busOwnerID, ruleID string) error {
+	rule, err := s.getOwnerRule(busOwnerID, ruleID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(rule.ID); err != nil {
+		return err
+	}
+	s.repriceTarget(rule)
+	return nil
+}
+
+// PreviewTrip evaluates the rules for one of the owner's trips without changing its seats
+func (s *SeatPricingService) PreviewTrip(busOwnerID, tripID string) (*models.SeatPriceEvaluation, error) {
+	trip, err := s.repo.GetPricingTrip(tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil || trip.BusOwnerID == nil || *trip.BusOwnerID != busOwnerID {
+		return nil, ErrPricingTripNotFound
+	}
+	rules, err := s.repo.ListForTrip(trip)
+	if err != nil {
+		return nil, err
+	}
+	return models.EvaluateSeatPricing(rules, trip.BaseFare, trip.FareCap, trip.Context(time.Now())), nil
+}
+
+func (s *SeatPricingService) getOwnerRule(busOwnerID, ruleID string) (*models.SeatPricingRule, error) {
+	rule, err := s.repo.GetByID(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil || rule.BusOwnerID != busOwnerID {
+		return nil, ErrPricingRuleNotFound
+	}
+	return rule, nil
+}
+
+func (s *SeatPricingService) checkTargetOwner(busOwnerID string, routeID, scheduleID *string) error {
+	var ownerID string
+	if routeID != nil {
+		route, err := s.routeRepo.GetByID(*routeID)
+		if err == sql.ErrNoRows {
+			return ErrPricingRuleTargetNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get route: %w", err)
+		}
+		ownerID = route.BusOwnerID
+	} else {
+		schedule, err := s.scheduleRepo.GetByID(*scheduleID)
+		if err == sql.ErrNoRows {
+			return ErrPricingRuleTargetNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get schedule: %w", err)
+		}
+		ownerID = schedule.BusOwnerID
+	}
+	if ownerID != busOwnerID {
+		return ErrPricingRuleTargetNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// REPRICING
+// ============================================================================
+
+// RepriceTrip prices a trip's seats from its rules, e.g. right after they are created. A nil
+// service, a trip without rules or seats, or one no longer open for booking is left as it is.
+func (s *SeatPricingService) RepriceTrip(tripID string) error {
+	if s == nil {
+		return nil
+	}
+	trip, err := s.repo.GetPricingTrip(tripID)
+	if err != nil || trip == nil {
+		return err
+	}
+	_, err = s.reprice(trip, time.Now(), false)
+	return err
+}
+
+// repriceTarget reprices the upcoming trips of a changed rule's route or schedule, including
+// ones left with no rules. Failures are logged; the background job catches up.
+func (s *SeatPricingService) repriceTarget(rule *models.SeatPricingRule) {
+	trips, err := s.repo.ListTargetTrips(rule.BusOwnerRouteID, rule.TripScheduleID)
+	if err != nil {
+		s.logger.WithError(err).WithField("rule_id", rule.ID).Error("Failed to list trips to reprice")
+		return
+	}
+	now := time.Now()
+	for i := range trips {
+		if _, err := s.reprice(&trips[i], now, true); err != nil {
+			s.logger.WithError(err).WithField("trip_id", trips[i].TripID).Error("Failed to reprice trip seats")
+		}
+	}
+}
+
+// reprice sets the trip's seats to its rules' price. Without rules the seats are only reset to
+// the base fare when resetWithoutRules is set, as when the trip's last rule was removed.
+func (s *SeatPricingService) reprice(trip *models.SeatPricingTrip, now time.Time, resetWithoutRules bool) (int, error) {
+	rules, err := s.repo.ListForTrip(trip)
+	if err != nil {
+		return 0, err
+	}
+	if len(rules) == 0 && !resetWithoutRules {
+		return 0, nil
+	}
+	eval := models.EvaluateSeatPricing(rules, trip.BaseFare, trip.FareCap, trip.Context(now))
+	return s.repo.RepriceSeats(trip.TripID, eval.Price)
+}
+
+// Start begins the background repricing job
+func (s *SeatPricingService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Seat pricing job disabled (SEAT_PRICING_ENABLED=false)")
+		return
+	}
+	s.logger.WithField("interval", s.config.CheckInterval.String()).Info("💺 Starting Seat Pricing job")
+	go s.run()
+}
+
+// Stop stops the background repricing job
+func (s *SeatPricingService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Seat Pricing job")
+	close(s.stopCh)
+}
+
+func (s *SeatPricingService) run() {
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	s.repriceAll()
+	for {
+		select {
+		case <-ticker.C:
+			s.repriceAll()
+		case <-s.stopCh:
+			s.logger.Info("Seat Pricing job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single repricing cycle (useful for testing or manual trigger)
+func (s *SeatPricingService) RunOnce() {
+	s.repriceAll()
+}
+
+// repriceAll re-evaluates every upcoming trip with rules, a batch at a time
+func (s *SeatPricingService) repriceAll() {
+	now := time.Now()
+	var afterDeparture time.Time
+	afterID := ""
+	trips, seats := 0, 0
+	for {
+		batch, err := s.repo.ListRuledTrips(afterDeparture, afterID, s.config.BatchSize)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to get trips to reprice")
+			return
+		}
+		for i := range batch {
+			changed, err := s.reprice(&batch[i], now, false)
+			if err != nil {
+				s.logger.WithError(err).WithField("trip_id", batch[i].TripID).Error("Failed to reprice trip seats")
+				continue
+			}
+			if changed > 0 {
+				trips++
+				seats += changed
+			}
+		}
+		if len(batch) < s.config.BatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		afterDeparture, afterID = last.DepartureDatetime, last.TripID
+	}
+
+	if trips > 0 {
+		s.logger.WithFields(logrus.Fields{"trips": trips, "seats": seats}).Info("Seat pricing run finished")
+	}
+}
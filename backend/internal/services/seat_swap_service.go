@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrSeatSwapBookingNotFound = errors.New("seat booking not found on this trip")
+	ErrSeatSwapDenied          = errors.New("only the trip's driver, conductor or bus owner can swap its passengers' seats")
+	ErrSeatSwapNotSwappable    = errors.New("passenger no longer holds a seat on this trip")
+	ErrSeatSwapSameSeat        = errors.New("passenger is already in that seat")
+)
+
+// SeatSwapAttempt is a staff request to move a passenger to another seat
+type SeatSwapAttempt struct {
+	UserID    uuid.UUID
+	IPAddress string
+	UserAgent string
+}
+
+// SeatSwapService lets a trip's crew resolve onboard seat conflicts by moving a booked
+// passenger to another free seat. Every swap is audited with the conductor's note.
+type SeatSwapService struct {
+	repo   *database.SeatSwapRepository
+	logger *logrus.Logger
+}
+
+// NewSeatSwapService creates a new SeatSwapService
+func NewSeatSwapService(repo *database.SeatSwapRepository, logger *logrus.Logger) *SeatSwapService {
+	return &SeatSwapService{repo: repo, logger: logger}
+}
+
+// Swap moves the passenger of a seat booking on the trip to the requested free seat. Returns
+// database.ErrSeatSwapSeatUnavailable if that seat is not free on the trip.
+func (s *SeatSwapService) Swap(scheduledTripID string, req *models.SeatSwapRequest, attempt *SeatSwapAttempt) (*models.SeatSwap, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(req.BusBookingSeatID); err != nil {
+		return nil, ErrSeatSwapBookingNotFound
+	}
+	if _, err := uuid.Parse(req.ToTripSeatID); err != nil {
+		return nil, database.ErrSeatSwapSeatUnavailable
+	}
+
+	target, err := s.repo.GetTarget(req.BusBookingSeatID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil || target.ScheduledTripID != scheduledTripID {
+		return nil, ErrSeatSwapBookingNotFound
+	}
+	if !target.CanBeSwappedBy(attempt.UserID.String()) {
+		return nil, ErrSeatSwapDenied
+	}
+	if !target.IsSwappable() {
+		return nil, ErrSeatSwapNotSwappable
+	}
+	if target.TripSeatID != nil && *target.TripSeatID == req.ToTripSeatID {
+		return nil, ErrSeatSwapSameSeat
+	}
+
+	swap := &models.SeatSwap{
+		BusBookingSeatID: target.BusBookingSeatID,
+		BusBookingID:     target.BusBookingID,
+		ScheduledTripID:  target.ScheduledTripID,
+		ToTripSeatID:     req.ToTripSeatID,
+		Note:             req.Note,
+	}
+	if err := s.repo.Swap(swap, attempt.UserID.String(), attempt.IPAddress, attempt.UserAgent); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":             attempt.UserID,
+		"bus_booking_seat_id": swap.BusBookingSeatID,
+		"scheduled_trip_id":   swap.ScheduledTripID,
+		"to_seat_number":      swap.ToSeatNumber,
+	}).Info("Passenger seat swapped by staff")
+	return swap, nil
+}
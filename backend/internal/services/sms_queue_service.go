@@ -0,0 +1,414 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/pkg/sms"
+)
+
+var (
+	// ErrSMSQueueFull is returned when the queue cannot accept more messages
+	ErrSMSQueueFull = errors.New("sms queue is full")
+	// ErrSMSGatewayUnavailable is returned when the gateway's circuit breaker is open
+	ErrSMSGatewayUnavailable = errors.New("sms gateway temporarily unavailable")
+	// ErrSMSQueueStopped is returned once the queue is shutting down
+	ErrSMSQueueStopped = errors.New("sms queue is stopped")
+)
+
+// SMSDeliveryState represents where an OTP SMS is in the delivery pipeline
+type SMSDeliveryState string
+
+const (
+	SMSDeliveryQueued   SMSDeliveryState = "queued"
+	SMSDeliverySending  SMSDeliveryState = "sending"
+	SMSDeliveryRetrying SMSDeliveryState = "retrying"
+	SMSDeliverySent     SMSDeliveryState = "sent"
+	SMSDeliveryFailed   SMSDeliveryState = "failed"
+)
+
+// OTPChannel is the medium an OTP is delivered over
+type OTPChannel string
+
+const (
+	OTPChannelSMS      OTPChannel = "sms"
+	OTPChannelWhatsApp OTPChannel = "whatsapp"
+)
+
+// SMSDeliveryStatus is the pollable delivery state of the latest OTP SMS for a phone
+type SMSDeliveryStatus struct {
+	JobID         string           `json:"job_id"`
+	Channel       OTPChannel       `json:"channel"`
+	State         SMSDeliveryState `json:"state"`
+	Attempts      int              `json:"attempts"`
+	MaxAttempts   int              `json:"max_attempts"`
+	LastError     string           `json:"last_error,omitempty"`
+	TransactionID int64            `json:"transaction_id,omitempty"`
+	QueuedAt      time.Time        `json:"queued_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// smsJob is a single OTP SMS waiting to be delivered
+type smsJob struct {
+	id       string
+	phone    string
+	otp      string
+	appType  string
+	channel  OTPChannel
+	branding sms.OTPBranding
+	attempts int
+}
+
+// SMSQueueService sends OTP SMS from a worker pool so HTTP handlers never block on the gateway
+type SMSQueueService struct {
+	gateway  sms.SMSGateway
+	whatsApp sms.WhatsAppOTPSender // Overrides the gateway's own WhatsApp delivery when set
+	config   config.SMSQueueConfig
+	logger   *logrus.Logger
+
+	jobs   chan *smsJob
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	statuses map[string]*SMSDeliveryStatus // Keyed by phone; only the latest job per phone is tracked
+	stopped  bool                          // Set by Stop; no job is queued or retried after it
+}
+
+// NewSMSQueueService creates a new SMS queue service
+func NewSMSQueueService(gateway sms.SMSGateway, cfg config.SMSQueueConfig, logger *logrus.Logger) *SMSQueueService {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = time.Second
+	}
+	if cfg.StatusTTL <= 0 {
+		cfg.StatusTTL = 15 * time.Minute
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = 10 * time.Second
+	}
+
+	return &SMSQueueService{
+		gateway:  gateway,
+		config:   cfg,
+		logger:   logger,
+		jobs:     make(chan *smsJob, cfg.QueueSize),
+		stopCh:   make(chan struct{}),
+		statuses: make(map[string]*SMSDeliveryStatus),
+	}
+}
+
+// Start launches the worker pool
+func (s *SMSQueueService) Start() {
+	s.logger.WithFields(logrus.Fields{
+		"workers":    s.config.Workers,
+		"queue_size": s.config.QueueSize,
+	}).Info("📨 Starting SMS Queue")
+
+	for i := 0; i < s.config.Workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+}
+
+// Stop stops accepting jobs, lets the workers send the jobs still queued for up to
+// DrainTimeout and marks any left over failed. Pending retries are dropped.
+func (s *SMSQueueService) Stop() {
+	s.logger.Info("🛑 Stopping SMS Queue")
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	close(s.stopCh)
+	s.wg.Wait()
+
+	abandoned := 0
+	for {
+		select {
+		case job := <-s.jobs:
+			s.update(job, func(st *SMSDeliveryStatus) {
+				st.State = SMSDeliveryFailed
+				st.LastError = "not sent before shutdown"
+			})
+			abandoned++
+		default:
+			if abandoned > 0 {
+				s.logger.WithField("count", abandoned).Warn("OTP SMS not sent before shutdown")
+			}
+			return
+		}
+	}
+}
+
+// SetWhatsAppSender delivers WhatsApp OTPs through sender, e.g. the WhatsApp Business API,
+// instead of the SMS gateway. Must be called before Start.
+func (s *SMSQueueService) SetWhatsAppSender(sender sms.WhatsAppOTPSender) {
+	s.whatsApp = sender
+}
+
+// SupportsWhatsApp reports whether OTPs can be delivered over WhatsApp
+func (s *SMSQueueService) SupportsWhatsApp() bool {
+	return s.whatsAppSender() != nil
+}
+
+// whatsAppSender returns the configured WhatsApp sender, falling back to the gateway's own
+func (s *SMSQueueService) whatsAppSender() sms.WhatsAppOTPSender {
+	if s.whatsApp != nil {
+		return s.whatsApp
+	}
+	if sender, ok := s.gateway.(sms.WhatsAppOTPSender); ok {
+		return sender
+	}
+	return nil
+}
+
+// EnqueueOTP queues an OTP message and returns the job ID.
+// WhatsApp falls back to SMS when the gateway does not support it.
+// A newer job for the same phone supersedes any older one that has not been sent yet.
+func (s *SMSQueueService) EnqueueOTP(phone, otp, appType string, channel OTPChannel) (string, error) {
+	return s.EnqueueBrandedOTP(phone, otp, appType, channel, sms.OTPBranding{})
+}
+
+// EnqueueBrandedOTP queues an OTP message sent under a tenant's sender mask and app name.
+// The branding is ignored for WhatsApp and for gateways that cannot brand OTPs.
+func (s *SMSQueueService) EnqueueBrandedOTP(phone, otp, appType string, channel OTPChannel, branding sms.OTPBranding) (string, error) {
+	if channel != OTPChannelWhatsApp || !s.SupportsWhatsApp() {
+		channel = OTPChannelSMS
+	}
+
+	var sender interface{} = s.gateway
+	if channel == OTPChannelWhatsApp {
+		sender = s.whatsAppSender()
+	}
+	if checker, ok := sender.(availabilityReporter); ok && !checker.Available() {
+		return "", ErrSMSGatewayUnavailable
+	}
+
+	now := time.Now()
+	job := &smsJob{
+		id:       uuid.New().String(),
+		phone:    phone,
+		otp:      otp,
+		appType:  appType,
+		channel:  channel,
+		branding: branding,
+	}
+
+	// Queued under mu, so a job is either refused or in the queue before Stop drains it
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return "", ErrSMSQueueStopped
+	}
+
+	s.pruneLocked(now)
+	select {
+	case s.jobs <- job:
+	default:
+		return "", ErrSMSQueueFull
+	}
+	s.statuses[phone] = &SMSDeliveryStatus{
+		JobID:       job.id,
+		Channel:     channel,
+		State:       SMSDeliveryQueued,
+		MaxAttempts: s.config.MaxAttempts,
+		QueuedAt:    now,
+		UpdatedAt:   now,
+	}
+	return job.id, nil
+}
+
+// GetStatus returns the delivery status of the latest OTP SMS for a phone
+func (s *SMSQueueService) GetStatus(phone string) (*SMSDeliveryStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, ok := s.statuses[phone]
+	if !ok {
+		return nil, false
+	}
+	copied := *status
+	return &copied, true
+}
+
+func (s *SMSQueueService) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case job := <-s.jobs:
+			s.process(job)
+		case <-s.stopCh:
+			s.drain()
+			return
+		}
+	}
+}
+
+// drain sends the jobs still queued at Stop until the queue is empty or DrainTimeout has passed
+func (s *SMSQueueService) drain() {
+	deadline := time.Now().Add(s.config.DrainTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case job := <-s.jobs:
+			s.process(job)
+		default:
+			return
+		}
+	}
+}
+
+// process makes one delivery attempt and schedules a retry on failure
+func (s *SMSQueueService) process(job *smsJob) {
+	if !s.isCurrent(job) {
+		return
+	}
+
+	job.attempts++
+	s.update(job, func(st *SMSDeliveryStatus) {
+		st.State = SMSDeliverySending
+		st.Attempts = job.attempts
+	})
+
+	transactionID, err := s.send(job)
+	if err == nil {
+		s.update(job, func(st *SMSDeliveryStatus) {
+			st.State = SMSDeliverySent
+			st.TransactionID = transactionID
+			st.LastError = ""
+		})
+		s.logger.WithFields(logrus.Fields{
+			"phone":          job.phone,
+			"channel":        job.channel,
+			"attempts":       job.attempts,
+			"transaction_id": transactionID,
+		}).Info("✅ OTP SMS delivered")
+		return
+	}
+
+	entry := s.logger.WithError(err).WithFields(logrus.Fields{
+		"phone":    job.phone,
+		"attempts": job.attempts,
+	})
+
+	if job.attempts >= s.config.MaxAttempts || s.isStopped() {
+		s.update(job, func(st *SMSDeliveryStatus) {
+			st.State = SMSDeliveryFailed
+			st.LastError = err.Error()
+		})
+		entry.Error("❌ OTP SMS delivery failed, giving up")
+		return
+	}
+
+	delay := s.retryDelay(job.attempts)
+	s.update(job, func(st *SMSDeliveryStatus) {
+		st.State = SMSDeliveryRetrying
+		st.LastError = err.Error()
+	})
+	entry.WithField("retry_in", delay.String()).Warn("OTP SMS delivery failed, retrying")
+
+	time.AfterFunc(delay, func() {
+		s.retry(job, err)
+	})
+}
+
+// retry puts a failed job back on the queue, unless the queue has stopped or is full
+func (s *SMSQueueService) retry(job *smsJob, lastErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reason := "queue stopped"
+	if !s.stopped {
+		select {
+		case s.jobs <- job:
+			return
+		default:
+			reason = "queue full"
+		}
+	}
+	s.updateLocked(job, func(st *SMSDeliveryStatus) {
+		st.State = SMSDeliveryFailed
+		st.LastError = fmt.Sprintf("%s (%s, retry dropped)", lastErr.Error(), reason)
+	})
+}
+
+// isStopped reports whether Stop has been called
+func (s *SMSQueueService) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+// send delivers the job over its channel
+func (s *SMSQueueService) send(job *smsJob) (int64, error) {
+	if job.channel == OTPChannelWhatsApp {
+		if sender := s.whatsAppSender(); sender != nil {
+			return sender.SendWhatsAppOTP(job.phone, job.otp, job.appType)
+		}
+	}
+	if job.branding != (sms.OTPBranding{}) {
+		if sender, ok := s.gateway.(sms.BrandedOTPSender); ok {
+			return sender.SendBrandedOTP(job.phone, job.otp, job.appType, job.branding)
+		}
+	}
+	return s.gateway.SendOTP(job.phone, job.otp, job.appType)
+}
+
+// retryDelay returns an exponential backoff with +/-20% jitter
+func (s *SMSQueueService) retryDelay(attempt int) time.Duration {
+	delay := s.config.RetryDelay << uint(attempt-1)
+	jitter := time.Duration(rand.Int63n(int64(delay)/5*2+1)) - delay/5
+	return delay + jitter
+}
+
+// isCurrent reports whether job is still the latest one for its phone.
+// Older jobs are dropped so a user who re-requested an OTP never receives a stale code.
+func (s *SMSQueueService) isCurrent(job *smsJob) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, ok := s.statuses[job.phone]
+	if ok && status.JobID == job.id {
+		return true
+	}
+	s.logger.WithField("phone", job.phone).Debug("Skipping superseded OTP SMS")
+	return false
+}
+
+// update applies fn to the job's status if the job is still the latest for its phone
+func (s *SMSQueueService) update(job *smsJob, fn func(*SMSDeliveryStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateLocked(job, fn)
+}
+
+// updateLocked is update with mu held
+func (s *SMSQueueService) updateLocked(job *smsJob, fn func(*SMSDeliveryStatus)) {
+	status, ok := s.statuses[job.phone]
+	if !ok || status.JobID != job.id {
+		return
+	}
+	fn(status)
+	status.UpdatedAt = time.Now()
+}
+
+// pruneLocked drops statuses that finished longer than StatusTTL ago; mu must be held
+func (s *SMSQueueService) pruneLocked(now time.Time) {
+	for phone, status := range s.statuses {
+		if now.Sub(status.UpdatedAt) > s.config.StatusTTL {
+			delete(s.statuses, phone)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOTPGateway struct {
+	mu        sync.Mutex
+	failFirst int // Number of sends that fail before succeeding
+	available bool
+	sent      []string
+}
+
+func (g *fakeOTPGateway) SendOTP(phone, otpCode, appType string) (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.failFirst > 0 {
+		g.failFirst--
+		return 0, errors.New("gateway timeout")
+	}
+	g.sent = append(g.sent, otpCode)
+	return 42, nil
+}
+
+func (g *fakeOTPGateway) GetName() string { return "fake" }
+func (g *fakeOTPGateway) Available() bool { return g.available }
+
+func (g *fakeOTPGateway) sentCodes() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string{}, g.sent...)
+}
+
+func testQueueConfig() config.SMSQueueConfig {
+	return config.SMSQueueConfig{
+		Workers:     1,
+		QueueSize:   10,
+		MaxAttempts: 3,
+		RetryDelay:  time.Millisecond,
+		StatusTTL:   time.Minute,
+	}
+}
+
+func waitForState(t *testing.T, q *SMSQueueService, phone string, want SMSDeliveryState) *SMSDeliveryStatus {
+	t.Helper()
+	var status *SMSDeliveryStatus
+	require.Eventually(t, func() bool {
+		status, _ = q.GetStatus(phone)
+		return status != nil && status.State == want
+	}, 2*time.Second, 5*time.Millisecond)
+	return status
+}
+
+func TestSMSQueue_RetriesUntilSent(t *testing.T) {
+	gateway := &fakeOTPGateway{available: true, failFirst: 2}
+	q := NewSMSQueueService(gateway, testQueueConfig(), logrus.New())
+	q.Start()
+	defer q.Stop()
+
+	jobID, err := q.EnqueueOTP("+94771234567", "123456", "passenger", OTPChannelSMS)
+	require.NoError(t, err)
+
+	status := waitForState(t, q, "+94771234567", SMSDeliverySent)
+	assert.Equal(t, jobID, status.JobID)
+	assert.Equal(t, 3, status.Attempts)
+	assert.Equal(t, int64(42), status.TransactionID)
+	assert.Equal(t, []string{"123456"}, gateway.sentCodes())
+}
+
+func TestSMSQueue_FailsAfterMaxAttempts(t *testing.T) {
+	gateway := &fakeOTPGateway{available: true, failFirst: 10}
+	q := NewSMSQueueService(gateway, testQueueConfig(), logrus.New())
+	q.Start()
+	defer q.Stop()
+
+	_, err := q.EnqueueOTP("+94771234567", "123456", "passenger", OTPChannelSMS)
+	require.NoError(t, err)
+
+	status := waitForState(t, q, "+94771234567", SMSDeliveryFailed)
+	assert.Equal(t, 3, status.Attempts)
+	assert.Equal(t, "gateway timeout", status.LastError)
+	assert.Empty(t, gateway.sentCodes())
+}
+
+func TestSMSQueue_NewerOTPSupersedesQueued(t *testing.T) {
+	gateway := &fakeOTPGateway{available: true}
+	q := NewSMSQueueService(gateway, testQueueConfig(), logrus.New())
+
+	// Enqueue both before any worker runs
+	_, err := q.EnqueueOTP("+94771234567", "111111", "passenger", OTPChannelSMS)
+	require.NoError(t, err)
+	_, err = q.EnqueueOTP("+94771234567", "222222", "passenger", OTPChannelSMS)
+	require.NoError(t, err)
+
+	q.Start()
+	defer q.Stop()
+
+	waitForState(t, q, "+94771234567", SMSDeliverySent)
+	assert.Equal(t, []string{"222222"}, gateway.sentCodes())
+}
+
+func TestSMSQueue_RejectsWhenFullOrUnavailable(t *testing.T) {
+	cfg := testQueueConfig()
+	cfg.QueueSize = 1
+	gateway := &fakeOTPGateway{available: true}
+	q := NewSMSQueueService(gateway, cfg, logrus.New())
+
+	_, err := q.EnqueueOTP("+94771234567", "111111", "passenger", OTPChannelSMS)
+	require.NoError(t, err)
+	_, err = q.EnqueueOTP("+94771234568", "222222", "passenger", OTPChannelSMS)
+	assert.ErrorIs(t, err, ErrSMSQueueFull)
+
+	// The rejected phone has no pollable status
+	_, ok := q.GetStatus("+94771234568")
+	assert.False(t, ok)
+
+	gateway.available = false
+	_, err = q.EnqueueOTP("+94771234569", "333333", "passenger", OTPChannelSMS)
+	assert.ErrorIs(t, err, ErrSMSGatewayUnavailable)
+}
+
+func TestSMSQueue_StopSendsQueuedJobs(t *testing.T) {
+	gateway := &fakeOTPGateway{available: true}
+	q := NewSMSQueueService(gateway, testQueueConfig(), logrus.New())
+
+	_, err := q.EnqueueOTP("+94771234567", "111111", "passenger", OTPChannelSMS)
+	require.NoError(t, err)
+	_, err = q.EnqueueOTP("+94771234568", "222222", "passenger", OTPChannelSMS)
+	require.NoError(t, err)
+
+	q.Start()
+	q.Stop()
+	assert.ElementsMatch(t, []string{"111111", "222222"}, gateway.sentCodes(), "queued jobs are sent before shutdown")
+
+	_, err = q.EnqueueOTP("+94771234569", "333333", "passenger", OTPChannelSMS)
+	assert.ErrorIs(t, err, ErrSMSQueueStopped)
+}
+
+func TestSMSQueue_StopDropsPendingRetry(t *testing.T) {
+	cfg := testQueueConfig()
+	cfg.RetryDelay = 50 * time.Millisecond
+	gateway := &fakeOTPGateway{available: true, failFirst: 1}
+	q := NewSMSQueueService(gateway, cfg, logrus.New())
+	q.Start()
+
+	_, err := q.EnqueueOTP("+94771234567", "123456", "passenger", OTPChannelSMS)
+	require.NoError(t, err)
+	waitForState(t, q, "+94771234567", SMSDeliveryRetrying)
+	q.Stop()
+
+	status := waitForState(t, q, "+94771234567", SMSDeliveryFailed)
+	assert.Contains(t, status.LastError, "queue stopped")
+	assert.Empty(t, gateway.sentCodes(), "the retry never runs after Stop")
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrInvalidDevicePublicKey   = errors.New("invalid device public key")
+	ErrDeviceCredentialNotFound = errors.New("device credential not found")
+	ErrDeviceCredentialInactive = errors.New("device credential has been revoked or has expired")
+	ErrDeviceChallengeInvalid   = errors.New("device challenge is invalid, expired or already used")
+	ErrDeviceSignatureInvalid   = errors.New("device signature is invalid")
+	ErrTooManyStaffDevices      = errors.New("maximum number of registered devices reached")
+	ErrStaffNotEmployed         = errors.New("staff member has no active employment")
+)
+
+// deviceChallengeBytes is the size of the random nonce a device signs
+const deviceChallengeBytes = 32
+
+// StaffDeviceAuthService issues and verifies long-lived device credentials so staff
+// can log in with a biometric-unlocked device key instead of an OTP every shift
+type StaffDeviceAuthService struct {
+	credentialRepo *database.StaffDeviceCredentialRepository
+	staffRepo      *database.BusStaffRepository
+	config         config.StaffDeviceConfig
+}
+
+// NewStaffDeviceAuthService creates a new StaffDeviceAuthService
+func NewStaffDeviceAuthService(
+	credentialRepo *database.StaffDeviceCredentialRepository,
+	staffRepo *database.BusStaffRepository,
+	cfg config.StaffDeviceConfig,
+) *StaffDeviceAuthService {
+	return &StaffDeviceAuthService{
+		credentialRepo: credentialRepo,
+		staffRepo:      staffRepo,
+		config:         cfg,
+	}
+}
+
+// RegisterDevice binds a device public key to a staff member.
+// Only staff with an active employment can register devices.
+func (s *StaffDeviceAuthService) RegisterDevice(staff *models.BusStaff, req *models.RegisterStaffDeviceRequest) (*models.StaffDeviceCredential, error) {
+	if _, err := parseDevicePublicKey(req.PublicKey, req.KeyAlgorithm); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireActiveEmployment(staff.ID); err != nil {
+		return nil, err
+	}
+
+	if s.config.MaxDevicesPerStaff > 0 {
+		count, err := s.credentialRepo.CountActiveByStaffID(staff.ID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= s.config.MaxDevicesPerStaff {
+			return nil, ErrTooManyStaffDevices
+		}
+	}
+
+	credential := &models.StaffDeviceCredential{
+		UserID:       staff.UserID,
+		StaffID:      staff.ID,
+		DeviceID:     req.DeviceID,
+		DeviceName:   req.DeviceName,
+		PublicKey:    req.PublicKey,
+		KeyAlgorithm: req.KeyAlgorithm,
+		ExpiresAt:    time.Now().Add(s.config.CredentialTTL),
+	}
+	if err := s.credentialRepo.Create(credential); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// IssueChallenge creates a single-use nonce for an active credential
+func (s *StaffDeviceAuthService) IssueChallenge(credentialID string) (*models.StaffDeviceChallenge, error) {
+	if _, err := s.getActiveCredential(credentialID); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, deviceChallengeBytes)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate device challenge: %w", err)
+	}
+
+	challenge := &models.StaffDeviceChallenge{
+		CredentialID: credentialID,
+		Nonce:        base64.StdEncoding.EncodeToString(nonce),
+		ExpiresAt:    time.Now().Add(s.config.ChallengeTTL),
+	}
+	if err := s.credentialRepo.CreateChallenge(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// VerifyLogin consumes the challenge and checks the device's signature over it.
+// The staff member must still be employed, so unlinking staff also stops device logins.
+func (s *StaffDeviceAuthService) VerifyLogin(req *models.StaffDeviceLoginRequest) (*models.StaffDeviceCredential, error) {
+	credential, err := s.getActiveCredential(req.CredentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Consume before verifying so a challenge can never be tried twice
+	challenge, err := s.credentialRepo.ConsumeChallenge(req.ChallengeID, credential.ID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, ErrDeviceChallengeInvalid
+	}
+
+	if err := verifyDeviceSignature(credential.PublicKey, credential.KeyAlgorithm, challenge.Nonce, req.Signature); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireActiveEmployment(credential.StaffID); err != nil {
+		return nil, err
+	}
+
+	if err := s.credentialRepo.MarkUsed(credential.ID); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// ListDevices returns all credentials registered for a staff member
+func (s *StaffDeviceAuthService) ListDevices(staffID string) ([]models.StaffDeviceCredential, error) {
+	return s.credentialRepo.ListByStaffID(staffID)
+}
+
+// RevokeDevice revokes a staff member's credential. revokedBy is the acting user's ID.
+func (s *StaffDeviceAuthService) RevokeDevice(staffID, credentialID, revokedBy, reason string) (*models.StaffDeviceCredential, error) {
+	credential, err := s.credentialRepo.GetByID(credentialID)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil || credential.StaffID != staffID {
+		return nil, ErrDeviceCredentialNotFound
+	}
+
+	if _, err := s.credentialRepo.Revoke(credentialID, revokedBy, reason); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// RevokeAllDevices revokes every credential of a staff member (e.g. when they leave an operator)
+func (s *StaffDeviceAuthService) RevokeAllDevices(staffID, revokedBy, reason string) (int64, error) {
+	return s.credentialRepo.RevokeAllForStaff(staffID, revokedBy, reason)
+}
+
+func (s *StaffDeviceAuthService) getActiveCredential(credentialID string) (*models.StaffDeviceCredential, error) {
+	credential, err := s.credentialRepo.GetByID(credentialID)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, ErrDeviceCredentialNotFound
+	}
+	if !credential.IsActive() {
+		return nil, ErrDeviceCredentialInactive
+	}
+	return credential, nil
+}
+
+func (s *StaffDeviceAuthService) requireActiveEmployment(staffID string) error {
+	employment, err := s.staffRepo.GetCurrentEmployment(staffID)
+	if err != nil {
+		return fmt.Errorf("failed to check staff employment: %w", err)
+	}
+	if employment == nil || employment.EmploymentStatus != models.EmploymentStatusActive {
+		return ErrStaffNotEmployed
+	}
+	return nil
+}
+
+// parseDevicePublicKey decodes a base64 PKIX public key and checks it matches the algorithm
+func parseDevicePublicKey(encoded string, algorithm models.DeviceKeyAlgorithm) (interface{}, error) {
+	der, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not valid base64", ErrInvalidDevicePublicKey)
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDevicePublicKey, err)
+	}
+
+	switch algorithm {
+	case models.DeviceKeyECDSAP256:
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: expected an ECDSA P-256 key", ErrInvalidDevicePublicKey)
+		}
+		return ecKey, nil
+	case models.DeviceKeyEd25519:
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected an Ed25519 key", ErrInvalidDevicePublicKey)
+		}
+		return edKey, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key algorithm %q", ErrInvalidDevicePublicKey, algorithm)
+	}
+}
+
+// verifyDeviceSignature checks a base64 signature over the decoded challenge nonce
+func verifyDeviceSignature(publicKey string, algorithm models.DeviceKeyAlgorithm, nonce, signature string) error {
+	key, err := parseDevicePublicKey(publicKey, algorithm)
+	if err != nil {
+		return err
+	}
+	message, err := base64.StdEncoding.DecodeString(nonce)
+	if err != nil {
+		return fmt.Errorf("failed to decode device challenge: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrDeviceSignatureInvalid
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		if !ecdsa.VerifyASN1(k, digest[:], sig) {
+			return ErrDeviceSignatureInvalid
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, message, sig) {
+			return ErrDeviceSignatureInvalid
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func TestVerifyDeviceSignature_ECDSAP256(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	publicKey := encodePublicKey(t, &privateKey.PublicKey)
+
+	nonce := []byte("0123456789abcdef0123456789abcdef")
+	digest := sha256.Sum256(nonce)
+	sig, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	require.NoError(t, err)
+
+	encodedNonce := base64.StdEncoding.EncodeToString(nonce)
+	err = verifyDeviceSignature(publicKey, models.DeviceKeyECDSAP256, encodedNonce, base64.StdEncoding.EncodeToString(sig))
+	assert.NoError(t, err)
+
+	// Signature over a different challenge is rejected
+	other := base64.StdEncoding.EncodeToString([]byte("another challenge"))
+	err = verifyDeviceSignature(publicKey, models.DeviceKeyECDSAP256, other, base64.StdEncoding.EncodeToString(sig))
+	assert.ErrorIs(t, err, ErrDeviceSignatureInvalid)
+}
+
+func TestVerifyDeviceSignature_Ed25519(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	nonce := []byte("challenge-bytes")
+	sig := ed25519.Sign(privateKey, nonce)
+
+	encodedNonce := base64.StdEncoding.EncodeToString(nonce)
+	err = verifyDeviceSignature(encodePublicKey(t, publicKey), models.DeviceKeyEd25519, encodedNonce, base64.StdEncoding.EncodeToString(sig))
+	assert.NoError(t, err)
+
+	sig[0] ^= 0xff
+	err = verifyDeviceSignature(encodePublicKey(t, publicKey), models.DeviceKeyEd25519, encodedNonce, base64.StdEncoding.EncodeToString(sig))
+	assert.ErrorIs(t, err, ErrDeviceSignatureInvalid)
+}
+
+func TestParseDevicePublicKey_RejectsMismatchedAlgorithm(t *testing.T) {
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = parseDevicePublicKey(encodePublicKey(t, &p384Key.PublicKey), models.DeviceKeyECDSAP256)
+	assert.ErrorIs(t, err, ErrInvalidDevicePublicKey)
+
+	_, err = parseDevicePublicKey(encodePublicKey(t, edKey), models.DeviceKeyECDSAP256)
+	assert.ErrorIs(t, err, ErrInvalidDevicePublicKey)
+
+	_, err = parseDevicePublicKey(encodePublicKey(t, edKey), "rsa")
+	assert.ErrorIs(t, err, ErrInvalidDevicePublicKey)
+
+	_, err = parseDevicePublicKey("not base64!", models.DeviceKeyEd25519)
+	assert.ErrorIs(t, err, ErrInvalidDevicePublicKey)
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/push"
+)
+
+var (
+	ErrStaffProfileNotFound  = errors.New("staff profile not found")
+	ErrStaffDocumentNotFound = errors.New("staff document not found")
+	ErrStaffDocumentReviewed = errors.New("staff document is no longer pending review")
+)
+
+// StaffDocumentService lets drivers and conductors renew their documents themselves. Uploads
+// go to the staff member's current bus owner for review, and admins can review any of them;
+// approving a license renewal updates the license that trip assignment checks.
+type StaffDocumentService struct {
+	repo      *database.StaffDocumentRenewalRepository
+	staffRepo *database.BusStaffRepository
+	ownerRepo *database.BusOwnerRepository
+	push      *PushNotificationService
+	logger    *logrus.Logger
+}
+
+// NewStaffDocumentService creates a new StaffDocumentService
+func NewStaffDocumentService(
+	repo *database.StaffDocumentRenewalRepository,
+	staffRepo *database.BusStaffRepository,
+	ownerRepo *database.BusOwnerRepository,
+	pushService *PushNotificationService,
+	logger *logrus.Logger,
+) *StaffDocumentService {
+	return &StaffDocumentService{
+		repo:      repo,
+		staffRepo: staffRepo,
+		ownerRepo: ownerRepo,
+		push:      pushService,
+		logger:    logger,
+	}
+}
+
+// GetMyDocuments reports the state of each of the staff member's documents
+func (s *StaffDocumentService) GetMyDocuments(userID string) ([]models.StaffDocumentStatus, error) {
+	staff, err := s.staffByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	renewals, err := s.repo.ListByStaff(staff.ID)
+	if err != nil {
+		return nil, err
+	}
+	return models.StaffDocumentStatuses(staff, renewals, time.Now()), nil
+}
+
+// SubmitRenewal stores an uploaded document for review and tells the bus owner about it
+func (s *StaffDocumentService) SubmitRenewal(userID string, req *models.SubmitStaffDocumentRequest) (*models.StaffDocumentRenewal, error) {
+	staff, err := s.staffByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := req.Validate(staff.StaffType, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	renewal := &models.StaffDocumentRenewal{
+		StaffID:      staff.ID,
+		DocumentType: req.DocumentType,
+		ExpiryDate:   expiry,
+		FileURL:      req.FileURL,
+	}
+	if req.DocumentNumber != "" {
+		renewal.DocumentNumber = &req.DocumentNumber
+	}
+	if err := s.repo.Create(renewal); err != nil {
+		return nil, err
+	}
+
+	if s.push != nil {
+		employment, err := s.staffRepo.GetCurrentEmployment(staff.ID)
+		if err != nil {
+			s.logger.WithError(err).WithField("staff_id", staff.ID).Warn("Failed to find employer to notify of document renewal")
+		} else if employment != nil && employment.EmploymentStatus == models.EmploymentStatusActive {
+			if owner, err := s.ownerRepo.GetByID(employment.BusOwnerID); err == nil && owner != nil {
+				s.push.NotifyUsersAsync([]string{owner.UserID}, staffDocumentSubmittedNotification(staff, renewal))
+			}
+		}
+	}
+	return renewal, nil
+}
+
+// ListPendingForBusOwner returns the renewals from the bus owner's staff waiting for review
+func (s *StaffDocumentService) ListPendingForBusOwner(busOwnerID string) ([]models.PendingStaffDocumentRenewal, error) {
+	return s.repo.ListPendingForBusOwner(busOwnerID)
+}
+
+// ListPending returns every renewal waiting for review, for admins
+func (s *StaffDocumentService) ListPending(limit, offset int) ([]models.PendingStaffDocumentRenewal, int, error) {
+	return s.repo.ListPending(limit, offset)
+}
+
+// Review approves or rejects a renewal. busOwnerID restricts the review to that owner's staff;
+// admins pass an empty string. The staff member is told the outcome.
+func (s *StaffDocumentService) Review(renewalID, reviewerUserID, busOwnerID string, approve bool, reason string) (*models.StaffDocumentRenewal, error) {
+	if _, err := uuid.Parse(renewalID); err != nil {
+		return nil, ErrStaffDocumentNotFound
+	}
+	pending, err := s.repo.GetByID(renewalID)
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil {
+		return nil, ErrStaffDocumentNotFound
+	}
+	if busOwnerID != "" && (pending.BusOwnerID == nil || *pending.BusOwnerID != busOwnerID) {
+		return nil, ErrStaffDocumentNotFound
+	}
+	if pending.Status != models.StaffDocumentRenewalPending {
+		return nil, ErrStaffDocumentReviewed
+	}
+
+	renewal := &pending.StaffDocumentRenewal
+	var reviewed bool
+	if approve {
+		reviewed, err = s.repo.Approve(renewal, reviewerUserID)
+	} else {
+		reviewed, err = s.repo.Reject(renewal, reviewerUserID, strings.TrimSpace(reason))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !reviewed {
+		return nil, ErrStaffDocumentReviewed
+	}
+
+	if s.push != nil {
+		if staff, err := s.staffRepo.GetByID(renewal.StaffID); err == nil && staff != nil {
+			s.push.NotifyUsersAsync([]string{staff.UserID}, staffDocumentReviewedNotification(renewal))
+		}
+	}
+	return renewal, nil
+}
+
+func (s *StaffDocumentService) staffByUserID(userID string) (*models.BusStaff, error) {
+	staff, err := s.staffRepo.GetByUserID(userID)
+	if errors.Is(err, database.ErrStaffNotFound) {
+		return nil, ErrStaffProfileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staff profile: %w", err)
+	}
+	return staff, nil
+}
+
+// staffDocumentLabels names document types in notifications
+var staffDocumentLabels = map[string]string{
+	models.StaffDocDrivingLicense:     "driving license",
+	models.StaffDocNTCLicense:         "NTC license",
+	models.StaffDocNIC:                "NIC",
+	models.StaffDocMedicalCertificate: "medical certificate",
+}
+
+// staffDocumentSubmittedNotification tells the bus owner a staff member uploaded a renewal
+func staffDocumentSubmittedNotification(staff *models.BusStaff, renewal *models.StaffDocumentRenewal) push.Message {
+	name := "A staff member"
+	if staff.FirstName != nil && strings.TrimSpace(*staff.FirstName) != "" {
+		name = strings.TrimSpace(*staff.FirstName)
+	}
+	return push.Message{
+		Title: "Document renewal to review",
+		Body:  fmt.Sprintf("%s uploaded a new %s for your approval.", name, staffDocumentLabels[renewal.DocumentType]),
+		Data: map[string]string{
+			"type":        "staff_document_submitted",
+			"document_id": renewal.ID,
+			"staff_id":    staff.ID,
+		},
+	}
+}
+
+// staffDocumentReviewedNotification tells a staff member the decision on their renewal
+func staffDocumentReviewedNotification(renewal *models.StaffDocumentRenewal) push.Message {
+	label := staffDocumentLabels[renewal.DocumentType]
+	if renewal.Status == models.StaffDocumentRenewalApproved {
+		return push.Message{
+			Title: "Document approved",
+			Body:  fmt.Sprintf("Your new %s was approved.", label),
+			Data:  map[string]string{"type": "staff_document_approved", "document_id": renewal.ID},
+		}
+	}
+	reason := ""
+	if renewal.RejectionReason != nil {
+		reason = *renewal.RejectionReason
+	}
+	return push.Message{
+		Title: "Document declined",
+		Body:  fmt.Sprintf("Your new %s was declined: %s", label, reason),
+		Data:  map[string]string{"type": "staff_document_rejected", "document_id": renewal.ID},
+	}
+}
@@ -1,19 +1,35 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/smarttransit/sms-auth-backend/internal/database"
 	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/push"
+)
+
+var (
+	ErrJoinBusOwnerNotFound    = errors.New("bus owner not found")
+	ErrJoinBusOwnerNotVerified = errors.New("bus owner is not verified")
+	ErrStaffAlreadyEmployed    = errors.New("staff already has active employment")
+	ErrStaffRequestPending     = errors.New("staff already has a pending request to join a bus owner")
+	ErrStaffRequestNotFound    = errors.New("staff request not found")
+	ErrStaffRequestResolved    = errors.New("staff request is no longer pending")
 )
 
 // StaffService handles business logic for staff operations
 type StaffService struct {
-	staffRepo *database.BusStaffRepository
-	ownerRepo *database.BusOwnerRepository
-	userRepo  *database.UserRepository
+	staffRepo     *database.BusStaffRepository
+	ownerRepo     *database.BusOwnerRepository
+	userRepo      *database.UserRepository
+	push          *PushNotificationService
+	notifications *NotificationService
+	claimsCache   *UserClaimsCache
+	devices       *StaffDeviceAuthService
 }
 
 // NewStaffService creates a new StaffService
@@ -21,11 +37,19 @@ func NewStaffService(
 	staffRepo *database.BusStaffRepository,
 	ownerRepo *database.BusOwnerRepository,
 	userRepo *database.UserRepository,
+	pushService *PushNotificationService,
+	notificationService *NotificationService,
+	claimsCache *UserClaimsCache,
+	devices *StaffDeviceAuthService,
 ) *StaffService {
 	return &StaffService{
-		staffRepo: staffRepo,
-		ownerRepo: ownerRepo,
-		userRepo:  userRepo,
+		staffRepo:     staffRepo,
+		ownerRepo:     ownerRepo,
+		userRepo:      userRepo,
+		push:          pushService,
+		notifications: notificationService,
+		claimsCache:   claimsCache,
+		devices:       devices,
 	}
 }
 
@@ -53,6 +77,15 @@ func (s *StaffService) RegisterStaff(input *models.StaffRegistrationInput) (*mod
 		return nil, fmt.Errorf("user already registered as staff")
 	}
 
+	// Staff who picked a bus owner ask to join it; check it can take them before registering
+	var owner *models.BusOwner
+	if input.BusOwnerID != nil && *input.BusOwnerID != "" {
+		owner, err = s.joinableBusOwner(*input.BusOwnerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Build staff record (profile only - no employment fields)
 	staff := &models.BusStaff{
 		UserID:               input.UserID,
@@ -99,20 +132,13 @@ func (s *StaffService) RegisterStaff(input *models.StaffRegistrationInput) (*mod
 		return nil, fmt.Errorf("failed to create staff record: %v", err)
 	}
 
-	// IMPORTANT: Assign role to user based on staff type
-	var roleToAdd string
-	if staff.StaffType == models.StaffTypeDriver {
-		roleToAdd = "driver"
-	} else if staff.StaffType == models.StaffTypeConductor {
-		roleToAdd = "conductor"
-	}
-
-	// Add the role to the user
-	err = s.userRepo.AddUserRole(userUUID, roleToAdd)
-	if err != nil {
-		// Log error but don't fail the registration
-		// The staff record is already created
-		fmt.Printf("WARNING: Failed to add role %s to user %s: %v\n", roleToAdd, userUUID, err)
+	// The driver/conductor role is granted when a bus owner approves the staff member
+	// (see ApproveStaffRequest), so the next token refresh carries it
+	if owner != nil {
+		if _, err := s.createJoinRequest(staff, owner); err != nil {
+			// Registration stands; the staff member can ask again from the app
+			fmt.Printf("WARNING: Failed to create join request for staff %s: %v\n", staff.ID, err)
+		}
 	}
 
 	// IMPORTANT: Set profile_completed = true on users table
@@ -311,48 +337,272 @@ func (s *StaffService) FindBusOwnerByBusNumber(busNumber string) (*models.BusOwn
 	return nil, fmt.Errorf("bus number search not yet implemented")
 }
 
-// AssignBusOwner assigns a bus owner to a staff member (creates new employment record)
-func (s *StaffService) AssignBusOwner(userID, busOwnerID string) error {
-	// Verify staff exists
+// RequestToJoinBusOwner asks a verified bus owner to take on a registered staff member. The
+// request waits as pending employment until the owner approves or rejects it.
+func (s *StaffService) RequestToJoinBusOwner(userID, busOwnerID string) (*models.BusStaffEmployment, error) {
 	staff, err := s.staffRepo.GetByUserID(userID)
 	if err != nil {
-		return fmt.Errorf("staff not found: %v", err)
+		return nil, err
 	}
 
-	// Verify bus owner exists and is verified
-	owner, err := s.ownerRepo.GetByID(busOwnerID)
+	owner, err := s.joinableBusOwner(busOwnerID)
 	if err != nil {
-		return fmt.Errorf("bus owner not found: %v", err)
+		return nil, err
 	}
 
-	if owner.VerificationStatus != models.VerificationVerified {
-		return fmt.Errorf("bus owner is not verified")
+	existingEmployment, err := s.staffRepo.GetCurrentEmployment(staff.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check current employment: %w", err)
 	}
-
-	// Check if staff already has current employment
-	existingEmployment, _ := s.staffRepo.GetCurrentEmployment(staff.ID)
 	if existingEmployment != nil {
-		return fmt.Errorf("staff already has active employment with another company")
+		if existingEmployment.EmploymentStatus == models.EmploymentStatusPending {
+			return nil, ErrStaffRequestPending
+		}
+		return nil, ErrStaffAlreadyEmployed
 	}
 
-	// Create new employment record
-	now := time.Now()
+	return s.createJoinRequest(staff, owner)
+}
+
+// joinableBusOwner returns the bus owner if staff can ask to join it
+func (s *StaffService) joinableBusOwner(busOwnerID string) (*models.BusOwner, error) {
+	owner, err := s.ownerRepo.GetByID(busOwnerID)
+	if err != nil {
+		if errors.Is(err, database.ErrBusOwnerNotFound) {
+			return nil, ErrJoinBusOwnerNotFound
+		}
+		return nil, fmt.Errorf("failed to get bus owner: %w", err)
+	}
+	if owner.VerificationStatus != models.VerificationVerified {
+		return nil, ErrJoinBusOwnerNotVerified
+	}
+	return owner, nil
+}
+
+// createJoinRequest records a pending join request as pending employment and lets the bus owner know
+func (s *StaffService) createJoinRequest(staff *models.BusStaff, owner *models.BusOwner) (*models.BusStaffEmployment, error) {
 	employment := &models.BusStaffEmployment{
 		StaffID:          staff.ID,
-		BusOwnerID:       busOwnerID,
-		EmploymentStatus: models.EmploymentStatusActive,
-		HireDate:         &now,
+		BusOwnerID:       owner.ID,
+		EmploymentStatus: models.EmploymentStatusPending,
 		IsCurrent:        true,
 	}
+	if err := s.staffRepo.CreateEmployment(employment); err != nil {
+		return nil, fmt.Errorf("failed to create staff request: %w", err)
+	}
 
-	err = s.staffRepo.CreateEmployment(employment)
+	if s.push != nil {
+		s.push.NotifyUsersAsync([]string{owner.UserID}, staffJoinRequestNotification(staff, employment))
+	}
+	return employment, nil
+}
+
+// GetPendingStaffRequests lists staff waiting for the bus owner's approval, with their documents
+func (s *StaffService) GetPendingStaffRequests(busOwnerID string) ([]*models.PendingStaffRequest, error) {
+	pending, err := s.staffRepo.GetPendingByBusOwner(busOwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending staff: %w", err)
+	}
+
+	now := time.Now()
+	requests := make([]*models.PendingStaffRequest, 0, len(pending))
+	for _, swe := range pending {
+		staff, employment := swe.Staff, swe.Employment
+		request := &models.PendingStaffRequest{
+			EmploymentID:         employment.ID,
+			StaffID:              staff.ID,
+			UserID:               staff.UserID,
+			FirstName:            staff.FirstName,
+			LastName:             staff.LastName,
+			StaffType:            staff.StaffType,
+			ExperienceYears:      staff.ExperienceYears,
+			EmergencyContact:     staff.EmergencyContact,
+			EmergencyContactName: staff.EmergencyContactName,
+			VerificationStatus:   staff.VerificationStatus,
+			Documents:            models.StaffDocuments(staff, now),
+			RequestedAt:          employment.CreatedAt,
+		}
+		if userUUID, err := uuid.Parse(staff.UserID); err == nil {
+			if user, err := s.userRepo.GetUserByID(userUUID); err == nil && user != nil {
+				request.Phone = user.Phone
+			}
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// ApproveStaffRequest accepts a join request: the employment becomes active, the staff member
+// is marked verified by the owner and given the driver/conductor role, so their next token
+// refresh reflects the approval. Approving an already-approved request re-applies the
+// verification and role without notifying again.
+func (s *StaffService) ApproveStaffRequest(owner *models.BusOwner, employmentID string) (*models.BusStaffEmployment, error) {
+	employment, err := s.ownedRequest(owner, employmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	alreadyApproved := employment.IsCurrent && employment.EmploymentStatus == models.EmploymentStatusActive
+	if !alreadyApproved {
+		if employment.EmploymentStatus != models.EmploymentStatusPending || !employment.IsCurrent {
+			return nil, ErrStaffRequestResolved
+		}
+		approved, err := s.staffRepo.ApprovePendingEmployment(employment.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to approve staff request: %w", err)
+		}
+		if !approved {
+			return nil, ErrStaffRequestResolved
+		}
+	}
+
+	staff, err := s.staffRepo.GetByID(employment.StaffID)
+	if err != nil {
+		return nil, err
+	}
+	if !staff.IsVerified || staff.VerificationStatus != models.StaffVerificationApproved {
+		now := time.Now()
+		staff.IsVerified = true
+		staff.VerificationStatus = models.StaffVerificationApproved
+		staff.VerifiedAt = &now
+		staff.VerifiedBy = &owner.UserID
+		if err := s.staffRepo.Update(staff); err != nil {
+			return nil, fmt.Errorf("failed to verify staff: %w", err)
+		}
+	}
+
+	userUUID, err := uuid.Parse(staff.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staff user ID: %w", err)
+	}
+	if err := s.userRepo.AddUserRole(userUUID, string(staff.StaffType)); err != nil {
+		return nil, fmt.Errorf("failed to grant %s role: %w", staff.StaffType, err)
+	}
+	s.claimsCache.Invalidate(userUUID)
+
+	if !alreadyApproved {
+		go s.notifyStaff(userUUID, staffRequestReviewedNotification(owner, employment.ID, true, ""))
+	}
+
+	employment, err = s.staffRepo.GetEmploymentByID(employment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get employment: %w", err)
+	}
+	return employment, nil
+}
+
+// RejectStaffRequest turns down a join request with a reason, which is sent to the staff member.
+// Their profile is untouched so they can ask another bus owner.
+func (s *StaffService) RejectStaffRequest(owner *models.BusOwner, employmentID, reason string) error {
+	employment, err := s.ownedRequest(owner, employmentID)
 	if err != nil {
-		return fmt.Errorf("failed to create employment record: %v", err)
+		return err
+	}
+	if employment.EmploymentStatus != models.EmploymentStatusPending || !employment.IsCurrent {
+		return ErrStaffRequestResolved
 	}
 
+	reason = strings.TrimSpace(reason)
+	rejected, err := s.staffRepo.RejectPendingEmployment(employment.ID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to reject staff request: %w", err)
+	}
+	if !rejected {
+		return ErrStaffRequestResolved
+	}
+
+	staff, err := s.staffRepo.GetByID(employment.StaffID)
+	if err != nil {
+		return err
+	}
+	if userUUID, err := uuid.Parse(staff.UserID); err == nil {
+		go s.notifyStaff(userUUID, staffRequestReviewedNotification(owner, employment.ID, false, reason))
+	}
 	return nil
 }
 
+// ownedRequest returns one of the owner's employment records
+func (s *StaffService) ownedRequest(owner *models.BusOwner, employmentID string) (*models.BusStaffEmployment, error) {
+	if _, err := uuid.Parse(employmentID); err != nil {
+		return nil, ErrStaffRequestNotFound
+	}
+	employment, err := s.staffRepo.GetEmploymentByID(employmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staff request: %w", err)
+	}
+	if employment == nil || employment.BusOwnerID != owner.ID {
+		return nil, ErrStaffRequestNotFound
+	}
+	return employment, nil
+}
+
+// notifyStaff pushes a review outcome to the staff member's devices, falling back to SMS when
+// they have no device registered for push
+func (s *StaffService) notifyStaff(userID uuid.UUID, msg push.Message) {
+	if s.push != nil && s.push.NotifyUser(userID, msg) > 0 {
+		return
+	}
+	if s.notifications == nil {
+		return
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil || user == nil {
+		fmt.Printf("WARNING: Failed to find staff user %s to notify: %v\n", userID, err)
+		return
+	}
+	if err := s.notifications.SendSMS(user.Phone, "SmartTransit: "+msg.Body); err != nil {
+		fmt.Printf("WARNING: Failed to send staff request SMS to user %s: %v\n", userID, err)
+	}
+}
+
+// staffJoinRequestNotification tells the bus owner a staff member wants to join
+func staffJoinRequestNotification(staff *models.BusStaff, employment *models.BusStaffEmployment) push.Message {
+	name := "A new staff member"
+	if staff.FirstName != nil && strings.TrimSpace(*staff.FirstName) != "" {
+		name = strings.TrimSpace(*staff.FirstName)
+		if staff.LastName != nil && strings.TrimSpace(*staff.LastName) != "" {
+			name += " " + strings.TrimSpace(*staff.LastName)
+		}
+	}
+	return push.Message{
+		Title: "New staff request",
+		Body:  fmt.Sprintf("%s wants to join your fleet as a %s. Review their documents to approve.", name, staff.StaffType),
+		Data: map[string]string{
+			"type":          "staff_join_request",
+			"employment_id": employment.ID,
+			"staff_id":      staff.ID,
+		},
+	}
+}
+
+// staffRequestReviewedNotification tells a staff member the owner's decision
+func staffRequestReviewedNotification(owner *models.BusOwner, employmentID string, approved bool, reason string) push.Message {
+	company := "The bus owner"
+	if owner.CompanyName != nil && strings.TrimSpace(*owner.CompanyName) != "" {
+		company = strings.TrimSpace(*owner.CompanyName)
+	}
+	if approved {
+		return push.Message{
+			Title: "Request approved",
+			Body:  fmt.Sprintf("%s approved your request. You can now be assigned to trips.", company),
+			Data: map[string]string{
+				"type":          "staff_request_approved",
+				"employment_id": employmentID,
+				"bus_owner_id":  owner.ID,
+			},
+		}
+	}
+	return push.Message{
+		Title: "Request declined",
+		Body:  fmt.Sprintf("%s declined your request: %s", company, reason),
+		Data: map[string]string{
+			"type":          "staff_request_rejected",
+			"employment_id": employmentID,
+			"bus_owner_id":  owner.ID,
+		},
+	}
+}
+
 // LinkStaffToBusOwner links staff to bus owner (used by bus owner to add staff)
 func (s *StaffService) LinkStaffToBusOwner(staffID, busOwnerID string) error {
 	// Verify staff exists
@@ -391,8 +641,9 @@ func (s *StaffService) LinkStaffToBusOwner(staffID, busOwnerID string) error {
 	return nil
 }
 
-// UnlinkStaff ends the employment of a staff member with a bus owner
-func (s *StaffService) UnlinkStaff(staffID, busOwnerID, reason string) error {
+// UnlinkStaff ends the employment of a staff member with a bus owner. Their device credentials
+// are revoked first, so a crew phone they keep can no longer sign in for the operator.
+func (s *StaffService) UnlinkStaff(staffID, busOwnerID, revokedByUserID string, status models.EmploymentStatus, reason string) error {
 	// Verify staff exists
 	_, err := s.staffRepo.GetByID(staffID)
 	if err != nil {
@@ -410,8 +661,14 @@ func (s *StaffService) UnlinkStaff(staffID, busOwnerID, reason string) error {
 		return fmt.Errorf("staff is not employed by this bus owner")
 	}
 
+	if s.devices != nil {
+		if _, err := s.devices.RevokeAllDevices(staffID, revokedByUserID, "staff_unlinked"); err != nil {
+			return fmt.Errorf("failed to revoke staff devices: %v", err)
+		}
+	}
+
 	// End the employment
-	err = s.staffRepo.EndEmployment(staffID, models.EmploymentStatusTerminated, reason)
+	err = s.staffRepo.EndEmployment(staffID, status, reason)
 	if err != nil {
 		return fmt.Errorf("failed to end employment: %v", err)
 	}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaffJoinRequestNotification(t *testing.T) {
+	first, last := "Nimal", "Perera"
+	staff := &models.BusStaff{ID: "staff-1", FirstName: &first, LastName: &last, StaffType: models.StaffTypeDriver}
+
+	msg := staffJoinRequestNotification(staff, &models.BusStaffEmployment{ID: "emp-1"})
+	assert.Equal(t, "New staff request", msg.Title)
+	assert.Contains(t, msg.Body, "Nimal Perera wants to join your fleet as a driver")
+	assert.Equal(t, map[string]string{"type": "staff_join_request", "employment_id": "emp-1", "staff_id": "staff-1"}, msg.Data)
+
+	msg = staffJoinRequestNotification(&models.BusStaff{StaffType: models.StaffTypeConductor}, &models.BusStaffEmployment{ID: "emp-2"})
+	assert.Contains(t, msg.Body, "A new staff member wants to join your fleet as a conductor")
+}
+
+func TestStaffRequestReviewedNotification(t *testing.T) {
+	company := "Lanka Express"
+	owner := &models.BusOwner{ID: "owner-1", CompanyName: &company}
+
+	approved := staffRequestReviewedNotification(owner, "emp-1", true, "")
+	assert.Equal(t, "staff_request_approved", approved.Data["type"])
+	assert.Equal(t, "owner-1", approved.Data["bus_owner_id"])
+	assert.Contains(t, approved.Body, "Lanka Express approved your request")
+
+	rejected := staffRequestReviewedNotification(&models.BusOwner{ID: "owner-2"}, "emp-2", false, "License expired")
+	assert.Equal(t, "staff_request_rejected", rejected.Data["type"])
+	assert.Equal(t, "The bus owner declined your request: License expired", rejected.Body)
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrStandingTripNotFound   = errors.New("trip not found or access denied")
+	ErrStandingTicketNotFound = errors.New("standing ticket not found")
+	ErrStandingDenied         = errors.New("only the trip's driver, conductor or bus owner can sell and validate its standing tickets")
+	ErrStandingNotOnSale      = errors.New("this trip does not sell standing tickets")
+	ErrStandingTripClosed     = errors.New("standing tickets are no longer sold for this trip")
+)
+
+// StandingTicketService sells unreserved standing places on routes that allow them, separate
+// from seat bookings. Owners set a per-trip cap that can never exceed the bus's licensed
+// standing capacity; passengers join the queue from the app and pay on boarding, or the crew
+// sells tickets on the spot. Standing passengers board in queue order when the conductor
+// validates their boarding token.
+type StandingTicketService struct {
+	repo   *database.StandingTicketRepository
+	logger *logrus.Logger
+}
+
+// NewStandingTicketService creates a new StandingTicketService
+func NewStandingTicketService(repo *database.StandingTicketRepository, logger *logrus.Logger) *StandingTicketService {
+	return &StandingTicketService{repo: repo, logger: logger}
+}
+
+// ============================================================================
+// OWNER SETTINGS
+// ============================================================================
+
+// GetTripStanding returns an owner's trip's standing cap, fare and counts
+func (s *StandingTicketService) GetTripStanding(scheduledTripID, busOwnerID string) (*models.TripStandingSummary, error) {
+	trip, err := s.ownerTrip(scheduledTripID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	return trip.Summary(), nil
+}
+
+// UpdateTripStanding sets how many standing tickets an owner's trip sells and at what fare
+func (s *StandingTicketService) UpdateTripStanding(scheduledTripID, busOwnerID string, req *models.UpdateTripStandingRequest) (*models.TripStandingSummary, error) {
+	trip, err := s.ownerTrip(scheduledTripID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := trip.CheckCap(req.Cap); err != nil {
+		return nil, err
+	}
+	fare := trip.BaseFare
+	if req.Fare != nil {
+		fare = *req.Fare
+	}
+	if err := trip.CheckFare(fare); err != nil {
+		return nil, err
+	}
+
+	settings := &models.TripStandingSettings{ScheduledTripID: scheduledTripID, Cap: req.Cap, Fare: fare}
+	if err := s.repo.UpsertSettings(settings); err != nil {
+		return nil, err
+	}
+	trip.Cap, trip.Fare, trip.SettingsUpdatedAt = &settings.Cap, &settings.Fare, &settings.UpdatedAt
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": scheduledTripID,
+		"cap":               settings.Cap,
+		"fare":              settings.Fare,
+	}).Info("Trip standing tickets updated")
+	return trip.Summary(), nil
+}
+
+func (s *StandingTicketService) ownerTrip(scheduledTripID, busOwnerID string) (*models.StandingTrip, error) {
+	trip, err := s.getTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.BusOwnerID == nil || *trip.BusOwnerID != busOwnerID {
+		return nil, ErrStandingTripNotFound
+	}
+	return trip, nil
+}
+
+// ============================================================================
+// PASSENGERS
+// ============================================================================
+
+// GetAvailability returns how many standing places a trip has left and what they cost
+func (s *StandingTicketService) GetAvailability(scheduledTripID string) (*models.TripStandingSummary, error) {
+	trip, err := s.getTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if !trip.IsBookable {
+		return nil, ErrStandingTripNotFound
+	}
+	return trip.Summary(), nil
+}
+
+// Reserve puts a passenger at the back of a trip's standing queue. They pay the conductor when
+// they board.
+func (s *StandingTicketService) Reserve(userID uuid.UUID, req *models.ReserveStandingTicketRequest) (*models.StandingTicket, error) {
+	trip, err := s.getTrip(req.ScheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if !trip.IsBookable {
+		return nil, ErrStandingTripNotFound
+	}
+	if trip.EffectiveCap() == 0 {
+		return nil, ErrStandingNotOnSale
+	}
+	if !trip.AcceptsReservations(time.Now()) {
+		return nil, ErrStandingTripClosed
+	}
+
+	passenger := userID.String()
+	ticket := &models.StandingTicket{
+		ScheduledTripID: trip.ScheduledTripID,
+		Source:          models.StandingTicketSourceApp,
+		PassengerUserID: &passenger,
+		Fare:            trip.StandingFare(),
+		PaymentStatus:   models.StandingPaymentCollectOnBus,
+	}
+	if err := s.repo.Issue(ticket); err != nil {
+		return nil, err
+	}
+	if err := s.fillAhead(ticket); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": ticket.ScheduledTripID,
+		"queue_position":    ticket.QueuePosition,
+		"user_id":           passenger,
+	}).Info("Standing ticket reserved")
+	return ticket, nil
+}
+
+// ListMyTickets returns a passenger's standing tickets for upcoming and recent trips
+func (s *StandingTicketService) ListMyTickets(userID uuid.UUID) ([]models.StandingTicket, error) {
+	return s.repo.ListPassengerTickets(userID.String())
+}
+
+// GetMyTicket returns a passenger's standing ticket with how many passengers are ahead of them
+func (s *StandingTicketService) GetMyTicket(ticketID string, userID uuid.UUID) (*models.StandingTicket, error) {
+	ticket, err := s.passengerTicket(ticketID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillAhead(ticket); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// CancelMyTicket gives up a passenger's place in the standing queue
+func (s *StandingTicketService) CancelMyTicket(ticketID string, userID uuid.UUID) error {
+	ticket, err := s.passengerTicket(ticketID, userID)
+	if err != nil {
+		return err
+	}
+	cancelled, err := s.repo.Cancel(ticket.ID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return database.ErrStandingTicketNotWaiting
+	}
+	return nil
+}
+
+func (s *StandingTicketService) passengerTicket(ticketID string, userID uuid.UUID) (*models.StandingTicket, error) {
+	if _, err := uuid.Parse(ticketID); err != nil {
+		return nil, ErrStandingTicketNotFound
+	}
+	ticket, err := s.repo.GetByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil || ticket.PassengerUserID == nil || *ticket.PassengerUserID != userID.String() {
+		return nil, ErrStandingTicketNotFound
+	}
+	return ticket, nil
+}
+
+func (s *StandingTicketService) fillAhead(ticket *models.StandingTicket) error {
+	if ticket.Status != models.StandingTicketWaiting {
+		return nil
+	}
+	ahead, err := s.repo.CountAhead(ticket)
+	if err != nil {
+		return err
+	}
+	ticket.PassengersAhead = &ahead
+	return nil
+}
+
+// ============================================================================
+// CREW
+// ============================================================================
+
+// Issue sells a standing ticket on the spot, paid in cash. The crew can keep selling after
+// departure while the trip is under way.
+func (s *StandingTicketService) Issue(scheduledTripID string, userID uuid.UUID, req *models.IssueStandingTicketRequest) (*models.StandingTicket, error) {
+	trip, err := s.crewTrip(scheduledTripID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.EffectiveCap() == 0 {
+		return nil, ErrStandingNotOnSale
+	}
+	if !trip.AcceptsCrewSales() {
+		return nil, ErrStandingTripClosed
+	}
+
+	issuer := userID.String()
+	ticket := &models.StandingTicket{
+		ScheduledTripID: trip.ScheduledTripID,
+		Source:          models.StandingTicketSourceConductor,
+		PassengerName:   req.PassengerName,
+		Fare:            trip.StandingFare(),
+		PaymentStatus:   models.StandingPaymentPaid,
+		IssuedByUserID:  &issuer,
+	}
+	if err := s.repo.Issue(ticket); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": ticket.ScheduledTripID,
+		"queue_position":    ticket.QueuePosition,
+		"issued_by":         issuer,
+	}).Info("Standing ticket sold by crew")
+	return ticket, nil
+}
+
+// GetQueue returns a trip's standing passengers waiting to board, in order, for its crew
+func (s *StandingTicketService) GetQueue(scheduledTripID string, userID uuid.UUID) (*models.StandingQueue, error) {
+	trip, err := s.crewTrip(scheduledTripID, userID)
+	if err != nil {
+		return nil, err
+	}
+	waiting, err := s.repo.ListWaiting(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	queue := &models.StandingQueue{Summary: trip.Summary(), Waiting: waiting}
+	if len(waiting) > 0 {
+		queue.Next = &waiting[0]
+	}
+	return queue, nil
+}
+
+// Validate checks a standing passenger's boarding token and boards them if they are next in
+// the queue and the bus has standing room left. Returns a *database.StandingQueueOrderError
+// when passengers ahead are still waiting; skip them first if they are not there.
+func (s *StandingTicketService) Validate(token string, userID uuid.UUID) (*models.StandingTicket, error) {
+	ticket, err := s.repo.GetByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil {
+		return nil, ErrStandingTicketNotFound
+	}
+	trip, err := s.crewTrip(ticket.ScheduledTripID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status == models.ScheduledTripStatusCancelled || trip.Status == models.ScheduledTripStatusCompleted {
+		return nil, ErrStandingTripClosed
+	}
+
+	if err := s.repo.Board(ticket, userID.String()); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": ticket.ScheduledTripID,
+		"queue_position":    ticket.QueuePosition,
+		"boarded_by":        userID,
+	}).Info("Standing passenger boarded")
+	return ticket, nil
+}
+
+// Skip takes a waiting passenger who was not there when called out of the queue, so the
+// passengers behind them can board
+func (s *StandingTicketService) Skip(ticketID string, userID uuid.UUID) (*models.StandingTicket, error) {
+	if _, err := uuid.Parse(ticketID); err != nil {
+		return nil, ErrStandingTicketNotFound
+	}
+	ticket, err := s.repo.GetByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil {
+		return nil, ErrStandingTicketNotFound
+	}
+	if _, err := s.crewTrip(ticket.ScheduledTripID, userID); err != nil {
+		return nil, err
+	}
+
+	skipped, err := s.repo.Skip(ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !skipped {
+		return nil, database.ErrStandingTicketNotWaiting
+	}
+	now := time.Now()
+	ticket.Status, ticket.SkippedAt = models.StandingTicketSkipped, &now
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": ticket.ScheduledTripID,
+		"queue_position":    ticket.QueuePosition,
+		"skipped_by":        userID,
+	}).Info("Standing passenger skipped")
+	return ticket, nil
+}
+
+func (s *StandingTicketService) crewTrip(scheduledTripID string, userID uuid.UUID) (*models.StandingTrip, error) {
+	trip, err := s.getTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if !trip.IsCrew(userID.String()) {
+		return nil, ErrStandingDenied
+	}
+	return trip, nil
+}
+
+func (s *StandingTicketService) getTrip(scheduledTripID string) (*models.StandingTrip, error) {
+	if _, err := uuid.Parse(scheduledTripID); err != nil {
+		return nil, ErrStandingTripNotFound
+	}
+	trip, err := s.repo.GetTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, ErrStandingTripNotFound
+	}
+	return trip, nil
+}
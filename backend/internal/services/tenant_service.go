@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/internal/utils"
+)
+
+var (
+	ErrTenantNotFound         = errors.New("tenant not found")
+	ErrTenantSlugTaken        = errors.New("tenant slug is already in use")
+	ErrInvalidTenantSlug      = errors.New("slug may only contain lowercase letters, digits and hyphens")
+	ErrTenantAPIKeyNotFound   = errors.New("tenant API key not found")
+	ErrTenantBusOwnerNotFound = errors.New("bus owner not found")
+	ErrIncompleteMerchant     = errors.New("payment_merchant_key and payment_merchant_token must be set together")
+)
+
+const (
+	tenantAPIKeyPrefix    = "stk_"
+	tenantAPIKeyBytes     = 24
+	tenantAPIKeyShownSize = 12 // Characters kept in key_prefix so admins can recognise a key
+)
+
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// TenantService manages white-label tenants, their configuration and their app API keys
+type TenantService struct {
+	repo   *database.TenantRepository
+	logger *logrus.Logger
+}
+
+// NewTenantService creates a new TenantService
+func NewTenantService(repo *database.TenantRepository, logger *logrus.Logger) *TenantService {
+	return &TenantService{repo: repo, logger: logger}
+}
+
+// CreateTenant registers a new tenant
+func (s *TenantService) CreateTenant(req *models.CreateTenantRequest) (*models.Tenant, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !tenantSlugPattern.MatchString(slug) || slug == models.DefaultTenant().Slug {
+		return nil, ErrInvalidTenantSlug
+	}
+	if (req.PaymentMerchantKey == nil) != (req.PaymentMerchantToken == nil) {
+		return nil, ErrIncompleteMerchant
+	}
+
+	exists, err := s.repo.SlugExists(slug)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrTenantSlugTaken
+	}
+
+	tenant := &models.Tenant{
+		Slug:                 slug,
+		Name:                 req.Name,
+		IsActive:             true,
+		AppName:              req.AppName,
+		LogoURL:              req.LogoURL,
+		PrimaryColor:         req.PrimaryColor,
+		SupportPhone:         req.SupportPhone,
+		SupportEmail:         req.SupportEmail,
+		SMSMask:              req.SMSMask,
+		PaymentMerchantKey:   req.PaymentMerchantKey,
+		PaymentMerchantToken: req.PaymentMerchantToken,
+		PaymentPackageName:   req.PaymentPackageName,
+	}
+	if err := s.repo.Create(tenant); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"tenant_id": tenant.ID,
+		"slug":      tenant.Slug,
+	}).Info("Tenant created")
+	return tenant, nil
+}
+
+// UpdateTenant changes a tenant's configuration
+func (s *TenantService) UpdateTenant(id string, req *models.UpdateTenantRequest) (*models.Tenant, error) {
+	tenant, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, ErrTenantNotFound
+	}
+
+	applyTenantUpdate(tenant, req)
+	if (tenant.PaymentMerchantKey == nil) != (tenant.PaymentMerchantToken == nil) {
+		return nil, ErrIncompleteMerchant
+	}
+
+	if err := s.repo.Update(tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// GetTenant returns a tenant
+func (s *TenantService) GetTenant(id string) (*models.Tenant, error) {
+	tenant, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, ErrTenantNotFound
+	}
+	return tenant, nil
+}
+
+// ListTenants returns all tenants
+func (s *TenantService) ListTenants() ([]models.Tenant, error) {
+	return s.repo.List()
+}
+
+// IssueAPIKey creates an API key for a tenant's app. The raw key is returned only here;
+// afterwards only its prefix is visible.
+func (s *TenantService) IssueAPIKey(tenantID string, req *models.CreateTenantAPIKeyRequest) (*models.TenantAPIKey, string, error) {
+	if _, err := s.GetTenant(tenantID); err != nil {
+		return nil, "", err
+	}
+
+	rawKey, err := generateTenantAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key := &models.TenantAPIKey{
+		TenantID:  tenantID,
+		Name:      req.Name,
+		KeyPrefix: rawKey[:tenantAPIKeyShownSize],
+	}
+	if err := s.repo.CreateAPIKey(key, rawKey); err != nil {
+		return nil, "", err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"tenant_id":  tenantID,
+		"key_id":     key.ID,
+		"key_prefix": key.KeyPrefix,
+	}).Info("Tenant API key issued")
+	return key, rawKey, nil
+}
+
+// ListAPIKeys returns a tenant's API keys, including revoked ones
+func (s *TenantService) ListAPIKeys(tenantID string) ([]models.TenantAPIKey, error) {
+	if _, err := s.GetTenant(tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAPIKeys(tenantID)
+}
+
+// RevokeAPIKey stops a key from being accepted. Cached lookups expire within a minute.
+func (s *TenantService) RevokeAPIKey(tenantID, keyID string) error {
+	revoked, err := s.repo.RevokeAPIKey(tenantID, keyID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrTenantAPIKeyNotFound
+	}
+	return nil
+}
+
+// AssignBusOwner moves a bus owner and its upcoming trips to a tenant (nil = SmartTransit)
+func (s *TenantService) AssignBusOwner(busOwnerID string, tenantID *string) error {
+	if tenantID != nil {
+		if _, err := s.GetTenant(*tenantID); err != nil {
+			return err
+		}
+	}
+
+	assigned, err := s.repo.AssignBusOwner(busOwnerID, tenantID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return ErrTenantBusOwnerNotFound
+	}
+	return nil
+}
+
+// applyTenantUpdate copies the fields set in req onto tenant. An empty string clears an optional field.
+func applyTenantUpdate(tenant *models.Tenant, req *models.UpdateTenantRequest) {
+	if req.Name != nil {
+		tenant.Name = *req.Name
+	}
+	if req.AppName != nil {
+		tenant.AppName = *req.AppName
+	}
+	if req.IsActive != nil {
+		tenant.IsActive = *req.IsActive
+	}
+
+	optional := []struct {
+		value  *string
+		target **string
+	}{
+		{req.LogoURL, &tenant.LogoURL},
+		{req.PrimaryColor, &tenant.PrimaryColor},
+		{req.SupportPhone, &tenant.SupportPhone},
+		{req.SupportEmail, &tenant.SupportEmail},
+		{req.SMSMask, &tenant.SMSMask},
+		{req.PaymentMerchantKey, &tenant.PaymentMerchantKey},
+		{req.PaymentMerchantToken, &tenant.PaymentMerchantToken},
+		{req.PaymentPackageName, &tenant.PaymentPackageName},
+	}
+	for _, field := range optional {
+		if field.value == nil {
+			continue
+		}
+		if *field.value == "" {
+			*field.target = nil
+		} else {
+			value := *field.value
+			*field.target = &value
+		}
+	}
+}
+
+// generateTenantAPIKey returns a new random tenant API key
+func generateTenantAPIKey() (string, error) {
+	secret, err := utils.GenerateSecret(tenantAPIKeyBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate tenant API key: %w", err)
+	}
+	return tenantAPIKeyPrefix + secret, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTenantUpdate_SetsClearsAndKeepsFields(t *testing.T) {
+	tenant := &models.Tenant{
+		Name:         "Lanka Express",
+		AppName:      "LankaGo",
+		IsActive:     true,
+		LogoURL:      stringPtr("https://cdn.example.lk/old.png"),
+		SMSMask:      stringPtr("LankaGo"),
+		SupportPhone: stringPtr("+94112345678"),
+	}
+	inactive := false
+
+	applyTenantUpdate(tenant, &models.UpdateTenantRequest{
+		AppName:  stringPtr("Lanka Go"),
+		IsActive: &inactive,
+		LogoURL:  stringPtr("https://cdn.example.lk/new.png"),
+		SMSMask:  stringPtr(""),
+	})
+
+	assert.Equal(t, "Lanka Express", tenant.Name)
+	assert.Equal(t, "Lanka Go", tenant.AppName)
+	assert.False(t, tenant.IsActive)
+	require.NotNil(t, tenant.LogoURL)
+	assert.Equal(t, "https://cdn.example.lk/new.png", *tenant.LogoURL)
+	assert.Nil(t, tenant.SMSMask, "an empty string clears the mask so the platform mask is used")
+	require.NotNil(t, tenant.SupportPhone)
+	assert.Equal(t, "+94112345678", *tenant.SupportPhone)
+}
+
+func TestGenerateTenantAPIKey_IsPrefixedAndUnique(t *testing.T) {
+	first, err := generateTenantAPIKey()
+	require.NoError(t, err)
+	second, err := generateTenantAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, tenantAPIKeyPrefix))
+	assert.Len(t, first, len(tenantAPIKeyPrefix)+tenantAPIKeyBytes*2)
+	assert.NotEqual(t, first, second)
+	assert.Greater(t, len(first), tenantAPIKeyShownSize)
+}
+
+func TestTenantSlugPattern(t *testing.T) {
+	for _, slug := range []string{"lanka-express", "nct2", "a1-b2"} {
+		assert.True(t, tenantSlugPattern.MatchString(slug), slug)
+	}
+	for _, slug := range []string{"Lanka", "-lanka", "lanka-", "lanka--express", "lanka express"} {
+		assert.False(t, tenantSlugPattern.MatchString(slug), slug)
+	}
+}
+
+func TestIntentTenantMatches_RejectsOtherOperatorsTrips(t *testing.T) {
+	lankaExpress := &models.Tenant{ID: "9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d", Slug: "lanka-express"}
+	nct := &models.Tenant{ID: "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bed", Slug: "nct"}
+
+	assert.True(t, intentTenantMatches(nil, models.DefaultTenant()))
+	assert.True(t, intentTenantMatches(stringPtr(lankaExpress.ID), lankaExpress))
+
+	assert.False(t, intentTenantMatches(stringPtr(lankaExpress.ID), nct), "another tenant's key would pay the wrong merchant")
+	assert.False(t, intentTenantMatches(stringPtr(lankaExpress.ID), models.DefaultTenant()))
+	assert.False(t, intentTenantMatches(nil, lankaExpress), "a SmartTransit trip is paid to the platform account")
+}
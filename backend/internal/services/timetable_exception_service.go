@@ -0,0 +1,243 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrTimetableExceptionNotFound         = errors.New("timetable exception not found")
+	ErrTimetableExceptionScheduleNotFound = errors.New("schedule not found or access denied")
+	ErrTimetableExceptionDuplicate        = errors.New("an exception already exists for this date")
+)
+
+// TimetableExceptionService manages the holiday calendar used by trip generation: system-level
+// holidays set by admins and each schedule's own exceptions, which can skip a date, move its
+// departure time, or run through a system holiday.
+type TimetableExceptionService struct {
+	repo         *database.TimetableExceptionRepository
+	scheduleRepo *database.TripScheduleRepository
+	tripRepo     *database.ScheduledTripRepository
+	logger       *logrus.Logger
+}
+
+// NewTimetableExceptionService creates a new TimetableExceptionService
+func NewTimetableExceptionService(
+	repo *database.TimetableExceptionRepository,
+	scheduleRepo *database.TripScheduleRepository,
+	tripRepo *database.ScheduledTripRepository,
+	logger *logrus.Logger,
+) *TimetableExceptionService {
+	return &TimetableExceptionService{
+		repo:         repo,
+		scheduleRepo: scheduleRepo,
+		tripRepo:     tripRepo,
+		logger:       logger,
+	}
+}
+
+// TimetableExceptionResult is a saved exception, with the trip already generated for its date
+// that the change does not touch
+type TimetableExceptionResult struct {
+	Exception              *models.TimetableException `json:"exception"`
+	AlreadyGeneratedTripID *string                    `json:"already_generated_trip_id,omitempty"`
+}
+
+// ============================================================================
+// SCHEDULE EXCEPTIONS
+// ============================================================================
+
+// ListScheduleExceptions returns the exceptions applying to the owner's schedule from today:
+// its own and the system holidays
+func (s *TimetableExceptionService) ListScheduleExceptions(scheduleID, busOwnerID string) ([]models.TimetableException, error) {
+	if err := s.checkScheduleOwner(scheduleID, busOwnerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListForSchedule(scheduleID, timetableToday())
+}
+
+// CreateScheduleException adds an exception to the owner's schedule
+func (s *TimetableExceptionService) CreateScheduleException(scheduleID, busOwnerID string, userID uuid.UUID, req *models.TimetableExceptionRequest) (*TimetableExceptionResult, error) {
+	if err := s.checkScheduleOwner(scheduleID, busOwnerID); err != nil {
+		return nil, err
+	}
+	exception := &models.TimetableException{TripScheduleID: &scheduleID, CreatedByUserID: userID.String()}
+	if err := s.save(exception, req); err != nil {
+		return nil, err
+	}
+	return s.result(exception), nil
+}
+
+// UpdateScheduleException replaces one of the owner's schedule's exceptions
+func (s *TimetableExceptionService) UpdateScheduleException(scheduleID, exceptionID, busOwnerID string, req *models.TimetableExceptionRequest) (*TimetableExceptionResult, error) {
+	exception, err := s.getScheduleException(scheduleID, exceptionID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(exception, req); err != nil {
+		return nil, err
+	}
+	return s.result(exception), nil
+}
+
+// DeleteScheduleException removes one of the owner's schedule's exceptions
+func (s *TimetableExceptionService) DeleteScheduleException(scheduleID, exceptionID, busOwnerID string) error {
+	if _, err := s.getScheduleException(scheduleID, exceptionID, busOwnerID); err != nil {
+		return err
+	}
+	return s.repo.Delete(exceptionID)
+}
+
+func (s *TimetableExceptionService) getScheduleException(scheduleID, exceptionID, busOwnerID string) (*models.TimetableException, error) {
+	if err := s.checkScheduleOwner(scheduleID, busOwnerID); err != nil {
+		return nil, err
+	}
+	exception, err := s.repo.GetByID(exceptionID)
+	if err != nil {
+		return nil, err
+	}
+	// System holidays are listed with a schedule's exceptions but only admins may change them
+	if exception == nil || exception.TripScheduleID == nil || *exception.TripScheduleID != scheduleID {
+		return nil, ErrTimetableExceptionNotFound
+	}
+	return exception, nil
+}
+
+func (s *TimetableExceptionService) checkScheduleOwner(scheduleID, busOwnerID string) error {
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrTimetableExceptionScheduleNotFound
+		}
+		return fmt.Errorf("failed to get schedule: %w", err)
+	}
+	if schedule.BusOwnerID != busOwnerID {
+		return ErrTimetableExceptionScheduleNotFound
+	}
+	return nil
+}
+
+// result reports a trip the generator already created for a one-off skip or adjust date, which
+// the owner has to cancel or reschedule by hand
+func (s *TimetableExceptionService) result(exception *models.TimetableException) *TimetableExceptionResult {
+	result := &TimetableExceptionResult{Exception: exception}
+	if exception.RecursYearly || exception.Action == models.TimetableExceptionRun {
+		return result
+	}
+	date, err := time.Parse("2006-01-02", exception.ExceptionDate)
+	if err != nil {
+		return result
+	}
+	trip, err := s.tripRepo.GetByScheduleAndDate(*exception.TripScheduleID, date)
+	if err == nil && trip != nil && trip.Status != models.ScheduledTripStatusCancelled {
+		result.AlreadyGeneratedTripID = &trip.ID
+	}
+	return result
+}
+
+// ============================================================================
+// SYSTEM HOLIDAYS
+// ============================================================================
+
+// ListSystemHolidays returns the system holiday calendar from today, with yearly holidays
+func (s *TimetableExceptionService) ListSystemHolidays() ([]models.TimetableException, error) {
+	return s.repo.ListSystem(timetableToday())
+}
+
+// CreateSystemHoliday adds a holiday on which no schedule generates trips unless it opts out
+func (s *TimetableExceptionService) CreateSystemHoliday(userID uuid.UUID, req *models.TimetableExceptionRequest) (*models.TimetableException, error) {
+	exception := &models.TimetableException{CreatedByUserID: userID.String()}
+	if err := s.save(exception, req); err != nil {
+		return nil, err
+	}
+	return exception, nil
+}
+
+// UpdateSystemHoliday replaces a system holiday
+func (s *TimetableExceptionService) UpdateSystemHoliday(exceptionID string, req *models.TimetableExceptionRequest) (*models.TimetableException, error) {
+	exception, err := s.getSystemHoliday(exceptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(exception, req); err != nil {
+		return nil, err
+	}
+	return exception, nil
+}
+
+// DeleteSystemHoliday removes a system holiday
+func (s *TimetableExceptionService) DeleteSystemHoliday(exceptionID string) error {
+	if _, err := s.getSystemHoliday(exceptionID); err != nil {
+		return err
+	}
+	return s.repo.Delete(exceptionID)
+}
+
+func (s *TimetableExceptionService) getSystemHoliday(exceptionID string) (*models.TimetableException, error) {
+	exception, err := s.repo.GetByID(exceptionID)
+	if err != nil {
+		return nil, err
+	}
+	if exception == nil || !exception.IsSystem() {
+		return nil, ErrTimetableExceptionNotFound
+	}
+	return exception, nil
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+// save validates the request onto the exception and creates it, or updates it if it has an ID
+func (s *TimetableExceptionService) save(exception *models.TimetableException, req *models.TimetableExceptionRequest) error {
+	if err := req.Validate(exception.IsSystem()); err != nil {
+		return err
+	}
+	count, err := s.repo.CountDuplicate(exception.TripScheduleID, req.ExceptionDate, req.RecursYearly, exception.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTimetableExceptionDuplicate
+	}
+
+	exception.ExceptionDate = req.ExceptionDate
+	exception.RecursYearly = req.RecursYearly
+	exception.Action = req.Action
+	exception.DepartureTime = nil
+	if req.Action == models.TimetableExceptionAdjust {
+		exception.DepartureTime = req.DepartureTime
+	}
+	exception.Reason = strings.TrimSpace(req.Reason)
+
+	if exception.ID == "" {
+		err = s.repo.Create(exception)
+	} else {
+		err = s.repo.Update(exception)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"exception_id":     exception.ID,
+		"trip_schedule_id": exception.TripScheduleID,
+		"exception_date":   exception.ExceptionDate,
+		"recurs_yearly":    exception.RecursYearly,
+		"action":           exception.Action,
+	}).Info("Timetable exception saved")
+	return nil
+}
+
+// timetableToday is the current date in Sri Lanka as YYYY-MM-DD
+func timetableToday() string {
+	return time.Now().In(models.ReportTimezone).Format("2006-01-02")
+}
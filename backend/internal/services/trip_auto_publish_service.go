@@ -0,0 +1,284 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrAutoPublishScheduleNotFound = errors.New("schedule not found or access denied")
+	ErrAutoPublishAlertNotFound    = errors.New("alert not found")
+)
+
+// TripAutoPublishService lets owners have a schedule's generated trips go live on their own and
+// runs the job that publishes them. A due trip that cannot be published (no bus or seat layout,
+// fare above the permit, layout over capacity) raises an alert on the owner's dashboard rather
+// than being skipped silently; the job retries it every run until it is published or departs.
+type TripAutoPublishService struct {
+	repo           *database.TripAutoPublishRepository
+	scheduleRepo   *database.TripScheduleRepository
+	tripRepo       *database.ScheduledTripRepository
+	tripSeatRepo   *database.TripSeatRepository
+	fareCompliance *FareComplianceService
+	seatCapacity   *SeatCapacityService
+	searchCache    *SearchCache
+	seatPricing    *SeatPricingService
+	config         config.TripAutoPublishConfig
+	logger         *logrus.Logger
+	stopCh         chan struct{}
+}
+
+// NewTripAutoPublishService creates a new TripAutoPublishService
+func NewTripAutoPublishService(
+	repo *database.TripAutoPublishRepository,
+	scheduleRepo *database.TripScheduleRepository,
+	tripRepo *database.ScheduledTripRepository,
+	tripSeatRepo *database.TripSeatRepository,
+	fareCompliance *FareComplianceService,
+	seatCapacity *SeatCapacityService,
+	searchCache *SearchCache,
+	seatPricing *SeatPricingService,
+	cfg config.TripAutoPublishConfig,
+	logger *logrus.Logger,
+) *TripAutoPublishService {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 15 * time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 200
+	}
+	return &TripAutoPublishService{
+		repo:           repo,
+		scheduleRepo:   scheduleRepo,
+		tripRepo:       tripRepo,
+		tripSeatRepo:   tripSeatRepo,
+		fareCompliance: fareCompliance,
+		seatCapacity:   seatCapacity,
+		searchCache:    searchCache,
+		seatPricing:    seatPricing,
+		config:         cfg,
+		logger:         logger,
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// OWNER SETTINGS AND ALERTS
+// ============================================================================
+
+// GetSettings returns the owner's schedule's auto-publish settings, off if never configured
+func (s *TripAutoPublishService) GetSettings(scheduleID, busOwnerID string) (*models.TripAutoPublishSettings, error) {
+	if err := s.checkScheduleOwner(scheduleID, busOwnerID); err != nil {
+		return nil, err
+	}
+	settings, err := s.repo.GetSettings(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &models.TripAutoPublishSettings{TripScheduleID: scheduleID, DaysBeforeDeparture: models.DefaultAutoPublishDaysBefore}
+	}
+	return settings, nil
+}
+
+// UpdateSettings turns auto-publish on or off for the owner's schedule
+func (s *TripAutoPublishService) UpdateSettings(scheduleID, busOwnerID string, req *models.UpdateTripAutoPublishRequest) (*models.TripAutoPublishSettings, error) {
+	settings, err := s.GetSettings(scheduleID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	settings.Enabled = req.Enabled
+	if req.DaysBeforeDeparture != nil {
+		settings.DaysBeforeDeparture = *req.DaysBeforeDeparture
+	}
+	if err := s.repo.UpsertSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// ListAlerts returns the owner's trips that are due but could not be published
+func (s *TripAutoPublishService) ListAlerts(busOwnerID string, includeDismissed bool) ([]models.TripAutoPublishAlert, error) {
+	return s.repo.ListOwnerAlerts(busOwnerID, includeDismissed)
+}
+
+// DismissAlert hides an alert until the reason the trip cannot be published changes
+func (s *TripAutoPublishService) DismissAlert(alertID, busOwnerID string) error {
+	dismissed, err := s.repo.DismissAlert(alertID, busOwnerID)
+	if err != nil {
+		return err
+	}
+	if !dismissed {
+		return ErrAutoPublishAlertNotFound
+	}
+	return nil
+}
+
+func (s *TripAutoPublishService) checkScheduleOwner(scheduleID, busOwnerID string) error {
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrAutoPublishScheduleNotFound
+		}
+		return fmt.Errorf("failed to get schedule: %w", err)
+	}
+	if schedule.BusOwnerID != busOwnerID {
+		return ErrAutoPublishScheduleNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// BACKGROUND PUBLISHING
+// ============================================================================
+
+// Start begins the background auto-publish job
+func (s *TripAutoPublishService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Trip auto-publish job disabled (TRIP_AUTO_PUBLISH_ENABLED=false)")
+		return
+	}
+	s.logger.WithField("interval", s.config.CheckInterval.String()).Info("🚌 Starting Trip Auto-Publish job")
+	go s.run()
+}
+
+// Stop stops the background auto-publish job
+func (s *TripAutoPublishService) Stop() {
+	if !s.config.Enabled {
+		return
+	}
+	s.logger.Info("🛑 Stopping Trip Auto-Publish job")
+	close(s.stopCh)
+}
+
+func (s *TripAutoPublishService) run() {
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	s.publishDue()
+	for {
+		select {
+		case <-ticker.C:
+			s.publishDue()
+		case <-s.stopCh:
+			s.logger.Info("Trip Auto-Publish job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single publishing cycle (useful for testing or manual trigger)
+func (s *TripAutoPublishService) RunOnce() {
+	s.publishDue()
+}
+
+func (s *TripAutoPublishService) publishDue() {
+	if resolved, err := s.repo.ResolveStaleAlerts(); err != nil {
+		s.logger.WithError(err).Error("Failed to resolve stale auto-publish alerts")
+	} else if resolved > 0 {
+		s.logger.WithField("count", resolved).Info("Resolved auto-publish alerts")
+	}
+
+	due, err := s.repo.GetDueTrips(s.config.BatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get trips due for auto-publish")
+		return
+	}
+
+	published, alerted := 0, 0
+	for i := range due {
+		trip := &due[i]
+		reason, message, err := s.publish(trip)
+		if err != nil {
+			// Lookups failing is not the owner's to fix; try again next run
+			s.logger.WithError(err).WithField("trip_id", trip.TripID).Error("Auto-publish check failed")
+			continue
+		}
+		if reason != "" {
+			alerted++
+			if err := s.repo.RaiseAlert(trip, reason, message); err != nil {
+				s.logger.WithError(err).WithField("trip_id", trip.TripID).Error("Failed to raise auto-publish alert")
+			}
+			continue
+		}
+		published++
+		if err := s.repo.ResolveTripAlerts(trip.TripID); err != nil {
+			s.logger.WithError(err).WithField("trip_id", trip.TripID).Warn("Failed to resolve auto-publish alert")
+		}
+	}
+
+	if published > 0 || alerted > 0 {
+		s.logger.WithFields(logrus.Fields{"published": published, "alerted": alerted}).Info("Trip auto-publish run finished")
+	}
+}
+
+// publish runs the checks a manual publish does and publishes the trip. It returns the reason
+// and message for the owner's alert when the trip cannot go live, or an error when a check
+// could not be made.
+func (s *TripAutoPublishService) publish(trip *models.DueAutoPublishTrip) (models.AutoPublishAlertReason, string, error) {
+	if reason, message := trip.Blocker(); reason != "" {
+		return reason, message, nil
+	}
+	tripIDs := []string{trip.TripID}
+
+	if s.fareCompliance != nil {
+		var complianceErr *models.FareComplianceError
+		if err := s.fareCompliance.CheckPublish(tripIDs); errors.As(err, &complianceErr) {
+			return models.AutoPublishFareAbovePermit, complianceErr.Message, nil
+		} else if err != nil {
+			return "", "", err
+		}
+	}
+	if s.seatCapacity != nil {
+		var capacityErr *models.SeatCapacityError
+		if err := s.seatCapacity.CheckTrips(tripIDs, nil); errors.As(err, &capacityErr) {
+			return models.AutoPublishOverCapacity, capacityErr.Message, nil
+		} else if err != nil {
+			return "", "", err
+		}
+	}
+
+	seats, err := s.tripSeatRepo.GetByScheduledTripID(trip.TripID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check trip seats: %w", err)
+	}
+	if len(seats) == 0 {
+		if _, err := s.tripSeatRepo.CreateTripSeatsFromLayout(trip.TripID, *trip.SeatLayoutID, trip.BaseFare); err != nil {
+			return models.AutoPublishSeatsFailed, "Seats could not be created from the assigned layout; try assigning the seat layout again", nil
+		}
+		if err := s.seatPricing.RepriceTrip(trip.TripID); err != nil {
+			s.logger.WithError(err).WithField("trip_id", trip.TripID).Warn("Failed to apply pricing rules to auto-published trip")
+		}
+	}
+
+	if err := s.tripRepo.PublishTrip(trip.TripID, trip.BusOwnerID); err != nil {
+		return models.AutoPublishFailed, "The trip could not be published: " + err.Error(), nil
+	}
+	s.invalidateSearches(trip.TripID)
+	s.logger.WithFields(logrus.Fields{
+		"trip_id":     trip.TripID,
+		"schedule_id": trip.TripScheduleID,
+		"departure":   trip.DepartureDatetime,
+	}).Info("Trip auto-published")
+	return "", "", nil
+}
+
+// invalidateSearches drops cached searches on the corridor of a newly published trip
+func (s *TripAutoPublishService) invalidateSearches(tripID string) {
+	if s.searchCache == nil {
+		return
+	}
+	routeIDs, err := s.tripRepo.GetMasterRouteIDs([]string{tripID})
+	if err != nil {
+		s.logger.WithError(err).WithField("trip_id", tripID).Warn("Search cache: failed to find trip routes")
+		return
+	}
+	s.searchCache.InvalidateRoutes(routeIDs...)
+}
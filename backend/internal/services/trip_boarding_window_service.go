@@ -0,0 +1,232 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrBoardingTripNotFound       = errors.New("trip not found or access denied")
+	ErrBoardingBookingNotFound    = errors.New("booking not found")
+	ErrBoardingOverrideNotAllowed = errors.New("only the trip's bus owner can override the boarding window")
+	ErrBoardingOverrideReason     = errors.New("override_reason is required to board outside the boarding window")
+)
+
+// Boarding audit actions
+const (
+	BoardingActionCheckIn = "check_in"
+	BoardingActionBoard   = "board"
+)
+
+// BoardingWindowError is returned when a check-in or boarding falls outside the trip's window
+type BoardingWindowError struct {
+	State    models.BoardingWindowState
+	OpensAt  time.Time
+	ClosesAt time.Time
+}
+
+func (e *BoardingWindowError) Error() string {
+	if e.State == models.BoardingWindowNotOpen {
+		return fmt.Sprintf("boarding opens at %s", e.OpensAt.In(models.ReportTimezone).Format("15:04"))
+	}
+	return fmt.Sprintf("boarding closed at %s", e.ClosesAt.In(models.ReportTimezone).Format("15:04"))
+}
+
+// BoardingAttempt is a staff request to check in or board a passenger
+type BoardingAttempt struct {
+	Action         string // BoardingActionCheckIn or BoardingActionBoard
+	UserID         uuid.UUID
+	Override       bool
+	OverrideReason string
+	IPAddress      string
+	UserAgent      string
+}
+
+// TripBoardingWindowService enforces when passengers may be checked in and boarded for a trip.
+// Bus owners set each trip's window; outside it only they can override, and every check-in,
+// boarding, rejection and override is recorded in the audit log.
+type TripBoardingWindowService struct {
+	repo         *database.TripBoardingWindowRepository
+	busOwnerRepo *database.BusOwnerRepository
+	auditService *AuditService
+	logger       *logrus.Logger
+}
+
+// NewTripBoardingWindowService creates a new TripBoardingWindowService
+func NewTripBoardingWindowService(
+	repo *database.TripBoardingWindowRepository,
+	busOwnerRepo *database.BusOwnerRepository,
+	auditService *AuditService,
+	logger *logrus.Logger,
+) *TripBoardingWindowService {
+	return &TripBoardingWindowService{
+		repo:         repo,
+		busOwnerRepo: busOwnerRepo,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ============================================================================
+// OWNER SETTINGS
+// ============================================================================
+
+// GetWindow returns the boarding window of an owner's trip; trips without one get the defaults
+func (s *TripBoardingWindowService) GetWindow(scheduledTripID, busOwnerID string) (*models.TripBoardingWindow, error) {
+	if _, err := uuid.Parse(scheduledTripID); err != nil {
+		return nil, ErrBoardingTripNotFound
+	}
+	owner, err := s.repo.GetTripOwner(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil || *owner != busOwnerID {
+		return nil, ErrBoardingTripNotFound
+	}
+	return s.windowFor(scheduledTripID)
+}
+
+// ConfigureWindow sets when boarding opens and closes for an owner's trip
+func (s *TripBoardingWindowService) ConfigureWindow(scheduledTripID, busOwnerID string, req *models.UpdateTripBoardingWindowRequest) (*models.TripBoardingWindow, error) {
+	window, err := s.GetWindow(scheduledTripID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	req.ApplyTo(window)
+	if err := s.repo.UpsertWindow(window); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id":    scheduledTripID,
+		"opens_minutes_before": window.OpensMinutesBefore,
+		"closes_minutes_after": window.ClosesMinutesAfter,
+	}).Info("Trip boarding window updated")
+	return window, nil
+}
+
+func (s *TripBoardingWindowService) windowFor(scheduledTripID string) (*models.TripBoardingWindow, error) {
+	window, err := s.repo.GetWindow(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		window = models.NewTripBoardingWindow(scheduledTripID)
+	}
+	return window, nil
+}
+
+// ============================================================================
+// ENFORCEMENT
+// ============================================================================
+
+// AuthorizeBusBooking checks a whole-booking check-in or boarding against the trip's window
+func (s *TripBoardingWindowService) AuthorizeBusBooking(busBookingID string, attempt *BoardingAttempt) error {
+	if _, err := uuid.Parse(busBookingID); err != nil {
+		return ErrBoardingBookingNotFound
+	}
+	target, err := s.repo.GetTargetByBusBooking(busBookingID)
+	if err != nil {
+		return err
+	}
+	return s.authorize(target, attempt, "")
+}
+
+// AuthorizeSeat checks a single-seat check-in or boarding against the trip's window
+func (s *TripBoardingWindowService) AuthorizeSeat(seatID string, attempt *BoardingAttempt) error {
+	if _, err := uuid.Parse(seatID); err != nil {
+		return ErrBoardingBookingNotFound
+	}
+	target, err := s.repo.GetTargetBySeat(seatID)
+	if err != nil {
+		return err
+	}
+	return s.authorize(target, attempt, seatID)
+}
+
+func (s *TripBoardingWindowService) authorize(target *models.BoardingTarget, attempt *BoardingAttempt, seatID string) error {
+	if target == nil {
+		return ErrBoardingBookingNotFound
+	}
+	window, err := s.windowFor(target.ScheduledTripID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	state := window.StateAt(target.DepartureDatetime, now)
+	opensAt, closesAt := window.Bounds(target.DepartureDatetime)
+	details := map[string]interface{}{
+		"scheduled_trip_id":  target.ScheduledTripID,
+		"departure_datetime": target.DepartureDatetime,
+		"window_state":       state,
+		"opens_at":           opensAt,
+		"closes_at":          closesAt,
+	}
+	if seatID != "" {
+		details["seat_id"] = seatID
+	}
+
+	if state == models.BoardingWindowOpen {
+		s.audit(target, attempt, attempt.Action, details)
+		return nil
+	}
+
+	windowErr := &BoardingWindowError{State: state, OpensAt: opensAt, ClosesAt: closesAt}
+	if !attempt.Override {
+		s.audit(target, attempt, attempt.Action+"_rejected", details)
+		return windowErr
+	}
+
+	if !s.isTripOwner(target, attempt.UserID) {
+		details["override_denied"] = true
+		s.audit(target, attempt, attempt.Action+"_rejected", details)
+		return ErrBoardingOverrideNotAllowed
+	}
+	reason := strings.TrimSpace(attempt.OverrideReason)
+	if reason == "" {
+		return ErrBoardingOverrideReason
+	}
+
+	details["override_reason"] = reason
+	s.audit(target, attempt, attempt.Action+"_override", details)
+	s.logger.WithFields(logrus.Fields{
+		"bus_booking_id":    target.BusBookingID,
+		"scheduled_trip_id": target.ScheduledTripID,
+		"action":            attempt.Action,
+		"window_state":      state,
+	}).Warn("Boarding window overridden by bus owner")
+	return nil
+}
+
+func (s *TripBoardingWindowService) isTripOwner(target *models.BoardingTarget, userID uuid.UUID) bool {
+	if target.BusOwnerID == nil {
+		return false
+	}
+	busOwner, err := s.busOwnerRepo.GetByUserID(userID.String())
+	if err != nil {
+		return false
+	}
+	return busOwner.ID == *target.BusOwnerID
+}
+
+// audit records the boarding event; failures are logged and never block boarding
+func (s *TripBoardingWindowService) audit(target *models.BoardingTarget, attempt *BoardingAttempt, action string, details map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+	var busBookingID *uuid.UUID
+	if id, err := uuid.Parse(target.BusBookingID); err == nil {
+		busBookingID = &id
+	}
+	if err := s.auditService.LogBoardingEvent(attempt.UserID, "boarding_"+action, busBookingID, attempt.IPAddress, attempt.UserAgent, details); err != nil {
+		s.logger.WithError(err).WithField("bus_booking_id", target.BusBookingID).Error("Failed to audit boarding event")
+	}
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoardingWindowError_Message(t *testing.T) {
+	departure := time.Date(2026, time.March, 4, 8, 0, 0, 0, models.ReportTimezone)
+	window := &models.TripBoardingWindow{OpensMinutesBefore: 45, ClosesMinutesAfter: 5}
+	opensAt, closesAt := window.Bounds(departure)
+
+	err := error(&BoardingWindowError{State: models.BoardingWindowNotOpen, OpensAt: opensAt, ClosesAt: closesAt})
+	assert.Equal(t, "boarding opens at 07:15", err.Error())
+
+	err = &BoardingWindowError{State: models.BoardingWindowClosed, OpensAt: opensAt, ClosesAt: closesAt.UTC()}
+	assert.Equal(t, "boarding closed at 08:05", err.Error(), "times are shown in local time")
+
+	var windowErr *BoardingWindowError
+	assert.True(t, errors.As(err, &windowErr))
+}
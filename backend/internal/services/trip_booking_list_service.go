@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/pdf"
+)
+
+var (
+	ErrTripBookingsNotFound = errors.New("trip not found or access denied")
+)
+
+// TripBookingListService gives bus owners one list of everyone booked on a trip: app bookings,
+// the phone/agent bookings they entered themselves, and walk-ins and standing tickets sold on
+// the bus, with revenue subtotals per source. The trip's crew can also print its passenger
+// manifest.
+type TripBookingListService struct {
+	repo     *database.TripBookingListRepository
+	contacts *PassengerContactService
+	logger   *logrus.Logger
+}
+
+// NewTripBookingListService creates a new TripBookingListService
+func NewTripBookingListService(repo *database.TripBookingListRepository, contacts *PassengerContactService, logger *logrus.Logger) *TripBookingListService {
+	return &TripBookingListService{repo: repo, contacts: contacts, logger: logger}
+}
+
+// GetTripBookings returns the bookings on an owner's trip that pass the filter
+func (s *TripBookingListService) GetTripBookings(scheduledTripID, busOwnerID string, filter *models.TripBookingListFilter) (*models.TripBookingList, error) {
+	trip, err := s.repo.GetTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil || trip.BusOwnerID == nil || *trip.BusOwnerID != busOwnerID {
+		return nil, ErrTripBookingsNotFound
+	}
+
+	entries, err := s.repo.ListByTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildTripBookingList(trip, entries, filter), nil
+}
+
+// GetTripManifest returns the passenger manifest of a trip for its bus owner, driver or
+// conductor. The crew see passenger phone numbers masked, as in their other booking views.
+func (s *TripBookingListService) GetTripManifest(scheduledTripID, userID string) (*models.TripManifest, error) {
+	trip, err := s.repo.GetManifestTrip(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil || !trip.CanBeViewedBy(userID) {
+		return nil, ErrTripBookingsNotFound
+	}
+
+	passengers, err := s.repo.ListManifestPassengers(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	manifest := models.BuildTripManifest(trip, passengers, time.Now())
+	if s.contacts != nil && (trip.OwnerUserID == nil || *trip.OwnerUserID != userID) {
+		s.contacts.MaskManifest(manifest)
+	}
+	return manifest, nil
+}
+
+// TripManifestPDF renders a manifest as a printable document, one section per boarding stop
+func TripManifestPDF(manifest *models.TripManifest) []byte {
+	trip := manifest.Trip
+	doc := pdf.NewDocument()
+	doc.Heading("Passenger manifest")
+	route := derefOr(trip.RouteName, "Unknown route")
+	if trip.RouteNumber != nil {
+		route = *trip.RouteNumber + " " + route
+	}
+	doc.Linef("Route:      %s", route)
+	doc.Linef("Departure:  %s", trip.DepartureDatetime.In(models.ReportTimezone).Format("Mon Jan 2, 2006 15:04"))
+	doc.Linef("Bus:        %s", derefOr(trip.BusNumber, "-"))
+	doc.Linef("Driver:     %s", derefOr(trip.DriverName, "-"))
+	doc.Linef("Conductor:  %s", derefOr(trip.ConductorName, "-"))
+	doc.Linef("Passengers: %d (%d standing), %d to collect fare from", manifest.TotalPassengers, manifest.Standing, manifest.Unpaid)
+	doc.Linef("Printed:    %s", manifest.GeneratedAt.In(models.ReportTimezone).Format("Jan 2 15:04"))
+	doc.Line("")
+
+	for _, stop := range manifest.Stops {
+		doc.Heading(fmt.Sprintf("%s (%d)", stop.BoardingStopName, len(stop.Passengers)))
+		doc.Linef("%-8s %-24s %-13s %-18s %-14s %-8s %s", "Seat", "Passenger", "Phone", "Alighting", "Reference", "Payment", "Status")
+		for _, p := range stop.Passengers {
+			seat := derefOr(p.SeatNumber, "stand")
+			payment := p.PaymentStatus
+			if !p.IsPaid() {
+				payment = strings.ToUpper(payment) // Stands out for the conductor
+			}
+			doc.Linef("%-8s %-24s %-13s %-18s %-14s %-8s %s",
+				fitColumn(seat, 8), fitColumn(p.PassengerName, 24), fitColumn(derefOr(p.PassengerPhone, ""), 13),
+				fitColumn(derefOr(p.AlightingStopName, ""), 18), fitColumn(p.BookingReference, 14), fitColumn(payment, 8), p.Status)
+		}
+		doc.Line("")
+	}
+	if len(manifest.Stops) == 0 {
+		doc.Line("No passengers booked.")
+	}
+	return doc.Bytes()
+}
+
+// fitColumn cuts text to a fixed-width PDF column, marking the cut with "~"
+func fitColumn(text string, width int) string {
+	if runes := []rune(text); len(runes) > width {
+		return string(runes[:width-1]) + "~"
+	}
+	return text
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrBookableTripNotFound = errors.New("trip not found or not open for booking")
+)
+
+// tripLoungeStopDistance is how many stops away from the trip's origin or destination a lounge
+// may be, matching the lounge near-stop search default
+const tripLoungeStopDistance = 2
+
+// TripDetailsService assembles the public details of a bookable trip and keeps the operators'
+// travel policies shown in them
+type TripDetailsService struct {
+	repo               *database.TripDetailsRepository
+	loungeRepo         *database.LoungeRepository
+	cancellationPolicy models.CancellationPolicy
+	logger             *logrus.Logger
+}
+
+// NewTripDetailsService creates a new TripDetailsService
+func NewTripDetailsService(
+	repo *database.TripDetailsRepository,
+	loungeRepo *database.LoungeRepository,
+	cancellationPolicy models.CancellationPolicy,
+	logger *logrus.Logger,
+) *TripDetailsService {
+	return &TripDetailsService{
+		repo:               repo,
+		loungeRepo:         loungeRepo,
+		cancellationPolicy: cancellationPolicy,
+		logger:             logger,
+	}
+}
+
+// GetTripDetails returns a bookable trip's stops with estimated times, amenities, the operator's
+// policies, seat price ranges and lounges near its first and last stops
+func (s *TripDetailsService) GetTripDetails(tripID string, tenant *models.Tenant) (*models.BookableTripDetails, error) {
+	if _, err := uuid.Parse(tripID); err != nil {
+		return nil, ErrBookableTripNotFound
+	}
+	trip, err := s.repo.GetBookableTrip(tripID, tenant.ScopeID())
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, ErrBookableTripNotFound
+	}
+
+	details := &models.BookableTripDetails{
+		TripID:             trip.TripID,
+		RouteName:          trip.RouteName,
+		RouteNumber:        trip.RouteNumber,
+		BusType:            trip.BusType,
+		DepartureDatetime:  trip.DepartureDatetime,
+		EstimatedArrival:   trip.DepartureDatetime.Add(time.Duration(trip.DurationMinutes) * time.Minute),
+		DurationMinutes:    trip.DurationMinutes,
+		Fare:               trip.Fare,
+		Stops:              []models.BookableTripStop{},
+		Amenities:          trip.BusFeatures,
+		Operator:           models.TripOperator{Name: trip.OperatorName},
+		CancellationPolicy: s.cancellationPolicy.Summary(),
+		Lounges: models.TripLoungeOptions{
+			Origin:      []models.TripLoungeOption{},
+			Destination: []models.TripLoungeOption{},
+		},
+	}
+
+	if trip.MasterRouteID != nil {
+		stops, err := s.repo.GetTripStops(*trip.MasterRouteID, trip.BusOwnerRouteID)
+		if err != nil {
+			return nil, err
+		}
+		details.Stops = models.TripStopTimes(trip.DepartureDatetime, stops)
+		if len(stops) > 0 {
+			details.Lounges.Origin = s.loungesNear(*trip.MasterRouteID, stops[0].ID)
+			details.Lounges.Destination = s.loungesNear(*trip.MasterRouteID, stops[len(stops)-1].ID)
+		}
+	}
+
+	if trip.BusOwnerID != nil {
+		policy, err := s.repo.GetTravelPolicy(*trip.BusOwnerID)
+		if err != nil {
+			return nil, err
+		}
+		details.Operator.TravelPolicy = policy
+	}
+
+	if details.SeatPrices, err = s.repo.GetSeatPriceRanges(trip.TripID); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// loungesNear lists the lounges near a stop. Lounges only complement the trip, so a failed
+// lookup is logged and leaves the list empty rather than failing the details.
+func (s *TripDetailsService) loungesNear(masterRouteID, stopID string) []models.TripLoungeOption {
+	options := []models.TripLoungeOption{}
+	routeID, err := uuid.Parse(masterRouteID)
+	if err != nil {
+		return options
+	}
+	stop, err := uuid.Parse(stopID)
+	if err != nil {
+		return options
+	}
+	lounges, err := s.loungeRepo.GetLoungesNearStop(routeID, stop, tripLoungeStopDistance)
+	if err != nil {
+		s.logger.WithError(err).WithField("stop_id", stopID).Warn("Failed to get lounges near trip stop")
+		return options
+	}
+	for i := range lounges {
+		options = append(options, models.NewTripLoungeOption(&lounges[i]))
+	}
+	return options
+}
+
+// GetTravelPolicy returns a bus owner's travel policy, nil if they have not set one
+func (s *TripDetailsService) GetTravelPolicy(busOwnerID string) (*models.OperatorTravelPolicy, error) {
+	return s.repo.GetTravelPolicy(busOwnerID)
+}
+
+// UpdateTravelPolicy replaces a bus owner's travel policy
+func (s *TripDetailsService) UpdateTravelPolicy(busOwnerID string, req *models.UpdateOperatorTravelPolicyRequest) (*models.OperatorTravelPolicy, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	policy := &models.OperatorTravelPolicy{
+		BusOwnerID:         busOwnerID,
+		LuggageAllowanceKg: req.LuggageAllowanceKg,
+		LuggagePieces:      req.LuggagePieces,
+		LuggageNotes:       req.LuggageNotes,
+	}
+	if err := s.repo.UpsertTravelPolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
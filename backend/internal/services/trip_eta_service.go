@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// ErrETAUnavailable is returned when a trip's stops cannot be timed, as when its route has too
+// few located stops
+var ErrETAUnavailable = errors.New("arrival times cannot be estimated for this trip")
+
+// routeStopPassages are the stop passages of a route's recent trips and when they were read
+type routeStopPassages struct {
+	passages []models.TripStopPassage
+	readAt   time.Time
+}
+
+// TripETAService estimates when active trips reach their stops, from the bus's last position,
+// the route's stops and how long recent trips of the route took between them. Each route's
+// history is reused for a while, as it only changes when a trip completes.
+type TripETAService struct {
+	repo   *database.TripETARepository
+	config config.ETAConfig
+	logger *logrus.Logger
+
+	mu      sync.Mutex
+	history map[string]routeStopPassages // By master route ID
+}
+
+// NewTripETAService creates a new TripETAService
+func NewTripETAService(repo *database.TripETARepository, cfg config.ETAConfig, logger *logrus.Logger) *TripETAService {
+	if cfg.HistoryDays <= 0 {
+		cfg.HistoryDays = 30
+	}
+	if cfg.HistoryTrips <= 0 {
+		cfg.HistoryTrips = 20
+	}
+	if cfg.HistoryCacheTTL <= 0 {
+		cfg.HistoryCacheTTL = 30 * time.Minute
+	}
+	return &TripETAService{
+		repo:    repo,
+		config:  cfg,
+		logger:  logger,
+		history: map[string]routeStopPassages{},
+	}
+}
+
+// GetETA estimates the arrival of an active trip at each of its stops
+func (s *TripETAService) GetETA(activeTripID string) (*models.TripETA, error) {
+	trip, err := s.repo.GetTrip(activeTripID)
+	if err != nil {
+		return nil, err
+	}
+	return s.estimate(trip)
+}
+
+// GetETAForScheduledTrip estimates the arrivals of a scheduled trip that has started
+func (s *TripETAService) GetETAForScheduledTrip(scheduledTripID string) (*models.TripETA, error) {
+	trip, err := s.repo.GetTripByScheduledTripID(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	return s.estimate(trip)
+}
+
+func (s *TripETAService) estimate(trip *models.TripETATrip) (*models.TripETA, error) {
+	if trip == nil {
+		return nil, ErrActiveTripNotFound
+	}
+	switch trip.Status {
+	case models.ActiveTripStatusNotStarted, models.ActiveTripStatusInTransit, models.ActiveTripStatusAtStop:
+	default:
+		return nil, ErrTripNotActive
+	}
+
+	stops, err := s.repo.GetRouteStops(trip.MasterRouteID)
+	if err != nil {
+		return nil, err
+	}
+	passages, err := s.passages(trip.MasterRouteID)
+	if err != nil {
+		return nil, err
+	}
+	eta := models.BuildTripETA(&models.TripETASource{Trip: *trip, Stops: stops, Passages: passages}, time.Now())
+	if eta == nil {
+		return nil, ErrETAUnavailable
+	}
+	return eta, nil
+}
+
+// passages returns the route's recent stop passages, reading them again once they are older
+// than the cache TTL. If they can't be read, older ones are used rather than failing.
+func (s *TripETAService) passages(masterRouteID string) ([]models.TripStopPassage, error) {
+	s.mu.Lock()
+	cached, ok := s.history[masterRouteID]
+	s.mu.Unlock()
+	now := time.Now()
+	if ok && now.Sub(cached.readAt) < s.config.HistoryCacheTTL {
+		return cached.passages, nil
+	}
+
+	passages, err := s.repo.GetStopPassages(masterRouteID, now.AddDate(0, 0, -s.config.HistoryDays), s.config.HistoryTrips)
+	if err != nil {
+		if ok {
+			s.logger.WithError(err).WithField("master_route_id", masterRouteID).Warn("Failed to refresh route leg times, using cached ones")
+			return cached.passages, nil
+		}
+		return nil, err
+	}
+	s.mu.Lock()
+	s.history[masterRouteID] = routeStopPassages{passages: passages, readAt: now}
+	s.mu.Unlock()
+	return passages, nil
+}
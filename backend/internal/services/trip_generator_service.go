@@ -16,6 +16,7 @@ type TripGeneratorService struct {
 	busRepo           *database.BusRepository
 	seatLayoutRepo    *database.BusSeatLayoutRepository
 	settingsRepo      *database.SystemSettingRepository
+	exceptionRepo     *database.TimetableExceptionRepository
 }
 
 // NewTripGeneratorService creates a new TripGeneratorService
@@ -25,6 +26,7 @@ func NewTripGeneratorService(
 	busRepo *database.BusRepository,
 	seatLayoutRepo *database.BusSeatLayoutRepository,
 	settingsRepo *database.SystemSettingRepository,
+	exceptionRepo *database.TimetableExceptionRepository,
 ) *TripGeneratorService {
 	return &TripGeneratorService{
 		scheduleRepo:      scheduleRepo,
@@ -32,10 +34,12 @@ func NewTripGeneratorService(
 		busRepo:           busRepo,
 		seatLayoutRepo:    seatLayoutRepo,
 		settingsRepo:      settingsRepo,
+		exceptionRepo:     exceptionRepo,
 	}
 }
 
 // GenerateTripsForSchedule generates scheduled trips for a given schedule and date range
+// Dates on the holiday calendar are skipped or generated at their adjusted departure time
 func (s *TripGeneratorService) GenerateTripsForSchedule(schedule *models.TripSchedule, startDate, endDate time.Time) (int, error) {
 	generated := 0
 	currentDate := startDate
@@ -43,12 +47,22 @@ func (s *TripGeneratorService) GenerateTripsForSchedule(schedule *models.TripSch
 	fmt.Printf(">>> GenerateTripsForSchedule: Schedule %s from %s to %s\n",
 		schedule.ID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 
+	exceptions, err := s.exceptionRepo.ListForSchedule(schedule.ID, startDate.Format("2006-01-02"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to load timetable exceptions: %w", err)
+	}
+
 	for currentDate.Before(endDate) || currentDate.Equal(endDate) {
 		// Check if schedule is valid for this date
 		isValid := schedule.IsValidForDate(currentDate)
 		fmt.Printf("  Day %s: IsValid=%v\n", currentDate.Format("2006-01-02"), isValid)
 
-		if isValid {
+		departureTime, runs := departureTimeOn(schedule, exceptions, currentDate)
+		if isValid && !runs {
+			fmt.Printf("  Day %s: skipped by timetable exception\n", currentDate.Format("2006-01-02"))
+		}
+
+		if isValid && runs {
 			// Check if trip already exists for this date
 			existing, err := s.scheduledTripRepo.GetByScheduleAndDate(schedule.ID, currentDate)
 			if err == nil && existing != nil {
@@ -80,12 +94,12 @@ func (s *TripGeneratorService) GenerateTripsForSchedule(schedule *models.TripSch
 			var departureDatetime time.Time
 			var parseErr error
 
-			if t, err := time.Parse("15:04", schedule.DepartureTime); err == nil {
+			if t, err := time.Parse("15:04", departureTime); err == nil {
 				departureDatetime = time.Date(currentDate.Year(), currentDate.Month(), currentDate.Day(), t.Hour(), t.Minute(), 0, 0, loc)
-			} else if t, err := time.Parse("15:04:05", schedule.DepartureTime); err == nil {
+			} else if t, err := time.Parse("15:04:05", departureTime); err == nil {
 				departureDatetime = time.Date(currentDate.Year(), currentDate.Month(), currentDate.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
 			} else {
-				parseErr = fmt.Errorf("failed to parse departure time '%s' for schedule %s", schedule.DepartureTime, schedule.ID)
+				parseErr = fmt.Errorf("failed to parse departure time '%s' for schedule %s", departureTime, schedule.ID)
 				fmt.Printf("ERROR: %v\n", parseErr)
 				currentDate = currentDate.AddDate(0, 0, 1)
 				continue // Skip this date if time parsing fails
@@ -134,6 +148,19 @@ func (s *TripGeneratorService) GenerateTripsForSchedule(schedule *models.TripSch
 	return generated, nil
 }
 
+// departureTimeOn returns the schedule's departure time on a date after applying the holiday
+// calendar, and false if an exception skips the date
+func departureTimeOn(schedule *models.TripSchedule, exceptions []models.TimetableException, date time.Time) (string, bool) {
+	exception := models.ResolveTimetableException(exceptions, date)
+	if exception == nil {
+		return schedule.DepartureTime, true
+	}
+	if exception.Action == models.TimetableExceptionAdjust && exception.DepartureTime != nil {
+		return *exception.DepartureTime, true
+	}
+	return "", false
+}
+
 // getEstimatedDuration returns the duration or default if nil
 func getEstimatedDuration(duration *int) *int {
 	if duration != nil && *duration > 0 {
@@ -201,7 +228,18 @@ func (s *TripGeneratorService) GenerateFutureTrips() (int, error) {
 		// Get next 7 occurrences for this timetable
 		nextDates := timetable.GetNextOccurrences(7)
 
+		exceptions, err := s.exceptionRepo.ListForSchedule(timetable.ID, time.Now().Format("2006-01-02"))
+		if err != nil {
+			fmt.Printf("Failed to load timetable exceptions for timetable %s: %v\n", timetable.ID, err)
+			continue
+		}
+
 		for _, date := range nextDates {
+			departureTime, runs := departureTimeOn(&timetable, exceptions, date)
+			if !runs {
+				continue
+			}
+
 			// Check if trip already exists for this date
 			existing, err := s.scheduledTripRepo.GetByScheduleAndDate(timetable.ID, date)
 			if err == nil && existing != nil {
@@ -234,9 +272,9 @@ func (s *TripGeneratorService) GenerateFutureTrips() (int, error) {
 
 			// Parse departure time from timetable and combine with date to create departure_datetime
 			var departureDatetime time.Time
-			if t, err := time.Parse("15:04", timetable.DepartureTime); err == nil {
+			if t, err := time.Parse("15:04", departureTime); err == nil {
 				departureDatetime = time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, loc)
-			} else if t, err := time.Parse("15:04:05", timetable.DepartureTime); err == nil {
+			} else if t, err := time.Parse("15:04:05", departureTime); err == nil {
 				departureDatetime = time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
 			}
 
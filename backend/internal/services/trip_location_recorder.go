@@ -0,0 +1,154 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// tripLocationWriter stores a batch of reported positions
+type tripLocationWriter interface {
+	InsertBatch(records []models.TripLocationRecord) error
+}
+
+// TripLocationRecorder keeps every position the crew app reports in trip_location_history.
+// Positions are buffered and written in batches, a full batch at once and the rest every flush
+// interval, so a location update never waits on the insert. When the database is down the
+// buffer is kept up to its bound, dropping the oldest positions first.
+type TripLocationRecorder struct {
+	store  tripLocationWriter
+	config config.LiveTrackingConfig
+	logger *logrus.Logger
+	stopCh chan struct{}
+	doneCh chan struct{}
+	fullCh chan struct{} // Signalled when a full batch is waiting
+
+	flushMu sync.Mutex // One flush at a time, so batches go out in order
+	mu      sync.Mutex
+	buffer  []models.TripLocationRecord
+	dropped int64
+}
+
+// NewTripLocationRecorder creates a new TripLocationRecorder
+func NewTripLocationRecorder(store tripLocationWriter, cfg config.LiveTrackingConfig, logger *logrus.Logger) *TripLocationRecorder {
+	if cfg.HistoryBatchSize <= 0 {
+		cfg.HistoryBatchSize = 100
+	}
+	if cfg.HistoryFlushInterval <= 0 {
+		cfg.HistoryFlushInterval = 5 * time.Second
+	}
+	if cfg.HistoryMaxBuffered < cfg.HistoryBatchSize {
+		cfg.HistoryMaxBuffered = 100 * cfg.HistoryBatchSize
+	}
+	return &TripLocationRecorder{
+		store:  store,
+		config: cfg,
+		logger: logger,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+		fullCh: make(chan struct{}, 1),
+	}
+}
+
+// Record queues a position for the next batch
+func (r *TripLocationRecorder) Record(record models.TripLocationRecord) {
+	r.mu.Lock()
+	r.buffer = append(r.buffer, record)
+	r.trimLocked()
+	full := len(r.buffer) >= r.config.HistoryBatchSize
+	r.mu.Unlock()
+
+	if full {
+		select {
+		case r.fullCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start begins writing buffered positions
+func (r *TripLocationRecorder) Start() {
+	r.logger.WithFields(logrus.Fields{
+		"batch_size":     r.config.HistoryBatchSize,
+		"flush_interval": r.config.HistoryFlushInterval.String(),
+	}).Info("📍 Starting trip location history recorder")
+	go r.run()
+}
+
+// Stop writes what is still buffered and stops the recorder
+func (r *TripLocationRecorder) Stop() {
+	r.logger.Info("🛑 Stopping trip location history recorder")
+	close(r.stopCh)
+	<-r.doneCh
+}
+
+func (r *TripLocationRecorder) run() {
+	defer close(r.doneCh)
+	ticker := time.NewTicker(r.config.HistoryFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush()
+		case <-r.fullCh:
+			r.Flush()
+		case <-r.stopCh:
+			r.Flush()
+			r.logger.Info("Trip location history recorder stopped")
+			return
+		}
+	}
+}
+
+// Flush writes the buffered positions in batches. A batch that fails goes back to the front of
+// the buffer, with everything after it, for the next flush.
+func (r *TripLocationRecorder) Flush() {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.Lock()
+	pending := r.buffer
+	r.buffer = nil
+	r.mu.Unlock()
+
+	for len(pending) > 0 {
+		n := r.config.HistoryBatchSize
+		if n > len(pending) {
+			n = len(pending)
+		}
+		if err := r.store.InsertBatch(pending[:n]); err != nil {
+			r.mu.Lock()
+			r.buffer = append(pending, r.buffer...)
+			r.trimLocked()
+			buffered, dropped := len(r.buffer), r.dropped
+			r.mu.Unlock()
+			r.logger.WithError(err).WithFields(logrus.Fields{
+				"buffered":      buffered,
+				"dropped_total": dropped,
+			}).Error("Failed to write trip location history")
+			return
+		}
+		pending = pending[n:]
+	}
+}
+
+// Buffered returns how many positions are waiting to be written
+func (r *TripLocationRecorder) Buffered() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.buffer)
+}
+
+// trimLocked drops the oldest positions past the buffer's bound, counting them; r.mu must be held
+func (r *TripLocationRecorder) trimLocked() {
+	over := len(r.buffer) - r.config.HistoryMaxBuffered
+	if over <= 0 {
+		return
+	}
+	r.buffer = append(r.buffer[:0:0], r.buffer[over:]...)
+	r.dropped += int64(over)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTripLocationWriter struct {
+	batches [][]models.TripLocationRecord
+	fail    bool
+}
+
+func (w *fakeTripLocationWriter) InsertBatch(records []models.TripLocationRecord) error {
+	if w.fail {
+		return errors.New("database unavailable")
+	}
+	w.batches = append(w.batches, append([]models.TripLocationRecord(nil), records...))
+	return nil
+}
+
+func TestTripLocationRecorderFlush(t *testing.T) {
+	store := &fakeTripLocationWriter{}
+	recorder := NewTripLocationRecorder(store, config.LiveTrackingConfig{HistoryBatchSize: 2, HistoryMaxBuffered: 4}, logrus.New())
+	record := func(lat float64) {
+		recorder.Record(models.TripLocationRecord{ActiveTripID: "at1", Latitude: lat})
+	}
+
+	for _, lat := range []float64{1, 2, 3} {
+		record(lat)
+	}
+	recorder.Flush()
+	if assert.Len(t, store.batches, 2, "written in batches of two") {
+		assert.Len(t, store.batches[0], 2)
+		assert.Equal(t, 3.0, store.batches[1][0].Latitude)
+	}
+	assert.Zero(t, recorder.Buffered())
+
+	// Failed batches are kept, up to the bound, dropping the oldest
+	store.fail = true
+	for _, lat := range []float64{4, 5, 6} {
+		record(lat)
+	}
+	recorder.Flush()
+	assert.Equal(t, 3, recorder.Buffered())
+	record(7)
+	record(8)
+	assert.Equal(t, 4, recorder.Buffered())
+
+	store.fail = false
+	store.batches = nil
+	recorder.Flush()
+	if assert.Len(t, store.batches, 2) {
+		assert.Equal(t, 5.0, store.batches[0][0].Latitude, "the oldest position was dropped")
+		assert.Equal(t, 8.0, store.batches[1][1].Latitude, "positions keep their order")
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/push"
+)
+
+var (
+	ErrTripThreadNotFound = errors.New("scheduled trip not found")
+	ErrNotTripParticipant = errors.New("only the bus owner and assigned staff can access this trip's messages")
+)
+
+const (
+	defaultTripMessagePageSize = 50
+	maxTripMessagePageSize     = 200
+	tripMessagePreviewLength   = 120 // Characters of the message shown in the push notification
+)
+
+// TripMessageService manages per-trip message threads between a bus owner and the trip's
+// assigned driver and conductor, with read receipts and push notifications
+type TripMessageService struct {
+	repo *database.TripMessageRepository
+	push *PushNotificationService
+}
+
+// NewTripMessageService creates a new TripMessageService
+func NewTripMessageService(repo *database.TripMessageRepository, pushService *PushNotificationService) *TripMessageService {
+	return &TripMessageService{
+		repo: repo,
+		push: pushService,
+	}
+}
+
+// GetThread returns a page of messages (newest first) with read receipts.
+// before pages back through older messages; limit <= 0 uses the default page size.
+func (s *TripMessageService) GetThread(scheduledTripID, userID string, before *time.Time, limit int) (*models.TripThread, error) {
+	participants, _, err := s.authorize(scheduledTripID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultTripMessagePageSize
+	}
+	if limit > maxTripMessagePageSize {
+		limit = maxTripMessagePageSize
+	}
+
+	// Fetch one extra to know whether there are older messages
+	messages, err := s.repo.ListMessages(scheduledTripID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(messages) > limit
+	if hasMore {
+		messages = messages[:limit]
+	}
+
+	markers, err := s.repo.GetReadMarkers(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.repo.CountUnread(scheduledTripID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := participants.List()
+	for i := range list {
+		if readAt, ok := markers[list[i].UserID]; ok {
+			readAt := readAt
+			list[i].LastReadAt = &readAt
+		}
+	}
+
+	return &models.TripThread{
+		ScheduledTripID: scheduledTripID,
+		Participants:    list,
+		Messages:        applyReadReceipts(messages, list),
+		UnreadCount:     unread,
+		HasMore:         hasMore,
+	}, nil
+}
+
+// SendMessage posts a message and notifies the other participants by push
+func (s *TripMessageService) SendMessage(scheduledTripID, userID, body string) (*models.TripMessage, error) {
+	participants, role, err := s.authorize(scheduledTripID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &models.TripMessage{
+		ScheduledTripID: scheduledTripID,
+		SenderUserID:    userID,
+		SenderRole:      role,
+		Body:            strings.TrimSpace(body),
+	}
+	if err := s.repo.CreateMessage(message); err != nil {
+		return nil, err
+	}
+	message.ReadBy = []string{}
+
+	// Sending implies the sender has read everything before it
+	if _, err := s.repo.MarkRead(scheduledTripID, userID); err != nil {
+		return nil, err
+	}
+
+	var recipients []string
+	for _, participant := range participants.List() {
+		if participant.UserID != userID {
+			recipients = append(recipients, participant.UserID)
+		}
+	}
+	if len(recipients) > 0 {
+		s.push.NotifyUsersAsync(recipients, tripMessageNotification(participants, message))
+	}
+
+	return message, nil
+}
+
+// MarkRead records that the user has read the thread up to now
+func (s *TripMessageService) MarkRead(scheduledTripID, userID string) (time.Time, error) {
+	if _, _, err := s.authorize(scheduledTripID, userID); err != nil {
+		return time.Time{}, err
+	}
+	return s.repo.MarkRead(scheduledTripID, userID)
+}
+
+// authorize loads the trip's participants and checks the user is one of them
+func (s *TripMessageService) authorize(scheduledTripID, userID string) (*models.TripThreadParticipants, models.TripParticipantRole, error) {
+	participants, err := s.repo.GetParticipants(scheduledTripID)
+	if err != nil {
+		return nil, "", err
+	}
+	if participants == nil {
+		return nil, "", ErrTripThreadNotFound
+	}
+	role, ok := participants.RoleOf(userID)
+	if !ok {
+		return nil, "", ErrNotTripParticipant
+	}
+	return participants, role, nil
+}
+
+// applyReadReceipts fills ReadBy with the other participants whose read marker is at or after each message
+func applyReadReceipts(messages []models.TripMessage, participants []models.TripThreadParticipant) []models.TripMessage {
+	for i := range messages {
+		messages[i].ReadBy = []string{}
+		for _, participant := range participants {
+			if participant.UserID == messages[i].SenderUserID || participant.LastReadAt == nil {
+				continue
+			}
+			if !participant.LastReadAt.Before(messages[i].CreatedAt) {
+				messages[i].ReadBy = append(messages[i].ReadBy, participant.UserID)
+			}
+		}
+	}
+	return messages
+}
+
+func tripMessageNotification(participants *models.TripThreadParticipants, message *models.TripMessage) push.Message {
+	preview := message.Body
+	if utf8.RuneCountInString(preview) > tripMessagePreviewLength {
+		preview = string([]rune(preview)[:tripMessagePreviewLength]) + "…"
+	}
+
+	sender := strings.ToUpper(string(message.SenderRole[:1])) + string(message.SenderRole[1:])
+	for _, participant := range participants.List() {
+		if participant.UserID == message.SenderUserID && participant.Name != nil && *participant.Name != "" {
+			sender = *participant.Name
+		}
+	}
+
+	return push.Message{
+		Title: fmt.Sprintf("%s · Trip %s", sender, participants.DepartureDatetime.Format("Jan 2, 15:04")),
+		Body:  preview,
+		Data: map[string]string{
+			"type":              "trip_message",
+			"scheduled_trip_id": message.ScheduledTripID,
+			"message_id":        message.ID,
+		},
+	}
+}
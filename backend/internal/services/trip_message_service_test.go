@@ -0,0 +1,57 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyReadReceipts_ExcludesSenderAndUnreadParticipants(t *testing.T) {
+	base := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	ownerRead := base.Add(10 * time.Minute)
+	driverRead := base.Add(2 * time.Minute)
+
+	participants := []models.TripThreadParticipant{
+		{UserID: "owner", Role: models.TripParticipantOwner, LastReadAt: &ownerRead},
+		{UserID: "driver", Role: models.TripParticipantDriver, LastReadAt: &driverRead},
+		{UserID: "conductor", Role: models.TripParticipantConductor},
+	}
+	messages := []models.TripMessage{
+		{ID: "late", SenderUserID: "owner", CreatedAt: base.Add(5 * time.Minute)},
+		{ID: "early", SenderUserID: "conductor", CreatedAt: base.Add(2 * time.Minute)},
+	}
+
+	result := applyReadReceipts(messages, participants)
+
+	assert.Empty(t, result[0].ReadBy, "the owner's own read marker must not count and the driver read before it")
+	assert.NotNil(t, result[0].ReadBy)
+	assert.ElementsMatch(t, []string{"owner", "driver"}, result[1].ReadBy)
+}
+
+func TestTripMessageNotification_UsesSenderNameAndTruncates(t *testing.T) {
+	participants := &models.TripThreadParticipants{
+		ScheduledTripID:   "trip-1",
+		DepartureDatetime: time.Date(2026, 3, 1, 6, 30, 0, 0, time.UTC),
+		OwnerUserID:       stringPtr("owner"),
+		OwnerName:         stringPtr("Lanka Express"),
+		DriverUserID:      stringPtr("driver"),
+	}
+
+	msg := tripMessageNotification(participants, &models.TripMessage{
+		ID: "m1", ScheduledTripID: "trip-1", SenderUserID: "owner", SenderRole: models.TripParticipantOwner,
+		Body: strings.Repeat("a", tripMessagePreviewLength+10),
+	})
+	assert.Equal(t, "Lanka Express · Trip Mar 1, 06:30", msg.Title)
+	assert.Equal(t, tripMessagePreviewLength+1, len([]rune(msg.Body)))
+	assert.Equal(t, "trip_message", msg.Data["type"])
+	assert.Equal(t, "trip-1", msg.Data["scheduled_trip_id"])
+
+	msg = tripMessageNotification(participants, &models.TripMessage{
+		ScheduledTripID: "trip-1", SenderUserID: "driver", SenderRole: models.TripParticipantDriver, Body: "Running late",
+	})
+	assert.Equal(t, "Driver · Trip Mar 1, 06:30", msg.Title)
+	assert.Equal(t, "Running late", msg.Body)
+}
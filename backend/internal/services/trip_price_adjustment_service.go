@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrPriceAdjustmentTripNotFound    = errors.New("trip not found")
+	ErrPriceAdjustmentTripNotEditable = errors.New("trip has departed, completed or been cancelled")
+	ErrPriceAdjustmentNotFound        = errors.New("price adjustment not found")
+)
+
+// TripPriceAdjustmentService lets bus owners put a surcharge or discount on a set of their trips.
+// Adjustments are applied when seats are priced, so seat maps, search results, fare quotes and
+// booking intents all see the same fare.
+type TripPriceAdjustmentService struct {
+	repo   *database.TripPriceAdjustmentRepository
+	logger *logrus.Logger
+}
+
+// NewTripPriceAdjustmentService creates a new TripPriceAdjustmentService
+func NewTripPriceAdjustmentService(repo *database.TripPriceAdjustmentRepository, logger *logrus.Logger) *TripPriceAdjustmentService {
+	return &TripPriceAdjustmentService{repo: repo, logger: logger}
+}
+
+// Apply puts the adjustment on each requested trip, replacing any it already had. Every trip
+// must belong to the bus owner and still be open for booking, or nothing is changed.
+func (s *TripPriceAdjustmentService) Apply(busOwnerID, userID string, req *models.ApplyTripPriceAdjustmentRequest) ([]models.TripPriceAdjustmentResult, error) {
+	now := time.Now()
+	from, err := req.Validate(now)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	tripIDs := []string{}
+	for _, id := range req.TripIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPriceAdjustmentTripNotFound, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			tripIDs = append(tripIDs, id)
+		}
+	}
+
+	trips, err := s.repo.GetTrips(tripIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.PriceAdjustmentTrip, len(trips))
+	for _, trip := range trips {
+		byID[trip.TripID] = trip
+	}
+
+	var label *string
+	if req.Label != "" {
+		label = &req.Label
+	}
+	adjustments := make([]*models.TripPriceAdjustment, 0, len(tripIDs))
+	for _, id := range tripIDs {
+		trip, ok := byID[id]
+		if !ok || trip.BusOwnerID == nil || *trip.BusOwnerID != busOwnerID {
+			return nil, fmt.Errorf("%w: %s", ErrPriceAdjustmentTripNotFound, id)
+		}
+		if trip.Status == models.ScheduledTripStatusCancelled || trip.Status == models.ScheduledTripStatusCompleted ||
+			!trip.DepartureDatetime.After(now) {
+			return nil, fmt.Errorf("%w: %s", ErrPriceAdjustmentTripNotEditable, id)
+		}
+		adjustments = append(adjustments, &models.TripPriceAdjustment{
+			ScheduledTripID: id,
+			BusOwnerID:      busOwnerID,
+			Kind:            req.Kind,
+			Mode:            req.Mode,
+			Value:           req.Value,
+			Label:           label,
+			ValidFrom:       from,
+			ValidUntil:      req.ValidUntil,
+			CreatedBy:       userID,
+			FareCap:         trip.FareCap,
+		})
+	}
+
+	if err := s.repo.ReplaceForTrips(adjustments); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.TripPriceAdjustmentResult, len(adjustments))
+	for i, a := range adjustments {
+		base := byID[a.ScheduledTripID].BaseFare
+		uncapped := *a
+		uncapped.FareCap = nil
+		adjusted := a.Apply(base)
+		results[i] = models.TripPriceAdjustmentResult{
+			Adjustment:       a,
+			BaseFare:         base,
+			AdjustedBaseFare: adjusted,
+			Capped:           adjusted != uncapped.Apply(base),
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"bus_owner_id": busOwnerID,
+		"trips":        len(adjustments),
+		"kind":         req.Kind,
+		"mode":         req.Mode,
+		"value":        req.Value,
+	}).Info("Trip price adjustment applied")
+	return results, nil
+}
+
+// List returns the owner's current and upcoming adjustments, optionally for one trip
+func (s *TripPriceAdjustmentService) List(busOwnerID string, tripID *string) ([]models.TripPriceAdjustment, error) {
+	return s.repo.ListForBusOwner(busOwnerID, tripID)
+}
+
+// Revoke ends one of the owner's adjustments; seats go back to their usual price
+func (s *TripPriceAdjustmentService) Revoke(busOwnerID, adjustmentID string) error {
+	if _, err := uuid.Parse(adjustmentID); err != nil {
+		return ErrPriceAdjustmentNotFound
+	}
+	revoked, err := s.repo.Revoke(adjustmentID, busOwnerID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrPriceAdjustmentNotFound
+	}
+	return nil
+}
+
+// ActiveForTrip returns the adjustment pricing the trip's bookings now, or nil
+func (s *TripPriceAdjustmentService) ActiveForTrip(tripID string) (*models.TripPriceAdjustment, error) {
+	active, err := s.ActiveForTrips([]string{tripID})
+	if err != nil {
+		return nil, err
+	}
+	return active[tripID], nil
+}
+
+// ActiveForTrips returns the adjustments pricing the trips' bookings now, keyed by trip ID.
+// A nil service has no adjustments.
+func (s *TripPriceAdjustmentService) ActiveForTrips(tripIDs []string) (map[string]*models.TripPriceAdjustment, error) {
+	if s == nil {
+		return map[string]*models.TripPriceAdjustment{}, nil
+	}
+	return s.repo.GetActiveForTrips(tripIDs, time.Now())
+}
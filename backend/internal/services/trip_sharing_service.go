@@ -0,0 +1,365 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrEmergencyContactNotFound = errors.New("emergency contact not found")
+	ErrTooManyEmergencyContacts = errors.New("maximum number of emergency contacts reached")
+	ErrNotBookedOnTrip          = errors.New("you do not have an active booking on this trip")
+	ErrShareLinkInvalid         = errors.New("tracking link is invalid or has expired")
+)
+
+// shareTokenBytes is the entropy of a public tracking token
+const shareTokenBytes = 24
+
+// TripSharingService lets passengers share live trips with their emergency contacts.
+// Contacts opted into auto-sharing get a tracking link by SMS when a booked trip starts,
+// and every contact is alerted when staff flag an incident on the trip.
+type TripSharingService struct {
+	repo                *database.TripSharingRepository
+	passengerRepo       *database.PassengerRepository
+	notificationService *NotificationService
+	config              config.TripSharingConfig
+	logger              *logrus.Logger
+}
+
+// NewTripSharingService creates a new TripSharingService
+func NewTripSharingService(
+	repo *database.TripSharingRepository,
+	passengerRepo *database.PassengerRepository,
+	notificationService *NotificationService,
+	cfg config.TripSharingConfig,
+	logger *logrus.Logger,
+) *TripSharingService {
+	return &TripSharingService{
+		repo:                repo,
+		passengerRepo:       passengerRepo,
+		notificationService: notificationService,
+		config:              cfg,
+		logger:              logger,
+	}
+}
+
+// ============================================================================
+// EMERGENCY CONTACTS
+// ============================================================================
+
+// ListContacts returns a passenger's emergency contacts
+func (s *TripSharingService) ListContacts(userID string) ([]models.EmergencyContact, error) {
+	return s.repo.ListEmergencyContacts(userID)
+}
+
+// AddContact registers an emergency contact. The phone must already be normalized.
+// The first contact also becomes the passenger's primary emergency contact on their profile.
+func (s *TripSharingService) AddContact(userID string, req *models.CreateEmergencyContactRequest) (*models.EmergencyContact, error) {
+	count, err := s.repo.CountEmergencyContacts(userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.MaxContactsPerPassenger > 0 && count >= s.config.MaxContactsPerPassenger {
+		return nil, ErrTooManyEmergencyContacts
+	}
+
+	contact := &models.EmergencyContact{
+		UserID:         userID,
+		Name:           strings.TrimSpace(req.Name),
+		Phone:          req.Phone,
+		Relationship:   req.Relationship,
+		AutoShareTrips: req.AutoShareTrips,
+	}
+	if err := s.repo.CreateEmergencyContact(contact); err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		if err := s.syncPrimaryContact(userID, contact); err != nil {
+			s.logger.WithError(err).WithField("user_id", userID).Warn("Failed to set primary emergency contact")
+		}
+	}
+	return contact, nil
+}
+
+// syncPrimaryContact copies a contact to the passenger profile unless one is already set there
+func (s *TripSharingService) syncPrimaryContact(userID string, contact *models.EmergencyContact) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+	passenger, err := s.passengerRepo.GetPassengerByUserID(uid)
+	if err != nil || passenger == nil || passenger.HasCompletedStep(models.ProfileStepEmergencyContact) {
+		return err
+	}
+	return s.passengerRepo.UpdatePassengerEmergencyContact(uid, contact.Name, contact.Phone)
+}
+
+// UpdateContact applies the provided fields to a contact. The phone must already be normalized.
+func (s *TripSharingService) UpdateContact(userID, contactID string, req *models.UpdateEmergencyContactRequest) (*models.EmergencyContact, error) {
+	contact, err := s.repo.GetEmergencyContact(contactID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if contact == nil {
+		return nil, ErrEmergencyContactNotFound
+	}
+
+	if req.Name != nil {
+		contact.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Phone != nil {
+		contact.Phone = *req.Phone
+	}
+	if req.Relationship != nil {
+		contact.Relationship = req.Relationship
+	}
+	if req.AutoShareTrips != nil {
+		contact.AutoShareTrips = *req.AutoShareTrips
+	}
+
+	if err := s.repo.UpdateEmergencyContact(contact); err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
+// DeleteContact removes an emergency contact
+func (s *TripSharingService) DeleteContact(userID, contactID string) error {
+	deleted, err := s.repo.DeleteEmergencyContact(contactID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrEmergencyContactNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// SHARE LINKS
+// ============================================================================
+
+// ShareTrip returns a tracking link for a trip the passenger is booked on, reusing an
+// unexpired one. With notifyContacts every emergency contact is sent the link.
+func (s *TripSharingService) ShareTrip(userID, scheduledTripID string, notifyContacts bool) (*models.TripShareToken, string, error) {
+	booked, err := s.repo.IsPassengerBookedOnTrip(userID, scheduledTripID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !booked {
+		return nil, "", ErrNotBookedOnTrip
+	}
+
+	token, err := s.getOrCreateShareToken(userID, scheduledTripID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if notifyContacts {
+		contacts, err := s.repo.ListEmergencyContacts(userID)
+		if err != nil {
+			return nil, "", err
+		}
+		view, err := s.repo.GetSharedTripView(token.Token)
+		if err != nil {
+			return nil, "", err
+		}
+		if view != nil {
+			for _, contact := range contacts {
+				s.send(contact.Phone, tripSharedMessage(view.PassengerFirstName, view.RouteName, s.TrackingLink(token.Token)))
+			}
+		}
+	}
+
+	return token, s.TrackingLink(token.Token), nil
+}
+
+// StopSharing revokes the passenger's tracking links for a trip
+func (s *TripSharingService) StopSharing(userID, scheduledTripID string) error {
+	_, err := s.repo.RevokeShareTokens(userID, scheduledTripID)
+	return err
+}
+
+// GetSharedTrip resolves a public tracking token
+func (s *TripSharingService) GetSharedTrip(token string) (*models.SharedTripView, error) {
+	view, err := s.repo.GetSharedTripView(token)
+	if err != nil {
+		return nil, err
+	}
+	if view == nil {
+		return nil, ErrShareLinkInvalid
+	}
+
+	incidents, err := s.repo.ListIncidents(view.ScheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	view.Incidents = incidents
+	return view, nil
+}
+
+// TrackingLink builds the public URL for a share token
+func (s *TripSharingService) TrackingLink(token string) string {
+	return strings.TrimRight(s.config.TrackingBaseURL, "/") + "/" + token
+}
+
+// ============================================================================
+// TRIP EVENTS
+// ============================================================================
+
+// NotifyTripStarted sends a tracking link to the auto-share contacts of every passenger
+// booked on the trip. Failures are logged; trip start never fails because of sharing.
+func (s *TripSharingService) NotifyTripStarted(scheduledTripID string) {
+	if !s.config.Enabled {
+		return
+	}
+	s.notifyRecipients(scheduledTripID, true, func(passengerName *string, routeName, link string) string {
+		return tripStartedMessage(passengerName, routeName, link)
+	})
+}
+
+// ReportIncident records an incident on an active trip and alerts all emergency contacts
+// of passengers booked on it. The active trip and staff assignment must already be verified.
+func (s *TripSharingService) ReportIncident(activeTrip *models.ActiveTrip, staffID string, req *models.ReportTripIncidentRequest) (*models.TripIncident, error) {
+	incident := &models.TripIncident{
+		ActiveTripID:      activeTrip.ID,
+		ScheduledTripID:   activeTrip.ScheduledTripID,
+		ReportedByStaffID: staffID,
+		IncidentType:      req.IncidentType,
+		Description:       req.Description,
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+	}
+	if incident.Latitude == nil || incident.Longitude == nil {
+		incident.Latitude = activeTrip.CurrentLatitude
+		incident.Longitude = activeTrip.CurrentLongitude
+	}
+	if err := s.repo.CreateIncident(incident); err != nil {
+		return nil, err
+	}
+
+	if s.config.Enabled {
+		go s.notifyRecipients(activeTrip.ScheduledTripID, false, func(passengerName *string, routeName, link string) string {
+			return tripIncidentMessage(passengerName, routeName, incident.IncidentType, link)
+		})
+	}
+	return incident, nil
+}
+
+// notifyRecipients sends one SMS per emergency contact of passengers on the trip, creating
+// a share link per passenger. autoShareOnly limits delivery to contacts opted into auto-sharing.
+func (s *TripSharingService) notifyRecipients(scheduledTripID string, autoShareOnly bool, message func(passengerName *string, routeName, link string) string) {
+	recipients, err := s.repo.GetTripShareRecipients(scheduledTripID)
+	if err != nil {
+		s.logger.WithError(err).WithField("scheduled_trip_id", scheduledTripID).Error("Failed to load trip share recipients")
+		return
+	}
+
+	views := make(map[string]*models.SharedTripView) // user ID -> tracking data
+	tokens := make(map[string]string)                // user ID -> share token
+	sent := 0
+	for _, recipient := range recipients {
+		if autoShareOnly && !recipient.AutoShareTrips {
+			continue
+		}
+
+		view, ok := views[recipient.UserID]
+		if !ok {
+			token, err := s.getOrCreateShareToken(recipient.UserID, scheduledTripID)
+			if err != nil {
+				s.logger.WithError(err).WithField("user_id", recipient.UserID).Error("Failed to create trip share link")
+				continue
+			}
+			view, err = s.repo.GetSharedTripView(token.Token)
+			if err != nil || view == nil {
+				s.logger.WithError(err).WithField("user_id", recipient.UserID).Error("Failed to load shared trip")
+				continue
+			}
+			views[recipient.UserID] = view
+			tokens[recipient.UserID] = token.Token
+		}
+
+		s.send(recipient.ContactPhone, message(recipient.PassengerName, view.RouteName, s.TrackingLink(tokens[recipient.UserID])))
+		sent++
+	}
+
+	if sent > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"scheduled_trip_id": scheduledTripID,
+			"contacts":          sent,
+		}).Info("Sent trip sharing notifications")
+	}
+}
+
+func (s *TripSharingService) getOrCreateShareToken(userID, scheduledTripID string) (*models.TripShareToken, error) {
+	existing, err := s.repo.GetActiveShareToken(userID, scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	value, err := generateShareToken()
+	if err != nil {
+		return nil, err
+	}
+	token := &models.TripShareToken{
+		Token:           value,
+		UserID:          userID,
+		ScheduledTripID: scheduledTripID,
+		ExpiresAt:       time.Now().Add(s.config.LinkTTL),
+	}
+	if err := s.repo.CreateShareToken(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *TripSharingService) send(phone, message string) {
+	if err := s.notificationService.SendSMS(phone, message); err != nil {
+		s.logger.WithError(err).Warn("Failed to send trip sharing SMS")
+	}
+}
+
+// generateShareToken returns a URL-safe random token
+func generateShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func passengerDisplayName(name *string) string {
+	if name == nil || strings.TrimSpace(*name) == "" {
+		return "Your contact"
+	}
+	return strings.TrimSpace(*name)
+}
+
+func tripStartedMessage(passengerName *string, routeName, link string) string {
+	return fmt.Sprintf("SmartTransit: %s's bus on %s has started. Follow the trip live: %s",
+		passengerDisplayName(passengerName), routeName, link)
+}
+
+func tripSharedMessage(passengerName *string, routeName, link string) string {
+	return fmt.Sprintf("SmartTransit: %s shared their bus trip on %s with you. Follow it live: %s",
+		passengerDisplayName(passengerName), routeName, link)
+}
+
+func tripIncidentMessage(passengerName *string, routeName string, incidentType models.TripIncidentType, link string) string {
+	return fmt.Sprintf("SmartTransit safety alert: a %s incident was reported on %s's bus (%s). Live status: %s",
+		incidentType, passengerDisplayName(passengerName), routeName, link)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackingLink_JoinsBaseURLAndToken(t *testing.T) {
+	for _, base := range []string{"https://smarttransit.lk/track", "https://smarttransit.lk/track/"} {
+		s := &TripSharingService{config: config.TripSharingConfig{TrackingBaseURL: base}}
+		assert.Equal(t, "https://smarttransit.lk/track/abc123", s.TrackingLink("abc123"))
+	}
+}
+
+func TestGenerateShareToken_IsURLSafeAndUnique(t *testing.T) {
+	first, err := generateShareToken()
+	require.NoError(t, err)
+	second, err := generateShareToken()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	decoded, err := base64.RawURLEncoding.DecodeString(first)
+	require.NoError(t, err)
+	assert.Len(t, decoded, shareTokenBytes)
+	assert.False(t, strings.ContainsAny(first, "+/="))
+}
+
+func TestTripMessages_IncludeNameRouteAndLink(t *testing.T) {
+	name := "Nimal"
+	link := "https://smarttransit.lk/track/tok"
+
+	started := tripStartedMessage(&name, "Colombo - Kandy", link)
+	assert.Contains(t, started, "Nimal's bus on Colombo - Kandy has started")
+	assert.Contains(t, started, link)
+
+	incident := tripIncidentMessage(&name, "Colombo - Kandy", models.TripIncidentBreakdown, link)
+	assert.Contains(t, incident, "breakdown incident")
+	assert.Contains(t, incident, "Nimal's bus")
+	assert.Contains(t, incident, link)
+
+	// Passengers without a name on file are referred to generically
+	assert.Contains(t, tripStartedMessage(nil, "Route 1", link), "Your contact's bus")
+}
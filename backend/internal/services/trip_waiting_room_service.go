@@ -0,0 +1,268 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrWaitingRoomTripNotFound = errors.New("trip not found or access denied")
+	ErrQueueTokenNotFound      = errors.New("queue token not found")
+	ErrQueueAdmissionRequired  = errors.New("this trip has a waiting room: join the queue and create the intent once admitted")
+	ErrQueueTokenNotAdmitted   = errors.New("queue token is not admitted for this trip or its admission has expired")
+)
+
+// waitingRoomTickInterval is how often the job expires idle tokens and admits due batches;
+// it bounds the shortest admit interval an owner can configure
+const waitingRoomTickInterval = 5 * time.Second
+
+// TripWaitingRoomService runs optional per-trip virtual waiting rooms for high-demand releases.
+// Users join the queue, poll their position and are admitted in batches; only admitted users
+// can create bus intents for the trip while its room is enabled.
+type TripWaitingRoomService struct {
+	repo   *database.TripWaitingRoomRepository
+	logger *logrus.Logger
+	stopCh chan struct{}
+}
+
+// NewTripWaitingRoomService creates a new TripWaitingRoomService
+func NewTripWaitingRoomService(repo *database.TripWaitingRoomRepository, logger *logrus.Logger) *TripWaitingRoomService {
+	return &TripWaitingRoomService{
+		repo:   repo,
+		logger: logger,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// OWNER SETTINGS
+// ============================================================================
+
+// GetRoom returns the waiting room settings of an owner's trip; trips without a room get the
+// (disabled) defaults
+func (s *TripWaitingRoomService) GetRoom(scheduledTripID, busOwnerID string) (*models.TripWaitingRoom, error) {
+	if err := s.checkOwnership(scheduledTripID, busOwnerID); err != nil {
+		return nil, err
+	}
+	room, err := s.repo.GetRoom(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		room = models.NewTripWaitingRoom(scheduledTripID)
+	}
+	return room, nil
+}
+
+// ConfigureRoom turns an owner's trip waiting room on or off and updates its settings.
+// Disabling lets everyone book directly again; tokens are left to expire.
+func (s *TripWaitingRoomService) ConfigureRoom(scheduledTripID, busOwnerID string, req *models.UpdateTripWaitingRoomRequest) (*models.TripWaitingRoom, error) {
+	room, err := s.GetRoom(scheduledTripID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	req.ApplyTo(room)
+	if err := s.repo.UpsertRoom(room); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": scheduledTripID,
+		"enabled":           room.IsEnabled,
+		"batch_size":        room.BatchSize,
+	}).Info("Trip waiting room updated")
+	return room, nil
+}
+
+func (s *TripWaitingRoomService) checkOwnership(scheduledTripID, busOwnerID string) error {
+	if _, err := uuid.Parse(scheduledTripID); err != nil {
+		return ErrWaitingRoomTripNotFound
+	}
+	owned, err := s.repo.IsTripOwnedBy(scheduledTripID, busOwnerID)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return ErrWaitingRoomTripNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// PASSENGER QUEUE
+// ============================================================================
+
+// JoinQueue puts the user in the trip's waiting room, or tells them no queue is needed.
+// Joining again returns the same token and place.
+func (s *TripWaitingRoomService) JoinQueue(scheduledTripID string, userID uuid.UUID) (*models.QueueStatusResponse, error) {
+	if _, err := uuid.Parse(scheduledTripID); err != nil {
+		return nil, ErrWaitingRoomTripNotFound
+	}
+	room, err := s.repo.GetRoom(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil || !room.IsEnabled {
+		return &models.QueueStatusResponse{QueueRequired: false, ScheduledTripID: scheduledTripID}, nil
+	}
+
+	token, err := s.repo.JoinQueue(scheduledTripID, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	return s.buildQueueStatus(room, token, time.Now())
+}
+
+// GetQueueStatus returns the user's current place; polling keeps a waiting token alive
+func (s *TripWaitingRoomService) GetQueueStatus(tokenID string, userID uuid.UUID) (*models.QueueStatusResponse, error) {
+	if _, err := uuid.Parse(tokenID); err != nil {
+		return nil, ErrQueueTokenNotFound
+	}
+	token, err := s.repo.TouchToken(tokenID, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrQueueTokenNotFound
+	}
+
+	room, err := s.repo.GetRoom(token.ScheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil || !room.IsEnabled {
+		return &models.QueueStatusResponse{QueueRequired: false, ScheduledTripID: token.ScheduledTripID}, nil
+	}
+	return s.buildQueueStatus(room, token, time.Now())
+}
+
+func (s *TripWaitingRoomService) buildQueueStatus(room *models.TripWaitingRoom, token *models.TripQueueToken, now time.Time) (*models.QueueStatusResponse, error) {
+	status := &models.QueueStatusResponse{
+		QueueRequired:   true,
+		QueueToken:      token.ID,
+		ScheduledTripID: token.ScheduledTripID,
+		Status:          token.Status,
+	}
+
+	switch {
+	case token.Status == models.QueueTokenWaiting:
+		ahead, err := s.repo.CountAhead(token)
+		if err != nil {
+			return nil, err
+		}
+		status.Position = ahead + 1
+		status.EstimatedWaitSeconds = models.EstimateQueueWait(status.Position, room.BatchSize, room.AdmitIntervalSeconds)
+		status.PollAfterSeconds = models.WaitingRoomPollAfterSeconds
+	case token.IsAdmitted(now):
+		status.AdmissionExpiresAt = token.AdmissionExpiresAt
+	case token.Status == models.QueueTokenAdmitted:
+		// Window passed but the job has not caught up yet
+		status.Status = models.QueueTokenExpired
+	}
+	return status, nil
+}
+
+// RequireAdmission checks the user may create a bus intent for the trip. Returns the queue
+// token to mark used once the intent is created, or "" when the trip has no active room.
+func (s *TripWaitingRoomService) RequireAdmission(scheduledTripID string, userID uuid.UUID, queueToken *string) (string, error) {
+	room, err := s.repo.GetRoom(scheduledTripID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check waiting room: %w", err)
+	}
+	if room == nil || !room.IsEnabled {
+		return "", nil
+	}
+	if queueToken == nil || *queueToken == "" {
+		return "", ErrQueueAdmissionRequired
+	}
+	if _, err := uuid.Parse(*queueToken); err != nil {
+		return "", ErrQueueTokenNotAdmitted
+	}
+
+	token, err := s.repo.GetToken(*queueToken)
+	if err != nil {
+		return "", err
+	}
+	if token == nil || token.UserID != userID.String() || token.ScheduledTripID != scheduledTripID || !token.IsAdmitted(time.Now()) {
+		return "", ErrQueueTokenNotAdmitted
+	}
+	return token.ID, nil
+}
+
+// MarkAdmissionUsed closes the admission once an intent has been created with it
+func (s *TripWaitingRoomService) MarkAdmissionUsed(tokenID string) {
+	if err := s.repo.MarkTokenUsed(tokenID); err != nil {
+		s.logger.WithError(err).WithField("queue_token", tokenID).Warn("Failed to mark queue token used")
+	}
+}
+
+// ============================================================================
+// BACKGROUND ADMISSION
+// ============================================================================
+
+// Start begins the background admission job
+func (s *TripWaitingRoomService) Start() {
+	s.logger.Info("🚦 Starting Trip Waiting Room job")
+	go s.run()
+}
+
+// Stop stops the background admission job
+func (s *TripWaitingRoomService) Stop() {
+	s.logger.Info("🛑 Stopping Trip Waiting Room job")
+	close(s.stopCh)
+}
+
+func (s *TripWaitingRoomService) run() {
+	ticker := time.NewTicker(waitingRoomTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processQueues()
+		case <-s.stopCh:
+			s.logger.Info("Trip Waiting Room job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single admission cycle (useful for testing or manual trigger)
+func (s *TripWaitingRoomService) RunOnce() {
+	s.processQueues()
+}
+
+// processQueues expires idle tokens first so their places go to users who are still there,
+// then admits the next batch of every room that is due
+func (s *TripWaitingRoomService) processQueues() {
+	if expired, err := s.repo.ExpireStaleTokens(); err != nil {
+		s.logger.WithError(err).Error("Failed to expire queue tokens")
+	} else if expired > 0 {
+		s.logger.WithField("count", expired).Debug("Expired idle queue tokens")
+	}
+
+	rooms, err := s.repo.GetRoomsDueForAdmission()
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get waiting rooms due for admission")
+		return
+	}
+	for i := range rooms {
+		admitted, err := s.repo.AdmitNextBatch(&rooms[i])
+		if err != nil {
+			s.logger.WithError(err).WithField("scheduled_trip_id", rooms[i].ScheduledTripID).Error("Failed to admit queue batch")
+			continue
+		}
+		if admitted > 0 {
+			s.logger.WithFields(logrus.Fields{
+				"scheduled_trip_id": rooms[i].ScheduledTripID,
+				"admitted":          admitted,
+			}).Info("Admitted waiting room batch")
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/events"
+	"github.com/smarttransit/sms-auth-backend/pkg/push"
+)
+
+var (
+	ErrWaitlistTripNotFound   = errors.New("trip not found or access denied")
+	ErrWaitlistTripClosed     = errors.New("trip not found or no longer taking bookings")
+	ErrWaitlistSeatsAvailable = errors.New("seats are still available on this trip: book them directly")
+	ErrWaitlistEntryNotFound  = errors.New("you are not on this trip's waitlist")
+	ErrSeatsHeldForWaitlist   = errors.New("the remaining seats are held for passengers on the waitlist")
+)
+
+// TripWaitlistService runs the waitlists of sold-out trips. Passengers join a trip's line with
+// the number of seats they need; when seats free up, through a cancellation, an owner
+// unblocking them or a hold running out, the next entries in line are offered them and have
+// OfferTTL to book. Seats kept for open offers cannot be taken by anyone else meanwhile.
+type TripWaitlistService struct {
+	repo   *database.TripWaitlistRepository
+	push   *PushNotificationService
+	config config.WaitlistConfig
+	logger *logrus.Logger
+	stopCh chan struct{}
+
+	promoteMu sync.Mutex // Offers of a trip are planned one promotion at a time
+}
+
+// NewTripWaitlistService creates a new TripWaitlistService
+func NewTripWaitlistService(repo *database.TripWaitlistRepository, push *PushNotificationService, cfg config.WaitlistConfig, logger *logrus.Logger) *TripWaitlistService {
+	if cfg.OfferTTL <= 0 {
+		cfg.OfferTTL = 15 * time.Minute
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 30 * time.Second
+	}
+	return &TripWaitlistService{
+		repo:   repo,
+		push:   push,
+		config: cfg,
+		logger: logger,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// PASSENGERS
+// ============================================================================
+
+// Join puts the user on a sold-out trip's waitlist. Joining again returns the same entry and
+// place; trips with enough seats left and nobody waiting are booked directly instead.
+func (s *TripWaitlistService) Join(scheduledTripID string, userID uuid.UUID, req *models.JoinWaitlistRequest) (*models.WaitlistStatusResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(scheduledTripID); err != nil {
+		return nil, ErrWaitlistTripClosed
+	}
+	open, err := s.repo.IsTripOpen(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, ErrWaitlistTripClosed
+	}
+
+	existing, err := s.repo.GetLiveEntry(scheduledTripID, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return s.buildStatus(existing, time.Now())
+	}
+
+	free, err := s.repo.CountFreeSeats(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.GetCounts(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	if counts.WaitingEntries == 0 && models.WaitlistSeatsLeft(free, counts.OfferedSeats, nil) >= req.SeatCount {
+		return nil, ErrWaitlistSeatsAvailable
+	}
+
+	entry, err := s.repo.Join(scheduledTripID, userID.String(), req.SeatCount)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": scheduledTripID,
+		"user_id":           userID,
+		"seat_count":        entry.SeatCount,
+	}).Info("Passenger joined trip waitlist")
+	return s.buildStatus(entry, time.Now())
+}
+
+// GetStatus returns the user's latest entry on the trip's waitlist with their place in line
+func (s *TripWaitlistService) GetStatus(scheduledTripID string, userID uuid.UUID) (*models.WaitlistStatusResponse, error) {
+	if _, err := uuid.Parse(scheduledTripID); err != nil {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	entry, err := s.repo.GetLatestEntry(scheduledTripID, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	return s.buildStatus(entry, time.Now())
+}
+
+// Leave takes the user off the trip's waitlist; seats kept for their offer go to the next in line
+func (s *TripWaitlistService) Leave(scheduledTripID string, userID uuid.UUID) error {
+	if _, err := uuid.Parse(scheduledTripID); err != nil {
+		return ErrWaitlistEntryNotFound
+	}
+	left, err := s.repo.Leave(scheduledTripID, userID.String())
+	if err != nil {
+		return err
+	}
+	if !left {
+		return ErrWaitlistEntryNotFound
+	}
+	s.PromoteTripAsync(scheduledTripID)
+	return nil
+}
+
+func (s *TripWaitlistService) buildStatus(entry *models.TripWaitlistEntry, now time.Time) (*models.WaitlistStatusResponse, error) {
+	status := &models.WaitlistStatusResponse{TripWaitlistEntry: *entry}
+	switch {
+	case entry.Status == models.WaitlistWaiting:
+		ahead, err := s.repo.CountAhead(entry)
+		if err != nil {
+			return nil, err
+		}
+		status.Position = ahead + 1
+	case entry.Status == models.WaitlistOffered && !entry.IsOffered(now):
+		// Window passed but the job has not caught up yet
+		status.Status = models.WaitlistExpired
+	}
+	return status, nil
+}
+
+// ============================================================================
+// OWNERS
+// ============================================================================
+
+// GetSummary returns how deep the waitlist of an owner's trip is, with its line in order
+func (s *TripWaitlistService) GetSummary(scheduledTripID, busOwnerID string) (*models.TripWaitlistSummary, error) {
+	if _, err := uuid.Parse(scheduledTripID); err != nil {
+		return nil, ErrWaitlistTripNotFound
+	}
+	owned, err := s.repo.IsTripOwnedBy(scheduledTripID, busOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, ErrWaitlistTripNotFound
+	}
+
+	counts, err := s.repo.GetCounts(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	free, err := s.repo.CountFreeSeats(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	line, err := s.repo.GetLine(scheduledTripID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TripWaitlistSummary{
+		ScheduledTripID: scheduledTripID,
+		FreeSeats:       free,
+		WaitingEntries:  counts.WaitingEntries,
+		WaitingSeats:    counts.WaitingSeats,
+		OfferedEntries:  counts.OfferedEntries,
+		OfferedSeats:    counts.OfferedSeats,
+		AcceptedEntries: counts.AcceptedEntries,
+		ExpiredEntries:  counts.ExpiredEntries,
+		OfferTTLSeconds: int(s.config.OfferTTL.Seconds()),
+		Entries:         line,
+	}, nil
+}
+
+// ============================================================================
+// BOOKING
+// ============================================================================
+
+// ReserveForIntent checks the user may hold seatCount seats of the trip without taking seats
+// kept for someone else's offer. Returns the user's own open offer to mark accepted once the
+// intent is created, or "" when they have none.
+func (s *TripWaitlistService) ReserveForIntent(scheduledTripID string, userID uuid.UUID, seatCount int) (string, error) {
+	offered, err := s.repo.CountOfferedSeats(scheduledTripID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check waitlist offers: %w", err)
+	}
+	if offered == 0 {
+		return "", nil
+	}
+
+	own, err := s.repo.GetLiveEntry(scheduledTripID, userID.String())
+	if err != nil {
+		return "", err
+	}
+	if own != nil && !own.IsOffered(time.Now()) {
+		own = nil
+	}
+	free, err := s.repo.CountFreeSeats(scheduledTripID)
+	if err != nil {
+		return "", err
+	}
+	if models.WaitlistSeatsLeft(free, offered, own) < seatCount {
+		return "", ErrSeatsHeldForWaitlist
+	}
+	if own == nil {
+		return "", nil
+	}
+	return own.ID, nil
+}
+
+// MarkOfferAccepted closes an offer once an intent has been created with it. If the intent is
+// not paid, its seats are released and offered to the next in line.
+func (s *TripWaitlistService) MarkOfferAccepted(entryID string, intentID uuid.UUID) {
+	if err := s.repo.MarkAccepted(entryID, intentID.String()); err != nil {
+		s.logger.WithError(err).WithField("waitlist_entry_id", entryID).Warn("Failed to mark waitlist offer accepted")
+	}
+}
+
+// ============================================================================
+// PROMOTION
+// ============================================================================
+
+// Register subscribes the waitlist to booking.cancelled and booking.seats_cancelled, so
+// released seats are offered straight away
+func (s *TripWaitlistService) Register(dispatcher *OutboxDispatcher) {
+	dispatcher.Subscribe("waitlist_promotion", s.promoteCancelled, models.EventBookingCancelled, models.EventBookingSeatsCancelled)
+}
+
+func (s *TripWaitlistService) promoteCancelled(event events.Event) error {
+	data, err := decodeBookingEvent(event)
+	if err != nil {
+		return err
+	}
+	if data.ScheduledTripID == "" {
+		return nil // Lounge-only booking
+	}
+	return s.PromoteTrip(data.ScheduledTripID)
+}
+
+// PromoteTripAsync offers a trip's freed seats in the background, e.g. after an owner unblocks
+// seats
+func (s *TripWaitlistService) PromoteTripAsync(scheduledTripID string) {
+	go func() {
+		if err := s.PromoteTrip(scheduledTripID); err != nil {
+			s.logger.WithError(err).WithField("scheduled_trip_id", scheduledTripID).Error("Failed to promote trip waitlist")
+		}
+	}()
+}
+
+// PromoteTrip offers the trip's free seats that are not kept for open offers to the next
+// passengers in line, and notifies them
+func (s *TripWaitlistService) PromoteTrip(scheduledTripID string) error {
+	s.promoteMu.Lock()
+	defer s.promoteMu.Unlock()
+
+	waiting, err := s.repo.GetWaiting(scheduledTripID)
+	if err != nil || len(waiting) == 0 {
+		return err
+	}
+	free, err := s.repo.CountFreeSeats(scheduledTripID)
+	if err != nil {
+		return err
+	}
+	offeredSeats, err := s.repo.CountOfferedSeats(scheduledTripID)
+	if err != nil {
+		return err
+	}
+	plan := models.PlanWaitlistOffers(waiting, free, offeredSeats)
+	if len(plan) == 0 {
+		return nil
+	}
+
+	entryIDs := make([]string, len(plan))
+	for i, entry := range plan {
+		entryIDs[i] = entry.ID
+	}
+	offered, err := s.repo.Offer(entryIDs, time.Now().Add(s.config.OfferTTL))
+	if err != nil {
+		return err
+	}
+	for i := range offered {
+		s.push.NotifyUsersAsync([]string{offered[i].UserID}, waitlistOfferNotification(&offered[i]))
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"scheduled_trip_id": scheduledTripID,
+		"offered":           len(offered),
+		"free_seats":        free,
+	}).Info("Waitlist seats offered")
+	return nil
+}
+
+func waitlistOfferNotification(entry *models.TripWaitlistEntry) push.Message {
+	seats := "A seat has"
+	if entry.SeatCount > 1 {
+		seats = fmt.Sprintf("%d seats have", entry.SeatCount)
+	}
+	return push.Message{
+		Title: "Seats opened up on your trip",
+		Body:  fmt.Sprintf("%s been kept for you until %s. Book now to keep them.", seats, entry.OfferExpiresAt.In(models.ReportTimezone).Format("15:04")),
+		Data: map[string]string{
+			"type":              "waitlist_offer",
+			"scheduled_trip_id": entry.ScheduledTripID,
+			"waitlist_entry_id": entry.ID,
+		},
+	}
+}
+
+// Start begins the background promotion job
+func (s *TripWaitlistService) Start() {
+	s.logger.WithFields(logrus.Fields{
+		"interval":  s.config.CheckInterval.String(),
+		"offer_ttl": s.config.OfferTTL.String(),
+	}).Info("🎟️ Starting Trip Waitlist job")
+	go s.run()
+}
+
+// Stop stops the background promotion job
+func (s *TripWaitlistService) Stop() {
+	s.logger.Info("🛑 Stopping Trip Waitlist job")
+	close(s.stopCh)
+}
+
+func (s *TripWaitlistService) run() {
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processWaitlists()
+		case <-s.stopCh:
+			s.logger.Info("Trip Waitlist job stopped")
+			return
+		}
+	}
+}
+
+// RunOnce runs a single promotion cycle (useful for testing or manual trigger)
+func (s *TripWaitlistService) RunOnce() {
+	s.processWaitlists()
+}
+
+// processWaitlists expires lapsed offers first so their seats are offered again, then promotes
+// every upcoming trip with passengers waiting. This also catches seats freed by expired holds,
+// which nothing announces.
+func (s *TripWaitlistService) processWaitlists() {
+	if expired, err := s.repo.ExpireStale(); err != nil {
+		s.logger.WithError(err).Error("Failed to expire waitlist entries")
+	} else if expired > 0 {
+		s.logger.WithField("count", expired).Debug("Expired waitlist entries")
+	}
+
+	tripIDs, err := s.repo.GetTripsWithWaiting()
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get trips with waitlists")
+		return
+	}
+	for _, tripID := range tripIDs {
+		if err := s.PromoteTrip(tripID); err != nil {
+			s.logger.WithError(err).WithField("scheduled_trip_id", tripID).Error("Failed to promote trip waitlist")
+		}
+	}
+}
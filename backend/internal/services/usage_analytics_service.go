@@ -0,0 +1,241 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// maxUsageAppVersionLength bounds the client-supplied app version and platform kept per event
+const maxUsageAppVersionLength = 32
+
+// UsageAnalyticsService collects anonymized usage events from the usage middleware and writes
+// them in batches off the request path, and builds the feature adoption and retention reports.
+// Events keep no user ID or IP address: users are only a salted hash, enough to count
+// distinct and returning users.
+type UsageAnalyticsService struct {
+	repo   *database.UsageAnalyticsRepository
+	config config.UsageAnalyticsConfig
+	logger *logrus.Logger
+	events chan models.UsageEvent
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	dropped  int64 // Events lost to a full buffer, logged at each flush
+	stopOnce sync.Once
+}
+
+// NewUsageAnalyticsService creates a new UsageAnalyticsService
+func NewUsageAnalyticsService(repo *database.UsageAnalyticsRepository, cfg config.UsageAnalyticsConfig, logger *logrus.Logger) *UsageAnalyticsService {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 10 * time.Second
+	}
+	return &UsageAnalyticsService{
+		repo:   repo,
+		config: cfg,
+		logger: logger,
+		events: make(chan models.UsageEvent, cfg.BufferSize),
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+}
+
+// ============================================================================
+// COLLECTION
+// ============================================================================
+
+// Record queues a finished request as a usage event. It never blocks: when the buffer is full
+// the event is dropped and counted.
+func (s *UsageAnalyticsService) Record(req models.UsageRequest) {
+	if !s.config.Enabled {
+		return
+	}
+	select {
+	case s.events <- s.newUsageEvent(req):
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+// newUsageEvent reduces a request to what analytics keeps
+func (s *UsageAnalyticsService) newUsageEvent(req models.UsageRequest) models.UsageEvent {
+	event := models.UsageEvent{
+		Event:      models.UsageEventName(req.Method, req.Route),
+		Role:       models.UsageRole(req.Roles, req.SignedIn),
+		AppVersion: usageLabel(req.AppVersion),
+		Platform:   usageLabel(strings.ToLower(req.Platform)),
+		StatusCode: req.StatusCode,
+		OccurredAt: req.At,
+	}
+
+	feature := req.Feature
+	if feature == "" {
+		feature = models.UsageFeatureForEvent(event.Event)
+	}
+	if feature != "" {
+		event.Feature = &feature
+	}
+
+	if req.SignedIn && req.UserID != "" {
+		hash := usageUserHash(s.config.HashSalt, req.UserID)
+		event.UserHash = &hash
+	}
+	return event
+}
+
+// usageUserHash is the salted SHA-256 a user is counted under, hashed like the retention
+// job's phone and IP hashes
+func usageUserHash(salt, userID string) string {
+	sum := sha256.Sum256([]byte(salt + userID))
+	return hex.EncodeToString(sum[:])
+}
+
+// usageLabel trims a client-supplied label and bounds its length; nil when empty
+func usageLabel(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if len(value) > maxUsageAppVersionLength {
+		value = value[:maxUsageAppVersionLength]
+	}
+	return &value
+}
+
+// Start begins writing queued events
+func (s *UsageAnalyticsService) Start() {
+	if !s.config.Enabled {
+		s.logger.Info("Usage analytics disabled (USAGE_ANALYTICS_ENABLED=false)")
+		close(s.doneCh)
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"batch_size":     s.config.BatchSize,
+		"flush_interval": s.config.FlushInterval.String(),
+	}).Info("📊 Starting Usage Analytics writer")
+	go s.run()
+}
+
+// Stop writes the events still queued and stops the writer
+func (s *UsageAnalyticsService) Stop() {
+	s.stopOnce.Do(func() {
+		if s.config.Enabled {
+			s.logger.Info("🛑 Stopping Usage Analytics writer")
+			close(s.stopCh)
+		}
+	})
+	<-s.doneCh
+}
+
+func (s *UsageAnalyticsService) run() {
+	defer close(s.doneCh)
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.UsageEvent, 0, s.config.BatchSize)
+	for {
+		select {
+		case event := <-s.events:
+			batch = append(batch, event)
+			if len(batch) >= s.config.BatchSize {
+				batch = s.flush(batch)
+			}
+		case <-ticker.C:
+			batch = s.flush(batch)
+		case <-s.stopCh:
+			for {
+				select {
+				case event := <-s.events:
+					batch = append(batch, event)
+					if len(batch) >= s.config.BatchSize {
+						batch = s.flush(batch)
+					}
+				default:
+					s.flush(batch)
+					s.logger.Info("Usage Analytics writer stopped")
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes a batch, returning it emptied for reuse. A failed batch is dropped rather than
+// retried, so a database outage can't grow memory.
+func (s *UsageAnalyticsService) flush(batch []models.UsageEvent) []models.UsageEvent {
+	if dropped := atomic.SwapInt64(&s.dropped, 0); dropped > 0 {
+		s.logger.WithField("dropped", dropped).Warn("Usage analytics buffer full, events dropped")
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := s.repo.InsertEvents(batch); err != nil {
+		s.logger.WithError(err).WithField("events", len(batch)).Error("Failed to write usage events")
+	}
+	return batch[:0]
+}
+
+// ============================================================================
+// REPORTS
+// ============================================================================
+
+// GetUsageReport returns feature or endpoint adoption by role over the requested dates
+func (s *UsageAnalyticsService) GetUsageReport(req *models.UsageReportRequest) (*models.UsageReport, error) {
+	from, to, err := req.Validate(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.GetAdoption(from, to, req.GroupBy, req.Role, req.Feature)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []models.UsageAdoptionRow{}
+	}
+
+	return &models.UsageReport{
+		From:     from.Format("2006-01-02"),
+		To:       to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Timezone: models.ReportTimezone.String(),
+		GroupBy:  req.GroupBy,
+		Rows:     rows,
+	}, nil
+}
+
+// GetRetention returns weekly cohort retention for a feature, for users who first used it
+// within the requested dates
+func (s *UsageAnalyticsService) GetRetention(req *models.UsageRetentionRequest) (*models.UsageRetention, error) {
+	from, to, err := req.Validate(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := s.repo.GetRetention(req.Feature, req.Role, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UsageRetention{
+		Feature:  req.Feature,
+		Role:     req.Role,
+		From:     from.Format("2006-01-02"),
+		To:       to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Timezone: models.ReportTimezone.String(),
+		Cohorts:  models.BuildUsageRetentionCohorts(activity),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageAnalyticsService_NewUsageEvent(t *testing.T) {
+	s := NewUsageAnalyticsService(nil, config.UsageAnalyticsConfig{Enabled: true, HashSalt: "salt"}, logrus.New())
+	at := time.Now()
+
+	event := s.newUsageEvent(models.UsageRequest{
+		Method: "POST", Route: "/api/v1/lounge-bookings",
+		Roles: []string{"passenger"}, SignedIn: true, UserID: "user-1",
+		AppVersion: " 1.4.2 ", Platform: "Android", StatusCode: 201, At: at,
+	})
+	assert.Equal(t, "POST /api/v1/lounge-bookings", event.Event)
+	require.NotNil(t, event.Feature)
+	assert.Equal(t, models.UsageFeatureLoungeBooking, *event.Feature)
+	assert.Equal(t, "passenger", event.Role)
+	assert.Equal(t, "1.4.2", *event.AppVersion)
+	assert.Equal(t, "android", *event.Platform)
+	require.NotNil(t, event.UserHash)
+	assert.Equal(t, usageUserHash("salt", "user-1"), *event.UserHash)
+	assert.NotContains(t, *event.UserHash, "user-1")
+	assert.Len(t, *event.UserHash, 64)
+
+	tagged := s.newUsageEvent(models.UsageRequest{Method: "POST", Route: "/api/v1/booking/intent", Feature: models.UsageFeatureLoungeAddOn})
+	assert.Equal(t, models.UsageFeatureLoungeAddOn, *tagged.Feature, "a handler's tag overrides the route")
+	assert.Equal(t, models.UsageRoleAnonymous, tagged.Role)
+	assert.Nil(t, tagged.UserHash)
+	assert.Nil(t, tagged.AppVersion)
+
+	untracked := s.newUsageEvent(models.UsageRequest{Method: "GET", Route: "/api/v1/lounges/:id", AppVersion: strings.Repeat("9", 100), Platform: "  "})
+	assert.Nil(t, untracked.Feature)
+	assert.Len(t, *untracked.AppVersion, maxUsageAppVersionLength)
+	assert.Nil(t, untracked.Platform, "blank labels are dropped")
+
+	assert.NotEqual(t, usageUserHash("salt", "user-1"), usageUserHash("other", "user-1"), "the salt changes the hash")
+}
+
+func TestUsageAnalyticsService_RecordDropsWhenFull(t *testing.T) {
+	s := NewUsageAnalyticsService(nil, config.UsageAnalyticsConfig{Enabled: true, BufferSize: 2}, logrus.New())
+	req := models.UsageRequest{Method: "GET", Route: "/api/v1/search/popular"}
+
+	for i := 0; i < 5; i++ {
+		s.Record(req)
+	}
+	assert.Len(t, s.events, 2)
+	assert.Equal(t, int64(3), s.dropped)
+
+	disabled := NewUsageAnalyticsService(nil, config.UsageAnalyticsConfig{Enabled: false}, logrus.New())
+	disabled.Record(req)
+	assert.Empty(t, disabled.events)
+}
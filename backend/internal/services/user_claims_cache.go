@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/pkg/jwt"
+)
+
+// userClaimsCacheMaxEntries bounds memory; expired entries are swept when the cache fills up
+const userClaimsCacheMaxEntries = 50000
+
+// UserClaimsCache replaces the roles in access token claims with the user's current ones, so a
+// role granted or revoked after sign-in applies without a new login. Roles are re-read at most
+// once per TTL; Invalidate applies a change made on this instance at once. Tokens of admins, who
+// are not in the users table, keep their claims. A nil cache leaves claims as issued.
+type UserClaimsCache struct {
+	userRepo *database.UserRepository
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]userClaimsEntry
+}
+
+type userClaimsEntry struct {
+	found     bool
+	roles     []string
+	expiresAt time.Time
+}
+
+// NewUserClaimsCache creates a new UserClaimsCache, or nil when claim enrichment is disabled
+func NewUserClaimsCache(userRepo *database.UserRepository, cfg config.JWTConfig) *UserClaimsCache {
+	if !cfg.ClaimsEnrichment {
+		return nil
+	}
+	return &UserClaimsCache{
+		userRepo: userRepo,
+		ttl:      cfg.ClaimsCacheTTL,
+		entries:  make(map[uuid.UUID]userClaimsEntry),
+	}
+}
+
+// EnrichClaims implements jwt.ClaimsEnricher
+func (c *UserClaimsCache) EnrichClaims(claims *jwt.Claims) error {
+	if c == nil {
+		return nil
+	}
+	entry, err := c.get(claims.UserID, time.Now())
+	if err != nil {
+		return err
+	}
+	if entry.found {
+		claims.Roles = append([]string(nil), entry.roles...)
+	}
+	return nil
+}
+
+// Invalidate drops the cached roles of users whose roles just changed
+func (c *UserClaimsCache) Invalidate(userIDs ...uuid.UUID) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, userID := range userIDs {
+		delete(c.entries, userID)
+	}
+}
+
+func (c *UserClaimsCache) get(userID uuid.UUID, now time.Time) (userClaimsEntry, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[userID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry, nil
+	}
+
+	user, err := c.userRepo.GetUserByID(userID)
+	if err != nil {
+		return userClaimsEntry{}, fmt.Errorf("failed to load user claims: %w", err)
+	}
+	entry = userClaimsEntry{expiresAt: now.Add(c.ttl)}
+	if user != nil {
+		entry.found = true
+		entry.roles = user.Roles
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= userClaimsCacheMaxEntries {
+		for id, cached := range c.entries {
+			if !now.Before(cached.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	if len(c.entries) < userClaimsCacheMaxEntries {
+		c.entries[userID] = entry
+	}
+	return entry, nil
+}
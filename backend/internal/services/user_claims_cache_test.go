@@ -0,0 +1,81 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/smarttransit/sms-auth-backend/internal/config"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupUserClaimsCacheTest(t *testing.T) (*UserClaimsCache, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	userRepo := database.NewUserRepository(&database.PostgresDB{DB: sqlx.NewDb(db, "sqlmock")}, nil)
+	cache := NewUserClaimsCache(userRepo, config.JWTConfig{ClaimsEnrichment: true, ClaimsCacheTTL: time.Minute})
+	return cache, mock
+}
+
+func expectUserRoles(mock sqlmock.Sqlmock, userID uuid.UUID, roles string) {
+	mock.ExpectQuery(`SELECT (.+) FROM users`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "phone", "roles", "status"}).
+			AddRow(userID, "+94771234567", roles, "active"))
+}
+
+func TestUserClaimsCache_EnrichClaims(t *testing.T) {
+	cache, mock := setupUserClaimsCacheTest(t)
+	userID := uuid.New()
+
+	expectUserRoles(mock, userID, "{passenger,driver}")
+	claims := &jwt.Claims{UserID: userID, Roles: []string{"passenger"}, ProfileCompleted: true}
+	require.NoError(t, cache.EnrichClaims(claims))
+	assert.Equal(t, []string{"passenger", "driver"}, claims.Roles, "role granted after sign-in")
+	assert.True(t, claims.ProfileCompleted, "profile completion stays as issued")
+
+	// Served from the cache: no further query expected
+	again := &jwt.Claims{UserID: userID, Roles: []string{"passenger"}}
+	require.NoError(t, cache.EnrichClaims(again))
+	assert.Equal(t, []string{"passenger", "driver"}, again.Roles)
+
+	// A role change on this instance applies at once
+	cache.Invalidate(userID)
+	expectUserRoles(mock, userID, "{passenger}")
+	revoked := &jwt.Claims{UserID: userID, Roles: []string{"passenger", "driver"}}
+	require.NoError(t, cache.EnrichClaims(revoked))
+	assert.Equal(t, []string{"passenger"}, revoked.Roles)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserClaimsCache_UnknownUserKeepsClaims(t *testing.T) {
+	cache, mock := setupUserClaimsCacheTest(t)
+	adminID := uuid.New()
+
+	mock.ExpectQuery(`SELECT (.+) FROM users`).WithArgs(adminID).WillReturnError(sql.ErrNoRows)
+	claims := &jwt.Claims{UserID: adminID, Roles: []string{"admin"}}
+	require.NoError(t, cache.EnrichClaims(claims))
+	assert.Equal(t, []string{"admin"}, claims.Roles, "admins are not in the users table")
+
+	require.NoError(t, cache.EnrichClaims(claims), "miss is cached too")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserClaimsCache_Disabled(t *testing.T) {
+	cache := NewUserClaimsCache(nil, config.JWTConfig{ClaimsEnrichment: false})
+	assert.Nil(t, cache)
+
+	claims := &jwt.Claims{UserID: uuid.New(), Roles: []string{"passenger"}}
+	assert.NoError(t, cache.EnrichClaims(claims))
+	assert.Equal(t, []string{"passenger"}, claims.Roles)
+	cache.Invalidate(claims.UserID)
+}
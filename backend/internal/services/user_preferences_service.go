@@ -0,0 +1,88 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+// UserPreferencesService is the single source of a user's language, notification, messaging and
+// seat preferences. Notification delivery asks it which channels a user accepts, OTP delivery
+// whether they prefer WhatsApp; the seat position is mirrored onto the passenger profile's
+// preferred seat type.
+type UserPreferencesService struct {
+	repo          *database.UserPreferencesRepository
+	passengerRepo *database.PassengerRepository
+	logger        *logrus.Logger
+}
+
+// NewUserPreferencesService creates a new UserPreferencesService
+func NewUserPreferencesService(repo *database.UserPreferencesRepository, passengerRepo *database.PassengerRepository, logger *logrus.Logger) *UserPreferencesService {
+	return &UserPreferencesService{
+		repo:          repo,
+		passengerRepo: passengerRepo,
+		logger:        logger,
+	}
+}
+
+// Get returns the user's preferences, or the defaults if they never saved any
+func (s *UserPreferencesService) Get(userID uuid.UUID) (*models.UserPreferences, error) {
+	prefs, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil {
+		return models.DefaultUserPreferences(userID), nil
+	}
+	return prefs, nil
+}
+
+// Update applies the request to the user's preferences and saves them
+func (s *UserPreferencesService) Update(userID uuid.UUID, req *models.UpdateUserPreferencesRequest) (*models.UserPreferences, error) {
+	prefs, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	previousPosition := prefs.Position
+	if err := req.Apply(prefs); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Upsert(prefs); err != nil {
+		return nil, err
+	}
+
+	if prefs.Position != previousPosition && s.passengerRepo != nil {
+		var seatType *string
+		if prefs.Position != models.SeatPreferenceAny {
+			seatType = &prefs.Position
+		}
+		if err := s.passengerRepo.UpdatePreferredSeatType(userID, seatType); err != nil {
+			s.logger.WithError(err).WithField("user_id", userID).Warn("Failed to mirror seat preference to passenger profile")
+		}
+	}
+	return prefs, nil
+}
+
+// AllowsChannel reports whether the user accepts non-essential notifications over the channel.
+// If preferences cannot be loaded the notification is allowed, so a database hiccup does not
+// silently drop reminders.
+func (s *UserPreferencesService) AllowsChannel(userID uuid.UUID, channel models.NotificationChannel) bool {
+	prefs, err := s.Get(userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Warn("Failed to load notification preferences")
+		return true
+	}
+	return prefs.AllowsChannel(channel)
+}
+
+// PrefersWhatsApp reports whether the user wants OTPs and booking confirmations over WhatsApp.
+// If preferences cannot be loaded SMS is used.
+func (s *UserPreferencesService) PrefersWhatsApp(userID uuid.UUID) bool {
+	prefs, err := s.Get(userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Warn("Failed to load messaging preference")
+		return false
+	}
+	return prefs.PrefersWhatsApp()
+}
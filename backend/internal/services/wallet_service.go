@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+)
+
+var (
+	ErrWalletInsufficientBalance = errors.New("wallet balance is too low")
+	ErrWalletTopUpUnavailable    = errors.New("wallet top-ups need the payment gateway, which is not configured")
+)
+
+// WalletService manages passengers' stored credit. Money comes in through PAYable top-ups and
+// refunds, and goes out towards booking intents; a wallet payment on an intent that is never
+// confirmed is returned to the wallet.
+type WalletService struct {
+	repo           *database.WalletRepository
+	userRepo       *database.UserRepository
+	payableService *PAYableService
+	logger         *logrus.Logger
+}
+
+// NewWalletService creates a new WalletService
+func NewWalletService(
+	repo *database.WalletRepository,
+	userRepo *database.UserRepository,
+	payableService *PAYableService,
+	logger *logrus.Logger,
+) *WalletService {
+	return &WalletService{
+		repo:           repo,
+		userRepo:       userRepo,
+		payableService: payableService,
+		logger:         logger,
+	}
+}
+
+// GetWallet returns the user's wallet with a page of its ledger
+func (s *WalletService) GetWallet(userID uuid.UUID, limit, offset int) (*models.WalletPage, error) {
+	wallet, err := s.repo.GetOrCreate(userID.String(), models.WalletCurrencyLKR)
+	if err != nil {
+		return nil, err
+	}
+	entries, total, err := s.repo.ListEntries(wallet.ID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WalletPage{Wallet: wallet, Entries: entries, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Balance returns the user's wallet balance, zero if they have no wallet
+func (s *WalletService) Balance(userID uuid.UUID) (models.Money, error) {
+	wallet, err := s.repo.GetByUser(userID.String())
+	if err != nil || wallet == nil {
+		return models.Money{}, err
+	}
+	return wallet.Balance, nil
+}
+
+// ============================================================================
+// TOP-UPS
+// ============================================================================
+
+// ListTopUps returns the user's top-ups, newest first
+func (s *WalletService) ListTopUps(userID uuid.UUID, limit, offset int) ([]models.WalletTopUp, error) {
+	return s.repo.ListTopUps(userID.String(), limit, offset)
+}
+
+// InitiateTopUp starts a PAYable payment that adds money to the user's wallet once the
+// payment webhook reports it paid
+func (s *WalletService) InitiateTopUp(userID uuid.UUID, phone string, req *models.WalletTopUpRequest) (*models.WalletTopUpResponse, error) {
+	if s.payableService == nil || !s.payableService.IsConfigured() {
+		return nil, ErrWalletTopUpUnavailable
+	}
+	if !s.payableService.IsAvailable() {
+		return nil, ErrPaymentUnavailable
+	}
+	wallet, err := s.repo.GetOrCreate(userID.String(), models.WalletCurrencyLKR)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Validate(wallet.Balance); err != nil {
+		return nil, err
+	}
+
+	topUp := &models.WalletTopUp{
+		UserID:    userID.String(),
+		Amount:    models.NewMoney(req.Amount),
+		Currency:  wallet.Currency,
+		Status:    models.WalletTopUpPending,
+		InvoiceID: "WTU-" + strings.ToUpper(uuid.New().String()[:8]),
+	}
+	if err := s.repo.CreateTopUp(topUp); err != nil {
+		return nil, err
+	}
+
+	amount := topUp.Amount.String()
+	payableResp, err := s.payableService.InitiatePayment(&InitiatePaymentParams{
+		InvoiceID:        topUp.InvoiceID,
+		Amount:           amount,
+		CurrencyCode:     topUp.Currency,
+		CustomerName:     s.customerName(userID, phone),
+		CustomerPhone:    phone,
+		OrderDescription: fmt.Sprintf("Wallet Top-up - %s", topUp.InvoiceID),
+	})
+	if err != nil {
+		s.logger.WithError(err).WithField("top_up_id", topUp.ID).Error("Failed to initiate PAYable wallet top-up")
+		if failErr := s.repo.FailTopUp(topUp.ID, err.Error()); failErr != nil {
+			s.logger.WithError(failErr).WithField("top_up_id", topUp.ID).Error("Failed to mark wallet top-up failed")
+		}
+		return nil, fmt.Errorf("payment gateway error: %w", err)
+	}
+	if err := s.repo.SetTopUpPayment(topUp.ID, payableResp.UID, payableResp.StatusIndicator); err != nil {
+		return nil, err
+	}
+	topUp.PaymentUID = &payableResp.UID
+
+	s.logger.WithFields(logrus.Fields{
+		"top_up_id":  topUp.ID,
+		"invoice_id": topUp.InvoiceID,
+		"amount":     topUp.Amount,
+		"uid":        payableResp.UID,
+	}).Info("PAYable wallet top-up initiated")
+	return &models.WalletTopUpResponse{TopUp: topUp, PaymentURL: payableResp.PaymentPage, UID: payableResp.UID}, nil
+}
+
+// customerName is the name PAYable shows on the payment page, the phone number if the profile has none
+func (s *WalletService) customerName(userID uuid.UUID, phone string) string {
+	if s.userRepo == nil {
+		return phone
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil || user == nil {
+		return phone
+	}
+	name := strings.TrimSpace(user.FirstName.String + " " + user.LastName.String)
+	if name == "" {
+		return phone
+	}
+	return name
+}
+
+// GetTopUpByPaymentUID returns the top-up paid with a PAYable UID, nil if the UID is not a top-up
+func (s *WalletService) GetTopUpByPaymentUID(uid string) (*models.WalletTopUp, error) {
+	return s.repo.GetTopUpByPaymentUID(uid)
+}
+
+// CompleteTopUp credits a paid top-up to its wallet. Returns nil if it was already settled.
+func (s *WalletService) CompleteTopUp(topUp *models.WalletTopUp) (*models.WalletEntry, error) {
+	entry, err := s.repo.CompleteTopUp(topUp.ID)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		s.logger.WithFields(logrus.Fields{
+			"top_up_id":     topUp.ID,
+			"user_id":       topUp.UserID,
+			"amount":        topUp.Amount,
+			"balance_after": entry.BalanceAfter,
+		}).Info("Wallet topped up")
+	}
+	return entry, nil
+}
+
+// FailTopUp records that a top-up's payment failed or was cancelled
+func (s *WalletService) FailTopUp(topUp *models.WalletTopUp, reason string) error {
+	return s.repo.FailTopUp(topUp.ID, reason)
+}
+
+// ============================================================================
+// BOOKINGS
+// ============================================================================
+
+// PayIntent takes amount from the user's wallet towards a booking intent
+func (s *WalletService) PayIntent(intent *models.BookingIntent, amount models.Money) error {
+	description := fmt.Sprintf("Booking payment INT-%s", intent.ID.String()[:8])
+	entry, err := s.repo.PayIntent(intent.UserID.String(), intent.ID.String(), amount, description)
+	if errors.Is(err, database.ErrWalletInsufficientBalance) {
+		return ErrWalletInsufficientBalance
+	}
+	if err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"intent_id":     intent.ID,
+		"amount":        amount,
+		"balance_after": entry.BalanceAfter,
+	}).Info("Booking intent paid from wallet")
+	return nil
+}
+
+// ReleaseIntent returns the wallet payment of an intent that will not be confirmed. It is
+// best-effort and safe to repeat: failures are logged, and an intent is only released once.
+func (s *WalletService) ReleaseIntent(intentID, userID uuid.UUID) {
+	if s == nil {
+		return
+	}
+	entry, err := s.repo.ReleaseIntent(userID.String(), intentID.String())
+	if err != nil {
+		s.logger.WithError(err).WithField("intent_id", intentID).Error("Failed to return wallet payment of unconfirmed intent")
+		return
+	}
+	if entry != nil {
+		s.logger.WithFields(logrus.Fields{
+			"intent_id":     intentID,
+			"amount":        entry.Amount,
+			"balance_after": entry.BalanceAfter,
+		}).Info("Wallet payment of unconfirmed intent returned")
+	}
+}
+
+// CreditRefund pays a refund into the passenger's wallet; crediting the same refund again
+// returns the original entry
+func (s *WalletService) CreditRefund(refund *models.Refund) (*models.WalletEntry, error) {
+	if _, err := s.repo.GetOrCreate(refund.UserID, refund.Currency); err != nil {
+		return nil, err
+	}
+	entry, err := s.repo.CreditRefund(refund.UserID, refund.ID.String(), models.NewMoney(refund.Amount), "Refund of booking "+refund.BookingReference)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"refund_id":     refund.ID,
+		"amount":        refund.Amount,
+		"balance_after": entry.BalanceAfter,
+	}).Info("Refund credited to wallet")
+	return entry, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/smarttransit/sms-auth-backend/internal/database"
+	"github.com/smarttransit/sms-auth-backend/internal/models"
+	"github.com/smarttransit/sms-auth-backend/pkg/events"
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+	"github.com/smarttransit/sms-auth-backend/pkg/messaging"
+)
+
+// BookingConfirmationSender delivers a booking confirmation to a passenger's phone
+type BookingConfirmationSender interface {
+	SendBookingConfirmation(phone string, booking messaging.BookingConfirmation) error
+}
+
+// WhatsAppBookingConfirmations sends the booking reference and boarding QR over WhatsApp to
+// passengers who chose WhatsApp as their messaging channel, driven by booking.confirmed
+type WhatsAppBookingConfirmations struct {
+	userRepo    *database.UserRepository
+	preferences *UserPreferencesService
+	bookingRepo *database.AppBookingRepository
+	sender      BookingConfirmationSender
+	logger      *logrus.Logger
+}
+
+// NewWhatsAppBookingConfirmations creates a new WhatsAppBookingConfirmations
+func NewWhatsAppBookingConfirmations(
+	userRepo *database.UserRepository,
+	preferences *UserPreferencesService,
+	bookingRepo *database.AppBookingRepository,
+	sender BookingConfirmationSender,
+	logger *logrus.Logger,
+) *WhatsAppBookingConfirmations {
+	return &WhatsAppBookingConfirmations{
+		userRepo:    userRepo,
+		preferences: preferences,
+		bookingRepo: bookingRepo,
+		sender:      sender,
+		logger:      logger,
+	}
+}
+
+// Register subscribes the confirmations to the dispatcher. Register it after the other
+// booking.confirmed consumers: the message cannot be taken back, so it should only go out
+// once nothing before it can fail and have the event redelivered.
+func (w *WhatsAppBookingConfirmations) Register(dispatcher *OutboxDispatcher) {
+	dispatcher.Subscribe("whatsapp_booking_confirmation", w.send, models.EventBookingConfirmed)
+}
+
+// send delivers the confirmation. Only an open circuit breaker is retried; other failures,
+// such as a number not on WhatsApp, are logged since the booking is in the app regardless.
+func (w *WhatsAppBookingConfirmations) send(event events.Event) error {
+	data, err := decodeBookingEvent(event)
+	if err != nil {
+		return err
+	}
+	if data.BookingID == "" {
+		return nil
+	}
+
+	userID, err := uuid.Parse(data.UserID)
+	if err != nil || !w.preferences.PrefersWhatsApp(userID) {
+		return nil
+	}
+	user, err := w.userRepo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to load user for booking confirmation: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	booking, err := w.bookingRepo.GetBookingByID(data.BookingID)
+	if err != nil {
+		return fmt.Errorf("failed to load booking for confirmation: %w", err)
+	}
+
+	if err := w.sender.SendBookingConfirmation(user.Phone, bookingConfirmation(booking)); err != nil {
+		if errors.Is(err, httpclient.ErrCircuitOpen) {
+			return err
+		}
+		w.logger.WithError(err).WithField("booking_id", booking.ID).Warn("Failed to send WhatsApp booking confirmation")
+	}
+	return nil
+}
+
+// bookingConfirmation summarises a booking for the confirmation message
+func bookingConfirmation(booking *models.MasterBooking) messaging.BookingConfirmation {
+	confirmation := messaging.BookingConfirmation{Reference: booking.BookingReference}
+
+	bus := booking.BusBooking
+	if bus == nil {
+		confirmation.Details = fmt.Sprintf("%d lounge booking(s)", len(booking.LoungeBookings))
+		return confirmation
+	}
+
+	var details []string
+	if bus.BoardingStopName != "" && bus.AlightingStopName != "" {
+		details = append(details, bus.BoardingStopName+" - "+bus.AlightingStopName)
+	} else if bus.RouteName != "" {
+		details = append(details, bus.RouteName)
+	}
+	if bus.DepartureDatetime != nil {
+		details = append(details, bus.DepartureDatetime.In(models.ReportTimezone).Format("Jan 2 15:04"))
+	}
+	seats := "1 seat"
+	if bus.NumberOfSeats != 1 {
+		seats = fmt.Sprintf("%d seats", bus.NumberOfSeats)
+	}
+	confirmation.Details = strings.Join(append(details, seats), ", ")
+
+	if bus.QRCodeData != nil {
+		confirmation.QRCodeData = *bus.QRCodeData
+	}
+	return confirmation
+}
@@ -0,0 +1,131 @@
+// Package email delivers transactional email, with optional file attachments.
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Attachment is a file sent with a message
+type Attachment struct {
+	Filename    string
+	ContentType string // e.g. "text/csv", "application/pdf"
+	Data        []byte
+}
+
+// Message is a plain-text email
+type Message struct {
+	To          string
+	Subject     string
+	Body        string
+	Headers     map[string]string // Extra headers (e.g. List-Unsubscribe)
+	Attachments []Attachment
+}
+
+// Sender delivers email messages
+type Sender interface {
+	// Send delivers the message
+	Send(msg Message) error
+
+	// GetName returns the name of the email provider implementation
+	GetName() string
+}
+
+// LogSender prints messages instead of sending them (development mode)
+type LogSender struct{}
+
+// NewLogSender creates a new LogSender
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send logs the message
+func (s *LogSender) Send(msg Message) error {
+	names := make([]string, 0, len(msg.Attachments))
+	for _, a := range msg.Attachments {
+		names = append(names, fmt.Sprintf("%s (%d bytes)", a.Filename, len(a.Data)))
+	}
+	fmt.Printf("📧 [DEV EMAIL] to=%s subject=%q attachments=%v\n", msg.To, msg.Subject, names)
+	return nil
+}
+
+// GetName returns the provider name
+func (s *LogSender) GetName() string {
+	return "log"
+}
+
+// BuildMIME renders a message as an RFC 5322 message with a multipart/mixed body
+func BuildMIME(from string, msg Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	for key, value := range msg.Headers {
+		header(key, value)
+	}
+	header("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", writer.Boundary()))
+	buf.WriteString("\r\n")
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create body part: %w", err)
+	}
+	if err := writeBase64(part, []byte(msg.Body)); err != nil {
+		return nil, err
+	}
+
+	for _, a := range msg.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create attachment part: %w", err)
+		}
+		if err := writeBase64(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close message: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data base64-encoded in 76 character lines
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var lines strings.Builder
+	for len(encoded) > 76 {
+		lines.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	lines.WriteString(encoded + "\r\n")
+	if _, err := w.Write([]byte(lines.String())); err != nil {
+		return fmt.Errorf("failed to write message part: %w", err)
+	}
+	return nil
+}
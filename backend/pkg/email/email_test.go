@@ -0,0 +1,130 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMIME_BodyAndAttachments(t *testing.T) {
+	msg := Message{
+		To:      "owner@example.com",
+		Subject: "Daily bookings – Mar 1",
+		Body:    "Your report is attached.",
+		Headers: map[string]string{"List-Unsubscribe": "<https://example.com/u/abc>"},
+		Attachments: []Attachment{
+			{Filename: "bookings.csv", ContentType: "text/csv", Data: []byte("a,b\n1,2\n")},
+		},
+	}
+
+	raw, err := BuildMIME("SmartTransit <reports@example.com>", msg, time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, msg.Subject, subject)
+	assert.Equal(t, "<https://example.com/u/abc>", parsed.Header.Get("List-Unsubscribe"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var parts []*multipart.Part
+	var contents []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		// multipart.Reader does not decode base64 transfer encoding
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts = append(parts, part)
+		contents = append(contents, strings.ReplaceAll(string(data), "\r\n", ""))
+	}
+
+	require.Len(t, parts, 2)
+	assert.Equal(t, "bookings.csv", parts[1].FileName())
+	assert.Equal(t, "YSxiCjEsMgo=", contents[1])
+}
+
+func TestSMTPSender_SendsToRecipient(t *testing.T) {
+	sender := NewSMTPSender(SMTPConfig{Host: "smtp.example.com", From: "reports@example.com", FromName: "SmartTransit"})
+
+	var gotAddr string
+	var gotTo []string
+	sender.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotTo = addr, to
+		assert.Nil(t, a, "no auth without a username")
+		assert.Equal(t, "reports@example.com", from)
+		return nil
+	}
+
+	require.NoError(t, sender.Send(Message{To: "owner@example.com", Subject: "Hi", Body: "Hello"}))
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, []string{"owner@example.com"}, gotTo)
+
+	assert.Error(t, sender.Send(Message{To: "not an address"}))
+}
+
+func TestSESSender_SendsRawMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+		assert.Equal(t, "20260314T093000Z", r.Header.Get("X-Amz-Date"))
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260314/ap-south-1/ses/aws4_request, "), auth)
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=")
+
+		var req sesSendEmailRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, []string{"rider@example.com"}, req.Destination.ToAddresses)
+		assert.Equal(t, `"SmartTransit" <bookings@example.com>`, req.FromEmailAddress)
+		parsed, err := mail.ReadMessage(bytes.NewReader(req.Content.Raw.Data))
+		require.NoError(t, err)
+		assert.Equal(t, "rider@example.com", parsed.Header.Get("To"))
+
+		w.Write([]byte(`{"MessageId": "0100-abc"}`))
+	}))
+	defer server.Close()
+
+	sender := NewSESSender(SESConfig{
+		Region:          "ap-south-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		Endpoint:        server.URL,
+		From:            "bookings@example.com",
+		FromName:        "SmartTransit",
+	})
+	sender.now = func() time.Time { return time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC) }
+
+	require.NoError(t, sender.Send(Message{To: "rider@example.com", Subject: "Booking confirmed", Body: "See you on board"}))
+}
+
+func TestSESSender_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message": "Email address is not verified."}`))
+	}))
+	defer server.Close()
+
+	sender := NewSESSender(SESConfig{Region: "ap-south-1", Endpoint: server.URL, From: "bookings@example.com"})
+	err := sender.Send(Message{To: "rider@example.com", Subject: "Hi", Body: "Hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not verified")
+}
@@ -0,0 +1,176 @@
+package email
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+)
+
+// SESConfig holds Amazon SES (API v2) configuration
+type SESConfig struct {
+	Region          string // e.g. "ap-south-1"
+	AccessKeyID     string
+	SecretAccessKey string
+	Endpoint        string // Defaults to https://email.{region}.amazonaws.com
+	From            string // Sender address, verified in SES
+	FromName        string // Display name shown to recipients
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker (zero values use defaults)
+}
+
+// SESSender sends email through the Amazon SES v2 SendEmail API as raw MIME messages, so
+// attachments go the same way as over SMTP
+type SESSender struct {
+	config SESConfig
+	client *httpclient.Client
+	now    func() time.Time
+}
+
+// NewSESSender creates a new SESSender
+func NewSESSender(config SESConfig) *SESSender {
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", config.Region)
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	if config.HTTP.Name == "" {
+		config.HTTP.Name = "ses"
+	}
+	return &SESSender{
+		config: config,
+		client: httpclient.New(config.HTTP),
+		now:    time.Now,
+	}
+}
+
+type sesSendEmailRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Raw struct {
+			Data []byte `json:"Data"` // Base64 encoded by encoding/json
+		} `json:"Raw"`
+	} `json:"Content"`
+}
+
+// Send delivers the message
+func (s *SESSender) Send(msg Message) error {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	now := s.now().UTC()
+	from := (&mail.Address{Name: s.config.FromName, Address: s.config.From}).String()
+	raw, err := BuildMIME(from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	var body sesSendEmailRequest
+	body.FromEmailAddress = from
+	body.Destination.ToAddresses = []string{msg.To}
+	body.Content.Raw.Data = raw
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SES request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.config.Endpoint+"/v2/email/outbound-emails", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create SES request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.sign(req, payload, now)
+
+	// Sent once - a retried request could deliver the email twice
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Message != "" {
+			return fmt.Errorf("SES rejected email: %s (status %d)", errResp.Message, resp.StatusCode)
+		}
+		return fmt.Errorf("SES returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header for the "ses" service
+func (s *SESSender) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"content-type:" + req.Header.Get("Content-Type") + "\n" +
+			"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.config.Region + "/ses/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), day)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// GetName returns the provider name
+func (s *SESSender) GetName() string {
+	return "ses"
+}
+
+// Available reports whether the sender's circuit breaker lets requests through
+func (s *SESSender) Available() bool {
+	return s.client.Available()
+}
+
+// HTTPStats returns the sender's HTTP client metrics
+func (s *SESSender) HTTPStats() httpclient.Stats {
+	return s.client.Stats()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,66 @@
+package email
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig holds SMTP server configuration
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string // Sender address
+	FromName string // Display name shown to recipients
+}
+
+// SMTPSender sends email through an SMTP server (STARTTLS is used when the server offers it)
+type SMTPSender struct {
+	config SMTPConfig
+	send   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPSender creates a new SMTPSender
+func NewSMTPSender(config SMTPConfig) *SMTPSender {
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	return &SMTPSender{
+		config: config,
+		send:   smtp.SendMail,
+	}
+}
+
+// Send delivers the message
+func (s *SMTPSender) Send(msg Message) error {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	from := (&mail.Address{Name: s.config.FromName, Address: s.config.From}).String()
+	raw, err := BuildMIME(from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	if err := s.send(addr, auth, s.config.From, []string{msg.To}, raw); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// GetName returns the provider name
+func (s *SMTPSender) GetName() string {
+	return "smtp"
+}
@@ -0,0 +1,194 @@
+// Package events carries domain events between internal services. Publishers are pluggable:
+// an in-process channel bus today, a Kafka or NATS adapter later.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrBusFull indicates the bus buffer is full and the event was dropped
+	ErrBusFull = errors.New("event bus buffer is full")
+
+	// ErrBusClosed indicates the bus no longer accepts events
+	ErrBusClosed = errors.New("event bus is closed")
+)
+
+// Event is a structured domain event
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"` // e.g. "booking.confirmed"
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// NewEvent builds an event with a fresh ID, encoding data as its payload
+func NewEvent(eventType string, data interface{}) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	return Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       payload,
+	}, nil
+}
+
+// Publisher delivers events to their consumers
+type Publisher interface {
+	// Publish hands the event over for delivery. It must not block the caller for long.
+	Publish(event Event) error
+
+	// GetName returns the name of the publisher implementation
+	GetName() string
+}
+
+// Handler consumes an event
+type Handler func(event Event)
+
+// NopPublisher discards events (events disabled)
+type NopPublisher struct{}
+
+// Publish discards the event
+func (NopPublisher) Publish(event Event) error {
+	return nil
+}
+
+// GetName returns the publisher name
+func (NopPublisher) GetName() string {
+	return "none"
+}
+
+// Stats counts a channel bus's traffic
+type Stats struct {
+	Published int64 `json:"published"`
+	Dropped   int64 `json:"dropped"`
+	Delivered int64 `json:"delivered"`
+	Panics    int64 `json:"panics"`
+}
+
+type subscription struct {
+	name    string
+	types   map[string]bool // Empty means every type
+	handler Handler
+}
+
+// ChannelBus is an in-process Publisher backed by a buffered channel. One goroutine hands
+// each event to the subscribers in order; Publish never blocks and drops events when full.
+type ChannelBus struct {
+	events chan Event
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	subs   []subscription
+	closed bool
+	stats  Stats
+
+	closeOnce sync.Once
+}
+
+// NewChannelBus creates a ChannelBus holding up to buffer undelivered events and starts delivery
+func NewChannelBus(buffer int) *ChannelBus {
+	if buffer <= 0 {
+		buffer = 1024
+	}
+	b := &ChannelBus{
+		events: make(chan Event, buffer),
+		done:   make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Subscribe registers a handler for the given event types, or for every type when none are
+// given. Handlers run on the bus goroutine, so slow work should be handed off.
+func (b *ChannelBus) Subscribe(name string, handler Handler, types ...string) {
+	sub := subscription{name: name, handler: handler, types: make(map[string]bool, len(types))}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+}
+
+// Publish queues the event for delivery
+func (b *ChannelBus) Publish(event Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+
+	select {
+	case b.events <- event:
+		b.stats.Published++
+		return nil
+	default:
+		b.stats.Dropped++
+		return ErrBusFull
+	}
+}
+
+// GetName returns the publisher name
+func (b *ChannelBus) GetName() string {
+	return "channel"
+}
+
+// Stats returns the bus's counters so far
+func (b *ChannelBus) Stats() Stats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.stats
+}
+
+// Close stops accepting events and waits for the queued ones to be delivered
+func (b *ChannelBus) Close() {
+	b.closeOnce.Do(func() {
+		b.mu.Lock()
+		b.closed = true
+		close(b.events)
+		b.mu.Unlock()
+	})
+	<-b.done
+}
+
+func (b *ChannelBus) run() {
+	defer close(b.done)
+	for event := range b.events {
+		b.mu.RLock()
+		subs := b.subs
+		b.mu.RUnlock()
+
+		for _, sub := range subs {
+			if len(sub.types) > 0 && !sub.types[event.Type] {
+				continue
+			}
+			b.deliver(sub, event)
+		}
+	}
+}
+
+// deliver runs one handler, so a panicking consumer cannot stop delivery to the others
+func (b *ChannelBus) deliver(sub subscription, event Event) {
+	defer func() {
+		recovered := recover()
+		b.mu.Lock()
+		if recovered != nil {
+			b.stats.Panics++
+		} else {
+			b.stats.Delivered++
+		}
+		b.mu.Unlock()
+	}()
+	sub.handler(event)
+}
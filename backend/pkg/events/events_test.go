@@ -0,0 +1,92 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEvent(t *testing.T) {
+	event, err := NewEvent("intent.created", map[string]string{"intent_id": "abc"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, "intent.created", event.Type)
+	assert.False(t, event.OccurredAt.IsZero())
+	assert.JSONEq(t, `{"intent_id":"abc"}`, string(event.Data))
+
+	_, err = NewEvent("bad", map[string]interface{}{"ch": make(chan int)})
+	assert.Error(t, err)
+}
+
+func TestChannelBus_DeliversByType(t *testing.T) {
+	bus := NewChannelBus(10)
+
+	var mu sync.Mutex
+	var all, confirmed []string
+	bus.Subscribe("all", func(e Event) {
+		mu.Lock()
+		all = append(all, e.Type)
+		mu.Unlock()
+	})
+	bus.Subscribe("confirmed", func(e Event) {
+		mu.Lock()
+		confirmed = append(confirmed, e.Type)
+		mu.Unlock()
+	}, "booking.confirmed")
+
+	for _, eventType := range []string{"intent.created", "booking.confirmed", "intent.expired"} {
+		event, err := NewEvent(eventType, json.RawMessage(`{}`))
+		require.NoError(t, err)
+		require.NoError(t, bus.Publish(event))
+	}
+	bus.Close()
+
+	assert.Equal(t, []string{"intent.created", "booking.confirmed", "intent.expired"}, all)
+	assert.Equal(t, []string{"booking.confirmed"}, confirmed)
+	assert.Equal(t, int64(3), bus.Stats().Published)
+	assert.Equal(t, int64(4), bus.Stats().Delivered)
+}
+
+func TestChannelBus_FullAndClosed(t *testing.T) {
+	bus := NewChannelBus(1)
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	bus.Subscribe("slow", func(e Event) {
+		started <- struct{}{}
+		<-release
+	})
+
+	require.NoError(t, bus.Publish(Event{Type: "a"}))
+	<-started // first event is being handled, buffer is empty again
+	require.NoError(t, bus.Publish(Event{Type: "b"}))
+	assert.ErrorIs(t, bus.Publish(Event{Type: "c"}), ErrBusFull)
+
+	close(release)
+	bus.Close()
+	assert.ErrorIs(t, bus.Publish(Event{Type: "d"}), ErrBusClosed)
+	assert.Equal(t, int64(1), bus.Stats().Dropped)
+	bus.Close() // closing twice is safe
+}
+
+func TestChannelBus_RecoversPanickingHandler(t *testing.T) {
+	bus := NewChannelBus(10)
+	var got int
+	bus.Subscribe("broken", func(e Event) { panic("boom") })
+	bus.Subscribe("ok", func(e Event) { got++ })
+
+	require.NoError(t, bus.Publish(Event{Type: "a"}))
+	require.NoError(t, bus.Publish(Event{Type: "b"}))
+	bus.Close()
+
+	assert.Equal(t, 2, got)
+	assert.Equal(t, int64(2), bus.Stats().Panics)
+}
+
+func TestNopPublisher(t *testing.T) {
+	var p Publisher = NopPublisher{}
+	assert.NoError(t, p.Publish(Event{Type: "a"}))
+	assert.Equal(t, "none", p.GetName())
+}
@@ -0,0 +1,164 @@
+// Package fieldcrypt encrypts individual database column values with AES-256-GCM. Values are
+// tagged with the ID of the key that encrypted them, so old keys can stay configured for
+// decryption while new writes use the active key, and stored values can be re-encrypted later.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnknownKey is returned when a value was encrypted with a key that is not configured
+	ErrUnknownKey = errors.New("value encrypted with a key that is not configured")
+	// ErrMalformed is returned for a value that looks encrypted but cannot be parsed
+	ErrMalformed = errors.New("malformed encrypted value")
+)
+
+// prefix marks encrypted values: "enc:v1:<key id>:<base64 nonce+ciphertext>"
+const prefix = "enc:v1:"
+
+// KeySize is the length of an AES-256 key in bytes
+const KeySize = 32
+
+// Cipher encrypts with the active key and decrypts with any configured key. A nil *Cipher is
+// valid and leaves values in plaintext, for deployments that have not configured keys yet.
+type Cipher struct {
+	activeID string
+	keys     map[string]cipher.AEAD
+}
+
+// New creates a Cipher from raw 32-byte keys by ID, encrypting with activeID
+func New(keys map[string][]byte, activeID string) (*Cipher, error) {
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("active key %q is not among the configured keys", activeID)
+	}
+	c := &Cipher{activeID: activeID, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key %q must be %d bytes, got %d", id, KeySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		c.keys[id] = aead
+	}
+	return c, nil
+}
+
+// ParseKeys parses "<id>:<base64 key>" pairs separated by commas, e.g. "2026b:...,2026a:..."
+func ParseKeys(spec string) (map[string][]byte, error) {
+	keys := map[string][]byte{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("key %q is not in <id>:<base64 key> form", pair)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64: %w", id, err)
+		}
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("key %q is configured twice", id)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// ActiveKeyID returns the ID of the key new values are encrypted with
+func (c *Cipher) ActiveKeyID() string {
+	if c == nil {
+		return ""
+	}
+	return c.activeID
+}
+
+// Encrypt encrypts a value with the active key. Empty values and values that are already
+// encrypted are returned unchanged; a nil Cipher returns every value unchanged.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if c == nil || plaintext == "" || IsEncrypted(plaintext) {
+		return plaintext, nil
+	}
+	aead := c.keys[c.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(c.activeID))
+	return prefix + c.activeID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of an encrypted value. Values without the encryption prefix
+// were written before encryption was enabled and are returned unchanged.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, sealed, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	if c == nil {
+		return "", ErrUnknownKey
+	}
+	aead, ok := c.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value with key %q: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored value should be rewritten: it is still plaintext, or
+// was encrypted with a key other than the active one. Always false for a nil Cipher.
+func (c *Cipher) NeedsRotation(value string) bool {
+	if c == nil || value == "" {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	id, _, err := parse(value)
+	return err == nil && id != c.activeID
+}
+
+// IsEncrypted reports whether a stored value carries the encryption prefix
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func parse(value string) (string, []byte, error) {
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok || id == "" {
+		return "", nil, ErrMalformed
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, ErrMalformed
+	}
+	return id, sealed, nil
+}
@@ -0,0 +1,134 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c, err := New(map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+
+	stored, err := c.Encrypt("199012345678")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(stored))
+	assert.NotContains(t, stored, "199012345678")
+
+	again, err := c.Encrypt("199012345678")
+	require.NoError(t, err)
+	assert.NotEqual(t, stored, again, "random nonce per value")
+
+	plain, err := c.Decrypt(stored)
+	require.NoError(t, err)
+	assert.Equal(t, "199012345678", plain)
+
+	reencrypted, err := c.Encrypt(stored)
+	require.NoError(t, err)
+	assert.Equal(t, stored, reencrypted, "already encrypted values are not encrypted twice")
+}
+
+func TestCipher_PlaintextPassthrough(t *testing.T) {
+	c, err := New(map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+
+	plain, err := c.Decrypt("12 Galle Road")
+	require.NoError(t, err)
+	assert.Equal(t, "12 Galle Road", plain, "values written before encryption are read as is")
+
+	empty, err := c.Encrypt("")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	var disabled *Cipher
+	stored, err := disabled.Encrypt("12 Galle Road")
+	require.NoError(t, err)
+	assert.Equal(t, "12 Galle Road", stored)
+	assert.False(t, disabled.NeedsRotation("12 Galle Road"))
+
+	encrypted, _ := c.Encrypt("12 Galle Road")
+	_, err = disabled.Decrypt(encrypted)
+	assert.True(t, errors.Is(err, ErrUnknownKey), "encrypted data cannot be read without keys")
+}
+
+func TestCipher_KeyRotation(t *testing.T) {
+	old, err := New(map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+	stored, err := old.Encrypt("B1234567")
+	require.NoError(t, err)
+
+	rotated, err := New(map[string][]byte{"k1": testKey(1), "k2": testKey(2)}, "k2")
+	require.NoError(t, err)
+	assert.True(t, rotated.NeedsRotation(stored), "encrypted with a retired key")
+	assert.True(t, rotated.NeedsRotation("B1234567"), "plaintext")
+
+	plain, err := rotated.Decrypt(stored)
+	require.NoError(t, err)
+	assert.Equal(t, "B1234567", plain, "old keys still decrypt")
+
+	fresh, err := rotated.Encrypt(plain)
+	require.NoError(t, err)
+	assert.False(t, rotated.NeedsRotation(fresh))
+
+	newOnly, err := New(map[string][]byte{"k2": testKey(2)}, "k2")
+	require.NoError(t, err)
+	_, err = newOnly.Decrypt(stored)
+	assert.True(t, errors.Is(err, ErrUnknownKey))
+}
+
+func TestCipher_TamperedValue(t *testing.T) {
+	c, err := New(map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+	stored, err := c.Encrypt("199012345678")
+	require.NoError(t, err)
+
+	tampered := stored[:len(stored)-2] + "AA"
+	if tampered == stored {
+		tampered = stored[:len(stored)-2] + "BB"
+	}
+	_, err = c.Decrypt(tampered)
+	assert.Error(t, err)
+
+	_, err = c.Decrypt("enc:v1:k1")
+	assert.True(t, errors.Is(err, ErrMalformed))
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(map[string][]byte{"k1": testKey(1)}, "k2")
+	assert.Error(t, err, "active key missing")
+
+	_, err = New(map[string][]byte{"k1": []byte("short")}, "k1")
+	assert.Error(t, err, "wrong key length")
+
+	_, err = New(map[string][]byte{"k:1": testKey(1)}, "k:1")
+	assert.Error(t, err, "key id may not contain the separator")
+}
+
+func TestParseKeys(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(1))
+	k2 := base64.StdEncoding.EncodeToString(testKey(2))
+
+	keys, err := ParseKeys("2026b:" + k2 + ", 2026a:" + k1)
+	require.NoError(t, err)
+	assert.Equal(t, testKey(2), keys["2026b"])
+	assert.Equal(t, testKey(1), keys["2026a"])
+
+	keys, err = ParseKeys("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = ParseKeys("nokey")
+	assert.Error(t, err)
+	_, err = ParseKeys("k1:not-base64!")
+	assert.Error(t, err)
+	_, err = ParseKeys("k1:" + k1 + ",k1:" + k2)
+	assert.Error(t, err)
+}
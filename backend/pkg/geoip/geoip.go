@@ -0,0 +1,130 @@
+// Package geoip resolves client IP addresses to a country, city and network (ASN)
+// using local MaxMind GeoLite2/GeoIP2 database files. No network calls are made.
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Location is what the databases know about an IP address
+type Location struct {
+	CountryCode string `json:"country_code,omitempty"` // ISO 3166-1 alpha-2, e.g. "LK"
+	Country     string `json:"country,omitempty"`
+	City        string `json:"city,omitempty"`
+	ASN         uint   `json:"asn,omitempty"`
+	ASOrg       string `json:"as_org,omitempty"` // Network operator, e.g. "Dialog Axiata PLC."
+}
+
+// String returns a readable location, e.g. "Kandy, Sri Lanka (AS18001 Dialog Axiata PLC.)"
+func (l *Location) String() string {
+	if l == nil {
+		return ""
+	}
+	parts := make([]string, 0, 2)
+	if l.City != "" {
+		parts = append(parts, l.City)
+	}
+	if l.Country != "" {
+		parts = append(parts, l.Country)
+	} else if l.CountryCode != "" {
+		parts = append(parts, l.CountryCode)
+	}
+	place := strings.Join(parts, ", ")
+
+	if l.ASN == 0 {
+		return place
+	}
+	network := fmt.Sprintf("AS%d", l.ASN)
+	if l.ASOrg != "" {
+		network += " " + l.ASOrg
+	}
+	if place == "" {
+		return network
+	}
+	return place + " (" + network + ")"
+}
+
+// Locator looks up IP addresses in a City database and an ASN database. Either may be
+// missing; a nil or empty Locator returns no locations, so callers need not check.
+type Locator struct {
+	city *Reader
+	asn  *Reader
+}
+
+// NewLocator opens the configured database files; empty paths are skipped
+func NewLocator(cityDBPath, asnDBPath string) (*Locator, error) {
+	locator := &Locator{}
+	if cityDBPath != "" {
+		reader, err := Open(cityDBPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open GeoIP city database: %w", err)
+		}
+		locator.city = reader
+	}
+	if asnDBPath != "" {
+		reader, err := Open(asnDBPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open GeoIP ASN database: %w", err)
+		}
+		locator.asn = reader
+	}
+	return locator, nil
+}
+
+// NewLocatorFromReaders builds a Locator from already opened databases (either may be nil)
+func NewLocatorFromReaders(city, asn *Reader) *Locator {
+	return &Locator{city: city, asn: asn}
+}
+
+// Enabled reports whether at least one database is loaded
+func (l *Locator) Enabled() bool {
+	return l != nil && (l.city != nil || l.asn != nil)
+}
+
+// Lookup returns the location of a public IP address, or nil when it is private,
+// unparsable or unknown to the databases
+func (l *Locator) Lookup(ipAddress string) *Location {
+	if !l.Enabled() {
+		return nil
+	}
+	ip := net.ParseIP(strings.TrimSpace(ipAddress))
+	if ip == nil || ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() {
+		return nil
+	}
+
+	location := &Location{}
+	if l.city != nil {
+		if record, err := l.city.Lookup(ip); err == nil && record != nil {
+			location.CountryCode = stringAt(record, "country", "iso_code")
+			location.Country = stringAt(record, "country", "names", "en")
+			location.City = stringAt(record, "city", "names", "en")
+		}
+	}
+	if l.asn != nil {
+		if record, err := l.asn.Lookup(ip); err == nil && record != nil {
+			location.ASN = uint(asUint(record["autonomous_system_number"]))
+			location.ASOrg, _ = record["autonomous_system_organization"].(string)
+		}
+	}
+
+	if *location == (Location{}) {
+		return nil
+	}
+	return location
+}
+
+// stringAt walks nested maps and returns the string at the end of the path
+func stringAt(record map[string]interface{}, path ...string) string {
+	var current interface{} = record
+	for _, key := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = m[key]
+	}
+	s, _ := current.(string)
+	return s
+}
@@ -0,0 +1,174 @@
+package geoip
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Minimal MaxMind DB writer for tests (IPv4 tree, 24-bit records)
+// ============================================================================
+
+func encString(s string) []byte {
+	if len(s) >= 29 { // One extra size byte
+		return append([]byte{byte(typeString<<5 | 29), byte(len(s) - 29)}, s...)
+	}
+	return append([]byte{byte(typeString<<5 | len(s))}, s...)
+}
+
+func encUint(fieldType int, v uint64) []byte {
+	var raw []byte
+	for ; v > 0; v >>= 8 {
+		raw = append([]byte{byte(v)}, raw...)
+	}
+	if fieldType >= 8 { // Extended type
+		return append([]byte{byte(len(raw)), byte(fieldType - 7)}, raw...)
+	}
+	return append([]byte{byte(fieldType<<5 | len(raw))}, raw...)
+}
+
+// encMap encodes alternating pre-encoded keys and values
+func encMap(kv ...[]byte) []byte {
+	out := []byte{byte(typeMap<<5 | len(kv)/2)}
+	for _, part := range kv {
+		out = append(out, part...)
+	}
+	return out
+}
+
+func encPointer(offset int) []byte {
+	return []byte{byte(typePointer<<5 | (offset>>8)&0x7), byte(offset)}
+}
+
+type testNetwork struct {
+	cidr       string
+	dataOffset int
+}
+
+func buildDB(t *testing.T, dbType string, data []byte, networks ...testNetwork) []byte {
+	type node struct{ records [2]int } // >= 0 node index, -1 empty, <= -2 data offset (-2 - offset)
+	nodes := []node{{records: [2]int{-1, -1}}}
+
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.cidr)
+		require.NoError(t, err)
+		ones, _ := ipNet.Mask.Size()
+		ip := ipNet.IP.To4()
+
+		current := 0
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if i == ones-1 {
+				nodes[current].records[bit] = -2 - network.dataOffset
+				break
+			}
+			if nodes[current].records[bit] < 0 {
+				nodes = append(nodes, node{records: [2]int{-1, -1}})
+				nodes[current].records[bit] = len(nodes) - 1
+			}
+			current = nodes[current].records[bit]
+		}
+	}
+
+	nodeCount := len(nodes)
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		for _, record := range n.records {
+			value := record
+			switch {
+			case record == -1:
+				value = nodeCount
+			case record <= -2:
+				value = nodeCount + 16 + (-2 - record)
+			}
+			buf.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+	buf.Write(make([]byte, 16))
+	buf.Write(data)
+	buf.Write(metadataMarker)
+	buf.Write(encMap(
+		encString("node_count"), encUint(typeUint32, uint64(nodeCount)),
+		encString("record_size"), encUint(typeUint16, 24),
+		encString("ip_version"), encUint(typeUint16, 4),
+		encString("database_type"), encString(dbType),
+	))
+	return buf.Bytes()
+}
+
+func testLocator(t *testing.T) *Locator {
+	// "Colombo" is stored once and referenced through a pointer, as real databases do
+	cityData := encString("Colombo")
+	recordOffset := len(cityData)
+	cityData = append(cityData, encMap(
+		encString("country"), encMap(
+			encString("iso_code"), encString("LK"),
+			encString("names"), encMap(encString("en"), encString("Sri Lanka")),
+		),
+		encString("city"), encMap(
+			encString("names"), encMap(encString("en"), encPointer(0)),
+		),
+	)...)
+
+	asnData := encMap(
+		encString("autonomous_system_number"), encUint(typeUint64, 18001),
+		encString("autonomous_system_organization"), encString("Dialog Axiata PLC."),
+	)
+
+	city, err := FromBytes(buildDB(t, "GeoLite2-City", cityData, testNetwork{"203.0.113.0/24", recordOffset}))
+	require.NoError(t, err)
+	assert.Equal(t, "GeoLite2-City", city.DatabaseType)
+
+	asn, err := FromBytes(buildDB(t, "GeoLite2-ASN", asnData, testNetwork{"203.0.112.0/23", 0}))
+	require.NoError(t, err)
+
+	return NewLocatorFromReaders(city, asn)
+}
+
+func TestLocator_Lookup(t *testing.T) {
+	locator := testLocator(t)
+
+	location := locator.Lookup("203.0.113.7")
+	require.NotNil(t, location)
+	assert.Equal(t, "LK", location.CountryCode)
+	assert.Equal(t, "Sri Lanka", location.Country)
+	assert.Equal(t, "Colombo", location.City)
+	assert.Equal(t, uint(18001), location.ASN)
+	assert.Equal(t, "Dialog Axiata PLC.", location.ASOrg)
+	assert.Equal(t, "Colombo, Sri Lanka (AS18001 Dialog Axiata PLC.)", location.String())
+
+	// Only in the ASN database
+	location = locator.Lookup("203.0.112.20")
+	require.NotNil(t, location)
+	assert.Empty(t, location.CountryCode)
+	assert.Equal(t, "AS18001 Dialog Axiata PLC.", location.String())
+}
+
+func TestLocator_Lookup_NoLocation(t *testing.T) {
+	locator := testLocator(t)
+
+	assert.Nil(t, locator.Lookup("198.51.100.1"), "not in either database")
+	assert.Nil(t, locator.Lookup("192.168.1.10"), "private")
+	assert.Nil(t, locator.Lookup("127.0.0.1"), "loopback")
+	assert.Nil(t, locator.Lookup("not-an-ip"))
+	assert.Nil(t, locator.Lookup("2001:db8::1"), "IPv6 in an IPv4-only database")
+
+	var unconfigured *Locator
+	assert.False(t, unconfigured.Enabled())
+	assert.Nil(t, unconfigured.Lookup("203.0.113.7"))
+}
+
+func TestFromBytes_Invalid(t *testing.T) {
+	_, err := FromBytes([]byte("definitely not a database"))
+	assert.ErrorIs(t, err, ErrInvalidDatabase)
+}
+
+func TestLocation_String(t *testing.T) {
+	assert.Equal(t, "Sri Lanka", (&Location{CountryCode: "LK", Country: "Sri Lanka"}).String())
+	assert.Equal(t, "LK", (&Location{CountryCode: "LK"}).String())
+	assert.Equal(t, "", (*Location)(nil).String())
+}
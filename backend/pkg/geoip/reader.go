@@ -0,0 +1,348 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"os"
+)
+
+// ErrInvalidDatabase is returned when a file is not a readable MaxMind DB
+var ErrInvalidDatabase = errors.New("invalid MaxMind DB file")
+
+// metadataMarker precedes the metadata map at the end of every MaxMind DB file
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// Data section field types (MaxMind DB format spec 2.0)
+const (
+	typeExtended = 0
+	typePointer  = 1
+	typeString   = 2
+	typeDouble   = 3
+	typeBytes    = 4
+	typeUint16   = 5
+	typeUint32   = 6
+	typeMap      = 7
+	typeInt32    = 8
+	typeUint64   = 9
+	typeUint128  = 10
+	typeArray    = 11
+	typeBool     = 14
+	typeFloat    = 15
+)
+
+// Reader reads a MaxMind DB (.mmdb) file such as GeoLite2-City or GeoLite2-ASN.
+// The whole file is held in memory; lookups are safe for concurrent use.
+type Reader struct {
+	DatabaseType string
+
+	buf        []byte
+	data       []byte // Data section
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Start  uint // Node IPv4 lookups start from in IPv6 trees (::/96)
+}
+
+// Open loads a MaxMind DB file
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MaxMind DB: %w", err)
+	}
+	return FromBytes(buf)
+}
+
+// FromBytes reads a MaxMind DB already in memory
+func FromBytes(buf []byte) (*Reader, error) {
+	markerAt := bytes.LastIndex(buf, metadataMarker)
+	if markerAt == -1 {
+		return nil, ErrInvalidDatabase
+	}
+
+	meta, _, err := (&decoder{buf: buf[markerAt+len(metadataMarker):]}).decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: metadata: %v", ErrInvalidDatabase, err)
+	}
+	metadata, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidDatabase
+	}
+
+	r := &Reader{
+		buf:        buf,
+		nodeCount:  uint(asUint(metadata["node_count"])),
+		recordSize: uint(asUint(metadata["record_size"])),
+		ipVersion:  uint(asUint(metadata["ip_version"])),
+	}
+	r.DatabaseType, _ = metadata["database_type"].(string)
+
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("%w: unsupported record size %d", ErrInvalidDatabase, r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("%w: unsupported IP version %d", ErrInvalidDatabase, r.ipVersion)
+	}
+
+	// The search tree is followed by 16 zero bytes, then the data section
+	treeSize := r.nodeCount * r.recordSize / 4
+	dataStart := treeSize + 16
+	if dataStart > uint(markerAt) {
+		return nil, fmt.Errorf("%w: search tree exceeds file", ErrInvalidDatabase)
+	}
+	r.data = buf[dataStart:markerAt]
+
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			node = r.readNode(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+// Lookup returns the record for the network containing ip, or nil if the database has none
+func (r *Reader) Lookup(ip net.IP) (map[string]interface{}, error) {
+	node := uint(0)
+	address := ip.To4()
+	if address != nil {
+		node = r.ipv4Start
+	} else {
+		if r.ipVersion == 4 {
+			return nil, nil
+		}
+		address = ip.To16()
+		if address == nil {
+			return nil, fmt.Errorf("invalid IP address")
+		}
+	}
+
+	for i := 0; i < len(address)*8 && node < r.nodeCount; i++ {
+		bit := (address[i>>3] >> (7 - uint(i&7))) & 1
+		node = r.readNode(node, uint(bit))
+	}
+	if node <= r.nodeCount {
+		return nil, nil
+	}
+
+	offset := node - r.nodeCount - 16
+	value, _, err := (&decoder{buf: r.data}).decode(offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode MaxMind record: %w", err)
+	}
+	record, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// readNode returns the left (bit 0) or right (bit 1) record of a search tree node
+func (r *Reader) readNode(node, bit uint) uint {
+	switch r.recordSize {
+	case 24:
+		b := r.buf[node*6 : node*6+6]
+		if bit == 0 {
+			return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3])<<16 | uint(b[4])<<8 | uint(b[5])
+	case 28:
+		b := r.buf[node*7 : node*7+7]
+		if bit == 0 {
+			return (uint(b[3])&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return (uint(b[3])&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		b := r.buf[node*8 : node*8+8]
+		if bit == 0 {
+			return uint(binary.BigEndian.Uint32(b[0:4]))
+		}
+		return uint(binary.BigEndian.Uint32(b[4:8]))
+	}
+}
+
+// decoder decodes values from a data section (or the metadata, which uses the same encoding)
+type decoder struct {
+	buf []byte
+}
+
+// decode returns the value at offset and the offset just after it
+func (d *decoder) decode(offset uint) (interface{}, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return nil, 0, errors.New("offset out of range")
+	}
+	ctrl := d.buf[offset]
+	offset++
+
+	fieldType := uint(ctrl >> 5)
+	if fieldType == typePointer {
+		target, next, err := d.pointer(ctrl, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(target)
+		return value, next, err
+	}
+	if fieldType == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return nil, 0, errors.New("truncated extended type")
+		}
+		fieldType = 7 + uint(d.buf[offset])
+		offset++
+	}
+
+	size, offset, err := d.size(ctrl, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return d.value(fieldType, size, offset)
+}
+
+func (d *decoder) size(ctrl byte, offset uint) (uint, uint, error) {
+	size := uint(ctrl & 0x1f)
+	if size < 29 {
+		return size, offset, nil
+	}
+	n := size - 28
+	raw, err := d.bytes(offset, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	extra := uint(uintFromBytes(raw))
+	switch size {
+	case 29:
+		size = 29 + extra
+	case 30:
+		size = 285 + extra
+	default:
+		size = 65821 + extra
+	}
+	return size, offset + n, nil
+}
+
+func (d *decoder) pointer(ctrl byte, offset uint) (uint, uint, error) {
+	n := uint((ctrl>>3)&0x3) + 1
+	raw, err := d.bytes(offset, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	value := uintFromBytes(raw)
+	switch n {
+	case 1:
+		value |= uint64(ctrl&0x7) << 8
+	case 2:
+		value = (value | uint64(ctrl&0x7)<<16) + 2048
+	case 3:
+		value = (value | uint64(ctrl&0x7)<<24) + 526336
+	}
+	return uint(value), offset + n, nil
+}
+
+func (d *decoder) value(fieldType, size, offset uint) (interface{}, uint, error) {
+	switch fieldType {
+	case typeMap:
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			value, after, err := d.decode(next)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[keyString] = value
+			offset = after
+		}
+		return m, offset, nil
+	case typeArray:
+		items := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, value)
+			offset = next
+		}
+		return items, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	raw, err := d.bytes(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	next := offset + size
+
+	switch fieldType {
+	case typeString:
+		return string(raw), next, nil
+	case typeBytes:
+		return append([]byte(nil), raw...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("invalid double size")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("invalid float size")
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(raw))), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, errors.New("invalid unsigned integer size")
+		}
+		return uintFromBytes(raw), next, nil
+	case typeUint128:
+		if size <= 8 {
+			return uintFromBytes(raw), next, nil
+		}
+		return new(big.Int).SetBytes(raw), next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, errors.New("invalid int32 size")
+		}
+		return int64(int32(uint32(uintFromBytes(raw)))), next, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported data type %d", fieldType)
+	}
+}
+
+func (d *decoder) bytes(offset, n uint) ([]byte, error) {
+	if offset+n > uint(len(d.buf)) {
+		return nil, errors.New("value exceeds data section")
+	}
+	return d.buf[offset : offset+n], nil
+}
+
+func uintFromBytes(raw []byte) uint64 {
+	var value uint64
+	for _, b := range raw {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func asUint(value interface{}) uint64 {
+	switch v := value.(type) {
+	case uint64:
+		return v
+	case int64:
+		if v > 0 {
+			return uint64(v)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,266 @@
+// Package gtfsrt encodes GTFS Realtime feeds (gtfs-realtime.proto, version 2.0) in the protobuf
+// wire format. Only the vehicle position and trip update messages the platform publishes are
+// covered; field numbers follow the specification.
+package gtfsrt
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Version is the GTFS Realtime version of the feeds
+const Version = "2.0"
+
+// TripScheduleRelationship says how a trip relates to the static schedule
+type TripScheduleRelationship int32
+
+const (
+	TripScheduled TripScheduleRelationship = 0
+	TripCanceled  TripScheduleRelationship = 3
+)
+
+// VehicleStopStatus says where a vehicle is relative to the stop it reports
+type VehicleStopStatus int32
+
+const (
+	VehicleIncomingAt  VehicleStopStatus = 0
+	VehicleStoppedAt   VehicleStopStatus = 1
+	VehicleInTransitTo VehicleStopStatus = 2
+)
+
+// FeedMessage is a whole feed, always a full dataset
+type FeedMessage struct {
+	Timestamp uint64 // POSIX seconds the feed was generated
+	Entities  []FeedEntity
+}
+
+// FeedEntity is one vehicle position or trip update
+type FeedEntity struct {
+	ID         string
+	TripUpdate *TripUpdate
+	Vehicle    *VehiclePosition
+}
+
+// TripDescriptor identifies a trip of the static feed
+type TripDescriptor struct {
+	TripID               string
+	RouteID              string
+	DirectionID          *uint32
+	StartTime            string // HH:MM:SS
+	StartDate            string // YYYYMMDD
+	ScheduleRelationship TripScheduleRelationship
+}
+
+// VehicleDescriptor identifies the vehicle running a trip
+type VehicleDescriptor struct {
+	ID           string
+	Label        string
+	LicensePlate string
+}
+
+// Position is a vehicle's location
+type Position struct {
+	Latitude  float32
+	Longitude float32
+	Bearing   *float32 // Degrees clockwise from north
+	Speed     *float32 // Meters per second
+}
+
+// VehiclePosition is where a vehicle running a trip is
+type VehiclePosition struct {
+	Trip                *TripDescriptor
+	Vehicle             *VehicleDescriptor
+	Position            *Position
+	CurrentStopSequence *uint32
+	StopID              string
+	CurrentStatus       *VehicleStopStatus
+	Timestamp           uint64 // POSIX seconds the position was measured
+}
+
+// StopTimeEvent is a predicted arrival or departure
+type StopTimeEvent struct {
+	Delay *int32 // Seconds late (negative when early)
+	Time  *int64 // POSIX seconds
+}
+
+// StopTimeUpdate is the prediction for one stop of a trip; it also applies to later stops
+// without their own update
+type StopTimeUpdate struct {
+	StopSequence *uint32
+	StopID       string
+	Arrival      *StopTimeEvent
+	Departure    *StopTimeEvent
+}
+
+// TripUpdate is the predicted progress of a trip, or its cancellation
+type TripUpdate struct {
+	Trip            TripDescriptor
+	Vehicle         *VehicleDescriptor
+	StopTimeUpdates []StopTimeUpdate
+	Timestamp       uint64
+	Delay           *int32
+}
+
+// Marshal encodes the feed
+func (m *FeedMessage) Marshal() []byte {
+	header := appendString(nil, 1, Version)
+	header = appendVarint(header, 2, 0) // FULL_DATASET
+	header = appendVarint(header, 3, m.Timestamp)
+
+	b := appendMessage(nil, 1, header)
+	for i := range m.Entities {
+		b = appendMessage(b, 2, m.Entities[i].marshal())
+	}
+	return b
+}
+
+func (e *FeedEntity) marshal() []byte {
+	b := appendString(nil, 1, e.ID)
+	if e.TripUpdate != nil {
+		b = appendMessage(b, 3, e.TripUpdate.marshal())
+	}
+	if e.Vehicle != nil {
+		b = appendMessage(b, 4, e.Vehicle.marshal())
+	}
+	return b
+}
+
+func (t *TripDescriptor) marshal() []byte {
+	var b []byte
+	b = appendOptionalString(b, 1, t.TripID)
+	b = appendOptionalString(b, 2, t.StartTime)
+	b = appendOptionalString(b, 3, t.StartDate)
+	if t.ScheduleRelationship != TripScheduled {
+		b = appendInt32(b, 4, int32(t.ScheduleRelationship))
+	}
+	b = appendOptionalString(b, 5, t.RouteID)
+	if t.DirectionID != nil {
+		b = appendVarint(b, 6, uint64(*t.DirectionID))
+	}
+	return b
+}
+
+func (v *VehicleDescriptor) marshal() []byte {
+	var b []byte
+	b = appendOptionalString(b, 1, v.ID)
+	b = appendOptionalString(b, 2, v.Label)
+	b = appendOptionalString(b, 3, v.LicensePlate)
+	return b
+}
+
+func (p *Position) marshal() []byte {
+	b := appendFloat(nil, 1, p.Latitude)
+	b = appendFloat(b, 2, p.Longitude)
+	if p.Bearing != nil {
+		b = appendFloat(b, 3, *p.Bearing)
+	}
+	if p.Speed != nil {
+		b = appendFloat(b, 5, *p.Speed)
+	}
+	return b
+}
+
+func (v *VehiclePosition) marshal() []byte {
+	var b []byte
+	if v.Trip != nil {
+		b = appendMessage(b, 1, v.Trip.marshal())
+	}
+	if v.Position != nil {
+		b = appendMessage(b, 2, v.Position.marshal())
+	}
+	if v.CurrentStopSequence != nil {
+		b = appendVarint(b, 3, uint64(*v.CurrentStopSequence))
+	}
+	if v.CurrentStatus != nil {
+		b = appendInt32(b, 4, int32(*v.CurrentStatus))
+	}
+	if v.Timestamp != 0 {
+		b = appendVarint(b, 5, v.Timestamp)
+	}
+	b = appendOptionalString(b, 7, v.StopID)
+	if v.Vehicle != nil {
+		b = appendMessage(b, 8, v.Vehicle.marshal())
+	}
+	return b
+}
+
+func (e *StopTimeEvent) marshal() []byte {
+	var b []byte
+	if e.Delay != nil {
+		b = appendInt32(b, 1, *e.Delay)
+	}
+	if e.Time != nil {
+		b = appendInt64(b, 2, *e.Time)
+	}
+	return b
+}
+
+func (u *StopTimeUpdate) marshal() []byte {
+	var b []byte
+	if u.StopSequence != nil {
+		b = appendVarint(b, 1, uint64(*u.StopSequence))
+	}
+	if u.Arrival != nil {
+		b = appendMessage(b, 2, u.Arrival.marshal())
+	}
+	if u.Departure != nil {
+		b = appendMessage(b, 3, u.Departure.marshal())
+	}
+	b = appendOptionalString(b, 4, u.StopID)
+	return b
+}
+
+func (t *TripUpdate) marshal() []byte {
+	b := appendMessage(nil, 1, t.Trip.marshal())
+	for i := range t.StopTimeUpdates {
+		b = appendMessage(b, 2, t.StopTimeUpdates[i].marshal())
+	}
+	if t.Vehicle != nil {
+		b = appendMessage(b, 3, t.Vehicle.marshal())
+	}
+	if t.Timestamp != 0 {
+		b = appendVarint(b, 4, t.Timestamp)
+	}
+	if t.Delay != nil {
+		b = appendInt32(b, 5, *t.Delay)
+	}
+	return b
+}
+
+func appendVarint(b []byte, field protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, field, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// appendInt32 encodes an int32 or enum; negative values are sign-extended to 64 bits as
+// protobuf requires
+func appendInt32(b []byte, field protowire.Number, v int32) []byte {
+	return appendVarint(b, field, uint64(int64(v)))
+}
+
+func appendInt64(b []byte, field protowire.Number, v int64) []byte {
+	return appendVarint(b, field, uint64(v))
+}
+
+func appendFloat(b []byte, field protowire.Number, v float32) []byte {
+	b = protowire.AppendTag(b, field, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, math.Float32bits(v))
+}
+
+func appendString(b []byte, field protowire.Number, v string) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendOptionalString(b []byte, field protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	return appendString(b, field, v)
+}
+
+func appendMessage(b []byte, field protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
@@ -0,0 +1,95 @@
+package gtfsrt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// fields decodes one message level into its fields, keeping repeated ones in order
+func fields(t *testing.T, b []byte) map[protowire.Number][]any {
+	t.Helper()
+	out := map[protowire.Number][]any{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			require.GreaterOrEqual(t, n, 0)
+			out[num] = append(out[num], v)
+			b = b[n:]
+		case protowire.Fixed32Type:
+			v, n := protowire.ConsumeFixed32(b)
+			require.GreaterOrEqual(t, n, 0)
+			out[num] = append(out[num], math.Float32frombits(v))
+			b = b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			require.GreaterOrEqual(t, n, 0)
+			out[num] = append(out[num], v)
+			b = b[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+	}
+	return out
+}
+
+func TestFeedMessage_Marshal(t *testing.T) {
+	delay := int32(-90)
+	sequence := uint32(4)
+	direction := uint32(1)
+	status := VehicleStoppedAt
+	speed := float32(12.5)
+	feed := &FeedMessage{
+		Timestamp: 1767225600,
+		Entities: []FeedEntity{
+			{ID: "vehicle-t1", Vehicle: &VehiclePosition{
+				Trip:                &TripDescriptor{TripID: "t1", RouteID: "r1", DirectionID: &direction, StartDate: "20260101"},
+				Vehicle:             &VehicleDescriptor{ID: "bus-1", LicensePlate: "NB-1234"},
+				Position:            &Position{Latitude: 6.9271, Longitude: 79.8612, Speed: &speed},
+				CurrentStopSequence: &sequence,
+				CurrentStatus:       &status,
+				Timestamp:           1767225590,
+			}},
+			{ID: "trip-t2", TripUpdate: &TripUpdate{
+				Trip:            TripDescriptor{TripID: "t2", ScheduleRelationship: TripCanceled},
+				StopTimeUpdates: []StopTimeUpdate{{StopSequence: &sequence, StopID: "s4", Arrival: &StopTimeEvent{Delay: &delay}}},
+			}},
+		},
+	}
+
+	message := fields(t, feed.Marshal())
+	header := fields(t, message[1][0].([]byte))
+	assert.Equal(t, []byte("2.0"), header[1][0])
+	assert.Equal(t, uint64(0), header[2][0], "always a full dataset")
+	assert.Equal(t, uint64(1767225600), header[3][0])
+	require.Len(t, message[2], 2)
+
+	vehicleEntity := fields(t, message[2][0].([]byte))
+	assert.Equal(t, []byte("vehicle-t1"), vehicleEntity[1][0])
+	vehicle := fields(t, vehicleEntity[4][0].([]byte))
+	trip := fields(t, vehicle[1][0].([]byte))
+	assert.Equal(t, []byte("t1"), trip[1][0])
+	assert.Equal(t, []byte("r1"), trip[5][0])
+	assert.Equal(t, uint64(1), trip[6][0])
+	position := fields(t, vehicle[2][0].([]byte))
+	assert.InDelta(t, 6.9271, position[1][0], 1e-5)
+	assert.Equal(t, float32(12.5), position[5][0])
+	assert.Equal(t, uint64(4), vehicle[3][0])
+	assert.Equal(t, uint64(1), vehicle[4][0])
+	assert.Equal(t, []byte("NB-1234"), fields(t, vehicle[8][0].([]byte))[3][0])
+
+	updateEntity := fields(t, message[2][1].([]byte))
+	update := fields(t, updateEntity[3][0].([]byte))
+	assert.Equal(t, uint64(3), fields(t, update[1][0].([]byte))[4][0], "canceled")
+	stopUpdate := fields(t, update[2][0].([]byte))
+	assert.Equal(t, []byte("s4"), stopUpdate[4][0])
+	arrival := fields(t, stopUpdate[2][0].([]byte))
+	assert.Equal(t, int64(-90), int64(arrival[1][0].(uint64)), "negative delays are sign-extended")
+}
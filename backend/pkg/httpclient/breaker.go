@@ -0,0 +1,123 @@
+package httpclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the upstream while the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open: upstream temporarily unavailable")
+
+// State is the state of a circuit breaker
+type State string
+
+const (
+	StateClosed   State = "closed"    // Requests flow normally
+	StateOpen     State = "open"      // Requests are rejected until the open duration elapses
+	StateHalfOpen State = "half_open" // A single trial request is allowed through
+)
+
+// CircuitBreaker trips after a run of consecutive failures and rejects calls for a cool-down period
+type CircuitBreaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+	onStateChange    func(from, to State)
+	now              func() time.Time
+
+	mu                  sync.Mutex
+	state               State
+	consecutiveFailures int
+	openedAt            time.Time
+	trialInFlight       bool
+}
+
+// NewCircuitBreaker creates a breaker. A threshold of 0 or less disables tripping.
+func NewCircuitBreaker(failureThreshold int, openDuration time.Duration, onStateChange func(from, to State)) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		onStateChange:    onStateChange,
+		now:              time.Now,
+		state:            StateClosed,
+	}
+}
+
+// Allow reports whether a request may be sent now
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.openDuration {
+			return ErrCircuitOpen
+		}
+		b.setState(StateHalfOpen)
+		b.trialInFlight = true
+		return nil
+	case StateHalfOpen:
+		if b.trialInFlight {
+			return ErrCircuitOpen
+		}
+		b.trialInFlight = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess closes the breaker and resets the failure count
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures = 0
+	b.trialInFlight = false
+	if b.state != StateClosed {
+		b.setState(StateClosed)
+	}
+}
+
+// RecordFailure counts a failure and opens the breaker once the threshold is reached.
+// A failed half-open trial re-opens the breaker immediately.
+func (b *CircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures++
+	b.trialInFlight = false
+
+	if b.state == StateHalfOpen || (b.failureThreshold > 0 && b.consecutiveFailures >= b.failureThreshold) {
+		b.openedAt = b.now()
+		if b.state != StateOpen {
+			b.setState(StateOpen)
+		}
+	}
+}
+
+// State returns the current state, reporting half_open once an open breaker's cool-down has elapsed
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.openDuration {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+func (b *CircuitBreaker) snapshot() (State, int, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.consecutiveFailures, b.openedAt
+}
+
+// setState must be called with mu held
+func (b *CircuitBreaker) setState(to State) {
+	from := b.state
+	b.state = to
+	if b.onStateChange != nil {
+		go b.onStateChange(from, to)
+	}
+}
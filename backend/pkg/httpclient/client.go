@@ -0,0 +1,223 @@
+// Package httpclient wraps net/http with the resilience external gateways need:
+// per-request timeouts, bounded retries with jittered backoff for idempotent calls,
+// and a circuit breaker that fails fast while an upstream is down.
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Config controls timeouts, retries and circuit breaking for one upstream
+type Config struct {
+	Name             string        // Upstream name used in stats and logs (e.g. "payable", "dialog")
+	Timeout          time.Duration // Timeout for a single attempt
+	MaxRetries       int           // Extra attempts for idempotent calls (0 = no retries)
+	RetryBaseDelay   time.Duration // Backoff base; attempt n waits a random duration up to base*2^n
+	RetryMaxDelay    time.Duration // Upper bound for a single backoff
+	FailureThreshold int           // Consecutive failures that open the breaker (0 disables it)
+	OpenDuration     time.Duration // How long the breaker stays open before a trial request
+
+	// OnStateChange is called (asynchronously) when the breaker changes state
+	OnStateChange func(name string, from, to State)
+}
+
+// DefaultConfig returns conservative defaults for a payment/SMS gateway
+func DefaultConfig(name string) Config {
+	return Config{
+		Name:             name,
+		Timeout:          10 * time.Second,
+		MaxRetries:       2,
+		RetryBaseDelay:   200 * time.Millisecond,
+		RetryMaxDelay:    2 * time.Second,
+		FailureThreshold: 5,
+		OpenDuration:     30 * time.Second,
+	}
+}
+
+// withDefaults fills zero values from DefaultConfig
+func (c Config) withDefaults() Config {
+	d := DefaultConfig(c.Name)
+	if c.Timeout <= 0 {
+		c.Timeout = d.Timeout
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.RetryBaseDelay <= 0 {
+		c.RetryBaseDelay = d.RetryBaseDelay
+	}
+	if c.RetryMaxDelay <= 0 {
+		c.RetryMaxDelay = d.RetryMaxDelay
+	}
+	if c.OpenDuration <= 0 {
+		c.OpenDuration = d.OpenDuration
+	}
+	return c
+}
+
+// Stats is a point-in-time snapshot of a client's metrics
+type Stats struct {
+	Name                string     `json:"name"`
+	State               State      `json:"state"`
+	Requests            int64      `json:"requests"`
+	Failures            int64      `json:"failures"`
+	Retries             int64      `json:"retries"`
+	ShortCircuited      int64      `json:"short_circuited"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// StatsProvider is implemented by gateways that expose their HTTP client metrics
+type StatsProvider interface {
+	HTTPStats() Stats
+}
+
+// Client is a resilient HTTP client for a single upstream
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	breaker *CircuitBreaker
+	sleep   func(time.Duration)
+
+	requests       atomic.Int64
+	failures       atomic.Int64
+	retries        atomic.Int64
+	shortCircuited atomic.Int64
+}
+
+// New creates a resilient client; zero-valued config fields fall back to DefaultConfig
+func New(cfg Config) *Client {
+	cfg = cfg.withDefaults()
+
+	var onChange func(from, to State)
+	if cfg.OnStateChange != nil {
+		name, notify := cfg.Name, cfg.OnStateChange
+		onChange = func(from, to State) { notify(name, from, to) }
+	}
+
+	return &Client{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		breaker: NewCircuitBreaker(cfg.FailureThreshold, cfg.OpenDuration, onChange),
+		sleep:   time.Sleep,
+	}
+}
+
+// Do sends a request exactly once. Use it for calls that must not be repeated
+// (sending an SMS, creating a payment).
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.attempt(req)
+}
+
+// DoIdempotent sends a request, retrying transport errors, 429 and 5xx responses
+// up to MaxRetries times with jittered exponential backoff.
+// The request body must be replayable (requests built with bytes.Buffer/Reader are).
+func (c *Client) DoIdempotent(req *http.Request) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if req.Body != nil {
+				if req.GetBody == nil {
+					return nil, fmt.Errorf("%s: cannot retry request with non-replayable body: %w", c.cfg.Name, lastErr)
+				}
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("%s: failed to rewind request body: %w", c.cfg.Name, err)
+				}
+				req.Body = body
+			}
+			c.retries.Add(1)
+			c.sleep(c.backoff(attempt))
+		}
+
+		resp, err := c.attempt(req)
+		if errors.Is(err, ErrCircuitOpen) {
+			return nil, err
+		}
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("%s returned status %d", c.cfg.Name, resp.StatusCode)
+			if attempt == c.cfg.MaxRetries {
+				return resp, nil // Hand the final response to the caller to inspect
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+	return nil, lastErr
+}
+
+// Get sends a GET request exactly once
+func (c *Client) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Available reports whether the breaker currently lets requests through
+func (c *Client) Available() bool {
+	return c.breaker.State() != StateOpen
+}
+
+// Stats returns a snapshot of the client's metrics
+func (c *Client) Stats() Stats {
+	_, consecutive, openedAt := c.breaker.snapshot()
+	stats := Stats{
+		Name:                c.cfg.Name,
+		State:               c.breaker.State(),
+		Requests:            c.requests.Load(),
+		Failures:            c.failures.Load(),
+		Retries:             c.retries.Load(),
+		ShortCircuited:      c.shortCircuited.Load(),
+		ConsecutiveFailures: consecutive,
+	}
+	if stats.State != StateClosed && !openedAt.IsZero() {
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
+
+// attempt sends one request through the circuit breaker
+func (c *Client) attempt(req *http.Request) (*http.Response, error) {
+	if err := c.breaker.Allow(); err != nil {
+		c.shortCircuited.Add(1)
+		return nil, fmt.Errorf("%s: %w", c.cfg.Name, err)
+	}
+
+	c.requests.Add(1)
+	resp, err := c.http.Do(req)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		c.failures.Add(1)
+		c.breaker.RecordFailure()
+		return resp, err
+	}
+
+	c.breaker.RecordSuccess()
+	return resp, nil
+}
+
+// backoff returns a random delay in [0, min(max, base*2^attempt)) ("full jitter")
+func (c *Client) backoff(attempt int) time.Duration {
+	ceiling := c.cfg.RetryBaseDelay << uint(attempt-1)
+	if ceiling <= 0 || ceiling > c.cfg.RetryMaxDelay {
+		ceiling = c.cfg.RetryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
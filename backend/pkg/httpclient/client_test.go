@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(cfg Config) *Client {
+	c := New(cfg)
+	c.sleep = func(time.Duration) {}
+	return c
+}
+
+func TestDoIdempotent_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"uid":"abc"}`, string(body))
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := newTestClient(Config{Name: "test", MaxRetries: 2, FailureThreshold: 10})
+	req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(`{"uid":"abc"}`))
+
+	resp, err := c.DoIdempotent(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, int64(2), c.Stats().Retries)
+	assert.Equal(t, int64(2), c.Stats().Failures)
+}
+
+func TestDoIdempotent_ReturnsFinalResponseWhenRetriesExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	c := newTestClient(Config{Name: "test", MaxRetries: 1, FailureThreshold: 10})
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	resp, err := c.DoIdempotent(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+func TestDo_DoesNotRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := newTestClient(Config{Name: "test", MaxRetries: 3, FailureThreshold: 10})
+	req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString("sms"))
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCircuitBreaker_OpensAndShortCircuits(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := newTestClient(Config{Name: "test", FailureThreshold: 2, OpenDuration: time.Hour})
+	for i := 0; i < 2; i++ {
+		resp, err := c.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.False(t, c.Available())
+	_, err := c.Get(server.URL)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(2), calls.Load())
+
+	stats := c.Stats()
+	assert.Equal(t, StateOpen, stats.State)
+	assert.Equal(t, int64(1), stats.ShortCircuited)
+	assert.NotNil(t, stats.OpenedAt)
+}
+
+func TestCircuitBreaker_HalfOpenTrial(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(1, time.Minute, nil)
+	b.now = func() time.Time { return now }
+
+	b.RecordFailure()
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// Cool-down elapsed: exactly one trial goes through
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// Failed trial re-opens the breaker
+	b.RecordFailure()
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// Successful trial closes it
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	b.RecordSuccess()
+	assert.Equal(t, StateClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
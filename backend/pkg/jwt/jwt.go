@@ -26,12 +26,19 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// ClaimsEnricher brings claims that can change during a token's lifetime, such as roles, up to
+// date with their current values
+type ClaimsEnricher interface {
+	EnrichClaims(claims *Claims) error
+}
+
 // Service handles JWT operations
 type Service struct {
 	accessSecret       string
 	refreshSecret      string
 	accessTokenExpiry  time.Duration
 	refreshTokenExpiry time.Duration
+	enricher           ClaimsEnricher
 }
 
 // NewService creates a new JWT service
@@ -44,6 +51,25 @@ func NewService(accessSecret, refreshSecret string, accessExpiry, refreshExpiry
 	}
 }
 
+// SetClaimsEnricher makes EnrichClaims refresh access token claims with e; call before serving
+func (s *Service) SetClaimsEnricher(e ClaimsEnricher) {
+	s.enricher = e
+}
+
+// EnrichClaims updates validated access token claims with their current values. Claims are left
+// as issued when no enricher is set.
+func (s *Service) EnrichClaims(claims *Claims) error {
+	if s.enricher == nil {
+		return nil
+	}
+	return s.enricher.EnrichClaims(claims)
+}
+
+// AccessTokenExpiry returns how long newly issued access tokens are valid
+func (s *Service) AccessTokenExpiry() time.Duration {
+	return s.accessTokenExpiry
+}
+
 // GenerateAccessToken generates a new access token
 func (s *Service) GenerateAccessToken(userID uuid.UUID, phone string, roles []string, profileCompleted bool) (string, error) {
 	now := time.Now()
@@ -0,0 +1,250 @@
+// Package loadshed protects an expensive code path from bursts: requests for the same key share
+// a small concurrency limit and queue briefly for it, while the whole path sheds new requests
+// once too many are queued or the path has become slow.
+package loadshed
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrOverloaded is returned while the limiter is shedding load
+	ErrOverloaded = errors.New("overloaded: try again shortly")
+	// ErrKeyBusy is returned when a key's concurrency limit stayed full for the whole wait
+	ErrKeyBusy = errors.New("too many concurrent requests for this resource")
+)
+
+// latencyWeight is the weight of the newest observation in the latency moving average
+const latencyWeight = 0.2
+
+// Config holds the limiter's thresholds
+type Config struct {
+	Name             string
+	Enabled          bool
+	MaxPerKey        int           // Requests running at once for one key
+	KeyWait          time.Duration // How long a request may queue for its key before ErrKeyBusy
+	MaxQueueDepth    int           // Queued requests across all keys at which new ones are shed (0 = no limit)
+	LatencyThreshold time.Duration // Average latency at which new requests are shed (0 = no limit)
+	LatencyWindow    time.Duration // Latency older than this no longer sheds
+	RetryAfter       time.Duration // Retry hint given to shed and rejected callers
+}
+
+// DefaultConfig returns the defaults zero-valued fields fall back to
+func DefaultConfig() Config {
+	return Config{
+		Enabled:       true,
+		MaxPerKey:     4,
+		KeyWait:       2 * time.Second,
+		LatencyWindow: 10 * time.Second,
+		RetryAfter:    5 * time.Second,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.MaxPerKey <= 0 {
+		c.MaxPerKey = d.MaxPerKey
+	}
+	if c.KeyWait <= 0 {
+		c.KeyWait = d.KeyWait
+	}
+	if c.LatencyWindow <= 0 {
+		c.LatencyWindow = d.LatencyWindow
+	}
+	if c.RetryAfter <= 0 {
+		c.RetryAfter = d.RetryAfter
+	}
+	return c
+}
+
+// Stats is a point-in-time snapshot of a limiter's metrics
+type Stats struct {
+	Name             string  `json:"name"`
+	Enabled          bool    `json:"enabled"`
+	Shedding         bool    `json:"shedding"`
+	InFlight         int     `json:"in_flight"`
+	QueueDepth       int     `json:"queue_depth"`
+	ActiveKeys       int     `json:"active_keys"`
+	AverageLatencyMs float64 `json:"average_latency_ms"`
+	Admitted         int64   `json:"admitted"`
+	Shed             int64   `json:"shed"`
+	KeyBusy          int64   `json:"key_busy"`
+}
+
+// keySlots is the semaphore for one key; users counts holders and waiters so it can be
+// dropped once nobody needs it
+type keySlots struct {
+	slots chan struct{}
+	users int
+}
+
+// Limiter is a keyed concurrency limiter with load shedding
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]*keySlots
+	inFlight  int
+	queued    int
+	latency   time.Duration // Moving average of admitted requests' latency
+	latencyAt time.Time     // When latency was last observed
+	admitted  int64
+	shed      int64
+	keyBusy   int64
+}
+
+// New creates a limiter; zero-valued config fields fall back to DefaultConfig
+func New(cfg Config) *Limiter {
+	return &Limiter{
+		cfg:  cfg.withDefaults(),
+		now:  time.Now,
+		keys: map[string]*keySlots{},
+	}
+}
+
+// RetryAfter is the retry hint for rejected callers
+func (l *Limiter) RetryAfter() time.Duration {
+	return l.cfg.RetryAfter
+}
+
+// Acquire admits a request for key, waiting up to KeyWait for one of the key's slots. An empty
+// key is only subject to load shedding. The returned release must be called once the request
+// is done; its duration feeds the latency average.
+func (l *Limiter) Acquire(ctx context.Context, key string) (func(), error) {
+	if !l.cfg.Enabled {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.overloadedLocked() {
+		l.shed++
+		l.mu.Unlock()
+		return nil, ErrOverloaded
+	}
+	var slots *keySlots
+	if key != "" {
+		slots = l.keys[key]
+		if slots == nil {
+			slots = &keySlots{slots: make(chan struct{}, l.cfg.MaxPerKey)}
+			l.keys[key] = slots
+		}
+		slots.users++
+	}
+	l.mu.Unlock()
+
+	if slots != nil {
+		if err := l.waitForSlot(ctx, key, slots); err != nil {
+			return nil, err
+		}
+	}
+
+	l.mu.Lock()
+	l.inFlight++
+	l.admitted++
+	l.mu.Unlock()
+
+	start := l.now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if slots != nil {
+				<-slots.slots
+			}
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.inFlight--
+			l.observeLocked(l.now().Sub(start))
+			if slots != nil {
+				l.dropUserLocked(key, slots)
+			}
+		})
+	}, nil
+}
+
+// waitForSlot takes one of the key's slots, queueing for up to KeyWait
+func (l *Limiter) waitForSlot(ctx context.Context, key string, slots *keySlots) error {
+	select {
+	case slots.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	l.mu.Lock()
+	l.queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.KeyWait)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case slots.slots <- struct{}{}:
+	case <-timer.C:
+		err = ErrKeyBusy
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queued--
+	if err != nil {
+		if err == ErrKeyBusy {
+			l.keyBusy++
+		}
+		l.dropUserLocked(key, slots)
+	}
+	return err
+}
+
+// overloadedLocked reports whether new requests should be shed
+func (l *Limiter) overloadedLocked() bool {
+	if l.cfg.MaxQueueDepth > 0 && l.queued >= l.cfg.MaxQueueDepth {
+		return true
+	}
+	return l.cfg.LatencyThreshold > 0 && l.latency >= l.cfg.LatencyThreshold &&
+		l.now().Sub(l.latencyAt) < l.cfg.LatencyWindow
+}
+
+func (l *Limiter) observeLocked(d time.Duration) {
+	if l.latencyAt.IsZero() || l.now().Sub(l.latencyAt) >= l.cfg.LatencyWindow {
+		l.latency = d // The previous average is too old to matter
+	} else {
+		l.latency = time.Duration(latencyWeight*float64(d) + (1-latencyWeight)*float64(l.latency))
+	}
+	l.latencyAt = l.now()
+}
+
+func (l *Limiter) dropUserLocked(key string, slots *keySlots) {
+	slots.users--
+	if slots.users == 0 && l.keys[key] == slots {
+		delete(l.keys, key)
+	}
+}
+
+// Stats returns a snapshot of the limiter's metrics
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := Stats{
+		Name:       l.cfg.Name,
+		Enabled:    l.cfg.Enabled,
+		InFlight:   l.inFlight,
+		QueueDepth: l.queued,
+		ActiveKeys: len(l.keys),
+		Admitted:   l.admitted,
+		Shed:       l.shed,
+		KeyBusy:    l.keyBusy,
+	}
+	if l.cfg.Enabled {
+		stats.Shedding = l.overloadedLocked()
+	}
+	if l.now().Sub(l.latencyAt) < l.cfg.LatencyWindow {
+		stats.AverageLatencyMs = float64(l.latency.Microseconds()) / 1000
+	}
+	return stats
+}
@@ -0,0 +1,110 @@
+package loadshed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire_PerKeyLimit(t *testing.T) {
+	l := New(Config{Name: "test", Enabled: true, MaxPerKey: 2, KeyWait: 20 * time.Millisecond})
+
+	release1, err := l.Acquire(context.Background(), "trip-a")
+	require.NoError(t, err)
+	release2, err := l.Acquire(context.Background(), "trip-a")
+	require.NoError(t, err)
+
+	_, err = l.Acquire(context.Background(), "trip-a")
+	assert.ErrorIs(t, err, ErrKeyBusy)
+
+	// Other keys are unaffected
+	releaseB, err := l.Acquire(context.Background(), "trip-b")
+	require.NoError(t, err)
+	releaseB()
+
+	release1()
+	release3, err := l.Acquire(context.Background(), "trip-a")
+	require.NoError(t, err)
+	release2()
+	release3()
+
+	stats := l.Stats()
+	assert.Equal(t, 0, stats.InFlight)
+	assert.Equal(t, 0, stats.ActiveKeys)
+	assert.Equal(t, int64(4), stats.Admitted)
+	assert.Equal(t, int64(1), stats.KeyBusy)
+}
+
+func TestAcquire_QueuedRequestGetsFreedSlot(t *testing.T) {
+	l := New(Config{Enabled: true, MaxPerKey: 1, KeyWait: time.Second})
+	release, err := l.Acquire(context.Background(), "trip")
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		next, err := l.Acquire(context.Background(), "trip")
+		if err == nil {
+			next()
+		}
+		done <- err
+	}()
+
+	require.Eventually(t, func() bool { return l.Stats().QueueDepth == 1 }, time.Second, time.Millisecond)
+	release()
+	assert.NoError(t, <-done)
+}
+
+func TestAcquire_ShedsOnQueueDepth(t *testing.T) {
+	l := New(Config{Enabled: true, MaxPerKey: 1, KeyWait: time.Second, MaxQueueDepth: 1})
+	release, err := l.Acquire(context.Background(), "trip")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(ctx, "trip")
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return l.Stats().QueueDepth == 1 }, time.Second, time.Millisecond)
+
+	_, err = l.Acquire(context.Background(), "other-trip")
+	assert.ErrorIs(t, err, ErrOverloaded)
+	assert.True(t, l.Stats().Shedding)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	release()
+	assert.Equal(t, int64(1), l.Stats().Shed)
+}
+
+func TestAcquire_ShedsOnLatency(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(Config{Enabled: true, LatencyThreshold: 500 * time.Millisecond, LatencyWindow: 10 * time.Second})
+	l.now = func() time.Time { return now }
+
+	release, err := l.Acquire(context.Background(), "")
+	require.NoError(t, err)
+	now = now.Add(2 * time.Second)
+	release()
+
+	_, err = l.Acquire(context.Background(), "")
+	assert.ErrorIs(t, err, ErrOverloaded)
+
+	// Once the slow observation is outside the window, requests are let through again
+	now = now.Add(11 * time.Second)
+	release, err = l.Acquire(context.Background(), "")
+	require.NoError(t, err)
+	release()
+	assert.False(t, l.Stats().Shedding)
+}
+
+func TestAcquire_Disabled(t *testing.T) {
+	l := New(Config{Enabled: false, MaxPerKey: 1})
+	for i := 0; i < 3; i++ {
+		_, err := l.Acquire(context.Background(), "trip")
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,317 @@
+// Package messaging delivers OTPs and booking messages over chat apps, starting with the
+// WhatsApp Business (Cloud) API.
+package messaging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+	"github.com/smarttransit/sms-auth-backend/pkg/sms"
+)
+
+const whatsAppAPIURL = "https://graph.facebook.com/v21.0"
+
+// qrImageSize is the width and height in pixels of the boarding QR sent with confirmations
+const qrImageSize = 512
+
+// WhatsAppConfig holds WhatsApp Business Cloud API credentials and message templates.
+// Business-initiated messages must use templates approved in the WhatsApp Manager.
+type WhatsAppConfig struct {
+	APIURL          string // Defaults to https://graph.facebook.com/v21.0
+	PhoneNumberID   string // ID of the business phone number messages are sent from
+	AccessToken     string // System user access token
+	OTPTemplate     string // Authentication template: {{1}} is the code, with a copy-code button
+	BookingTemplate string // Utility template: image header (QR), {{1}} reference, {{2}} trip details
+	Language        string // Template language code, e.g. "en"
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker (zero values use defaults)
+}
+
+// BookingConfirmation is what a passenger is sent when their booking is confirmed
+type BookingConfirmation struct {
+	Reference  string // Booking reference shown to the conductor
+	Details    string // One line about the trip, e.g. "Colombo - Kandy, Oct 14 08:30, 2 seats"
+	QRCodeData string // Encoded as the boarding QR image; no image is sent when empty
+}
+
+// WhatsAppClient sends template messages through the WhatsApp Business Cloud API
+type WhatsAppClient struct {
+	apiURL          string
+	phoneNumberID   string
+	accessToken     string
+	otpTemplate     string
+	bookingTemplate string
+	language        string
+	client          *httpclient.Client
+}
+
+// NewWhatsAppClient creates a new WhatsApp Business API client
+func NewWhatsAppClient(config WhatsAppConfig) *WhatsAppClient {
+	if config.APIURL == "" {
+		config.APIURL = whatsAppAPIURL
+	}
+	if config.Language == "" {
+		config.Language = "en"
+	}
+	if config.HTTP.Name == "" {
+		config.HTTP.Name = "whatsapp"
+	}
+	return &WhatsAppClient{
+		apiURL:          strings.TrimRight(config.APIURL, "/"),
+		phoneNumberID:   config.PhoneNumberID,
+		accessToken:     config.AccessToken,
+		otpTemplate:     config.OTPTemplate,
+		bookingTemplate: config.BookingTemplate,
+		language:        config.Language,
+		client:          httpclient.New(config.HTTP),
+	}
+}
+
+type templateMessage struct {
+	MessagingProduct string   `json:"messaging_product"`
+	To               string   `json:"to"`
+	Type             string   `json:"type"`
+	Template         template `json:"template"`
+}
+
+type template struct {
+	Name       string      `json:"name"`
+	Language   language    `json:"language"`
+	Components []component `json:"components,omitempty"`
+}
+
+type language struct {
+	Code string `json:"code"`
+}
+
+type component struct {
+	Type       string      `json:"type"`
+	SubType    string      `json:"sub_type,omitempty"`
+	Index      string      `json:"index,omitempty"`
+	Parameters []parameter `json:"parameters"`
+}
+
+type parameter struct {
+	Type  string    `json:"type"`
+	Text  string    `json:"text,omitempty"`
+	Image *mediaRef `json:"image,omitempty"`
+}
+
+type mediaRef struct {
+	ID string `json:"id"`
+}
+
+type sendResponse struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+// SendWhatsAppOTP sends an OTP code with the authentication template. The app type does not
+// change the message; WhatsApp has no SMS auto-read hash.
+func (w *WhatsAppClient) SendWhatsAppOTP(phone, otpCode, appType string) (int64, error) {
+	to, err := formatRecipient(phone)
+	if err != nil {
+		return 0, err
+	}
+
+	msg := templateMessage{
+		MessagingProduct: "whatsapp",
+		To:               to,
+		Type:             "template",
+		Template: template{
+			Name:     w.otpTemplate,
+			Language: language{Code: w.language},
+			Components: []component{
+				{Type: "body", Parameters: []parameter{{Type: "text", Text: otpCode}}},
+				{Type: "button", SubType: "url", Index: "0", Parameters: []parameter{{Type: "text", Text: otpCode}}},
+			},
+		},
+	}
+
+	transactionID := time.Now().UnixMicro()
+	if err := w.send(msg); err != nil {
+		return 0, err
+	}
+	return transactionID, nil
+}
+
+// SendBookingConfirmation sends the booking template with the reference, the trip details and,
+// when the booking has one, its boarding QR as the header image
+func (w *WhatsAppClient) SendBookingConfirmation(phone string, booking BookingConfirmation) error {
+	to, err := formatRecipient(phone)
+	if err != nil {
+		return err
+	}
+
+	var components []component
+	if booking.QRCodeData != "" {
+		mediaID, err := w.uploadQRCode(booking.QRCodeData)
+		if err != nil {
+			return err
+		}
+		components = append(components, component{
+			Type:       "header",
+			Parameters: []parameter{{Type: "image", Image: &mediaRef{ID: mediaID}}},
+		})
+	}
+	components = append(components, component{
+		Type: "body",
+		Parameters: []parameter{
+			{Type: "text", Text: booking.Reference},
+			{Type: "text", Text: booking.Details},
+		},
+	})
+
+	return w.send(templateMessage{
+		MessagingProduct: "whatsapp",
+		To:               to,
+		Type:             "template",
+		Template: template{
+			Name:       w.bookingTemplate,
+			Language:   language{Code: w.language},
+			Components: components,
+		},
+	})
+}
+
+// send posts a template message
+func (w *WhatsAppClient) send(msg templateMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal WhatsApp message: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s/messages", w.apiURL, w.phoneNumberID), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create WhatsApp request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+w.accessToken)
+
+	// Sent once - a retried request could deliver the message twice
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send WhatsApp message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read WhatsApp response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return apiError("WhatsApp message", resp.StatusCode, body)
+	}
+
+	var sendResp sendResponse
+	if err := json.Unmarshal(body, &sendResp); err != nil {
+		return fmt.Errorf("failed to parse WhatsApp response: %w", err)
+	}
+	if len(sendResp.Messages) == 0 {
+		return fmt.Errorf("WhatsApp accepted no message: %s", strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// uploadQRCode renders the QR as a PNG and uploads it, returning the media ID to send
+func (w *WhatsAppClient) uploadQRCode(data string) (string, error) {
+	png, err := qrcode.Encode(data, qrcode.Medium, qrImageSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to render QR code: %w", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField("messaging_product", "whatsapp")
+	_ = form.WriteField("type", "image/png")
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="boarding-qr.png"`)
+	header.Set("Content-Type", "image/png")
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to build QR upload: %w", err)
+	}
+	if _, err := part.Write(png); err != nil {
+		return "", fmt.Errorf("failed to build QR upload: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to build QR upload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s/media", w.apiURL, w.phoneNumberID), &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create QR upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+w.accessToken)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload QR code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read QR upload response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", apiError("QR upload", resp.StatusCode, respBody)
+	}
+
+	var media mediaRef
+	if err := json.Unmarshal(respBody, &media); err != nil || media.ID == "" {
+		return "", fmt.Errorf("failed to parse QR upload response: %s", strings.TrimSpace(string(respBody)))
+	}
+	return media.ID, nil
+}
+
+// apiError describes a rejected request, using the Graph API error message when there is one
+func apiError(what string, status int, body []byte) error {
+	var errResp errorResponse
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error.Message != "" {
+		return fmt.Errorf("%s failed: %s (error code: %d)", what, errResp.Error.Message, errResp.Error.Code)
+	}
+	return fmt.Errorf("%s failed with status %d: %s", what, status, strings.TrimSpace(string(body)))
+}
+
+// formatRecipient converts a Sri Lankan mobile number to the international digits WhatsApp expects
+func formatRecipient(phone string) (string, error) {
+	to, err := sms.FormatPhoneE164(phone)
+	if err != nil {
+		return "", fmt.Errorf("failed to format phone number: %w", err)
+	}
+	return strings.TrimPrefix(to, "+"), nil
+}
+
+// GetName returns the provider name
+func (w *WhatsAppClient) GetName() string {
+	return "WhatsApp Business API"
+}
+
+// Available reports whether the client's circuit breaker lets requests through
+func (w *WhatsAppClient) Available() bool {
+	return w.client.Available()
+}
+
+// HTTPStats returns the client's HTTP metrics
+func (w *WhatsAppClient) HTTPStats() httpclient.Stats {
+	return w.client.Stats()
+}
@@ -0,0 +1,89 @@
+package messaging
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhatsAppClientSendOTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/PN1/messages", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var msg templateMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		assert.Equal(t, "94771234567", msg.To)
+		assert.Equal(t, "otp_code", msg.Template.Name)
+		assert.Equal(t, "en", msg.Template.Language.Code)
+		require.Len(t, msg.Template.Components, 2)
+		assert.Equal(t, "123456", msg.Template.Components[0].Parameters[0].Text)
+		assert.Equal(t, "url", msg.Template.Components[1].SubType)
+
+		w.Write([]byte(`{"messages": [{"id": "wamid.1"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewWhatsAppClient(WhatsAppConfig{APIURL: server.URL, PhoneNumberID: "PN1", AccessToken: "token", OTPTemplate: "otp_code"})
+	id, err := client.SendWhatsAppOTP("0771234567", "123456", "passenger")
+	require.NoError(t, err)
+	assert.NotZero(t, id)
+}
+
+func TestWhatsAppClientSendOTPRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "Recipient phone number not in allowed list", "code": 131030}}`))
+	}))
+	defer server.Close()
+
+	client := NewWhatsAppClient(WhatsAppConfig{APIURL: server.URL, PhoneNumberID: "PN1", OTPTemplate: "otp_code"})
+	_, err := client.SendWhatsAppOTP("0771234567", "123456", "passenger")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "131030")
+}
+
+func TestWhatsAppClientSendBookingConfirmation(t *testing.T) {
+	var uploaded bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/PN1/media":
+			file, header, err := r.FormFile("file")
+			require.NoError(t, err)
+			defer file.Close()
+			png, _ := io.ReadAll(file)
+			assert.Equal(t, "image/png", header.Header.Get("Content-Type"))
+			assert.Equal(t, "\x89PNG", string(png[:4]))
+			uploaded = true
+			w.Write([]byte(`{"id": "MEDIA1"}`))
+		case "/PN1/messages":
+			var msg templateMessage
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			assert.Equal(t, "booking_confirmed", msg.Template.Name)
+			require.Len(t, msg.Template.Components, 2)
+			assert.Equal(t, "header", msg.Template.Components[0].Type)
+			assert.Equal(t, "MEDIA1", msg.Template.Components[0].Parameters[0].Image.ID)
+			body := msg.Template.Components[1].Parameters
+			assert.Equal(t, "BK-1234", body[0].Text)
+			assert.Equal(t, "Colombo - Kandy, 2 seats", body[1].Text)
+			w.Write([]byte(`{"messages": [{"id": "wamid.2"}]}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewWhatsAppClient(WhatsAppConfig{APIURL: server.URL, PhoneNumberID: "PN1", BookingTemplate: "booking_confirmed"})
+	err := client.SendBookingConfirmation("0771234567", BookingConfirmation{
+		Reference:  "BK-1234",
+		Details:    "Colombo - Kandy, 2 seats",
+		QRCodeData: "BK-1234|signature",
+	})
+	require.NoError(t, err)
+	assert.True(t, uploaded)
+}
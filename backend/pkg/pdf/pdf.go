@@ -0,0 +1,218 @@
+// Package pdf renders simple text reports (headings, fixed-width lines and grayscale images
+// such as QR codes) as PDF documents.
+//
+// Text is set in Courier so that column-aligned tables built with fmt padding line up.
+// Only Latin-1 characters are supported; anything else is replaced with '?'.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+const (
+	pageWidth     = 595 // A4 in points
+	pageHeight    = 842
+	margin        = 50
+	fontSize      = 9
+	headingSize   = 14
+	lineHeight    = 12
+	headingHeight = 22
+)
+
+type line struct {
+	text    string
+	heading bool
+	image   *grayImage
+}
+
+// grayImage is an image drawn at width x height points
+type grayImage struct {
+	pixels        []byte // 8-bit gray, row by row from the top
+	columns, rows int
+	width, height int
+}
+
+// Document is a text document laid out onto A4 pages
+type Document struct {
+	lines []line
+}
+
+// NewDocument creates an empty document
+func NewDocument() *Document {
+	return &Document{}
+}
+
+// Heading adds a bold, larger line
+func (d *Document) Heading(text string) {
+	d.lines = append(d.lines, line{text: text, heading: true})
+}
+
+// Line adds a line of body text
+func (d *Document) Line(text string) {
+	d.lines = append(d.lines, line{text: text})
+}
+
+// Linef adds a formatted line of body text
+func (d *Document) Linef(format string, args ...interface{}) {
+	d.Line(fmt.Sprintf(format, args...))
+}
+
+// Image adds a grayscale copy of img, drawn width points wide with its aspect ratio kept
+func (d *Document) Image(img image.Image, width int) {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return
+	}
+	pixels := make([]byte, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixels = append(pixels, color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+		}
+	}
+	d.lines = append(d.lines, line{image: &grayImage{
+		pixels:  pixels,
+		columns: bounds.Dx(),
+		rows:    bounds.Dy(),
+		width:   width,
+		height:  width * bounds.Dy() / bounds.Dx(),
+	}})
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() []byte {
+	pages := d.paginate()
+
+	var buf bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// 1: catalog, 2: page tree, 3-4: fonts, then a page and content stream per page, then
+	// the images in order
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	var images []*grayImage
+	firstImage := 5 + len(pages)*2
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		pageImages := len(images)
+		var xobjects strings.Builder
+		for _, l := range page {
+			if l.image != nil {
+				fmt.Fprintf(&xobjects, " /Im%d %d 0 R", len(images), firstImage+len(images))
+				images = append(images, l.image)
+			}
+		}
+		resources := "/Font << /F1 3 0 R /F2 4 0 R >>"
+		if xobjects.Len() > 0 {
+			resources += " /XObject <<" + xobjects.String() + " >>"
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << %s >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, resources, 6+i*2))
+		content := renderPage(page, pageImages)
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	for _, img := range images {
+		var data bytes.Buffer
+		zw := zlib.NewWriter(&data)
+		zw.Write(img.pixels)
+		zw.Close()
+		object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream",
+			img.columns, img.rows, data.Len(), data.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// paginate splits lines into pages that fit between the margins
+func (d *Document) paginate() [][]line {
+	pages := [][]line{{}}
+	used := 0
+	for _, l := range d.lines {
+		height := l.height()
+		if used+height > pageHeight-2*margin && len(pages[len(pages)-1]) > 0 {
+			pages = append(pages, []line{})
+			used = 0
+		}
+		pages[len(pages)-1] = append(pages[len(pages)-1], l)
+		used += height
+	}
+	return pages
+}
+
+// height is the vertical space the line takes up
+func (l line) height() int {
+	switch {
+	case l.image != nil:
+		return l.image.height + lineHeight
+	case l.heading:
+		return headingHeight
+	default:
+		return lineHeight
+	}
+}
+
+// renderPage draws the page's lines; firstImage is the number of the page's first image
+func renderPage(lines []line, firstImage int) string {
+	var content strings.Builder
+	y := pageHeight - margin
+	for _, l := range lines {
+		if l.image != nil {
+			y -= l.height()
+			fmt.Fprintf(&content, "q %d 0 0 %d %d %d cm /Im%d Do Q\n", l.image.width, l.image.height, margin, y+lineHeight/2, firstImage)
+			firstImage++
+			continue
+		}
+		font, size, height := "F1", fontSize, lineHeight
+		if l.heading {
+			font, size, height = "F2", headingSize, headingHeight
+		}
+		y -= height
+		fmt.Fprintf(&content, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, margin, y, escape(l.text))
+	}
+	return content.String()
+}
+
+// escape encodes text as a Latin-1 PDF string literal body
+func escape(text string) string {
+	var out strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r == '\t':
+			out.WriteString("    ")
+		case r < 32 || r > 255:
+			out.WriteByte('?')
+		case r > 126:
+			fmt.Fprintf(&out, "\\%03o", r)
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,65 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_XrefOffsetsPointAtObjects(t *testing.T) {
+	doc := NewDocument()
+	doc.Heading("Weekly revenue")
+	doc.Linef("%-20s %10s", "Route", "Revenue")
+
+	out := doc.Bytes()
+	require.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, startxref)
+	xref, err := strconv.Atoi(string(startxref[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	require.Len(t, entries, 6) // catalog, pages, 2 fonts, 1 page + content
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))), "object %d", i+1)
+	}
+}
+
+func TestDocument_PaginatesLongReports(t *testing.T) {
+	doc := NewDocument()
+	for i := 0; i < 150; i++ {
+		doc.Linef("row %d", i)
+	}
+	assert.Contains(t, string(doc.Bytes()), "/Count 3")
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `Rs \(net\) \\ ok`, escape(`Rs (net) \ ok`))
+	assert.Equal(t, `caf\351 ?`, escape("café ✓"))
+}
+
+func TestDocument_Image(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 2))
+	img.SetGray(1, 1, color.Gray{Y: 255})
+
+	doc := NewDocument()
+	doc.Heading("E-ticket")
+	doc.Image(img, 100)
+
+	out := string(doc.Bytes())
+	assert.Contains(t, out, "/XObject << /Im0 7 0 R >>")
+	assert.Contains(t, out, "/Width 4 /Height 2 /ColorSpace /DeviceGray")
+	assert.Contains(t, out, "q 100 0 0 50 50 ")
+	assert.Contains(t, out, "/Im0 Do Q")
+}
@@ -0,0 +1,153 @@
+// Package polyline encodes, decodes and simplifies route geometry in the Google encoded
+// polyline format used by the mobile map SDKs and by OSRM.
+package polyline
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// precision is the 5-decimal (roughly 1 m) precision of the standard polyline format
+const precision = 1e5
+
+// earthRadiusMeters is the mean Earth radius used to measure simplification tolerance
+const earthRadiusMeters = 6371000.0
+
+// ErrInvalid is returned when an encoded polyline is truncated or contains invalid characters
+var ErrInvalid = errors.New("invalid encoded polyline")
+
+// Point is a WGS84 coordinate
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Encode returns the encoded polyline of points
+func Encode(points []Point) string {
+	var sb strings.Builder
+	var prevLat, prevLng int64
+	for _, p := range points {
+		lat := int64(math.Round(p.Lat * precision))
+		lng := int64(math.Round(p.Lng * precision))
+		encodeValue(&sb, lat-prevLat)
+		encodeValue(&sb, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return sb.String()
+}
+
+func encodeValue(sb *strings.Builder, v int64) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		sb.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	sb.WriteByte(byte(u + 63))
+}
+
+// Decode returns the points of an encoded polyline
+func Decode(encoded string) ([]Point, error) {
+	points := []Point{}
+	var lat, lng int64
+	for i := 0; i < len(encoded); {
+		dLat, next, err := decodeValue(encoded, i)
+		if err != nil {
+			return nil, err
+		}
+		dLng, next, err := decodeValue(encoded, next)
+		if err != nil {
+			return nil, err
+		}
+		i = next
+		lat += dLat
+		lng += dLng
+		points = append(points, Point{Lat: float64(lat) / precision, Lng: float64(lng) / precision})
+	}
+	return points, nil
+}
+
+func decodeValue(encoded string, i int) (int64, int, error) {
+	var result uint64
+	var shift uint
+	for {
+		if i >= len(encoded) || shift > 60 {
+			return 0, 0, ErrInvalid
+		}
+		b := int(encoded[i]) - 63
+		i++
+		if b < 0 || b > 0x3f {
+			return 0, 0, ErrInvalid
+		}
+		result |= uint64(b&0x1f) << shift
+		shift += 5
+		if b < 0x20 {
+			break
+		}
+	}
+	v := int64(result >> 1)
+	if result&1 != 0 {
+		v = ^v
+	}
+	return v, i, nil
+}
+
+// Simplify reduces points with the Douglas-Peucker algorithm, dropping points that lie
+// within toleranceMeters of the simplified line. The first and last points are always kept.
+func Simplify(points []Point, toleranceMeters float64) []Point {
+	if len(points) <= 2 || toleranceMeters <= 0 {
+		return append([]Point(nil), points...)
+	}
+
+	// Project onto a local plane so the tolerance is in meters; accurate at route scale
+	refLat := points[0].Lat * math.Pi / 180
+	xy := make([][2]float64, len(points))
+	for i, p := range points {
+		xy[i] = [2]float64{
+			p.Lng * math.Pi / 180 * math.Cos(refLat) * earthRadiusMeters,
+			p.Lat * math.Pi / 180 * earthRadiusMeters,
+		}
+	}
+
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		first, last := span[0], span[1]
+
+		farthest, maxDist := -1, toleranceMeters
+		for i := first + 1; i < last; i++ {
+			if d := segmentDistance(xy[i], xy[first], xy[last]); d > maxDist {
+				farthest, maxDist = i, d
+			}
+		}
+		if farthest >= 0 {
+			keep[farthest] = true
+			stack = append(stack, [2]int{first, farthest}, [2]int{farthest, last})
+		}
+	}
+
+	simplified := make([]Point, 0, len(points))
+	for i, p := range points {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
+
+// segmentDistance is the planar distance from p to the segment a-b
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
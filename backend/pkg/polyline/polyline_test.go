@@ -0,0 +1,67 @@
+package polyline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Example from Google's encoded polyline algorithm documentation
+var googleExample = []Point{{Lat: 38.5, Lng: -120.2}, {Lat: 40.7, Lng: -120.95}, {Lat: 43.252, Lng: -126.453}}
+
+const googleExampleEncoded = "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, googleExampleEncoded, Encode(googleExample))
+	assert.Equal(t, "", Encode(nil))
+}
+
+func TestDecode(t *testing.T) {
+	points, err := Decode(googleExampleEncoded)
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+	for i, p := range points {
+		assert.InDelta(t, googleExample[i].Lat, p.Lat, 1e-6)
+		assert.InDelta(t, googleExample[i].Lng, p.Lng, 1e-6)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	_, err := Decode("_p~iF~ps|U_ulL") // Truncated mid-point
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	_, err = Decode("_p~iF ps|U")
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestSimplify_DropsPointsWithinTolerance(t *testing.T) {
+	// Colombo to Kandy with two intermediate points a few kilometres off the straight line
+	points := []Point{
+		{Lat: 6.9271, Lng: 79.8612},
+		{Lat: 7.0000, Lng: 80.0000},
+		{Lat: 7.0500, Lng: 80.0953},
+		{Lat: 7.2906, Lng: 80.6337},
+	}
+
+	simplified := Simplify(points, 5000)
+	assert.Equal(t, []Point{points[0], points[3]}, simplified)
+
+	// A tight tolerance keeps the detour
+	assert.Len(t, Simplify(points, 1), 4)
+}
+
+func TestSimplify_KeepsSharpCorner(t *testing.T) {
+	points := []Point{
+		{Lat: 7.0, Lng: 80.0},
+		{Lat: 7.1, Lng: 80.0},
+		{Lat: 7.1, Lng: 80.1},
+	}
+	assert.Len(t, Simplify(points, 100), 3)
+}
+
+func TestSimplify_ShortInputUnchanged(t *testing.T) {
+	points := []Point{{Lat: 7.0, Lng: 80.0}, {Lat: 7.1, Lng: 80.1}}
+	assert.Equal(t, points, Simplify(points, 1000))
+	assert.Empty(t, Simplify(nil, 1000))
+}
@@ -0,0 +1,214 @@
+package push
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+)
+
+const (
+	fcmScope       = "https://www.googleapis.com/auth/firebase.messaging"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	fcmSendURL     = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// FCMConfig holds Firebase Cloud Messaging service account credentials
+type FCMConfig struct {
+	ProjectID   string
+	ClientEmail string // Service account email
+	PrivateKey  string // Service account PEM private key
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker (zero values use defaults)
+}
+
+// FCMSender sends notifications through the FCM HTTP v1 API using a service account
+type FCMSender struct {
+	projectID   string
+	clientEmail string
+	privateKey  string
+	client      *httpclient.Client
+
+	// OAuth access token cache
+	tokenMutex  sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+
+	// Overridable for tests
+	tokenURL string
+	sendURL  string
+}
+
+// NewFCMSender creates a new FCM sender
+func NewFCMSender(config FCMConfig) *FCMSender {
+	if config.HTTP.Name == "" {
+		config.HTTP.Name = "fcm"
+	}
+	return &FCMSender{
+		projectID:   config.ProjectID,
+		clientEmail: config.ClientEmail,
+		privateKey:  config.PrivateKey,
+		client:      httpclient.New(config.HTTP),
+		tokenURL:    googleTokenURL,
+		sendURL:     fmt.Sprintf(fcmSendURL, config.ProjectID),
+	}
+}
+
+// GetName returns the provider name
+func (s *FCMSender) GetName() string {
+	return "fcm"
+}
+
+// Stats returns the FCM client's resilience counters
+func (s *FCMSender) Stats() httpclient.Stats {
+	return s.client.Stats()
+}
+
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send delivers a notification to one device token
+func (s *FCMSender) Send(token string, msg Message) error {
+	accessToken, err := s.getAccessToken()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(fcmRequest{Message: fcmMessage{
+		Token:        token,
+		Notification: fcmNotification{Title: msg.Title, Body: msg.Body},
+		Data:         msg.Data,
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal FCM message: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.sendURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create FCM request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	// Not idempotent: a retried send could deliver the notification twice
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send FCM message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var errResp fcmErrorResponse
+	_ = json.Unmarshal(body, &errResp)
+	for _, detail := range errResp.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return ErrUnregistered
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrUnregistered
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		s.invalidateAccessToken()
+	}
+	return fmt.Errorf("FCM send failed: HTTP %d %s", resp.StatusCode, errResp.Error.Message)
+}
+
+// getAccessToken returns a cached OAuth token, exchanging a signed service account JWT when it expires
+func (s *FCMSender) getAccessToken() (string, error) {
+	s.tokenMutex.Lock()
+	defer s.tokenMutex.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.tokenExpiry.Add(-time.Minute)) {
+		return s.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(s.privateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid FCM private key: %w", err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.clientEmail,
+		"scope": fcmScope,
+		"aud":   s.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign FCM assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequest(http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create FCM token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.DoIdempotent(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get FCM access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("FCM token exchange failed: HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to parse FCM token response: %w", err)
+	}
+
+	s.accessToken = tokenResp.AccessToken
+	s.tokenExpiry = now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
+
+func (s *FCMSender) invalidateAccessToken() {
+	s.tokenMutex.Lock()
+	s.accessToken = ""
+	s.tokenMutex.Unlock()
+}
@@ -0,0 +1,75 @@
+package push
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPrivateKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func newTestFCMSender(t *testing.T, sendHandler http.HandlerFunc) (*FCMSender, *int32) {
+	t.Helper()
+	var tokenRequests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenRequests, 1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+		assert.NotEmpty(t, r.Form.Get("assertion"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access-123", "expires_in": 3600})
+	})
+	mux.HandleFunc("/send", sendHandler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	sender := NewFCMSender(FCMConfig{
+		ProjectID:   "smarttransit-test",
+		ClientEmail: "push@smarttransit-test.iam.gserviceaccount.com",
+		PrivateKey:  testPrivateKeyPEM(t),
+	})
+	sender.tokenURL = server.URL + "/token"
+	sender.sendURL = server.URL + "/send"
+	return sender, &tokenRequests
+}
+
+func TestFCMSender_SendCachesAccessToken(t *testing.T) {
+	sender, tokenRequests := newTestFCMSender(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer access-123", r.Header.Get("Authorization"))
+		var body fcmRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "device-token", body.Message.Token)
+		assert.Equal(t, "New message", body.Message.Notification.Title)
+		assert.Equal(t, "trip_message", body.Message.Data["type"])
+		w.Write([]byte(`{"name":"projects/smarttransit-test/messages/1"}`))
+	})
+
+	msg := Message{Title: "New message", Body: "Bus is delayed", Data: map[string]string{"type": "trip_message"}}
+	require.NoError(t, sender.Send("device-token", msg))
+	require.NoError(t, sender.Send("device-token", msg))
+	assert.Equal(t, int32(1), atomic.LoadInt32(tokenRequests))
+}
+
+func TestFCMSender_UnregisteredToken(t *testing.T) {
+	sender, _ := newTestFCMSender(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+	})
+
+	err := sender.Send("stale-token", Message{Title: "t", Body: "b"})
+	assert.ErrorIs(t, err, ErrUnregistered)
+}
@@ -0,0 +1,52 @@
+// Package push delivers mobile push notifications to device registration tokens.
+package push
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnregistered is returned when a device token is no longer valid and should be discarded
+var ErrUnregistered = errors.New("push token is no longer registered")
+
+// Message is a notification shown on the device, with optional data for the app
+type Message struct {
+	Title string
+	Body  string
+	Data  map[string]string // Delivered to the app (e.g. {"type": "trip_message", "scheduled_trip_id": "..."})
+}
+
+// Sender delivers a push notification to a single device token
+type Sender interface {
+	// Send delivers the message. Returns ErrUnregistered if the token should be removed.
+	Send(token string, msg Message) error
+
+	// GetName returns the name of the push provider implementation
+	GetName() string
+}
+
+// LogSender prints notifications instead of sending them (development mode)
+type LogSender struct{}
+
+// NewLogSender creates a new LogSender
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send logs the notification
+func (s *LogSender) Send(token string, msg Message) error {
+	fmt.Printf("🔔 [DEV PUSH] to=%s... title=%q body=%q data=%v\n", truncateToken(token), msg.Title, msg.Body, msg.Data)
+	return nil
+}
+
+// GetName returns the provider name
+func (s *LogSender) GetName() string {
+	return "log"
+}
+
+func truncateToken(token string) string {
+	if len(token) > 12 {
+		return token[:12]
+	}
+	return token
+}
@@ -0,0 +1,163 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+	"github.com/smarttransit/sms-auth-backend/pkg/polyline"
+)
+
+const googleDirectionsURL = "https://maps.googleapis.com/maps/api/directions/json"
+
+// googleMaxPoints is the origin, destination and up to 23 intermediate waypoints Google
+// accepts per request; longer routes are requested in chunks that share their end stops
+const googleMaxPoints = 25
+
+// GoogleDirectionsConfig holds the Google Directions API credentials
+type GoogleDirectionsConfig struct {
+	APIKey string
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker (zero values use defaults)
+}
+
+// GoogleDirectionsClient fetches driving routes from the Google Directions API
+type GoogleDirectionsClient struct {
+	apiKey string
+	client *httpclient.Client
+
+	// Overridable for tests
+	directionsURL string
+}
+
+// NewGoogleDirectionsClient creates a new Google Directions client
+func NewGoogleDirectionsClient(config GoogleDirectionsConfig) *GoogleDirectionsClient {
+	if config.HTTP.Name == "" {
+		config.HTTP.Name = "google_directions"
+	}
+	return &GoogleDirectionsClient{
+		apiKey:        config.APIKey,
+		client:        httpclient.New(config.HTTP),
+		directionsURL: googleDirectionsURL,
+	}
+}
+
+// Name returns the engine name
+func (c *GoogleDirectionsClient) Name() string {
+	return "google"
+}
+
+// HTTPStats returns the Google Directions client's HTTP metrics
+func (c *GoogleDirectionsClient) HTTPStats() httpclient.Stats {
+	return c.client.Stats()
+}
+
+type googleDirectionsResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Routes       []struct {
+		OverviewPolyline struct {
+			Points string `json:"points"`
+		} `json:"overview_polyline"`
+		Legs []struct {
+			Distance struct {
+				Value float64 `json:"value"` // Meters
+			} `json:"distance"`
+			Duration struct {
+				Value float64 `json:"value"` // Seconds
+			} `json:"duration"`
+		} `json:"legs"`
+	} `json:"routes"`
+}
+
+// Route returns the driving route visiting waypoints in order. The geometry is Google's
+// overview polyline, which is already smoothed slightly.
+func (c *GoogleDirectionsClient) Route(waypoints []polyline.Point) (*Route, error) {
+	if len(waypoints) < 2 {
+		return nil, fmt.Errorf("at least 2 waypoints are required, got %d", len(waypoints))
+	}
+
+	route := &Route{}
+	var geometry []polyline.Point
+	for start := 0; start < len(waypoints)-1; start += googleMaxPoints - 1 {
+		end := start + googleMaxPoints
+		if end > len(waypoints) {
+			end = len(waypoints)
+		}
+
+		chunk, err := c.routeChunk(waypoints[start:end])
+		if err != nil {
+			return nil, err
+		}
+		points, err := polyline.Decode(chunk.EncodedPolyline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Google Directions polyline: %w", err)
+		}
+		if len(geometry) > 0 && len(points) > 0 {
+			points = points[1:] // The chunk starts where the previous one ended
+		}
+		geometry = append(geometry, points...)
+
+		route.DistanceMeters += chunk.DistanceMeters
+		route.DurationSeconds += chunk.DurationSeconds
+		route.Legs = append(route.Legs, chunk.Legs...)
+	}
+
+	route.EncodedPolyline = polyline.Encode(geometry)
+	return route, nil
+}
+
+func (c *GoogleDirectionsClient) routeChunk(waypoints []polyline.Point) (*Route, error) {
+	params := url.Values{
+		"origin":      {formatLatLng(waypoints[0])},
+		"destination": {formatLatLng(waypoints[len(waypoints)-1])},
+		"mode":        {"driving"},
+		"key":         {c.apiKey},
+	}
+	if len(waypoints) > 2 {
+		via := make([]string, 0, len(waypoints)-2)
+		for _, p := range waypoints[1 : len(waypoints)-1] {
+			via = append(via, formatLatLng(p))
+		}
+		params.Set("waypoints", strings.Join(via, "|"))
+	}
+
+	resp, err := c.client.Get(c.directionsURL + "?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to request Google Directions route: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google Directions response: %w", err)
+	}
+
+	var result googleDirectionsResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("Google Directions route failed: HTTP %d", resp.StatusCode)
+	}
+	switch {
+	case result.Status == "ZERO_RESULTS" || (result.Status == "OK" && len(result.Routes) == 0):
+		return nil, ErrNoRoute
+	case resp.StatusCode != http.StatusOK || result.Status != "OK":
+		return nil, fmt.Errorf("Google Directions route failed: HTTP %d %s %s", resp.StatusCode, result.Status, result.ErrorMessage)
+	}
+
+	r := result.Routes[0]
+	route := &Route{EncodedPolyline: r.OverviewPolyline.Points}
+	for _, leg := range r.Legs {
+		route.Legs = append(route.Legs, Leg{DistanceMeters: leg.Distance.Value, DurationSeconds: leg.Duration.Value})
+		route.DistanceMeters += leg.Distance.Value
+		route.DurationSeconds += leg.Duration.Value
+	}
+	return route, nil
+}
+
+func formatLatLng(p polyline.Point) string {
+	return fmt.Sprintf("%.6f,%.6f", p.Lat, p.Lng)
+}
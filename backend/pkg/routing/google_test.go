@@ -0,0 +1,116 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+	"github.com/smarttransit/sms-auth-backend/pkg/polyline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGoogleClient(t *testing.T, handler http.HandlerFunc) *GoogleDirectionsClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := NewGoogleDirectionsClient(GoogleDirectionsConfig{APIKey: "test-key", HTTP: httpclient.Config{MaxRetries: 0}})
+	client.directionsURL = server.URL
+	return client
+}
+
+// googleLegsResponse answers a directions request with 1 km / 2 min legs along the requested points
+func googleLegsResponse(w http.ResponseWriter, r *http.Request) {
+	points := []polyline.Point{parseLatLng(r.URL.Query().Get("origin"))}
+	if via := r.URL.Query().Get("waypoints"); via != "" {
+		for _, p := range strings.Split(via, "|") {
+			points = append(points, parseLatLng(p))
+		}
+	}
+	points = append(points, parseLatLng(r.URL.Query().Get("destination")))
+
+	legs := []map[string]interface{}{}
+	for i := 1; i < len(points); i++ {
+		legs = append(legs, map[string]interface{}{
+			"distance": map[string]float64{"value": 1000},
+			"duration": map[string]float64{"value": 120},
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "OK",
+		"routes": []map[string]interface{}{{
+			"overview_polyline": map[string]string{"points": polyline.Encode(points)},
+			"legs":              legs,
+		}},
+	})
+}
+
+func parseLatLng(s string) polyline.Point {
+	var p polyline.Point
+	parts := strings.Split(s, ",")
+	json.Unmarshal([]byte(parts[0]), &p.Lat)
+	json.Unmarshal([]byte(parts[1]), &p.Lng)
+	return p
+}
+
+func TestGoogleDirectionsClient_Route(t *testing.T) {
+	client := newTestGoogleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.URL.Query().Get("key"))
+		assert.Equal(t, "6.927100,79.861200", r.URL.Query().Get("origin"))
+		assert.Equal(t, "7.290600,80.633700", r.URL.Query().Get("destination"))
+		assert.Empty(t, r.URL.Query().Get("waypoints"))
+		googleLegsResponse(w, r)
+	})
+
+	route, err := client.Route(colomboToKandy)
+	require.NoError(t, err)
+	assert.Equal(t, []Leg{{DistanceMeters: 1000, DurationSeconds: 120}}, route.Legs)
+	assert.Equal(t, 1000.0, route.DistanceMeters)
+	assert.Equal(t, 120.0, route.DurationSeconds)
+}
+
+func TestGoogleDirectionsClient_ChunksLongRoutes(t *testing.T) {
+	var requests int32
+	client := newTestGoogleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		googleLegsResponse(w, r)
+	})
+
+	waypoints := make([]polyline.Point, 30)
+	for i := range waypoints {
+		waypoints[i] = polyline.Point{Lat: 7 + float64(i)*0.01, Lng: 80}
+	}
+
+	route, err := client.Route(waypoints)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Len(t, route.Legs, 29)
+	assert.Equal(t, 29000.0, route.DistanceMeters)
+
+	geometry, err := polyline.Decode(route.EncodedPolyline)
+	require.NoError(t, err)
+	assert.Len(t, geometry, 30) // The shared stop between chunks appears once
+}
+
+func TestGoogleDirectionsClient_ZeroResults(t *testing.T) {
+	client := newTestGoogleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ZERO_RESULTS","routes":[]}`))
+	})
+
+	_, err := client.Route(colomboToKandy)
+	assert.ErrorIs(t, err, ErrNoRoute)
+}
+
+func TestGoogleDirectionsClient_RequestDenied(t *testing.T) {
+	client := newTestGoogleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"REQUEST_DENIED","error_message":"The provided API key is invalid."}`))
+	})
+
+	_, err := client.Route(colomboToKandy)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "REQUEST_DENIED")
+}
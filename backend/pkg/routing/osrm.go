@@ -0,0 +1,114 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+	"github.com/smarttransit/sms-auth-backend/pkg/polyline"
+)
+
+// OSRMConfig holds the connection settings of an OSRM server
+type OSRMConfig struct {
+	BaseURL string // e.g. https://router.project-osrm.org
+	Profile string // Routing profile, "driving" when empty
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker (zero values use defaults)
+}
+
+// OSRMClient fetches road routes from the OSRM HTTP route service
+type OSRMClient struct {
+	baseURL string
+	profile string
+	client  *httpclient.Client
+}
+
+// NewOSRMClient creates a new OSRM client
+func NewOSRMClient(config OSRMConfig) *OSRMClient {
+	if config.HTTP.Name == "" {
+		config.HTTP.Name = "osrm"
+	}
+	if config.Profile == "" {
+		config.Profile = "driving"
+	}
+	return &OSRMClient{
+		baseURL: strings.TrimRight(config.BaseURL, "/"),
+		profile: config.Profile,
+		client:  httpclient.New(config.HTTP),
+	}
+}
+
+// Name returns the engine name
+func (c *OSRMClient) Name() string {
+	return "osrm"
+}
+
+// HTTPStats returns the OSRM client's HTTP metrics
+func (c *OSRMClient) HTTPStats() httpclient.Stats {
+	return c.client.Stats()
+}
+
+type osrmRouteResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Routes  []struct {
+		Geometry string  `json:"geometry"`
+		Distance float64 `json:"distance"`
+		Duration float64 `json:"duration"`
+		Legs     []struct {
+			Distance float64 `json:"distance"`
+			Duration float64 `json:"duration"`
+		} `json:"legs"`
+	} `json:"routes"`
+}
+
+// Route returns the driving route visiting waypoints in order
+func (c *OSRMClient) Route(waypoints []polyline.Point) (*Route, error) {
+	if len(waypoints) < 2 {
+		return nil, fmt.Errorf("at least 2 waypoints are required, got %d", len(waypoints))
+	}
+
+	coords := make([]string, len(waypoints))
+	for i, p := range waypoints {
+		coords[i] = fmt.Sprintf("%.6f,%.6f", p.Lng, p.Lat) // OSRM takes lng,lat
+	}
+	url := fmt.Sprintf("%s/route/v1/%s/%s?overview=full&geometries=polyline",
+		c.baseURL, c.profile, strings.Join(coords, ";"))
+
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request OSRM route: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OSRM response: %w", err)
+	}
+
+	var result osrmRouteResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("OSRM route failed: HTTP %d", resp.StatusCode)
+	}
+	switch {
+	case result.Code == "NoRoute" || (result.Code == "Ok" && len(result.Routes) == 0):
+		return nil, ErrNoRoute
+	case resp.StatusCode != http.StatusOK || result.Code != "Ok":
+		return nil, fmt.Errorf("OSRM route failed: HTTP %d %s %s", resp.StatusCode, result.Code, result.Message)
+	}
+
+	route := result.Routes[0]
+	legs := make([]Leg, len(route.Legs))
+	for i, leg := range route.Legs {
+		legs[i] = Leg{DistanceMeters: leg.Distance, DurationSeconds: leg.Duration}
+	}
+	return &Route{
+		EncodedPolyline: route.Geometry,
+		DistanceMeters:  route.Distance,
+		DurationSeconds: route.Duration,
+		Legs:            legs,
+	}, nil
+}
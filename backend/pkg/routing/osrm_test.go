@@ -0,0 +1,64 @@
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+	"github.com/smarttransit/sms-auth-backend/pkg/polyline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var colomboToKandy = []polyline.Point{{Lat: 6.9271, Lng: 79.8612}, {Lat: 7.2906, Lng: 80.6337}}
+
+func newTestOSRMClient(t *testing.T, handler http.HandlerFunc) *OSRMClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewOSRMClient(OSRMConfig{BaseURL: server.URL + "/", HTTP: httpclient.Config{MaxRetries: 0}})
+}
+
+func TestOSRMClient_Route(t *testing.T) {
+	client := newTestOSRMClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/route/v1/driving/79.861200,6.927100;80.633700,7.290600", r.URL.Path)
+		assert.Equal(t, "full", r.URL.Query().Get("overview"))
+		assert.Equal(t, "polyline", r.URL.Query().Get("geometries"))
+		w.Write([]byte(`{"code":"Ok","routes":[{"geometry":"_p~iF~ps|U_ulLnnqC","distance":115230.4,"duration":10800.5,` +
+			`"legs":[{"distance":115230.4,"duration":10800.5}]}]}`))
+	})
+
+	route, err := client.Route(colomboToKandy)
+	require.NoError(t, err)
+	assert.Equal(t, "_p~iF~ps|U_ulLnnqC", route.EncodedPolyline)
+	assert.Equal(t, 115230.4, route.DistanceMeters)
+	assert.Equal(t, 10800.5, route.DurationSeconds)
+	assert.Equal(t, []Leg{{DistanceMeters: 115230.4, DurationSeconds: 10800.5}}, route.Legs)
+}
+
+func TestOSRMClient_NoRoute(t *testing.T) {
+	client := newTestOSRMClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"NoRoute","message":"Impossible route between points"}`))
+	})
+
+	_, err := client.Route(colomboToKandy)
+	assert.ErrorIs(t, err, ErrNoRoute)
+}
+
+func TestOSRMClient_ServerError(t *testing.T) {
+	client := newTestOSRMClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := client.Route(colomboToKandy)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNoRoute)
+}
+
+func TestOSRMClient_RequiresTwoWaypoints(t *testing.T) {
+	client := NewOSRMClient(OSRMConfig{BaseURL: "http://localhost"})
+	_, err := client.Route(colomboToKandy[:1])
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+// Package routing asks a road routing engine (OSRM or Google Directions) for the driving
+// path, distances and durations through a route's stops
+package routing
+
+import (
+	"errors"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+	"github.com/smarttransit/sms-auth-backend/pkg/polyline"
+)
+
+// ErrNoRoute is returned when the engine finds no road path between the waypoints
+var ErrNoRoute = errors.New("no road route between waypoints")
+
+// Engine computes driving routes through ordered waypoints
+type Engine interface {
+	// Route returns the driving route visiting waypoints in order
+	Route(waypoints []polyline.Point) (*Route, error)
+	// Name identifies the engine in cached results and logs
+	Name() string
+
+	httpclient.StatsProvider
+}
+
+// Route is the driving path through a list of waypoints
+type Route struct {
+	EncodedPolyline string  // Full-resolution road geometry
+	DistanceMeters  float64 // Total driving distance
+	DurationSeconds float64 // Total driving time without traffic
+	Legs            []Leg   // One per consecutive pair of waypoints
+}
+
+// Leg is the driving distance and time between two consecutive waypoints
+type Leg struct {
+	DistanceMeters  float64
+	DurationSeconds float64
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
 )
 
 // DialogGateway implements SMS sending via Dialog eSMS API
@@ -18,7 +20,7 @@ type DialogGateway struct {
 	username string
 	password string
 	mask     string
-	client   *http.Client
+	client   *httpclient.Client
 
 	// Token management
 	token       string
@@ -38,6 +40,8 @@ type DialogConfig struct {
 	Mask             string
 	DriverAppHash    string // Driver/Conductor app signature hash
 	PassengerAppHash string // Passenger app signature hash
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker (zero values use defaults)
 }
 
 // NewDialogGateway creates a new Dialog SMS Gateway client
@@ -49,12 +53,23 @@ func NewDialogGateway(config DialogConfig) *DialogGateway {
 		mask:             config.Mask,
 		driverAppHash:    config.DriverAppHash,
 		passengerAppHash: config.PassengerAppHash,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:           newGatewayClient(config.HTTP),
 	}
 }
 
+// newGatewayClient builds the resilient HTTP client shared by the Dialog gateways
+func newGatewayClient(cfg httpclient.Config) *httpclient.Client {
+	if cfg.Name == "" {
+		cfg.Name = "dialog"
+	}
+	if cfg.OnStateChange == nil {
+		cfg.OnStateChange = func(name string, from, to httpclient.State) {
+			fmt.Printf("⚠️  %s SMS gateway circuit breaker: %s -> %s\n", name, from, to)
+		}
+	}
+	return httpclient.New(cfg)
+}
+
 // LoginRequest represents the login request structure
 type LoginRequest struct {
 	Username string `json:"username"`
@@ -142,7 +157,7 @@ func (d *DialogGateway) GetAccessToken() error {
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := d.client.Do(req)
+	resp, err := d.client.DoIdempotent(req)
 	if err != nil {
 		fmt.Printf("❌ HTTP request failed: %v\n", err)
 		return fmt.Errorf("failed to send login request: %w", err)
@@ -231,6 +246,12 @@ func FormatPhoneForDialog(phone string) (string, error) {
 
 // SendOTP sends an OTP to a single phone number
 func (d *DialogGateway) SendOTP(phone, otpCode, appType string) (int64, error) {
+	return d.SendBrandedOTP(phone, otpCode, appType, OTPBranding{})
+}
+
+// SendBrandedOTP sends an OTP to a single phone number under a tenant's sender mask and app name
+func (d *DialogGateway) SendBrandedOTP(phone, otpCode, appType string, branding OTPBranding) (int64, error) {
+	branding = brandingOrDefault(branding, d.mask)
 	fmt.Printf("📱 SendOTP called - Phone: %s, OTP: %s, AppType: %s\n", phone, otpCode, appType)
 
 	// Ensure we have a valid token
@@ -271,7 +292,7 @@ func (d *DialogGateway) SendOTP(phone, otpCode, appType string) (int64, error) {
 	if appHash != "" {
 		// Format for Android SMS auto-read:
 		// OTP code followed by message and app hash on a new line
-		message = fmt.Sprintf("Your SmartTransit OTP is: %s\n\nPlease use the above OTP to complete your action.\n\nRegards,\nSmartTransit\n%s", otpCode, appHash)
+		message = fmt.Sprintf("Your %s OTP is: %s\n\nPlease use the above OTP to complete your action.\n\nRegards,\n%s\n%s", branding.AppName, otpCode, branding.AppName, appHash)
 	} else {
 		// Fallback message without app hash
 		message = fmt.Sprintf("Your OTP is %s. Valid for 5 minutes. Do not share this code with anyone.", otpCode)
@@ -283,7 +304,7 @@ func (d *DialogGateway) SendOTP(phone, otpCode, appType string) (int64, error) {
 			{Mobile: formattedPhone},
 		},
 		Message:       message,
-		SourceAddress: d.mask,
+		SourceAddress: branding.Mask,
 		TransactionID: transactionID,
 		PaymentMethod: 0, // 0 = wallet payment
 	}
@@ -373,8 +394,8 @@ func (d *DialogGateway) CheckCampaignStatus(transactionID int64) (string, error)
 	d.tokenMutex.RUnlock()
 	req.Header.Set("Content-Type", "application/json")
 
-	// Send request
-	resp, err := d.client.Do(req)
+	// Send request (status checks are safe to retry)
+	resp, err := d.client.DoIdempotent(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send status check request: %w", err)
 	}
@@ -476,3 +497,13 @@ func (d *DialogGateway) SendBulkSMS(phones []string, message string) (int64, err
 func (d *DialogGateway) GetName() string {
 	return "Dialog API v2 Gateway"
 }
+
+// Available reports whether the gateway's circuit breaker lets requests through
+func (d *DialogGateway) Available() bool {
+	return d.client.Available()
+}
+
+// HTTPStats returns the gateway's HTTP client metrics
+func (d *DialogGateway) HTTPStats() httpclient.Stats {
+	return d.client.Stats()
+}
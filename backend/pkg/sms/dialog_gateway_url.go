@@ -7,6 +7,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
 )
 
 // DialogURLGateway implements SMS sending using Dialog's GET request API (URL method)
@@ -16,21 +18,29 @@ type DialogURLGateway struct {
 	mask             string // Source address/mask
 	driverAppHash    string // Driver/Conductor app signature hash for SMS auto-read (Android)
 	passengerAppHash string // Passenger app signature hash for SMS auto-read (Android)
+	client           *httpclient.Client
 }
 
 // NewDialogURLGateway creates a new Dialog URL gateway instance
-func NewDialogURLGateway(apiKey, mask, driverHash, passengerHash string) *DialogURLGateway {
+func NewDialogURLGateway(apiKey, mask, driverHash, passengerHash string, httpConfig httpclient.Config) *DialogURLGateway {
 	return &DialogURLGateway{
 		apiKey:           apiKey,
 		mask:             mask,
 		driverAppHash:    driverHash,
 		passengerAppHash: passengerHash,
+		client:           newGatewayClient(httpConfig),
 	}
 }
 
 // SendOTP sends an OTP via Dialog's URL-based SMS API
 // Uses the appropriate app hash based on the appType parameter
 func (d *DialogURLGateway) SendOTP(phone, otpCode, appType string) (int64, error) {
+	return d.SendBrandedOTP(phone, otpCode, appType, OTPBranding{})
+}
+
+// SendBrandedOTP sends an OTP via the URL API under a tenant's sender mask and app name
+func (d *DialogURLGateway) SendBrandedOTP(phone, otpCode, appType string, branding OTPBranding) (int64, error) {
+	branding = brandingOrDefault(branding, d.mask)
 	fmt.Printf("📱 SendOTP (URL method) called - Phone: %s, OTP: %s, AppType: %s\n", phone, otpCode, appType)
 
 	// Format phone number for Dialog
@@ -57,12 +67,16 @@ func (d *DialogURLGateway) SendOTP(phone, otpCode, appType string) (int64, error
 	// Create the message with the specific app hash for Android SMS auto-read
 	var message string
 	if appHash != "" {
-		message = fmt.Sprintf("Your SmartTransit OTP is: %s\n\nPlease use the above OTP to complete your action.\n\nRegards,\nSmartTransit\n%s",
+		message = fmt.Sprintf("Your %s OTP is: %s\n\nPlease use the above OTP to complete your action.\n\nRegards,\n%s\n%s",
+			branding.AppName,
 			otpCode,
+			branding.AppName,
 			appHash)
 	} else {
-		message = fmt.Sprintf("Your SmartTransit OTP is: %s\n\nPlease use the above OTP to complete your action.\n\nRegards,\nSmartTransit",
-			otpCode)
+		message = fmt.Sprintf("Your %s OTP is: %s\n\nPlease use the above OTP to complete your action.\n\nRegards,\n%s",
+			branding.AppName,
+			otpCode,
+			branding.AppName)
 	}
 
 	fmt.Printf("📱 Using app hash: %s (Type: %s)\n", appHash, appType)
@@ -74,23 +88,18 @@ func (d *DialogURLGateway) SendOTP(phone, otpCode, appType string) (int64, error
 	params := url.Values{}
 	params.Add("esmsqk", d.apiKey)
 	params.Add("list", formattedPhone)
-	params.Add("source_address", d.mask)
+	params.Add("source_address", branding.Mask)
 	params.Add("message", message)
 
 	fullURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
 	fmt.Printf("🌐 Request URL: %s (with masked API key)\n", strings.Replace(fullURL, d.apiKey, "***MASKED***", 1))
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	// Make the GET request
+	// Make the GET request (sent once - each call creates a new SMS campaign)
 	fmt.Println("📤 Sending GET request to Dialog...")
-	resp, err := client.Get(fullURL)
+	resp, err := d.client.Get(fullURL)
 	if err != nil {
 		fmt.Printf("❌ HTTP request error: %v\n", err)
-		return 0, fmt.Errorf("failed to send SMS: %v", err)
+		return 0, fmt.Errorf("failed to send SMS: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -137,3 +146,13 @@ func (d *DialogURLGateway) SendOTPWithHash(phone, otpCode, appHash string) (int6
 func (d *DialogURLGateway) GetName() string {
 	return "Dialog URL Gateway"
 }
+
+// Available reports whether the gateway's circuit breaker lets requests through
+func (d *DialogURLGateway) Available() bool {
+	return d.client.Available()
+}
+
+// HTTPStats returns the gateway's HTTP client metrics
+func (d *DialogURLGateway) HTTPStats() httpclient.Stats {
+	return d.client.Stats()
+}
@@ -0,0 +1,139 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+)
+
+// DeliveryAttempt describes one try at sending through one of a FailoverGateway's providers
+type DeliveryAttempt struct {
+	Provider string        // GetName of the gateway tried
+	Phone    string        // Empty for bulk sends
+	Fallback bool          // Not the primary gateway
+	Skipped  bool          // Not tried because its circuit breaker is open
+	Err      error         // Nil when the send succeeded
+	Duration time.Duration // Time the provider took
+}
+
+// FailoverGateway sends through the first of its gateways and, when that fails, tries the
+// next in order. Gateways whose circuit breaker is open are skipped without a request.
+type FailoverGateway struct {
+	gateways  []SMSGateway
+	onAttempt func(DeliveryAttempt)
+}
+
+// NewFailoverGateway creates a gateway trying the primary and then each fallback in turn.
+// onAttempt is called after every attempt, for logging; nil prints failures to stdout.
+func NewFailoverGateway(onAttempt func(DeliveryAttempt), primary SMSGateway, fallbacks ...SMSGateway) *FailoverGateway {
+	if onAttempt == nil {
+		onAttempt = func(a DeliveryAttempt) {
+			if a.Err != nil {
+				fmt.Printf("⚠️  SMS delivery via %s failed: %v\n", a.Provider, a.Err)
+			}
+		}
+	}
+	return &FailoverGateway{
+		gateways:  append([]SMSGateway{primary}, fallbacks...),
+		onAttempt: onAttempt,
+	}
+}
+
+// Gateways returns the providers in the order they are tried
+func (f *FailoverGateway) Gateways() []SMSGateway {
+	return f.gateways
+}
+
+// SendOTP sends an OTP code via SMS, failing over to the next gateway on error
+func (f *FailoverGateway) SendOTP(phone, otpCode, appType string) (int64, error) {
+	return f.SendBrandedOTP(phone, otpCode, appType, OTPBranding{})
+}
+
+// SendBrandedOTP sends an OTP code under the given branding, failing over to the next gateway
+// on error. Gateways without branding support send the default OTP message.
+func (f *FailoverGateway) SendBrandedOTP(phone, otpCode, appType string, branding OTPBranding) (int64, error) {
+	return f.try(phone, func(gateway SMSGateway) (int64, bool, error) {
+		if sender, ok := gateway.(BrandedOTPSender); ok {
+			id, err := sender.SendBrandedOTP(phone, otpCode, appType, branding)
+			return id, true, err
+		}
+		id, err := gateway.SendOTP(phone, otpCode, appType)
+		return id, true, err
+	})
+}
+
+// SendBulkSMS sends free-form text, failing over to the next gateway that supports it
+func (f *FailoverGateway) SendBulkSMS(phones []string, message string) (int64, error) {
+	return f.try("", func(gateway SMSGateway) (int64, bool, error) {
+		sender, ok := gateway.(interface {
+			SendBulkSMS(phones []string, message string) (int64, error)
+		})
+		if !ok {
+			return 0, false, nil
+		}
+		id, err := sender.SendBulkSMS(phones, message)
+		return id, true, err
+	})
+}
+
+// try runs send on each gateway in order until one succeeds. send reports false when the
+// gateway cannot handle the message at all. A gateway skipped for its open breaker adds
+// ErrCircuitOpen to the returned error, so IsUnavailable holds for it.
+func (f *FailoverGateway) try(phone string, send func(SMSGateway) (int64, bool, error)) (int64, error) {
+	var errs []error
+	for i, gateway := range f.gateways {
+		attempt := DeliveryAttempt{Provider: gateway.GetName(), Phone: phone, Fallback: i > 0}
+		if !gatewayAvailable(gateway) {
+			attempt.Skipped = true
+			attempt.Err = fmt.Errorf("%s: %w", attempt.Provider, httpclient.ErrCircuitOpen)
+			f.onAttempt(attempt)
+			errs = append(errs, attempt.Err)
+			continue
+		}
+
+		start := time.Now()
+		id, supported, err := send(gateway)
+		if !supported {
+			continue
+		}
+		attempt.Duration = time.Since(start)
+		attempt.Err = err
+		f.onAttempt(attempt)
+		if err == nil {
+			return id, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", attempt.Provider, err))
+	}
+
+	if len(errs) == 0 {
+		return 0, fmt.Errorf("no SMS gateway supports this message")
+	}
+	return 0, errors.Join(errs...)
+}
+
+// Available reports whether any gateway's circuit breaker lets requests through
+func (f *FailoverGateway) Available() bool {
+	for _, gateway := range f.gateways {
+		if gatewayAvailable(gateway) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetName returns the providers in the order they are tried
+func (f *FailoverGateway) GetName() string {
+	names := make([]string, len(f.gateways))
+	for i, gateway := range f.gateways {
+		names[i] = gateway.GetName()
+	}
+	return "Failover (" + strings.Join(names, " -> ") + ")"
+}
+
+func gatewayAvailable(gateway SMSGateway) bool {
+	reporter, ok := gateway.(interface{ Available() bool })
+	return !ok || reporter.Available()
+}
@@ -0,0 +1,83 @@
+package sms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubGateway struct {
+	name        string
+	err         error
+	unavailable bool
+	sends       int
+}
+
+func (g *stubGateway) SendOTP(phone, otpCode, appType string) (int64, error) {
+	g.sends++
+	if g.err != nil {
+		return 0, g.err
+	}
+	return int64(len(g.name)), nil
+}
+
+func (g *stubGateway) GetName() string { return g.name }
+
+func (g *stubGateway) Available() bool { return !g.unavailable }
+
+func TestFailoverGateway_UsesPrimaryWhenItSucceeds(t *testing.T) {
+	primary := &stubGateway{name: "dialog"}
+	fallback := &stubGateway{name: "twilio"}
+	var attempts []DeliveryAttempt
+	gateway := NewFailoverGateway(func(a DeliveryAttempt) { attempts = append(attempts, a) }, primary, fallback)
+
+	_, err := gateway.SendOTP("0771234567", "123456", "passenger")
+	require.NoError(t, err)
+	assert.Equal(t, 1, primary.sends)
+	assert.Equal(t, 0, fallback.sends)
+	require.Len(t, attempts, 1)
+	assert.Equal(t, "dialog", attempts[0].Provider)
+	assert.NoError(t, attempts[0].Err)
+}
+
+func TestFailoverGateway_FailsOver(t *testing.T) {
+	primary := &stubGateway{name: "dialog", err: errors.New("SMS sending failed with error code: 2003")}
+	fallback := &stubGateway{name: "twilio"}
+	var attempts []DeliveryAttempt
+	gateway := NewFailoverGateway(func(a DeliveryAttempt) { attempts = append(attempts, a) }, primary, fallback)
+
+	id, err := gateway.SendOTP("0771234567", "123456", "passenger")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("twilio")), id)
+	require.Len(t, attempts, 2)
+	assert.Error(t, attempts[0].Err)
+	assert.False(t, attempts[0].Fallback)
+	assert.Equal(t, "twilio", attempts[1].Provider)
+	assert.True(t, attempts[1].Fallback)
+	assert.NoError(t, attempts[1].Err)
+}
+
+func TestFailoverGateway_SkipsOpenBreaker(t *testing.T) {
+	primary := &stubGateway{name: "dialog", unavailable: true}
+	fallback := &stubGateway{name: "twilio", err: errors.New("down")}
+	gateway := NewFailoverGateway(nil, primary, fallback)
+
+	_, err := gateway.SendOTP("0771234567", "123456", "passenger")
+	require.Error(t, err)
+	assert.Equal(t, 0, primary.sends, "no request while the breaker is open")
+	assert.Equal(t, 1, fallback.sends)
+	assert.Contains(t, err.Error(), "down")
+	assert.True(t, IsUnavailable(err))
+	assert.True(t, gateway.Available())
+
+	fallback.unavailable = true
+	assert.False(t, gateway.Available())
+}
+
+func TestFailoverGateway_BulkSMSUnsupported(t *testing.T) {
+	gateway := NewFailoverGateway(nil, &stubGateway{name: "dialog"})
+	_, err := gateway.SendBulkSMS([]string{"0771234567"}, "hello")
+	assert.Error(t, err)
+}
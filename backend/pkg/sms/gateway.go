@@ -1,5 +1,11 @@
 package sms
 
+import (
+	"errors"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+)
+
 // SMSGateway defines the interface for sending SMS messages
 type SMSGateway interface {
 	// SendOTP sends an OTP code via SMS
@@ -9,3 +15,37 @@ type SMSGateway interface {
 	// GetName returns the name of the SMS gateway implementation
 	GetName() string
 }
+
+// WhatsAppOTPSender is implemented by gateways that can also deliver OTPs over WhatsApp
+type WhatsAppOTPSender interface {
+	// SendWhatsAppOTP sends an OTP code as a WhatsApp message
+	SendWhatsAppOTP(phone, otpCode, appType string) (int64, error)
+}
+
+// OTPBranding customises an OTP SMS for a white-label tenant; empty fields use the gateway defaults
+type OTPBranding struct {
+	Mask    string // Sender ID shown to the recipient
+	AppName string // Name used in the message text
+}
+
+// BrandedOTPSender is implemented by gateways that can send OTPs under another sender mask and app name
+type BrandedOTPSender interface {
+	// SendBrandedOTP sends an OTP code via SMS using the given branding
+	SendBrandedOTP(phone, otpCode, appType string, branding OTPBranding) (int64, error)
+}
+
+// brandingOrDefault fills empty branding fields from the gateway's own mask
+func brandingOrDefault(branding OTPBranding, mask string) OTPBranding {
+	if branding.Mask == "" {
+		branding.Mask = mask
+	}
+	if branding.AppName == "" {
+		branding.AppName = "SmartTransit"
+	}
+	return branding
+}
+
+// IsUnavailable reports whether a send failed fast because the gateway's circuit breaker is open
+func IsUnavailable(err error) bool {
+	return errors.Is(err, httpclient.ErrCircuitOpen)
+}
@@ -0,0 +1,203 @@
+package sms
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/smarttransit/sms-auth-backend/pkg/httpclient"
+)
+
+const twilioAPIURL = "https://api.twilio.com"
+
+// TwilioConfig holds Twilio Programmable Messaging credentials
+type TwilioConfig struct {
+	AccountSID       string
+	AuthToken        string
+	From             string // Sender number in E.164 format, or an alphanumeric sender ID
+	APIURL           string // Defaults to https://api.twilio.com
+	DriverAppHash    string // Driver/Conductor app signature hash
+	PassengerAppHash string // Passenger app signature hash
+
+	HTTP httpclient.Config // Timeouts, retries and circuit breaker (zero values use defaults)
+}
+
+// TwilioGateway implements SMS sending via the Twilio Messages API
+type TwilioGateway struct {
+	accountSID       string
+	authToken        string
+	from             string
+	apiURL           string
+	driverAppHash    string
+	passengerAppHash string
+	client           *httpclient.Client
+}
+
+// NewTwilioGateway creates a new Twilio SMS gateway client
+func NewTwilioGateway(config TwilioConfig) *TwilioGateway {
+	if config.APIURL == "" {
+		config.APIURL = twilioAPIURL
+	}
+	if config.HTTP.Name == "" {
+		config.HTTP.Name = "twilio"
+	}
+	return &TwilioGateway{
+		accountSID:       config.AccountSID,
+		authToken:        config.AuthToken,
+		from:             config.From,
+		apiURL:           strings.TrimRight(config.APIURL, "/"),
+		driverAppHash:    config.DriverAppHash,
+		passengerAppHash: config.PassengerAppHash,
+		client:           newGatewayClient(config.HTTP),
+	}
+}
+
+// twilioMessageResponse is the part of a Twilio message resource we read
+type twilioMessageResponse struct {
+	SID          string `json:"sid"`
+	Status       string `json:"status"`
+	ErrorCode    *int   `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// twilioErrorResponse is returned by Twilio when a request is rejected
+type twilioErrorResponse struct {
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+	MoreInfo string `json:"more_info"`
+}
+
+// FormatPhoneE164 converts a Sri Lankan mobile number to E.164, e.g. "0771234567" -> "+94771234567"
+func FormatPhoneE164(phone string) (string, error) {
+	local, err := FormatPhoneForDialog(phone)
+	if err != nil {
+		return "", err
+	}
+	return "+94" + local, nil
+}
+
+// SendOTP sends an OTP to a single phone number
+func (t *TwilioGateway) SendOTP(phone, otpCode, appType string) (int64, error) {
+	return t.SendBrandedOTP(phone, otpCode, appType, OTPBranding{})
+}
+
+// SendBrandedOTP sends an OTP under a tenant's app name. Twilio sends from the configured
+// number, so the branding's mask is not used.
+func (t *TwilioGateway) SendBrandedOTP(phone, otpCode, appType string, branding OTPBranding) (int64, error) {
+	branding = brandingOrDefault(branding, t.from)
+
+	appHash := t.passengerAppHash
+	if appType == "driver" || appType == "conductor" {
+		appHash = t.driverAppHash
+	}
+
+	message := fmt.Sprintf("Your %s OTP is: %s\n\nPlease use the above OTP to complete your action.\n\nRegards,\n%s",
+		branding.AppName, otpCode, branding.AppName)
+	if appHash != "" {
+		message += "\n" + appHash
+	}
+
+	to, err := FormatPhoneE164(phone)
+	if err != nil {
+		return 0, fmt.Errorf("failed to format phone number: %w", err)
+	}
+	return t.send(to, message)
+}
+
+// SendBulkSMS sends the message to each recipient; Twilio takes one recipient per request.
+// Invalid numbers are skipped; the send fails if no message could be sent.
+func (t *TwilioGateway) SendBulkSMS(phones []string, message string) (int64, error) {
+	var transactionID int64
+	var lastErr error
+	for _, phone := range phones {
+		to, err := FormatPhoneE164(phone)
+		if err != nil {
+			continue
+		}
+		id, err := t.send(to, message)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if transactionID == 0 {
+			transactionID = id
+		}
+	}
+
+	if transactionID == 0 {
+		if lastErr != nil {
+			return 0, lastErr
+		}
+		return 0, fmt.Errorf("no valid recipients after formatting")
+	}
+	return transactionID, nil
+}
+
+// send creates one message. Twilio identifies messages by SID; the returned transaction ID
+// is generated locally like the Dialog gateways'.
+func (t *TwilioGateway) send(to, message string) (int64, error) {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", t.from)
+	form.Set("Body", message)
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", t.apiURL, url.PathEscape(t.accountSID))
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create SMS request: %w", err)
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	transactionID := time.Now().UnixMicro()
+
+	// Sent once - a retried request could deliver the SMS twice
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send SMS request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read SMS response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		var errResp twilioErrorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Message != "" {
+			return 0, fmt.Errorf("SMS sending failed: %s (error code: %d)", errResp.Message, errResp.Code)
+		}
+		return 0, fmt.Errorf("SMS API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var msgResp twilioMessageResponse
+	if err := json.Unmarshal(body, &msgResp); err != nil {
+		return 0, fmt.Errorf("failed to parse SMS response: %w", err)
+	}
+	if msgResp.Status == "failed" || msgResp.Status == "undelivered" {
+		return 0, fmt.Errorf("SMS sending failed: %s (status: %s)", msgResp.ErrorMessage, msgResp.Status)
+	}
+
+	fmt.Printf("✅ SMS sent via Twilio, SID: %s, status: %s\n", msgResp.SID, msgResp.Status)
+	return transactionID, nil
+}
+
+// GetName returns the name of this SMS gateway
+func (t *TwilioGateway) GetName() string {
+	return "Twilio Gateway"
+}
+
+// Available reports whether the gateway's circuit breaker lets requests through
+func (t *TwilioGateway) Available() bool {
+	return t.client.Available()
+}
+
+// HTTPStats returns the gateway's HTTP client metrics
+func (t *TwilioGateway) HTTPStats() httpclient.Stats {
+	return t.client.Stats()
+}
@@ -0,0 +1,63 @@
+package sms
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatPhoneE164(t *testing.T) {
+	phone, err := FormatPhoneE164("0771234567")
+	require.NoError(t, err)
+	assert.Equal(t, "+94771234567", phone)
+
+	_, err = FormatPhoneE164("12345")
+	assert.Error(t, err)
+}
+
+func TestTwilioGatewaySendOTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC123", user)
+		assert.Equal(t, "secret", pass)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "+94771234567", r.PostForm.Get("To"))
+		assert.Equal(t, "+15005550006", r.PostForm.Get("From"))
+		assert.True(t, strings.Contains(r.PostForm.Get("Body"), "123456"))
+		assert.True(t, strings.HasSuffix(r.PostForm.Get("Body"), "\ndriverhash"))
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM1", "status": "queued"}`))
+	}))
+	defer server.Close()
+
+	gateway := NewTwilioGateway(TwilioConfig{
+		AccountSID:    "AC123",
+		AuthToken:     "secret",
+		From:          "+15005550006",
+		APIURL:        server.URL,
+		DriverAppHash: "driverhash",
+	})
+	id, err := gateway.SendOTP("0771234567", "123456", "driver")
+	require.NoError(t, err)
+	assert.NotZero(t, id)
+}
+
+func TestTwilioGatewaySendOTPRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": 21211, "message": "The 'To' number is not a valid phone number."}`))
+	}))
+	defer server.Close()
+
+	gateway := NewTwilioGateway(TwilioConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15005550006", APIURL: server.URL})
+	_, err := gateway.SendOTP("0771234567", "123456", "passenger")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "21211")
+}